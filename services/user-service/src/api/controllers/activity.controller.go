@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// ActivityController handles user activity feed requests
+type ActivityController struct {
+	activityService *services.ActivityService
+}
+
+// NewActivityController creates a new activity controller
+func NewActivityController(activityService *services.ActivityService) *ActivityController {
+	return &ActivityController{
+		activityService: activityService,
+	}
+}
+
+// GetUserActivity gets the current user's activity feed, newest first. The
+// cursor query parameter continues a page.
+func (c *ActivityController) GetUserActivity(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get activity
+	activity, err := c.activityService.GetUserActivity(ctx, userID, ctx.Query("cursor"), activityLimit(ctx))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user activity")
+		ctx.Error(apperrors.From(err, "Failed to get user activity"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, activity)
+}
+
+// GetOrganizationActivity gets the activity of an organization's members,
+// newest first. The userId query parameter narrows it to one member and
+// cursor continues a page.
+func (c *ActivityController) GetOrganizationActivity(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get activity
+	activity, err := c.activityService.GetOrganizationActivity(ctx, id, ctx.Query("userId"), ctx.Query("cursor"), activityLimit(ctx), userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get organization activity")
+		ctx.Error(apperrors.From(err, "Failed to get organization activity"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, activity)
+}
+
+// activityLimit parses the limit query parameter of an activity feed
+func activityLimit(ctx *gin.Context) int {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(services.DefaultActivityLimit)))
+	if err != nil || limit < 1 || limit > services.MaxActivityLimit {
+		return services.DefaultActivityLimit
+	}
+	return limit
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/api/validators"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// BannerController handles the platform banner
+type BannerController struct {
+	bannerService *services.BannerService
+	validator     *validator.Validate
+}
+
+// NewBannerController creates a new banner controller
+func NewBannerController(bannerService *services.BannerService) *BannerController {
+	return &BannerController{
+		bannerService: bannerService,
+		validator:     validators.New(),
+	}
+}
+
+// GetCurrentBanner gets the active platform banner. It is public and served
+// from memory, so clients can poll it cheaply.
+func (c *BannerController) GetCurrentBanner(ctx *gin.Context) {
+	banner := c.bannerService.Current()
+	if banner == nil {
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, banner)
+}
+
+// GetBanner gets the platform banner, including one that isn't active
+func (c *BannerController) GetBanner(ctx *gin.Context) {
+	banner, err := c.bannerService.GetBanner(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Failed to get platform banner")
+		ctx.Error(apperrors.From(err, "Failed to get banner"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, banner)
+}
+
+// UpdateBanner sets the platform banner
+func (c *BannerController) UpdateBanner(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request
+	var req models.UpdateBannerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+
+	// Set banner
+	banner, err := c.bannerService.SetBanner(ctx, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Failed to update platform banner")
+		ctx.Error(apperrors.From(err, "Failed to update banner"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, banner)
+}
+
+// DeleteBanner removes the platform banner
+func (c *BannerController) DeleteBanner(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	if err := c.bannerService.ClearBanner(ctx, userID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Failed to delete platform banner")
+		ctx.Error(apperrors.From(err, "Failed to delete banner"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Banner deleted successfully"})
+}
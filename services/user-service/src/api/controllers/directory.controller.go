@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// DirectoryController handles public organization directory requests
+type DirectoryController struct {
+	directoryService *services.DirectoryService
+}
+
+// NewDirectoryController creates a new directory controller
+func NewDirectoryController(directoryService *services.DirectoryService) *DirectoryController {
+	return &DirectoryController{
+		directoryService: directoryService,
+	}
+}
+
+// ListOrganizations lists the organizations in the public directory
+func (c *DirectoryController) ListOrganizations(ctx *gin.Context) {
+	// Parse pagination parameters
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := models.DirectoryFilter{
+		Search:   ctx.Query("search"),
+		Industry: ctx.Query("industry"),
+	}
+
+	// Signed in users see which organizations they are members of
+	userID := middleware.GetUserId(ctx)
+
+	// Get organizations
+	directory, err := c.directoryService.ListOrganizations(ctx, filter, page, limit, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Int("page", page).Int("limit", limit).
+			Msg("Failed to list directory organizations")
+		ctx.Error(apperrors.From(err, "Failed to list organizations"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, directory)
+}
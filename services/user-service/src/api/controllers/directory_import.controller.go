@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/httpx"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// DirectoryImportController handles workspace directory import requests
+type DirectoryImportController struct {
+	directoryImportService *services.DirectoryImportService
+}
+
+// NewDirectoryImportController creates a new directory import controller
+func NewDirectoryImportController(directoryImportService *services.DirectoryImportService) *DirectoryImportController {
+	return &DirectoryImportController{
+		directoryImportService: directoryImportService,
+	}
+}
+
+// ImportGoogle previews or applies an import of a Google Workspace directory
+func (c *DirectoryImportController) ImportGoogle(ctx *gin.Context) {
+	c.importDirectory(ctx, models.DirectoryImportGoogle)
+}
+
+// ImportSlack previews or applies an import of a Slack workspace directory
+func (c *DirectoryImportController) ImportSlack(ctx *gin.Context) {
+	c.importDirectory(ctx, models.DirectoryImportSlack)
+}
+
+// importDirectory previews or applies an import of a directory into an organization
+func (c *DirectoryImportController) importDirectory(ctx *gin.Context, source models.DirectoryImportSource) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.DirectoryImportRequest](ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := c.directoryImportService.Import(ctx, id, source, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("source", string(source)).Msg("Failed to import directory members")
+		ctx.Error(apperrors.From(err, "Failed to import directory members"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, resp)
+}
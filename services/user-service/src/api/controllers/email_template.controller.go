@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/api/validators"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// EmailTemplateController handles organization email template requests
+type EmailTemplateController struct {
+	templateService *services.EmailTemplateService
+	validator       *validator.Validate
+}
+
+// NewEmailTemplateController creates a new email template controller
+func NewEmailTemplateController(templateService *services.EmailTemplateService) *EmailTemplateController {
+	return &EmailTemplateController{
+		templateService: templateService,
+		validator:       validators.New(),
+	}
+}
+
+// GetTemplates gets the email template overrides of an organization
+func (c *EmailTemplateController) GetTemplates(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get templates
+	templates, err := c.templateService.GetTemplates(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get email templates")
+		ctx.Error(apperrors.From(err, "Failed to get email templates"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"templates":          templates,
+		"availableTemplates": models.EmailTemplateKeys,
+		"builtinVariables":   models.EmailTemplateBuiltinVariables,
+	})
+}
+
+// GetTemplate gets the override of one email template
+func (c *EmailTemplateController) GetTemplate(ctx *gin.Context) {
+	id := ctx.Param("id")
+	key := models.EmailTemplateKey(ctx.Param("key"))
+	if id == "" || key == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or template key"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get template
+	template, err := c.templateService.GetTemplate(ctx, id, key, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("key", string(key)).Msg("Failed to get email template")
+		ctx.Error(apperrors.From(err, "Failed to get email template"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, template)
+}
+
+// GetTemplateVersions gets the version history of one email template
+func (c *EmailTemplateController) GetTemplateVersions(ctx *gin.Context) {
+	id := ctx.Param("id")
+	key := models.EmailTemplateKey(ctx.Param("key"))
+	if id == "" || key == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or template key"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get versions
+	versions, err := c.templateService.GetTemplateVersions(ctx, id, key, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("key", string(key)).Msg("Failed to get email template versions")
+		ctx.Error(apperrors.From(err, "Failed to get email template versions"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"versions": versions})
+}
+
+// UpdateTemplate overrides an email template with a new version
+func (c *EmailTemplateController) UpdateTemplate(ctx *gin.Context) {
+	id := ctx.Param("id")
+	key := models.EmailTemplateKey(ctx.Param("key"))
+	if id == "" || key == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or template key"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request
+	var req models.UpdateEmailTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		ctx.Error(apperrors.InvalidField("variables", err.Error()))
+		return
+	}
+
+	// Update template
+	template, err := c.templateService.UpdateTemplate(ctx, id, key, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("key", string(key)).Msg("Failed to update email template")
+		ctx.Error(apperrors.From(err, "Failed to update email template"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate resets an email template to the platform default
+func (c *EmailTemplateController) DeleteTemplate(ctx *gin.Context) {
+	id := ctx.Param("id")
+	key := models.EmailTemplateKey(ctx.Param("key"))
+	if id == "" || key == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or template key"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Reset template
+	err := c.templateService.DeleteTemplate(ctx, id, key, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("key", string(key)).Msg("Failed to delete email template")
+		ctx.Error(apperrors.From(err, "Failed to delete email template"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Email template reset to default"})
+}
+
+// GetInternalTemplates gets the branding and email template overrides of an
+// organization for the notification service
+func (c *EmailTemplateController) GetInternalTemplates(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get templates
+	response, err := c.templateService.GetEffectiveTemplates(ctx, id)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get effective email templates")
+		ctx.Error(apperrors.From(err, "Failed to get email templates"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, response)
+}
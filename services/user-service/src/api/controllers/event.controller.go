@@ -0,0 +1,304 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/api/validators"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// EventController handles organization event requests
+type EventController struct {
+	eventService *services.EventService
+	validator    *validator.Validate
+}
+
+// NewEventController creates a new event controller
+func NewEventController(eventService *services.EventService) *EventController {
+	return &EventController{
+		eventService: eventService,
+		validator:    validators.New(),
+	}
+}
+
+// CreateEvent creates a draft event in an organization
+func (c *EventController) CreateEvent(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request
+	var req models.CreateEventRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+
+	// Create event
+	event, err := c.eventService.CreateEvent(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to create event")
+		ctx.Error(apperrors.From(err, "Failed to create event"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusCreated, event)
+}
+
+// GetEvents lists the events of an organization, optionally with a status
+func (c *EventController) GetEvents(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	status := models.EventStatus(ctx.Query("status"))
+	switch status {
+	case "", models.EventDraft, models.EventLive, models.EventEnded:
+	default:
+		ctx.Error(apperrors.InvalidField("status", "status must be one of draft, live, ended"))
+		return
+	}
+
+	// Parse pagination parameters
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Get events
+	events, total, err := c.eventService.GetEvents(ctx, id, status, page, limit, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get events")
+		ctx.Error(apperrors.From(err, "Failed to get events"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"events":     events,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+		"totalPages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// GetEvent gets an event of an organization
+func (c *EventController) GetEvent(ctx *gin.Context) {
+	id := ctx.Param("id")
+	eventID := ctx.Param("eventId")
+	if id == "" || eventID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or event ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get event
+	event, err := c.eventService.GetEvent(ctx, id, eventID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("eventId", eventID).Msg("Failed to get event")
+		ctx.Error(apperrors.From(err, "Failed to get event"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, event)
+}
+
+// GetEventByCode gets the event with a join code
+func (c *EventController) GetEventByCode(ctx *gin.Context) {
+	code := ctx.Param("code")
+	if code == "" {
+		ctx.Error(apperrors.MissingParameter("event code"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get event
+	event, err := c.eventService.GetEventByCode(ctx, code)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("code", code).Msg("Failed to get event by code")
+		ctx.Error(apperrors.From(err, "Failed to get event"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, event)
+}
+
+// UpdateEvent updates an event of an organization
+func (c *EventController) UpdateEvent(ctx *gin.Context) {
+	id := ctx.Param("id")
+	eventID := ctx.Param("eventId")
+	if id == "" || eventID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or event ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request
+	var req models.UpdateEventRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+
+	// Update event
+	event, err := c.eventService.UpdateEvent(ctx, id, eventID, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("eventId", eventID).Msg("Failed to update event")
+		ctx.Error(apperrors.From(err, "Failed to update event"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, event)
+}
+
+// DeleteEvent deletes an event of an organization
+func (c *EventController) DeleteEvent(ctx *gin.Context) {
+	id := ctx.Param("id")
+	eventID := ctx.Param("eventId")
+	if id == "" || eventID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or event ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Delete event
+	err := c.eventService.DeleteEvent(ctx, id, eventID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("eventId", eventID).Msg("Failed to delete event")
+		ctx.Error(apperrors.From(err, "Failed to delete event"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully"})
+}
+
+// StartEvent takes a draft event live
+func (c *EventController) StartEvent(ctx *gin.Context) {
+	id := ctx.Param("id")
+	eventID := ctx.Param("eventId")
+	if id == "" || eventID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or event ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Start event
+	event, err := c.eventService.StartEvent(ctx, id, eventID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("eventId", eventID).Msg("Failed to start event")
+		ctx.Error(apperrors.From(err, "Failed to start event"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, event)
+}
+
+// EndEvent ends a live event
+func (c *EventController) EndEvent(ctx *gin.Context) {
+	id := ctx.Param("id")
+	eventID := ctx.Param("eventId")
+	if id == "" || eventID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or event ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// End event
+	event, err := c.eventService.EndEvent(ctx, id, eventID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("eventId", eventID).Msg("Failed to end event")
+		ctx.Error(apperrors.From(err, "Failed to end event"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, event)
+}
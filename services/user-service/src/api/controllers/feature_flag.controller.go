@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/httpx"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// FeatureFlagController handles feature flags
+type FeatureFlagController struct {
+	flagService *services.FeatureFlagService
+}
+
+// NewFeatureFlagController creates a new feature flag controller
+func NewFeatureFlagController(flagService *services.FeatureFlagService) *FeatureFlagController {
+	return &FeatureFlagController{
+		flagService: flagService,
+	}
+}
+
+// GetFlags gets every flag evaluated for the caller, in the organization
+// given by the orgId query parameter if set
+func (c *FeatureFlagController) GetFlags(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	orgID := ctx.Query("orgId")
+	flags, err := c.flagService.Evaluate(ctx, userID, orgID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to evaluate feature flags")
+		ctx.Error(apperrors.From(err, "Failed to get feature flags"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flags)
+}
+
+// ListFlags lists every feature flag with its overrides
+func (c *FeatureFlagController) ListFlags(ctx *gin.Context) {
+	flags, err := c.flagService.ListFlags(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Failed to list feature flags")
+		ctx.Error(apperrors.From(err, "Failed to list feature flags"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"flags": flags})
+}
+
+// GetFlag gets a feature flag
+func (c *FeatureFlagController) GetFlag(ctx *gin.Context) {
+	key := ctx.Param("key")
+
+	flag, err := c.flagService.GetFlag(ctx, key)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("key", key).Msg("Failed to get feature flag")
+		ctx.Error(apperrors.From(err, "Failed to get feature flag"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flag)
+}
+
+// UpdateFlag creates or updates a feature flag
+func (c *FeatureFlagController) UpdateFlag(ctx *gin.Context) {
+	key := ctx.Param("key")
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateFeatureFlagRequest](ctx)
+	if !ok {
+		return
+	}
+
+	flag, err := c.flagService.UpdateFlag(ctx, key, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("key", key).Msg("Failed to update feature flag")
+		ctx.Error(apperrors.From(err, "Failed to update feature flag"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flag)
+}
+
+// DeleteFlag deletes a feature flag
+func (c *FeatureFlagController) DeleteFlag(ctx *gin.Context) {
+	key := ctx.Param("key")
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	if err := c.flagService.DeleteFlag(ctx, key, userID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("key", key).Msg("Failed to delete feature flag")
+		ctx.Error(apperrors.From(err, "Failed to delete feature flag"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Feature flag deleted successfully"})
+}
+
+// SetOverride turns a feature flag on or off for an organization or user
+func (c *FeatureFlagController) SetOverride(ctx *gin.Context) {
+	key := ctx.Param("key")
+	scope := ctx.Param("scope")
+	targetID := ctx.Param("targetId")
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.SetFlagOverrideRequest](ctx)
+	if !ok {
+		return
+	}
+
+	flag, err := c.flagService.SetOverride(ctx, key, scope, targetID, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("key", key).Str("scope", scope).Str("targetId", targetID).
+			Msg("Failed to set feature flag override")
+		ctx.Error(apperrors.From(err, "Failed to set feature flag override"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flag)
+}
+
+// DeleteOverride removes the override of a feature flag for an organization
+// or user
+func (c *FeatureFlagController) DeleteOverride(ctx *gin.Context) {
+	key := ctx.Param("key")
+	scope := ctx.Param("scope")
+	targetID := ctx.Param("targetId")
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	flag, err := c.flagService.DeleteOverride(ctx, key, scope, targetID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("key", key).Str("scope", scope).Str("targetId", targetID).
+			Msg("Failed to delete feature flag override")
+		ctx.Error(apperrors.From(err, "Failed to delete feature flag override"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flag)
+}
@@ -0,0 +1,290 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/api/validators"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// GroupController handles organization group requests
+type GroupController struct {
+	groupService *services.GroupService
+	validator    *validator.Validate
+}
+
+// NewGroupController creates a new group controller
+func NewGroupController(groupService *services.GroupService) *GroupController {
+	return &GroupController{
+		groupService: groupService,
+		validator:    validators.New(),
+	}
+}
+
+// CreateGroup creates a group in an organization
+func (c *GroupController) CreateGroup(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request
+	var req models.CreateGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+
+	// Create group
+	group, err := c.groupService.CreateGroup(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to create group")
+		ctx.Error(apperrors.From(err, "Failed to create group"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusCreated, group.ToResponse(true))
+}
+
+// GetGroups lists the groups of an organization
+func (c *GroupController) GetGroups(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse pagination parameters
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Get groups
+	groups, total, err := c.groupService.GetGroups(ctx, id, page, limit, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get groups")
+		ctx.Error(apperrors.From(err, "Failed to get groups"))
+		return
+	}
+
+	// Convert to response
+	responses := make([]models.GroupResponse, len(groups))
+	for i, group := range groups {
+		responses[i] = group.ToResponse(false)
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"groups":     responses,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+		"totalPages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// GetGroup gets a group of an organization with its members
+func (c *GroupController) GetGroup(ctx *gin.Context) {
+	id := ctx.Param("id")
+	groupID := ctx.Param("groupId")
+	if id == "" || groupID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or group ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get group
+	group, err := c.groupService.GetGroup(ctx, id, groupID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("groupId", groupID).Msg("Failed to get group")
+		ctx.Error(apperrors.From(err, "Failed to get group"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, group.ToResponse(true))
+}
+
+// UpdateGroup updates a group of an organization
+func (c *GroupController) UpdateGroup(ctx *gin.Context) {
+	id := ctx.Param("id")
+	groupID := ctx.Param("groupId")
+	if id == "" || groupID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or group ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request
+	var req models.UpdateGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+
+	// Update group
+	group, err := c.groupService.UpdateGroup(ctx, id, groupID, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("groupId", groupID).Msg("Failed to update group")
+		ctx.Error(apperrors.From(err, "Failed to update group"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, group.ToResponse(true))
+}
+
+// DeleteGroup deletes a group of an organization
+func (c *GroupController) DeleteGroup(ctx *gin.Context) {
+	id := ctx.Param("id")
+	groupID := ctx.Param("groupId")
+	if id == "" || groupID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or group ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Delete group
+	err := c.groupService.DeleteGroup(ctx, id, groupID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("groupId", groupID).Msg("Failed to delete group")
+		ctx.Error(apperrors.From(err, "Failed to delete group"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Group deleted successfully"})
+}
+
+// AddGroupMembers adds organization members to a group
+func (c *GroupController) AddGroupMembers(ctx *gin.Context) {
+	id := ctx.Param("id")
+	groupID := ctx.Param("groupId")
+	if id == "" || groupID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or group ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request
+	var req models.AddGroupMembersRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+
+	// Add members
+	group, err := c.groupService.AddGroupMembers(ctx, id, groupID, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("groupId", groupID).Msg("Failed to add group members")
+		ctx.Error(apperrors.From(err, "Failed to add group members"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, group.ToResponse(true))
+}
+
+// RemoveGroupMember removes a member from a group
+func (c *GroupController) RemoveGroupMember(ctx *gin.Context) {
+	id := ctx.Param("id")
+	groupID := ctx.Param("groupId")
+	memberID := ctx.Param("userId")
+	if id == "" || groupID == "" || memberID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID, group ID or user ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Remove member
+	err := c.groupService.RemoveGroupMember(ctx, id, groupID, memberID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("groupId", groupID).Str("memberId", memberID).
+			Msg("Failed to remove group member")
+		ctx.Error(apperrors.From(err, "Failed to remove group member"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Group member removed successfully"})
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/api/validators"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// ImpersonationController handles admin impersonation requests
+type ImpersonationController struct {
+	impersonationService *services.ImpersonationService
+	validator            *validator.Validate
+}
+
+// NewImpersonationController creates a new impersonation controller
+func NewImpersonationController(impersonationService *services.ImpersonationService) *ImpersonationController {
+	return &ImpersonationController{
+		impersonationService: impersonationService,
+		validator:            validators.New(),
+	}
+}
+
+// StartImpersonation starts an impersonation session of a user
+func (c *ImpersonationController) StartImpersonation(ctx *gin.Context) {
+	userID := ctx.Param("userId")
+	if userID == "" {
+		ctx.Error(apperrors.MissingParameter("user ID"))
+		return
+	}
+
+	// Get admin ID from context
+	adminID := middleware.GetUserId(ctx)
+	if adminID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request
+	var req models.StartImpersonationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+
+	// Start impersonation
+	impersonation, err := c.impersonationService.StartImpersonation(ctx, userID, req, adminID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Str("adminId", adminID).Msg("Failed to start impersonation")
+		ctx.Error(apperrors.From(err, "Failed to start impersonation"))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, impersonation)
+}
+
+// EndImpersonation ends an impersonation session
+func (c *ImpersonationController) EndImpersonation(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("impersonation ID"))
+		return
+	}
+
+	// Get admin ID from context
+	adminID := middleware.GetUserId(ctx)
+	if adminID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// End impersonation
+	if err := c.impersonationService.EndImpersonation(ctx, id, adminID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("impersonationId", id).Msg("Failed to end impersonation")
+		ctx.Error(apperrors.From(err, "Failed to end impersonation"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Impersonation ended successfully"})
+}
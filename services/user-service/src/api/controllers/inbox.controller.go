@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/api/validators"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// InboxController handles in-app notification inbox requests
+type InboxController struct {
+	inboxService *services.InboxService
+	validator    *validator.Validate
+}
+
+// NewInboxController creates a new inbox controller
+func NewInboxController(inboxService *services.InboxService) *InboxController {
+	return &InboxController{
+		inboxService: inboxService,
+		validator:    validators.New(),
+	}
+}
+
+// GetInbox gets the current user's inbox, newest first. The unread query
+// parameter lists unread notifications only, and cursor continues a page.
+func (c *InboxController) GetInbox(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse query
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(services.DefaultInboxLimit)))
+	if err != nil || limit < 1 || limit > services.MaxInboxLimit {
+		limit = services.DefaultInboxLimit
+	}
+	unreadOnly := ctx.Query("unread") == "true"
+
+	// Get inbox
+	inbox, err := c.inboxService.GetInbox(ctx, userID, ctx.Query("cursor"), limit, unreadOnly)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get inbox")
+		ctx.Error(apperrors.From(err, "Failed to get inbox"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, inbox)
+}
+
+// GetUnreadCount gets the number of unread notifications in the current
+// user's inbox
+func (c *InboxController) GetUnreadCount(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Count unread notifications
+	unread, err := c.inboxService.GetUnreadCount(ctx, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to count unread notifications")
+		ctx.Error(apperrors.From(err, "Failed to count unread notifications"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, unread)
+}
+
+// MarkRead marks a notification of the current user's inbox read
+func (c *InboxController) MarkRead(ctx *gin.Context) {
+	id := ctx.Param("notificationId")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("notification ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Mark notification read
+	result, err := c.inboxService.MarkRead(ctx, userID, id)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Str("id", id).Msg("Failed to mark notification read")
+		ctx.Error(apperrors.From(err, "Failed to mark notification read"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, result)
+}
+
+// MarkAllRead marks every unread notification of the current user's inbox
+// read
+func (c *InboxController) MarkAllRead(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request; the body is optional
+	var req models.MarkInboxReadRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.Error(apperrors.InvalidBody(err))
+			return
+		}
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+
+	// Mark notifications read
+	result, err := c.inboxService.MarkAllRead(ctx, userID, req)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to mark notifications read")
+		ctx.Error(apperrors.From(err, "Failed to mark notifications read"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Stream streams the changes of the current user's inbox as server-sent
+// events: the unread count first and whenever it changes, each notification
+// added to the inbox, and periodic pings. Clients reconnect when the stream
+// ends.
+func (c *InboxController) Stream(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Start the event stream
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	err := c.inboxService.Watch(ctx.Request.Context(), userID, func(event string, data interface{}) error {
+		ctx.SSEvent(event, data)
+		ctx.Writer.Flush()
+		return ctx.Request.Context().Err()
+	})
+	if err != nil && ctx.Request.Context().Err() == nil {
+		// The response has started, so the error can only be logged
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to stream inbox")
+	}
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// JobController handles the admin view of scheduled jobs
+type JobController struct {
+	jobService *services.JobService
+}
+
+// NewJobController creates a new job controller
+func NewJobController(jobService *services.JobService) *JobController {
+	return &JobController{
+		jobService: jobService,
+	}
+}
+
+// GetJobs lists the scheduled jobs with their next and latest run
+func (c *JobController) GetJobs(ctx *gin.Context) {
+	jobs, err := c.jobService.ListJobs(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Failed to get jobs")
+		ctx.Error(apperrors.From(err, "Failed to get jobs"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"jobs": jobs,
+	})
+}
+
+// GetJobRuns lists the run history of a job
+func (c *JobController) GetJobRuns(ctx *gin.Context) {
+	name := ctx.Param("name")
+	if name == "" {
+		ctx.Error(apperrors.MissingParameter("job name"))
+		return
+	}
+
+	// Parse pagination parameters
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	runs, total, err := c.jobService.ListRuns(ctx, name, page, limit)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("job", name).Msg("Failed to get job runs")
+		ctx.Error(apperrors.From(err, "Failed to get job runs"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"runs":       runs,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+		"totalPages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// TriggerJob starts a run of a job outside its schedule
+func (c *JobController) TriggerJob(ctx *gin.Context) {
+	name := ctx.Param("name")
+	if name == "" {
+		ctx.Error(apperrors.MissingParameter("job name"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	run, err := c.jobService.TriggerJob(ctx, name, userID)
+	if err != nil {
+		ctx.Error(apperrors.From(err, "Failed to trigger job"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusAccepted, run)
+}
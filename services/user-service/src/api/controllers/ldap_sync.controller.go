@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/httpx"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// LDAPSyncController handles organization LDAP sync requests
+type LDAPSyncController struct {
+	ldapSyncService *services.LDAPSyncService
+}
+
+// NewLDAPSyncController creates a new LDAP sync controller
+func NewLDAPSyncController(ldapSyncService *services.LDAPSyncService) *LDAPSyncController {
+	return &LDAPSyncController{
+		ldapSyncService: ldapSyncService,
+	}
+}
+
+// GetConfig gets the LDAP sync configuration of an organization
+func (c *LDAPSyncController) GetConfig(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	config, err := c.ldapSyncService.GetConfig(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get LDAP sync configuration")
+		ctx.Error(apperrors.From(err, "Failed to get LDAP sync configuration"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, config.ToResponse())
+}
+
+// UpdateConfig creates or updates the LDAP sync configuration of an
+// organization
+func (c *LDAPSyncController) UpdateConfig(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateLDAPSyncRequest](ctx)
+	if !ok {
+		return
+	}
+
+	config, err := c.ldapSyncService.UpdateConfig(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to update LDAP sync configuration")
+		ctx.Error(apperrors.From(err, "Failed to update LDAP sync configuration"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, config.ToResponse())
+}
+
+// DeleteConfig deletes the LDAP sync configuration of an organization
+func (c *LDAPSyncController) DeleteConfig(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	if err := c.ldapSyncService.DeleteConfig(ctx, id, userID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to delete LDAP sync configuration")
+		ctx.Error(apperrors.From(err, "Failed to delete LDAP sync configuration"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "LDAP sync configuration deleted successfully"})
+}
+
+// RunSync starts a sync of an organization, optionally as a dry run
+func (c *LDAPSyncController) RunSync(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindOptionalAndValidate[models.RunLDAPSyncRequest](ctx)
+	if !ok {
+		return
+	}
+
+	run, err := c.ldapSyncService.RunNow(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to start LDAP sync")
+		ctx.Error(apperrors.From(err, "Failed to start LDAP sync"))
+		return
+	}
+
+	// The sync runs in the background; its report has the outcome
+	ctx.JSON(http.StatusAccepted, run)
+}
+
+// GetRuns lists the reports of an organization's syncs
+func (c *LDAPSyncController) GetRuns(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse pagination parameters
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	runs, total, err := c.ldapSyncService.ListRuns(ctx, id, page, limit, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get LDAP sync reports")
+		ctx.Error(apperrors.From(err, "Failed to get LDAP sync reports"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"runs":       runs,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+		"totalPages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// GetRun gets the report of a sync of an organization, with its changes
+func (c *LDAPSyncController) GetRun(ctx *gin.Context) {
+	id := ctx.Param("id")
+	runID := ctx.Param("runId")
+	if id == "" || runID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or run ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	run, err := c.ldapSyncService.GetRun(ctx, id, runID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("runId", runID).Msg("Failed to get LDAP sync report")
+		ctx.Error(apperrors.From(err, "Failed to get LDAP sync report"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, run)
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/your-username/slido-clone/user-service/api/validators"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// MemberStorageController handles moving organization members between
+// storage layouts
+type MemberStorageController struct {
+	memberStorageService *services.MemberStorageService
+	validator            *validator.Validate
+}
+
+// NewMemberStorageController creates a new member storage controller
+func NewMemberStorageController(memberStorageService *services.MemberStorageService) *MemberStorageController {
+	return &MemberStorageController{
+		memberStorageService: memberStorageService,
+		validator:            validators.New(),
+	}
+}
+
+// UpdateMemberStorage moves the members of an organization to a storage layout
+func (c *MemberStorageController) UpdateMemberStorage(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Parse request
+	var req models.UpdateMemberStorageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+
+	// Move members
+	org, err := c.memberStorageService.SetMemberStorage(ctx, id, req.Storage)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to update organization member storage")
+		ctx.Error(apperrors.From(err, "Failed to update organization member storage"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"id":            org.ID,
+		"memberStorage": req.Storage,
+		"memberCount":   len(org.Members),
+	})
+}
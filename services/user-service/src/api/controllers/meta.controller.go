@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/models"
+)
+
+// MetaController serves the catalogs clients render preference pickers from
+type MetaController struct{}
+
+// NewMetaController creates a new meta controller
+func NewMetaController() *MetaController {
+	return &MetaController{}
+}
+
+// GetTimezones lists the timezones users can pick, with their current
+// offsets from UTC. Offsets change with daylight saving time, so clients
+// may cache the list for an hour.
+func (c *MetaController) GetTimezones(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=3600")
+	ctx.JSON(http.StatusOK, gin.H{
+		"timezones": models.Timezones(time.Now()),
+	})
+}
+
+// GetLocales lists the languages users can pick, and the themes
+func (c *MetaController) GetLocales(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=3600")
+	ctx.JSON(http.StatusOK, gin.H{
+		"locales": models.SupportedLocales,
+		"themes":  models.Themes,
+	})
+}
@@ -5,24 +5,23 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
-	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/api/httpx"
 	"github.com/your-username/slido-clone/user-service/api/middleware"
 	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
 	"github.com/your-username/slido-clone/user-service/services"
 )
 
 // OrganizationController handles organization-related requests
 type OrganizationController struct {
 	orgService *services.OrganizationService
-	validator  *validator.Validate
 }
 
 // NewOrganizationController creates a new organization controller
 func NewOrganizationController(orgService *services.OrganizationService) *OrganizationController {
 	return &OrganizationController{
 		orgService: orgService,
-		validator:  validator.New(),
 	}
 }
 
@@ -30,22 +29,28 @@ func NewOrganizationController(orgService *services.OrganizationService) *Organi
 func (c *OrganizationController) GetOrganization(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing organization ID"})
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Parse fieldset
+	fields, ok := httpx.Fields(ctx, models.OrganizationResponseFields, models.OrganizationExpansions...)
+	if !ok {
 		return
 	}
 
 	// Get organization
 	org, err := c.orgService.GetOrganizationByID(ctx, id)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to get organization")
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get organization")
+		ctx.Error(apperrors.From(err, "Failed to get organization"))
 		return
 	}
 
-	// Return response
-	includeMembers := ctx.Query("includeMembers") == "true"
-	includeSettings := ctx.Query("includeSettings") == "true"
-	ctx.JSON(http.StatusOK, org.ToResponse(includeMembers, includeSettings))
+	// Return response, unless the client has it already
+	includeMembers := ctx.Query("includeMembers") == "true" || fields.Includes("members")
+	includeSettings := ctx.Query("includeSettings") == "true" || fields.Includes("settings")
+	httpx.VersionedJSON(ctx, http.StatusOK, org.ETag(), httpx.Sparse(org.ToResponse(includeMembers, includeSettings), fields))
 }
 
 // CreateOrganization creates a new organization
@@ -53,29 +58,21 @@ func (c *OrganizationController) CreateOrganization(ctx *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
-	// Parse request
-	var req models.CreateOrganizationRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
-		return
-	}
-
-	// Validate request
-	if err := c.validator.Struct(req); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": validationErrors.Error()})
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.CreateOrganizationRequest](ctx)
+	if !ok {
 		return
 	}
 
 	// Create organization
 	org, err := c.orgService.CreateOrganization(ctx, req, userID)
 	if err != nil {
-		log.Error().Err(err).Interface("req", req).Msg("Failed to create organization")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organization", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Interface("req", req).Msg("Failed to create organization")
+		ctx.Error(apperrors.From(err, "Failed to create organization"))
 		return
 	}
 
@@ -87,63 +84,96 @@ func (c *OrganizationController) CreateOrganization(ctx *gin.Context) {
 func (c *OrganizationController) UpdateOrganization(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing organization ID"})
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateOrganizationRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Update organization
+	org, err := c.orgService.UpdateOrganization(ctx, id, req, userID, httpx.IfMatch(ctx))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to update organization")
+		ctx.Error(apperrors.From(err, "Failed to update organization"))
+		return
+	}
+
+	// Return response
+	httpx.VersionedJSON(ctx, http.StatusOK, org.ETag(), org.ToResponse(true, true))
+}
+
+// PatchOrganization updates an organization with a JSON merge patch
+func (c *OrganizationController) PatchOrganization(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
 		return
 	}
 
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
-	// Parse request
-	var req models.UpdateOrganizationRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	// Get organization to merge the patch into
+	org, err := c.orgService.GetOrganizationByID(ctx, id)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get organization for patch")
+		ctx.Error(apperrors.From(err, "Failed to get organization"))
 		return
 	}
 
-	// Validate request
-	if err := c.validator.Struct(req); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": validationErrors.Error()})
+	// Parse and validate patch
+	req, ok := httpx.BindMergePatch[models.UpdateOrganizationRequest](ctx, org.ToResponse(false, true), models.OrganizationClearableFields)
+	if !ok {
 		return
 	}
 
 	// Update organization
-	org, err := c.orgService.UpdateOrganization(ctx, id, req, userID)
+	org, err = c.orgService.UpdateOrganization(ctx, id, req, userID, httpx.IfMatch(ctx))
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to update organization")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to patch organization")
+		ctx.Error(apperrors.From(err, "Failed to update organization"))
 		return
 	}
 
 	// Return response
-	ctx.JSON(http.StatusOK, org.ToResponse(true, true))
+	httpx.VersionedJSON(ctx, http.StatusOK, org.ETag(), org.ToResponse(true, true))
 }
 
 // DeleteOrganization deletes an organization
 func (c *OrganizationController) DeleteOrganization(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing organization ID"})
+		ctx.Error(apperrors.MissingParameter("organization ID"))
 		return
 	}
 
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
 	// Delete organization
 	err := c.orgService.DeleteOrganization(ctx, id, userID)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to delete organization")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organization", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to delete organization")
+		ctx.Error(apperrors.From(err, "Failed to delete organization"))
 		return
 	}
 
@@ -151,65 +181,239 @@ func (c *OrganizationController) DeleteOrganization(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "Organization deleted successfully"})
 }
 
-// GetOrganizationMembers gets organization members
+// GetOrganizationMembers gets a page of organization members with their
+// user details. The role and search query parameters filter the members.
 func (c *OrganizationController) GetOrganizationMembers(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing organization ID"})
+		ctx.Error(apperrors.MissingParameter("organization ID"))
 		return
 	}
 
-	// Get organization
-	org, err := c.orgService.GetOrganizationByID(ctx, id)
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse pagination and filter parameters
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := models.OrganizationMemberFilter{
+		Role:   models.OrganizationMemberRole(ctx.Query("role")),
+		Search: ctx.Query("search"),
+	}
+	switch filter.Role {
+	case "", models.OrgRoleOwner, models.OrgRoleAdmin, models.OrgRoleMember, models.OrgRoleGuest:
+	default:
+		ctx.Error(apperrors.InvalidField("role", "role must be one of owner, admin, member, guest"))
+		return
+	}
+
+	// Get members
+	members, err := c.orgService.GetOrganizationMembers(ctx, id, filter, page, limit, userID)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to get organization for members")
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get organization members")
+		ctx.Error(apperrors.From(err, "Failed to get organization members"))
 		return
 	}
 
 	// Return response
-	ctx.JSON(http.StatusOK, gin.H{
-		"organizationId":   org.ID,
-		"organizationName": org.Name,
-		"memberCount":      len(org.Members),
-		"members":          org.Members,
-	})
+	ctx.JSON(http.StatusOK, members)
 }
 
-// AddOrganizationMember adds a member to an organization
-func (c *OrganizationController) AddOrganizationMember(ctx *gin.Context) {
+// GetTwoFactorCompliance lists the members of an organization that haven't
+// enabled two-factor authentication
+func (c *OrganizationController) GetTwoFactorCompliance(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Get report
+	report, err := c.orgService.GetTwoFactorCompliance(ctx, id, page, limit, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get two-factor compliance")
+		ctx.Error(apperrors.From(err, "Failed to get two-factor compliance"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetAccessReview lists the members of an organization who haven't logged in,
+// or been confirmed by an access review, within the inactiveDays query
+// parameter
+func (c *OrganizationController) GetAccessReview(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse pagination and inactivity parameters
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	inactiveDays := 0
+	if value := ctx.Query("inactiveDays"); value != "" {
+		if inactiveDays, err = strconv.Atoi(value); err != nil {
+			ctx.Error(apperrors.InvalidField("inactiveDays", "inactiveDays must be a number of days"))
+			return
+		}
+	}
+
+	// Get report
+	report, err := c.orgService.GetAccessReview(ctx, id, inactiveDays, page, limit, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get access review")
+		ctx.Error(apperrors.From(err, "Failed to get access review"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, report)
+}
+
+// ConfirmAccessReview confirms that members of an organization keep their
+// access
+func (c *OrganizationController) ConfirmAccessReview(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.AccessReviewDecisionRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Confirm members
+	result, err := c.orgService.ConfirmAccessReview(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Int("members", len(req.UserIDs)).
+			Msg("Failed to confirm access review")
+		ctx.Error(apperrors.From(err, "Failed to confirm access review"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, result)
+}
+
+// RevokeAccessReview removes members of an organization whose access an
+// access review revoked
+func (c *OrganizationController) RevokeAccessReview(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing organization ID"})
+		ctx.Error(apperrors.MissingParameter("organization ID"))
 		return
 	}
 
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.AccessReviewDecisionRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Revoke members
+	result, err := c.orgService.RevokeAccessReview(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Int("members", len(req.UserIDs)).
+			Msg("Failed to revoke access review")
+		ctx.Error(apperrors.From(err, "Failed to revoke access review"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, result)
+}
+
+// AddOrganizationMember adds a member to an organization
+func (c *OrganizationController) AddOrganizationMember(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
 		return
 	}
 
-	// Parse request
-	var req models.AddOrganizationMemberRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
-	// Validate request
-	if err := c.validator.Struct(req); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": validationErrors.Error()})
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.AddOrganizationMemberRequest](ctx)
+	if !ok {
 		return
 	}
 
 	// Add member
 	err := c.orgService.AddOrganizationMember(ctx, id, req, userID)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to add organization member")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add organization member", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to add organization member")
+		ctx.Error(apperrors.From(err, "Failed to add organization member"))
 		return
 	}
 
@@ -221,42 +425,34 @@ func (c *OrganizationController) AddOrganizationMember(ctx *gin.Context) {
 func (c *OrganizationController) UpdateOrganizationMember(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing organization ID"})
+		ctx.Error(apperrors.MissingParameter("organization ID"))
 		return
 	}
 
 	memberID := ctx.Param("memberId")
 	if memberID == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing member ID"})
+		ctx.Error(apperrors.MissingParameter("member ID"))
 		return
 	}
 
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	// Parse request
-	var req models.UpdateOrganizationMemberRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
-	// Validate request
-	if err := c.validator.Struct(req); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": validationErrors.Error()})
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateOrganizationMemberRequest](ctx)
+	if !ok {
 		return
 	}
 
 	// Update member
 	err := c.orgService.UpdateOrganizationMember(ctx, id, memberID, req, userID)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Str("memberId", memberID).Interface("req", req).Msg("Failed to update organization member")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization member", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("memberId", memberID).Interface("req", req).Msg("Failed to update organization member")
+		ctx.Error(apperrors.From(err, "Failed to update organization member"))
 		return
 	}
 
@@ -268,28 +464,28 @@ func (c *OrganizationController) UpdateOrganizationMember(ctx *gin.Context) {
 func (c *OrganizationController) RemoveOrganizationMember(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing organization ID"})
+		ctx.Error(apperrors.MissingParameter("organization ID"))
 		return
 	}
 
 	memberID := ctx.Param("memberId")
 	if memberID == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing member ID"})
+		ctx.Error(apperrors.MissingParameter("member ID"))
 		return
 	}
 
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
 	// Remove member
 	err := c.orgService.RemoveOrganizationMember(ctx, id, memberID, userID)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Str("memberId", memberID).Msg("Failed to remove organization member")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove organization member", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("memberId", memberID).Msg("Failed to remove organization member")
+		ctx.Error(apperrors.From(err, "Failed to remove organization member"))
 		return
 	}
 
@@ -297,12 +493,47 @@ func (c *OrganizationController) RemoveOrganizationMember(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "Organization member removed successfully"})
 }
 
+// BulkOrganizationMembers runs a batch of member operations on an
+// organization
+func (c *OrganizationController) BulkOrganizationMembers(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.BulkOrganizationMembersRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Run operations
+	result, err := c.orgService.BulkOrganizationMembers(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Int("operations", len(req.Operations)).
+			Msg("Failed to run bulk organization member operations")
+		ctx.Error(apperrors.From(err, "Failed to run bulk organization member operations"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, result)
+}
+
 // GetUserOrganizations gets organizations by user
 func (c *OrganizationController) GetUserOrganizations(ctx *gin.Context) {
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
@@ -320,24 +551,37 @@ func (c *OrganizationController) GetUserOrganizations(ctx *gin.Context) {
 		limit = 20
 	}
 
+	// Parse tag filter
+	tags, err := models.ParseTagFilter(ctx.Query("tags"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Parse fieldset
+	fields, ok := httpx.Fields(ctx, models.OrganizationResponseFields, models.OrganizationExpansions...)
+	if !ok {
+		return
+	}
+
 	// Get organizations
-	orgs, total, err := c.orgService.GetOrganizationsByUser(ctx, userID, page, limit)
+	orgs, total, err := c.orgService.GetOrganizationsByUser(ctx, userID, tags, page, limit, fields)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Int("page", page).Int("limit", limit).
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Int("page", page).Int("limit", limit).
 			Msg("Failed to get user organizations")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user organizations", "message": err.Error()})
+		ctx.Error(apperrors.From(err, "Failed to get user organizations"))
 		return
 	}
 
 	// Convert to response
 	orgResponses := make([]models.OrganizationResponse, len(orgs))
 	for i, org := range orgs {
-		orgResponses[i] = org.ToResponse(false, false)
+		orgResponses[i] = org.ToResponse(fields.Includes("members"), fields.Includes("settings"))
 	}
 
 	// Return response
 	ctx.JSON(http.StatusOK, gin.H{
-		"organizations": orgResponses,
+		"organizations": httpx.SparseList(orgResponses, fields),
 		"total":         total,
 		"page":          page,
 		"limit":         limit,
@@ -345,18 +589,79 @@ func (c *OrganizationController) GetUserOrganizations(ctx *gin.Context) {
 	})
 }
 
+// AddOrganizationTags adds tags to an organization
+func (c *OrganizationController) AddOrganizationTags(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.AddTagsRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Add tags
+	org, err := c.orgService.AddOrganizationTags(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to add organization tags")
+		ctx.Error(apperrors.From(err, "Failed to add organization tags"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, org.ToResponse(false, false))
+}
+
+// RemoveOrganizationTag removes a tag from an organization
+func (c *OrganizationController) RemoveOrganizationTag(ctx *gin.Context) {
+	id := ctx.Param("id")
+	tag := ctx.Param("tag")
+	if id == "" || tag == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or tag"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Remove tag
+	err := c.orgService.RemoveOrganizationTag(ctx, id, tag, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("tag", tag).Msg("Failed to remove organization tag")
+		ctx.Error(apperrors.From(err, "Failed to remove organization tag"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Organization tag removed successfully"})
+}
+
 // GetOrganizationTeams gets teams in an organization
 func (c *OrganizationController) GetOrganizationTeams(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing organization ID"})
+		ctx.Error(apperrors.MissingParameter("organization ID"))
 		return
 	}
 
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
@@ -374,24 +679,38 @@ func (c *OrganizationController) GetOrganizationTeams(ctx *gin.Context) {
 		limit = 20
 	}
 
+	// Parse tag filter
+	tags, err := models.ParseTagFilter(ctx.Query("tags"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Parse fieldset
+	fields, ok := httpx.Fields(ctx, models.TeamResponseFields, models.TeamExpansions...)
+	if !ok {
+		return
+	}
+	includeArchived := ctx.Query("includeArchived") == "true"
+
 	// Get teams
-	teams, total, err := c.orgService.GetOrganizationTeams(ctx, id, page, limit, userID)
+	teams, viewer, total, err := c.orgService.GetOrganizationTeams(ctx, id, tags, page, limit, fields, includeArchived, userID)
 	if err != nil {
-		log.Error().Err(err).Str("orgId", id).Int("page", page).Int("limit", limit).
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Int("page", page).Int("limit", limit).
 			Msg("Failed to get organization teams")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization teams", "message": err.Error()})
+		ctx.Error(apperrors.From(err, "Failed to get organization teams"))
 		return
 	}
 
 	// Convert to response
 	teamResponses := make([]models.TeamResponse, len(teams))
 	for i, team := range teams {
-		teamResponses[i] = team.ToResponse(false)
+		teamResponses[i] = team.ToResponseFor(viewer, fields.Includes("members"))
 	}
 
 	// Return response
 	ctx.JSON(http.StatusOK, gin.H{
-		"teams":      teamResponses,
+		"teams":      httpx.SparseList(teamResponses, fields),
 		"total":      total,
 		"page":       page,
 		"limit":      limit,
@@ -415,27 +734,607 @@ func (c *OrganizationController) ListOrganizations(ctx *gin.Context) {
 		limit = 20
 	}
 
+	// Parse tag filter
+	tags, err := models.ParseTagFilter(ctx.Query("tags"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Parse fieldset
+	fields, ok := httpx.Fields(ctx, models.OrganizationResponseFields, models.OrganizationExpansions...)
+	if !ok {
+		return
+	}
+
 	// Get organizations
-	orgs, total, err := c.orgService.ListOrganizations(ctx, page, limit)
+	orgs, total, err := c.orgService.ListOrganizations(ctx, tags, page, limit, fields)
 	if err != nil {
-		log.Error().Err(err).Int("page", page).Int("limit", limit).
+		logger.Ctx(ctx).Error().Err(err).Int("page", page).Int("limit", limit).
 			Msg("Failed to list organizations")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list organizations", "message": err.Error()})
+		ctx.Error(apperrors.From(err, "Failed to list organizations"))
 		return
 	}
 
 	// Convert to response
 	orgResponses := make([]models.OrganizationResponse, len(orgs))
 	for i, org := range orgs {
-		orgResponses[i] = org.ToResponse(false, false)
+		orgResponses[i] = org.ToResponse(fields.Includes("members"), fields.Includes("settings"))
 	}
 
 	// Return response
 	ctx.JSON(http.StatusOK, gin.H{
-		"organizations": orgResponses,
+		"organizations": httpx.SparseList(orgResponses, fields),
 		"total":         total,
 		"page":          page,
 		"limit":         limit,
 		"totalPages":    (total + int64(limit) - 1) / int64(limit),
 	})
 }
+
+// GetApprovalWebhook gets the approval webhook of an organization
+func (c *OrganizationController) GetApprovalWebhook(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get webhook
+	hook, err := c.orgService.GetApprovalWebhook(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get approval webhook")
+		ctx.Error(apperrors.From(err, "Failed to get approval webhook"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, hook.ToResponse())
+}
+
+// UpdateApprovalWebhook configures the approval webhook of an organization
+func (c *OrganizationController) UpdateApprovalWebhook(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateApprovalWebhookRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Update webhook
+	hook, err := c.orgService.UpdateApprovalWebhook(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to update approval webhook")
+		ctx.Error(apperrors.From(err, "Failed to update approval webhook"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, hook.ToResponse())
+}
+
+// DeleteApprovalWebhook removes the approval webhook of an organization
+func (c *OrganizationController) DeleteApprovalWebhook(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Delete webhook
+	err := c.orgService.DeleteApprovalWebhook(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to delete approval webhook")
+		ctx.Error(apperrors.From(err, "Failed to delete approval webhook"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Approval webhook deleted successfully"})
+}
+
+// GetCustomFields gets the custom profile fields of an organization
+func (c *OrganizationController) GetCustomFields(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get custom fields
+	fields, err := c.orgService.GetCustomFields(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get custom fields")
+		ctx.Error(apperrors.From(err, "Failed to get custom fields"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, models.CustomFieldsResponse{OrganizationID: id, Fields: fields})
+}
+
+// UpdateCustomFields replaces the custom profile fields of an organization
+func (c *OrganizationController) UpdateCustomFields(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateCustomFieldsRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Update custom fields
+	fields, err := c.orgService.UpdateCustomFields(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to update custom fields")
+		ctx.Error(apperrors.From(err, "Failed to update custom fields"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, models.CustomFieldsResponse{OrganizationID: id, Fields: fields})
+}
+
+// GetSettingsHistory lists the versions of an organization's settings
+func (c *OrganizationController) GetSettingsHistory(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse pagination parameters
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Get settings history
+	versions, total, err := c.orgService.GetSettingsHistory(ctx, id, page, limit, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Int("page", page).Int("limit", limit).
+			Msg("Failed to get settings history")
+		ctx.Error(apperrors.From(err, "Failed to get settings history"))
+		return
+	}
+	if versions == nil {
+		versions = []*models.SettingsVersion{}
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"versions":   versions,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+		"totalPages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// RollbackSettings restores the settings of an organization to a recorded
+// version
+func (c *OrganizationController) RollbackSettings(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil || version < 1 {
+		ctx.Error(apperrors.InvalidField("version", "version must be a positive number"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Roll back settings
+	org, err := c.orgService.RollbackSettings(ctx, id, version, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Int("version", version).Msg("Failed to roll back settings")
+		ctx.Error(apperrors.From(err, "Failed to roll back settings"))
+		return
+	}
+
+	// Return response
+	httpx.VersionedJSON(ctx, http.StatusOK, org.ETag(), org.ToResponse(true, true))
+}
+
+// UpdateMemberCustomFields replaces the custom profile field values of an
+// organization member
+func (c *OrganizationController) UpdateMemberCustomFields(ctx *gin.Context) {
+	id := ctx.Param("id")
+	memberID := ctx.Param("memberId")
+	if id == "" || memberID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or member ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateMemberCustomFieldsRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Update values
+	values, err := c.orgService.UpdateMemberCustomFields(ctx, id, memberID, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("memberId", memberID).Msg("Failed to update member custom fields")
+		ctx.Error(apperrors.From(err, "Failed to update member custom fields"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, models.MemberCustomFieldsResponse{OrganizationID: id, UserID: memberID, Values: values})
+}
+
+// TransferOwnership starts a organization ownership transfer
+func (c *OrganizationController) TransferOwnership(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.TransferOwnershipRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Start transfer
+	transfer, err := c.orgService.TransferOwnership(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to transfer organization ownership")
+		ctx.Error(apperrors.From(err, "Failed to transfer organization ownership"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message":  "Ownership transfer pending acceptance by the new owner",
+		"transfer": transfer,
+	})
+}
+
+// AcceptOwnershipTransfer accepts a pending organization ownership transfer
+func (c *OrganizationController) AcceptOwnershipTransfer(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Accept transfer
+	err := c.orgService.AcceptOwnershipTransfer(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to accept organization ownership transfer")
+		ctx.Error(apperrors.From(err, "Failed to accept organization ownership transfer"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Organization ownership transferred successfully"})
+}
+
+// CancelOwnershipTransfer cancels or declines a pending organization ownership transfer
+func (c *OrganizationController) CancelOwnershipTransfer(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Cancel transfer
+	err := c.orgService.CancelOwnershipTransfer(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to cancel organization ownership transfer")
+		ctx.Error(apperrors.From(err, "Failed to cancel organization ownership transfer"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Ownership transfer cancelled successfully"})
+}
+
+// CreateJoinRequest requests to join an organization
+func (c *OrganizationController) CreateJoinRequest(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request; the body is optional
+	req, ok := httpx.BindOptionalAndValidate[models.CreateJoinRequestRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Create join request
+	joinReq, err := c.orgService.CreateJoinRequest(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("userId", userID).Msg("Failed to create join request")
+		ctx.Error(apperrors.From(err, "Failed to create join request"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusCreated, joinReq.ToResponse(nil))
+}
+
+// GetJoinRequests lists the pending join requests of an organization
+func (c *OrganizationController) GetJoinRequests(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse pagination parameters
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Get join requests
+	joinReqs, total, err := c.orgService.GetJoinRequests(ctx, id, page, limit, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Int("page", page).Int("limit", limit).
+			Msg("Failed to get join requests")
+		ctx.Error(apperrors.From(err, "Failed to get join requests"))
+		return
+	}
+
+	// Convert to response
+	joinReqResponses := make([]models.JoinRequestResponse, len(joinReqs))
+	for i, joinReq := range joinReqs {
+		joinReqResponses[i] = joinReq.ToResponse(c.orgService.GetJoinRequestUser(ctx, joinReq))
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"joinRequests": joinReqResponses,
+		"total":        total,
+		"page":         page,
+		"limit":        limit,
+		"totalPages":   (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// ApproveJoinRequest approves a pending join request
+func (c *OrganizationController) ApproveJoinRequest(ctx *gin.Context) {
+	id := ctx.Param("id")
+	requestID := ctx.Param("requestId")
+	if id == "" || requestID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or join request ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request; the body is optional
+	req, ok := httpx.BindOptionalAndValidate[models.ApproveJoinRequestRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Approve join request
+	joinReq, err := c.orgService.ApproveJoinRequest(ctx, id, requestID, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("requestId", requestID).Msg("Failed to approve join request")
+		ctx.Error(apperrors.From(err, "Failed to approve join request"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, joinReq.ToResponse(nil))
+}
+
+// RejectJoinRequest rejects a pending join request
+func (c *OrganizationController) RejectJoinRequest(ctx *gin.Context) {
+	id := ctx.Param("id")
+	requestID := ctx.Param("requestId")
+	if id == "" || requestID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or join request ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request; the body is optional
+	req, ok := httpx.BindOptionalAndValidate[models.RejectJoinRequestRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Reject join request
+	joinReq, err := c.orgService.RejectJoinRequest(ctx, id, requestID, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("requestId", requestID).Msg("Failed to reject join request")
+		ctx.Error(apperrors.From(err, "Failed to reject join request"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, joinReq.ToResponse(nil))
+}
+
+// AcceptInvitation accepts the current user's pending invitation to join an
+// organization
+func (c *OrganizationController) AcceptInvitation(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Accept invitation
+	joinReq, err := c.orgService.AcceptInvitation(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("userId", userID).Msg("Failed to accept invitation")
+		ctx.Error(apperrors.From(err, "Failed to accept invitation"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, joinReq.ToResponse(nil))
+}
+
+// CancelJoinRequest withdraws the current user's pending join request
+func (c *OrganizationController) CancelJoinRequest(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Cancel join request
+	err := c.orgService.CancelJoinRequest(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("userId", userID).Msg("Failed to cancel join request")
+		ctx.Error(apperrors.From(err, "Failed to cancel join request"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Join request cancelled successfully"})
+}
@@ -0,0 +1,130 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"github.com/your-username/slido-clone/user-service/services"
+	"github.com/your-username/slido-clone/user-service/testsupport"
+)
+
+// newOrganizationController creates an organization controller over the
+// fixtures
+func newOrganizationController(f *testsupport.Fixtures) *controllers.OrganizationController {
+	syncService := services.NewSyncService(f.Users, f.Teams, f.Orgs, repositories.NewTombstoneRepository(f.Store), &config.SyncConfig{})
+	orgService := services.NewOrganizationService(f.Orgs, f.Users, f.Teams,
+		repositories.NewJoinRequestRepository(f.Store), repositories.NewSettingsHistoryRepository(f.Store),
+		f.Kafka, syncService, nil, &config.OrganizationConfig{})
+	return controllers.NewOrganizationController(orgService)
+}
+
+func TestDuplicateOrganizationNameConflicts(t *testing.T) {
+	f := testsupport.New(t)
+	owner := f.SeedUser()
+	existing := f.SeedOrganization(owner)
+
+	router := newTestRouter(owner.UserID)
+	orgController := newOrganizationController(f)
+	router.POST("/api/organizations", orgController.CreateOrganization)
+	router.PUT("/api/organizations/:id", orgController.UpdateOrganization)
+
+	other := f.SeedOrganization(owner)
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+	}{
+		{
+			name:   "create",
+			method: http.MethodPost,
+			path:   "/api/organizations",
+			body:   models.CreateOrganizationRequest{Name: existing.Name},
+		},
+		{
+			name:   "rename",
+			method: http.MethodPut,
+			path:   "/api/organizations/" + other.ID,
+			body:   models.UpdateOrganizationRequest{Name: &existing.Name},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := sendJSON(t, router, tt.method, tt.path, tt.body)
+			if rec.Code != http.StatusConflict {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusConflict, rec.Body.String())
+			}
+			if code := errorCode(t, rec); code != "ORGANIZATION_NAME_TAKEN" {
+				t.Errorf("code = %q, want %q", code, "ORGANIZATION_NAME_TAKEN")
+			}
+		})
+	}
+}
+
+func TestOrganizationETagFollowsRepresentation(t *testing.T) {
+	f := testsupport.New(t)
+	owner := f.SeedUser()
+	org := f.SeedOrganization(owner)
+
+	router := newTestRouter(owner.UserID)
+	orgController := newOrganizationController(f)
+	router.GET("/api/organizations/:id", orgController.GetOrganization)
+	router.PUT("/api/organizations/:id", orgController.UpdateOrganization)
+
+	get := func(query, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/organizations/"+org.ID+query, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	full := get("", "").Header().Get("ETag")
+	sparse := get("?fields=name", "").Header().Get("ETag")
+	withSettings := get("?includeSettings=true", "").Header().Get("ETag")
+	if full == "" || full == sparse || full == withSettings || sparse == withSettings {
+		t.Fatalf("ETags of the representations = %s, %s and %s, want distinct tags", full, sparse, withSettings)
+	}
+
+	if rec := get("?fields=name", sparse); rec.Code != http.StatusNotModified {
+		t.Errorf("status with the representation's own ETag = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+	rec := get("", sparse)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status with another representation's ETag = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("ETag"); got != full {
+		t.Errorf("ETag = %s, want %s", got, full)
+	}
+
+	// Any representation's ETag is a precondition for updating its version.
+	// Versions are told apart by the millisecond of their update.
+	time.Sleep(2 * time.Millisecond)
+	name := "Renamed Organization"
+	req := httptest.NewRequest(http.MethodPut, "/api/organizations/"+org.ID, jsonBody(t, models.UpdateOrganizationRequest{Name: &name}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", sparse)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update with a representation's ETag: status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	// The old version's tags no longer hold
+	req = httptest.NewRequest(http.MethodPut, "/api/organizations/"+org.ID, jsonBody(t, models.UpdateOrganizationRequest{Name: &name}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", full)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("update with a stale ETag: status = %d, want %d", rec.Code, http.StatusPreconditionFailed)
+	}
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/httpx"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// OrganizationExportController handles organization export requests
+type OrganizationExportController struct {
+	exportService *services.OrganizationExportService
+}
+
+// NewOrganizationExportController creates a new organization export controller
+func NewOrganizationExportController(exportService *services.OrganizationExportService) *OrganizationExportController {
+	return &OrganizationExportController{
+		exportService: exportService,
+	}
+}
+
+// CreateExport starts an export of an organization's data
+func (c *OrganizationExportController) CreateExport(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindOptionalAndValidate[models.CreateOrganizationExportRequest](ctx)
+	if !ok {
+		return
+	}
+
+	export, err := c.exportService.StartExport(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to start organization export")
+		ctx.Error(apperrors.From(err, "Failed to start organization export"))
+		return
+	}
+
+	// The export runs in the background; its status has the outcome
+	ctx.JSON(http.StatusAccepted, export)
+}
+
+// GetExports lists the exports of an organization
+func (c *OrganizationExportController) GetExports(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse pagination parameters
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	exports, total, err := c.exportService.ListExports(ctx, id, page, limit, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get organization exports")
+		ctx.Error(apperrors.From(err, "Failed to get organization exports"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"exports":    exports,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+		"totalPages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// GetExport gets an export of an organization, with a signed download URL
+// once it's completed
+func (c *OrganizationExportController) GetExport(ctx *gin.Context) {
+	id := ctx.Param("id")
+	exportID := ctx.Param("exportId")
+	if id == "" || exportID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or export ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	export, err := c.exportService.GetExport(ctx, id, exportID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("exportId", exportID).Msg("Failed to get organization export")
+		ctx.Error(apperrors.From(err, "Failed to get organization export"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, export)
+}
+
+// DownloadExport downloads the archive of an export with a signed URL
+func (c *OrganizationExportController) DownloadExport(ctx *gin.Context) {
+	id := ctx.Param("id")
+	exportID := ctx.Param("exportId")
+	if id == "" || exportID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or export ID"))
+		return
+	}
+
+	expires := ctx.Query("expires")
+	signature := ctx.Query("signature")
+	if expires == "" || signature == "" {
+		ctx.Error(apperrors.MissingParameter("expires or signature"))
+		return
+	}
+
+	export, archive, err := c.exportService.Download(ctx, id, exportID, expires, signature)
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("orgId", id).Str("exportId", exportID).Msg("Failed to download organization export")
+		ctx.Error(apperrors.From(err, "Failed to download organization export"))
+		return
+	}
+
+	// Stream the archive
+	ctx.DataFromReader(http.StatusOK, archive.Length(), "application/zip", archive, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, export.ArchiveName()),
+		"Cache-Control":       "no-store",
+	})
+}
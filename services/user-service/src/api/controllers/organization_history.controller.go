@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// OrganizationHistoryController handles organization history requests
+type OrganizationHistoryController struct {
+	historyService *services.OrganizationHistoryService
+}
+
+// NewOrganizationHistoryController creates a new organization history controller
+func NewOrganizationHistoryController(historyService *services.OrganizationHistoryService) *OrganizationHistoryController {
+	return &OrganizationHistoryController{
+		historyService: historyService,
+	}
+}
+
+// GetHistory gets an organization's history with the changes of each
+// transition, newest first. The before query parameter continues a page.
+func (c *OrganizationHistoryController) GetHistory(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse pagination parameters
+	before := 0
+	if value := ctx.Query("before"); value != "" {
+		var err error
+		if before, err = strconv.Atoi(value); err != nil || before < 1 {
+			ctx.Error(apperrors.InvalidField("before", "before must be a positive version"))
+			return
+		}
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(services.DefaultHistoryLimit)))
+	if err != nil || limit < 1 || limit > services.MaxHistoryLimit {
+		limit = services.DefaultHistoryLimit
+	}
+
+	// Get history
+	history, err := c.historyService.GetHistory(ctx, id, before, limit, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get organization history")
+		ctx.Error(apperrors.From(err, "Failed to get organization history"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, history)
+}
+
+// GetState gets the state of an organization at the time of the at query
+// parameter, or now
+func (c *OrganizationHistoryController) GetState(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	at := time.Now().UTC()
+	if value := ctx.Query("at"); value != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, value); err != nil {
+			ctx.Error(apperrors.InvalidField("at", "at must be an RFC 3339 time"))
+			return
+		}
+	}
+
+	// Get state
+	state, err := c.historyService.GetState(ctx, id, at, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get organization state")
+		ctx.Error(apperrors.From(err, "Failed to get organization state"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, state)
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// archiveContentType is the media type of organization export archives
+const archiveContentType = "application/zip"
+
+var errArchiveRequired = apperrors.UnsupportedMediaType("UNSUPPORTED_MEDIA_TYPE", "organization imports must be "+archiveContentType)
+
+// OrganizationImportController handles organization import requests
+type OrganizationImportController struct {
+	importService *services.OrganizationImportService
+}
+
+// NewOrganizationImportController creates a new organization import controller
+func NewOrganizationImportController(importService *services.OrganizationImportService) *OrganizationImportController {
+	return &OrganizationImportController{
+		importService: importService,
+	}
+}
+
+// ImportOrganization previews or applies an import of an organization from
+// the archive of an organization export, sent as the request body
+func (c *OrganizationImportController) ImportOrganization(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	if ctx.ContentType() != archiveContentType {
+		ctx.Error(errArchiveRequired)
+		return
+	}
+
+	// Imports are only previewed unless applied
+	name := ctx.Query("name")
+	apply := ctx.Query("apply") == "true"
+
+	resp, err := c.importService.Import(ctx, ctx.Request.Body, name, apply, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Bool("apply", apply).Msg("Failed to import organization")
+		ctx.Error(apperrors.From(err, "Failed to import organization"))
+		return
+	}
+
+	// Return response
+	status := http.StatusOK
+	if resp.Applied {
+		status = http.StatusCreated
+	}
+	ctx.JSON(status, resp)
+}
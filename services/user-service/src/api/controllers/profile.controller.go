@@ -4,19 +4,23 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
-	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/api/httpx"
 	"github.com/your-username/slido-clone/user-service/api/middleware"
 	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
 	"github.com/your-username/slido-clone/user-service/services"
 )
 
 // ProfileController handles user profile-related requests
 type ProfileController struct {
-	userService *services.UserService
-	teamService *services.TeamService
-	orgService  *services.OrganizationService
-	validator   *validator.Validate
+	userService       *services.UserService
+	teamService       *services.TeamService
+	orgService        *services.OrganizationService
+	permissionService *services.PermissionService
+	sessionService    *services.SessionService
+	presenceService   *services.PresenceService
+	onboardingService *services.OnboardingService
 }
 
 // NewProfileController creates a new profile controller
@@ -24,12 +28,19 @@ func NewProfileController(
 	userService *services.UserService,
 	teamService *services.TeamService,
 	orgService *services.OrganizationService,
+	permissionService *services.PermissionService,
+	sessionService *services.SessionService,
+	presenceService *services.PresenceService,
+	onboardingService *services.OnboardingService,
 ) *ProfileController {
 	return &ProfileController{
-		userService: userService,
-		teamService: teamService,
-		orgService:  orgService,
-		validator:   validator.New(),
+		userService:       userService,
+		teamService:       teamService,
+		orgService:        orgService,
+		permissionService: permissionService,
+		sessionService:    sessionService,
+		presenceService:   presenceService,
+		onboardingService: onboardingService,
 	}
 }
 
@@ -38,20 +49,20 @@ func (c *ProfileController) GetProfile(ctx *gin.Context) {
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
 	// Get user
 	user, err := c.userService.GetUserByUserID(ctx, userID)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Failed to get user profile")
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user profile")
+		ctx.Error(apperrors.From(err, "Failed to get user"))
 		return
 	}
 
-	// Return response
-	ctx.JSON(http.StatusOK, user.ToResponse())
+	// Return response, unless the client has it already
+	httpx.VersionedJSON(ctx, http.StatusOK, user.ETag(), user.ToResponse())
 }
 
 // UpdateProfile updates the current user's profile
@@ -59,42 +70,34 @@ func (c *ProfileController) UpdateProfile(ctx *gin.Context) {
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
 	// Get user
 	user, err := c.userService.GetUserByUserID(ctx, userID)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Failed to get user for profile update")
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		return
-	}
-
-	// Parse request
-	var req models.UpdateUserRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user for profile update")
+		ctx.Error(apperrors.From(err, "Failed to get user"))
 		return
 	}
 
-	// Validate request
-	if err := c.validator.Struct(req); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": validationErrors.Error()})
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateUserRequest](ctx)
+	if !ok {
 		return
 	}
 
 	// Update user profile
-	updatedUser, err := c.userService.UpdateUser(ctx, user.ID, req)
+	updatedUser, err := c.userService.UpdateUser(ctx, user.ID, req, httpx.IfMatch(ctx))
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Interface("req", req).Msg("Failed to update user profile")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Interface("req", req).Msg("Failed to update user profile")
+		ctx.Error(apperrors.From(err, "Failed to update profile"))
 		return
 	}
 
 	// Return response
-	ctx.JSON(http.StatusOK, updatedUser.ToResponse())
+	httpx.VersionedJSON(ctx, http.StatusOK, updatedUser.ETag(), updatedUser.ToResponse())
 }
 
 // GetUserTeams gets the current user's teams
@@ -102,7 +105,7 @@ func (c *ProfileController) GetUserTeams(ctx *gin.Context) {
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
@@ -111,10 +114,10 @@ func (c *ProfileController) GetUserTeams(ctx *gin.Context) {
 	limit := 100 // Get all teams for profile view
 
 	// Get teams
-	teams, total, err := c.teamService.GetTeamsByUser(ctx, userID, page, limit)
+	teams, total, err := c.teamService.GetTeamsByUser(ctx, userID, nil, page, limit, models.FieldSet{}, false)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Failed to get user teams")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get teams", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user teams")
+		ctx.Error(apperrors.From(err, "Failed to get teams"))
 		return
 	}
 
@@ -136,7 +139,7 @@ func (c *ProfileController) GetUserOrganizations(ctx *gin.Context) {
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
@@ -145,10 +148,10 @@ func (c *ProfileController) GetUserOrganizations(ctx *gin.Context) {
 	limit := 100 // Get all organizations for profile view
 
 	// Get organizations
-	orgs, total, err := c.orgService.GetOrganizationsByUser(ctx, userID, page, limit)
+	orgs, total, err := c.orgService.GetOrganizationsByUser(ctx, userID, nil, page, limit, models.FieldSet{})
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Failed to get user organizations")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organizations", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user organizations")
+		ctx.Error(apperrors.From(err, "Failed to get organizations"))
 		return
 	}
 
@@ -170,29 +173,29 @@ func (c *ProfileController) GetFullProfile(ctx *gin.Context) {
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
 	// Get user
 	user, err := c.userService.GetUserByUserID(ctx, userID)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Failed to get user profile")
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user profile")
+		ctx.Error(apperrors.From(err, "Failed to get user"))
 		return
 	}
 
 	// Get teams
-	teams, _, err := c.teamService.GetTeamsByUser(ctx, userID, 1, 100)
+	teams, _, err := c.teamService.GetTeamsByUser(ctx, userID, nil, 1, 100, models.FieldSet{}, false)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Failed to get user teams")
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user teams")
 		teams = []*models.Team{} // Continue with empty teams
 	}
 
 	// Get organizations
-	orgs, _, err := c.orgService.GetOrganizationsByUser(ctx, userID, 1, 100)
+	orgs, _, err := c.orgService.GetOrganizationsByUser(ctx, userID, nil, 1, 100, models.FieldSet{})
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Failed to get user organizations")
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user organizations")
 		orgs = []*models.Organization{} // Continue with empty organizations
 	}
 
@@ -212,37 +215,226 @@ func (c *ProfileController) GetFullProfile(ctx *gin.Context) {
 		"user":          user.ToResponse(),
 		"teams":         teamResponses,
 		"organizations": orgResponses,
+		"favorites":     models.ResolveFavorites(user.Favorites, orgs, teams),
+		"twoFactor":     models.NewTwoFactorResponse(user, orgs),
 	})
 }
 
-// UpdateUserPreferences updates the current user's preferences
-func (c *ProfileController) UpdateUserPreferences(ctx *gin.Context) {
+// UpdateFavorites replaces the organizations and teams the current user pinned
+func (c *ProfileController) UpdateFavorites(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateFavoritesRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Update favorites
+	user, err := c.userService.UpdateFavorites(ctx, userID, req)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to update favorites")
+		ctx.Error(apperrors.From(err, "Failed to update favorites"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"favorites": user.Favorites})
+}
+
+// GetEmailChange gets the current user's pending email change
+func (c *ProfileController) GetEmailChange(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get user
+	user, err := c.userService.GetUserByUserID(ctx, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user for email change")
+		ctx.Error(apperrors.From(err, "Failed to get user"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, user.ToEmailChangeResponse())
+}
+
+// RequestEmailChange starts changing the current user's email. The change
+// applies once the user verifies the new email with the Auth Service.
+func (c *ProfileController) RequestEmailChange(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.EmailChangeRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Request email change
+	user, err := c.userService.RequestEmailChange(ctx, userID, req)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to request email change")
+		ctx.Error(apperrors.From(err, "Failed to request email change"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusAccepted, user.ToEmailChangeResponse())
+}
+
+// CancelEmailChange cancels the current user's pending email change
+func (c *ProfileController) CancelEmailChange(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Cancel email change
+	if err := c.userService.CancelEmailChange(ctx, userID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to cancel email change")
+		ctx.Error(apperrors.From(err, "Failed to cancel email change"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Email change cancelled successfully"})
+}
+
+// GetSessions lists the sessions the current user is signed in with
+func (c *ProfileController) GetSessions(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get sessions
+	sessions, err := c.sessionService.GetUserSessions(ctx, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get sessions")
+		ctx.Error(apperrors.From(err, "Failed to get sessions"))
+		return
+	}
+
+	// Convert to response
+	response := make([]models.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		response[i] = session.ToResponse()
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"sessions": response})
+}
+
+// RevokeSession signs the current user out of one of their sessions
+func (c *ProfileController) RevokeSession(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	sessionID := ctx.Param("id")
+	if sessionID == "" {
+		ctx.Error(apperrors.MissingParameter("session ID"))
+		return
+	}
+
+	// Revoke session
+	if err := c.sessionService.RevokeSession(ctx, userID, sessionID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Str("sessionId", sessionID).Msg("Failed to revoke session")
+		ctx.Error(apperrors.From(err, "Failed to revoke session"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// Heartbeat marks the current user online. Clients send it periodically while
+// the app is open, more often than the returned TTL.
+func (c *ProfileController) Heartbeat(ctx *gin.Context) {
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Record heartbeat
+	response, err := c.presenceService.Heartbeat(ctx, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to record heartbeat")
+		ctx.Error(apperrors.From(err, "Failed to record heartbeat"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetUserPreferences gets the current user's effective preferences, with
+// their organization's defaults applied to those they haven't set
+func (c *ProfileController) GetUserPreferences(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
 	// Get user
 	user, err := c.userService.GetUserByUserID(ctx, userID)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Failed to get user for preferences update")
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user preferences")
+		ctx.Error(apperrors.From(err, "Failed to get user"))
 		return
 	}
 
-	// Parse request
-	var req models.UpdatePreferences
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"preferences": c.userService.ResolvePreferences(ctx, user),
+	})
+}
+
+// UpdateUserPreferences updates the current user's preferences
+func (c *ProfileController) UpdateUserPreferences(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get user
+	user, err := c.userService.GetUserByUserID(ctx, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user for preferences update")
+		ctx.Error(apperrors.From(err, "Failed to get user"))
 		return
 	}
 
-	// Validate request
-	if err := c.validator.Struct(req); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": validationErrors.Error()})
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdatePreferences](ctx)
+	if !ok {
 		return
 	}
 
@@ -252,16 +444,95 @@ func (c *ProfileController) UpdateUserPreferences(ctx *gin.Context) {
 	}
 
 	// Update user preferences
-	updatedUser, err := c.userService.UpdateUser(ctx, user.ID, updateReq)
+	updatedUser, err := c.userService.UpdateUser(ctx, user.ID, updateReq, "")
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Interface("req", req).Msg("Failed to update user preferences")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update preferences", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Interface("req", req).Msg("Failed to update user preferences")
+		ctx.Error(apperrors.From(err, "Failed to update preferences"))
 		return
 	}
 
 	// Return response
 	ctx.JSON(http.StatusOK, gin.H{
 		"message":     "Preferences updated successfully",
-		"preferences": updatedUser.Preferences,
+		"preferences": c.userService.ResolvePreferences(ctx, updatedUser),
 	})
 }
+
+// GetPermissions gets the current user's effective permissions, optionally
+// in an organization (orgId) and team (teamId)
+func (c *ProfileController) GetPermissions(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	orgID := ctx.Query("orgId")
+	teamID := ctx.Query("teamId")
+
+	// Get permissions
+	permissions, err := c.permissionService.GetPermissions(ctx, userID, middleware.GetUserRoles(ctx), orgID, teamID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Str("orgId", orgID).Str("teamId", teamID).
+			Msg("Failed to get user permissions")
+		ctx.Error(apperrors.From(err, "Failed to get permissions"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, permissions)
+}
+
+// GetOnboarding gets the current user's onboarding checklist
+func (c *ProfileController) GetOnboarding(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	onboarding, err := c.onboardingService.GetOnboarding(ctx, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get onboarding")
+		ctx.Error(apperrors.From(err, "Failed to get onboarding"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, onboarding)
+}
+
+// UpdateOnboarding skips steps of the current user's onboarding checklist,
+// or dismisses it, with a JSON merge patch
+func (c *ProfileController) UpdateOnboarding(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get onboarding
+	current, err := c.onboardingService.GetOnboarding(ctx, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get onboarding for update")
+		ctx.Error(apperrors.From(err, "Failed to get onboarding"))
+		return
+	}
+
+	// Parse and validate merge patch
+	req, ok := httpx.BindMergePatch[models.UpdateOnboardingRequest](ctx, current, nil)
+	if !ok {
+		return
+	}
+
+	onboarding, err := c.onboardingService.UpdateOnboarding(ctx, userID, req)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Interface("req", req).Msg("Failed to update onboarding")
+		ctx.Error(apperrors.From(err, "Failed to update onboarding"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, onboarding)
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// ProfileStreamController handles profile stream requests
+type ProfileStreamController struct {
+	streamService *services.ProfileStreamService
+}
+
+// NewProfileStreamController creates a new profile stream controller
+func NewProfileStreamController(streamService *services.ProfileStreamService) *ProfileStreamController {
+	return &ProfileStreamController{
+		streamService: streamService,
+	}
+}
+
+// Stream streams the changes of the current user's profile, memberships and
+// organizations as server-sent events, with periodic pings. Clients
+// reconnect and reload the profile when the stream ends.
+func (c *ProfileStreamController) Stream(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Start the event stream
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	err := c.streamService.Watch(ctx.Request.Context(), userID, func(event string, data interface{}) error {
+		ctx.SSEvent(event, data)
+		ctx.Writer.Flush()
+		return ctx.Request.Context().Err()
+	})
+	if err != nil && ctx.Request.Context().Err() == nil {
+		// The response has started, so the error can only be logged
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to stream profile changes")
+	}
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/realtime"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// RealtimeController handles realtime connection requests
+type RealtimeController struct {
+	realtimeService *services.RealtimeService
+	upgrader        websocket.Upgrader
+}
+
+// NewRealtimeController creates a new realtime controller
+func NewRealtimeController(realtimeService *services.RealtimeService) *RealtimeController {
+	return &RealtimeController{
+		realtimeService: realtimeService,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// Origins are checked by the route's middleware, against the
+			// CORS policy
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+	}
+}
+
+// Connect upgrades the request to a websocket connection sending the
+// membership and presence changes of the current user's organizations.
+// Clients reconnect when the connection closes.
+func (c *RealtimeController) Connect(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// The upgrader writes its own error response
+	conn, err := c.upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		logger.Ctx(ctx).Debug().Err(err).Str("userId", userID).Msg("Failed to upgrade realtime connection")
+		return
+	}
+
+	err = c.realtimeService.Connect(ctx.Request.Context(), conn, userID)
+	if err != nil && !errors.Is(err, realtime.ErrHubClosed) {
+		// The connection was taken over, so the error can only be logged
+		logger.Ctx(ctx).Warn().Err(err).Str("userId", userID).Msg("Realtime connection failed")
+	}
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/api/validators"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// ReplayController handles admin event replays
+type ReplayController struct {
+	replayService *services.ReplayService
+	validator     *validator.Validate
+}
+
+// NewReplayController creates a new replay controller
+func NewReplayController(replayService *services.ReplayService) *ReplayController {
+	return &ReplayController{
+		replayService: replayService,
+		validator:     validators.New(),
+	}
+}
+
+// StartReplay starts re-publishing entity snapshots as replayed events
+func (c *ReplayController) StartReplay(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request
+	var req models.ReplayEventsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+
+	// Start replay
+	job, err := c.replayService.StartReplay(req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("entityType", string(req.EntityType)).Msg("Failed to start event replay")
+		ctx.Error(apperrors.From(err, "Failed to start event replay"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+// GetReplay gets the progress of a replay job
+func (c *ReplayController) GetReplay(ctx *gin.Context) {
+	id := ctx.Param("jobId")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("job ID"))
+		return
+	}
+
+	job, err := c.replayService.GetJob(id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+// CancelReplay stops a running replay job
+func (c *ReplayController) CancelReplay(ctx *gin.Context) {
+	id := ctx.Param("jobId")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("job ID"))
+		return
+	}
+
+	job, err := c.replayService.CancelJob(id)
+	if err != nil {
+		ctx.Error(apperrors.From(err, "Failed to cancel event replay"))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, job)
+}
@@ -0,0 +1,446 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/api/validators"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/scim"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// SCIMController handles SCIM 2.0 provisioning requests
+type SCIMController struct {
+	scimService *services.SCIMService
+	validator   *validator.Validate
+}
+
+// NewSCIMController creates a new SCIM controller
+func NewSCIMController(scimService *services.SCIMService) *SCIMController {
+	return &SCIMController{
+		scimService: scimService,
+		validator:   validators.New(),
+	}
+}
+
+// CreateToken creates a SCIM token for an organization
+func (c *SCIMController) CreateToken(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request
+	var req models.CreateSCIMTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+
+	// Create token
+	token, plainToken, err := c.scimService.CreateToken(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to create SCIM token")
+		ctx.Error(apperrors.From(err, "Failed to create SCIM token"))
+		return
+	}
+
+	// Return response
+	response := token.ToResponse()
+	response.Token = plainToken
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// ListTokens lists the SCIM tokens of an organization
+func (c *SCIMController) ListTokens(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get tokens
+	tokens, err := c.scimService.ListTokens(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to list SCIM tokens")
+		ctx.Error(apperrors.From(err, "Failed to list SCIM tokens"))
+		return
+	}
+
+	// Convert to response
+	tokenResponses := make([]models.SCIMTokenResponse, len(tokens))
+	for i, token := range tokens {
+		tokenResponses[i] = token.ToResponse()
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"tokens": tokenResponses,
+		"total":  len(tokenResponses),
+	})
+}
+
+// RevokeToken revokes a SCIM token of an organization
+func (c *SCIMController) RevokeToken(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	tokenID := ctx.Param("tokenId")
+	if tokenID == "" {
+		ctx.Error(apperrors.MissingParameter("token ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Revoke token
+	err := c.scimService.RevokeToken(ctx, id, tokenID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("tokenId", tokenID).Msg("Failed to revoke SCIM token")
+		ctx.Error(apperrors.From(err, "Failed to revoke SCIM token"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "SCIM token revoked successfully"})
+}
+
+// ListUsers lists SCIM users
+func (c *SCIMController) ListUsers(ctx *gin.Context) {
+	orgID := middleware.GetSCIMOrgId(ctx)
+
+	// Parse filter and pagination
+	filter, err := scim.ParseFilter(ctx.Query("filter"))
+	if err != nil {
+		c.respondError(ctx, http.StatusBadRequest, scim.ErrorTypeInvalidFilter, err.Error())
+		return
+	}
+	startIndex, count := scim.Pagination(ctx.Query("startIndex"), ctx.Query("count"))
+
+	// Get users
+	users, total, err := c.scimService.ListUsers(ctx, orgID, filter, startIndex, count)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to list SCIM users")
+		c.handleError(ctx, err)
+		return
+	}
+
+	// Convert to response
+	resources := make([]models.SCIMUser, len(users))
+	for i, user := range users {
+		resources[i] = user.ToSCIM(services.SCIMBasePath)
+	}
+
+	// Return response
+	c.respond(ctx, http.StatusOK, scim.NewListResponse(resources, total, startIndex, len(resources)))
+}
+
+// GetUser gets a SCIM user
+func (c *SCIMController) GetUser(ctx *gin.Context) {
+	orgID := middleware.GetSCIMOrgId(ctx)
+
+	// Get user
+	user, err := c.scimService.GetUser(ctx, orgID, ctx.Param("id"))
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	// Return response
+	c.respond(ctx, http.StatusOK, user.ToSCIM(services.SCIMBasePath))
+}
+
+// CreateUser provisions a SCIM user
+func (c *SCIMController) CreateUser(ctx *gin.Context) {
+	orgID := middleware.GetSCIMOrgId(ctx)
+
+	// Parse request
+	var req models.SCIMUser
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	// Create user
+	user, err := c.scimService.CreateUser(ctx, orgID, req)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userName", req.UserName).Msg("Failed to create SCIM user")
+		c.handleError(ctx, err)
+		return
+	}
+
+	// Return response
+	c.respond(ctx, http.StatusCreated, user.ToSCIM(services.SCIMBasePath))
+}
+
+// ReplaceUser replaces a SCIM user
+func (c *SCIMController) ReplaceUser(ctx *gin.Context) {
+	orgID := middleware.GetSCIMOrgId(ctx)
+
+	// Parse request
+	var req models.SCIMUser
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	// Replace user
+	user, err := c.scimService.ReplaceUser(ctx, orgID, ctx.Param("id"), req)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("id", ctx.Param("id")).Msg("Failed to replace SCIM user")
+		c.handleError(ctx, err)
+		return
+	}
+
+	// Return response
+	c.respond(ctx, http.StatusOK, user.ToSCIM(services.SCIMBasePath))
+}
+
+// PatchUser patches a SCIM user
+func (c *SCIMController) PatchUser(ctx *gin.Context) {
+	orgID := middleware.GetSCIMOrgId(ctx)
+
+	// Parse request
+	var req scim.PatchRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	// Patch user
+	user, err := c.scimService.PatchUser(ctx, orgID, ctx.Param("id"), req)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("id", ctx.Param("id")).Msg("Failed to patch SCIM user")
+		c.handleError(ctx, err)
+		return
+	}
+
+	// Return response
+	c.respond(ctx, http.StatusOK, user.ToSCIM(services.SCIMBasePath))
+}
+
+// DeleteUser deprovisions a SCIM user
+func (c *SCIMController) DeleteUser(ctx *gin.Context) {
+	orgID := middleware.GetSCIMOrgId(ctx)
+
+	// Delete user
+	err := c.scimService.DeleteUser(ctx, orgID, ctx.Param("id"))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("id", ctx.Param("id")).Msg("Failed to delete SCIM user")
+		c.handleError(ctx, err)
+		return
+	}
+
+	// Return response
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListGroups lists SCIM groups
+func (c *SCIMController) ListGroups(ctx *gin.Context) {
+	orgID := middleware.GetSCIMOrgId(ctx)
+
+	// Parse filter and pagination
+	filter, err := scim.ParseFilter(ctx.Query("filter"))
+	if err != nil {
+		c.respondError(ctx, http.StatusBadRequest, scim.ErrorTypeInvalidFilter, err.Error())
+		return
+	}
+	startIndex, count := scim.Pagination(ctx.Query("startIndex"), ctx.Query("count"))
+
+	// Get groups
+	teams, total, err := c.scimService.ListGroups(ctx, orgID, filter, startIndex, count)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to list SCIM groups")
+		c.handleError(ctx, err)
+		return
+	}
+
+	// Convert to response
+	resources := make([]models.SCIMGroup, len(teams))
+	for i, team := range teams {
+		resources[i] = team.ToSCIM(services.SCIMBasePath)
+	}
+
+	// Return response
+	c.respond(ctx, http.StatusOK, scim.NewListResponse(resources, total, startIndex, len(resources)))
+}
+
+// GetGroup gets a SCIM group
+func (c *SCIMController) GetGroup(ctx *gin.Context) {
+	orgID := middleware.GetSCIMOrgId(ctx)
+
+	// Get group
+	team, err := c.scimService.GetGroup(ctx, orgID, ctx.Param("id"))
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	// Return response
+	c.respond(ctx, http.StatusOK, team.ToSCIM(services.SCIMBasePath))
+}
+
+// CreateGroup provisions a SCIM group
+func (c *SCIMController) CreateGroup(ctx *gin.Context) {
+	orgID := middleware.GetSCIMOrgId(ctx)
+
+	// Parse request
+	var req models.SCIMGroup
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	// Create group
+	team, err := c.scimService.CreateGroup(ctx, orgID, req)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("displayName", req.DisplayName).Msg("Failed to create SCIM group")
+		c.handleError(ctx, err)
+		return
+	}
+
+	// Return response
+	c.respond(ctx, http.StatusCreated, team.ToSCIM(services.SCIMBasePath))
+}
+
+// ReplaceGroup replaces a SCIM group
+func (c *SCIMController) ReplaceGroup(ctx *gin.Context) {
+	orgID := middleware.GetSCIMOrgId(ctx)
+
+	// Parse request
+	var req models.SCIMGroup
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	// Replace group
+	team, err := c.scimService.ReplaceGroup(ctx, orgID, ctx.Param("id"), req)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("id", ctx.Param("id")).Msg("Failed to replace SCIM group")
+		c.handleError(ctx, err)
+		return
+	}
+
+	// Return response
+	c.respond(ctx, http.StatusOK, team.ToSCIM(services.SCIMBasePath))
+}
+
+// PatchGroup patches a SCIM group
+func (c *SCIMController) PatchGroup(ctx *gin.Context) {
+	orgID := middleware.GetSCIMOrgId(ctx)
+
+	// Parse request
+	var req scim.PatchRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	// Patch group
+	team, err := c.scimService.PatchGroup(ctx, orgID, ctx.Param("id"), req)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("id", ctx.Param("id")).Msg("Failed to patch SCIM group")
+		c.handleError(ctx, err)
+		return
+	}
+
+	// Return response
+	c.respond(ctx, http.StatusOK, team.ToSCIM(services.SCIMBasePath))
+}
+
+// DeleteGroup deletes a SCIM group
+func (c *SCIMController) DeleteGroup(ctx *gin.Context) {
+	orgID := middleware.GetSCIMOrgId(ctx)
+
+	// Delete group
+	err := c.scimService.DeleteGroup(ctx, orgID, ctx.Param("id"))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("id", ctx.Param("id")).Msg("Failed to delete SCIM group")
+		c.handleError(ctx, err)
+		return
+	}
+
+	// Return response
+	ctx.Status(http.StatusNoContent)
+}
+
+// bind parses and validates a SCIM request body, writing a SCIM error on failure
+func (c *SCIMController) bind(ctx *gin.Context, req interface{}) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		c.respondError(ctx, http.StatusBadRequest, scim.ErrorTypeInvalidSyntax, "Invalid request body")
+		return false
+	}
+
+	if err := c.validator.Struct(req); err != nil {
+		c.respondError(ctx, http.StatusBadRequest, scim.ErrorTypeInvalidValue, err.Error())
+		return false
+	}
+
+	return true
+}
+
+// handleError maps service errors to SCIM error responses
+func (c *SCIMController) handleError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrSCIMNotFound):
+		c.respondError(ctx, http.StatusNotFound, "", "Resource not found")
+	case errors.Is(err, services.ErrSCIMConflict):
+		c.respondError(ctx, http.StatusConflict, scim.ErrorTypeUniqueness, "Resource already exists")
+	case errors.Is(err, services.ErrSCIMUserNotLinkable):
+		c.respondError(ctx, http.StatusConflict, scim.ErrorTypeUniqueness,
+			"A user with this email already exists outside the organization's verified domain")
+	case errors.Is(err, services.ErrSCIMInvalidValue):
+		c.respondError(ctx, http.StatusBadRequest, scim.ErrorTypeInvalidValue, err.Error())
+	case errors.Is(err, services.ErrSCIMUnsupportedFilter):
+		c.respondError(ctx, http.StatusBadRequest, scim.ErrorTypeInvalidFilter, err.Error())
+	default:
+		// Domain errors, such as a join policy refusing a user, keep their status
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) && appErr.Kind != apperrors.KindInternal {
+			c.respondError(ctx, appErr.Status(), "", appErr.Message)
+			return
+		}
+		c.respondError(ctx, http.StatusInternalServerError, "", "Internal server error")
+	}
+}
+
+// respond writes a SCIM response body
+func (c *SCIMController) respond(ctx *gin.Context, status int, body interface{}) {
+	ctx.Header("Content-Type", scim.ContentType)
+	ctx.JSON(status, body)
+}
+
+// respondError writes a SCIM error response body
+func (c *SCIMController) respondError(ctx *gin.Context, status int, scimType, detail string) {
+	c.respond(ctx, status, scim.NewError(status, scimType, detail))
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/api/validators"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// SignupReviewController handles the signup review queue
+type SignupReviewController struct {
+	reviewService *services.SignupReviewService
+	validator     *validator.Validate
+}
+
+// NewSignupReviewController creates a new signup review controller
+func NewSignupReviewController(reviewService *services.SignupReviewService) *SignupReviewController {
+	return &SignupReviewController{
+		reviewService: reviewService,
+		validator:     validators.New(),
+	}
+}
+
+// GetQueue lists the signups pending review
+func (c *SignupReviewController) GetQueue(ctx *gin.Context) {
+	// Parse pagination parameters
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Get queue
+	users, total, err := c.reviewService.GetQueue(ctx, page, limit)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Int("page", page).Int("limit", limit).Msg("Failed to get signup review queue")
+		ctx.Error(apperrors.From(err, "Failed to get signup review queue"))
+		return
+	}
+
+	// Convert to response
+	reviews := make([]models.SignupReviewResponse, len(users))
+	for i, user := range users {
+		reviews[i] = user.ToSignupReviewResponse()
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"reviews":    reviews,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+		"totalPages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// ApproveSignup activates a user held for review
+func (c *SignupReviewController) ApproveSignup(ctx *gin.Context) {
+	c.review(ctx, c.reviewService.Approve, "approve")
+}
+
+// RejectSignup deactivates a user held for review
+func (c *SignupReviewController) RejectSignup(ctx *gin.Context) {
+	c.review(ctx, c.reviewService.Reject, "reject")
+}
+
+// review handles a signup review decision
+func (c *SignupReviewController) review(
+	ctx *gin.Context,
+	decide func(ctx context.Context, userID string, req models.ReviewSignupRequest, reviewedBy string) (*models.User, error),
+	action string,
+) {
+	id := ctx.Param("userId")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("user ID"))
+		return
+	}
+
+	// Get user ID from context
+	reviewerID := middleware.GetUserId(ctx)
+	if reviewerID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request; the body is optional
+	var req models.ReviewSignupRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.Error(apperrors.InvalidBody(err))
+			return
+		}
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+
+	// Record decision
+	user, err := decide(ctx, id, req, reviewerID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", id).Msgf("Failed to %s signup", action)
+		ctx.Error(apperrors.From(err, "Failed to "+action+" signup"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, user.ToSignupReviewResponse())
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// StatsController handles usage statistics requests
+type StatsController struct {
+	statsService *services.StatsService
+}
+
+// NewStatsController creates a new stats controller
+func NewStatsController(statsService *services.StatsService) *StatsController {
+	return &StatsController{
+		statsService: statsService,
+	}
+}
+
+// GetOrganizationStats gets an organization's usage statistics. The interval,
+// from and to query parameters select the member growth series.
+func (c *StatsController) GetOrganizationStats(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse series parameters
+	query, err := services.ParseStatsQuery(ctx.Query("interval"), ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Get stats
+	stats, err := c.statsService.GetOrganizationStats(ctx, id, query, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get organization stats")
+		ctx.Error(apperrors.From(err, "Failed to get organization stats"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// GetUserStats gets the number of users of the platform by status and role
+func (c *StatsController) GetUserStats(ctx *gin.Context) {
+	stats, err := c.statsService.GetUserStats(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Failed to get user stats")
+		ctx.Error(apperrors.From(err, "Failed to get user stats"))
+		return
+	}
+
+	respondStats(ctx, "user-stats", stats)
+}
+
+// GetOrganizationSizeStats gets the number of organizations of the platform
+// by member count
+func (c *StatsController) GetOrganizationSizeStats(ctx *gin.Context) {
+	stats, err := c.statsService.GetOrganizationSizeStats(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Failed to get organization stats")
+		ctx.Error(apperrors.From(err, "Failed to get organization stats"))
+		return
+	}
+
+	respondStats(ctx, "organization-stats", stats)
+}
+
+// GetSignupStats gets the number of signups per interval. The interval, from
+// and to query parameters select the series.
+func (c *StatsController) GetSignupStats(ctx *gin.Context) {
+	query, err := services.ParseStatsQuery(ctx.Query("interval"), ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	series, err := c.statsService.GetSignupSeries(ctx, query)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Failed to get signup stats")
+		ctx.Error(apperrors.From(err, "Failed to get signup stats"))
+		return
+	}
+
+	respondStats(ctx, "signup-stats", series)
+}
+
+// GetEventStats gets the number of published events per interval and type.
+// The interval, from and to query parameters select the series.
+func (c *StatsController) GetEventStats(ctx *gin.Context) {
+	query, err := services.ParseStatsQuery(ctx.Query("interval"), ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	series, err := c.statsService.GetEventSeries(ctx, query)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Failed to get event stats")
+		ctx.Error(apperrors.From(err, "Failed to get event stats"))
+		return
+	}
+
+	respondStats(ctx, "event-stats", series)
+}
+
+// csvExporter is implemented by statistics that can be exported as CSV
+type csvExporter interface {
+	CSV() [][]string
+}
+
+// respondStats returns statistics as JSON, or as a CSV download named name
+// when the format query parameter is csv
+func respondStats(ctx *gin.Context, name string, stats csvExporter) {
+	switch ctx.DefaultQuery("format", "json") {
+	case "json":
+		ctx.JSON(http.StatusOK, stats)
+	case "csv":
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+		ctx.Status(http.StatusOK)
+
+		if err := csv.NewWriter(ctx.Writer).WriteAll(stats.CSV()); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("export", name).Msg("Failed to write stats CSV")
+		}
+	default:
+		ctx.Error(apperrors.InvalidField("format", "format must be json or csv"))
+	}
+}
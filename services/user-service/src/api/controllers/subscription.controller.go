@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// SubscriptionController handles organization subscription requests
+type SubscriptionController struct {
+	subscriptionService *services.SubscriptionService
+}
+
+// NewSubscriptionController creates a new subscription controller
+func NewSubscriptionController(subscriptionService *services.SubscriptionService) *SubscriptionController {
+	return &SubscriptionController{
+		subscriptionService: subscriptionService,
+	}
+}
+
+// GetSubscription gets an organization's subscription and its seat usage
+func (c *SubscriptionController) GetSubscription(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get subscription
+	subscription, err := c.subscriptionService.GetSubscription(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get organization subscription")
+		ctx.Error(apperrors.From(err, "Failed to get organization subscription"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, subscription)
+}
+
+// AssignSeat assigns a presenter seat to a member of an organization
+func (c *SubscriptionController) AssignSeat(ctx *gin.Context) {
+	c.changeSeat(ctx, true)
+}
+
+// UnassignSeat frees the presenter seat of a member of an organization
+func (c *SubscriptionController) UnassignSeat(ctx *gin.Context) {
+	c.changeSeat(ctx, false)
+}
+
+// changeSeat assigns or frees the presenter seat of a member
+func (c *SubscriptionController) changeSeat(ctx *gin.Context, assign bool) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	memberID := ctx.Param("memberId")
+	if memberID == "" {
+		ctx.Error(apperrors.MissingParameter("member ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	if assign {
+		if err := c.subscriptionService.AssignSeat(ctx, id, memberID, userID); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("memberId", memberID).Msg("Failed to assign seat")
+			ctx.Error(apperrors.From(err, "Failed to assign seat"))
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{"message": "Seat assigned successfully"})
+		return
+	}
+
+	if err := c.subscriptionService.UnassignSeat(ctx, id, memberID, userID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("memberId", memberID).Msg("Failed to unassign seat")
+		ctx.Error(apperrors.From(err, "Failed to unassign seat"))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Seat unassigned successfully"})
+}
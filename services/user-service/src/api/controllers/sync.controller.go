@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// SyncController handles differential sync
+type SyncController struct {
+	syncService *services.SyncService
+}
+
+// NewSyncController creates a new sync controller
+func NewSyncController(syncService *services.SyncService) *SyncController {
+	return &SyncController{
+		syncService: syncService,
+	}
+}
+
+// Sync gets the users, teams and organizations the caller can see that
+// changed since a timestamp or cursor
+func (c *SyncController) Sync(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get changes
+	response, err := c.syncService.Sync(ctx, userID, ctx.Query("since"))
+	if err != nil {
+		// An expired cursor is reported as 410 Gone; deletions older than it
+		// may be gone, so the client must start over
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to sync")
+		ctx.Error(apperrors.From(err, "Failed to sync"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, response)
+}
@@ -5,24 +5,23 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
-	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/api/httpx"
 	"github.com/your-username/slido-clone/user-service/api/middleware"
 	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
 	"github.com/your-username/slido-clone/user-service/services"
 )
 
 // TeamController handles team-related requests
 type TeamController struct {
 	teamService *services.TeamService
-	validator   *validator.Validate
 }
 
 // NewTeamController creates a new team controller
 func NewTeamController(teamService *services.TeamService) *TeamController {
 	return &TeamController{
 		teamService: teamService,
-		validator:   validator.New(),
 	}
 }
 
@@ -30,21 +29,34 @@ func NewTeamController(teamService *services.TeamService) *TeamController {
 func (c *TeamController) GetTeam(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing team ID"})
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse fieldset
+	fields, ok := httpx.Fields(ctx, models.TeamResponseFields, models.TeamExpansions...)
+	if !ok {
 		return
 	}
 
 	// Get team
-	team, err := c.teamService.GetTeamByID(ctx, id)
+	team, viewer, err := c.teamService.GetVisibleTeam(ctx, id, userID)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to get team")
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get team")
+		ctx.Error(apperrors.From(err, "Failed to get team"))
 		return
 	}
 
 	// Return response
-	includeMembers := ctx.Query("includeMembers") == "true"
-	ctx.JSON(http.StatusOK, team.ToResponse(includeMembers))
+	includeMembers := ctx.Query("includeMembers") == "true" || fields.Includes("members")
+	ctx.JSON(http.StatusOK, httpx.Sparse(team.ToResponseFor(viewer, includeMembers), fields))
 }
 
 // CreateTeam creates a new team
@@ -52,29 +64,21 @@ func (c *TeamController) CreateTeam(ctx *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
-	// Parse request
-	var req models.CreateTeamRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
-		return
-	}
-
-	// Validate request
-	if err := c.validator.Struct(req); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": validationErrors.Error()})
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.CreateTeamRequest](ctx)
+	if !ok {
 		return
 	}
 
 	// Create team
 	team, err := c.teamService.CreateTeam(ctx, req, userID)
 	if err != nil {
-		log.Error().Err(err).Interface("req", req).Msg("Failed to create team")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create team", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Interface("req", req).Msg("Failed to create team")
+		ctx.Error(apperrors.From(err, "Failed to create team"))
 		return
 	}
 
@@ -86,36 +90,102 @@ func (c *TeamController) CreateTeam(ctx *gin.Context) {
 func (c *TeamController) UpdateTeam(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing team ID"})
+		ctx.Error(apperrors.MissingParameter("team ID"))
 		return
 	}
 
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
-	// Parse request
-	var req models.UpdateTeamRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateTeamRequest](ctx)
+	if !ok {
 		return
 	}
 
-	// Validate request
-	if err := c.validator.Struct(req); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": validationErrors.Error()})
+	// Update team
+	team, err := c.teamService.UpdateTeam(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to update team")
+		ctx.Error(apperrors.From(err, "Failed to update team"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, team.ToResponse(true))
+}
+
+// UpdateTeamSettings updates the settings of a team
+func (c *TeamController) UpdateTeamSettings(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateTeamSettingsRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Update settings
+	team, err := c.teamService.UpdateTeamSettings(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to update team settings")
+		ctx.Error(apperrors.From(err, "Failed to update team settings"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, team.ToResponse(true))
+}
+
+// PatchTeam updates a team with a JSON merge patch
+func (c *TeamController) PatchTeam(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get team to merge the patch into
+	team, err := c.teamService.GetTeamByID(ctx, id)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get team for patch")
+		ctx.Error(apperrors.From(err, "Failed to get team"))
+		return
+	}
+
+	// Parse and validate patch
+	req, ok := httpx.BindMergePatch[models.UpdateTeamRequest](ctx, team.ToResponse(false), models.TeamClearableFields)
+	if !ok {
 		return
 	}
 
 	// Update team
-	team, err := c.teamService.UpdateTeam(ctx, id, req, userID)
+	team, err = c.teamService.UpdateTeam(ctx, id, req, userID)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to update team")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to patch team")
+		ctx.Error(apperrors.From(err, "Failed to update team"))
 		return
 	}
 
@@ -127,22 +197,22 @@ func (c *TeamController) UpdateTeam(ctx *gin.Context) {
 func (c *TeamController) DeleteTeam(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing team ID"})
+		ctx.Error(apperrors.MissingParameter("team ID"))
 		return
 	}
 
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
 	// Delete team
 	err := c.teamService.DeleteTeam(ctx, id, userID)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to delete team")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete team", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to delete team")
+		ctx.Error(apperrors.From(err, "Failed to delete team"))
 		return
 	}
 
@@ -150,19 +220,80 @@ func (c *TeamController) DeleteTeam(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "Team deleted successfully"})
 }
 
+// ArchiveTeam archives a team
+func (c *TeamController) ArchiveTeam(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Archive team
+	team, err := c.teamService.ArchiveTeam(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to archive team")
+		ctx.Error(apperrors.From(err, "Failed to archive team"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, team.ToResponse(false))
+}
+
+// UnarchiveTeam brings an archived team back
+func (c *TeamController) UnarchiveTeam(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Unarchive team
+	team, err := c.teamService.UnarchiveTeam(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to unarchive team")
+		ctx.Error(apperrors.From(err, "Failed to unarchive team"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, team.ToResponse(false))
+}
+
 // GetTeamMembers gets team members
 func (c *TeamController) GetTeamMembers(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing team ID"})
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
 	// Get team
-	team, err := c.teamService.GetTeamByID(ctx, id)
+	team, err := c.teamService.GetTeamMembers(ctx, id, userID)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to get team for members")
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get team for members")
+		ctx.Error(apperrors.From(err, "Failed to get team"))
 		return
 	}
 
@@ -179,36 +310,28 @@ func (c *TeamController) GetTeamMembers(ctx *gin.Context) {
 func (c *TeamController) AddTeamMember(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing team ID"})
+		ctx.Error(apperrors.MissingParameter("team ID"))
 		return
 	}
 
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	// Parse request
-	var req models.AddTeamMemberRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
-	// Validate request
-	if err := c.validator.Struct(req); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": validationErrors.Error()})
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.AddTeamMemberRequest](ctx)
+	if !ok {
 		return
 	}
 
 	// Add member
 	err := c.teamService.AddTeamMember(ctx, id, req, userID)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to add team member")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add team member", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to add team member")
+		ctx.Error(apperrors.From(err, "Failed to add team member"))
 		return
 	}
 
@@ -216,46 +339,105 @@ func (c *TeamController) AddTeamMember(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "Team member added successfully"})
 }
 
+// AddTeamGroup adds the members of an organization group to a team
+func (c *TeamController) AddTeamGroup(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.AddTeamGroupRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Add group members
+	result, err := c.teamService.AddTeamGroup(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to add group to team")
+		ctx.Error(apperrors.From(err, "Failed to add group to team"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, result)
+}
+
+// BulkTeamMembers runs a batch of member operations on a team
+func (c *TeamController) BulkTeamMembers(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.BulkTeamMembersRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Run operations
+	result, err := c.teamService.BulkTeamMembers(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Int("operations", len(req.Operations)).
+			Msg("Failed to run bulk team member operations")
+		ctx.Error(apperrors.From(err, "Failed to run bulk team member operations"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, result)
+}
+
 // UpdateTeamMember updates a team member
 func (c *TeamController) UpdateTeamMember(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing team ID"})
+		ctx.Error(apperrors.MissingParameter("team ID"))
 		return
 	}
 
 	memberID := ctx.Param("memberId")
 	if memberID == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing member ID"})
+		ctx.Error(apperrors.MissingParameter("member ID"))
 		return
 	}
 
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	// Parse request
-	var req models.UpdateTeamMemberRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
-	// Validate request
-	if err := c.validator.Struct(req); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": validationErrors.Error()})
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateTeamMemberRequest](ctx)
+	if !ok {
 		return
 	}
 
 	// Update member
 	err := c.teamService.UpdateTeamMember(ctx, id, memberID, req, userID)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Str("memberId", memberID).Interface("req", req).Msg("Failed to update team member")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team member", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("memberId", memberID).Interface("req", req).Msg("Failed to update team member")
+		ctx.Error(apperrors.From(err, "Failed to update team member"))
 		return
 	}
 
@@ -267,28 +449,28 @@ func (c *TeamController) UpdateTeamMember(ctx *gin.Context) {
 func (c *TeamController) RemoveTeamMember(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing team ID"})
+		ctx.Error(apperrors.MissingParameter("team ID"))
 		return
 	}
 
 	memberID := ctx.Param("memberId")
 	if memberID == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing member ID"})
+		ctx.Error(apperrors.MissingParameter("member ID"))
 		return
 	}
 
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
 	// Remove member
 	err := c.teamService.RemoveTeamMember(ctx, id, memberID, userID)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Str("memberId", memberID).Msg("Failed to remove team member")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove team member", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("memberId", memberID).Msg("Failed to remove team member")
+		ctx.Error(apperrors.From(err, "Failed to remove team member"))
 		return
 	}
 
@@ -301,7 +483,7 @@ func (c *TeamController) GetUserTeams(ctx *gin.Context) {
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
@@ -319,24 +501,38 @@ func (c *TeamController) GetUserTeams(ctx *gin.Context) {
 		limit = 20
 	}
 
+	// Parse tag filter
+	tags, err := models.ParseTagFilter(ctx.Query("tags"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Parse fieldset
+	fields, ok := httpx.Fields(ctx, models.TeamResponseFields, models.TeamExpansions...)
+	if !ok {
+		return
+	}
+	includeArchived := ctx.Query("includeArchived") == "true"
+
 	// Get teams
-	teams, total, err := c.teamService.GetTeamsByUser(ctx, userID, page, limit)
+	teams, total, err := c.teamService.GetTeamsByUser(ctx, userID, tags, page, limit, fields, includeArchived)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Int("page", page).Int("limit", limit).
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Int("page", page).Int("limit", limit).
 			Msg("Failed to get user teams")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user teams", "message": err.Error()})
+		ctx.Error(apperrors.From(err, "Failed to get user teams"))
 		return
 	}
 
 	// Convert to response
 	teamResponses := make([]models.TeamResponse, len(teams))
 	for i, team := range teams {
-		teamResponses[i] = team.ToResponse(false)
+		teamResponses[i] = team.ToResponse(fields.Includes("members"))
 	}
 
 	// Return response
 	ctx.JSON(http.StatusOK, gin.H{
-		"teams":      teamResponses,
+		"teams":      httpx.SparseList(teamResponses, fields),
 		"total":      total,
 		"page":       page,
 		"limit":      limit,
@@ -348,14 +544,14 @@ func (c *TeamController) GetUserTeams(ctx *gin.Context) {
 func (c *TeamController) GetOrganizationTeams(ctx *gin.Context) {
 	orgID := ctx.Param("orgId")
 	if orgID == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing organization ID"})
+		ctx.Error(apperrors.MissingParameter("organization ID"))
 		return
 	}
 
 	// Get user ID from context
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
@@ -373,12 +569,89 @@ func (c *TeamController) GetOrganizationTeams(ctx *gin.Context) {
 		limit = 20
 	}
 
+	// Parse tag filter
+	tags, err := models.ParseTagFilter(ctx.Query("tags"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Parse fieldset
+	fields, ok := httpx.Fields(ctx, models.TeamResponseFields, models.TeamExpansions...)
+	if !ok {
+		return
+	}
+	includeArchived := ctx.Query("includeArchived") == "true"
+
+	// Get viewer
+	viewer, err := c.teamService.GetTeamViewer(ctx, orgID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to get team viewer")
+		ctx.Error(apperrors.From(err, "Failed to get organization teams"))
+		return
+	}
+
 	// Get teams
-	teams, total, err := c.teamService.GetTeamsByOrganization(ctx, orgID, page, limit)
+	teams, total, err := c.teamService.GetTeamsByOrganization(ctx, orgID, tags, page, limit, fields, viewer, includeArchived)
 	if err != nil {
-		log.Error().Err(err).Str("orgId", orgID).Int("page", page).Int("limit", limit).
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Int("page", page).Int("limit", limit).
 			Msg("Failed to get organization teams")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization teams", "message": err.Error()})
+		ctx.Error(apperrors.From(err, "Failed to get organization teams"))
+		return
+	}
+
+	// Convert to response
+	teamResponses := make([]models.TeamResponse, len(teams))
+	for i, team := range teams {
+		teamResponses[i] = team.ToResponseFor(viewer, fields.Includes("members"))
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"teams":      httpx.SparseList(teamResponses, fields),
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+		"totalPages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// GetTeamChildren gets the teams directly under a team
+func (c *TeamController) GetTeamChildren(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse pagination parameters
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+	includeArchived := ctx.Query("includeArchived") == "true"
+
+	// Get child teams
+	teams, total, err := c.teamService.GetChildTeams(ctx, id, page, limit, includeArchived, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Int("page", page).Int("limit", limit).
+			Msg("Failed to get child teams")
+		ctx.Error(apperrors.From(err, "Failed to get child teams"))
 		return
 	}
 
@@ -397,3 +670,373 @@ func (c *TeamController) GetOrganizationTeams(ctx *gin.Context) {
 		"totalPages": (total + int64(limit) - 1) / int64(limit),
 	})
 }
+
+// MoveTeam moves a team under another team or to the top level
+func (c *TeamController) MoveTeam(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.MoveTeamRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Move team
+	team, err := c.teamService.MoveTeam(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to move team")
+		ctx.Error(apperrors.From(err, "Failed to move team"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, team.ToResponse(false))
+}
+
+// AddTeamTags adds tags to a team
+func (c *TeamController) AddTeamTags(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.AddTagsRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Add tags
+	team, err := c.teamService.AddTeamTags(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to add team tags")
+		ctx.Error(apperrors.From(err, "Failed to add team tags"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, team.ToResponse(false))
+}
+
+// RemoveTeamTag removes a tag from a team
+func (c *TeamController) RemoveTeamTag(ctx *gin.Context) {
+	id := ctx.Param("id")
+	tag := ctx.Param("tag")
+	if id == "" || tag == "" {
+		ctx.Error(apperrors.MissingParameter("team ID or tag"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Remove tag
+	err := c.teamService.RemoveTeamTag(ctx, id, tag, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("tag", tag).Msg("Failed to remove team tag")
+		ctx.Error(apperrors.From(err, "Failed to remove team tag"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Team tag removed successfully"})
+}
+
+// TransferOwnership starts a team ownership transfer
+func (c *TeamController) TransferOwnership(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.TransferOwnershipRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Start transfer
+	transfer, err := c.teamService.TransferOwnership(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to transfer team ownership")
+		ctx.Error(apperrors.From(err, "Failed to transfer team ownership"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message":  "Ownership transfer pending acceptance by the new owner",
+		"transfer": transfer,
+	})
+}
+
+// AcceptOwnershipTransfer accepts a pending team ownership transfer
+func (c *TeamController) AcceptOwnershipTransfer(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Accept transfer
+	err := c.teamService.AcceptOwnershipTransfer(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to accept team ownership transfer")
+		ctx.Error(apperrors.From(err, "Failed to accept team ownership transfer"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Team ownership transferred successfully"})
+}
+
+// CancelOwnershipTransfer cancels or declines a pending team ownership transfer
+func (c *TeamController) CancelOwnershipTransfer(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Cancel transfer
+	err := c.teamService.CancelOwnershipTransfer(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to cancel team ownership transfer")
+		ctx.Error(apperrors.From(err, "Failed to cancel team ownership transfer"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Ownership transfer cancelled successfully"})
+}
+
+// JoinTeam joins a team, or asks to join it if it requires approval
+func (c *TeamController) JoinTeam(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request; the body is optional
+	req, ok := httpx.BindOptionalAndValidate[models.CreateJoinRequestRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Join team
+	resp, err := c.teamService.JoinTeam(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("userId", userID).Msg("Failed to join team")
+		ctx.Error(apperrors.From(err, "Failed to join team"))
+		return
+	}
+
+	// Return response
+	if !resp.Joined {
+		ctx.JSON(http.StatusCreated, resp)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// GetTeamJoinRequests lists the pending join requests of a team
+func (c *TeamController) GetTeamJoinRequests(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse pagination parameters
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Get join requests
+	joinReqs, total, err := c.teamService.GetTeamJoinRequests(ctx, id, page, limit, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", id).Int("page", page).Int("limit", limit).
+			Msg("Failed to get team join requests")
+		ctx.Error(apperrors.From(err, "Failed to get team join requests"))
+		return
+	}
+
+	// Convert to response
+	joinReqResponses := make([]models.TeamJoinRequestResponse, len(joinReqs))
+	for i, joinReq := range joinReqs {
+		joinReqResponses[i] = joinReq.ToResponse(c.teamService.GetTeamJoinRequestUser(ctx, joinReq))
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"joinRequests": joinReqResponses,
+		"total":        total,
+		"page":         page,
+		"limit":        limit,
+		"totalPages":   (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// ApproveTeamJoinRequest approves a pending team join request
+func (c *TeamController) ApproveTeamJoinRequest(ctx *gin.Context) {
+	id := ctx.Param("id")
+	requestID := ctx.Param("requestId")
+	if id == "" || requestID == "" {
+		ctx.Error(apperrors.MissingParameter("team ID or join request ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request; the body is optional
+	req, ok := httpx.BindOptionalAndValidate[models.ApproveTeamJoinRequestRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Approve join request
+	joinReq, err := c.teamService.ApproveTeamJoinRequest(ctx, id, requestID, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("requestId", requestID).Msg("Failed to approve team join request")
+		ctx.Error(apperrors.From(err, "Failed to approve team join request"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, joinReq.ToResponse(nil))
+}
+
+// RejectTeamJoinRequest rejects a pending team join request
+func (c *TeamController) RejectTeamJoinRequest(ctx *gin.Context) {
+	id := ctx.Param("id")
+	requestID := ctx.Param("requestId")
+	if id == "" || requestID == "" {
+		ctx.Error(apperrors.MissingParameter("team ID or join request ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request; the body is optional
+	req, ok := httpx.BindOptionalAndValidate[models.RejectJoinRequestRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Reject join request
+	joinReq, err := c.teamService.RejectTeamJoinRequest(ctx, id, requestID, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("requestId", requestID).Msg("Failed to reject team join request")
+		ctx.Error(apperrors.From(err, "Failed to reject team join request"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, joinReq.ToResponse(nil))
+}
+
+// CancelTeamJoinRequest withdraws the current user's pending team join request
+func (c *TeamController) CancelTeamJoinRequest(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("team ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Cancel join request
+	err := c.teamService.CancelTeamJoinRequest(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("userId", userID).Msg("Failed to cancel team join request")
+		ctx.Error(apperrors.From(err, "Failed to cancel team join request"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Join request cancelled successfully"})
+}
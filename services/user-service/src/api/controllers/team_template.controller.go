@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/httpx"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// TeamTemplateController handles team template requests
+type TeamTemplateController struct {
+	templateService *services.TeamTemplateService
+}
+
+// NewTeamTemplateController creates a new team template controller
+func NewTeamTemplateController(templateService *services.TeamTemplateService) *TeamTemplateController {
+	return &TeamTemplateController{
+		templateService: templateService,
+	}
+}
+
+// CreateTemplate creates a team template in an organization
+func (c *TeamTemplateController) CreateTemplate(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.CreateTeamTemplateRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Create template
+	template, err := c.templateService.CreateTemplate(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to create team template")
+		ctx.Error(apperrors.From(err, "Failed to create team template"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusCreated, template.ToResponse())
+}
+
+// GetTemplates lists the team templates of an organization
+func (c *TeamTemplateController) GetTemplates(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse pagination parameters
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Get templates
+	templates, total, err := c.templateService.GetTemplates(ctx, id, page, limit, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get team templates")
+		ctx.Error(apperrors.From(err, "Failed to get team templates"))
+		return
+	}
+
+	// Convert to response
+	responses := make([]models.TeamTemplateResponse, len(templates))
+	for i, template := range templates {
+		responses[i] = template.ToResponse()
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"templates":  responses,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+		"totalPages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// GetTemplate gets a team template of an organization
+func (c *TeamTemplateController) GetTemplate(ctx *gin.Context) {
+	id := ctx.Param("id")
+	templateID := ctx.Param("templateId")
+	if id == "" || templateID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or template ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get template
+	template, err := c.templateService.GetTemplate(ctx, id, templateID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("templateId", templateID).Msg("Failed to get team template")
+		ctx.Error(apperrors.From(err, "Failed to get team template"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, template.ToResponse())
+}
+
+// UpdateTemplate updates a team template of an organization
+func (c *TeamTemplateController) UpdateTemplate(ctx *gin.Context) {
+	id := ctx.Param("id")
+	templateID := ctx.Param("templateId")
+	if id == "" || templateID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or template ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateTeamTemplateRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Update template
+	template, err := c.templateService.UpdateTemplate(ctx, id, templateID, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("templateId", templateID).Msg("Failed to update team template")
+		ctx.Error(apperrors.From(err, "Failed to update team template"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, template.ToResponse())
+}
+
+// DeleteTemplate deletes a team template of an organization
+func (c *TeamTemplateController) DeleteTemplate(ctx *gin.Context) {
+	id := ctx.Param("id")
+	templateID := ctx.Param("templateId")
+	if id == "" || templateID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or template ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Delete template
+	err := c.templateService.DeleteTemplate(ctx, id, templateID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("templateId", templateID).Msg("Failed to delete team template")
+		ctx.Error(apperrors.From(err, "Failed to delete team template"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Team template deleted successfully"})
+}
+
+// CreateTeamFromTemplate creates a team from a team template of an
+// organization
+func (c *TeamTemplateController) CreateTeamFromTemplate(ctx *gin.Context) {
+	id := ctx.Param("id")
+	templateID := ctx.Param("templateId")
+	if id == "" || templateID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or template ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindOptionalAndValidate[models.CreateTeamFromTemplateRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Create team
+	team, err := c.templateService.CreateTeamFromTemplate(ctx, id, templateID, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("templateId", templateID).Msg("Failed to create team from template")
+		ctx.Error(apperrors.From(err, "Failed to create team from template"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusCreated, team.ToResponse(true))
+}
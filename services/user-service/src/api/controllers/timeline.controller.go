@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// TimelineController handles organization timeline requests
+type TimelineController struct {
+	timelineService *services.TimelineService
+}
+
+// NewTimelineController creates a new timeline controller
+func NewTimelineController(timelineService *services.TimelineService) *TimelineController {
+	return &TimelineController{
+		timelineService: timelineService,
+	}
+}
+
+// GetTimeline gets an organization's activity timeline, newest first. The
+// types query parameter filters by entry type and cursor continues a page.
+func (c *TimelineController) GetTimeline(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse filter and pagination parameters
+	types, err := services.ParseTimelineTypes(ctx.Query("types"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(services.DefaultTimelineLimit)))
+	if err != nil || limit < 1 || limit > services.MaxTimelineLimit {
+		limit = services.DefaultTimelineLimit
+	}
+
+	// Get timeline
+	timeline, err := c.timelineService.GetTimeline(ctx, id, types, ctx.Query("cursor"), limit, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get organization timeline")
+		ctx.Error(apperrors.From(err, "Failed to get organization timeline"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, timeline)
+}
@@ -5,24 +5,23 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
-	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/api/httpx"
 	"github.com/your-username/slido-clone/user-service/api/middleware"
 	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
 	"github.com/your-username/slido-clone/user-service/services"
 )
 
 // UserController handles user-related requests
 type UserController struct {
 	userService *services.UserService
-	validator   *validator.Validate
 }
 
 // NewUserController creates a new user controller
 func NewUserController(userService *services.UserService) *UserController {
 	return &UserController{
 		userService: userService,
-		validator:   validator.New(),
 	}
 }
 
@@ -30,20 +29,40 @@ func NewUserController(userService *services.UserService) *UserController {
 func (c *UserController) GetUser(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing user ID"})
+		ctx.Error(apperrors.MissingParameter("user ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse fieldset
+	fields, ok := httpx.Fields(ctx, models.UserResponseFields)
+	if !ok {
 		return
 	}
 
 	// Get user
 	user, err := c.userService.GetUserByID(ctx, id)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to get user")
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get user")
+		ctx.Error(apperrors.From(err, "Failed to get user"))
+		return
+	}
+
+	// Get viewer, to apply the user's privacy preferences
+	viewer, err := c.userService.GetProfileViewer(ctx, userID, middleware.GetUserRoles(ctx))
+	if err != nil {
+		ctx.Error(apperrors.From(err, "Failed to get user"))
 		return
 	}
 
 	// Return response
-	ctx.JSON(http.StatusOK, user.ToResponse())
+	ctx.JSON(http.StatusOK, httpx.Sparse(user.ToResponseFor(viewer), fields))
 }
 
 // GetCurrentUser gets the current user
@@ -51,15 +70,15 @@ func (c *UserController) GetCurrentUser(ctx *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
 	// Get user
 	user, err := c.userService.GetUserByUserID(ctx, userID)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Failed to get current user")
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get current user")
+		ctx.Error(apperrors.From(err, "Failed to get user"))
 		return
 	}
 
@@ -69,25 +88,17 @@ func (c *UserController) GetCurrentUser(ctx *gin.Context) {
 
 // CreateUser creates a new user
 func (c *UserController) CreateUser(ctx *gin.Context) {
-	// Parse request
-	var req models.CreateUserRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
-		return
-	}
-
-	// Validate request
-	if err := c.validator.Struct(req); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": validationErrors.Error()})
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.CreateUserRequest](ctx)
+	if !ok {
 		return
 	}
 
 	// Create user
 	user, err := c.userService.CreateUser(ctx, req)
 	if err != nil {
-		log.Error().Err(err).Interface("req", req).Msg("Failed to create user")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Interface("req", req).Msg("Failed to create user")
+		ctx.Error(apperrors.From(err, "Failed to create user"))
 		return
 	}
 
@@ -99,34 +110,60 @@ func (c *UserController) CreateUser(ctx *gin.Context) {
 func (c *UserController) UpdateUser(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing user ID"})
+		ctx.Error(apperrors.MissingParameter("user ID"))
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateUserRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Update user
+	user, err := c.userService.UpdateUser(ctx, id, req, "")
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to update user")
+		ctx.Error(apperrors.From(err, "Failed to update user"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, user.ToResponse())
+}
+
+// PatchUser updates a user with a JSON merge patch
+func (c *UserController) PatchUser(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("user ID"))
 		return
 	}
 
-	// Parse request
-	var req models.UpdateUserRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	// Get user to merge the patch into
+	user, err := c.userService.GetUserByID(ctx, id)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get user for patch")
+		ctx.Error(apperrors.From(err, "Failed to get user"))
 		return
 	}
 
-	// Validate request
-	if err := c.validator.Struct(req); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": validationErrors.Error()})
+	// Parse and validate patch
+	req, ok := httpx.BindMergePatch[models.UpdateUserRequest](ctx, user.ToResponse(), models.UserClearableFields)
+	if !ok {
 		return
 	}
 
 	// Update user
-	user, err := c.userService.UpdateUser(ctx, id, req)
+	user, err = c.userService.UpdateUser(ctx, id, req, httpx.IfMatch(ctx))
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to update user")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).Msg("Failed to patch user")
+		ctx.Error(apperrors.From(err, "Failed to update user"))
 		return
 	}
 
 	// Return response
-	ctx.JSON(http.StatusOK, user.ToResponse())
+	httpx.VersionedJSON(ctx, http.StatusOK, user.ETag(), user.ToResponse())
 }
 
 // UpdateCurrentUser updates the current user
@@ -134,37 +171,29 @@ func (c *UserController) UpdateCurrentUser(ctx *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID := middleware.GetUserId(ctx)
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		ctx.Error(apperrors.ErrUnauthorized)
 		return
 	}
 
 	// Get user
 	user, err := c.userService.GetUserByUserID(ctx, userID)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Failed to get current user for update")
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get current user for update")
+		ctx.Error(apperrors.From(err, "Failed to get user"))
 		return
 	}
 
-	// Parse request
-	var req models.UpdateUserRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
-		return
-	}
-
-	// Validate request
-	if err := c.validator.Struct(req); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": validationErrors.Error()})
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.UpdateUserRequest](ctx)
+	if !ok {
 		return
 	}
 
 	// Update user
-	updatedUser, err := c.userService.UpdateUser(ctx, user.ID, req)
+	updatedUser, err := c.userService.UpdateUser(ctx, user.ID, req, "")
 	if err != nil {
-		log.Error().Err(err).Str("id", user.ID).Interface("req", req).Msg("Failed to update current user")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", user.ID).Interface("req", req).Msg("Failed to update current user")
+		ctx.Error(apperrors.From(err, "Failed to update user"))
 		return
 	}
 
@@ -176,15 +205,15 @@ func (c *UserController) UpdateCurrentUser(ctx *gin.Context) {
 func (c *UserController) DeactivateUser(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing user ID"})
+		ctx.Error(apperrors.MissingParameter("user ID"))
 		return
 	}
 
 	// Deactivate user
 	err := c.userService.DeactivateUser(ctx, id)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to deactivate user")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate user", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to deactivate user")
+		ctx.Error(apperrors.From(err, "Failed to deactivate user"))
 		return
 	}
 
@@ -196,15 +225,15 @@ func (c *UserController) DeactivateUser(ctx *gin.Context) {
 func (c *UserController) ActivateUser(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing user ID"})
+		ctx.Error(apperrors.MissingParameter("user ID"))
 		return
 	}
 
 	// Activate user
 	err := c.userService.ActivateUser(ctx, id)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to activate user")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to activate user", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to activate user")
+		ctx.Error(apperrors.From(err, "Failed to activate user"))
 		return
 	}
 
@@ -216,15 +245,15 @@ func (c *UserController) ActivateUser(ctx *gin.Context) {
 func (c *UserController) DeleteUser(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing user ID"})
+		ctx.Error(apperrors.MissingParameter("user ID"))
 		return
 	}
 
 	// Delete user
 	err := c.userService.DeleteUser(ctx, id)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to delete user")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user", "message": err.Error()})
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to delete user")
+		ctx.Error(apperrors.From(err, "Failed to delete user"))
 		return
 	}
 
@@ -232,12 +261,65 @@ func (c *UserController) DeleteUser(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
 
-// ListUsers lists users with pagination and filtering
+// RestoreUser restores a soft deleted user
+func (c *UserController) RestoreUser(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("user ID"))
+		return
+	}
+
+	// Restore user
+	user, err := c.userService.RestoreUser(ctx, id)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to restore user")
+		ctx.Error(apperrors.From(err, "Failed to restore user"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, user.ToResponse())
+}
+
+// PurgeUser removes a soft deleted user for good
+func (c *UserController) PurgeUser(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("user ID"))
+		return
+	}
+
+	// Get admin ID from context
+	adminID := middleware.GetUserId(ctx)
+	if adminID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Purge user
+	err := c.userService.PurgeUser(ctx, id, adminID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to purge user")
+		ctx.Error(apperrors.From(err, "Failed to purge user"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "User purged successfully"})
+}
+
+// ListUsers lists users with pagination, filtering and sorting
 func (c *UserController) ListUsers(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
 	// Parse pagination parameters
 	pageStr := ctx.DefaultQuery("page", "1")
 	limitStr := ctx.DefaultQuery("limit", "20")
-	search := ctx.DefaultQuery("search", "")
 
 	page, err := strconv.Atoi(pageStr)
 	if err != nil || page < 1 {
@@ -249,24 +331,38 @@ func (c *UserController) ListUsers(ctx *gin.Context) {
 		limit = 20
 	}
 
+	// Parse filter and sort parameters
+	filter, err := services.ParseUserListFilter(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
 	// Get users
-	users, total, err := c.userService.GetUsers(ctx, page, limit, search)
+	users, total, err := c.userService.GetUsers(ctx, page, limit, filter)
 	if err != nil {
-		log.Error().Err(err).Int("page", page).Int("limit", limit).Str("search", search).
+		logger.Ctx(ctx).Error().Err(err).Int("page", page).Int("limit", limit).Str("search", filter.Search).
 			Msg("Failed to list users")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users", "message": err.Error()})
+		ctx.Error(apperrors.From(err, "Failed to list users"))
+		return
+	}
+
+	// Get viewer, to apply the users' privacy preferences
+	viewer, err := c.userService.GetProfileViewer(ctx, userID, middleware.GetUserRoles(ctx))
+	if err != nil {
+		ctx.Error(apperrors.From(err, "Failed to list users"))
 		return
 	}
 
 	// Convert to response
 	userResponses := make([]models.UserResponse, len(users))
 	for i, user := range users {
-		userResponses[i] = user.ToResponse()
+		userResponses[i] = user.ToResponseFor(viewer)
 	}
 
 	// Return response
 	ctx.JSON(http.StatusOK, gin.H{
-		"users":      userResponses,
+		"users":      httpx.SparseList(userResponses, filter.Fields),
 		"total":      total,
 		"page":       page,
 		"limit":      limit,
@@ -0,0 +1,103 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"github.com/your-username/slido-clone/user-service/services"
+	"github.com/your-username/slido-clone/user-service/testsupport"
+)
+
+// newTestRouter creates a router writing errors like the service's, with
+// the user ID of requests set to userID
+func newTestRouter(userID string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(), func(c *gin.Context) {
+		c.Set("userId", userID)
+		c.Next()
+	}, middleware.Tenancy(nil))
+	return router
+}
+
+// jsonBody encodes a request body
+func jsonBody(t *testing.T, body interface{}) *bytes.Reader {
+	t.Helper()
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("encoding body: %v", err)
+	}
+	return bytes.NewReader(payload)
+}
+
+// sendJSON sends a request with a JSON body to a router
+func sendJSON(t *testing.T, router http.Handler, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, jsonBody(t, body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// errorCode gets the code of an error response
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding error response %q: %v", rec.Body.String(), err)
+	}
+	return body.Code
+}
+
+func TestCreateDuplicateUserConflicts(t *testing.T) {
+	f := testsupport.New(t)
+	existing := f.SeedUser()
+
+	syncService := services.NewSyncService(f.Users, f.Teams, f.Orgs, repositories.NewTombstoneRepository(f.Store), &config.SyncConfig{})
+	userService := services.NewUserService(f.Users, f.Orgs, f.Teams, nil, syncService, f.Kafka)
+	router := newTestRouter(existing.UserID)
+	router.POST("/api/users", controllers.NewUserController(userService).CreateUser)
+
+	tests := []struct {
+		name     string
+		req      models.CreateUserRequest
+		wantCode string
+	}{
+		{
+			name:     "same user ID",
+			req:      models.CreateUserRequest{UserID: existing.UserID, Email: "other@example.com", FirstName: "Other", LastName: "User", Role: models.RoleUser},
+			wantCode: "USER_ID_TAKEN",
+		},
+		{
+			name:     "same email",
+			req:      models.CreateUserRequest{UserID: "other-user", Email: existing.Email, FirstName: "Other", LastName: "User", Role: models.RoleUser},
+			wantCode: "EMAIL_TAKEN",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := sendJSON(t, router, http.MethodPost, "/api/users", tt.req)
+			if rec.Code != http.StatusConflict {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusConflict, rec.Body.String())
+			}
+			if code := errorCode(t, rec); code != tt.wantCode {
+				t.Errorf("code = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
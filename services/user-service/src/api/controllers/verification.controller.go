@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/httpx"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// VerificationController handles organization verification requests and
+// their review
+type VerificationController struct {
+	verificationService *services.VerificationService
+}
+
+// NewVerificationController creates a new verification controller
+func NewVerificationController(verificationService *services.VerificationService) *VerificationController {
+	return &VerificationController{
+		verificationService: verificationService,
+	}
+}
+
+// RequestVerification requests a verified badge for an organization
+func (c *VerificationController) RequestVerification(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request
+	req, ok := httpx.BindAndValidate[models.CreateVerificationRequest](ctx)
+	if !ok {
+		return
+	}
+
+	verification, err := c.verificationService.RequestVerification(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to request organization verification")
+		ctx.Error(apperrors.From(err, "Failed to request organization verification"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusCreated, verification)
+}
+
+// GetVerification gets the latest verification request of an organization
+func (c *VerificationController) GetVerification(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	verification, err := c.verificationService.GetVerification(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get organization verification")
+		ctx.Error(apperrors.From(err, "Failed to get organization verification"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, verification)
+}
+
+// CheckDomain looks up the TXT record of an organization's pending domain
+// verification request
+func (c *VerificationController) CheckDomain(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	verification, err := c.verificationService.CheckDomain(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to check organization verification domain")
+		ctx.Error(apperrors.From(err, "Failed to check organization verification domain"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, verification)
+}
+
+// CancelVerification withdraws an organization's pending verification request
+func (c *VerificationController) CancelVerification(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	if err := c.verificationService.CancelVerification(ctx, id, userID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to cancel organization verification")
+		ctx.Error(apperrors.From(err, "Failed to cancel organization verification"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Verification request cancelled successfully"})
+}
+
+// GetQueue lists the verification requests with a status, pending by default
+func (c *VerificationController) GetQueue(ctx *gin.Context) {
+	status := models.VerificationStatus(ctx.DefaultQuery("status", string(models.VerificationPending)))
+	switch status {
+	case models.VerificationPending, models.VerificationApproved, models.VerificationRejected,
+		models.VerificationCancelled, models.VerificationRevoked:
+	default:
+		ctx.Error(apperrors.InvalidField("status", "status must be one of pending, approved, rejected, cancelled, revoked"))
+		return
+	}
+
+	// Parse pagination parameters
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	verifications, total, err := c.verificationService.ListVerifications(ctx, status, page, limit)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("status", string(status)).Msg("Failed to get verification requests")
+		ctx.Error(apperrors.From(err, "Failed to get verification requests"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"verifications": verifications,
+		"total":         total,
+		"page":          page,
+		"limit":         limit,
+		"totalPages":    (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// GetRequest gets a verification request for review
+func (c *VerificationController) GetRequest(ctx *gin.Context) {
+	requestID := ctx.Param("requestId")
+	if requestID == "" {
+		ctx.Error(apperrors.MissingParameter("request ID"))
+		return
+	}
+
+	verification, err := c.verificationService.GetVerificationByID(ctx, requestID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("requestId", requestID).Msg("Failed to get verification request")
+		ctx.Error(apperrors.From(err, "Failed to get verification request"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, verification)
+}
+
+// Approve approves a verification request, granting the verified badge
+func (c *VerificationController) Approve(ctx *gin.Context) {
+	c.review(ctx, c.verificationService.Approve, "approve")
+}
+
+// Reject rejects a verification request
+func (c *VerificationController) Reject(ctx *gin.Context) {
+	c.review(ctx, c.verificationService.Reject, "reject")
+}
+
+// Revoke takes back the verified badge an approved request granted
+func (c *VerificationController) Revoke(ctx *gin.Context) {
+	c.review(ctx, c.verificationService.Revoke, "revoke")
+}
+
+// review handles a verification review decision
+func (c *VerificationController) review(
+	ctx *gin.Context,
+	decide func(ctx context.Context, verificationID string, req models.ReviewVerificationRequest, reviewedBy string) (*models.OrganizationVerification, error),
+	action string,
+) {
+	requestID := ctx.Param("requestId")
+	if requestID == "" {
+		ctx.Error(apperrors.MissingParameter("request ID"))
+		return
+	}
+
+	// Get user ID from context
+	reviewerID := middleware.GetUserId(ctx)
+	if reviewerID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse and validate request; the body is optional
+	req, ok := httpx.BindOptionalAndValidate[models.ReviewVerificationRequest](ctx)
+	if !ok {
+		return
+	}
+
+	// Record decision
+	verification, err := decide(ctx, requestID, req, reviewerID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("requestId", requestID).Msgf("Failed to %s verification request", action)
+		ctx.Error(apperrors.From(err, "Failed to "+action+" verification request"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, verification)
+}
@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/api/validators"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// WebhookController handles organization webhook requests
+type WebhookController struct {
+	webhookService *services.WebhookService
+	validator      *validator.Validate
+}
+
+// NewWebhookController creates a new webhook controller
+func NewWebhookController(webhookService *services.WebhookService) *WebhookController {
+	return &WebhookController{
+		webhookService: webhookService,
+		validator:      validators.New(),
+	}
+}
+
+// CreateWebhook registers a webhook for an organization
+func (c *WebhookController) CreateWebhook(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request
+	var req models.CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+
+	// Create webhook
+	wh, err := c.webhookService.CreateWebhook(ctx, id, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to create webhook")
+		ctx.Error(apperrors.From(err, "Failed to create webhook"))
+		return
+	}
+
+	// Return response, including the secret this once
+	ctx.JSON(http.StatusCreated, wh.ToResponse(true))
+}
+
+// GetWebhooks lists the webhooks of an organization
+func (c *WebhookController) GetWebhooks(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get webhooks
+	webhooks, err := c.webhookService.GetWebhooks(ctx, id, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Msg("Failed to get webhooks")
+		ctx.Error(apperrors.From(err, "Failed to get webhooks"))
+		return
+	}
+
+	// Convert to response
+	responses := make([]models.WebhookResponse, len(webhooks))
+	for i, wh := range webhooks {
+		responses[i] = wh.ToResponse(false)
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"webhooks": responses})
+}
+
+// GetWebhook gets a webhook of an organization
+func (c *WebhookController) GetWebhook(ctx *gin.Context) {
+	id := ctx.Param("id")
+	webhookID := ctx.Param("webhookId")
+	if id == "" || webhookID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or webhook ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Get webhook
+	wh, err := c.webhookService.GetWebhook(ctx, id, webhookID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("webhookId", webhookID).Msg("Failed to get webhook")
+		ctx.Error(apperrors.From(err, "Failed to get webhook"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, wh.ToResponse(false))
+}
+
+// UpdateWebhook updates a webhook of an organization
+func (c *WebhookController) UpdateWebhook(ctx *gin.Context) {
+	id := ctx.Param("id")
+	webhookID := ctx.Param("webhookId")
+	if id == "" || webhookID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or webhook ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request
+	var req models.UpdateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return
+	}
+
+	// Validate request
+	if err := c.validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return
+	}
+
+	// Update webhook
+	wh, err := c.webhookService.UpdateWebhook(ctx, id, webhookID, req, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("webhookId", webhookID).Msg("Failed to update webhook")
+		ctx.Error(apperrors.From(err, "Failed to update webhook"))
+		return
+	}
+
+	// Return response, including the secret if it was changed
+	ctx.JSON(http.StatusOK, wh.ToResponse(req.Secret != nil))
+}
+
+// DeleteWebhook deletes a webhook of an organization
+func (c *WebhookController) DeleteWebhook(ctx *gin.Context) {
+	id := ctx.Param("id")
+	webhookID := ctx.Param("webhookId")
+	if id == "" || webhookID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or webhook ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Delete webhook
+	err := c.webhookService.DeleteWebhook(ctx, id, webhookID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("webhookId", webhookID).Msg("Failed to delete webhook")
+		ctx.Error(apperrors.From(err, "Failed to delete webhook"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// GetDeliveries lists the delivery log of a webhook
+func (c *WebhookController) GetDeliveries(ctx *gin.Context) {
+	id := ctx.Param("id")
+	webhookID := ctx.Param("webhookId")
+	if id == "" || webhookID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or webhook ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse pagination parameters
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "20")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Get deliveries
+	deliveries, total, err := c.webhookService.GetDeliveries(ctx, id, webhookID, page, limit, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("webhookId", webhookID).Msg("Failed to get webhook deliveries")
+		ctx.Error(apperrors.From(err, "Failed to get webhook deliveries"))
+		return
+	}
+
+	// Return response
+	ctx.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+		"totalPages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// TestWebhook sends a test event to a webhook
+func (c *WebhookController) TestWebhook(ctx *gin.Context) {
+	id := ctx.Param("id")
+	webhookID := ctx.Param("webhookId")
+	if id == "" || webhookID == "" {
+		ctx.Error(apperrors.MissingParameter("organization ID or webhook ID"))
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Send test delivery
+	delivery, err := c.webhookService.TestWebhook(ctx, id, webhookID, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("webhookId", webhookID).Msg("Failed to test webhook")
+		ctx.Error(apperrors.From(err, "Failed to test webhook"))
+		return
+	}
+
+	// Return the logged delivery; its status reports whether the endpoint accepted it
+	ctx.JSON(http.StatusOK, delivery)
+}
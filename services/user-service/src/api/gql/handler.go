@@ -0,0 +1,120 @@
+// Package gql serves the GraphQL API of the viewer's profile, teams and
+// organizations
+package gql
+
+import (
+	"context"
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// Handler executes GraphQL queries
+type Handler struct {
+	schema   *graphql.Schema
+	userRepo repositories.UserStore
+	orgRepo  repositories.OrgStore
+	teamRepo repositories.TeamStore
+	cfg      *config.GraphQLConfig
+}
+
+// Request is the body of a GraphQL request
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewHandler creates a new GraphQL handler
+func NewHandler(userRepo repositories.UserStore, orgRepo repositories.OrgStore, teamRepo repositories.TeamStore, teamService *services.TeamService, orgService *services.OrganizationService, cfg *config.GraphQLConfig) *Handler {
+	root := &rootResolver{
+		teamService: teamService,
+		orgService:  orgService,
+	}
+
+	return &Handler{
+		schema: graphql.MustParseSchema(schemaSDL, root,
+			graphql.MaxDepth(cfg.MaxDepth),
+			graphql.MaxParallelism(cfg.MaxParallelism),
+		),
+		userRepo: userRepo,
+		orgRepo:  orgRepo,
+		teamRepo: teamRepo,
+		cfg:      cfg,
+	}
+}
+
+// ServeGraphQL executes a GraphQL query for the authenticated user
+func (h *Handler) ServeGraphQL(ctx *gin.Context) {
+	// Get user ID from context
+	userID := middleware.GetUserId(ctx)
+	if userID == "" {
+		ctx.Error(apperrors.ErrUnauthorized)
+		return
+	}
+
+	// Parse request
+	var req Request
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return
+	}
+
+	// Lookups are batched per request
+	reqCtx := withUserID(ctx.Request.Context(), userID)
+	reqCtx = withLoaders(reqCtx, NewLoaders(reqCtx, h.userRepo, h.orgRepo, h.teamRepo, h.cfg))
+
+	// Execute query; errors are part of the GraphQL response
+	resp := h.schema.Exec(reqCtx, req.Query, req.OperationName, req.Variables)
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// resolverError converts a service error to a GraphQL error carrying its
+// code. Errors without a domain error are logged and reported with message.
+func resolverError(err error, message string) error {
+	appErr := apperrors.From(err, message)
+	if appErr.Kind == apperrors.KindInternal {
+		log.Error().Err(err).Msg(message)
+	}
+	return &gqlError{err: appErr}
+}
+
+// gqlError is a domain error as a GraphQL error
+type gqlError struct {
+	err *apperrors.Error
+}
+
+// Error returns the client-safe message of the error
+func (e *gqlError) Error() string {
+	return e.err.Message
+}
+
+// Extensions adds the error code to the GraphQL error
+func (e *gqlError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.err.Code}
+}
+
+// userIDKey is the context key of the authenticated user ID
+type userIDKey struct{}
+
+// withUserID adds the authenticated user ID to a context
+func withUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// userIDFrom gets the authenticated user ID of a request
+func userIDFrom(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	return userID
+}
@@ -0,0 +1,90 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/dataloader"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Loaders batch the repository lookups of one request
+type Loaders struct {
+	// Users loads users by user ID
+	Users *dataloader.Loader[string, *models.User]
+	// Organizations loads organizations by ID
+	Organizations *dataloader.Loader[string, *models.Organization]
+	// OrganizationTeams loads the teams of organizations by organization ID
+	OrganizationTeams *dataloader.Loader[string, []*models.Team]
+}
+
+// NewLoaders creates the loaders of a request
+func NewLoaders(ctx context.Context, userRepo repositories.UserStore, orgRepo repositories.OrgStore, teamRepo repositories.TeamStore, cfg *config.GraphQLConfig) *Loaders {
+	return &Loaders{
+		Users: dataloader.New(ctx, func(ctx context.Context, userIDs []string) (map[string]*models.User, error) {
+			users, err := userRepo.FindBatch(ctx, bson.M{"userId": bson.M{"$in": userIDs}}, int64(len(userIDs)))
+			if err != nil {
+				return nil, err
+			}
+
+			byID := make(map[string]*models.User, len(users))
+			for _, user := range users {
+				byID[user.UserID] = user
+			}
+			return byID, nil
+		}, cfg.BatchWait, cfg.MaxParallelism),
+
+		Organizations: dataloader.New(ctx, func(ctx context.Context, ids []string) (map[string]*models.Organization, error) {
+			// Malformed IDs can't match an organization
+			objIDs := make([]primitive.ObjectID, 0, len(ids))
+			for _, id := range ids {
+				if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+					objIDs = append(objIDs, objID)
+				}
+			}
+
+			orgs, err := orgRepo.FindBatch(ctx, bson.M{"_id": bson.M{"$in": objIDs}}, int64(len(objIDs)))
+			if err != nil {
+				return nil, err
+			}
+
+			byID := make(map[string]*models.Organization, len(orgs))
+			for _, org := range orgs {
+				byID[org.ID] = org
+			}
+			return byID, nil
+		}, cfg.BatchWait, cfg.MaxParallelism),
+
+		OrganizationTeams: dataloader.New(ctx, func(ctx context.Context, orgIDs []string) (map[string][]*models.Team, error) {
+			teams, err := teamRepo.FindBatch(ctx, bson.M{
+				"organizationId": bson.M{"$in": orgIDs},
+				"archivedAt":     bson.M{"$exists": false},
+			}, 0)
+			if err != nil {
+				return nil, err
+			}
+
+			byOrg := make(map[string][]*models.Team, len(orgIDs))
+			for _, team := range teams {
+				byOrg[team.OrganizationID] = append(byOrg[team.OrganizationID], team)
+			}
+			return byOrg, nil
+		}, cfg.BatchWait, cfg.MaxParallelism),
+	}
+}
+
+// loadersKey is the context key of the request's loaders
+type loadersKey struct{}
+
+// withLoaders adds loaders to a context
+func withLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+// loadersFrom gets the loaders of a request
+func loadersFrom(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
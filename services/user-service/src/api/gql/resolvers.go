@@ -0,0 +1,223 @@
+package gql
+
+import (
+	"context"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/services"
+)
+
+// viewerListLimit is the number of teams and organizations listed for the
+// viewer
+const viewerListLimit = 100
+
+// rootResolver resolves the Query type
+type rootResolver struct {
+	teamService *services.TeamService
+	orgService  *services.OrganizationService
+}
+
+// Viewer resolves the authenticated user
+func (r *rootResolver) Viewer(ctx context.Context) (*viewerResolver, error) {
+	userID := userIDFrom(ctx)
+	if userID == "" {
+		return nil, resolverError(apperrors.ErrUnauthorized, "Unauthorized")
+	}
+	return &viewerResolver{root: r, userID: userID}, nil
+}
+
+// viewerResolver resolves the Viewer type
+type viewerResolver struct {
+	root   *rootResolver
+	userID string
+}
+
+// Profile resolves the viewer's user
+func (r *viewerResolver) Profile(ctx context.Context) (*userResolver, error) {
+	user, found, err := loadersFrom(ctx).Users.Load(ctx, r.userID)
+	if err != nil {
+		return nil, resolverError(err, "Failed to get profile")
+	}
+	if !found {
+		return nil, resolverError(services.ErrUserNotFound, "Failed to get profile")
+	}
+	return &userResolver{user: user}, nil
+}
+
+// Teams resolves the viewer's teams
+func (r *viewerResolver) Teams(ctx context.Context) ([]*teamResolver, error) {
+	teams, _, err := r.root.teamService.GetTeamsByUser(ctx, r.userID, nil, 1, viewerListLimit, models.FieldSet{}, false)
+	if err != nil {
+		return nil, resolverError(err, "Failed to get teams")
+	}
+	return toTeamResolvers(teams), nil
+}
+
+// Organizations resolves the viewer's organizations
+func (r *viewerResolver) Organizations(ctx context.Context) ([]*organizationResolver, error) {
+	orgs, _, err := r.root.orgService.GetOrganizationsByUser(ctx, r.userID, nil, 1, viewerListLimit, models.FieldSet{})
+	if err != nil {
+		return nil, resolverError(err, "Failed to get organizations")
+	}
+
+	resolvers := make([]*organizationResolver, 0, len(orgs))
+	for _, org := range orgs {
+		resolvers = append(resolvers, &organizationResolver{org: org})
+	}
+	return resolvers, nil
+}
+
+// userResolver resolves the User type
+type userResolver struct {
+	user *models.User
+}
+
+func (r *userResolver) ID() graphql.ID          { return graphql.ID(r.user.ID) }
+func (r *userResolver) UserID() string          { return r.user.UserID }
+func (r *userResolver) Email() string           { return r.user.Email }
+func (r *userResolver) FirstName() string       { return r.user.FirstName }
+func (r *userResolver) LastName() string        { return r.user.LastName }
+func (r *userResolver) FullName() string        { return r.user.FirstName + " " + r.user.LastName }
+func (r *userResolver) Role() string            { return string(r.user.Role) }
+func (r *userResolver) Status() string          { return string(r.user.Status) }
+func (r *userResolver) ProfilePicture() *string { return optional(r.user.ProfilePicture) }
+func (r *userResolver) Bio() *string            { return optional(r.user.Bio) }
+func (r *userResolver) JobTitle() *string       { return optional(r.user.JobTitle) }
+func (r *userResolver) Company() *string        { return optional(r.user.Company) }
+func (r *userResolver) Location() *string       { return optional(r.user.Location) }
+func (r *userResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.user.CreatedAt} }
+
+// teamResolver resolves the Team type
+type teamResolver struct {
+	team *models.Team
+}
+
+func (r *teamResolver) ID() graphql.ID             { return graphql.ID(r.team.ID) }
+func (r *teamResolver) Name() string               { return r.team.Name }
+func (r *teamResolver) Description() *string       { return optional(r.team.Description) }
+func (r *teamResolver) LogoURL() *string           { return optional(r.team.LogoURL) }
+func (r *teamResolver) OrganizationID() graphql.ID { return graphql.ID(r.team.OrganizationID) }
+func (r *teamResolver) CreatedAt() graphql.Time    { return graphql.Time{Time: r.team.CreatedAt} }
+func (r *teamResolver) MemberCount() int32         { return int32(len(r.team.Members)) }
+func (r *teamResolver) Visibility() string         { return string(r.team.Settings.Resolve().Visibility) }
+
+// Organization resolves the organization of the team
+func (r *teamResolver) Organization(ctx context.Context) (*organizationResolver, error) {
+	org, found, err := loadersFrom(ctx).Organizations.Load(ctx, r.team.OrganizationID)
+	if err != nil {
+		return nil, resolverError(err, "Failed to get organization")
+	}
+	if !found {
+		return nil, nil
+	}
+	return &organizationResolver{org: org}, nil
+}
+
+// Members resolves the members of the team, which are empty for viewers who
+// can't see who is in a private or secret team
+func (r *teamResolver) Members(ctx context.Context) ([]*memberResolver, error) {
+	org, found, err := loadersFrom(ctx).Organizations.Load(ctx, r.team.OrganizationID)
+	if err != nil {
+		return nil, resolverError(err, "Failed to get organization")
+	}
+	if !found {
+		org = nil
+	}
+
+	members := make([]*memberResolver, 0, len(r.team.Members))
+	if !models.NewTeamViewer(userIDFrom(ctx), org).CanSeeMembers(r.team) {
+		return members, nil
+	}
+	for _, member := range r.team.Members {
+		members = append(members, &memberResolver{userID: member.UserID, role: string(member.Role), joinedAt: member.JoinedAt})
+	}
+	return members, nil
+}
+
+// organizationResolver resolves the Organization type
+type organizationResolver struct {
+	org *models.Organization
+}
+
+func (r *organizationResolver) ID() graphql.ID          { return graphql.ID(r.org.ID) }
+func (r *organizationResolver) Name() string            { return r.org.Name }
+func (r *organizationResolver) Description() *string    { return optional(r.org.Description) }
+func (r *organizationResolver) LogoURL() *string        { return optional(r.org.LogoURL) }
+func (r *organizationResolver) Website() *string        { return optional(r.org.Website) }
+func (r *organizationResolver) Industry() *string       { return optional(r.org.Industry) }
+func (r *organizationResolver) Size() *string           { return optional(r.org.Size) }
+func (r *organizationResolver) Location() *string       { return optional(r.org.Location) }
+func (r *organizationResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.org.CreatedAt} }
+func (r *organizationResolver) MemberCount() int32      { return int32(len(r.org.Members)) }
+func (r *organizationResolver) TeamCount() int32        { return int32(len(r.org.TeamIDs)) }
+func (r *organizationResolver) Verified() bool          { return r.org.Verified }
+
+// Members resolves the members of the organization
+func (r *organizationResolver) Members() []*memberResolver {
+	members := make([]*memberResolver, 0, len(r.org.Members))
+	for _, member := range r.org.Members {
+		members = append(members, &memberResolver{userID: member.UserID, role: string(member.Role), joinedAt: member.JoinedAt})
+	}
+	return members
+}
+
+// Teams resolves the teams of the organization
+func (r *organizationResolver) Teams(ctx context.Context) ([]*teamResolver, error) {
+	teams, _, err := loadersFrom(ctx).OrganizationTeams.Load(ctx, r.org.ID)
+	if err != nil {
+		return nil, resolverError(err, "Failed to get teams")
+	}
+
+	// Leave out the secret teams the viewer can't see
+	viewer := models.NewTeamViewer(userIDFrom(ctx), r.org)
+	visible := make([]*models.Team, 0, len(teams))
+	for _, team := range teams {
+		if viewer.CanSee(team) {
+			visible = append(visible, team)
+		}
+	}
+	return toTeamResolvers(visible), nil
+}
+
+// memberResolver resolves the TeamMember and OrganizationMember types
+type memberResolver struct {
+	userID   string
+	role     string
+	joinedAt time.Time
+}
+
+func (r *memberResolver) UserID() string         { return r.userID }
+func (r *memberResolver) Role() string           { return r.role }
+func (r *memberResolver) JoinedAt() graphql.Time { return graphql.Time{Time: r.joinedAt} }
+
+// User resolves the member's user
+func (r *memberResolver) User(ctx context.Context) (*userResolver, error) {
+	user, found, err := loadersFrom(ctx).Users.Load(ctx, r.userID)
+	if err != nil {
+		return nil, resolverError(err, "Failed to get user")
+	}
+	if !found {
+		return nil, nil
+	}
+	return &userResolver{user: user}, nil
+}
+
+// toTeamResolvers wraps teams in resolvers
+func toTeamResolvers(teams []*models.Team) []*teamResolver {
+	resolvers := make([]*teamResolver, 0, len(teams))
+	for _, team := range teams {
+		resolvers = append(resolvers, &teamResolver{team: team})
+	}
+	return resolvers
+}
+
+// optional converts an empty string to null
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
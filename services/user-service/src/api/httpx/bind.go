@@ -0,0 +1,45 @@
+// Package httpx holds the request handling shared by the controllers
+package httpx
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/validators"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+)
+
+// Validator is the validator shared by the controllers. It names fields by
+// their JSON names.
+var Validator = validators.New()
+
+// BindAndValidate parses a request's JSON body into a T and validates it.
+// Failures are reported on the context, and the handler should return when
+// ok is false.
+func BindAndValidate[T any](ctx *gin.Context) (req T, ok bool) {
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return req, false
+	}
+	return req, Validate(ctx, req)
+}
+
+// BindOptionalAndValidate is like BindAndValidate for optional bodies; a
+// request without a body validates the zero T.
+func BindOptionalAndValidate[T any](ctx *gin.Context) (req T, ok bool) {
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.Error(apperrors.InvalidBody(err))
+			return req, false
+		}
+	}
+	return req, Validate(ctx, req)
+}
+
+// Validate validates a request. Failures are reported on the context, listing
+// the fields that failed.
+func Validate(ctx *gin.Context, req interface{}) bool {
+	if err := Validator.Struct(req); err != nil {
+		ctx.Error(apperrors.FromValidator(err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+)
+
+// VersionedJSON responds with a representation of a resource version,
+// tagged with the version's entity tag and a hash of the representation.
+// Reads whose If-None-Match lists the tag get 304 Not Modified with no body,
+// as the client already has the current representation.
+func VersionedJSON(ctx *gin.Context, status int, etag string, response interface{}) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		ctx.Error(apperrors.Internal("Failed to encode response", err))
+		return
+	}
+
+	etag = models.RepresentationETag(etag, body)
+	ctx.Header("ETag", etag)
+	isRead := ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead
+	if match := ctx.GetHeader("If-None-Match"); isRead && match != "" && models.ETagListed(match, etag, true) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+	ctx.Data(status, "application/json; charset=utf-8", body)
+}
+
+// IfMatch gets the If-Match precondition of a request
+func IfMatch(ctx *gin.Context) models.Precondition {
+	return models.Precondition(ctx.GetHeader("If-Match"))
+}
@@ -0,0 +1,52 @@
+package httpx
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/models"
+)
+
+// Fields parses the fields and include query parameters of a request. On
+// failure, it reports the error and returns false.
+func Fields(ctx *gin.Context, responseFields map[string][]string, expansions ...string) (models.FieldSet, bool) {
+	fields, err := models.ParseFieldSet(ctx.Query("fields"), ctx.Query("include"), responseFields, expansions...)
+	if err != nil {
+		ctx.Error(err)
+		return models.FieldSet{}, false
+	}
+	return fields, true
+}
+
+// Sparse trims a response down to the fields of a fieldset. Responses are
+// returned as they are when every field is selected.
+func Sparse(response interface{}, fields models.FieldSet) interface{} {
+	if !fields.Sparse() {
+		return response
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return response
+	}
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(data, &document); err != nil {
+		return response
+	}
+
+	for field := range document {
+		if !fields.Has(field) {
+			delete(document, field)
+		}
+	}
+	return document
+}
+
+// SparseList trims each response of a list down to the fields of a fieldset
+func SparseList[T any](responses []T, fields models.FieldSet) []interface{} {
+	sparse := make([]interface{}, len(responses))
+	for i, response := range responses {
+		sparse[i] = Sparse(response, fields)
+	}
+	return sparse
+}
@@ -0,0 +1,177 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+)
+
+// MergePatchContentType is the media type of JSON merge patches (RFC 7386)
+const MergePatchContentType = "application/merge-patch+json"
+
+var errMergePatchRequired = apperrors.UnsupportedMediaType("UNSUPPORTED_MEDIA_TYPE", "PATCH requests must be "+MergePatchContentType)
+
+// BindMergePatch parses a JSON merge patch (RFC 7386) into an update request
+// T and validates it. Members of the patch set the fields of T, and nested
+// objects are merged. null clears a field to its empty value, which is only
+// allowed for the clearable fields, given as JSON paths such as
+// "settings.branding.logoUrl". Map fields are merged into their value in
+// current, the resource's JSON representation. Failures are reported on the
+// context, and the handler should return when ok is false.
+func BindMergePatch[T any](ctx *gin.Context, current interface{}, clearable []string) (req T, ok bool) {
+	if ctx.ContentType() != MergePatchContentType {
+		ctx.Error(errMergePatchRequired)
+		return req, false
+	}
+
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return req, false
+	}
+	var patch map[string]interface{}
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		if err == nil {
+			err = errors.New("merge patch must be a JSON object")
+		}
+		ctx.Error(apperrors.InvalidBody(err))
+		return req, false
+	}
+
+	// Resolve the patch into the update request
+	var currentDoc map[string]interface{}
+	if encoded, err := json.Marshal(current); err == nil {
+		_ = json.Unmarshal(encoded, &currentDoc)
+	}
+	clearableSet := make(map[string]bool, len(clearable))
+	for _, path := range clearable {
+		clearableSet[path] = true
+	}
+	doc, fieldErrs := patchDocument(patch, currentDoc, reflect.TypeOf(req), "", clearableSet)
+	if len(fieldErrs) > 0 {
+		ctx.Error(apperrors.ErrValidation.WithDetails(fieldErrs))
+		return req, false
+	}
+
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return req, false
+	}
+	if err := json.Unmarshal(encoded, &req); err != nil {
+		ctx.Error(apperrors.InvalidBody(err))
+		return req, false
+	}
+	return req, Validate(ctx, req)
+}
+
+// patchDocument resolves the members of a merge patch into the JSON document
+// of an update request of type t, listing the fields that can't be cleared.
+// Members that aren't fields of t are ignored, as in JSON bodies.
+func patchDocument(patch, current map[string]interface{}, t reflect.Type, prefix string, clearable map[string]bool) (map[string]interface{}, []apperrors.FieldError) {
+	t = indirect(t)
+	doc := make(map[string]interface{}, len(patch))
+	var fieldErrs []apperrors.FieldError
+	for key, value := range patch {
+		field, found := jsonField(t, key)
+		if !found {
+			continue
+		}
+		path := prefix + key
+		fieldType := indirect(field.Type)
+
+		switch {
+		case value == nil:
+			if !clearable[path] {
+				fieldErrs = append(fieldErrs, apperrors.FieldError{
+					Field:   path,
+					Rule:    "clearable",
+					Message: path + " can't be cleared",
+				})
+				continue
+			}
+			doc[key] = emptyValue(fieldType)
+		case fieldType.Kind() == reflect.Map:
+			doc[key] = mergePatch(current[key], value)
+		case fieldType.Kind() == reflect.Struct:
+			nested, isObject := value.(map[string]interface{})
+			if !isObject {
+				// Left for decoding to report
+				doc[key] = value
+				continue
+			}
+			currentNested, _ := current[key].(map[string]interface{})
+			nestedDoc, nestedErrs := patchDocument(nested, currentNested, fieldType, path+".", clearable)
+			doc[key] = nestedDoc
+			fieldErrs = append(fieldErrs, nestedErrs...)
+		default:
+			doc[key] = value
+		}
+	}
+	return doc, fieldErrs
+}
+
+// mergePatch applies a merge patch to a JSON value as RFC 7386 defines
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	merged := make(map[string]interface{})
+	if targetObject, ok := target.(map[string]interface{}); ok {
+		for key, value := range targetObject {
+			merged[key] = value
+		}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = mergePatch(merged[key], value)
+		}
+	}
+	return merged
+}
+
+// emptyValue gets the JSON value a cleared field of a type is set to
+func emptyValue(t reflect.Type) interface{} {
+	switch t.Kind() {
+	case reflect.Map:
+		return map[string]interface{}{}
+	case reflect.Slice, reflect.Array:
+		return []interface{}{}
+	default:
+		return reflect.Zero(t).Interface()
+	}
+}
+
+// jsonField finds the field of a struct type with a JSON name
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tagName := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if tagName == "-" || !field.IsExported() {
+			continue
+		}
+		if tagName == name || (tagName == "" && field.Name == name) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// indirect gets the type a pointer type points to
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -1,28 +1,44 @@
 package middleware
 
 import (
-	"net/http"
+	"errors"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
 	"github.com/your-username/slido-clone/user-service/pkg/utils"
 )
 
+// Authorization errors
+var (
+	errRolesNotFound           = apperrors.Unauthorized("UNAUTHORIZED", "Unauthorized: user roles not found")
+	errInsufficientPermissions = apperrors.Forbidden("INSUFFICIENT_PERMISSIONS", "Forbidden: insufficient permissions")
+)
+
+// tokenError converts a token error to a domain error, so clients can tell
+// an expired token from an invalid one
+func tokenError(err error) *apperrors.Error {
+	code := "INVALID_TOKEN"
+	if errors.Is(err, utils.ErrTokenExpired) {
+		code = "TOKEN_EXPIRED"
+	}
+	return apperrors.Unauthorized(code, "Unauthorized: "+err.Error())
+}
+
 // AuthMiddleware creates a Gin middleware for authentication
 func AuthMiddleware(cfg *config.JWTConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get authorization header
-		authHeader := c.GetHeader("Authorization")
+		authHeader := authorization(c, cfg)
 
 		// Extract token
 		token, err := utils.ExtractToken(authHeader)
 		if err != nil {
 			log.Debug().Err(err).Msg("Failed to extract token")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized: " + err.Error(),
-			})
+			AbortWithError(c, tokenError(err))
 			return
 		}
 
@@ -30,9 +46,7 @@ func AuthMiddleware(cfg *config.JWTConfig) gin.HandlerFunc {
 		claims, err := utils.ValidateToken(token, cfg)
 		if err != nil {
 			log.Debug().Err(err).Msg("Invalid token")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized: " + err.Error(),
-			})
+			AbortWithError(c, tokenError(err))
 			return
 		}
 
@@ -59,17 +73,13 @@ func RoleMiddleware(roles ...string) gin.HandlerFunc {
 		// Get user roles from context
 		userRolesI, exists := c.Get("userRoles")
 		if !exists {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized: user roles not found",
-			})
+			AbortWithError(c, errRolesNotFound)
 			return
 		}
 
 		userRoles, ok := userRolesI.([]string)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-				"error": "Internal server error: invalid user roles format",
-			})
+			AbortWithError(c, apperrors.Internal("Internal server error: invalid user roles format", nil))
 			return
 		}
 
@@ -88,9 +98,28 @@ func RoleMiddleware(roles ...string) gin.HandlerFunc {
 		}
 
 		if !hasRole {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-				"error": "Forbidden: insufficient permissions",
-			})
+			AbortWithError(c, errInsufficientPermissions)
+			return
+		}
+
+		// Continue
+		c.Next()
+	}
+}
+
+// PermissionMiddleware creates a Gin middleware that requires a platform
+// permission. Permissions are resolved from the user's platform roles by the
+// same engine that serves GET /profile/permissions.
+func PermissionMiddleware(permission models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get user roles from context
+		if _, exists := c.Get("userRoles"); !exists {
+			AbortWithError(c, errRolesNotFound)
+			return
+		}
+
+		if !models.HasPlatformPermission(GetUserRoles(c), permission) {
+			AbortWithError(c, errInsufficientPermissions)
 			return
 		}
 
@@ -99,11 +128,24 @@ func RoleMiddleware(roles ...string) gin.HandlerFunc {
 	}
 }
 
+// authorization gets the Authorization header of a request. Without one, the
+// access token is read from the session cookie, if cookie sessions are
+// enabled.
+func authorization(c *gin.Context, cfg *config.JWTConfig) string {
+	if header := c.GetHeader("Authorization"); header != "" || cfg.SessionCookie == "" {
+		return header
+	}
+	if cookie, err := c.Cookie(cfg.SessionCookie); err == nil && cookie != "" {
+		return "Bearer " + cookie
+	}
+	return ""
+}
+
 // OptionalAuthMiddleware creates a Gin middleware for optional authentication
 func OptionalAuthMiddleware(cfg *config.JWTConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get authorization header
-		authHeader := c.GetHeader("Authorization")
+		authHeader := authorization(c, cfg)
 		if authHeader == "" {
 			// No token, but that's ok
 			c.Next()
@@ -161,6 +203,19 @@ func GetUserId(c *gin.Context) string {
 	return userId
 }
 
+// GetUserRoles gets the platform roles of the user from the context
+func GetUserRoles(c *gin.Context) []string {
+	userRolesI, exists := c.Get("userRoles")
+	if !exists {
+		return nil
+	}
+	userRoles, ok := userRolesI.([]string)
+	if !ok {
+		return nil
+	}
+	return userRoles
+}
+
 // IsAuthenticated checks if the user is authenticated
 func IsAuthenticated(c *gin.Context) bool {
 	authenticatedI, exists := c.Get("authenticated")
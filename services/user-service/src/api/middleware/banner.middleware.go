@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/models"
+)
+
+// Platform banner response headers
+const (
+	BannerHeader         = "X-Platform-Banner"
+	BannerSeverityHeader = "X-Platform-Banner-Severity"
+)
+
+// BannerSource provides the platform banner to show now
+type BannerSource interface {
+	Current() *models.Banner
+}
+
+// Banner is a middleware that attaches the active platform banner to every
+// response. The message is percent-encoded, since header values can't carry
+// arbitrary text.
+func Banner(source BannerSource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if banner := source.Current(); banner != nil {
+			c.Writer.Header().Set(BannerHeader, url.PathEscape(banner.Message))
+			c.Writer.Header().Set(BannerSeverityHeader, string(banner.Severity))
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+)
+
+// CORSPolicy is a middleware applying the CORS policy. Allowed origins are
+// exact origins, "*" for any origin, or wildcard subdomains such as
+// https://*.example.com. Requests to the public path prefixes are served
+// without credentials unless cfg.PublicCredentials is set. The allowed
+// origins can be replaced at runtime.
+type CORSPolicy struct {
+	publicPaths []string
+	handlers    atomic.Pointer[corsHandlers]
+}
+
+// corsHandlers are the CORS handlers of private and public paths, and the
+// origins they allow
+type corsHandlers struct {
+	private gin.HandlerFunc
+	public  gin.HandlerFunc
+	origins []string
+}
+
+var errWebSocketOrigin = apperrors.Forbidden("ORIGIN_NOT_ALLOWED", "Forbidden: origin not allowed")
+
+// NewCORSPolicy creates a CORS policy
+func NewCORSPolicy(cfg *config.CORSConfig, publicPaths ...string) *CORSPolicy {
+	policy := &CORSPolicy{publicPaths: publicPaths}
+	policy.Update(cfg)
+	return policy
+}
+
+// Update replaces the policy's settings; requests already being handled
+// keep the previous ones
+func (p *CORSPolicy) Update(cfg *config.CORSConfig) {
+	origins := corsOrigins(cfg.AllowedOrigins)
+	if len(origins) == 0 {
+		log.Warn().Msg("No CORS origins are allowed; browsers can't call the API from other origins")
+	}
+
+	// Credentials can't be shared with any origin
+	credentials := cfg.AllowCredentials
+	if credentials && containsString(origins, "*") {
+		log.Warn().Msg("CORS credentials are disabled, since any origin is allowed")
+		credentials = false
+	}
+
+	p.handlers.Store(&corsHandlers{
+		private: newCORS(origins, credentials),
+		public:  newCORS(origins, credentials && cfg.PublicCredentials),
+		origins: origins,
+	})
+}
+
+// Handler gets the middleware applying the policy
+func (p *CORSPolicy) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handlers := p.handlers.Load()
+		for _, prefix := range p.publicPaths {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				handlers.public(c)
+				return
+			}
+		}
+		handlers.private(c)
+	}
+}
+
+// WebSocketOrigin gets a middleware refusing websocket upgrades from origins
+// the policy doesn't allow. Browsers don't apply CORS to websockets but do
+// send cookies with them, so any site could otherwise connect as the user.
+// Requests without an origin, from non-browser clients, are let through.
+func (p *CORSPolicy) WebSocketOrigin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin != "" && !sameOrigin(origin, c.Request.Host) && !originAllowed(p.handlers.Load().origins, origin) {
+			AbortWithError(c, errWebSocketOrigin)
+			return
+		}
+		c.Next()
+	}
+}
+
+// sameOrigin checks if an origin is the host the request was sent to
+func sameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, host)
+}
+
+// newCORS creates the CORS handler of a policy
+func newCORS(origins []string, credentials bool) gin.HandlerFunc {
+	return cors.New(cors.Config{
+		AllowOriginFunc: func(origin string) bool {
+			return originAllowed(origins, origin)
+		},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", CSRFHeader, "If-Match", "If-None-Match", logger.CorrelationIDHeader, tenancy.OrganizationHeader},
+		ExposeHeaders:    []string{"Content-Length", "ETag", BannerHeader, BannerSeverityHeader, "X-Request-ID", logger.CorrelationIDHeader},
+		AllowCredentials: credentials,
+		MaxAge:           12 * time.Hour,
+	})
+}
+
+// corsOrigins normalizes the allowed origins, given as comma-separated lists
+func corsOrigins(entries []string) []string {
+	var origins []string
+	for _, entry := range entries {
+		for _, origin := range strings.Split(entry, ",") {
+			origin = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(origin)), "/")
+			if origin != "" {
+				origins = append(origins, origin)
+			}
+		}
+	}
+	return origins
+}
+
+// originAllowed checks if an origin matches one of the allowed origins. A
+// wildcard subdomain matches subdomains of any depth, but not the domain
+// itself.
+func originAllowed(allowed []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range allowed {
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+
+		scheme, domain, ok := strings.Cut(pattern, "://*.")
+		if !ok {
+			continue
+		}
+		prefix, suffix := scheme+"://", "."+domain
+		if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+			continue
+		}
+		subdomain := strings.TrimSuffix(strings.TrimPrefix(origin, prefix), suffix)
+		if subdomain != "" && !strings.ContainsAny(subdomain, "/:@") {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString checks if a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Errors of malformed IDs and missing documents that reach a handler
+// unconverted
+var (
+	errInvalidID = apperrors.Validation("INVALID_ID", "invalid ID")
+	errNotFound  = apperrors.NotFound("NOT_FOUND", "resource not found")
+)
+
+// ErrorHandler writes the error a handler attached with ctx.Error as a
+// {code, message, details, requestId} response. Errors without a domain
+// error are reported as internal errors without exposing their message.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		// The cause of internal errors is logged by Logger, not returned
+		appErr := responseError(c.Errors.Last().Err)
+		c.AbortWithStatusJSON(appErr.Status(), appErr.ToResponse(c.GetString("request_id")))
+	}
+}
+
+// responseError gets the domain error ErrorHandler responds with for an
+// error attached to a request
+func responseError(err error) *apperrors.Error {
+	switch {
+	case errors.Is(err, primitive.ErrInvalidHex):
+		err = errInvalidID.WithCause(err)
+	case errors.Is(err, mongo.ErrNoDocuments):
+		err = errNotFound.WithCause(err)
+	}
+
+	return apperrors.From(err, "Internal server error")
+}
+
+// AbortWithError attaches a domain error for ErrorHandler and stops the
+// remaining handlers
+func AbortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
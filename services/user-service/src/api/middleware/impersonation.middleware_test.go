@@ -0,0 +1,114 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/utils"
+)
+
+// sessionCookie is the cookie sessions send their access token in
+const sessionCookie = "session"
+
+// fakeAuditor is an impersonation auditor over a set of active sessions that
+// keeps the requests it records
+type fakeAuditor struct {
+	active  map[string]bool
+	entries []*models.AuditEntry
+}
+
+func (a *fakeAuditor) IsActive(ctx context.Context, sessionID string) (bool, error) {
+	return a.active[sessionID], nil
+}
+
+func (a *fakeAuditor) RecordRequest(entry *models.AuditEntry) {
+	a.entries = append(a.entries, entry)
+}
+
+// newImpersonationRouter creates a router with the impersonation middleware
+// in front of a handler that answers 200
+func newImpersonationRouter(cfg *config.JWTConfig, auditor *fakeAuditor) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(), middleware.Impersonation(cfg, auditor))
+	router.Any("/api/users/me", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+// newImpersonationToken mints a token of an impersonation session
+func newImpersonationToken(t *testing.T, cfg *config.JWTConfig, sessionID string, readOnly bool) string {
+	t.Helper()
+
+	token, err := utils.NewImpersonationToken(cfg, "user-1", "user@example.com", string(models.RoleUser),
+		utils.ImpersonationClaims{SessionID: sessionID, AdminID: "admin-1", ReadOnly: readOnly},
+		time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("NewImpersonationToken: %v", err)
+	}
+	return token
+}
+
+func TestImpersonationTokenFromSessionCookie(t *testing.T) {
+	cfg := &config.JWTConfig{Secret: "test-secret", Issuer: "user-service", SessionCookie: sessionCookie}
+
+	tests := []struct {
+		name       string
+		method     string
+		active     bool
+		readOnly   bool
+		wantStatus int
+	}{
+		{name: "active session", method: http.MethodGet, active: true, wantStatus: http.StatusOK},
+		{name: "ended session", method: http.MethodGet, active: false, wantStatus: http.StatusUnauthorized},
+		{name: "read-only session writing", method: http.MethodPatch, active: true, readOnly: true, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditor := &fakeAuditor{active: map[string]bool{"session-1": tt.active}}
+			router := newImpersonationRouter(cfg, auditor)
+
+			req := httptest.NewRequest(tt.method, "/api/users/me", nil)
+			req.AddCookie(&http.Cookie{Name: sessionCookie, Value: newImpersonationToken(t, cfg, "session-1", tt.readOnly)})
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if len(auditor.entries) != 1 {
+				t.Fatalf("recorded %d requests, want 1", len(auditor.entries))
+			}
+			if entry := auditor.entries[0]; entry.ImpersonationID != "session-1" || entry.Status != tt.wantStatus {
+				t.Errorf("recorded session %q with status %d, want session-1 with %d",
+					entry.ImpersonationID, entry.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestImpersonationIgnoresCookieWithoutCookieSessions(t *testing.T) {
+	cfg := &config.JWTConfig{Secret: "test-secret", Issuer: "user-service"}
+	auditor := &fakeAuditor{active: map[string]bool{}}
+	router := newImpersonationRouter(cfg, auditor)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/me", nil)
+	req.AddCookie(&http.Cookie{Name: sessionCookie, Value: newImpersonationToken(t, cfg, "session-1", false)})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if len(auditor.entries) != 0 {
+		t.Errorf("recorded %d requests, want none", len(auditor.entries))
+	}
+}
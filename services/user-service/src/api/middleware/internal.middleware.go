@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+)
+
+// InternalAPIKeyHeader is the header other services authenticate internal calls with
+const InternalAPIKeyHeader = "X-Internal-API-Key"
+
+// InternalAuthMiddleware creates a Gin middleware that authenticates
+// service-to-service calls with the shared internal API key
+func InternalAuthMiddleware(cfg *config.InternalAPIConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.APIKey == "" {
+			AbortWithError(c, apperrors.Unavailable("INTERNAL_API_DISABLED", "Internal API is not configured"))
+			return
+		}
+
+		key := c.GetHeader(InternalAPIKeyHeader)
+		if subtle.ConstantTimeCompare([]byte(key), []byte(cfg.APIKey)) != 1 {
+			log.Debug().Str("path", c.Request.URL.Path).Msg("Invalid internal API key")
+			AbortWithError(c, apperrors.Unauthorized("INVALID_API_KEY", "Unauthorized: invalid internal API key"))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -4,7 +4,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/pkg/id"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
 )
 
 // Logger is a middleware for logging HTTP requests
@@ -52,7 +53,7 @@ func Logger() gin.HandlerFunc {
 		}
 
 		// Log request
-		logEvent := log.Info()
+		logEvent := logger.Ctx(c).Info()
 
 		// Add fields
 		logEvent = logEvent.
@@ -80,7 +81,7 @@ func Logger() gin.HandlerFunc {
 
 		// Determine log level based on status code
 		if status >= 500 {
-			log.Error().
+			logger.Ctx(c).Error().
 				Str("method", c.Request.Method).
 				Str("path", path).
 				Int("status", status).
@@ -90,7 +91,7 @@ func Logger() gin.HandlerFunc {
 				Str("error", errMsg).
 				Msg("Server error")
 		} else if status >= 400 {
-			log.Warn().
+			logger.Ctx(c).Warn().
 				Str("method", c.Request.Method).
 				Str("path", path).
 				Int("status", status).
@@ -105,7 +106,8 @@ func Logger() gin.HandlerFunc {
 	}
 }
 
-// RequestID is a middleware for adding a request ID to the context
+// RequestID is a middleware for adding a request ID and a correlation ID to
+// the context
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get request ID from header
@@ -122,27 +124,22 @@ func RequestID() gin.HandlerFunc {
 		// Set request ID in response header
 		c.Writer.Header().Set("X-Request-ID", requestID)
 
+		// Carry the caller's correlation ID, or start one with the request ID,
+		// through the request's events, outbound calls and logs
+		correlationID := c.Request.Header.Get(logger.CorrelationIDHeader)
+		if correlationID == "" {
+			correlationID = requestID
+		}
+		c.Set(logger.CorrelationIDKey, correlationID)
+		c.Request = c.Request.WithContext(logger.WithCorrelationID(c.Request.Context(), correlationID))
+		c.Writer.Header().Set(logger.CorrelationIDHeader, correlationID)
+
 		c.Next()
 	}
 }
 
-// GenerateRequestID generates a unique request ID
+// GenerateRequestID generates a unique request ID, a UUIDv7 so request IDs
+// sort by time
 func GenerateRequestID() string {
-	// Simple implementation using timestamp
-	return time.Now().Format("20060102150405") + "-" + RandomString(8)
-}
-
-// RandomString generates a random string of the specified length
-func RandomString(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[RandomInt(len(charset))]
-	}
-	return string(b)
-}
-
-// RandomInt generates a random integer between 0 and n-1
-func RandomInt(n int) int {
-	return int(time.Now().UnixNano() % int64(n))
+	return id.New()
 }
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/pkg/scim"
+	"github.com/your-username/slido-clone/user-service/pkg/utils"
+)
+
+// SCIMTokenAuthenticator resolves a SCIM bearer token to an organization ID
+type SCIMTokenAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (string, error)
+}
+
+// SCIMAuthMiddleware creates a Gin middleware for SCIM bearer token authentication
+func SCIMAuthMiddleware(authenticator SCIMTokenAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Extract token
+		token, err := utils.ExtractToken(c.GetHeader("Authorization"))
+		if err != nil {
+			log.Debug().Err(err).Msg("Failed to extract SCIM token")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, scim.NewError(http.StatusUnauthorized, "", err.Error()))
+			return
+		}
+
+		// Resolve organization
+		orgID, err := authenticator.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			log.Debug().Err(err).Msg("Invalid SCIM token")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, scim.NewError(http.StatusUnauthorized, "", "Invalid SCIM token"))
+			return
+		}
+
+		// Store organization in context; the request acts on it alone
+		c.Set("scimOrgId", orgID)
+		SetTenant(c, orgID)
+
+		// Continue
+		c.Next()
+	}
+}
+
+// GetSCIMOrgId gets the SCIM organization ID from the context
+func GetSCIMOrgId(c *gin.Context) string {
+	orgIdI, exists := c.Get("scimOrgId")
+	if !exists {
+		return ""
+	}
+	orgId, ok := orgIdI.(string)
+	if !ok {
+		return ""
+	}
+	return orgId
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/pkg/slo"
+)
+
+// keyEndpoints are the routes counted towards the API availability SLI
+var keyEndpoints = map[string]bool{
+	"GET /api/me":                         true,
+	"PUT /api/me":                         true,
+	"GET /api/users/:id":                  true,
+	"GET /api/profile":                    true,
+	"GET /api/profile/full":               true,
+	"GET /api/teams/:id":                  true,
+	"GET /api/teams/:id/members":          true,
+	"GET /api/organizations/:id":          true,
+	"GET /api/organizations/:id/members":  true,
+	"POST /api/organizations/:id/members": true,
+}
+
+// SLO is a middleware that records key endpoint outcomes for the availability SLI.
+// Panics count as the 500 the recovery middleware answers them with, and are
+// passed on to it.
+func SLO() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				recordRequest(c, http.StatusInternalServerError)
+				panic(r)
+			}
+			recordRequest(c, c.Writer.Status())
+		}()
+
+		// Process request
+		c.Next()
+	}
+}
+
+// recordRequest records the outcome of a request, if it's to a key endpoint
+func recordRequest(c *gin.Context, status int) {
+	// Only key endpoints count towards the SLI
+	if !keyEndpoints[c.Request.Method+" "+c.FullPath()] {
+		return
+	}
+
+	slo.RecordRequest(status)
+}
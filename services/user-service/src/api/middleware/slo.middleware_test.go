@@ -0,0 +1,41 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/pkg/slo"
+)
+
+func TestSLOCountsPanicsAsServerErrors(t *testing.T) {
+	slo.Init(&config.SLOConfig{AvailabilityObjective: 0.99})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.Recovery(), middleware.SLO())
+	router.GET("/api/me", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/api/me", nil))
+	if res.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", res.Code, http.StatusInternalServerError)
+	}
+
+	for _, summary := range slo.Default().Summaries() {
+		if summary.Name != slo.APIAvailability {
+			continue
+		}
+		window := summary.Windows[0]
+		if window.Total != 1 || window.Good != 0 {
+			t.Errorf("availability = %d good of %d, want 0 of 1", window.Good, window.Total)
+		}
+		return
+	}
+	t.Fatalf("no %s SLI", slo.APIAvailability)
+}
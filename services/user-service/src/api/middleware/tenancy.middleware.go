@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+)
+
+// errTenantMismatch is returned when the organization header names another
+// organization than the request's path
+var errTenantMismatch = apperrors.Validation("TENANT_MISMATCH", "the "+tenancy.OrganizationHeader+" header names another organization than the path")
+
+// TenantResolver gets the organization a resource belongs to by its ID. It
+// is called with an unscoped context.
+type TenantResolver func(ctx context.Context, id string) (string, error)
+
+// Tenancy is a middleware setting the active organization of requests, the
+// one their path names, as in /organizations/:id, the one the resource their
+// path names belongs to, as in /teams/:id with a resolver for "teams", or
+// else the one the X-Organization-ID header names. Repositories keep the
+// queries of organization data made for the request inside it. Requests
+// naming no organization act for their user across organizations and are
+// marked unscoped.
+func Tenancy(resolvers map[string]TenantResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID, err := pathOrganization(c, resolvers)
+		if err != nil {
+			AbortWithError(c, apperrors.From(err, "Failed to resolve the organization of the request"))
+			return
+		}
+
+		header := strings.TrimSpace(c.GetHeader(tenancy.OrganizationHeader))
+		switch {
+		case orgID == "":
+			orgID = header
+		case header != "" && header != orgID:
+			AbortWithError(c, errTenantMismatch)
+			return
+		}
+
+		SetTenant(c, orgID)
+		c.Next()
+	}
+}
+
+// SetTenant sets the active organization of a request, or marks a request
+// naming none unscoped
+func SetTenant(c *gin.Context, orgID string) {
+	if orgID == "" {
+		c.Set(tenancy.UnscopedKey, true)
+		c.Request = c.Request.WithContext(tenancy.Unscoped(c.Request.Context()))
+		return
+	}
+	c.Set(tenancy.OrganizationKey, orgID)
+	c.Request = c.Request.WithContext(tenancy.WithOrganization(c.Request.Context(), orgID))
+}
+
+// pathOrganization gets the organization ID of a request's path: the
+// parameter following an organizations segment of its route, or the
+// organization of the resource whose parameter follows a resolver's segment
+func pathOrganization(c *gin.Context, resolvers map[string]TenantResolver) (string, error) {
+	segments := strings.Split(c.FullPath(), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if !strings.HasPrefix(segments[i+1], ":") {
+			continue
+		}
+		id := c.Param(strings.TrimPrefix(segments[i+1], ":"))
+		if segments[i] == "organizations" {
+			return id, nil
+		}
+		if resolve, ok := resolvers[segments[i]]; ok {
+			return resolve(tenancy.Unscoped(c.Request.Context()), id)
+		}
+	}
+	return "", nil
+}
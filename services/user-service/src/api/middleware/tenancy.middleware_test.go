@@ -0,0 +1,58 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+)
+
+func TestTenancyScopesResourceRoutesAndMarksOthersUnscoped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(), middleware.Tenancy(map[string]middleware.TenantResolver{
+		"teams": func(ctx context.Context, id string) (string, error) {
+			if !tenancy.IsUnscoped(ctx) {
+				t.Errorf("team %s resolved without an unscoped context", id)
+			}
+			return "org-of-" + id, nil
+		},
+	}))
+
+	var checked error
+	check := func(c *gin.Context) {
+		checked = tenancy.Check(c, "org-of-team-1")
+	}
+	router.GET("/api/teams/:id", check)
+	router.GET("/api/profile", check)
+
+	tests := []struct {
+		path string
+		want error
+	}{
+		{path: "/api/teams/team-1", want: nil},
+		{path: "/api/teams/team-2", want: tenancy.ErrCrossTenant},
+		{path: "/api/profile", want: nil},
+	}
+	for _, tt := range tests {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if !errors.Is(checked, tt.want) {
+			t.Errorf("GET %s: Check = %v, want %v", tt.path, checked, tt.want)
+		}
+	}
+
+	// Contexts that are neither scoped nor marked unscoped access nothing
+	if err := tenancy.Check(context.Background(), "org-of-team-1"); !errors.Is(err, tenancy.ErrNoTenant) {
+		t.Errorf("Check without a tenant = %v, want ErrNoTenant", err)
+	}
+	scoped := tenancy.WithOrganization(context.Background(), "org-of-team-2")
+	if err := tenancy.Check(tenancy.Unscoped(scoped), "org-of-team-1"); err != nil {
+		t.Errorf("Check with an unscoped context = %v, want nil", err)
+	}
+}
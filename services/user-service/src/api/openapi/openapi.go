@@ -0,0 +1,11 @@
+// Package openapi embeds the OpenAPI document of the user, team,
+// organization and profile routes
+package openapi
+
+import _ "embed"
+
+// Spec is the OpenAPI 3.0 document served at /api/openapi.json. Keep it in
+// sync with the routes and models when they change.
+//
+//go:embed openapi.json
+var Spec []byte
@@ -0,0 +1,5 @@
+// Package userv1 contains the messages and gRPC service of the internal user
+// lookup API, generated from user.proto. Regenerate after editing it.
+package userv1
+
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative --go-grpc_out=../../.. --go-grpc_opt=paths=source_relative api/proto/userv1/user.proto
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterActivityRoutes registers user activity feed routes
+func RegisterActivityRoutes(router *gin.RouterGroup, activityController *controllers.ActivityController, cfg *config.JWTConfig) {
+	// All activity routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/profile/activity", activityController.GetUserActivity)
+	protected.GET("/organizations/:id/activity", activityController.GetOrganizationActivity)
+}
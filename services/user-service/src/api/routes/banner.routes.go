@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+)
+
+// RegisterSystemRoutes registers the public system routes
+func RegisterSystemRoutes(router *gin.RouterGroup, bannerController *controllers.BannerController) {
+	router.GET("/banner", bannerController.GetCurrentBanner)
+}
+
+// RegisterBannerRoutes registers the platform banner admin routes
+func RegisterBannerRoutes(router *gin.RouterGroup, bannerController *controllers.BannerController, cfg *config.JWTConfig) {
+	// Managing the banner is restricted to platform admins
+	admin := router.Group("/admin/banner")
+	admin.Use(middleware.AuthMiddleware(cfg), middleware.PermissionMiddleware(models.PermPlatformManageBanner))
+
+	admin.GET("", bannerController.GetBanner)
+	admin.PUT("", bannerController.UpdateBanner)
+	admin.DELETE("", bannerController.DeleteBanner)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+)
+
+// RegisterConfigRoutes registers the active configuration routes
+func RegisterConfigRoutes(router *gin.RouterGroup, reloader *config.Reloader, cfg *config.JWTConfig) {
+	// Config routes are restricted to admins
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+	protected.Use(middleware.PermissionMiddleware(models.PermPlatformViewConfig))
+
+	protected.GET("/config", func(c *gin.Context) {
+		active, reloadedAt := reloader.Current()
+		c.JSON(http.StatusOK, gin.H{
+			"service":    "user-service",
+			"reloadedAt": reloadedAt,
+			"reloadable": config.ReloadableSettings,
+			"config":     active.Masked(),
+		})
+	})
+}
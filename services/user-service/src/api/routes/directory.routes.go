@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterDirectoryRoutes registers the public organization directory routes
+func RegisterDirectoryRoutes(router *gin.RouterGroup, directoryController *controllers.DirectoryController, cfg *config.JWTConfig) {
+	// Directory routes are public; signed in users are recognized
+	public := router.Group("")
+	public.Use(middleware.OptionalAuthMiddleware(cfg))
+
+	public.GET("/directory/organizations", directoryController.ListOrganizations)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterDirectoryImportRoutes registers organization directory import routes
+func RegisterDirectoryImportRoutes(router *gin.RouterGroup, directoryImportController *controllers.DirectoryImportController, cfg *config.JWTConfig) {
+	// All directory import routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.POST("/organizations/:id/import/google", directoryImportController.ImportGoogle)
+	protected.POST("/organizations/:id/import/slack", directoryImportController.ImportSlack)
+}
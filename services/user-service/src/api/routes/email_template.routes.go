@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterEmailTemplateRoutes registers organization email template routes
+func RegisterEmailTemplateRoutes(router *gin.RouterGroup, templateController *controllers.EmailTemplateController, cfg *config.JWTConfig) {
+	// All email template routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/organizations/:id/email-templates", templateController.GetTemplates)
+	protected.GET("/organizations/:id/email-templates/:key", templateController.GetTemplate)
+	protected.PUT("/organizations/:id/email-templates/:key", templateController.UpdateTemplate)
+	protected.DELETE("/organizations/:id/email-templates/:key", templateController.DeleteTemplate)
+	protected.GET("/organizations/:id/email-templates/:key/versions", templateController.GetTemplateVersions)
+}
+
+// RegisterInternalEmailTemplateRoutes registers the email template routes used by other services
+func RegisterInternalEmailTemplateRoutes(router *gin.RouterGroup, templateController *controllers.EmailTemplateController, cfg *config.InternalAPIConfig) {
+	// All internal routes require the internal API key
+	internal := router.Group("")
+	internal.Use(middleware.InternalAuthMiddleware(cfg))
+
+	internal.GET("/organizations/:id/email-templates", templateController.GetInternalTemplates)
+}
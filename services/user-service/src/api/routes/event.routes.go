@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterEventRoutes registers organization event routes
+func RegisterEventRoutes(router *gin.RouterGroup, eventController *controllers.EventController, cfg *config.JWTConfig) {
+	// All event routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/organizations/:id/events", eventController.GetEvents)
+	protected.POST("/organizations/:id/events", eventController.CreateEvent)
+	protected.GET("/organizations/:id/events/:eventId", eventController.GetEvent)
+	protected.PUT("/organizations/:id/events/:eventId", eventController.UpdateEvent)
+	protected.DELETE("/organizations/:id/events/:eventId", eventController.DeleteEvent)
+	protected.POST("/organizations/:id/events/:eventId/start", eventController.StartEvent)
+	protected.POST("/organizations/:id/events/:eventId/end", eventController.EndEvent)
+
+	// Participants join events by code, whatever their organization
+	protected.GET("/events/code/:code", eventController.GetEventByCode)
+}
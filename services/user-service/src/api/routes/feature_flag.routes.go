@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+)
+
+// RegisterFeatureFlagRoutes registers feature flag routes
+func RegisterFeatureFlagRoutes(router *gin.RouterGroup, flagController *controllers.FeatureFlagController, cfg *config.JWTConfig) {
+	// Every signed in user gets their evaluated flags
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+	protected.GET("/flags", flagController.GetFlags)
+
+	// Managing flags is restricted to platform admins
+	admin := router.Group("/admin/flags")
+	admin.Use(middleware.AuthMiddleware(cfg), middleware.PermissionMiddleware(models.PermPlatformManageFeatureFlags))
+
+	admin.GET("", flagController.ListFlags)
+	admin.GET("/:key", flagController.GetFlag)
+	admin.PUT("/:key", flagController.UpdateFlag)
+	admin.DELETE("/:key", flagController.DeleteFlag)
+	admin.PUT("/:key/overrides/:scope/:targetId", flagController.SetOverride)
+	admin.DELETE("/:key/overrides/:scope/:targetId", flagController.DeleteOverride)
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/gql"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterGraphQLRoutes registers the GraphQL endpoint
+func RegisterGraphQLRoutes(router *gin.RouterGroup, handler *gql.Handler, cfg *config.JWTConfig) {
+	// Every query is resolved for the viewer, so it requires authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.POST("", handler.ServeGraphQL)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterGroupRoutes registers organization group routes
+func RegisterGroupRoutes(router *gin.RouterGroup, groupController *controllers.GroupController, cfg *config.JWTConfig) {
+	// All group routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/organizations/:id/groups", groupController.GetGroups)
+	protected.POST("/organizations/:id/groups", groupController.CreateGroup)
+	protected.GET("/organizations/:id/groups/:groupId", groupController.GetGroup)
+	protected.PUT("/organizations/:id/groups/:groupId", groupController.UpdateGroup)
+	protected.DELETE("/organizations/:id/groups/:groupId", groupController.DeleteGroup)
+	protected.POST("/organizations/:id/groups/:groupId/members", groupController.AddGroupMembers)
+	protected.DELETE("/organizations/:id/groups/:groupId/members/:userId", groupController.RemoveGroupMember)
+}
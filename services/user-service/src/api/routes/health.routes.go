@@ -1,70 +1,84 @@
 package routes
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/pkg/health"
 	"github.com/your-username/slido-clone/user-service/pkg/kafka"
 )
 
+// healthCheckTimeout bounds each dependency check of a probe, staying under
+// the default timeout of Kubernetes probes
+const healthCheckTimeout = 900 * time.Millisecond
+
 // Health represents the service health information
 type Health struct {
-	Status       string            `json:"status"`
-	Service      string            `json:"service"`
-	Version      string            `json:"version"`
-	Timestamp    time.Time         `json:"timestamp"`
-	Dependencies map[string]string `json:"dependencies"`
+	Status       string                       `json:"status"`
+	Service      string                       `json:"service"`
+	Version      string                       `json:"version"`
+	Timestamp    time.Time                    `json:"timestamp"`
+	Dependencies map[string]health.Dependency `json:"dependencies,omitempty"`
 }
 
-// RegisterHealthRoutes registers health routes
-func RegisterHealthRoutes(router *gin.RouterGroup, mongoDB *db.MongoDB, producer *kafka.Producer) {
-	router.GET("", func(c *gin.Context) {
-		// Basic health check
-		health := Health{
-			Status:    "UP",
-			Service:   "user-service",
-			Version:   "1.0.0",
-			Timestamp: time.Now(),
-			Dependencies: map[string]string{
-				"mongodb": "UP",
-				"kafka":   "UP",
-			},
-		}
+// RegisterHealthRoutes registers the liveness, readiness and startup probes.
+// Liveness only checks the process is serving requests; readiness and
+// startup check the storage backend, the Kafka brokers and the consumer.
+func RegisterHealthRoutes(router *gin.RouterGroup, store db.Storage, producer *kafka.Producer, consumer *kafka.Consumer) {
+	ready := health.NewProbe(healthCheckTimeout).
+		Add(store.Driver(), store.Ping).
+		Add("kafka", producer.Ping).
+		Add("kafka-consumer", func(ctx context.Context) error {
+			if !consumer.Running() {
+				return errors.New("consumer is not running")
+			}
+			return nil
+		})
+	startup := health.NewStartupProbe(ready)
+
+	router.GET("/live", func(c *gin.Context) {
+		respondHealth(c, true, nil)
+	})
 
-		c.JSON(http.StatusOK, health)
+	router.GET("/ready", func(c *gin.Context) {
+		up, dependencies := ready.Run(c.Request.Context())
+		respondHealth(c, up, dependencies)
 	})
 
-	router.GET("/detailed", func(c *gin.Context) {
-		// Check MongoDB connection
-		ctx := c.Request.Context()
-		mongoStatus := "UP"
+	router.GET("/startup", func(c *gin.Context) {
+		up, dependencies := startup.Run(c.Request.Context())
+		respondHealth(c, up, dependencies)
+	})
 
-		err := mongoDB.Client.Ping(ctx, nil)
-		if err != nil {
-			mongoStatus = "DOWN"
-		}
+	// Kept for existing monitors; both report readiness
+	for _, path := range []string{"", "/detailed"} {
+		router.GET(path, func(c *gin.Context) {
+			up, dependencies := ready.Run(c.Request.Context())
+			respondHealth(c, up, dependencies)
+		})
+	}
+}
 
-		// Detailed health check
-		health := Health{
-			Status:    mongoStatus,
-			Service:   "user-service",
-			Version:   "1.0.0",
-			Timestamp: time.Now(),
-			Dependencies: map[string]string{
-				"mongodb": mongoStatus,
-				"kafka":   "UP", // Assuming Kafka is UP - we could add a specific check
-			},
-		}
+// respondHealth responds with the outcome of a probe: 200 when it is up,
+// and 503 otherwise
+func respondHealth(c *gin.Context, up bool, dependencies map[string]health.Dependency) {
+	report := Health{
+		Status:       health.StatusUp,
+		Service:      "user-service",
+		Version:      "1.0.0",
+		Timestamp:    time.Now(),
+		Dependencies: dependencies,
+	}
 
-		// Set status code based on dependencies
-		statusCode := http.StatusOK
-		if mongoStatus != "UP" {
-			health.Status = "DEGRADED"
-			statusCode = http.StatusServiceUnavailable
-		}
+	statusCode := http.StatusOK
+	if !up {
+		report.Status = health.StatusDown
+		statusCode = http.StatusServiceUnavailable
+	}
 
-		c.JSON(statusCode, health)
-	})
+	c.JSON(statusCode, report)
 }
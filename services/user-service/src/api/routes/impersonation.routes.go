@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+)
+
+// RegisterImpersonationRoutes registers the admin impersonation routes
+func RegisterImpersonationRoutes(router *gin.RouterGroup, impersonationController *controllers.ImpersonationController, cfg *config.JWTConfig) {
+	// Impersonating users is restricted to platform admins
+	admin := router.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(cfg), middleware.PermissionMiddleware(models.PermPlatformImpersonateUsers))
+
+	admin.POST("/impersonate/:userId", impersonationController.StartImpersonation)
+	admin.DELETE("/impersonations/:id", impersonationController.EndImpersonation)
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterInboxRoutes registers in-app notification inbox routes
+func RegisterInboxRoutes(router *gin.RouterGroup, inboxController *controllers.InboxController, cfg *config.JWTConfig) {
+	// All inbox routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/profile/notifications", inboxController.GetInbox)
+	protected.GET("/profile/notifications/unread-count", inboxController.GetUnreadCount)
+	protected.GET("/profile/notifications/stream", inboxController.Stream)
+	protected.POST("/profile/notifications/read", inboxController.MarkAllRead)
+	protected.POST("/profile/notifications/:notificationId/read", inboxController.MarkRead)
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+)
+
+// RegisterJobRoutes registers the admin scheduled job routes
+func RegisterJobRoutes(router *gin.RouterGroup, jobController *controllers.JobController, cfg *config.JWTConfig) {
+	// Jobs are managed by platform admins
+	admin := router.Group("/admin/jobs")
+	admin.Use(middleware.AuthMiddleware(cfg), middleware.PermissionMiddleware(models.PermPlatformManageJobs))
+
+	admin.GET("", jobController.GetJobs)
+	admin.GET("/:name/runs", jobController.GetJobRuns)
+	admin.POST("/:name/run", jobController.TriggerJob)
+}
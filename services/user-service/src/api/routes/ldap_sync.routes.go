@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterLDAPSyncRoutes registers organization LDAP sync routes
+func RegisterLDAPSyncRoutes(router *gin.RouterGroup, ldapSyncController *controllers.LDAPSyncController, cfg *config.JWTConfig) {
+	// All LDAP sync routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/organizations/:id/ldap-sync", ldapSyncController.GetConfig)
+	protected.PUT("/organizations/:id/ldap-sync", ldapSyncController.UpdateConfig)
+	protected.DELETE("/organizations/:id/ldap-sync", ldapSyncController.DeleteConfig)
+	protected.POST("/organizations/:id/ldap-sync/runs", ldapSyncController.RunSync)
+	protected.GET("/organizations/:id/ldap-sync/runs", ldapSyncController.GetRuns)
+	protected.GET("/organizations/:id/ldap-sync/runs/:runId", ldapSyncController.GetRun)
+}
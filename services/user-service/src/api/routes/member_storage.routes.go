@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+)
+
+// RegisterMemberStorageRoutes registers the organization member storage routes
+func RegisterMemberStorageRoutes(router *gin.RouterGroup, memberStorageController *controllers.MemberStorageController, cfg *config.JWTConfig) {
+	// Moving members between layouts is restricted to platform admins
+	admin := router.Group("/admin/organizations")
+	admin.Use(middleware.AuthMiddleware(cfg), middleware.PermissionMiddleware(models.PermPlatformManageMemberStorage))
+
+	admin.PUT("/:id/member-storage", memberStorageController.UpdateMemberStorage)
+}
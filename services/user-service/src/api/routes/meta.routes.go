@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+)
+
+// RegisterMetaRoutes registers the public catalog routes
+func RegisterMetaRoutes(router *gin.RouterGroup, metaController *controllers.MetaController) {
+	meta := router.Group("/meta")
+
+	meta.GET("/timezones", metaController.GetTimezones)
+	meta.GET("/locales", metaController.GetLocales)
+}
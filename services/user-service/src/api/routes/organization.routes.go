@@ -5,6 +5,7 @@ import (
 	"github.com/your-username/slido-clone/user-service/api/controllers"
 	"github.com/your-username/slido-clone/user-service/api/middleware"
 	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
 )
 
 // RegisterOrganizationRoutes registers organization routes
@@ -18,19 +19,56 @@ func RegisterOrganizationRoutes(router *gin.RouterGroup, orgController *controll
 	protected.POST("/organizations", orgController.CreateOrganization)
 	protected.GET("/organizations/:id", orgController.GetOrganization)
 	protected.PUT("/organizations/:id", orgController.UpdateOrganization)
+	protected.PATCH("/organizations/:id", orgController.PatchOrganization)
 	protected.DELETE("/organizations/:id", orgController.DeleteOrganization)
+	protected.POST("/organizations/:id/tags", orgController.AddOrganizationTags)
+	protected.DELETE("/organizations/:id/tags/:tag", orgController.RemoveOrganizationTag)
 
 	// Organization members routes
 	protected.GET("/organizations/:id/members", orgController.GetOrganizationMembers)
 	protected.POST("/organizations/:id/members", orgController.AddOrganizationMember)
+	protected.POST("/organizations/:id/members/bulk", orgController.BulkOrganizationMembers)
 	protected.PUT("/organizations/:id/members/:memberId", orgController.UpdateOrganizationMember)
 	protected.DELETE("/organizations/:id/members/:memberId", orgController.RemoveOrganizationMember)
+	protected.PUT("/organizations/:id/members/:memberId/custom-fields", orgController.UpdateMemberCustomFields)
+	protected.GET("/organizations/:id/two-factor/compliance", orgController.GetTwoFactorCompliance)
+
+	// Organization access review routes
+	protected.GET("/organizations/:id/access-review", orgController.GetAccessReview)
+	protected.POST("/organizations/:id/access-review/confirm", orgController.ConfirmAccessReview)
+	protected.POST("/organizations/:id/access-review/revoke", orgController.RevokeAccessReview)
+
+	// Organization join request routes
+	protected.POST("/organizations/:id/join-requests", orgController.CreateJoinRequest)
+	protected.DELETE("/organizations/:id/join-requests/me", orgController.CancelJoinRequest)
+	protected.POST("/organizations/:id/join-requests/me/accept", orgController.AcceptInvitation)
+	protected.GET("/organizations/:id/join-requests", orgController.GetJoinRequests)
+	protected.POST("/organizations/:id/join-requests/:requestId/approve", orgController.ApproveJoinRequest)
+	protected.POST("/organizations/:id/join-requests/:requestId/reject", orgController.RejectJoinRequest)
+
+	// Organization ownership routes
+	protected.POST("/organizations/:id/transfer-ownership", orgController.TransferOwnership)
+	protected.POST("/organizations/:id/transfer-ownership/accept", orgController.AcceptOwnershipTransfer)
+	protected.DELETE("/organizations/:id/transfer-ownership", orgController.CancelOwnershipTransfer)
+
+	// Organization approval webhook routes
+	protected.GET("/organizations/:id/approval-webhook", orgController.GetApprovalWebhook)
+	protected.PUT("/organizations/:id/approval-webhook", orgController.UpdateApprovalWebhook)
+	protected.DELETE("/organizations/:id/approval-webhook", orgController.DeleteApprovalWebhook)
+
+	// Organization custom field routes
+	protected.GET("/organizations/:id/custom-fields", orgController.GetCustomFields)
+	protected.PUT("/organizations/:id/custom-fields", orgController.UpdateCustomFields)
+
+	// Organization settings history routes
+	protected.GET("/organizations/:id/settings/history", orgController.GetSettingsHistory)
+	protected.POST("/organizations/:id/settings/rollback/:version", orgController.RollbackSettings)
 
 	// Organization teams routes
 	protected.GET("/organizations/:id/teams", orgController.GetOrganizationTeams)
 
 	// Admin routes
 	admin := router.Group("")
-	admin.Use(middleware.AuthMiddleware(cfg), middleware.RoleMiddleware("admin"))
+	admin.Use(middleware.AuthMiddleware(cfg), middleware.PermissionMiddleware(models.PermPlatformListOrganizations))
 	admin.GET("/admin/organizations", orgController.ListOrganizations)
 }
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterOrganizationExportRoutes registers organization export routes
+func RegisterOrganizationExportRoutes(router *gin.RouterGroup, exportController *controllers.OrganizationExportController, cfg *config.JWTConfig) {
+	// Archives are downloaded with signed URLs, which authorize the
+	// download themselves
+	router.GET("/organizations/:id/exports/:exportId/download", exportController.DownloadExport)
+
+	// All other export routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.POST("/organizations/:id/export", exportController.CreateExport)
+	protected.GET("/organizations/:id/exports", exportController.GetExports)
+	protected.GET("/organizations/:id/exports/:exportId", exportController.GetExport)
+}
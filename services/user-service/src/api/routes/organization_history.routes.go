@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterOrganizationHistoryRoutes registers organization history routes
+func RegisterOrganizationHistoryRoutes(router *gin.RouterGroup, historyController *controllers.OrganizationHistoryController, cfg *config.JWTConfig) {
+	// All history routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/organizations/:id/history", historyController.GetHistory)
+	protected.GET("/organizations/:id/history/state", historyController.GetState)
+}
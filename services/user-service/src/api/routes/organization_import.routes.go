@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterOrganizationImportRoutes registers organization import routes
+func RegisterOrganizationImportRoutes(router *gin.RouterGroup, importController *controllers.OrganizationImportController, cfg *config.JWTConfig) {
+	// All import routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.POST("/organizations/import", importController.ImportOrganization)
+}
@@ -19,5 +19,16 @@ func RegisterProfileRoutes(router *gin.RouterGroup, profileController *controlle
 	protected.GET("/profile/teams", profileController.GetUserTeams)
 	protected.GET("/profile/organizations", profileController.GetUserOrganizations)
 	protected.GET("/profile/full", profileController.GetFullProfile)
+	protected.PUT("/profile/favorites", profileController.UpdateFavorites)
+	protected.POST("/profile/presence/heartbeat", profileController.Heartbeat)
+	protected.GET("/profile/sessions", profileController.GetSessions)
+	protected.DELETE("/profile/sessions/:id", profileController.RevokeSession)
+	protected.GET("/profile/email-change", profileController.GetEmailChange)
+	protected.POST("/profile/email-change", profileController.RequestEmailChange)
+	protected.DELETE("/profile/email-change", profileController.CancelEmailChange)
+	protected.GET("/profile/permissions", profileController.GetPermissions)
+	protected.GET("/profile/preferences", profileController.GetUserPreferences)
 	protected.PUT("/profile/preferences", profileController.UpdateUserPreferences)
+	protected.GET("/profile/onboarding", profileController.GetOnboarding)
+	protected.PATCH("/profile/onboarding", profileController.UpdateOnboarding)
 }
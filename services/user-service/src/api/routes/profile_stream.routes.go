@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterProfileStreamRoutes registers profile stream routes
+func RegisterProfileStreamRoutes(router *gin.RouterGroup, streamController *controllers.ProfileStreamController, cfg *config.JWTConfig) {
+	// The stream requires authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/stream", streamController.Stream)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterRealtimeRoutes registers realtime routes
+func RegisterRealtimeRoutes(router *gin.RouterGroup, realtimeController *controllers.RealtimeController, corsPolicy *middleware.CORSPolicy, cfg *config.JWTConfig) {
+	// Connections require authentication from an allowed origin
+	protected := router.Group("")
+	protected.Use(corsPolicy.WebSocketOrigin())
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/ws", realtimeController.Connect)
+}
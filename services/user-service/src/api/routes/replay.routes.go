@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+)
+
+// RegisterReplayRoutes registers the admin event replay routes
+func RegisterReplayRoutes(router *gin.RouterGroup, replayController *controllers.ReplayController, cfg *config.JWTConfig) {
+	// Replays are restricted to platform admins
+	admin := router.Group("/admin/events/replay")
+	admin.Use(middleware.AuthMiddleware(cfg), middleware.PermissionMiddleware(models.PermPlatformReplayEvents))
+
+	admin.POST("", replayController.StartReplay)
+	admin.GET("/:jobId", replayController.GetReplay)
+	admin.POST("/:jobId/cancel", replayController.CancelReplay)
+}
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterSCIMRoutes registers the SCIM 2.0 provisioning routes
+func RegisterSCIMRoutes(router *gin.RouterGroup, scimController *controllers.SCIMController, authenticator middleware.SCIMTokenAuthenticator) {
+	// All SCIM routes require an organization SCIM token
+	protected := router.Group("")
+	protected.Use(middleware.SCIMAuthMiddleware(authenticator))
+
+	// User resources
+	protected.GET("/Users", scimController.ListUsers)
+	protected.POST("/Users", scimController.CreateUser)
+	protected.GET("/Users/:id", scimController.GetUser)
+	protected.PUT("/Users/:id", scimController.ReplaceUser)
+	protected.PATCH("/Users/:id", scimController.PatchUser)
+	protected.DELETE("/Users/:id", scimController.DeleteUser)
+
+	// Group resources
+	protected.GET("/Groups", scimController.ListGroups)
+	protected.POST("/Groups", scimController.CreateGroup)
+	protected.GET("/Groups/:id", scimController.GetGroup)
+	protected.PUT("/Groups/:id", scimController.ReplaceGroup)
+	protected.PATCH("/Groups/:id", scimController.PatchGroup)
+	protected.DELETE("/Groups/:id", scimController.DeleteGroup)
+}
+
+// RegisterSCIMTokenRoutes registers the SCIM token management routes
+func RegisterSCIMTokenRoutes(router *gin.RouterGroup, scimController *controllers.SCIMController, cfg *config.JWTConfig) {
+	// All SCIM token routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/organizations/:id/scim/tokens", scimController.ListTokens)
+	protected.POST("/organizations/:id/scim/tokens", scimController.CreateToken)
+	protected.DELETE("/organizations/:id/scim/tokens/:tokenId", scimController.RevokeToken)
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+)
+
+// RegisterSignupReviewRoutes registers the signup review queue routes
+func RegisterSignupReviewRoutes(router *gin.RouterGroup, reviewController *controllers.SignupReviewController, cfg *config.JWTConfig) {
+	// The review queue is restricted to platform admins
+	admin := router.Group("/admin/signup-reviews")
+	admin.Use(middleware.AuthMiddleware(cfg), middleware.PermissionMiddleware(models.PermPlatformReviewSignups))
+
+	admin.GET("", reviewController.GetQueue)
+	admin.POST("/:userId/approve", reviewController.ApproveSignup)
+	admin.POST("/:userId/reject", reviewController.RejectSignup)
+}
@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/slo"
+)
+
+// MetricsWriter writes metrics in the Prometheus text format
+type MetricsWriter interface {
+	WritePrometheus(w io.Writer) error
+}
+
+// RegisterMetricsRoutes registers the Prometheus metrics route, serving the
+// SLO metrics followed by those of extra
+func RegisterMetricsRoutes(router *gin.RouterGroup, extra ...MetricsWriter) {
+	router.GET("", func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Status(http.StatusOK)
+
+		if err := slo.Default().WritePrometheus(c.Writer); err != nil {
+			log.Error().Err(err).Msg("Failed to write metrics")
+			return
+		}
+		for _, metrics := range extra {
+			if err := metrics.WritePrometheus(c.Writer); err != nil {
+				log.Error().Err(err).Msg("Failed to write metrics")
+				return
+			}
+		}
+	})
+}
+
+// RegisterSLORoutes registers the SLO summary routes
+func RegisterSLORoutes(router *gin.RouterGroup, cfg *config.JWTConfig) {
+	// SLO routes are restricted to admins
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+	protected.Use(middleware.PermissionMiddleware(models.PermPlatformViewSLO))
+
+	protected.GET("/slo", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"service":   "user-service",
+			"timestamp": time.Now(),
+			"slos":      slo.Default().Summaries(),
+		})
+	})
+}
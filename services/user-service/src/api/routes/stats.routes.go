@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+)
+
+// RegisterStatsRoutes registers usage statistics routes
+func RegisterStatsRoutes(router *gin.RouterGroup, statsController *controllers.StatsController, cfg *config.JWTConfig) {
+	// All stats routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/organizations/:id/stats", statsController.GetOrganizationStats)
+
+	// Platform stats are restricted to platform admins
+	admin := router.Group("/admin/stats")
+	admin.Use(middleware.AuthMiddleware(cfg), middleware.PermissionMiddleware(models.PermPlatformViewStats))
+
+	admin.GET("/users", statsController.GetUserStats)
+	admin.GET("/organizations", statsController.GetOrganizationSizeStats)
+	admin.GET("/signups", statsController.GetSignupStats)
+	admin.GET("/events", statsController.GetEventStats)
+}
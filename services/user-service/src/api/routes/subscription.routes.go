@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterSubscriptionRoutes registers organization subscription routes
+func RegisterSubscriptionRoutes(router *gin.RouterGroup, subscriptionController *controllers.SubscriptionController, cfg *config.JWTConfig) {
+	// All subscription routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/organizations/:id/subscription", subscriptionController.GetSubscription)
+	protected.PUT("/organizations/:id/members/:memberId/seat", subscriptionController.AssignSeat)
+	protected.DELETE("/organizations/:id/members/:memberId/seat", subscriptionController.UnassignSeat)
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterSyncRoutes registers differential sync routes
+func RegisterSyncRoutes(router *gin.RouterGroup, syncController *controllers.SyncController, cfg *config.JWTConfig) {
+	// Sync returns what the caller can see, so it requires authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/sync", syncController.Sync)
+}
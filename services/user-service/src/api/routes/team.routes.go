@@ -18,13 +18,37 @@ func RegisterTeamRoutes(router *gin.RouterGroup, teamController *controllers.Tea
 	protected.POST("/teams", teamController.CreateTeam)
 	protected.GET("/teams/:id", teamController.GetTeam)
 	protected.PUT("/teams/:id", teamController.UpdateTeam)
+	protected.PATCH("/teams/:id", teamController.PatchTeam)
 	protected.DELETE("/teams/:id", teamController.DeleteTeam)
+	protected.POST("/teams/:id/tags", teamController.AddTeamTags)
+	protected.DELETE("/teams/:id/tags/:tag", teamController.RemoveTeamTag)
+	protected.PUT("/teams/:id/settings", teamController.UpdateTeamSettings)
+	protected.POST("/teams/:id/archive", teamController.ArchiveTeam)
+	protected.POST("/teams/:id/unarchive", teamController.UnarchiveTeam)
+
+	// Team hierarchy routes
+	protected.GET("/teams/:id/children", teamController.GetTeamChildren)
+	protected.PUT("/teams/:id/parent", teamController.MoveTeam)
 
 	// Team members routes
 	protected.GET("/teams/:id/members", teamController.GetTeamMembers)
 	protected.POST("/teams/:id/members", teamController.AddTeamMember)
+	protected.POST("/teams/:id/members/bulk", teamController.BulkTeamMembers)
 	protected.PUT("/teams/:id/members/:memberId", teamController.UpdateTeamMember)
 	protected.DELETE("/teams/:id/members/:memberId", teamController.RemoveTeamMember)
+	protected.POST("/teams/:id/groups", teamController.AddTeamGroup)
+
+	// Team join routes
+	protected.POST("/teams/:id/join", teamController.JoinTeam)
+	protected.DELETE("/teams/:id/join-requests/me", teamController.CancelTeamJoinRequest)
+	protected.GET("/teams/:id/join-requests", teamController.GetTeamJoinRequests)
+	protected.POST("/teams/:id/join-requests/:requestId/approve", teamController.ApproveTeamJoinRequest)
+	protected.POST("/teams/:id/join-requests/:requestId/reject", teamController.RejectTeamJoinRequest)
+
+	// Team ownership routes
+	protected.POST("/teams/:id/transfer-ownership", teamController.TransferOwnership)
+	protected.POST("/teams/:id/transfer-ownership/accept", teamController.AcceptOwnershipTransfer)
+	protected.DELETE("/teams/:id/transfer-ownership", teamController.CancelOwnershipTransfer)
 
 	// Organization teams
 	protected.GET("/organizations/:orgId/teams", teamController.GetOrganizationTeams)
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterTeamTemplateRoutes registers team template routes
+func RegisterTeamTemplateRoutes(router *gin.RouterGroup, templateController *controllers.TeamTemplateController, cfg *config.JWTConfig) {
+	// All team template routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/organizations/:id/team-templates", templateController.GetTemplates)
+	protected.POST("/organizations/:id/team-templates", templateController.CreateTemplate)
+	protected.GET("/organizations/:id/team-templates/:templateId", templateController.GetTemplate)
+	protected.PUT("/organizations/:id/team-templates/:templateId", templateController.UpdateTemplate)
+	protected.DELETE("/organizations/:id/team-templates/:templateId", templateController.DeleteTemplate)
+	protected.POST("/organizations/:id/teams/from-template/:templateId", templateController.CreateTeamFromTemplate)
+}
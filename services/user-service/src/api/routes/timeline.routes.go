@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterTimelineRoutes registers organization timeline routes
+func RegisterTimelineRoutes(router *gin.RouterGroup, timelineController *controllers.TimelineController, cfg *config.JWTConfig) {
+	// All timeline routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/organizations/:id/timeline", timelineController.GetTimeline)
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/your-username/slido-clone/user-service/api/controllers"
 	"github.com/your-username/slido-clone/user-service/api/middleware"
 	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
 )
 
 // RegisterUserRoutes registers user routes
@@ -29,7 +30,12 @@ func RegisterUserRoutes(router *gin.RouterGroup, userController *controllers.Use
 	protected.POST("/users", userController.CreateUser)
 	protected.GET("/users/:id", userController.GetUser)
 	protected.PUT("/users/:id", userController.UpdateUser)
+	protected.PATCH("/users/:id", userController.PatchUser)
 	protected.DELETE("/users/:id", userController.DeleteUser)
 	protected.POST("/users/:id/deactivate", userController.DeactivateUser)
 	protected.POST("/users/:id/activate", userController.ActivateUser)
+
+	// Soft deleted users are restored and purged by platform admins
+	protected.POST("/users/:id/restore", middleware.PermissionMiddleware(models.PermPlatformRestoreUsers), userController.RestoreUser)
+	protected.DELETE("/users/:id/purge", middleware.PermissionMiddleware(models.PermPlatformPurgeUsers), userController.PurgeUser)
 }
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+)
+
+// RegisterVerificationRoutes registers organization verification routes and
+// their review queue
+func RegisterVerificationRoutes(router *gin.RouterGroup, verificationController *controllers.VerificationController, cfg *config.JWTConfig) {
+	// All verification routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/organizations/:id/verification", verificationController.GetVerification)
+	protected.POST("/organizations/:id/verification", verificationController.RequestVerification)
+	protected.DELETE("/organizations/:id/verification", verificationController.CancelVerification)
+	protected.POST("/organizations/:id/verification/check", verificationController.CheckDomain)
+
+	// The review queue is restricted to platform admins
+	admin := router.Group("/admin/organization-verifications")
+	admin.Use(middleware.AuthMiddleware(cfg), middleware.PermissionMiddleware(models.PermPlatformVerifyOrganizations))
+
+	admin.GET("", verificationController.GetQueue)
+	admin.GET("/:requestId", verificationController.GetRequest)
+	admin.POST("/:requestId/approve", verificationController.Approve)
+	admin.POST("/:requestId/reject", verificationController.Reject)
+	admin.POST("/:requestId/revoke", verificationController.Revoke)
+}
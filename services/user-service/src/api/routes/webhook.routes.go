@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/middleware"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// RegisterWebhookRoutes registers organization webhook routes
+func RegisterWebhookRoutes(router *gin.RouterGroup, webhookController *controllers.WebhookController, cfg *config.JWTConfig) {
+	// All webhook routes require authentication
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg))
+
+	protected.GET("/organizations/:id/webhooks", webhookController.GetWebhooks)
+	protected.POST("/organizations/:id/webhooks", webhookController.CreateWebhook)
+	protected.GET("/organizations/:id/webhooks/:webhookId", webhookController.GetWebhook)
+	protected.PUT("/organizations/:id/webhooks/:webhookId", webhookController.UpdateWebhook)
+	protected.DELETE("/organizations/:id/webhooks/:webhookId", webhookController.DeleteWebhook)
+	protected.GET("/organizations/:id/webhooks/:webhookId/deliveries", webhookController.GetDeliveries)
+	protected.POST("/organizations/:id/webhooks/:webhookId/test", webhookController.TestWebhook)
+}
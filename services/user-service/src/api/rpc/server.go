@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/api/proto/userv1"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// APIKeyMetadata is the metadata key other services authenticate gRPC calls
+// with, matching the X-Internal-API-Key header of the internal HTTP API
+const APIKeyMetadata = "x-internal-api-key"
+
+// errorDomain identifies the service in the ErrorInfo of failed calls
+const errorDomain = "user-service"
+
+// NewServer creates a gRPC server serving the user service. Calls are logged,
+// recovered from panics and authenticated with the internal API key.
+func NewServer(cfg *config.InternalAPIConfig, userServer *UserServer) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		loggingInterceptor,
+		recoveryInterceptor,
+		authInterceptor(cfg),
+	))
+	userv1.RegisterUserServiceServer(server, userServer)
+	return server
+}
+
+// authInterceptor rejects calls without the internal API key
+func authInterceptor(cfg *config.InternalAPIConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if cfg.APIKey == "" {
+			return nil, toStatus(apperrors.Unavailable("INTERNAL_API_DISABLED", "Internal API is not configured"))
+		}
+
+		var key string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(APIKeyMetadata); len(values) > 0 {
+				key = values[0]
+			}
+		}
+		if subtle.ConstantTimeCompare([]byte(key), []byte(cfg.APIKey)) != 1 {
+			log.Debug().Str("method", info.FullMethod).Msg("Invalid internal API key")
+			return nil, toStatus(apperrors.Unauthorized("INVALID_API_KEY", "Unauthorized: invalid internal API key"))
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// recoveryInterceptor turns a panicking handler into an internal error
+func recoveryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Interface("panic", r).Str("method", info.FullMethod).Msg("Recovered from panic in gRPC handler")
+			err = status.Error(codes.Internal, "Internal server error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// loggingInterceptor logs each call with its status code and latency
+func loggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	logEvent := log.Info().
+		Str("method", info.FullMethod).
+		Str("code", status.Code(err).String()).
+		Dur("latency", time.Since(start))
+	if err != nil {
+		logEvent = logEvent.Str("error", err.Error())
+	}
+	logEvent.Msg("gRPC request")
+
+	return resp, err
+}
+
+// toStatus converts a service error to a gRPC status carrying the error code
+// as ErrorInfo reason. Errors without a domain error are reported as
+// internal errors without exposing their message.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, primitive.ErrInvalidHex):
+		err = apperrors.Validation("INVALID_ID", "invalid ID").WithCause(err)
+	case errors.Is(err, mongo.ErrNoDocuments):
+		err = apperrors.NotFound("NOT_FOUND", "resource not found").WithCause(err)
+	}
+
+	appErr := apperrors.From(err, "Internal server error")
+	st := status.New(statusCode(appErr.Kind), appErr.Message)
+	if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{Reason: appErr.Code, Domain: errorDomain}); detailErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// statusCode gets the gRPC code of an error kind
+func statusCode(kind apperrors.Kind) codes.Code {
+	switch kind {
+	case apperrors.KindValidation, apperrors.KindUnsupported:
+		return codes.InvalidArgument
+	case apperrors.KindUnauthorized:
+		return codes.Unauthenticated
+	case apperrors.KindForbidden:
+		return codes.PermissionDenied
+	case apperrors.KindNotFound:
+		return codes.NotFound
+	case apperrors.KindConflict, apperrors.KindGone, apperrors.KindPrecondition:
+		return codes.FailedPrecondition
+	case apperrors.KindUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
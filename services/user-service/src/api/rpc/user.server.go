@@ -0,0 +1,179 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/your-username/slido-clone/user-service/api/proto/userv1"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/services"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UserServer serves user and organization lookups over gRPC
+type UserServer struct {
+	userv1.UnimplementedUserServiceServer
+
+	userService       *services.UserService
+	orgService        *services.OrganizationService
+	permissionService *services.PermissionService
+	maxBatchSize      int
+}
+
+// NewUserServer creates a new user gRPC server
+func NewUserServer(userService *services.UserService, orgService *services.OrganizationService, permissionService *services.PermissionService, cfg *config.InternalAPIConfig) *UserServer {
+	return &UserServer{
+		userService:       userService,
+		orgService:        orgService,
+		permissionService: permissionService,
+		maxBatchSize:      cfg.MaxBatchSize,
+	}
+}
+
+// GetUser gets a user by user ID
+func (s *UserServer) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.User, error) {
+	if req.GetUserId() == "" {
+		return nil, toStatus(apperrors.InvalidField("userId", "user ID is required"))
+	}
+
+	user, err := s.userService.GetUserByUserID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toUser(user), nil
+}
+
+// BatchGetUsers gets users by user IDs, reporting the IDs that don't exist
+func (s *UserServer) BatchGetUsers(ctx context.Context, req *userv1.BatchGetUsersRequest) (*userv1.BatchGetUsersResponse, error) {
+	// Drop duplicate IDs so they don't count against the batch size
+	userIDs := make([]string, 0, len(req.GetUserIds()))
+	requested := make(map[string]bool)
+	for _, userID := range req.GetUserIds() {
+		if userID != "" && !requested[userID] {
+			requested[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+	if len(userIDs) > s.maxBatchSize {
+		return nil, toStatus(apperrors.InvalidField("userIds", "too many user IDs in batch"))
+	}
+
+	users, err := s.userService.GetUsersByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &userv1.BatchGetUsersResponse{
+		Users:          make([]*userv1.User, 0, len(users)),
+		MissingUserIds: []string{},
+	}
+	found := make(map[string]bool, len(users))
+	for _, user := range users {
+		found[user.UserID] = true
+		resp.Users = append(resp.Users, toUser(user))
+	}
+	for _, userID := range userIDs {
+		if !found[userID] {
+			resp.MissingUserIds = append(resp.MissingUserIds, userID)
+		}
+	}
+
+	return resp, nil
+}
+
+// GetOrganization gets an organization with its members
+func (s *UserServer) GetOrganization(ctx context.Context, req *userv1.GetOrganizationRequest) (*userv1.Organization, error) {
+	if req.GetOrganizationId() == "" {
+		return nil, toStatus(apperrors.InvalidField("organizationId", "organization ID is required"))
+	}
+
+	org, err := s.orgService.GetOrganizationByID(ctx, req.GetOrganizationId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toOrganization(org), nil
+}
+
+// CheckMembership checks a user's roles in an organization and optionally
+// one of its teams
+func (s *UserServer) CheckMembership(ctx context.Context, req *userv1.CheckMembershipRequest) (*userv1.CheckMembershipResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, toStatus(apperrors.InvalidField("userId", "user ID is required"))
+	}
+	if req.GetOrganizationId() == "" {
+		return nil, toStatus(apperrors.InvalidField("organizationId", "organization ID is required"))
+	}
+
+	// Platform roles aren't known to internal callers, so only membership
+	// permissions are resolved
+	perms, err := s.permissionService.GetPermissions(ctx, req.GetUserId(), nil, req.GetOrganizationId(), req.GetTeamId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	isMember := perms.OrganizationRole != ""
+	if req.GetTeamId() != "" {
+		isMember = isMember && perms.TeamRole != ""
+	}
+
+	return &userv1.CheckMembershipResponse{
+		IsMember:                isMember,
+		OrganizationId:          perms.OrganizationID,
+		OrganizationRole:        string(perms.OrganizationRole),
+		OrganizationPermissions: toStrings(perms.Organization),
+		TeamId:                  perms.TeamID,
+		TeamRole:                string(perms.TeamRole),
+		TeamPermissions:         toStrings(perms.Team),
+	}, nil
+}
+
+// toUser converts a user to its protobuf message
+func toUser(user *models.User) *userv1.User {
+	return &userv1.User{
+		Id:             user.ID,
+		UserId:         user.UserID,
+		Email:          user.Email,
+		FirstName:      user.FirstName,
+		LastName:       user.LastName,
+		FullName:       user.FirstName + " " + user.LastName,
+		Role:           string(user.Role),
+		Status:         string(user.Status),
+		ProfilePicture: user.ProfilePicture,
+		CreatedAt:      timestamppb.New(user.CreatedAt),
+	}
+}
+
+// toOrganization converts an organization to its protobuf message
+func toOrganization(org *models.Organization) *userv1.Organization {
+	members := make([]*userv1.OrganizationMember, 0, len(org.Members))
+	for _, member := range org.Members {
+		members = append(members, &userv1.OrganizationMember{
+			UserId:   member.UserID,
+			Role:     string(member.Role),
+			JoinedAt: timestamppb.New(member.JoinedAt),
+		})
+	}
+
+	return &userv1.Organization{
+		Id:          org.ID,
+		Name:        org.Name,
+		Description: org.Description,
+		LogoUrl:     org.LogoURL,
+		CreatedBy:   org.CreatedBy,
+		CreatedAt:   timestamppb.New(org.CreatedAt),
+		Members:     members,
+		TeamIds:     org.TeamIDs,
+	}
+}
+
+// toStrings converts permissions to strings
+func toStrings(perms []models.Permission) []string {
+	values := make([]string, 0, len(perms))
+	for _, perm := range perms {
+		values = append(values, string(perm))
+	}
+	return values
+}
@@ -1,9 +1,6 @@
 package validators
 
 import (
-	"reflect"
-	"strings"
-
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog/log"
@@ -25,13 +22,7 @@ func InitTeamValidators() {
 		registerOrgRoleValidator(v)
 
 		// Register JSON tag name if not already registered
-		v.RegisterTagNameFunc(func(fld reflect.StructField) string {
-			name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
-			if name == "-" {
-				return ""
-			}
-			return name
-		})
+		v.RegisterTagNameFunc(jsonTagName)
 
 		log.Info().Msg("Custom team validators registered")
 	} else {
@@ -1,9 +1,7 @@
 package validators
 
 import (
-	"reflect"
 	"regexp"
-	"strings"
 
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
@@ -35,13 +33,7 @@ func InitUserValidators() {
 		registerPhoneValidator(v)
 
 		// Register JSON tag name
-		v.RegisterTagNameFunc(func(fld reflect.StructField) string {
-			name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
-			if name == "-" {
-				return ""
-			}
-			return name
-		})
+		v.RegisterTagNameFunc(jsonTagName)
 
 		log.Info().Msg("Custom validators registered")
 	} else {
@@ -0,0 +1,41 @@
+package validators
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/your-username/slido-clone/user-service/models"
+)
+
+// localeTag validates that a language is a supported locale
+const localeTag = "locale"
+
+// New creates a validator that names fields by their JSON names, as the
+// binding validator does, so validation errors name the fields clients send
+func New() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(jsonTagName)
+	registerLocaleValidator(v)
+	return v
+}
+
+// registerLocaleValidator registers the locale validator
+func registerLocaleValidator(v *validator.Validate) {
+	_ = v.RegisterValidation(localeTag, func(fl validator.FieldLevel) bool {
+		return models.ValidLocale(fl.Field().String())
+	})
+}
+
+// jsonTagName gets the JSON name of a struct field, or its form name for
+// query parameters
+func jsonTagName(fld reflect.StructField) string {
+	name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+	if name == "" {
+		name = strings.SplitN(fld.Tag.Get("form"), ",", 2)[0]
+	}
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,114 @@
+// Command migrate applies the service's schema migrations, or lists them.
+//
+//	migrate [-dry-run] [-to version] [up]
+//	migrate status
+//
+// The service applies pending migrations at startup too; run this to apply
+// them ahead of a deploy, or to see what a deploy would change with -dry-run.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/migrations"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "list the pending migrations and what they would change, without applying them")
+	target := flag.Int("to", 0, "apply migrations up to this version (default: all)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [up|status]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	command := flag.Arg(0)
+	if command == "" {
+		command = "up"
+	}
+	if command != "up" && command != "status" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Printf("Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Printf("Failed to start: %v\n", err)
+		os.Exit(1)
+	}
+	logger.Init(cfg)
+
+	// Stop between migrations on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	store, err := db.Open(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Str("driver", cfg.Storage.Driver).Msg("Failed to open storage backend")
+	}
+	defer store.Close()
+
+	migrator, err := migrations.New(store, repositories.NewMigrationRepository(store), migrations.All(&cfg.Org))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid migrations")
+	}
+
+	if command == "status" {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to list migrations")
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Migration.Version, status.Migration.Name, applied)
+		}
+		w.Flush()
+		return
+	}
+
+	results, err := migrator.Up(ctx, migrations.Options{DryRun: *dryRun, Target: *target})
+	if *dryRun {
+		for _, result := range results {
+			plan := result.Plan
+			if plan == "" {
+				plan = "no plan available"
+			}
+			fmt.Printf("%s: %s\n", result.Migration.ID(), plan)
+		}
+		if len(results) == 0 && err == nil {
+			fmt.Println("No pending migrations")
+		}
+	}
+	if err != nil {
+		log.Error().Err(err).Int("applied", len(results)).Msg("Migrations stopped")
+		stop()
+		os.Exit(1)
+	}
+
+	if !*dryRun {
+		log.Info().Int("applied", len(results)).Msg("Migrations applied")
+	}
+}
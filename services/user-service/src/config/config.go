@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -11,19 +14,51 @@ import (
 
 // Config holds all configuration for the service
 type Config struct {
-	Server  ServerConfig
-	MongoDB MongoDBConfig
-	JWT     JWTConfig
-	Kafka   KafkaConfig
-	AuthSvc AuthServiceConfig
-	Logging LoggingConfig
-	CORS    CORSConfig
+	Server   ServerConfig
+	Storage  StorageConfig
+	MongoDB  MongoDBConfig
+	JWT      JWTConfig
+	Kafka    KafkaConfig
+	AuthSvc  AuthServiceConfig
+	Logging  LoggingConfig
+	CORS     CORSConfig
+	SLO      SLOConfig
+	Leader   LeaderElectionConfig
+	Internal InternalAPIConfig
+	Signup   SignupReviewConfig
+	Replay   ReplayConfig
+	Org      OrganizationConfig
+	Banner   BannerConfig
+	Flags    FeatureFlagConfig
+	Sync     SyncConfig
+	GraphQL  GraphQLConfig
+	Stats    StatsConfig
+	Presence PresenceConfig
+	Notify   NotificationConfig
+	Support  SupportConfig
+	Security SecurityConfig
+	Secrets  SecretsConfig
+	Cache    CacheConfig
+	Changes  ChangeStreamConfig
+	LDAP     LDAPConfig
+	Import   DirectoryImportConfig
+	Export   ExportConfig
+	Jobs     JobsConfig
+	Realtime RealtimeConfig
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
 	Port    string
 	GinMode string
+	// ShutdownTimeout bounds each stage of the shutdown
+	ShutdownTimeout time.Duration
+}
+
+// StorageConfig holds storage backend configuration
+type StorageConfig struct {
+	Driver string
+	Path   string
 }
 
 // MongoDBConfig holds MongoDB-related configuration
@@ -33,28 +68,130 @@ type MongoDBConfig struct {
 	Timeout     time.Duration
 	MaxPoolSize uint64
 	MinPoolSize uint64
+	// ReadPreference is the members reads go to: primary, primaryPreferred,
+	// secondary, secondaryPreferred or nearest
+	ReadPreference string
+	// PrimaryReads lists repository methods, as collection.Method, that
+	// always read from the primary; entries may be comma-separated lists
+	PrimaryReads []string
+	// Regions are the clusters of data residency regions, as region=uri
+	// entries. Organizations resident in a region keep their documents,
+	// teams and member records in a database named DBName on its cluster.
+	Regions []string
+}
+
+// RegionURIs returns the cluster URIs of the data residency regions by
+// region. Malformed entries are skipped; Validate reports them.
+func (c *MongoDBConfig) RegionURIs() map[string]string {
+	uris := make(map[string]string)
+	for _, entry := range c.Regions {
+		region, uri, ok := strings.Cut(entry, "=")
+		region, uri = strings.TrimSpace(region), strings.TrimSpace(uri)
+		if ok && region != "" && uri != "" {
+			uris[region] = uri
+		}
+	}
+	return uris
+}
+
+// RegionNames returns the names of the data residency regions, sorted
+func (c *MongoDBConfig) RegionNames() []string {
+	uris := c.RegionURIs()
+	names := make([]string, 0, len(uris))
+	for name := range uris {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // JWTConfig holds JWT validation configuration
 type JWTConfig struct {
 	Secret string
 	Issuer string
+	Claims JWTClaimsConfig
+
+	// SessionCookie is the cookie the admin UI sends its access token in,
+	// when there is no Authorization header; empty disables cookie sessions
+	SessionCookie string
+
+	// Keys holds the secret tokens are signed with and, during a rotation,
+	// the secrets it replaced
+	Keys *KeySet `json:"-"`
+}
+
+// SigningSecret gets the secret tokens are signed with
+func (c *JWTConfig) SigningSecret() string {
+	if c.Keys == nil {
+		return c.Secret
+	}
+	return c.Keys.Current()
+}
+
+// VerificationSecrets gets the secrets tokens may be signed with
+func (c *JWTConfig) VerificationSecrets() []string {
+	if c.Keys == nil {
+		return []string{c.Secret}
+	}
+	return c.Keys.Verification()
+}
+
+// JWTClaimsConfig maps token claims to user attributes with JSONPath-style
+// paths, so tokens of different identity providers can be consumed
+type JWTClaimsConfig struct {
+	Subject         string
+	Email           string
+	Role            string
+	Roles           []string
+	TokenType       string
+	AccessTokenType string
 }
 
 // KafkaConfig holds Kafka-related configuration
 type KafkaConfig struct {
-	Brokers         []string
-	GroupID         string
-	ClientID        string
-	AutoOffsetReset string
-	Topics          KafkaTopics
+	Brokers            []string
+	GroupID            string
+	ClientID           string
+	AutoOffsetReset    string
+	EventFormat        string
+	ProcessedEventTTL  time.Duration
+	MaxHandlerAttempts int
+	Workers            int
+	MaxInFlight        int
+	DrainTimeout       time.Duration
+	// StreamGroupID prefixes the consumer group each instance reads the
+	// events of profile streams with; every instance reads every event
+	StreamGroupID string
+	// PublishQueueSize bounds the events waiting to be published in the
+	// background
+	PublishQueueSize int
+	// PublishMaxAttempts is the number of attempts to publish an event,
+	// including the first one
+	PublishMaxAttempts int
+	Security           KafkaSecurityConfig
+	Topics             KafkaTopics
+}
+
+// KafkaSecurityConfig holds Kafka authentication and TLS configuration
+type KafkaSecurityConfig struct {
+	Protocol      string
+	SASLMechanism string
+	SASLUsername  string
+	SASLPassword  string
+	CAFile        string
+	CertFile      string
+	KeyFile       string
+	KeyPassword   string
+	SkipVerify    bool
 }
 
 // KafkaTopics holds Kafka topic names
 type KafkaTopics struct {
-	UserEvents string
-	AuthEvents string
-	TeamEvents string
+	UserEvents    string
+	AuthEvents    string
+	TeamEvents    string
+	BillingEvents string
+	Notifications string
 }
 
 // AuthServiceConfig holds Auth Service connection details
@@ -65,11 +202,249 @@ type AuthServiceConfig struct {
 // LoggingConfig holds logging configuration
 type LoggingConfig struct {
 	Level string
+	// Format is json for log aggregation, or console for human-readable logs
+	Format string
+	// DebugSampleRate logs 1 in every n debug and trace messages; 0 or 1
+	// logs all of them
+	DebugSampleRate int
+	// RedactFields redacts the values of logged fields whose names contain
+	// any of these, case-insensitively, at any depth
+	RedactFields []string
 }
 
 // CORSConfig holds CORS configuration
 type CORSConfig struct {
-	AllowedOrigins string
+	AllowedOrigins   []string
+	AllowCredentials bool
+	// PublicCredentials allows credentials on public endpoints, which are
+	// served without them by default
+	PublicCredentials bool
+}
+
+// SLOConfig holds service level objective configuration
+type SLOConfig struct {
+	AvailabilityObjective float64
+	PublishObjective      float64
+	ConsumerLagObjective  float64
+	ConsumerLagThreshold  time.Duration
+}
+
+// LeaderElectionConfig holds leader election configuration for singleton
+// workers and scheduled jobs. Leases are kept in MongoDB, or in Redis when
+// Backend is redis.
+type LeaderElectionConfig struct {
+	Enabled       bool
+	InstanceID    string
+	LeaseTTL      time.Duration
+	RenewInterval time.Duration
+	Backend       string
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+}
+
+// Lease backends
+const (
+	LeaseBackendMongoDB = "mongodb"
+	LeaseBackendRedis   = "redis"
+)
+
+// InternalAPIConfig holds configuration of the service-to-service API
+type InternalAPIConfig struct {
+	APIKey       string
+	GRPCPort     string
+	MaxBatchSize int
+}
+
+// SignupReviewConfig holds the risk heuristics that hold new signups for review
+type SignupReviewConfig struct {
+	Enabled           bool
+	DisposableDomains []string
+	BurstThreshold    int
+	BurstWindow       time.Duration
+}
+
+// OrganizationConfig holds how organization members are stored, how often
+// expired members are removed and how long members may be inactive before an
+// access review lists them
+type OrganizationConfig struct {
+	MemberStorage         string
+	MemberQuota           int
+	MemberStorageInterval time.Duration
+	MemberExpiryInterval  time.Duration
+	AccessReviewWindow    time.Duration
+	// Residencies are the data residency regions organizations can be
+	// created in, the regions of MongoDBConfig
+	Residencies []string
+}
+
+// BannerConfig holds how often the platform banner is reloaded
+type BannerConfig struct {
+	RefreshInterval time.Duration
+}
+
+// FeatureFlagConfig holds how often feature flags are reloaded
+type FeatureFlagConfig struct {
+	RefreshInterval time.Duration
+}
+
+// SyncConfig holds the limits of differential sync
+type SyncConfig struct {
+	TombstoneTTL time.Duration
+	MaxItems     int
+}
+
+// GraphQLConfig holds the query limits and batching window of the GraphQL
+// endpoint
+type GraphQLConfig struct {
+	MaxDepth       int
+	MaxParallelism int
+	BatchWait      time.Duration
+}
+
+// StatsConfig holds how long statistics are cached and the window in which
+// a member counts as active
+type StatsConfig struct {
+	CacheTTL     time.Duration
+	ActiveWindow time.Duration
+}
+
+// PresenceConfig holds where presence is kept, how long a heartbeat keeps a
+// user online and how often their last seen time is saved
+type PresenceConfig struct {
+	RedisAddr        string
+	RedisPassword    string
+	RedisDB          int
+	TTL              time.Duration
+	LastSeenInterval time.Duration
+}
+
+// CacheConfig holds the shared Redis cache whose user, team and
+// organization entries are invalidated as they change. Without a Redis
+// address nothing is invalidated.
+type CacheConfig struct {
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	KeyPrefix     string
+}
+
+// ChangeStreamConfig holds whether MongoDB change streams are watched for
+// user, team and organization writes, and how long to wait before a failed
+// stream is reopened
+type ChangeStreamConfig struct {
+	Enabled    bool
+	RetryDelay time.Duration
+}
+
+// LDAPConfig holds how often the LDAP sync worker checks for due syncs and
+// the limits of each sync: the timeout of each directory request, the page
+// size of searches and the most entries a sync reads
+type LDAPConfig struct {
+	SyncInterval time.Duration
+	Timeout      time.Duration
+	PageSize     int
+	MaxEntries   int
+}
+
+// DirectoryImportConfig holds the API base URLs of the directories members
+// are imported from and the most members an import reads
+type DirectoryImportConfig struct {
+	GoogleAPIURL string
+	SlackAPIURL  string
+	MaxMembers   int
+}
+
+// ExportConfig holds how organization export archives are kept and
+// downloaded: archives are removed after Retention, checked every
+// CleanupInterval, and can't grow past MaxSize bytes. Download URLs are
+// signed with SigningSecret, which is kept apart from the JWT secret, and
+// stay valid for URLTTL.
+type ExportConfig struct {
+	SigningSecret   string
+	URLTTL          time.Duration
+	Retention       time.Duration
+	MaxSize         int
+	CleanupInterval time.Duration
+}
+
+// JobsConfig holds the scheduled jobs: whether this replica runs them, how
+// long a run may take and how long runs are kept in the job history.
+// Soft-deleted users are purged on UserPurgeSchedule once they've been
+// deleted for UserPurgeAfter; 0 disables the purge.
+type JobsConfig struct {
+	Enabled           bool
+	Timeout           time.Duration
+	HistoryRetention  time.Duration
+	UserPurgeSchedule string
+	UserPurgeAfter    time.Duration
+}
+
+// RealtimeConfig holds the dashboard websocket connections: how many
+// messages may wait to be sent to a connection before it is dropped as too
+// slow, how long a write may take, how often idle connections are pinged and
+// how many connections a user may have open on an instance.
+type RealtimeConfig struct {
+	SendBuffer            int
+	WriteTimeout          time.Duration
+	PingInterval          time.Duration
+	MaxConnectionsPerUser int
+}
+
+// NotificationConfig holds when daily notification digests are sent: the
+// hour of the day in each user's timezone, checked every DigestInterval. The
+// action links of notifications point to the web app at AppURL. In-app
+// inbox notifications are kept for InboxRetention, and inbox streams check
+// for notifications added by other instances every InboxPollInterval.
+type NotificationConfig struct {
+	DigestHour        int
+	DigestInterval    time.Duration
+	AppURL            string
+	InboxRetention    time.Duration
+	InboxPollInterval time.Duration
+}
+
+// SupportConfig holds how long admin impersonation sessions last
+type SupportConfig struct {
+	ImpersonationTTL time.Duration
+}
+
+// SecurityConfig holds the security headers of responses and whether cookie
+// sessions are protected against CSRF
+type SecurityConfig struct {
+	HSTSMaxAge     time.Duration
+	FrameOptions   string
+	ReferrerPolicy string
+	CSRF           bool
+}
+
+// SecretsConfig selects the backend secret settings are read from, and how
+// often they are refreshed
+type SecretsConfig struct {
+	// Provider is env, file, vault or aws
+	Provider string
+	// Path is the directory of secret files, the secret's path in Vault or
+	// its ID in AWS Secrets Manager
+	Path            string
+	RefreshInterval time.Duration
+	// RotationGrace is how long tokens signed with a replaced JWT secret
+	// stay valid
+	RotationGrace time.Duration
+
+	VaultAddr  string
+	VaultToken string
+	VaultMount string
+
+	AWSRegion          string
+	AWSAccessKeyID     string
+	AWSSecretAccessKey string
+	AWSSessionToken    string
+}
+
+// ReplayConfig holds the limits of admin event replays
+type ReplayConfig struct {
+	RateLimit int
+	BatchSize int
 }
 
 // LoadConfig loads configuration from environment variables
@@ -85,44 +460,239 @@ func LoadConfig() (*Config, error) {
 	// Set defaults
 	setDefaults()
 
-	// Return the config
+	// Read the config file, if there is one
+	if err := readConfigFile(); err != nil {
+		return nil, err
+	}
+	cfg := readConfig()
+
+	// Secrets from a secrets backend override the environment
+	if err := loadSecrets(cfg); err != nil {
+		return nil, err
+	}
+	cfg.JWT.Keys = NewKeySet(cfg.JWT.Secret)
+
+	return cfg, nil
+}
+
+// readConfig reads the configuration from viper
+func readConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:    viper.GetString("PORT"),
-			GinMode: viper.GetString("GIN_MODE"),
+			Port:            viper.GetString("PORT"),
+			GinMode:         viper.GetString("GIN_MODE"),
+			ShutdownTimeout: time.Duration(viper.GetInt("SHUTDOWN_TIMEOUT")) * time.Second,
+		},
+		Storage: StorageConfig{
+			Driver: viper.GetString("STORAGE_DRIVER"),
+			Path:   viper.GetString("STORAGE_PATH"),
 		},
 		MongoDB: MongoDBConfig{
-			URI:         viper.GetString("MONGO_URI"),
-			DBName:      viper.GetString("MONGO_DB_NAME"),
-			Timeout:     time.Duration(viper.GetInt("MONGO_TIMEOUT")) * time.Second,
-			MaxPoolSize: viper.GetUint64("MONGO_MAX_POOL_SIZE"),
-			MinPoolSize: viper.GetUint64("MONGO_MIN_POOL_SIZE"),
+			URI:            viper.GetString("MONGO_URI"),
+			DBName:         viper.GetString("MONGO_DB_NAME"),
+			Timeout:        time.Duration(viper.GetInt("MONGO_TIMEOUT")) * time.Second,
+			MaxPoolSize:    viper.GetUint64("MONGO_MAX_POOL_SIZE"),
+			MinPoolSize:    viper.GetUint64("MONGO_MIN_POOL_SIZE"),
+			ReadPreference: viper.GetString("MONGO_READ_PREFERENCE"),
+			PrimaryReads:   viper.GetStringSlice("MONGO_PRIMARY_READS"),
+			Regions:        viper.GetStringSlice("MONGO_REGIONS"),
 		},
 		JWT: JWTConfig{
 			Secret: viper.GetString("JWT_SECRET"),
 			Issuer: viper.GetString("JWT_ISSUER"),
+			Claims: JWTClaimsConfig{
+				Subject:         viper.GetString("JWT_CLAIM_SUBJECT"),
+				Email:           viper.GetString("JWT_CLAIM_EMAIL"),
+				Role:            viper.GetString("JWT_CLAIM_ROLE"),
+				Roles:           viper.GetStringSlice("JWT_CLAIM_ROLES"),
+				TokenType:       viper.GetString("JWT_CLAIM_TOKEN_TYPE"),
+				AccessTokenType: viper.GetString("JWT_ACCESS_TOKEN_TYPE"),
+			},
+			SessionCookie: viper.GetString("JWT_SESSION_COOKIE"),
 		},
 		Kafka: KafkaConfig{
-			Brokers:         viper.GetStringSlice("KAFKA_BROKERS"),
-			GroupID:         viper.GetString("KAFKA_GROUP_ID"),
-			ClientID:        viper.GetString("KAFKA_CLIENT_ID"),
-			AutoOffsetReset: viper.GetString("KAFKA_AUTO_OFFSET_RESET"),
+			Brokers:            viper.GetStringSlice("KAFKA_BROKERS"),
+			GroupID:            viper.GetString("KAFKA_GROUP_ID"),
+			StreamGroupID:      viper.GetString("KAFKA_STREAM_GROUP_ID"),
+			ClientID:           viper.GetString("KAFKA_CLIENT_ID"),
+			AutoOffsetReset:    viper.GetString("KAFKA_AUTO_OFFSET_RESET"),
+			EventFormat:        viper.GetString("KAFKA_EVENT_FORMAT"),
+			ProcessedEventTTL:  time.Duration(viper.GetInt("KAFKA_PROCESSED_EVENT_TTL")) * time.Hour,
+			MaxHandlerAttempts: viper.GetInt("KAFKA_MAX_HANDLER_ATTEMPTS"),
+			Workers:            viper.GetInt("KAFKA_CONSUMER_WORKERS"),
+			MaxInFlight:        viper.GetInt("KAFKA_CONSUMER_MAX_IN_FLIGHT"),
+			DrainTimeout:       time.Duration(viper.GetInt("KAFKA_CONSUMER_DRAIN_TIMEOUT")) * time.Second,
+			PublishQueueSize:   viper.GetInt("KAFKA_PUBLISH_QUEUE_SIZE"),
+			PublishMaxAttempts: viper.GetInt("KAFKA_PUBLISH_MAX_ATTEMPTS"),
+			Security: KafkaSecurityConfig{
+				Protocol:      viper.GetString("KAFKA_SECURITY_PROTOCOL"),
+				SASLMechanism: viper.GetString("KAFKA_SASL_MECHANISM"),
+				SASLUsername:  viper.GetString("KAFKA_SASL_USERNAME"),
+				SASLPassword:  viper.GetString("KAFKA_SASL_PASSWORD"),
+				CAFile:        viper.GetString("KAFKA_TLS_CA_FILE"),
+				CertFile:      viper.GetString("KAFKA_TLS_CERT_FILE"),
+				KeyFile:       viper.GetString("KAFKA_TLS_KEY_FILE"),
+				KeyPassword:   viper.GetString("KAFKA_TLS_KEY_PASSWORD"),
+				SkipVerify:    viper.GetBool("KAFKA_TLS_SKIP_VERIFY"),
+			},
 			Topics: KafkaTopics{
-				UserEvents: viper.GetString("KAFKA_TOPIC_USER_EVENTS"),
-				AuthEvents: viper.GetString("KAFKA_TOPIC_AUTH_EVENTS"),
-				TeamEvents: viper.GetString("KAFKA_TOPIC_TEAM_EVENTS"),
+				UserEvents:    viper.GetString("KAFKA_TOPIC_USER_EVENTS"),
+				AuthEvents:    viper.GetString("KAFKA_TOPIC_AUTH_EVENTS"),
+				TeamEvents:    viper.GetString("KAFKA_TOPIC_TEAM_EVENTS"),
+				BillingEvents: viper.GetString("KAFKA_TOPIC_BILLING_EVENTS"),
+				Notifications: viper.GetString("KAFKA_TOPIC_NOTIFICATIONS"),
 			},
 		},
 		AuthSvc: AuthServiceConfig{
 			URL: viper.GetString("AUTH_SERVICE_URL"),
 		},
 		Logging: LoggingConfig{
-			Level: viper.GetString("LOG_LEVEL"),
+			Level:           viper.GetString("LOG_LEVEL"),
+			Format:          viper.GetString("LOG_FORMAT"),
+			DebugSampleRate: viper.GetInt("LOG_DEBUG_SAMPLE_RATE"),
+			RedactFields:    viper.GetStringSlice("LOG_REDACT_FIELDS"),
 		},
 		CORS: CORSConfig{
-			AllowedOrigins: viper.GetString("CORS_ALLOWED_ORIGINS"),
+			AllowedOrigins:    viper.GetStringSlice("CORS_ALLOWED_ORIGINS"),
+			AllowCredentials:  viper.GetBool("CORS_ALLOW_CREDENTIALS"),
+			PublicCredentials: viper.GetBool("CORS_PUBLIC_CREDENTIALS"),
+		},
+		SLO: SLOConfig{
+			AvailabilityObjective: viper.GetFloat64("SLO_AVAILABILITY_OBJECTIVE"),
+			PublishObjective:      viper.GetFloat64("SLO_EVENT_PUBLISH_OBJECTIVE"),
+			ConsumerLagObjective:  viper.GetFloat64("SLO_CONSUMER_LAG_OBJECTIVE"),
+			ConsumerLagThreshold:  time.Duration(viper.GetInt("SLO_CONSUMER_LAG_THRESHOLD")) * time.Second,
+		},
+		Leader: LeaderElectionConfig{
+			Enabled:       viper.GetBool("LEADER_ELECTION_ENABLED"),
+			InstanceID:    viper.GetString("INSTANCE_ID"),
+			LeaseTTL:      time.Duration(viper.GetInt("LEADER_LEASE_TTL")) * time.Second,
+			RenewInterval: time.Duration(viper.GetInt("LEADER_RENEW_INTERVAL")) * time.Second,
+			Backend:       viper.GetString("LEADER_BACKEND"),
+			RedisAddr:     viper.GetString("LEADER_REDIS_ADDR"),
+			RedisPassword: viper.GetString("LEADER_REDIS_PASSWORD"),
+			RedisDB:       viper.GetInt("LEADER_REDIS_DB"),
+		},
+		Internal: InternalAPIConfig{
+			APIKey:       viper.GetString("INTERNAL_API_KEY"),
+			GRPCPort:     viper.GetString("GRPC_PORT"),
+			MaxBatchSize: viper.GetInt("GRPC_MAX_BATCH_SIZE"),
+		},
+		Signup: SignupReviewConfig{
+			Enabled:           viper.GetBool("SIGNUP_REVIEW_ENABLED"),
+			DisposableDomains: viper.GetStringSlice("SIGNUP_DISPOSABLE_DOMAINS"),
+			BurstThreshold:    viper.GetInt("SIGNUP_BURST_THRESHOLD"),
+			BurstWindow:       time.Duration(viper.GetInt("SIGNUP_BURST_WINDOW")) * time.Second,
+		},
+		Org: OrganizationConfig{
+			MemberStorage:         viper.GetString("ORGANIZATION_MEMBER_STORAGE"),
+			MemberQuota:           viper.GetInt("ORGANIZATION_MEMBER_QUOTA"),
+			MemberStorageInterval: time.Duration(viper.GetInt("ORGANIZATION_MEMBER_STORAGE_INTERVAL")) * time.Second,
+			MemberExpiryInterval:  time.Duration(viper.GetInt("ORGANIZATION_MEMBER_EXPIRY_INTERVAL")) * time.Second,
+			AccessReviewWindow:    time.Duration(viper.GetInt("ORGANIZATION_ACCESS_REVIEW_DAYS")) * 24 * time.Hour,
+			Residencies:           (&MongoDBConfig{Regions: viper.GetStringSlice("MONGO_REGIONS")}).RegionNames(),
+		},
+		Banner: BannerConfig{
+			RefreshInterval: time.Duration(viper.GetInt("BANNER_REFRESH_INTERVAL")) * time.Second,
+		},
+		Flags: FeatureFlagConfig{
+			RefreshInterval: time.Duration(viper.GetInt("FEATURE_FLAGS_REFRESH_INTERVAL")) * time.Second,
+		},
+		Sync: SyncConfig{
+			TombstoneTTL: time.Duration(viper.GetInt("SYNC_TOMBSTONE_TTL")) * time.Hour,
+			MaxItems:     viper.GetInt("SYNC_MAX_ITEMS"),
+		},
+		GraphQL: GraphQLConfig{
+			MaxDepth:       viper.GetInt("GRAPHQL_MAX_DEPTH"),
+			MaxParallelism: viper.GetInt("GRAPHQL_MAX_PARALLELISM"),
+			BatchWait:      time.Duration(viper.GetInt("GRAPHQL_BATCH_WAIT")) * time.Millisecond,
 		},
-	}, nil
+		Stats: StatsConfig{
+			CacheTTL:     time.Duration(viper.GetInt("STATS_CACHE_TTL")) * time.Second,
+			ActiveWindow: time.Duration(viper.GetInt("STATS_ACTIVE_WINDOW")) * 24 * time.Hour,
+		},
+		Replay: ReplayConfig{
+			RateLimit: viper.GetInt("REPLAY_RATE_LIMIT"),
+			BatchSize: viper.GetInt("REPLAY_BATCH_SIZE"),
+		},
+		Presence: PresenceConfig{
+			RedisAddr:        viper.GetString("PRESENCE_REDIS_ADDR"),
+			RedisPassword:    viper.GetString("PRESENCE_REDIS_PASSWORD"),
+			RedisDB:          viper.GetInt("PRESENCE_REDIS_DB"),
+			TTL:              time.Duration(viper.GetInt("PRESENCE_TTL")) * time.Second,
+			LastSeenInterval: time.Duration(viper.GetInt("PRESENCE_LAST_SEEN_INTERVAL")) * time.Second,
+		},
+		Cache: CacheConfig{
+			RedisAddr:     viper.GetString("CACHE_REDIS_ADDR"),
+			RedisPassword: viper.GetString("CACHE_REDIS_PASSWORD"),
+			RedisDB:       viper.GetInt("CACHE_REDIS_DB"),
+			KeyPrefix:     viper.GetString("CACHE_KEY_PREFIX"),
+		},
+		Changes: ChangeStreamConfig{
+			Enabled:    viper.GetBool("CHANGE_STREAMS_ENABLED"),
+			RetryDelay: time.Duration(viper.GetInt("CHANGE_STREAMS_RETRY_DELAY")) * time.Second,
+		},
+		LDAP: LDAPConfig{
+			SyncInterval: time.Duration(viper.GetInt("LDAP_SYNC_INTERVAL")) * time.Second,
+			Timeout:      time.Duration(viper.GetInt("LDAP_SYNC_TIMEOUT")) * time.Second,
+			PageSize:     viper.GetInt("LDAP_SYNC_PAGE_SIZE"),
+			MaxEntries:   viper.GetInt("LDAP_SYNC_MAX_ENTRIES"),
+		},
+		Import: DirectoryImportConfig{
+			GoogleAPIURL: viper.GetString("DIRECTORY_IMPORT_GOOGLE_URL"),
+			SlackAPIURL:  viper.GetString("DIRECTORY_IMPORT_SLACK_URL"),
+			MaxMembers:   viper.GetInt("DIRECTORY_IMPORT_MAX_MEMBERS"),
+		},
+		Export: ExportConfig{
+			SigningSecret:   viper.GetString("EXPORT_SIGNING_SECRET"),
+			URLTTL:          time.Duration(viper.GetInt("EXPORT_URL_TTL")) * time.Second,
+			Retention:       time.Duration(viper.GetInt("EXPORT_RETENTION")) * time.Second,
+			MaxSize:         viper.GetInt("EXPORT_MAX_SIZE"),
+			CleanupInterval: time.Duration(viper.GetInt("EXPORT_CLEANUP_INTERVAL")) * time.Second,
+		},
+		Jobs: JobsConfig{
+			Enabled:           viper.GetBool("JOBS_ENABLED"),
+			Timeout:           time.Duration(viper.GetInt("JOBS_TIMEOUT")) * time.Second,
+			HistoryRetention:  time.Duration(viper.GetInt("JOBS_HISTORY_RETENTION")) * time.Second,
+			UserPurgeSchedule: viper.GetString("JOBS_USER_PURGE_SCHEDULE"),
+			UserPurgeAfter:    time.Duration(viper.GetInt("JOBS_USER_PURGE_AFTER")) * time.Second,
+		},
+		Realtime: RealtimeConfig{
+			SendBuffer:            viper.GetInt("REALTIME_SEND_BUFFER"),
+			WriteTimeout:          time.Duration(viper.GetInt("REALTIME_WRITE_TIMEOUT")) * time.Second,
+			PingInterval:          time.Duration(viper.GetInt("REALTIME_PING_INTERVAL")) * time.Second,
+			MaxConnectionsPerUser: viper.GetInt("REALTIME_MAX_CONNECTIONS_PER_USER"),
+		},
+		Notify: NotificationConfig{
+			DigestHour:        viper.GetInt("NOTIFICATION_DIGEST_HOUR"),
+			DigestInterval:    time.Duration(viper.GetInt("NOTIFICATION_DIGEST_INTERVAL")) * time.Second,
+			AppURL:            viper.GetString("NOTIFICATION_APP_URL"),
+			InboxRetention:    time.Duration(viper.GetInt("NOTIFICATION_INBOX_RETENTION")) * time.Second,
+			InboxPollInterval: time.Duration(viper.GetInt("NOTIFICATION_INBOX_POLL_INTERVAL")) * time.Second,
+		},
+		Support: SupportConfig{
+			ImpersonationTTL: time.Duration(viper.GetInt("IMPERSONATION_TTL")) * time.Second,
+		},
+		Security: SecurityConfig{
+			HSTSMaxAge:     time.Duration(viper.GetInt("SECURITY_HSTS_MAX_AGE")) * time.Second,
+			FrameOptions:   viper.GetString("SECURITY_FRAME_OPTIONS"),
+			ReferrerPolicy: viper.GetString("SECURITY_REFERRER_POLICY"),
+			CSRF:           viper.GetBool("CSRF_ENABLED"),
+		},
+		Secrets: SecretsConfig{
+			Provider:           viper.GetString("SECRETS_PROVIDER"),
+			Path:               viper.GetString("SECRETS_PATH"),
+			RefreshInterval:    time.Duration(viper.GetInt("SECRETS_REFRESH_INTERVAL")) * time.Second,
+			RotationGrace:      time.Duration(viper.GetInt("SECRETS_ROTATION_GRACE")) * time.Second,
+			VaultAddr:          viper.GetString("VAULT_ADDR"),
+			VaultToken:         viper.GetString("VAULT_TOKEN"),
+			VaultMount:         viper.GetString("VAULT_MOUNT"),
+			AWSRegion:          viper.GetString("AWS_REGION"),
+			AWSAccessKeyID:     viper.GetString("AWS_ACCESS_KEY_ID"),
+			AWSSecretAccessKey: viper.GetString("AWS_SECRET_ACCESS_KEY"),
+			AWSSessionToken:    viper.GetString("AWS_SESSION_TOKEN"),
+		},
+	}
 }
 
 // setDefaults sets default values for configuration
@@ -130,6 +700,11 @@ func setDefaults() {
 	// Server defaults
 	viper.SetDefault("PORT", "8001")
 	viper.SetDefault("GIN_MODE", "debug")
+	viper.SetDefault("SHUTDOWN_TIMEOUT", 10)
+
+	// Storage defaults
+	viper.SetDefault("STORAGE_DRIVER", "mongodb")
+	viper.SetDefault("STORAGE_PATH", "")
 
 	// MongoDB defaults
 	viper.SetDefault("MONGO_URI", "mongodb://localhost:27017")
@@ -137,30 +712,220 @@ func setDefaults() {
 	viper.SetDefault("MONGO_TIMEOUT", 10)
 	viper.SetDefault("MONGO_MAX_POOL_SIZE", 100)
 	viper.SetDefault("MONGO_MIN_POOL_SIZE", 5)
+	viper.SetDefault("MONGO_READ_PREFERENCE", "primary")
+	viper.SetDefault("MONGO_PRIMARY_READS", []string{})
+	viper.SetDefault("MONGO_REGIONS", []string{})
 
 	// JWT defaults
-	viper.SetDefault("JWT_SECRET", "your_jwt_secret_here")
+	viper.SetDefault("JWT_SECRET", defaultJWTSecret)
 	viper.SetDefault("JWT_ISSUER", "slido-clone-auth")
 
+	// JWT claims mapping defaults match tokens of the Auth Service
+	viper.SetDefault("JWT_CLAIM_SUBJECT", "sub")
+	viper.SetDefault("JWT_CLAIM_EMAIL", "email")
+	viper.SetDefault("JWT_CLAIM_ROLE", "role")
+	viper.SetDefault("JWT_CLAIM_ROLES", []string{"roles"})
+	viper.SetDefault("JWT_CLAIM_TOKEN_TYPE", "type")
+	viper.SetDefault("JWT_ACCESS_TOKEN_TYPE", "access")
+	viper.SetDefault("JWT_SESSION_COOKIE", "")
+
 	// Kafka defaults
 	viper.SetDefault("KAFKA_BROKERS", []string{"localhost:9092"})
 	viper.SetDefault("KAFKA_GROUP_ID", "user-service-group")
+	viper.SetDefault("KAFKA_STREAM_GROUP_ID", "user-service-stream")
 	viper.SetDefault("KAFKA_CLIENT_ID", "user-service")
 	viper.SetDefault("KAFKA_AUTO_OFFSET_RESET", "earliest")
+	viper.SetDefault("KAFKA_EVENT_FORMAT", "legacy")
+	viper.SetDefault("KAFKA_PROCESSED_EVENT_TTL", 168)
+	viper.SetDefault("KAFKA_MAX_HANDLER_ATTEMPTS", 3)
+	viper.SetDefault("KAFKA_CONSUMER_WORKERS", 4)
+	viper.SetDefault("KAFKA_CONSUMER_MAX_IN_FLIGHT", 64)
+	viper.SetDefault("KAFKA_CONSUMER_DRAIN_TIMEOUT", 20)
+	viper.SetDefault("KAFKA_PUBLISH_QUEUE_SIZE", 1000)
+	viper.SetDefault("KAFKA_PUBLISH_MAX_ATTEMPTS", 3)
+
+	// Kafka security defaults; plaintext suits a local broker
+	viper.SetDefault("KAFKA_SECURITY_PROTOCOL", "plaintext")
+	viper.SetDefault("KAFKA_SASL_MECHANISM", "SCRAM-SHA-512")
+	viper.SetDefault("KAFKA_SASL_USERNAME", "")
+	viper.SetDefault("KAFKA_SASL_PASSWORD", "")
+	viper.SetDefault("KAFKA_TLS_CA_FILE", "")
+	viper.SetDefault("KAFKA_TLS_CERT_FILE", "")
+	viper.SetDefault("KAFKA_TLS_KEY_FILE", "")
+	viper.SetDefault("KAFKA_TLS_KEY_PASSWORD", "")
+	viper.SetDefault("KAFKA_TLS_SKIP_VERIFY", false)
 
 	// Kafka topic defaults
 	viper.SetDefault("KAFKA_TOPIC_USER_EVENTS", "user.events")
 	viper.SetDefault("KAFKA_TOPIC_AUTH_EVENTS", "auth.events")
 	viper.SetDefault("KAFKA_TOPIC_TEAM_EVENTS", "team.events")
+	viper.SetDefault("KAFKA_TOPIC_BILLING_EVENTS", "billing.events")
+	viper.SetDefault("KAFKA_TOPIC_NOTIFICATIONS", "notification.events")
 
 	// Auth Service defaults
 	viper.SetDefault("AUTH_SERVICE_URL", "http://localhost:3001")
 
 	// Logging defaults
 	viper.SetDefault("LOG_LEVEL", "debug")
+	viper.SetDefault("LOG_FORMAT", "console")
+	viper.SetDefault("LOG_DEBUG_SAMPLE_RATE", 1)
+	viper.SetDefault("LOG_REDACT_FIELDS", []string{"email", "phone"})
+
+	// CORS defaults; any origin is allowed outside release mode, while
+	// release deployments must list their origins
+	if viper.GetString("GIN_MODE") == "release" {
+		viper.SetDefault("CORS_ALLOWED_ORIGINS", []string{})
+	} else {
+		viper.SetDefault("CORS_ALLOWED_ORIGINS", []string{"*"})
+	}
+	viper.SetDefault("CORS_ALLOW_CREDENTIALS", true)
+	viper.SetDefault("CORS_PUBLIC_CREDENTIALS", false)
+
+	// SLO defaults
+	viper.SetDefault("SLO_AVAILABILITY_OBJECTIVE", 0.999)
+	viper.SetDefault("SLO_EVENT_PUBLISH_OBJECTIVE", 0.999)
+	viper.SetDefault("SLO_CONSUMER_LAG_OBJECTIVE", 0.99)
+	viper.SetDefault("SLO_CONSUMER_LAG_THRESHOLD", 30)
+
+	// Leader election defaults
+	viper.SetDefault("LEADER_ELECTION_ENABLED", true)
+	viper.SetDefault("INSTANCE_ID", "")
+	viper.SetDefault("LEADER_LEASE_TTL", 15)
+	viper.SetDefault("LEADER_RENEW_INTERVAL", 5)
+	viper.SetDefault("LEADER_BACKEND", LeaseBackendMongoDB)
+	viper.SetDefault("LEADER_REDIS_ADDR", "localhost:6379")
+	viper.SetDefault("LEADER_REDIS_PASSWORD", "")
+	viper.SetDefault("LEADER_REDIS_DB", 0)
+
+	// Internal API defaults; an empty key disables the internal API
+	viper.SetDefault("INTERNAL_API_KEY", "")
+
+	// gRPC defaults; an empty port disables the gRPC server
+	viper.SetDefault("GRPC_PORT", "9001")
+	viper.SetDefault("GRPC_MAX_BATCH_SIZE", 100)
+
+	// Signup review defaults; disposable domains extend the built-in list
+	viper.SetDefault("SIGNUP_REVIEW_ENABLED", true)
+	viper.SetDefault("SIGNUP_DISPOSABLE_DOMAINS", []string{})
+	viper.SetDefault("SIGNUP_BURST_THRESHOLD", 5)
+	viper.SetDefault("SIGNUP_BURST_WINDOW", 3600)
+
+	// Organization defaults; organizations that embed more members than the
+	// quota are moved to the members collection
+	viper.SetDefault("ORGANIZATION_MEMBER_STORAGE", "collection")
+	viper.SetDefault("ORGANIZATION_MEMBER_QUOTA", 1000)
+	viper.SetDefault("ORGANIZATION_MEMBER_STORAGE_INTERVAL", 3600)
+	viper.SetDefault("ORGANIZATION_MEMBER_EXPIRY_INTERVAL", 300)
+	viper.SetDefault("ORGANIZATION_ACCESS_REVIEW_DAYS", 90)
+
+	// Banner defaults
+	viper.SetDefault("BANNER_REFRESH_INTERVAL", 15)
+
+	// Feature flag defaults
+	viper.SetDefault("FEATURE_FLAGS_REFRESH_INTERVAL", 15)
+
+	// Sync defaults; the tombstone TTL is in hours and bounds how old a sync
+	// cursor may be
+	viper.SetDefault("SYNC_TOMBSTONE_TTL", 720)
+	viper.SetDefault("SYNC_MAX_ITEMS", 500)
+
+	// GraphQL defaults; the batch wait is in milliseconds and bounds how long
+	// a lookup waits for others to batch with
+	viper.SetDefault("GRAPHQL_MAX_DEPTH", 8)
+	viper.SetDefault("GRAPHQL_MAX_PARALLELISM", 100)
+	viper.SetDefault("GRAPHQL_BATCH_WAIT", 2)
+
+	// Stats defaults; the active window is in days since a member's last login
+	viper.SetDefault("STATS_CACHE_TTL", 300)
+	viper.SetDefault("STATS_ACTIVE_WINDOW", 30)
+
+	// Replay defaults; the rate limit is in events per second
+	viper.SetDefault("REPLAY_RATE_LIMIT", 100)
+	viper.SetDefault("REPLAY_BATCH_SIZE", 100)
+
+	// Presence defaults; without a Redis address presence is kept in memory
+	viper.SetDefault("PRESENCE_REDIS_ADDR", "")
+	viper.SetDefault("PRESENCE_REDIS_PASSWORD", "")
+	viper.SetDefault("PRESENCE_REDIS_DB", 0)
+	viper.SetDefault("PRESENCE_TTL", 90)
+	viper.SetDefault("PRESENCE_LAST_SEEN_INTERVAL", 60)
+
+	// Cache defaults; without a Redis address nothing is invalidated
+	viper.SetDefault("CACHE_REDIS_ADDR", "")
+	viper.SetDefault("CACHE_REDIS_PASSWORD", "")
+	viper.SetDefault("CACHE_REDIS_DB", 0)
+	viper.SetDefault("CACHE_KEY_PREFIX", "user-service:")
 
-	// CORS defaults
-	viper.SetDefault("CORS_ALLOWED_ORIGINS", "*")
+	// Change stream defaults; change streams need a replica set, so they
+	// are off unless enabled
+	viper.SetDefault("CHANGE_STREAMS_ENABLED", false)
+	viper.SetDefault("CHANGE_STREAMS_RETRY_DELAY", 5)
+
+	// LDAP sync defaults; due syncs are checked every 5 minutes and read at
+	// most 50000 entries, 500 per page
+	viper.SetDefault("LDAP_SYNC_INTERVAL", 300)
+	viper.SetDefault("LDAP_SYNC_TIMEOUT", 30)
+	viper.SetDefault("LDAP_SYNC_PAGE_SIZE", 500)
+	viper.SetDefault("LDAP_SYNC_MAX_ENTRIES", 50000)
+
+	// Directory import defaults; imports read at most 5000 members
+	viper.SetDefault("DIRECTORY_IMPORT_GOOGLE_URL", "https://admin.googleapis.com/admin/directory/v1")
+	viper.SetDefault("DIRECTORY_IMPORT_SLACK_URL", "https://slack.com/api")
+	viper.SetDefault("DIRECTORY_IMPORT_MAX_MEMBERS", 5000)
+
+	// Export defaults; download URLs stay valid for 15 minutes and archives,
+	// which must fit in a MongoDB document, are kept for 7 days and checked
+	// hourly
+	viper.SetDefault("EXPORT_SIGNING_SECRET", defaultExportSigningSecret)
+	viper.SetDefault("EXPORT_URL_TTL", 900)
+	viper.SetDefault("EXPORT_RETENTION", 604800)
+	viper.SetDefault("EXPORT_MAX_SIZE", 15<<20)
+	viper.SetDefault("EXPORT_CLEANUP_INTERVAL", 3600)
+
+	// Jobs defaults; runs may take an hour and are kept for 30 days, and the
+	// purge of soft-deleted users, which runs daily at 3:00 UTC, is disabled
+	viper.SetDefault("JOBS_ENABLED", true)
+	viper.SetDefault("JOBS_TIMEOUT", 3600)
+	viper.SetDefault("JOBS_HISTORY_RETENTION", 2592000)
+	viper.SetDefault("JOBS_USER_PURGE_SCHEDULE", "0 3 * * *")
+	viper.SetDefault("JOBS_USER_PURGE_AFTER", 0)
+
+	// Realtime defaults; a connection 64 messages behind is dropped
+	viper.SetDefault("REALTIME_SEND_BUFFER", 64)
+	viper.SetDefault("REALTIME_WRITE_TIMEOUT", 10)
+	viper.SetDefault("REALTIME_PING_INTERVAL", 30)
+	viper.SetDefault("REALTIME_MAX_CONNECTIONS_PER_USER", 10)
+
+	// Notification defaults; digests go out at 8:00 in each user's timezone
+	viper.SetDefault("NOTIFICATION_DIGEST_HOUR", 8)
+	viper.SetDefault("NOTIFICATION_DIGEST_INTERVAL", 300)
+	viper.SetDefault("NOTIFICATION_APP_URL", "http://localhost:3000")
+	viper.SetDefault("NOTIFICATION_INBOX_RETENTION", 7776000)
+	viper.SetDefault("NOTIFICATION_INBOX_POLL_INTERVAL", 5)
+
+	// Support defaults; impersonation sessions last 15 minutes
+	viper.SetDefault("IMPERSONATION_TTL", 900)
+
+	// Security defaults; browsers keep to HTTPS for a year
+	viper.SetDefault("SECURITY_HSTS_MAX_AGE", 31536000)
+	viper.SetDefault("SECURITY_FRAME_OPTIONS", "DENY")
+	viper.SetDefault("SECURITY_REFERRER_POLICY", "strict-origin-when-cross-origin")
+	viper.SetDefault("CSRF_ENABLED", false)
+
+	// Secrets defaults; secrets are read from the environment unless a
+	// backend is set, and refreshed every 5 minutes. Tokens signed with a
+	// replaced JWT secret stay valid for an hour.
+	viper.SetDefault("SECRETS_PROVIDER", SecretsProviderEnv)
+	viper.SetDefault("SECRETS_PATH", "")
+	viper.SetDefault("SECRETS_REFRESH_INTERVAL", 300)
+	viper.SetDefault("SECRETS_ROTATION_GRACE", 3600)
+	viper.SetDefault("VAULT_ADDR", "")
+	viper.SetDefault("VAULT_TOKEN", "")
+	viper.SetDefault("VAULT_MOUNT", "secret")
+	viper.SetDefault("AWS_REGION", "")
+	viper.SetDefault("AWS_ACCESS_KEY_ID", "")
+	viper.SetDefault("AWS_SECRET_ACCESS_KEY", "")
+	viper.SetDefault("AWS_SESSION_TOKEN", "")
 }
 
 // String returns a string representation of the config
@@ -169,53 +934,364 @@ func (c *Config) String() string {
 Server:
   Port: %s
   GinMode: %s
+  ShutdownTimeout: %v
+Storage:
+  Driver: %s
+  Path: %s
 MongoDB:
   URI: %s
   DBName: %s
   Timeout: %v
   MaxPoolSize: %d
   MinPoolSize: %d
+  ReadPreference: %s
+  PrimaryReads: %v
+  Regions: %v
 JWT:
   Secret: %s
   Issuer: %s
+  Claims:
+    Subject: %s
+    Email: %s
+    Role: %s
+    Roles: %v
+    TokenType: %s
+    AccessTokenType: %s
+  SessionCookie: %s
 Kafka:
   Brokers: %v
   GroupID: %s
+  StreamGroupID: %s
   ClientID: %s
   AutoOffsetReset: %s
+  EventFormat: %s
+  ProcessedEventTTL: %v
+  MaxHandlerAttempts: %d
+  Workers: %d
+  MaxInFlight: %d
+  DrainTimeout: %v
+  PublishQueueSize: %d
+  PublishMaxAttempts: %d
+  Security:
+    Protocol: %s
+    SASLMechanism: %s
+    SASLUsername: %s
+    SASLPassword: %s
+    CAFile: %s
+    CertFile: %s
+    KeyFile: %s
+    KeyPassword: %s
+    SkipVerify: %t
   Topics:
     UserEvents: %s
     AuthEvents: %s
     TeamEvents: %s
+    BillingEvents: %s
+    Notifications: %s
 AuthService:
   URL: %s
 Logging:
   Level: %s
+  Format: %s
+  DebugSampleRate: %d
+  RedactFields: %v
 CORS:
-  AllowedOrigins: %s
+  AllowedOrigins: %v
+  AllowCredentials: %t
+  PublicCredentials: %t
+SLO:
+  AvailabilityObjective: %g
+  PublishObjective: %g
+  ConsumerLagObjective: %g
+  ConsumerLagThreshold: %v
+Leader:
+  Enabled: %t
+  InstanceID: %s
+  LeaseTTL: %v
+  RenewInterval: %v
+  Backend: %s
+  RedisAddr: %s
+  RedisPassword: %s
+  RedisDB: %d
+Internal:
+  APIKey: %s
+  GRPCPort: %s
+  MaxBatchSize: %d
+Signup:
+  Enabled: %t
+  DisposableDomains: %v
+  BurstThreshold: %d
+  BurstWindow: %v
+Organization:
+  MemberStorage: %s
+  MemberQuota: %d
+  MemberStorageInterval: %v
+  MemberExpiryInterval: %v
+  AccessReviewWindow: %v
+  Residencies: %v
+Banner:
+  RefreshInterval: %v
+Flags:
+  RefreshInterval: %v
+Sync:
+  TombstoneTTL: %v
+  MaxItems: %d
+GraphQL:
+  MaxDepth: %d
+  MaxParallelism: %d
+  BatchWait: %v
+Stats:
+  CacheTTL: %v
+  ActiveWindow: %v
+Replay:
+  RateLimit: %d
+  BatchSize: %d
+Presence:
+  RedisAddr: %s
+  RedisPassword: %s
+  RedisDB: %d
+  TTL: %v
+  LastSeenInterval: %v
+Cache:
+  RedisAddr: %s
+  RedisPassword: %s
+  RedisDB: %d
+  KeyPrefix: %s
+Changes:
+  Enabled: %t
+  RetryDelay: %v
+LDAP:
+  SyncInterval: %v
+  Timeout: %v
+  PageSize: %d
+  MaxEntries: %d
+Import:
+  GoogleAPIURL: %s
+  SlackAPIURL: %s
+  MaxMembers: %d
+Export:
+  SigningSecret: %s
+  URLTTL: %v
+  Retention: %v
+  MaxSize: %d
+  CleanupInterval: %v
+Jobs:
+  Enabled: %t
+  Timeout: %v
+  HistoryRetention: %v
+  UserPurgeSchedule: %s
+  UserPurgeAfter: %v
+Realtime:
+  SendBuffer: %d
+  WriteTimeout: %v
+  PingInterval: %v
+  MaxConnectionsPerUser: %d
+Notify:
+  DigestHour: %d
+  DigestInterval: %v
+  AppURL: %s
+  InboxRetention: %v
+  InboxPollInterval: %v
+Support:
+  ImpersonationTTL: %v
+Security:
+  HSTSMaxAge: %v
+  FrameOptions: %s
+  ReferrerPolicy: %s
+  CSRF: %t
+Secrets:
+  Provider: %s
+  Path: %s
+  RefreshInterval: %v
+  RotationGrace: %v
+  VaultAddr: %s
+  VaultToken: %s
+  VaultMount: %s
+  AWSRegion: %s
+  AWSAccessKeyID: %s
+  AWSSecretAccessKey: %s
 `,
 		c.Server.Port,
 		c.Server.GinMode,
+		c.Server.ShutdownTimeout,
+		c.Storage.Driver,
+		c.Storage.Path,
 		c.MongoDB.URI,
 		c.MongoDB.DBName,
 		c.MongoDB.Timeout,
 		c.MongoDB.MaxPoolSize,
 		c.MongoDB.MinPoolSize,
+		c.MongoDB.ReadPreference,
+		c.MongoDB.PrimaryReads,
+		c.MongoDB.Regions,
 		maskString(c.JWT.Secret),
 		c.JWT.Issuer,
+		c.JWT.Claims.Subject,
+		c.JWT.Claims.Email,
+		c.JWT.Claims.Role,
+		c.JWT.Claims.Roles,
+		c.JWT.Claims.TokenType,
+		c.JWT.Claims.AccessTokenType,
+		c.JWT.SessionCookie,
 		c.Kafka.Brokers,
 		c.Kafka.GroupID,
+		c.Kafka.StreamGroupID,
 		c.Kafka.ClientID,
 		c.Kafka.AutoOffsetReset,
+		c.Kafka.EventFormat,
+		c.Kafka.ProcessedEventTTL,
+		c.Kafka.MaxHandlerAttempts,
+		c.Kafka.Workers,
+		c.Kafka.MaxInFlight,
+		c.Kafka.DrainTimeout,
+		c.Kafka.PublishQueueSize,
+		c.Kafka.PublishMaxAttempts,
+		c.Kafka.Security.Protocol,
+		c.Kafka.Security.SASLMechanism,
+		c.Kafka.Security.SASLUsername,
+		maskString(c.Kafka.Security.SASLPassword),
+		c.Kafka.Security.CAFile,
+		c.Kafka.Security.CertFile,
+		c.Kafka.Security.KeyFile,
+		maskString(c.Kafka.Security.KeyPassword),
+		c.Kafka.Security.SkipVerify,
 		c.Kafka.Topics.UserEvents,
 		c.Kafka.Topics.AuthEvents,
 		c.Kafka.Topics.TeamEvents,
+		c.Kafka.Topics.BillingEvents,
+		c.Kafka.Topics.Notifications,
 		c.AuthSvc.URL,
 		c.Logging.Level,
+		c.Logging.Format,
+		c.Logging.DebugSampleRate,
+		c.Logging.RedactFields,
 		c.CORS.AllowedOrigins,
+		c.CORS.AllowCredentials,
+		c.CORS.PublicCredentials,
+		c.SLO.AvailabilityObjective,
+		c.SLO.PublishObjective,
+		c.SLO.ConsumerLagObjective,
+		c.SLO.ConsumerLagThreshold,
+		c.Leader.Enabled,
+		c.Leader.InstanceID,
+		c.Leader.LeaseTTL,
+		c.Leader.RenewInterval,
+		c.Leader.Backend,
+		c.Leader.RedisAddr,
+		maskString(c.Leader.RedisPassword),
+		c.Leader.RedisDB,
+		maskString(c.Internal.APIKey),
+		c.Internal.GRPCPort,
+		c.Internal.MaxBatchSize,
+		c.Signup.Enabled,
+		c.Signup.DisposableDomains,
+		c.Signup.BurstThreshold,
+		c.Signup.BurstWindow,
+		c.Org.MemberStorage,
+		c.Org.MemberQuota,
+		c.Org.MemberStorageInterval,
+		c.Org.MemberExpiryInterval,
+		c.Org.AccessReviewWindow,
+		c.Org.Residencies,
+		c.Banner.RefreshInterval,
+		c.Flags.RefreshInterval,
+		c.Sync.TombstoneTTL,
+		c.Sync.MaxItems,
+		c.GraphQL.MaxDepth,
+		c.GraphQL.MaxParallelism,
+		c.GraphQL.BatchWait,
+		c.Stats.CacheTTL,
+		c.Stats.ActiveWindow,
+		c.Replay.RateLimit,
+		c.Replay.BatchSize,
+		c.Presence.RedisAddr,
+		maskString(c.Presence.RedisPassword),
+		c.Presence.RedisDB,
+		c.Presence.TTL,
+		c.Presence.LastSeenInterval,
+		c.Cache.RedisAddr,
+		maskString(c.Cache.RedisPassword),
+		c.Cache.RedisDB,
+		c.Cache.KeyPrefix,
+		c.Changes.Enabled,
+		c.Changes.RetryDelay,
+		c.LDAP.SyncInterval,
+		c.LDAP.Timeout,
+		c.LDAP.PageSize,
+		c.LDAP.MaxEntries,
+		c.Import.GoogleAPIURL,
+		c.Import.SlackAPIURL,
+		c.Import.MaxMembers,
+		maskString(c.Export.SigningSecret),
+		c.Export.URLTTL,
+		c.Export.Retention,
+		c.Export.MaxSize,
+		c.Export.CleanupInterval,
+		c.Jobs.Enabled,
+		c.Jobs.Timeout,
+		c.Jobs.HistoryRetention,
+		c.Jobs.UserPurgeSchedule,
+		c.Jobs.UserPurgeAfter,
+		c.Realtime.SendBuffer,
+		c.Realtime.WriteTimeout,
+		c.Realtime.PingInterval,
+		c.Realtime.MaxConnectionsPerUser,
+		c.Notify.DigestHour,
+		c.Notify.DigestInterval,
+		c.Notify.AppURL,
+		c.Notify.InboxRetention,
+		c.Notify.InboxPollInterval,
+		c.Support.ImpersonationTTL,
+		c.Security.HSTSMaxAge,
+		c.Security.FrameOptions,
+		c.Security.ReferrerPolicy,
+		c.Security.CSRF,
+		c.Secrets.Provider,
+		c.Secrets.Path,
+		c.Secrets.RefreshInterval,
+		c.Secrets.RotationGrace,
+		c.Secrets.VaultAddr,
+		maskString(c.Secrets.VaultToken),
+		c.Secrets.VaultMount,
+		c.Secrets.AWSRegion,
+		c.Secrets.AWSAccessKeyID,
+		maskString(c.Secrets.AWSSecretAccessKey),
 	)
 }
 
+// Masked returns a copy of the config with its secrets masked, for display
+func (c *Config) Masked() Config {
+	masked := *c
+	masked.MongoDB.URI = maskURI(c.MongoDB.URI)
+	masked.MongoDB.Regions = make([]string, len(c.MongoDB.Regions))
+	for i, entry := range c.MongoDB.Regions {
+		region, uri, _ := strings.Cut(entry, "=")
+		masked.MongoDB.Regions[i] = region + "=" + maskURI(uri)
+	}
+	masked.JWT.Secret = maskString(c.JWT.Secret)
+	masked.Kafka.Security.SASLPassword = maskString(c.Kafka.Security.SASLPassword)
+	masked.Kafka.Security.KeyPassword = maskString(c.Kafka.Security.KeyPassword)
+	masked.Leader.RedisPassword = maskString(c.Leader.RedisPassword)
+	masked.Internal.APIKey = maskString(c.Internal.APIKey)
+	masked.Presence.RedisPassword = maskString(c.Presence.RedisPassword)
+	masked.Cache.RedisPassword = maskString(c.Cache.RedisPassword)
+	masked.Export.SigningSecret = maskString(c.Export.SigningSecret)
+	masked.Secrets.VaultToken = maskString(c.Secrets.VaultToken)
+	masked.Secrets.AWSSecretAccessKey = maskString(c.Secrets.AWSSecretAccessKey)
+	masked.Secrets.AWSSessionToken = maskString(c.Secrets.AWSSessionToken)
+	return masked
+}
+
+// maskURI masks the password of a URI; URIs that can't be parsed are masked
+// whole
+func maskURI(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return maskString(uri)
+	}
+	return parsed.Redacted()
+}
+
 // maskString masks a string for logging purposes
 func maskString(s string) string {
 	if len(s) <= 4 {
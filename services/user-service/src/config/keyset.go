@@ -0,0 +1,74 @@
+package config
+
+import (
+	"sync"
+	"time"
+)
+
+// KeySet holds the secret JWTs are signed with and, during a rotation, the
+// secrets it replaced. Replaced secrets still verify tokens until their
+// grace period ends, so tokens issued before a rotation keep working.
+type KeySet struct {
+	mu      sync.RWMutex
+	current string
+	retired []retiredKey
+}
+
+// retiredKey is a replaced secret and when it stops verifying tokens
+type retiredKey struct {
+	secret string
+	until  time.Time
+}
+
+// NewKeySet creates a key set signing with a secret
+func NewKeySet(secret string) *KeySet {
+	return &KeySet{current: secret}
+}
+
+// Current gets the secret tokens are signed with
+func (k *KeySet) Current() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.current
+}
+
+// Verification gets the secrets tokens may be signed with: the current one
+// first, then the replaced ones still in their grace period
+func (k *KeySet) Verification() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	now := time.Now()
+	secrets := []string{k.current}
+	for _, key := range k.retired {
+		if now.Before(key.until) {
+			secrets = append(secrets, key.secret)
+		}
+	}
+	return secrets
+}
+
+// Rotate makes a secret the current one. The replaced secret keeps verifying
+// tokens for the grace period.
+func (k *KeySet) Rotate(secret string, grace time.Duration) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if secret == k.current {
+		return
+	}
+
+	now := time.Now()
+	retired := k.retired[:0]
+	for _, key := range k.retired {
+		if now.Before(key.until) && key.secret != secret {
+			retired = append(retired, key)
+		}
+	}
+	if grace > 0 && k.current != "" {
+		retired = append(retired, retiredKey{secret: k.current, until: now.Add(grace)})
+	}
+	k.retired = retired
+	k.current = secret
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// ReloadableSettings are the settings that take effect without a restart
+var ReloadableSettings = []string{"LOG_LEVEL", "CORS_ALLOWED_ORIGINS", "REPLAY_RATE_LIMIT"}
+
+// readConfigFile reads the config file named by CONFIG_FILE, if any. Its
+// settings apply unless an environment variable overrides them.
+func readConfigFile() error {
+	path := viper.GetString("CONFIG_FILE")
+	if path == "" {
+		return nil
+	}
+
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Reloader reloads the settings that can change at runtime, on SIGHUP or
+// when the config file changes, and hands the new configuration to its
+// listeners. Every other setting keeps its value until a restart.
+type Reloader struct {
+	mu         sync.Mutex
+	current    *Config
+	reloadedAt time.Time
+	listeners  []func(cfg *Config)
+}
+
+// NewReloader creates a reloader of a loaded configuration
+func NewReloader(cfg *Config) *Reloader {
+	return &Reloader{
+		current:    cfg,
+		reloadedAt: time.Now(),
+	}
+}
+
+// OnReload registers a listener called with the configuration after every
+// reload. Register listeners before calling Watch.
+func (r *Reloader) OnReload(listener func(cfg *Config)) {
+	r.listeners = append(r.listeners, listener)
+}
+
+// Current gets the active configuration and when it was loaded
+func (r *Reloader) Current() (*Config, time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.current, r.reloadedAt
+}
+
+// Reload re-reads the configuration and applies its reloadable settings. An
+// invalid configuration is rejected, leaving the active one in place.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if viper.ConfigFileUsed() != "" {
+		if err := viper.ReadInConfig(); err != nil {
+			return fmt.Errorf("error reading config file %s: %w", viper.ConfigFileUsed(), err)
+		}
+	}
+	next := readConfig()
+	if _, err := zerolog.ParseLevel(next.Logging.Level); err != nil {
+		return &ValidationError{Problems: []string{fmt.Sprintf("LOG_LEVEL %q is not a log level", next.Logging.Level)}}
+	}
+
+	// The active configuration is never modified, since components hold
+	// pointers into it
+	cfg := *r.current
+	cfg.Logging.Level = next.Logging.Level
+	cfg.CORS.AllowedOrigins = next.CORS.AllowedOrigins
+	cfg.Replay.RateLimit = next.Replay.RateLimit
+	r.current = &cfg
+	r.reloadedAt = time.Now()
+
+	for _, listener := range r.listeners {
+		listener(&cfg)
+	}
+	return nil
+}
+
+// Watch reloads the configuration on SIGHUP, and whenever the config file
+// changes, until ctx is done
+func (r *Reloader) Watch(ctx context.Context) {
+	if viper.ConfigFileUsed() != "" {
+		viper.OnConfigChange(func(event fsnotify.Event) {
+			r.reload("config file changed")
+		})
+		viper.WatchConfig()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			r.reload("SIGHUP received")
+		}
+	}
+}
+
+// reload reloads the configuration, logging the outcome
+func (r *Reloader) reload(reason string) {
+	if err := r.Reload(); err != nil {
+		log.Error().Err(err).Str("reason", reason).Msg("Failed to reload configuration")
+		return
+	}
+	log.Info().Str("reason", reason).Strs("settings", ReloadableSettings).Msg("Configuration reloaded")
+}
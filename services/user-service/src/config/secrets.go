@@ -0,0 +1,183 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Secrets providers
+const (
+	// SecretsProviderEnv reads secrets from environment variables, like any
+	// other setting
+	SecretsProviderEnv = "env"
+	// SecretsProviderFile reads secrets from a directory of files named
+	// after the settings, such as a mounted Kubernetes secret
+	SecretsProviderFile = "file"
+	// SecretsProviderVault reads secrets from a Vault KV version 2 engine
+	SecretsProviderVault = "vault"
+	// SecretsProviderAWS reads secrets from AWS Secrets Manager
+	SecretsProviderAWS = "aws"
+)
+
+// secretsFetchTimeout bounds fetching the secrets from their backend
+const secretsFetchTimeout = 10 * time.Second
+
+// jwtSecretSetting is the setting holding the JWT secret
+const jwtSecretSetting = "JWT_SECRET"
+
+// secretSettings are the settings that can be read from a secrets backend,
+// by the name of their environment variable
+var secretSettings = map[string]func(cfg *Config) *string{
+	jwtSecretSetting:          func(cfg *Config) *string { return &cfg.JWT.Secret },
+	"MONGO_URI":               func(cfg *Config) *string { return &cfg.MongoDB.URI },
+	"KAFKA_SASL_PASSWORD":     func(cfg *Config) *string { return &cfg.Kafka.Security.SASLPassword },
+	"LEADER_REDIS_PASSWORD":   func(cfg *Config) *string { return &cfg.Leader.RedisPassword },
+	"INTERNAL_API_KEY":        func(cfg *Config) *string { return &cfg.Internal.APIKey },
+	"PRESENCE_REDIS_PASSWORD": func(cfg *Config) *string { return &cfg.Presence.RedisPassword },
+	"CACHE_REDIS_PASSWORD":    func(cfg *Config) *string { return &cfg.Cache.RedisPassword },
+	"EXPORT_SIGNING_SECRET":   func(cfg *Config) *string { return &cfg.Export.SigningSecret },
+}
+
+// SecretsProvider fetches secrets from a secrets backend
+type SecretsProvider interface {
+	// Fetch fetches the secrets, keyed by the name of the setting they hold.
+	// Keys that aren't secret settings are ignored.
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// NewSecretsProvider creates the provider of the configured secrets backend,
+// or nil when secrets are read from environment variables
+func NewSecretsProvider(cfg *SecretsConfig) (SecretsProvider, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "", SecretsProviderEnv:
+		return nil, nil
+	case SecretsProviderFile:
+		return &fileSecrets{dir: cfg.Path}, nil
+	case SecretsProviderVault:
+		return newVaultSecrets(cfg), nil
+	case SecretsProviderAWS:
+		return newAWSSecrets(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown secrets provider %q", cfg.Provider)
+	}
+}
+
+// loadSecrets reads the secret settings from the secrets backend, overriding
+// their environment variables
+func loadSecrets(cfg *Config) error {
+	provider, err := NewSecretsProvider(&cfg.Secrets)
+	if err != nil || provider == nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretsFetchTimeout)
+	defer cancel()
+
+	secrets, err := provider.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("error fetching secrets from %s: %w", cfg.Secrets.Provider, err)
+	}
+
+	loaded := 0
+	for name, value := range secrets {
+		if setting, ok := secretSettings[name]; ok && value != "" {
+			*setting(cfg) = value
+			loaded++
+		}
+	}
+	log.Info().Str("provider", cfg.Secrets.Provider).Int("secrets", loaded).Msg("Secrets loaded")
+	return nil
+}
+
+// WatchSecrets fetches the secrets again every refresh interval, until ctx is
+// done. A new JWT secret is rotated into the key set, so tokens signed with
+// the previous one stay valid for the rotation grace period; other secrets
+// take effect on restart. Failed fetches keep the current secrets.
+func (c *Config) WatchSecrets(ctx context.Context) {
+	provider, err := NewSecretsProvider(&c.Secrets)
+	if err != nil || provider == nil || c.Secrets.RefreshInterval <= 0 {
+		return
+	}
+
+	seen := make(map[string]string, len(secretSettings))
+	for name, setting := range secretSettings {
+		seen[name] = *setting(c)
+	}
+
+	ticker := time.NewTicker(c.Secrets.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.refreshSecrets(ctx, provider, seen)
+		}
+	}
+}
+
+// refreshSecrets fetches the secrets and applies the ones that changed
+func (c *Config) refreshSecrets(ctx context.Context, provider SecretsProvider, seen map[string]string) {
+	fetchCtx, cancel := context.WithTimeout(ctx, secretsFetchTimeout)
+	defer cancel()
+
+	secrets, err := provider.Fetch(fetchCtx)
+	if err != nil {
+		log.Error().Err(err).Str("provider", c.Secrets.Provider).Msg("Failed to refresh secrets")
+		return
+	}
+
+	for name, value := range secrets {
+		if _, ok := secretSettings[name]; !ok || value == "" || value == seen[name] {
+			continue
+		}
+		seen[name] = value
+
+		if name == jwtSecretSetting && c.JWT.Keys != nil {
+			c.JWT.Keys.Rotate(value, c.Secrets.RotationGrace)
+			log.Info().Dur("grace", c.Secrets.RotationGrace).Msg("JWT secret rotated")
+			continue
+		}
+		log.Warn().Str("secret", name).Msg("Secret changed; it takes effect on restart")
+	}
+}
+
+// fileSecrets reads secrets from a directory holding a file per setting
+type fileSecrets struct {
+	dir string
+}
+
+// Fetch implements SecretsProvider
+func (f *fileSecrets) Fetch(ctx context.Context) (map[string]string, error) {
+	secrets := make(map[string]string)
+	for name := range secretSettings {
+		data, err := os.ReadFile(filepath.Join(f.dir, name))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		secrets[name] = strings.TrimSpace(string(data))
+	}
+	return secrets, nil
+}
+
+// stringValues keeps the string values of a decoded secret
+func stringValues(values map[string]interface{}) map[string]string {
+	secrets := make(map[string]string, len(values))
+	for name, value := range values {
+		if s, ok := value.(string); ok {
+			secrets[name] = s
+		}
+	}
+	return secrets
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// vaultSecrets reads secrets from a Vault KV version 2 secrets engine
+type vaultSecrets struct {
+	addr   string
+	token  string
+	mount  string
+	path   string
+	client *http.Client
+}
+
+// newVaultSecrets creates a Vault secrets provider
+func newVaultSecrets(cfg *SecretsConfig) *vaultSecrets {
+	return &vaultSecrets{
+		addr:   strings.TrimSuffix(cfg.VaultAddr, "/"),
+		token:  cfg.VaultToken,
+		mount:  strings.Trim(cfg.VaultMount, "/"),
+		path:   strings.Trim(cfg.Path, "/"),
+		client: &http.Client{},
+	}
+}
+
+// Fetch implements SecretsProvider
+func (v *vaultSecrets) Fetch(ctx context.Context) (map[string]string, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", v.addr, v.mount, v.path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	res, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error reading secret from Vault: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("vault responded %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("error decoding Vault secret: %w", err)
+	}
+	return stringValues(secret.Data.Data), nil
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/your-username/slido-clone/user-service/pkg/jobs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// defaultJWTSecret is the placeholder JWT secret of development setups
+const defaultJWTSecret = "your_jwt_secret_here"
+
+// defaultExportSigningSecret is the placeholder export download URL signing
+// secret of development setups
+const defaultExportSigningSecret = "your_export_signing_secret_here"
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks the configuration, reporting every problem at once. In
+// release mode, settings whose defaults only suit development must be set:
+// non-default JWT and export signing secrets that differ, at least one Kafka
+// broker and, with the MongoDB
+// storage driver, a MongoDB URI and database. A secrets backend must have
+// the settings it needs.
+func (c *Config) Validate() error {
+	var problems []string
+	release := c.Server.GinMode == "release"
+	usesMongo := c.Storage.Driver == "" || c.Storage.Driver == "mongodb"
+
+	if release {
+		if c.JWT.Secret == "" || c.JWT.Secret == defaultJWTSecret {
+			problems = append(problems, "JWT_SECRET must be set to a secret other than the default")
+		}
+		if c.Export.SigningSecret == defaultExportSigningSecret || c.Export.SigningSecret == c.JWT.Secret {
+			problems = append(problems, "EXPORT_SIGNING_SECRET must be set to a secret other than the default and JWT_SECRET")
+		}
+		if !hasBroker(c.Kafka.Brokers) {
+			problems = append(problems, "KAFKA_BROKERS must list at least one broker")
+		}
+		if usesMongo && c.MongoDB.URI == "" {
+			problems = append(problems, "MONGO_URI must be set")
+		}
+		if usesMongo && c.MongoDB.DBName == "" {
+			problems = append(problems, "MONGO_DB_NAME must be set")
+		}
+	}
+
+	if c.Server.ShutdownTimeout <= 0 {
+		problems = append(problems, "SHUTDOWN_TIMEOUT must be a positive number of seconds")
+	}
+
+	if c.Flags.RefreshInterval <= 0 {
+		problems = append(problems, "FEATURE_FLAGS_REFRESH_INTERVAL must be a positive number of seconds")
+	}
+
+	if c.LDAP.SyncInterval > 0 && (c.LDAP.Timeout <= 0 || c.LDAP.PageSize < 0 || c.LDAP.MaxEntries <= 0) {
+		problems = append(problems, "LDAP_SYNC_TIMEOUT and LDAP_SYNC_MAX_ENTRIES must be positive and LDAP_SYNC_PAGE_SIZE not negative")
+	}
+
+	if c.Export.URLTTL <= 0 || c.Export.Retention <= 0 || c.Export.CleanupInterval <= 0 {
+		problems = append(problems, "EXPORT_URL_TTL, EXPORT_RETENTION and EXPORT_CLEANUP_INTERVAL must be positive numbers of seconds")
+	}
+	if c.Export.SigningSecret == "" {
+		problems = append(problems, "EXPORT_SIGNING_SECRET must be set")
+	}
+	if c.Export.MaxSize <= 0 {
+		problems = append(problems, "EXPORT_MAX_SIZE must be a positive number of bytes")
+	}
+
+	switch strings.ToLower(c.Leader.Backend) {
+	case "", LeaseBackendMongoDB:
+	case LeaseBackendRedis:
+		if c.Leader.RedisAddr == "" {
+			problems = append(problems, "LEADER_REDIS_ADDR must be set for the redis lease backend")
+		}
+	default:
+		problems = append(problems, "LEADER_BACKEND must be mongodb or redis")
+	}
+
+	if c.Jobs.Timeout <= 0 || c.Jobs.HistoryRetention <= 0 || c.Jobs.UserPurgeAfter < 0 {
+		problems = append(problems, "JOBS_TIMEOUT and JOBS_HISTORY_RETENTION must be positive numbers of seconds and JOBS_USER_PURGE_AFTER not negative")
+	}
+	if c.Jobs.UserPurgeAfter > 0 {
+		if _, err := jobs.ParseSchedule(c.Jobs.UserPurgeSchedule); err != nil {
+			problems = append(problems, "JOBS_USER_PURGE_SCHEDULE is invalid: "+err.Error())
+		}
+	}
+
+	if c.Import.GoogleAPIURL == "" || c.Import.SlackAPIURL == "" || c.Import.MaxMembers <= 0 {
+		problems = append(problems, "DIRECTORY_IMPORT_GOOGLE_URL and DIRECTORY_IMPORT_SLACK_URL must be set and DIRECTORY_IMPORT_MAX_MEMBERS positive")
+	}
+
+	if c.Realtime.SendBuffer <= 0 || c.Realtime.WriteTimeout <= 0 || c.Realtime.PingInterval <= 0 || c.Realtime.MaxConnectionsPerUser <= 0 {
+		problems = append(problems, "REALTIME_SEND_BUFFER, REALTIME_WRITE_TIMEOUT, REALTIME_PING_INTERVAL and REALTIME_MAX_CONNECTIONS_PER_USER must be positive")
+	}
+
+	if c.Kafka.StreamGroupID == "" {
+		problems = append(problems, "KAFKA_STREAM_GROUP_ID must be set")
+	}
+
+	if c.Kafka.Topics.Notifications == "" || c.Notify.AppURL == "" {
+		problems = append(problems, "KAFKA_TOPIC_NOTIFICATIONS and NOTIFICATION_APP_URL must be set")
+	}
+	if c.Notify.InboxRetention <= 0 || c.Notify.InboxPollInterval <= 0 {
+		problems = append(problems, "NOTIFICATION_INBOX_RETENTION and NOTIFICATION_INBOX_POLL_INTERVAL must be positive numbers of seconds")
+	}
+
+	switch strings.ToLower(c.Logging.Format) {
+	case "", "json", "console":
+	default:
+		problems = append(problems, "LOG_FORMAT must be json or console")
+	}
+
+	switch strings.ToLower(c.Secrets.Provider) {
+	case "", SecretsProviderEnv:
+	case SecretsProviderFile:
+		if c.Secrets.Path == "" {
+			problems = append(problems, "SECRETS_PATH must name the secrets directory")
+		}
+	case SecretsProviderVault:
+		if c.Secrets.VaultAddr == "" || c.Secrets.VaultToken == "" {
+			problems = append(problems, "VAULT_ADDR and VAULT_TOKEN must be set for the vault secrets provider")
+		}
+		if c.Secrets.Path == "" {
+			problems = append(problems, "SECRETS_PATH must name the Vault secret")
+		}
+	case SecretsProviderAWS:
+		if c.Secrets.AWSRegion == "" || c.Secrets.AWSAccessKeyID == "" || c.Secrets.AWSSecretAccessKey == "" {
+			problems = append(problems, "AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set for the aws secrets provider")
+		}
+		if c.Secrets.Path == "" {
+			problems = append(problems, "SECRETS_PATH must name the AWS secret")
+		}
+	default:
+		problems = append(problems, "SECRETS_PROVIDER must be one of env, file, vault, aws")
+	}
+
+	if usesMongo && c.MongoDB.ReadPreference != "" {
+		if _, err := readpref.ModeFromString(c.MongoDB.ReadPreference); err != nil {
+			problems = append(problems, "MONGO_READ_PREFERENCE must be one of primary, primaryPreferred, secondary, secondaryPreferred, nearest")
+		}
+	}
+
+	if c.Changes.Enabled && !usesMongo {
+		problems = append(problems, "CHANGE_STREAMS_ENABLED requires the mongodb storage driver")
+	}
+
+	if usesMongo && c.MongoDB.URI != "" {
+		if err := options.Client().ApplyURI(c.MongoDB.URI).Validate(); err != nil {
+			problems = append(problems, "MONGO_URI is invalid: "+err.Error())
+		}
+	}
+
+	if len(c.MongoDB.Regions) > 0 && !usesMongo {
+		problems = append(problems, "MONGO_REGIONS requires the mongodb storage driver")
+	}
+
+	seenRegions := make(map[string]bool)
+	for _, entry := range c.MongoDB.Regions {
+		region, uri, ok := strings.Cut(entry, "=")
+		region, uri = strings.TrimSpace(region), strings.TrimSpace(uri)
+		switch {
+		case !ok || region == "" || uri == "":
+			problems = append(problems, "MONGO_REGIONS entries must be region=uri")
+		case seenRegions[region]:
+			problems = append(problems, "MONGO_REGIONS lists region "+region+" more than once")
+		default:
+			if err := options.Client().ApplyURI(uri).Validate(); err != nil {
+				problems = append(problems, "MONGO_REGIONS URI of region "+region+" is invalid: "+err.Error())
+			}
+		}
+		seenRegions[region] = true
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// hasBroker checks if broker entries, which may be comma-separated lists,
+// name at least one broker
+func hasBroker(entries []string) bool {
+	for _, entry := range entries {
+		for _, broker := range strings.Split(entry, ",") {
+			if strings.TrimSpace(broker) != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// advisorTimeout bounds explaining a query
+const advisorTimeout = 5 * time.Second
+
+// advisorConcurrency bounds the queries being explained at once; queries
+// beyond it aren't explained
+const advisorConcurrency = 4
+
+// indexAdvisor warns about queries that scan a whole collection. While debug
+// logging is on, it explains the find and aggregate commands sent to MongoDB,
+// and logs a warning the first time a query shape is planned as a collection
+// scan. It costs nothing otherwise.
+type indexAdvisor struct {
+	mu     sync.RWMutex
+	db     *mongo.Database
+	warned sync.Map
+	slots  chan struct{}
+}
+
+// newIndexAdvisor creates an index advisor, which starts explaining queries
+// once it's given the database
+func newIndexAdvisor() *indexAdvisor {
+	return &indexAdvisor{slots: make(chan struct{}, advisorConcurrency)}
+}
+
+// setDatabase sets the database queries are explained on
+func (a *indexAdvisor) setDatabase(db *mongo.Database) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.db = db
+}
+
+// monitor gets the command monitor feeding commands to the advisor
+func (a *indexAdvisor) monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{Started: a.started}
+}
+
+// started explains a find or aggregate command in the background
+func (a *indexAdvisor) started(_ context.Context, evt *event.CommandStartedEvent) {
+	if zerolog.GlobalLevel() > zerolog.DebugLevel {
+		return
+	}
+	if evt.CommandName != "find" && evt.CommandName != "aggregate" {
+		return
+	}
+
+	a.mu.RLock()
+	db := a.db
+	a.mu.RUnlock()
+	if db == nil || db.Name() != evt.DatabaseName {
+		return
+	}
+
+	var command bson.D
+	if err := bson.Unmarshal(evt.Command, &command); err != nil {
+		return
+	}
+	collection, shape := queryShape(evt.CommandName, command)
+	key := collection + " " + shape
+	if _, seen := a.warned.Load(key); seen {
+		return
+	}
+
+	select {
+	case a.slots <- struct{}{}:
+	default:
+		return
+	}
+	go func() {
+		defer func() { <-a.slots }()
+		a.explain(db, command, collection, shape, key)
+	}()
+}
+
+// explain explains a command, warning if it scans the whole collection
+func (a *indexAdvisor) explain(db *mongo.Database, command bson.D, collection, shape, key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), advisorTimeout)
+	defer cancel()
+
+	var plan bson.M
+	explain := bson.D{{Key: "explain", Value: explainable(command)}, {Key: "verbosity", Value: "queryPlanner"}}
+	if err := db.RunCommand(ctx, explain).Decode(&plan); err != nil {
+		log.Debug().Err(err).Str("collection", collection).Msg("Failed to explain query")
+		return
+	}
+
+	if !hasCollectionScan(plan, false) {
+		return
+	}
+	if _, seen := a.warned.LoadOrStore(key, true); seen {
+		return
+	}
+	log.Warn().Str("collection", collection).Str("query", shape).
+		Msg("Query scans the whole collection; consider adding an index")
+}
+
+// explainable strips a command of the session and cluster fields the driver
+// adds, which the explain command rejects
+func explainable(command bson.D) bson.D {
+	stripped := make(bson.D, 0, len(command))
+	for _, elem := range command {
+		if strings.HasPrefix(elem.Key, "$") || elem.Key == "lsid" || elem.Key == "txnNumber" {
+			continue
+		}
+		stripped = append(stripped, elem)
+	}
+	return stripped
+}
+
+// queryShape gets the collection of a command and the shape of its query:
+// the fields it filters and sorts by, without their values
+func queryShape(commandName string, command bson.D) (string, string) {
+	var collection string
+	var filter, sortBy interface{}
+	for _, elem := range command {
+		switch elem.Key {
+		case commandName:
+			collection, _ = elem.Value.(string)
+		case "filter":
+			filter = elem.Value
+		case "sort":
+			sortBy = elem.Value
+		case "pipeline":
+			// The first stage is the one that can use an index
+			if stages, ok := elem.Value.(bson.A); ok && len(stages) > 0 {
+				if first, ok := stages[0].(bson.D); ok && len(first) > 0 && first[0].Key == "$match" {
+					filter = first[0].Value
+				}
+			}
+		}
+	}
+
+	shape := "filter=" + strings.Join(fieldPaths(filter, ""), ",")
+	if sortBy != nil {
+		shape += " sort=" + strings.Join(fieldPaths(sortBy, ""), ",")
+	}
+	return collection, shape
+}
+
+// fieldPaths lists the field paths of a filter or sort document, sorted
+func fieldPaths(doc interface{}, prefix string) []string {
+	var paths []string
+	switch d := doc.(type) {
+	case bson.D:
+		for _, elem := range d {
+			paths = append(paths, fieldPaths(elem, prefix)...)
+		}
+	case bson.E:
+		key := d.Key
+		if !strings.HasPrefix(key, "$") && prefix != "" {
+			key = prefix + "." + key
+		}
+		switch d.Value.(type) {
+		case bson.D, bson.A:
+			if strings.HasPrefix(d.Key, "$") {
+				paths = append(paths, fieldPaths(d.Value, prefix)...)
+			} else {
+				paths = append(paths, key)
+			}
+		default:
+			if !strings.HasPrefix(d.Key, "$") {
+				paths = append(paths, key)
+			}
+		}
+	case bson.A:
+		for _, v := range d {
+			paths = append(paths, fieldPaths(v, prefix)...)
+		}
+	}
+	sort.Strings(paths)
+	return dedupe(paths)
+}
+
+// dedupe removes repeated entries from a sorted list
+func dedupe(sorted []string) []string {
+	out := sorted[:0]
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// hasCollectionScan checks if an explain result's winning plan has a
+// COLLSCAN stage
+func hasCollectionScan(doc interface{}, inWinningPlan bool) bool {
+	switch d := doc.(type) {
+	case bson.M:
+		if inWinningPlan && d["stage"] == "COLLSCAN" {
+			return true
+		}
+		for key, value := range d {
+			if key == "rejectedPlans" {
+				continue
+			}
+			if hasCollectionScan(value, inWinningPlan || key == "winningPlan") {
+				return true
+			}
+		}
+	case bson.A:
+		for _, value := range d {
+			if hasCollectionScan(value, inWinningPlan) {
+				return true
+			}
+		}
+	}
+	return false
+}
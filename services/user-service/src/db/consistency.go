@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// primaryReadKey marks a context whose reads go to the primary
+type primaryReadKey struct{}
+
+// readRouting is how reads are routed between replica set members
+type readRouting struct {
+	// secondaryReads is set when the read preference may read from
+	// secondaries, which can lag behind the primary
+	secondaryReads bool
+	// primaryMethods are the repository methods that always read from the
+	// primary
+	primaryMethods map[string]bool
+}
+
+var (
+	routingMu sync.RWMutex
+	routing   readRouting
+
+	// primaryClones caches the primary-reading clones of collections
+	primaryClones sync.Map
+)
+
+// configureReads sets how reads are routed from the MongoDB configuration
+func configureReads(mode readpref.Mode, primaryReads []string) {
+	methods := make(map[string]bool)
+	for _, entry := range primaryReads {
+		for _, method := range strings.Split(entry, ",") {
+			if method = strings.TrimSpace(method); method != "" {
+				methods[method] = true
+			}
+		}
+	}
+
+	routingMu.Lock()
+	defer routingMu.Unlock()
+	routing = readRouting{
+		secondaryReads: mode != readpref.PrimaryMode,
+		primaryMethods: methods,
+	}
+}
+
+// ReadPrimary marks ctx so reads made with it go to the primary. Use it to
+// read back what was just written, which a secondary may not have yet.
+func ReadPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadKey{}, true)
+}
+
+// ReadCollection gets the collection a repository method reads with: the
+// collection reading from the primary if ctx was marked with ReadPrimary or
+// the method, named collection.Method, is configured to read from the
+// primary, and collection itself otherwise. Collections of drivers without
+// replicas are always returned as they are.
+func ReadCollection(ctx context.Context, collection Collection, method string) Collection {
+	// Collections stored by region read each region's collection this way
+	if regional, ok := collection.(*regionalCollection); ok {
+		reading := *regional
+		reading.method, reading.read = method, true
+		return &reading
+	}
+	if tenant, ok := collection.(*tenantCollection); ok {
+		return &tenantCollection{collection: ReadCollection(ctx, tenant.collection, method), field: tenant.field}
+	}
+
+	mongoCollection, ok := collection.(*mongo.Collection)
+	if !ok {
+		return collection
+	}
+
+	routingMu.RLock()
+	secondaryReads := routing.secondaryReads
+	primaryMethod := routing.primaryMethods[method]
+	routingMu.RUnlock()
+
+	if !secondaryReads {
+		return collection
+	}
+	if primary, _ := ctx.Value(primaryReadKey{}).(bool); !primary && !primaryMethod {
+		return collection
+	}
+
+	if clone, ok := primaryClones.Load(mongoCollection); ok {
+		return clone.(*mongo.Collection)
+	}
+	clone, err := mongoCollection.Clone(options.Collection().SetReadPreference(readpref.Primary()))
+	if err != nil {
+		return collection
+	}
+	primaryClones.Store(mongoCollection, clone)
+	return clone
+}
@@ -2,10 +2,12 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/your-username/slido-clone/user-service/config"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -15,13 +17,53 @@ import (
 type MongoDB struct {
 	Client *mongo.Client
 	DB     *mongo.Database
+
+	// Regions are the databases of the data residency regions, by region
+	Regions map[string]*mongo.Database
+	clients []*mongo.Client
+	router  *regionRouter
 }
 
 // Collections represents the collection names
 const (
-	UsersCollection         = "users"
-	TeamsCollection         = "teams"
-	OrganizationsCollection = "organizations"
+	UsersCollection             = "users"
+	TeamsCollection             = "teams"
+	OrganizationsCollection     = "organizations"
+	SCIMTokensCollection        = "scim_tokens"
+	JoinRequestsCollection      = "join_requests"
+	LeasesCollection            = "leases"
+	WebhooksCollection          = "webhooks"
+	WebhookDeliveriesCollection = "webhook_deliveries"
+	EmailTemplatesCollection    = "email_templates"
+	ProcessedEventsCollection   = "processed_events"
+	MigrationsCollection        = "schema_migrations"
+	LegacyMigrationsCollection  = "migrations"
+	TimelineCollection          = "organization_timeline"
+	OrgMembersCollection        = "organization_members"
+	BannersCollection           = "system_banners"
+	TombstonesCollection        = "sync_tombstones"
+	EventCountsCollection       = "event_counts"
+	GroupsCollection            = "organization_groups"
+	SessionsCollection          = "user_sessions"
+	ActivitiesCollection        = "user_activities"
+	DigestsCollection           = "notification_digests"
+	ImpersonationsCollection    = "impersonation_sessions"
+	AuditLogCollection          = "audit_log"
+	ChangeStreamsCollection     = "change_stream_tokens"
+	SettingsHistoryCollection   = "organization_settings_history"
+	FeatureFlagsCollection      = "feature_flags"
+	LDAPSyncConfigsCollection   = "ldap_sync_configs"
+	LDAPSyncRunsCollection      = "ldap_sync_runs"
+	VerificationsCollection     = "organization_verifications"
+	TeamJoinRequestsCollection  = "team_join_requests"
+	TeamTemplatesCollection     = "team_templates"
+	OrgExportsCollection        = "organization_exports"
+	OrgExportFilesCollection    = "organization_export_files"
+	OrgExportChunksCollection   = "organization_export_chunks"
+	JobRunsCollection           = "job_runs"
+	OrgHistoryCollection        = "organization_history"
+	InboxCollection             = "notification_inbox"
+	EventsCollection            = "organization_events"
 )
 
 // New creates a new MongoDB client
@@ -30,40 +72,106 @@ func New(cfg *config.MongoDBConfig) (*MongoDB, error) {
 	defer cancel()
 
 	// Create client options
-	clientOptions := options.Client().
-		ApplyURI(cfg.URI).
-		SetMaxPoolSize(cfg.MaxPoolSize).
-		SetMinPoolSize(cfg.MinPoolSize)
+	advisor := newIndexAdvisor()
+	clientOptions := func(uri string) *options.ClientOptions {
+		return options.Client().
+			ApplyURI(uri).
+			SetMaxPoolSize(cfg.MaxPoolSize).
+			SetMinPoolSize(cfg.MinPoolSize).
+			SetMonitor(advisor.monitor())
+	}
+	var readPreference *readpref.ReadPref
 
-	// Connect to MongoDB
-	client, err := mongo.Connect(ctx, clientOptions)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to connect to MongoDB")
-		return nil, err
+	// Route reads by the configured read preference
+	mode := readpref.PrimaryMode
+	if cfg.ReadPreference != "" {
+		var err error
+		if mode, err = readpref.ModeFromString(cfg.ReadPreference); err != nil {
+			return nil, err
+		}
+		if readPreference, err = readpref.New(mode); err != nil {
+			return nil, err
+		}
 	}
+	configureReads(mode, cfg.PrimaryReads)
 
-	// Check the connection
-	err = client.Ping(ctx, readpref.Primary())
+	// Connect to MongoDB
+	client, err := connect(ctx, clientOptions(cfg.URI), readPreference)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to ping MongoDB")
 		return nil, err
 	}
 
 	// Get the database
 	db := client.Database(cfg.DBName)
+	advisor.setDatabase(db)
 
 	// Create indexes
-	if err := createIndexes(ctx, db); err != nil {
+	if err := createIndexes(ctx, db, indexModels()); err != nil {
 		log.Error().Err(err).Msg("Failed to create indexes")
 		return nil, err
 	}
 
 	log.Info().Str("database", cfg.DBName).Msg("Connected to MongoDB")
 
-	return &MongoDB{
-		Client: client,
-		DB:     db,
-	}, nil
+	m := &MongoDB{
+		Client:  client,
+		DB:      db,
+		Regions: make(map[string]*mongo.Database),
+	}
+
+	// Connect to the clusters of the data residency regions. They only
+	// hold the collections of organizations resident in them, and the
+	// index advisor, which explains queries on the main database, doesn't
+	// watch them.
+	regionalIndexes := make(map[string][]mongo.IndexModel)
+	for name, indexes := range indexModels() {
+		if _, ok := regionalCollections[name]; ok {
+			regionalIndexes[name] = indexes
+		}
+	}
+	for region, uri := range cfg.RegionURIs() {
+		regionClient, err := connect(ctx, clientOptions(uri).SetMonitor(nil), readPreference)
+		if err != nil {
+			m.Close()
+			return nil, fmt.Errorf("region %s: %w", region, err)
+		}
+		m.clients = append(m.clients, regionClient)
+
+		regionDB := regionClient.Database(cfg.DBName)
+		if err := createIndexes(ctx, regionDB, regionalIndexes); err != nil {
+			log.Error().Err(err).Str("region", region).Msg("Failed to create indexes")
+			m.Close()
+			return nil, err
+		}
+		m.Regions[region] = regionDB
+
+		log.Info().Str("database", cfg.DBName).Str("region", region).Msg("Connected to MongoDB region")
+	}
+	m.router = newRegionRouter(db, m.Regions)
+
+	return m, nil
+}
+
+// connect connects a MongoDB client and checks the connection
+func connect(ctx context.Context, clientOptions *options.ClientOptions, readPreference *readpref.ReadPref) (*mongo.Client, error) {
+	if readPreference != nil {
+		clientOptions.SetReadPreference(readPreference)
+	}
+
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to connect to MongoDB")
+		return nil, err
+	}
+
+	// Check the connection
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		log.Error().Err(err).Msg("Failed to ping MongoDB")
+		client.Disconnect(ctx)
+		return nil, err
+	}
+
+	return client, nil
 }
 
 // Close closes the MongoDB connection
@@ -71,6 +179,12 @@ func (m *MongoDB) Close() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	for _, client := range m.clients {
+		if err := client.Disconnect(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to disconnect from MongoDB region")
+		}
+	}
+
 	if err := m.Client.Disconnect(ctx); err != nil {
 		log.Error().Err(err).Msg("Failed to disconnect from MongoDB")
 		return err
@@ -81,14 +195,34 @@ func (m *MongoDB) Close() error {
 }
 
 // GetCollection returns a collection from the database
-func (m *MongoDB) GetCollection(name string) *mongo.Collection {
+func (m *MongoDB) GetCollection(name string) Collection {
 	return m.DB.Collection(name)
 }
 
+// Ping checks the MongoDB connection
+func (m *MongoDB) Ping(ctx context.Context) error {
+	return m.Client.Ping(ctx, readpref.Primary())
+}
+
+// Driver returns the name of the storage driver
+func (m *MongoDB) Driver() string {
+	return DriverMongoDB
+}
+
 // createIndexes creates indexes for the collections
-func createIndexes(ctx context.Context, db *mongo.Database) error {
+func createIndexes(ctx context.Context, db *mongo.Database, models map[string][]mongo.IndexModel) error {
+	for name, indexes := range models {
+		if _, err := db.Collection(name).Indexes().CreateMany(ctx, indexes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexModels returns the indexes of every collection. Storage drivers
+// other than MongoDB use it to enforce unique constraints.
+func indexModels() map[string][]mongo.IndexModel {
 	// Users collection
-	usersCollection := db.Collection(UsersCollection)
 	userIndexes := []mongo.IndexModel{
 		{
 			Keys: map[string]interface{}{
@@ -102,14 +236,59 @@ func createIndexes(ctx context.Context, db *mongo.Database) error {
 			},
 			Options: options.Index().SetUnique(true),
 		},
-	}
-	_, err := usersCollection.Indexes().CreateMany(ctx, userIndexes)
-	if err != nil {
-		return err
+		{
+			Keys: map[string]interface{}{
+				"signupIp":  1,
+				"createdAt": 1,
+			},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys: map[string]interface{}{
+				"status": 1,
+			},
+		},
+		{
+			Keys: map[string]interface{}{
+				"createdAt": 1,
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "lastName", Value: 1},
+				{Key: "firstName", Value: 1},
+				{Key: "_id", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "role", Value: 1},
+				{Key: "status", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "organizationIds", Value: 1},
+				{Key: "lastName", Value: 1},
+				{Key: "firstName", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "teamIds", Value: 1},
+				{Key: "lastName", Value: 1},
+				{Key: "firstName", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "organizationIds", Value: 1},
+				{Key: "updatedAt", Value: 1},
+			},
+		},
 	}
 
 	// Teams collection
-	teamsCollection := db.Collection(TeamsCollection)
 	teamIndexes := []mongo.IndexModel{
 		{
 			Keys: map[string]interface{}{
@@ -123,14 +302,28 @@ func createIndexes(ctx context.Context, db *mongo.Database) error {
 			},
 			Options: options.Index().SetUnique(true),
 		},
-	}
-	_, err = teamsCollection.Indexes().CreateMany(ctx, teamIndexes)
-	if err != nil {
-		return err
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "updatedAt", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "parentTeamId", Value: 1},
+				{Key: "name", Value: 1},
+			},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "tags", Value: 1},
+			},
+		},
 	}
 
 	// Organizations collection
-	orgsCollection := db.Collection(OrganizationsCollection)
 	orgIndexes := []mongo.IndexModel{
 		{
 			Keys: map[string]interface{}{
@@ -138,8 +331,522 @@ func createIndexes(ctx context.Context, db *mongo.Database) error {
 			},
 			Options: options.Index().SetUnique(true),
 		},
+		{
+			Keys: bson.D{
+				{Key: "members.userId", Value: 1},
+				{Key: "updatedAt", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "tags", Value: 1},
+				{Key: "name", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "settings.features.discoverable", Value: 1},
+				{Key: "name", Value: 1},
+			},
+		},
+	}
+
+	// Organization members collection, for organizations that don't embed
+	// their members
+	orgMemberIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "userId", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "role", Value: 1},
+				{Key: "joinedAt", Value: 1},
+			},
+		},
+	}
+
+	// SCIM tokens collection
+	scimTokenIndexes := []mongo.IndexModel{
+		{
+			Keys: map[string]interface{}{
+				"tokenHash": 1,
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: map[string]interface{}{
+				"organizationId": 1,
+			},
+		},
+	}
+
+	// Join requests collection
+	joinRequestIndexes := []mongo.IndexModel{
+		{
+			Keys: map[string]interface{}{
+				"organizationId": 1,
+				"userId":         1,
+			},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(map[string]interface{}{"status": "pending"}),
+		},
+		{
+			Keys: map[string]interface{}{
+				"organizationId": 1,
+				"status":         1,
+			},
+		},
+	}
+
+	// Webhooks collection
+	webhookIndexes := []mongo.IndexModel{
+		{
+			Keys: map[string]interface{}{
+				"organizationId": 1,
+			},
+		},
+	}
+
+	// Webhook deliveries collection
+	webhookDeliveryIndexes := []mongo.IndexModel{
+		{
+			Keys: map[string]interface{}{
+				"status":        1,
+				"nextAttemptAt": 1,
+			},
+		},
+		{
+			Keys: map[string]interface{}{
+				"webhookId": 1,
+				"createdAt": -1,
+			},
+		},
+	}
+
+	// Email templates collection
+	emailTemplateIndexes := []mongo.IndexModel{
+		{
+			Keys: map[string]interface{}{
+				"organizationId": 1,
+				"key":            1,
+				"version":        1,
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	// Processed events collection; records are removed once they expire
+	processedEventIndexes := []mongo.IndexModel{
+		{
+			Keys: map[string]interface{}{
+				"expiresAt": 1,
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	// Sync tombstones collection; tombstones are removed once they expire
+	tombstoneIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "userIds", Value: 1},
+				{Key: "deletedAt", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "deletedAt", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "expiresAt", Value: 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	// Organization timeline collection
+	timelineIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "occurredAt", Value: -1},
+				{Key: "_id", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "type", Value: 1},
+				{Key: "occurredAt", Value: -1},
+				{Key: "_id", Value: -1},
+			},
+		},
+	}
+
+	// Published event counts collection
+	eventCountIndexes := []mongo.IndexModel{
+		{
+			Keys: map[string]interface{}{
+				"day": 1,
+			},
+		},
+	}
+
+	// Organization groups collection
+	groupIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "name", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "memberIds", Value: 1},
+			},
+		},
+	}
+
+	// User sessions collection; sessions are removed once they expire
+	sessionIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "lastActiveAt", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "expiresAt", Value: 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	// User activities collection
+	activityIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "occurredAt", Value: -1},
+				{Key: "_id", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "occurredAt", Value: -1},
+				{Key: "_id", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "userId", Value: 1},
+				{Key: "occurredAt", Value: -1},
+				{Key: "_id", Value: -1},
+			},
+		},
+	}
+
+	// Notification digests collection
+	digestIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "dueAt", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "dueAt", Value: 1},
+				{Key: "occurredAt", Value: 1},
+			},
+		},
+	}
+
+	// Impersonation sessions collection
+	impersonationIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "adminId", Value: 1},
+				{Key: "startedAt", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "startedAt", Value: -1},
+			},
+		},
+	}
+
+	// Audit log collection
+	auditLogIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "impersonationId", Value: 1},
+				{Key: "occurredAt", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "actorId", Value: 1},
+				{Key: "occurredAt", Value: -1},
+			},
+		},
+	}
+
+	// Settings history collection; each version number is recorded once per
+	// organization
+	settingsHistoryIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "version", Value: -1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
 	}
-	_, err = orgsCollection.Indexes().CreateMany(ctx, orgIndexes)
 
-	return err
+	// LDAP sync collections; the scheduler finds due configurations, and
+	// reports are listed newest first per organization
+	ldapSyncConfigIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "enabled", Value: 1},
+				{Key: "nextRunAt", Value: 1},
+			},
+		},
+	}
+	ldapSyncRunIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "startedAt", Value: -1},
+			},
+		},
+	}
+
+	// Organization verification requests collection; at most one request
+	// of an organization is pending
+	verificationIndexes := []mongo.IndexModel{
+		{
+			Keys: map[string]interface{}{"organizationId": 1},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(map[string]interface{}{"status": "pending"}),
+		},
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "createdAt", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "createdAt", Value: 1},
+			},
+		},
+	}
+
+	// Team join requests collection; at most one request of a user for a
+	// team is pending
+	teamJoinRequestIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "teamId", Value: 1},
+				{Key: "userId", Value: 1},
+			},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(map[string]interface{}{"status": "pending"}),
+		},
+		{
+			Keys: bson.D{
+				{Key: "teamId", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "createdAt", Value: 1},
+			},
+		},
+	}
+
+	// Team templates collection; template names are unique within an
+	// organization
+	teamTemplateIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "name", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	// Organization export collections; exports are listed newest first per
+	// organization, at most one export of an organization runs, and archives
+	// and their chunks are removed once they expire
+	orgExportIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "createdAt", Value: -1},
+			},
+		},
+		{
+			Keys: map[string]interface{}{"organizationId": 1},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(map[string]interface{}{"status": "running"}),
+		},
+	}
+	orgExportFileIndexes := []mongo.IndexModel{
+		{
+			Keys: map[string]interface{}{
+				"expiresAt": 1,
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	// Organization history collection; entries are numbered per
+	// organization, and a concurrent entry taking a recorded version fails
+	orgHistoryIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "version", Value: -1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	// Job runs collection; runs are listed newest first per job and removed
+	// from the history once they expire
+	jobRunIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "job", Value: 1},
+				{Key: "startedAt", Value: -1},
+			},
+		},
+		{
+			Keys: map[string]interface{}{
+				"expiresAt": 1,
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	// Notification inbox collection; a notification is added to an inbox once
+	// per event and removed once it expires
+	inboxIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "occurredAt", Value: -1},
+				{Key: "_id", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "readAt", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "createdAt", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "eventId", Value: 1},
+				{Key: "type", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: map[string]interface{}{
+				"expiresAt": 1,
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	// Events collection; join codes are unique across organizations, and an
+	// organization's events are listed by start time, optionally by status
+	eventIndexes := []mongo.IndexModel{
+		{
+			Keys:    map[string]interface{}{"code": 1},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "startsAt", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "organizationId", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "startsAt", Value: -1},
+			},
+		},
+	}
+
+	return map[string][]mongo.IndexModel{
+		UsersCollection:             userIndexes,
+		TeamsCollection:             teamIndexes,
+		OrganizationsCollection:     orgIndexes,
+		OrgMembersCollection:        orgMemberIndexes,
+		SCIMTokensCollection:        scimTokenIndexes,
+		JoinRequestsCollection:      joinRequestIndexes,
+		WebhooksCollection:          webhookIndexes,
+		WebhookDeliveriesCollection: webhookDeliveryIndexes,
+		EmailTemplatesCollection:    emailTemplateIndexes,
+		ProcessedEventsCollection:   processedEventIndexes,
+		TimelineCollection:          timelineIndexes,
+		TombstonesCollection:        tombstoneIndexes,
+		EventCountsCollection:       eventCountIndexes,
+		GroupsCollection:            groupIndexes,
+		SessionsCollection:          sessionIndexes,
+		ActivitiesCollection:        activityIndexes,
+		DigestsCollection:           digestIndexes,
+		ImpersonationsCollection:    impersonationIndexes,
+		AuditLogCollection:          auditLogIndexes,
+		SettingsHistoryCollection:   settingsHistoryIndexes,
+		LDAPSyncConfigsCollection:   ldapSyncConfigIndexes,
+		LDAPSyncRunsCollection:      ldapSyncRunIndexes,
+		VerificationsCollection:     verificationIndexes,
+		TeamJoinRequestsCollection:  teamJoinRequestIndexes,
+		TeamTemplatesCollection:     teamTemplateIndexes,
+		OrgExportsCollection:        orgExportIndexes,
+		OrgExportFilesCollection:    orgExportFileIndexes,
+		OrgExportChunksCollection:   orgExportFileIndexes,
+		JobRunsCollection:           jobRunIndexes,
+		OrgHistoryCollection:        orgHistoryIndexes,
+		InboxCollection:             inboxIndexes,
+		EventsCollection:            eventIndexes,
+	}
 }
@@ -0,0 +1,486 @@
+//go:build !noembedded
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func init() {
+	RegisterDriver(DriverEmbedded, func(cfg *config.Config) (Storage, error) {
+		return NewEmbedded(cfg.Storage.Path)
+	})
+}
+
+// duplicateKeyCode is the MongoDB error code for unique index violations
+const duplicateKeyCode = 11000
+
+// EmbeddedStore is an in-process document store for deployments where
+// operating MongoDB isn't an option. Documents are kept in memory and, when
+// a path is configured, snapshotted to disk after every write.
+type EmbeddedStore struct {
+	mu          sync.RWMutex
+	path        string
+	collections map[string][]bson.M
+	uniques     map[string][]uniqueIndex
+}
+
+// uniqueIndex is a unique constraint, optionally limited to documents matching a partial filter
+type uniqueIndex struct {
+	fields  []string
+	partial bson.M
+}
+
+// embeddedCollection is a collection of an embedded store
+type embeddedCollection struct {
+	store *EmbeddedStore
+	name  string
+}
+
+// NewEmbedded opens an embedded store, loading the snapshot at path if it exists.
+// An empty path keeps all data in memory.
+func NewEmbedded(path string) (*EmbeddedStore, error) {
+	s := &EmbeddedStore{
+		path:        path,
+		collections: make(map[string][]bson.M),
+		uniques:     make(map[string][]uniqueIndex),
+	}
+
+	// Unique constraints mirror the MongoDB unique indexes
+	for name, indexes := range indexModels() {
+		for _, index := range indexes {
+			if index.Options == nil || index.Options.Unique == nil || !*index.Options.Unique {
+				continue
+			}
+			keys, err := toDocument(index.Keys)
+			if err != nil {
+				return nil, err
+			}
+			fields := make([]string, 0, len(keys))
+			for field := range keys {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+
+			unique := uniqueIndex{fields: fields}
+			if index.Options.PartialFilterExpression != nil {
+				if unique.partial, err = toDocument(index.Options.PartialFilterExpression); err != nil {
+					return nil, err
+				}
+			}
+			s.uniques[name] = append(s.uniques[name], unique)
+		}
+	}
+
+	if err := s.load(); err != nil {
+		log.Error().Err(err).Str("path", path).Msg("Failed to load embedded store snapshot")
+		return nil, err
+	}
+
+	log.Info().Str("path", path).Msg("Opened embedded store")
+	return s, nil
+}
+
+// GetCollection returns a collection from the store
+func (s *EmbeddedStore) GetCollection(name string) Collection {
+	return &embeddedCollection{store: s, name: name}
+}
+
+// Ping checks the store is usable
+func (s *EmbeddedStore) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Close flushes the store to disk
+func (s *EmbeddedStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.persist(); err != nil {
+		log.Error().Err(err).Msg("Failed to flush embedded store")
+		return err
+	}
+
+	log.Info().Msg("Closed embedded store")
+	return nil
+}
+
+// Driver returns the name of the storage driver
+func (s *EmbeddedStore) Driver() string {
+	return DriverEmbedded
+}
+
+// load reads the snapshot from disk
+func (s *EmbeddedStore) load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	var snapshot bson.M
+	if err := bson.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("invalid embedded store snapshot: %w", err)
+	}
+
+	for name, docs := range snapshot {
+		arr, ok := normalize(docs).(primitive.A)
+		if !ok {
+			continue
+		}
+		for _, doc := range arr {
+			if m, ok := doc.(bson.M); ok {
+				s.collections[name] = append(s.collections[name], m)
+			}
+		}
+	}
+
+	return nil
+}
+
+// persist writes the snapshot to disk. The caller must hold the write lock.
+func (s *EmbeddedStore) persist() error {
+	if s.path == "" {
+		return nil
+	}
+
+	snapshot := make(bson.M, len(s.collections))
+	for name, docs := range s.collections {
+		snapshot[name] = docs
+	}
+
+	data, err := bson.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	// Write atomically
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// checkUnique verifies a document doesn't violate a unique constraint. The caller must hold the lock.
+func (s *EmbeddedStore) checkUnique(name string, doc bson.M, skip int) error {
+	docs := s.collections[name]
+	constraints := append([]uniqueIndex{{fields: []string{"_id"}}}, s.uniques[name]...)
+
+	for _, unique := range constraints {
+		if unique.partial != nil && !matches(doc, unique.partial) {
+			continue
+		}
+		for i, other := range docs {
+			if i == skip || (unique.partial != nil && !matches(other, unique.partial)) {
+				continue
+			}
+			duplicate := true
+			for _, field := range unique.fields {
+				if !equal(first(doc, field), first(other, field)) {
+					duplicate = false
+					break
+				}
+			}
+			if duplicate {
+				return mongo.WriteException{
+					WriteErrors: mongo.WriteErrors{{
+						Code:    duplicateKeyCode,
+						Message: fmt.Sprintf("E11000 duplicate key error collection: %s index: %v", name, unique.fields),
+					}},
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// InsertOne inserts a document
+func (c *embeddedCollection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	doc, err := toDocument(document)
+	if err != nil {
+		return nil, err
+	}
+	if id, ok := doc["_id"]; !ok || id == nil || id == "" {
+		doc["_id"] = primitive.NewObjectID()
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	if err := c.store.checkUnique(c.name, doc, -1); err != nil {
+		return nil, err
+	}
+
+	c.store.collections[c.name] = append(c.store.collections[c.name], doc)
+	if err := c.store.persist(); err != nil {
+		return nil, err
+	}
+
+	return &mongo.InsertOneResult{InsertedID: doc["_id"]}, nil
+}
+
+// FindOne finds a single document
+func (c *embeddedCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	findOpts := options.Find().SetLimit(1)
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.Sort != nil {
+			findOpts.SetSort(opt.Sort)
+		}
+		if opt.Skip != nil {
+			findOpts.SetSkip(*opt.Skip)
+		}
+	}
+
+	docs, err := c.find(filter, findOpts)
+	if err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	if len(docs) == 0 {
+		return mongo.NewSingleResultFromDocument(bson.D{}, mongo.ErrNoDocuments, nil)
+	}
+
+	return mongo.NewSingleResultFromDocument(docs[0], nil, nil)
+}
+
+// Find finds documents
+func (c *embeddedCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	findOpts := options.Find()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.Sort != nil {
+			findOpts.SetSort(opt.Sort)
+		}
+		if opt.Skip != nil {
+			findOpts.SetSkip(*opt.Skip)
+		}
+		if opt.Limit != nil {
+			findOpts.SetLimit(*opt.Limit)
+		}
+	}
+
+	docs, err := c.find(filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		results[i] = doc
+	}
+	return mongo.NewCursorFromDocuments(results, nil, nil)
+}
+
+// UpdateOne updates a single document
+func (c *embeddedCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	query, err := toDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+	upd, err := toUpdate(update)
+	if err != nil {
+		return nil, err
+	}
+
+	upsert := false
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.ArrayFilters != nil {
+			if upd.arrayFilters, err = toArrayFilters(opt.ArrayFilters.Filters); err != nil {
+				return nil, err
+			}
+		}
+		if opt.Upsert != nil {
+			upsert = *opt.Upsert
+		}
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	docs := c.store.collections[c.name]
+	for i, doc := range docs {
+		if !matches(doc, query) {
+			continue
+		}
+
+		updated, err := upd.apply(clone(doc), query, false)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.store.checkUnique(c.name, updated, i); err != nil {
+			return nil, err
+		}
+
+		modified := int64(0)
+		if !equal(doc, updated) {
+			docs[i] = updated
+			modified = 1
+			if err := c.store.persist(); err != nil {
+				return nil, err
+			}
+		}
+		return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: modified}, nil
+	}
+
+	if !upsert {
+		return &mongo.UpdateResult{}, nil
+	}
+
+	// Upsert a new document seeded from the filter's equality conditions
+	seed := bson.M{}
+	for key, value := range query {
+		if strings.HasPrefix(key, "$") || isOperatorDoc(value) {
+			continue
+		}
+		setPath(seed, splitPath(key), value)
+	}
+	doc, err := upd.apply(seed, query, true)
+	if err != nil {
+		return nil, err
+	}
+	if id, ok := doc["_id"]; !ok || id == nil || id == "" {
+		doc["_id"] = primitive.NewObjectID()
+	}
+	if err := c.store.checkUnique(c.name, doc, -1); err != nil {
+		return nil, err
+	}
+
+	c.store.collections[c.name] = append(docs, doc)
+	if err := c.store.persist(); err != nil {
+		return nil, err
+	}
+
+	return &mongo.UpdateResult{UpsertedCount: 1, UpsertedID: doc["_id"]}, nil
+}
+
+// DeleteOne deletes a single document
+func (c *embeddedCollection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	query, err := toDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	docs := c.store.collections[c.name]
+	for i, doc := range docs {
+		if !matches(doc, query) {
+			continue
+		}
+
+		c.store.collections[c.name] = append(docs[:i:i], docs[i+1:]...)
+		if err := c.store.persist(); err != nil {
+			return nil, err
+		}
+		return &mongo.DeleteResult{DeletedCount: 1}, nil
+	}
+
+	return &mongo.DeleteResult{}, nil
+}
+
+// CountDocuments counts documents
+func (c *embeddedCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	findOpts := options.Find()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.Skip != nil {
+			findOpts.SetSkip(*opt.Skip)
+		}
+		if opt.Limit != nil {
+			findOpts.SetLimit(*opt.Limit)
+		}
+	}
+
+	docs, err := c.find(filter, findOpts)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(docs)), nil
+}
+
+// find returns copies of the documents matching a filter
+func (c *embeddedCollection) find(filter interface{}, opts *options.FindOptions) ([]bson.M, error) {
+	query, err := toDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var sortSpec bson.D
+	if opts.Sort != nil {
+		if sortSpec, err = toOrdered(opts.Sort); err != nil {
+			return nil, err
+		}
+	}
+
+	c.store.mu.RLock()
+	var docs []bson.M
+	for _, doc := range c.store.collections[c.name] {
+		if matches(doc, query) {
+			docs = append(docs, clone(doc))
+		}
+	}
+	c.store.mu.RUnlock()
+
+	if len(sortSpec) > 0 {
+		sort.SliceStable(docs, func(i, j int) bool {
+			for _, key := range sortSpec {
+				dir := 1
+				if n, ok := toFloat(key.Value); ok && n < 0 {
+					dir = -1
+				}
+				if cmp := compareValues(first(docs[i], key.Key), first(docs[j], key.Key)); cmp != 0 {
+					return cmp*dir < 0
+				}
+			}
+			return false
+		})
+	}
+
+	if opts.Skip != nil && *opts.Skip > 0 {
+		if int(*opts.Skip) >= len(docs) {
+			return nil, nil
+		}
+		docs = docs[*opts.Skip:]
+	}
+	if opts.Limit != nil && *opts.Limit > 0 && int(*opts.Limit) < len(docs) {
+		docs = docs[:*opts.Limit]
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,1092 @@
+//go:build !noembedded
+
+package db
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// errPositionalNoMatch is returned when a positional update has no matching array element
+var errPositionalNoMatch = errors.New("embedded store: the positional operator did not find the match needed from the query")
+
+// embeddedUpdate is an update document or an update pipeline
+type embeddedUpdate struct {
+	ops          bson.M
+	pipeline     []bson.M
+	arrayFilters map[string]interface{}
+}
+
+// toDocument converts a filter, update or document into a normalized bson.M
+func toDocument(v interface{}) (bson.M, error) {
+	if v == nil {
+		return bson.M{}, nil
+	}
+
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("embedded store: invalid document: %w", err)
+	}
+
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("embedded store: invalid document: %w", err)
+	}
+
+	return normalize(doc).(bson.M), nil
+}
+
+// toOrdered converts a sort specification into an ordered document
+func toOrdered(v interface{}) (bson.D, error) {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("embedded store: invalid sort: %w", err)
+	}
+
+	var doc bson.D
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("embedded store: invalid sort: %w", err)
+	}
+	return doc, nil
+}
+
+// toUpdate converts an update document or pipeline
+func toUpdate(v interface{}) (*embeddedUpdate, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		ops, err := toDocument(v)
+		if err != nil {
+			return nil, err
+		}
+		return &embeddedUpdate{ops: ops}, nil
+	}
+
+	update := &embeddedUpdate{}
+	for i := 0; i < rv.Len(); i++ {
+		stage, err := toDocument(rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		update.pipeline = append(update.pipeline, stage)
+	}
+	return update, nil
+}
+
+// normalize converts decoded BSON values into bson.M documents and primitive.A arrays
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case bson.M:
+		for k, val := range t {
+			t[k] = normalize(val)
+		}
+		return t
+	case bson.D:
+		m := make(bson.M, len(t))
+		for _, e := range t {
+			m[e.Key] = normalize(e.Value)
+		}
+		return m
+	case primitive.A:
+		for i, val := range t {
+			t[i] = normalize(val)
+		}
+		return t
+	case []interface{}:
+		return normalize(primitive.A(t))
+	default:
+		return v
+	}
+}
+
+// clone deep copies a document
+func clone(doc bson.M) bson.M {
+	copied, err := toDocument(doc)
+	if err != nil {
+		return bson.M{}
+	}
+	return copied
+}
+
+// splitPath splits a dotted field path
+func splitPath(path string) []string {
+	return strings.Split(path, ".")
+}
+
+// isOperatorDoc checks if a value is a document of query operators
+func isOperatorDoc(v interface{}) bool {
+	m, ok := v.(bson.M)
+	if !ok || len(m) == 0 {
+		return false
+	}
+	for k := range m {
+		if !strings.HasPrefix(k, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup resolves a dotted path, traversing arrays like MongoDB does
+func lookup(v interface{}, parts []string) []interface{} {
+	if len(parts) == 0 {
+		return []interface{}{v}
+	}
+
+	switch t := v.(type) {
+	case bson.M:
+		child, ok := t[parts[0]]
+		if !ok {
+			return nil
+		}
+		return lookup(child, parts[1:])
+	case primitive.A:
+		if i, err := strconv.Atoi(parts[0]); err == nil {
+			if i >= 0 && i < len(t) {
+				return lookup(t[i], parts[1:])
+			}
+			return nil
+		}
+		var out []interface{}
+		for _, e := range t {
+			out = append(out, lookup(e, parts)...)
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// first returns the first value at a path, or nil
+func first(doc bson.M, path string) interface{} {
+	values := lookup(doc, splitPath(path))
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+// expand returns values plus the elements of any array values
+func expand(values []interface{}) []interface{} {
+	out := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		out = append(out, v)
+		if arr, ok := v.(primitive.A); ok {
+			out = append(out, arr...)
+		}
+	}
+	return out
+}
+
+// matches checks if a document matches a filter
+func matches(doc bson.M, filter bson.M) bool {
+	for key, cond := range filter {
+		switch key {
+		case "$and", "$or", "$nor":
+			clauses, _ := cond.(primitive.A)
+			matched := 0
+			for _, clause := range clauses {
+				if sub, ok := clause.(bson.M); ok && matches(doc, sub) {
+					matched++
+				}
+			}
+			switch key {
+			case "$and":
+				if matched != len(clauses) {
+					return false
+				}
+			case "$or":
+				if matched == 0 {
+					return false
+				}
+			case "$nor":
+				if matched != 0 {
+					return false
+				}
+			}
+		default:
+			if strings.HasPrefix(key, "$") {
+				return false
+			}
+			if !matchCondition(lookup(doc, splitPath(key)), cond) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchCondition checks if the values at a path satisfy a condition
+func matchCondition(values []interface{}, cond interface{}) bool {
+	if !isOperatorDoc(cond) {
+		if cond == nil && len(values) == 0 {
+			return true
+		}
+		return containsValue(expand(values), cond)
+	}
+
+	ops := cond.(bson.M)
+	for op, arg := range ops {
+		switch op {
+		case "$eq":
+			if !matchCondition(values, arg) {
+				return false
+			}
+		case "$ne":
+			if matchCondition(values, arg) {
+				return false
+			}
+		case "$in", "$nin":
+			list, _ := arg.(primitive.A)
+			found := false
+			for _, item := range list {
+				if containsValue(expand(values), item) || (item == nil && len(values) == 0) {
+					found = true
+					break
+				}
+			}
+			if found != (op == "$in") {
+				return false
+			}
+		case "$gt", "$gte", "$lt", "$lte":
+			ok := false
+			for _, v := range expand(values) {
+				cmp, comparable := compare(v, arg)
+				if !comparable {
+					continue
+				}
+				if (op == "$gt" && cmp > 0) || (op == "$gte" && cmp >= 0) ||
+					(op == "$lt" && cmp < 0) || (op == "$lte" && cmp <= 0) {
+					ok = true
+					break
+				}
+			}
+			if !ok {
+				return false
+			}
+		case "$exists":
+			want, _ := arg.(bool)
+			if (len(values) > 0) != want {
+				return false
+			}
+		case "$regex":
+			re, err := compileRegex(arg, ops["$options"])
+			if err != nil {
+				return false
+			}
+			ok := false
+			for _, v := range expand(values) {
+				if s, isString := v.(string); isString && re.MatchString(s) {
+					ok = true
+					break
+				}
+			}
+			if !ok {
+				return false
+			}
+		case "$options":
+			// Handled by $regex
+		case "$elemMatch":
+			sub, _ := arg.(bson.M)
+			ok := false
+			for _, v := range values {
+				arr, isArray := v.(primitive.A)
+				if !isArray {
+					continue
+				}
+				for _, elem := range arr {
+					if elemMatches(elem, sub) {
+						ok = true
+						break
+					}
+				}
+			}
+			if !ok {
+				return false
+			}
+		case "$size":
+			size, _ := toFloat(arg)
+			ok := false
+			for _, v := range values {
+				if arr, isArray := v.(primitive.A); isArray && float64(len(arr)) == size {
+					ok = true
+				}
+			}
+			if !ok {
+				return false
+			}
+		case "$all":
+			list, _ := arg.(primitive.A)
+			for _, item := range list {
+				if !containsValue(expand(values), item) {
+					return false
+				}
+			}
+		case "$not":
+			if matchCondition(values, arg) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// elemMatches checks if an array element satisfies an $elemMatch or $pull condition
+func elemMatches(elem interface{}, cond interface{}) bool {
+	sub, ok := cond.(bson.M)
+	if !ok {
+		return equal(elem, cond)
+	}
+	if isOperatorDoc(sub) {
+		return matchCondition([]interface{}{elem}, sub)
+	}
+	doc, ok := elem.(bson.M)
+	if !ok {
+		return false
+	}
+	return matches(doc, sub)
+}
+
+// compileRegex compiles a $regex condition
+func compileRegex(pattern interface{}, opts interface{}) (*regexp.Regexp, error) {
+	var expr, flags string
+	switch p := pattern.(type) {
+	case string:
+		expr = p
+	case primitive.Regex:
+		expr, flags = p.Pattern, p.Options
+	default:
+		return nil, errors.New("embedded store: invalid $regex")
+	}
+	if o, ok := opts.(string); ok {
+		flags += o
+	}
+
+	prefix := ""
+	for _, f := range flags {
+		switch f {
+		case 'i', 'm', 's':
+			prefix += string(f)
+		}
+	}
+	if prefix != "" {
+		expr = "(?" + prefix + ")" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+// containsValue checks if any value equals the target
+func containsValue(values []interface{}, target interface{}) bool {
+	for _, v := range values {
+		if equal(v, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// toFloat converts numeric values
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// equal compares two BSON values
+func equal(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	if cmp, ok := compare(a, b); ok {
+		return cmp == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compare orders two comparable BSON values of the same kind
+func compare(a, b interface{}) (int, bool) {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(x, y), true
+	case primitive.DateTime:
+		y, ok := b.(primitive.DateTime)
+		if !ok {
+			return 0, false
+		}
+		return compare(int64(x), int64(y))
+	case bool:
+		y, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x == y:
+			return 0, true
+		case !x:
+			return -1, true
+		}
+		return 1, true
+	case primitive.ObjectID:
+		y, ok := b.(primitive.ObjectID)
+		if !ok {
+			return 0, false
+		}
+		return bytes.Compare(x[:], y[:]), true
+	}
+	return 0, false
+}
+
+// compareValues orders any two values for sorting, placing missing values first
+func compareValues(a, b interface{}) int {
+	if cmp, ok := compare(a, b); ok {
+		return cmp
+	}
+	switch {
+	case a == nil && b != nil:
+		return -1
+	case a != nil && b == nil:
+		return 1
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// setPath sets a value at a dotted path, creating intermediate documents
+func setPath(doc bson.M, parts []string, value interface{}) error {
+	var current interface{} = doc
+	for i, part := range parts {
+		last := i == len(parts)-1
+		switch t := current.(type) {
+		case bson.M:
+			if last {
+				t[part] = value
+				return nil
+			}
+			next, ok := t[part]
+			if !ok || next == nil {
+				next = bson.M{}
+				t[part] = next
+			}
+			current = next
+		case primitive.A:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(t) {
+				return fmt.Errorf("embedded store: cannot set %s", strings.Join(parts, "."))
+			}
+			if last {
+				t[idx] = value
+				return nil
+			}
+			current = t[idx]
+		default:
+			return fmt.Errorf("embedded store: cannot set %s", strings.Join(parts, "."))
+		}
+	}
+	return nil
+}
+
+// deletePath removes the value at a dotted path
+func deletePath(doc bson.M, parts []string) {
+	var current interface{} = doc
+	for i, part := range parts {
+		last := i == len(parts)-1
+		switch t := current.(type) {
+		case bson.M:
+			if last {
+				delete(t, part)
+				return
+			}
+			current = t[part]
+		case primitive.A:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(t) {
+				return
+			}
+			if last {
+				t[idx] = nil
+				return
+			}
+			current = t[idx]
+		default:
+			return
+		}
+	}
+}
+
+// resolvePositional replaces a positional "$" path segment with the index of
+// the first array element matched by the filter
+func resolvePositional(doc bson.M, path string, filter bson.M) (string, error) {
+	idx := strings.Index(path, ".$")
+	if idx < 0 || (len(path) > idx+2 && path[idx+2] != '.') {
+		return path, nil
+	}
+
+	arrayPath := path[:idx]
+	arr, ok := first(doc, arrayPath).(primitive.A)
+	if !ok {
+		return "", errPositionalNoMatch
+	}
+
+	// Collect the filter conditions that apply to the array elements
+	sub := bson.M{}
+	var scalar interface{}
+	hasScalar := false
+	for key, cond := range filter {
+		switch {
+		case strings.HasPrefix(key, arrayPath+"."):
+			sub[strings.TrimPrefix(key, arrayPath+".")] = cond
+		case key == arrayPath:
+			if ops, isOps := cond.(bson.M); isOps && isOperatorDoc(ops) {
+				if elem, hasElem := ops["$elemMatch"].(bson.M); hasElem {
+					for k, v := range elem {
+						sub[k] = v
+					}
+					continue
+				}
+			}
+			scalar, hasScalar = cond, true
+		}
+	}
+
+	for i, elem := range arr {
+		if hasScalar && !elemMatches(elem, scalar) {
+			continue
+		}
+		if len(sub) > 0 {
+			elemDoc, isDoc := elem.(bson.M)
+			if !isDoc || !matches(elemDoc, sub) {
+				continue
+			}
+		}
+		if !hasScalar && len(sub) == 0 {
+			break
+		}
+		return arrayPath + "." + strconv.Itoa(i) + path[idx+2:], nil
+	}
+
+	return "", errPositionalNoMatch
+}
+
+// toArrayFilters groups array filter conditions by their identifier. A
+// condition on "identifier.field" applies to a field of the array element,
+// one on "identifier" to the element itself.
+func toArrayFilters(filters []interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{})
+	for _, f := range filters {
+		doc, err := toDocument(f)
+		if err != nil {
+			return nil, err
+		}
+		for key, cond := range doc {
+			dot := strings.Index(key, ".")
+			if dot < 0 {
+				out[key] = cond
+				continue
+			}
+			fields, ok := out[key[:dot]].(bson.M)
+			if !ok {
+				fields = bson.M{}
+				out[key[:dot]] = fields
+			}
+			fields[key[dot+1:]] = cond
+		}
+	}
+	return out, nil
+}
+
+// resolveArrayFilters expands "$[identifier]" and "$[]" path segments into
+// the paths of every array element matched by the identifier's array filter
+func (u *embeddedUpdate) resolveArrayFilters(doc bson.M, path string) ([]string, error) {
+	parts := splitPath(path)
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "$[") || !strings.HasSuffix(part, "]") {
+			continue
+		}
+
+		ident := part[2 : len(part)-1]
+		cond, ok := u.arrayFilters[ident]
+		if ident != "" && !ok {
+			return nil, fmt.Errorf("embedded store: no array filter found for identifier %q", ident)
+		}
+
+		arr, _ := first(doc, strings.Join(parts[:i], ".")).(primitive.A)
+		paths := make([]string, 0, len(arr))
+		for idx, elem := range arr {
+			if ident != "" && !elemMatches(elem, cond) {
+				continue
+			}
+			elemPath := make([]string, 0, len(parts))
+			elemPath = append(elemPath, parts[:i]...)
+			elemPath = append(elemPath, strconv.Itoa(idx))
+			elemPath = append(elemPath, parts[i+1:]...)
+
+			expanded, err := u.resolveArrayFilters(doc, strings.Join(elemPath, "."))
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, expanded...)
+		}
+		return paths, nil
+	}
+	return []string{path}, nil
+}
+
+// apply applies the update to a document
+func (u *embeddedUpdate) apply(doc bson.M, filter bson.M, isInsert bool) (bson.M, error) {
+	if u.pipeline != nil {
+		return applyPipeline(doc, u.pipeline)
+	}
+
+	for op, arg := range u.ops {
+		fields, ok := arg.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("embedded store: invalid %s", op)
+		}
+
+		for path, value := range fields {
+			path, err := resolvePositional(doc, path, filter)
+			if err != nil {
+				return nil, err
+			}
+			paths, err := u.resolveArrayFilters(doc, path)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, path := range paths {
+				parts := splitPath(path)
+
+				switch op {
+				case "$set":
+					if err := setPath(doc, parts, value); err != nil {
+						return nil, err
+					}
+				case "$setOnInsert":
+					if isInsert {
+						if err := setPath(doc, parts, value); err != nil {
+							return nil, err
+						}
+					}
+				case "$unset":
+					deletePath(doc, parts)
+				case "$inc":
+					current, _ := toFloat(first(doc, path))
+					delta, ok := toFloat(value)
+					if !ok {
+						return nil, fmt.Errorf("embedded store: invalid $inc for %s", path)
+					}
+					var result interface{} = current + delta
+					if _, isFloat := value.(float64); !isFloat && current == float64(int64(current)) {
+						result = int64(current + delta)
+					}
+					if err := setPath(doc, parts, result); err != nil {
+						return nil, err
+					}
+				case "$push", "$addToSet":
+					arr, _ := first(doc, path).(primitive.A)
+					items := primitive.A{value}
+					if each, isEach := value.(bson.M); isEach {
+						if list, hasEach := each["$each"].(primitive.A); hasEach {
+							items = list
+						}
+					}
+					for _, item := range items {
+						if op == "$addToSet" && containsValue(arr, item) {
+							continue
+						}
+						arr = append(arr, item)
+					}
+					if err := setPath(doc, parts, arr); err != nil {
+						return nil, err
+					}
+				case "$pull":
+					arr, ok := first(doc, path).(primitive.A)
+					if !ok {
+						continue
+					}
+					kept := primitive.A{}
+					for _, elem := range arr {
+						if !elemMatches(elem, value) {
+							kept = append(kept, elem)
+						}
+					}
+					if err := setPath(doc, parts, kept); err != nil {
+						return nil, err
+					}
+				default:
+					return nil, fmt.Errorf("embedded store: unsupported update operator %s", op)
+				}
+			}
+		}
+	}
+
+	return doc, nil
+}
+
+// applyPipeline applies an update pipeline to a document
+func applyPipeline(doc bson.M, pipeline []bson.M) (bson.M, error) {
+	now := primitive.NewDateTimeFromTime(time.Now())
+
+	for _, stage := range pipeline {
+		for op, arg := range stage {
+			vars := map[string]interface{}{"NOW": now, "ROOT": doc, "CURRENT": doc}
+
+			switch op {
+			case "$set", "$addFields":
+				fields, ok := arg.(bson.M)
+				if !ok {
+					return nil, fmt.Errorf("embedded store: invalid %s stage", op)
+				}
+				computed := bson.M{}
+				for path, expr := range fields {
+					value, err := evalExpr(expr, doc, vars)
+					if err != nil {
+						return nil, err
+					}
+					computed[path] = value
+				}
+				for path, value := range computed {
+					if err := setPath(doc, splitPath(path), value); err != nil {
+						return nil, err
+					}
+				}
+			case "$unset":
+				switch fields := arg.(type) {
+				case string:
+					deletePath(doc, splitPath(fields))
+				case primitive.A:
+					for _, field := range fields {
+						if s, ok := field.(string); ok {
+							deletePath(doc, splitPath(s))
+						}
+					}
+				}
+			case "$replaceRoot", "$replaceWith":
+				expr := arg
+				if op == "$replaceRoot" {
+					expr = arg.(bson.M)["newRoot"]
+				}
+				value, err := evalExpr(expr, doc, vars)
+				if err != nil {
+					return nil, err
+				}
+				root, ok := value.(bson.M)
+				if !ok {
+					return nil, errors.New("embedded store: replacement root must be a document")
+				}
+				if _, hasID := root["_id"]; !hasID {
+					root["_id"] = doc["_id"]
+				}
+				doc = root
+			default:
+				return nil, fmt.Errorf("embedded store: unsupported pipeline stage %s", op)
+			}
+		}
+	}
+
+	return doc, nil
+}
+
+// evalExpr evaluates an aggregation expression
+func evalExpr(expr interface{}, root bson.M, vars map[string]interface{}) (interface{}, error) {
+	switch e := expr.(type) {
+	case string:
+		switch {
+		case strings.HasPrefix(e, "$$"):
+			parts := splitPath(e[2:])
+			value, ok := vars[parts[0]]
+			if !ok {
+				return nil, fmt.Errorf("embedded store: undefined variable %s", parts[0])
+			}
+			return fieldValue(value, parts[1:]), nil
+		case strings.HasPrefix(e, "$"):
+			return fieldValue(root, splitPath(e[1:])), nil
+		}
+		return e, nil
+	case primitive.A:
+		out := make(primitive.A, len(e))
+		for i, item := range e {
+			value, err := evalExpr(item, root, vars)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = value
+		}
+		return out, nil
+	case bson.M:
+		if len(e) == 1 {
+			for op, arg := range e {
+				if strings.HasPrefix(op, "$") {
+					return evalOperator(op, arg, root, vars)
+				}
+			}
+		}
+		out := bson.M{}
+		for k, v := range e {
+			value, err := evalExpr(v, root, vars)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = value
+		}
+		return out, nil
+	default:
+		return expr, nil
+	}
+}
+
+// fieldValue resolves a field path inside an expression value
+func fieldValue(v interface{}, parts []string) interface{} {
+	if len(parts) == 0 {
+		return v
+	}
+	switch t := v.(type) {
+	case bson.M:
+		return fieldValue(t[parts[0]], parts[1:])
+	case primitive.A:
+		out := primitive.A{}
+		for _, elem := range t {
+			if value := fieldValue(elem, parts); value != nil {
+				out = append(out, value)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// evalOperator evaluates an aggregation operator
+func evalOperator(op string, arg interface{}, root bson.M, vars map[string]interface{}) (interface{}, error) {
+	// Operators with lazily evaluated arguments
+	switch op {
+	case "$literal":
+		return arg, nil
+	case "$map", "$filter":
+		spec, ok := arg.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("embedded store: invalid %s", op)
+		}
+		input, err := evalExpr(spec["input"], root, vars)
+		if err != nil {
+			return nil, err
+		}
+		arr, _ := input.(primitive.A)
+		name, _ := spec["as"].(string)
+		if name == "" {
+			name = "this"
+		}
+
+		out := primitive.A{}
+		for _, elem := range arr {
+			scope := make(map[string]interface{}, len(vars)+1)
+			for k, v := range vars {
+				scope[k] = v
+			}
+			scope[name] = elem
+
+			if op == "$map" {
+				value, err := evalExpr(spec["in"], root, scope)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, value)
+				continue
+			}
+
+			keep, err := evalExpr(spec["cond"], root, scope)
+			if err != nil {
+				return nil, err
+			}
+			if truthy(keep) {
+				out = append(out, elem)
+			}
+		}
+		return out, nil
+	case "$switch":
+		spec, ok := arg.(bson.M)
+		if !ok {
+			return nil, errors.New("embedded store: invalid $switch")
+		}
+		branches, _ := spec["branches"].(primitive.A)
+		for _, b := range branches {
+			branch, ok := b.(bson.M)
+			if !ok {
+				continue
+			}
+			matched, err := evalExpr(branch["case"], root, vars)
+			if err != nil {
+				return nil, err
+			}
+			if truthy(matched) {
+				return evalExpr(branch["then"], root, vars)
+			}
+		}
+		if def, ok := spec["default"]; ok {
+			return evalExpr(def, root, vars)
+		}
+		return nil, errors.New("embedded store: $switch has no matching branch and no default")
+	case "$cond":
+		var ifExpr, thenExpr, elseExpr interface{}
+		switch c := arg.(type) {
+		case bson.M:
+			ifExpr, thenExpr, elseExpr = c["if"], c["then"], c["else"]
+		case primitive.A:
+			if len(c) != 3 {
+				return nil, errors.New("embedded store: invalid $cond")
+			}
+			ifExpr, thenExpr, elseExpr = c[0], c[1], c[2]
+		}
+		cond, err := evalExpr(ifExpr, root, vars)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(cond) {
+			return evalExpr(thenExpr, root, vars)
+		}
+		return evalExpr(elseExpr, root, vars)
+	}
+
+	// Operators with eagerly evaluated arguments
+	evaluated, err := evalExpr(arg, root, vars)
+	if err != nil {
+		return nil, err
+	}
+	args, isList := evaluated.(primitive.A)
+	if !isList {
+		args = primitive.A{evaluated}
+	}
+
+	switch op {
+	case "$eq", "$ne", "$gt", "$gte", "$lt", "$lte":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("embedded store: %s takes two arguments", op)
+		}
+		cmp := compareValues(args[0], args[1])
+		switch op {
+		case "$eq":
+			return equal(args[0], args[1]), nil
+		case "$ne":
+			return !equal(args[0], args[1]), nil
+		case "$gt":
+			return cmp > 0, nil
+		case "$gte":
+			return cmp >= 0, nil
+		case "$lt":
+			return cmp < 0, nil
+		}
+		return cmp <= 0, nil
+	case "$and":
+		for _, a := range args {
+			if !truthy(a) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "$or":
+		for _, a := range args {
+			if truthy(a) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$not":
+		return !truthy(args[0]), nil
+	case "$in":
+		if len(args) != 2 {
+			return nil, errors.New("embedded store: $in takes two arguments")
+		}
+		list, _ := args[1].(primitive.A)
+		return containsValue(list, args[0]), nil
+	case "$ifNull":
+		for _, a := range args {
+			if a != nil {
+				return a, nil
+			}
+		}
+		return nil, nil
+	case "$size":
+		list, _ := args[0].(primitive.A)
+		return int32(len(list)), nil
+	case "$concat":
+		var sb strings.Builder
+		for _, a := range args {
+			s, _ := a.(string)
+			sb.WriteString(s)
+		}
+		return sb.String(), nil
+	case "$concatArrays":
+		out := primitive.A{}
+		for _, a := range args {
+			list, _ := a.(primitive.A)
+			out = append(out, list...)
+		}
+		return out, nil
+	case "$mergeObjects":
+		out := bson.M{}
+		for _, a := range args {
+			if m, ok := a.(bson.M); ok {
+				for k, v := range m {
+					out[k] = v
+				}
+			}
+		}
+		return out, nil
+	}
+
+	return nil, fmt.Errorf("embedded store: unsupported expression operator %s", op)
+}
+
+// truthy evaluates a value as a boolean
+func truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	}
+	if n, ok := toFloat(v); ok {
+		return n != 0
+	}
+	return true
+}
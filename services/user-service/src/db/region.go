@@ -0,0 +1,661 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ResidencyField is the field of an organization naming the data residency
+// region it is stored in. Organizations without one are stored in the main
+// database.
+const ResidencyField = "residency"
+
+// homeRegion is the region of the main database
+const homeRegion = ""
+
+// ErrUnknownRegion is returned for documents of a data residency region that
+// isn't configured
+var ErrUnknownRegion = errors.New("unknown data residency region")
+
+// regionalCollections are the collections whose documents are stored in the
+// region of their organization, with the field naming the organization
+var regionalCollections = map[string]string{
+	OrganizationsCollection: "_id",
+	TeamsCollection:         "organizationId",
+	OrgMembersCollection:    "organizationId",
+}
+
+// RegionalStorage is implemented by storage backends that store the data of
+// organizations in their data residency region. MongoDB implements it.
+type RegionalStorage interface {
+	// RegionalCollection returns a collection whose documents are stored
+	// in the region of their organization
+	RegionalCollection(name string) Collection
+}
+
+// RegionalCollection returns a collection of organization data. Storage
+// backends with data residency regions store its documents in the region of
+// their organization; other backends return the collection itself.
+func RegionalCollection(storage Storage, name string) Collection {
+	if regional, ok := storage.(RegionalStorage); ok {
+		return regional.RegionalCollection(name)
+	}
+	return storage.GetCollection(name)
+}
+
+// RegionalCollection returns a collection whose documents are stored in the
+// region of their organization. Without regions, or for a collection that
+// isn't stored by region, it is the main database's collection.
+func (m *MongoDB) RegionalCollection(name string) Collection {
+	key, ok := regionalCollections[name]
+	if !ok || len(m.Regions) == 0 {
+		return m.GetCollection(name)
+	}
+	return &regionalCollection{router: m.router, name: name, key: key}
+}
+
+// regionRouter finds the databases documents of organization data are
+// stored in: the main database or the database of a data residency region
+type regionRouter struct {
+	home    *mongo.Database
+	regions map[string]*mongo.Database
+	// order is the order databases are searched in, the main database
+	// first and then the regions by name
+	order []string
+
+	// collections caches collections by name and region
+	collections sync.Map
+	// orgRegions caches the regions of organizations by ID. An
+	// organization's residency never changes.
+	orgRegions sync.Map
+	// docRegions caches the regions of documents found by ID, by
+	// collection and ID
+	docRegions sync.Map
+}
+
+// newRegionRouter creates a router between the main database and the
+// databases of the data residency regions
+func newRegionRouter(home *mongo.Database, regions map[string]*mongo.Database) *regionRouter {
+	order := make([]string, 0, len(regions)+1)
+	for region := range regions {
+		order = append(order, region)
+	}
+	sort.Strings(order)
+
+	return &regionRouter{
+		home:    home,
+		regions: regions,
+		order:   append([]string{homeRegion}, order...),
+	}
+}
+
+// collection gets a collection of a region
+func (r *regionRouter) collection(name, region string) (*mongo.Collection, error) {
+	key := name + "/" + region
+	if collection, ok := r.collections.Load(key); ok {
+		return collection.(*mongo.Collection), nil
+	}
+
+	database := r.home
+	if region != homeRegion {
+		var ok bool
+		if database, ok = r.regions[region]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownRegion, region)
+		}
+	}
+
+	collection, _ := r.collections.LoadOrStore(key, database.Collection(name))
+	return collection.(*mongo.Collection), nil
+}
+
+// orgRegion finds the region an organization is stored in, searching the
+// databases for organizations not seen before
+func (r *regionRouter) orgRegion(ctx context.Context, orgID string) (string, bool) {
+	if region, ok := r.orgRegions.Load(orgID); ok {
+		return region.(string), true
+	}
+
+	// Organizations are keyed by ObjectIDs or, when created through the
+	// API, by UUIDs
+	ids := bson.A{orgID}
+	if objID, err := primitive.ObjectIDFromHex(orgID); err == nil {
+		ids = append(ids, objID)
+	}
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+	opts := options.FindOne().SetProjection(bson.M{"_id": 1})
+
+	for _, region := range r.order {
+		orgs, err := r.collection(OrganizationsCollection, region)
+		if err != nil {
+			return "", false
+		}
+		err = orgs.FindOne(ctx, filter, opts).Err()
+		if err == nil {
+			r.orgRegions.Store(orgID, region)
+			return region, true
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// regionalCollection is a collection whose documents are stored in the
+// region of their organization. Operations naming the organization, or its
+// residency, go to its region; others search every database, the main one
+// first. Single-document operations stop at the first database with a
+// matching document.
+type regionalCollection struct {
+	router *regionRouter
+	name   string
+	// key is the field naming a document's organization
+	key string
+
+	// method is the repository method reading with the collection, set by
+	// ReadCollection
+	method string
+	read   bool
+}
+
+// collection gets the collection of a region
+func (c *regionalCollection) collection(ctx context.Context, region string) (Collection, error) {
+	collection, err := c.router.collection(c.name, region)
+	if err != nil {
+		return nil, err
+	}
+	if c.read {
+		return ReadCollection(ctx, collection, c.method), nil
+	}
+	return collection, nil
+}
+
+// locate finds the region of the document a filter, or an update's
+// fields, describe: the residency they name, or the region of their
+// organization. Documents found before by ID are located too.
+func (c *regionalCollection) locate(ctx context.Context, docs ...bson.M) (string, bool) {
+	for _, doc := range docs {
+		if residency, ok := doc[ResidencyField].(string); ok {
+			return residency, true
+		}
+	}
+	for _, doc := range docs {
+		if orgID := idString(doc[c.key]); orgID != "" {
+			return c.router.orgRegion(ctx, orgID)
+		}
+	}
+	for _, doc := range docs {
+		if id := idString(doc["_id"]); id != "" {
+			if region, ok := c.router.docRegions.Load(c.name + "/" + id); ok {
+				return region.(string), true
+			}
+		}
+	}
+	return "", false
+}
+
+// remember caches the region a document found by ID is stored in
+func (c *regionalCollection) remember(filter bson.M, region string) {
+	id := idString(filter["_id"])
+	if id == "" {
+		return
+	}
+	if c.key == "_id" {
+		c.router.orgRegions.Store(id, region)
+		return
+	}
+	c.router.docRegions.Store(c.name+"/"+id, region)
+}
+
+// regions returns the regions a filter's documents are searched in
+func (c *regionalCollection) regions(ctx context.Context, docs ...bson.M) []string {
+	if region, ok := c.locate(ctx, docs...); ok {
+		return []string{region}
+	}
+	return c.router.order
+}
+
+// InsertOne inserts a document in the region of its organization
+func (c *regionalCollection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	doc := asDocument(document)
+
+	// Organizations are stored in the region of their residency; other
+	// documents in the region of their organization
+	region := homeRegion
+	if c.key == "_id" {
+		region, _ = doc[ResidencyField].(string)
+	} else if located, ok := c.locate(ctx, doc); ok {
+		region = located
+	}
+
+	collection, err := c.collection(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	result, err := collection.InsertOne(ctx, document, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	c.remember(bson.M{"_id": result.InsertedID}, region)
+	return result, nil
+}
+
+// FindOne finds a single document in the first region that has one
+func (c *regionalCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	filterDoc := asDocument(filter)
+
+	var result *mongo.SingleResult
+	for _, region := range c.regions(ctx, filterDoc) {
+		collection, err := c.collection(ctx, region)
+		if err != nil {
+			return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+		}
+		result = collection.FindOne(ctx, filter, opts...)
+		if err := result.Err(); !errors.Is(err, mongo.ErrNoDocuments) {
+			if err == nil {
+				c.remember(filterDoc, region)
+			}
+			return result
+		}
+	}
+	return result
+}
+
+// Find finds documents. Documents of several regions are merged, then
+// sorted, skipped and limited as one result.
+func (c *regionalCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	regions := c.regions(ctx, asDocument(filter))
+	if len(regions) == 1 {
+		collection, err := c.collection(ctx, regions[0])
+		if err != nil {
+			return nil, err
+		}
+		return collection.Find(ctx, filter, opts...)
+	}
+
+	// Each region returns every document up to the end of the page
+	findOpts := options.MergeFindOptions(opts...)
+	regionOpts := *findOpts
+	regionOpts.Skip = nil
+	var skip, limit int64
+	if findOpts.Skip != nil {
+		skip = *findOpts.Skip
+	}
+	if findOpts.Limit != nil && *findOpts.Limit != 0 {
+		limit = *findOpts.Limit
+		if limit < 0 {
+			limit = -limit
+		}
+		regionLimit := skip + limit
+		regionOpts.Limit = &regionLimit
+	}
+
+	var docs []bson.Raw
+	for _, region := range regions {
+		collection, err := c.collection(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+		cursor, err := collection.Find(ctx, filter, &regionOpts)
+		if err != nil {
+			return nil, err
+		}
+		var found []bson.Raw
+		if err := cursor.All(ctx, &found); err != nil {
+			return nil, err
+		}
+		docs = append(docs, found...)
+	}
+
+	if findOpts.Sort != nil {
+		if err := sortRegionDocuments(docs, findOpts.Sort); err != nil {
+			return nil, err
+		}
+	}
+	if skip >= int64(len(docs)) {
+		docs = nil
+	} else {
+		docs = docs[skip:]
+	}
+	if limit > 0 && limit < int64(len(docs)) {
+		docs = docs[:limit]
+	}
+
+	results := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		results[i] = doc
+	}
+	return mongo.NewCursorFromDocuments(results, nil, nil)
+}
+
+// UpdateOne updates a single document in the first region that has a
+// matching one. Upserts insert in the region of the new document's
+// organization.
+func (c *regionalCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	filterDoc := asDocument(filter)
+	updateDoc := asDocument(update)
+	updateOpts := options.MergeUpdateOptions(opts...)
+
+	docs := []bson.M{filterDoc, asDocument(updateDoc["$setOnInsert"]), asDocument(updateDoc["$set"])}
+	if region, ok := c.locate(ctx, docs...); ok {
+		collection, err := c.collection(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+		return collection.UpdateOne(ctx, filter, update, updateOpts)
+	}
+
+	regionOpts := *updateOpts
+	regionOpts.Upsert = nil
+	result := &mongo.UpdateResult{}
+	for _, region := range c.router.order {
+		collection, err := c.collection(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+		if result, err = collection.UpdateOne(ctx, filter, update, &regionOpts); err != nil {
+			return nil, err
+		}
+		if result.MatchedCount > 0 {
+			c.remember(filterDoc, region)
+			return result, nil
+		}
+	}
+
+	// No region has the document, and an upsert whose organization isn't
+	// known inserts it in the main database
+	if updateOpts.Upsert == nil || !*updateOpts.Upsert {
+		return result, nil
+	}
+	collection, err := c.collection(ctx, homeRegion)
+	if err != nil {
+		return nil, err
+	}
+	return collection.UpdateOne(ctx, filter, update, updateOpts)
+}
+
+// DeleteOne deletes a single document from the first region that has a
+// matching one
+func (c *regionalCollection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	result := &mongo.DeleteResult{}
+	for _, region := range c.regions(ctx, asDocument(filter)) {
+		collection, err := c.collection(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+		if result, err = collection.DeleteOne(ctx, filter, opts...); err != nil {
+			return nil, err
+		}
+		if result.DeletedCount > 0 {
+			return result, nil
+		}
+	}
+	return result, nil
+}
+
+// CountDocuments counts the documents of every region searched
+func (c *regionalCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	regions := c.regions(ctx, asDocument(filter))
+	if len(regions) == 1 {
+		collection, err := c.collection(ctx, regions[0])
+		if err != nil {
+			return 0, err
+		}
+		return collection.CountDocuments(ctx, filter, opts...)
+	}
+
+	// Each region counts up to the end of the counted range
+	countOpts := options.MergeCountOptions(opts...)
+	regionOpts := *countOpts
+	regionOpts.Skip = nil
+	var skip int64
+	if countOpts.Skip != nil {
+		skip = *countOpts.Skip
+	}
+	if countOpts.Limit != nil && *countOpts.Limit > 0 {
+		regionLimit := skip + *countOpts.Limit
+		regionOpts.Limit = &regionLimit
+	}
+
+	var total int64
+	for _, region := range regions {
+		collection, err := c.collection(ctx, region)
+		if err != nil {
+			return 0, err
+		}
+		count, err := collection.CountDocuments(ctx, filter, &regionOpts)
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+
+	total -= skip
+	if total < 0 {
+		total = 0
+	}
+	if countOpts.Limit != nil && *countOpts.Limit > 0 && total > *countOpts.Limit {
+		total = *countOpts.Limit
+	}
+	return total, nil
+}
+
+// Aggregate runs an aggregation pipeline. Pipelines join collections of the
+// main database, such as users, so they only run for organizations stored
+// there, named by the pipeline's first $match stage.
+func (c *regionalCollection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	if region, ok := c.locate(ctx, firstMatch(pipeline)); ok && region == homeRegion {
+		collection, err := c.collection(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+		return Aggregate(ctx, collection, pipeline, opts...)
+	}
+	return nil, ErrAggregationUnsupported
+}
+
+// firstMatch returns the filter of a pipeline's first stage if it is a
+// $match stage
+func firstMatch(pipeline interface{}) bson.M {
+	stages := pipelineStages(pipeline)
+	if len(stages) == 0 {
+		return bson.M{}
+	}
+	return asDocument(asDocument(stages[0])["$match"])
+}
+
+// pipelineStages returns the stages of an aggregation pipeline
+func pipelineStages(pipeline interface{}) []interface{} {
+	var stages []interface{}
+	switch pipeline := pipeline.(type) {
+	case mongo.Pipeline:
+		for _, stage := range pipeline {
+			stages = append(stages, stage)
+		}
+	case []bson.D:
+		for _, stage := range pipeline {
+			stages = append(stages, stage)
+		}
+	case []bson.M:
+		for _, stage := range pipeline {
+			stages = append(stages, stage)
+		}
+	case bson.A:
+		stages = append(stages, pipeline...)
+	case []interface{}:
+		stages = append(stages, pipeline...)
+	}
+	return stages
+}
+
+// asDocument converts a filter, update or document to a map of its
+// fields. Values that aren't documents convert to an empty map, and maps
+// are returned as they are, so callers must not modify the result.
+func asDocument(value interface{}) bson.M {
+	switch value := value.(type) {
+	case nil:
+		return bson.M{}
+	case bson.M:
+		return value
+	case map[string]interface{}:
+		return value
+	}
+
+	raw, err := bson.Marshal(value)
+	if err != nil {
+		return bson.M{}
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return bson.M{}
+	}
+	return doc
+}
+
+// idString returns an ID value as a string, or an empty string for values
+// that aren't a single ID, such as operator documents
+func idString(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case primitive.ObjectID:
+		return value.Hex()
+	}
+	return ""
+}
+
+// sortRegionDocuments sorts documents merged from several regions by a
+// sort specification
+func sortRegionDocuments(docs []bson.Raw, sortSpec interface{}) error {
+	raw, err := bson.Marshal(sortSpec)
+	if err != nil {
+		return err
+	}
+	var keys bson.D
+	if err := bson.Unmarshal(raw, &keys); err != nil {
+		return err
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, key := range keys {
+			direction := 1
+			switch value := key.Value.(type) {
+			case int32:
+				direction = int(value)
+			case int64:
+				direction = int(value)
+			case float64:
+				direction = int(value)
+			default:
+				// Sorting by text score and other metadata isn't merged
+				continue
+			}
+
+			path := strings.Split(key.Key, ".")
+			compared := compareRawValues(docs[i].Lookup(path...), docs[j].Lookup(path...))
+			if compared != 0 {
+				return compared*direction < 0
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+// rawSortRanks orders the BSON types the way MongoDB sorts them; missing
+// values sort as null
+var rawSortRanks = map[bsontype.Type]int{
+	bsontype.MinKey:           0,
+	bsontype.Type(0):          1,
+	bsontype.Null:             1,
+	bsontype.Undefined:        1,
+	bsontype.Int32:            2,
+	bsontype.Int64:            2,
+	bsontype.Double:           2,
+	bsontype.Decimal128:       2,
+	bsontype.String:           3,
+	bsontype.Symbol:           3,
+	bsontype.EmbeddedDocument: 4,
+	bsontype.Array:            5,
+	bsontype.Binary:           6,
+	bsontype.ObjectID:         7,
+	bsontype.Boolean:          8,
+	bsontype.DateTime:         9,
+	bsontype.Timestamp:        10,
+	bsontype.Regex:            11,
+	bsontype.MaxKey:           12,
+}
+
+// compareRawValues compares two BSON values the way MongoDB sorts them
+func compareRawValues(a, b bson.RawValue) int {
+	rankA, rankB := rawSortRanks[a.Type], rawSortRanks[b.Type]
+	if rankA != rankB {
+		return rankA - rankB
+	}
+
+	switch a.Type {
+	case bsontype.Type(0), bsontype.Null, bsontype.Undefined, bsontype.MinKey, bsontype.MaxKey:
+		return 0
+	case bsontype.Int32, bsontype.Int64, bsontype.Double, bsontype.Decimal128:
+		numberA, numberB := rawNumber(a), rawNumber(b)
+		switch {
+		case numberA < numberB:
+			return -1
+		case numberA > numberB:
+			return 1
+		}
+		return 0
+	case bsontype.String, bsontype.Symbol:
+		return strings.Compare(a.StringValue(), b.StringValue())
+	case bsontype.ObjectID:
+		idA, idB := a.ObjectID(), b.ObjectID()
+		return bytes.Compare(idA[:], idB[:])
+	case bsontype.Boolean:
+		switch {
+		case a.Boolean() == b.Boolean():
+			return 0
+		case b.Boolean():
+			return -1
+		}
+		return 1
+	case bsontype.DateTime:
+		timeA, timeB := a.DateTime(), b.DateTime()
+		switch {
+		case timeA < timeB:
+			return -1
+		case timeA > timeB:
+			return 1
+		}
+		return 0
+	}
+	return bytes.Compare(a.Value, b.Value)
+}
+
+// rawNumber returns a numeric BSON value as a float64
+func rawNumber(value bson.RawValue) float64 {
+	switch value.Type {
+	case bsontype.Int32:
+		return float64(value.Int32())
+	case bsontype.Int64:
+		return float64(value.Int64())
+	case bsontype.Double:
+		return value.Double()
+	case bsontype.Decimal128:
+		number, _ := strconv.ParseFloat(value.Decimal128().String(), 64)
+		return number
+	}
+	return 0
+}
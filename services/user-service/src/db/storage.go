@@ -0,0 +1,189 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Storage drivers
+const (
+	DriverMongoDB  = "mongodb"
+	DriverEmbedded = "embedded"
+)
+
+// Collection is the set of document operations repositories rely on.
+// *mongo.Collection satisfies it, as does every other storage driver.
+type Collection interface {
+	InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error)
+	FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult
+	Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error)
+	UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+	CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error)
+}
+
+// Aggregator is implemented by collections that run aggregation pipelines.
+// *mongo.Collection implements it; the embedded driver doesn't.
+type Aggregator interface {
+	Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error)
+}
+
+// ErrAggregationUnsupported is returned by Aggregate for collections of a
+// storage driver without aggregation pipelines
+var ErrAggregationUnsupported = errors.New("storage driver does not support aggregation pipelines")
+
+// Aggregate runs an aggregation pipeline on a collection
+func Aggregate(ctx context.Context, collection Collection, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	aggregator, ok := collection.(Aggregator)
+	if !ok {
+		return nil, ErrAggregationUnsupported
+	}
+	return aggregator.Aggregate(ctx, pipeline, opts...)
+}
+
+// Watcher is implemented by collections that open change streams.
+// *mongo.Collection implements it when MongoDB runs as a replica set; the
+// embedded driver doesn't.
+type Watcher interface {
+	Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error)
+}
+
+// ErrChangeStreamsUnsupported is returned by Watch for collections of a
+// storage driver without change streams
+var ErrChangeStreamsUnsupported = errors.New("storage driver does not support change streams")
+
+// Watch opens a change stream on a collection
+func Watch(ctx context.Context, collection Collection, pipeline interface{}, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	watcher, ok := collection.(Watcher)
+	if !ok {
+		return nil, ErrChangeStreamsUnsupported
+	}
+	return watcher.Watch(ctx, pipeline, opts...)
+}
+
+// BulkWriter is implemented by collections that run several writes in one
+// request. *mongo.Collection implements it; the embedded driver doesn't.
+type BulkWriter interface {
+	BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error)
+}
+
+// BulkWrite runs insert, update and delete models on a collection in one
+// request. Collections of a storage driver without bulk writes run them one
+// at a time, in order, stopping at the first error.
+func BulkWrite(ctx context.Context, collection Collection, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	if len(models) == 0 {
+		return &mongo.BulkWriteResult{}, nil
+	}
+	if writer, ok := collection.(BulkWriter); ok {
+		return writer.BulkWrite(ctx, models, opts...)
+	}
+
+	result := &mongo.BulkWriteResult{UpsertedIDs: make(map[int64]interface{})}
+	for i, model := range models {
+		switch model := model.(type) {
+		case *mongo.InsertOneModel:
+			if _, err := collection.InsertOne(ctx, model.Document); err != nil {
+				return result, err
+			}
+			result.InsertedCount++
+		case *mongo.UpdateOneModel:
+			updateOpts := options.Update()
+			if model.Upsert != nil {
+				updateOpts.SetUpsert(*model.Upsert)
+			}
+			if model.ArrayFilters != nil {
+				updateOpts.SetArrayFilters(*model.ArrayFilters)
+			}
+			updated, err := collection.UpdateOne(ctx, model.Filter, model.Update, updateOpts)
+			if err != nil {
+				return result, err
+			}
+			result.MatchedCount += updated.MatchedCount
+			result.ModifiedCount += updated.ModifiedCount
+			if updated.UpsertedID != nil {
+				result.UpsertedCount++
+				result.UpsertedIDs[int64(i)] = updated.UpsertedID
+			}
+		case *mongo.DeleteOneModel:
+			deleted, err := collection.DeleteOne(ctx, model.Filter)
+			if err != nil {
+				return result, err
+			}
+			result.DeletedCount += deleted.DeletedCount
+		default:
+			return result, fmt.Errorf("storage driver does not support bulk write model %T", model)
+		}
+	}
+	return result, nil
+}
+
+// Storage is a document storage backend
+type Storage interface {
+	// GetCollection returns a collection by name
+	GetCollection(name string) Collection
+	// Ping checks that the backend is reachable
+	Ping(ctx context.Context) error
+	// Close releases the backend's resources
+	Close() error
+	// Driver returns the name of the storage driver
+	Driver() string
+}
+
+// OpenFunc opens a storage backend from configuration
+type OpenFunc func(cfg *config.Config) (Storage, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]OpenFunc)
+)
+
+func init() {
+	RegisterDriver(DriverMongoDB, func(cfg *config.Config) (Storage, error) {
+		return New(&cfg.MongoDB)
+	})
+}
+
+// RegisterDriver makes a storage driver available by name. Drivers
+// built behind build tags register themselves from init.
+func RegisterDriver(name string, open OpenFunc) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers[name] = open
+}
+
+// Drivers returns the names of the registered storage drivers
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open opens the storage backend selected by configuration
+func Open(cfg *config.Config) (Storage, error) {
+	driver := cfg.Storage.Driver
+	if driver == "" {
+		driver = DriverMongoDB
+	}
+
+	driversMu.RLock()
+	open, ok := drivers[driver]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown storage driver %q (available: %v)", driver, Drivers())
+	}
+
+	return open(cfg)
+}
@@ -0,0 +1,265 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TenantCollection returns a collection of organization data, whose
+// documents name their organization in field, that keeps the queries of
+// contexts with an active organization inside it. Filters only match the
+// active organization's documents, and filters, inserts and updates naming
+// another organization fail with tenancy.ErrCrossTenant. Contexts marked
+// with tenancy.Unscoped, such as those of background work, query any, and
+// contexts with neither fail with tenancy.ErrNoTenant.
+func TenantCollection(collection Collection, field string) Collection {
+	return &tenantCollection{collection: collection, field: field}
+}
+
+// tenantCollection is a collection keeping queries inside the active
+// organization of their context
+type tenantCollection struct {
+	collection Collection
+	// field is the field naming a document's organization
+	field string
+}
+
+// tenantIDs returns the values an organization ID is stored as: its string
+// and, for hexadecimal IDs, its ObjectID
+func tenantIDs(orgID string) bson.A {
+	ids := bson.A{orgID}
+	if objID, err := primitive.ObjectIDFromHex(orgID); err == nil {
+		ids = append(ids, objID)
+	}
+	return ids
+}
+
+// crossTenant logs and returns the error of a query naming another
+// organization than the active one
+func (c *tenantCollection) crossTenant(ctx context.Context, orgID string) error {
+	logger.Ctx(ctx).Warn().Str("activeOrgId", tenancy.Organization(ctx)).Str("orgId", orgID).
+		Str("field", c.field).Msg("Rejected cross-tenant query")
+	return tenancy.ErrCrossTenant
+}
+
+// unscoped checks if a context without an active organization may query
+// any, and fails for contexts that have neither
+func (c *tenantCollection) unscoped(ctx context.Context) (bool, error) {
+	if tenancy.Organization(ctx) != "" {
+		return false, nil
+	}
+	if tenancy.IsUnscoped(ctx) {
+		return true, nil
+	}
+	logger.Ctx(ctx).Error().Str("field", c.field).Msg("Rejected query without a tenant")
+	return false, tenancy.ErrNoTenant
+}
+
+// scope narrows a filter to the documents of the active organization
+func (c *tenantCollection) scope(ctx context.Context, filter interface{}) (interface{}, error) {
+	if unscoped, err := c.unscoped(ctx); unscoped || err != nil {
+		return filter, err
+	}
+	active := tenancy.Organization(ctx)
+
+	doc := asDocument(filter)
+	scoped := make(bson.M, len(doc)+1)
+	for key, value := range doc {
+		scoped[key] = value
+	}
+
+	value, ok := scoped[c.field]
+	switch orgID := idString(value); {
+	case !ok:
+		scoped[c.field] = bson.M{"$in": tenantIDs(active)}
+	case orgID != "":
+		if orgID != active {
+			return nil, c.crossTenant(ctx, orgID)
+		}
+	default:
+		// Conditions on the organization, such as $in, also have to
+		// match the active organization
+		scoped = bson.M{"$and": bson.A{scoped, bson.M{c.field: bson.M{"$in": tenantIDs(active)}}}}
+	}
+	return scoped, nil
+}
+
+// checkDocument checks that a document inserted, or the fields an update
+// sets, belong to the active organization
+func (c *tenantCollection) checkDocument(ctx context.Context, document interface{}) error {
+	if unscoped, err := c.unscoped(ctx); unscoped || err != nil {
+		return err
+	}
+	active := tenancy.Organization(ctx)
+
+	var orgID string
+	if scoped, ok := document.(tenancy.Scoped); ok {
+		orgID = scoped.TenantID()
+	} else if orgID = idString(asDocument(document)[c.field]); orgID == "" {
+		return nil
+	}
+	if orgID != active {
+		return c.crossTenant(ctx, orgID)
+	}
+	return nil
+}
+
+// checkUpdate checks that an update doesn't move documents to another
+// organization
+func (c *tenantCollection) checkUpdate(ctx context.Context, update interface{}) error {
+	updateDoc := asDocument(update)
+	for _, operator := range []string{"$set", "$setOnInsert"} {
+		if fields := asDocument(updateDoc[operator]); len(fields) > 0 {
+			if err := c.checkDocument(ctx, fields); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// InsertOne inserts a document of the active organization
+func (c *tenantCollection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	if err := c.checkDocument(ctx, document); err != nil {
+		return nil, err
+	}
+	return c.collection.InsertOne(ctx, document, opts...)
+}
+
+// FindOne finds a single document of the active organization
+func (c *tenantCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	scoped, err := c.scope(ctx, filter)
+	if err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	return c.collection.FindOne(ctx, scoped, opts...)
+}
+
+// Find finds documents of the active organization
+func (c *tenantCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	scoped, err := c.scope(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return c.collection.Find(ctx, scoped, opts...)
+}
+
+// UpdateOne updates a single document of the active organization
+func (c *tenantCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	if err := c.checkUpdate(ctx, update); err != nil {
+		return nil, err
+	}
+	scoped, err := c.scope(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return c.collection.UpdateOne(ctx, scoped, update, opts...)
+}
+
+// DeleteOne deletes a single document of the active organization
+func (c *tenantCollection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	scoped, err := c.scope(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return c.collection.DeleteOne(ctx, scoped, opts...)
+}
+
+// CountDocuments counts the documents of the active organization
+func (c *tenantCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	scoped, err := c.scope(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return c.collection.CountDocuments(ctx, scoped, opts...)
+}
+
+// Aggregate runs an aggregation pipeline on the documents of the active
+// organization, narrowing its first $match stage or starting it with one.
+// Collections the pipeline joins aren't narrowed.
+func (c *tenantCollection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	unscoped, err := c.unscoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if unscoped {
+		return Aggregate(ctx, c.collection, pipeline, opts...)
+	}
+
+	stages := pipelineStages(pipeline)
+	match := bson.M{}
+	if len(stages) > 0 {
+		if filter, ok := asDocument(stages[0])["$match"]; ok {
+			match = asDocument(filter)
+			stages = stages[1:]
+		}
+	}
+	scoped, err := c.scope(ctx, match)
+	if err != nil {
+		return nil, err
+	}
+
+	scopedPipeline := append(bson.A{bson.M{"$match": scoped}}, stages...)
+	return Aggregate(ctx, c.collection, scopedPipeline, opts...)
+}
+
+// BulkWrite runs insert, update and delete models on documents of the
+// active organization
+func (c *tenantCollection) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	unscoped, err := c.unscoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if unscoped {
+		return BulkWrite(ctx, c.collection, models, opts...)
+	}
+
+	scopedModels := make([]mongo.WriteModel, len(models))
+	for i, model := range models {
+		var err error
+		switch model := model.(type) {
+		case *mongo.InsertOneModel:
+			err = c.checkDocument(ctx, model.Document)
+			scopedModels[i] = model
+		case *mongo.UpdateOneModel:
+			scoped := *model
+			if err = c.checkUpdate(ctx, model.Update); err == nil {
+				scoped.Filter, err = c.scope(ctx, model.Filter)
+			}
+			scopedModels[i] = &scoped
+		case *mongo.UpdateManyModel:
+			scoped := *model
+			if err = c.checkUpdate(ctx, model.Update); err == nil {
+				scoped.Filter, err = c.scope(ctx, model.Filter)
+			}
+			scopedModels[i] = &scoped
+		case *mongo.ReplaceOneModel:
+			scoped := *model
+			if err = c.checkDocument(ctx, model.Replacement); err == nil {
+				scoped.Filter, err = c.scope(ctx, model.Filter)
+			}
+			scopedModels[i] = &scoped
+		case *mongo.DeleteOneModel:
+			scoped := *model
+			scoped.Filter, err = c.scope(ctx, model.Filter)
+			scopedModels[i] = &scoped
+		case *mongo.DeleteManyModel:
+			scoped := *model
+			scoped.Filter, err = c.scope(ctx, model.Filter)
+			scopedModels[i] = &scoped
+		default:
+			return nil, fmt.Errorf("tenant collections do not support bulk write model %T", model)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return BulkWrite(ctx, c.collection, scopedModels, opts...)
+}
@@ -3,25 +3,43 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Timezone validation doesn't depend on the host's database
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 	"github.com/your-username/slido-clone/user-service/api/controllers"
+	"github.com/your-username/slido-clone/user-service/api/gql"
 	"github.com/your-username/slido-clone/user-service/api/middleware"
 	"github.com/your-username/slido-clone/user-service/api/routes"
+	"github.com/your-username/slido-clone/user-service/api/rpc"
 	"github.com/your-username/slido-clone/user-service/api/validators"
 	"github.com/your-username/slido-clone/user-service/config"
 	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/migrations"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/cache"
+	"github.com/your-username/slido-clone/user-service/pkg/jobs"
 	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/leader"
+	"github.com/your-username/slido-clone/user-service/pkg/lifecycle"
 	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/presence"
+	"github.com/your-username/slido-clone/user-service/pkg/realtime"
+	"github.com/your-username/slido-clone/user-service/pkg/slo"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+	"github.com/your-username/slido-clone/user-service/pkg/utils"
 	"github.com/your-username/slido-clone/user-service/repositories"
 	"github.com/your-username/slido-clone/user-service/services"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -31,49 +49,234 @@ func main() {
 		fmt.Printf("Failed to load configuration: %v\n", err)
 		os.Exit(1)
 	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Printf("Failed to start: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Initialize logger
 	logger.Init(cfg)
 	log.Info().Msg("Starting User Service")
 	log.Debug().Interface("config", cfg.String()).Msg("Configuration loaded")
 
+	// Check the token claims mapping before serving requests
+	if err := utils.ValidateClaimsMapping(&cfg.JWT.Claims); err != nil {
+		log.Fatal().Err(err).Msg("Invalid JWT claims mapping")
+	}
+
+	// Check the member storage of new organizations
+	switch models.MemberStorage(cfg.Org.MemberStorage) {
+	case models.MemberStorageEmbedded, models.MemberStorageCollection:
+	default:
+		log.Fatal().Str("storage", cfg.Org.MemberStorage).Msg("Invalid organization member storage")
+	}
+
+	// Initialize SLO tracking
+	slo.Init(&cfg.SLO)
+
 	// Set Gin mode
 	gin.SetMode(cfg.Server.GinMode)
 
-	// Create context with cancellation for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
+	// Create context with cancellation for graceful shutdown; background
+	// workers run until it is cancelled, across organizations
+	ctx, cancel := context.WithCancel(tenancy.Unscoped(context.Background()))
 	defer cancel()
 
-	// Connect to MongoDB
-	mongoDB, err := db.New(&cfg.MongoDB)
+	// Components register their shutdown stages as they are created, and
+	// are stopped in the order the stages run in
+	lc := lifecycle.NewManager()
+	shutdownTimeout := cfg.Server.ShutdownTimeout
+
+	// Refresh secrets from the secrets backend, rotating the JWT secret
+	go cfg.WatchSecrets(ctx)
+
+	// Open the storage backend
+	store, err := db.Open(cfg)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to connect to MongoDB")
+		log.Fatal().Err(err).Str("driver", cfg.Storage.Driver).Msg("Failed to open storage backend")
 	}
-	defer mongoDB.Close()
 
 	// Create Kafka producer
 	producer, err := kafka.NewProducer(&cfg.Kafka)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to create Kafka producer")
 	}
-	defer producer.Close()
+
+	// User, team and organization events are published in the background
+	events := kafka.NewAsyncPublisher(producer, &cfg.Kafka)
 
 	// Create Kafka consumer
 	consumer, err := kafka.NewConsumer(&cfg.Kafka)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to create Kafka consumer")
 	}
-	defer consumer.Close()
+
+	// Open the presence store
+	presenceStore := presence.New(&cfg.Presence)
+
+	// Connect to the shared cache whose entries change streams invalidate
+	cacheInvalidator := cache.NewInvalidator(&cfg.Cache)
 
 	// Initialize repositories
-	userRepo := repositories.NewUserRepository(mongoDB)
-	teamRepo := repositories.NewTeamRepository(mongoDB)
-	orgRepo := repositories.NewOrganizationRepository(mongoDB)
+	userRepo := repositories.NewUserRepository(store)
+	teamRepo := repositories.NewTeamRepository(store)
+	orgRepo := repositories.NewOrganizationRepository(store)
+	scimTokenRepo := repositories.NewSCIMTokenRepository(store)
+	joinRequestRepo := repositories.NewJoinRequestRepository(store)
+	leaseRepo := repositories.NewLeaseRepository(store)
+	webhookRepo := repositories.NewWebhookRepository(store)
+	webhookDeliveryRepo := repositories.NewWebhookDeliveryRepository(store)
+	emailTemplateRepo := repositories.NewEmailTemplateRepository(store)
+	processedEventRepo := repositories.NewProcessedEventRepository(store, cfg.Kafka.ProcessedEventTTL)
+	migrationRepo := repositories.NewMigrationRepository(store)
+	timelineRepo := repositories.NewTimelineRepository(store)
+	bannerRepo := repositories.NewBannerRepository(store)
+	tombstoneRepo := repositories.NewTombstoneRepository(store)
+	eventCountRepo := repositories.NewEventCountRepository(store)
+	groupRepo := repositories.NewGroupRepository(store)
+	sessionRepo := repositories.NewSessionRepository(store)
+	activityRepo := repositories.NewActivityRepository(store)
+	digestRepo := repositories.NewDigestRepository(store)
+	inboxRepo := repositories.NewInboxRepository(store)
+	impersonationRepo := repositories.NewImpersonationRepository(store)
+	auditRepo := repositories.NewAuditRepository(store)
+	changeStreamRepo := repositories.NewChangeStreamRepository(store)
+	settingsHistoryRepo := repositories.NewSettingsHistoryRepository(store)
+	featureFlagRepo := repositories.NewFeatureFlagRepository(store)
+	ldapSyncRepo := repositories.NewLDAPSyncRepository(store)
+	verificationRepo := repositories.NewVerificationRepository(store)
+	teamJoinRequestRepo := repositories.NewTeamJoinRequestRepository(store)
+	teamTemplateRepo := repositories.NewTeamTemplateRepository(store)
+	exportRepo := repositories.NewOrganizationExportRepository(store)
+	jobRunRepo := repositories.NewJobRunRepository(store)
+	historyRepo := repositories.NewOrganizationHistoryRepository(store)
+	eventRepo := repositories.NewEventRepository(store)
+
+	// Keep leader election and job leases in storage, or in Redis when configured
+	var leaseStore leader.LeaseStore = leaseRepo
+	if strings.ToLower(cfg.Leader.Backend) == config.LeaseBackendRedis {
+		leaseStore = leader.NewRedisLeaseStore(&cfg.Leader)
+	}
 
 	// Initialize services
-	userService := services.NewUserService(userRepo, producer)
-	teamService := services.NewTeamService(teamRepo, userRepo, orgRepo, producer)
-	orgService := services.NewOrganizationService(orgRepo, userRepo, teamRepo, producer)
+	signupReviewService := services.NewSignupReviewService(userRepo, events, &cfg.Signup)
+	syncService := services.NewSyncService(userRepo, teamRepo, orgRepo, tombstoneRepo, &cfg.Sync)
+	userService := services.NewUserService(userRepo, orgRepo, teamRepo, signupReviewService, syncService, events)
+	presenceService := services.NewPresenceService(presenceStore, userRepo, &cfg.Presence)
+	teamService := services.NewTeamService(teamRepo, userRepo, orgRepo, groupRepo, teamJoinRequestRepo, events, syncService)
+	orgService := services.NewOrganizationService(orgRepo, userRepo, teamRepo, joinRequestRepo, settingsHistoryRepo, events, syncService, presenceService, &cfg.Org)
+	scimService := services.NewSCIMService(scimTokenRepo, userRepo, teamRepo, orgRepo, verificationRepo, orgService, events, syncService)
+	webhookService := services.NewWebhookService(webhookRepo, webhookDeliveryRepo, orgRepo)
+	emailTemplateService := services.NewEmailTemplateService(emailTemplateRepo, orgRepo, events)
+	permissionService := services.NewPermissionService(orgRepo, teamRepo)
+	migrator, err := migrations.New(store, migrationRepo, migrations.All(&cfg.Org))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid migrations")
+	}
+	timelineService := services.NewTimelineService(timelineRepo, orgRepo, teamRepo)
+	historyService := services.NewOrganizationHistoryService(historyRepo, orgRepo)
+	memberStorageService := services.NewMemberStorageService(orgRepo, &cfg.Org)
+	bannerService := services.NewBannerService(bannerRepo, &cfg.Banner)
+	replayService := services.NewReplayService(userRepo, orgRepo, teamRepo, producer, &cfg.Replay)
+	groupService := services.NewGroupService(groupRepo, orgRepo)
+	sessionService := services.NewSessionService(sessionRepo, events)
+	activityService := services.NewActivityService(activityRepo, orgRepo, teamRepo)
+	inboxService := services.NewInboxService(inboxRepo, &cfg.Notify)
+	profileStreamService := services.NewProfileStreamService(userRepo, orgRepo)
+	realtimeHub := realtime.NewHub(&cfg.Realtime)
+	realtimeService := services.NewRealtimeService(realtimeHub, orgRepo, teamRepo, presenceService, events, &cfg.Presence)
+	notificationService := services.NewNotificationService(digestRepo, userRepo, inboxService, emailTemplateService, producer, &cfg.Notify)
+	subscriptionService := services.NewSubscriptionService(orgRepo, events)
+	directoryService := services.NewDirectoryService(orgRepo)
+	impersonationService := services.NewImpersonationService(impersonationRepo, auditRepo, userRepo, events, &cfg.JWT, &cfg.Support)
+	statsService := services.NewStatsService(orgRepo, userRepo, teamRepo, timelineRepo, eventCountRepo, &cfg.Stats)
+	featureFlagService := services.NewFeatureFlagService(featureFlagRepo, orgRepo, userRepo, events, &cfg.Flags)
+	onboardingService := services.NewOnboardingService(userRepo, events)
+	twoFactorService := services.NewTwoFactorService(userRepo)
+	ldapSyncService := services.NewLDAPSyncService(ldapSyncRepo, userRepo, orgRepo, teamRepo, orgService, events, syncService, &cfg.LDAP)
+	directoryImportService := services.NewDirectoryImportService(userRepo, orgRepo, orgService, &cfg.Import)
+	verificationService := services.NewVerificationService(verificationRepo, orgRepo, events)
+	teamTemplateService := services.NewTeamTemplateService(teamTemplateRepo, orgRepo, teamService)
+	exportService := services.NewOrganizationExportService(exportRepo, orgRepo, teamRepo, timelineRepo, orgService, events, &cfg.Export)
+	importService := services.NewOrganizationImportService(userRepo, orgRepo, orgService, teamService, &cfg.Export)
+	eventService := services.NewEventService(eventRepo, orgRepo, events)
+	changeStreamService := services.NewChangeStreamService(store, changeStreamRepo, events, cacheInvalidator, &cfg.Changes)
+
+	// Queue webhook deliveries, record organization timelines, histories and
+	// user activity, advance onboarding, dispatch notifications and count
+	// every published event
+	background := tenancy.Unscoped(context.Background())
+	producer.OnPublish(func(event kafka.Event) {
+		lifecycle.Go(func() { webhookService.HandleEvent(background, event) })
+		lifecycle.Go(func() { timelineService.HandleEvent(background, event) })
+		lifecycle.Go(func() { historyService.HandleEvent(background, event) })
+
+		// Activity, onboarding, notifications and stats handle members one
+		// at a time, so they see a member batch as the events it replaces
+		events, err := kafka.ExpandMemberBatch(event)
+		if err != nil {
+			log.Error().Err(err).Str("eventId", event.ID).Str("type", string(event.Type)).Msg("Failed to expand member batch event")
+			return
+		}
+		for _, event := range events {
+			lifecycle.Go(func() { activityService.HandleEvent(background, event) })
+			lifecycle.Go(func() { onboardingService.HandleEvent(background, event) })
+			lifecycle.Go(func() { notificationService.HandleEvent(background, event) })
+			lifecycle.Go(func() { statsService.RecordEvent(background, event) })
+		}
+	})
+
+	// Elect the instance that runs singleton background workers; workers
+	// register with elector.RunSingleton so only the leader runs them
+	elector := leader.NewElector(leaseStore, leader.DefaultLease, &cfg.Leader)
+	go elector.Run(ctx)
+	elector.RunSingleton(ctx, "webhook-dispatcher", webhookService.RunDispatcher)
+	elector.RunSingleton(ctx, "migrations", migrator.Run)
+	elector.RunSingleton(ctx, "member-storage", memberStorageService.RunMover)
+	elector.RunSingleton(ctx, "member-expiry", orgService.RunMemberExpiry)
+	elector.RunSingleton(ctx, "notification-digests", notificationService.RunDigests)
+	elector.RunSingleton(ctx, "change-streams", changeStreamService.RunListener)
+	elector.RunSingleton(ctx, "ldap-sync", ldapSyncService.RunScheduler)
+	elector.RunSingleton(ctx, "export-cleanup", exportService.RunCleanup)
+
+	// Run scheduled jobs; every instance runs the scheduler, and each run
+	// takes the job's lease so only one instance runs it
+	scheduler := jobs.NewScheduler(leaseStore, jobRunRepo, elector.ID(), cfg.Jobs.HistoryRetention)
+	if cfg.Jobs.UserPurgeAfter > 0 {
+		schedule, err := jobs.ParseSchedule(cfg.Jobs.UserPurgeSchedule)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid user purge schedule")
+		}
+		purgeAfter := cfg.Jobs.UserPurgeAfter
+		if err := scheduler.Register(jobs.Job{
+			Name:        "user-purge",
+			Description: "Purges users soft deleted for longer than the purge delay",
+			Schedule:    schedule,
+			Timeout:     cfg.Jobs.Timeout,
+			Run: func(ctx context.Context) error {
+				_, err := userService.PurgeDeletedUsers(ctx, time.Now().Add(-purgeAfter))
+				return err
+			},
+		}); err != nil {
+			log.Fatal().Err(err).Msg("Failed to register job")
+		}
+	}
+	jobService := services.NewJobService(scheduler, jobRunRepo)
+	jobsDone := make(chan struct{})
+	go func() {
+		defer close(jobsDone)
+		if cfg.Jobs.Enabled {
+			scheduler.Run(ctx)
+		}
+	}()
+
+	// Every instance serves the banner and feature flags from memory, so each
+	// reloads them
+	go bannerService.RunRefresher(ctx)
+	go featureFlagService.RunRefresher(ctx)
+
+	// Skip events redelivered after they were handled
+	consumer.UseIdempotencyStore(processedEventRepo)
 
 	// Register Kafka event handlers
 	consumer.RegisterHandler(
@@ -81,17 +284,116 @@ func main() {
 		kafka.UserCreated,
 		userService.ProcessAuthUserCreated,
 	)
+	consumer.RegisterHandler(
+		cfg.Kafka.Topics.AuthEvents,
+		kafka.UserUpdated,
+		userService.ProcessAuthUserUpdated,
+	)
+	consumer.RegisterHandler(
+		cfg.Kafka.Topics.AuthEvents,
+		kafka.UserEmailChanged,
+		userService.ProcessAuthUserEmailChanged,
+	)
+	consumer.RegisterHandler(
+		cfg.Kafka.Topics.AuthEvents,
+		kafka.UserEmailChangeConfirmed,
+		userService.ProcessAuthEmailChangeConfirmed,
+	)
+	consumer.RegisterHandler(
+		cfg.Kafka.Topics.AuthEvents,
+		kafka.UserTwoFactorChanged,
+		twoFactorService.ProcessAuthUserTwoFactorChanged,
+	)
+	consumer.RegisterHandler(
+		cfg.Kafka.Topics.AuthEvents,
+		kafka.UserDeleted,
+		userService.ProcessAuthUserDeleted,
+	)
+	consumer.RegisterHandler(
+		cfg.Kafka.Topics.AuthEvents,
+		kafka.SessionCreated,
+		sessionService.ProcessAuthSessionCreated,
+	)
+	consumer.RegisterHandler(
+		cfg.Kafka.Topics.AuthEvents,
+		kafka.SessionRefreshed,
+		sessionService.ProcessAuthSessionRefreshed,
+	)
+	consumer.RegisterHandler(
+		cfg.Kafka.Topics.AuthEvents,
+		kafka.SessionTerminated,
+		sessionService.ProcessAuthSessionTerminated,
+	)
+	consumer.RegisterHandler(
+		cfg.Kafka.Topics.BillingEvents,
+		kafka.BillingSubscriptionUpdated,
+		subscriptionService.ProcessBillingSubscriptionUpdated,
+	)
 
 	// Start Kafka consumer
 	if err := consumer.Start(ctx); err != nil {
 		log.Error().Err(err).Msg("Failed to start Kafka consumer")
 	}
 
+	// Every instance reads every user and team event for the profile streams
+	// and realtime connections it serves, in a consumer group of its own that
+	// starts at the latest events
+	streamKafkaCfg := cfg.Kafka
+	streamKafkaCfg.GroupID = cfg.Kafka.StreamGroupID + "-" + elector.ID()
+	streamKafkaCfg.AutoOffsetReset = "latest"
+	streamConsumer, err := kafka.NewConsumer(&streamKafkaCfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create Kafka stream consumer")
+	}
+	streamEvent := func(ctx context.Context, event kafka.Event) error {
+		if err := profileStreamService.HandleEvent(ctx, event); err != nil {
+			return err
+		}
+		return realtimeService.HandleEvent(ctx, event)
+	}
+	streamConsumer.RegisterHandler(cfg.Kafka.Topics.UserEvents, "*", streamEvent)
+	streamConsumer.RegisterHandler(cfg.Kafka.Topics.TeamEvents, "*", streamEvent)
+	if err := streamConsumer.Start(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to start Kafka stream consumer")
+	}
+
 	// Initialize controllers
 	userController := controllers.NewUserController(userService)
 	teamController := controllers.NewTeamController(teamService)
 	orgController := controllers.NewOrganizationController(orgService)
-	profileController := controllers.NewProfileController(userService, teamService, orgService)
+	profileController := controllers.NewProfileController(userService, teamService, orgService, permissionService, sessionService, presenceService, onboardingService)
+	scimController := controllers.NewSCIMController(scimService)
+	webhookController := controllers.NewWebhookController(webhookService)
+	emailTemplateController := controllers.NewEmailTemplateController(emailTemplateService)
+	signupReviewController := controllers.NewSignupReviewController(signupReviewService)
+	timelineController := controllers.NewTimelineController(timelineService)
+	historyController := controllers.NewOrganizationHistoryController(historyService)
+	replayController := controllers.NewReplayController(replayService)
+	memberStorageController := controllers.NewMemberStorageController(memberStorageService)
+	bannerController := controllers.NewBannerController(bannerService)
+	syncController := controllers.NewSyncController(syncService)
+	statsController := controllers.NewStatsController(statsService)
+	groupController := controllers.NewGroupController(groupService)
+	activityController := controllers.NewActivityController(activityService)
+	inboxController := controllers.NewInboxController(inboxService)
+	profileStreamController := controllers.NewProfileStreamController(profileStreamService)
+	realtimeController := controllers.NewRealtimeController(realtimeService)
+	subscriptionController := controllers.NewSubscriptionController(subscriptionService)
+	directoryController := controllers.NewDirectoryController(directoryService)
+	impersonationController := controllers.NewImpersonationController(impersonationService)
+	featureFlagController := controllers.NewFeatureFlagController(featureFlagService)
+	ldapSyncController := controllers.NewLDAPSyncController(ldapSyncService)
+	directoryImportController := controllers.NewDirectoryImportController(directoryImportService)
+	verificationController := controllers.NewVerificationController(verificationService)
+	teamTemplateController := controllers.NewTeamTemplateController(teamTemplateService)
+	exportController := controllers.NewOrganizationExportController(exportService)
+	importController := controllers.NewOrganizationImportController(importService)
+	jobController := controllers.NewJobController(jobService)
+	eventController := controllers.NewEventController(eventService)
+	metaController := controllers.NewMetaController()
+
+	// Initialize GraphQL handler
+	graphqlHandler := gql.NewHandler(userRepo, orgRepo, teamRepo, teamService, orgService, &cfg.GraphQL)
 
 	// Initialize validators
 	validators.InitUserValidators()
@@ -104,57 +406,193 @@ func main() {
 	router.Use(gin.Recovery())
 	router.Use(middleware.Logger())
 	router.Use(middleware.RequestID())
-
-	// Configure CORS
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{cfg.CORS.AllowedOrigins},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+	router.Use(middleware.SLO())
+	router.Use(middleware.Banner(bannerService))
+	router.Use(middleware.SecurityHeaders(&cfg.Security))
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Impersonation(&cfg.JWT, impersonationService))
+	router.Use(middleware.Tenancy(map[string]middleware.TenantResolver{
+		"teams": func(ctx context.Context, id string) (string, error) {
+			team, err := teamService.GetTeamByID(ctx, id)
+			if err != nil {
+				return "", err
+			}
+			return team.OrganizationID, nil
+		},
 	}))
 
+	// Configure CORS; public endpoints don't need credentials
+	corsPolicy := middleware.NewCORSPolicy(&cfg.CORS, "/api/directory", "/api/openapi.json", "/api/docs", "/health", "/system")
+	router.Use(corsPolicy.Handler())
+
+	// Apply the reloadable settings on SIGHUP or config file changes
+	reloader := config.NewReloader(cfg)
+	reloader.OnReload(func(cfg *config.Config) {
+		logger.SetLevel(cfg.Logging.Level)
+		corsPolicy.Update(&cfg.CORS)
+		replayService.SetRateLimit(cfg.Replay.RateLimit)
+	})
+	go reloader.Watch(ctx)
+
+	// Protect admin UI cookie sessions against CSRF
+	router.Use(middleware.CSRF(&cfg.Security, &cfg.JWT))
+
 	// Register routes
 	apiGroup := router.Group("/api")
 	routes.RegisterUserRoutes(apiGroup, userController, &cfg.JWT)
 	routes.RegisterTeamRoutes(apiGroup, teamController, &cfg.JWT)
 	routes.RegisterOrganizationRoutes(apiGroup, orgController, &cfg.JWT)
 	routes.RegisterProfileRoutes(apiGroup, profileController, &cfg.JWT)
-	routes.RegisterHealthRoutes(router.Group("/health"), mongoDB, producer)
+	routes.RegisterSCIMTokenRoutes(apiGroup, scimController, &cfg.JWT)
+	routes.RegisterWebhookRoutes(apiGroup, webhookController, &cfg.JWT)
+	routes.RegisterEmailTemplateRoutes(apiGroup, emailTemplateController, &cfg.JWT)
+	routes.RegisterSignupReviewRoutes(apiGroup, signupReviewController, &cfg.JWT)
+	routes.RegisterTimelineRoutes(apiGroup, timelineController, &cfg.JWT)
+	routes.RegisterOrganizationHistoryRoutes(apiGroup, historyController, &cfg.JWT)
+	routes.RegisterReplayRoutes(apiGroup, replayController, &cfg.JWT)
+	routes.RegisterMemberStorageRoutes(apiGroup, memberStorageController, &cfg.JWT)
+	routes.RegisterBannerRoutes(apiGroup, bannerController, &cfg.JWT)
+	routes.RegisterSyncRoutes(apiGroup, syncController, &cfg.JWT)
+	routes.RegisterStatsRoutes(apiGroup, statsController, &cfg.JWT)
+	routes.RegisterGroupRoutes(apiGroup, groupController, &cfg.JWT)
+	routes.RegisterActivityRoutes(apiGroup, activityController, &cfg.JWT)
+	routes.RegisterInboxRoutes(apiGroup, inboxController, &cfg.JWT)
+	routes.RegisterProfileStreamRoutes(apiGroup, profileStreamController, &cfg.JWT)
+	routes.RegisterRealtimeRoutes(apiGroup, realtimeController, corsPolicy, &cfg.JWT)
+	routes.RegisterSubscriptionRoutes(apiGroup, subscriptionController, &cfg.JWT)
+	routes.RegisterDirectoryRoutes(apiGroup, directoryController, &cfg.JWT)
+	routes.RegisterImpersonationRoutes(apiGroup, impersonationController, &cfg.JWT)
+	routes.RegisterFeatureFlagRoutes(apiGroup, featureFlagController, &cfg.JWT)
+	routes.RegisterLDAPSyncRoutes(apiGroup, ldapSyncController, &cfg.JWT)
+	routes.RegisterDirectoryImportRoutes(apiGroup, directoryImportController, &cfg.JWT)
+	routes.RegisterVerificationRoutes(apiGroup, verificationController, &cfg.JWT)
+	routes.RegisterTeamTemplateRoutes(apiGroup, teamTemplateController, &cfg.JWT)
+	routes.RegisterOrganizationExportRoutes(apiGroup, exportController, &cfg.JWT)
+	routes.RegisterOrganizationImportRoutes(apiGroup, importController, &cfg.JWT)
+	routes.RegisterJobRoutes(apiGroup, jobController, &cfg.JWT)
+	routes.RegisterEventRoutes(apiGroup, eventController, &cfg.JWT)
+	routes.RegisterMetaRoutes(apiGroup, metaController)
+	routes.RegisterOpenAPIRoutes(apiGroup, cfg.Server.GinMode)
+	routes.RegisterGraphQLRoutes(router.Group("/graphql"), graphqlHandler, &cfg.JWT)
+	routes.RegisterInternalEmailTemplateRoutes(router.Group("/internal"), emailTemplateController, &cfg.Internal)
+	routes.RegisterSCIMRoutes(router.Group(services.SCIMBasePath), scimController, scimService)
+	routes.RegisterHealthRoutes(router.Group("/health"), store, producer, consumer)
+	routes.RegisterSystemRoutes(router.Group("/system"), bannerController)
+	routes.RegisterMetricsRoutes(router.Group("/metrics"), events, realtimeHub)
+	routes.RegisterSLORoutes(router.Group("/admin"), &cfg.JWT)
+	routes.RegisterConfigRoutes(router.Group("/admin"), reloader, &cfg.JWT)
+
+	// Unknown routes get the standard error body too
+	router.NoRoute(func(c *gin.Context) {
+		_ = c.Error(apperrors.NotFound("ROUTE_NOT_FOUND", "route not found"))
+	})
 
-	// Start server
+	// Serve HTTP, stopping to accept requests first on shutdown
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%s", cfg.Server.Port),
 		Handler: router,
 	}
-
-	// Graceful shutdown
-	go func() {
+	// Inbox and profile streams don't end on their own, and the server doesn't
+	// track realtime connections, so end them for the shutdown
+	srv.RegisterOnShutdown(inboxService.Close)
+	srv.RegisterOnShutdown(profileStreamService.Close)
+	srv.RegisterOnShutdown(realtimeService.Close)
+	lc.Serve("http", func() error {
+		log.Info().Str("port", cfg.Server.Port).Msg("Server started")
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal().Err(err).Msg("Failed to start server")
+			return err
 		}
-	}()
+		return nil
+	})
+	lc.Stage("http", shutdownTimeout, srv.Shutdown)
 
-	log.Info().Str("port", cfg.Server.Port).Msg("Server started")
+	// Start the gRPC server for internal lookups alongside the HTTP server
+	if cfg.Internal.GRPCPort != "" {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.Internal.GRPCPort))
+		if err != nil {
+			log.Fatal().Err(err).Str("port", cfg.Internal.GRPCPort).Msg("Failed to listen for gRPC")
+		}
 
-	// Wait for interrupt signal to gracefully shut down the server
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+		userServer := rpc.NewUserServer(userService, orgService, permissionService, &cfg.Internal)
+		grpcServer := rpc.NewServer(&cfg.Internal, userServer)
+		lc.Serve("grpc", func() error {
+			log.Info().Str("port", cfg.Internal.GRPCPort).Msg("gRPC server started")
+			return grpcServer.Serve(lis)
+		})
+		lc.Stage("grpc", shutdownTimeout, func(ctx context.Context) error {
+			stopGRPC(ctx, grpcServer)
+			return nil
+		})
+	}
 
-	log.Info().Msg("Shutting down server...")
+	// Then stop the background workers, jobs and replays, drain the Kafka
+	// consumer, committing the offsets of handled messages, and wait for the
+	// events being published and handled to be flushed before closing the stores
+	lc.Stage("workers", shutdownTimeout, func(ctx context.Context) error {
+		cancel()
+		replayService.Stop()
 
-	// Cancel context to stop Kafka consumer and other background tasks
-	cancel()
+		// Wait for the cancelled job runs to record their outcome
+		select {
+		case <-jobsDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	lc.Stage("kafka-consumer", cfg.Kafka.DrainTimeout+shutdownTimeout, func(context.Context) error {
+		consumer.Close()
+		streamConsumer.Close()
+		return nil
+	})
+	lc.Stage("event-publisher", shutdownTimeout, events.Close)
+	lc.Stage("background-tasks", shutdownTimeout, lifecycle.Wait)
+	lc.Stage("kafka-producer", shutdownTimeout, func(ctx context.Context) error {
+		err := producer.Flush(ctx)
+		producer.Close()
+		return err
+	})
+	lc.Stage("presence", shutdownTimeout, func(context.Context) error {
+		return presenceStore.Close()
+	})
+	lc.Stage("cache", shutdownTimeout, func(context.Context) error {
+		return cacheInvalidator.Close()
+	})
+	lc.Stage("leases", shutdownTimeout, func(context.Context) error {
+		if closer, ok := leaseStore.(io.Closer); ok {
+			return closer.Close()
+		}
+		return nil
+	})
+	lc.Stage("storage", shutdownTimeout, func(context.Context) error {
+		return store.Close()
+	})
 
-	// Create a deadline for server shutdown
-	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	// Run until SIGINT or SIGTERM, or until a server fails
+	signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal().Err(err).Msg("Server forced to shutdown")
+	if err := lc.Run(signalCtx); err != nil {
+		log.Error().Err(err).Msg("Server stopped with errors")
+		os.Exit(1)
 	}
 
 	log.Info().Msg("Server exiting")
 }
+
+// stopGRPC stops the gRPC server after in-flight calls finish, cancelling
+// them if they outlast the shutdown deadline
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Warn().Msg("gRPC server forced to stop")
+		server.Stop()
+	}
+}
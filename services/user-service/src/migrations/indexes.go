@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// indexNotFoundCode is the MongoDB error code for dropping a missing index
+const indexNotFoundCode = 27
+
+// indexer is implemented by collections with indexes; *mongo.Collection
+// implements it
+type indexer interface {
+	Indexes() mongo.IndexView
+}
+
+// indexes gets the index view of a collection, or false if the storage
+// driver has no indexes to manage
+func indexes(ctx context.Context, store db.Storage, collection string) (mongo.IndexView, bool) {
+	c, ok := store.GetCollection(collection).(indexer)
+	if !ok {
+		logger.Ctx(ctx).Debug().Str("driver", store.Driver()).Str("collection", collection).
+			Msg("Storage driver has no indexes, skipping index change")
+		return mongo.IndexView{}, false
+	}
+	return c.Indexes(), true
+}
+
+// CreateIndexes creates indexes on a collection. Creating an index that
+// already exists with the same options does nothing, so migrations adding
+// indexes are idempotent.
+func CreateIndexes(ctx context.Context, store db.Storage, collection string, models ...mongo.IndexModel) error {
+	view, ok := indexes(ctx, store, collection)
+	if !ok {
+		return nil
+	}
+	_, err := view.CreateMany(ctx, models)
+	return err
+}
+
+// DropIndex drops an index of a collection by name. Dropping an index that
+// doesn't exist does nothing.
+func DropIndex(ctx context.Context, store db.Storage, collection, name string) error {
+	view, ok := indexes(ctx, store, collection)
+	if !ok {
+		return nil
+	}
+	if _, err := view.DropOne(ctx, name); err != nil && !isIndexNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// isIndexNotFound checks if an error is MongoDB's IndexNotFound
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Code == indexNotFoundCode
+	}
+	return false
+}
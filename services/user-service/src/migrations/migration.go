@@ -0,0 +1,208 @@
+// Package migrations evolves the stored data and its indexes with ordered,
+// versioned migrations. Applied migrations are recorded in the
+// schema_migrations collection, so each runs once.
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+	"github.com/your-username/slido-clone/user-service/repositories"
+)
+
+// Migration is a versioned change to the stored data or its indexes.
+// Migrations must be idempotent: one that fails is run again the next time
+// migrations run.
+type Migration struct {
+	Version int
+	Name    string
+	// Up applies the migration
+	Up func(ctx context.Context, store db.Storage) error
+	// Plan describes what Up would change, for dry runs. It must not change
+	// anything; migrations without one are only listed.
+	Plan func(ctx context.Context, store db.Storage) (string, error)
+}
+
+// ID identifies a migration in the schema_migrations collection
+func (m Migration) ID() string {
+	return fmt.Sprintf("%04d-%s", m.Version, m.Name)
+}
+
+// Options are the options of a migration run
+type Options struct {
+	// DryRun plans the pending migrations without applying them
+	DryRun bool
+	// Target is the last version to apply; 0 applies every migration
+	Target int
+}
+
+// Result is the outcome of running a migration
+type Result struct {
+	Migration Migration
+	// Plan is what a dry run would change
+	Plan     string
+	Duration time.Duration
+}
+
+// Status is a migration and when it was applied
+type Status struct {
+	Migration Migration
+	// AppliedAt is nil if the migration is pending
+	AppliedAt *time.Time
+}
+
+// Migrator runs migrations that haven't been applied yet
+type Migrator struct {
+	store         db.Storage
+	migrationRepo *repositories.MigrationRepository
+	migrations    []Migration
+}
+
+// New creates a migrator of migrations, which must be in increasing version
+// order
+func New(store db.Storage, migrationRepo *repositories.MigrationRepository, migrations []Migration) (*Migrator, error) {
+	for i, migration := range migrations {
+		if migration.Version < 1 || migration.Name == "" || migration.Up == nil {
+			return nil, fmt.Errorf("migration %d: version, name and Up are required", i)
+		}
+		if i > 0 && migration.Version <= migrations[i-1].Version {
+			return nil, fmt.Errorf("migration %s: versions must increase, after %s", migration.ID(), migrations[i-1].ID())
+		}
+	}
+
+	return &Migrator{
+		store:         store,
+		migrationRepo: migrationRepo,
+		migrations:    migrations,
+	}, nil
+}
+
+// Status lists the migrations and when they were applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.adoptLegacy(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.migrationRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[string]time.Time, len(applied))
+	for _, migration := range applied {
+		appliedAt[migration.ID] = migration.AppliedAt
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if at, ok := appliedAt[migration.ID()]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Up applies the pending migrations in order, up to the target version. It
+// stops at the first failure so later migrations never run before the ones
+// they may depend on. A dry run plans the pending migrations instead.
+func (m *Migrator) Up(ctx context.Context, opts Options) ([]Result, error) {
+	// Migrations rewrite the data of every organization
+	ctx = tenancy.Unscoped(ctx)
+	if err := m.adoptLegacy(ctx); err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, migration := range m.migrations {
+		if opts.Target > 0 && migration.Version > opts.Target {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		applied, err := m.migrationRepo.IsApplied(ctx, migration.ID())
+		if err != nil {
+			return results, fmt.Errorf("checking migration %s: %w", migration.ID(), err)
+		}
+		if applied {
+			continue
+		}
+
+		if opts.DryRun {
+			result := Result{Migration: migration}
+			if migration.Plan != nil {
+				if result.Plan, err = migration.Plan(ctx, m.store); err != nil {
+					return results, fmt.Errorf("planning migration %s: %w", migration.ID(), err)
+				}
+			}
+			results = append(results, result)
+			continue
+		}
+
+		logger.Ctx(ctx).Info().Str("migration", migration.ID()).Msg("Applying migration")
+		startTime := time.Now()
+
+		if err := migration.Up(ctx, m.store); err != nil {
+			return results, fmt.Errorf("applying migration %s: %w", migration.ID(), err)
+		}
+		duration := time.Since(startTime)
+
+		if err := m.migrationRepo.MarkApplied(ctx, &models.Migration{
+			ID:         migration.ID(),
+			Version:    migration.Version,
+			Name:       migration.Name,
+			AppliedAt:  time.Now(),
+			DurationMs: duration.Milliseconds(),
+		}); err != nil {
+			return results, fmt.Errorf("recording migration %s: %w", migration.ID(), err)
+		}
+
+		logger.Ctx(ctx).Info().Str("migration", migration.ID()).Dur("duration", duration).Msg("Migration applied")
+		results = append(results, Result{Migration: migration, Duration: duration})
+	}
+	return results, nil
+}
+
+// adoptLegacy records the migrations applied before migrations were
+// versioned as applied, so they don't run again. They were recorded in the
+// migrations collection under the IDs of the migrations that replaced them.
+func (m *Migrator) adoptLegacy(ctx context.Context) error {
+	legacy, err := m.migrationRepo.ListLegacy(ctx)
+	if err != nil {
+		return fmt.Errorf("listing legacy migrations: %w", err)
+	}
+	appliedAt := make(map[string]time.Time, len(legacy))
+	for _, migration := range legacy {
+		appliedAt[migration.ID] = migration.AppliedAt
+	}
+
+	for _, migration := range m.migrations {
+		at, ok := appliedAt[migration.ID()]
+		if !ok {
+			continue
+		}
+		if err := m.migrationRepo.MarkApplied(ctx, &models.Migration{
+			ID:        migration.ID(),
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: at,
+		}); err != nil {
+			return fmt.Errorf("recording legacy migration %s: %w", migration.ID(), err)
+		}
+	}
+	return nil
+}
+
+// Run applies every pending migration, logging a failure. It suits running
+// migrations as a background worker.
+func (m *Migrator) Run(ctx context.Context) {
+	if _, err := m.Up(ctx, Options{}); err != nil && ctx.Err() == nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Migration failed, stopping migrations")
+	}
+}
@@ -0,0 +1,315 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// All returns the service's migrations, in the order they run. Add new
+// migrations at the end with the next version; never change or reorder
+// migrations that have shipped. Indexes added or dropped after the initial
+// ones belong here too, using CreateIndexes and DropIndex. orgConfig sets the
+// member storage layout that existing organizations are moved to.
+func All(orgConfig *config.OrganizationConfig) []Migration {
+	return []Migration{
+		{
+			Version: 1,
+			Name:    "remove-duplicate-organization-members",
+			Up: func(ctx context.Context, store db.Storage) error {
+				_, err := repositories.NewOrganizationRepository(store).RemoveDuplicateMembers(ctx)
+				return err
+			},
+		},
+		{
+			Version: 2,
+			Name:    "remove-duplicate-team-members",
+			Up: func(ctx context.Context, store db.Storage) error {
+				_, err := repositories.NewTeamRepository(store).RemoveDuplicateMembers(ctx)
+				return err
+			},
+		},
+		{
+			Version: 3,
+			Name:    "search-and-membership-indexes",
+			Up:      createSearchAndMembershipIndexes,
+			Plan: func(context.Context, db.Storage) (string, error) {
+				return "create member indexes on organizations and teams, and text indexes on users, organizations and teams", nil
+			},
+		},
+		{
+			Version: 4,
+			Name:    "record-preference-overrides",
+			Up:      recordPreferenceOverrides,
+			Plan: func(ctx context.Context, store db.Storage) (string, error) {
+				count, err := store.GetCollection(db.UsersCollection).CountDocuments(ctx, usersWithoutOverrides)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("record the preference overrides of %d users", count), nil
+			},
+		},
+		{
+			Version: 5,
+			Name:    "search-keys",
+			Up:      indexSearchKeys,
+			Plan: func(ctx context.Context, store db.Storage) (string, error) {
+				users, err := store.GetCollection(db.UsersCollection).CountDocuments(ctx, withoutSearchKeys)
+				if err != nil {
+					return "", err
+				}
+				orgs, err := store.GetCollection(db.OrganizationsCollection).CountDocuments(ctx, withoutSearchKeys)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("record the search keys of %d users and %d organizations, index them and drop the user and organization text indexes", users, orgs), nil
+			},
+		},
+		{
+			Version: 6,
+			Name:    "move-members-to-collection",
+			Up: func(ctx context.Context, store db.Storage) error {
+				return moveMembersToCollection(ctx, store, orgConfig)
+			},
+			Plan: func(ctx context.Context, store db.Storage) (string, error) {
+				if models.MemberStorage(orgConfig.MemberStorage) != models.MemberStorageCollection {
+					return "nothing, new organizations embed their members", nil
+				}
+				orgIDs, err := repositories.NewOrganizationRepository(store).FindOversizedOrganizations(ctx, 0)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("move the members of %d organizations to the members collection", len(orgIDs)), nil
+			},
+		},
+		{
+			Version: 7,
+			Name:    "move-scim-external-ids-to-members",
+			Up:      moveExternalIDsToMembers,
+			Plan: func(ctx context.Context, store db.Storage) (string, error) {
+				count, err := store.GetCollection(db.UsersCollection).CountDocuments(ctx, usersWithExternalID)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("move the SCIM external IDs of %d users to their organization memberships", count), nil
+			},
+		},
+	}
+}
+
+// usersWithoutOverrides matches users created before preference overrides
+// were recorded
+var usersWithoutOverrides = bson.M{"preferences.overrides": bson.M{"$exists": false}}
+
+// recordPreferenceOverrides records the preferences that users created
+// before overrides were recorded set themselves, inferred from those that
+// differ from the system defaults, so organization defaults don't replace
+// them
+func recordPreferenceOverrides(ctx context.Context, store db.Storage) error {
+	userRepo := repositories.NewUserRepository(store)
+	for {
+		users, err := userRepo.FindBatch(ctx, usersWithoutOverrides, 500)
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+
+		for _, user := range users {
+			overrides := models.InferPreferenceOverrides(user.Preferences)
+			if err := userRepo.SetPreferenceOverrides(ctx, user.UserID, overrides); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// createSearchAndMembershipIndexes indexes the membership listings, which
+// find the organizations and teams of a user sorted by name, and the text
+// searches of users and organizations. Names and emails aren't prose, so
+// the text indexes neither stem words nor drop stop words.
+func createSearchAndMembershipIndexes(ctx context.Context, store db.Storage) error {
+	memberIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "members.userId", Value: 1},
+			{Key: "name", Value: 1},
+		},
+	}
+	if err := CreateIndexes(ctx, store, db.OrganizationsCollection, memberIndex); err != nil {
+		return err
+	}
+	if err := CreateIndexes(ctx, store, db.TeamsCollection, memberIndex); err != nil {
+		return err
+	}
+
+	if err := CreateIndexes(ctx, store, db.UsersCollection, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "firstName", Value: "text"},
+			{Key: "lastName", Value: "text"},
+			{Key: "email", Value: "text"},
+		},
+		Options: options.Index().SetName("users_text").SetDefaultLanguage("none"),
+	}); err != nil {
+		return err
+	}
+	if err := CreateIndexes(ctx, store, db.OrganizationsCollection, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "name", Value: "text"},
+			{Key: "description", Value: "text"},
+			{Key: "industry", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("organizations_text").
+			SetDefaultLanguage("none").
+			SetWeights(bson.D{{Key: "name", Value: 10}, {Key: "industry", Value: 5}, {Key: "description", Value: 1}}),
+	}); err != nil {
+		return err
+	}
+	return CreateIndexes(ctx, store, db.TeamsCollection, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: "text"}},
+		Options: options.Index().SetName("teams_text").SetDefaultLanguage("none"),
+	})
+}
+
+// withoutSearchKeys matches users and organizations stored before searches
+// matched search keys
+var withoutSearchKeys = bson.M{"search": bson.M{"$exists": false}}
+
+// indexSearchKeys records the search keys of existing users and
+// organizations and indexes them. Searches match the start of words since
+// then, so the text indexes they used before are dropped.
+func indexSearchKeys(ctx context.Context, store db.Storage) error {
+	userRepo := repositories.NewUserRepository(store)
+	for {
+		users, err := userRepo.FindBatch(ctx, withoutSearchKeys, 500)
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			break
+		}
+		for _, user := range users {
+			if err := userRepo.SetSearchKeys(ctx, user.UserID, user.SearchKeys()); err != nil {
+				return err
+			}
+		}
+	}
+
+	orgRepo := repositories.NewOrganizationRepository(store)
+	for {
+		orgs, err := orgRepo.FindBatch(ctx, withoutSearchKeys, 500)
+		if err != nil {
+			return err
+		}
+		if len(orgs) == 0 {
+			break
+		}
+		for _, org := range orgs {
+			if err := orgRepo.SetSearchKeys(ctx, org.ID, org.SearchKeys()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := CreateIndexes(ctx, store, db.UsersCollection, searchKeyIndexes(models.UserSearchFields)...); err != nil {
+		return err
+	}
+	if err := CreateIndexes(ctx, store, db.OrganizationsCollection, searchKeyIndexes(models.OrganizationSearchFields)...); err != nil {
+		return err
+	}
+	if err := DropIndex(ctx, store, db.UsersCollection, "users_text"); err != nil {
+		return err
+	}
+	return DropIndex(ctx, store, db.OrganizationsCollection, "organizations_text")
+}
+
+// searchKeyIndexes indexes the search keys of fields, one index each, so
+// every branch of a search's $or is answered from an index
+func searchKeyIndexes(fields []string) []mongo.IndexModel {
+	indexes := make([]mongo.IndexModel, 0, len(fields))
+	for _, field := range fields {
+		indexes = append(indexes, mongo.IndexModel{Keys: bson.D{{Key: "search." + field, Value: 1}}})
+	}
+	return indexes
+}
+
+// moveMembersToCollection moves the members of every organization that
+// embeds them to the members collection, when new organizations store their
+// members there. Organizations that fail to move, for example because they
+// changed while moving, fail the migration, so it moves them the next time
+// migrations run.
+func moveMembersToCollection(ctx context.Context, store db.Storage, orgConfig *config.OrganizationConfig) error {
+	if models.MemberStorage(orgConfig.MemberStorage) != models.MemberStorageCollection {
+		return nil
+	}
+
+	orgRepo := repositories.NewOrganizationRepository(store)
+	orgIDs, err := orgRepo.FindOversizedOrganizations(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, orgID := range orgIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := orgRepo.MoveMembersToCollection(ctx, orgID); err != nil {
+			logger.Ctx(ctx).Warn().Err(err).Str("orgId", orgID).Msg("Failed to move organization members")
+			failed = append(failed, orgID)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("moving the members of %d of %d organizations failed", len(failed), len(orgIDs))
+	}
+	return nil
+}
+
+// usersWithExternalID matches users with the external ID SCIM used to set on
+// the user, before it was kept on their membership
+var usersWithExternalID = bson.M{"externalId": bson.M{"$exists": true}}
+
+// moveExternalIDsToMembers moves the external IDs SCIM set on users to their
+// membership of the organization that provisioned them. Users of several
+// organizations can't tell which one that was, so their external ID is
+// dropped; their identity provider links them again by userName.
+func moveExternalIDsToMembers(ctx context.Context, store db.Storage) error {
+	users := store.GetCollection(db.UsersCollection)
+	orgRepo := repositories.NewOrganizationRepository(store)
+
+	opts := options.Find().SetProjection(bson.M{"userId": 1, "organizationIds": 1, "externalId": 1})
+	cursor, err := users.Find(ctx, usersWithExternalID, opts)
+	if err != nil {
+		return err
+	}
+	var provisioned []struct {
+		UserID          string   `bson:"userId"`
+		OrganizationIDs []string `bson:"organizationIds"`
+		ExternalID      string   `bson:"externalId"`
+	}
+	if err := cursor.All(ctx, &provisioned); err != nil {
+		return err
+	}
+
+	for _, user := range provisioned {
+		if len(user.OrganizationIDs) == 1 && user.ExternalID != "" {
+			if err := orgRepo.SetMemberProvisioning(ctx, user.OrganizationIDs[0], user.UserID, user.ExternalID, false); err != nil {
+				logger.Ctx(ctx).Warn().Err(err).Str("userId", user.UserID).Msg("Failed to move SCIM external ID to member")
+			}
+		}
+		if _, err := users.UpdateOne(ctx, bson.M{"userId": user.UserID}, bson.M{"$unset": bson.M{"externalId": ""}}); err != nil {
+			return err
+		}
+	}
+
+	return DropIndex(ctx, store, db.UsersCollection, "organizationIds_1_externalId_1")
+}
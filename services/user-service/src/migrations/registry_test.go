@@ -0,0 +1,150 @@
+package migrations_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/migrations"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"github.com/your-username/slido-clone/user-service/testsupport"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// orgConfig stores the members of new organizations in the members
+// collection, the default
+var orgConfig = &config.OrganizationConfig{MemberStorage: string(models.MemberStorageCollection)}
+
+// migration gets a migration of the registry by name
+func migration(t *testing.T, name string) migrations.Migration {
+	t.Helper()
+
+	for _, m := range migrations.All(orgConfig) {
+		if m.Name == name {
+			return m
+		}
+	}
+	t.Fatalf("no migration %q", name)
+	return migrations.Migration{}
+}
+
+func TestSearchKeysMigrationIndexesExistingUsers(t *testing.T) {
+	f := testsupport.New(t)
+	ctx := tenancy.Unscoped(context.Background())
+
+	// A user stored before search keys were recorded
+	user := f.SeedUser(func(u *models.User) {
+		u.FirstName, u.LastName, u.Email = "Jane", "Doe", "jane@example.com"
+	})
+	if _, err := f.Store.GetCollection(db.UsersCollection).UpdateOne(ctx,
+		bson.M{"userId": user.UserID}, bson.M{"$unset": bson.M{"search": ""}}); err != nil {
+		t.Fatalf("removing search keys: %v", err)
+	}
+
+	users, _, err := f.Users.GetUsers(ctx, 1, 10, models.UserListFilter{Search: "doe"})
+	if err != nil {
+		t.Fatalf("GetUsers: %v", err)
+	}
+	if len(users) != 0 {
+		t.Fatalf("found %d users without search keys, want none", len(users))
+	}
+
+	if err := migration(t, "search-keys").Up(ctx, f.Store); err != nil {
+		t.Fatalf("search-keys migration: %v", err)
+	}
+
+	users, _, err = f.Users.GetUsers(ctx, 1, 10, models.UserListFilter{Search: "doe"})
+	if err != nil {
+		t.Fatalf("GetUsers: %v", err)
+	}
+	if len(users) != 1 || users[0].UserID != user.UserID {
+		t.Errorf("found %d users after the migration, want %s", len(users), user.UserID)
+	}
+}
+
+func TestMoveMembersMigrationMovesEmbeddedMembers(t *testing.T) {
+	f := testsupport.New(t)
+	ctx := tenancy.Unscoped(context.Background())
+	owner := f.SeedUser()
+	org := f.SeedOrganization(owner)
+	f.SeedMember(org, f.SeedUser(), models.OrgRoleMember)
+
+	// Nothing moves while new organizations embed their members
+	embedded := migrations.All(&config.OrganizationConfig{MemberStorage: string(models.MemberStorageEmbedded)})
+	if err := embedded[5].Up(ctx, f.Store); err != nil {
+		t.Fatalf("migration with embedded members: %v", err)
+	}
+	if stored, err := f.Orgs.GetByID(ctx, org.ID); err != nil || stored.MemberStorage == models.MemberStorageCollection {
+		t.Fatalf("organization moved while new organizations embed members (err %v)", err)
+	}
+
+	if err := migration(t, "move-members-to-collection").Up(ctx, f.Store); err != nil {
+		t.Fatalf("move-members-to-collection migration: %v", err)
+	}
+	stored, err := f.Orgs.GetByID(ctx, org.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.MemberStorage != models.MemberStorageCollection {
+		t.Fatalf("member storage = %q, want %q", stored.MemberStorage, models.MemberStorageCollection)
+	}
+	total, err := f.Store.GetCollection(db.OrgMembersCollection).CountDocuments(ctx, bson.M{"organizationId": org.ID})
+	if err != nil {
+		t.Fatalf("counting members: %v", err)
+	}
+	if total != 2 {
+		t.Errorf("organization has %d members after the move, want 2", total)
+	}
+}
+
+func TestMigratorAdoptsLegacyMigrations(t *testing.T) {
+	f := testsupport.New(t)
+	ctx := tenancy.Unscoped(context.Background())
+
+	// Recorded by the data migrations that preceded versioned migrations
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if _, err := f.Store.GetCollection(db.LegacyMigrationsCollection).InsertOne(ctx,
+		bson.M{"_id": "0001-remove-duplicate-organization-members", "appliedAt": appliedAt}); err != nil {
+		t.Fatalf("recording legacy migration: %v", err)
+	}
+
+	ran := map[string]bool{}
+	var registry []migrations.Migration
+	for _, m := range migrations.All(orgConfig)[:2] {
+		name := m.Name
+		m.Up = func(context.Context, db.Storage) error {
+			ran[name] = true
+			return nil
+		}
+		registry = append(registry, m)
+	}
+	migrator, err := migrations.New(f.Store, repositories.NewMigrationRepository(f.Store), registry)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if at := statuses[0].AppliedAt; at == nil || !at.Equal(appliedAt) {
+		t.Errorf("legacy migration applied at %v, want %v", at, appliedAt)
+	}
+	if statuses[1].AppliedAt != nil {
+		t.Errorf("migration without a legacy record applied at %v, want pending", statuses[1].AppliedAt)
+	}
+
+	if _, err := migrator.Up(ctx, migrations.Options{}); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if ran["remove-duplicate-organization-members"] {
+		t.Error("legacy migration ran again")
+	}
+	if !ran["remove-duplicate-team-members"] {
+		t.Error("pending migration didn't run")
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// MaxAccessReviewDays is the longest inactivity an access review can ask for
+const MaxAccessReviewDays = 3650
+
+// AccessReviewMember is a stale member listed in an access review, with when
+// they last logged in
+type AccessReviewMember struct {
+	OrganizationMemberDetail
+	LastLogin *time.Time `json:"lastLogin,omitempty"`
+}
+
+// AccessReviewResponse represents the stale members of an organization: those
+// who haven't logged in, or been confirmed by a review, within InactiveDays
+type AccessReviewResponse struct {
+	OrganizationID   string               `json:"organizationId"`
+	OrganizationName string               `json:"organizationName"`
+	InactiveDays     int                  `json:"inactiveDays"`
+	MemberCount      int                  `json:"memberCount"`
+	Members          []AccessReviewMember `json:"members"`
+	Total            int64                `json:"total"`
+	Page             int                  `json:"page"`
+	Limit            int                  `json:"limit"`
+	TotalPages       int64                `json:"totalPages"`
+}
+
+// AccessReviewDecisionRequest represents a request to confirm or revoke the
+// access of up to 100 members at once
+type AccessReviewDecisionRequest struct {
+	UserIDs []string `json:"userIds" validate:"required,min=1,max=100,dive,required"`
+}
+
+// IsStale checks if a member, whose user may no longer exist, has neither
+// logged in nor been confirmed by an access review since a time. Members who
+// joined since then aren't stale yet.
+func (m OrganizationMember) IsStale(user *User, since time.Time) bool {
+	if !m.JoinedAt.Before(since) {
+		return false
+	}
+	if m.ReviewedAt != nil && !m.ReviewedAt.Before(since) {
+		return false
+	}
+	return user == nil || user.LastLogin == nil || user.LastLogin.Before(since)
+}
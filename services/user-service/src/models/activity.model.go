@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ActivityType represents what a user did
+type ActivityType string
+
+// Activity types
+const (
+	ActivityOrganizationJoined      ActivityType = "organization_joined"
+	ActivityOrganizationLeft        ActivityType = "organization_left"
+	ActivityOrganizationRoleChanged ActivityType = "organization_role_changed"
+	ActivityTeamCreated             ActivityType = "team_created"
+	ActivityTeamJoined              ActivityType = "team_joined"
+	ActivityTeamLeft                ActivityType = "team_left"
+	ActivityTeamRoleChanged         ActivityType = "team_role_changed"
+)
+
+// Activity is an entry of a user's activity feed, recorded from an event the
+// service published. The user is who the activity is about; the actor, when
+// set, is who made it happen, such as the admin that changed their role.
+type Activity struct {
+	ID               string       `bson:"_id" json:"id"`
+	UserID           string       `bson:"userId" json:"userId"`
+	ActorID          string       `bson:"actorId,omitempty" json:"actorId,omitempty"`
+	Type             ActivityType `bson:"type" json:"type"`
+	OrganizationID   string       `bson:"organizationId" json:"organizationId"`
+	OrganizationName string       `bson:"organizationName,omitempty" json:"organizationName,omitempty"`
+	TeamID           string       `bson:"teamId,omitempty" json:"teamId,omitempty"`
+	TeamName         string       `bson:"teamName,omitempty" json:"teamName,omitempty"`
+	Role             string       `bson:"role,omitempty" json:"role,omitempty"`
+	EventID          string       `bson:"eventId" json:"-"`
+	OccurredAt       time.Time    `bson:"occurredAt" json:"occurredAt"`
+}
+
+// NewActivity creates a new activity of a user in an organization
+func NewActivity(userID string, activityType ActivityType, orgID, eventID string, occurredAt time.Time) *Activity {
+	return &Activity{
+		ID:             uuid.New().String(),
+		UserID:         userID,
+		Type:           activityType,
+		OrganizationID: orgID,
+		EventID:        eventID,
+		OccurredAt:     occurredAt,
+	}
+}
+
+// ActivityFilter represents the filters of an activity feed
+type ActivityFilter struct {
+	UserID         string
+	OrganizationID string
+}
+
+// ActivityResponse represents a page of an activity feed. Pages continue
+// from the cursor of the last one, like organization timelines.
+type ActivityResponse struct {
+	Activities []*Activity `json:"activities"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+	Limit      int         `json:"limit"`
+}
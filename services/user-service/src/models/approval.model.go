@@ -0,0 +1,155 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ApprovalAction represents a membership change that requires approval
+type ApprovalAction string
+
+// Approval actions
+const (
+	ApprovalActionMemberAdd      ApprovalAction = "member.add"
+	ApprovalActionRoleEscalation ApprovalAction = "member.role_escalation"
+)
+
+// ApprovalFallbackPolicy decides what happens when the approval webhook cannot be reached
+type ApprovalFallbackPolicy string
+
+// Approval fallback policies
+const (
+	ApprovalFallbackAllow ApprovalFallbackPolicy = "allow"
+	ApprovalFallbackDeny  ApprovalFallbackPolicy = "deny"
+)
+
+// Approval webhook defaults
+const (
+	DefaultApprovalTimeoutMs = 5000
+)
+
+// ApprovalWebhookSettings represents an organization's external approval webhook
+type ApprovalWebhookSettings struct {
+	Enabled        bool                   `bson:"enabled" json:"enabled"`
+	URL            string                 `bson:"url" json:"url"`
+	Secret         string                 `bson:"secret,omitempty" json:"-"`
+	TimeoutMs      int                    `bson:"timeoutMs" json:"timeoutMs"`
+	FallbackPolicy ApprovalFallbackPolicy `bson:"fallbackPolicy" json:"fallbackPolicy"`
+	Actions        []ApprovalAction       `bson:"actions" json:"actions"`
+	UpdatedBy      string                 `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
+	UpdatedAt      time.Time              `bson:"updatedAt" json:"updatedAt"`
+}
+
+// UpdateApprovalWebhookRequest represents a request to configure an approval webhook
+type UpdateApprovalWebhookRequest struct {
+	Enabled        *bool                  `json:"enabled,omitempty"`
+	URL            string                 `json:"url" validate:"required,url"`
+	Secret         *string                `json:"secret,omitempty" validate:"omitempty,min=16,max=256"`
+	TimeoutMs      int                    `json:"timeoutMs" validate:"omitempty,min=100,max=10000"`
+	FallbackPolicy ApprovalFallbackPolicy `json:"fallbackPolicy" validate:"omitempty,oneof=allow deny"`
+	Actions        []ApprovalAction       `json:"actions" validate:"omitempty,dive,oneof=member.add member.role_escalation"`
+}
+
+// ApprovalWebhookResponse represents an approval webhook response
+type ApprovalWebhookResponse struct {
+	ApprovalWebhookSettings
+	HasSecret bool `json:"hasSecret"`
+}
+
+// ApprovalRequest is the payload sent to an approval webhook
+type ApprovalRequest struct {
+	ID             string                 `json:"id"`
+	Action         ApprovalAction         `json:"action"`
+	OrganizationID string                 `json:"organizationId"`
+	UserID         string                 `json:"userId"`
+	Role           OrganizationMemberRole `json:"role"`
+	PreviousRole   OrganizationMemberRole `json:"previousRole,omitempty"`
+	RequestedBy    string                 `json:"requestedBy"`
+	RequestedAt    time.Time              `json:"requestedAt"`
+}
+
+// ApprovalDecision is the response expected from an approval webhook
+type ApprovalDecision struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// NewApprovalRequest creates a new approval request
+func NewApprovalRequest(action ApprovalAction, orgID, userID string, role, previousRole OrganizationMemberRole, requestedBy string) ApprovalRequest {
+	return ApprovalRequest{
+		ID:             uuid.New().String(),
+		Action:         action,
+		OrganizationID: orgID,
+		UserID:         userID,
+		Role:           role,
+		PreviousRole:   previousRole,
+		RequestedBy:    requestedBy,
+		RequestedAt:    time.Now(),
+	}
+}
+
+// Apply applies an update request to approval webhook settings
+func (w *ApprovalWebhookSettings) Apply(req UpdateApprovalWebhookRequest, updatedBy string) {
+	w.URL = req.URL
+	w.Enabled = true
+	if req.Enabled != nil {
+		w.Enabled = *req.Enabled
+	}
+	if req.Secret != nil {
+		w.Secret = *req.Secret
+	}
+
+	w.TimeoutMs = req.TimeoutMs
+	if w.TimeoutMs == 0 {
+		w.TimeoutMs = DefaultApprovalTimeoutMs
+	}
+
+	w.FallbackPolicy = req.FallbackPolicy
+	if w.FallbackPolicy == "" {
+		w.FallbackPolicy = ApprovalFallbackDeny
+	}
+
+	w.Actions = req.Actions
+	if len(w.Actions) == 0 {
+		w.Actions = []ApprovalAction{ApprovalActionMemberAdd, ApprovalActionRoleEscalation}
+	}
+
+	w.UpdatedBy = updatedBy
+	w.UpdatedAt = time.Now()
+}
+
+// Requires checks if the webhook must approve an action
+func (w *ApprovalWebhookSettings) Requires(action ApprovalAction) bool {
+	if w == nil || !w.Enabled || w.URL == "" {
+		return false
+	}
+	for _, a := range w.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// ToResponse converts approval webhook settings to a response
+func (w *ApprovalWebhookSettings) ToResponse() ApprovalWebhookResponse {
+	return ApprovalWebhookResponse{
+		ApprovalWebhookSettings: *w,
+		HasSecret:               w.Secret != "",
+	}
+}
+
+// Rank returns the privilege rank of an organization role
+func (r OrganizationMemberRole) Rank() int {
+	switch r {
+	case OrgRoleOwner:
+		return 3
+	case OrgRoleAdmin:
+		return 2
+	case OrgRoleMember:
+		return 1
+	default:
+		return 0
+	}
+}
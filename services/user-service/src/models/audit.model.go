@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEntry records a request made by an admin acting as another user
+type AuditEntry struct {
+	ID              string    `bson:"_id" json:"id"`
+	ActorID         string    `bson:"actorId" json:"actorId"`
+	UserID          string    `bson:"userId" json:"userId"`
+	ImpersonationID string    `bson:"impersonationId,omitempty" json:"impersonationId,omitempty"`
+	Method          string    `bson:"method" json:"method"`
+	Path            string    `bson:"path" json:"path"`
+	Status          int       `bson:"status" json:"status"`
+	IPAddress       string    `bson:"ipAddress,omitempty" json:"ipAddress,omitempty"`
+	RequestID       string    `bson:"requestId,omitempty" json:"requestId,omitempty"`
+	OccurredAt      time.Time `bson:"occurredAt" json:"occurredAt"`
+}
+
+// NewAuditEntry creates a new audit entry of a request
+func NewAuditEntry(actorID, userID, method, path string, status int) *AuditEntry {
+	return &AuditEntry{
+		ID:         uuid.New().String(),
+		ActorID:    actorID,
+		UserID:     userID,
+		Method:     method,
+		Path:       path,
+		Status:     status,
+		OccurredAt: time.Now(),
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// BannerSeverity represents how prominently clients should render a banner
+type BannerSeverity string
+
+// Banner severities
+const (
+	BannerInfo     BannerSeverity = "info"
+	BannerWarning  BannerSeverity = "warning"
+	BannerCritical BannerSeverity = "critical"
+)
+
+// PlatformBannerID is the ID of the platform banner; there is only one
+const PlatformBannerID = "platform"
+
+// Banner is a platform-wide message, such as a maintenance window or an
+// incident notice, that clients render above their UI
+type Banner struct {
+	ID        string         `bson:"_id" json:"-"`
+	Message   string         `bson:"message" json:"message"`
+	Severity  BannerSeverity `bson:"severity" json:"severity"`
+	StartsAt  *time.Time     `bson:"startsAt,omitempty" json:"startsAt,omitempty"`
+	EndsAt    *time.Time     `bson:"endsAt,omitempty" json:"endsAt,omitempty"`
+	UpdatedBy string         `bson:"updatedBy" json:"updatedBy,omitempty"`
+	UpdatedAt time.Time      `bson:"updatedAt" json:"updatedAt"`
+}
+
+// UpdateBannerRequest represents a request to set the platform banner
+type UpdateBannerRequest struct {
+	Message  string         `json:"message" validate:"required,max=500"`
+	Severity BannerSeverity `json:"severity" validate:"required,oneof=info warning critical"`
+	StartsAt *time.Time     `json:"startsAt,omitempty"`
+	EndsAt   *time.Time     `json:"endsAt,omitempty"`
+}
+
+// NewBanner creates the platform banner from a request
+func NewBanner(req UpdateBannerRequest, updatedBy string) *Banner {
+	return &Banner{
+		ID:        PlatformBannerID,
+		Message:   req.Message,
+		Severity:  req.Severity,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		UpdatedBy: updatedBy,
+		UpdatedAt: time.Now(),
+	}
+}
+
+// IsActive checks if the banner should be shown at a time
+func (b *Banner) IsActive(now time.Time) bool {
+	if b.StartsAt != nil && now.Before(*b.StartsAt) {
+		return false
+	}
+	if b.EndsAt != nil && !now.Before(*b.EndsAt) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+)
+
+// BulkMemberOp is the kind of an operation of a bulk member request
+type BulkMemberOp string
+
+// Bulk member operations
+const (
+	BulkMemberAdd    BulkMemberOp = "add"
+	BulkMemberUpdate BulkMemberOp = "update"
+	BulkMemberRemove BulkMemberOp = "remove"
+
+	// BulkMemberConfirm is reported for members an access review confirmed
+	BulkMemberConfirm BulkMemberOp = "confirm"
+)
+
+// BulkMemberStatus is the outcome of an operation of a bulk member request
+type BulkMemberStatus string
+
+// Bulk member operation outcomes
+const (
+	BulkMemberSucceeded BulkMemberStatus = "succeeded"
+	BulkMemberFailed    BulkMemberStatus = "failed"
+)
+
+// BulkOrganizationMemberOperation adds a member to an organization, changes
+// the role of a member or removes one. Adds and updates need a role.
+type BulkOrganizationMemberOperation struct {
+	Op     BulkMemberOp           `json:"op" validate:"required,oneof=add update remove"`
+	UserID string                 `json:"userId" validate:"required"`
+	Role   OrganizationMemberRole `json:"role,omitempty" validate:"omitempty,oneof=owner admin member"`
+}
+
+// BulkOrganizationMembersRequest represents a request to run up to 100
+// member operations on an organization at once
+type BulkOrganizationMembersRequest struct {
+	Operations []BulkOrganizationMemberOperation `json:"operations" validate:"required,min=1,max=100,dive"`
+}
+
+// BulkTeamMemberOperation adds a member to a team, changes the role of a
+// member or removes one. Updates need a role; members added without one get
+// the team's default member role.
+type BulkTeamMemberOperation struct {
+	Op     BulkMemberOp   `json:"op" validate:"required,oneof=add update remove"`
+	UserID string         `json:"userId" validate:"required"`
+	Role   TeamMemberRole `json:"role,omitempty" validate:"omitempty,oneof=owner admin member viewer"`
+}
+
+// BulkTeamMembersRequest represents a request to run up to 100 member
+// operations on a team at once
+type BulkTeamMembersRequest struct {
+	Operations []BulkTeamMemberOperation `json:"operations" validate:"required,min=1,max=100,dive"`
+}
+
+// BulkMemberResult is the outcome of an operation of a bulk member request.
+// Failed operations carry the code and message of their error.
+type BulkMemberResult struct {
+	Index   int              `json:"index"`
+	Op      BulkMemberOp     `json:"op"`
+	UserID  string           `json:"userId"`
+	Status  BulkMemberStatus `json:"status"`
+	Error   string           `json:"error,omitempty"`
+	Message string           `json:"message,omitempty"`
+}
+
+// BulkMemberResponse represents the outcome of a bulk member request
+type BulkMemberResponse struct {
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Results   []BulkMemberResult `json:"results"`
+}
+
+// NewBulkMemberResponse creates an empty response for a bulk member request
+// of n operations
+func NewBulkMemberResponse(n int) *BulkMemberResponse {
+	return &BulkMemberResponse{Results: make([]BulkMemberResult, 0, n)}
+}
+
+// Add adds the outcome of an operation to the response and counts it. Errors
+// other than domain errors are reported without their details.
+func (r *BulkMemberResponse) Add(index int, op BulkMemberOp, userID string, err error) {
+	result := BulkMemberResult{
+		Index:  index,
+		Op:     op,
+		UserID: userID,
+		Status: BulkMemberSucceeded,
+	}
+	if err != nil {
+		result.Status = BulkMemberFailed
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) {
+			result.Error, result.Message = appErr.Code, appErr.Message
+		} else {
+			result.Error, result.Message = "INTERNAL_ERROR", "operation failed"
+		}
+	}
+
+	r.Results = append(r.Results, result)
+	if result.Status == BulkMemberFailed {
+		r.Failed++
+	} else {
+		r.Succeeded++
+	}
+}
+
+// OrganizationMemberChanges are the net member changes of a bulk member
+// request, written to an organization at once
+type OrganizationMemberChanges struct {
+	Added   []OrganizationMember
+	Updated []OrganizationMember
+	Removed []OrganizationMember
+}
+
+// IsEmpty checks if there are no changes to write
+func (c OrganizationMemberChanges) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Updated) == 0 && len(c.Removed) == 0
+}
+
+// DiffOrganizationMembers gets the changes that turn one member list into
+// another. Members whose role changed are updated.
+func DiffOrganizationMembers(before, after []OrganizationMember) OrganizationMemberChanges {
+	changes := OrganizationMemberChanges{}
+	previous := make(map[string]OrganizationMember, len(before))
+	for _, member := range before {
+		previous[member.UserID] = member
+	}
+
+	for _, member := range after {
+		old, ok := previous[member.UserID]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, member)
+		case old.Role != member.Role:
+			changes.Updated = append(changes.Updated, member)
+		}
+		delete(previous, member.UserID)
+	}
+	for _, member := range before {
+		if _, ok := previous[member.UserID]; ok {
+			changes.Removed = append(changes.Removed, member)
+		}
+	}
+
+	return changes
+}
+
+// TeamMemberChanges are the net member changes of a bulk member request
+type TeamMemberChanges struct {
+	Added   []TeamMember
+	Updated []TeamMember
+	Removed []TeamMember
+}
+
+// IsEmpty checks if there are no changes to write
+func (c TeamMemberChanges) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Updated) == 0 && len(c.Removed) == 0
+}
+
+// DiffTeamMembers gets the changes that turn one member list into another.
+// Members whose role changed are updated.
+func DiffTeamMembers(before, after []TeamMember) TeamMemberChanges {
+	changes := TeamMemberChanges{}
+	previous := make(map[string]TeamMember, len(before))
+	for _, member := range before {
+		previous[member.UserID] = member
+	}
+
+	for _, member := range after {
+		old, ok := previous[member.UserID]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, member)
+		case old.Role != member.Role:
+			changes.Updated = append(changes.Updated, member)
+		}
+		delete(previous, member.UserID)
+	}
+	for _, member := range before {
+		if _, ok := previous[member.UserID]; ok {
+			changes.Removed = append(changes.Removed, member)
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ChangeStreamToken records how far a change stream has been read, so it
+// resumes after the last change handled when the service restarts
+type ChangeStreamToken struct {
+	Stream      string    `bson:"_id" json:"stream"`
+	ResumeToken bson.Raw  `bson:"resumeToken" json:"-"`
+	UpdatedAt   time.Time `bson:"updatedAt" json:"updatedAt"`
+}
@@ -0,0 +1,225 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+)
+
+// MaxCustomFields is the most custom profile fields an organization may define
+const MaxCustomFields = 50
+
+// MaxCustomFieldTextLength is the longest value a text field may hold
+const MaxCustomFieldTextLength = 1000
+
+// CustomFieldDateLayout is the layout of date field values
+const CustomFieldDateLayout = "2006-01-02"
+
+// CustomFieldType represents the type of a custom profile field
+type CustomFieldType string
+
+// Custom field types
+const (
+	CustomFieldText    CustomFieldType = "text"
+	CustomFieldNumber  CustomFieldType = "number"
+	CustomFieldBoolean CustomFieldType = "boolean"
+	CustomFieldDate    CustomFieldType = "date"
+	CustomFieldSelect  CustomFieldType = "select"
+)
+
+// CustomFieldVisibility represents who can see the values of a custom field
+type CustomFieldVisibility string
+
+// Custom field visibilities
+const (
+	// CustomFieldVisibleToMembers shows values to every organization member
+	CustomFieldVisibleToMembers CustomFieldVisibility = "members"
+	// CustomFieldVisibleToAdmins shows values only to members who can manage
+	// members, and to the member the value belongs to
+	CustomFieldVisibleToAdmins CustomFieldVisibility = "admins"
+)
+
+// customFieldKeyPattern matches custom field keys. Keys are used in document
+// paths, so they can't contain dots or dollar signs.
+var customFieldKeyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,49}$`)
+
+// CustomFieldDefinition defines a custom profile field of an organization
+type CustomFieldDefinition struct {
+	Key        string                `bson:"key" json:"key" validate:"required,max=50"`
+	Name       string                `bson:"name" json:"name" validate:"required,min=1,max=100"`
+	Type       CustomFieldType       `bson:"type" json:"type" validate:"required,oneof=text number boolean date select"`
+	Required   bool                  `bson:"required" json:"required"`
+	Visibility CustomFieldVisibility `bson:"visibility" json:"visibility" validate:"required,oneof=members admins"`
+	Options    []string              `bson:"options,omitempty" json:"options,omitempty" validate:"max=100,dive,required,max=100"`
+}
+
+// UpdateCustomFieldsRequest represents a request to replace the custom
+// profile fields of an organization
+type UpdateCustomFieldsRequest struct {
+	Fields []CustomFieldDefinition `json:"fields" validate:"max=50,dive"`
+}
+
+// UpdateMemberCustomFieldsRequest represents a request to replace the custom
+// profile field values of an organization member
+type UpdateMemberCustomFieldsRequest struct {
+	Values map[string]interface{} `json:"values" validate:"max=50"`
+}
+
+// CustomFieldsResponse represents the custom profile fields of an organization
+type CustomFieldsResponse struct {
+	OrganizationID string                  `json:"organizationId"`
+	Fields         []CustomFieldDefinition `json:"fields"`
+}
+
+// MemberCustomFieldsResponse represents the custom profile field values of an
+// organization member
+type MemberCustomFieldsResponse struct {
+	OrganizationID string                 `json:"organizationId"`
+	UserID         string                 `json:"userId"`
+	Values         map[string]interface{} `json:"values"`
+}
+
+// ValidateCustomFieldDefinitions checks that custom field definitions have
+// distinct, well-formed keys and that select fields have options
+func ValidateCustomFieldDefinitions(fields []CustomFieldDefinition) error {
+	if len(fields) > MaxCustomFields {
+		return apperrors.InvalidField("fields", fmt.Sprintf("organizations cannot have more than %d custom fields", MaxCustomFields))
+	}
+
+	seen := make(map[string]bool, len(fields))
+	for i := range fields {
+		field := &fields[i]
+		if !customFieldKeyPattern.MatchString(field.Key) {
+			return apperrors.InvalidField("fields", "key "+field.Key+" must start with a letter and contain only letters, digits and underscores")
+		}
+		if seen[field.Key] {
+			return apperrors.InvalidField("fields", "key "+field.Key+" is defined more than once")
+		}
+		seen[field.Key] = true
+
+		if field.Type == CustomFieldSelect {
+			field.Options = uniqueStrings(field.Options)
+			if len(field.Options) == 0 {
+				return apperrors.InvalidField("fields", "select field "+field.Key+" must have options")
+			}
+		} else {
+			field.Options = nil
+		}
+	}
+	return nil
+}
+
+// ValidateCustomFieldValues checks values against the custom fields of the
+// organization and returns them normalized. Null values are treated as
+// missing; unknown keys and missing required fields are rejected.
+func (s *OrganizationSettings) ValidateCustomFieldValues(values map[string]interface{}) (map[string]interface{}, error) {
+	normalized := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if value == nil {
+			continue
+		}
+		field := s.CustomField(key)
+		if field == nil {
+			return nil, apperrors.InvalidField("values", "unknown custom field "+key)
+		}
+		v, err := field.normalize(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized[key] = v
+	}
+
+	for _, field := range s.CustomFields {
+		if _, ok := normalized[field.Key]; field.Required && !ok {
+			return nil, apperrors.InvalidField("values", "custom field "+field.Key+" is required")
+		}
+	}
+	return normalized, nil
+}
+
+// VisibleCustomFieldValues returns the values of the organization's current
+// custom fields that a viewer can see. Values of removed fields are dropped.
+func (s *OrganizationSettings) VisibleCustomFieldValues(values map[string]interface{}, canSeeAdminFields bool) map[string]interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+
+	visible := make(map[string]interface{}, len(values))
+	for _, field := range s.CustomFields {
+		value, ok := values[field.Key]
+		if !ok {
+			continue
+		}
+		if field.Visibility == CustomFieldVisibleToAdmins && !canSeeAdminFields {
+			continue
+		}
+		visible[field.Key] = value
+	}
+	if len(visible) == 0 {
+		return nil
+	}
+	return visible
+}
+
+// CustomField gets a custom field of the organization by key
+func (s *OrganizationSettings) CustomField(key string) *CustomFieldDefinition {
+	for i := range s.CustomFields {
+		if s.CustomFields[i].Key == key {
+			return &s.CustomFields[i]
+		}
+	}
+	return nil
+}
+
+// normalize checks that a value has the field's type, returning the value to
+// store
+func (f *CustomFieldDefinition) normalize(value interface{}) (interface{}, error) {
+	invalid := func(expected string) error {
+		return apperrors.InvalidField("values", "custom field "+f.Key+" must be "+expected)
+	}
+
+	switch f.Type {
+	case CustomFieldText:
+		s, ok := value.(string)
+		if !ok || len(s) > MaxCustomFieldTextLength {
+			return nil, invalid(fmt.Sprintf("a string of at most %d characters", MaxCustomFieldTextLength))
+		}
+		return s, nil
+	case CustomFieldNumber:
+		n, ok := value.(float64)
+		if !ok || math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, invalid("a number")
+		}
+		return n, nil
+	case CustomFieldBoolean:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, invalid("a boolean")
+		}
+		return b, nil
+	case CustomFieldDate:
+		s, ok := value.(string)
+		if !ok {
+			return nil, invalid("a date formatted as YYYY-MM-DD")
+		}
+		date, err := time.Parse(CustomFieldDateLayout, s)
+		if err != nil {
+			return nil, invalid("a date formatted as YYYY-MM-DD")
+		}
+		return date.Format(CustomFieldDateLayout), nil
+	case CustomFieldSelect:
+		s, ok := value.(string)
+		if ok {
+			for _, option := range f.Options {
+				if s == option {
+					return s, nil
+				}
+			}
+		}
+		return nil, invalid("one of the field's options")
+	}
+	return nil, invalid("a supported type")
+}
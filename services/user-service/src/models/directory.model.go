@@ -0,0 +1,55 @@
+package models
+
+// DirectoryFilter represents the filters of the public organization directory
+type DirectoryFilter struct {
+	// Search matches the name, description or industry of the organization
+	Search string
+	// Industry matches the organization's industry exactly, ignoring case
+	Industry string
+}
+
+// DirectoryOrganization is the public listing of a discoverable organization
+type DirectoryOrganization struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	LogoURL     string `json:"logoUrl,omitempty"`
+	Website     string `json:"website,omitempty"`
+	Industry    string `json:"industry,omitempty"`
+	Size        string `json:"size,omitempty"`
+	Location    string `json:"location,omitempty"`
+	MemberCount int    `json:"memberCount"`
+	Verified    bool   `json:"verified"`
+	// AcceptsJoinRequests tells if users can request to join the organization
+	AcceptsJoinRequests bool `json:"acceptsJoinRequests"`
+	// IsMember is set for signed in users that are members of the organization
+	IsMember bool `json:"isMember,omitempty"`
+}
+
+// DirectoryResponse represents a page of the public organization directory
+type DirectoryResponse struct {
+	Organizations []DirectoryOrganization `json:"organizations"`
+	Total         int64                   `json:"total"`
+	Page          int                     `json:"page"`
+	Limit         int                     `json:"limit"`
+	TotalPages    int64                   `json:"totalPages"`
+}
+
+// ToDirectoryEntry converts an organization to its public directory listing
+// as seen by a user, who is empty for anonymous visitors
+func (o *Organization) ToDirectoryEntry(viewerID string) DirectoryOrganization {
+	return DirectoryOrganization{
+		ID:                  o.ID,
+		Name:                o.Name,
+		Description:         o.Description,
+		LogoURL:             o.LogoURL,
+		Website:             o.Website,
+		Industry:            o.Industry,
+		Size:                o.Size,
+		Location:            o.Location,
+		MemberCount:         len(o.Members),
+		Verified:            o.Verified,
+		AcceptsJoinRequests: o.Settings.Features.AllowExternalUsers,
+		IsMember:            viewerID != "" && o.IsMember(viewerID),
+	}
+}
@@ -0,0 +1,110 @@
+package models
+
+import "strings"
+
+// DirectoryImportSource is a workspace directory members are imported from
+type DirectoryImportSource string
+
+// Directory import sources
+const (
+	DirectoryImportGoogle DirectoryImportSource = "google"
+	DirectoryImportSlack  DirectoryImportSource = "slack"
+)
+
+// DirectoryImportAction is what an import does with a directory member
+type DirectoryImportAction string
+
+// Directory import actions
+const (
+	// DirectoryImportAdd adds an existing user to the organization
+	DirectoryImportAdd DirectoryImportAction = "add"
+	// DirectoryImportInvite creates a pending user for a member without an
+	// account, like SCIM provisioning, and adds them to the organization
+	DirectoryImportInvite DirectoryImportAction = "invite"
+	// DirectoryImportAlreadyMember leaves a member of the organization as is
+	DirectoryImportAlreadyMember DirectoryImportAction = "already_member"
+	// DirectoryImportSkip leaves out a member who can't be added
+	DirectoryImportSkip DirectoryImportAction = "skip"
+)
+
+// DirectoryImportRequest represents a request to preview or apply an import
+// of a workspace directory into an organization. The access token is only
+// used for the request and never stored.
+type DirectoryImportRequest struct {
+	AccessToken string `json:"accessToken" validate:"required,max=4096"`
+	// Domain limits a Google Workspace import to one of the account's domains
+	Domain string `json:"domain,omitempty" validate:"omitempty,hostname,max=253"`
+	// Role is the role of the added members, the organization's default
+	// role if empty
+	Role OrganizationMemberRole `json:"role,omitempty" validate:"omitempty,oneof=admin member"`
+	// Emails limits the import to the listed directory members, such as the
+	// ones picked from a preview
+	Emails []string `json:"emails,omitempty" validate:"max=10000,dive,email"`
+	// Apply applies the import; without it the import is only previewed
+	Apply bool `json:"apply"`
+}
+
+// DirectoryImportEntry is what an import does, or would do, with a
+// directory member
+type DirectoryImportEntry struct {
+	Email     string                `json:"email"`
+	FirstName string                `json:"firstName,omitempty"`
+	LastName  string                `json:"lastName,omitempty"`
+	UserID    string                `json:"userId,omitempty"`
+	Action    DirectoryImportAction `json:"action"`
+	Role      string                `json:"role,omitempty"`
+	// Reason says why a member is skipped
+	Reason string `json:"reason,omitempty"`
+	// Error is the code of the error that failed an applied change
+	Error string `json:"error,omitempty"`
+}
+
+// DirectoryImportSummary counts the entries of an import by action
+type DirectoryImportSummary struct {
+	Total         int `json:"total"`
+	Add           int `json:"add"`
+	Invite        int `json:"invite"`
+	AlreadyMember int `json:"alreadyMember"`
+	Skip          int `json:"skip"`
+	Failed        int `json:"failed"`
+}
+
+// DirectoryImportResponse represents the preview or outcome of a directory import
+type DirectoryImportResponse struct {
+	OrganizationID string                 `json:"organizationId"`
+	Source         DirectoryImportSource  `json:"source"`
+	Applied        bool                   `json:"applied"`
+	Summary        DirectoryImportSummary `json:"summary"`
+	Entries        []DirectoryImportEntry `json:"entries"`
+}
+
+// Add adds an entry to the response and counts it
+func (r *DirectoryImportResponse) Add(entry DirectoryImportEntry) {
+	r.Summary.Total++
+	switch {
+	case entry.Error != "":
+		r.Summary.Failed++
+	case entry.Action == DirectoryImportAdd:
+		r.Summary.Add++
+	case entry.Action == DirectoryImportInvite:
+		r.Summary.Invite++
+	case entry.Action == DirectoryImportAlreadyMember:
+		r.Summary.AlreadyMember++
+	case entry.Action == DirectoryImportSkip:
+		r.Summary.Skip++
+	}
+	r.Entries = append(r.Entries, entry)
+}
+
+// Selects checks if an import includes a directory member's email
+func (req DirectoryImportRequest) Selects(email string) bool {
+	if len(req.Emails) == 0 {
+		return true
+	}
+	for _, selected := range req.Emails {
+		if strings.EqualFold(selected, email) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// EmailChangeRequest represents a request to change the current user's email.
+// The change applies once the user verifies the new email with the Auth
+// Service.
+type EmailChangeRequest struct {
+	NewEmail string `json:"newEmail" validate:"required,email,max=254"`
+}
+
+// EmailChangeResponse represents the state of a user's email change
+type EmailChangeResponse struct {
+	Email        string     `json:"email"`
+	PendingEmail string     `json:"pendingEmail,omitempty"`
+	RequestedAt  *time.Time `json:"requestedAt,omitempty"`
+}
+
+// HasPendingEmailChange checks if a user is waiting to verify a new email
+func (u *User) HasPendingEmailChange() bool {
+	return u.PendingEmail != ""
+}
+
+// ToEmailChangeResponse converts a user to the state of their email change
+func (u *User) ToEmailChangeResponse() EmailChangeResponse {
+	return EmailChangeResponse{
+		Email:        u.Email,
+		PendingEmail: u.PendingEmail,
+		RequestedAt:  u.EmailChangeRequestedAt,
+	}
+}
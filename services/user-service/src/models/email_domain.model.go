@@ -0,0 +1,68 @@
+package models
+
+import "strings"
+
+// EmailDomainRule is the domain list rule an email address breaks
+type EmailDomainRule string
+
+// Email domain rules
+const (
+	// EmailDomainBlocked is broken by addresses of a blocked domain
+	EmailDomainBlocked EmailDomainRule = "blocked_email_domain"
+	// EmailDomainExternal is broken by addresses outside the allowed domains
+	// of an organization that doesn't allow external users
+	EmailDomainExternal EmailDomainRule = "allowed_email_domain"
+)
+
+// EmailDomain gets the lowercased domain of an email address
+func EmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(email[at+1:]))
+}
+
+// NormalizeEmailDomains lowercases and trims domains, dropping empty and
+// duplicate ones. Empty lists become nil.
+func NormalizeEmailDomains(domains []string) []string {
+	var normalized []string
+	seen := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+		if domain == "" || seen[domain] {
+			continue
+		}
+		seen[domain] = true
+		normalized = append(normalized, domain)
+	}
+	return normalized
+}
+
+// matchesEmailDomain checks if a domain is one of the domains or a subdomain
+// of one
+func matchesEmailDomain(domain string, domains []string) bool {
+	for _, d := range domains {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// EmailDomainViolation gets the rule of the organization's email domain
+// lists that an address breaks, or an empty rule if it may be added. Blocked
+// domains always win; outside the allowed domains, addresses are only
+// accepted when the organization allows external users. Organizations
+// without allowed domains don't restrict them.
+func (s *OrganizationSettings) EmailDomainViolation(email string) EmailDomainRule {
+	domain := EmailDomain(email)
+	if matchesEmailDomain(domain, s.BlockedEmailDomains) {
+		return EmailDomainBlocked
+	}
+	if len(s.AllowedEmailDomains) > 0 && !s.Features.AllowExternalUsers &&
+		!matchesEmailDomain(domain, s.AllowedEmailDomains) {
+		return EmailDomainExternal
+	}
+	return ""
+}
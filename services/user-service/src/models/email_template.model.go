@@ -0,0 +1,243 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailTemplateKey identifies an email the platform sends on behalf of an organization
+type EmailTemplateKey string
+
+// Email template keys
+const (
+	EmailTemplateInvitation          EmailTemplateKey = "invitation"
+	EmailTemplateMemberAdded         EmailTemplateKey = "member_added"
+	EmailTemplateRoleChanged         EmailTemplateKey = "role_changed"
+	EmailTemplateMemberRemoved       EmailTemplateKey = "member_removed"
+	EmailTemplateJoinRequestApproved EmailTemplateKey = "join_request_approved"
+	EmailTemplateJoinRequestRejected EmailTemplateKey = "join_request_rejected"
+	EmailTemplateOwnershipTransfer   EmailTemplateKey = "ownership_transfer"
+	EmailTemplateNotification        EmailTemplateKey = "notification"
+)
+
+// EmailTemplateKeys lists every email template key that can be overridden
+var EmailTemplateKeys = []EmailTemplateKey{
+	EmailTemplateInvitation,
+	EmailTemplateMemberAdded,
+	EmailTemplateRoleChanged,
+	EmailTemplateMemberRemoved,
+	EmailTemplateJoinRequestApproved,
+	EmailTemplateJoinRequestRejected,
+	EmailTemplateOwnershipTransfer,
+	EmailTemplateNotification,
+}
+
+// EmailTemplateBuiltinVariables are the placeholders the notification service always provides
+var EmailTemplateBuiltinVariables = []string{
+	"orgName", "orgLogoUrl", "primaryColor", "secondaryColor",
+	"userName", "userEmail", "actorName", "actionUrl", "role",
+}
+
+// DefaultEmailTemplates are the platform's subject and intro text of the
+// emails an organization hasn't overridden
+var DefaultEmailTemplates = map[EmailTemplateKey]EmailTemplateContent{
+	EmailTemplateInvitation: {
+		Subject:   "You're invited to join {{orgName}}",
+		IntroText: "{{actorName}} invited you to join {{orgName}} as {{role}}. Accept the invitation to get started.",
+	},
+	EmailTemplateMemberAdded: {
+		Subject:   "You've been added to {{orgName}}",
+		IntroText: "{{actorName}} added you to {{orgName}} as {{role}}.",
+	},
+	EmailTemplateRoleChanged: {
+		Subject:   "Your role in {{orgName}} has changed",
+		IntroText: "{{actorName}} changed your role in {{orgName}} to {{role}}.",
+	},
+	EmailTemplateMemberRemoved: {
+		Subject:   "You've been removed from {{orgName}}",
+		IntroText: "{{actorName}} removed you from {{orgName}}.",
+	},
+	EmailTemplateJoinRequestApproved: {
+		Subject:   "Your request to join {{orgName}} was approved",
+		IntroText: "Your request to join {{orgName}} was approved.",
+	},
+	EmailTemplateJoinRequestRejected: {
+		Subject:   "Your request to join {{orgName}} was declined",
+		IntroText: "Your request to join {{orgName}} was declined.",
+	},
+	EmailTemplateOwnershipTransfer: {
+		Subject:   "{{actorName}} wants to make you the owner of {{orgName}}",
+		IntroText: "{{actorName}} asked to transfer the ownership of {{orgName}} to you.",
+	},
+	EmailTemplateNotification: {
+		Subject:   "News from {{orgName}}",
+		IntroText: "There is news from {{orgName}}.",
+	},
+}
+
+// MaxEmailTemplateVariables limits the branding variables of an email template
+const MaxEmailTemplateVariables = 20
+
+var (
+	emailTemplatePlaceholder = regexp.MustCompile(`\{\{\s*([^{}\s]*)\s*\}\}`)
+	emailTemplateVariable    = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,49}$`)
+)
+
+// EmailTemplate is one version of an organization's override of an email
+// template. Every change creates a new version; a deleted version resets the
+// template to the platform default.
+type EmailTemplate struct {
+	ID             string            `bson:"_id" json:"id"`
+	OrganizationID string            `bson:"organizationId" json:"organizationId"`
+	Key            EmailTemplateKey  `bson:"key" json:"key"`
+	Version        int               `bson:"version" json:"version"`
+	Subject        string            `bson:"subject,omitempty" json:"subject,omitempty"`
+	IntroText      string            `bson:"introText,omitempty" json:"introText,omitempty"`
+	Variables      map[string]string `bson:"variables,omitempty" json:"variables,omitempty"`
+	Deleted        bool              `bson:"deleted,omitempty" json:"deleted,omitempty"`
+	CreatedBy      string            `bson:"createdBy" json:"createdBy"`
+	CreatedAt      time.Time         `bson:"createdAt" json:"createdAt"`
+}
+
+// EmailTemplateContent is the subject and intro text of an email
+type EmailTemplateContent struct {
+	Subject   string `json:"subject"`
+	IntroText string `json:"introText,omitempty"`
+}
+
+// UpdateEmailTemplateRequest represents a request to override an email template.
+// ExpectedVersion, when set, must match the current version.
+type UpdateEmailTemplateRequest struct {
+	Subject         string            `json:"subject" validate:"required,max=200"`
+	IntroText       string            `json:"introText" validate:"max=2000"`
+	Variables       map[string]string `json:"variables" validate:"omitempty,max=20,dive,max=500"`
+	ExpectedVersion *int              `json:"expectedVersion,omitempty" validate:"omitempty,min=0"`
+}
+
+// EmailTemplateBranding holds the organization branding values templates are rendered with
+type EmailTemplateBranding struct {
+	OrgName        string `json:"orgName"`
+	OrgLogoURL     string `json:"orgLogoUrl,omitempty"`
+	PrimaryColor   string `json:"primaryColor,omitempty"`
+	SecondaryColor string `json:"secondaryColor,omitempty"`
+}
+
+// OrganizationEmailTemplatesResponse is the set of template overrides the
+// notification service renders an organization's emails with
+type OrganizationEmailTemplatesResponse struct {
+	OrganizationID string                `json:"organizationId"`
+	Branding       EmailTemplateBranding `json:"branding"`
+	Templates      []*EmailTemplate      `json:"templates"`
+}
+
+// NewEmailTemplate creates a new template version from a request
+func NewEmailTemplate(orgID string, key EmailTemplateKey, version int, req UpdateEmailTemplateRequest, createdBy string) *EmailTemplate {
+	return &EmailTemplate{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		Key:            key,
+		Version:        version,
+		Subject:        req.Subject,
+		IntroText:      req.IntroText,
+		Variables:      req.Variables,
+		CreatedBy:      createdBy,
+		CreatedAt:      time.Now(),
+	}
+}
+
+// NewDeletedEmailTemplate creates a template version that resets the template to the default
+func NewDeletedEmailTemplate(orgID string, key EmailTemplateKey, version int, createdBy string) *EmailTemplate {
+	return &EmailTemplate{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		Key:            key,
+		Version:        version,
+		Deleted:        true,
+		CreatedBy:      createdBy,
+		CreatedAt:      time.Now(),
+	}
+}
+
+// Content gets the subject and intro text the template overrides, falling
+// back to the platform default for a deleted version or an empty intro text
+func (t *EmailTemplate) Content() EmailTemplateContent {
+	content := DefaultEmailTemplates[t.Key]
+	if t.Deleted {
+		return content
+	}
+	content.Subject = t.Subject
+	if t.IntroText != "" {
+		content.IntroText = t.IntroText
+	}
+	return content
+}
+
+// Render replaces the placeholders of the subject and intro text with the
+// values of variables. Placeholders without a value are left empty.
+func (c EmailTemplateContent) Render(variables map[string]string) EmailTemplateContent {
+	render := func(text string) string {
+		return emailTemplatePlaceholder.ReplaceAllStringFunc(text, func(placeholder string) string {
+			return variables[emailTemplatePlaceholder.FindStringSubmatch(placeholder)[1]]
+		})
+	}
+	return EmailTemplateContent{
+		Subject:   render(c.Subject),
+		IntroText: render(c.IntroText),
+	}
+}
+
+// IsValid checks if a key is a known email template key
+func (k EmailTemplateKey) IsValid() bool {
+	for _, key := range EmailTemplateKeys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the variable names and that the subject and intro text only
+// use built-in or defined placeholders
+func (r UpdateEmailTemplateRequest) Validate() error {
+	if len(r.Variables) > MaxEmailTemplateVariables {
+		return fmt.Errorf("at most %d variables are allowed", MaxEmailTemplateVariables)
+	}
+
+	known := make(map[string]bool, len(EmailTemplateBuiltinVariables)+len(r.Variables))
+	for _, name := range EmailTemplateBuiltinVariables {
+		known[name] = true
+	}
+
+	names := make([]string, 0, len(r.Variables))
+	for name := range r.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !emailTemplateVariable.MatchString(name) {
+			return fmt.Errorf("invalid variable name %q", name)
+		}
+		if known[name] {
+			return fmt.Errorf("variable %q overrides a built-in variable", name)
+		}
+		known[name] = true
+	}
+
+	fields := []struct{ name, text string }{
+		{"subject", r.Subject},
+		{"introText", r.IntroText},
+	}
+	for _, field := range fields {
+		for _, match := range emailTemplatePlaceholder.FindAllStringSubmatch(field.text, -1) {
+			if !known[match[1]] {
+				return fmt.Errorf("%s uses unknown placeholder %q", field.name, match[0])
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Precondition is the If-Match precondition of an update: the entity tags
+// the client expects the resource to have, or "*". The zero Precondition
+// always holds.
+type Precondition string
+
+// Holds checks if the precondition holds for the entity tag of a resource
+// version. The tags of the version's representations match it too, so a
+// client can send back the tag of any response it read.
+func (p Precondition) Holds(etag string) bool {
+	if p == "" || ETagListed(string(p), etag, false) {
+		return true
+	}
+	prefix := strings.TrimSuffix(etag, `"`) + "-"
+	for _, listed := range strings.Split(string(p), ",") {
+		if strings.HasPrefix(strings.TrimSpace(listed), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// entityTag gets the entity tag of a resource version. Versions are told
+// apart by their last update, to the millisecond as stored.
+func entityTag(id string, updatedAt time.Time) string {
+	return fmt.Sprintf(`"%s-%x"`, id, updatedAt.UnixMilli())
+}
+
+// RepresentationETag gets the entity tag of a representation of a resource
+// version: the version's tag with a hash of the serialized representation,
+// so responses of the same version with other fields or expansions have
+// other tags
+func RepresentationETag(etag string, representation []byte) string {
+	sum := sha256.Sum256(representation)
+	return strings.TrimSuffix(etag, `"`) + "-" + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// ETagListed checks if an If-Match or If-None-Match header lists an entity
+// tag, or is "*". Weak comparison, as If-None-Match uses, also matches weak
+// tags.
+func ETagListed(header, etag string, weak bool) bool {
+	for _, listed := range strings.Split(header, ",") {
+		listed = strings.TrimSpace(listed)
+		if listed == "*" {
+			return true
+		}
+		if weak {
+			listed = strings.TrimPrefix(listed, "W/")
+		}
+		if listed == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/pkg/id"
+)
+
+// EventStatus represents the lifecycle state of an event
+type EventStatus string
+
+// Event statuses. Events are drafts until a host starts them, and stay
+// ended once ended.
+const (
+	EventDraft EventStatus = "draft"
+	EventLive  EventStatus = "live"
+	EventEnded EventStatus = "ended"
+)
+
+// EventCodeLength is the length of event join codes
+const EventCodeLength = 6
+
+// MaxEventHosts is the most hosts an event may have
+const MaxEventHosts = 20
+
+// Event is a meeting, talk or session of an organization that participants
+// join with its code to ask questions and answer polls. Its hosts run it.
+type Event struct {
+	ID             string `bson:"_id" json:"id"`
+	OrganizationID string `bson:"organizationId" json:"organizationId"`
+	Title          string `bson:"title" json:"title"`
+	Description    string `bson:"description,omitempty" json:"description,omitempty"`
+	// Code is the join code participants enter, unique across organizations
+	Code      string      `bson:"code" json:"code"`
+	StartsAt  time.Time   `bson:"startsAt" json:"startsAt"`
+	EndsAt    time.Time   `bson:"endsAt" json:"endsAt"`
+	HostIDs   []string    `bson:"hostIds" json:"hostIds"`
+	Status    EventStatus `bson:"status" json:"status"`
+	StartedAt *time.Time  `bson:"startedAt,omitempty" json:"startedAt,omitempty"`
+	EndedAt   *time.Time  `bson:"endedAt,omitempty" json:"endedAt,omitempty"`
+	CreatedBy string      `bson:"createdBy" json:"createdBy"`
+	CreatedAt time.Time   `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time   `bson:"updatedAt" json:"updatedAt"`
+}
+
+// CreateEventRequest represents a request to create an event
+type CreateEventRequest struct {
+	Title       string    `json:"title" validate:"required,min=1,max=200"`
+	Description string    `json:"description" validate:"max=2000"`
+	StartsAt    time.Time `json:"startsAt" validate:"required"`
+	EndsAt      time.Time `json:"endsAt" validate:"required,gtfield=StartsAt"`
+	HostIDs     []string  `json:"hostIds" validate:"max=20,dive,required"`
+}
+
+// UpdateEventRequest represents a request to update an event
+type UpdateEventRequest struct {
+	Title       *string    `json:"title,omitempty" validate:"omitempty,min=1,max=200"`
+	Description *string    `json:"description,omitempty" validate:"omitempty,max=2000"`
+	StartsAt    *time.Time `json:"startsAt,omitempty"`
+	EndsAt      *time.Time `json:"endsAt,omitempty"`
+	HostIDs     *[]string  `json:"hostIds,omitempty" validate:"omitempty,min=1,max=20,dive,required"`
+}
+
+// EventFilter filters the events of an organization
+type EventFilter struct {
+	OrganizationID string
+	// Status is the status of the events listed; empty lists all
+	Status EventStatus
+}
+
+// NewEvent creates a new draft event from a request. Its creator hosts it.
+func NewEvent(orgID, code string, req CreateEventRequest, createdBy string) *Event {
+	now := time.Now()
+
+	return &Event{
+		ID:             id.New(),
+		OrganizationID: orgID,
+		Title:          req.Title,
+		Description:    req.Description,
+		Code:           code,
+		StartsAt:       req.StartsAt,
+		EndsAt:         req.EndsAt,
+		HostIDs:        uniqueStrings(append([]string{createdBy}, req.HostIDs...)),
+		Status:         EventDraft,
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// Apply applies an update request to an event
+func (e *Event) Apply(req UpdateEventRequest) {
+	e.UpdatedAt = time.Now()
+
+	if req.Title != nil {
+		e.Title = *req.Title
+	}
+	if req.Description != nil {
+		e.Description = *req.Description
+	}
+	if req.StartsAt != nil {
+		e.StartsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		e.EndsAt = *req.EndsAt
+	}
+	if req.HostIDs != nil {
+		e.HostIDs = uniqueStrings(*req.HostIDs)
+	}
+}
+
+// IsHost checks if a user hosts the event
+func (e *Event) IsHost(userID string) bool {
+	for _, hostID := range e.HostIDs {
+		if hostID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeEventCode normalizes a join code as people type it: any case,
+// with surrounding spaces and a leading #
+func NormalizeEventCode(code string) string {
+	return strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(code), "#"))
+}
@@ -0,0 +1,111 @@
+package models
+
+import "github.com/your-username/slido-clone/user-service/pkg/apperrors"
+
+// MaxFavorites is the most organizations and teams a user may pin
+const MaxFavorites = 20
+
+// FavoriteType represents the kind of item a favorite pins
+type FavoriteType string
+
+// Favorite types
+const (
+	FavoriteOrganization FavoriteType = "organization"
+	FavoriteTeam         FavoriteType = "team"
+)
+
+// Favorite is an organization or team a user pinned to their sidebar
+type Favorite struct {
+	Type FavoriteType `bson:"type" json:"type" validate:"required,oneof=organization team"`
+	ID   string       `bson:"id" json:"id" validate:"required"`
+}
+
+// UpdateFavoritesRequest represents a request to replace a user's favorites.
+// The favorites are kept in the order given.
+type UpdateFavoritesRequest struct {
+	Favorites []Favorite `json:"favorites" validate:"max=20,dive"`
+}
+
+// FavoriteResponse represents a favorite with the name and logo of the
+// organization or team it pins
+type FavoriteResponse struct {
+	Type           FavoriteType `json:"type"`
+	ID             string       `json:"id"`
+	Name           string       `json:"name"`
+	LogoURL        string       `json:"logoUrl,omitempty"`
+	OrganizationID string       `json:"organizationId,omitempty"`
+}
+
+// ValidateFavorites checks that a user's favorites are distinct organizations
+// and teams the user is a member of
+func (u *User) ValidateFavorites(favorites []Favorite) error {
+	seen := make(map[Favorite]bool, len(favorites))
+	for _, favorite := range favorites {
+		if seen[favorite] {
+			return apperrors.InvalidField("favorites", string(favorite.Type)+" "+favorite.ID+" is pinned more than once")
+		}
+		seen[favorite] = true
+
+		var memberOf []string
+		switch favorite.Type {
+		case FavoriteOrganization:
+			memberOf = u.OrganizationIDs
+		case FavoriteTeam:
+			memberOf = u.TeamIDs
+		}
+		if !containsString(memberOf, favorite.ID) {
+			return apperrors.InvalidField("favorites", "you are not a member of "+string(favorite.Type)+" "+favorite.ID)
+		}
+	}
+	return nil
+}
+
+// ResolveFavorites pairs a user's favorites with their organizations and
+// teams, in the user's order. Favorites of organizations and teams the user
+// is no longer in are left out.
+func ResolveFavorites(favorites []Favorite, orgs []*Organization, teams []*Team) []FavoriteResponse {
+	orgsByID := make(map[string]*Organization, len(orgs))
+	for _, org := range orgs {
+		orgsByID[org.ID] = org
+	}
+	teamsByID := make(map[string]*Team, len(teams))
+	for _, team := range teams {
+		teamsByID[team.ID] = team
+	}
+
+	resolved := make([]FavoriteResponse, 0, len(favorites))
+	for _, favorite := range favorites {
+		switch favorite.Type {
+		case FavoriteOrganization:
+			if org, ok := orgsByID[favorite.ID]; ok {
+				resolved = append(resolved, FavoriteResponse{
+					Type:    favorite.Type,
+					ID:      org.ID,
+					Name:    org.Name,
+					LogoURL: org.LogoURL,
+				})
+			}
+		case FavoriteTeam:
+			if team, ok := teamsByID[favorite.ID]; ok {
+				resolved = append(resolved, FavoriteResponse{
+					Type:           favorite.Type,
+					ID:             team.ID,
+					Name:           team.Name,
+					LogoURL:        team.LogoURL,
+					OrganizationID: team.OrganizationID,
+				})
+			}
+		}
+	}
+	return resolved
+}
+
+// containsString checks if a list of strings contains a value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"hash/fnv"
+	"regexp"
+	"time"
+)
+
+// Feature flag override scopes
+const (
+	FlagScopeOrganizations = "organizations"
+	FlagScopeUsers         = "users"
+)
+
+// MaxFlagOverrides is the most overrides a flag may have in each scope
+const MaxFlagOverrides = 1000
+
+// flagKeyPattern is the form of feature flag keys, such as new-editor or
+// billing.v2
+var flagKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// FeatureFlag is a feature that can be turned on for some organizations and
+// users before everyone. A disabled flag is off for everyone. Otherwise an
+// override for the user decides, then one for the organization, and then the
+// rollout: the flag is on for Rollout percent of users.
+type FeatureFlag struct {
+	Key           string          `bson:"_id" json:"key"`
+	Description   string          `bson:"description,omitempty" json:"description,omitempty"`
+	Enabled       bool            `bson:"enabled" json:"enabled"`
+	Rollout       int             `bson:"rollout" json:"rollout"`
+	Organizations map[string]bool `bson:"organizations,omitempty" json:"organizations,omitempty"`
+	Users         map[string]bool `bson:"users,omitempty" json:"users,omitempty"`
+	UpdatedBy     string          `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
+	CreatedAt     time.Time       `bson:"createdAt" json:"createdAt"`
+	UpdatedAt     time.Time       `bson:"updatedAt" json:"updatedAt"`
+}
+
+// UpdateFeatureFlagRequest represents a request to create or update a feature
+// flag. Fields left out keep their value; a new flag is disabled with no
+// rollout unless they are set.
+type UpdateFeatureFlagRequest struct {
+	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
+	Enabled     *bool   `json:"enabled,omitempty"`
+	Rollout     *int    `json:"rollout,omitempty" validate:"omitempty,min=0,max=100"`
+}
+
+// SetFlagOverrideRequest represents a request to turn a flag on or off for an
+// organization or user
+type SetFlagOverrideRequest struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+// FeatureFlagsResponse is the flag set evaluated for a caller, by flag key
+type FeatureFlagsResponse struct {
+	OrganizationID string          `json:"organizationId,omitempty"`
+	Flags          map[string]bool `json:"flags"`
+}
+
+// ValidFlagKey checks if a feature flag key is well formed
+func ValidFlagKey(key string) bool {
+	return flagKeyPattern.MatchString(key)
+}
+
+// ValidFlagScope checks if an override scope exists
+func ValidFlagScope(scope string) bool {
+	return scope == FlagScopeOrganizations || scope == FlagScopeUsers
+}
+
+// Apply applies an update request to the flag
+func (f *FeatureFlag) Apply(req UpdateFeatureFlagRequest, updatedBy string) {
+	if req.Description != nil {
+		f.Description = *req.Description
+	}
+	if req.Enabled != nil {
+		f.Enabled = *req.Enabled
+	}
+	if req.Rollout != nil {
+		f.Rollout = *req.Rollout
+	}
+	f.UpdatedBy = updatedBy
+	f.UpdatedAt = time.Now()
+}
+
+// Overrides returns the overrides of a scope
+func (f *FeatureFlag) Overrides(scope string) map[string]bool {
+	if scope == FlagScopeUsers {
+		return f.Users
+	}
+	return f.Organizations
+}
+
+// Evaluate checks if the flag is on for a user, in an organization if orgID
+// is set
+func (f *FeatureFlag) Evaluate(userID, orgID string) bool {
+	if !f.Enabled {
+		return false
+	}
+	if enabled, ok := f.Users[userID]; ok && userID != "" {
+		return enabled
+	}
+	if enabled, ok := f.Organizations[orgID]; ok && orgID != "" {
+		return enabled
+	}
+	return f.Rollout >= 100 || (f.Rollout > 0 && flagBucket(f.Key, userID) < f.Rollout)
+}
+
+// flagBucket places a user in one of 100 buckets of a flag. Hashing the key
+// with the user ID keeps each user's result stable as the rollout grows, and
+// spreads users differently for each flag.
+func flagBucket(key, userID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key + ":" + userID))
+	return int(h.Sum32() % 100)
+}
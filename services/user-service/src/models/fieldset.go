@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+)
+
+// FieldSet is the sparse fieldset of a request: the response fields a client
+// asked for with ?fields=, and the expansions it asked for with ?include=.
+// The zero FieldSet selects every field and no expansions.
+type FieldSet struct {
+	fields  map[string]bool
+	include map[string]bool
+}
+
+// ParseFieldSet parses the fields and include parameters of a request, as
+// comma-separated lists. responseFields maps the fields of the response to
+// the stored fields they are read from, and expansions are the fields it
+// only has when included. The id is always selected; expansions are selected
+// when included, and included when selected.
+func ParseFieldSet(fields, include string, responseFields map[string][]string, expansions ...string) (FieldSet, error) {
+	var fieldSet FieldSet
+
+	for _, name := range splitList(include) {
+		if !containsString(expansions, name) {
+			return FieldSet{}, apperrors.InvalidField("include", fmt.Sprintf("include must be one of %s", strings.Join(expansions, ", ")))
+		}
+		if fieldSet.include == nil {
+			fieldSet.include = make(map[string]bool)
+		}
+		fieldSet.include[name] = true
+	}
+
+	names := splitList(fields)
+	if len(names) == 0 {
+		return fieldSet, nil
+	}
+	fieldSet.fields = map[string]bool{"id": true}
+	for _, name := range names {
+		if _, ok := responseFields[name]; !ok {
+			return FieldSet{}, apperrors.InvalidField("fields", fmt.Sprintf("unknown field %q; fields are %s", name, strings.Join(sortedKeys(responseFields), ", ")))
+		}
+		fieldSet.fields[name] = true
+		if containsString(expansions, name) {
+			if fieldSet.include == nil {
+				fieldSet.include = make(map[string]bool)
+			}
+			fieldSet.include[name] = true
+		}
+	}
+	for name := range fieldSet.include {
+		fieldSet.fields[name] = true
+	}
+	return fieldSet, nil
+}
+
+// Sparse checks if the fieldset selects only some fields
+func (f FieldSet) Sparse() bool {
+	return f.fields != nil
+}
+
+// Has checks if the fieldset selects a field
+func (f FieldSet) Has(field string) bool {
+	return f.fields == nil || f.fields[field]
+}
+
+// Includes checks if the fieldset includes an expansion
+func (f FieldSet) Includes(expansion string) bool {
+	return f.include[expansion]
+}
+
+// StoredFields gets the stored fields the selected fields are read from,
+// along with the required ones, or nil if every field is selected
+func (f FieldSet) StoredFields(responseFields map[string][]string, required ...string) []string {
+	if f.fields == nil {
+		return nil
+	}
+	stored := append([]string{}, required...)
+	for field := range f.fields {
+		stored = append(stored, responseFields[field]...)
+	}
+	return stored
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(s string) []string {
+	var values []string
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
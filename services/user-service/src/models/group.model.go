@@ -0,0 +1,150 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxGroupMembers is the most members a group may have
+const MaxGroupMembers = 5000
+
+// Group is a lightweight set of organization members, such as "All
+// Engineers", used to grant permissions and add people to teams in bulk
+// without creating a team
+type Group struct {
+	ID             string       `bson:"_id" json:"id"`
+	OrganizationID string       `bson:"organizationId" json:"organizationId"`
+	Name           string       `bson:"name" json:"name"`
+	Description    string       `bson:"description,omitempty" json:"description,omitempty"`
+	MemberIDs      []string     `bson:"memberIds" json:"memberIds"`
+	Permissions    []Permission `bson:"permissions" json:"permissions"`
+	CreatedBy      string       `bson:"createdBy" json:"createdBy"`
+	CreatedAt      time.Time    `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time    `bson:"updatedAt" json:"updatedAt"`
+}
+
+// CreateGroupRequest represents a request to create a group
+type CreateGroupRequest struct {
+	Name        string       `json:"name" validate:"required,min=1,max=100"`
+	Description string       `json:"description" validate:"max=500"`
+	Permissions []Permission `json:"permissions" validate:"max=50,dive,required"`
+	MemberIDs   []string     `json:"memberIds" validate:"max=1000,dive,required"`
+}
+
+// UpdateGroupRequest represents a request to update a group
+type UpdateGroupRequest struct {
+	Name        *string       `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description *string       `json:"description,omitempty" validate:"omitempty,max=500"`
+	Permissions *[]Permission `json:"permissions,omitempty" validate:"omitempty,max=50,dive,required"`
+}
+
+// AddGroupMembersRequest represents a request to add members to a group
+type AddGroupMembersRequest struct {
+	UserIDs []string `json:"userIds" validate:"required,min=1,max=1000,dive,required"`
+}
+
+// AddTeamGroupRequest represents a request to add the members of a group to
+// a team
+type AddTeamGroupRequest struct {
+	GroupID string         `json:"groupId" validate:"required"`
+	Role    TeamMemberRole `json:"role" validate:"required,oneof=admin member viewer"`
+}
+
+// AddTeamGroupResponse reports the outcome of adding a group to a team
+type AddTeamGroupResponse struct {
+	Added   int `json:"added"`
+	Skipped int `json:"skipped"`
+}
+
+// GroupResponse represents a group response
+type GroupResponse struct {
+	ID             string       `json:"id"`
+	OrganizationID string       `json:"organizationId"`
+	Name           string       `json:"name"`
+	Description    string       `json:"description,omitempty"`
+	Permissions    []Permission `json:"permissions"`
+	MemberCount    int          `json:"memberCount"`
+	MemberIDs      []string     `json:"memberIds,omitempty"`
+	CreatedBy      string       `json:"createdBy"`
+	CreatedAt      time.Time    `json:"createdAt"`
+	UpdatedAt      time.Time    `json:"updatedAt"`
+}
+
+// NewGroup creates a new group from a request
+func NewGroup(orgID string, req CreateGroupRequest, createdBy string) *Group {
+	now := time.Now()
+	permissions := req.Permissions
+	if permissions == nil {
+		permissions = []Permission{}
+	}
+
+	return &Group{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		Name:           req.Name,
+		Description:    req.Description,
+		MemberIDs:      uniqueStrings(req.MemberIDs),
+		Permissions:    permissions,
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// Apply applies an update request to a group
+func (g *Group) Apply(req UpdateGroupRequest) {
+	g.UpdatedAt = time.Now()
+
+	if req.Name != nil {
+		g.Name = *req.Name
+	}
+	if req.Description != nil {
+		g.Description = *req.Description
+	}
+	if req.Permissions != nil {
+		g.Permissions = *req.Permissions
+	}
+}
+
+// ToResponse converts a group to a response
+func (g *Group) ToResponse(includeMembers bool) GroupResponse {
+	response := GroupResponse{
+		ID:             g.ID,
+		OrganizationID: g.OrganizationID,
+		Name:           g.Name,
+		Description:    g.Description,
+		Permissions:    g.Permissions,
+		MemberCount:    len(g.MemberIDs),
+		CreatedBy:      g.CreatedBy,
+		CreatedAt:      g.CreatedAt,
+		UpdatedAt:      g.UpdatedAt,
+	}
+	if includeMembers {
+		response.MemberIDs = g.MemberIDs
+	}
+	return response
+}
+
+// IsMember checks if a user is a member of the group
+func (g *Group) IsMember(userID string) bool {
+	for _, memberID := range g.MemberIDs {
+		if memberID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueStrings returns the distinct strings of a list, in order
+func uniqueStrings(values []string) []string {
+	unique := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,6 @@
+package models
+
+// IsGuest checks if the member is a guest
+func (m OrganizationMember) IsGuest() bool {
+	return m.Role == OrgRoleGuest
+}
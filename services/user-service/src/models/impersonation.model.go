@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImpersonationSession is a time-limited session of a support engineer
+// acting as a user. Sessions are read-only unless started otherwise, and
+// every request made in one is recorded in the audit log.
+type ImpersonationSession struct {
+	ID        string     `bson:"_id" json:"id"`
+	AdminID   string     `bson:"adminId" json:"adminId"`
+	UserID    string     `bson:"userId" json:"userId"`
+	Reason    string     `bson:"reason" json:"reason"`
+	ReadOnly  bool       `bson:"readOnly" json:"readOnly"`
+	StartedAt time.Time  `bson:"startedAt" json:"startedAt"`
+	ExpiresAt time.Time  `bson:"expiresAt" json:"expiresAt"`
+	EndedAt   *time.Time `bson:"endedAt,omitempty" json:"endedAt,omitempty"`
+	EndedBy   string     `bson:"endedBy,omitempty" json:"endedBy,omitempty"`
+}
+
+// StartImpersonationRequest represents a request to impersonate a user
+type StartImpersonationRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+	// ReadOnly defaults to true; only read requests are allowed then
+	ReadOnly *bool `json:"readOnly,omitempty"`
+}
+
+// ImpersonationResponse represents a started impersonation session with the
+// access token to act as the user
+type ImpersonationResponse struct {
+	Token   string                `json:"token"`
+	Session *ImpersonationSession `json:"session"`
+}
+
+// NewImpersonationSession creates a new impersonation session of an admin
+// acting as a user, lasting ttl
+func NewImpersonationSession(adminID, userID string, req StartImpersonationRequest, ttl time.Duration) *ImpersonationSession {
+	now := time.Now()
+	readOnly := true
+	if req.ReadOnly != nil {
+		readOnly = *req.ReadOnly
+	}
+
+	return &ImpersonationSession{
+		ID:        uuid.New().String(),
+		AdminID:   adminID,
+		UserID:    userID,
+		Reason:    req.Reason,
+		ReadOnly:  readOnly,
+		StartedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+}
+
+// IsActive checks if the session is neither ended nor expired at a time
+func (s *ImpersonationSession) IsActive(now time.Time) bool {
+	return s.EndedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InboxNotification is a notification in a user's in-app inbox. Inbox
+// notifications are kept until they expire, read or not.
+type InboxNotification struct {
+	ID               string           `bson:"_id" json:"id"`
+	UserID           string           `bson:"userId" json:"-"`
+	Type             NotificationType `bson:"type" json:"type"`
+	ActorID          string           `bson:"actorId,omitempty" json:"actorId,omitempty"`
+	OrganizationID   string           `bson:"organizationId,omitempty" json:"organizationId,omitempty"`
+	OrganizationName string           `bson:"organizationName,omitempty" json:"organizationName,omitempty"`
+	TeamID           string           `bson:"teamId,omitempty" json:"teamId,omitempty"`
+	TeamName         string           `bson:"teamName,omitempty" json:"teamName,omitempty"`
+	Role             string           `bson:"role,omitempty" json:"role,omitempty"`
+	Reason           string           `bson:"reason,omitempty" json:"reason,omitempty"`
+	EventID          string           `bson:"eventId" json:"-"`
+	OccurredAt       time.Time        `bson:"occurredAt" json:"occurredAt"`
+	ReadAt           *time.Time       `bson:"readAt" json:"readAt,omitempty"`
+	CreatedAt        time.Time        `bson:"createdAt" json:"createdAt"`
+	ExpiresAt        time.Time        `bson:"expiresAt" json:"-"`
+}
+
+// NewInboxNotification creates the inbox notification of a notification,
+// kept for retention
+func NewInboxNotification(n *Notification, retention time.Duration) *InboxNotification {
+	now := time.Now()
+	return &InboxNotification{
+		ID:               uuid.New().String(),
+		UserID:           n.UserID,
+		Type:             n.Type,
+		ActorID:          n.ActorID,
+		OrganizationID:   n.OrganizationID,
+		OrganizationName: n.OrganizationName,
+		TeamID:           n.TeamID,
+		TeamName:         n.TeamName,
+		Role:             n.Role,
+		Reason:           n.Reason,
+		EventID:          n.EventID,
+		OccurredAt:       n.OccurredAt,
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(retention),
+	}
+}
+
+// InboxFilter represents the filters of an inbox page
+type InboxFilter struct {
+	UserID     string
+	UnreadOnly bool
+}
+
+// InboxResponse represents a page of a user's inbox, newest first, with the
+// number of unread notifications in the whole inbox. Pages continue after
+// NextCursor.
+type InboxResponse struct {
+	Notifications []*InboxNotification `json:"notifications"`
+	UnreadCount   int64                `json:"unreadCount"`
+	NextCursor    string               `json:"nextCursor,omitempty"`
+	Limit         int                  `json:"limit"`
+}
+
+// InboxUnreadResponse represents the number of unread notifications in a
+// user's inbox
+type InboxUnreadResponse struct {
+	UnreadCount int64 `json:"unreadCount"`
+}
+
+// MarkInboxReadRequest represents a request to mark every notification of
+// an inbox read. Before, when set, only marks notifications that occurred by
+// then, so notifications that arrived after the user looked stay unread.
+type MarkInboxReadRequest struct {
+	Before *time.Time `json:"before,omitempty"`
+}
+
+// MarkInboxReadResponse represents the result of marking notifications read
+type MarkInboxReadResponse struct {
+	Updated     int64 `json:"updated"`
+	UnreadCount int64 `json:"unreadCount"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/pkg/jobs"
+)
+
+// JobResponse represents a scheduled job with its next and latest run
+type JobResponse struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Schedule    string    `json:"schedule"`
+	NextRunAt   time.Time `json:"nextRunAt"`
+	LastRun     *jobs.Run `json:"lastRun,omitempty"`
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JoinRequestStatus represents the state of a join request
+type JoinRequestStatus string
+
+// Join request statuses
+const (
+	JoinRequestPending   JoinRequestStatus = "pending"
+	JoinRequestApproved  JoinRequestStatus = "approved"
+	JoinRequestRejected  JoinRequestStatus = "rejected"
+	JoinRequestCancelled JoinRequestStatus = "cancelled"
+)
+
+// JoinRequest represents a user's request to join an organization. A join
+// request made by the organization, with InvitedBy set, is an invitation,
+// which the invited user accepts rather than an admin.
+type JoinRequest struct {
+	ID             string                 `bson:"_id" json:"id"`
+	OrganizationID string                 `bson:"organizationId" json:"organizationId"`
+	UserID         string                 `bson:"userId" json:"userId"`
+	Message        string                 `bson:"message,omitempty" json:"message,omitempty"`
+	Status         JoinRequestStatus      `bson:"status" json:"status"`
+	Role           OrganizationMemberRole `bson:"role,omitempty" json:"role,omitempty"`
+	InvitedBy      string                 `bson:"invitedBy,omitempty" json:"invitedBy,omitempty"`
+	ExpiresAt      *time.Time             `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	ReviewedBy     string                 `bson:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+	ReviewReason   string                 `bson:"reviewReason,omitempty" json:"reviewReason,omitempty"`
+	ReviewedAt     *time.Time             `bson:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	CreatedAt      time.Time              `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time              `bson:"updatedAt" json:"updatedAt"`
+}
+
+// CreateJoinRequestRequest represents a request to join an organization
+type CreateJoinRequestRequest struct {
+	Message string `json:"message" validate:"max=500"`
+}
+
+// ApproveJoinRequestRequest represents a request to approve a join request
+type ApproveJoinRequestRequest struct {
+	Role   OrganizationMemberRole `json:"role" validate:"omitempty,oneof=admin member"`
+	Reason string                 `json:"reason" validate:"max=500"`
+}
+
+// RejectJoinRequestRequest represents a request to reject a join request
+type RejectJoinRequestRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// JoinRequestResponse represents a join request response
+type JoinRequestResponse struct {
+	ID             string                 `json:"id"`
+	OrganizationID string                 `json:"organizationId"`
+	UserID         string                 `json:"userId"`
+	Email          string                 `json:"email,omitempty"`
+	FullName       string                 `json:"fullName,omitempty"`
+	Message        string                 `json:"message,omitempty"`
+	Status         JoinRequestStatus      `json:"status"`
+	Role           OrganizationMemberRole `json:"role,omitempty"`
+	InvitedBy      string                 `json:"invitedBy,omitempty"`
+	ExpiresAt      *time.Time             `json:"expiresAt,omitempty"`
+	ReviewedBy     string                 `json:"reviewedBy,omitempty"`
+	ReviewReason   string                 `json:"reviewReason,omitempty"`
+	ReviewedAt     *time.Time             `json:"reviewedAt,omitempty"`
+	CreatedAt      time.Time              `json:"createdAt"`
+}
+
+// NewJoinRequest creates a new pending join request
+func NewJoinRequest(orgID, userID string, req CreateJoinRequestRequest) *JoinRequest {
+	now := time.Now()
+	return &JoinRequest{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		UserID:         userID,
+		Message:        req.Message,
+		Status:         JoinRequestPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// NewInvitation creates a new pending invitation of a user to join an
+// organization with a role
+func NewInvitation(orgID, userID string, role OrganizationMemberRole, expiresAt *time.Time, invitedBy string) *JoinRequest {
+	invitation := NewJoinRequest(orgID, userID, CreateJoinRequestRequest{})
+	invitation.Role = role
+	invitation.ExpiresAt = expiresAt
+	invitation.InvitedBy = invitedBy
+	return invitation
+}
+
+// IsInvitation checks if the join request was made by the organization
+func (r *JoinRequest) IsInvitation() bool {
+	return r.InvitedBy != ""
+}
+
+// IsPending checks if the join request is awaiting review
+func (r *JoinRequest) IsPending() bool {
+	return r.Status == JoinRequestPending
+}
+
+// ToResponse converts a join request to a response
+func (r *JoinRequest) ToResponse(user *User) JoinRequestResponse {
+	response := JoinRequestResponse{
+		ID:             r.ID,
+		OrganizationID: r.OrganizationID,
+		UserID:         r.UserID,
+		Message:        r.Message,
+		Status:         r.Status,
+		Role:           r.Role,
+		InvitedBy:      r.InvitedBy,
+		ExpiresAt:      r.ExpiresAt,
+		ReviewedBy:     r.ReviewedBy,
+		ReviewReason:   r.ReviewReason,
+		ReviewedAt:     r.ReviewedAt,
+		CreatedAt:      r.CreatedAt,
+	}
+
+	if user != nil {
+		response.Email = user.Email
+		response.FullName = user.FirstName + " " + user.LastName
+	}
+
+	return response
+}
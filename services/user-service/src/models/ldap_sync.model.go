@@ -0,0 +1,383 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LDAPSyncActor is recorded as who added the members an LDAP sync adds, so
+// later syncs only remove members they added
+const LDAPSyncActor = "ldap-sync"
+
+// LDAP sync defaults
+const (
+	DefaultLDAPUserFilter = "(objectClass=person)"
+	DefaultLDAPSyncHour   = 2
+	// MaxLDAPSyncChanges is the most changes a sync report lists; further
+	// changes are only counted
+	MaxLDAPSyncChanges = 1000
+)
+
+// LDAPSyncConfig is an organization's connection to its LDAP or Active
+// Directory server, and how directory entries map to members and teams
+type LDAPSyncConfig struct {
+	OrganizationID     string               `bson:"_id" json:"organizationId"`
+	Enabled            bool                 `bson:"enabled" json:"enabled"`
+	URL                string               `bson:"url" json:"url"`
+	BindDN             string               `bson:"bindDn" json:"bindDn"`
+	BindPassword       string               `bson:"bindPassword,omitempty" json:"-"`
+	InsecureSkipVerify bool                 `bson:"insecureSkipVerify,omitempty" json:"insecureSkipVerify,omitempty"`
+	BaseDN             string               `bson:"baseDn" json:"baseDn"`
+	UserFilter         string               `bson:"userFilter" json:"userFilter"`
+	Attributes         LDAPAttributeMapping `bson:"attributes" json:"attributes"`
+	TeamMappings       []LDAPTeamMapping    `bson:"teamMappings,omitempty" json:"teamMappings"`
+	RoleMappings       []LDAPRoleMapping    `bson:"roleMappings,omitempty" json:"roleMappings"`
+	// RemoveMissing removes members and team members a sync added once they
+	// leave the directory or their mapped OU
+	RemoveMissing bool `bson:"removeMissing" json:"removeMissing"`
+	// DryRun makes scheduled syncs only report the changes they would make
+	DryRun bool `bson:"dryRun" json:"dryRun"`
+	// SyncHour is the hour of the day, in UTC, of the nightly sync
+	SyncHour  int        `bson:"syncHour" json:"syncHour"`
+	NextRunAt *time.Time `bson:"nextRunAt,omitempty" json:"nextRunAt,omitempty"`
+	LastRunID string     `bson:"lastRunId,omitempty" json:"lastRunId,omitempty"`
+	LastRunAt *time.Time `bson:"lastRunAt,omitempty" json:"lastRunAt,omitempty"`
+	// RunningSince is set while a sync runs, so runs of an organization
+	// don't overlap
+	RunningSince *time.Time `bson:"runningSince,omitempty" json:"-"`
+	UpdatedBy    string     `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
+	UpdatedAt    time.Time  `bson:"updatedAt" json:"updatedAt"`
+}
+
+// LDAPAttributeMapping names the directory attributes user fields are read from
+type LDAPAttributeMapping struct {
+	Email     string `bson:"email" json:"email"`
+	FirstName string `bson:"firstName" json:"firstName"`
+	LastName  string `bson:"lastName" json:"lastName"`
+	JobTitle  string `bson:"jobTitle,omitempty" json:"jobTitle,omitempty"`
+}
+
+// LDAPTeamMapping adds the users of an organizational unit, including its
+// sub-units, to a team
+type LDAPTeamMapping struct {
+	OU     string `bson:"ou" json:"ou" validate:"required,max=500"`
+	TeamID string `bson:"teamId" json:"teamId" validate:"required"`
+}
+
+// LDAPRoleMapping gives users with an attribute value an organization role.
+// Values match case-insensitively, and the first matching mapping wins.
+type LDAPRoleMapping struct {
+	Attribute string                 `bson:"attribute" json:"attribute" validate:"required,max=100"`
+	Value     string                 `bson:"value" json:"value" validate:"required,max=500"`
+	Role      OrganizationMemberRole `bson:"role" json:"role" validate:"required,oneof=admin member"`
+}
+
+// UpdateLDAPSyncRequest represents a request to configure an organization's
+// LDAP sync
+type UpdateLDAPSyncRequest struct {
+	Enabled            *bool                 `json:"enabled,omitempty"`
+	URL                string                `json:"url" validate:"required,url,startswith=ldap://|startswith=ldaps://"`
+	BindDN             string                `json:"bindDn" validate:"max=500"`
+	BindPassword       *string               `json:"bindPassword,omitempty" validate:"omitempty,max=256"`
+	InsecureSkipVerify bool                  `json:"insecureSkipVerify,omitempty"`
+	BaseDN             string                `json:"baseDn" validate:"required,max=500"`
+	UserFilter         string                `json:"userFilter" validate:"max=1000"`
+	Attributes         *LDAPAttributeMapping `json:"attributes,omitempty"`
+	TeamMappings       []LDAPTeamMapping     `json:"teamMappings" validate:"max=100,dive"`
+	RoleMappings       []LDAPRoleMapping     `json:"roleMappings" validate:"max=100,dive"`
+	RemoveMissing      bool                  `json:"removeMissing"`
+	DryRun             bool                  `json:"dryRun"`
+	SyncHour           *int                  `json:"syncHour,omitempty" validate:"omitempty,min=0,max=23"`
+}
+
+// LDAPSyncResponse represents an organization's LDAP sync configuration
+type LDAPSyncResponse struct {
+	LDAPSyncConfig
+	HasBindPassword bool `json:"hasBindPassword"`
+	Running         bool `json:"running"`
+}
+
+// LDAPSyncTrigger is what started an LDAP sync
+type LDAPSyncTrigger string
+
+// LDAP sync triggers
+const (
+	LDAPSyncScheduled LDAPSyncTrigger = "scheduled"
+	LDAPSyncManual    LDAPSyncTrigger = "manual"
+)
+
+// LDAPSyncStatus is the outcome of an LDAP sync
+type LDAPSyncStatus string
+
+// LDAP sync statuses
+const (
+	LDAPSyncRunning   LDAPSyncStatus = "running"
+	LDAPSyncSucceeded LDAPSyncStatus = "succeeded"
+	LDAPSyncFailed    LDAPSyncStatus = "failed"
+)
+
+// LDAPSyncAction is a change an LDAP sync makes
+type LDAPSyncAction string
+
+// LDAP sync actions
+const (
+	LDAPSyncUserCreated       LDAPSyncAction = "user.created"
+	LDAPSyncUserUpdated       LDAPSyncAction = "user.updated"
+	LDAPSyncMemberAdded       LDAPSyncAction = "member.added"
+	LDAPSyncMemberRoleChanged LDAPSyncAction = "member.role_changed"
+	LDAPSyncMemberRemoved     LDAPSyncAction = "member.removed"
+	LDAPSyncTeamMemberAdded   LDAPSyncAction = "team_member.added"
+	LDAPSyncTeamMemberRemoved LDAPSyncAction = "team_member.removed"
+	LDAPSyncEntrySkipped      LDAPSyncAction = "entry.skipped"
+)
+
+// LDAPSyncRun is the report of an LDAP sync of an organization
+type LDAPSyncRun struct {
+	ID               string           `bson:"_id" json:"id"`
+	OrganizationID   string           `bson:"organizationId" json:"organizationId"`
+	Trigger          LDAPSyncTrigger  `bson:"trigger" json:"trigger"`
+	TriggeredBy      string           `bson:"triggeredBy,omitempty" json:"triggeredBy,omitempty"`
+	DryRun           bool             `bson:"dryRun" json:"dryRun"`
+	Status           LDAPSyncStatus   `bson:"status" json:"status"`
+	Error            string           `bson:"error,omitempty" json:"error,omitempty"`
+	Summary          LDAPSyncSummary  `bson:"summary" json:"summary"`
+	Changes          []LDAPSyncChange `bson:"changes,omitempty" json:"changes,omitempty"`
+	ChangesTruncated bool             `bson:"changesTruncated,omitempty" json:"changesTruncated,omitempty"`
+	StartedAt        time.Time        `bson:"startedAt" json:"startedAt"`
+	FinishedAt       *time.Time       `bson:"finishedAt,omitempty" json:"finishedAt,omitempty"`
+}
+
+// LDAPSyncSummary counts what an LDAP sync read and changed
+type LDAPSyncSummary struct {
+	EntriesRead        int `bson:"entriesRead" json:"entriesRead"`
+	UsersCreated       int `bson:"usersCreated" json:"usersCreated"`
+	UsersUpdated       int `bson:"usersUpdated" json:"usersUpdated"`
+	MembersAdded       int `bson:"membersAdded" json:"membersAdded"`
+	RolesChanged       int `bson:"rolesChanged" json:"rolesChanged"`
+	MembersRemoved     int `bson:"membersRemoved" json:"membersRemoved"`
+	TeamMembersAdded   int `bson:"teamMembersAdded" json:"teamMembersAdded"`
+	TeamMembersRemoved int `bson:"teamMembersRemoved" json:"teamMembersRemoved"`
+	Skipped            int `bson:"skipped" json:"skipped"`
+	Failed             int `bson:"failed" json:"failed"`
+}
+
+// LDAPSyncChange is a change an LDAP sync made, or would make in a dry run
+type LDAPSyncChange struct {
+	Action       LDAPSyncAction `bson:"action" json:"action"`
+	DN           string         `bson:"dn,omitempty" json:"dn,omitempty"`
+	Email        string         `bson:"email,omitempty" json:"email,omitempty"`
+	UserID       string         `bson:"userId,omitempty" json:"userId,omitempty"`
+	TeamID       string         `bson:"teamId,omitempty" json:"teamId,omitempty"`
+	Role         string         `bson:"role,omitempty" json:"role,omitempty"`
+	PreviousRole string         `bson:"previousRole,omitempty" json:"previousRole,omitempty"`
+	Reason       string         `bson:"reason,omitempty" json:"reason,omitempty"`
+	Error        string         `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// RunLDAPSyncRequest represents a request to run an organization's LDAP sync now
+type RunLDAPSyncRequest struct {
+	DryRun bool `json:"dryRun"`
+}
+
+// Apply applies an update request to an LDAP sync configuration
+func (c *LDAPSyncConfig) Apply(req UpdateLDAPSyncRequest, updatedBy string) {
+	c.Enabled = true
+	if req.Enabled != nil {
+		c.Enabled = *req.Enabled
+	}
+	c.URL = req.URL
+	c.BindDN = req.BindDN
+	if req.BindPassword != nil {
+		c.BindPassword = *req.BindPassword
+	}
+	c.InsecureSkipVerify = req.InsecureSkipVerify
+	c.BaseDN = req.BaseDN
+
+	c.UserFilter = req.UserFilter
+	if c.UserFilter == "" {
+		c.UserFilter = DefaultLDAPUserFilter
+	}
+
+	c.Attributes = LDAPAttributeMapping{}
+	if req.Attributes != nil {
+		c.Attributes = *req.Attributes
+	}
+	if c.Attributes.Email == "" {
+		c.Attributes.Email = "mail"
+	}
+	if c.Attributes.FirstName == "" {
+		c.Attributes.FirstName = "givenName"
+	}
+	if c.Attributes.LastName == "" {
+		c.Attributes.LastName = "sn"
+	}
+
+	c.TeamMappings = req.TeamMappings
+	c.RoleMappings = req.RoleMappings
+	c.RemoveMissing = req.RemoveMissing
+	c.DryRun = req.DryRun
+
+	if req.SyncHour != nil {
+		c.SyncHour = *req.SyncHour
+	} else if c.UpdatedAt.IsZero() {
+		c.SyncHour = DefaultLDAPSyncHour
+	}
+	nextRunAt := NextLDAPSyncAt(time.Now(), c.SyncHour)
+	c.NextRunAt = &nextRunAt
+
+	c.UpdatedBy = updatedBy
+	c.UpdatedAt = time.Now()
+}
+
+// ToResponse converts an LDAP sync configuration to a response
+func (c *LDAPSyncConfig) ToResponse() LDAPSyncResponse {
+	response := LDAPSyncResponse{
+		LDAPSyncConfig:  *c,
+		HasBindPassword: c.BindPassword != "",
+		Running:         c.RunningSince != nil,
+	}
+	if response.TeamMappings == nil {
+		response.TeamMappings = []LDAPTeamMapping{}
+	}
+	if response.RoleMappings == nil {
+		response.RoleMappings = []LDAPRoleMapping{}
+	}
+	return response
+}
+
+// SearchAttributes lists the directory attributes a sync reads
+func (c *LDAPSyncConfig) SearchAttributes() []string {
+	attributes := []string{c.Attributes.Email, c.Attributes.FirstName, c.Attributes.LastName}
+	if c.Attributes.JobTitle != "" {
+		attributes = append(attributes, c.Attributes.JobTitle)
+	}
+	for _, mapping := range c.RoleMappings {
+		attributes = append(attributes, mapping.Attribute)
+	}
+	return attributes
+}
+
+// Role gets the organization role of a directory user from the role
+// mappings, or an empty role if none matches
+func (c *LDAPSyncConfig) Role(values func(attribute string) []string) OrganizationMemberRole {
+	for _, mapping := range c.RoleMappings {
+		for _, value := range values(mapping.Attribute) {
+			if strings.EqualFold(value, mapping.Value) {
+				return mapping.Role
+			}
+		}
+	}
+	return ""
+}
+
+// TeamIDs gets the mapped teams of a directory entry, from the OUs its DN
+// falls under
+func (c *LDAPSyncConfig) TeamIDs(dn string) []string {
+	var teamIDs []string
+	for _, mapping := range c.TeamMappings {
+		if DNUnder(dn, mapping.OU) && !containsTeamID(teamIDs, mapping.TeamID) {
+			teamIDs = append(teamIDs, mapping.TeamID)
+		}
+	}
+	return teamIDs
+}
+
+// containsTeamID checks if a team ID is listed
+func containsTeamID(teamIDs []string, teamID string) bool {
+	for _, id := range teamIDs {
+		if id == teamID {
+			return true
+		}
+	}
+	return false
+}
+
+// DNUnder checks if a DN is a parent DN or falls under it. DNs compare
+// case-insensitively, ignoring spaces around their separators.
+func DNUnder(dn, parent string) bool {
+	dn, parent = normalizeDN(dn), normalizeDN(parent)
+	return parent != "" && (dn == parent || strings.HasSuffix(dn, ","+parent))
+}
+
+// normalizeDN lowercases a DN and trims the spaces around its RDNs and their
+// equals signs
+func normalizeDN(dn string) string {
+	rdns := strings.Split(dn, ",")
+	for i, rdn := range rdns {
+		if eq := strings.IndexByte(rdn, '='); eq >= 0 {
+			rdn = strings.TrimSpace(rdn[:eq]) + "=" + strings.TrimSpace(rdn[eq+1:])
+		}
+		rdns[i] = strings.ToLower(strings.TrimSpace(rdn))
+	}
+	return strings.Join(rdns, ",")
+}
+
+// NextLDAPSyncAt gets the next time after now at hour, in UTC
+func NextLDAPSyncAt(now time.Time, hour int) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// NewLDAPSyncRun creates the report of an LDAP sync that is starting
+func NewLDAPSyncRun(orgID string, trigger LDAPSyncTrigger, triggeredBy string, dryRun bool) *LDAPSyncRun {
+	return &LDAPSyncRun{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		Trigger:        trigger,
+		TriggeredBy:    triggeredBy,
+		DryRun:         dryRun,
+		Status:         LDAPSyncRunning,
+		Changes:        []LDAPSyncChange{},
+		StartedAt:      time.Now(),
+	}
+}
+
+// Record adds a change to the report and counts it. Changes past
+// MaxLDAPSyncChanges are counted without being listed.
+func (r *LDAPSyncRun) Record(change LDAPSyncChange) {
+	if change.Error != "" {
+		r.Summary.Failed++
+	} else {
+		switch change.Action {
+		case LDAPSyncUserCreated:
+			r.Summary.UsersCreated++
+		case LDAPSyncUserUpdated:
+			r.Summary.UsersUpdated++
+		case LDAPSyncMemberAdded:
+			r.Summary.MembersAdded++
+		case LDAPSyncMemberRoleChanged:
+			r.Summary.RolesChanged++
+		case LDAPSyncMemberRemoved:
+			r.Summary.MembersRemoved++
+		case LDAPSyncTeamMemberAdded:
+			r.Summary.TeamMembersAdded++
+		case LDAPSyncTeamMemberRemoved:
+			r.Summary.TeamMembersRemoved++
+		case LDAPSyncEntrySkipped:
+			r.Summary.Skipped++
+		}
+	}
+
+	if len(r.Changes) >= MaxLDAPSyncChanges {
+		r.ChangesTruncated = true
+		return
+	}
+	r.Changes = append(r.Changes, change)
+}
+
+// Finish completes the report, failed if err isn't nil
+func (r *LDAPSyncRun) Finish(err error) {
+	now := time.Now()
+	r.FinishedAt = &now
+	r.Status = LDAPSyncSucceeded
+	if err != nil {
+		r.Status = LDAPSyncFailed
+		r.Error = err.Error()
+	}
+}
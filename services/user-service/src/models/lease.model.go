@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Lease represents a time-limited lock held by one service instance
+type Lease struct {
+	Name      string    `bson:"_id" json:"name"`
+	Holder    string    `bson:"holder" json:"holder"`
+	RenewedAt time.Time `bson:"renewedAt" json:"renewedAt"`
+	ExpiresAt time.Time `bson:"expiresAt" json:"expiresAt"`
+}
+
+// IsExpired checks if the lease can be taken over by another instance
+func (l *Lease) IsExpired() bool {
+	return !time.Now().Before(l.ExpiresAt)
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Locale is a language the platform is available in
+type Locale struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	NativeName string `json:"nativeName"`
+}
+
+// SupportedLocales are the languages users can pick, by BCP 47 code
+var SupportedLocales = []Locale{
+	{Code: "en", Name: "English", NativeName: "English"},
+	{Code: "en-GB", Name: "English (United Kingdom)", NativeName: "English (United Kingdom)"},
+	{Code: "de", Name: "German", NativeName: "Deutsch"},
+	{Code: "es", Name: "Spanish", NativeName: "Español"},
+	{Code: "fr", Name: "French", NativeName: "Français"},
+	{Code: "it", Name: "Italian", NativeName: "Italiano"},
+	{Code: "nl", Name: "Dutch", NativeName: "Nederlands"},
+	{Code: "pl", Name: "Polish", NativeName: "Polski"},
+	{Code: "pt-BR", Name: "Portuguese (Brazil)", NativeName: "Português (Brasil)"},
+	{Code: "ja", Name: "Japanese", NativeName: "日本語"},
+	{Code: "ko", Name: "Korean", NativeName: "한국어"},
+	{Code: "zh-CN", Name: "Chinese (Simplified)", NativeName: "简体中文"},
+}
+
+// Themes are the interface themes users can pick; system follows the
+// device's setting
+var Themes = []string{"light", "dark", "system"}
+
+// ValidLocale checks if a language code is a supported locale
+func ValidLocale(code string) bool {
+	for _, locale := range SupportedLocales {
+		if locale.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Timezone is an IANA timezone with its current offset from UTC
+type Timezone struct {
+	ID string `json:"id"`
+	// Offset is the current offset from UTC, such as +05:30, which changes
+	// with daylight saving time
+	Offset string `json:"offset"`
+}
+
+// Timezones lists the timezones offered to users, by ID, with their offsets
+// at a time. Timezones the runtime's database doesn't have are left out.
+func Timezones(at time.Time) []Timezone {
+	timezones := make([]Timezone, 0, len(timezoneNames))
+	for _, name := range timezoneNames {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			continue
+		}
+		_, offset := at.In(loc).Zone()
+		timezones = append(timezones, Timezone{ID: name, Offset: formatUTCOffset(offset)})
+	}
+	return timezones
+}
+
+// formatUTCOffset formats an offset from UTC in seconds as ±hh:mm
+func formatUTCOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, offset/3600, offset%3600/60)
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// MemberExpiryActor is recorded as who removed the members whose membership
+// expired
+const MemberExpiryActor = "member-expiry"
+
+// MaxMembershipDuration is the longest a membership with an expiry may last
+// from when the expiry is set
+const MaxMembershipDuration = 365 * 24 * time.Hour
+
+// IsExpired checks if the membership of the member has ended. Owners can't
+// have an expiry, so their membership never ends.
+func (m OrganizationMember) IsExpired(now time.Time) bool {
+	return m.Role != OrgRoleOwner && m.ExpiresAt != nil && !m.ExpiresAt.After(now)
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Migration records a schema migration that has been applied
+type Migration struct {
+	ID         string    `bson:"_id" json:"id"`
+	Version    int       `bson:"version" json:"version"`
+	Name       string    `bson:"name" json:"name"`
+	AppliedAt  time.Time `bson:"appliedAt" json:"appliedAt"`
+	DurationMs int64     `bson:"durationMs" json:"durationMs"`
+}
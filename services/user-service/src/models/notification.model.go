@@ -0,0 +1,134 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationFrequency represents how often a user is sent notifications
+type NotificationFrequency string
+
+// Notification frequencies
+const (
+	NotificationImmediate   NotificationFrequency = "immediate"
+	NotificationDailyDigest NotificationFrequency = "daily_digest"
+)
+
+// NotificationChannel represents a channel notifications are delivered on
+type NotificationChannel string
+
+// Notification channels
+const (
+	NotificationChannelEmail NotificationChannel = "email"
+	NotificationChannelPush  NotificationChannel = "push"
+	NotificationChannelInApp NotificationChannel = "in_app"
+)
+
+// NotificationType represents what a notification is about
+type NotificationType string
+
+// Notification types
+const (
+	NotificationOrganizationMemberAdded    NotificationType = "organization_member_added"
+	NotificationOrganizationMemberInvited  NotificationType = "organization_member_invited"
+	NotificationOrganizationRoleChanged    NotificationType = "organization_role_changed"
+	NotificationOrganizationMemberRemoved  NotificationType = "organization_member_removed"
+	NotificationTeamMemberAdded            NotificationType = "team_member_added"
+	NotificationTeamRoleChanged            NotificationType = "team_role_changed"
+	NotificationTeamMemberRemoved          NotificationType = "team_member_removed"
+	NotificationOwnershipTransferRequested NotificationType = "ownership_transfer_requested"
+	NotificationJoinRequestRejected        NotificationType = "join_request_rejected"
+)
+
+// notificationEmailTemplates maps notifications about an organization to the
+// email template they are rendered with
+var notificationEmailTemplates = map[NotificationType]EmailTemplateKey{
+	NotificationOrganizationMemberAdded:    EmailTemplateMemberAdded,
+	NotificationOrganizationMemberInvited:  EmailTemplateInvitation,
+	NotificationOrganizationRoleChanged:    EmailTemplateRoleChanged,
+	NotificationOrganizationMemberRemoved:  EmailTemplateMemberRemoved,
+	NotificationOwnershipTransferRequested: EmailTemplateOwnershipTransfer,
+	NotificationJoinRequestRejected:        EmailTemplateJoinRequestRejected,
+}
+
+// MaxDigestNotifications is the most notifications sent in one digest
+const MaxDigestNotifications = 100
+
+// Notification is a notification for a user about an event. Notifications of
+// users who get a daily digest are held until their digest is due.
+type Notification struct {
+	ID               string           `bson:"_id" json:"id"`
+	UserID           string           `bson:"userId" json:"userId"`
+	Type             NotificationType `bson:"type" json:"type"`
+	ActorID          string           `bson:"actorId,omitempty" json:"actorId,omitempty"`
+	OrganizationID   string           `bson:"organizationId,omitempty" json:"organizationId,omitempty"`
+	OrganizationName string           `bson:"organizationName,omitempty" json:"organizationName,omitempty"`
+	TeamID           string           `bson:"teamId,omitempty" json:"teamId,omitempty"`
+	TeamName         string           `bson:"teamName,omitempty" json:"teamName,omitempty"`
+	Role             string           `bson:"role,omitempty" json:"role,omitempty"`
+	Reason           string           `bson:"reason,omitempty" json:"reason,omitempty"`
+	EventID          string           `bson:"eventId" json:"-"`
+	OccurredAt       time.Time        `bson:"occurredAt" json:"occurredAt"`
+	DueAt            time.Time        `bson:"dueAt" json:"-"`
+}
+
+// NewNotification creates a new notification for a user
+func NewNotification(userID string, notificationType NotificationType, eventID string, occurredAt time.Time) *Notification {
+	return &Notification{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Type:       notificationType,
+		EventID:    eventID,
+		OccurredAt: occurredAt,
+	}
+}
+
+// EmailTemplate gets the email template a notification is rendered with.
+// Only notifications about an organization have one, so team notifications
+// are left to the notification service's own wording.
+func (n *Notification) EmailTemplate() (EmailTemplateKey, bool) {
+	if n.OrganizationID == "" || n.TeamID != "" {
+		return "", false
+	}
+	key, ok := notificationEmailTemplates[n.Type]
+	return key, ok
+}
+
+// NotificationChannels gets the channels a user gets notifications on
+func (p *UserPreferences) NotificationChannels() []NotificationChannel {
+	var channels []NotificationChannel
+	if p.NotificationSettings.Email {
+		channels = append(channels, NotificationChannelEmail)
+	}
+	if p.NotificationSettings.Push {
+		channels = append(channels, NotificationChannelPush)
+	}
+	if p.NotificationSettings.InApp {
+		channels = append(channels, NotificationChannelInApp)
+	}
+	return channels
+}
+
+// WantsDigest checks if a user gets a daily digest instead of immediate
+// notifications. Users without a frequency get them immediately.
+func (p *UserPreferences) WantsDigest() bool {
+	return p.NotificationSettings.Frequency == NotificationDailyDigest
+}
+
+// NextDigestAt gets when the next daily digest after a time is due: the next
+// time it is the digest hour in the timezone. Unknown timezones fall back to
+// UTC.
+func NextDigestAt(after time.Time, timezone string, hour int) time.Time {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := after.In(loc)
+	due := time.Date(local.Year(), local.Month(), local.Day(), hour, 0, 0, 0, loc)
+	if !due.After(local) {
+		due = due.AddDate(0, 0, 1)
+	}
+	return due.UTC()
+}
@@ -0,0 +1,141 @@
+package models
+
+import "time"
+
+// OnboardingStep is a step of the onboarding checklist
+type OnboardingStep string
+
+// Onboarding steps
+const (
+	OnboardingProfileCompleted   OnboardingStep = "profile_completed"
+	OnboardingJoinedOrganization OnboardingStep = "joined_organization"
+	OnboardingCreatedTeam        OnboardingStep = "created_team"
+)
+
+// OnboardingSteps are the steps of the onboarding checklist, in the order
+// clients show them
+var OnboardingSteps = []OnboardingStep{
+	OnboardingProfileCompleted,
+	OnboardingJoinedOrganization,
+	OnboardingCreatedTeam,
+}
+
+// OnboardingStatus is where a user is in onboarding
+type OnboardingStatus string
+
+// Onboarding statuses. A user starts not_started, is in_progress once a step
+// is done and completed once every step is; dismissing the checklist hides it
+// until it is completed or brought back.
+const (
+	OnboardingNotStarted OnboardingStatus = "not_started"
+	OnboardingInProgress OnboardingStatus = "in_progress"
+	OnboardingCompleted  OnboardingStatus = "completed"
+	OnboardingDismissed  OnboardingStatus = "dismissed"
+)
+
+// Onboarding is a user's progress through the onboarding checklist
+type Onboarding struct {
+	Steps       []OnboardingStepState `bson:"steps,omitempty" json:"steps,omitempty"`
+	DismissedAt *time.Time            `bson:"dismissedAt,omitempty" json:"dismissedAt,omitempty"`
+	CompletedAt *time.Time            `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+}
+
+// OnboardingStepState records a done onboarding step. Skipped steps were
+// marked done by the user rather than done.
+type OnboardingStepState struct {
+	Step        OnboardingStep `bson:"step" json:"step"`
+	Skipped     bool           `bson:"skipped,omitempty" json:"skipped,omitempty"`
+	CompletedAt time.Time      `bson:"completedAt" json:"completedAt"`
+}
+
+// UpdateOnboardingRequest represents a request to update the current user's
+// onboarding: skip steps, or dismiss or bring back the checklist
+type UpdateOnboardingRequest struct {
+	Dismissed    *bool            `json:"dismissed,omitempty"`
+	SkippedSteps []OnboardingStep `json:"skippedSteps,omitempty" validate:"omitempty,max=3,dive,oneof=profile_completed joined_organization created_team"`
+}
+
+// OnboardingResponse represents a user's onboarding checklist
+type OnboardingResponse struct {
+	Status      OnboardingStatus         `json:"status"`
+	Steps       []OnboardingStepResponse `json:"steps"`
+	DismissedAt *time.Time               `json:"dismissedAt,omitempty"`
+	CompletedAt *time.Time               `json:"completedAt,omitempty"`
+}
+
+// OnboardingStepResponse represents a step of the onboarding checklist
+type OnboardingStepResponse struct {
+	Step        OnboardingStep `json:"step"`
+	Done        bool           `json:"done"`
+	Skipped     bool           `json:"skipped,omitempty"`
+	CompletedAt *time.Time     `json:"completedAt,omitempty"`
+}
+
+// Step gets the state of a step, or nil if it isn't done
+func (o *Onboarding) Step(step OnboardingStep) *OnboardingStepState {
+	if o == nil {
+		return nil
+	}
+	for i := range o.Steps {
+		if o.Steps[i].Step == step {
+			return &o.Steps[i]
+		}
+	}
+	return nil
+}
+
+// AllStepsDone checks if every step of the checklist is done or skipped
+func (o *Onboarding) AllStepsDone() bool {
+	for _, step := range OnboardingSteps {
+		if o.Step(step) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Status gets where the user is in onboarding
+func (o *Onboarding) Status() OnboardingStatus {
+	switch {
+	case o == nil:
+		return OnboardingNotStarted
+	case o.CompletedAt != nil:
+		return OnboardingCompleted
+	case o.DismissedAt != nil:
+		return OnboardingDismissed
+	case len(o.Steps) == 0:
+		return OnboardingNotStarted
+	default:
+		return OnboardingInProgress
+	}
+}
+
+// ToResponse converts onboarding progress to a checklist response, listing
+// every step
+func (o *Onboarding) ToResponse() OnboardingResponse {
+	response := OnboardingResponse{
+		Status: o.Status(),
+		Steps:  make([]OnboardingStepResponse, len(OnboardingSteps)),
+	}
+	if o != nil {
+		response.DismissedAt = o.DismissedAt
+		response.CompletedAt = o.CompletedAt
+	}
+
+	for i, step := range OnboardingSteps {
+		response.Steps[i] = OnboardingStepResponse{Step: step}
+		if state := o.Step(step); state != nil {
+			completedAt := state.CompletedAt
+			response.Steps[i].Done = true
+			response.Steps[i].Skipped = state.Skipped
+			response.Steps[i].CompletedAt = &completedAt
+		}
+	}
+	return response
+}
+
+// ProfileComplete checks if a user filled in the profile the onboarding
+// checklist asks for: a picture and a job title
+func (u *User) ProfileComplete() bool {
+	return u.ProfilePicture != "" && u.JobTitle != ""
+}
@@ -1,9 +1,8 @@
 package models
 
 import (
+	"strings"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 // OrganizationMemberRole represents an organization member role
@@ -14,6 +13,21 @@ const (
 	OrgRoleOwner  OrganizationMemberRole = "owner"
 	OrgRoleAdmin  OrganizationMemberRole = "admin"
 	OrgRoleMember OrganizationMemberRole = "member"
+	// OrgRoleGuest members, such as consultants, can only view the
+	// organization, and their membership ends when it expires
+	OrgRoleGuest OrganizationMemberRole = "guest"
+)
+
+// MemberStorage represents where the members of an organization are stored
+type MemberStorage string
+
+// Member storage layouts
+const (
+	// MemberStorageEmbedded keeps members in the organization document
+	MemberStorageEmbedded MemberStorage = "embedded"
+	// MemberStorageCollection keeps members in the organization_members
+	// collection, for organizations too large to embed them
+	MemberStorageCollection MemberStorage = "collection"
 )
 
 // Organization represents an organization in the system
@@ -31,7 +45,35 @@ type Organization struct {
 	UpdatedAt   time.Time            `bson:"updatedAt" json:"updatedAt"`
 	Members     []OrganizationMember `bson:"members" json:"members"`
 	TeamIDs     []string             `bson:"teamIds,omitempty" json:"teamIds,omitempty"`
+	Tags        []string             `bson:"tags,omitempty" json:"tags,omitempty"`
 	Settings    OrganizationSettings `bson:"settings" json:"settings"`
+
+	// MemberStorage is empty for organizations created before the members
+	// collection existed, which embed their members
+	MemberStorage MemberStorage `bson:"memberStorage,omitempty" json:"memberStorage,omitempty"`
+
+	PendingTransfer *OwnershipTransfer `bson:"pendingTransfer,omitempty" json:"pendingTransfer,omitempty"`
+
+	// Subscription is set once the billing service reports one
+	Subscription *Subscription `bson:"subscription,omitempty" json:"subscription,omitempty"`
+
+	// Residency is the data residency region the organization, its teams
+	// and its member records are stored in. It is set at creation and
+	// empty for organizations stored in the main database.
+	Residency string `bson:"residency,omitempty" json:"residency,omitempty"`
+
+	// Search holds the normalized keys directory searches match the
+	// organization's name, description and industry against
+	Search SearchKeys `bson:"search,omitempty" json:"-"`
+
+	// Verified organizations show a verified badge, granted by a platform
+	// admin reviewing a verification request
+	Verified   bool       `bson:"verified,omitempty" json:"verified"`
+	VerifiedAt *time.Time `bson:"verifiedAt,omitempty" json:"verifiedAt,omitempty"`
+
+	// GroupGrants maps members to the permissions their groups grant them.
+	// It is loaded by GetByID, which serves permission checks.
+	GroupGrants map[string][]Permission `bson:"-" json:"-"`
 }
 
 // OrganizationMember represents a member of an organization
@@ -40,6 +82,20 @@ type OrganizationMember struct {
 	Role      OrganizationMemberRole `bson:"role" json:"role"`
 	JoinedAt  time.Time              `bson:"joinedAt" json:"joinedAt"`
 	InvitedBy string                 `bson:"invitedBy,omitempty" json:"invitedBy,omitempty"`
+	// Licensed members take one of the subscription's presenter seats
+	Licensed bool `bson:"licensed,omitempty" json:"licensed,omitempty"`
+	// ExpiresAt is when the membership ends; expired members are removed
+	// from the organization. Guests always have one, owners never do.
+	ExpiresAt *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	// ReviewedAt is when an access review last confirmed the member
+	ReviewedAt *time.Time `bson:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	ReviewedBy string     `bson:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+	// ExternalID is the member's ID at the identity provider that
+	// provisioned them through SCIM
+	ExternalID string `bson:"externalId,omitempty" json:"externalId,omitempty"`
+	// Suspended members were deactivated by the identity provider; they
+	// stay in the organization without any of its permissions
+	Suspended bool `bson:"suspended,omitempty" json:"suspended,omitempty"`
 }
 
 // OrganizationSettings represents settings for an organization
@@ -49,6 +105,7 @@ type OrganizationSettings struct {
 		AllowPublicEvents  bool `bson:"allowPublicEvents" json:"allowPublicEvents"`
 		AllowExternalUsers bool `bson:"allowExternalUsers" json:"allowExternalUsers"`
 		EnableTeams        bool `bson:"enableTeams" json:"enableTeams"`
+		Discoverable       bool `bson:"discoverable" json:"discoverable"`
 	} `bson:"features" json:"features"`
 	Branding struct {
 		PrimaryColor   string `bson:"primaryColor,omitempty" json:"primaryColor,omitempty"`
@@ -56,6 +113,21 @@ type OrganizationSettings struct {
 		LogoURL        string `bson:"logoUrl,omitempty" json:"logoUrl,omitempty"`
 		FaviconURL     string `bson:"faviconUrl,omitempty" json:"faviconUrl,omitempty"`
 	} `bson:"branding" json:"branding"`
+	ApprovalWebhook *ApprovalWebhookSettings `bson:"approvalWebhook,omitempty" json:"approvalWebhook,omitempty"`
+	CustomFields    []CustomFieldDefinition  `bson:"customFields,omitempty" json:"customFields,omitempty"`
+
+	// DefaultPreferences are the preferences of members that haven't set
+	// them themselves
+	DefaultPreferences *OrganizationDefaultPreferences `bson:"defaultPreferences,omitempty" json:"defaultPreferences,omitempty"`
+
+	// AllowedEmailDomains are the organization's own email domains; users of
+	// other domains are external. BlockedEmailDomains can never be added.
+	AllowedEmailDomains []string `bson:"allowedEmailDomains,omitempty" json:"allowedEmailDomains,omitempty"`
+	BlockedEmailDomains []string `bson:"blockedEmailDomains,omitempty" json:"blockedEmailDomains,omitempty"`
+
+	// RequireTwoFactor keeps users without two-factor authentication from
+	// joining the organization or being given a higher role in it
+	RequireTwoFactor bool `bson:"requireTwoFactor,omitempty" json:"requireTwoFactor"`
 }
 
 // CreateOrganizationRequest represents a request to create a new organization
@@ -67,6 +139,7 @@ type CreateOrganizationRequest struct {
 	Industry    string `json:"industry" validate:"max=100"`
 	Size        string `json:"size" validate:"omitempty,oneof=1-10 11-50 51-200 201-500 501-1000 1001+"`
 	Location    string `json:"location" validate:"max=100"`
+	Residency   string `json:"residency" validate:"max=50"`
 }
 
 // UpdateOrganizationRequest represents a request to update an organization
@@ -81,6 +154,17 @@ type UpdateOrganizationRequest struct {
 	Settings    *UpdateOrganizationSettings `json:"settings,omitempty"`
 }
 
+// OrganizationClearableFields are the fields of an organization that a merge
+// patch can clear
+var OrganizationClearableFields = []string{
+	"description", "logoUrl", "website", "industry", "size", "location",
+	"settings.branding.primaryColor", "settings.branding.secondaryColor",
+	"settings.branding.logoUrl", "settings.branding.faviconUrl",
+	"settings.allowedEmailDomains", "settings.blockedEmailDomains",
+	"settings.defaultPreferences.language", "settings.defaultPreferences.timezone",
+	"settings.defaultPreferences.notificationSettings",
+}
+
 // UpdateOrganizationSettings represents a request to update organization settings
 type UpdateOrganizationSettings struct {
 	DefaultUserRole *OrganizationMemberRole `json:"defaultUserRole,omitempty" validate:"omitempty,oneof=owner admin member"`
@@ -88,6 +172,7 @@ type UpdateOrganizationSettings struct {
 		AllowPublicEvents  *bool `json:"allowPublicEvents,omitempty"`
 		AllowExternalUsers *bool `json:"allowExternalUsers,omitempty"`
 		EnableTeams        *bool `json:"enableTeams,omitempty"`
+		Discoverable       *bool `json:"discoverable,omitempty"`
 	} `json:"features,omitempty"`
 	Branding *struct {
 		PrimaryColor   *string `json:"primaryColor,omitempty" validate:"omitempty,hexcolor"`
@@ -95,17 +180,34 @@ type UpdateOrganizationSettings struct {
 		LogoURL        *string `json:"logoUrl,omitempty" validate:"omitempty,url"`
 		FaviconURL     *string `json:"faviconUrl,omitempty" validate:"omitempty,url"`
 	} `json:"branding,omitempty"`
+	DefaultPreferences *UpdateDefaultPreferences `json:"defaultPreferences,omitempty"`
+
+	// Email domain lists replace the current ones; empty lists clear them
+	AllowedEmailDomains *[]string `json:"allowedEmailDomains,omitempty" validate:"omitempty,max=100,dive,fqdn"`
+	BlockedEmailDomains *[]string `json:"blockedEmailDomains,omitempty" validate:"omitempty,max=100,dive,fqdn"`
+
+	RequireTwoFactor *bool `json:"requireTwoFactor,omitempty"`
 }
 
 // AddOrganizationMemberRequest represents a request to add a member to an organization
 type AddOrganizationMemberRequest struct {
 	UserID string                 `json:"userId" validate:"required"`
-	Role   OrganizationMemberRole `json:"role" validate:"required,oneof=owner admin member"`
+	Role   OrganizationMemberRole `json:"role" validate:"required,oneof=owner admin member guest"`
+	// Licensed assigns the new member a presenter seat
+	Licensed bool `json:"licensed,omitempty"`
+	// ExpiresAt is when the membership ends. Guests need one, owners can't
+	// have one.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
 }
 
 // UpdateOrganizationMemberRequest represents a request to update an organization member
 type UpdateOrganizationMemberRequest struct {
-	Role OrganizationMemberRole `json:"role" validate:"required,oneof=owner admin member"`
+	Role OrganizationMemberRole `json:"role" validate:"required,oneof=owner admin member guest"`
+	// ExpiresAt is when the membership ends. Members keep their expiry
+	// unless it is set or removed, except that members made owners and guests
+	// given another role lose it. Members made guests need one.
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	RemoveExpiry bool       `json:"removeExpiry,omitempty" validate:"excluded_with=ExpiresAt"`
 }
 
 // OrganizationResponse represents an organization response
@@ -122,26 +224,120 @@ type OrganizationResponse struct {
 	CreatedAt   time.Time                  `json:"createdAt"`
 	MemberCount int                        `json:"memberCount"`
 	TeamCount   int                        `json:"teamCount"`
+	Tags        []string                   `json:"tags,omitempty"`
 	Members     []OrganizationMemberDetail `json:"members,omitempty"`
 	Settings    OrganizationSettings       `json:"settings,omitempty"`
+	Verified    bool                       `json:"verified"`
+	VerifiedAt  *time.Time                 `json:"verifiedAt,omitempty"`
+	Residency   string                     `json:"residency,omitempty"`
+}
+
+// OrganizationResponseFields maps the fields of an organization response to
+// the stored fields they are read from
+var OrganizationResponseFields = map[string][]string{
+	"id":          {"_id"},
+	"name":        {"name"},
+	"description": {"description"},
+	"logoUrl":     {"logoUrl"},
+	"website":     {"website"},
+	"industry":    {"industry"},
+	"size":        {"size"},
+	"location":    {"location"},
+	"createdBy":   {"createdBy"},
+	"createdAt":   {"createdAt"},
+	"memberCount": {"members", "memberStorage"},
+	"teamCount":   {"teamIds"},
+	"tags":        {"tags"},
+	"members":     {"members", "memberStorage"},
+	"settings":    {"settings"},
+	"verified":    {"verified"},
+	"verifiedAt":  {"verifiedAt"},
+	"residency":   {"residency"},
 }
 
+// OrganizationExpansions are the fields of an organization response it only
+// has when included
+var OrganizationExpansions = []string{"members", "settings"}
+
 // OrganizationMemberDetail represents detailed information about an organization member
 type OrganizationMemberDetail struct {
-	UserID    string                 `json:"userId"`
-	Email     string                 `json:"email"`
-	FirstName string                 `json:"firstName"`
-	LastName  string                 `json:"lastName"`
-	FullName  string                 `json:"fullName"`
-	Role      OrganizationMemberRole `json:"role"`
-	JoinedAt  time.Time              `json:"joinedAt"`
+	UserID         string                 `bson:"userId" json:"userId"`
+	Email          string                 `bson:"email" json:"email"`
+	FirstName      string                 `bson:"firstName" json:"firstName"`
+	LastName       string                 `bson:"lastName" json:"lastName"`
+	FullName       string                 `bson:"-" json:"fullName"`
+	ProfilePicture string                 `bson:"profilePicture,omitempty" json:"profilePicture,omitempty"`
+	Status         UserStatus             `bson:"status,omitempty" json:"status,omitempty"`
+	Role           OrganizationMemberRole `bson:"role" json:"role"`
+	JoinedAt       time.Time              `bson:"joinedAt" json:"joinedAt"`
+	InvitedBy      string                 `bson:"invitedBy,omitempty" json:"invitedBy,omitempty"`
+	Licensed       bool                   `bson:"licensed,omitempty" json:"licensed,omitempty"`
+	ExpiresAt      *time.Time             `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	ReviewedAt     *time.Time             `bson:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	ReviewedBy     string                 `bson:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+	CustomFields   map[string]interface{} `bson:"customFields,omitempty" json:"customFields,omitempty"`
+	Presence       PresenceStatus         `bson:"-" json:"presence,omitempty"`
+	LastSeenAt     *time.Time             `bson:"lastSeenAt,omitempty" json:"lastSeenAt,omitempty"`
+}
+
+// NewOrganizationMemberDetail creates the details of a member of an
+// organization from the member's user, which is nil for users that no longer
+// exist
+func NewOrganizationMemberDetail(orgID string, member OrganizationMember, user *User) OrganizationMemberDetail {
+	detail := OrganizationMemberDetail{
+		UserID:     member.UserID,
+		Role:       member.Role,
+		JoinedAt:   member.JoinedAt,
+		InvitedBy:  member.InvitedBy,
+		Licensed:   member.Licensed,
+		ExpiresAt:  member.ExpiresAt,
+		ReviewedAt: member.ReviewedAt,
+		ReviewedBy: member.ReviewedBy,
+	}
+	if user != nil {
+		detail.Email = user.Email
+		detail.FirstName = user.FirstName
+		detail.LastName = user.LastName
+		detail.ProfilePicture = user.ProfilePicture
+		detail.Status = user.Status
+		detail.CustomFields = user.CustomFields[orgID]
+		detail.LastSeenAt = user.LastSeenAt
+	}
+	detail.SetFullName()
+	return detail
 }
 
-// NewOrganization creates a new organization from a request
+// SetFullName sets the full name from the first and last names
+func (d *OrganizationMemberDetail) SetFullName() {
+	d.FullName = strings.TrimSpace(d.FirstName + " " + d.LastName)
+}
+
+// OrganizationMemberFilter represents the filters of an organization member
+// listing
+type OrganizationMemberFilter struct {
+	Role OrganizationMemberRole
+	// Search matches the name or email of the member's user
+	Search string
+}
+
+// OrganizationMembersResponse represents a page of organization members
+type OrganizationMembersResponse struct {
+	OrganizationID   string                     `json:"organizationId"`
+	OrganizationName string                     `json:"organizationName"`
+	MemberCount      int                        `json:"memberCount"`
+	Members          []OrganizationMemberDetail `json:"members"`
+	Total            int64                      `json:"total"`
+	Page             int                        `json:"page"`
+	Limit            int                        `json:"limit"`
+	TotalPages       int64                      `json:"totalPages"`
+}
+
+// NewOrganization creates a new organization from a request. Its ID is left
+// to the store, which assigns the ObjectID the repositories look
+// organizations up by.
 func NewOrganization(req CreateOrganizationRequest, createdBy string) *Organization {
 	now := time.Now()
 	return &Organization{
-		ID:          uuid.New().String(),
 		Name:        req.Name,
 		Description: req.Description,
 		LogoURL:     req.LogoURL,
@@ -149,6 +345,7 @@ func NewOrganization(req CreateOrganizationRequest, createdBy string) *Organizat
 		Industry:    req.Industry,
 		Size:        req.Size,
 		Location:    req.Location,
+		Residency:   req.Residency,
 		CreatedBy:   createdBy,
 		CreatedAt:   now,
 		UpdatedAt:   now,
@@ -165,6 +362,7 @@ func NewOrganization(req CreateOrganizationRequest, createdBy string) *Organizat
 				AllowPublicEvents  bool `bson:"allowPublicEvents" json:"allowPublicEvents"`
 				AllowExternalUsers bool `bson:"allowExternalUsers" json:"allowExternalUsers"`
 				EnableTeams        bool `bson:"enableTeams" json:"enableTeams"`
+				Discoverable       bool `bson:"discoverable" json:"discoverable"`
 			}{
 				AllowPublicEvents:  true,
 				AllowExternalUsers: false,
@@ -198,6 +396,10 @@ func (o *Organization) ToResponse(includeMembers bool, includeSettings bool) Org
 		CreatedAt:   o.CreatedAt,
 		MemberCount: len(o.Members),
 		TeamCount:   len(o.TeamIDs),
+		Tags:        o.Tags,
+		Verified:    o.Verified,
+		VerifiedAt:  o.VerifiedAt,
+		Residency:   o.Residency,
 	}
 
 	if includeMembers {
@@ -220,6 +422,11 @@ func (o *Organization) ToResponse(includeMembers bool, includeSettings bool) Org
 	return response
 }
 
+// ETag gets the entity tag of the organization's current version
+func (o *Organization) ETag() string {
+	return entityTag(o.ID, o.UpdatedAt)
+}
+
 // Apply applies an update request to an organization
 func (o *Organization) Apply(req UpdateOrganizationRequest) {
 	o.UpdatedAt = time.Now()
@@ -263,6 +470,9 @@ func (o *Organization) Apply(req UpdateOrganizationRequest) {
 			if req.Settings.Features.EnableTeams != nil {
 				o.Settings.Features.EnableTeams = *req.Settings.Features.EnableTeams
 			}
+			if req.Settings.Features.Discoverable != nil {
+				o.Settings.Features.Discoverable = *req.Settings.Features.Discoverable
+			}
 		}
 
 		// Update branding
@@ -280,6 +490,24 @@ func (o *Organization) Apply(req UpdateOrganizationRequest) {
 				o.Settings.Branding.FaviconURL = *req.Settings.Branding.FaviconURL
 			}
 		}
+
+		// Update default preferences
+		if req.Settings.DefaultPreferences != nil {
+			o.Settings.DefaultPreferences = applyDefaultPreferences(o.Settings.DefaultPreferences, *req.Settings.DefaultPreferences)
+		}
+
+		// Update email domains
+		if req.Settings.AllowedEmailDomains != nil {
+			o.Settings.AllowedEmailDomains = NormalizeEmailDomains(*req.Settings.AllowedEmailDomains)
+		}
+		if req.Settings.BlockedEmailDomains != nil {
+			o.Settings.BlockedEmailDomains = NormalizeEmailDomains(*req.Settings.BlockedEmailDomains)
+		}
+
+		// Update two-factor policy
+		if req.Settings.RequireTwoFactor != nil {
+			o.Settings.RequireTwoFactor = *req.Settings.RequireTwoFactor
+		}
 	}
 }
 
@@ -348,6 +576,32 @@ func (o *Organization) GetMember(userID string) *OrganizationMember {
 	return nil
 }
 
+// MemberIDs gets the user IDs of the organization's members
+func (o *Organization) MemberIDs() []string {
+	ids := make([]string, 0, len(o.Members))
+	for _, member := range o.Members {
+		ids = append(ids, member.UserID)
+	}
+	return ids
+}
+
+// LicensedMembers counts the organization's members that take a seat
+func (o *Organization) LicensedMembers() int {
+	count := 0
+	for _, member := range o.Members {
+		if member.Licensed {
+			count++
+		}
+	}
+	return count
+}
+
+// HasMemberCollection checks if the organization's members are stored in the
+// organization_members collection
+func (o *Organization) HasMemberCollection() bool {
+	return o.MemberStorage == MemberStorageCollection
+}
+
 // IsMember checks if a user is a member of the organization
 func (o *Organization) IsMember(userID string) bool {
 	return o.GetMember(userID) != nil
@@ -0,0 +1,246 @@
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExportFormat is the format of the data files of an organization export
+type ExportFormat string
+
+// Export formats
+const (
+	ExportJSON ExportFormat = "json"
+	ExportCSV  ExportFormat = "csv"
+)
+
+// ExportStatus is the state of an organization export
+type ExportStatus string
+
+// Export statuses
+const (
+	ExportRunning   ExportStatus = "running"
+	ExportCompleted ExportStatus = "completed"
+	ExportFailed    ExportStatus = "failed"
+	// ExportExpired is a completed export whose archive was removed
+	ExportExpired ExportStatus = "expired"
+)
+
+// Files of an organization export archive. The organization's metadata is
+// always JSON; the other files are in the export's format.
+const (
+	ExportOrganizationFile = "organization.json"
+	ExportTeamsFile        = "teams"
+	// ExportTeamMembersFile lists team members in CSV exports; JSON exports
+	// list them with their team
+	ExportTeamMembersFile = "team_members"
+	ExportMembersFile     = "members"
+	ExportAuditLogFile    = "audit_log"
+)
+
+// OrganizationExport is a dump of an organization's data: its metadata,
+// teams, members and audit log, archived as a zip file. Exports run in the
+// background, and their archive can be downloaded until it expires.
+type OrganizationExport struct {
+	ID             string       `bson:"_id" json:"id"`
+	OrganizationID string       `bson:"organizationId" json:"organizationId"`
+	Format         ExportFormat `bson:"format" json:"format"`
+	Status         ExportStatus `bson:"status" json:"status"`
+	Error          string       `bson:"error,omitempty" json:"error,omitempty"`
+	// Size is the size of the archive, in bytes
+	Size        int64      `bson:"size,omitempty" json:"size,omitempty"`
+	RequestedBy string     `bson:"requestedBy" json:"requestedBy"`
+	CreatedAt   time.Time  `bson:"createdAt" json:"createdAt"`
+	CompletedAt *time.Time `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+	// ExpiresAt is when the archive of a completed export is removed
+	ExpiresAt *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+}
+
+// OrganizationExportFile is the archive of a completed organization export.
+// Like a GridFS file, the archive is stored in chunks, so its size isn't
+// bound by the size of a document; the file is recorded once every chunk
+// is written.
+type OrganizationExportFile struct {
+	ExportID       string    `bson:"_id"`
+	OrganizationID string    `bson:"organizationId"`
+	Length         int64     `bson:"length"`
+	Chunks         int       `bson:"chunks"`
+	ExpiresAt      time.Time `bson:"expiresAt"`
+}
+
+// OrganizationExportChunk is a chunk of the archive of an export, the nth
+// from the start
+type OrganizationExportChunk struct {
+	ID             string    `bson:"_id"`
+	ExportID       string    `bson:"exportId"`
+	OrganizationID string    `bson:"organizationId"`
+	N              int       `bson:"n"`
+	Data           []byte    `bson:"data"`
+	ExpiresAt      time.Time `bson:"expiresAt"`
+}
+
+// CreateOrganizationExportRequest represents a request to export an
+// organization's data
+type CreateOrganizationExportRequest struct {
+	Format ExportFormat `json:"format" validate:"omitempty,oneof=json csv"`
+}
+
+// OrganizationExportResponse represents an organization export, with a
+// signed URL its archive can be downloaded from while it's completed
+type OrganizationExportResponse struct {
+	*OrganizationExport
+	DownloadURL          string     `json:"downloadUrl,omitempty"`
+	DownloadURLExpiresAt *time.Time `json:"downloadUrlExpiresAt,omitempty"`
+}
+
+// NewOrganizationExport creates an organization export that is starting
+func NewOrganizationExport(orgID string, format ExportFormat, requestedBy string) *OrganizationExport {
+	if format == "" {
+		format = ExportJSON
+	}
+	return &OrganizationExport{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		Format:         format,
+		Status:         ExportRunning,
+		RequestedBy:    requestedBy,
+		CreatedAt:      time.Now(),
+	}
+}
+
+// Finish completes the export with an archive of size bytes kept for
+// retention, failed if err isn't nil
+func (e *OrganizationExport) Finish(size int64, retention time.Duration, err error) {
+	now := time.Now()
+	e.CompletedAt = &now
+	if err != nil {
+		e.Status = ExportFailed
+		e.Error = err.Error()
+		return
+	}
+
+	expiresAt := now.Add(retention)
+	e.Status = ExportCompleted
+	e.Size = size
+	e.ExpiresAt = &expiresAt
+}
+
+// Expired checks if the archive of a completed export was removed
+func (e *OrganizationExport) Expired(now time.Time) bool {
+	return e.Status == ExportExpired || (e.ExpiresAt != nil && !now.Before(*e.ExpiresAt))
+}
+
+// Downloadable checks if the archive of an export can be downloaded
+func (e *OrganizationExport) Downloadable(now time.Time) bool {
+	return e.Status == ExportCompleted && !e.Expired(now)
+}
+
+// FileName gets the name of an export's data file in its format
+func (e *OrganizationExport) FileName(name string) string {
+	return name + "." + string(e.Format)
+}
+
+// ArchiveName gets the name an export's archive is downloaded as
+func (e *OrganizationExport) ArchiveName() string {
+	return "organization-" + e.OrganizationID + "-export-" + e.CreatedAt.UTC().Format("20060102T150405Z") + ".zip"
+}
+
+// ExportTeamsCSV gets teams as CSV records, one per team
+func ExportTeamsCSV(teams []*Team) [][]string {
+	records := [][]string{{"id", "name", "description", "parentTeamId", "externalId", "tags", "memberCount", "createdBy", "createdAt", "archivedAt"}}
+	for _, team := range teams {
+		records = append(records, []string{
+			team.ID,
+			team.Name,
+			team.Description,
+			team.ParentTeamID,
+			team.ExternalID,
+			strings.Join(team.Tags, ";"),
+			strconv.Itoa(len(team.Members)),
+			team.CreatedBy,
+			team.CreatedAt.Format(time.RFC3339),
+			formatExportTime(team.ArchivedAt),
+		})
+	}
+	return records
+}
+
+// ExportTeamMembersCSV gets the members of teams as CSV records, one per
+// team membership
+func ExportTeamMembersCSV(teams []*Team) [][]string {
+	records := [][]string{{"teamId", "userId", "role", "joinedAt", "invitedBy"}}
+	for _, team := range teams {
+		for _, member := range team.Members {
+			records = append(records, []string{
+				team.ID,
+				member.UserID,
+				string(member.Role),
+				member.JoinedAt.Format(time.RFC3339),
+				member.InvitedBy,
+			})
+		}
+	}
+	return records
+}
+
+// ExportMembersCSV gets organization members as CSV records, one per member
+func ExportMembersCSV(members []OrganizationMemberDetail) [][]string {
+	records := [][]string{{"userId", "email", "firstName", "lastName", "status", "role", "joinedAt", "invitedBy", "licensed", "expiresAt", "customFields"}}
+	for _, member := range members {
+		records = append(records, []string{
+			member.UserID,
+			member.Email,
+			member.FirstName,
+			member.LastName,
+			string(member.Status),
+			string(member.Role),
+			member.JoinedAt.Format(time.RFC3339),
+			member.InvitedBy,
+			strconv.FormatBool(member.Licensed),
+			formatExportTime(member.ExpiresAt),
+			formatExportJSON(member.CustomFields),
+		})
+	}
+	return records
+}
+
+// ExportAuditLogCSV gets timeline entries as CSV records, one per entry
+func ExportAuditLogCSV(entries []*TimelineEntry) [][]string {
+	records := [][]string{{"id", "type", "eventType", "actorId", "subject", "occurredAt", "data"}}
+	for _, entry := range entries {
+		records = append(records, []string{
+			entry.ID,
+			string(entry.Type),
+			entry.EventType,
+			entry.ActorID,
+			entry.Subject,
+			entry.OccurredAt.Format(time.RFC3339),
+			formatExportJSON(entry.Data),
+		})
+	}
+	return records
+}
+
+// formatExportTime formats an optional time for a CSV record
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// formatExportJSON formats a map for a CSV record as JSON
+func formatExportJSON(values map[string]interface{}) string {
+	if len(values) == 0 {
+		return ""
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
@@ -0,0 +1,251 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationHistorySnapshotInterval is how many versions apart the history
+// entries holding the whole state of an organization are, so a state is
+// rebuilt from a bounded number of entries
+const OrganizationHistorySnapshotInterval = 50
+
+// organizationMembersField prefixes the state fields of members, which are
+// named members.<userId>.<field>
+const organizationMembersField = "members."
+
+// OrganizationHistoryEntry is a transition of an organization's state,
+// appended each time a published event changes it. Versions number the
+// entries of an organization from 1, in the order they were recorded.
+type OrganizationHistoryEntry struct {
+	ID             string                      `bson:"_id" json:"id"`
+	OrganizationID string                      `bson:"organizationId" json:"organizationId"`
+	Version        int                         `bson:"version" json:"version"`
+	EventID        string                      `bson:"eventId" json:"eventId"`
+	EventType      string                      `bson:"eventType" json:"eventType"`
+	ActorID        string                      `bson:"actorId,omitempty" json:"actorId,omitempty"`
+	Changes        []OrganizationHistoryChange `bson:"changes" json:"changes"`
+	// Snapshot is the whole state after the transition, kept every
+	// OrganizationHistorySnapshotInterval versions
+	Snapshot   []OrganizationStateField `bson:"snapshot,omitempty" json:"-"`
+	OccurredAt time.Time                `bson:"occurredAt" json:"occurredAt"`
+}
+
+// OrganizationHistoryChange is a field of an organization's state that
+// changed, by its dotted path, such as settings.features.enableTeams or
+// members.<userId>.role. Values are JSON; a missing value is a field that
+// was added or removed.
+type OrganizationHistoryChange struct {
+	Field string          `bson:"field" json:"field"`
+	From  json.RawMessage `bson:"from,omitempty" json:"from,omitempty"`
+	To    json.RawMessage `bson:"to,omitempty" json:"to,omitempty"`
+}
+
+// OrganizationStateField is a field of a stored organization state
+type OrganizationStateField struct {
+	Field string          `bson:"field"`
+	Value json.RawMessage `bson:"value"`
+}
+
+// OrganizationState is the state of an organization, by the dotted path of
+// its fields
+type OrganizationState map[string]json.RawMessage
+
+// organizationStateFields are the fields of an organization its history
+// follows
+type organizationStateFields struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	LogoURL     string               `json:"logoUrl,omitempty"`
+	Website     string               `json:"website,omitempty"`
+	Industry    string               `json:"industry,omitempty"`
+	Size        string               `json:"size,omitempty"`
+	Location    string               `json:"location,omitempty"`
+	Residency   string               `json:"residency,omitempty"`
+	Verified    bool                 `json:"verified"`
+	Tags        []string             `json:"tags,omitempty"`
+	Settings    OrganizationSettings `json:"settings"`
+}
+
+// organizationMemberState is the state of a member its history follows
+type organizationMemberState struct {
+	Role      OrganizationMemberRole `json:"role"`
+	Licensed  bool                   `json:"licensed,omitempty"`
+	ExpiresAt *time.Time             `json:"expiresAt,omitempty"`
+}
+
+// NewOrganizationState captures the state of an organization: its profile,
+// tags, settings and members. Settings hidden from JSON, such as the
+// approval webhook secret, aren't part of it.
+func NewOrganizationState(org *Organization) OrganizationState {
+	state := make(OrganizationState)
+	for field, value := range flattenJSON(organizationStateFields{
+		Name:        org.Name,
+		Description: org.Description,
+		LogoURL:     org.LogoURL,
+		Website:     org.Website,
+		Industry:    org.Industry,
+		Size:        org.Size,
+		Location:    org.Location,
+		Residency:   org.Residency,
+		Verified:    org.Verified,
+		Tags:        org.Tags,
+		Settings:    org.Settings,
+	}) {
+		state.set(field, value)
+	}
+
+	for _, member := range org.Members {
+		prefix := organizationMembersField + member.UserID + "."
+		for field, value := range flattenJSON(organizationMemberState{
+			Role:      member.Role,
+			Licensed:  member.Licensed,
+			ExpiresAt: member.ExpiresAt,
+		}) {
+			state.set(prefix+field, value)
+		}
+	}
+	return state
+}
+
+// set stores a field of the state as JSON
+func (s OrganizationState) set(field string, value interface{}) {
+	if data, err := json.Marshal(value); err == nil {
+		s[field] = data
+	}
+}
+
+// Apply applies the changes of a history entry to the state, or replaces it
+// with the entry's snapshot
+func (s OrganizationState) Apply(entry *OrganizationHistoryEntry) OrganizationState {
+	if len(entry.Snapshot) > 0 {
+		state := make(OrganizationState, len(entry.Snapshot))
+		for _, field := range entry.Snapshot {
+			state[field.Field] = field.Value
+		}
+		return state
+	}
+
+	if s == nil {
+		s = make(OrganizationState)
+	}
+	for _, change := range entry.Changes {
+		if len(change.To) == 0 {
+			delete(s, change.Field)
+		} else {
+			s[change.Field] = change.To
+		}
+	}
+	return s
+}
+
+// Snapshot lists the fields of the state, in field order, to be stored
+func (s OrganizationState) Snapshot() []OrganizationStateField {
+	fields := make([]OrganizationStateField, 0, len(s))
+	for _, field := range s.fields() {
+		fields = append(fields, OrganizationStateField{Field: field, Value: s[field]})
+	}
+	return fields
+}
+
+// Diff lists the fields that differ between the state and after, in field
+// order
+func (s OrganizationState) Diff(after OrganizationState) []OrganizationHistoryChange {
+	fields := s.fields()
+	for field := range after {
+		if _, ok := s[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	var changes []OrganizationHistoryChange
+	for _, field := range fields {
+		if !bytes.Equal(s[field], after[field]) {
+			changes = append(changes, OrganizationHistoryChange{Field: field, From: s[field], To: after[field]})
+		}
+	}
+	return changes
+}
+
+// Nest nests the fields of the state by their path, with members keyed by
+// user ID, for display
+func (s OrganizationState) Nest() map[string]interface{} {
+	nested := make(map[string]interface{})
+	for field, value := range s {
+		var path []string
+		if rest, ok := strings.CutPrefix(field, organizationMembersField); ok {
+			// User IDs may contain dots, member fields don't
+			cut := strings.LastIndex(rest, ".")
+			if cut < 0 {
+				continue
+			}
+			path = []string{"members", rest[:cut], rest[cut+1:]}
+		} else {
+			path = strings.Split(field, ".")
+		}
+
+		parent := nested
+		for _, key := range path[:len(path)-1] {
+			child, ok := parent[key].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				parent[key] = child
+			}
+			parent = child
+		}
+		parent[path[len(path)-1]] = value
+	}
+	return nested
+}
+
+// fields lists the fields of the state in order
+func (s OrganizationState) fields() []string {
+	fields := make([]string, 0, len(s))
+	for field := range s {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// NewOrganizationHistoryEntry creates a new history entry of an organization
+func NewOrganizationHistoryEntry(orgID string, version int, eventID, eventType, actorID string, changes []OrganizationHistoryChange, occurredAt time.Time) *OrganizationHistoryEntry {
+	if changes == nil {
+		changes = []OrganizationHistoryChange{}
+	}
+	return &OrganizationHistoryEntry{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		Version:        version,
+		EventID:        eventID,
+		EventType:      eventType,
+		ActorID:        actorID,
+		Changes:        changes,
+		OccurredAt:     occurredAt,
+	}
+}
+
+// OrganizationHistoryResponse represents a page of an organization's
+// history, newest first
+type OrganizationHistoryResponse struct {
+	Entries []*OrganizationHistoryEntry `json:"entries"`
+	// NextBefore is the version to list the next page before
+	NextBefore int `json:"nextBefore,omitempty"`
+	Limit      int `json:"limit"`
+}
+
+// OrganizationStateResponse represents the state of an organization at a
+// time, as of the latest history entry recorded by then
+type OrganizationStateResponse struct {
+	OrganizationID string                 `json:"organizationId"`
+	At             time.Time              `json:"at"`
+	Version        int                    `json:"version"`
+	RecordedAt     time.Time              `json:"recordedAt"`
+	State          map[string]interface{} `json:"state"`
+}
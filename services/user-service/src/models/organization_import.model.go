@@ -0,0 +1,247 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OrganizationImportItem is the kind of data an import entry is about
+type OrganizationImportItem string
+
+// Organization import items
+const (
+	OrganizationImportOrganization OrganizationImportItem = "organization"
+	OrganizationImportMember       OrganizationImportItem = "member"
+	OrganizationImportTeam         OrganizationImportItem = "team"
+	OrganizationImportTeamMember   OrganizationImportItem = "team_member"
+)
+
+// OrganizationImportAction is what an import does with an item of an
+// export archive
+type OrganizationImportAction string
+
+// Organization import actions
+const (
+	// OrganizationImportCreate creates the organization or a team
+	OrganizationImportCreate OrganizationImportAction = "create"
+	// OrganizationImportAdd adds a member of the organization to a team
+	OrganizationImportAdd OrganizationImportAction = "add"
+	// OrganizationImportInvite creates a pending user for a member without an
+	// account, like a directory import, and adds them to the organization
+	OrganizationImportInvite OrganizationImportAction = "invite"
+	// OrganizationImportRequest sends an existing user an invitation to join
+	// the organization, which they accept themselves
+	OrganizationImportRequest OrganizationImportAction = "request"
+	// OrganizationImportSkip leaves out an item that conflicts with the
+	// existing data or can't be restored
+	OrganizationImportSkip OrganizationImportAction = "skip"
+)
+
+// OrganizationImportEntry is what an import does, or would do, with an item
+// of an export archive
+type OrganizationImportEntry struct {
+	Item   OrganizationImportItem   `json:"item"`
+	Action OrganizationImportAction `json:"action"`
+	// SourceID is the item's ID in the exported organization
+	SourceID string `json:"sourceId,omitempty"`
+	// ID is the ID of the created organization or team, or of the member's user
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	// Team is the name of a team member's team
+	Team string `json:"team,omitempty"`
+	Role string `json:"role,omitempty"`
+	// Reason says why an item is skipped, or what was changed to restore it
+	Reason string `json:"reason,omitempty"`
+	// Error is the code of the error that failed an applied change
+	Error string `json:"error,omitempty"`
+}
+
+// OrganizationImportSummary counts the entries of an import by action
+type OrganizationImportSummary struct {
+	Total   int `json:"total"`
+	Create  int `json:"create"`
+	Add     int `json:"add"`
+	Invite  int `json:"invite"`
+	Request int `json:"request"`
+	Skip    int `json:"skip"`
+	Failed  int `json:"failed"`
+}
+
+// OrganizationImportResponse represents the preview or outcome of an import
+// of an organization from an export archive
+type OrganizationImportResponse struct {
+	// OrganizationID is the ID of the created organization, once applied
+	OrganizationID string                    `json:"organizationId,omitempty"`
+	Name           string                    `json:"name"`
+	Format         ExportFormat              `json:"format"`
+	Applied        bool                      `json:"applied"`
+	Summary        OrganizationImportSummary `json:"summary"`
+	Entries        []OrganizationImportEntry `json:"entries"`
+}
+
+// Add adds an entry to the response and counts it
+func (r *OrganizationImportResponse) Add(entry OrganizationImportEntry) {
+	r.Summary.Total++
+	switch {
+	case entry.Error != "":
+		r.Summary.Failed++
+	case entry.Action == OrganizationImportCreate:
+		r.Summary.Create++
+	case entry.Action == OrganizationImportAdd:
+		r.Summary.Add++
+	case entry.Action == OrganizationImportInvite:
+		r.Summary.Invite++
+	case entry.Action == OrganizationImportRequest:
+		r.Summary.Request++
+	case entry.Action == OrganizationImportSkip:
+		r.Summary.Skip++
+	}
+	r.Entries = append(r.Entries, entry)
+}
+
+// OrganizationArchive is the data of an organization export archive
+type OrganizationArchive struct {
+	Format       ExportFormat
+	Organization OrganizationResponse
+	Teams        []*Team
+	Members      []OrganizationMemberDetail
+}
+
+// ParseTeamsCSV reads teams from the CSV records of an export. Their members
+// are read from the team members file.
+func ParseTeamsCSV(records [][]string) ([]*Team, error) {
+	rows, err := exportRows(records, "id", "name")
+	if err != nil {
+		return nil, err
+	}
+
+	teams := make([]*Team, 0, len(rows))
+	for _, row := range rows {
+		team := &Team{
+			ID:           row["id"],
+			Name:         row["name"],
+			Description:  row["description"],
+			ParentTeamID: row["parentTeamId"],
+			ExternalID:   row["externalId"],
+			CreatedBy:    row["createdBy"],
+			Members:      []TeamMember{},
+		}
+		if tags := row["tags"]; tags != "" {
+			team.Tags = strings.Split(tags, ";")
+		}
+		if team.ArchivedAt, err = parseExportTime(row["archivedAt"]); err != nil {
+			return nil, fmt.Errorf("team %s: archivedAt: %w", team.ID, err)
+		}
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
+
+// ParseTeamMembersCSV reads the members of teams from the CSV records of an
+// export and adds them to their team
+func ParseTeamMembersCSV(records [][]string, teams []*Team) error {
+	rows, err := exportRows(records, "teamId", "userId")
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]*Team, len(teams))
+	for _, team := range teams {
+		byID[team.ID] = team
+	}
+	for _, row := range rows {
+		team := byID[row["teamId"]]
+		if team == nil {
+			return fmt.Errorf("member %s of unknown team %s", row["userId"], row["teamId"])
+		}
+		team.Members = append(team.Members, TeamMember{
+			UserID:    row["userId"],
+			Role:      TeamMemberRole(row["role"]),
+			InvitedBy: row["invitedBy"],
+		})
+	}
+	return nil
+}
+
+// ParseMembersCSV reads organization members from the CSV records of an export
+func ParseMembersCSV(records [][]string) ([]OrganizationMemberDetail, error) {
+	rows, err := exportRows(records, "userId", "email", "role")
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]OrganizationMemberDetail, 0, len(rows))
+	for _, row := range rows {
+		member := OrganizationMemberDetail{
+			UserID:    row["userId"],
+			Email:     row["email"],
+			FirstName: row["firstName"],
+			LastName:  row["lastName"],
+			Status:    UserStatus(row["status"]),
+			Role:      OrganizationMemberRole(row["role"]),
+			InvitedBy: row["invitedBy"],
+		}
+		if licensed := row["licensed"]; licensed != "" {
+			if member.Licensed, err = strconv.ParseBool(licensed); err != nil {
+				return nil, fmt.Errorf("member %s: licensed: %w", member.UserID, err)
+			}
+		}
+		if member.ExpiresAt, err = parseExportTime(row["expiresAt"]); err != nil {
+			return nil, fmt.Errorf("member %s: expiresAt: %w", member.UserID, err)
+		}
+		if fields := row["customFields"]; fields != "" {
+			if err := json.Unmarshal([]byte(fields), &member.CustomFields); err != nil {
+				return nil, fmt.Errorf("member %s: customFields: %w", member.UserID, err)
+			}
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// exportRows maps the CSV records of an export, after the header, to their
+// header's columns. The header must have the required columns.
+func exportRows(records [][]string, required ...string) ([]map[string]string, error) {
+	if len(records) == 0 {
+		return nil, errors.New("missing header")
+	}
+	header := records[0]
+	columns := make(map[string]bool, len(header))
+	for _, column := range header {
+		columns[column] = true
+	}
+	for _, column := range required {
+		if !columns[column] {
+			return nil, fmt.Errorf("missing column %s", column)
+		}
+	}
+
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[column] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseExportTime parses an optional time of a CSV record
+func parseExportTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
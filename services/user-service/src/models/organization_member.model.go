@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// OrganizationMemberRecord is a member of an organization stored in the
+// organization_members collection
+type OrganizationMemberRecord struct {
+	ID             string                 `bson:"_id" json:"id"`
+	OrganizationID string                 `bson:"organizationId" json:"organizationId"`
+	UserID         string                 `bson:"userId" json:"userId"`
+	Role           OrganizationMemberRole `bson:"role" json:"role"`
+	JoinedAt       time.Time              `bson:"joinedAt" json:"joinedAt"`
+	InvitedBy      string                 `bson:"invitedBy,omitempty" json:"invitedBy,omitempty"`
+	Licensed       bool                   `bson:"licensed,omitempty" json:"licensed,omitempty"`
+	ExpiresAt      *time.Time             `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	ReviewedAt     *time.Time             `bson:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	ReviewedBy     string                 `bson:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+	ExternalID     string                 `bson:"externalId,omitempty" json:"externalId,omitempty"`
+	Suspended      bool                   `bson:"suspended,omitempty" json:"suspended,omitempty"`
+}
+
+// UpdateMemberStorageRequest represents a request to move the members of an
+// organization to another storage layout
+type UpdateMemberStorageRequest struct {
+	Storage MemberStorage `json:"storage" validate:"required,oneof=embedded collection"`
+}
+
+// OrganizationMemberRecordID returns the ID of a user's member record. One
+// record per organization and user keeps concurrent adds from duplicating it.
+func OrganizationMemberRecordID(orgID, userID string) string {
+	return orgID + ":" + userID
+}
+
+// NewOrganizationMemberRecord creates a member record from an embedded member
+func NewOrganizationMemberRecord(orgID string, member OrganizationMember) *OrganizationMemberRecord {
+	return &OrganizationMemberRecord{
+		ID:             OrganizationMemberRecordID(orgID, member.UserID),
+		OrganizationID: orgID,
+		UserID:         member.UserID,
+		Role:           member.Role,
+		JoinedAt:       member.JoinedAt,
+		InvitedBy:      member.InvitedBy,
+		Licensed:       member.Licensed,
+		ExpiresAt:      member.ExpiresAt,
+		ReviewedAt:     member.ReviewedAt,
+		ReviewedBy:     member.ReviewedBy,
+		ExternalID:     member.ExternalID,
+		Suspended:      member.Suspended,
+	}
+}
+
+// ToMember converts a member record to an embedded member
+func (r *OrganizationMemberRecord) ToMember() OrganizationMember {
+	return OrganizationMember{
+		UserID:     r.UserID,
+		Role:       r.Role,
+		JoinedAt:   r.JoinedAt,
+		InvitedBy:  r.InvitedBy,
+		Licensed:   r.Licensed,
+		ExpiresAt:  r.ExpiresAt,
+		ReviewedAt: r.ReviewedAt,
+		ReviewedBy: r.ReviewedBy,
+		ExternalID: r.ExternalID,
+		Suspended:  r.Suspended,
+	}
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// VerificationMethod is how an organization proves who it is
+type VerificationMethod string
+
+// Verification methods
+const (
+	// VerificationDomain proves control of a domain with a DNS TXT record
+	VerificationDomain VerificationMethod = "domain"
+	// VerificationDocument submits a document, such as a business
+	// registration, for a platform admin to check
+	VerificationDocument VerificationMethod = "document"
+)
+
+// VerificationStatus represents the state of a verification request
+type VerificationStatus string
+
+// Verification request statuses
+const (
+	VerificationPending   VerificationStatus = "pending"
+	VerificationApproved  VerificationStatus = "approved"
+	VerificationRejected  VerificationStatus = "rejected"
+	VerificationCancelled VerificationStatus = "cancelled"
+	// VerificationRevoked is an approved request whose badge was taken back
+	VerificationRevoked VerificationStatus = "revoked"
+)
+
+// VerificationTXTPrefix starts the DNS TXT record value that proves control
+// of a domain
+const VerificationTXTPrefix = "slido-verification="
+
+// OrganizationVerification is an organization's request for a verified
+// badge. Requests are reviewed by platform admins; domain requests can only
+// be approved once their TXT record was found.
+type OrganizationVerification struct {
+	ID             string             `bson:"_id" json:"id"`
+	OrganizationID string             `bson:"organizationId" json:"organizationId"`
+	Method         VerificationMethod `bson:"method" json:"method"`
+	// Domain and TXTRecord are set for domain requests. The TXT record must
+	// be published on the domain.
+	Domain    string `bson:"domain,omitempty" json:"domain,omitempty"`
+	TXTRecord string `bson:"txtRecord,omitempty" json:"txtRecord,omitempty"`
+	// DomainVerifiedAt is when the TXT record was last found
+	DomainVerifiedAt *time.Time `bson:"domainVerifiedAt,omitempty" json:"domainVerifiedAt,omitempty"`
+	// DocumentURL is set for document requests
+	DocumentURL  string             `bson:"documentUrl,omitempty" json:"documentUrl,omitempty"`
+	Notes        string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	Status       VerificationStatus `bson:"status" json:"status"`
+	RequestedBy  string             `bson:"requestedBy" json:"requestedBy"`
+	ReviewedBy   string             `bson:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+	ReviewReason string             `bson:"reviewReason,omitempty" json:"reviewReason,omitempty"`
+	ReviewedAt   *time.Time         `bson:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// CreateVerificationRequest represents a request for a verified badge
+type CreateVerificationRequest struct {
+	Method      VerificationMethod `json:"method" validate:"required,oneof=domain document"`
+	Domain      string             `json:"domain" validate:"required_if=Method domain,omitempty,fqdn,max=253"`
+	DocumentURL string             `json:"documentUrl" validate:"required_if=Method document,omitempty,url,max=2048"`
+	Notes       string             `json:"notes" validate:"max=1000"`
+}
+
+// ReviewVerificationRequest represents a platform admin's decision on a
+// verification request
+type ReviewVerificationRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// NewOrganizationVerification creates a pending verification request. txtRecord
+// is only used for domain requests.
+func NewOrganizationVerification(id, orgID string, req CreateVerificationRequest, txtRecord, requestedBy string) *OrganizationVerification {
+	now := time.Now()
+	verification := &OrganizationVerification{
+		ID:             id,
+		OrganizationID: orgID,
+		Method:         req.Method,
+		Notes:          req.Notes,
+		Status:         VerificationPending,
+		RequestedBy:    requestedBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	switch req.Method {
+	case VerificationDomain:
+		verification.Domain = strings.ToLower(strings.TrimSuffix(req.Domain, "."))
+		verification.TXTRecord = txtRecord
+	case VerificationDocument:
+		verification.DocumentURL = req.DocumentURL
+	}
+
+	return verification
+}
+
+// IsPending checks if the verification request is awaiting review
+func (v *OrganizationVerification) IsPending() bool {
+	return v.Status == VerificationPending
+}
+
+// CoversEmail checks if an approved domain verification proves the
+// organization controls the domain of an email address, or a parent domain
+// of it
+func (v *OrganizationVerification) CoversEmail(email string) bool {
+	if v.Status != VerificationApproved || v.Method != VerificationDomain || v.Domain == "" {
+		return false
+	}
+	return matchesEmailDomain(EmailDomain(email), []string{v.Domain})
+}
+
+// Reviewable checks if a platform admin can approve the request. Domain
+// requests need their TXT record found first.
+func (v *OrganizationVerification) Reviewable() bool {
+	return v.IsPending() && (v.Method != VerificationDomain || v.DomainVerifiedAt != nil)
+}
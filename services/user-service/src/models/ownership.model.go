@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// OwnershipTransferTTL is how long a pending ownership transfer can be accepted
+const OwnershipTransferTTL = 7 * 24 * time.Hour
+
+// PreviousOwnerRemove is the previous owner action that removes the previous owner
+const PreviousOwnerRemove = "remove"
+
+// OwnershipTransfer represents a pending ownership transfer awaiting acceptance
+type OwnershipTransfer struct {
+	FromUserID        string    `bson:"fromUserId" json:"fromUserId"`
+	ToUserID          string    `bson:"toUserId" json:"toUserId"`
+	PreviousOwnerRole string    `bson:"previousOwnerRole" json:"previousOwnerRole"`
+	RequestedAt       time.Time `bson:"requestedAt" json:"requestedAt"`
+	ExpiresAt         time.Time `bson:"expiresAt" json:"expiresAt"`
+}
+
+// TransferOwnershipRequest represents a request to transfer ownership to another member
+type TransferOwnershipRequest struct {
+	NewOwnerID        string `json:"newOwnerId" validate:"required"`
+	PreviousOwnerRole string `json:"previousOwnerRole" validate:"omitempty,oneof=admin member viewer remove"`
+}
+
+// NewOwnershipTransfer creates a new pending ownership transfer
+func NewOwnershipTransfer(fromUserID string, req TransferOwnershipRequest) *OwnershipTransfer {
+	now := time.Now()
+	previousOwnerRole := req.PreviousOwnerRole
+	if previousOwnerRole == "" {
+		previousOwnerRole = "admin"
+	}
+
+	return &OwnershipTransfer{
+		FromUserID:        fromUserID,
+		ToUserID:          req.NewOwnerID,
+		PreviousOwnerRole: previousOwnerRole,
+		RequestedAt:       now,
+		ExpiresAt:         now.Add(OwnershipTransferTTL),
+	}
+}
+
+// IsExpired checks if the transfer can no longer be accepted
+func (t *OwnershipTransfer) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// RemovesPreviousOwner checks if the previous owner leaves once the transfer completes
+func (t *OwnershipTransfer) RemovesPreviousOwner() bool {
+	return t.PreviousOwnerRole == PreviousOwnerRemove
+}
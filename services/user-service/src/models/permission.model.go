@@ -0,0 +1,269 @@
+package models
+
+import "strings"
+
+// Permission represents an action a user may perform
+type Permission string
+
+// Platform permissions, granted by the platform role in the access token
+const (
+	PermPlatformListOrganizations   Permission = "platform:organizations:list"
+	PermPlatformReviewSignups       Permission = "platform:signups:review"
+	PermPlatformViewSLO             Permission = "platform:slo:view"
+	PermPlatformViewConfig          Permission = "platform:config:view"
+	PermPlatformReplayEvents        Permission = "platform:events:replay"
+	PermPlatformManageMemberStorage Permission = "platform:organizations:member_storage:manage"
+	PermPlatformManageBanner        Permission = "platform:banner:manage"
+	PermPlatformViewStats           Permission = "platform:stats:view"
+	PermPlatformViewPrivateProfiles Permission = "platform:users:private_profiles:view"
+	PermPlatformImpersonateUsers    Permission = "platform:users:impersonate"
+	PermPlatformRestoreUsers        Permission = "platform:users:restore"
+	PermPlatformPurgeUsers          Permission = "platform:users:purge"
+	PermPlatformManageFeatureFlags  Permission = "platform:feature_flags:manage"
+	PermPlatformVerifyOrganizations Permission = "platform:organizations:verify"
+	PermPlatformManageJobs          Permission = "platform:jobs:manage"
+)
+
+// Organization permissions, granted by the organization member role
+const (
+	PermOrgView                  Permission = "organization:view"
+	PermOrgUpdate                Permission = "organization:update"
+	PermOrgDelete                Permission = "organization:delete"
+	PermOrgManageMembers         Permission = "organization:members:manage"
+	PermOrgReviewJoinRequests    Permission = "organization:join_requests:review"
+	PermOrgTransferOwnership     Permission = "organization:ownership:transfer"
+	PermOrgCreateTeams           Permission = "organization:teams:create"
+	PermOrgManageTeams           Permission = "organization:teams:manage"
+	PermOrgViewApprovalWebhook   Permission = "organization:approval_webhook:view"
+	PermOrgManageApprovalWebhook Permission = "organization:approval_webhook:manage"
+	PermOrgManageWebhooks        Permission = "organization:webhooks:manage"
+	PermOrgManageEmailTemplates  Permission = "organization:email_templates:manage"
+	PermOrgManageSCIM            Permission = "organization:scim:manage"
+	PermOrgViewStats             Permission = "organization:stats:view"
+	PermOrgManageGroups          Permission = "organization:groups:manage"
+	PermOrgManageCustomFields    Permission = "organization:custom_fields:manage"
+	PermOrgViewActivity          Permission = "organization:activity:view"
+	PermOrgViewSubscription      Permission = "organization:subscription:view"
+	PermOrgManageSeats           Permission = "organization:seats:manage"
+	PermOrgManageLDAPSync        Permission = "organization:ldap_sync:manage"
+	PermOrgManageVerification    Permission = "organization:verification:manage"
+	PermOrgExport                Permission = "organization:export"
+	PermOrgCreateEvents          Permission = "organization:events:create"
+	PermOrgManageEvents          Permission = "organization:events:manage"
+)
+
+// Team permissions, granted by the team member role
+const (
+	PermTeamView              Permission = "team:view"
+	PermTeamUpdate            Permission = "team:update"
+	PermTeamDelete            Permission = "team:delete"
+	PermTeamManageMembers     Permission = "team:members:manage"
+	PermTeamTransferOwnership Permission = "team:ownership:transfer"
+	PermTeamManageSubteams    Permission = "team:subteams:manage"
+)
+
+// platformRolePermissions maps platform roles to their permissions
+var platformRolePermissions = map[UserRole][]Permission{
+	RoleAdmin: {
+		PermPlatformListOrganizations,
+		PermPlatformReviewSignups,
+		PermPlatformViewSLO,
+		PermPlatformViewConfig,
+		PermPlatformReplayEvents,
+		PermPlatformManageMemberStorage,
+		PermPlatformManageBanner,
+		PermPlatformViewStats,
+		PermPlatformViewPrivateProfiles,
+		PermPlatformImpersonateUsers,
+		PermPlatformRestoreUsers,
+		PermPlatformPurgeUsers,
+		PermPlatformManageFeatureFlags,
+		PermPlatformVerifyOrganizations,
+		PermPlatformManageJobs,
+	},
+}
+
+// orgRolePermissions maps organization member roles to their permissions
+var orgRolePermissions = map[OrganizationMemberRole][]Permission{
+	OrgRoleOwner: {
+		PermOrgView,
+		PermOrgUpdate,
+		PermOrgDelete,
+		PermOrgManageMembers,
+		PermOrgReviewJoinRequests,
+		PermOrgTransferOwnership,
+		PermOrgCreateTeams,
+		PermOrgManageTeams,
+		PermOrgViewApprovalWebhook,
+		PermOrgManageApprovalWebhook,
+		PermOrgManageWebhooks,
+		PermOrgManageEmailTemplates,
+		PermOrgManageSCIM,
+		PermOrgViewStats,
+		PermOrgManageGroups,
+		PermOrgManageCustomFields,
+		PermOrgViewActivity,
+		PermOrgViewSubscription,
+		PermOrgManageSeats,
+		PermOrgManageLDAPSync,
+		PermOrgManageVerification,
+		PermOrgExport,
+		PermOrgCreateEvents,
+		PermOrgManageEvents,
+	},
+	OrgRoleAdmin: {
+		PermOrgView,
+		PermOrgUpdate,
+		PermOrgManageMembers,
+		PermOrgReviewJoinRequests,
+		PermOrgCreateTeams,
+		PermOrgManageTeams,
+		PermOrgViewApprovalWebhook,
+		PermOrgManageWebhooks,
+		PermOrgManageEmailTemplates,
+		PermOrgViewStats,
+		PermOrgManageGroups,
+		PermOrgManageCustomFields,
+		PermOrgViewActivity,
+		PermOrgViewSubscription,
+		PermOrgManageSeats,
+		PermOrgCreateEvents,
+		PermOrgManageEvents,
+	},
+	OrgRoleMember: {
+		PermOrgView,
+		PermOrgCreateTeams,
+		PermOrgCreateEvents,
+	},
+	OrgRoleGuest: {
+		PermOrgView,
+	},
+}
+
+// teamRolePermissions maps team member roles to their permissions
+var teamRolePermissions = map[TeamMemberRole][]Permission{
+	TeamRoleOwner: {
+		PermTeamView,
+		PermTeamUpdate,
+		PermTeamDelete,
+		PermTeamManageMembers,
+		PermTeamTransferOwnership,
+		PermTeamManageSubteams,
+	},
+	TeamRoleAdmin: {
+		PermTeamView,
+		PermTeamUpdate,
+		PermTeamManageMembers,
+		PermTeamManageSubteams,
+	},
+	TeamRoleMember: {
+		PermTeamView,
+	},
+	TeamRoleViewer: {
+		PermTeamView,
+	},
+}
+
+// PermissionsResponse represents a user's effective permissions
+type PermissionsResponse struct {
+	Platform         []Permission           `json:"platform"`
+	OrganizationID   string                 `json:"organizationId,omitempty"`
+	OrganizationRole OrganizationMemberRole `json:"organizationRole,omitempty"`
+	Organization     []Permission           `json:"organization,omitempty"`
+	TeamID           string                 `json:"teamId,omitempty"`
+	TeamRole         TeamMemberRole         `json:"teamRole,omitempty"`
+	Team             []Permission           `json:"team,omitempty"`
+}
+
+// PlatformPermissions returns the permissions granted by platform roles
+func PlatformPermissions(roles []string) []Permission {
+	permissions := make([]Permission, 0)
+	seen := make(map[Permission]bool)
+	for _, role := range roles {
+		for _, permission := range platformRolePermissions[UserRole(strings.ToLower(role))] {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+// HasPlatformPermission checks if platform roles grant a permission
+func HasPlatformPermission(roles []string, permission Permission) bool {
+	return containsPermission(PlatformPermissions(roles), permission)
+}
+
+// IsGroupGrantable checks if groups may grant a permission. Groups can grant
+// what an organization admin has, so owner-only actions stay with owners.
+func IsGroupGrantable(permission Permission) bool {
+	return containsPermission(orgRolePermissions[OrgRoleAdmin], permission)
+}
+
+// Permissions returns the permissions of a user in the organization, from
+// their role and the groups they are in. Suspended members have none.
+func (o *Organization) Permissions(userID string) []Permission {
+	member := o.GetMember(userID)
+	if member == nil || member.Suspended {
+		return []Permission{}
+	}
+
+	permissions := append([]Permission{}, orgRolePermissions[member.Role]...)
+	for _, permission := range o.GroupGrants[userID] {
+		if !containsPermission(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
+
+// Can checks if a user has a permission in the organization
+func (o *Organization) Can(userID string, permission Permission) bool {
+	return containsPermission(o.Permissions(userID), permission)
+}
+
+// Permissions returns the permissions of a user in the team
+func (t *Team) Permissions(userID string) []Permission {
+	member := t.GetMember(userID)
+	if member == nil {
+		return []Permission{}
+	}
+	return append([]Permission{}, teamRolePermissions[member.Role]...)
+}
+
+// Can checks if a user has a permission in the team
+func (t *Team) Can(userID string, permission Permission) bool {
+	return containsPermission(t.Permissions(userID), permission)
+}
+
+// InheritedPermissions returns the permissions of a user in the team,
+// including those granted by their roles in the team's ancestors. Ownership
+// transfer is not inherited, since only members can hand over a team.
+func (t *Team) InheritedPermissions(userID string, ancestors []*Team) []Permission {
+	permissions := t.Permissions(userID)
+	for _, ancestor := range ancestors {
+		for _, permission := range ancestor.Permissions(userID) {
+			if permission != PermTeamTransferOwnership && !containsPermission(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+// CanInherited checks if a user has a permission in the team, directly or
+// through the team's ancestors
+func (t *Team) CanInherited(userID string, ancestors []*Team, permission Permission) bool {
+	return containsPermission(t.InheritedPermissions(userID, ancestors), permission)
+}
+
+// containsPermission checks if a permission is in a list
+func containsPermission(permissions []Permission, permission Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
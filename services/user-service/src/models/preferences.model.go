@@ -0,0 +1,181 @@
+package models
+
+// System default preferences, for users that haven't set a preference and
+// whose organization has no default for it
+const (
+	DefaultLanguage = "en"
+	DefaultTheme    = "light"
+	DefaultTimezone = "UTC"
+)
+
+// Preferences that organizations can set defaults for. A user's preference
+// overrides are recorded by these names.
+const (
+	PreferenceLanguage              = "language"
+	PreferenceTimezone              = "timezone"
+	PreferenceNotificationEmail     = "notificationSettings.email"
+	PreferenceNotificationPush      = "notificationSettings.push"
+	PreferenceNotificationInApp     = "notificationSettings.inApp"
+	PreferenceNotificationFrequency = "notificationSettings.frequency"
+)
+
+// OrganizationDefaultPreferences are an organization's defaults for the
+// preferences of its members. Unset defaults fall back to the system ones.
+type OrganizationDefaultPreferences struct {
+	Language             string                       `bson:"language,omitempty" json:"language,omitempty"`
+	Timezone             string                       `bson:"timezone,omitempty" json:"timezone,omitempty"`
+	NotificationSettings *DefaultNotificationSettings `bson:"notificationSettings,omitempty" json:"notificationSettings,omitempty"`
+}
+
+// DefaultNotificationSettings are an organization's defaults for the
+// notification settings of its members
+type DefaultNotificationSettings struct {
+	Email     *bool                 `bson:"email,omitempty" json:"email,omitempty"`
+	Push      *bool                 `bson:"push,omitempty" json:"push,omitempty"`
+	InApp     *bool                 `bson:"inApp,omitempty" json:"inApp,omitempty"`
+	Frequency NotificationFrequency `bson:"frequency,omitempty" json:"frequency,omitempty"`
+}
+
+// UpdateDefaultPreferences represents a request to update an organization's
+// default preferences. Empty strings clear a default, and an empty
+// notificationSettings object clears the notification defaults.
+type UpdateDefaultPreferences struct {
+	Language             *string                            `json:"language,omitempty" validate:"omitempty,locale"`
+	Timezone             *string                            `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	NotificationSettings *UpdateDefaultNotificationSettings `json:"notificationSettings,omitempty"`
+}
+
+// UpdateDefaultNotificationSettings represents a request to update an
+// organization's default notification settings
+type UpdateDefaultNotificationSettings struct {
+	Email     *bool                  `json:"email,omitempty"`
+	Push      *bool                  `json:"push,omitempty"`
+	InApp     *bool                  `json:"inApp,omitempty"`
+	Frequency *NotificationFrequency `json:"frequency,omitempty" validate:"omitempty,oneof=immediate daily_digest"`
+}
+
+// applyDefaultPreferences applies an update request to default preferences,
+// returning nil if no defaults are left
+func applyDefaultPreferences(defaults *OrganizationDefaultPreferences, req UpdateDefaultPreferences) *OrganizationDefaultPreferences {
+	updated := OrganizationDefaultPreferences{}
+	if defaults != nil {
+		updated = *defaults
+	}
+
+	if req.Language != nil {
+		updated.Language = *req.Language
+	}
+	if req.Timezone != nil {
+		updated.Timezone = *req.Timezone
+	}
+
+	if req.NotificationSettings != nil {
+		notifications := DefaultNotificationSettings{}
+		if updated.NotificationSettings != nil {
+			notifications = *updated.NotificationSettings
+		}
+		if *req.NotificationSettings == (UpdateDefaultNotificationSettings{}) {
+			notifications = DefaultNotificationSettings{}
+		}
+		if req.NotificationSettings.Email != nil {
+			notifications.Email = req.NotificationSettings.Email
+		}
+		if req.NotificationSettings.Push != nil {
+			notifications.Push = req.NotificationSettings.Push
+		}
+		if req.NotificationSettings.InApp != nil {
+			notifications.InApp = req.NotificationSettings.InApp
+		}
+		if req.NotificationSettings.Frequency != nil {
+			notifications.Frequency = *req.NotificationSettings.Frequency
+		}
+
+		updated.NotificationSettings = &notifications
+		if notifications == (DefaultNotificationSettings{}) {
+			updated.NotificationSettings = nil
+		}
+	}
+
+	if updated == (OrganizationDefaultPreferences{}) {
+		return nil
+	}
+	return &updated
+}
+
+// Overridden checks if the user set a preference themselves
+func (p *UserPreferences) Overridden(preference string) bool {
+	for _, overridden := range p.Overrides {
+		if overridden == preference {
+			return true
+		}
+	}
+	return false
+}
+
+// override records that the user set a preference themselves
+func (p *UserPreferences) override(preference string) {
+	if !p.Overridden(preference) {
+		p.Overrides = append(p.Overrides, preference)
+	}
+}
+
+// Resolve gets the effective preferences of a user: preferences they set
+// themselves, then the organization's defaults, then the system defaults.
+// defaults may be nil.
+func (p UserPreferences) Resolve(defaults *OrganizationDefaultPreferences) UserPreferences {
+	if defaults == nil {
+		return p
+	}
+
+	resolved := p
+	if defaults.Language != "" && !p.Overridden(PreferenceLanguage) {
+		resolved.Language = defaults.Language
+	}
+	if defaults.Timezone != "" && !p.Overridden(PreferenceTimezone) {
+		resolved.Timezone = defaults.Timezone
+	}
+
+	if notifications := defaults.NotificationSettings; notifications != nil {
+		if notifications.Email != nil && !p.Overridden(PreferenceNotificationEmail) {
+			resolved.NotificationSettings.Email = *notifications.Email
+		}
+		if notifications.Push != nil && !p.Overridden(PreferenceNotificationPush) {
+			resolved.NotificationSettings.Push = *notifications.Push
+		}
+		if notifications.InApp != nil && !p.Overridden(PreferenceNotificationInApp) {
+			resolved.NotificationSettings.InApp = *notifications.InApp
+		}
+		if notifications.Frequency != "" && !p.Overridden(PreferenceNotificationFrequency) {
+			resolved.NotificationSettings.Frequency = notifications.Frequency
+		}
+	}
+	return resolved
+}
+
+// InferPreferenceOverrides gets the preferences of a user that differ from
+// the system defaults, which they must have set themselves. Users created
+// before overrides were recorded have their overrides inferred.
+func InferPreferenceOverrides(p UserPreferences) []string {
+	system := NewUser(CreateUserRequest{}).Preferences
+
+	overrides := []string{}
+	if p.Language != system.Language {
+		overrides = append(overrides, PreferenceLanguage)
+	}
+	if p.Timezone != system.Timezone {
+		overrides = append(overrides, PreferenceTimezone)
+	}
+	if p.NotificationSettings.Email != system.NotificationSettings.Email {
+		overrides = append(overrides, PreferenceNotificationEmail)
+	}
+	if p.NotificationSettings.Push != system.NotificationSettings.Push {
+		overrides = append(overrides, PreferenceNotificationPush)
+	}
+	if p.NotificationSettings.InApp != system.NotificationSettings.InApp {
+		overrides = append(overrides, PreferenceNotificationInApp)
+	}
+	if p.NotificationSettings.Frequency != "" && p.NotificationSettings.Frequency != system.NotificationSettings.Frequency {
+		overrides = append(overrides, PreferenceNotificationFrequency)
+	}
+	return overrides
+}
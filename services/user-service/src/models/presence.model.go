@@ -0,0 +1,26 @@
+package models
+
+// PresenceStatus represents whether a user is online
+type PresenceStatus string
+
+// Presence statuses
+const (
+	PresenceOnline  PresenceStatus = "online"
+	PresenceOffline PresenceStatus = "offline"
+)
+
+// NewPresenceStatus gets the presence status of a user that is or isn't
+// online
+func NewPresenceStatus(online bool) PresenceStatus {
+	if online {
+		return PresenceOnline
+	}
+	return PresenceOffline
+}
+
+// HeartbeatResponse represents the response to a presence heartbeat
+type HeartbeatResponse struct {
+	Status PresenceStatus `json:"status"`
+	// TTLSeconds is how long the user stays online without another heartbeat
+	TTLSeconds int `json:"ttlSeconds"`
+}
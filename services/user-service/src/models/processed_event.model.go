@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ProcessedEvent records a Kafka event that has been handled, so a redelivered
+// copy of it can be skipped
+type ProcessedEvent struct {
+	EventID     string    `bson:"_id" json:"eventId"`
+	Topic       string    `bson:"topic" json:"topic"`
+	Type        string    `bson:"type" json:"type"`
+	ProcessedAt time.Time `bson:"processedAt" json:"processedAt"`
+	ExpiresAt   time.Time `bson:"expiresAt" json:"expiresAt"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ProfileStreamEvent is an event of a user's profile stream, telling their
+// frontend that their profile, memberships or organizations changed so it
+// doesn't have to poll for changes
+type ProfileStreamEvent struct {
+	// Type is the type of the event that caused the change, such as
+	// organization.member.updated
+	Type             string        `json:"type"`
+	User             *UserResponse `json:"user,omitempty"`
+	OrganizationID   string        `json:"organizationId,omitempty"`
+	OrganizationName string        `json:"organizationName,omitempty"`
+	TeamID           string        `json:"teamId,omitempty"`
+	TeamName         string        `json:"teamName,omitempty"`
+	Role             string        `json:"role,omitempty"`
+	OccurredAt       time.Time     `json:"occurredAt"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// RealtimeMessage is a message sent over realtime connections to the members
+// of an organization, so dashboards update live as members join, leave,
+// change roles and come online
+type RealtimeMessage struct {
+	// Type is the type of the event the message is about, such as
+	// organization.member.added or user.presence.changed
+	Type           string         `json:"type"`
+	OrganizationID string         `json:"organizationId"`
+	TeamID         string         `json:"teamId,omitempty"`
+	TeamName       string         `json:"teamName,omitempty"`
+	UserID         string         `json:"userId,omitempty"`
+	Role           string         `json:"role,omitempty"`
+	Status         PresenceStatus `json:"status,omitempty"`
+	OccurredAt     time.Time      `json:"occurredAt"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReplayEntityType represents the kind of entity an event replay re-publishes
+type ReplayEntityType string
+
+// Replay entity types
+const (
+	ReplayUsers         ReplayEntityType = "user"
+	ReplayOrganizations ReplayEntityType = "organization"
+	ReplayTeams         ReplayEntityType = "team"
+)
+
+// ReplayJobStatus represents the status of an event replay job
+type ReplayJobStatus string
+
+// Replay job statuses
+const (
+	ReplayRunning   ReplayJobStatus = "running"
+	ReplayCompleted ReplayJobStatus = "completed"
+	ReplayCancelled ReplayJobStatus = "cancelled"
+	ReplayFailed    ReplayJobStatus = "failed"
+)
+
+// ReplayEventsRequest represents a request to re-publish entity snapshots.
+// The ID range is inclusive; the time window applies to the last update.
+type ReplayEventsRequest struct {
+	EntityType ReplayEntityType `json:"entityType" validate:"required,oneof=user organization team"`
+	FromID     string           `json:"fromId,omitempty" validate:"omitempty,mongodb"`
+	ToID       string           `json:"toId,omitempty" validate:"omitempty,mongodb"`
+	Since      *time.Time       `json:"since,omitempty"`
+	Until      *time.Time       `json:"until,omitempty"`
+}
+
+// ReplayJob tracks the progress of an event replay
+type ReplayJob struct {
+	ID          string              `json:"id"`
+	Request     ReplayEventsRequest `json:"request"`
+	Status      ReplayJobStatus     `json:"status"`
+	Published   int                 `json:"published"`
+	Failed      int                 `json:"failed"`
+	LastID      string              `json:"lastId,omitempty"`
+	Error       string              `json:"error,omitempty"`
+	RequestedBy string              `json:"requestedBy"`
+	StartedAt   time.Time           `json:"startedAt"`
+	FinishedAt  *time.Time          `json:"finishedAt,omitempty"`
+}
+
+// NewReplayJob creates a new running replay job
+func NewReplayJob(req ReplayEventsRequest, requestedBy string) *ReplayJob {
+	return &ReplayJob{
+		ID:          uuid.New().String(),
+		Request:     req,
+		Status:      ReplayRunning,
+		RequestedBy: requestedBy,
+		StartedAt:   time.Now(),
+	}
+}
+
+// IsRunning checks if the replay job is still running
+func (j *ReplayJob) IsRunning() bool {
+	return j.Status == ReplayRunning
+}
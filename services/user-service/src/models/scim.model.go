@@ -0,0 +1,183 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-username/slido-clone/user-service/pkg/scim"
+)
+
+// SCIMToken represents a SCIM bearer token issued to an organization
+type SCIMToken struct {
+	ID             string     `bson:"_id,omitempty" json:"id"`
+	OrganizationID string     `bson:"organizationId" json:"organizationId"`
+	TokenHash      string     `bson:"tokenHash" json:"-"`
+	Description    string     `bson:"description,omitempty" json:"description,omitempty"`
+	CreatedBy      string     `bson:"createdBy" json:"createdBy"`
+	CreatedAt      time.Time  `bson:"createdAt" json:"createdAt"`
+	LastUsedAt     *time.Time `bson:"lastUsedAt,omitempty" json:"lastUsedAt,omitempty"`
+}
+
+// CreateSCIMTokenRequest represents a request to create a SCIM token
+type CreateSCIMTokenRequest struct {
+	Description string `json:"description" validate:"max=200"`
+}
+
+// SCIMTokenResponse represents a SCIM token response. The plain token is
+// only returned once, when the token is created.
+type SCIMTokenResponse struct {
+	ID             string     `json:"id"`
+	OrganizationID string     `json:"organizationId"`
+	Token          string     `json:"token,omitempty"`
+	Description    string     `json:"description,omitempty"`
+	CreatedBy      string     `json:"createdBy"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	LastUsedAt     *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// NewSCIMToken creates a new SCIM token record
+func NewSCIMToken(orgID, tokenHash, description, createdBy string) *SCIMToken {
+	return &SCIMToken{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		TokenHash:      tokenHash,
+		Description:    description,
+		CreatedBy:      createdBy,
+		CreatedAt:      time.Now(),
+	}
+}
+
+// ToResponse converts a SCIM token to a response
+func (t *SCIMToken) ToResponse() SCIMTokenResponse {
+	return SCIMTokenResponse{
+		ID:             t.ID,
+		OrganizationID: t.OrganizationID,
+		Description:    t.Description,
+		CreatedBy:      t.CreatedBy,
+		CreatedAt:      t.CreatedAt,
+		LastUsedAt:     t.LastUsedAt,
+	}
+}
+
+// SCIMUser represents a SCIM User resource
+type SCIMUser struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	ExternalID  string          `json:"externalId,omitempty"`
+	UserName    string          `json:"userName" validate:"required,email"`
+	Name        *SCIMName       `json:"name,omitempty"`
+	DisplayName string          `json:"displayName,omitempty"`
+	Title       string          `json:"title,omitempty"`
+	Active      *bool           `json:"active,omitempty"`
+	Emails      []SCIMMultiAttr `json:"emails,omitempty"`
+	Groups      []SCIMMultiAttr `json:"groups,omitempty"`
+	Meta        *scim.Meta      `json:"meta,omitempty"`
+}
+
+// SCIMName represents the SCIM name complex attribute
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// SCIMMultiAttr represents a SCIM multi-valued attribute entry
+type SCIMMultiAttr struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// SCIMGroup represents a SCIM Group resource
+type SCIMGroup struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	ExternalID  string          `json:"externalId,omitempty"`
+	DisplayName string          `json:"displayName" validate:"required,min=3,max=50"`
+	Members     []SCIMMultiAttr `json:"members,omitempty"`
+	Meta        *scim.Meta      `json:"meta,omitempty"`
+}
+
+// SCIMMember is a user as the SCIM client of an organization sees them.
+// Users can belong to several organizations, so whether they're active and
+// their external ID are kept on their membership of the organization.
+type SCIMMember struct {
+	*User
+	Member OrganizationMember
+}
+
+// ToSCIM converts a member to a SCIM User resource
+func (m *SCIMMember) ToSCIM(baseURL string) SCIMUser {
+	u := m.User
+	active := !m.Member.Suspended
+	resource := SCIMUser{
+		Schemas:    []string{scim.SchemaUser},
+		ID:         u.UserID,
+		ExternalID: m.Member.ExternalID,
+		UserName:   u.Email,
+		Name: &SCIMName{
+			Formatted:  u.FirstName + " " + u.LastName,
+			GivenName:  u.FirstName,
+			FamilyName: u.LastName,
+		},
+		DisplayName: u.FirstName + " " + u.LastName,
+		Title:       u.JobTitle,
+		Active:      &active,
+		Emails: []SCIMMultiAttr{
+			{
+				Value:   u.Email,
+				Type:    "work",
+				Primary: true,
+			},
+		},
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      u.CreatedAt.UTC().Format(time.RFC3339),
+			LastModified: u.UpdatedAt.UTC().Format(time.RFC3339),
+			Location:     baseURL + "/Users/" + u.UserID,
+		},
+	}
+
+	return resource
+}
+
+// PrimaryEmail returns the email to use for a SCIM user, preferring the
+// primary entry of the emails attribute and falling back to userName
+func (r *SCIMUser) PrimaryEmail() string {
+	for _, email := range r.Emails {
+		if email.Primary && email.Value != "" {
+			return email.Value
+		}
+	}
+	if len(r.Emails) > 0 && r.Emails[0].Value != "" {
+		return r.Emails[0].Value
+	}
+	return r.UserName
+}
+
+// ToSCIM converts a team to a SCIM Group resource
+func (t *Team) ToSCIM(baseURL string) SCIMGroup {
+	members := make([]SCIMMultiAttr, 0, len(t.Members))
+	for _, member := range t.Members {
+		members = append(members, SCIMMultiAttr{
+			Value: member.UserID,
+			Ref:   baseURL + "/Users/" + member.UserID,
+		})
+	}
+
+	return SCIMGroup{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          t.ID,
+		ExternalID:  t.ExternalID,
+		DisplayName: t.Name,
+		Members:     members,
+		Meta: &scim.Meta{
+			ResourceType: "Group",
+			Created:      t.CreatedAt.UTC().Format(time.RFC3339),
+			LastModified: t.UpdatedAt.UTC().Format(time.RFC3339),
+			Location:     baseURL + "/Groups/" + t.ID,
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxSearchKeyLength is the longest a search key may be; longer keys are
+// cut, so long descriptions don't make oversized index entries
+const maxSearchKeyLength = 64
+
+// SearchKeys are the normalized keys of the searchable fields of a user or
+// organization, by field. A search matches a record if each of its words
+// starts a key of one of the fields, which anchored regexes on indexes of
+// the keys find without scanning the collection.
+type SearchKeys map[string][]string
+
+// UserSearchFields are the fields of a user matched by searches
+var UserSearchFields = []string{"firstName", "lastName", "email"}
+
+// OrganizationSearchFields are the fields of an organization matched by
+// directory searches
+var OrganizationSearchFields = []string{"name", "description", "industry"}
+
+// SearchKeysOf gets the search keys of a value: the lowercased value from
+// the start of each of its words, so a term matches the start of any word.
+// For example "Jane.Doe@example.com" has the keys "jane.doe@example.com",
+// "doe@example.com", "example.com" and "com".
+func SearchKeysOf(value string) []string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	var keys []string
+	inWord := false
+	for i, r := range value {
+		isWordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWordRune && !inWord {
+			keys = append(keys, truncateSearchKey(value[i:]))
+		}
+		inWord = isWordRune
+	}
+	return uniqueStrings(keys)
+}
+
+// SearchTerms splits a search into the lowercased words that must each
+// start a search key
+func SearchTerms(search string) []string {
+	return uniqueStrings(strings.Fields(strings.ToLower(search)))
+}
+
+// SearchKeys gets the search keys of a user
+func (u *User) SearchKeys() SearchKeys {
+	return SearchKeys{
+		"firstName": SearchKeysOf(u.FirstName),
+		"lastName":  SearchKeysOf(u.LastName),
+		"email":     SearchKeysOf(u.Email),
+	}
+}
+
+// SearchKeys gets the search keys of an organization
+func (o *Organization) SearchKeys() SearchKeys {
+	return SearchKeys{
+		"name":        SearchKeysOf(o.Name),
+		"description": SearchKeysOf(o.Description),
+		"industry":    SearchKeysOf(o.Industry),
+	}
+}
+
+// truncateSearchKey cuts a search key to the longest key length, without
+// splitting a character
+func truncateSearchKey(key string) string {
+	if len(key) <= maxSearchKeyLength {
+		return key
+	}
+	cut := maxSearchKeyLength
+	for cut > 0 && !utf8.RuneStart(key[cut]) {
+		cut--
+	}
+	return key[:cut]
+}
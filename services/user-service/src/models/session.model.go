@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// MaxListedSessions is the most sessions listed for a user
+const MaxListedSessions = 100
+
+// Session is a sign-in of a user, mirrored from the Auth Service's session
+// events. The ID is the Auth Service's session ID.
+type Session struct {
+	ID           string     `bson:"_id" json:"id"`
+	UserID       string     `bson:"userId" json:"userId"`
+	Device       string     `bson:"device,omitempty" json:"device,omitempty"`
+	UserAgent    string     `bson:"userAgent,omitempty" json:"userAgent,omitempty"`
+	IPAddress    string     `bson:"ipAddress,omitempty" json:"ipAddress,omitempty"`
+	CreatedAt    time.Time  `bson:"createdAt" json:"createdAt"`
+	LastActiveAt time.Time  `bson:"lastActiveAt" json:"lastActiveAt"`
+	ExpiresAt    *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+}
+
+// SessionResponse represents a session response
+type SessionResponse struct {
+	ID           string     `json:"id"`
+	Device       string     `json:"device,omitempty"`
+	UserAgent    string     `json:"userAgent,omitempty"`
+	IPAddress    string     `json:"ipAddress,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	LastActiveAt time.Time  `json:"lastActiveAt"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+}
+
+// ToResponse converts a session to a response
+func (s *Session) ToResponse() SessionResponse {
+	return SessionResponse{
+		ID:           s.ID,
+		Device:       s.Device,
+		UserAgent:    s.UserAgent,
+		IPAddress:    s.IPAddress,
+		CreatedAt:    s.CreatedAt,
+		LastActiveAt: s.LastActiveAt,
+		ExpiresAt:    s.ExpiresAt,
+	}
+}
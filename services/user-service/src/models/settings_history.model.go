@@ -0,0 +1,132 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SettingsVersion is a version of an organization's settings, recorded each
+// time they change. The first version holds the settings from before their
+// first recorded change.
+type SettingsVersion struct {
+	ID             string               `bson:"_id" json:"id"`
+	OrganizationID string               `bson:"organizationId" json:"organizationId"`
+	Version        int                  `bson:"version" json:"version"`
+	Settings       OrganizationSettings `bson:"settings" json:"settings"`
+	Changes        []SettingChange      `bson:"changes" json:"changes"`
+	ChangedBy      string               `bson:"changedBy,omitempty" json:"changedBy,omitempty"`
+	ChangedAt      time.Time            `bson:"changedAt" json:"changedAt"`
+	// RestoredVersion is the version a rollback restored
+	RestoredVersion int `bson:"restoredVersion,omitempty" json:"restoredVersion,omitempty"`
+}
+
+// SettingChange is a setting that changed, by its dotted JSON path, such as
+// features.enableTeams. Lists change as a whole.
+type SettingChange struct {
+	Field string      `bson:"field" json:"field"`
+	From  interface{} `bson:"from,omitempty" json:"from,omitempty"`
+	To    interface{} `bson:"to,omitempty" json:"to,omitempty"`
+}
+
+// NewSettingsVersion creates a new version of an organization's settings
+func NewSettingsVersion(orgID string, version int, settings OrganizationSettings, changes []SettingChange, changedBy string) *SettingsVersion {
+	if changes == nil {
+		changes = []SettingChange{}
+	}
+	return &SettingsVersion{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		Version:        version,
+		Settings:       settings,
+		Changes:        changes,
+		ChangedBy:      changedBy,
+		ChangedAt:      time.Now(),
+	}
+}
+
+// Clone returns a copy of the settings that shares nothing the organization
+// service modifies in place
+func (s OrganizationSettings) Clone() OrganizationSettings {
+	clone := s
+	if s.ApprovalWebhook != nil {
+		webhook := *s.ApprovalWebhook
+		webhook.Actions = append([]ApprovalAction(nil), s.ApprovalWebhook.Actions...)
+		clone.ApprovalWebhook = &webhook
+	}
+	if s.DefaultPreferences != nil {
+		defaults := *s.DefaultPreferences
+		clone.DefaultPreferences = &defaults
+	}
+	clone.CustomFields = append([]CustomFieldDefinition(nil), s.CustomFields...)
+	clone.AllowedEmailDomains = append([]string(nil), s.AllowedEmailDomains...)
+	clone.BlockedEmailDomains = append([]string(nil), s.BlockedEmailDomains...)
+	return clone
+}
+
+// DiffSettings lists the settings that differ between before and after, in
+// field order. Settings hidden from JSON, such as the approval webhook
+// secret, aren't listed.
+func DiffSettings(before, after OrganizationSettings) []SettingChange {
+	from := flattenSettings(before)
+	to := flattenSettings(after)
+
+	fields := make([]string, 0, len(from)+len(to))
+	for field := range from {
+		fields = append(fields, field)
+	}
+	for field := range to {
+		if _, ok := from[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	var changes []SettingChange
+	for _, field := range fields {
+		if !reflect.DeepEqual(from[field], to[field]) {
+			changes = append(changes, SettingChange{Field: field, From: from[field], To: to[field]})
+		}
+	}
+	return changes
+}
+
+// flattenSettings maps the dotted JSON path of every setting to its value
+func flattenSettings(settings OrganizationSettings) map[string]interface{} {
+	return flattenJSON(settings)
+}
+
+// flattenJSON maps the dotted JSON path of every field of value to its
+// value. Lists are values as a whole.
+func flattenJSON(value interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return flat
+	}
+	var tree map[string]interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return flat
+	}
+
+	var walk func(prefix string, value interface{})
+	walk = func(prefix string, value interface{}) {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			flat[prefix] = value
+			return
+		}
+		for key, child := range object {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			walk(key, child)
+		}
+	}
+	walk("", tree)
+	return flat
+}
@@ -0,0 +1,272 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+)
+
+// StatsInterval is the size of the buckets of a statistics time series
+type StatsInterval string
+
+// Statistics intervals
+const (
+	StatsDaily   StatsInterval = "day"
+	StatsWeekly  StatsInterval = "week"
+	StatsMonthly StatsInterval = "month"
+)
+
+// Statistics range limits
+const (
+	// DefaultStatsBuckets is the number of buckets of a series without a start
+	DefaultStatsBuckets = 30
+	// MaxStatsBuckets is the largest number of buckets of a series
+	MaxStatsBuckets = 366
+)
+
+// Statistics errors
+var (
+	ErrInvalidStatsInterval = apperrors.InvalidField("interval", "interval must be one of day, week, month")
+	ErrInvalidStatsRange    = apperrors.InvalidField("from", "from must be before to")
+	ErrStatsRangeTooLarge   = apperrors.InvalidField("from", "range spans too many intervals")
+	ErrStatsUnavailable     = apperrors.Unavailable("STATS_UNAVAILABLE", "statistics are not available with this storage driver")
+)
+
+// Valid checks the interval is supported
+func (i StatsInterval) Valid() bool {
+	switch i {
+	case StatsDaily, StatsWeekly, StatsMonthly:
+		return true
+	}
+	return false
+}
+
+// Truncate gets the start of the bucket t falls in. Buckets are in UTC and
+// weeks start on Monday, matching $dateTrunc.
+func (i StatsInterval) Truncate(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	switch i {
+	case StatsWeekly:
+		day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case StatsMonthly:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// Add gets the start of the bucket n intervals after the one starting at
+// start
+func (i StatsInterval) Add(start time.Time, n int) time.Time {
+	switch i {
+	case StatsWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case StatsMonthly:
+		return start.AddDate(0, n, 0)
+	default:
+		return start.AddDate(0, 0, n)
+	}
+}
+
+// Buckets gets the starts of the buckets from the one containing from to
+// the one containing to
+func (i StatsInterval) Buckets(from, to time.Time) []time.Time {
+	var starts []time.Time
+	for start := i.Truncate(from); !start.After(to); start = i.Add(start, 1) {
+		starts = append(starts, start)
+	}
+	return starts
+}
+
+// StatsCount is the number of documents of a group of an aggregation
+type StatsCount struct {
+	Key   string `bson:"_id" json:"key"`
+	Count int64  `bson:"count" json:"count"`
+}
+
+// StatsBucketCount is the number of documents within a bucket of a time
+// series
+type StatsBucketCount struct {
+	Start time.Time `bson:"_id" json:"start"`
+	Count int64     `bson:"count" json:"count"`
+}
+
+// EventTypeCount is the number of events of a type published within a
+// bucket
+type EventTypeCount struct {
+	Start     time.Time `bson:"start"`
+	EventType string    `bson:"eventType"`
+	Count     int64     `bson:"count"`
+}
+
+// OrganizationSizeBucket is a range of organization member counts, labelled
+// like the organization size field
+type OrganizationSizeBucket struct {
+	Label string
+	// MaxMembers is the largest member count of the bucket; 0 means no limit
+	MaxMembers int
+}
+
+// OrganizationSizeBuckets are the member count ranges organizations are
+// grouped by, smallest first
+var OrganizationSizeBuckets = []OrganizationSizeBucket{
+	{Label: "1-10", MaxMembers: 10},
+	{Label: "11-50", MaxMembers: 50},
+	{Label: "51-200", MaxMembers: 200},
+	{Label: "201-500", MaxMembers: 500},
+	{Label: "501-1000", MaxMembers: 1000},
+	{Label: "1001+"},
+}
+
+// MembershipChangeCount is the number of members that joined and left an
+// organization within a bucket
+type MembershipChangeCount struct {
+	Start  time.Time `bson:"_id"`
+	Joined int64     `bson:"joined"`
+	Left   int64     `bson:"left"`
+}
+
+// StatsQuery selects the range and interval of a statistics time series
+type StatsQuery struct {
+	Interval StatsInterval
+	From     time.Time
+	To       time.Time
+}
+
+// OrganizationStats represents the usage statistics of an organization
+type OrganizationStats struct {
+	OrganizationID string                  `json:"organizationId"`
+	Members        OrganizationMemberStats `json:"members"`
+	Teams          OrganizationTeamStats   `json:"teams"`
+	Growth         MemberGrowthSeries      `json:"growth"`
+	GeneratedAt    time.Time               `json:"generatedAt"`
+}
+
+// OrganizationMemberStats represents the members of an organization
+type OrganizationMemberStats struct {
+	Total int64 `json:"total"`
+	// Active members logged in within ActiveWindowDays
+	Active           int64                            `json:"active"`
+	ActiveWindowDays int                              `json:"activeWindowDays"`
+	ByRole           map[OrganizationMemberRole]int64 `json:"byRole"`
+}
+
+// OrganizationTeamStats represents the teams of an organization
+type OrganizationTeamStats struct {
+	Total int64 `json:"total"`
+	// Memberships counts every team member, so a user in two teams counts twice
+	Memberships int64 `json:"memberships"`
+}
+
+// MemberGrowthSeries represents the member count of an organization over time
+type MemberGrowthSeries struct {
+	Interval StatsInterval       `json:"interval"`
+	From     time.Time           `json:"from"`
+	To       time.Time           `json:"to"`
+	Points   []MemberGrowthPoint `json:"points"`
+}
+
+// MemberGrowthPoint represents the members that joined and left within a
+// bucket, and the member count at its end
+type MemberGrowthPoint struct {
+	Start  time.Time `json:"start"`
+	Joined int64     `json:"joined"`
+	Left   int64     `json:"left"`
+	Total  int64     `json:"total"`
+}
+
+// PlatformUserStats represents the users of the platform
+type PlatformUserStats struct {
+	Total       int64                `json:"total"`
+	ByStatus    map[UserStatus]int64 `json:"byStatus"`
+	ByRole      map[UserRole]int64   `json:"byRole"`
+	GeneratedAt time.Time            `json:"generatedAt"`
+}
+
+// CSV gets the counts as CSV records
+func (s *PlatformUserStats) CSV() [][]string {
+	records := [][]string{{"dimension", "value", "count"}}
+	for _, status := range sortedKeys(s.ByStatus) {
+		records = append(records, []string{"status", string(status), strconv.FormatInt(s.ByStatus[status], 10)})
+	}
+	for _, role := range sortedKeys(s.ByRole) {
+		records = append(records, []string{"role", string(role), strconv.FormatInt(s.ByRole[role], 10)})
+	}
+	return records
+}
+
+// PlatformOrganizationStats represents the organizations of the platform
+// grouped by member count
+type PlatformOrganizationStats struct {
+	Total       int64        `json:"total"`
+	BySize      []StatsCount `json:"bySize"`
+	GeneratedAt time.Time    `json:"generatedAt"`
+}
+
+// CSV gets the counts as CSV records
+func (s *PlatformOrganizationStats) CSV() [][]string {
+	records := [][]string{{"size", "count"}}
+	for _, size := range s.BySize {
+		records = append(records, []string{size.Key, strconv.FormatInt(size.Count, 10)})
+	}
+	return records
+}
+
+// SignupSeries represents the users that signed up per bucket
+type SignupSeries struct {
+	Interval    StatsInterval      `json:"interval"`
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	Points      []StatsBucketCount `json:"points"`
+	GeneratedAt time.Time          `json:"generatedAt"`
+}
+
+// CSV gets the series as CSV records
+func (s *SignupSeries) CSV() [][]string {
+	records := [][]string{{"start", "signups"}}
+	for _, point := range s.Points {
+		records = append(records, []string{point.Start.Format(time.RFC3339), strconv.FormatInt(point.Count, 10)})
+	}
+	return records
+}
+
+// EventSeries represents the events published per bucket
+type EventSeries struct {
+	Interval    StatsInterval `json:"interval"`
+	From        time.Time     `json:"from"`
+	To          time.Time     `json:"to"`
+	Points      []EventPoint  `json:"points"`
+	GeneratedAt time.Time     `json:"generatedAt"`
+}
+
+// EventPoint represents the events published within a bucket
+type EventPoint struct {
+	Start  time.Time        `json:"start"`
+	Total  int64            `json:"total"`
+	ByType map[string]int64 `json:"byType"`
+}
+
+// CSV gets the series as CSV records, one per bucket and event type
+func (s *EventSeries) CSV() [][]string {
+	records := [][]string{{"start", "eventType", "count"}}
+	for _, point := range s.Points {
+		start := point.Start.Format(time.RFC3339)
+		for _, eventType := range sortedKeys(point.ByType) {
+			records = append(records, []string{start, eventType, strconv.FormatInt(point.ByType[eventType], 10)})
+		}
+	}
+	return records
+}
+
+// sortedKeys gets the keys of a map in order, so CSV exports are stable
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// SubscriptionStatus represents the billing status of a subscription
+type SubscriptionStatus string
+
+// Subscription statuses
+const (
+	SubscriptionActive   SubscriptionStatus = "active"
+	SubscriptionTrialing SubscriptionStatus = "trialing"
+	SubscriptionPastDue  SubscriptionStatus = "past_due"
+	SubscriptionCanceled SubscriptionStatus = "canceled"
+)
+
+// Subscription is an organization's billing subscription, mirrored from the
+// billing service's events. Seats is the number of presenter seats bought,
+// each taken by a licensed member.
+type Subscription struct {
+	Plan   string             `bson:"plan" json:"plan"`
+	Seats  int                `bson:"seats" json:"seats"`
+	Status SubscriptionStatus `bson:"status" json:"status"`
+	// RenewsAt is when the current billing period ends, and the subscription
+	// renews unless CancelAtPeriodEnd is set
+	RenewsAt          *time.Time `bson:"renewsAt,omitempty" json:"renewsAt,omitempty"`
+	CancelAtPeriodEnd bool       `bson:"cancelAtPeriodEnd" json:"cancelAtPeriodEnd"`
+	// UpdatedAt is when the billing service changed the subscription. Older
+	// updates delivered late are ignored.
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// HasFreeSeat checks if an organization with a number of licensed members
+// can license another. Organizations without a subscription, or on a plan
+// without a seat count, have no seat limit.
+func (s *Subscription) HasFreeSeat(licensed int) bool {
+	return s == nil || s.Seats <= 0 || licensed < s.Seats
+}
+
+// SubscriptionResponse represents an organization's subscription and how
+// many of its seats are used
+type SubscriptionResponse struct {
+	OrganizationID string `json:"organizationId"`
+	Subscription
+	SeatsUsed int `json:"seatsUsed"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/base64"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+)
+
+// SyncEntityType represents the kind of entity a differential sync returns
+type SyncEntityType string
+
+// Sync entity types
+const (
+	SyncUser         SyncEntityType = "user"
+	SyncTeam         SyncEntityType = "team"
+	SyncOrganization SyncEntityType = "organization"
+)
+
+// Sync errors
+var (
+	ErrInvalidSyncCursor = apperrors.InvalidField("since", "invalid sync cursor")
+	ErrSyncCursorExpired = apperrors.Gone("SYNC_CURSOR_EXPIRED", "sync cursor expired; perform a full sync without since")
+)
+
+// Tombstone records that an entity was deleted, or stopped being visible to
+// some users, so differential syncs can tell clients to drop it. It is shown
+// to the listed users and to the members of the organization, if set.
+type Tombstone struct {
+	ID             string         `bson:"_id" json:"-"`
+	EntityType     SyncEntityType `bson:"entityType" json:"type"`
+	EntityID       string         `bson:"entityId" json:"id"`
+	OrganizationID string         `bson:"organizationId,omitempty" json:"organizationId,omitempty"`
+	UserIDs        []string       `bson:"userIds,omitempty" json:"-"`
+	DeletedAt      time.Time      `bson:"deletedAt" json:"deletedAt"`
+	ExpiresAt      time.Time      `bson:"expiresAt" json:"-"`
+}
+
+// NewTombstone creates a new tombstone kept for ttl
+func NewTombstone(entityType SyncEntityType, entityID, orgID string, userIDs []string, ttl time.Duration) *Tombstone {
+	now := time.Now()
+	return &Tombstone{
+		ID:             uuid.New().String(),
+		EntityType:     entityType,
+		EntityID:       entityID,
+		OrganizationID: orgID,
+		UserIDs:        userIDs,
+		DeletedAt:      now,
+		ExpiresAt:      now.Add(ttl),
+	}
+}
+
+// SyncCursor is the point a differential sync continues from
+type SyncCursor struct {
+	Since time.Time
+}
+
+// Encode encodes the cursor as an opaque string
+func (c SyncCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.Since.UTC().Format(time.RFC3339Nano)))
+}
+
+// ParseSyncCursor parses a cursor returned by Encode, or an RFC 3339 timestamp
+func ParseSyncCursor(s string) (*SyncCursor, error) {
+	if since, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return &SyncCursor{Since: since}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidSyncCursor
+	}
+
+	since, err := time.Parse(time.RFC3339Nano, string(raw))
+	if err != nil {
+		return nil, ErrInvalidSyncCursor
+	}
+
+	return &SyncCursor{Since: since}, nil
+}
+
+// SyncResponse represents the changes visible to a user since a sync cursor
+type SyncResponse struct {
+	Users         []UserResponse         `json:"users"`
+	Teams         []TeamResponse         `json:"teams"`
+	Organizations []OrganizationResponse `json:"organizations"`
+	Deleted       []*Tombstone           `json:"deleted"`
+	NextCursor    string                 `json:"nextCursor"`
+	HasMore       bool                   `json:"hasMore"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+)
+
+// MaxTags is the most tags an organization or team may have
+const MaxTags = 20
+
+// MaxTagLength is the longest a tag may be
+const MaxTagLength = 50
+
+// AddTagsRequest represents a request to tag an organization or team
+type AddTagsRequest struct {
+	Tags []string `json:"tags" validate:"required,min=1,max=20,dive,required,max=50"`
+}
+
+// NormalizeTag trims and lowercases a tag, so tags match regardless of case
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags normalizes tags and removes duplicates, rejecting empty tags,
+// tags that are too long and tags with commas, which separate tags in filters
+func NormalizeTags(field string, tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || len(tag) > MaxTagLength || strings.Contains(tag, ",") {
+			return nil, apperrors.InvalidField(field, fmt.Sprintf("tags must be 1 to %d characters without commas", MaxTagLength))
+		}
+		normalized = append(normalized, tag)
+	}
+	return uniqueStrings(normalized), nil
+}
+
+// ParseTagFilter parses a comma-separated tags query parameter. Listings
+// filtered by tags only include items with all of them.
+func ParseTagFilter(s string) ([]string, error) {
+	var tags []string
+	for _, value := range strings.Split(s, ",") {
+		if strings.TrimSpace(value) != "" {
+			tags = append(tags, value)
+		}
+	}
+	if len(tags) > MaxTags {
+		return nil, apperrors.InvalidField("tags", fmt.Sprintf("cannot filter by more than %d tags", MaxTags))
+	}
+	return NormalizeTags("tags", tags)
+}
+
+// MissingTags returns the tags that aren't already in existing
+func MissingTags(existing, tags []string) []string {
+	has := make(map[string]bool, len(existing))
+	for _, tag := range existing {
+		has[tag] = true
+	}
+
+	missing := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !has[tag] {
+			missing = append(missing, tag)
+		}
+	}
+	return missing
+}
@@ -1,10 +1,6 @@
 package models
 
-import (
-	"time"
-
-	"github.com/google/uuid"
-)
+import "time"
 
 // TeamMemberRole represents a team member role
 type TeamMemberRole string
@@ -17,6 +13,41 @@ const (
 	TeamRoleViewer TeamMemberRole = "viewer"
 )
 
+// TeamVisibility decides who can see a team
+type TeamVisibility string
+
+// Team visibilities
+const (
+	// TeamVisibilityOrganization teams, the default, are seen in full by
+	// everyone in the organization
+	TeamVisibilityOrganization TeamVisibility = "org-visible"
+	// TeamVisibilityPrivate teams are listed in the organization, but only
+	// their members see who is in them
+	TeamVisibilityPrivate TeamVisibility = "private"
+	// TeamVisibilitySecret teams are only seen by their members
+	TeamVisibilitySecret TeamVisibility = "secret"
+)
+
+// TeamJoinPolicy decides who can join a team
+type TeamJoinPolicy string
+
+// Team join policies
+const (
+	// TeamJoinInviteOnly teams, the default, only take members added by their
+	// admins
+	TeamJoinInviteOnly TeamJoinPolicy = "invite-only"
+	// TeamJoinApproval teams can be asked to join by members of the
+	// organization that can see them, and a team admin approves the request
+	TeamJoinApproval TeamJoinPolicy = "approval-required"
+	// TeamJoinOpen teams can be joined right away by members of the
+	// organization that can see them
+	TeamJoinOpen TeamJoinPolicy = "open"
+)
+
+// MaxTeamDepth is the most levels a team hierarchy may have, counting the
+// top-level team
+const MaxTeamDepth = 5
+
 // Team represents a team in the system
 type Team struct {
 	ID             string       `bson:"_id,omitempty" json:"id"`
@@ -24,10 +55,22 @@ type Team struct {
 	Description    string       `bson:"description,omitempty" json:"description,omitempty"`
 	LogoURL        string       `bson:"logoUrl,omitempty" json:"logoUrl,omitempty"`
 	OrganizationID string       `bson:"organizationId" json:"organizationId"`
+	ParentTeamID   string       `bson:"parentTeamId,omitempty" json:"parentTeamId,omitempty"`
+	ExternalID     string       `bson:"externalId,omitempty" json:"externalId,omitempty"`
+	Tags           []string     `bson:"tags,omitempty" json:"tags,omitempty"`
 	CreatedBy      string       `bson:"createdBy" json:"createdBy"`
 	CreatedAt      time.Time    `bson:"createdAt" json:"createdAt"`
 	UpdatedAt      time.Time    `bson:"updatedAt" json:"updatedAt"`
 	Members        []TeamMember `bson:"members" json:"members"`
+	Settings       TeamSettings `bson:"settings" json:"settings"`
+
+	PendingTransfer *OwnershipTransfer `bson:"pendingTransfer,omitempty" json:"pendingTransfer,omitempty"`
+
+	// ArchivedAt is set while the team is archived. Archived teams stay
+	// readable, but are left out of team listings and their members can't
+	// change.
+	ArchivedAt *time.Time `bson:"archivedAt,omitempty" json:"archivedAt,omitempty"`
+	ArchivedBy string     `bson:"archivedBy,omitempty" json:"archivedBy,omitempty"`
 }
 
 // TeamMember represents a member of a team
@@ -38,12 +81,62 @@ type TeamMember struct {
 	InvitedBy string         `bson:"invitedBy,omitempty" json:"invitedBy,omitempty"`
 }
 
+// TeamSettings represents settings for a team. Teams created before settings
+// existed have them empty, which reads as the defaults.
+type TeamSettings struct {
+	Visibility TeamVisibility `bson:"visibility,omitempty" json:"visibility"`
+	JoinPolicy TeamJoinPolicy `bson:"joinPolicy,omitempty" json:"joinPolicy"`
+	// DefaultMemberRole is the role of members added without one, and of
+	// users joining the team themselves
+	DefaultMemberRole TeamMemberRole `bson:"defaultMemberRole,omitempty" json:"defaultMemberRole"`
+}
+
+// DefaultTeamSettings returns the settings of a new team
+func DefaultTeamSettings() TeamSettings {
+	return TeamSettings{
+		Visibility:        TeamVisibilityOrganization,
+		JoinPolicy:        TeamJoinInviteOnly,
+		DefaultMemberRole: TeamRoleMember,
+	}
+}
+
+// Resolve fills the unset settings with their defaults
+func (s TeamSettings) Resolve() TeamSettings {
+	defaults := DefaultTeamSettings()
+	if s.Visibility == "" {
+		s.Visibility = defaults.Visibility
+	}
+	if s.JoinPolicy == "" {
+		s.JoinPolicy = defaults.JoinPolicy
+	}
+	if s.DefaultMemberRole == "" {
+		s.DefaultMemberRole = defaults.DefaultMemberRole
+	}
+	return s
+}
+
+// Apply returns the settings with a settings update request applied
+func (s TeamSettings) Apply(req UpdateTeamSettingsRequest) TeamSettings {
+	s = s.Resolve()
+	if req.Visibility != nil {
+		s.Visibility = *req.Visibility
+	}
+	if req.JoinPolicy != nil {
+		s.JoinPolicy = *req.JoinPolicy
+	}
+	if req.DefaultMemberRole != nil {
+		s.DefaultMemberRole = *req.DefaultMemberRole
+	}
+	return s
+}
+
 // CreateTeamRequest represents a request to create a new team
 type CreateTeamRequest struct {
 	Name           string `json:"name" validate:"required,min=3,max=50"`
 	Description    string `json:"description" validate:"max=500"`
 	LogoURL        string `json:"logoUrl" validate:"omitempty,url"`
 	OrganizationID string `json:"organizationId" validate:"required"`
+	ParentTeamID   string `json:"parentTeamId"`
 }
 
 // UpdateTeamRequest represents a request to update a team
@@ -53,10 +146,27 @@ type UpdateTeamRequest struct {
 	LogoURL     *string `json:"logoUrl,omitempty" validate:"omitempty,url"`
 }
 
-// AddTeamMemberRequest represents a request to add a member to a team
+// UpdateTeamSettingsRequest represents a request to update team settings
+type UpdateTeamSettingsRequest struct {
+	Visibility        *TeamVisibility `json:"visibility,omitempty" validate:"omitempty,oneof=org-visible private secret"`
+	JoinPolicy        *TeamJoinPolicy `json:"joinPolicy,omitempty" validate:"omitempty,oneof=invite-only approval-required open"`
+	DefaultMemberRole *TeamMemberRole `json:"defaultMemberRole,omitempty" validate:"omitempty,oneof=admin member viewer"`
+}
+
+// TeamClearableFields are the fields of a team that a merge patch can clear
+var TeamClearableFields = []string{"description", "logoUrl"}
+
+// MoveTeamRequest represents a request to move a team under another team. An
+// empty parent moves the team to the top level.
+type MoveTeamRequest struct {
+	ParentTeamID string `json:"parentTeamId"`
+}
+
+// AddTeamMemberRequest represents a request to add a member to a team.
+// Without a role, the member gets the team's default member role.
 type AddTeamMemberRequest struct {
 	UserID string         `json:"userId" validate:"required"`
-	Role   TeamMemberRole `json:"role" validate:"required,oneof=owner admin member viewer"`
+	Role   TeamMemberRole `json:"role" validate:"omitempty,oneof=owner admin member viewer"`
 }
 
 // UpdateTeamMemberRequest represents a request to update a team member
@@ -71,12 +181,40 @@ type TeamResponse struct {
 	Description    string             `json:"description,omitempty"`
 	LogoURL        string             `json:"logoUrl,omitempty"`
 	OrganizationID string             `json:"organizationId"`
+	ParentTeamID   string             `json:"parentTeamId,omitempty"`
+	Tags           []string           `json:"tags,omitempty"`
 	CreatedBy      string             `json:"createdBy"`
 	CreatedAt      time.Time          `json:"createdAt"`
 	MemberCount    int                `json:"memberCount"`
 	Members        []TeamMemberDetail `json:"members,omitempty"`
+	Settings       TeamSettings       `json:"settings"`
+	ArchivedAt     *time.Time         `json:"archivedAt,omitempty"`
+	ArchivedBy     string             `json:"archivedBy,omitempty"`
+}
+
+// TeamResponseFields maps the fields of a team response to the stored fields
+// they are read from. Members need the settings, since the team's visibility
+// decides who sees them.
+var TeamResponseFields = map[string][]string{
+	"id":             {"_id"},
+	"name":           {"name"},
+	"description":    {"description"},
+	"logoUrl":        {"logoUrl"},
+	"organizationId": {"organizationId"},
+	"parentTeamId":   {"parentTeamId"},
+	"tags":           {"tags"},
+	"createdBy":      {"createdBy"},
+	"createdAt":      {"createdAt"},
+	"memberCount":    {"members"},
+	"members":        {"members", "settings"},
+	"settings":       {"settings"},
+	"archivedAt":     {"archivedAt"},
+	"archivedBy":     {"archivedBy"},
 }
 
+// TeamExpansions are the fields of a team response it only has when included
+var TeamExpansions = []string{"members"}
+
 // TeamMemberDetail represents detailed information about a team member
 type TeamMemberDetail struct {
 	UserID    string         `json:"userId"`
@@ -88,15 +226,16 @@ type TeamMemberDetail struct {
 	JoinedAt  time.Time      `json:"joinedAt"`
 }
 
-// NewTeam creates a new team from a request
+// NewTeam creates a new team from a request. Its ID is left to the store,
+// which assigns the ObjectID the repositories look teams up by.
 func NewTeam(req CreateTeamRequest, createdBy string) *Team {
 	now := time.Now()
 	return &Team{
-		ID:             uuid.New().String(),
 		Name:           req.Name,
 		Description:    req.Description,
 		LogoURL:        req.LogoURL,
 		OrganizationID: req.OrganizationID,
+		ParentTeamID:   req.ParentTeamID,
 		CreatedBy:      createdBy,
 		CreatedAt:      now,
 		UpdatedAt:      now,
@@ -107,6 +246,7 @@ func NewTeam(req CreateTeamRequest, createdBy string) *Team {
 				JoinedAt: now,
 			},
 		},
+		Settings: DefaultTeamSettings(),
 	}
 }
 
@@ -118,9 +258,14 @@ func (t *Team) ToResponse(includeMembers bool) TeamResponse {
 		Description:    t.Description,
 		LogoURL:        t.LogoURL,
 		OrganizationID: t.OrganizationID,
+		ParentTeamID:   t.ParentTeamID,
+		Tags:           t.Tags,
 		CreatedBy:      t.CreatedBy,
 		CreatedAt:      t.CreatedAt,
 		MemberCount:    len(t.Members),
+		Settings:       t.Settings.Resolve(),
+		ArchivedAt:     t.ArchivedAt,
+		ArchivedBy:     t.ArchivedBy,
 	}
 
 	if includeMembers {
@@ -154,6 +299,12 @@ func (t *Team) Apply(req UpdateTeamRequest) {
 	}
 }
 
+// ApplySettings applies a settings update request to a team
+func (t *Team) ApplySettings(req UpdateTeamSettingsRequest) {
+	t.UpdatedAt = time.Now()
+	t.Settings = t.Settings.Apply(req)
+}
+
 // AddMember adds a member to the team
 func (t *Team) AddMember(userID string, role TeamMemberRole, invitedBy string) bool {
 	// Check if the user is already a member
@@ -219,6 +370,20 @@ func (t *Team) GetMember(userID string) *TeamMember {
 	return nil
 }
 
+// MemberIDs gets the user IDs of the team's members
+func (t *Team) MemberIDs() []string {
+	ids := make([]string, 0, len(t.Members))
+	for _, member := range t.Members {
+		ids = append(ids, member.UserID)
+	}
+	return ids
+}
+
+// IsArchived checks if the team is archived
+func (t *Team) IsArchived() bool {
+	return t.ArchivedAt != nil
+}
+
 // IsMember checks if a user is a member of the team
 func (t *Team) IsMember(userID string) bool {
 	return t.GetMember(userID) != nil
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TeamJoinRequest represents a user's request to join a team whose join
+// policy requires approval
+type TeamJoinRequest struct {
+	ID             string            `bson:"_id" json:"id"`
+	TeamID         string            `bson:"teamId" json:"teamId"`
+	OrganizationID string            `bson:"organizationId" json:"organizationId"`
+	UserID         string            `bson:"userId" json:"userId"`
+	Message        string            `bson:"message,omitempty" json:"message,omitempty"`
+	Status         JoinRequestStatus `bson:"status" json:"status"`
+	Role           TeamMemberRole    `bson:"role,omitempty" json:"role,omitempty"`
+	ReviewedBy     string            `bson:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+	ReviewReason   string            `bson:"reviewReason,omitempty" json:"reviewReason,omitempty"`
+	ReviewedAt     *time.Time        `bson:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	CreatedAt      time.Time         `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time         `bson:"updatedAt" json:"updatedAt"`
+}
+
+// ApproveTeamJoinRequestRequest represents a request to approve a team join
+// request. Without a role, the user gets the team's default member role.
+type ApproveTeamJoinRequestRequest struct {
+	Role   TeamMemberRole `json:"role" validate:"omitempty,oneof=admin member viewer"`
+	Reason string         `json:"reason" validate:"max=500"`
+}
+
+// TeamJoinRequestResponse represents a team join request response
+type TeamJoinRequestResponse struct {
+	ID           string            `json:"id"`
+	TeamID       string            `json:"teamId"`
+	UserID       string            `json:"userId"`
+	Email        string            `json:"email,omitempty"`
+	FullName     string            `json:"fullName,omitempty"`
+	Message      string            `json:"message,omitempty"`
+	Status       JoinRequestStatus `json:"status"`
+	Role         TeamMemberRole    `json:"role,omitempty"`
+	ReviewedBy   string            `json:"reviewedBy,omitempty"`
+	ReviewReason string            `json:"reviewReason,omitempty"`
+	ReviewedAt   *time.Time        `json:"reviewedAt,omitempty"`
+	CreatedAt    time.Time         `json:"createdAt"`
+}
+
+// TeamJoinResponse reports the outcome of a user joining a team. Open teams
+// are joined right away; teams requiring approval get a pending join request.
+type TeamJoinResponse struct {
+	Joined      bool                     `json:"joined"`
+	Role        TeamMemberRole           `json:"role,omitempty"`
+	JoinRequest *TeamJoinRequestResponse `json:"joinRequest,omitempty"`
+}
+
+// NewTeamJoinRequest creates a new pending team join request
+func NewTeamJoinRequest(team *Team, userID string, req CreateJoinRequestRequest) *TeamJoinRequest {
+	now := time.Now()
+	return &TeamJoinRequest{
+		ID:             uuid.New().String(),
+		TeamID:         team.ID,
+		OrganizationID: team.OrganizationID,
+		UserID:         userID,
+		Message:        req.Message,
+		Status:         JoinRequestPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// IsPending checks if the team join request is awaiting review
+func (r *TeamJoinRequest) IsPending() bool {
+	return r.Status == JoinRequestPending
+}
+
+// ToResponse converts a team join request to a response
+func (r *TeamJoinRequest) ToResponse(user *User) TeamJoinRequestResponse {
+	response := TeamJoinRequestResponse{
+		ID:           r.ID,
+		TeamID:       r.TeamID,
+		UserID:       r.UserID,
+		Message:      r.Message,
+		Status:       r.Status,
+		Role:         r.Role,
+		ReviewedBy:   r.ReviewedBy,
+		ReviewReason: r.ReviewReason,
+		ReviewedAt:   r.ReviewedAt,
+		CreatedAt:    r.CreatedAt,
+	}
+
+	if user != nil {
+		response.Email = user.Email
+		response.FullName = user.FirstName + " " + user.LastName
+	}
+
+	return response
+}
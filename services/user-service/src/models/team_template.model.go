@@ -0,0 +1,205 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var teamNamePlaceholder = regexp.MustCompile(`\{\{\s*([^{}\s]*)\s*\}\}`)
+
+// TeamTemplate is an organization's blueprint for a team structure it
+// recreates often, such as one team per department. Teams created from a
+// template get their name from its name pattern, its settings and its seed
+// roles as members.
+type TeamTemplate struct {
+	ID             string `bson:"_id" json:"id"`
+	OrganizationID string `bson:"organizationId" json:"organizationId"`
+	Name           string `bson:"name" json:"name"`
+	Description    string `bson:"description,omitempty" json:"description,omitempty"`
+	// NamePattern is the name of the teams created from the template, with
+	// {{variable}} placeholders filled in when a team is created
+	NamePattern string                 `bson:"namePattern" json:"namePattern"`
+	Settings    TeamSettings           `bson:"settings" json:"settings"`
+	SeedRoles   []TeamTemplateSeedRole `bson:"seedRoles" json:"seedRoles"`
+	CreatedBy   string                 `bson:"createdBy" json:"createdBy"`
+	CreatedAt   time.Time              `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time              `bson:"updatedAt" json:"updatedAt"`
+}
+
+// TeamTemplateSeedRole is an organization member added with a role to every
+// team created from a template
+type TeamTemplateSeedRole struct {
+	UserID string         `bson:"userId" json:"userId" validate:"required"`
+	Role   TeamMemberRole `bson:"role" json:"role" validate:"required,oneof=admin member viewer"`
+}
+
+// CreateTeamTemplateRequest represents a request to create a team template.
+// Settings left out get their defaults.
+type CreateTeamTemplateRequest struct {
+	Name        string                    `json:"name" validate:"required,min=1,max=100"`
+	Description string                    `json:"description" validate:"max=500"`
+	NamePattern string                    `json:"namePattern" validate:"required,min=1,max=100"`
+	Settings    UpdateTeamSettingsRequest `json:"settings"`
+	SeedRoles   []TeamTemplateSeedRole    `json:"seedRoles" validate:"max=50,dive"`
+}
+
+// UpdateTeamTemplateRequest represents a request to update a team template
+type UpdateTeamTemplateRequest struct {
+	Name        *string                    `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description *string                    `json:"description,omitempty" validate:"omitempty,max=500"`
+	NamePattern *string                    `json:"namePattern,omitempty" validate:"omitempty,min=1,max=100"`
+	Settings    *UpdateTeamSettingsRequest `json:"settings,omitempty"`
+	SeedRoles   *[]TeamTemplateSeedRole    `json:"seedRoles,omitempty" validate:"omitempty,max=50,dive"`
+}
+
+// CreateTeamFromTemplateRequest represents a request to create a team from a
+// template. Variables fill in the placeholders of the template's name pattern.
+type CreateTeamFromTemplateRequest struct {
+	Variables    map[string]string `json:"variables" validate:"max=20,dive,max=50"`
+	Description  string            `json:"description" validate:"max=500"`
+	LogoURL      string            `json:"logoUrl" validate:"omitempty,url"`
+	ParentTeamID string            `json:"parentTeamId"`
+}
+
+// TeamTemplateResponse represents a team template response
+type TeamTemplateResponse struct {
+	ID             string                 `json:"id"`
+	OrganizationID string                 `json:"organizationId"`
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description,omitempty"`
+	NamePattern    string                 `json:"namePattern"`
+	Variables      []string               `json:"variables"`
+	Settings       TeamSettings           `json:"settings"`
+	SeedRoles      []TeamTemplateSeedRole `json:"seedRoles"`
+	CreatedBy      string                 `json:"createdBy"`
+	CreatedAt      time.Time              `json:"createdAt"`
+	UpdatedAt      time.Time              `json:"updatedAt"`
+}
+
+// NewTeamTemplate creates a new team template from a request
+func NewTeamTemplate(orgID string, req CreateTeamTemplateRequest, createdBy string) *TeamTemplate {
+	now := time.Now()
+	seedRoles := req.SeedRoles
+	if seedRoles == nil {
+		seedRoles = []TeamTemplateSeedRole{}
+	}
+
+	return &TeamTemplate{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		Name:           req.Name,
+		Description:    req.Description,
+		NamePattern:    strings.TrimSpace(req.NamePattern),
+		Settings:       DefaultTeamSettings().Apply(req.Settings),
+		SeedRoles:      uniqueSeedRoles(seedRoles),
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// Apply applies an update request to a team template
+func (t *TeamTemplate) Apply(req UpdateTeamTemplateRequest) {
+	t.UpdatedAt = time.Now()
+
+	if req.Name != nil {
+		t.Name = *req.Name
+	}
+	if req.Description != nil {
+		t.Description = *req.Description
+	}
+	if req.NamePattern != nil {
+		t.NamePattern = strings.TrimSpace(*req.NamePattern)
+	}
+	if req.Settings != nil {
+		t.Settings = t.Settings.Apply(*req.Settings)
+	}
+	if req.SeedRoles != nil {
+		t.SeedRoles = uniqueSeedRoles(*req.SeedRoles)
+	}
+}
+
+// Variables lists the distinct placeholders of the template's name pattern,
+// in order
+func (t *TeamTemplate) Variables() []string {
+	return NamePatternVariables(t.NamePattern)
+}
+
+// TeamName fills in the placeholders of the template's name pattern. Every
+// placeholder needs a variable.
+func (t *TeamTemplate) TeamName(variables map[string]string) (string, error) {
+	var missing []string
+	name := teamNamePlaceholder.ReplaceAllStringFunc(t.NamePattern, func(placeholder string) string {
+		variable := teamNamePlaceholder.FindStringSubmatch(placeholder)[1]
+		value, ok := variables[variable]
+		if !ok || strings.TrimSpace(value) == "" {
+			missing = append(missing, variable)
+			return placeholder
+		}
+		return strings.TrimSpace(value)
+	})
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "", fmt.Errorf("missing value for variable %q", missing[0])
+	}
+	return strings.TrimSpace(name), nil
+}
+
+// ToResponse converts a team template to a response
+func (t *TeamTemplate) ToResponse() TeamTemplateResponse {
+	return TeamTemplateResponse{
+		ID:             t.ID,
+		OrganizationID: t.OrganizationID,
+		Name:           t.Name,
+		Description:    t.Description,
+		NamePattern:    t.NamePattern,
+		Variables:      t.Variables(),
+		Settings:       t.Settings.Resolve(),
+		SeedRoles:      t.SeedRoles,
+		CreatedBy:      t.CreatedBy,
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
+	}
+}
+
+// NamePatternVariables lists the distinct placeholders of a team name
+// pattern, in order
+func NamePatternVariables(pattern string) []string {
+	variables := []string{}
+	for _, match := range teamNamePlaceholder.FindAllStringSubmatch(pattern, -1) {
+		variables = append(variables, match[1])
+	}
+	return uniqueStrings(variables)
+}
+
+// ValidateNamePattern checks that the placeholders of a team name pattern are
+// valid variable names
+func ValidateNamePattern(pattern string) error {
+	for _, variable := range NamePatternVariables(pattern) {
+		if !emailTemplateVariable.MatchString(variable) {
+			return fmt.Errorf("invalid variable name %q", variable)
+		}
+	}
+	return nil
+}
+
+// uniqueSeedRoles keeps the last role given to each user, in the order the
+// users were first given
+func uniqueSeedRoles(seedRoles []TeamTemplateSeedRole) []TeamTemplateSeedRole {
+	unique := make([]TeamTemplateSeedRole, 0, len(seedRoles))
+	index := make(map[string]int, len(seedRoles))
+	for _, seed := range seedRoles {
+		if i, ok := index[seed.UserID]; ok {
+			unique[i].Role = seed.Role
+			continue
+		}
+		index[seed.UserID] = len(unique)
+		unique = append(unique, seed)
+	}
+	return unique
+}
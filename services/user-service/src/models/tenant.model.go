@@ -0,0 +1,40 @@
+package models
+
+// Organization data names the organization it belongs to, so repositories
+// can keep it inside the active organization of a request
+
+// TenantID returns the organization's own ID
+func (o *Organization) TenantID() string { return o.ID }
+
+// TenantID returns the ID of the team's organization
+func (t *Team) TenantID() string { return t.OrganizationID }
+
+// TenantID returns the ID of the member's organization
+func (r *OrganizationMemberRecord) TenantID() string { return r.OrganizationID }
+
+// TenantID returns the ID of the group's organization
+func (g *Group) TenantID() string { return g.OrganizationID }
+
+// TenantID returns the ID of the organization the request is to join
+func (r *JoinRequest) TenantID() string { return r.OrganizationID }
+
+// TenantID returns the ID of the template's organization
+func (t *TeamTemplate) TenantID() string { return t.OrganizationID }
+
+// TenantID returns the ID of the organization whose settings the version holds
+func (v *SettingsVersion) TenantID() string { return v.OrganizationID }
+
+// TenantID returns the ID of the exported organization
+func (e *OrganizationExport) TenantID() string { return e.OrganizationID }
+
+// TenantID returns the ID of the exported organization
+func (f *OrganizationExportFile) TenantID() string { return f.OrganizationID }
+
+// TenantID returns the ID of the exported organization
+func (c *OrganizationExportChunk) TenantID() string { return c.OrganizationID }
+
+// TenantID returns the ID of the organization whose history the entry is part of
+func (e *OrganizationHistoryEntry) TenantID() string { return e.OrganizationID }
+
+// TenantID returns the ID of the event's organization
+func (e *Event) TenantID() string { return e.OrganizationID }
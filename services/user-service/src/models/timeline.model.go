@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+)
+
+// TimelineEntryType groups organization timeline entries for filtering
+type TimelineEntryType string
+
+// Timeline entry types
+const (
+	TimelineMembership TimelineEntryType = "membership"
+	TimelineTeam       TimelineEntryType = "team"
+	TimelineAudit      TimelineEntryType = "audit"
+)
+
+// ErrInvalidTimelineCursor is returned for a malformed timeline cursor
+var ErrInvalidTimelineCursor = apperrors.InvalidField("cursor", "invalid timeline cursor")
+
+// TimelineEntry is an entry of an organization's activity timeline, recorded
+// from an event the service published
+type TimelineEntry struct {
+	ID             string                 `bson:"_id" json:"id"`
+	OrganizationID string                 `bson:"organizationId" json:"organizationId"`
+	Type           TimelineEntryType      `bson:"type" json:"type"`
+	EventID        string                 `bson:"eventId" json:"eventId"`
+	EventType      string                 `bson:"eventType" json:"eventType"`
+	ActorID        string                 `bson:"actorId,omitempty" json:"actorId,omitempty"`
+	Subject        string                 `bson:"subject,omitempty" json:"subject,omitempty"`
+	Data           map[string]interface{} `bson:"data,omitempty" json:"data,omitempty"`
+	OccurredAt     time.Time              `bson:"occurredAt" json:"occurredAt"`
+}
+
+// NewTimelineEntry creates a new timeline entry
+func NewTimelineEntry(orgID string, entryType TimelineEntryType, eventID, eventType, actorID, subject string, data map[string]interface{}, occurredAt time.Time) *TimelineEntry {
+	return &TimelineEntry{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		Type:           entryType,
+		EventID:        eventID,
+		EventType:      eventType,
+		ActorID:        actorID,
+		Subject:        subject,
+		Data:           data,
+		OccurredAt:     occurredAt,
+	}
+}
+
+// TimelineCursor is the position after the last entry of a timeline page
+type TimelineCursor struct {
+	OccurredAt time.Time
+	ID         string
+}
+
+// Encode encodes the cursor as an opaque string
+func (c TimelineCursor) Encode() string {
+	raw := c.OccurredAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseTimelineCursor parses a cursor returned by Encode
+func ParseTimelineCursor(s string) (*TimelineCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidTimelineCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, ErrInvalidTimelineCursor
+	}
+
+	occurredAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, ErrInvalidTimelineCursor
+	}
+
+	return &TimelineCursor{OccurredAt: occurredAt, ID: parts[1]}, nil
+}
+
+// TimelineResponse represents a page of an organization's timeline
+type TimelineResponse struct {
+	Entries    []*TimelineEntry `json:"entries"`
+	NextCursor string           `json:"nextCursor,omitempty"`
+	Limit      int              `json:"limit"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// TwoFactorStatus is a user's two-factor enrollment, synced from the Auth
+// Service, which enrolls users
+type TwoFactorStatus struct {
+	Enabled   bool      `bson:"enabled" json:"enabled"`
+	Methods   []string  `bson:"methods,omitempty" json:"methods,omitempty"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// TwoFactorResponse represents the current user's two-factor enrollment and
+// the organizations that require it
+type TwoFactorResponse struct {
+	Enabled    bool       `json:"enabled"`
+	Methods    []string   `json:"methods,omitempty"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+	RequiredBy []string   `json:"requiredBy"`
+}
+
+// TwoFactorComplianceResponse represents the members of an organization that
+// haven't enabled two-factor authentication
+type TwoFactorComplianceResponse struct {
+	OrganizationID   string                     `json:"organizationId"`
+	OrganizationName string                     `json:"organizationName"`
+	RequireTwoFactor bool                       `json:"requireTwoFactor"`
+	MemberCount      int                        `json:"memberCount"`
+	CompliantCount   int                        `json:"compliantCount"`
+	Members          []OrganizationMemberDetail `json:"members"`
+	Total            int64                      `json:"total"`
+	Page             int                        `json:"page"`
+	Limit            int                        `json:"limit"`
+	TotalPages       int64                      `json:"totalPages"`
+}
+
+// TwoFactorEnabled checks if a user enabled two-factor authentication
+func (u *User) TwoFactorEnabled() bool {
+	return u.TwoFactor != nil && u.TwoFactor.Enabled
+}
+
+// NewTwoFactorResponse creates the two-factor enrollment response of a user
+// from the organizations they are a member of
+func NewTwoFactorResponse(user *User, orgs []*Organization) TwoFactorResponse {
+	response := TwoFactorResponse{
+		Enabled:    user.TwoFactorEnabled(),
+		RequiredBy: []string{},
+	}
+	if user.TwoFactor != nil {
+		updatedAt := user.TwoFactor.UpdatedAt
+		response.Methods = user.TwoFactor.Methods
+		response.UpdatedAt = &updatedAt
+	}
+	for _, org := range orgs {
+		if org.Settings.RequireTwoFactor {
+			response.RequiredBy = append(response.RequiredBy, org.ID)
+		}
+	}
+	return response
+}
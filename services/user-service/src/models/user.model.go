@@ -1,10 +1,6 @@
 package models
 
-import (
-	"time"
-
-	"github.com/google/uuid"
-)
+import "time"
 
 // UserRole represents a user role
 type UserRole string
@@ -24,6 +20,19 @@ const (
 	StatusActive   UserStatus = "active"
 	StatusInactive UserStatus = "inactive"
 	StatusPending  UserStatus = "pending"
+
+	// StatusPendingReview marks a signup held for review by a platform admin.
+	// These users are hidden from search and can't join organizations or teams.
+	StatusPendingReview UserStatus = "pending-review"
+)
+
+// SignupReviewDecision represents the outcome of a signup review
+type SignupReviewDecision string
+
+// Signup review decisions
+const (
+	SignupReviewApproved SignupReviewDecision = "approved"
+	SignupReviewRejected SignupReviewDecision = "rejected"
 )
 
 // User represents a user in the system
@@ -45,10 +54,60 @@ type User struct {
 	SocialLinks     map[string]string `bson:"socialLinks,omitempty" json:"socialLinks,omitempty"`
 	Preferences     UserPreferences   `bson:"preferences" json:"preferences"`
 	LastLogin       *time.Time        `bson:"lastLogin,omitempty" json:"lastLogin,omitempty"`
+	SignupIP        string            `bson:"signupIp,omitempty" json:"signupIp,omitempty"`
+	SignupReview    *SignupReview     `bson:"signupReview,omitempty" json:"signupReview,omitempty"`
 	CreatedAt       time.Time         `bson:"createdAt" json:"createdAt"`
 	UpdatedAt       time.Time         `bson:"updatedAt" json:"updatedAt"`
 	OrganizationIDs []string          `bson:"organizationIds,omitempty" json:"organizationIds,omitempty"`
 	TeamIDs         []string          `bson:"teamIds,omitempty" json:"teamIds,omitempty"`
+	Favorites       []Favorite        `bson:"favorites,omitempty" json:"favorites,omitempty"`
+	Onboarding      *Onboarding       `bson:"onboarding,omitempty" json:"onboarding,omitempty"`
+	TwoFactor       *TwoFactorStatus  `bson:"twoFactor,omitempty" json:"twoFactor,omitempty"`
+
+	// PendingEmail is the address the user asked to change their email to,
+	// kept until the Auth Service confirms they verified it
+	PendingEmail           string     `bson:"pendingEmail,omitempty" json:"pendingEmail,omitempty"`
+	EmailChangeRequestedAt *time.Time `bson:"emailChangeRequestedAt,omitempty" json:"emailChangeRequestedAt,omitempty"`
+
+	// LastSeenAt is the last presence heartbeat of the user, saved at most
+	// once per presence last seen interval
+	LastSeenAt *time.Time `bson:"lastSeenAt,omitempty" json:"lastSeenAt,omitempty"`
+
+	// DeletedAt is set when the user is soft deleted, until they are
+	// restored or purged
+	DeletedAt *time.Time `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"`
+
+	// CustomFields maps organizations to the user's values for their custom
+	// profile fields. Values are only exposed through member details, which
+	// apply the fields' visibility.
+	CustomFields map[string]map[string]interface{} `bson:"customFields,omitempty" json:"-"`
+
+	// Search holds the normalized keys searches match the user's names and
+	// email against
+	Search SearchKeys `bson:"search,omitempty" json:"-"`
+}
+
+// SignupReview records why a signup was held for review and how it was resolved
+type SignupReview struct {
+	Reasons    []string             `bson:"reasons" json:"reasons"`
+	FlaggedAt  time.Time            `bson:"flaggedAt" json:"flaggedAt"`
+	Decision   SignupReviewDecision `bson:"decision,omitempty" json:"decision,omitempty"`
+	ReviewedBy string               `bson:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+	Reason     string               `bson:"reason,omitempty" json:"reason,omitempty"`
+	ReviewedAt *time.Time           `bson:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+}
+
+// ReviewSignupRequest represents a request to approve or reject a held signup
+type ReviewSignupRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// SignupReviewResponse represents a held signup in the review queue
+type SignupReviewResponse struct {
+	UserID       string        `json:"userId"`
+	User         UserResponse  `json:"user"`
+	SignupIP     string        `json:"signupIp,omitempty"`
+	SignupReview *SignupReview `json:"signupReview,omitempty"`
 }
 
 // UserPreferences represents user preferences
@@ -57,14 +116,19 @@ type UserPreferences struct {
 	Theme                string `bson:"theme" json:"theme"`
 	Timezone             string `bson:"timezone" json:"timezone"`
 	NotificationSettings struct {
-		Email bool `bson:"email" json:"email"`
-		Push  bool `bson:"push" json:"push"`
-		InApp bool `bson:"inApp" json:"inApp"`
+		Email     bool                  `bson:"email" json:"email"`
+		Push      bool                  `bson:"push" json:"push"`
+		InApp     bool                  `bson:"inApp" json:"inApp"`
+		Frequency NotificationFrequency `bson:"frequency,omitempty" json:"frequency"`
 	} `bson:"notificationSettings" json:"notificationSettings"`
 	Privacy struct {
 		ShowProfileToEveryone bool `bson:"showProfileToEveryone" json:"showProfileToEveryone"`
 		ShowEmailToEveryone   bool `bson:"showEmailToEveryone" json:"showEmailToEveryone"`
 	} `bson:"privacy" json:"privacy"`
+
+	// Overrides are the preferences the user set themselves, which their
+	// organization's defaults don't replace
+	Overrides []string `bson:"overrides,omitempty" json:"-"`
 }
 
 // CreateUserRequest represents a request to create a new user
@@ -92,15 +156,21 @@ type UpdateUserRequest struct {
 	Preferences    *UpdatePreferences `json:"preferences,omitempty"`
 }
 
+// UserClearableFields are the fields of a user that a merge patch can clear
+var UserClearableFields = []string{
+	"profilePicture", "bio", "jobTitle", "company", "location", "phone", "website", "socialLinks",
+}
+
 // UpdatePreferences represents a request to update user preferences
 type UpdatePreferences struct {
-	Language             *string `json:"language,omitempty"`
-	Theme                *string `json:"theme,omitempty"`
-	Timezone             *string `json:"timezone,omitempty"`
+	Language             *string `json:"language,omitempty" validate:"omitempty,locale"`
+	Theme                *string `json:"theme,omitempty" validate:"omitempty,oneof=light dark system"`
+	Timezone             *string `json:"timezone,omitempty" validate:"omitempty,timezone"`
 	NotificationSettings *struct {
-		Email *bool `json:"email,omitempty"`
-		Push  *bool `json:"push,omitempty"`
-		InApp *bool `json:"inApp,omitempty"`
+		Email     *bool                  `json:"email,omitempty"`
+		Push      *bool                  `json:"push,omitempty"`
+		InApp     *bool                  `json:"inApp,omitempty"`
+		Frequency *NotificationFrequency `json:"frequency,omitempty" validate:"omitempty,oneof=immediate daily_digest"`
 	} `json:"notificationSettings,omitempty"`
 	Privacy *struct {
 		ShowProfileToEveryone *bool `json:"showProfileToEveryone,omitempty"`
@@ -111,7 +181,7 @@ type UpdatePreferences struct {
 // UserResponse represents a user response
 type UserResponse struct {
 	ID             string            `json:"id"`
-	Email          string            `json:"email"`
+	Email          string            `json:"email,omitempty"`
 	FirstName      string            `json:"firstName"`
 	LastName       string            `json:"lastName"`
 	FullName       string            `json:"fullName"`
@@ -124,14 +194,68 @@ type UserResponse struct {
 	Location       string            `json:"location,omitempty"`
 	SocialLinks    map[string]string `json:"socialLinks,omitempty"`
 	LastLogin      *time.Time        `json:"lastLogin,omitempty"`
+	DeletedAt      *time.Time        `json:"deletedAt,omitempty"`
 	CreatedAt      time.Time         `json:"createdAt"`
 }
 
-// NewUser creates a new user from a request
+// UserSortFields maps the sortBy values of a user listing to the fields
+// they sort by
+var UserSortFields = map[string][]string{
+	"name":      {"lastName", "firstName"},
+	"email":     {"email"},
+	"createdAt": {"createdAt"},
+	"updatedAt": {"updatedAt"},
+	"lastLogin": {"lastLogin"},
+}
+
+// UserResponseFields maps the fields of a user response to the stored fields
+// they are read from
+var UserResponseFields = map[string][]string{
+	"id":             {"_id"},
+	"email":          {"email"},
+	"firstName":      {"firstName"},
+	"lastName":       {"lastName"},
+	"fullName":       {"firstName", "lastName"},
+	"role":           {"role"},
+	"status":         {"status"},
+	"profilePicture": {"profilePicture"},
+	"bio":            {"bio"},
+	"jobTitle":       {"jobTitle"},
+	"company":        {"company"},
+	"location":       {"location"},
+	"socialLinks":    {"socialLinks"},
+	"lastLogin":      {"lastLogin"},
+	"deletedAt":      {"deletedAt"},
+	"createdAt":      {"createdAt"},
+}
+
+// UserVisibilityFields are the stored fields that decide who may see a
+// user's profile, which every user response needs
+var UserVisibilityFields = []string{"userId", "preferences.privacy", "organizationIds", "teamIds"}
+
+// UserListFilter represents the filters and order of a user listing
+type UserListFilter struct {
+	Search         string
+	Role           UserRole
+	Status         UserStatus
+	OrganizationID string
+	TeamID         string
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	// IncludeDeleted lists soft deleted users too
+	IncludeDeleted bool
+	SortBy         string
+	// SortOrder is 1 for ascending and -1 for descending
+	SortOrder int
+	// Fields are the response fields to read
+	Fields FieldSet
+}
+
+// NewUser creates a new user from a request. Its ID is left to the store,
+// which assigns the ObjectID the repositories look users up by.
 func NewUser(req CreateUserRequest) *User {
 	now := time.Now()
 	return &User{
-		ID:        uuid.New().String(),
 		UserID:    req.UserID,
 		Email:     req.Email,
 		FirstName: req.FirstName,
@@ -139,17 +263,19 @@ func NewUser(req CreateUserRequest) *User {
 		Role:      req.Role,
 		Status:    StatusActive,
 		Preferences: UserPreferences{
-			Language: "en",
-			Theme:    "light",
-			Timezone: "UTC",
+			Language: DefaultLanguage,
+			Theme:    DefaultTheme,
+			Timezone: DefaultTimezone,
 			NotificationSettings: struct {
-				Email bool `bson:"email" json:"email"`
-				Push  bool `bson:"push" json:"push"`
-				InApp bool `bson:"inApp" json:"inApp"`
+				Email     bool                  `bson:"email" json:"email"`
+				Push      bool                  `bson:"push" json:"push"`
+				InApp     bool                  `bson:"inApp" json:"inApp"`
+				Frequency NotificationFrequency `bson:"frequency,omitempty" json:"frequency"`
 			}{
-				Email: true,
-				Push:  true,
-				InApp: true,
+				Email:     true,
+				Push:      true,
+				InApp:     true,
+				Frequency: NotificationImmediate,
 			},
 			Privacy: struct {
 				ShowProfileToEveryone bool `bson:"showProfileToEveryone" json:"showProfileToEveryone"`
@@ -181,10 +307,43 @@ func (u *User) ToResponse() UserResponse {
 		Location:       u.Location,
 		SocialLinks:    u.SocialLinks,
 		LastLogin:      u.LastLogin,
+		DeletedAt:      u.DeletedAt,
 		CreatedAt:      u.CreatedAt,
 	}
 }
 
+// IsPendingReview checks if a user's signup is held for review
+func (u *User) IsPendingReview() bool {
+	return u.Status == StatusPendingReview
+}
+
+// BelongsOnlyTo checks if an organization is the only one a user belongs
+// to. Their profile is otherwise shared with other organizations, so it's
+// not the organization's to manage.
+func (u *User) BelongsOnlyTo(orgID string) bool {
+	return len(u.OrganizationIDs) == 1 && u.OrganizationIDs[0] == orgID
+}
+
+// IsDeleted checks if a user is soft deleted
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
+// ToSignupReviewResponse converts a held user to a review queue entry
+func (u *User) ToSignupReviewResponse() SignupReviewResponse {
+	return SignupReviewResponse{
+		UserID:       u.UserID,
+		User:         u.ToResponse(),
+		SignupIP:     u.SignupIP,
+		SignupReview: u.SignupReview,
+	}
+}
+
+// ETag gets the entity tag of the user's current version
+func (u *User) ETag() string {
+	return entityTag(u.ID, u.UpdatedAt)
+}
+
 // Apply applies an update request to a user
 func (u *User) Apply(req UpdateUserRequest) {
 	u.UpdatedAt = time.Now()
@@ -227,24 +386,33 @@ func (u *User) Apply(req UpdateUserRequest) {
 	if req.Preferences != nil {
 		if req.Preferences.Language != nil {
 			u.Preferences.Language = *req.Preferences.Language
+			u.Preferences.override(PreferenceLanguage)
 		}
 		if req.Preferences.Theme != nil {
 			u.Preferences.Theme = *req.Preferences.Theme
 		}
 		if req.Preferences.Timezone != nil {
 			u.Preferences.Timezone = *req.Preferences.Timezone
+			u.Preferences.override(PreferenceTimezone)
 		}
 
 		// Update notification settings
 		if req.Preferences.NotificationSettings != nil {
 			if req.Preferences.NotificationSettings.Email != nil {
 				u.Preferences.NotificationSettings.Email = *req.Preferences.NotificationSettings.Email
+				u.Preferences.override(PreferenceNotificationEmail)
 			}
 			if req.Preferences.NotificationSettings.Push != nil {
 				u.Preferences.NotificationSettings.Push = *req.Preferences.NotificationSettings.Push
+				u.Preferences.override(PreferenceNotificationPush)
 			}
 			if req.Preferences.NotificationSettings.InApp != nil {
 				u.Preferences.NotificationSettings.InApp = *req.Preferences.NotificationSettings.InApp
+				u.Preferences.override(PreferenceNotificationInApp)
+			}
+			if req.Preferences.NotificationSettings.Frequency != nil {
+				u.Preferences.NotificationSettings.Frequency = *req.Preferences.NotificationSettings.Frequency
+				u.Preferences.override(PreferenceNotificationFrequency)
 			}
 		}
 
@@ -0,0 +1,5 @@
+package models
+
+// UserPurgeActor is recorded as who purged the users whose soft deletion
+// passed the purge delay
+const UserPurgeActor = "user-purge"
@@ -0,0 +1,112 @@
+package models
+
+// ProfileViewer is the user reading another user's profile, with what
+// decides which of the profile's fields they can see
+type ProfileViewer struct {
+	UserID string
+	// CanViewPrivate is set for viewers whose platform role lets them see
+	// every profile in full
+	CanViewPrivate  bool
+	OrganizationIDs []string
+	TeamIDs         []string
+}
+
+// NewProfileViewer creates a viewer from the viewer's user, which is nil for
+// callers without a user, and their platform roles
+func NewProfileViewer(userID string, user *User, roles []string) ProfileViewer {
+	viewer := ProfileViewer{
+		UserID:         userID,
+		CanViewPrivate: HasPlatformPermission(roles, PermPlatformViewPrivateProfiles),
+	}
+	if user != nil {
+		viewer.OrganizationIDs = user.OrganizationIDs
+		viewer.TeamIDs = user.TeamIDs
+	}
+	return viewer
+}
+
+// IsColleague checks if the viewer shares an organization or team with a user
+func (v ProfileViewer) IsColleague(u *User) bool {
+	for _, orgID := range v.OrganizationIDs {
+		if containsString(u.OrganizationIDs, orgID) {
+			return true
+		}
+	}
+	for _, teamID := range v.TeamIDs {
+		if containsString(u.TeamIDs, teamID) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToResponseFor converts a user to the response a viewer may see. The user,
+// platform admins and people sharing an organization or team with the user
+// see everything. Anyone else only sees the email when ShowEmailToEveryone is
+// set, and the profile details when ShowProfileToEveryone is set.
+func (u *User) ToResponseFor(viewer ProfileViewer) UserResponse {
+	response := u.ToResponse()
+	if viewer.UserID == u.UserID || viewer.CanViewPrivate || viewer.IsColleague(u) {
+		return response
+	}
+
+	privacy := u.Preferences.Privacy
+	if !privacy.ShowEmailToEveryone {
+		response.Email = ""
+	}
+	if !privacy.ShowProfileToEveryone {
+		response.ProfilePicture = ""
+		response.Bio = ""
+		response.JobTitle = ""
+		response.Company = ""
+		response.Location = ""
+		response.SocialLinks = nil
+		response.LastLogin = nil
+	}
+	return response
+}
+
+// TeamViewer is the user reading an organization's teams, with what decides
+// which of the teams they can see
+type TeamViewer struct {
+	UserID string
+	// CanViewAll is set for viewers whose organization role lets them see
+	// every team of the organization in full
+	CanViewAll bool
+}
+
+// NewTeamViewer creates a viewer of the teams of an organization, which is
+// nil when it no longer exists
+func NewTeamViewer(userID string, org *Organization) TeamViewer {
+	return TeamViewer{
+		UserID:     userID,
+		CanViewAll: org != nil && org.Can(userID, PermOrgManageTeams),
+	}
+}
+
+// CanSee checks if the viewer can see a team. Secret teams are hidden from
+// everyone but their members.
+func (v TeamViewer) CanSee(t *Team) bool {
+	return v.CanViewAll || t.Settings.Resolve().Visibility != TeamVisibilitySecret || t.IsMember(v.UserID)
+}
+
+// CanSeeMembers checks if the viewer can see who is in a team. Only the
+// members of private and secret teams can.
+func (v TeamViewer) CanSeeMembers(t *Team) bool {
+	return v.CanViewAll || t.Settings.Resolve().Visibility == TeamVisibilityOrganization || t.IsMember(v.UserID)
+}
+
+// HiddenFrom gets the user that team listings hide secret teams from, unless
+// they are a member, which is empty for viewers that see every team
+func (v TeamViewer) HiddenFrom() string {
+	if v.CanViewAll {
+		return ""
+	}
+	return v.UserID
+}
+
+// ToResponseFor converts a team to the response a viewer may see, leaving out
+// the members they can't see
+func (t *Team) ToResponseFor(viewer TeamViewer, includeMembers bool) TeamResponse {
+	return t.ToResponse(includeMembers && viewer.CanSeeMembers(t))
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookTestEvent is the event type sent by test deliveries
+const WebhookTestEvent = "webhook.test"
+
+// WebhookDeliveryStatus represents the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookSubscription represents an organization's HTTP callback registration
+type WebhookSubscription struct {
+	ID             string    `bson:"_id" json:"id"`
+	OrganizationID string    `bson:"organizationId" json:"organizationId"`
+	URL            string    `bson:"url" json:"url"`
+	Secret         string    `bson:"secret" json:"-"`
+	Events         []string  `bson:"events" json:"events"`
+	Description    string    `bson:"description,omitempty" json:"description,omitempty"`
+	Active         bool      `bson:"active" json:"active"`
+	CreatedBy      string    `bson:"createdBy" json:"createdBy"`
+	CreatedAt      time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// CreateWebhookRequest represents a request to register a webhook
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url,startswith=https://|startswith=http://"`
+	Secret      string   `json:"secret" validate:"omitempty,min=16,max=256"`
+	Events      []string `json:"events" validate:"required,min=1,dive,required,max=100"`
+	Description string   `json:"description" validate:"max=200"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// UpdateWebhookRequest represents a request to update a webhook
+type UpdateWebhookRequest struct {
+	URL         *string   `json:"url,omitempty" validate:"omitempty,url,startswith=https://|startswith=http://"`
+	Secret      *string   `json:"secret,omitempty" validate:"omitempty,min=16,max=256"`
+	Events      *[]string `json:"events,omitempty" validate:"omitempty,min=1,dive,required,max=100"`
+	Description *string   `json:"description,omitempty" validate:"omitempty,max=200"`
+	Active      *bool     `json:"active,omitempty"`
+}
+
+// WebhookResponse represents a webhook response. The secret is only
+// returned once, when it is generated or changed.
+type WebhookResponse struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organizationId"`
+	URL            string    `json:"url"`
+	Secret         string    `json:"secret,omitempty"`
+	Events         []string  `json:"events"`
+	Description    string    `json:"description,omitempty"`
+	Active         bool      `json:"active"`
+	CreatedBy      string    `json:"createdBy"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// WebhookPayload is the JSON body POSTed to webhook subscribers
+type WebhookPayload struct {
+	ID             string      `json:"id"`
+	Type           string      `json:"type"`
+	OrganizationID string      `json:"organizationId"`
+	Time           time.Time   `json:"time"`
+	Data           interface{} `json:"data"`
+}
+
+// WebhookAttempt records a single delivery attempt
+type WebhookAttempt struct {
+	At         time.Time `bson:"at" json:"at"`
+	StatusCode int       `bson:"statusCode,omitempty" json:"statusCode,omitempty"`
+	Error      string    `bson:"error,omitempty" json:"error,omitempty"`
+	DurationMs int64     `bson:"durationMs" json:"durationMs"`
+}
+
+// WebhookDelivery represents the delivery of one event to one webhook
+type WebhookDelivery struct {
+	ID             string                `bson:"_id" json:"id"`
+	WebhookID      string                `bson:"webhookId" json:"webhookId"`
+	OrganizationID string                `bson:"organizationId" json:"organizationId"`
+	EventType      string                `bson:"eventType" json:"eventType"`
+	Payload        json.RawMessage       `bson:"payload" json:"payload"`
+	Status         WebhookDeliveryStatus `bson:"status" json:"status"`
+	Attempts       []WebhookAttempt      `bson:"attempts" json:"attempts"`
+	NextAttemptAt  *time.Time            `bson:"nextAttemptAt,omitempty" json:"nextAttemptAt,omitempty"`
+	CreatedAt      time.Time             `bson:"createdAt" json:"createdAt"`
+	CompletedAt    *time.Time            `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+}
+
+// NewWebhookSubscription creates a new webhook subscription from a request
+func NewWebhookSubscription(orgID string, req CreateWebhookRequest, secret, createdBy string) *WebhookSubscription {
+	now := time.Now()
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	return &WebhookSubscription{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		URL:            req.URL,
+		Secret:         secret,
+		Events:         req.Events,
+		Description:    req.Description,
+		Active:         active,
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// Apply applies an update request to a webhook subscription
+func (w *WebhookSubscription) Apply(req UpdateWebhookRequest) {
+	w.UpdatedAt = time.Now()
+
+	if req.URL != nil {
+		w.URL = *req.URL
+	}
+	if req.Secret != nil {
+		w.Secret = *req.Secret
+	}
+	if req.Events != nil {
+		w.Events = *req.Events
+	}
+	if req.Description != nil {
+		w.Description = *req.Description
+	}
+	if req.Active != nil {
+		w.Active = *req.Active
+	}
+}
+
+// Matches checks if the subscription wants an event type. Filters are exact
+// event types, "*" for every event, or a prefix wildcard like "organization.*".
+func (w *WebhookSubscription) Matches(eventType string) bool {
+	for _, filter := range w.Events {
+		switch {
+		case filter == "*" || filter == eventType:
+			return true
+		case strings.HasSuffix(filter, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(filter, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+// ToResponse converts a webhook subscription to a response
+func (w *WebhookSubscription) ToResponse(includeSecret bool) WebhookResponse {
+	response := WebhookResponse{
+		ID:             w.ID,
+		OrganizationID: w.OrganizationID,
+		URL:            w.URL,
+		Events:         w.Events,
+		Description:    w.Description,
+		Active:         w.Active,
+		CreatedBy:      w.CreatedBy,
+		CreatedAt:      w.CreatedAt,
+		UpdatedAt:      w.UpdatedAt,
+	}
+
+	if includeSecret {
+		response.Secret = w.Secret
+	}
+
+	return response
+}
+
+// NewWebhookDelivery creates a new pending delivery of an encoded event payload to a webhook
+func NewWebhookDelivery(webhook *WebhookSubscription, eventType string, payload json.RawMessage) *WebhookDelivery {
+	now := time.Now()
+	return &WebhookDelivery{
+		ID:             uuid.New().String(),
+		WebhookID:      webhook.ID,
+		OrganizationID: webhook.OrganizationID,
+		EventType:      eventType,
+		Payload:        payload,
+		Status:         WebhookDeliveryPending,
+		Attempts:       []WebhookAttempt{},
+		NextAttemptAt:  &now,
+		CreatedAt:      now,
+	}
+}
@@ -0,0 +1,256 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Kind classifies a domain error and decides its HTTP status
+type Kind string
+
+// Error kinds
+const (
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindGone         Kind = "gone"
+	KindPrecondition Kind = "precondition"
+	KindUnsupported  Kind = "unsupported"
+	KindUnavailable  Kind = "unavailable"
+	KindInternal     Kind = "internal"
+)
+
+// Error is a domain error with a machine-readable code. Its message is
+// returned to clients, so it must not leak internals; the wrapped cause is
+// only logged.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details interface{}
+	Err     error
+}
+
+// Error returns the message of the error, followed by its cause if any
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the cause of the error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the same domain error, so sentinels still
+// match after WithDetails or WithCause
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code && t.Message == e.Message
+}
+
+// Status gets the HTTP status of the error
+func (e *Error) Status() int {
+	switch e.Kind {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindGone:
+		return http.StatusGone
+	case KindPrecondition:
+		return http.StatusPreconditionFailed
+	case KindUnsupported:
+		return http.StatusUnsupportedMediaType
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WithDetails returns a copy of the error with details for the client
+func (e *Error) WithDetails(details interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// WithCause returns a copy of the error wrapping a cause
+func (e *Error) WithCause(err error) *Error {
+	copied := *e
+	copied.Err = err
+	return &copied
+}
+
+// New creates a new domain error
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// Validation creates an error for a malformed or invalid request
+func Validation(code, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+// Unauthorized creates an error for a missing or invalid identity
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+// Forbidden creates an error for a caller lacking permission
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+// NotFound creates an error for a missing resource
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+// Conflict creates an error for a request conflicting with the current state
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+// Gone creates an error for a resource that is no longer available
+func Gone(code, message string) *Error {
+	return New(KindGone, code, message)
+}
+
+// PreconditionFailed creates an error for a conditional request whose
+// precondition doesn't hold, such as an If-Match header naming an outdated
+// version
+func PreconditionFailed(code, message string) *Error {
+	return New(KindPrecondition, code, message)
+}
+
+// UnsupportedMediaType creates an error for a request body in a format the
+// endpoint doesn't accept
+func UnsupportedMediaType(code, message string) *Error {
+	return New(KindUnsupported, code, message)
+}
+
+// Unavailable creates an error for a dependency that can't be reached
+func Unavailable(code, message string) *Error {
+	return New(KindUnavailable, code, message)
+}
+
+// Internal creates an error for an unexpected failure
+func Internal(message string, err error) *Error {
+	return &Error{Kind: KindInternal, Code: "INTERNAL_ERROR", Message: message, Err: err}
+}
+
+// Common request errors
+var (
+	// ErrUnauthorized is returned when a request has no authenticated user
+	ErrUnauthorized = Unauthorized("UNAUTHORIZED", "Unauthorized")
+	// ErrInvalidBody is returned when a request body can't be parsed
+	ErrInvalidBody = Validation("INVALID_REQUEST_BODY", "Invalid request body")
+	// ErrValidation is returned when a request body fails validation
+	ErrValidation = Validation("VALIDATION_ERROR", "Validation error")
+)
+
+// FieldError describes a request field that failed validation. Fields are
+// named by their JSON paths, so clients can map them to form fields.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// InvalidBody creates an error for a request body that can't be parsed.
+// Bodies that parse but fail binding validation, or have a field of the
+// wrong type, get field details like FromValidator.
+func InvalidBody(err error) *Error {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return FromValidator(err)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return ErrInvalidBody.WithDetails([]FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Param:   typeErr.Type.String(),
+			Message: fmt.Sprintf("%s must be of type %s", typeErr.Field, jsonType(typeErr.Type)),
+		}}).WithCause(err)
+	}
+
+	return ErrInvalidBody.WithCause(err)
+}
+
+// FromValidator creates a validation error listing the fields that failed
+func FromValidator(err error) *Error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return ErrValidation.WithDetails([]FieldError{{Rule: "invalid", Message: err.Error()}})
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		field := fieldErr.Namespace()[strings.Index(fieldErr.Namespace(), ".")+1:]
+		fields = append(fields, FieldError{
+			Field:   field,
+			Rule:    fieldErr.Tag(),
+			Param:   fieldErr.Param(),
+			Message: validationMessage(field, fieldErr),
+		})
+	}
+	return ErrValidation.WithDetails(fields).WithCause(err)
+}
+
+// InvalidField creates a validation error for a single field
+func InvalidField(field, message string) *Error {
+	return Validation("VALIDATION_ERROR", message).WithDetails([]FieldError{{Field: field, Rule: "invalid", Message: message}})
+}
+
+// MissingParameter creates an error for a missing path parameter
+func MissingParameter(name string) *Error {
+	return Validation("MISSING_PARAMETER", "Missing "+name)
+}
+
+// From gets the domain error of err. Errors without one become internal
+// errors with the given message.
+func From(err error, message string) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(message, err)
+}
+
+// Response is the body of every error response
+type Response struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"requestId,omitempty"`
+}
+
+// ToResponse converts the error to a response body
+func (e *Error) ToResponse(requestID string) Response {
+	return Response{
+		Code:      e.Code,
+		Message:   e.Message,
+		Details:   e.Details,
+		RequestID: requestID,
+	}
+}
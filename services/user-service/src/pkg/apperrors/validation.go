@@ -0,0 +1,80 @@
+package apperrors
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validationMessage describes the rule a field failed in plain words
+func validationMessage(field string, fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required", "required_without", "required_if":
+		return field + " is required"
+	case "excluded_with":
+		return fmt.Sprintf("%s can't be set together with %s", field, param)
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s", field, quantity(fieldErr.Kind(), param))
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s", field, quantity(fieldErr.Kind(), param))
+	case "len":
+		return fmt.Sprintf("%s must be exactly %s", field, quantity(fieldErr.Kind(), param))
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, strings.Join(strings.Fields(param), ", "))
+	case "startswith":
+		return fmt.Sprintf("%s must start with %s", field, param)
+	case "email":
+		return field + " must be a valid email address"
+	case "url":
+		return field + " must be a valid URL"
+	case "fqdn":
+		return field + " must be a valid domain name"
+	case "ip":
+		return field + " must be a valid IP address"
+	case "e164":
+		return field + " must be a phone number in E.164 format, such as +14155552671"
+	case "hexcolor":
+		return field + " must be a hex color, such as #1a2b3c"
+	case "timezone":
+		return field + " must be an IANA timezone, such as Europe/Berlin; see /api/meta/timezones"
+	case "locale":
+		return field + " must be a supported locale; see /api/meta/locales"
+	case "mongodb":
+		return field + " must be a valid ID"
+	default:
+		return field + " is invalid"
+	}
+}
+
+// quantity describes a length or size limit for the kind of field it applies to
+func quantity(kind reflect.Kind, param string) string {
+	switch kind {
+	case reflect.String:
+		return param + " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return param + " items"
+	default:
+		return param
+	}
+}
+
+// jsonType names a Go type as the JSON type a client should send
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,64 @@
+// Package cache keeps expensive results in memory for a fixed time, and
+// invalidates the entries of the shared Redis cache
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// TTL is a cache whose entries expire a fixed time after they are set.
+// Expired entries are dropped as new ones are set, so the cache only grows
+// with the number of keys in use within the TTL.
+type TTL[K comparable, V any] struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[K]entry[V]
+}
+
+// entry is a cached value and when it expires
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// New creates a cache keeping entries for ttl. A ttl of zero disables
+// caching.
+func New[K comparable, V any](ttl time.Duration) *TTL[K, V] {
+	return &TTL[K, V]{
+		ttl:     ttl,
+		entries: make(map[K]entry[V]),
+	}
+}
+
+// Get gets the value of a key, reporting whether it is cached
+func (c *TTL[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set caches the value of a key
+func (c *TTL[K, V]) Set(key K, value V) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, e := range c.entries {
+		if now.After(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = entry[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// Invalidator drops cached entities from the shared cache, so readers load
+// them again from storage
+type Invalidator interface {
+	// Invalidate drops the cached entity of a kind, such as "user", by ID
+	Invalidate(ctx context.Context, kind, id string) error
+	// Close releases the invalidator's connections
+	Close() error
+}
+
+// NewInvalidator creates the invalidator selected by configuration. Without
+// a Redis address there is no shared cache, so nothing is invalidated.
+func NewInvalidator(cfg *config.CacheConfig) Invalidator {
+	if cfg.RedisAddr == "" {
+		return nopInvalidator{}
+	}
+	return &redisInvalidator{
+		client: redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		}),
+		prefix: cfg.KeyPrefix,
+	}
+}
+
+// EntityKey returns the key an entity is cached under in the shared cache
+func EntityKey(prefix, kind, id string) string {
+	return prefix + kind + ":" + id
+}
+
+// redisInvalidator deletes the keys of changed entities from Redis
+type redisInvalidator struct {
+	client *redis.Client
+	prefix string
+}
+
+// Invalidate deletes the key of an entity
+func (i *redisInvalidator) Invalidate(ctx context.Context, kind, id string) error {
+	return i.client.Del(ctx, EntityKey(i.prefix, kind, id)).Err()
+}
+
+// Close closes the Redis client
+func (i *redisInvalidator) Close() error {
+	return i.client.Close()
+}
+
+// nopInvalidator is used without a shared cache
+type nopInvalidator struct{}
+
+// Invalidate does nothing
+func (nopInvalidator) Invalidate(context.Context, string, string) error { return nil }
+
+// Close does nothing
+func (nopInvalidator) Close() error { return nil }
@@ -0,0 +1,103 @@
+// Package dataloader batches lookups by key that are made concurrently, so
+// resolving a list of items costs one query per batch instead of one per item
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchFunc fetches the values of a batch of keys. Keys without a value are
+// left out of the result.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects the keys loaded within a wait window and fetches them in one
+// batch. Results are cached for the lifetime of the loader, so a loader is
+// created per request.
+type Loader[K comparable, V any] struct {
+	ctx      context.Context
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	pending []K
+}
+
+// result is the outcome of loading a key, available once done is closed
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	found bool
+	err   error
+}
+
+// New creates a loader fetching batches of up to maxBatch keys with the
+// context of the request
+func New[K comparable, V any](ctx context.Context, fetch BatchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{
+		ctx:      ctx,
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		results:  make(map[K]*result[V]),
+	}
+}
+
+// Load gets the value of a key, reporting whether it exists
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, bool, error) {
+	l.mu.Lock()
+	res, ok := l.results[key]
+	if !ok {
+		res = &result[V]{done: make(chan struct{})}
+		l.results[key] = res
+		l.pending = append(l.pending, key)
+
+		// The first key starts the wait window; a full batch is fetched
+		// right away
+		switch {
+		case len(l.pending) >= l.maxBatch:
+			go l.dispatch()
+		case len(l.pending) == 1:
+			time.AfterFunc(l.wait, l.dispatch)
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-res.done:
+		return res.value, res.found, res.err
+	case <-ctx.Done():
+		var zero V
+		return zero, false, ctx.Err()
+	}
+}
+
+// dispatch fetches the pending keys and resolves their results
+func (l *Loader[K, V]) dispatch() {
+	l.mu.Lock()
+	keys := l.pending
+	l.pending = nil
+	results := make([]*result[V], len(keys))
+	for i, key := range keys {
+		results[i] = l.results[key]
+	}
+	l.mu.Unlock()
+
+	if len(keys) == 0 {
+		return
+	}
+
+	values, err := l.fetch(l.ctx, keys)
+	for i, key := range keys {
+		res := results[i]
+		if err != nil {
+			res.err = err
+		} else {
+			res.value, res.found = values[key]
+		}
+		close(res.done)
+	}
+}
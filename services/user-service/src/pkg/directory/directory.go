@@ -0,0 +1,218 @@
+// Package directory reads the member directories of Google Workspace and
+// Slack workspaces with an access token granted by one of their admins.
+package directory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/your-username/slido-clone/user-service/pkg/utils"
+)
+
+// Page sizes of directory requests, the most each API allows
+const (
+	googlePageSize = 500
+	slackPageSize  = 200
+)
+
+var (
+	// ErrUnauthorized is returned when the directory rejects the access token
+	// or it lacks the scopes to read members
+	ErrUnauthorized = errors.New("directory: access token was rejected")
+	// ErrTooManyMembers is returned when a directory has more active members
+	// than the limit
+	ErrTooManyMembers = errors.New("directory: too many members")
+)
+
+// Member is an active person in a directory. Email is empty when the
+// directory doesn't share it.
+type Member struct {
+	ID        string
+	Email     string
+	FirstName string
+	LastName  string
+	JobTitle  string
+}
+
+// googleUsersPage is a page of the Admin SDK users.list response
+type googleUsersPage struct {
+	Users []struct {
+		ID           string `json:"id"`
+		PrimaryEmail string `json:"primaryEmail"`
+		Name         struct {
+			GivenName  string `json:"givenName"`
+			FamilyName string `json:"familyName"`
+		} `json:"name"`
+		Suspended     bool `json:"suspended"`
+		Archived      bool `json:"archived"`
+		Organizations []struct {
+			Title   string `json:"title"`
+			Primary bool   `json:"primary"`
+		} `json:"organizations"`
+	} `json:"users"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// Google lists the active users of a Google Workspace account with the Admin
+// SDK Directory API, only those of domain if set. The token needs the
+// admin.directory.user.readonly scope. At most limit members are read; 0 is
+// unlimited.
+func Google(ctx context.Context, baseURL, token, domain string, limit int) ([]Member, error) {
+	query := url.Values{}
+	if domain != "" {
+		query.Set("domain", domain)
+	} else {
+		query.Set("customer", "my_customer")
+	}
+	query.Set("maxResults", fmt.Sprint(googlePageSize))
+	query.Set("orderBy", "email")
+
+	headers := map[string]string{"Authorization": "Bearer " + token}
+
+	var members []Member
+	for {
+		var page googleUsersPage
+		if err := utils.GetJSON(ctx, strings.TrimRight(baseURL, "/")+"/users?"+query.Encode(), headers, &page); err != nil {
+			return nil, googleError(err)
+		}
+
+		for _, user := range page.Users {
+			if user.Suspended || user.Archived {
+				continue
+			}
+
+			member := Member{
+				ID:        user.ID,
+				Email:     user.PrimaryEmail,
+				FirstName: user.Name.GivenName,
+				LastName:  user.Name.FamilyName,
+			}
+			for _, org := range user.Organizations {
+				if org.Title != "" && (member.JobTitle == "" || org.Primary) {
+					member.JobTitle = org.Title
+				}
+			}
+
+			if limit > 0 && len(members) >= limit {
+				return nil, ErrTooManyMembers
+			}
+			members = append(members, member)
+		}
+
+		if page.NextPageToken == "" {
+			return members, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// googleError maps rejected tokens to ErrUnauthorized
+func googleError(err error) error {
+	var httpErr *utils.HTTPError
+	if errors.As(err, &httpErr) && (httpErr.Status == http.StatusUnauthorized || httpErr.Status == http.StatusForbidden) {
+		return ErrUnauthorized
+	}
+	return fmt.Errorf("directory: list Google Workspace users: %w", err)
+}
+
+// slackUsersPage is a page of the users.list response
+type slackUsersPage struct {
+	OK      bool   `json:"ok"`
+	Error   string `json:"error"`
+	Members []struct {
+		ID                string `json:"id"`
+		Deleted           bool   `json:"deleted"`
+		IsBot             bool   `json:"is_bot"`
+		IsAppUser         bool   `json:"is_app_user"`
+		IsRestricted      bool   `json:"is_restricted"`
+		IsUltraRestricted bool   `json:"is_ultra_restricted"`
+		Profile           struct {
+			Email     string `json:"email"`
+			FirstName string `json:"first_name"`
+			LastName  string `json:"last_name"`
+			RealName  string `json:"real_name"`
+			Title     string `json:"title"`
+		} `json:"profile"`
+	} `json:"members"`
+	ResponseMetadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
+}
+
+// slackAuthErrors are the users.list errors caused by the token
+var slackAuthErrors = map[string]bool{
+	"not_authed":        true,
+	"invalid_auth":      true,
+	"account_inactive":  true,
+	"token_revoked":     true,
+	"token_expired":     true,
+	"missing_scope":     true,
+	"not_allowed_token": true,
+}
+
+// Slack lists the active full members of a Slack workspace; guests, bots
+// and deactivated accounts are left out. The token needs the users:read
+// scope, and users:read.email for members' emails. At most limit members
+// are read; 0 is unlimited.
+func Slack(ctx context.Context, baseURL, token string, limit int) ([]Member, error) {
+	query := url.Values{}
+	query.Set("limit", fmt.Sprint(slackPageSize))
+
+	headers := map[string]string{"Authorization": "Bearer " + token}
+
+	var members []Member
+	for {
+		var page slackUsersPage
+		if err := utils.GetJSON(ctx, strings.TrimRight(baseURL, "/")+"/users.list?"+query.Encode(), headers, &page); err != nil {
+			return nil, fmt.Errorf("directory: list Slack users: %w", err)
+		}
+		// Slack reports failures in the body of 200 responses
+		if !page.OK {
+			if slackAuthErrors[page.Error] {
+				return nil, ErrUnauthorized
+			}
+			return nil, fmt.Errorf("directory: list Slack users: %s", page.Error)
+		}
+
+		for _, user := range page.Members {
+			if user.Deleted || user.IsBot || user.IsAppUser || user.IsRestricted || user.IsUltraRestricted || user.ID == "USLACKBOT" {
+				continue
+			}
+
+			member := Member{
+				ID:        user.ID,
+				Email:     user.Profile.Email,
+				FirstName: user.Profile.FirstName,
+				LastName:  user.Profile.LastName,
+				JobTitle:  user.Profile.Title,
+			}
+			// Many profiles only have a display name
+			if member.FirstName == "" && member.LastName == "" {
+				member.FirstName, member.LastName = splitName(user.Profile.RealName)
+			}
+
+			if limit > 0 && len(members) >= limit {
+				return nil, ErrTooManyMembers
+			}
+			members = append(members, member)
+		}
+
+		if page.ResponseMetadata.NextCursor == "" {
+			return members, nil
+		}
+		query.Set("cursor", page.ResponseMetadata.NextCursor)
+	}
+}
+
+// splitName splits a full name into first and last names at its last space
+func splitName(name string) (string, string) {
+	name = strings.TrimSpace(name)
+	if i := strings.LastIndexByte(name, ' '); i > 0 {
+		return strings.TrimSpace(name[:i]), name[i+1:]
+	}
+	return name, ""
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses of a probe and its dependencies
+const (
+	StatusUp   = "UP"
+	StatusDown = "DOWN"
+)
+
+// Check checks a dependency, returning an error when it can't be used
+type Check func(ctx context.Context) error
+
+// Dependency is the outcome of checking a dependency
+type Dependency struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Probe checks a set of dependencies. Checks run concurrently, each bounded
+// by the probe's timeout, and the probe is up when all of them pass.
+type Probe struct {
+	timeout time.Duration
+	names   []string
+	checks  []Check
+}
+
+// NewProbe creates a probe whose checks time out after timeout
+func NewProbe(timeout time.Duration) *Probe {
+	return &Probe{timeout: timeout}
+}
+
+// Add adds a dependency check to the probe
+func (p *Probe) Add(name string, check Check) *Probe {
+	p.names = append(p.names, name)
+	p.checks = append(p.checks, check)
+	return p
+}
+
+// Run runs the checks, returning if they all passed and the outcome of each
+func (p *Probe) Run(ctx context.Context) (bool, map[string]Dependency) {
+	results := make([]Dependency, len(p.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range p.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = run(ctx, check, p.timeout)
+		}(i, check)
+	}
+	wg.Wait()
+
+	up := true
+	dependencies := make(map[string]Dependency, len(results))
+	for i, result := range results {
+		dependencies[p.names[i]] = result
+		if result.Status != StatusUp {
+			up = false
+		}
+	}
+	return up, dependencies
+}
+
+// run runs one check, timing it
+func run(ctx context.Context, check Check, timeout time.Duration) Dependency {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := check(ctx)
+	dependency := Dependency{
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		dependency.Status = StatusDown
+		dependency.Error = err.Error()
+	}
+	return dependency
+}
+
+// StartupProbe is a probe that stops checking once it has passed, since
+// startup only completes once
+type StartupProbe struct {
+	probe   *Probe
+	started atomic.Bool
+}
+
+// NewStartupProbe creates a startup probe running the checks of a probe
+func NewStartupProbe(probe *Probe) *StartupProbe {
+	return &StartupProbe{probe: probe}
+}
+
+// Run runs the checks until they have all passed once. After that, it
+// reports success without any dependency outcomes.
+func (s *StartupProbe) Run(ctx context.Context) (bool, map[string]Dependency) {
+	if s.started.Load() {
+		return true, nil
+	}
+
+	up, dependencies := s.probe.Run(ctx)
+	if up {
+		s.started.Store(true)
+	}
+	return up, dependencies
+}
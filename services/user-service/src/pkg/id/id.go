@@ -0,0 +1,78 @@
+package id
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+
+	"github.com/google/uuid"
+)
+
+// alphanumeric is the charset of random strings
+const alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// unambiguous is the charset of codes people read and type: uppercase
+// letters and digits without 0, O, 1 and I, which are easily mistaken
+const unambiguous = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// New generates a UUIDv7: unique, and ordered by creation time so IDs
+// generated close together sort and index together. It panics if the system's
+// secure random source fails, like uuid.New.
+func New() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// Bytes generates n cryptographically secure random bytes
+func Bytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Token generates a secret token of n random bytes, hex-encoded after a
+// prefix, for API keys and similar secrets that identify their kind
+func Token(prefix string, n int) (string, error) {
+	b, err := Bytes(n)
+	if err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
+
+// URLToken generates a secret token of n random bytes, encoded for use in
+// URLs, cookies and headers, such as invite and CSRF tokens
+func URLToken(n int) (string, error) {
+	b, err := Bytes(n)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// String generates a random alphanumeric string of the given length
+func String(length int) (string, error) {
+	return fromCharset(alphanumeric, length)
+}
+
+// Code generates a random code of the given length that people can read
+// out and type, such as a join code
+func Code(length int) (string, error) {
+	return fromCharset(unambiguous, length)
+}
+
+// fromCharset generates a random string of the given length from a charset
+func fromCharset(charset string, length int) (string, error) {
+	max := big.NewInt(int64(len(charset)))
+	b := make([]byte, length)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = charset[n.Int64()]
+	}
+	return string(b), nil
+}
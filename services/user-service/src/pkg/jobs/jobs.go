@@ -0,0 +1,334 @@
+// Package jobs runs scheduled background jobs. Every replica runs the
+// scheduler, and a job takes a lease before each run, so only one replica
+// runs it at a time. Runs are recorded as the job's history.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Scheduler errors
+var (
+	// ErrJobNotFound is returned when triggering a job that isn't registered
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobRunning is returned when a job is already running on some replica
+	ErrJobRunning = errors.New("job is already running")
+	// ErrRunExists is returned by a RunStore when a run with the ID was
+	// already recorded, such as a scheduled run another replica started
+	ErrRunExists = errors.New("job run already exists")
+)
+
+// lockMargin is how much longer than its timeout a run holds its job's
+// lease, so the lease outlives a run that stops once its context is done
+const lockMargin = time.Minute
+
+// Trigger is what started a run
+type Trigger string
+
+// Run triggers
+const (
+	TriggerSchedule Trigger = "schedule"
+	TriggerManual   Trigger = "manual"
+)
+
+// Status is the state of a run
+type Status string
+
+// Run statuses
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Job is background work run on a schedule
+type Job struct {
+	Name        string
+	Description string
+	Schedule    *Schedule
+	// Timeout bounds a run; its context is cancelled once it passes
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Run is a run of a job
+type Run struct {
+	ID          string  `bson:"_id" json:"id"`
+	Job         string  `bson:"job" json:"job"`
+	Trigger     Trigger `bson:"trigger" json:"trigger"`
+	TriggeredBy string  `bson:"triggeredBy,omitempty" json:"triggeredBy,omitempty"`
+	// Instance is the replica that ran the job
+	Instance string `bson:"instance" json:"instance"`
+	Status   Status `bson:"status" json:"status"`
+	Error    string `bson:"error,omitempty" json:"error,omitempty"`
+	// ScheduledAt is the time a scheduled run was due
+	ScheduledAt *time.Time `bson:"scheduledAt,omitempty" json:"scheduledAt,omitempty"`
+	StartedAt   time.Time  `bson:"startedAt" json:"startedAt"`
+	FinishedAt  *time.Time `bson:"finishedAt,omitempty" json:"finishedAt,omitempty"`
+	DurationMs  int64      `bson:"durationMs,omitempty" json:"durationMs,omitempty"`
+	// ExpiresAt is when the run is removed from the job's history
+	ExpiresAt time.Time `bson:"expiresAt" json:"-"`
+}
+
+// Locker holds the leases jobs run under
+type Locker interface {
+	// Acquire takes or renews a lease, reporting false if another holder owns it
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// Release gives up a lease held by holder
+	Release(ctx context.Context, name, holder string) error
+}
+
+// RunStore records the runs of jobs
+type RunStore interface {
+	// Create records a started run, returning ErrRunExists if a run with its
+	// ID was already recorded
+	Create(ctx context.Context, run *Run) error
+	// Save records the outcome of a run
+	Save(ctx context.Context, run *Run) error
+}
+
+// Scheduler runs registered jobs on their schedule and on demand
+type Scheduler struct {
+	locks     Locker
+	runs      RunStore
+	instance  string
+	retention time.Duration
+
+	mu   sync.RWMutex
+	jobs []*Job
+	// ctx is the context of the running scheduler, which cancels manual runs
+	// when it stops
+	ctx context.Context
+	wg  sync.WaitGroup
+}
+
+// NewScheduler creates a new scheduler for the replica instance, keeping
+// the runs of jobs for retention
+func NewScheduler(locks Locker, runs RunStore, instance string, retention time.Duration) *Scheduler {
+	return &Scheduler{
+		locks:     locks,
+		runs:      runs,
+		instance:  instance,
+		retention: retention,
+	}
+}
+
+// Register adds a job. Jobs are registered before the scheduler runs.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil || job.Timeout <= 0 {
+		return fmt.Errorf("job %q needs a name, schedule, timeout and function", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, registered := range s.jobs {
+		if registered.Name == job.Name {
+			return fmt.Errorf("job %q is already registered", job.Name)
+		}
+	}
+	s.jobs = append(s.jobs, &job)
+	return nil
+}
+
+// Jobs returns the registered jobs, in the order they were registered
+func (s *Scheduler) Jobs() []*Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]*Job(nil), s.jobs...)
+}
+
+// Job returns a registered job by name
+func (s *Scheduler) Job(name string) (*Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, job := range s.jobs {
+		if job.Name == name {
+			return job, true
+		}
+	}
+	return nil, false
+}
+
+// Run runs every registered job on its schedule until ctx is cancelled, then
+// waits for the runs in progress, manual ones included
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	jobs := s.Jobs()
+	var loops sync.WaitGroup
+	for _, job := range jobs {
+		loops.Add(1)
+		go func(job *Job) {
+			defer loops.Done()
+			s.schedule(ctx, job)
+		}(job)
+	}
+	log.Info().Str("instanceId", s.instance).Int("jobs", len(jobs)).Msg("Job scheduler started")
+
+	loops.Wait()
+	s.wg.Wait()
+	log.Info().Str("instanceId", s.instance).Msg("Job scheduler stopped")
+}
+
+// schedule runs a job each time it's due until ctx is cancelled
+func (s *Scheduler) schedule(ctx context.Context, job *Job) {
+	for {
+		next := job.Schedule.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// Replicas record a scheduled run under the same ID, so only the
+		// first to start it runs it
+		run := s.newRun(job, TriggerSchedule, "")
+		run.ID = job.Name + "@" + next.Format(time.RFC3339)
+		run.ScheduledAt = &next
+		if err := s.start(ctx, job, run); err != nil {
+			if !errors.Is(err, ErrJobRunning) && !errors.Is(err, ErrRunExists) {
+				log.Error().Err(err).Str("job", job.Name).Msg("Failed to start scheduled job")
+			}
+			continue
+		}
+		s.finish(ctx, job, run)
+	}
+}
+
+// Trigger starts a run of a job outside its schedule and returns it. The run
+// continues in the background with ctx, which should outlive the request
+// that triggered it, until the job's timeout passes or the scheduler stops.
+func (s *Scheduler) Trigger(ctx context.Context, name, triggeredBy string) (*Run, error) {
+	job, ok := s.Job(name)
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+
+	run := s.newRun(job, TriggerManual, triggeredBy)
+	if err := s.start(ctx, job, run); err != nil {
+		return nil, err
+	}
+
+	started := *run
+	runCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(s.context(), cancel)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		defer stop()
+		s.finish(runCtx, job, run)
+	}()
+	return &started, nil
+}
+
+// context returns the context of the running scheduler
+func (s *Scheduler) context() context.Context {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// newRun creates a run of a job that is starting
+func (s *Scheduler) newRun(job *Job, trigger Trigger, triggeredBy string) *Run {
+	now := time.Now()
+	return &Run{
+		ID:          uuid.New().String(),
+		Job:         job.Name,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		Instance:    s.instance,
+		Status:      StatusRunning,
+		StartedAt:   now,
+		ExpiresAt:   now.Add(s.retention),
+	}
+}
+
+// start takes the job's lease for a run and records it
+func (s *Scheduler) start(ctx context.Context, job *Job, run *Run) error {
+	acquired, err := s.locks.Acquire(ctx, lockName(job), run.ID, job.Timeout+lockMargin)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return ErrJobRunning
+	}
+
+	if err := s.runs.Create(ctx, run); err != nil {
+		s.release(job, run)
+		return err
+	}
+	return nil
+}
+
+// finish runs a started run, records its outcome and releases its lease
+func (s *Scheduler) finish(ctx context.Context, job *Job, run *Run) {
+	defer s.release(job, run)
+
+	runCtx, cancel := context.WithTimeout(ctx, job.Timeout)
+	err := execute(runCtx, job)
+	cancel()
+
+	now := time.Now()
+	run.FinishedAt = &now
+	run.DurationMs = now.Sub(run.StartedAt).Milliseconds()
+	run.Status = StatusSucceeded
+	event := log.Info()
+	if err != nil {
+		run.Status = StatusFailed
+		run.Error = err.Error()
+		event = log.Error().Err(err)
+	}
+	event.Str("job", job.Name).Str("runId", run.ID).Str("trigger", string(run.Trigger)).
+		Int64("durationMs", run.DurationMs).Msg("Job finished")
+
+	// Record the outcome even if the scheduler is stopping
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), lockMargin)
+	defer cancel()
+	if err := s.runs.Save(saveCtx, run); err != nil {
+		log.Error().Err(err).Str("job", job.Name).Str("runId", run.ID).Msg("Failed to record job run")
+	}
+}
+
+// release gives up the lease of a run's job, with a fresh context so another
+// replica can run the job right away
+func (s *Scheduler) release(job *Job, run *Run) {
+	ctx, cancel := context.WithTimeout(context.Background(), lockMargin)
+	defer cancel()
+	if err := s.locks.Release(ctx, lockName(job), run.ID); err != nil {
+		log.Error().Err(err).Str("job", job.Name).Msg("Failed to release job lease")
+	}
+}
+
+// execute runs a job, failing the run if the job panics
+func execute(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// lockName returns the name of the lease a job runs under
+func lockName(job *Job) string {
+	return "job:" + job.Name
+}
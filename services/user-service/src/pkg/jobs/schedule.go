@@ -0,0 +1,185 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleMacros are the shorthands accepted for common cron expressions
+var scheduleMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is when a job runs: a five field cron expression (minute, hour,
+// day of month, month, day of week), one of its @ shorthands such as @daily,
+// or "@every <duration>". Schedules are in UTC, and runs of @every
+// schedules start at multiples of the duration since the Unix epoch, so
+// every replica computes the same times.
+type Schedule struct {
+	spec   string
+	every  time.Duration
+	minute [60]bool
+	hour   [24]bool
+	dom    [32]bool
+	month  [13]bool
+	dow    [7]bool
+	// anyDom and anyDow are set for unrestricted day fields; when both are
+	// restricted, a day matching either runs the job, as in cron
+	anyDom bool
+	anyDow bool
+}
+
+// ParseSchedule parses a schedule
+func ParseSchedule(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	s := &Schedule{spec: spec}
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", spec, err)
+		}
+		if every < time.Minute || every%time.Minute != 0 {
+			return nil, fmt.Errorf("schedule %q: interval must be a whole number of minutes", spec)
+		}
+		s.every = every
+		return s, nil
+	}
+
+	expr := spec
+	if macro, ok := scheduleMacros[spec]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	dow := make([]bool, 8)
+	for i, field := range []struct {
+		set      []bool
+		min, max int
+	}{
+		{s.minute[:], 0, 59},
+		{s.hour[:], 0, 23},
+		{s.dom[:], 1, 31},
+		{s.month[:], 1, 12},
+		{dow, 0, 7},
+	} {
+		if err := parseField(fields[i], field.set, field.min, field.max); err != nil {
+			return nil, fmt.Errorf("schedule %q: field %d: %w", spec, i+1, err)
+		}
+	}
+	// 7 is Sunday too
+	copy(s.dow[:], dow[:7])
+	s.dow[0] = s.dow[0] || dow[7]
+	s.anyDom = fields[2] == "*" || fields[2] == "?"
+	s.anyDow = fields[4] == "*" || fields[4] == "?"
+
+	if s.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, fmt.Errorf("schedule %q never runs", spec)
+	}
+	return s, nil
+}
+
+// parseField marks the values of a comma separated list of values, ranges
+// and steps, such as "1,15", "9-17" or "*/5"
+func parseField(field string, set []bool, min, max int) error {
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		low, high := min, max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(lowPart); err != nil {
+				return fmt.Errorf("invalid value %q", lowPart)
+			}
+			if high, err = strconv.Atoi(highPart); err != nil {
+				return fmt.Errorf("invalid value %q", highPart)
+			}
+		default:
+			value, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return fmt.Errorf("invalid value %q", rangePart)
+			}
+			low, high = value, value
+			if hasStep {
+				high = max
+			}
+		}
+
+		if low < min || high > max || low > high {
+			return fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+		for value := low; value <= high; value += step {
+			set[value] = true
+		}
+	}
+	return nil
+}
+
+// String returns the schedule as it was written
+func (s *Schedule) String() string {
+	return s.spec
+}
+
+// Next returns the first time after t the schedule runs at, or the zero
+// time if it never does
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC()
+	if s.every > 0 {
+		return t.Truncate(s.every).Add(s.every)
+	}
+
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := next.AddDate(5, 0, 0)
+	for next.Before(limit) {
+		switch {
+		case !s.month[next.Month()]:
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !s.hour[next.Hour()]:
+			next = next.Truncate(time.Hour).Add(time.Hour)
+		case !s.minute[next.Minute()]:
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches checks if the schedule runs on t's day
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom[t.Day()]
+	dow := s.dow[t.Weekday()]
+	switch {
+	case s.anyDom && s.anyDow:
+		return true
+	case s.anyDom:
+		return dow
+	case s.anyDow:
+		return dom
+	default:
+		return dom || dow
+	}
+}
@@ -0,0 +1,135 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// Security protocols, as named by librdkafka
+const (
+	SecurityProtocolPlaintext     = "plaintext"
+	SecurityProtocolSSL           = "ssl"
+	SecurityProtocolSASLPlaintext = "sasl_plaintext"
+	SecurityProtocolSASLSSL       = "sasl_ssl"
+)
+
+// saslMechanisms are the supported SASL mechanisms
+var saslMechanisms = map[string]bool{
+	"PLAIN":         true,
+	"SCRAM-SHA-256": true,
+	"SCRAM-SHA-512": true,
+}
+
+// Brokers returns the configured broker addresses. Entries may themselves be
+// comma-separated lists, as KAFKA_BROKERS usually is.
+func Brokers(cfg *config.KafkaConfig) []string {
+	brokers := make([]string, 0, len(cfg.Brokers))
+	for _, entry := range cfg.Brokers {
+		for _, broker := range strings.Split(entry, ",") {
+			if broker = strings.TrimSpace(broker); broker != "" {
+				brokers = append(brokers, broker)
+			}
+		}
+	}
+	return brokers
+}
+
+// defaultMetadataTimeout bounds fetching the cluster metadata when the
+// context has no deadline
+const defaultMetadataTimeout = 5 * time.Second
+
+// metadataClient is a Kafka client that can fetch the cluster metadata
+type metadataClient interface {
+	GetMetadata(topic *string, allTopics bool, timeoutMs int) (*kafka.Metadata, error)
+}
+
+// pingBrokers checks the brokers are reachable by fetching the cluster
+// metadata, within the context's deadline
+func pingBrokers(ctx context.Context, client metadataClient) error {
+	timeout := defaultMetadataTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if timeout <= 0 {
+		return context.DeadlineExceeded
+	}
+
+	metadata, err := client.GetMetadata(nil, false, int(timeout.Milliseconds()))
+	if err != nil {
+		return fmt.Errorf("error fetching Kafka metadata: %w", err)
+	}
+	if len(metadata.Brokers) == 0 {
+		return errors.New("no Kafka brokers available")
+	}
+	return nil
+}
+
+// clientConfig returns the settings shared by producers and consumers: the
+// full broker list, the client ID, and authentication and TLS settings
+func clientConfig(cfg *config.KafkaConfig) (kafka.ConfigMap, error) {
+	brokers := Brokers(cfg)
+	if len(brokers) == 0 {
+		return nil, errors.New("no Kafka brokers configured")
+	}
+
+	configMap := kafka.ConfigMap{
+		"bootstrap.servers": strings.Join(brokers, ","),
+		"client.id":         cfg.ClientID,
+	}
+
+	security := cfg.Security
+	protocol := strings.ToLower(security.Protocol)
+	if protocol == "" {
+		protocol = SecurityProtocolPlaintext
+	}
+
+	switch protocol {
+	case SecurityProtocolPlaintext, SecurityProtocolSSL, SecurityProtocolSASLPlaintext, SecurityProtocolSASLSSL:
+		configMap["security.protocol"] = protocol
+	default:
+		return nil, fmt.Errorf("unknown Kafka security protocol %q", security.Protocol)
+	}
+
+	// SASL authentication
+	if protocol == SecurityProtocolSASLPlaintext || protocol == SecurityProtocolSASLSSL {
+		mechanism := strings.ToUpper(security.SASLMechanism)
+		if !saslMechanisms[mechanism] {
+			return nil, fmt.Errorf("unsupported Kafka SASL mechanism %q", security.SASLMechanism)
+		}
+		if security.SASLUsername == "" || security.SASLPassword == "" {
+			return nil, errors.New("kafka SASL username and password are required")
+		}
+
+		configMap["sasl.mechanisms"] = mechanism
+		configMap["sasl.username"] = security.SASLUsername
+		configMap["sasl.password"] = security.SASLPassword
+	}
+
+	// TLS; without a CA file the system trust store is used
+	if protocol == SecurityProtocolSSL || protocol == SecurityProtocolSASLSSL {
+		if security.CAFile != "" {
+			configMap["ssl.ca.location"] = security.CAFile
+		}
+		if security.CertFile != "" || security.KeyFile != "" {
+			if security.CertFile == "" || security.KeyFile == "" {
+				return nil, errors.New("kafka TLS client certificate and key must be set together")
+			}
+			configMap["ssl.certificate.location"] = security.CertFile
+			configMap["ssl.key.location"] = security.KeyFile
+			if security.KeyPassword != "" {
+				configMap["ssl.key.password"] = security.KeyPassword
+			}
+		}
+		if security.SkipVerify {
+			configMap["enable.ssl.certificate.verification"] = false
+		}
+	}
+
+	return configMap, nil
+}
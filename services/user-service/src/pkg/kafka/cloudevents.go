@@ -0,0 +1,215 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+// Event formats the producer can emit
+const (
+	// EventFormatLegacy emits the service's own Event envelope
+	EventFormatLegacy = "legacy"
+	// EventFormatStructured emits CloudEvents 1.0 in structured mode: the
+	// whole event, including data, is the JSON message value
+	EventFormatStructured = "cloudevents-structured"
+	// EventFormatBinary emits CloudEvents 1.0 in binary mode: attributes
+	// are ce_ headers and the message value is the event data
+	EventFormatBinary = "cloudevents-binary"
+)
+
+// CloudEvents constants
+const (
+	CloudEventsSpecVersion      = "1.0"
+	cloudEventsContentType      = "application/cloudevents+json"
+	cloudEventsDataContentType  = "application/json"
+	cloudEventsHeaderPrefix     = "ce_"
+	cloudEventsContentTypeKey   = "content-type"
+	cloudEventsSpecVersionKey   = cloudEventsHeaderPrefix + "specversion"
+	cloudEventsCorrelationIDKey = "correlationid"
+	cloudEventsSchemaVersionKey = "schemaversion"
+)
+
+// cloudEvent is a CloudEvents 1.0 event in structured mode. The event's
+// correlation ID and schema version are carried as extension attributes.
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            EventType       `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	CorrelationID   string          `json:"correlationid,omitempty"`
+	SchemaVersion   int             `json:"schemaversion,omitempty"`
+}
+
+// ValidEventFormat checks if a format is a known event format
+func ValidEventFormat(format string) bool {
+	switch format {
+	case EventFormatLegacy, EventFormatStructured, EventFormatBinary:
+		return true
+	}
+	return false
+}
+
+// encodeEvent encodes an event as a Kafka message value and headers in the given format
+func encodeEvent(format string, event Event) ([]byte, []kafka.Header, error) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch format {
+	case EventFormatStructured:
+		value, err := json.Marshal(cloudEvent{
+			SpecVersion:     CloudEventsSpecVersion,
+			ID:              event.ID,
+			Source:          event.Source,
+			Type:            event.Type,
+			Subject:         event.Subject,
+			Time:            event.Time,
+			DataContentType: cloudEventsDataContentType,
+			Data:            data,
+			CorrelationID:   event.CorrelationID,
+			SchemaVersion:   event.SchemaVersion,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return value, []kafka.Header{
+			{Key: cloudEventsContentTypeKey, Value: []byte(cloudEventsContentType)},
+		}, nil
+
+	case EventFormatBinary:
+		headers := []kafka.Header{
+			{Key: cloudEventsContentTypeKey, Value: []byte(cloudEventsDataContentType)},
+			{Key: cloudEventsSpecVersionKey, Value: []byte(CloudEventsSpecVersion)},
+			{Key: cloudEventsHeaderPrefix + "id", Value: []byte(event.ID)},
+			{Key: cloudEventsHeaderPrefix + "source", Value: []byte(event.Source)},
+			{Key: cloudEventsHeaderPrefix + "type", Value: []byte(event.Type)},
+			{Key: cloudEventsHeaderPrefix + "time", Value: []byte(event.Time.Format(time.RFC3339Nano))},
+		}
+		if event.Subject != "" {
+			headers = append(headers, kafka.Header{Key: cloudEventsHeaderPrefix + "subject", Value: []byte(event.Subject)})
+		}
+		if event.CorrelationID != "" {
+			headers = append(headers, kafka.Header{Key: cloudEventsHeaderPrefix + cloudEventsCorrelationIDKey, Value: []byte(event.CorrelationID)})
+		}
+		if event.SchemaVersion != 0 {
+			headers = append(headers, kafka.Header{Key: cloudEventsHeaderPrefix + cloudEventsSchemaVersionKey, Value: []byte(strconv.Itoa(event.SchemaVersion))})
+		}
+		return data, headers, nil
+
+	default:
+		value, err := json.Marshal(event)
+		if err != nil {
+			return nil, nil, err
+		}
+		return value, nil, nil
+	}
+}
+
+// decodeEvent decodes a Kafka message in any supported format: the legacy
+// Event envelope, or CloudEvents in structured or binary mode
+func decodeEvent(msg *kafka.Message) (Event, error) {
+	if headerValue(msg.Headers, cloudEventsSpecVersionKey) != "" {
+		return decodeBinaryEvent(msg)
+	}
+
+	var ce cloudEvent
+	if err := json.Unmarshal(msg.Value, &ce); err != nil {
+		return Event{}, err
+	}
+	if ce.SpecVersion == "" {
+		// Legacy envelope
+		var event Event
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			return Event{}, err
+		}
+		return event, nil
+	}
+	if ce.SpecVersion != CloudEventsSpecVersion {
+		return Event{}, fmt.Errorf("unsupported CloudEvents spec version %q", ce.SpecVersion)
+	}
+
+	event := Event{
+		ID:            ce.ID,
+		Type:          ce.Type,
+		Source:        ce.Source,
+		Subject:       ce.Subject,
+		Time:          ce.Time,
+		SchemaVersion: ce.SchemaVersion,
+		CorrelationID: ce.CorrelationID,
+	}
+	if err := decodeData(ce.DataContentType, ce.Data, &event); err != nil {
+		return Event{}, err
+	}
+
+	return event, nil
+}
+
+// decodeBinaryEvent decodes a CloudEvents binary mode message
+func decodeBinaryEvent(msg *kafka.Message) (Event, error) {
+	specVersion := headerValue(msg.Headers, cloudEventsSpecVersionKey)
+	if specVersion != CloudEventsSpecVersion {
+		return Event{}, fmt.Errorf("unsupported CloudEvents spec version %q", specVersion)
+	}
+
+	event := Event{
+		ID:            headerValue(msg.Headers, cloudEventsHeaderPrefix+"id"),
+		Type:          EventType(headerValue(msg.Headers, cloudEventsHeaderPrefix+"type")),
+		Source:        headerValue(msg.Headers, cloudEventsHeaderPrefix+"source"),
+		Subject:       headerValue(msg.Headers, cloudEventsHeaderPrefix+"subject"),
+		CorrelationID: headerValue(msg.Headers, cloudEventsHeaderPrefix+cloudEventsCorrelationIDKey),
+	}
+
+	if t := headerValue(msg.Headers, cloudEventsHeaderPrefix+"time"); t != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, t)
+		if err != nil {
+			return Event{}, fmt.Errorf("invalid CloudEvents time %q: %w", t, err)
+		}
+		event.Time = parsed
+	}
+	if v := headerValue(msg.Headers, cloudEventsHeaderPrefix+cloudEventsSchemaVersionKey); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			return Event{}, fmt.Errorf("invalid CloudEvents schema version %q: %w", v, err)
+		}
+		event.SchemaVersion = version
+	}
+
+	if err := decodeData(headerValue(msg.Headers, cloudEventsContentTypeKey), msg.Value, &event); err != nil {
+		return Event{}, err
+	}
+
+	return event, nil
+}
+
+// decodeData sets the data of an event, decoding JSON data the same way the
+// legacy envelope does so handlers see the same value in every format
+func decodeData(contentType string, data []byte, event *Event) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if contentType != "" && !strings.HasPrefix(contentType, cloudEventsDataContentType) {
+		return fmt.Errorf("unsupported CloudEvents data content type %q", contentType)
+	}
+
+	return json.Unmarshal(data, &event.Data)
+}
+
+// headerValue returns the value of a Kafka message header
+func headerValue(headers []kafka.Header, key string) string {
+	for _, header := range headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
@@ -2,39 +2,98 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/rs/zerolog/log"
 	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/slo"
 )
 
 // Handler is a function that handles a Kafka message
 type Handler func(ctx context.Context, event Event) error
 
-// Consumer is a Kafka consumer
+// IdempotencyStore records handled events so redelivered copies are skipped
+type IdempotencyStore interface {
+	// IsProcessed checks if an event has already been handled
+	IsProcessed(ctx context.Context, eventID string) (bool, error)
+	// MarkProcessed records that an event has been handled
+	MarkProcessed(ctx context.Context, eventID, topic, eventType string) error
+}
+
+// EventConsumer routes consumed events to their handlers
+type EventConsumer interface {
+	// UseIdempotencyStore skips events the store has recorded as handled
+	UseIdempotencyStore(store IdempotencyStore)
+	// RegisterHandler registers a handler for an event type of a topic; the
+	// "*" event type handles the topic's events without a handler of their own
+	RegisterHandler(topic string, eventType EventType, handler Handler)
+}
+
+var _ EventConsumer = (*Consumer)(nil)
+
+// errInvalidMessage marks messages that can never be handled, so they are
+// committed instead of retried
+var errInvalidMessage = errors.New("invalid message")
+
+// Consumer is a Kafka consumer. Messages are handled by a pool of workers;
+// messages with the same key always go to the same worker, so they are
+// handled in order. Offsets are committed manually once every earlier
+// message of the partition has been handled, so a crash redelivers any
+// message that wasn't.
 type Consumer struct {
 	consumer      *kafka.Consumer
 	config        *config.KafkaConfig
 	handlers      map[string]map[EventType]Handler
 	shutdownCh    chan struct{}
 	subscriptions []string
+	idempotency   IdempotencyStore
+
+	workers  []chan *kafka.Message
+	inFlight chan struct{}
+	wg       sync.WaitGroup
+	loopDone chan struct{}
+	running  atomic.Bool
+	workCtx  context.Context
+	stopWork context.CancelFunc
+
+	offsetsMu sync.Mutex
+	offsets   map[partitionKey]*partitionOffsets
+}
+
+// partitionKey identifies a topic partition
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+// partitionOffsets tracks the in-flight messages of a partition so only
+// offsets below the oldest unhandled message are committed
+type partitionOffsets struct {
+	pending []kafka.Offset
+	done    map[kafka.Offset]bool
 }
 
 // NewConsumer creates a new Kafka consumer
 func NewConsumer(cfg *config.KafkaConfig) (*Consumer, error) {
-	// Create Kafka consumer
-	c, err := kafka.NewConsumer(&kafka.ConfigMap{
-		"bootstrap.servers":  cfg.Brokers[0], // Use the first broker
-		"group.id":           cfg.GroupID,
-		"client.id":          cfg.ClientID,
-		"auto.offset.reset":  cfg.AutoOffsetReset,
-		"enable.auto.commit": true,
-	})
+	configMap, err := clientConfig(cfg)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid Kafka client configuration")
+		return nil, err
+	}
+	configMap["group.id"] = cfg.GroupID
+	configMap["auto.offset.reset"] = cfg.AutoOffsetReset
+	configMap["enable.auto.commit"] = false
 
+	// Create Kafka consumer
+	c, err := kafka.NewConsumer(&configMap)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create Kafka consumer")
 		return nil, err
@@ -42,15 +101,33 @@ func NewConsumer(cfg *config.KafkaConfig) (*Consumer, error) {
 
 	log.Info().Msg("Kafka consumer created")
 
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	maxInFlight := cfg.MaxInFlight
+	if maxInFlight < workers {
+		maxInFlight = workers
+	}
+
 	return &Consumer{
 		consumer:      c,
 		config:        cfg,
 		handlers:      make(map[string]map[EventType]Handler),
 		shutdownCh:    make(chan struct{}),
 		subscriptions: make([]string, 0),
+		workers:       make([]chan *kafka.Message, workers),
+		inFlight:      make(chan struct{}, maxInFlight),
+		offsets:       make(map[partitionKey]*partitionOffsets),
 	}, nil
 }
 
+// UseIdempotencyStore makes the consumer skip events the store has already
+// seen. It must be called before Start.
+func (c *Consumer) UseIdempotencyStore(store IdempotencyStore) {
+	c.idempotency = store
+}
+
 // RegisterHandler registers a handler for a specific event type
 func (c *Consumer) RegisterHandler(topic string, eventType EventType, handler Handler) {
 	// Add topic to subscriptions if it's not already there
@@ -79,58 +156,270 @@ func (c *Consumer) RegisterHandler(topic string, eventType EventType, handler Ha
 func (c *Consumer) Start(ctx context.Context) error {
 	// Make sure we have subscriptions
 	if len(c.subscriptions) == 0 {
-		log.Warn().Msg("No subscriptions registered, consumer won't start")
+		logger.Ctx(ctx).Warn().Msg("No subscriptions registered, consumer won't start")
 		return errors.New("no subscriptions registered")
 	}
 
 	// Subscribe to topics
-	if err := c.consumer.SubscribeTopics(c.subscriptions, nil); err != nil {
-		log.Error().Err(err).Strs("topics", c.subscriptions).Msg("Failed to subscribe to topics")
+	if err := c.consumer.SubscribeTopics(c.subscriptions, c.rebalance); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Strs("topics", c.subscriptions).Msg("Failed to subscribe to topics")
 		return err
 	}
 
-	log.Info().Strs("topics", c.subscriptions).Msg("Subscribed to topics")
+	logger.Ctx(ctx).Info().Strs("topics", c.subscriptions).Msg("Subscribed to topics")
+
+	// Handlers run under their own context, with the values of ctx, so
+	// in-flight messages can finish after ctx is cancelled while the
+	// consumer drains
+	c.workCtx, c.stopWork = context.WithCancel(context.WithoutCancel(ctx))
+	for i := range c.workers {
+		c.workers[i] = make(chan *kafka.Message, cap(c.inFlight))
+		c.wg.Add(1)
+		go c.work(c.workers[i])
+	}
+
+	logger.Ctx(ctx).Info().Int("workers", len(c.workers)).Int("max_in_flight", cap(c.inFlight)).Msg("Started consumer workers")
 
 	// Start consumer loop
+	c.loopDone = make(chan struct{})
+	c.running.Store(true)
 	go c.consume(ctx)
 
 	return nil
 }
 
-// Close closes the Kafka consumer
+// Running checks if the consumer has started and is still reading messages
+func (c *Consumer) Running() bool {
+	return c.running.Load()
+}
+
+// Close stops reading messages, waits up to the drain timeout for in-flight
+// messages to be handled, and closes the Kafka consumer
 func (c *Consumer) Close() {
 	close(c.shutdownCh)
+
+	if c.loopDone != nil {
+		<-c.loopDone
+
+		// No more messages are dispatched once the loop has stopped
+		for _, ch := range c.workers {
+			close(ch)
+		}
+
+		drained := make(chan struct{})
+		go func() {
+			c.wg.Wait()
+			close(drained)
+		}()
+
+		select {
+		case <-drained:
+			log.Info().Msg("Kafka consumer drained")
+		case <-time.After(c.config.DrainTimeout):
+			log.Warn().Dur("timeout", c.config.DrainTimeout).
+				Msg("Timed out draining Kafka consumer, unhandled messages will be redelivered")
+			c.stopWork()
+			<-drained
+		}
+		c.stopWork()
+	}
+
 	c.consumer.Close()
 	log.Info().Msg("Kafka consumer closed")
 }
 
-// consume consumes messages from Kafka
+// consume reads messages from Kafka and dispatches them to the workers
 func (c *Consumer) consume(ctx context.Context) {
+	defer close(c.loopDone)
+	defer c.running.Store(false)
+
 	for {
 		select {
 		case <-ctx.Done():
-			log.Info().Msg("Context cancelled, stopping consumer")
+			logger.Ctx(ctx).Info().Msg("Context cancelled, stopping consumer")
 			return
 		case <-c.shutdownCh:
-			log.Info().Msg("Shutdown signal received, stopping consumer")
+			logger.Ctx(ctx).Info().Msg("Shutdown signal received, stopping consumer")
 			return
 		default:
 			// Poll for messages
 			msg, err := c.consumer.ReadMessage(100 * time.Millisecond)
 			if err != nil {
 				// Ignore timeout errors
-				if err.(kafka.Error).Code() == kafka.ErrTimedOut {
+				if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.Code() == kafka.ErrTimedOut {
 					continue
 				}
-				log.Error().Err(err).Msg("Error reading message")
+				logger.Ctx(ctx).Error().Err(err).Msg("Error reading message")
 				continue
 			}
 
-			// Process message
-			if err := c.processMessage(ctx, msg); err != nil {
-				log.Error().Err(err).Msg("Error processing message")
+			// Track consumer lag
+			if !msg.Timestamp.IsZero() {
+				slo.RecordConsumerLag(time.Since(msg.Timestamp))
+			}
+
+			// Wait for a free in-flight slot; a message read but not
+			// dispatched is redelivered since its offset is never committed
+			select {
+			case c.inFlight <- struct{}{}:
+			case <-ctx.Done():
+				logger.Ctx(ctx).Info().Msg("Context cancelled, stopping consumer")
+				return
+			case <-c.shutdownCh:
+				logger.Ctx(ctx).Info().Msg("Shutdown signal received, stopping consumer")
+				return
 			}
+
+			c.track(msg)
+			c.workers[c.workerFor(msg)] <- msg
+		}
+	}
+}
+
+// workerFor picks the worker of a message by hashing its key. Messages
+// without a key are ordered per partition instead.
+func (c *Consumer) workerFor(msg *kafka.Message) int {
+	h := fnv.New32a()
+	if len(msg.Key) > 0 {
+		h.Write(msg.Key)
+	} else {
+		fmt.Fprintf(h, "%s/%d", *msg.TopicPartition.Topic, msg.TopicPartition.Partition)
+	}
+	return int(h.Sum32() % uint32(len(c.workers)))
+}
+
+// work handles the messages dispatched to one worker, in order
+func (c *Consumer) work(messages <-chan *kafka.Message) {
+	defer c.wg.Done()
+
+	for msg := range messages {
+		if c.handle(msg) {
+			c.commit(msg)
+		}
+		<-c.inFlight
+	}
+}
+
+// handle handles a message, retrying failures with a backoff. It reports
+// false if the consumer stopped before the message was handled or given up
+// on, in which case its offset must not be committed.
+func (c *Consumer) handle(msg *kafka.Message) bool {
+	for attempt := 1; ; attempt++ {
+		err := c.processMessage(c.workCtx, msg)
+		if err == nil || errors.Is(err, errInvalidMessage) {
+			return true
+		}
+		if c.workCtx.Err() != nil {
+			return false
+		}
+
+		log.Error().Err(err).Int("attempt", attempt).Msg("Error processing message")
+		if attempt >= c.config.MaxHandlerAttempts {
+			log.Error().
+				Str("topic", *msg.TopicPartition.Topic).
+				Int32("partition", msg.TopicPartition.Partition).
+				Int64("offset", int64(msg.TopicPartition.Offset)).
+				Int("attempts", attempt).
+				Msg("Giving up on message")
+			return true
 		}
+
+		// Back off before trying again; on shutdown the message is left
+		// uncommitted so it is redelivered
+		select {
+		case <-c.shutdownCh:
+			return false
+		case <-c.workCtx.Done():
+			return false
+		case <-time.After(time.Duration(attempt) * time.Second):
+		}
+	}
+}
+
+// rebalance forgets the in-flight offsets of the partitions assigned to or
+// revoked from the consumer. Messages of a revoked partition that are still
+// being handled then commit nothing over its new owner, and an assigned
+// partition is tracked afresh from its committed offset. The default
+// assignment is applied after the callback returns.
+func (c *Consumer) rebalance(_ *kafka.Consumer, event kafka.Event) error {
+	var partitions []kafka.TopicPartition
+	switch e := event.(type) {
+	case kafka.AssignedPartitions:
+		partitions = e.Partitions
+	case kafka.RevokedPartitions:
+		partitions = e.Partitions
+	default:
+		return nil
+	}
+
+	c.offsetsMu.Lock()
+	for _, partition := range partitions {
+		delete(c.offsets, partitionKey{topic: *partition.Topic, partition: partition.Partition})
+	}
+	c.offsetsMu.Unlock()
+
+	log.Info().Str("event", event.String()).Int("partitions", len(partitions)).Msg("Kafka consumer rebalanced")
+	return nil
+}
+
+// track records a message as in flight for its partition
+func (c *Consumer) track(msg *kafka.Message) {
+	key := partitionKey{topic: *msg.TopicPartition.Topic, partition: msg.TopicPartition.Partition}
+
+	c.offsetsMu.Lock()
+	defer c.offsetsMu.Unlock()
+
+	offsets, ok := c.offsets[key]
+	if !ok {
+		offsets = &partitionOffsets{done: make(map[kafka.Offset]bool)}
+		c.offsets[key] = offsets
+	}
+	offsets.pending = append(offsets.pending, msg.TopicPartition.Offset)
+}
+
+// commit marks a message handled and commits its partition up to the oldest
+// message that is still in flight
+func (c *Consumer) commit(msg *kafka.Message) {
+	key := partitionKey{topic: *msg.TopicPartition.Topic, partition: msg.TopicPartition.Partition}
+
+	c.offsetsMu.Lock()
+	offsets, ok := c.offsets[key]
+	if !ok {
+		c.offsetsMu.Unlock()
+		return
+	}
+	// Messages read before their partition was revoked aren't tracked
+	// anymore
+	i := sort.Search(len(offsets.pending), func(i int) bool {
+		return offsets.pending[i] >= msg.TopicPartition.Offset
+	})
+	if i == len(offsets.pending) || offsets.pending[i] != msg.TopicPartition.Offset {
+		c.offsetsMu.Unlock()
+		return
+	}
+	offsets.done[msg.TopicPartition.Offset] = true
+
+	next := kafka.Offset(-1)
+	for len(offsets.pending) > 0 && offsets.done[offsets.pending[0]] {
+		next = offsets.pending[0] + 1
+		delete(offsets.done, offsets.pending[0])
+		offsets.pending = offsets.pending[1:]
+	}
+	c.offsetsMu.Unlock()
+
+	if next < 0 {
+		return
+	}
+
+	partition := msg.TopicPartition
+	partition.Offset = next
+	if _, err := c.consumer.CommitOffsets([]kafka.TopicPartition{partition}); err != nil {
+		log.Error().
+			Err(err).
+			Str("topic", key.topic).
+			Int32("partition", key.partition).
+			Int64("offset", int64(next)).
+			Msg("Failed to commit offset")
 	}
 }
 
@@ -139,14 +428,14 @@ func (c *Consumer) processMessage(ctx context.Context, msg *kafka.Message) error
 	topic := *msg.TopicPartition.Topic
 
 	// Parse event
-	var event Event
-	if err := json.Unmarshal(msg.Value, &event); err != nil {
-		log.Error().
+	event, err := decodeEvent(msg)
+	if err != nil {
+		logger.Ctx(ctx).Error().
 			Err(err).
 			Str("topic", topic).
 			Bytes("value", msg.Value).
 			Msg("Failed to unmarshal event")
-		return fmt.Errorf("failed to unmarshal event: %w", err)
+		return fmt.Errorf("%w: failed to unmarshal event: %v", errInvalidMessage, err)
 	}
 
 	// Get event type from header if possible
@@ -166,7 +455,7 @@ func (c *Consumer) processMessage(ctx context.Context, msg *kafka.Message) error
 	// Check if we have a handler for this topic and event type
 	topicHandlers, ok := c.handlers[topic]
 	if !ok {
-		log.Debug().
+		logger.Ctx(ctx).Debug().
 			Str("topic", topic).
 			Str("event_type", string(eventType)).
 			Msg("No handlers for topic")
@@ -177,7 +466,7 @@ func (c *Consumer) processMessage(ctx context.Context, msg *kafka.Message) error
 	if !ok {
 		handler, ok = topicHandlers["*"] // Check for wildcard handler
 		if !ok {
-			log.Debug().
+			logger.Ctx(ctx).Debug().
 				Str("topic", topic).
 				Str("event_type", string(eventType)).
 				Msg("No handler for event type")
@@ -185,6 +474,22 @@ func (c *Consumer) processMessage(ctx context.Context, msg *kafka.Message) error
 		}
 	}
 
+	// Skip events that were already handled before a redelivery
+	if c.idempotency != nil && event.ID != "" {
+		processed, err := c.idempotency.IsProcessed(ctx, event.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check processed event: %w", err)
+		}
+		if processed {
+			logger.Ctx(ctx).Info().
+				Str("topic", topic).
+				Str("event_type", string(eventType)).
+				Str("event_id", event.ID).
+				Msg("Skipping already processed event")
+			return nil
+		}
+	}
+
 	// Get correlation ID if available
 	var correlationID string
 	for _, header := range msg.Headers {
@@ -202,40 +507,45 @@ func (c *Consumer) processMessage(ctx context.Context, msg *kafka.Message) error
 	// Create a context with correlation ID
 	handlerCtx := ctx
 	if correlationID != "" {
-		handlerCtx = context.WithValue(ctx, "correlation_id", correlationID)
+		handlerCtx = logger.WithCorrelationID(ctx, correlationID)
 	}
 
 	// Handle event with logging
-	log.Debug().
+	logger.Ctx(handlerCtx).Debug().
 		Str("topic", topic).
 		Str("event_type", string(eventType)).
 		Str("event_id", event.ID).
-		Str("correlation_id", correlationID).
 		Msg("Processing event")
 
 	startTime := time.Now()
-	err := handler(handlerCtx, event)
+	err = handler(handlerCtx, event)
 	duration := time.Since(startTime)
 
 	if err != nil {
-		log.Error().
+		logger.Ctx(handlerCtx).Error().
 			Err(err).
 			Str("topic", topic).
 			Str("event_type", string(eventType)).
 			Str("event_id", event.ID).
-			Str("correlation_id", correlationID).
 			Dur("duration", duration).
 			Msg("Error handling event")
 		return fmt.Errorf("error handling event: %w", err)
 	}
 
-	log.Debug().
+	logger.Ctx(handlerCtx).Debug().
 		Str("topic", topic).
 		Str("event_type", string(eventType)).
 		Str("event_id", event.ID).
-		Str("correlation_id", correlationID).
 		Dur("duration", duration).
 		Msg("Event processed successfully")
 
+	// Remember the event; if this fails the event is still committed and only
+	// a redelivery after a crash would handle it again
+	if c.idempotency != nil && event.ID != "" {
+		if err := c.idempotency.MarkProcessed(ctx, event.ID, topic, string(eventType)); err != nil {
+			logger.Ctx(handlerCtx).Warn().Err(err).Str("event_id", event.ID).Msg("Failed to mark event processed")
+		}
+	}
+
 	return nil
 }
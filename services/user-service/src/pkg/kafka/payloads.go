@@ -0,0 +1,680 @@
+package kafka
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Payload decoding errors
+var (
+	ErrInvalidPayload           = errors.New("invalid event payload")
+	ErrUnsupportedSchemaVersion = errors.New("unsupported event schema version")
+)
+
+// DefaultSchemaVersion is the schema version of events that don't carry one
+const DefaultSchemaVersion = 1
+
+// schemaVersions holds the current payload schema version of each event type.
+// Bump the version of an event type, and add a new payload struct, whenever
+// its payload changes incompatibly.
+var schemaVersions = map[EventType]int{}
+
+var payloadValidator = validator.New()
+
+// SchemaVersion returns the current payload schema version of an event type
+func SchemaVersion(eventType EventType) int {
+	if version, ok := schemaVersions[eventType]; ok {
+		return version
+	}
+	return DefaultSchemaVersion
+}
+
+// DecodeData decodes the data of an event into a typed payload struct and
+// validates it. Events newer than the schema version this service knows are
+// rejected with ErrUnsupportedSchemaVersion.
+func DecodeData(event Event, out interface{}) error {
+	version := event.SchemaVersion
+	if version == 0 {
+		version = DefaultSchemaVersion
+	}
+	if version > SchemaVersion(event.Type) {
+		return fmt.Errorf("%w: %s v%d", ErrUnsupportedSchemaVersion, event.Type, version)
+	}
+
+	var raw []byte
+	switch data := event.Data.(type) {
+	case nil:
+		return fmt.Errorf("%w: %s has no data", ErrInvalidPayload, event.Type)
+	case json.RawMessage:
+		raw = data
+	default:
+		var err error
+		if raw, err = json.Marshal(data); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+		}
+	}
+
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if err := payloadValidator.Struct(out); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	return nil
+}
+
+// AuthUserCreatedV1 is the payload of the Auth Service user.created event
+type AuthUserCreatedV1 struct {
+	ID        string `json:"id" validate:"required"`
+	Email     string `json:"email" validate:"required,email"`
+	FirstName string `json:"firstName" validate:"required"`
+	LastName  string `json:"lastName" validate:"required"`
+	Role      string `json:"role,omitempty"`
+	SignupIP  string `json:"signupIp,omitempty" validate:"omitempty,ip"`
+}
+
+// AuthUserUpdatedV1 is the payload of the Auth Service user.updated event.
+// Empty fields are left unchanged.
+type AuthUserUpdatedV1 struct {
+	ID        string `json:"id" validate:"required"`
+	Email     string `json:"email,omitempty" validate:"omitempty,email"`
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+	Role      string `json:"role,omitempty"`
+}
+
+// AuthUserDeletedV1 is the payload of the Auth Service user.deleted event
+type AuthUserDeletedV1 struct {
+	ID string `json:"id" validate:"required"`
+}
+
+// AuthUserEmailChangedV1 is the payload of the Auth Service
+// user.email.changed event
+type AuthUserEmailChangedV1 struct {
+	ID       string `json:"id" validate:"required"`
+	OldEmail string `json:"oldEmail,omitempty" validate:"omitempty,email"`
+	NewEmail string `json:"newEmail" validate:"required,email"`
+}
+
+// UserEmailChangedV1 is the payload of the user.email.changed event this
+// service publishes
+type UserEmailChangedV1 struct {
+	UserID    string    `json:"userId" validate:"required"`
+	OldEmail  string    `json:"oldEmail"`
+	NewEmail  string    `json:"newEmail" validate:"required,email"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+// UserPurgedV1 is the payload of the user.purged event, published when a
+// soft deleted user is removed for good along with their memberships
+type UserPurgedV1 struct {
+	ID              string    `json:"id" validate:"required"`
+	UserID          string    `json:"userId" validate:"required"`
+	Email           string    `json:"email"`
+	OrganizationIDs []string  `json:"organizationIds"`
+	TeamIDs         []string  `json:"teamIds"`
+	PurgedBy        string    `json:"purgedBy"`
+	PurgedAt        time.Time `json:"purgedAt"`
+}
+
+// UserEmailChangeRequestedV1 is the payload of the user.email.change.requested
+// event this service publishes for the Auth Service to verify the new email
+type UserEmailChangeRequestedV1 struct {
+	UserID       string    `json:"userId" validate:"required"`
+	CurrentEmail string    `json:"currentEmail"`
+	NewEmail     string    `json:"newEmail" validate:"required,email"`
+	RequestedAt  time.Time `json:"requestedAt"`
+}
+
+// AuthUserEmailChangeConfirmedV1 is the payload of the Auth Service
+// user.email.change.confirmed event, sent once the user verified the new email
+type AuthUserEmailChangeConfirmedV1 struct {
+	ID       string `json:"id" validate:"required"`
+	NewEmail string `json:"newEmail" validate:"required,email"`
+}
+
+// AuthUserTwoFactorChangedV1 is the payload of the Auth Service
+// user.two_factor.changed event
+type AuthUserTwoFactorChangedV1 struct {
+	ID        string    `json:"id" validate:"required"`
+	Enabled   bool      `json:"enabled"`
+	Methods   []string  `json:"methods,omitempty"`
+	ChangedAt time.Time `json:"changedAt" validate:"required"`
+}
+
+// AuthSessionCreatedV1 is the payload of the Auth Service session.created
+// event
+type AuthSessionCreatedV1 struct {
+	ID        string     `json:"id" validate:"required"`
+	UserID    string     `json:"userId" validate:"required"`
+	Device    string     `json:"device,omitempty"`
+	UserAgent string     `json:"userAgent,omitempty"`
+	IPAddress string     `json:"ipAddress,omitempty" validate:"omitempty,ip"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// AuthSessionRefreshedV1 is the payload of the Auth Service session.refreshed
+// event, sent when a session's tokens are refreshed
+type AuthSessionRefreshedV1 struct {
+	ID          string     `json:"id" validate:"required"`
+	UserID      string     `json:"userId" validate:"required"`
+	IPAddress   string     `json:"ipAddress,omitempty" validate:"omitempty,ip"`
+	RefreshedAt time.Time  `json:"refreshedAt"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+}
+
+// AuthSessionTerminatedV1 is the payload of the Auth Service
+// session.terminated event, sent when a session signs out, expires or is
+// revoked
+type AuthSessionTerminatedV1 struct {
+	ID     string `json:"id" validate:"required"`
+	UserID string `json:"userId" validate:"required"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// UserSessionRevokedV1 is the payload of the user.session.revoked event this
+// service publishes for the Auth Service to terminate the session
+type UserSessionRevokedV1 struct {
+	SessionID string    `json:"sessionId" validate:"required"`
+	UserID    string    `json:"userId" validate:"required"`
+	RevokedAt time.Time `json:"revokedAt"`
+}
+
+// UserPresenceChangedV1 is the payload of user.presence.changed. Status is
+// online or offline; OrganizationIDs are the organizations the user was a
+// member of when it changed.
+type UserPresenceChangedV1 struct {
+	UserID          string    `json:"userId" validate:"required"`
+	Status          string    `json:"status" validate:"required,oneof=online offline"`
+	OrganizationIDs []string  `json:"organizationIds"`
+	ChangedAt       time.Time `json:"changedAt"`
+}
+
+// ImpersonationV1 is the payload of the user.impersonation started and ended
+// events
+type ImpersonationV1 struct {
+	SessionID string     `json:"sessionId" validate:"required"`
+	AdminID   string     `json:"adminId" validate:"required"`
+	UserID    string     `json:"userId" validate:"required"`
+	Reason    string     `json:"reason"`
+	ReadOnly  bool       `json:"readOnly"`
+	StartedAt time.Time  `json:"startedAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+	EndedBy   string     `json:"endedBy,omitempty"`
+}
+
+// SignupReviewV1 is the payload of the user.signup flagged, approved and
+// rejected events
+type SignupReviewV1 struct {
+	UserID     string     `json:"userId" validate:"required"`
+	Email      string     `json:"email"`
+	SignupIP   string     `json:"signupIp,omitempty"`
+	Status     string     `json:"status" validate:"required"`
+	Reasons    []string   `json:"reasons,omitempty"`
+	FlaggedAt  time.Time  `json:"flaggedAt"`
+	Decision   string     `json:"decision,omitempty"`
+	ReviewedBy string     `json:"reviewedBy,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
+}
+
+// OrganizationMemberAddedV1 is the payload of organization.member.added
+type OrganizationMemberAddedV1 struct {
+	OrgID     string    `json:"orgId" validate:"required"`
+	OrgName   string    `json:"orgName"`
+	UserID    string    `json:"userId" validate:"required"`
+	UserEmail string    `json:"userEmail,omitempty"`
+	UserName  string    `json:"userName,omitempty"`
+	Role      string    `json:"role" validate:"required"`
+	InvitedBy string    `json:"invitedBy,omitempty"`
+	JoinedAt  time.Time `json:"joinedAt"`
+}
+
+// OrganizationMemberUpdatedV1 is the payload of organization.member.updated
+type OrganizationMemberUpdatedV1 struct {
+	OrgID     string    `json:"orgId" validate:"required"`
+	OrgName   string    `json:"orgName"`
+	UserID    string    `json:"userId" validate:"required"`
+	Role      string    `json:"role" validate:"required"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// OrganizationMemberRemovedV1 is the payload of organization.member.removed
+type OrganizationMemberRemovedV1 struct {
+	OrgID     string    `json:"orgId" validate:"required"`
+	OrgName   string    `json:"orgName"`
+	UserID    string    `json:"userId" validate:"required"`
+	RemovedBy string    `json:"removedBy,omitempty"`
+	RemovedAt time.Time `json:"removedAt"`
+}
+
+// OrganizationMemberBatchV1 is the payload of organization.member.batch. Its
+// lists hold the payloads of the per-member events it replaces.
+type OrganizationMemberBatchV1 struct {
+	OrgID     string                        `json:"orgId" validate:"required"`
+	OrgName   string                        `json:"orgName"`
+	ChangedBy string                        `json:"changedBy,omitempty"`
+	Added     []OrganizationMemberAddedV1   `json:"added" validate:"dive"`
+	Updated   []OrganizationMemberUpdatedV1 `json:"updated" validate:"dive"`
+	Removed   []OrganizationMemberRemovedV1 `json:"removed" validate:"dive"`
+	ChangedAt time.Time                     `json:"changedAt"`
+}
+
+// OrganizationMemberExpiredV1 is the payload of organization.member.expired
+type OrganizationMemberExpiredV1 struct {
+	OrgID     string    `json:"orgId" validate:"required"`
+	OrgName   string    `json:"orgName"`
+	UserID    string    `json:"userId" validate:"required"`
+	Role      string    `json:"role" validate:"required"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	RemovedAt time.Time `json:"removedAt"`
+}
+
+// OrganizationAccessReviewV1 is the payload of
+// organization.access_review.confirmed
+type OrganizationAccessReviewV1 struct {
+	OrgID      string    `json:"orgId" validate:"required"`
+	OrgName    string    `json:"orgName"`
+	UserIDs    []string  `json:"userIds" validate:"required"`
+	ReviewedBy string    `json:"reviewedBy"`
+	ReviewedAt time.Time `json:"reviewedAt"`
+}
+
+// OrganizationGuestV1 is the payload of the organization.guest added,
+// extended and expired events. ChangedBy is member-expiry for expired guests.
+type OrganizationGuestV1 struct {
+	OrgID     string    `json:"orgId" validate:"required"`
+	OrgName   string    `json:"orgName"`
+	UserID    string    `json:"userId" validate:"required"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	ChangedBy string    `json:"changedBy,omitempty"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+// TeamMemberAddedV1 is the payload of team.member.added
+type TeamMemberAddedV1 struct {
+	TeamID    string    `json:"teamId" validate:"required"`
+	TeamName  string    `json:"teamName"`
+	UserID    string    `json:"userId" validate:"required"`
+	Role      string    `json:"role" validate:"required"`
+	InvitedBy string    `json:"invitedBy,omitempty"`
+	JoinedAt  time.Time `json:"joinedAt"`
+}
+
+// TeamMemberUpdatedV1 is the payload of team.member.updated
+type TeamMemberUpdatedV1 struct {
+	TeamID    string    `json:"teamId" validate:"required"`
+	TeamName  string    `json:"teamName"`
+	UserID    string    `json:"userId" validate:"required"`
+	Role      string    `json:"role" validate:"required"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TeamMemberRemovedV1 is the payload of team.member.removed
+type TeamMemberRemovedV1 struct {
+	TeamID    string    `json:"teamId" validate:"required"`
+	TeamName  string    `json:"teamName"`
+	UserID    string    `json:"userId" validate:"required"`
+	RemovedBy string    `json:"removedBy,omitempty"`
+	RemovedAt time.Time `json:"removedAt"`
+}
+
+// TeamMemberBatchV1 is the payload of team.member.batch. Its lists hold the
+// payloads of the per-member events it replaces.
+type TeamMemberBatchV1 struct {
+	TeamID    string                `json:"teamId" validate:"required"`
+	TeamName  string                `json:"teamName"`
+	ChangedBy string                `json:"changedBy,omitempty"`
+	Added     []TeamMemberAddedV1   `json:"added" validate:"dive"`
+	Updated   []TeamMemberUpdatedV1 `json:"updated" validate:"dive"`
+	Removed   []TeamMemberRemovedV1 `json:"removed" validate:"dive"`
+	ChangedAt time.Time             `json:"changedAt"`
+}
+
+// ExpandMemberBatch expands an organization.member.batch or team.member.batch
+// event into the per-member events it replaces, for consumers that handle
+// members one at a time. The expanded events share the batch's subject, time
+// and correlation ID. Other events are returned as they are.
+func ExpandMemberBatch(event Event) ([]Event, error) {
+	var events []Event
+	expand := func(eventType EventType, data interface{}) {
+		events = append(events, Event{
+			ID:            fmt.Sprintf("%s-%d", event.ID, len(events)),
+			Type:          eventType,
+			Source:        event.Source,
+			Subject:       event.Subject,
+			Time:          event.Time,
+			Data:          data,
+			CorrelationID: event.CorrelationID,
+		})
+	}
+
+	switch event.Type {
+	case OrganizationMemberBatch:
+		var batch OrganizationMemberBatchV1
+		if err := DecodeData(event, &batch); err != nil {
+			return nil, err
+		}
+		for _, data := range batch.Added {
+			expand(OrganizationMemberAdded, data)
+		}
+		for _, data := range batch.Updated {
+			expand(OrganizationMemberUpdated, data)
+		}
+		for _, data := range batch.Removed {
+			expand(OrganizationMemberRemoved, data)
+		}
+	case TeamMemberBatch:
+		var batch TeamMemberBatchV1
+		if err := DecodeData(event, &batch); err != nil {
+			return nil, err
+		}
+		for _, data := range batch.Added {
+			expand(TeamMemberAdded, data)
+		}
+		for _, data := range batch.Updated {
+			expand(TeamMemberUpdated, data)
+		}
+		for _, data := range batch.Removed {
+			expand(TeamMemberRemoved, data)
+		}
+	default:
+		return []Event{event}, nil
+	}
+
+	return events, nil
+}
+
+// OwnershipTransferRequestedV1 is the payload of the organization and team
+// ownership.transfer_requested events. Exactly one of OrgID and TeamID is set.
+type OwnershipTransferRequestedV1 struct {
+	OrgID             string    `json:"orgId,omitempty" validate:"required_without=TeamID,excluded_with=TeamID"`
+	OrgName           string    `json:"orgName,omitempty"`
+	TeamID            string    `json:"teamId,omitempty" validate:"required_without=OrgID"`
+	TeamName          string    `json:"teamName,omitempty"`
+	FromUserID        string    `json:"fromUserId" validate:"required"`
+	ToUserID          string    `json:"toUserId" validate:"required"`
+	PreviousOwnerRole string    `json:"previousOwnerRole"`
+	RequestedAt       time.Time `json:"requestedAt"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+}
+
+// OwnershipTransferredV1 is the payload of the organization and team
+// ownership.transferred events. Exactly one of OrgID and TeamID is set.
+type OwnershipTransferredV1 struct {
+	OrgID             string    `json:"orgId,omitempty" validate:"required_without=TeamID,excluded_with=TeamID"`
+	OrgName           string    `json:"orgName,omitempty"`
+	TeamID            string    `json:"teamId,omitempty" validate:"required_without=OrgID"`
+	TeamName          string    `json:"teamName,omitempty"`
+	PreviousOwnerID   string    `json:"previousOwnerId" validate:"required"`
+	NewOwnerID        string    `json:"newOwnerId" validate:"required"`
+	PreviousOwnerRole string    `json:"previousOwnerRole"`
+	TransferredAt     time.Time `json:"transferredAt"`
+}
+
+// OwnershipTransferCancelledV1 is the payload of the organization and team
+// ownership.transfer_cancelled events. Exactly one of OrgID and TeamID is set.
+type OwnershipTransferCancelledV1 struct {
+	OrgID       string    `json:"orgId,omitempty" validate:"required_without=TeamID,excluded_with=TeamID"`
+	OrgName     string    `json:"orgName,omitempty"`
+	TeamID      string    `json:"teamId,omitempty" validate:"required_without=OrgID"`
+	TeamName    string    `json:"teamName,omitempty"`
+	FromUserID  string    `json:"fromUserId" validate:"required"`
+	ToUserID    string    `json:"toUserId" validate:"required"`
+	CancelledBy string    `json:"cancelledBy"`
+	CancelledAt time.Time `json:"cancelledAt"`
+}
+
+// JoinRequestCreatedV1 is the payload of organization.join_request.created
+type JoinRequestCreatedV1 struct {
+	RequestID string `json:"requestId" validate:"required"`
+	OrgID     string `json:"orgId" validate:"required"`
+	OrgName   string `json:"orgName"`
+	UserID    string `json:"userId" validate:"required"`
+	UserEmail string `json:"userEmail,omitempty"`
+	UserName  string `json:"userName,omitempty"`
+	Message   string `json:"message,omitempty"`
+	// Role and InvitedBy are set on invitations, which the user accepts
+	Role      string    `json:"role,omitempty"`
+	InvitedBy string    `json:"invitedBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// JoinRequestResolvedV1 is the payload of the organization.join_request
+// approved, rejected and cancelled events
+type JoinRequestResolvedV1 struct {
+	RequestID    string     `json:"requestId" validate:"required"`
+	OrgID        string     `json:"orgId" validate:"required"`
+	OrgName      string     `json:"orgName"`
+	UserID       string     `json:"userId" validate:"required"`
+	Status       string     `json:"status" validate:"required"`
+	Role         string     `json:"role,omitempty"`
+	ReviewedBy   string     `json:"reviewedBy,omitempty"`
+	ReviewReason string     `json:"reviewReason,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewedAt,omitempty"`
+}
+
+// TeamJoinRequestCreatedV1 is the payload of team.join_request.created
+type TeamJoinRequestCreatedV1 struct {
+	RequestID string    `json:"requestId" validate:"required"`
+	TeamID    string    `json:"teamId" validate:"required"`
+	TeamName  string    `json:"teamName"`
+	OrgID     string    `json:"orgId" validate:"required"`
+	UserID    string    `json:"userId" validate:"required"`
+	UserEmail string    `json:"userEmail,omitempty"`
+	UserName  string    `json:"userName,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TeamJoinRequestResolvedV1 is the payload of the team.join_request
+// approved, rejected and cancelled events
+type TeamJoinRequestResolvedV1 struct {
+	RequestID    string     `json:"requestId" validate:"required"`
+	TeamID       string     `json:"teamId" validate:"required"`
+	TeamName     string     `json:"teamName"`
+	OrgID        string     `json:"orgId" validate:"required"`
+	UserID       string     `json:"userId" validate:"required"`
+	Status       string     `json:"status" validate:"required"`
+	Role         string     `json:"role,omitempty"`
+	ReviewedBy   string     `json:"reviewedBy,omitempty"`
+	ReviewReason string     `json:"reviewReason,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewedAt,omitempty"`
+}
+
+// TeamArchiveV1 is the payload of the team.archived and team.unarchived
+// events. ChangedBy archived or unarchived the team.
+type TeamArchiveV1 struct {
+	TeamID    string    `json:"teamId" validate:"required"`
+	TeamName  string    `json:"teamName"`
+	OrgID     string    `json:"orgId" validate:"required"`
+	Archived  bool      `json:"archived"`
+	ChangedBy string    `json:"changedBy"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+// OrganizationVerificationV1 is the payload of the organization.verification
+// requested, approved, rejected, cancelled and revoked events
+type OrganizationVerificationV1 struct {
+	RequestID        string     `json:"requestId" validate:"required"`
+	OrgID            string     `json:"orgId" validate:"required"`
+	OrgName          string     `json:"orgName"`
+	Method           string     `json:"method" validate:"required"`
+	Domain           string     `json:"domain,omitempty"`
+	DomainVerifiedAt *time.Time `json:"domainVerifiedAt,omitempty"`
+	DocumentURL      string     `json:"documentUrl,omitempty"`
+	Status           string     `json:"status" validate:"required"`
+	RequestedBy      string     `json:"requestedBy"`
+	RequestedAt      time.Time  `json:"requestedAt"`
+	ReviewedBy       string     `json:"reviewedBy,omitempty"`
+	ReviewReason     string     `json:"reviewReason,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewedAt,omitempty"`
+}
+
+// OrganizationExportV1 is the payload of the organization.export requested,
+// completed and failed events
+type OrganizationExportV1 struct {
+	ExportID    string     `json:"exportId" validate:"required"`
+	OrgID       string     `json:"orgId" validate:"required"`
+	Format      string     `json:"format" validate:"required"`
+	Status      string     `json:"status" validate:"required"`
+	Error       string     `json:"error,omitempty"`
+	Size        int64      `json:"size,omitempty"`
+	RequestedBy string     `json:"requestedBy"`
+	RequestedAt time.Time  `json:"requestedAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+}
+
+// EmailTemplateChangedV1 is the payload of the organization.email_template
+// updated and deleted events
+type EmailTemplateChangedV1 struct {
+	OrgID     string            `json:"orgId" validate:"required"`
+	Key       string            `json:"key" validate:"required"`
+	Version   int               `json:"version" validate:"min=1"`
+	Subject   string            `json:"subject,omitempty"`
+	IntroText string            `json:"introText,omitempty"`
+	Variables map[string]string `json:"variables,omitempty"`
+	Deleted   bool              `json:"deleted,omitempty"`
+	ChangedBy string            `json:"changedBy"`
+	ChangedAt time.Time         `json:"changedAt"`
+}
+
+// BillingSubscriptionUpdatedV1 is the payload of the billing service
+// billing.subscription.updated event, sent whenever an organization's
+// subscription changes
+type BillingSubscriptionUpdatedV1 struct {
+	OrgID             string     `json:"orgId" validate:"required"`
+	Plan              string     `json:"plan" validate:"required"`
+	Seats             int        `json:"seats" validate:"min=0"`
+	Status            string     `json:"status" validate:"required,oneof=active trialing past_due canceled"`
+	RenewsAt          *time.Time `json:"renewsAt,omitempty"`
+	CancelAtPeriodEnd bool       `json:"cancelAtPeriodEnd"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+}
+
+// SeatChangedV1 is the payload of the seat.assigned and seat.unassigned
+// events. SeatsUsed is the organization's licensed members after the change,
+// for the billing service to reconcile usage with.
+type SeatChangedV1 struct {
+	OrgID     string    `json:"orgId" validate:"required"`
+	UserID    string    `json:"userId" validate:"required"`
+	ChangedBy string    `json:"changedBy,omitempty"`
+	Seats     int       `json:"seats"`
+	SeatsUsed int       `json:"seatsUsed"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+// NotificationRequestedV1 is the payload of the notification.requested event
+// this service publishes for the notification service. Immediate requests
+// carry one notification; daily digests carry every notification since the
+// user's last digest.
+type NotificationRequestedV1 struct {
+	UserID        string           `json:"userId" validate:"required"`
+	Email         string           `json:"email,omitempty"`
+	Language      string           `json:"language,omitempty"`
+	Timezone      string           `json:"timezone,omitempty"`
+	Channels      []string         `json:"channels" validate:"required,min=1"`
+	Digest        bool             `json:"digest"`
+	Notifications []NotificationV1 `json:"notifications" validate:"required,min=1,dive"`
+	RequestedAt   time.Time        `json:"requestedAt"`
+}
+
+// NotificationV1 is a notification of a notification.requested event
+type NotificationV1 struct {
+	ID         string    `json:"id" validate:"required"`
+	Type       string    `json:"type" validate:"required"`
+	ActorID    string    `json:"actorId,omitempty"`
+	OrgID      string    `json:"orgId,omitempty"`
+	OrgName    string    `json:"orgName,omitempty"`
+	TeamID     string    `json:"teamId,omitempty"`
+	TeamName   string    `json:"teamName,omitempty"`
+	Role       string    `json:"role,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+	// Message is the notification rendered with the organization's email
+	// template, for notifications about an organization
+	Message *NotificationMessageV1 `json:"message,omitempty"`
+}
+
+// NotificationMessageV1 is a notification rendered with an email template:
+// the organization's override of the template, or the platform default when
+// TemplateVersion is 0
+type NotificationMessageV1 struct {
+	Template        string                 `json:"template" validate:"required"`
+	TemplateVersion int                    `json:"templateVersion,omitempty"`
+	Subject         string                 `json:"subject"`
+	IntroText       string                 `json:"introText,omitempty"`
+	ActionURL       string                 `json:"actionUrl,omitempty"`
+	Branding        NotificationBrandingV1 `json:"branding"`
+}
+
+// NotificationBrandingV1 is the organization branding a notification is
+// shown with
+type NotificationBrandingV1 struct {
+	OrgName        string `json:"orgName"`
+	OrgLogoURL     string `json:"orgLogoUrl,omitempty"`
+	PrimaryColor   string `json:"primaryColor,omitempty"`
+	SecondaryColor string `json:"secondaryColor,omitempty"`
+}
+
+// DocumentChangedV1 is the payload of the user, team and organization
+// changed events. Operation is insert, update, replace or delete; updates
+// list the fields they set and removed.
+type DocumentChangedV1 struct {
+	ID            string    `json:"id" validate:"required"`
+	Operation     string    `json:"operation" validate:"required"`
+	UpdatedFields []string  `json:"updatedFields,omitempty"`
+	RemovedFields []string  `json:"removedFields,omitempty"`
+	ChangedAt     time.Time `json:"changedAt"`
+}
+
+// FeatureFlagChangedV1 is the payload of feature_flag.changed. Scope is
+// definition when the flag itself changed, or organizations or users when an
+// override for TargetID did; Deleted is set when the flag or override was
+// removed.
+type FeatureFlagChangedV1 struct {
+	Key       string    `json:"key" validate:"required"`
+	Scope     string    `json:"scope" validate:"required"`
+	TargetID  string    `json:"targetId,omitempty"`
+	Deleted   bool      `json:"deleted,omitempty"`
+	ChangedBy string    `json:"changedBy,omitempty"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+// EventV1 is the payload of the event created, updated, deleted, started
+// and ended events, with the event as it is after the change
+type EventV1 struct {
+	ID        string     `json:"id" validate:"required"`
+	OrgID     string     `json:"orgId" validate:"required"`
+	Title     string     `json:"title"`
+	Code      string     `json:"code" validate:"required"`
+	Status    string     `json:"status" validate:"required,oneof=draft live ended"`
+	StartsAt  time.Time  `json:"startsAt"`
+	EndsAt    time.Time  `json:"endsAt"`
+	HostIDs   []string   `json:"hostIds"`
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+	ChangedBy string     `json:"changedBy,omitempty"`
+	ChangedAt time.Time  `json:"changedAt"`
+}
+
+// OnboardingCompletedV1 is the payload of onboarding.completed, published
+// once when a user has done or skipped every onboarding step
+type OnboardingCompletedV1 struct {
+	UserID       string    `json:"userId" validate:"required"`
+	Email        string    `json:"email"`
+	FirstName    string    `json:"firstName"`
+	LastName     string    `json:"lastName"`
+	SkippedSteps []string  `json:"skippedSteps,omitempty"`
+	CompletedAt  time.Time `json:"completedAt"`
+}
@@ -1,14 +1,17 @@
 package kafka
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/slo"
 )
 
 // EventType represents the type of event
@@ -22,6 +25,37 @@ const (
 	UserDeleted     EventType = "user.deleted"
 	UserActivated   EventType = "user.activated"
 	UserDeactivated EventType = "user.deactivated"
+	UserRestored    EventType = "user.restored"
+	UserPurged      EventType = "user.purged"
+
+	// UserEmailChanged is consumed from the Auth Service and republished
+	// once the local user is reconciled
+	UserEmailChanged EventType = "user.email.changed"
+
+	// Email change events. A change requested here is verified by the Auth
+	// Service, which publishes the confirmation back.
+	UserEmailChangeRequested EventType = "user.email.change.requested"
+	UserEmailChangeConfirmed EventType = "user.email.change.confirmed"
+
+	// UserTwoFactorChanged is consumed from the Auth Service when a user
+	// enables or disables two-factor authentication
+	UserTwoFactorChanged EventType = "user.two_factor.changed"
+
+	// Session events are consumed from the Auth Service; a session signed
+	// out here is published as revoked for the Auth Service to terminate
+	SessionCreated     EventType = "session.created"
+	SessionRefreshed   EventType = "session.refreshed"
+	SessionTerminated  EventType = "session.terminated"
+	UserSessionRevoked EventType = "user.session.revoked"
+
+	// Impersonation events, for auditing admins acting as users
+	UserImpersonationStarted EventType = "user.impersonation.started"
+	UserImpersonationEnded   EventType = "user.impersonation.ended"
+
+	// Signup review events
+	UserSignupFlagged  EventType = "user.signup.flagged"
+	UserSignupApproved EventType = "user.signup.approved"
+	UserSignupRejected EventType = "user.signup.rejected"
 
 	// Team events
 	TeamCreated       EventType = "team.created"
@@ -31,6 +65,25 @@ const (
 	TeamMemberUpdated EventType = "team.member.updated"
 	TeamMemberRemoved EventType = "team.member.removed"
 
+	// TeamMemberBatch carries the member changes of a bulk member request,
+	// published once instead of an event per member
+	TeamMemberBatch EventType = "team.member.batch"
+
+	// Team archive events
+	TeamArchived   EventType = "team.archived"
+	TeamUnarchived EventType = "team.unarchived"
+
+	// Team join request events
+	TeamJoinRequested        EventType = "team.join_request.created"
+	TeamJoinRequestApproved  EventType = "team.join_request.approved"
+	TeamJoinRequestRejected  EventType = "team.join_request.rejected"
+	TeamJoinRequestCancelled EventType = "team.join_request.cancelled"
+
+	// Team ownership events
+	TeamOwnershipTransferRequested EventType = "team.ownership.transfer_requested"
+	TeamOwnershipTransferCancelled EventType = "team.ownership.transfer_cancelled"
+	TeamOwnershipTransferred       EventType = "team.ownership.transferred"
+
 	// Organization events
 	OrganizationCreated       EventType = "organization.created"
 	OrganizationUpdated       EventType = "organization.updated"
@@ -38,8 +91,108 @@ const (
 	OrganizationMemberAdded   EventType = "organization.member.added"
 	OrganizationMemberUpdated EventType = "organization.member.updated"
 	OrganizationMemberRemoved EventType = "organization.member.removed"
+
+	// OrganizationMemberBatch carries the member changes of a bulk member
+	// request, published once instead of an event per member
+	OrganizationMemberBatch EventType = "organization.member.batch"
+
+	// OrganizationMemberExpired is published when a member other than a guest
+	// is removed because their membership expired
+	OrganizationMemberExpired EventType = "organization.member.expired"
+
+	// Organization guest events, for memberships that end on their own
+	OrganizationGuestAdded    EventType = "organization.guest.added"
+	OrganizationGuestExtended EventType = "organization.guest.extended"
+	OrganizationGuestExpired  EventType = "organization.guest.expired"
+
+	// OrganizationAccessReviewConfirmed is published when an access review
+	// confirms that members keep their access
+	OrganizationAccessReviewConfirmed EventType = "organization.access_review.confirmed"
+
+	// Organization ownership events
+	OrganizationOwnershipTransferRequested EventType = "organization.ownership.transfer_requested"
+	OrganizationOwnershipTransferCancelled EventType = "organization.ownership.transfer_cancelled"
+	OrganizationOwnershipTransferred       EventType = "organization.ownership.transferred"
+
+	// Organization join request events
+	OrganizationJoinRequested        EventType = "organization.join_request.created"
+	OrganizationJoinRequestApproved  EventType = "organization.join_request.approved"
+	OrganizationJoinRequestRejected  EventType = "organization.join_request.rejected"
+	OrganizationJoinRequestCancelled EventType = "organization.join_request.cancelled"
+
+	// Organization verification events, for the trust & safety pipeline
+	OrganizationVerificationRequested EventType = "organization.verification.requested"
+	OrganizationVerificationApproved  EventType = "organization.verification.approved"
+	OrganizationVerificationRejected  EventType = "organization.verification.rejected"
+	OrganizationVerificationCancelled EventType = "organization.verification.cancelled"
+	OrganizationVerificationRevoked   EventType = "organization.verification.revoked"
+
+	// Organization export events; exports of an organization's data are
+	// recorded on its audit timeline
+	OrganizationExportRequested EventType = "organization.export.requested"
+	OrganizationExportCompleted EventType = "organization.export.completed"
+	OrganizationExportFailed    EventType = "organization.export.failed"
+
+	// Organization email template events
+	OrganizationEmailTemplateUpdated EventType = "organization.email_template.updated"
+	OrganizationEmailTemplateDeleted EventType = "organization.email_template.deleted"
+
+	// Billing service events
+	BillingSubscriptionUpdated EventType = "billing.subscription.updated"
+
+	// Seat events tell the billing service when presenter seats are taken
+	// and freed
+	SeatAssigned   EventType = "seat.assigned"
+	SeatUnassigned EventType = "seat.unassigned"
+
+	// UserPresenceChanged is published when a user opens their first or
+	// closes their last realtime connection to an instance, so every instance
+	// can tell the user's organizations
+	UserPresenceChanged EventType = "user.presence.changed"
+
+	// Event events tell the Q&A and poll services when an organization's
+	// events (meetings) are scheduled, changed, go live and end
+	EventCreated EventType = "event.created"
+	EventUpdated EventType = "event.updated"
+	EventDeleted EventType = "event.deleted"
+	EventStarted EventType = "event.started"
+	EventEnded   EventType = "event.ended"
+
+	// Onboarding events start lifecycle email campaigns
+	OnboardingCompleted EventType = "onboarding.completed"
+
+	// Feature flag events tell other services to drop their cached flags
+	FeatureFlagChanged EventType = "feature_flag.changed"
+
+	// Notification events ask the notification service to deliver
+	// notifications to a user
+	NotificationRequested EventType = "notification.requested"
+
+	// Replay events carry a current snapshot of an entity, re-published on
+	// request by a platform admin
+	UserReplayed         EventType = "user.replayed"
+	TeamReplayed         EventType = "team.replayed"
+	OrganizationReplayed EventType = "organization.replayed"
+
+	// Changed events are published for every write to a user, team or
+	// organization document seen on a MongoDB change stream, including
+	// writes that bypass the service such as migrations and manual fixes
+	UserChanged         EventType = "user.changed"
+	TeamChanged         EventType = "team.changed"
+	OrganizationChanged EventType = "organization.changed"
 )
 
+// IsReplay checks if an event type is a snapshot replay rather than a change
+func IsReplay(eventType EventType) bool {
+	return eventType == UserReplayed || eventType == TeamReplayed || eventType == OrganizationReplayed
+}
+
+// IsDocumentChange checks if an event type reports a stored document changing
+// rather than an action taken through the service
+func IsDocumentChange(eventType EventType) bool {
+	return eventType == UserChanged || eventType == TeamChanged || eventType == OrganizationChanged
+}
+
 // Event represents a Kafka event
 type Event struct {
 	ID            string      `json:"id"`
@@ -47,25 +200,33 @@ type Event struct {
 	Source        string      `json:"source"`
 	Subject       string      `json:"subject,omitempty"`
 	Time          time.Time   `json:"time"`
+	SchemaVersion int         `json:"schemaVersion,omitempty"`
 	Data          interface{} `json:"data"`
 	CorrelationID string      `json:"correlationId,omitempty"`
 }
 
 // Producer is a Kafka producer
 type Producer struct {
-	producer *kafka.Producer
-	config   *config.KafkaConfig
+	producer  *kafka.Producer
+	config    *config.KafkaConfig
+	observers []func(Event)
 }
 
 // NewProducer creates a new Kafka producer
 func NewProducer(cfg *config.KafkaConfig) (*Producer, error) {
-	// Create Kafka producer
-	p, err := kafka.NewProducer(&kafka.ConfigMap{
-		"bootstrap.servers": cfg.Brokers[0], // Use the first broker
-		"client.id":         cfg.ClientID,
-		"acks":              "all", // Wait for all replicas to acknowledge
-	})
+	if !ValidEventFormat(cfg.EventFormat) {
+		return nil, fmt.Errorf("unknown Kafka event format %q", cfg.EventFormat)
+	}
+
+	configMap, err := clientConfig(cfg)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid Kafka client configuration")
+		return nil, err
+	}
+	configMap["acks"] = "all" // Wait for all replicas to acknowledge
 
+	// Create Kafka producer
+	p, err := kafka.NewProducer(&configMap)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create Kafka producer")
 		return nil, err
@@ -76,6 +237,7 @@ func NewProducer(cfg *config.KafkaConfig) (*Producer, error) {
 		for e := range p.Events() {
 			switch ev := e.(type) {
 			case *kafka.Message:
+				slo.RecordPublish(ev.TopicPartition.Error == nil)
 				if ev.TopicPartition.Error != nil {
 					log.Error().
 						Err(ev.TopicPartition.Error).
@@ -105,6 +267,31 @@ func NewProducer(cfg *config.KafkaConfig) (*Producer, error) {
 	}, nil
 }
 
+// OnPublish registers an observer called with every event handed to Kafka.
+// Observers are called synchronously and must not block; register them
+// before the first event is published.
+func (p *Producer) OnPublish(observer func(Event)) {
+	p.observers = append(p.observers, observer)
+}
+
+// Ping checks the brokers are reachable
+func (p *Producer) Ping(ctx context.Context) error {
+	return pingBrokers(ctx, p.producer)
+}
+
+// Flush waits for the queued messages to be delivered, until ctx is done
+func (p *Producer) Flush(ctx context.Context) error {
+	for {
+		remaining := p.producer.Flush(100)
+		if remaining == 0 {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("%d messages not delivered: %w", remaining, ctx.Err())
+		}
+	}
+}
+
 // Close closes the Kafka producer
 func (p *Producer) Close() {
 	p.producer.Flush(15 * 1000) // Wait up to 15s for messages to be delivered
@@ -112,18 +299,26 @@ func (p *Producer) Close() {
 	log.Info().Msg("Kafka producer closed")
 }
 
-// PublishUserEvent publishes a user event
-func (p *Producer) PublishUserEvent(eventType EventType, data interface{}, subject string, correlationID string) error {
-	return p.publish(p.config.Topics.UserEvents, eventType, data, subject, correlationID)
+// PublishUserEvent publishes a user event, carrying the correlation ID of ctx
+func (p *Producer) PublishUserEvent(ctx context.Context, eventType EventType, data interface{}, subject string) error {
+	return p.publish(ctx, p.config.Topics.UserEvents, eventType, data, subject)
+}
+
+// PublishTeamEvent publishes a team event, carrying the correlation ID of ctx
+func (p *Producer) PublishTeamEvent(ctx context.Context, eventType EventType, data interface{}, subject string) error {
+	return p.publish(ctx, p.config.Topics.TeamEvents, eventType, data, subject)
 }
 
-// PublishTeamEvent publishes a team event
-func (p *Producer) PublishTeamEvent(eventType EventType, data interface{}, subject string, correlationID string) error {
-	return p.publish(p.config.Topics.TeamEvents, eventType, data, subject, correlationID)
+// PublishNotificationEvent publishes a notification event to the
+// notification topic, carrying the correlation ID of ctx
+func (p *Producer) PublishNotificationEvent(ctx context.Context, eventType EventType, data interface{}, subject string) error {
+	return p.publish(ctx, p.config.Topics.Notifications, eventType, data, subject)
 }
 
 // publish publishes an event to Kafka
-func (p *Producer) publish(topic string, eventType EventType, data interface{}, subject string, correlationID string) error {
+func (p *Producer) publish(ctx context.Context, topic string, eventType EventType, data interface{}, subject string) error {
+	correlationID := logger.CorrelationID(ctx)
+
 	// Create event
 	event := Event{
 		ID:            uuid.New().String(),
@@ -131,14 +326,15 @@ func (p *Producer) publish(topic string, eventType EventType, data interface{},
 		Source:        "user-service",
 		Subject:       subject,
 		Time:          time.Now(),
+		SchemaVersion: SchemaVersion(eventType),
 		Data:          data,
 		CorrelationID: correlationID,
 	}
 
 	// Serialize event
-	eventBytes, err := json.Marshal(event)
+	value, ceHeaders, err := encodeEvent(p.config.EventFormat, event)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to marshal event")
+		logger.Ctx(ctx).Error().Err(err).Str("format", p.config.EventFormat).Msg("Failed to marshal event")
 		return err
 	}
 
@@ -150,15 +346,16 @@ func (p *Producer) publish(topic string, eventType EventType, data interface{},
 		key = string(eventType)
 	}
 
-	// Create Kafka message
+	// Create Kafka message. The legacy headers are sent in every format so
+	// consumers that route on them keep working.
 	message := &kafka.Message{
 		TopicPartition: kafka.TopicPartition{
 			Topic:     &topic,
 			Partition: kafka.PartitionAny,
 		},
 		Key:   []byte(key),
-		Value: eventBytes,
-		Headers: []kafka.Header{
+		Value: value,
+		Headers: append([]kafka.Header{
 			{
 				Key:   "event-type",
 				Value: []byte(eventType),
@@ -175,7 +372,11 @@ func (p *Producer) publish(topic string, eventType EventType, data interface{},
 				Key:   "time",
 				Value: []byte(event.Time.Format(time.RFC3339)),
 			},
-		},
+			{
+				Key:   "schema-version",
+				Value: []byte(strconv.Itoa(event.SchemaVersion)),
+			},
+		}, ceHeaders...),
 	}
 
 	// Add correlation ID header if provided
@@ -188,7 +389,8 @@ func (p *Producer) publish(topic string, eventType EventType, data interface{},
 
 	// Produce message
 	if err := p.producer.Produce(message, nil); err != nil {
-		log.Error().
+		slo.RecordPublish(false)
+		logger.Ctx(ctx).Error().
 			Err(err).
 			Str("topic", topic).
 			Str("event_type", string(eventType)).
@@ -196,11 +398,15 @@ func (p *Producer) publish(topic string, eventType EventType, data interface{},
 		return fmt.Errorf("failed to produce message: %w", err)
 	}
 
-	log.Debug().
+	logger.Ctx(ctx).Debug().
 		Str("topic", topic).
 		Str("event_type", string(eventType)).
 		Str("event_id", event.ID).
 		Msg("Message produced")
 
+	for _, observer := range p.observers {
+		observer(event)
+	}
+
 	return nil
 }
@@ -0,0 +1,228 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+)
+
+// publishRetryDelay is the delay before the first retry of a failed
+// publish; later retries wait twice as long as the previous one
+const publishRetryDelay = 200 * time.Millisecond
+
+var (
+	// ErrPublishQueueFull is returned when an event can't be queued because
+	// too many events are waiting to be published
+	ErrPublishQueueFull = errors.New("event publish queue is full")
+	// ErrPublisherClosed is returned when an event is published after the
+	// publisher was closed
+	ErrPublisherClosed = errors.New("event publisher is closed")
+)
+
+// EventPublisher publishes events. Services depend on it rather than on the
+// producer, so how events are published can change without them.
+type EventPublisher interface {
+	// PublishUserEvent publishes a user event, carrying the correlation ID
+	// of ctx
+	PublishUserEvent(ctx context.Context, eventType EventType, data interface{}, subject string) error
+	// PublishTeamEvent publishes a team event, carrying the correlation ID
+	// of ctx
+	PublishTeamEvent(ctx context.Context, eventType EventType, data interface{}, subject string) error
+}
+
+// publishRequest is an event waiting to be published
+type publishRequest struct {
+	ctx       context.Context
+	team      bool
+	eventType EventType
+	data      interface{}
+	subject   string
+}
+
+// AsyncPublisher publishes events in the background, in the order they were
+// queued. Publishing returns once the event is queued; failed attempts are
+// retried, and a panic while publishing fails the event instead of crashing
+// the service.
+type AsyncPublisher struct {
+	next        EventPublisher
+	queue       chan publishRequest
+	maxAttempts int
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+
+	queued    atomic.Uint64
+	published atomic.Uint64
+	retried   atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+	panics    atomic.Uint64
+}
+
+// NewAsyncPublisher creates a publisher queueing events for next, and starts
+// publishing them
+func NewAsyncPublisher(next EventPublisher, cfg *config.KafkaConfig) *AsyncPublisher {
+	queueSize := cfg.PublishQueueSize
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	maxAttempts := cfg.PublishMaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	p := &AsyncPublisher{
+		next:        next,
+		queue:       make(chan publishRequest, queueSize),
+		maxAttempts: maxAttempts,
+		done:        make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// PublishUserEvent implements EventPublisher
+func (p *AsyncPublisher) PublishUserEvent(ctx context.Context, eventType EventType, data interface{}, subject string) error {
+	return p.enqueue(publishRequest{ctx: ctx, eventType: eventType, data: data, subject: subject})
+}
+
+// PublishTeamEvent implements EventPublisher
+func (p *AsyncPublisher) PublishTeamEvent(ctx context.Context, eventType EventType, data interface{}, subject string) error {
+	return p.enqueue(publishRequest{ctx: ctx, team: true, eventType: eventType, data: data, subject: subject})
+}
+
+// enqueue queues an event without blocking. The event keeps the correlation
+// ID of ctx but not ctx itself, which may be done before the event is
+// published.
+func (p *AsyncPublisher) enqueue(req publishRequest) error {
+	req.ctx = logger.Detach(req.ctx)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrPublisherClosed
+	}
+
+	select {
+	case p.queue <- req:
+		p.queued.Add(1)
+		return nil
+	default:
+		p.dropped.Add(1)
+		return ErrPublishQueueFull
+	}
+}
+
+// Close stops queueing events and waits until the queued ones are published,
+// or ctx is done
+func (p *AsyncPublisher) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d events not published: %w", len(p.queue), ctx.Err())
+	}
+}
+
+// run publishes the queued events until the publisher is closed
+func (p *AsyncPublisher) run() {
+	defer close(p.done)
+
+	for req := range p.queue {
+		p.publish(req)
+	}
+}
+
+// publish publishes an event, retrying failed attempts
+func (p *AsyncPublisher) publish(req publishRequest) {
+	delay := publishRetryDelay
+	var err error
+	for attempt := 1; attempt <= p.maxAttempts; attempt++ {
+		if attempt > 1 {
+			p.retried.Add(1)
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		if err = p.attempt(req); err == nil {
+			p.published.Add(1)
+			return
+		}
+	}
+
+	p.failed.Add(1)
+	logger.Ctx(req.ctx).Error().Err(err).
+		Str("event_type", string(req.eventType)).
+		Str("subject", req.subject).
+		Int("attempts", p.maxAttempts).
+		Msg("Failed to publish event")
+}
+
+// attempt makes one attempt to publish an event, turning a panic into an
+// error
+func (p *AsyncPublisher) attempt(req publishRequest) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.panics.Add(1)
+			logger.Ctx(req.ctx).Error().
+				Str("event_type", string(req.eventType)).
+				Str("stack", string(debug.Stack())).
+				Msgf("Panic publishing event: %v", r)
+			err = fmt.Errorf("panic publishing event: %v", r)
+		}
+	}()
+
+	if req.team {
+		return p.next.PublishTeamEvent(req.ctx, req.eventType, req.data, req.subject)
+	}
+	return p.next.PublishUserEvent(req.ctx, req.eventType, req.data, req.subject)
+}
+
+// WritePrometheus writes the publisher's metrics in the Prometheus text
+// format
+func (p *AsyncPublisher) WritePrometheus(w io.Writer) error {
+	var err error
+	write := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	write("# HELP user_service_events_publish_total Events handed to the background publisher, by outcome.\n")
+	write("# TYPE user_service_events_publish_total counter\n")
+	write("user_service_events_publish_total{result=\"queued\"} %d\n", p.queued.Load())
+	write("user_service_events_publish_total{result=\"published\"} %d\n", p.published.Load())
+	write("user_service_events_publish_total{result=\"failed\"} %d\n", p.failed.Load())
+	write("user_service_events_publish_total{result=\"dropped\"} %d\n", p.dropped.Load())
+
+	write("# HELP user_service_events_publish_retries_total Retried attempts to publish events.\n")
+	write("# TYPE user_service_events_publish_retries_total counter\n")
+	write("user_service_events_publish_retries_total %d\n", p.retried.Load())
+
+	write("# HELP user_service_events_publish_panics_total Panics recovered while publishing events.\n")
+	write("# TYPE user_service_events_publish_panics_total counter\n")
+	write("user_service_events_publish_panics_total %d\n", p.panics.Load())
+
+	write("# HELP user_service_events_publish_queue_depth Events waiting to be published.\n")
+	write("# TYPE user_service_events_publish_queue_depth gauge\n")
+	write("user_service_events_publish_queue_depth %d\n", len(p.queue))
+
+	return err
+}
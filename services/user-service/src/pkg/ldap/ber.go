@@ -0,0 +1,272 @@
+package ldap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BER classes
+const (
+	classUniversal   = 0x00
+	classApplication = 0x40
+	classContext     = 0x80
+)
+
+// Universal tags
+const (
+	tagBoolean     = 0x01
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagEnumerated  = 0x0a
+	tagSequence    = 0x10
+	tagSet         = 0x11
+)
+
+// maxPacketSize bounds the size of a message read from the server
+const maxPacketSize = 16 << 20
+
+// errMalformed is returned for BER data that can't be decoded
+var errMalformed = errors.New("ldap: malformed BER data")
+
+// packet is a BER element. Constructed elements have children; primitive
+// ones have a value.
+type packet struct {
+	class       byte
+	constructed bool
+	tag         int
+	value       []byte
+	children    []*packet
+}
+
+// sequence creates a universal SEQUENCE
+func sequence(children ...*packet) *packet {
+	return constructed(classUniversal, tagSequence, children...)
+}
+
+// set creates a universal SET
+func set(children ...*packet) *packet {
+	return constructed(classUniversal, tagSet, children...)
+}
+
+// constructed creates a constructed element
+func constructed(class byte, tag int, children ...*packet) *packet {
+	return &packet{class: class, constructed: true, tag: tag, children: children}
+}
+
+// primitive creates a primitive element
+func primitive(class byte, tag int, value []byte) *packet {
+	return &packet{class: class, tag: tag, value: value}
+}
+
+// octetString creates a universal OCTET STRING
+func octetString(s string) *packet {
+	return primitive(classUniversal, tagOctetString, []byte(s))
+}
+
+// boolean creates a universal BOOLEAN
+func boolean(b bool) *packet {
+	if b {
+		return primitive(classUniversal, tagBoolean, []byte{0xff})
+	}
+	return primitive(classUniversal, tagBoolean, []byte{0x00})
+}
+
+// integer creates a universal INTEGER
+func integer(n int64) *packet {
+	return primitive(classUniversal, tagInteger, encodeInt(n))
+}
+
+// enumerated creates a universal ENUMERATED
+func enumerated(n int64) *packet {
+	return primitive(classUniversal, tagEnumerated, encodeInt(n))
+}
+
+// encodeInt encodes an integer in the fewest two's complement bytes
+func encodeInt(n int64) []byte {
+	size := 1
+	for v := n; v > 127 || v < -128; v >>= 8 {
+		size++
+	}
+	b := make([]byte, size)
+	for i := size - 1; i >= 0; i-- {
+		b[i] = byte(n)
+		n >>= 8
+	}
+	return b
+}
+
+// decodeInt decodes a two's complement integer
+func decodeInt(b []byte) (int64, error) {
+	if len(b) == 0 || len(b) > 8 {
+		return 0, errMalformed
+	}
+	n := int64(int8(b[0]))
+	for _, c := range b[1:] {
+		n = n<<8 | int64(c)
+	}
+	return n, nil
+}
+
+// is checks the class and tag of an element
+func (p *packet) is(class byte, tag int) bool {
+	return p != nil && p.class == class && p.tag == tag
+}
+
+// str gets the value of a primitive element as a string
+func (p *packet) str() string {
+	return string(p.value)
+}
+
+// int gets the value of an INTEGER or ENUMERATED element
+func (p *packet) int() (int64, error) {
+	return decodeInt(p.value)
+}
+
+// child gets the i-th child of a constructed element, or nil
+func (p *packet) child(i int) *packet {
+	if p == nil || i >= len(p.children) {
+		return nil
+	}
+	return p.children[i]
+}
+
+// bytes encodes the element
+func (p *packet) bytes() []byte {
+	content := p.value
+	if p.constructed {
+		content = nil
+		for _, child := range p.children {
+			content = append(content, child.bytes()...)
+		}
+	}
+
+	identifier := p.class
+	if p.constructed {
+		identifier |= 0x20
+	}
+	// Tags above 30 don't occur in LDAP
+	identifier |= byte(p.tag & 0x1f)
+
+	out := []byte{identifier}
+	out = append(out, encodeLength(len(content))...)
+	return append(out, content...)
+}
+
+// encodeLength encodes a definite length
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var b []byte
+	for v := n; v > 0; v >>= 8 {
+		b = append([]byte{byte(v)}, b...)
+	}
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+// readPacket reads and decodes one element
+func readPacket(r *bufio.Reader) (*packet, error) {
+	identifier, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	length, err := readLength(r)
+	if err != nil {
+		return nil, err
+	}
+	if length > maxPacketSize {
+		return nil, fmt.Errorf("ldap: message of %d bytes is too large", length)
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+	return decodePacket(identifier, content)
+}
+
+// readLength reads a definite length
+func readLength(r *bufio.Reader) (int, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if first < 0x80 {
+		return int(first), nil
+	}
+
+	size := int(first & 0x7f)
+	if size == 0 || size > 4 {
+		return 0, errMalformed
+	}
+	length := 0
+	for i := 0; i < size; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length = length<<8 | int(b)
+	}
+	return length, nil
+}
+
+// decodePacket decodes an element from its identifier and content
+func decodePacket(identifier byte, content []byte) (*packet, error) {
+	p := &packet{
+		class:       identifier & 0xc0,
+		constructed: identifier&0x20 != 0,
+		tag:         int(identifier & 0x1f),
+	}
+	if p.tag == 0x1f {
+		return nil, errMalformed
+	}
+	if !p.constructed {
+		p.value = content
+		return p, nil
+	}
+
+	for len(content) > 0 {
+		if len(content) < 2 {
+			return nil, errMalformed
+		}
+		childIdentifier := content[0]
+		length, header, err := parseLength(content[1:])
+		if err != nil {
+			return nil, err
+		}
+		start := 1 + header
+		if length > len(content)-start {
+			return nil, errMalformed
+		}
+		child, err := decodePacket(childIdentifier, content[start:start+length])
+		if err != nil {
+			return nil, err
+		}
+		p.children = append(p.children, child)
+		content = content[start+length:]
+	}
+	return p, nil
+}
+
+// parseLength parses a definite length from the start of b, returning the
+// length and the number of bytes it took
+func parseLength(b []byte) (int, int, error) {
+	if len(b) == 0 {
+		return 0, 0, errMalformed
+	}
+	if b[0] < 0x80 {
+		return int(b[0]), 1, nil
+	}
+
+	size := int(b[0] & 0x7f)
+	if size == 0 || size > 4 || len(b) < 1+size {
+		return 0, 0, errMalformed
+	}
+	length := 0
+	for _, c := range b[1 : 1+size] {
+		length = length<<8 | int(c)
+	}
+	return length, 1 + size, nil
+}
@@ -0,0 +1,382 @@
+// Package ldap is a minimal LDAPv3 client (RFC 4511) for reading directories:
+// simple bind and paged subtree searches, over plain TCP or TLS.
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Protocol operations
+const (
+	opBindRequest        = 0
+	opBindResponse       = 1
+	opUnbindRequest      = 2
+	opSearchRequest      = 3
+	opSearchResultEntry  = 4
+	opSearchResultDone   = 5
+	opSearchResultRef    = 19
+	ldapProtocolVersion  = 3
+	pagedResultsOID      = "1.2.840.113556.1.4.319"
+	derefAliasesNever    = 0
+	controlsTag          = 0
+	simpleAuthentication = 0
+)
+
+// Result codes
+const (
+	ResultSuccess            = 0
+	ResultSizeLimitExceeded  = 4
+	ResultNoSuchObject       = 32
+	ResultInvalidCredentials = 49
+)
+
+// Scope is the part of the tree a search covers
+type Scope int
+
+// Search scopes
+const (
+	ScopeBaseObject   Scope = 0
+	ScopeSingleLevel  Scope = 1
+	ScopeWholeSubtree Scope = 2
+)
+
+// ErrTooManyEntries is returned when a search finds more entries than its
+// size limit
+var ErrTooManyEntries = errors.New("ldap: search returned more entries than the size limit")
+
+// Error is a result code other than success returned by the server
+type Error struct {
+	ResultCode int
+	MatchedDN  string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("ldap: result code %d: %s", e.ResultCode, e.Message)
+	}
+	return fmt.Sprintf("ldap: result code %d", e.ResultCode)
+}
+
+// Entry is an entry found by a search. Attribute names are lowercased.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Value gets the first value of an attribute, or an empty string
+func (e *Entry) Value(name string) string {
+	if values := e.Values(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Values gets the values of an attribute
+func (e *Entry) Values(name string) []string {
+	return e.Attributes[strings.ToLower(name)]
+}
+
+// SearchRequest is a search of a directory
+type SearchRequest struct {
+	BaseDN     string
+	Scope      Scope
+	Filter     string
+	Attributes []string
+	// PageSize is the number of entries fetched per page with the paged
+	// results control (RFC 2696); 0 fetches them all at once
+	PageSize int
+	// SizeLimit is the most entries the search may return; 0 is unlimited
+	SizeLimit int
+}
+
+// Conn is a connection to an LDAP server. It isn't safe for concurrent use.
+type Conn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+	nextID  int64
+}
+
+// Dial connects to the server of an ldap:// or ldaps:// URL. Each request on
+// the connection must complete within timeout. tlsConfig may be nil.
+func Dial(ctx context.Context, rawURL string, tlsConfig *tls.Config, timeout time.Duration) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: invalid URL: %w", err)
+	}
+
+	host := u.Hostname()
+	port := u.Port()
+	useTLS := false
+	switch strings.ToLower(u.Scheme) {
+	case "ldap":
+		if port == "" {
+			port = "389"
+		}
+	case "ldaps":
+		useTLS = true
+		if port == "" {
+			port = "636"
+		}
+	default:
+		return nil, fmt.Errorf("ldap: unsupported URL scheme %q", u.Scheme)
+	}
+	if host == "" {
+		return nil, errors.New("ldap: URL has no host")
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, err
+	}
+
+	if useTLS {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		tlsConfig = tlsConfig.Clone()
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = host
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		conn.SetDeadline(time.Now().Add(timeout))
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	return &Conn{
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		timeout: timeout,
+	}, nil
+}
+
+// Bind authenticates the connection with a simple bind
+func (c *Conn) Bind(dn, password string) error {
+	id, err := c.send(constructed(classApplication, opBindRequest,
+		integer(ldapProtocolVersion),
+		octetString(dn),
+		primitive(classContext, simpleAuthentication, []byte(password)),
+	))
+	if err != nil {
+		return err
+	}
+
+	op, _, err := c.read(id)
+	if err != nil {
+		return err
+	}
+	if !op.is(classApplication, opBindResponse) {
+		return fmt.Errorf("ldap: unexpected response to bind")
+	}
+	return resultError(op)
+}
+
+// Search searches the directory, following pages until every entry is read
+func (c *Conn) Search(req SearchRequest) ([]*Entry, error) {
+	filter, err := compileFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	attributes := sequence()
+	for _, attr := range req.Attributes {
+		attributes.children = append(attributes.children, octetString(attr))
+	}
+
+	var entries []*Entry
+	var cookie string
+	for {
+		var controls []*packet
+		if req.PageSize > 0 {
+			controls = append(controls, pagedResultsControl(req.PageSize, cookie))
+		}
+
+		id, err := c.send(constructed(classApplication, opSearchRequest,
+			octetString(req.BaseDN),
+			enumerated(int64(req.Scope)),
+			enumerated(derefAliasesNever),
+			integer(0),
+			integer(int64(c.timeout/time.Second)),
+			boolean(false),
+			filter,
+			attributes,
+		), controls...)
+		if err != nil {
+			return nil, err
+		}
+
+		cookie = ""
+		for done := false; !done; {
+			op, responseControls, err := c.read(id)
+			if err != nil {
+				return nil, err
+			}
+
+			switch {
+			case op.is(classApplication, opSearchResultEntry):
+				if req.SizeLimit > 0 && len(entries) >= req.SizeLimit {
+					return entries, ErrTooManyEntries
+				}
+				entry, err := parseEntry(op)
+				if err != nil {
+					return nil, err
+				}
+				entries = append(entries, entry)
+			case op.is(classApplication, opSearchResultRef):
+				// Referrals to other servers aren't followed
+			case op.is(classApplication, opSearchResultDone):
+				if err := resultError(op); err != nil {
+					return nil, err
+				}
+				cookie = pagedResultsCookie(responseControls)
+				done = true
+			default:
+				return nil, fmt.Errorf("ldap: unexpected response to search")
+			}
+		}
+
+		if cookie == "" {
+			return entries, nil
+		}
+	}
+}
+
+// Close unbinds and closes the connection
+func (c *Conn) Close() error {
+	c.send(primitive(classApplication, opUnbindRequest, nil))
+	return c.conn.Close()
+}
+
+// send sends a request, returning its message ID
+func (c *Conn) send(op *packet, controls ...*packet) (int64, error) {
+	c.nextID++
+	message := sequence(integer(c.nextID), op)
+	if len(controls) > 0 {
+		message.children = append(message.children, constructed(classContext, controlsTag, controls...))
+	}
+
+	c.conn.SetDeadline(time.Now().Add(c.timeout))
+	if _, err := c.conn.Write(message.bytes()); err != nil {
+		return 0, err
+	}
+	return c.nextID, nil
+}
+
+// read reads the next response to a request, returning its protocol
+// operation and controls
+func (c *Conn) read(id int64) (*packet, *packet, error) {
+	c.conn.SetDeadline(time.Now().Add(c.timeout))
+	message, err := readPacket(c.reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !message.is(classUniversal, tagSequence) || len(message.children) < 2 {
+		return nil, nil, errMalformed
+	}
+
+	messageID, err := message.child(0).int()
+	if err != nil {
+		return nil, nil, err
+	}
+	if messageID == 0 {
+		// Unsolicited notification, such as a notice of disconnection
+		return nil, nil, resultError(message.child(1))
+	}
+	if messageID != id {
+		return nil, nil, fmt.Errorf("ldap: response to message %d while waiting for %d", messageID, id)
+	}
+
+	var controls *packet
+	if control := message.child(2); control.is(classContext, controlsTag) {
+		controls = control
+	}
+	return message.child(1), controls, nil
+}
+
+// resultError gets the error of an LDAPResult, or nil if it succeeded
+func resultError(op *packet) error {
+	if op == nil || len(op.children) < 3 {
+		return errMalformed
+	}
+	code, err := op.child(0).int()
+	if err != nil {
+		return err
+	}
+	if code == ResultSuccess {
+		return nil
+	}
+	return &Error{
+		ResultCode: int(code),
+		MatchedDN:  op.child(1).str(),
+		Message:    op.child(2).str(),
+	}
+}
+
+// parseEntry parses a SearchResultEntry
+func parseEntry(op *packet) (*Entry, error) {
+	if len(op.children) < 2 {
+		return nil, errMalformed
+	}
+
+	entry := &Entry{
+		DN:         op.child(0).str(),
+		Attributes: make(map[string][]string, len(op.child(1).children)),
+	}
+	for _, attr := range op.child(1).children {
+		if len(attr.children) < 2 {
+			return nil, errMalformed
+		}
+		name := strings.ToLower(attr.child(0).str())
+		for _, value := range attr.child(1).children {
+			entry.Attributes[name] = append(entry.Attributes[name], value.str())
+		}
+	}
+	return entry, nil
+}
+
+// pagedResultsControl creates a paged results control asking for a page of
+// size entries after cookie
+func pagedResultsControl(size int, cookie string) *packet {
+	value := sequence(integer(int64(size)), octetString(cookie))
+	return sequence(octetString(pagedResultsOID), octetString(string(value.bytes())))
+}
+
+// pagedResultsCookie gets the cookie of the next page from the controls of a
+// search result, or an empty string on the last page
+func pagedResultsCookie(controls *packet) string {
+	if controls == nil {
+		return ""
+	}
+	for _, control := range controls.children {
+		if len(control.children) < 2 || control.child(0).str() != pagedResultsOID {
+			continue
+		}
+		value := control.children[len(control.children)-1]
+		if len(value.value) < 2 {
+			return ""
+		}
+		length, header, err := parseLength(value.value[1:])
+		if err != nil || 1+header+length > len(value.value) {
+			return ""
+		}
+		decoded, err := decodePacket(value.value[0], value.value[1+header:1+header+length])
+		if err != nil || len(decoded.children) < 2 {
+			return ""
+		}
+		return decoded.child(1).str()
+	}
+	return ""
+}
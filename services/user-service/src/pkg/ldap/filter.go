@@ -0,0 +1,239 @@
+package ldap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Filter choices (RFC 4511 section 4.5.1)
+const (
+	filterAnd             = 0
+	filterOr              = 1
+	filterNot             = 2
+	filterEqualityMatch   = 3
+	filterSubstrings      = 4
+	filterGreaterOrEqual  = 5
+	filterLessOrEqual     = 6
+	filterPresent         = 7
+	filterApproxMatch     = 8
+	filterExtensibleMatch = 9
+)
+
+// Substring choices
+const (
+	substringInitial = 0
+	substringAny     = 1
+	substringFinal   = 2
+)
+
+// Matching rule assertion fields
+const (
+	matchingRuleID  = 1
+	matchingType    = 2
+	matchingValue   = 3
+	matchingDNAttrs = 4
+)
+
+// ValidateFilter checks that a search filter is a valid RFC 4515 filter
+func ValidateFilter(filter string) error {
+	_, err := compileFilter(filter)
+	return err
+}
+
+// compileFilter compiles an RFC 4515 search filter, such as
+// (&(objectClass=person)(!(userAccountControl:1.2.840.113556.1.4.803:=2))),
+// to its BER encoding
+func compileFilter(filter string) (*packet, error) {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return nil, fmt.Errorf("ldap: empty filter")
+	}
+
+	p, rest, err := parseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("ldap: unexpected %q after filter", rest)
+	}
+	return p, nil
+}
+
+// parseFilter parses a parenthesized filter from the start of s, returning
+// the rest of s
+func parseFilter(s string) (*packet, string, error) {
+	if !strings.HasPrefix(s, "(") {
+		return nil, "", fmt.Errorf("ldap: filter must start with '('")
+	}
+	s = s[1:]
+
+	switch {
+	case strings.HasPrefix(s, "&"), strings.HasPrefix(s, "|"):
+		tag := filterAnd
+		if s[0] == '|' {
+			tag = filterOr
+		}
+		s = s[1:]
+		set := constructed(classContext, tag)
+		for strings.HasPrefix(s, "(") {
+			child, rest, err := parseFilter(s)
+			if err != nil {
+				return nil, "", err
+			}
+			set.children = append(set.children, child)
+			s = rest
+		}
+		if len(set.children) == 0 {
+			return nil, "", fmt.Errorf("ldap: empty filter list")
+		}
+		if !strings.HasPrefix(s, ")") {
+			return nil, "", fmt.Errorf("ldap: unterminated filter list")
+		}
+		return set, s[1:], nil
+
+	case strings.HasPrefix(s, "!"):
+		child, rest, err := parseFilter(s[1:])
+		if err != nil {
+			return nil, "", err
+		}
+		if !strings.HasPrefix(rest, ")") {
+			return nil, "", fmt.Errorf("ldap: unterminated negation")
+		}
+		return constructed(classContext, filterNot, child), rest[1:], nil
+	}
+
+	end := strings.IndexByte(s, ')')
+	if end < 0 {
+		return nil, "", fmt.Errorf("ldap: unterminated filter")
+	}
+	item, err := parseItem(s[:end])
+	if err != nil {
+		return nil, "", err
+	}
+	return item, s[end+1:], nil
+}
+
+// parseItem parses a simple filter item, such as cn=J*n or mail=*
+func parseItem(item string) (*packet, error) {
+	eq := strings.IndexByte(item, '=')
+	if eq < 1 {
+		return nil, fmt.Errorf("ldap: invalid filter item %q", item)
+	}
+	attr, value := item[:eq], item[eq+1:]
+
+	comparison := -1
+	switch attr[len(attr)-1] {
+	case '>':
+		comparison = filterGreaterOrEqual
+	case '<':
+		comparison = filterLessOrEqual
+	case '~':
+		comparison = filterApproxMatch
+	case ':':
+		return parseExtensible(attr[:len(attr)-1], value)
+	}
+	if comparison >= 0 {
+		decoded, err := unescapeValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return constructed(classContext, comparison, octetString(attr[:len(attr)-1]), octetString(decoded)), nil
+	}
+
+	if value == "*" {
+		return primitive(classContext, filterPresent, []byte(attr)), nil
+	}
+	if !strings.Contains(value, "*") {
+		decoded, err := unescapeValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return constructed(classContext, filterEqualityMatch, octetString(attr), octetString(decoded)), nil
+	}
+
+	// Substrings: initial*any*...*final, each part optional
+	parts := strings.Split(value, "*")
+	substrings := sequence()
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		decoded, err := unescapeValue(part)
+		if err != nil {
+			return nil, err
+		}
+		tag := substringAny
+		switch i {
+		case 0:
+			tag = substringInitial
+		case len(parts) - 1:
+			tag = substringFinal
+		}
+		substrings.children = append(substrings.children, primitive(classContext, tag, []byte(decoded)))
+	}
+	return constructed(classContext, filterSubstrings, octetString(attr), substrings), nil
+}
+
+// parseExtensible parses an extensible match, attr[:dn][:rule]:=value
+func parseExtensible(spec, value string) (*packet, error) {
+	parts := strings.Split(spec, ":")
+	assertion := constructed(classContext, filterExtensibleMatch)
+
+	var rule string
+	var dnAttributes bool
+	if parts[0] != "" {
+		assertion.children = append(assertion.children, primitive(classContext, matchingType, []byte(parts[0])))
+	}
+	for _, part := range parts[1:] {
+		switch {
+		case strings.EqualFold(part, "dn"):
+			dnAttributes = true
+		case part != "" && rule == "":
+			rule = part
+		default:
+			return nil, fmt.Errorf("ldap: invalid extensible match %q", spec)
+		}
+	}
+	if rule == "" && parts[0] == "" {
+		return nil, fmt.Errorf("ldap: extensible match needs an attribute or matching rule")
+	}
+	if rule != "" {
+		assertion.children = append([]*packet{primitive(classContext, matchingRuleID, []byte(rule))}, assertion.children...)
+	}
+
+	decoded, err := unescapeValue(value)
+	if err != nil {
+		return nil, err
+	}
+	assertion.children = append(assertion.children, primitive(classContext, matchingValue, []byte(decoded)))
+	if dnAttributes {
+		assertion.children = append(assertion.children, primitive(classContext, matchingDNAttrs, []byte{0xff}))
+	}
+	return assertion, nil
+}
+
+// unescapeValue decodes the \XX escapes of a filter value
+func unescapeValue(value string) (string, error) {
+	if !strings.Contains(value, `\`) {
+		return value, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			b.WriteByte(value[i])
+			continue
+		}
+		if i+3 > len(value) {
+			return "", fmt.Errorf("ldap: invalid escape in %q", value)
+		}
+		decoded, err := hex.DecodeString(value[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("ldap: invalid escape in %q", value)
+		}
+		b.Write(decoded)
+		i += 2
+	}
+	return b.String(), nil
+}
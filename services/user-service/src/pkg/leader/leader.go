@@ -0,0 +1,220 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// DefaultLease is the lease name singleton workers are elected under
+const DefaultLease = "user-service-leader"
+
+// LeaseStore stores leader election leases
+type LeaseStore interface {
+	// Acquire takes or renews a lease, reporting false if another holder owns it
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// Release gives up a lease held by holder
+	Release(ctx context.Context, name, holder string) error
+}
+
+// Elector elects one instance among the replicas as leader using a shared
+// lease. Leadership is kept by renewing the lease several times per TTL; an
+// instance that fails to renew steps down immediately, well before the
+// lease expires and another instance can take it over.
+type Elector struct {
+	store    LeaseStore
+	name     string
+	id       string
+	ttl      time.Duration
+	interval time.Duration
+	enabled  bool
+
+	mu          sync.RWMutex
+	leader      bool
+	subscribers []chan bool
+}
+
+// NewElector creates a new elector. When leader election is disabled the
+// instance always considers itself the leader.
+func NewElector(store LeaseStore, name string, cfg *config.LeaderElectionConfig) *Elector {
+	id := cfg.InstanceID
+	if id == "" {
+		hostname, _ := os.Hostname()
+		id = fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
+	}
+
+	interval := cfg.RenewInterval
+	if interval <= 0 || interval >= cfg.LeaseTTL {
+		interval = cfg.LeaseTTL / 3
+	}
+
+	return &Elector{
+		store:    store,
+		name:     name,
+		id:       id,
+		ttl:      cfg.LeaseTTL,
+		interval: interval,
+		enabled:  cfg.Enabled,
+	}
+}
+
+// ID returns the identity this instance holds the lease under
+func (e *Elector) ID() string {
+	return e.id
+}
+
+// IsLeader checks if this instance is currently the leader
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+// Run campaigns for leadership until the context is cancelled, then releases the lease
+func (e *Elector) Run(ctx context.Context) {
+	if !e.enabled {
+		log.Warn().Str("instanceId", e.id).Msg("Leader election disabled, running singleton workers on this instance; run a single replica")
+		e.setLeader(true)
+		<-ctx.Done()
+		e.setLeader(false)
+		return
+	}
+
+	log.Info().Str("instanceId", e.id).Str("lease", e.name).Dur("ttl", e.ttl).Msg("Starting leader election")
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.campaign(ctx)
+
+		select {
+		case <-ctx.Done():
+			if e.IsLeader() {
+				e.setLeader(false)
+
+				// Release with a fresh context so a standby can take over right away
+				releaseCtx, cancel := context.WithTimeout(context.Background(), e.interval)
+				if err := e.store.Release(releaseCtx, e.name, e.id); err != nil {
+					log.Error().Err(err).Str("lease", e.name).Msg("Failed to release leader lease")
+				}
+				cancel()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// campaign tries to acquire or renew the lease once
+func (e *Elector) campaign(ctx context.Context) {
+	acquireCtx, cancel := context.WithTimeout(ctx, e.interval)
+	defer cancel()
+
+	acquired, err := e.store.Acquire(acquireCtx, e.name, e.id, e.ttl)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Str("lease", e.name).Msg("Failed to renew leader lease")
+		}
+		acquired = false
+	}
+
+	e.setLeader(acquired)
+}
+
+// setLeader updates the leadership state and notifies subscribers of changes
+func (e *Elector) setLeader(leader bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.leader == leader {
+		return
+	}
+	e.leader = leader
+
+	if leader {
+		log.Info().Str("instanceId", e.id).Str("lease", e.name).Msg("Acquired leadership")
+	} else {
+		log.Info().Str("instanceId", e.id).Str("lease", e.name).Msg("Lost leadership")
+	}
+
+	for _, ch := range e.subscribers {
+		notify(ch, leader)
+	}
+}
+
+// subscribe returns a channel that receives the current and every later leadership state
+func (e *Elector) subscribe() chan bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ch := make(chan bool, 1)
+	ch <- e.leader
+	e.subscribers = append(e.subscribers, ch)
+	return ch
+}
+
+// notify sends the latest state, replacing a state the subscriber hasn't read yet
+func notify(ch chan bool, leader bool) {
+	select {
+	case <-ch:
+	default:
+	}
+	ch <- leader
+}
+
+// RunSingleton runs fn only while this instance is the leader. The context
+// passed to fn is cancelled when leadership is lost, and fn is started
+// again if leadership is regained. RunSingleton returns immediately.
+func (e *Elector) RunSingleton(ctx context.Context, name string, fn func(ctx context.Context)) {
+	updates := e.subscribe()
+
+	go func() {
+		var (
+			stop func()
+			done chan struct{}
+		)
+
+		halt := func() {
+			if stop == nil {
+				return
+			}
+			stop()
+			<-done
+			stop = nil
+			log.Info().Str("worker", name).Msg("Singleton worker stopped")
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				halt()
+				return
+			case leader := <-updates:
+				if !leader {
+					halt()
+					continue
+				}
+				if stop != nil {
+					continue
+				}
+
+				workerCtx, cancel := context.WithCancel(ctx)
+				stop = cancel
+				done = make(chan struct{})
+
+				log.Info().Str("worker", name).Msg("Singleton worker started")
+				go func(ctx context.Context, done chan struct{}) {
+					defer close(done)
+					fn(ctx)
+				}(workerCtx, done)
+			}
+		}
+	}()
+}
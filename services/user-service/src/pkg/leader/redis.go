@@ -0,0 +1,67 @@
+package leader
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// redisLeasePrefix prefixes the keys leases are stored under
+const redisLeasePrefix = "lease:"
+
+// acquireScript renews a lease the holder owns or takes a free one
+var acquireScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 1
+end
+return 0
+`)
+
+// releaseScript deletes a lease the holder owns
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLeaseStore stores leases in Redis, as keys that expire with the lease
+type RedisLeaseStore struct {
+	client *redis.Client
+}
+
+// NewRedisLeaseStore creates a new Redis lease store
+func NewRedisLeaseStore(cfg *config.LeaderElectionConfig) *RedisLeaseStore {
+	return &RedisLeaseStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		}),
+	}
+}
+
+// Acquire takes or renews a lease for holder. It reports false if another
+// holder owns an unexpired lease.
+func (s *RedisLeaseStore) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	acquired, err := acquireScript.Run(ctx, s.client, []string{redisLeasePrefix + name}, holder, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return acquired == 1, nil
+}
+
+// Release gives up a lease so another instance can take over immediately
+func (s *RedisLeaseStore) Release(ctx context.Context, name, holder string) error {
+	return releaseScript.Run(ctx, s.client, []string{redisLeasePrefix + name}, holder).Err()
+}
+
+// Close closes the Redis client
+func (s *RedisLeaseStore) Close() error {
+	return s.client.Close()
+}
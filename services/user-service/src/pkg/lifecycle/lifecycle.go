@@ -0,0 +1,149 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+)
+
+// Manager runs the service's servers until the service is asked to stop or
+// a server fails, then shuts the service down in stages
+type Manager struct {
+	servers []server
+	stages  []stage
+}
+
+// server is a long-running server, such as the HTTP server
+type server struct {
+	name  string
+	serve func() error
+}
+
+// stage is a step of the shutdown
+type stage struct {
+	name    string
+	timeout time.Duration
+	stop    func(ctx context.Context) error
+}
+
+// NewManager creates a lifecycle manager
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Serve registers a server. serve blocks until the server stops; a shutdown
+// stage must stop it.
+func (m *Manager) Serve(name string, serve func() error) {
+	m.servers = append(m.servers, server{name: name, serve: serve})
+}
+
+// Stage registers a shutdown stage. Stages run one at a time, in the order
+// they are registered, each given at most its timeout; a stage that times
+// out is abandoned and the shutdown moves on.
+func (m *Manager) Stage(name string, timeout time.Duration, stop func(ctx context.Context) error) {
+	m.stages = append(m.stages, stage{name: name, timeout: timeout, stop: stop})
+}
+
+// Run starts the servers and waits until ctx is done or a server fails,
+// then runs the shutdown stages. It returns the errors of the failed server
+// and stages.
+func (m *Manager) Run(ctx context.Context) error {
+	group, groupCtx := errgroup.WithContext(ctx)
+	for _, s := range m.servers {
+		group.Go(func() error {
+			if err := s.serve(); err != nil {
+				log.Error().Err(err).Str("server", s.name).Msg("Server failed")
+				return fmt.Errorf("%s: %w", s.name, err)
+			}
+			return nil
+		})
+	}
+
+	<-groupCtx.Done()
+	log.Info().Msg("Shutting down")
+
+	errs := []error{m.shutdown()}
+	if err := group.Wait(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// shutdown runs the shutdown stages in order
+func (m *Manager) shutdown() error {
+	var errs []error
+	for _, s := range m.stages {
+		start := time.Now()
+		err := runStage(s)
+		if err != nil {
+			log.Error().Err(err).Str("stage", s.name).Dur("duration", time.Since(start)).Msg("Shutdown stage failed")
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+			continue
+		}
+		log.Info().Str("stage", s.name).Dur("duration", time.Since(start)).Msg("Shutdown stage completed")
+	}
+	return errors.Join(errs...)
+}
+
+// runStage runs a stage, giving up on it once its timeout passes
+func runStage(s stage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out after %s", s.timeout)
+	}
+}
+
+// background tracks the goroutines doing work on behalf of requests and
+// events, such as publishing events, that must finish before shutdown
+var background sync.WaitGroup
+
+// Add registers a background goroutine about to start. Call it before the
+// go statement, and Done when the goroutine finishes.
+func Add() {
+	background.Add(1)
+}
+
+// Done marks a background goroutine finished
+func Done() {
+	background.Done()
+}
+
+// Go runs fn in a background goroutine that shutdown waits for
+func Go(fn func()) {
+	Add()
+	go func() {
+		defer Done()
+		fn()
+	}()
+}
+
+// Wait waits for the background goroutines to finish, until ctx is done
+func Wait(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
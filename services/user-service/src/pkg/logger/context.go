@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// CorrelationIDKey is the context key of the correlation ID. It is a string
+// key so gin contexts, which look string keys up in their own keys, find it
+// too.
+const CorrelationIDKey = "correlation_id"
+
+// CorrelationIDHeader is the header carrying the correlation ID of HTTP
+// requests
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// WithCorrelationID returns a context carrying a correlation ID
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, CorrelationIDKey, correlationID)
+}
+
+// CorrelationID gets the correlation ID of a context, or "" if it has none
+func CorrelationID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	correlationID, _ := ctx.Value(CorrelationIDKey).(string)
+	return correlationID
+}
+
+// Detach returns a background context carrying the correlation ID of ctx,
+// for work that outlives the request, such as publishing events in the
+// background. Request contexts must not be used once the request is done.
+func Detach(ctx context.Context) context.Context {
+	detached := context.Background()
+	if correlationID := CorrelationID(ctx); correlationID != "" {
+		detached = WithCorrelationID(detached, correlationID)
+	}
+	return detached
+}
+
+// Ctx gets the global logger, adding the correlation ID of ctx to its events
+func Ctx(ctx context.Context) *zerolog.Logger {
+	correlationID := CorrelationID(ctx)
+	if correlationID == "" {
+		return &log.Logger
+	}
+	logger := log.With().Str(CorrelationIDKey, correlationID).Logger()
+	return &logger
+}
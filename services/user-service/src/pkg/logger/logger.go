@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -19,13 +20,19 @@ func Init(config *config.Config) {
 	zerolog.LevelFieldName = "level"
 	zerolog.MessageFieldName = "message"
 
-	// Configure logger output
-	output := zerolog.ConsoleWriter{
-		Out:        os.Stdout,
-		TimeFormat: "2006-01-02 15:04:05",
-		FormatLevel: func(i interface{}) string {
-			return strings.ToUpper(fmt.Sprintf("| %-6s |", i))
-		},
+	// Configure logger output; JSON is written as is for log aggregation
+	var output io.Writer = os.Stdout
+	if !strings.EqualFold(config.Logging.Format, "json") {
+		output = zerolog.ConsoleWriter{
+			Out:        os.Stdout,
+			TimeFormat: "2006-01-02 15:04:05",
+			FormatLevel: func(i interface{}) string {
+				return strings.ToUpper(fmt.Sprintf("| %-6s |", i))
+			},
+		}
+	}
+	if redactor := newRedactor(config.Logging.RedactFields); redactor != nil {
+		output = &redactingWriter{out: output, redactor: redactor}
 	}
 
 	// Set the global logger
@@ -35,14 +42,28 @@ func Init(config *config.Config) {
 		Str("service", "user-service").
 		Logger()
 
+	// Sample high-volume debug and trace messages
+	if rate := config.Logging.DebugSampleRate; rate > 1 {
+		sampler := &zerolog.BasicSampler{N: uint32(rate)}
+		log.Logger = log.Logger.Sample(zerolog.LevelSampler{
+			TraceSampler: sampler,
+			DebugSampler: sampler,
+		})
+	}
+
 	// Set the log level
-	level, err := zerolog.ParseLevel(config.Logging.Level)
+	SetLevel(config.Logging.Level)
+
+	log.Info().Msg("Logger initialized")
+}
+
+// SetLevel sets the global log level, falling back to info for unknown levels
+func SetLevel(name string) {
+	level, err := zerolog.ParseLevel(name)
 	if err != nil {
 		level = zerolog.InfoLevel
 	}
 	zerolog.SetGlobalLevel(level)
-
-	log.Info().Msg("Logger initialized")
 }
 
 // Field represents a log field
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// redactedValue replaces the values of redacted fields
+const redactedValue = "[REDACTED]"
+
+// redactor redacts fields whose names contain one of its patterns
+type redactor struct {
+	patterns [][]byte
+}
+
+// newRedactor creates a redactor of fields whose names contain one of the
+// patterns, which may be comma-separated lists, or nil when there are none
+func newRedactor(entries []string) *redactor {
+	var patterns [][]byte
+	for _, entry := range entries {
+		for _, pattern := range strings.Split(entry, ",") {
+			if pattern = strings.ToLower(strings.TrimSpace(pattern)); pattern != "" {
+				patterns = append(patterns, []byte(pattern))
+			}
+		}
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+	return &redactor{patterns: patterns}
+}
+
+// matches checks if a field name, or any text, contains one of the patterns
+func (r *redactor) matches(name []byte) bool {
+	name = bytes.ToLower(name)
+	for _, pattern := range r.patterns {
+		if bytes.Contains(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact redacts a decoded JSON value in place, returning whether anything
+// was redacted
+func (r *redactor) redact(value interface{}) bool {
+	redacted := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, field := range v {
+			if r.matches([]byte(name)) {
+				v[name] = redactedValue
+				redacted = true
+			} else if r.redact(field) {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if r.redact(item) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// redactingWriter redacts sensitive fields of the JSON log events written to
+// it, such as the emails and phone numbers of logged request payloads
+type redactingWriter struct {
+	out      io.Writer
+	redactor *redactor
+}
+
+// Write implements io.Writer
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	// Most events mention no sensitive field, so they skip decoding
+	if !w.redactor.matches(p) {
+		return w.out.Write(p)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+	var event map[string]interface{}
+	if err := decoder.Decode(&event); err != nil || !w.redactor.redact(event) {
+		return w.out.Write(p)
+	}
+
+	redacted, err := json.Marshal(event)
+	if err != nil {
+		return w.out.Write(p)
+	}
+	if _, err := w.out.Write(append(redacted, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
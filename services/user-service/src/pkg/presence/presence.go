@@ -0,0 +1,109 @@
+// Package presence tracks which users are online. A user is online while
+// their heartbeats keep arriving within the presence TTL.
+package presence
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/pkg/cache"
+)
+
+// keyPrefix prefixes the Redis keys of online users
+const keyPrefix = "presence:"
+
+// Store records heartbeats and reports which users are online
+type Store interface {
+	// Touch marks a user online for the TTL
+	Touch(ctx context.Context, userID string) error
+	// Online reports which of the users are online
+	Online(ctx context.Context, userIDs []string) (map[string]bool, error)
+	// Close releases the store's connections
+	Close() error
+}
+
+// New creates the presence store selected by configuration. Without a Redis
+// address presence is kept in memory, which only suits a single instance.
+func New(cfg *config.PresenceConfig) Store {
+	if cfg.RedisAddr == "" {
+		return &memoryStore{online: cache.New[string, struct{}](cfg.TTL)}
+	}
+	return &redisStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		}),
+		ttl: cfg.TTL,
+	}
+}
+
+// redisStore keeps a key per online user that expires after the TTL, so
+// every instance sees the same presence
+type redisStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// Touch marks a user online for the TTL
+func (s *redisStore) Touch(ctx context.Context, userID string) error {
+	return s.client.Set(ctx, keyPrefix+userID, time.Now().Unix(), s.ttl).Err()
+}
+
+// Online reports which of the users are online
+func (s *redisStore) Online(ctx context.Context, userIDs []string) (map[string]bool, error) {
+	online := make(map[string]bool, len(userIDs))
+	if len(userIDs) == 0 {
+		return online, nil
+	}
+
+	keys := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		keys[i] = keyPrefix + userID
+	}
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		if value != nil {
+			online[userIDs[i]] = true
+		}
+	}
+	return online, nil
+}
+
+// Close closes the Redis client
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
+
+// memoryStore keeps presence in memory, for development and single-instance
+// deployments
+type memoryStore struct {
+	online *cache.TTL[string, struct{}]
+}
+
+// Touch marks a user online for the TTL
+func (s *memoryStore) Touch(_ context.Context, userID string) error {
+	s.online.Set(userID, struct{}{})
+	return nil
+}
+
+// Online reports which of the users are online
+func (s *memoryStore) Online(_ context.Context, userIDs []string) (map[string]bool, error) {
+	online := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if _, ok := s.online.Get(userID); ok {
+			online[userID] = true
+		}
+	}
+	return online, nil
+}
+
+// Close does nothing; the memory store holds no connections
+func (s *memoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,383 @@
+// Package realtime fans messages out to websocket connections. Connections
+// belong to a user and join rooms, such as the organizations the user is a
+// member of; a message sent to a room goes to every connection in it.
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// maxMessageSize is the largest message read from a client. Clients only
+// answer pings and close connections, so anything larger is refused.
+const maxMessageSize = 512
+
+var (
+	// ErrTooManyConnections is returned when a user already has the most
+	// connections allowed open on this instance
+	ErrTooManyConnections = errors.New("too many realtime connections")
+	// ErrHubClosed is returned when a connection is registered after the hub
+	// was closed
+	ErrHubClosed = errors.New("realtime hub is closed")
+)
+
+// Hub holds the open connections of this instance and the rooms they are in
+type Hub struct {
+	config *config.RealtimeConfig
+
+	mu     sync.RWMutex
+	users  map[string]map[*Client]struct{}
+	rooms  map[string]map[*Client]struct{}
+	closed bool
+
+	accepted     atomic.Uint64
+	rejected     atomic.Uint64
+	sent         atomic.Uint64
+	dropped      atomic.Uint64
+	slowConsumer atomic.Uint64
+}
+
+// Client is an open connection of a user. Messages are queued in a buffer
+// written by the client's own goroutine, so a slow connection never holds
+// up the others; a connection whose buffer is full is closed.
+type Client struct {
+	hub    *Hub
+	conn   *websocket.Conn
+	userID string
+	// first is set when the user had no other connection open
+	first bool
+	// rooms is guarded by the hub's lock
+	rooms map[string]struct{}
+
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+	closeCode int
+	closeText string
+}
+
+// NewHub creates a new hub
+func NewHub(cfg *config.RealtimeConfig) *Hub {
+	return &Hub{
+		config: cfg,
+		users:  make(map[string]map[*Client]struct{}),
+		rooms:  make(map[string]map[*Client]struct{}),
+	}
+}
+
+// Register adds a user's connection to the hub. Connections over the
+// user's limit are closed and ErrTooManyConnections returned.
+func (h *Hub) Register(conn *websocket.Conn, userID string) (*Client, error) {
+	client := &Client{
+		hub:    h,
+		conn:   conn,
+		userID: userID,
+		rooms:  make(map[string]struct{}),
+		send:   make(chan []byte, h.config.SendBuffer),
+		done:   make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	var err error
+	switch {
+	case h.closed:
+		err = ErrHubClosed
+	case len(h.users[userID]) >= h.config.MaxConnectionsPerUser:
+		err = ErrTooManyConnections
+	}
+	if err != nil {
+		h.mu.Unlock()
+		h.rejected.Add(1)
+		client.writeClose(websocket.ClosePolicyViolation, err.Error())
+		conn.Close()
+		return nil, err
+	}
+
+	if h.users[userID] == nil {
+		h.users[userID] = make(map[*Client]struct{})
+		client.first = true
+	}
+	h.users[userID][client] = struct{}{}
+	h.mu.Unlock()
+
+	h.accepted.Add(1)
+	return client, nil
+}
+
+// Join adds every connection of a user to a room
+func (h *Hub) Join(userID, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.users[userID] {
+		h.join(client, room)
+	}
+}
+
+// Leave removes every connection of a user from a room
+func (h *Hub) Leave(userID, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.users[userID] {
+		h.leave(client, room)
+	}
+}
+
+// CloseRoom removes every connection from a room, such as when its
+// organization is deleted
+func (h *Hub) CloseRoom(room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.rooms[room] {
+		h.leave(client, room)
+	}
+}
+
+// Broadcast sends a message to every connection in a room
+func (h *Hub) Broadcast(room string, message interface{}) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.rooms[room] {
+		client.queue(payload)
+	}
+	return nil
+}
+
+// SendToUser sends a message to every connection of a user
+func (h *Hub) SendToUser(userID string, message interface{}) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.users[userID] {
+		client.queue(payload)
+	}
+	return nil
+}
+
+// Close closes every connection and refuses new ones, so the server can
+// shut down; connections taken over from the HTTP server aren't closed by
+// its shutdown
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, clients := range h.users {
+		for client := range clients {
+			client.close(websocket.CloseGoingAway, "server shutting down")
+		}
+	}
+}
+
+// Closed checks if the hub was closed
+func (h *Hub) Closed() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.closed
+}
+
+// unregister removes a connection from the hub and its rooms, reporting if
+// it was the user's last
+func (h *Hub) unregister(client *Client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for room := range client.rooms {
+		h.leave(client, room)
+	}
+	delete(h.users[client.userID], client)
+	if len(h.users[client.userID]) > 0 {
+		return false
+	}
+	delete(h.users, client.userID)
+	return true
+}
+
+// join adds a connection to a room; the caller holds the lock
+func (h *Hub) join(client *Client, room string) {
+	if h.rooms[room] == nil {
+		h.rooms[room] = make(map[*Client]struct{})
+	}
+	h.rooms[room][client] = struct{}{}
+	client.rooms[room] = struct{}{}
+}
+
+// leave removes a connection from a room; the caller holds the lock
+func (h *Hub) leave(client *Client, room string) {
+	delete(h.rooms[room], client)
+	if len(h.rooms[room]) == 0 {
+		delete(h.rooms, room)
+	}
+	delete(client.rooms, room)
+}
+
+// First checks if the user had no other connection open when the
+// connection was registered
+func (c *Client) First() bool {
+	return c.first
+}
+
+// Join adds the connection to rooms
+func (c *Client) Join(rooms ...string) {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	for _, room := range rooms {
+		c.hub.join(c, room)
+	}
+}
+
+// Close closes the connection with a close code and reason
+func (c *Client) Close(code int, text string) {
+	c.close(code, text)
+}
+
+// Run serves the connection until it closes: pinging it, writing queued
+// messages and reading until the client goes away. The connection is then
+// removed from the hub, and Run reports if it was the user's last.
+func (c *Client) Run() bool {
+	go c.write()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(2 * c.hub.config.PingInterval))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(2 * c.hub.config.PingInterval))
+	})
+
+	// Messages from clients are ignored; reading handles pongs and closes
+	for {
+		if _, _, err := c.conn.NextReader(); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+				log.Debug().Err(err).Str("userId", c.userID).Msg("Realtime connection closed unexpectedly")
+			}
+			c.close(websocket.CloseNormalClosure, "")
+			return c.hub.unregister(c)
+		}
+	}
+}
+
+// queue queues a message for the connection, closing the connection if it
+// is too far behind to take it. Connections closing take no more messages.
+func (c *Client) queue(payload []byte) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	select {
+	case c.send <- payload:
+		c.hub.sent.Add(1)
+	default:
+		c.hub.dropped.Add(1)
+		c.close(websocket.CloseTryAgainLater, "too slow")
+	}
+}
+
+// close asks the connection's writer to close it with a close code; only
+// the first close counts
+func (c *Client) close(code int, text string) {
+	c.closeOnce.Do(func() {
+		if code == websocket.CloseTryAgainLater {
+			c.hub.slowConsumer.Add(1)
+		}
+		c.closeCode, c.closeText = code, text
+		close(c.done)
+	})
+}
+
+// write writes queued messages and pings to the connection until it is
+// closed or a write fails
+func (c *Client) write() {
+	ping := time.NewTicker(c.hub.config.PingInterval)
+	defer ping.Stop()
+	defer c.conn.Close()
+
+	for {
+		select {
+		case <-c.done:
+			c.writeClose(c.closeCode, c.closeText)
+			return
+		case payload := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(c.hub.config.WriteTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ping.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.hub.config.WriteTimeout)); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		}
+	}
+}
+
+// writeClose sends a close message, unless the connection already failed
+func (c *Client) writeClose(code int, text string) {
+	if code == websocket.CloseAbnormalClosure {
+		return
+	}
+	message := websocket.FormatCloseMessage(code, text)
+	c.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(c.hub.config.WriteTimeout))
+}
+
+// WritePrometheus writes the hub's metrics in the Prometheus text format
+func (h *Hub) WritePrometheus(w io.Writer) error {
+	h.mu.RLock()
+	var connections int
+	for _, clients := range h.users {
+		connections += len(clients)
+	}
+	users, rooms := len(h.users), len(h.rooms)
+	h.mu.RUnlock()
+
+	var err error
+	write := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	write("# HELP user_service_realtime_connections Open realtime connections.\n")
+	write("# TYPE user_service_realtime_connections gauge\n")
+	write("user_service_realtime_connections %d\n", connections)
+
+	write("# HELP user_service_realtime_users Users with a realtime connection open.\n")
+	write("# TYPE user_service_realtime_users gauge\n")
+	write("user_service_realtime_users %d\n", users)
+
+	write("# HELP user_service_realtime_rooms Rooms with a realtime connection in them.\n")
+	write("# TYPE user_service_realtime_rooms gauge\n")
+	write("user_service_realtime_rooms %d\n", rooms)
+
+	write("# HELP user_service_realtime_connections_total Realtime connections opened, by outcome.\n")
+	write("# TYPE user_service_realtime_connections_total counter\n")
+	write("user_service_realtime_connections_total{result=\"accepted\"} %d\n", h.accepted.Load())
+	write("user_service_realtime_connections_total{result=\"rejected\"} %d\n", h.rejected.Load())
+
+	write("# HELP user_service_realtime_messages_total Messages queued for realtime connections, by outcome.\n")
+	write("# TYPE user_service_realtime_messages_total counter\n")
+	write("user_service_realtime_messages_total{result=\"sent\"} %d\n", h.sent.Load())
+	write("user_service_realtime_messages_total{result=\"dropped\"} %d\n", h.dropped.Load())
+
+	write("# HELP user_service_realtime_slow_disconnects_total Realtime connections closed for falling behind.\n")
+	write("# TYPE user_service_realtime_slow_disconnects_total counter\n")
+	write("user_service_realtime_slow_disconnects_total %d\n", h.slowConsumer.Load())
+
+	return err
+}
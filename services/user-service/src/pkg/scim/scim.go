@@ -0,0 +1,262 @@
+package scim
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SCIM schema URNs
+const (
+	SchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// ContentType is the media type used by SCIM responses
+const ContentType = "application/scim+json"
+
+// Pagination defaults
+const (
+	DefaultCount = 100
+	MaxCount     = 200
+)
+
+// SCIM error types (RFC 7644 section 3.12)
+const (
+	ErrorTypeInvalidFilter = "invalidFilter"
+	ErrorTypeInvalidValue  = "invalidValue"
+	ErrorTypeInvalidSyntax = "invalidSyntax"
+	ErrorTypeUniqueness    = "uniqueness"
+	ErrorTypeNoTarget      = "noTarget"
+	ErrorTypeMutability    = "mutability"
+)
+
+// Error represents a SCIM error response
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// NewError creates a new SCIM error response
+func NewError(status int, scimType, detail string) Error {
+	return Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}
+}
+
+// ListResponse represents a SCIM list response
+type ListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int64       `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// NewListResponse creates a new SCIM list response
+func NewListResponse(resources interface{}, total int64, startIndex, itemsPerPage int) ListResponse {
+	return ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: itemsPerPage,
+		Resources:    resources,
+	}
+}
+
+// PatchRequest represents a SCIM PATCH request
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations" validate:"required,min=1,dive"`
+}
+
+// PatchOperation represents a single SCIM PATCH operation
+type PatchOperation struct {
+	Op    string      `json:"op" validate:"required"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Meta represents SCIM resource metadata
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Location     string `json:"location,omitempty"`
+}
+
+// Pagination normalizes SCIM startIndex/count query parameters
+func Pagination(startIndexStr, countStr string) (int, int) {
+	startIndex, err := strconv.Atoi(startIndexStr)
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count < 0 {
+		count = DefaultCount
+	}
+	if count > MaxCount {
+		count = MaxCount
+	}
+
+	return startIndex, count
+}
+
+// Filter operators
+const (
+	OpEqual      = "eq"
+	OpNotEqual   = "ne"
+	OpContains   = "co"
+	OpStartsWith = "sw"
+	OpEndsWith   = "ew"
+	OpPresent    = "pr"
+)
+
+// ErrInvalidFilter is returned when a filter expression cannot be parsed
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// Comparison is a single attribute comparison in a filter
+type Comparison struct {
+	Attribute string
+	Operator  string
+	Value     string
+}
+
+// Filter is a parsed SCIM filter. Groups are OR-ed together and the
+// comparisons within a group are AND-ed, which covers the filters sent by
+// the common identity providers without needing a full expression tree.
+type Filter struct {
+	Groups [][]Comparison
+}
+
+// IsEmpty checks if the filter has no comparisons
+func (f *Filter) IsEmpty() bool {
+	return f == nil || len(f.Groups) == 0
+}
+
+// ParseFilter parses a SCIM filter expression such as
+// `userName eq "john@example.com" and active eq true`
+func ParseFilter(expr string) (*Filter, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return &Filter{}, nil
+	}
+
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &Filter{}
+	current := []Comparison{}
+
+	for i := 0; i < len(tokens); {
+		// Expect an attribute path followed by an operator
+		if i+1 >= len(tokens) {
+			return nil, fmt.Errorf("%w: unexpected end of expression", ErrInvalidFilter)
+		}
+
+		comparison := Comparison{
+			Attribute: tokens[i],
+			Operator:  strings.ToLower(tokens[i+1]),
+		}
+		i += 2
+
+		switch comparison.Operator {
+		case OpPresent:
+			// Presence check has no value
+		case OpEqual, OpNotEqual, OpContains, OpStartsWith, OpEndsWith:
+			if i >= len(tokens) {
+				return nil, fmt.Errorf("%w: missing value for %s", ErrInvalidFilter, comparison.Attribute)
+			}
+			comparison.Value = unquote(tokens[i])
+			i++
+		default:
+			return nil, fmt.Errorf("%w: unsupported operator %q", ErrInvalidFilter, comparison.Operator)
+		}
+
+		current = append(current, comparison)
+
+		// Handle logical operator
+		if i < len(tokens) {
+			switch strings.ToLower(tokens[i]) {
+			case "and":
+				// Continue the current group
+			case "or":
+				filter.Groups = append(filter.Groups, current)
+				current = []Comparison{}
+			default:
+				return nil, fmt.Errorf("%w: unexpected token %q", ErrInvalidFilter, tokens[i])
+			}
+			i++
+			if i >= len(tokens) {
+				return nil, fmt.Errorf("%w: dangling logical operator", ErrInvalidFilter)
+			}
+		}
+	}
+
+	filter.Groups = append(filter.Groups, current)
+	return filter, nil
+}
+
+// tokenize splits a filter expression on whitespace while keeping quoted strings intact
+func tokenize(expr string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+	escaped := false
+
+	for _, r := range expr {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && inQuotes:
+			current.WriteRune(r)
+			escaped = true
+		case r == '"':
+			current.WriteRune(r)
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		case (r == '(' || r == ')') && !inQuotes:
+			return nil, fmt.Errorf("%w: grouping with parentheses is not supported", ErrInvalidFilter)
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("%w: unterminated string", ErrInvalidFilter)
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens, nil
+}
+
+// unquote removes surrounding quotes and escape sequences from a filter value
+func unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+		return value[1 : len(value)-1]
+	}
+	return value
+}
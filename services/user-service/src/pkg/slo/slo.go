@@ -0,0 +1,328 @@
+package slo
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+)
+
+// SLI names
+const (
+	APIAvailability = "api_availability"
+	EventPublish    = "event_publish"
+	ConsumerLag     = "consumer_lag"
+)
+
+const (
+	metricsPrefix       = "user_service"
+	bucketSize          = time.Minute
+	retentionBuckets    = 360 // 6h of one-minute buckets
+	defaultObjective    = 0.999
+	defaultLagObjective = 0.99
+)
+
+// Windows are the rolling windows error-budget burn is computed over
+var Windows = []time.Duration{5 * time.Minute, time.Hour, 6 * time.Hour}
+
+// bucket holds the good and total event counts of one minute
+type bucket struct {
+	start time.Time
+	good  uint64
+	total uint64
+}
+
+// SLI is a service level indicator with a rolling event history
+type SLI struct {
+	Name        string
+	Description string
+	Objective   float64
+
+	mu         sync.Mutex
+	buckets    [retentionBuckets]bucket
+	goodTotal  uint64
+	badTotal   uint64
+	lastValue  float64
+	hasLastVal bool
+}
+
+// WindowSummary summarizes an SLI over one rolling window
+type WindowSummary struct {
+	Window   string  `json:"window"`
+	Good     uint64  `json:"good"`
+	Total    uint64  `json:"total"`
+	SLI      float64 `json:"sli"`
+	BurnRate float64 `json:"burnRate"`
+}
+
+// Summary summarizes an SLI for on-call
+type Summary struct {
+	Name                 string          `json:"name"`
+	Description          string          `json:"description"`
+	Objective            float64         `json:"objective"`
+	ErrorBudgetRemaining float64         `json:"errorBudgetRemaining"`
+	Windows              []WindowSummary `json:"windows"`
+}
+
+// Registry holds the service SLIs
+type Registry struct {
+	slis         []*SLI
+	byName       map[string]*SLI
+	lagThreshold time.Duration
+	now          func() time.Time
+}
+
+var defaultRegistry = NewRegistry(&config.SLOConfig{
+	AvailabilityObjective: defaultObjective,
+	PublishObjective:      defaultObjective,
+	ConsumerLagObjective:  defaultLagObjective,
+	ConsumerLagThreshold:  30 * time.Second,
+})
+
+// NewRegistry creates a registry with the service SLIs
+func NewRegistry(cfg *config.SLOConfig) *Registry {
+	r := &Registry{
+		byName:       make(map[string]*SLI),
+		lagThreshold: cfg.ConsumerLagThreshold,
+		now:          time.Now,
+	}
+
+	r.add(&SLI{
+		Name:        APIAvailability,
+		Description: "Share of key endpoint requests served without a server error",
+		Objective:   cfg.AvailabilityObjective,
+	})
+	r.add(&SLI{
+		Name:        EventPublish,
+		Description: "Share of Kafka events delivered successfully",
+		Objective:   cfg.PublishObjective,
+	})
+	r.add(&SLI{
+		Name:        ConsumerLag,
+		Description: fmt.Sprintf("Share of consumed events processed within %s of being produced", cfg.ConsumerLagThreshold),
+		Objective:   cfg.ConsumerLagObjective,
+	})
+
+	return r
+}
+
+// Init replaces the default registry using the given configuration
+func Init(cfg *config.SLOConfig) {
+	defaultRegistry = NewRegistry(cfg)
+}
+
+// Default returns the default registry
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// RecordRequest records the outcome of a key endpoint request
+func RecordRequest(status int) {
+	defaultRegistry.Record(APIAvailability, status < 500)
+}
+
+// RecordPublish records the outcome of an event delivery
+func RecordPublish(success bool) {
+	defaultRegistry.Record(EventPublish, success)
+}
+
+// RecordConsumerLag records the lag of a consumed event
+func RecordConsumerLag(lag time.Duration) {
+	defaultRegistry.RecordLag(lag)
+}
+
+// add registers an SLI
+func (r *Registry) add(sli *SLI) {
+	r.slis = append(r.slis, sli)
+	r.byName[sli.Name] = sli
+}
+
+// Record records a good or bad event for an SLI
+func (r *Registry) Record(name string, good bool) {
+	sli, ok := r.byName[name]
+	if !ok {
+		return
+	}
+	sli.record(r.now(), good)
+}
+
+// RecordLag records a consumer lag sample
+func (r *Registry) RecordLag(lag time.Duration) {
+	sli := r.byName[ConsumerLag]
+	sli.record(r.now(), lag <= r.lagThreshold)
+
+	sli.mu.Lock()
+	sli.lastValue = lag.Seconds()
+	sli.hasLastVal = true
+	sli.mu.Unlock()
+}
+
+// Summaries returns a summary of every SLI
+func (r *Registry) Summaries() []Summary {
+	now := r.now()
+	summaries := make([]Summary, 0, len(r.slis))
+	for _, sli := range r.slis {
+		summaries = append(summaries, sli.summary(now))
+	}
+	return summaries
+}
+
+// WritePrometheus writes the SLO metrics in the Prometheus text exposition format
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	now := r.now()
+	summaries := make([]Summary, len(r.slis))
+	for i, sli := range r.slis {
+		summaries[i] = sli.summary(now)
+	}
+
+	var err error
+	write := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	write("# HELP %s_sli_events_total Total events counted towards an SLI.\n", metricsPrefix)
+	write("# TYPE %s_sli_events_total counter\n", metricsPrefix)
+	for _, sli := range r.slis {
+		good, bad := sli.totals()
+		write("%s_sli_events_total{sli=%q,result=\"good\"} %d\n", metricsPrefix, sli.Name, good)
+		write("%s_sli_events_total{sli=%q,result=\"bad\"} %d\n", metricsPrefix, sli.Name, bad)
+	}
+
+	write("# HELP %s_slo_objective Target ratio of good events.\n", metricsPrefix)
+	write("# TYPE %s_slo_objective gauge\n", metricsPrefix)
+	for _, s := range summaries {
+		write("%s_slo_objective{sli=%q} %g\n", metricsPrefix, s.Name, s.Objective)
+	}
+
+	write("# HELP %s_sli_ratio Ratio of good events over a rolling window.\n", metricsPrefix)
+	write("# TYPE %s_sli_ratio gauge\n", metricsPrefix)
+	for _, s := range summaries {
+		for _, ws := range s.Windows {
+			write("%s_sli_ratio{sli=%q,window=%q} %g\n", metricsPrefix, s.Name, ws.Window, ws.SLI)
+		}
+	}
+
+	write("# HELP %s_slo_error_budget_burn_rate Error budget burn rate over a rolling window.\n", metricsPrefix)
+	write("# TYPE %s_slo_error_budget_burn_rate gauge\n", metricsPrefix)
+	for _, s := range summaries {
+		for _, ws := range s.Windows {
+			write("%s_slo_error_budget_burn_rate{sli=%q,window=%q} %g\n", metricsPrefix, s.Name, ws.Window, ws.BurnRate)
+		}
+	}
+
+	write("# HELP %s_slo_error_budget_remaining Ratio of error budget left over the longest window.\n", metricsPrefix)
+	write("# TYPE %s_slo_error_budget_remaining gauge\n", metricsPrefix)
+	for _, s := range summaries {
+		write("%s_slo_error_budget_remaining{sli=%q} %g\n", metricsPrefix, s.Name, s.ErrorBudgetRemaining)
+	}
+
+	if lag, ok := r.byName[ConsumerLag].last(); ok {
+		write("# HELP %s_kafka_consumer_lag_seconds Lag of the most recently consumed event.\n", metricsPrefix)
+		write("# TYPE %s_kafka_consumer_lag_seconds gauge\n", metricsPrefix)
+		write("%s_kafka_consumer_lag_seconds %g\n", metricsPrefix, lag)
+	}
+
+	return err
+}
+
+// record adds an event to the bucket of the current minute
+func (s *SLI) record(now time.Time, good bool) {
+	start := now.Truncate(bucketSize)
+	idx := int(start.Unix()/int64(bucketSize/time.Second)) % retentionBuckets
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := &s.buckets[idx]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	b.total++
+	if good {
+		b.good++
+		s.goodTotal++
+	} else {
+		s.badTotal++
+	}
+}
+
+// totals returns the lifetime good and bad event counts
+func (s *SLI) totals() (uint64, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.goodTotal, s.badTotal
+}
+
+// last returns the last recorded value of the SLI, if any
+func (s *SLI) last() (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastValue, s.hasLastVal
+}
+
+// window returns the good and total event counts within a rolling window
+func (s *SLI) window(now time.Time, window time.Duration) (uint64, uint64) {
+	cutoff := now.Truncate(bucketSize).Add(-window + bucketSize)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var good, total uint64
+	for _, b := range s.buckets {
+		if b.total == 0 || b.start.Before(cutoff) || b.start.After(now) {
+			continue
+		}
+		good += b.good
+		total += b.total
+	}
+	return good, total
+}
+
+// summary summarizes the SLI over every window
+func (s *SLI) summary(now time.Time) Summary {
+	summary := Summary{
+		Name:                 s.Name,
+		Description:          s.Description,
+		Objective:            s.Objective,
+		ErrorBudgetRemaining: 1,
+		Windows:              make([]WindowSummary, 0, len(Windows)),
+	}
+
+	budget := 1 - s.Objective
+	for _, window := range Windows {
+		good, total := s.window(now, window)
+		ws := WindowSummary{
+			Window: formatWindow(window),
+			Good:   good,
+			Total:  total,
+			SLI:    1,
+		}
+		if total > 0 {
+			ws.SLI = float64(good) / float64(total)
+			if budget > 0 {
+				ws.BurnRate = (1 - ws.SLI) / budget
+			}
+		}
+		summary.Windows = append(summary.Windows, ws)
+	}
+
+	// Budget remaining is measured over the longest window
+	if n := len(summary.Windows); n > 0 {
+		summary.ErrorBudgetRemaining = math.Max(0, 1-summary.Windows[n-1].BurnRate)
+	}
+
+	return summary
+}
+
+// formatWindow formats a window duration as a Prometheus-style label
+func formatWindow(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dm", int(d/time.Minute))
+}
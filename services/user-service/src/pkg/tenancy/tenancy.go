@@ -0,0 +1,100 @@
+// Package tenancy carries the organization a request acts on, its tenant,
+// so repositories can keep every query of organization data inside it.
+package tenancy
+
+import (
+	"context"
+
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+)
+
+// OrganizationKey is the context key of the active organization. It is a
+// string key so gin contexts, which look string keys up in their own keys,
+// find it too.
+const OrganizationKey = "tenant_organization_id"
+
+// UnscopedKey is the context key marking contexts whose queries of
+// organization data may access any organization
+const UnscopedKey = "tenant_unscoped"
+
+// OrganizationHeader is the header naming the active organization of
+// requests whose path doesn't name one
+const OrganizationHeader = "X-Organization-ID"
+
+// ErrCrossTenant is returned when organization data of another organization
+// than the active one is queried or written
+var ErrCrossTenant = apperrors.Forbidden("CROSS_TENANT_ACCESS", "Forbidden: data of another organization can't be accessed")
+
+// ErrNoTenant is returned when organization data is queried or written with
+// a context that has neither an active organization nor the unscoped marker
+var ErrNoTenant = apperrors.New(apperrors.KindInternal, "TENANT_REQUIRED", "organization data can't be accessed without a tenant")
+
+// Scoped is implemented by documents that belong to an organization.
+// Repositories only insert Scoped documents into tenant collections, so a
+// document that can't name its organization doesn't compile.
+type Scoped interface {
+	// TenantID returns the ID of the organization the document belongs to
+	TenantID() string
+}
+
+// WithOrganization returns a context whose queries of organization data are
+// kept inside an organization
+func WithOrganization(ctx context.Context, orgID string) context.Context {
+	return context.WithValue(ctx, OrganizationKey, orgID)
+}
+
+// Unscoped returns a context whose queries of organization data may access
+// any organization, for background work, requests acting across the
+// organizations of their user and checks spanning every organization. It
+// leaves the active organization of ctx, if any.
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(context.WithValue(ctx, OrganizationKey, ""), UnscopedKey, true)
+}
+
+// Inherit returns ctx with the active organization, or the unscoped marker,
+// of parent, for background work a request starts with a detached context
+func Inherit(ctx, parent context.Context) context.Context {
+	if orgID := Organization(parent); orgID != "" {
+		return WithOrganization(ctx, orgID)
+	}
+	if IsUnscoped(parent) {
+		return Unscoped(ctx)
+	}
+	return ctx
+}
+
+// Organization gets the active organization of a context, or "" if it has
+// none
+func Organization(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	orgID, _ := ctx.Value(OrganizationKey).(string)
+	return orgID
+}
+
+// IsUnscoped checks if a context was marked to access any organization
+func IsUnscoped(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	unscoped, _ := ctx.Value(UnscopedKey).(bool)
+	return unscoped
+}
+
+// Check checks that organization data of orgID can be accessed with ctx:
+// contexts with an active organization access only their own, unscoped
+// contexts access any, and others none
+func Check(ctx context.Context, orgID string) error {
+	switch active := Organization(ctx); {
+	case active != "":
+		if active != orgID {
+			return ErrCrossTenant
+		}
+		return nil
+	case IsUnscoped(ctx):
+		return nil
+	default:
+		return ErrNoTenant
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,6 +33,19 @@ type TokenClaims struct {
 	Role  string   `json:"role,omitempty"`
 	Type  string   `json:"type,omitempty"`
 	Roles []string `json:"roles,omitempty"`
+
+	// Impersonation is set on tokens of an admin acting as the user
+	Impersonation *ImpersonationClaims `json:"impersonation,omitempty"`
+}
+
+// impersonationClaim is the claim that marks impersonation tokens
+const impersonationClaim = "impersonation"
+
+// ImpersonationClaims identify the impersonation session a token belongs to
+type ImpersonationClaims struct {
+	SessionID string `json:"sid"`
+	AdminID   string `json:"act"`
+	ReadOnly  bool   `json:"readOnly"`
 }
 
 // ExtractToken extracts the token from the authorization header
@@ -48,15 +62,23 @@ func ExtractToken(authHeader string) (string, error) {
 	return parts[1], nil
 }
 
-// ValidateToken validates a JWT token
+// ValidateToken validates a JWT token. User attributes are read from the
+// claims selected by the configured claims mapping.
 func ValidateToken(tokenString string, cfg *config.JWTConfig) (*TokenClaims, error) {
 	// Parse token
-	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+	raw := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, raw, func(token *jwt.Token) (interface{}, error) {
 		// Validate signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(cfg.Secret), nil
+		// Tokens signed with a secret replaced by a rotation stay valid for
+		// its grace period
+		var keys jwt.VerificationKeySet
+		for _, secret := range cfg.VerificationSecrets() {
+			keys.Keys = append(keys.Keys, []byte(secret))
+		}
+		return keys, nil
 	})
 
 	// Handle parsing errors
@@ -66,15 +88,24 @@ func ValidateToken(tokenString string, cfg *config.JWTConfig) (*TokenClaims, err
 		}
 		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
 	}
-
-	// Extract claims
-	claims, ok := token.Claims.(*TokenClaims)
-	if !ok || !token.Valid {
+	if !token.Valid {
 		return nil, ErrInvalidToken
 	}
 
-	// Validate token type
-	if claims.Type != "access" {
+	// Extract claims. Impersonation tokens are minted by this service, so
+	// their claims don't follow the identity provider's mapping.
+	var claims *TokenClaims
+	if _, ok := raw[impersonationClaim]; ok {
+		claims, err = impersonationTokenClaims(raw)
+	} else {
+		claims, err = MapClaims(raw, &cfg.Claims)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	// Validate token type, unless the identity provider doesn't set one
+	if claims.Impersonation == nil && cfg.Claims.AccessTokenType != "" && claims.Type != cfg.Claims.AccessTokenType {
 		return nil, ErrInvalidTokenType
 	}
 
@@ -84,12 +115,267 @@ func ValidateToken(tokenString string, cfg *config.JWTConfig) (*TokenClaims, err
 	}
 
 	// Check expiration
-	if claims.ExpiresAt != nil {
-		expiresAt, err := claims.ExpiresAt.Time()
-		if err != nil || expiresAt.Before(time.Now()) {
-			return nil, ErrTokenExpired
+	if claims.ExpiresAt != nil && claims.ExpiresAt.Before(time.Now()) {
+		return nil, ErrTokenExpired
+	}
+
+	return claims, nil
+}
+
+// MapClaims reads the user attributes of a token from its raw claims using a
+// claims mapping. The subject is required; other attributes are optional.
+func MapClaims(raw jwt.MapClaims, mapping *config.JWTClaimsConfig) (*TokenClaims, error) {
+	claims := &TokenClaims{}
+
+	// Registered claims
+	claims.Issuer, _ = raw["iss"].(string)
+	expiresAt, err := raw.GetExpirationTime()
+	if err != nil {
+		return nil, err
+	}
+	claims.ExpiresAt = expiresAt
+
+	subject, err := claimStrings(raw, mapping.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if len(subject) == 0 || subject[0] == "" {
+		return nil, fmt.Errorf("missing subject claim %q", mapping.Subject)
+	}
+	claims.Subject = subject[0]
+
+	if email, err := claimStrings(raw, mapping.Email); err != nil {
+		return nil, err
+	} else if len(email) > 0 {
+		claims.Email = email[0]
+	}
+
+	if role, err := claimStrings(raw, mapping.Role); err != nil {
+		return nil, err
+	} else if len(role) > 0 {
+		claims.Role = role[0]
+	}
+
+	if tokenType, err := claimStrings(raw, mapping.TokenType); err != nil {
+		return nil, err
+	} else if len(tokenType) > 0 {
+		claims.Type = tokenType[0]
+	}
+
+	// Roles are merged from every roles path, e.g. realm and client roles
+	seen := make(map[string]bool)
+	for _, path := range claimPaths(mapping.Roles) {
+		roles, err := claimStrings(raw, path)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range roles {
+			if role != "" && !seen[role] {
+				seen[role] = true
+				claims.Roles = append(claims.Roles, role)
+			}
 		}
 	}
 
 	return claims, nil
 }
+
+// NewImpersonationToken mints a token for an admin to act as a user in an
+// impersonation session. It carries the user's identity and role, and
+// expires with the session.
+func NewImpersonationToken(cfg *config.JWTConfig, userID, email, role string, impersonation ImpersonationClaims, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"sub":   userID,
+		"email": email,
+		"role":  role,
+		"roles": []string{role},
+		"iss":   cfg.Issuer,
+		"iat":   time.Now().Unix(),
+		"exp":   expiresAt.Unix(),
+		impersonationClaim: map[string]interface{}{
+			"sid":      impersonation.SessionID,
+			"act":      impersonation.AdminID,
+			"readOnly": impersonation.ReadOnly,
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.SigningSecret()))
+}
+
+// impersonationTokenClaims reads the claims of an impersonation token
+func impersonationTokenClaims(raw jwt.MapClaims) (*TokenClaims, error) {
+	impersonation, ok := raw[impersonationClaim].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid impersonation claim")
+	}
+
+	claims := &TokenClaims{Impersonation: &ImpersonationClaims{}}
+	claims.Impersonation.SessionID, _ = impersonation["sid"].(string)
+	claims.Impersonation.AdminID, _ = impersonation["act"].(string)
+	claims.Impersonation.ReadOnly, _ = impersonation["readOnly"].(bool)
+	if claims.Impersonation.SessionID == "" || claims.Impersonation.AdminID == "" {
+		return nil, errors.New("incomplete impersonation claim")
+	}
+
+	claims.Issuer, _ = raw["iss"].(string)
+	expiresAt, err := raw.GetExpirationTime()
+	if err != nil {
+		return nil, err
+	}
+	claims.ExpiresAt = expiresAt
+
+	claims.Subject, _ = raw["sub"].(string)
+	if claims.Subject == "" {
+		return nil, errors.New("missing subject claim")
+	}
+	claims.Email, _ = raw["email"].(string)
+	claims.Role, _ = raw["role"].(string)
+	if claims.Role != "" {
+		claims.Roles = []string{claims.Role}
+	}
+
+	return claims, nil
+}
+
+// ValidateClaimsMapping checks that every path of a claims mapping parses
+func ValidateClaimsMapping(mapping *config.JWTClaimsConfig) error {
+	if mapping.Subject == "" {
+		return errors.New("a subject claim path is required")
+	}
+
+	paths := []string{mapping.Subject, mapping.Email, mapping.Role, mapping.TokenType}
+	paths = append(paths, claimPaths(mapping.Roles)...)
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if _, err := parseClaimPath(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// claimSegment is a key or array index of a claim path
+type claimSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseClaimPath parses a JSONPath-style claim path. Keys are separated by
+// dots, and keys containing dots or other special characters are quoted in
+// brackets, e.g. "realm_access.roles", "$.resource_access['my-app'].roles"
+// or "$['https://example.com/roles']". Array elements are selected with [n].
+func parseClaimPath(path string) ([]claimSegment, error) {
+	invalid := func() ([]claimSegment, error) {
+		return nil, fmt.Errorf("invalid claim path %q", path)
+	}
+
+	p := strings.TrimSpace(path)
+	p = strings.TrimPrefix(p, "$")
+	if p == "" {
+		return invalid()
+	}
+
+	var segments []claimSegment
+	for i := 0; i < len(p); {
+		switch {
+		case p[i] == '[':
+			end := strings.IndexByte(p[i:], ']')
+			if end < 0 {
+				return invalid()
+			}
+			inner := p[i+1 : i+end]
+			i += end + 1
+
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, claimSegment{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return invalid()
+			}
+			segments = append(segments, claimSegment{index: index, isIndex: true})
+		default:
+			// A dot separates keys; it is optional at the start of the path
+			if p[i] == '.' {
+				i++
+			} else if len(segments) > 0 {
+				return invalid()
+			}
+			end := strings.IndexAny(p[i:], ".[")
+			if end < 0 {
+				end = len(p) - i
+			}
+			if end == 0 {
+				return invalid()
+			}
+			segments = append(segments, claimSegment{key: p[i : i+end]})
+			i += end
+		}
+	}
+
+	return segments, nil
+}
+
+// claimStrings returns the string values of the claim at a path. A string
+// claim gives one value and an array claim gives its string elements. An
+// empty path or a missing claim gives no values.
+func claimStrings(raw map[string]interface{}, path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	segments, err := parseClaimPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{} = raw
+	for _, segment := range segments {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if segment.isIndex {
+				return nil, nil
+			}
+			value = v[segment.key]
+		case []interface{}:
+			if !segment.isIndex || segment.index >= len(v) {
+				return nil, nil
+			}
+			value = v[segment.index]
+		default:
+			return nil, nil
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, elem := range v {
+			if s, ok := elem.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values, nil
+	default:
+		return nil, nil
+	}
+}
+
+// claimPaths splits roles paths given as comma-separated lists
+func claimPaths(entries []string) []string {
+	var paths []string
+	for _, entry := range entries {
+		for _, path := range strings.Split(entry, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrCircuitOpen is returned for calls a circuit breaker rejects
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a circuit breaker
+type CircuitState string
+
+// Circuit states
+const (
+	// CircuitClosed lets every call through
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects every call until the open timeout passes
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets one trial call through to probe the service
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitBreaker stops calling a service after consecutive failures, so
+// callers fail fast instead of piling up on a service that is down. Once the
+// open timeout passes, one trial call decides if it closes again.
+type CircuitBreaker struct {
+	name        string
+	maxFailures int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker creates a circuit breaker that opens after maxFailures
+// consecutive failures and stays open for openTimeout
+func NewCircuitBreaker(name string, maxFailures int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:        name,
+		maxFailures: maxFailures,
+		openTimeout: openTimeout,
+		state:       CircuitClosed,
+	}
+}
+
+// Allow checks if a call may be made. Callers that are allowed must record
+// the call's outcome.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return true
+	case CircuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Record records the outcome of a call
+func (b *CircuitBreaker) Record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		if b.state != CircuitClosed {
+			log.Info().Str("breaker", b.name).Msg("Circuit breaker closed")
+		}
+		b.state = CircuitClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.maxFailures {
+		if b.state != CircuitOpen {
+			log.Warn().Str("breaker", b.name).Int("failures", b.failures).Msg("Circuit breaker opened")
+		}
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Release releases an allowed call that was abandoned by its caller, without
+// recording an outcome
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// State gets the state of the circuit breaker
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// OpenError gets the error of a call rejected by the circuit breaker
+func (b *CircuitBreaker) OpenError() error {
+	return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+}
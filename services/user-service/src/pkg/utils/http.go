@@ -1,14 +1,18 @@
 package utils
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"strconv"
 	"time"
 
-	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
 )
 
 // HTTPClient defines an interface for HTTP clients
@@ -21,6 +25,9 @@ var (
 	Client HTTPClient = &http.Client{
 		Timeout: 10 * time.Second,
 	}
+
+	// AuthServiceBreaker guards calls to the Auth Service
+	AuthServiceBreaker = NewCircuitBreaker("auth-service", 5, 30*time.Second)
 )
 
 // HTTPError represents an error response from an HTTP request
@@ -35,41 +42,67 @@ func (e *HTTPError) Error() string {
 	return fmt.Sprintf("%d %s: %s", e.Status, e.StatusText, e.Message)
 }
 
-// Get makes a GET request to the specified URL
-func Get(ctx context.Context, url string, headers map[string]string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
+// RetryPolicy controls how a failed call is retried. Delays grow
+// exponentially from BaseDelay up to MaxDelay, with full jitter.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts, including the first one
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
 
-	// Add headers
-	for key, value := range headers {
-		req.Header.Add(key, value)
-	}
+// CallOptions configure the outbound calls made with a context
+type CallOptions struct {
+	// Timeout bounds each attempt of a call
+	Timeout time.Duration
+	Retry   RetryPolicy
+	// Breaker, if set, fails calls fast while the called service is down
+	Breaker *CircuitBreaker
+}
 
-	// Execute request
-	res, err := Client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error executing request: %w", err)
-	}
-	defer res.Body.Close()
+// DefaultCallOptions are used by calls whose context has no options
+var DefaultCallOptions = CallOptions{
+	Timeout: 5 * time.Second,
+	Retry: RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+	},
+}
 
-	// Read response body
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
-	}
+// HTTPHooks are called around every attempt of an outbound call, for
+// metrics and tracing
+type HTTPHooks struct {
+	// BeforeRequest is called before an attempt is sent, and may add
+	// headers such as trace context
+	BeforeRequest func(req *http.Request)
+	// AfterResponse is called once an attempt completes, with the response
+	// status or 0 if no response was received
+	AfterResponse func(req *http.Request, status int, duration time.Duration, err error)
+}
 
-	// Check status code
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return nil, &HTTPError{
-			Status:     res.StatusCode,
-			StatusText: res.Status,
-			Message:    string(body),
-		}
+// Hooks are the hooks of outbound calls; set them at startup
+var Hooks HTTPHooks
+
+// callOptionsKey is the context key of call options
+type callOptionsKey struct{}
+
+// WithCallOptions returns a context whose outbound calls use the options
+func WithCallOptions(ctx context.Context, opts CallOptions) context.Context {
+	return context.WithValue(ctx, callOptionsKey{}, opts)
+}
+
+// callOptions gets the call options of a context
+func callOptions(ctx context.Context) CallOptions {
+	if opts, ok := ctx.Value(callOptionsKey{}).(CallOptions); ok {
+		return opts
 	}
+	return DefaultCallOptions
+}
 
-	return body, nil
+// Get makes a GET request to the specified URL
+func Get(ctx context.Context, url string, headers map[string]string) ([]byte, error) {
+	return do(ctx, http.MethodGet, url, headers, nil)
 }
 
 // Post makes a POST request to the specified URL with the given body
@@ -80,43 +113,206 @@ func Post(ctx context.Context, url string, headers map[string]string, body inter
 		return nil, fmt.Errorf("error marshaling request body: %w", err)
 	}
 
+	return do(ctx, http.MethodPost, url, headers, jsonBody)
+}
+
+// do makes a request, retrying failed attempts as the context's call options
+// allow. POST requests are only retried when the server rejected them
+// without processing them, so they are never applied twice.
+func do(ctx context.Context, method, url string, headers map[string]string, body []byte) ([]byte, error) {
+	opts := callOptions(ctx)
+
+	attempts := opts.Retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			delay := backoff(opts.Retry, attempt, lastErr)
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("error executing request: %w", ctx.Err())
+			case <-time.After(delay):
+			}
+			logger.Ctx(ctx).Debug().Err(lastErr).Str("method", method).Str("url", url).Int("attempt", attempt+1).
+				Msg("Retrying request")
+		}
+
+		if opts.Breaker != nil && !opts.Breaker.Allow() {
+			return nil, fmt.Errorf("error executing request: %w", opts.Breaker.OpenError())
+		}
+
+		resBody, err := doAttempt(ctx, method, url, headers, body, opts.Timeout)
+		if opts.Breaker != nil {
+			// Calls the caller gave up on say nothing about the service
+			if err != nil && ctx.Err() != nil {
+				opts.Breaker.Release()
+			} else {
+				opts.Breaker.Record(isServerFailure(err))
+			}
+		}
+		if err == nil {
+			return resBody, nil
+		}
+
+		lastErr = err
+		if ctx.Err() != nil || !retryable(method, err) {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+// doAttempt makes one attempt of a request, bounded by the timeout
+func doAttempt(ctx context.Context, method, url string, headers map[string]string, body []byte, timeout time.Duration) ([]byte, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, io.NopCloser(io.Reader(io.New(jsonBody))))
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	// Add headers
-	req.Header.Add("Content-Type", "application/json")
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	if correlationID := logger.CorrelationID(ctx); correlationID != "" {
+		req.Header.Set(logger.CorrelationIDHeader, correlationID)
+	}
 	for key, value := range headers {
 		req.Header.Add(key, value)
 	}
 
+	if Hooks.BeforeRequest != nil {
+		Hooks.BeforeRequest(req)
+	}
+	start := time.Now()
+	status := 0
+	if Hooks.AfterResponse != nil {
+		defer func() {
+			Hooks.AfterResponse(req, status, time.Since(start), err)
+		}()
+	}
+
 	// Execute request
 	res, err := Client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error executing request: %w", err)
+		err = fmt.Errorf("error executing request: %w", err)
+		return nil, err
 	}
 	defer res.Body.Close()
+	status = res.StatusCode
 
 	// Read response body
 	resBody, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		err = fmt.Errorf("error reading response body: %w", err)
+		return nil, err
 	}
 
 	// Check status code
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return nil, &HTTPError{
+		err = &HTTPError{
 			Status:     res.StatusCode,
 			StatusText: res.Status,
 			Message:    string(resBody),
 		}
+		if retryAfter := res.Header.Get("Retry-After"); retryAfter != "" {
+			if seconds, convErr := strconv.Atoi(retryAfter); convErr == nil && seconds > 0 {
+				err = &retryAfterError{err: err, delay: time.Duration(seconds) * time.Second}
+			}
+		}
+		return nil, err
 	}
 
 	return resBody, nil
 }
 
+// retryAfterError carries the delay a server asked for before a retry
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+// Error implements the error interface
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the HTTP error
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+// retryable checks if a failed attempt of a request may be retried.
+// Requests that got no response are only retried when they are idempotent.
+func retryable(method string, err error) bool {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return method != http.MethodPost
+	}
+
+	switch httpErr.Status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return method != http.MethodPost
+	default:
+		return false
+	}
+}
+
+// isServerFailure checks if a failed attempt counts against the called
+// service's circuit breaker: it got no response or a 5xx one
+func isServerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Status >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// backoff gets the delay before a retry. It is a random delay up to the
+// exponential backoff of the attempt, unless the server asked for a longer
+// one.
+func backoff(policy RetryPolicy, attempt int, err error) time.Duration {
+	ceiling := policy.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || (policy.MaxDelay > 0 && ceiling > policy.MaxDelay) {
+		ceiling = policy.MaxDelay
+	}
+
+	var delay time.Duration
+	if ceiling > 0 {
+		delay = time.Duration(rand.Int63n(int64(ceiling) + 1))
+	}
+
+	var retryAfter *retryAfterError
+	if errors.As(err, &retryAfter) && retryAfter.delay > delay {
+		delay = retryAfter.delay
+		if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+	}
+	return delay
+}
+
 // GetJSON makes a GET request to the specified URL and unmarshals the response into the result
 func GetJSON(ctx context.Context, url string, headers map[string]string, result interface{}) error {
 	body, err := Get(ctx, url, headers)
@@ -126,7 +322,7 @@ func GetJSON(ctx context.Context, url string, headers map[string]string, result
 
 	// Unmarshal response
 	if err := json.Unmarshal(body, result); err != nil {
-		log.Error().Err(err).Str("url", url).Str("response", string(body)).Msg("Error unmarshaling response")
+		logger.Ctx(ctx).Error().Err(err).Str("url", url).Str("response", string(body)).Msg("Error unmarshaling response")
 		return fmt.Errorf("error unmarshaling response: %w", err)
 	}
 
@@ -142,7 +338,7 @@ func PostJSON(ctx context.Context, url string, headers map[string]string, body,
 
 	// Unmarshal response
 	if err := json.Unmarshal(resBody, result); err != nil {
-		log.Error().Err(err).Str("url", url).Str("response", string(resBody)).Msg("Error unmarshaling response")
+		logger.Ctx(ctx).Error().Err(err).Str("url", url).Str("response", string(resBody)).Msg("Error unmarshaling response")
 		return fmt.Errorf("error unmarshaling response: %w", err)
 	}
 
@@ -159,6 +355,11 @@ func VerifyTokenWithAuthService(ctx context.Context, token string, authServiceUR
 		"Authorization": fmt.Sprintf("Bearer %s", token),
 	}
 
+	// Fail fast while the Auth Service is down
+	opts := callOptions(ctx)
+	opts.Breaker = AuthServiceBreaker
+	ctx = WithCallOptions(ctx, opts)
+
 	// Make request
 	var result struct {
 		Status  string                 `json:"status"`
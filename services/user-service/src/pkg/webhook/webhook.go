@@ -0,0 +1,187 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/pkg/utils"
+)
+
+// Header names sent with every webhook call
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+)
+
+// maxResponseSize limits how much of a webhook response is read
+const maxResponseSize = 1 << 20
+
+// ErrForbiddenAddress is returned when a webhook URL resolves to an address
+// that isn't public, such as a loopback, private or link-local one
+var ErrForbiddenAddress = errors.New("webhook address is not public")
+
+// nonPublicNetworks are the reserved networks net.IP has no check for
+var nonPublicNetworks = mustParseCIDRs(
+	"0.0.0.0/8",      // "this" network
+	"100.64.0.0/10",  // carrier-grade NAT
+	"192.0.0.0/24",   // IETF protocol assignments
+	"198.18.0.0/15",  // benchmarking
+	"240.0.0.0/4",    // reserved, including broadcast
+	"64:ff9b:1::/48", // local-use IPv4/IPv6 translation
+	"2001:db8::/32",  // documentation
+)
+
+// Client is the HTTP client webhooks are sent with. Organizations choose
+// webhook URLs, so it only connects to public addresses. The check runs on
+// the address dialed, after DNS resolution and on every redirect, so names
+// that resolve or rebind to internal addresses are refused too. Proxies
+// would dial in its place, so none is used.
+var Client utils.HTTPClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   refuseNonPublic,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+// refuseNonPublic is a net.Dialer Control hook that refuses connections to
+// addresses that aren't public
+func refuseNonPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// IsPublic checks if an IP address is publicly routable
+func IsPublic(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// mustParseCIDRs parses CIDR blocks, panicking on invalid ones
+func mustParseCIDRs(blocks ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(blocks))
+	for _, block := range blocks {
+		_, network, err := net.ParseCIDR(block)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// Sign computes the HMAC-SHA256 signature of a payload
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Post sends a signed JSON payload to a webhook URL and decodes the JSON response into result
+func Post(ctx context.Context, url, secret string, payload interface{}, result interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshaling webhook payload: %w", err)
+	}
+
+	_, resBody, err := send(ctx, url, secret, body)
+	if err != nil {
+		return err
+	}
+
+	if result == nil || len(resBody) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(resBody, result); err != nil {
+		return fmt.Errorf("error unmarshaling webhook response: %w", err)
+	}
+
+	return nil
+}
+
+// Deliver sends an already encoded, signed JSON payload to a webhook URL and
+// returns the response status code. Non-2xx responses return a
+// *utils.HTTPError, without the response body.
+func Deliver(ctx context.Context, url, secret string, body []byte) (int, error) {
+	status, _, err := send(ctx, url, secret, body)
+	return status, err
+}
+
+// send POSTs a signed JSON body and returns the response status and body.
+// The body of non-2xx responses is dropped: the receiver isn't trusted, and
+// its errors would otherwise be shown to whoever configured the webhook.
+func send(ctx context.Context, url, secret string, body []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, fmt.Errorf("error creating webhook request: %w", err)
+	}
+
+	// Sign request
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "user-service-webhook")
+	req.Header.Set(TimestampHeader, timestamp)
+	if secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+	}
+
+	// Execute request
+	res, err := Client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error executing webhook request: %w", err)
+	}
+	defer res.Body.Close()
+
+	// Check status code
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, nil, &utils.HTTPError{
+			Status:     res.StatusCode,
+			StatusText: http.StatusText(res.StatusCode),
+			Message:    "webhook responded with an error status",
+		}
+	}
+
+	resBody, err := io.ReadAll(io.LimitReader(res.Body, maxResponseSize))
+	if err != nil {
+		return res.StatusCode, nil, fmt.Errorf("error reading webhook response: %w", err)
+	}
+
+	return res.StatusCode, resBody, nil
+}
@@ -0,0 +1,64 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/your-username/slido-clone/user-service/pkg/utils"
+	"github.com/your-username/slido-clone/user-service/pkg/webhook"
+)
+
+func TestDeliverRefusesNonPublicAddresses(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	// The test server listens on loopback, which webhooks never reach
+	_, err := webhook.Deliver(context.Background(), server.URL, "secret", []byte(`{}`))
+	if !errors.Is(err, webhook.ErrForbiddenAddress) {
+		t.Fatalf("Deliver(%s): err = %v, want ErrForbiddenAddress", server.URL, err)
+	}
+	if called {
+		t.Errorf("webhook reached a loopback address")
+	}
+
+	for _, address := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1"} {
+		if webhook.IsPublic(net.ParseIP(address)) {
+			t.Errorf("IsPublic(%s) = true, want false", address)
+		}
+	}
+	for _, address := range []string{"8.8.8.8", "2606:4700::1111"} {
+		if !webhook.IsPublic(net.ParseIP(address)) {
+			t.Errorf("IsPublic(%s) = false, want true", address)
+		}
+	}
+}
+
+func TestDeliverDropsErrorResponseBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("internal secret"))
+	}))
+	defer server.Close()
+
+	// Reach the test server with an unguarded client
+	client := webhook.Client
+	webhook.Client = server.Client()
+	defer func() { webhook.Client = client }()
+
+	status, err := webhook.Deliver(context.Background(), server.URL, "secret", []byte(`{}`))
+	var httpErr *utils.HTTPError
+	if !errors.As(err, &httpErr) || status != http.StatusBadGateway {
+		t.Fatalf("Deliver: status %d, err = %v; want %d and an HTTP error", status, err, http.StatusBadGateway)
+	}
+	if strings.Contains(err.Error(), "internal secret") {
+		t.Errorf("error %q includes the response body", err)
+	}
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ActivityRepository is a repository for user activities
+type ActivityRepository struct {
+	collection db.Collection
+}
+
+// NewActivityRepository creates a new activity repository
+func NewActivityRepository(store db.Storage) *ActivityRepository {
+	return &ActivityRepository{
+		collection: store.GetCollection(db.ActivitiesCollection),
+	}
+}
+
+// Create creates a new activity
+func (r *ActivityRepository) Create(ctx context.Context, activity *models.Activity) error {
+	_, err := r.collection.InsertOne(ctx, activity)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", activity.UserID).Str("type", string(activity.Type)).
+			Msg("Error creating activity")
+		return err
+	}
+
+	return nil
+}
+
+// Find lists the activities matching a filter, newest first, starting after
+// the cursor
+func (r *ActivityRepository) Find(ctx context.Context, filter models.ActivityFilter, after *models.TimelineCursor, limit int) ([]*models.Activity, error) {
+	var activities []*models.Activity
+
+	query := bson.M{}
+	if filter.UserID != "" {
+		query["userId"] = filter.UserID
+	}
+	if filter.OrganizationID != "" {
+		query["organizationId"] = filter.OrganizationID
+	}
+	if after != nil {
+		query["$or"] = []bson.M{
+			{"occurredAt": bson.M{"$lt": after.OccurredAt}},
+			{"occurredAt": after.OccurredAt, "_id": bson.M{"$lt": after.ID}},
+		}
+	}
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "occurredAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", filter.UserID).Str("orgId", filter.OrganizationID).
+			Msg("Error finding activities")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &activities); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding activities")
+		return nil, err
+	}
+
+	return activities, nil
+}
@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// aggregate runs an aggregation pipeline and decodes all its results. It
+// returns db.ErrAggregationUnsupported for drivers without pipelines.
+func aggregate(ctx context.Context, collection db.Collection, pipeline mongo.Pipeline, results interface{}) error {
+	cursor, err := db.Aggregate(ctx, collection, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	return cursor.All(ctx, results)
+}
+
+// dateTrunc truncates a date field to the start of its bucket of interval,
+// in UTC with weeks starting on Monday like models.StatsInterval
+func dateTrunc(field string, interval models.StatsInterval) bson.M {
+	return bson.M{"$dateTrunc": bson.M{
+		"date":        "$" + field,
+		"unit":        string(interval),
+		"startOfWeek": "monday",
+		"timezone":    "UTC",
+	}}
+}
@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+)
+
+// AuditRepository is a repository for the audit log
+type AuditRepository struct {
+	collection db.Collection
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(store db.Storage) *AuditRepository {
+	return &AuditRepository{
+		collection: store.GetCollection(db.AuditLogCollection),
+	}
+}
+
+// Create records an audit entry
+func (r *AuditRepository) Create(ctx context.Context, entry *models.AuditEntry) error {
+	_, err := r.collection.InsertOne(ctx, entry)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("actorId", entry.ActorID).Str("path", entry.Path).
+			Msg("Error creating audit entry")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BannerRepository is a repository for the platform banner
+type BannerRepository struct {
+	collection db.Collection
+}
+
+// NewBannerRepository creates a new banner repository
+func NewBannerRepository(store db.Storage) *BannerRepository {
+	return &BannerRepository{
+		collection: store.GetCollection(db.BannersCollection),
+	}
+}
+
+// Get gets the platform banner
+func (r *BannerRepository) Get(ctx context.Context) (*models.Banner, error) {
+	var banner models.Banner
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": models.PlatformBannerID}).Decode(&banner)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Msg("Error getting platform banner")
+		return nil, err
+	}
+
+	return &banner, nil
+}
+
+// Save sets the platform banner
+func (r *BannerRepository) Save(ctx context.Context, banner *models.Banner) error {
+	filter := bson.M{"_id": models.PlatformBannerID}
+	update := bson.M{
+		"$set": bson.M{
+			"message":   banner.Message,
+			"severity":  banner.Severity,
+			"startsAt":  banner.StartsAt,
+			"endsAt":    banner.EndsAt,
+			"updatedBy": banner.UpdatedBy,
+			"updatedAt": banner.UpdatedAt,
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error saving platform banner")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("severity", string(banner.Severity)).Msg("Platform banner saved")
+	return nil
+}
+
+// Delete removes the platform banner
+func (r *BannerRepository) Delete(ctx context.Context) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": models.PlatformBannerID})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error deleting platform banner")
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Msg("Platform banner deleted")
+	return nil
+}
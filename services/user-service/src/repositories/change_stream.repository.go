@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ChangeStreamRepository is a repository for change stream resume tokens
+type ChangeStreamRepository struct {
+	collection db.Collection
+}
+
+// NewChangeStreamRepository creates a new change stream repository
+func NewChangeStreamRepository(store db.Storage) *ChangeStreamRepository {
+	return &ChangeStreamRepository{
+		collection: store.GetCollection(db.ChangeStreamsCollection),
+	}
+}
+
+// GetResumeToken gets the resume token of a stream, or nil if the stream
+// hasn't been read yet
+func (r *ChangeStreamRepository) GetResumeToken(ctx context.Context, stream string) (bson.Raw, error) {
+	var token models.ChangeStreamToken
+	err := r.collection.FindOne(ctx, bson.M{"_id": stream}).Decode(&token)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("stream", stream).Msg("Error getting change stream resume token")
+		return nil, err
+	}
+
+	return token.ResumeToken, nil
+}
+
+// SaveResumeToken records the resume token of a stream
+func (r *ChangeStreamRepository) SaveResumeToken(ctx context.Context, stream string, resumeToken bson.Raw) error {
+	filter := bson.M{"_id": stream}
+	update := bson.M{
+		"$set": bson.M{
+			"resumeToken": resumeToken,
+			"updatedAt":   time.Now(),
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("stream", stream).Msg("Error saving change stream resume token")
+		return err
+	}
+
+	return nil
+}
+
+// DeleteResumeToken forgets the resume token of a stream, so it's read from
+// the current change on
+func (r *ChangeStreamRepository) DeleteResumeToken(ctx context.Context, stream string) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": stream}); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("stream", stream).Msg("Error deleting change stream resume token")
+		return err
+	}
+
+	return nil
+}
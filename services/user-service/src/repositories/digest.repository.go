@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DigestRepository is a repository for the notifications held for daily
+// digests
+type DigestRepository struct {
+	collection db.Collection
+}
+
+// NewDigestRepository creates a new digest repository
+func NewDigestRepository(store db.Storage) *DigestRepository {
+	return &DigestRepository{
+		collection: store.GetCollection(db.DigestsCollection),
+	}
+}
+
+// Queue holds a notification until its digest is due
+func (r *DigestRepository) Queue(ctx context.Context, notification *models.Notification) error {
+	_, err := r.collection.InsertOne(ctx, notification)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", notification.UserID).Str("type", string(notification.Type)).
+			Msg("Error queueing notification for digest")
+		return err
+	}
+
+	return nil
+}
+
+// FindDueUsers gets the IDs of up to limit users with a digest due at a time,
+// the longest overdue first
+func (r *DigestRepository) FindDueUsers(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	var notifications []*models.Notification
+
+	filter := bson.M{"dueAt": bson.M{"$lte": now}}
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "dueAt", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding due digests")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &notifications); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding due digests")
+		return nil, err
+	}
+
+	// Users with several due notifications are listed once
+	seen := make(map[string]bool, len(notifications))
+	var userIDs []string
+	for _, notification := range notifications {
+		if !seen[notification.UserID] {
+			seen[notification.UserID] = true
+			userIDs = append(userIDs, notification.UserID)
+		}
+	}
+
+	return userIDs, nil
+}
+
+// FindDue gets up to limit of a user's notifications whose digest is due at a
+// time, oldest first
+func (r *DigestRepository) FindDue(ctx context.Context, userID string, now time.Time, limit int) ([]*models.Notification, error) {
+	var notifications []*models.Notification
+
+	filter := bson.M{
+		"userId": userID,
+		"dueAt":  bson.M{"$lte": now},
+	}
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "occurredAt", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error finding due notifications")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &notifications); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding due notifications")
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// Delete deletes a held notification once its digest was sent
+func (r *DigestRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("notificationId", id).Msg("Error deleting digest notification")
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
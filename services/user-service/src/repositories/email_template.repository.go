@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrEmailTemplateVersionConflict is returned when another change created the same template version first
+var ErrEmailTemplateVersionConflict = apperrors.Conflict("EMAIL_TEMPLATE_VERSION_CONFLICT", "email template was modified concurrently")
+
+// EmailTemplateRepository is a repository for organization email template versions
+type EmailTemplateRepository struct {
+	collection db.Collection
+}
+
+// NewEmailTemplateRepository creates a new email template repository
+func NewEmailTemplateRepository(store db.Storage) *EmailTemplateRepository {
+	return &EmailTemplateRepository{
+		collection: store.GetCollection(db.EmailTemplatesCollection),
+	}
+}
+
+// Create stores a new email template version
+func (r *EmailTemplateRepository) Create(ctx context.Context, template *models.EmailTemplate) error {
+	_, err := r.collection.InsertOne(ctx, template)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrEmailTemplateVersionConflict
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", template.OrganizationID).Str("key", string(template.Key)).
+			Msg("Error creating email template version")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("orgId", template.OrganizationID).Str("key", string(template.Key)).
+		Int("version", template.Version).Msg("Email template version created")
+	return nil
+}
+
+// GetLatest gets the latest version of an organization's email template
+func (r *EmailTemplateRepository) GetLatest(ctx context.Context, orgID string, key models.EmailTemplateKey) (*models.EmailTemplate, error) {
+	var template models.EmailTemplate
+
+	filter := bson.M{"organizationId": orgID, "key": key}
+	opts := options.FindOne().SetSort(bson.M{"version": -1})
+
+	err := r.collection.FindOne(ctx, filter, opts).Decode(&template)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("key", string(key)).Msg("Error getting email template")
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// GetVersions gets every version of an organization's email template, newest first
+func (r *EmailTemplateRepository) GetVersions(ctx context.Context, orgID string, key models.EmailTemplateKey) ([]*models.EmailTemplate, error) {
+	filter := bson.M{"organizationId": orgID, "key": key}
+	return r.find(ctx, filter, bson.D{{Key: "version", Value: -1}})
+}
+
+// GetByOrganization gets every version of every email template of an organization,
+// grouped by key with the newest version first
+func (r *EmailTemplateRepository) GetByOrganization(ctx context.Context, orgID string) ([]*models.EmailTemplate, error) {
+	filter := bson.M{"organizationId": orgID}
+	return r.find(ctx, filter, bson.D{{Key: "key", Value: 1}, {Key: "version", Value: -1}})
+}
+
+// find finds email template versions
+func (r *EmailTemplateRepository) find(ctx context.Context, filter bson.M, sort bson.D) ([]*models.EmailTemplate, error) {
+	var templates []*models.EmailTemplate
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(sort))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Interface("filter", filter).Msg("Error finding email templates")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &templates); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding email templates")
+		return nil, err
+	}
+
+	return templates, nil
+}
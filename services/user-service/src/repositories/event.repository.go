@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrEventCodeTaken is returned when another event already has the join
+// code; the code is regenerated
+var ErrEventCodeTaken = errors.New("event code already taken")
+
+// EventRepository is a repository for organization events
+type EventRepository struct {
+	collection db.Collection
+}
+
+// NewEventRepository creates a new event repository
+func NewEventRepository(store db.Storage) *EventRepository {
+	return &EventRepository{
+		collection: tenantCollection(store, db.EventsCollection, "organizationId"),
+	}
+}
+
+// Create creates a new event
+func (r *EventRepository) Create(ctx context.Context, event *models.Event) error {
+	_, err := insertScoped(ctx, r.collection, event)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrEventCodeTaken
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", event.OrganizationID).Msg("Error creating event")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", event.ID).Str("orgId", event.OrganizationID).Msg("Event created")
+	return nil
+}
+
+// GetByID gets an event of an organization by ID
+func (r *EventRepository) GetByID(ctx context.Context, orgID, id string) (*models.Event, error) {
+	var event models.Event
+
+	filter := bson.M{"_id": id, "organizationId": orgID}
+	err := r.collection.FindOne(ctx, filter).Decode(&event)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error getting event by ID")
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// GetByCode gets an event by its join code
+func (r *EventRepository) GetByCode(ctx context.Context, code string) (*models.Event, error) {
+	var event models.Event
+
+	err := r.collection.FindOne(ctx, bson.M{"code": code}).Decode(&event)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("code", code).Msg("Error getting event by code")
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// Find gets a page of the events of an organization, latest start first
+func (r *EventRepository) Find(ctx context.Context, filter models.EventFilter, page, limit int) ([]*models.Event, int64, error) {
+	var events []*models.Event
+
+	query := bson.M{"organizationId": filter.OrganizationID}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	// Count total
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", filter.OrganizationID).Msg("Error counting events")
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "startsAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", filter.OrganizationID).Msg("Error finding events")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &events); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding events")
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+// Update updates the details, schedule and hosts of an event, unless it has
+// ended meanwhile. It returns mongo.ErrNoDocuments if the event is missing
+// or ended.
+func (r *EventRepository) Update(ctx context.Context, event *models.Event) error {
+	filter := bson.M{
+		"_id":            event.ID,
+		"organizationId": event.OrganizationID,
+		"status":         bson.M{"$ne": models.EventEnded},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"title":       event.Title,
+			"description": event.Description,
+			"startsAt":    event.StartsAt,
+			"endsAt":      event.EndsAt,
+			"hostIds":     event.HostIDs,
+			"updatedAt":   event.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", event.ID).Msg("Error updating event")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", event.ID).Msg("Event updated")
+	return nil
+}
+
+// Transition moves an event from a status to its current one. It returns
+// mongo.ErrNoDocuments if the event no longer has the from status.
+func (r *EventRepository) Transition(ctx context.Context, event *models.Event, from models.EventStatus) error {
+	filter := bson.M{
+		"_id":            event.ID,
+		"organizationId": event.OrganizationID,
+		"status":         from,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":    event.Status,
+			"startedAt": event.StartedAt,
+			"endedAt":   event.EndedAt,
+			"updatedAt": event.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", event.ID).Msg("Error changing event status")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", event.ID).Str("status", string(event.Status)).Msg("Event status changed")
+	return nil
+}
+
+// Delete deletes an event of an organization unless it is live. It returns
+// mongo.ErrNoDocuments if the event is missing or live.
+func (r *EventRepository) Delete(ctx context.Context, orgID, id string) error {
+	filter := bson.M{
+		"_id":            id,
+		"organizationId": orgID,
+		"status":         bson.M{"$ne": models.EventLive},
+	}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("orgId", orgID).Msg("Error deleting event")
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", id).Str("orgId", orgID).Msg("Event deleted")
+	return nil
+}
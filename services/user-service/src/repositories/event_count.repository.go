@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EventCountRepository is a repository for the daily counts of published
+// events
+type EventCountRepository struct {
+	collection db.Collection
+}
+
+// NewEventCountRepository creates a new event count repository
+func NewEventCountRepository(store db.Storage) *EventCountRepository {
+	return &EventCountRepository{
+		collection: store.GetCollection(db.EventCountsCollection),
+	}
+}
+
+// Increment counts a published event in the count of its type and UTC day
+func (r *EventCountRepository) Increment(ctx context.Context, eventType string, publishedAt time.Time) error {
+	day := models.StatsDaily.Truncate(publishedAt)
+
+	filter := bson.M{"_id": day.Format("2006-01-02") + "|" + eventType}
+	update := bson.M{
+		"$inc": bson.M{"count": 1},
+		"$setOnInsert": bson.M{
+			"day":       day,
+			"eventType": eventType,
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("eventType", eventType).Msg("Error incrementing event count")
+		return err
+	}
+
+	return nil
+}
+
+// CountByType counts the events published from one time until before
+// another, per event type and bucket of interval
+func (r *EventCountRepository) CountByType(ctx context.Context, interval models.StatsInterval, from, to time.Time) ([]models.EventTypeCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"day": bson.M{"$gte": from, "$lt": to}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"start": dateTrunc("day", interval), "eventType": "$eventType"},
+			"count": bson.M{"$sum": "$count"},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":       0,
+			"start":     "$_id.start",
+			"eventType": "$_id.eventType",
+			"count":     1,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "start", Value: 1}, {Key: "eventType", Value: 1}}}},
+	}
+
+	var counts []models.EventTypeCount
+	if err := aggregate(ctx, r.collection, pipeline, &counts); err != nil {
+		if !errors.Is(err, db.ErrAggregationUnsupported) {
+			logger.Ctx(ctx).Error().Err(err).Msg("Error counting published events")
+		}
+		return nil, err
+	}
+
+	return counts, nil
+}
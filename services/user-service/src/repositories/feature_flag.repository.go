@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FeatureFlagRepository is a repository for feature flags
+type FeatureFlagRepository struct {
+	collection db.Collection
+}
+
+// NewFeatureFlagRepository creates a new feature flag repository
+func NewFeatureFlagRepository(store db.Storage) *FeatureFlagRepository {
+	return &FeatureFlagRepository{
+		collection: store.GetCollection(db.FeatureFlagsCollection),
+	}
+}
+
+// List lists every feature flag, by key
+func (r *FeatureFlagRepository) List(ctx context.Context) ([]*models.FeatureFlag, error) {
+	var flags []*models.FeatureFlag
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding feature flags")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &flags); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding feature flags")
+		return nil, err
+	}
+
+	return flags, nil
+}
+
+// Get gets a feature flag by key
+func (r *FeatureFlagRepository) Get(ctx context.Context, key string) (*models.FeatureFlag, error) {
+	var flag models.FeatureFlag
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&flag)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("key", key).Msg("Error getting feature flag")
+		return nil, err
+	}
+
+	return &flag, nil
+}
+
+// Save creates or updates the definition of a feature flag, keeping its
+// overrides
+func (r *FeatureFlagRepository) Save(ctx context.Context, flag *models.FeatureFlag) error {
+	filter := bson.M{"_id": flag.Key}
+	update := bson.M{
+		"$set": bson.M{
+			"description": flag.Description,
+			"enabled":     flag.Enabled,
+			"rollout":     flag.Rollout,
+			"updatedBy":   flag.UpdatedBy,
+			"updatedAt":   flag.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"createdAt": flag.CreatedAt,
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("key", flag.Key).Msg("Error saving feature flag")
+		return err
+	}
+
+	return nil
+}
+
+// Delete deletes a feature flag
+func (r *FeatureFlagRepository) Delete(ctx context.Context, key string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": key})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("key", key).Msg("Error deleting feature flag")
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// SetOverride turns a flag on or off for an organization or user, by the
+// override scope. It returns mongo.ErrNoDocuments if the flag doesn't exist.
+func (r *FeatureFlagRepository) SetOverride(ctx context.Context, key, scope, targetID string, enabled bool, updatedBy string) error {
+	filter := bson.M{"_id": key}
+	update := bson.M{
+		"$set": bson.M{
+			scope + "." + targetID: enabled,
+			"updatedBy":            updatedBy,
+			"updatedAt":            time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("key", key).Str("scope", scope).Str("targetId", targetID).
+			Msg("Error setting feature flag override")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// DeleteOverride removes the override of a flag for an organization or user.
+// It returns mongo.ErrNoDocuments if the flag doesn't exist.
+func (r *FeatureFlagRepository) DeleteOverride(ctx context.Context, key, scope, targetID string, updatedBy string) error {
+	filter := bson.M{"_id": key}
+	update := bson.M{
+		"$unset": bson.M{scope + "." + targetID: ""},
+		"$set": bson.M{
+			"updatedBy": updatedBy,
+			"updatedAt": time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("key", key).Str("scope", scope).Str("targetId", targetID).
+			Msg("Error deleting feature flag override")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
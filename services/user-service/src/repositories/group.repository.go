@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrGroupNameTaken is returned when an organization already has a group with the name
+var ErrGroupNameTaken = apperrors.Conflict("GROUP_NAME_TAKEN", "a group with this name already exists in the organization")
+
+// ErrGroupFull is returned when adding members would take a group over MaxGroupMembers
+var ErrGroupFull = apperrors.Conflict("GROUP_FULL", fmt.Sprintf("groups cannot have more than %d members", models.MaxGroupMembers))
+
+// GroupRepository is a repository for organization groups
+type GroupRepository struct {
+	collection db.Collection
+}
+
+// NewGroupRepository creates a new group repository
+func NewGroupRepository(store db.Storage) *GroupRepository {
+	return &GroupRepository{
+		collection: tenantCollection(store, db.GroupsCollection, "organizationId"),
+	}
+}
+
+// Create creates a new group
+func (r *GroupRepository) Create(ctx context.Context, group *models.Group) error {
+	_, err := insertScoped(ctx, r.collection, group)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrGroupNameTaken
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", group.OrganizationID).Msg("Error creating group")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", group.ID).Str("orgId", group.OrganizationID).Msg("Group created")
+	return nil
+}
+
+// GetByID gets a group of an organization by ID
+func (r *GroupRepository) GetByID(ctx context.Context, orgID, id string) (*models.Group, error) {
+	var group models.Group
+
+	filter := bson.M{"_id": id, "organizationId": orgID}
+	err := r.collection.FindOne(ctx, filter).Decode(&group)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error getting group by ID")
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// GetByOrganization gets a page of the groups of an organization, by name
+func (r *GroupRepository) GetByOrganization(ctx context.Context, orgID string, page, limit int) ([]*models.Group, int64, error) {
+	var groups []*models.Group
+
+	filter := bson.M{"organizationId": orgID}
+
+	// Count total
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error counting groups")
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"name": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error finding groups")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &groups); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding groups")
+		return nil, 0, err
+	}
+
+	return groups, total, nil
+}
+
+// Update updates the name, description and permissions of a group
+func (r *GroupRepository) Update(ctx context.Context, group *models.Group) error {
+	filter := bson.M{"_id": group.ID, "organizationId": group.OrganizationID}
+	update := bson.M{
+		"$set": bson.M{
+			"name":        group.Name,
+			"description": group.Description,
+			"permissions": group.Permissions,
+			"updatedAt":   group.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrGroupNameTaken
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", group.ID).Msg("Error updating group")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", group.ID).Msg("Group updated")
+	return nil
+}
+
+// Delete deletes a group of an organization
+func (r *GroupRepository) Delete(ctx context.Context, orgID, id string) error {
+	filter := bson.M{"_id": id, "organizationId": orgID}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("orgId", orgID).Msg("Error deleting group")
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", id).Str("orgId", orgID).Msg("Group deleted")
+	return nil
+}
+
+// AddMembers adds users to a group. The update only applies while the group
+// has room for all of them, so concurrent adds can't take it over the limit.
+func (r *GroupRepository) AddMembers(ctx context.Context, orgID, id string, userIDs []string) error {
+	filter := bson.M{"_id": id, "organizationId": orgID}
+	if len(userIDs) > models.MaxGroupMembers {
+		return ErrGroupFull
+	}
+	filter[fmt.Sprintf("memberIds.%d", models.MaxGroupMembers-len(userIDs))] = bson.M{"$exists": false}
+
+	update := bson.M{
+		"$addToSet": bson.M{"memberIds": bson.M{"$each": userIDs}},
+		"$set":      bson.M{"updatedAt": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error adding group members")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		// Tell a missing group from a full one
+		if _, err := r.GetByID(ctx, orgID, id); err != nil {
+			return err
+		}
+		return ErrGroupFull
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", id).Int("count", len(userIDs)).Msg("Group members added")
+	return nil
+}
+
+// RemoveMember removes a user from a group
+func (r *GroupRepository) RemoveMember(ctx context.Context, orgID, id, userID string) error {
+	filter := bson.M{"_id": id, "organizationId": orgID, "memberIds": userID}
+	update := bson.M{
+		"$pull": bson.M{"memberIds": userID},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("userId", userID).Msg("Error removing group member")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", id).Str("userId", userID).Msg("Group member removed")
+	return nil
+}
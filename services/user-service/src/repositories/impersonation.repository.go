@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ImpersonationRepository is a repository for admin impersonation sessions
+type ImpersonationRepository struct {
+	collection db.Collection
+}
+
+// NewImpersonationRepository creates a new impersonation repository
+func NewImpersonationRepository(store db.Storage) *ImpersonationRepository {
+	return &ImpersonationRepository{
+		collection: store.GetCollection(db.ImpersonationsCollection),
+	}
+}
+
+// Create creates a new impersonation session
+func (r *ImpersonationRepository) Create(ctx context.Context, session *models.ImpersonationSession) error {
+	_, err := r.collection.InsertOne(ctx, session)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("adminId", session.AdminID).Str("userId", session.UserID).
+			Msg("Error creating impersonation session")
+		return err
+	}
+
+	return nil
+}
+
+// GetByID gets an impersonation session by ID
+func (r *ImpersonationRepository) GetByID(ctx context.Context, id string) (*models.ImpersonationSession, error) {
+	var session models.ImpersonationSession
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("impersonationId", id).Msg("Error getting impersonation session by ID")
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// End ends an impersonation session that hasn't ended yet
+func (r *ImpersonationRepository) End(ctx context.Context, id, endedBy string, endedAt time.Time) error {
+	filter := bson.M{
+		"_id":     id,
+		"endedAt": bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{
+		"endedAt": endedAt,
+		"endedBy": endedBy,
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("impersonationId", id).Msg("Error ending impersonation session")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InboxRepository is a repository for the notifications of in-app inboxes
+type InboxRepository struct {
+	collection db.Collection
+}
+
+// NewInboxRepository creates a new inbox repository
+func NewInboxRepository(store db.Storage) *InboxRepository {
+	return &InboxRepository{
+		collection: store.GetCollection(db.InboxCollection),
+	}
+}
+
+// Create adds a notification to a user's inbox. A notification of an event
+// already in the inbox, such as from a redelivered event, isn't added again.
+func (r *InboxRepository) Create(ctx context.Context, notification *models.InboxNotification) error {
+	_, err := r.collection.InsertOne(ctx, notification)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", notification.UserID).Str("type", string(notification.Type)).
+			Msg("Error creating inbox notification")
+		return err
+	}
+
+	return nil
+}
+
+// Find lists the notifications of an inbox, newest first, starting after the
+// cursor
+func (r *InboxRepository) Find(ctx context.Context, filter models.InboxFilter, after *models.TimelineCursor, limit int) ([]*models.InboxNotification, error) {
+	query := bson.M{"userId": filter.UserID}
+	if filter.UnreadOnly {
+		query["readAt"] = nil
+	}
+	if after != nil {
+		query["$or"] = []bson.M{
+			{"occurredAt": bson.M{"$lt": after.OccurredAt}},
+			{"occurredAt": after.OccurredAt, "_id": bson.M{"$lt": after.ID}},
+		}
+	}
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "occurredAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	return r.find(ctx, filter.UserID, query, opts)
+}
+
+// FindCreatedAfter lists the notifications added to an inbox after a time,
+// oldest first
+func (r *InboxRepository) FindCreatedAfter(ctx context.Context, userID string, after time.Time, limit int) ([]*models.InboxNotification, error) {
+	query := bson.M{
+		"userId":    userID,
+		"createdAt": bson.M{"$gt": after},
+	}
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}})
+
+	return r.find(ctx, userID, query, opts)
+}
+
+// find lists the inbox notifications matching a query
+func (r *InboxRepository) find(ctx context.Context, userID string, query bson.M, opts *options.FindOptions) ([]*models.InboxNotification, error) {
+	var notifications []*models.InboxNotification
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error finding inbox notifications")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &notifications); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding inbox notifications")
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// CountUnread counts the unread notifications of an inbox
+func (r *InboxRepository) CountUnread(ctx context.Context, userID string) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"userId": userID, "readAt": nil})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error counting unread inbox notifications")
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// MarkRead marks a notification of an inbox read, reporting if it was
+// unread. Notifications already read keep when they were first read.
+func (r *InboxRepository) MarkRead(ctx context.Context, userID, id string, at time.Time) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "userId": userID, "readAt": nil},
+		bson.M{"$set": bson.M{"readAt": at}},
+	)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Str("id", id).Msg("Error marking inbox notification read")
+		return false, err
+	}
+	if result.MatchedCount > 0 {
+		return true, nil
+	}
+
+	// Tell notifications already read from missing ones
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id, "userId": userID})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Str("id", id).Msg("Error finding inbox notification")
+		return false, err
+	}
+	if count == 0 {
+		return false, mongo.ErrNoDocuments
+	}
+
+	return false, nil
+}
+
+// MarkAllRead marks the unread notifications of an inbox that occurred by a
+// time read, returning how many were marked
+func (r *InboxRepository) MarkAllRead(ctx context.Context, userID string, before, at time.Time) (int64, error) {
+	filter := bson.M{"userId": userID, "readAt": nil, "occurredAt": bson.M{"$lte": before}}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error finding unread inbox notifications")
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var unread []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &unread); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error decoding unread inbox notifications")
+		return 0, err
+	}
+
+	// Notifications read meanwhile keep when they were first read
+	writes := make([]mongo.WriteModel, len(unread))
+	for i, notification := range unread {
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": notification.ID, "readAt": nil}).
+			SetUpdate(bson.M{"$set": bson.M{"readAt": at}})
+	}
+	result, err := db.BulkWrite(ctx, r.collection, writes, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error marking inbox notifications read")
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
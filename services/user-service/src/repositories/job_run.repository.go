@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/pkg/jobs"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// JobRunRepository is a repository for the run history of scheduled jobs
+type JobRunRepository struct {
+	collection db.Collection
+}
+
+// NewJobRunRepository creates a new job run repository
+func NewJobRunRepository(store db.Storage) *JobRunRepository {
+	return &JobRunRepository{
+		collection: store.GetCollection(db.JobRunsCollection),
+	}
+}
+
+// Create records a started run. It returns jobs.ErrRunExists if the run was
+// already recorded, such as a scheduled run another replica started.
+func (r *JobRunRepository) Create(ctx context.Context, run *jobs.Run) error {
+	_, err := r.collection.InsertOne(ctx, run)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return jobs.ErrRunExists
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("job", run.Job).Str("runId", run.ID).Msg("Error creating job run")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("job", run.Job).Str("runId", run.ID).Msg("Job run created")
+	return nil
+}
+
+// Save records the outcome of a run
+func (r *JobRunRepository) Save(ctx context.Context, run *jobs.Run) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":     run.Status,
+			"error":      run.Error,
+			"finishedAt": run.FinishedAt,
+			"durationMs": run.DurationMs,
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": run.ID}, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("job", run.Job).Str("runId", run.ID).Msg("Error saving job run")
+		return err
+	}
+	return nil
+}
+
+// List lists the runs of a job, newest first
+func (r *JobRunRepository) List(ctx context.Context, job string, page, limit int) ([]*jobs.Run, int64, error) {
+	var runs []*jobs.Run
+
+	filter := bson.M{"job": job}
+
+	// Count total
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("job", job).Msg("Error counting job runs")
+		return nil, 0, err
+	}
+
+	// Set options for pagination and sorting, newest first
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "startedAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("job", job).Msg("Error finding job runs")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &runs); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding job runs")
+		return nil, 0, err
+	}
+
+	return runs, total, nil
+}
+
+// Latest gets the latest run of a job
+func (r *JobRunRepository) Latest(ctx context.Context, job string) (*jobs.Run, error) {
+	var run jobs.Run
+
+	opts := options.FindOne().SetSort(bson.D{{Key: "startedAt", Value: -1}, {Key: "_id", Value: -1}})
+	err := r.collection.FindOne(ctx, bson.M{"job": job}, opts).Decode(&run)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("job", job).Msg("Error getting latest job run")
+		return nil, err
+	}
+
+	return &run, nil
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// JoinRequestRepository is a repository for organization join requests
+type JoinRequestRepository struct {
+	collection db.Collection
+}
+
+// NewJoinRequestRepository creates a new join request repository
+func NewJoinRequestRepository(store db.Storage) *JoinRequestRepository {
+	return &JoinRequestRepository{
+		collection: tenantCollection(store, db.JoinRequestsCollection, "organizationId"),
+	}
+}
+
+// Create creates a new join request
+func (r *JoinRequestRepository) Create(ctx context.Context, req *models.JoinRequest) error {
+	_, err := insertScoped(ctx, r.collection, req)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("join request already pending")
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", req.OrganizationID).Str("userId", req.UserID).
+			Msg("Error creating join request")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", req.ID).Str("orgId", req.OrganizationID).Msg("Join request created")
+	return nil
+}
+
+// GetByID gets a join request of an organization by ID
+func (r *JoinRequestRepository) GetByID(ctx context.Context, orgID, id string) (*models.JoinRequest, error) {
+	var req models.JoinRequest
+
+	filter := bson.M{"_id": id, "organizationId": orgID}
+	err := r.collection.FindOne(ctx, filter).Decode(&req)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error getting join request by ID")
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// GetPendingByUser gets the pending join request of a user for an organization
+func (r *JoinRequestRepository) GetPendingByUser(ctx context.Context, orgID, userID string) (*models.JoinRequest, error) {
+	var req models.JoinRequest
+
+	filter := bson.M{
+		"organizationId": orgID,
+		"userId":         userID,
+		"status":         models.JoinRequestPending,
+	}
+	err := r.collection.FindOne(ctx, filter).Decode(&req)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", userID).Msg("Error getting pending join request")
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// ListByOrganization lists the join requests of an organization with the given status
+func (r *JoinRequestRepository) ListByOrganization(ctx context.Context, orgID string, status models.JoinRequestStatus, page, limit int) ([]*models.JoinRequest, int64, error) {
+	var reqs []*models.JoinRequest
+
+	filter := bson.M{"organizationId": orgID, "status": status}
+
+	// Count total
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error counting join requests")
+		return nil, 0, err
+	}
+
+	// Set options for pagination and sorting, oldest first
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"createdAt": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error finding join requests")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &reqs); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding join requests")
+		return nil, 0, err
+	}
+
+	return reqs, total, nil
+}
+
+// Resolve moves a pending join request to a final status. It returns
+// mongo.ErrNoDocuments if the request is no longer pending.
+func (r *JoinRequestRepository) Resolve(ctx context.Context, req *models.JoinRequest) error {
+	filter := bson.M{
+		"_id":    req.ID,
+		"status": models.JoinRequestPending,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       req.Status,
+			"role":         req.Role,
+			"reviewedBy":   req.ReviewedBy,
+			"reviewReason": req.ReviewReason,
+			"reviewedAt":   req.ReviewedAt,
+			"updatedAt":    time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", req.ID).Msg("Error resolving join request")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", req.ID).Str("status", string(req.Status)).Msg("Join request resolved")
+	return nil
+}
@@ -0,0 +1,249 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LDAPSyncRepository is a repository for organizations' LDAP sync
+// configurations and the reports of their syncs
+type LDAPSyncRepository struct {
+	configs db.Collection
+	runs    db.Collection
+}
+
+// NewLDAPSyncRepository creates a new LDAP sync repository
+func NewLDAPSyncRepository(store db.Storage) *LDAPSyncRepository {
+	return &LDAPSyncRepository{
+		configs: store.GetCollection(db.LDAPSyncConfigsCollection),
+		runs:    store.GetCollection(db.LDAPSyncRunsCollection),
+	}
+}
+
+// GetConfig gets the LDAP sync configuration of an organization
+func (r *LDAPSyncRepository) GetConfig(ctx context.Context, orgID string) (*models.LDAPSyncConfig, error) {
+	var config models.LDAPSyncConfig
+
+	err := r.configs.FindOne(ctx, bson.M{"_id": orgID}).Decode(&config)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error getting LDAP sync configuration")
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// SaveConfig creates or updates the LDAP sync configuration of an
+// organization, keeping the state of its runs
+func (r *LDAPSyncRepository) SaveConfig(ctx context.Context, config *models.LDAPSyncConfig) error {
+	filter := bson.M{"_id": config.OrganizationID}
+	update := bson.M{
+		"$set": bson.M{
+			"enabled":            config.Enabled,
+			"url":                config.URL,
+			"bindDn":             config.BindDN,
+			"bindPassword":       config.BindPassword,
+			"insecureSkipVerify": config.InsecureSkipVerify,
+			"baseDn":             config.BaseDN,
+			"userFilter":         config.UserFilter,
+			"attributes":         config.Attributes,
+			"teamMappings":       config.TeamMappings,
+			"roleMappings":       config.RoleMappings,
+			"removeMissing":      config.RemoveMissing,
+			"dryRun":             config.DryRun,
+			"syncHour":           config.SyncHour,
+			"nextRunAt":          config.NextRunAt,
+			"updatedBy":          config.UpdatedBy,
+			"updatedAt":          config.UpdatedAt,
+		},
+	}
+
+	_, err := r.configs.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", config.OrganizationID).Msg("Error saving LDAP sync configuration")
+		return err
+	}
+
+	return nil
+}
+
+// DeleteConfig deletes the LDAP sync configuration of an organization. Its
+// reports are kept.
+func (r *LDAPSyncRepository) DeleteConfig(ctx context.Context, orgID string) error {
+	result, err := r.configs.DeleteOne(ctx, bson.M{"_id": orgID})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error deleting LDAP sync configuration")
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// FindDue finds enabled configurations whose next sync is due
+func (r *LDAPSyncRepository) FindDue(ctx context.Context, now time.Time, limit int64) ([]*models.LDAPSyncConfig, error) {
+	var configs []*models.LDAPSyncConfig
+
+	filter := bson.M{
+		"enabled":   true,
+		"nextRunAt": bson.M{"$lte": now},
+	}
+	opts := options.Find().
+		SetSort(bson.M{"nextRunAt": 1}).
+		SetLimit(limit)
+
+	cursor, err := r.configs.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding due LDAP syncs")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &configs); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding due LDAP syncs")
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+// StartRun marks an organization's sync as running, unless another run
+// started after staleBefore still is. It reports whether the run may start.
+func (r *LDAPSyncRepository) StartRun(ctx context.Context, orgID string, now, staleBefore time.Time) (bool, error) {
+	filter := bson.M{
+		"_id": orgID,
+		"$or": bson.A{
+			bson.M{"runningSince": bson.M{"$exists": false}},
+			bson.M{"runningSince": bson.M{"$lt": staleBefore}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"runningSince": now},
+	}
+
+	result, err := r.configs.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error starting LDAP sync")
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// FinishRun records the last run of an organization's sync and when it next
+// runs, and marks it as no longer running
+func (r *LDAPSyncRepository) FinishRun(ctx context.Context, orgID string, run *models.LDAPSyncRun, nextRunAt *time.Time) error {
+	set := bson.M{
+		"lastRunId": run.ID,
+		"lastRunAt": run.StartedAt,
+	}
+	if nextRunAt != nil {
+		set["nextRunAt"] = *nextRunAt
+	}
+	update := bson.M{
+		"$set":   set,
+		"$unset": bson.M{"runningSince": ""},
+	}
+
+	_, err := r.configs.UpdateOne(ctx, bson.M{"_id": orgID}, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("runId", run.ID).Msg("Error finishing LDAP sync")
+		return err
+	}
+	return nil
+}
+
+// SaveRun creates or replaces the report of a sync
+func (r *LDAPSyncRepository) SaveRun(ctx context.Context, run *models.LDAPSyncRun) error {
+	update := bson.M{
+		"$set": bson.M{
+			"organizationId":   run.OrganizationID,
+			"trigger":          run.Trigger,
+			"triggeredBy":      run.TriggeredBy,
+			"dryRun":           run.DryRun,
+			"status":           run.Status,
+			"error":            run.Error,
+			"summary":          run.Summary,
+			"changes":          run.Changes,
+			"changesTruncated": run.ChangesTruncated,
+			"startedAt":        run.StartedAt,
+			"finishedAt":       run.FinishedAt,
+		},
+	}
+
+	_, err := r.runs.UpdateOne(ctx, bson.M{"_id": run.ID}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("runId", run.ID).Msg("Error saving LDAP sync report")
+		return err
+	}
+	return nil
+}
+
+// GetRun gets the report of a sync of an organization
+func (r *LDAPSyncRepository) GetRun(ctx context.Context, orgID, runID string) (*models.LDAPSyncRun, error) {
+	var run models.LDAPSyncRun
+
+	err := r.runs.FindOne(ctx, bson.M{"_id": runID, "organizationId": orgID}).Decode(&run)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("runId", runID).Msg("Error getting LDAP sync report")
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// ListRuns lists the reports of an organization's syncs, newest first and
+// without their changes
+func (r *LDAPSyncRepository) ListRuns(ctx context.Context, orgID string, page, limit int) ([]*models.LDAPSyncRun, int64, error) {
+	var runs []*models.LDAPSyncRun
+
+	filter := bson.M{"organizationId": orgID}
+
+	// Count total
+	total, err := r.runs.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error counting LDAP sync reports")
+		return nil, 0, err
+	}
+
+	// Set options for pagination and sorting, newest first
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "startedAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetProjection(bson.M{"changes": 0})
+
+	cursor, err := r.runs.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error finding LDAP sync reports")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &runs); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding LDAP sync reports")
+		return nil, 0, err
+	}
+
+	// Storage drivers that ignore projections return the changes too
+	for _, run := range runs {
+		run.Changes = nil
+	}
+
+	return runs, total, nil
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LeaseRepository is a repository for leader election leases
+type LeaseRepository struct {
+	collection db.Collection
+}
+
+// NewLeaseRepository creates a new lease repository
+func NewLeaseRepository(store db.Storage) *LeaseRepository {
+	return &LeaseRepository{
+		collection: store.GetCollection(db.LeasesCollection),
+	}
+}
+
+// Acquire takes or renews a lease for holder. It reports false if another
+// holder owns an unexpired lease.
+func (r *LeaseRepository) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	// Match the lease if we already hold it or it has expired; otherwise the
+	// upsert collides with the existing _id and fails with a duplicate key error
+	filter := bson.M{
+		"_id": name,
+		"$or": bson.A{
+			bson.M{"holder": holder},
+			bson.M{"expiresAt": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"holder":    holder,
+			"renewedAt": now,
+			"expiresAt": now.Add(ttl),
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("lease", name).Str("holder", holder).Msg("Error acquiring lease")
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Release gives up a lease so another instance can take over immediately
+func (r *LeaseRepository) Release(ctx context.Context, name, holder string) error {
+	filter := bson.M{"_id": name, "holder": holder}
+	update := bson.M{
+		"$set": bson.M{
+			"expiresAt": time.Now(),
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("lease", name).Str("holder", holder).Msg("Error releasing lease")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("lease", name).Str("holder", holder).Msg("Lease released")
+	return nil
+}
+
+// Get gets a lease by name
+func (r *LeaseRepository) Get(ctx context.Context, name string) (*models.Lease, error) {
+	var lease models.Lease
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&lease)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("lease", name).Msg("Error getting lease")
+		return nil, err
+	}
+
+	return &lease, nil
+}
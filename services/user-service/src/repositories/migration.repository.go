@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MigrationRepository is a repository for applied schema migrations
+type MigrationRepository struct {
+	collection db.Collection
+	legacy     db.Collection
+}
+
+// NewMigrationRepository creates a new migration repository
+func NewMigrationRepository(store db.Storage) *MigrationRepository {
+	return &MigrationRepository{
+		collection: store.GetCollection(db.MigrationsCollection),
+		legacy:     store.GetCollection(db.LegacyMigrationsCollection),
+	}
+}
+
+// IsApplied checks if a migration has been applied
+func (r *MigrationRepository) IsApplied(ctx context.Context, id string) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("migration", id).Msg("Error checking applied migration")
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// List lists the applied migrations, by version
+func (r *MigrationRepository) List(ctx context.Context) ([]*models.Migration, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "version", Value: 1}}))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error listing applied migrations")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var migrations []*models.Migration
+	if err := cursor.All(ctx, &migrations); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding applied migrations")
+		return nil, err
+	}
+
+	return migrations, nil
+}
+
+// MarkApplied records that a migration has been applied
+func (r *MigrationRepository) MarkApplied(ctx context.Context, migration *models.Migration) error {
+	filter := bson.M{"_id": migration.ID}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"version":    migration.Version,
+			"name":       migration.Name,
+			"appliedAt":  migration.AppliedAt,
+			"durationMs": migration.DurationMs,
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("migration", migration.ID).Msg("Error marking migration applied")
+		return err
+	}
+
+	return nil
+}
+
+// ListLegacy lists the migrations recorded in the migrations collection
+// before migrations were versioned. Only their IDs and when they were applied
+// were recorded.
+func (r *MigrationRepository) ListLegacy(ctx context.Context) ([]*models.Migration, error) {
+	cursor, err := r.legacy.Find(ctx, bson.M{})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error listing legacy migrations")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var migrations []*models.Migration
+	if err := cursor.All(ctx, &migrations); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding legacy migrations")
+		return nil, err
+	}
+
+	return migrations, nil
+}
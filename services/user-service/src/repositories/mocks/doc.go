@@ -0,0 +1,11 @@
+// Package mocks provides mocks of the repository stores, so services can be
+// tested without a database. A mock's methods call the functions set on it:
+//
+//	users := &mocks.UserStore{
+//		GetByIDFunc: func(ctx context.Context, id string) (*models.User, error) {
+//			return &models.User{ID: id}, nil
+//		},
+//	}
+package mocks
+
+//go:generate go run gen.go
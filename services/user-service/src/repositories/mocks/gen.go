@@ -0,0 +1,124 @@
+//go:build ignore
+
+// gen writes stores.go, the mocks of the stores in ../stores.go. Run it with
+// go generate after changing a store.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+func main() {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "../stores.go", nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Standard library imports go first, like goimports groups them
+	var std, others []string
+	for _, spec := range file.Imports {
+		if strings.Contains(strings.Split(spec.Path.Value, "/")[0], ".") {
+			others = append(others, spec.Path.Value)
+		} else {
+			std = append(std, spec.Path.Value)
+		}
+	}
+	others = append(others, `"github.com/your-username/slido-clone/user-service/repositories"`)
+	sort.Strings(std)
+	sort.Strings(others)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen.go; DO NOT EDIT.\n\npackage mocks\n\nimport (\n")
+	for _, path := range std {
+		fmt.Fprintf(&buf, "\t%s\n", path)
+	}
+	buf.WriteString("\n")
+	for _, path := range others {
+		fmt.Fprintf(&buf, "\t%s\n", path)
+	}
+	buf.WriteString(")\n")
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			iface, ok := typeSpec.Type.(*ast.InterfaceType)
+			if !ok {
+				continue
+			}
+			writeMock(&buf, fset, typeSpec.Name.Name, iface)
+		}
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("stores.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writeMock writes the mock of a store: a struct with a function per method,
+// and the methods calling them
+func writeMock(buf *bytes.Buffer, fset *token.FileSet, name string, iface *ast.InterfaceType) {
+	fmt.Fprintf(buf, "\n// %s is a mock of repositories.%s. Each method calls the\n", name, name)
+	buf.WriteString("// function of the same name with a Func suffix, and panics if it isn't set.\n")
+	fmt.Fprintf(buf, "type %s struct {\n", name)
+	for _, method := range iface.Methods.List {
+		fmt.Fprintf(buf, "\t%sFunc %s\n", method.Names[0].Name, node(fset, method.Type))
+	}
+	buf.WriteString("}\n")
+	fmt.Fprintf(buf, "\nvar _ repositories.%s = (*%s)(nil)\n", name, name)
+
+	for _, method := range iface.Methods.List {
+		methodName := method.Names[0].Name
+		funcType := method.Type.(*ast.FuncType)
+		signature := strings.TrimPrefix(node(fset, funcType), "func")
+
+		var args []string
+		for _, param := range funcType.Params.List {
+			_, variadic := param.Type.(*ast.Ellipsis)
+			for _, paramName := range param.Names {
+				arg := paramName.Name
+				if variadic {
+					arg += "..."
+				}
+				args = append(args, arg)
+			}
+		}
+
+		call := fmt.Sprintf("m.%sFunc(%s)", methodName, strings.Join(args, ", "))
+		if funcType.Results != nil && len(funcType.Results.List) > 0 {
+			call = "return " + call
+		}
+
+		fmt.Fprintf(buf, "\n// %s calls %sFunc\n", methodName, methodName)
+		fmt.Fprintf(buf, "func (m *%s) %s%s {\n", name, methodName, signature)
+		fmt.Fprintf(buf, "\tif m.%sFunc == nil {\n\t\tpanic(\"mocks: %s.%s called but %sFunc isn't set\")\n\t}\n", methodName, name, methodName, methodName)
+		fmt.Fprintf(buf, "\t%s\n}\n", call)
+	}
+}
+
+// node prints a syntax tree node
+func node(fset *token.FileSet, n ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, n); err != nil {
+		log.Fatal(err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,870 @@
+// Code generated by gen.go; DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// UserStore is a mock of repositories.UserStore. Each method calls the
+// function of the same name with a Func suffix, and panics if it isn't set.
+type UserStore struct {
+	CreateFunc                        func(ctx context.Context, user *models.User) error
+	GetByIDFunc                       func(ctx context.Context, id string) (*models.User, error)
+	GetByUserIdFunc                   func(ctx context.Context, userId string) (*models.User, error)
+	GetByEmailFunc                    func(ctx context.Context, email string) (*models.User, error)
+	GetUsersFunc                      func(ctx context.Context, page, limit int, params models.UserListFilter) ([]*models.User, int64, error)
+	FindUsersFunc                     func(ctx context.Context, filter bson.M, skip, limit int64) ([]*models.User, int64, error)
+	FindBatchFunc                     func(ctx context.Context, filter bson.M, limit int64) ([]*models.User, error)
+	FindChangedSinceFunc              func(ctx context.Context, filter bson.M, since time.Time, limit int64) ([]*models.User, error)
+	UpdateFunc                        func(ctx context.Context, user *models.User) error
+	UpdateIfUnmodifiedFunc            func(ctx context.Context, user *models.User, updatedAt time.Time) error
+	UpdateIdentityFunc                func(ctx context.Context, user *models.User) error
+	UpdateLastLoginFunc               func(ctx context.Context, userId string, lastLogin time.Time) error
+	UpdateLastSeenFunc                func(ctx context.Context, userId string, seenAt time.Time, minInterval time.Duration) error
+	CountSignupsFromIPFunc            func(ctx context.Context, ip string, since time.Time) (int64, error)
+	CountOrganizationMembersFunc      func(ctx context.Context, orgID string, activeSince time.Time) (total, active int64, err error)
+	CountByFieldFunc                  func(ctx context.Context, field string) ([]models.StatsCount, error)
+	CountSignupsFunc                  func(ctx context.Context, interval models.StatsInterval, from, to time.Time) ([]models.StatsBucketCount, error)
+	ResolveSignupReviewFunc           func(ctx context.Context, userId string, status models.UserStatus, review *models.SignupReview) error
+	AddOrganizationToUserFunc         func(ctx context.Context, userId, organizationId string) error
+	RemoveOrganizationFromUserFunc    func(ctx context.Context, userId, organizationId string) error
+	UpdateOrganizationMembershipsFunc func(ctx context.Context, organizationId string, added, removed []string) error
+	SetFavoritesFunc                  func(ctx context.Context, userId string, favorites []models.Favorite) error
+	SetCustomFieldsFunc               func(ctx context.Context, userId, organizationId string, values map[string]interface{}) error
+	SetPendingEmailFunc               func(ctx context.Context, userId, email string, requestedAt time.Time) error
+	ClearPendingEmailFunc             func(ctx context.Context, userId string) error
+	ConfirmEmailChangeFunc            func(ctx context.Context, userId, email string) error
+	AddTeamToUserFunc                 func(ctx context.Context, userId, teamId string) error
+	RemoveTeamFromUserFunc            func(ctx context.Context, userId, teamId string) error
+	UpdateTeamMembershipsFunc         func(ctx context.Context, teamId string, added, removed []string) error
+	DeleteFunc                        func(ctx context.Context, id string) error
+	RestoreFunc                       func(ctx context.Context, id string) error
+	PurgeFunc                         func(ctx context.Context, id string) error
+}
+
+var _ repositories.UserStore = (*UserStore)(nil)
+
+// Create calls CreateFunc
+func (m *UserStore) Create(ctx context.Context, user *models.User) error {
+	if m.CreateFunc == nil {
+		panic("mocks: UserStore.Create called but CreateFunc isn't set")
+	}
+	return m.CreateFunc(ctx, user)
+}
+
+// GetByID calls GetByIDFunc
+func (m *UserStore) GetByID(ctx context.Context, id string) (*models.User, error) {
+	if m.GetByIDFunc == nil {
+		panic("mocks: UserStore.GetByID called but GetByIDFunc isn't set")
+	}
+	return m.GetByIDFunc(ctx, id)
+}
+
+// GetByUserId calls GetByUserIdFunc
+func (m *UserStore) GetByUserId(ctx context.Context, userId string) (*models.User, error) {
+	if m.GetByUserIdFunc == nil {
+		panic("mocks: UserStore.GetByUserId called but GetByUserIdFunc isn't set")
+	}
+	return m.GetByUserIdFunc(ctx, userId)
+}
+
+// GetByEmail calls GetByEmailFunc
+func (m *UserStore) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	if m.GetByEmailFunc == nil {
+		panic("mocks: UserStore.GetByEmail called but GetByEmailFunc isn't set")
+	}
+	return m.GetByEmailFunc(ctx, email)
+}
+
+// GetUsers calls GetUsersFunc
+func (m *UserStore) GetUsers(ctx context.Context, page, limit int, params models.UserListFilter) ([]*models.User, int64, error) {
+	if m.GetUsersFunc == nil {
+		panic("mocks: UserStore.GetUsers called but GetUsersFunc isn't set")
+	}
+	return m.GetUsersFunc(ctx, page, limit, params)
+}
+
+// FindUsers calls FindUsersFunc
+func (m *UserStore) FindUsers(ctx context.Context, filter bson.M, skip, limit int64) ([]*models.User, int64, error) {
+	if m.FindUsersFunc == nil {
+		panic("mocks: UserStore.FindUsers called but FindUsersFunc isn't set")
+	}
+	return m.FindUsersFunc(ctx, filter, skip, limit)
+}
+
+// FindBatch calls FindBatchFunc
+func (m *UserStore) FindBatch(ctx context.Context, filter bson.M, limit int64) ([]*models.User, error) {
+	if m.FindBatchFunc == nil {
+		panic("mocks: UserStore.FindBatch called but FindBatchFunc isn't set")
+	}
+	return m.FindBatchFunc(ctx, filter, limit)
+}
+
+// FindChangedSince calls FindChangedSinceFunc
+func (m *UserStore) FindChangedSince(ctx context.Context, filter bson.M, since time.Time, limit int64) ([]*models.User, error) {
+	if m.FindChangedSinceFunc == nil {
+		panic("mocks: UserStore.FindChangedSince called but FindChangedSinceFunc isn't set")
+	}
+	return m.FindChangedSinceFunc(ctx, filter, since, limit)
+}
+
+// Update calls UpdateFunc
+func (m *UserStore) Update(ctx context.Context, user *models.User) error {
+	if m.UpdateFunc == nil {
+		panic("mocks: UserStore.Update called but UpdateFunc isn't set")
+	}
+	return m.UpdateFunc(ctx, user)
+}
+
+// UpdateIfUnmodified calls UpdateIfUnmodifiedFunc
+func (m *UserStore) UpdateIfUnmodified(ctx context.Context, user *models.User, updatedAt time.Time) error {
+	if m.UpdateIfUnmodifiedFunc == nil {
+		panic("mocks: UserStore.UpdateIfUnmodified called but UpdateIfUnmodifiedFunc isn't set")
+	}
+	return m.UpdateIfUnmodifiedFunc(ctx, user, updatedAt)
+}
+
+// UpdateIdentity calls UpdateIdentityFunc
+func (m *UserStore) UpdateIdentity(ctx context.Context, user *models.User) error {
+	if m.UpdateIdentityFunc == nil {
+		panic("mocks: UserStore.UpdateIdentity called but UpdateIdentityFunc isn't set")
+	}
+	return m.UpdateIdentityFunc(ctx, user)
+}
+
+// UpdateLastLogin calls UpdateLastLoginFunc
+func (m *UserStore) UpdateLastLogin(ctx context.Context, userId string, lastLogin time.Time) error {
+	if m.UpdateLastLoginFunc == nil {
+		panic("mocks: UserStore.UpdateLastLogin called but UpdateLastLoginFunc isn't set")
+	}
+	return m.UpdateLastLoginFunc(ctx, userId, lastLogin)
+}
+
+// UpdateLastSeen calls UpdateLastSeenFunc
+func (m *UserStore) UpdateLastSeen(ctx context.Context, userId string, seenAt time.Time, minInterval time.Duration) error {
+	if m.UpdateLastSeenFunc == nil {
+		panic("mocks: UserStore.UpdateLastSeen called but UpdateLastSeenFunc isn't set")
+	}
+	return m.UpdateLastSeenFunc(ctx, userId, seenAt, minInterval)
+}
+
+// CountSignupsFromIP calls CountSignupsFromIPFunc
+func (m *UserStore) CountSignupsFromIP(ctx context.Context, ip string, since time.Time) (int64, error) {
+	if m.CountSignupsFromIPFunc == nil {
+		panic("mocks: UserStore.CountSignupsFromIP called but CountSignupsFromIPFunc isn't set")
+	}
+	return m.CountSignupsFromIPFunc(ctx, ip, since)
+}
+
+// CountOrganizationMembers calls CountOrganizationMembersFunc
+func (m *UserStore) CountOrganizationMembers(ctx context.Context, orgID string, activeSince time.Time) (total, active int64, err error) {
+	if m.CountOrganizationMembersFunc == nil {
+		panic("mocks: UserStore.CountOrganizationMembers called but CountOrganizationMembersFunc isn't set")
+	}
+	return m.CountOrganizationMembersFunc(ctx, orgID, activeSince)
+}
+
+// CountByField calls CountByFieldFunc
+func (m *UserStore) CountByField(ctx context.Context, field string) ([]models.StatsCount, error) {
+	if m.CountByFieldFunc == nil {
+		panic("mocks: UserStore.CountByField called but CountByFieldFunc isn't set")
+	}
+	return m.CountByFieldFunc(ctx, field)
+}
+
+// CountSignups calls CountSignupsFunc
+func (m *UserStore) CountSignups(ctx context.Context, interval models.StatsInterval, from, to time.Time) ([]models.StatsBucketCount, error) {
+	if m.CountSignupsFunc == nil {
+		panic("mocks: UserStore.CountSignups called but CountSignupsFunc isn't set")
+	}
+	return m.CountSignupsFunc(ctx, interval, from, to)
+}
+
+// ResolveSignupReview calls ResolveSignupReviewFunc
+func (m *UserStore) ResolveSignupReview(ctx context.Context, userId string, status models.UserStatus, review *models.SignupReview) error {
+	if m.ResolveSignupReviewFunc == nil {
+		panic("mocks: UserStore.ResolveSignupReview called but ResolveSignupReviewFunc isn't set")
+	}
+	return m.ResolveSignupReviewFunc(ctx, userId, status, review)
+}
+
+// AddOrganizationToUser calls AddOrganizationToUserFunc
+func (m *UserStore) AddOrganizationToUser(ctx context.Context, userId, organizationId string) error {
+	if m.AddOrganizationToUserFunc == nil {
+		panic("mocks: UserStore.AddOrganizationToUser called but AddOrganizationToUserFunc isn't set")
+	}
+	return m.AddOrganizationToUserFunc(ctx, userId, organizationId)
+}
+
+// RemoveOrganizationFromUser calls RemoveOrganizationFromUserFunc
+func (m *UserStore) RemoveOrganizationFromUser(ctx context.Context, userId, organizationId string) error {
+	if m.RemoveOrganizationFromUserFunc == nil {
+		panic("mocks: UserStore.RemoveOrganizationFromUser called but RemoveOrganizationFromUserFunc isn't set")
+	}
+	return m.RemoveOrganizationFromUserFunc(ctx, userId, organizationId)
+}
+
+// UpdateOrganizationMemberships calls UpdateOrganizationMembershipsFunc
+func (m *UserStore) UpdateOrganizationMemberships(ctx context.Context, organizationId string, added, removed []string) error {
+	if m.UpdateOrganizationMembershipsFunc == nil {
+		panic("mocks: UserStore.UpdateOrganizationMemberships called but UpdateOrganizationMembershipsFunc isn't set")
+	}
+	return m.UpdateOrganizationMembershipsFunc(ctx, organizationId, added, removed)
+}
+
+// SetFavorites calls SetFavoritesFunc
+func (m *UserStore) SetFavorites(ctx context.Context, userId string, favorites []models.Favorite) error {
+	if m.SetFavoritesFunc == nil {
+		panic("mocks: UserStore.SetFavorites called but SetFavoritesFunc isn't set")
+	}
+	return m.SetFavoritesFunc(ctx, userId, favorites)
+}
+
+// SetCustomFields calls SetCustomFieldsFunc
+func (m *UserStore) SetCustomFields(ctx context.Context, userId, organizationId string, values map[string]interface{}) error {
+	if m.SetCustomFieldsFunc == nil {
+		panic("mocks: UserStore.SetCustomFields called but SetCustomFieldsFunc isn't set")
+	}
+	return m.SetCustomFieldsFunc(ctx, userId, organizationId, values)
+}
+
+// SetPendingEmail calls SetPendingEmailFunc
+func (m *UserStore) SetPendingEmail(ctx context.Context, userId, email string, requestedAt time.Time) error {
+	if m.SetPendingEmailFunc == nil {
+		panic("mocks: UserStore.SetPendingEmail called but SetPendingEmailFunc isn't set")
+	}
+	return m.SetPendingEmailFunc(ctx, userId, email, requestedAt)
+}
+
+// ClearPendingEmail calls ClearPendingEmailFunc
+func (m *UserStore) ClearPendingEmail(ctx context.Context, userId string) error {
+	if m.ClearPendingEmailFunc == nil {
+		panic("mocks: UserStore.ClearPendingEmail called but ClearPendingEmailFunc isn't set")
+	}
+	return m.ClearPendingEmailFunc(ctx, userId)
+}
+
+// ConfirmEmailChange calls ConfirmEmailChangeFunc
+func (m *UserStore) ConfirmEmailChange(ctx context.Context, userId, email string) error {
+	if m.ConfirmEmailChangeFunc == nil {
+		panic("mocks: UserStore.ConfirmEmailChange called but ConfirmEmailChangeFunc isn't set")
+	}
+	return m.ConfirmEmailChangeFunc(ctx, userId, email)
+}
+
+// AddTeamToUser calls AddTeamToUserFunc
+func (m *UserStore) AddTeamToUser(ctx context.Context, userId, teamId string) error {
+	if m.AddTeamToUserFunc == nil {
+		panic("mocks: UserStore.AddTeamToUser called but AddTeamToUserFunc isn't set")
+	}
+	return m.AddTeamToUserFunc(ctx, userId, teamId)
+}
+
+// RemoveTeamFromUser calls RemoveTeamFromUserFunc
+func (m *UserStore) RemoveTeamFromUser(ctx context.Context, userId, teamId string) error {
+	if m.RemoveTeamFromUserFunc == nil {
+		panic("mocks: UserStore.RemoveTeamFromUser called but RemoveTeamFromUserFunc isn't set")
+	}
+	return m.RemoveTeamFromUserFunc(ctx, userId, teamId)
+}
+
+// UpdateTeamMemberships calls UpdateTeamMembershipsFunc
+func (m *UserStore) UpdateTeamMemberships(ctx context.Context, teamId string, added, removed []string) error {
+	if m.UpdateTeamMembershipsFunc == nil {
+		panic("mocks: UserStore.UpdateTeamMemberships called but UpdateTeamMembershipsFunc isn't set")
+	}
+	return m.UpdateTeamMembershipsFunc(ctx, teamId, added, removed)
+}
+
+// Delete calls DeleteFunc
+func (m *UserStore) Delete(ctx context.Context, id string) error {
+	if m.DeleteFunc == nil {
+		panic("mocks: UserStore.Delete called but DeleteFunc isn't set")
+	}
+	return m.DeleteFunc(ctx, id)
+}
+
+// Restore calls RestoreFunc
+func (m *UserStore) Restore(ctx context.Context, id string) error {
+	if m.RestoreFunc == nil {
+		panic("mocks: UserStore.Restore called but RestoreFunc isn't set")
+	}
+	return m.RestoreFunc(ctx, id)
+}
+
+// Purge calls PurgeFunc
+func (m *UserStore) Purge(ctx context.Context, id string) error {
+	if m.PurgeFunc == nil {
+		panic("mocks: UserStore.Purge called but PurgeFunc isn't set")
+	}
+	return m.PurgeFunc(ctx, id)
+}
+
+// TeamStore is a mock of repositories.TeamStore. Each method calls the
+// function of the same name with a Func suffix, and panics if it isn't set.
+type TeamStore struct {
+	CreateFunc                   func(ctx context.Context, team *models.Team) error
+	GetByIDFunc                  func(ctx context.Context, id string) (*models.Team, error)
+	GetByNameAndOrganizationFunc func(ctx context.Context, name, organizationID string) (*models.Team, error)
+	GetTeamsByOrganizationFunc   func(ctx context.Context, organizationID string, tags []string, page, limit int, fields models.FieldSet, hiddenFrom string, includeArchived bool) ([]*models.Team, int64, error)
+	GetTeamsByUserFunc           func(ctx context.Context, userID string, tags []string, page, limit int, fields models.FieldSet, includeArchived bool) ([]*models.Team, int64, error)
+	GetChildrenFunc              func(ctx context.Context, parentID string, page, limit int, hiddenFrom string, includeArchived bool) ([]*models.Team, int64, error)
+	GetChildIDsFunc              func(ctx context.Context, parentIDs ...string) ([]string, error)
+	GetAncestorsFunc             func(ctx context.Context, team *models.Team) ([]*models.Team, error)
+	SetParentFunc                func(ctx context.Context, teamID, parentID string) error
+	AddTagsFunc                  func(ctx context.Context, teamID string, tags []string) error
+	RemoveTagFunc                func(ctx context.Context, teamID, tag string) error
+	CountOrganizationTeamsFunc   func(ctx context.Context, orgID string) (teams, memberships int64, err error)
+	FindTeamsFunc                func(ctx context.Context, filter bson.M, skip, limit int64) ([]*models.Team, int64, error)
+	FindBatchFunc                func(ctx context.Context, filter bson.M, limit int64) ([]*models.Team, error)
+	FindChangedSinceFunc         func(ctx context.Context, filter bson.M, since time.Time, limit int64) ([]*models.Team, error)
+	UpdateFunc                   func(ctx context.Context, team *models.Team) error
+	UpdateSettingsFunc           func(ctx context.Context, teamID string, settings models.TeamSettings) error
+	SetArchivedFunc              func(ctx context.Context, teamID string, archivedAt *time.Time, archivedBy string) error
+	DeleteFunc                   func(ctx context.Context, id string) error
+	AddMemberFunc                func(ctx context.Context, teamID, userID string, role models.TeamMemberRole, invitedBy string) error
+	UpdateMemberRoleFunc         func(ctx context.Context, teamID, userID string, role models.TeamMemberRole) error
+	RemoveDuplicateMembersFunc   func(ctx context.Context) (int, error)
+	RemoveMemberFunc             func(ctx context.Context, teamID, userID string) error
+	ReplaceMembersFunc           func(ctx context.Context, team *models.Team, members []models.TeamMember) error
+	SetPendingTransferFunc       func(ctx context.Context, teamID string, transfer *models.OwnershipTransfer) error
+	TransferOwnershipFunc        func(ctx context.Context, teamID string, transfer *models.OwnershipTransfer) error
+}
+
+var _ repositories.TeamStore = (*TeamStore)(nil)
+
+// Create calls CreateFunc
+func (m *TeamStore) Create(ctx context.Context, team *models.Team) error {
+	if m.CreateFunc == nil {
+		panic("mocks: TeamStore.Create called but CreateFunc isn't set")
+	}
+	return m.CreateFunc(ctx, team)
+}
+
+// GetByID calls GetByIDFunc
+func (m *TeamStore) GetByID(ctx context.Context, id string) (*models.Team, error) {
+	if m.GetByIDFunc == nil {
+		panic("mocks: TeamStore.GetByID called but GetByIDFunc isn't set")
+	}
+	return m.GetByIDFunc(ctx, id)
+}
+
+// GetByNameAndOrganization calls GetByNameAndOrganizationFunc
+func (m *TeamStore) GetByNameAndOrganization(ctx context.Context, name, organizationID string) (*models.Team, error) {
+	if m.GetByNameAndOrganizationFunc == nil {
+		panic("mocks: TeamStore.GetByNameAndOrganization called but GetByNameAndOrganizationFunc isn't set")
+	}
+	return m.GetByNameAndOrganizationFunc(ctx, name, organizationID)
+}
+
+// GetTeamsByOrganization calls GetTeamsByOrganizationFunc
+func (m *TeamStore) GetTeamsByOrganization(ctx context.Context, organizationID string, tags []string, page, limit int, fields models.FieldSet, hiddenFrom string, includeArchived bool) ([]*models.Team, int64, error) {
+	if m.GetTeamsByOrganizationFunc == nil {
+		panic("mocks: TeamStore.GetTeamsByOrganization called but GetTeamsByOrganizationFunc isn't set")
+	}
+	return m.GetTeamsByOrganizationFunc(ctx, organizationID, tags, page, limit, fields, hiddenFrom, includeArchived)
+}
+
+// GetTeamsByUser calls GetTeamsByUserFunc
+func (m *TeamStore) GetTeamsByUser(ctx context.Context, userID string, tags []string, page, limit int, fields models.FieldSet, includeArchived bool) ([]*models.Team, int64, error) {
+	if m.GetTeamsByUserFunc == nil {
+		panic("mocks: TeamStore.GetTeamsByUser called but GetTeamsByUserFunc isn't set")
+	}
+	return m.GetTeamsByUserFunc(ctx, userID, tags, page, limit, fields, includeArchived)
+}
+
+// GetChildren calls GetChildrenFunc
+func (m *TeamStore) GetChildren(ctx context.Context, parentID string, page, limit int, hiddenFrom string, includeArchived bool) ([]*models.Team, int64, error) {
+	if m.GetChildrenFunc == nil {
+		panic("mocks: TeamStore.GetChildren called but GetChildrenFunc isn't set")
+	}
+	return m.GetChildrenFunc(ctx, parentID, page, limit, hiddenFrom, includeArchived)
+}
+
+// GetChildIDs calls GetChildIDsFunc
+func (m *TeamStore) GetChildIDs(ctx context.Context, parentIDs ...string) ([]string, error) {
+	if m.GetChildIDsFunc == nil {
+		panic("mocks: TeamStore.GetChildIDs called but GetChildIDsFunc isn't set")
+	}
+	return m.GetChildIDsFunc(ctx, parentIDs...)
+}
+
+// GetAncestors calls GetAncestorsFunc
+func (m *TeamStore) GetAncestors(ctx context.Context, team *models.Team) ([]*models.Team, error) {
+	if m.GetAncestorsFunc == nil {
+		panic("mocks: TeamStore.GetAncestors called but GetAncestorsFunc isn't set")
+	}
+	return m.GetAncestorsFunc(ctx, team)
+}
+
+// SetParent calls SetParentFunc
+func (m *TeamStore) SetParent(ctx context.Context, teamID, parentID string) error {
+	if m.SetParentFunc == nil {
+		panic("mocks: TeamStore.SetParent called but SetParentFunc isn't set")
+	}
+	return m.SetParentFunc(ctx, teamID, parentID)
+}
+
+// AddTags calls AddTagsFunc
+func (m *TeamStore) AddTags(ctx context.Context, teamID string, tags []string) error {
+	if m.AddTagsFunc == nil {
+		panic("mocks: TeamStore.AddTags called but AddTagsFunc isn't set")
+	}
+	return m.AddTagsFunc(ctx, teamID, tags)
+}
+
+// RemoveTag calls RemoveTagFunc
+func (m *TeamStore) RemoveTag(ctx context.Context, teamID, tag string) error {
+	if m.RemoveTagFunc == nil {
+		panic("mocks: TeamStore.RemoveTag called but RemoveTagFunc isn't set")
+	}
+	return m.RemoveTagFunc(ctx, teamID, tag)
+}
+
+// CountOrganizationTeams calls CountOrganizationTeamsFunc
+func (m *TeamStore) CountOrganizationTeams(ctx context.Context, orgID string) (teams, memberships int64, err error) {
+	if m.CountOrganizationTeamsFunc == nil {
+		panic("mocks: TeamStore.CountOrganizationTeams called but CountOrganizationTeamsFunc isn't set")
+	}
+	return m.CountOrganizationTeamsFunc(ctx, orgID)
+}
+
+// FindTeams calls FindTeamsFunc
+func (m *TeamStore) FindTeams(ctx context.Context, filter bson.M, skip, limit int64) ([]*models.Team, int64, error) {
+	if m.FindTeamsFunc == nil {
+		panic("mocks: TeamStore.FindTeams called but FindTeamsFunc isn't set")
+	}
+	return m.FindTeamsFunc(ctx, filter, skip, limit)
+}
+
+// FindBatch calls FindBatchFunc
+func (m *TeamStore) FindBatch(ctx context.Context, filter bson.M, limit int64) ([]*models.Team, error) {
+	if m.FindBatchFunc == nil {
+		panic("mocks: TeamStore.FindBatch called but FindBatchFunc isn't set")
+	}
+	return m.FindBatchFunc(ctx, filter, limit)
+}
+
+// FindChangedSince calls FindChangedSinceFunc
+func (m *TeamStore) FindChangedSince(ctx context.Context, filter bson.M, since time.Time, limit int64) ([]*models.Team, error) {
+	if m.FindChangedSinceFunc == nil {
+		panic("mocks: TeamStore.FindChangedSince called but FindChangedSinceFunc isn't set")
+	}
+	return m.FindChangedSinceFunc(ctx, filter, since, limit)
+}
+
+// Update calls UpdateFunc
+func (m *TeamStore) Update(ctx context.Context, team *models.Team) error {
+	if m.UpdateFunc == nil {
+		panic("mocks: TeamStore.Update called but UpdateFunc isn't set")
+	}
+	return m.UpdateFunc(ctx, team)
+}
+
+// UpdateSettings calls UpdateSettingsFunc
+func (m *TeamStore) UpdateSettings(ctx context.Context, teamID string, settings models.TeamSettings) error {
+	if m.UpdateSettingsFunc == nil {
+		panic("mocks: TeamStore.UpdateSettings called but UpdateSettingsFunc isn't set")
+	}
+	return m.UpdateSettingsFunc(ctx, teamID, settings)
+}
+
+// SetArchived calls SetArchivedFunc
+func (m *TeamStore) SetArchived(ctx context.Context, teamID string, archivedAt *time.Time, archivedBy string) error {
+	if m.SetArchivedFunc == nil {
+		panic("mocks: TeamStore.SetArchived called but SetArchivedFunc isn't set")
+	}
+	return m.SetArchivedFunc(ctx, teamID, archivedAt, archivedBy)
+}
+
+// Delete calls DeleteFunc
+func (m *TeamStore) Delete(ctx context.Context, id string) error {
+	if m.DeleteFunc == nil {
+		panic("mocks: TeamStore.Delete called but DeleteFunc isn't set")
+	}
+	return m.DeleteFunc(ctx, id)
+}
+
+// AddMember calls AddMemberFunc
+func (m *TeamStore) AddMember(ctx context.Context, teamID, userID string, role models.TeamMemberRole, invitedBy string) error {
+	if m.AddMemberFunc == nil {
+		panic("mocks: TeamStore.AddMember called but AddMemberFunc isn't set")
+	}
+	return m.AddMemberFunc(ctx, teamID, userID, role, invitedBy)
+}
+
+// UpdateMemberRole calls UpdateMemberRoleFunc
+func (m *TeamStore) UpdateMemberRole(ctx context.Context, teamID, userID string, role models.TeamMemberRole) error {
+	if m.UpdateMemberRoleFunc == nil {
+		panic("mocks: TeamStore.UpdateMemberRole called but UpdateMemberRoleFunc isn't set")
+	}
+	return m.UpdateMemberRoleFunc(ctx, teamID, userID, role)
+}
+
+// RemoveDuplicateMembers calls RemoveDuplicateMembersFunc
+func (m *TeamStore) RemoveDuplicateMembers(ctx context.Context) (int, error) {
+	if m.RemoveDuplicateMembersFunc == nil {
+		panic("mocks: TeamStore.RemoveDuplicateMembers called but RemoveDuplicateMembersFunc isn't set")
+	}
+	return m.RemoveDuplicateMembersFunc(ctx)
+}
+
+// RemoveMember calls RemoveMemberFunc
+func (m *TeamStore) RemoveMember(ctx context.Context, teamID, userID string) error {
+	if m.RemoveMemberFunc == nil {
+		panic("mocks: TeamStore.RemoveMember called but RemoveMemberFunc isn't set")
+	}
+	return m.RemoveMemberFunc(ctx, teamID, userID)
+}
+
+// ReplaceMembers calls ReplaceMembersFunc
+func (m *TeamStore) ReplaceMembers(ctx context.Context, team *models.Team, members []models.TeamMember) error {
+	if m.ReplaceMembersFunc == nil {
+		panic("mocks: TeamStore.ReplaceMembers called but ReplaceMembersFunc isn't set")
+	}
+	return m.ReplaceMembersFunc(ctx, team, members)
+}
+
+// SetPendingTransfer calls SetPendingTransferFunc
+func (m *TeamStore) SetPendingTransfer(ctx context.Context, teamID string, transfer *models.OwnershipTransfer) error {
+	if m.SetPendingTransferFunc == nil {
+		panic("mocks: TeamStore.SetPendingTransfer called but SetPendingTransferFunc isn't set")
+	}
+	return m.SetPendingTransferFunc(ctx, teamID, transfer)
+}
+
+// TransferOwnership calls TransferOwnershipFunc
+func (m *TeamStore) TransferOwnership(ctx context.Context, teamID string, transfer *models.OwnershipTransfer) error {
+	if m.TransferOwnershipFunc == nil {
+		panic("mocks: TeamStore.TransferOwnership called but TransferOwnershipFunc isn't set")
+	}
+	return m.TransferOwnershipFunc(ctx, teamID, transfer)
+}
+
+// OrgStore is a mock of repositories.OrgStore. Each method calls the
+// function of the same name with a Func suffix, and panics if it isn't set.
+type OrgStore struct {
+	CreateFunc                     func(ctx context.Context, org *models.Organization) error
+	GetByIDFunc                    func(ctx context.Context, id string) (*models.Organization, error)
+	GetByNameFunc                  func(ctx context.Context, name string) (*models.Organization, error)
+	GetOrganizationsByUserFunc     func(ctx context.Context, userID string, tags []string, page, limit int, fields models.FieldSet) ([]*models.Organization, int64, error)
+	GetOrganizationIDsByUserFunc   func(ctx context.Context, userID string) ([]string, error)
+	FindChangedForUserFunc         func(ctx context.Context, userID string, since time.Time, limit int64) ([]*models.Organization, error)
+	ListOrganizationsFunc          func(ctx context.Context, tags []string, page, limit int, fields models.FieldSet) ([]*models.Organization, int64, error)
+	ListDiscoverableFunc           func(ctx context.Context, directoryFilter models.DirectoryFilter, page, limit int) ([]*models.Organization, int64, error)
+	FindBatchFunc                  func(ctx context.Context, filter bson.M, limit int64) ([]*models.Organization, error)
+	GetMembersFunc                 func(ctx context.Context, org *models.Organization, filter models.OrganizationMemberFilter, page, limit int) ([]models.OrganizationMemberDetail, int64, error)
+	UpdateFunc                     func(ctx context.Context, org *models.Organization) error
+	UpdateIfUnmodifiedFunc         func(ctx context.Context, org *models.Organization, updatedAt time.Time) error
+	DeleteFunc                     func(ctx context.Context, id string) error
+	AddMemberFunc                  func(ctx context.Context, orgID, userID string, role models.OrganizationMemberRole, invitedBy string) error
+	UpdateMemberRoleFunc           func(ctx context.Context, orgID, userID string, role models.OrganizationMemberRole) error
+	SetMemberLicensedFunc          func(ctx context.Context, orgID, userID string, licensed bool) error
+	SetMemberExpiryFunc            func(ctx context.Context, orgID, userID string, expiresAt *time.Time) error
+	SetMemberProvisioningFunc      func(ctx context.Context, orgID, userID, externalID string, suspended bool) error
+	FindExpiredMembersFunc         func(ctx context.Context, now time.Time, limit int64) ([]*models.OrganizationMemberRecord, error)
+	SetMembersReviewedFunc         func(ctx context.Context, orgID string, userIDs []string, reviewedAt time.Time, reviewedBy string) error
+	MoveMembersToCollectionFunc    func(ctx context.Context, orgID string) error
+	MoveMembersToDocumentFunc      func(ctx context.Context, orgID string) error
+	CountBySizeFunc                func(ctx context.Context, buckets []models.OrganizationSizeBucket) ([]models.StatsCount, error)
+	FindOversizedOrganizationsFunc func(ctx context.Context, threshold int) ([]string, error)
+	RemoveDuplicateMembersFunc     func(ctx context.Context) (int, error)
+	RemoveMemberFunc               func(ctx context.Context, orgID, userID string) error
+	ApplyMemberChangesFunc         func(ctx context.Context, org *models.Organization, changes models.OrganizationMemberChanges) error
+	AddTeamFunc                    func(ctx context.Context, orgID, teamID string) error
+	RemoveTeamFunc                 func(ctx context.Context, orgID, teamID string) error
+	AddTagsFunc                    func(ctx context.Context, orgID string, tags []string) error
+	RemoveTagFunc                  func(ctx context.Context, orgID, tag string) error
+	SetPendingTransferFunc         func(ctx context.Context, orgID string, transfer *models.OwnershipTransfer) error
+	SetVerifiedFunc                func(ctx context.Context, orgID string, verifiedAt *time.Time) error
+	SetSubscriptionFunc            func(ctx context.Context, orgID string, subscription *models.Subscription) (bool, error)
+	TransferOwnershipFunc          func(ctx context.Context, orgID string, transfer *models.OwnershipTransfer) error
+}
+
+var _ repositories.OrgStore = (*OrgStore)(nil)
+
+// Create calls CreateFunc
+func (m *OrgStore) Create(ctx context.Context, org *models.Organization) error {
+	if m.CreateFunc == nil {
+		panic("mocks: OrgStore.Create called but CreateFunc isn't set")
+	}
+	return m.CreateFunc(ctx, org)
+}
+
+// GetByID calls GetByIDFunc
+func (m *OrgStore) GetByID(ctx context.Context, id string) (*models.Organization, error) {
+	if m.GetByIDFunc == nil {
+		panic("mocks: OrgStore.GetByID called but GetByIDFunc isn't set")
+	}
+	return m.GetByIDFunc(ctx, id)
+}
+
+// GetByName calls GetByNameFunc
+func (m *OrgStore) GetByName(ctx context.Context, name string) (*models.Organization, error) {
+	if m.GetByNameFunc == nil {
+		panic("mocks: OrgStore.GetByName called but GetByNameFunc isn't set")
+	}
+	return m.GetByNameFunc(ctx, name)
+}
+
+// GetOrganizationsByUser calls GetOrganizationsByUserFunc
+func (m *OrgStore) GetOrganizationsByUser(ctx context.Context, userID string, tags []string, page, limit int, fields models.FieldSet) ([]*models.Organization, int64, error) {
+	if m.GetOrganizationsByUserFunc == nil {
+		panic("mocks: OrgStore.GetOrganizationsByUser called but GetOrganizationsByUserFunc isn't set")
+	}
+	return m.GetOrganizationsByUserFunc(ctx, userID, tags, page, limit, fields)
+}
+
+// GetOrganizationIDsByUser calls GetOrganizationIDsByUserFunc
+func (m *OrgStore) GetOrganizationIDsByUser(ctx context.Context, userID string) ([]string, error) {
+	if m.GetOrganizationIDsByUserFunc == nil {
+		panic("mocks: OrgStore.GetOrganizationIDsByUser called but GetOrganizationIDsByUserFunc isn't set")
+	}
+	return m.GetOrganizationIDsByUserFunc(ctx, userID)
+}
+
+// FindChangedForUser calls FindChangedForUserFunc
+func (m *OrgStore) FindChangedForUser(ctx context.Context, userID string, since time.Time, limit int64) ([]*models.Organization, error) {
+	if m.FindChangedForUserFunc == nil {
+		panic("mocks: OrgStore.FindChangedForUser called but FindChangedForUserFunc isn't set")
+	}
+	return m.FindChangedForUserFunc(ctx, userID, since, limit)
+}
+
+// ListOrganizations calls ListOrganizationsFunc
+func (m *OrgStore) ListOrganizations(ctx context.Context, tags []string, page, limit int, fields models.FieldSet) ([]*models.Organization, int64, error) {
+	if m.ListOrganizationsFunc == nil {
+		panic("mocks: OrgStore.ListOrganizations called but ListOrganizationsFunc isn't set")
+	}
+	return m.ListOrganizationsFunc(ctx, tags, page, limit, fields)
+}
+
+// ListDiscoverable calls ListDiscoverableFunc
+func (m *OrgStore) ListDiscoverable(ctx context.Context, directoryFilter models.DirectoryFilter, page, limit int) ([]*models.Organization, int64, error) {
+	if m.ListDiscoverableFunc == nil {
+		panic("mocks: OrgStore.ListDiscoverable called but ListDiscoverableFunc isn't set")
+	}
+	return m.ListDiscoverableFunc(ctx, directoryFilter, page, limit)
+}
+
+// FindBatch calls FindBatchFunc
+func (m *OrgStore) FindBatch(ctx context.Context, filter bson.M, limit int64) ([]*models.Organization, error) {
+	if m.FindBatchFunc == nil {
+		panic("mocks: OrgStore.FindBatch called but FindBatchFunc isn't set")
+	}
+	return m.FindBatchFunc(ctx, filter, limit)
+}
+
+// GetMembers calls GetMembersFunc
+func (m *OrgStore) GetMembers(ctx context.Context, org *models.Organization, filter models.OrganizationMemberFilter, page, limit int) ([]models.OrganizationMemberDetail, int64, error) {
+	if m.GetMembersFunc == nil {
+		panic("mocks: OrgStore.GetMembers called but GetMembersFunc isn't set")
+	}
+	return m.GetMembersFunc(ctx, org, filter, page, limit)
+}
+
+// Update calls UpdateFunc
+func (m *OrgStore) Update(ctx context.Context, org *models.Organization) error {
+	if m.UpdateFunc == nil {
+		panic("mocks: OrgStore.Update called but UpdateFunc isn't set")
+	}
+	return m.UpdateFunc(ctx, org)
+}
+
+// UpdateIfUnmodified calls UpdateIfUnmodifiedFunc
+func (m *OrgStore) UpdateIfUnmodified(ctx context.Context, org *models.Organization, updatedAt time.Time) error {
+	if m.UpdateIfUnmodifiedFunc == nil {
+		panic("mocks: OrgStore.UpdateIfUnmodified called but UpdateIfUnmodifiedFunc isn't set")
+	}
+	return m.UpdateIfUnmodifiedFunc(ctx, org, updatedAt)
+}
+
+// Delete calls DeleteFunc
+func (m *OrgStore) Delete(ctx context.Context, id string) error {
+	if m.DeleteFunc == nil {
+		panic("mocks: OrgStore.Delete called but DeleteFunc isn't set")
+	}
+	return m.DeleteFunc(ctx, id)
+}
+
+// AddMember calls AddMemberFunc
+func (m *OrgStore) AddMember(ctx context.Context, orgID, userID string, role models.OrganizationMemberRole, invitedBy string) error {
+	if m.AddMemberFunc == nil {
+		panic("mocks: OrgStore.AddMember called but AddMemberFunc isn't set")
+	}
+	return m.AddMemberFunc(ctx, orgID, userID, role, invitedBy)
+}
+
+// UpdateMemberRole calls UpdateMemberRoleFunc
+func (m *OrgStore) UpdateMemberRole(ctx context.Context, orgID, userID string, role models.OrganizationMemberRole) error {
+	if m.UpdateMemberRoleFunc == nil {
+		panic("mocks: OrgStore.UpdateMemberRole called but UpdateMemberRoleFunc isn't set")
+	}
+	return m.UpdateMemberRoleFunc(ctx, orgID, userID, role)
+}
+
+// SetMemberLicensed calls SetMemberLicensedFunc
+func (m *OrgStore) SetMemberLicensed(ctx context.Context, orgID, userID string, licensed bool) error {
+	if m.SetMemberLicensedFunc == nil {
+		panic("mocks: OrgStore.SetMemberLicensed called but SetMemberLicensedFunc isn't set")
+	}
+	return m.SetMemberLicensedFunc(ctx, orgID, userID, licensed)
+}
+
+// SetMemberExpiry calls SetMemberExpiryFunc
+func (m *OrgStore) SetMemberExpiry(ctx context.Context, orgID, userID string, expiresAt *time.Time) error {
+	if m.SetMemberExpiryFunc == nil {
+		panic("mocks: OrgStore.SetMemberExpiry called but SetMemberExpiryFunc isn't set")
+	}
+	return m.SetMemberExpiryFunc(ctx, orgID, userID, expiresAt)
+}
+
+// SetMemberProvisioning calls SetMemberProvisioningFunc
+func (m *OrgStore) SetMemberProvisioning(ctx context.Context, orgID, userID, externalID string, suspended bool) error {
+	if m.SetMemberProvisioningFunc == nil {
+		panic("mocks: OrgStore.SetMemberProvisioning called but SetMemberProvisioningFunc isn't set")
+	}
+	return m.SetMemberProvisioningFunc(ctx, orgID, userID, externalID, suspended)
+}
+
+// FindExpiredMembers calls FindExpiredMembersFunc
+func (m *OrgStore) FindExpiredMembers(ctx context.Context, now time.Time, limit int64) ([]*models.OrganizationMemberRecord, error) {
+	if m.FindExpiredMembersFunc == nil {
+		panic("mocks: OrgStore.FindExpiredMembers called but FindExpiredMembersFunc isn't set")
+	}
+	return m.FindExpiredMembersFunc(ctx, now, limit)
+}
+
+// SetMembersReviewed calls SetMembersReviewedFunc
+func (m *OrgStore) SetMembersReviewed(ctx context.Context, orgID string, userIDs []string, reviewedAt time.Time, reviewedBy string) error {
+	if m.SetMembersReviewedFunc == nil {
+		panic("mocks: OrgStore.SetMembersReviewed called but SetMembersReviewedFunc isn't set")
+	}
+	return m.SetMembersReviewedFunc(ctx, orgID, userIDs, reviewedAt, reviewedBy)
+}
+
+// MoveMembersToCollection calls MoveMembersToCollectionFunc
+func (m *OrgStore) MoveMembersToCollection(ctx context.Context, orgID string) error {
+	if m.MoveMembersToCollectionFunc == nil {
+		panic("mocks: OrgStore.MoveMembersToCollection called but MoveMembersToCollectionFunc isn't set")
+	}
+	return m.MoveMembersToCollectionFunc(ctx, orgID)
+}
+
+// MoveMembersToDocument calls MoveMembersToDocumentFunc
+func (m *OrgStore) MoveMembersToDocument(ctx context.Context, orgID string) error {
+	if m.MoveMembersToDocumentFunc == nil {
+		panic("mocks: OrgStore.MoveMembersToDocument called but MoveMembersToDocumentFunc isn't set")
+	}
+	return m.MoveMembersToDocumentFunc(ctx, orgID)
+}
+
+// CountBySize calls CountBySizeFunc
+func (m *OrgStore) CountBySize(ctx context.Context, buckets []models.OrganizationSizeBucket) ([]models.StatsCount, error) {
+	if m.CountBySizeFunc == nil {
+		panic("mocks: OrgStore.CountBySize called but CountBySizeFunc isn't set")
+	}
+	return m.CountBySizeFunc(ctx, buckets)
+}
+
+// FindOversizedOrganizations calls FindOversizedOrganizationsFunc
+func (m *OrgStore) FindOversizedOrganizations(ctx context.Context, threshold int) ([]string, error) {
+	if m.FindOversizedOrganizationsFunc == nil {
+		panic("mocks: OrgStore.FindOversizedOrganizations called but FindOversizedOrganizationsFunc isn't set")
+	}
+	return m.FindOversizedOrganizationsFunc(ctx, threshold)
+}
+
+// RemoveDuplicateMembers calls RemoveDuplicateMembersFunc
+func (m *OrgStore) RemoveDuplicateMembers(ctx context.Context) (int, error) {
+	if m.RemoveDuplicateMembersFunc == nil {
+		panic("mocks: OrgStore.RemoveDuplicateMembers called but RemoveDuplicateMembersFunc isn't set")
+	}
+	return m.RemoveDuplicateMembersFunc(ctx)
+}
+
+// RemoveMember calls RemoveMemberFunc
+func (m *OrgStore) RemoveMember(ctx context.Context, orgID, userID string) error {
+	if m.RemoveMemberFunc == nil {
+		panic("mocks: OrgStore.RemoveMember called but RemoveMemberFunc isn't set")
+	}
+	return m.RemoveMemberFunc(ctx, orgID, userID)
+}
+
+// ApplyMemberChanges calls ApplyMemberChangesFunc
+func (m *OrgStore) ApplyMemberChanges(ctx context.Context, org *models.Organization, changes models.OrganizationMemberChanges) error {
+	if m.ApplyMemberChangesFunc == nil {
+		panic("mocks: OrgStore.ApplyMemberChanges called but ApplyMemberChangesFunc isn't set")
+	}
+	return m.ApplyMemberChangesFunc(ctx, org, changes)
+}
+
+// AddTeam calls AddTeamFunc
+func (m *OrgStore) AddTeam(ctx context.Context, orgID, teamID string) error {
+	if m.AddTeamFunc == nil {
+		panic("mocks: OrgStore.AddTeam called but AddTeamFunc isn't set")
+	}
+	return m.AddTeamFunc(ctx, orgID, teamID)
+}
+
+// RemoveTeam calls RemoveTeamFunc
+func (m *OrgStore) RemoveTeam(ctx context.Context, orgID, teamID string) error {
+	if m.RemoveTeamFunc == nil {
+		panic("mocks: OrgStore.RemoveTeam called but RemoveTeamFunc isn't set")
+	}
+	return m.RemoveTeamFunc(ctx, orgID, teamID)
+}
+
+// AddTags calls AddTagsFunc
+func (m *OrgStore) AddTags(ctx context.Context, orgID string, tags []string) error {
+	if m.AddTagsFunc == nil {
+		panic("mocks: OrgStore.AddTags called but AddTagsFunc isn't set")
+	}
+	return m.AddTagsFunc(ctx, orgID, tags)
+}
+
+// RemoveTag calls RemoveTagFunc
+func (m *OrgStore) RemoveTag(ctx context.Context, orgID, tag string) error {
+	if m.RemoveTagFunc == nil {
+		panic("mocks: OrgStore.RemoveTag called but RemoveTagFunc isn't set")
+	}
+	return m.RemoveTagFunc(ctx, orgID, tag)
+}
+
+// SetPendingTransfer calls SetPendingTransferFunc
+func (m *OrgStore) SetPendingTransfer(ctx context.Context, orgID string, transfer *models.OwnershipTransfer) error {
+	if m.SetPendingTransferFunc == nil {
+		panic("mocks: OrgStore.SetPendingTransfer called but SetPendingTransferFunc isn't set")
+	}
+	return m.SetPendingTransferFunc(ctx, orgID, transfer)
+}
+
+// SetVerified calls SetVerifiedFunc
+func (m *OrgStore) SetVerified(ctx context.Context, orgID string, verifiedAt *time.Time) error {
+	if m.SetVerifiedFunc == nil {
+		panic("mocks: OrgStore.SetVerified called but SetVerifiedFunc isn't set")
+	}
+	return m.SetVerifiedFunc(ctx, orgID, verifiedAt)
+}
+
+// SetSubscription calls SetSubscriptionFunc
+func (m *OrgStore) SetSubscription(ctx context.Context, orgID string, subscription *models.Subscription) (bool, error) {
+	if m.SetSubscriptionFunc == nil {
+		panic("mocks: OrgStore.SetSubscription called but SetSubscriptionFunc isn't set")
+	}
+	return m.SetSubscriptionFunc(ctx, orgID, subscription)
+}
+
+// TransferOwnership calls TransferOwnershipFunc
+func (m *OrgStore) TransferOwnership(ctx context.Context, orgID string, transfer *models.OwnershipTransfer) error {
+	if m.TransferOwnershipFunc == nil {
+		panic("mocks: OrgStore.TransferOwnership called but TransferOwnershipFunc isn't set")
+	}
+	return m.TransferOwnershipFunc(ctx, orgID, transfer)
+}
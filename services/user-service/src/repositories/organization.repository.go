@@ -3,26 +3,51 @@ package repositories
 import (
 	"context"
 	"errors"
+	"fmt"
+	"regexp"
 	"time"
 
-	"github.com/rs/zerolog/log"
 	"github.com/your-username/slido-clone/user-service/db"
 	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// OrganizationRepository is a repository for organizations
+// ErrOrganizationNameTaken is returned when an organization is created or
+// renamed with the name of another organization
+var ErrOrganizationNameTaken = apperrors.Conflict("ORGANIZATION_NAME_TAKEN", "an organization with this name already exists")
+
+// errMemberChangeConflict is returned by a member change whose conditional
+// update matched nothing, because of a concurrent change to the same member
+// or a move of the organization's members to another storage layout
+var errMemberChangeConflict = errors.New("organization member change conflicted with a concurrent change")
+
+// memberChangeAttempts is the number of times a conflicting member change is tried
+const memberChangeAttempts = 3
+
+// OrganizationRepository is a repository for organizations. Members are either
+// embedded in the organization document or, for large organizations, stored
+// in the organization_members collection; the repository reads and writes
+// both layouts. The permissions granted by the organization's groups are
+// loaded with the organization, and members leave their groups when they
+// leave the organization.
 type OrganizationRepository struct {
-	collection *mongo.Collection
+	collection db.Collection
+	members    db.Collection
+	groups     db.Collection
 }
 
 // NewOrganizationRepository creates a new organization repository
-func NewOrganizationRepository(mongoDB *db.MongoDB) *OrganizationRepository {
+func NewOrganizationRepository(store db.Storage) *OrganizationRepository {
 	return &OrganizationRepository{
-		collection: mongoDB.GetCollection(db.OrganizationsCollection),
+		collection: tenantCollection(store, db.OrganizationsCollection, "_id"),
+		members:    tenantCollection(store, db.OrgMembersCollection, "organizationId"),
+		groups:     store.GetCollection(db.GroupsCollection),
 	}
 }
 
@@ -31,17 +56,24 @@ func (r *OrganizationRepository) Create(ctx context.Context, org *models.Organiz
 	// Check if organization with the same name already exists
 	existingOrg, err := r.GetByName(ctx, org.Name)
 	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-		log.Error().Err(err).Str("name", org.Name).Msg("Error checking existing organization")
+		logger.Ctx(ctx).Error().Err(err).Str("name", org.Name).Msg("Error checking existing organization")
 		return err
 	}
 	if existingOrg != nil {
-		return errors.New("organization with this name already exists")
+		return ErrOrganizationNameTaken
 	}
 
-	// Create organization
-	result, err := r.collection.InsertOne(ctx, org)
+	// Create organization. Members stored in the members collection are
+	// written once the organization has its ID.
+	org.Search = org.SearchKeys()
+	members := org.Members
+	if org.HasMemberCollection() {
+		org.Members = []models.OrganizationMember{}
+	}
+	result, err := insertScoped(ctx, r.collection, org)
+	org.Members = members
 	if err != nil {
-		log.Error().Err(err).Interface("organization", org).Msg("Error creating organization")
+		logger.Ctx(ctx).Error().Err(err).Interface("organization", org).Msg("Error creating organization")
 		return err
 	}
 
@@ -50,7 +82,16 @@ func (r *OrganizationRepository) Create(ctx context.Context, org *models.Organiz
 		org.ID = oid.Hex()
 	}
 
-	log.Debug().Str("id", org.ID).Str("name", org.Name).Msg("Organization created")
+	if org.HasMemberCollection() {
+		for _, member := range members {
+			if err := r.upsertMemberRecord(ctx, models.NewOrganizationMemberRecord(org.ID, member)); err != nil {
+				logger.Ctx(ctx).Error().Err(err).Str("id", org.ID).Str("userId", member.UserID).Msg("Error creating organization member")
+				return err
+			}
+		}
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", org.ID).Str("name", org.Name).Msg("Organization created")
 	return nil
 }
 
@@ -64,12 +105,19 @@ func (r *OrganizationRepository) GetByID(ctx context.Context, id string) (*model
 	}
 
 	filter := bson.M{"_id": objID}
-	err = r.collection.FindOne(ctx, filter).Decode(&org)
+	err = db.ReadCollection(ctx, r.collection, "organizations.GetByID").FindOne(ctx, filter).Decode(&org)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, mongo.ErrNoDocuments
 		}
-		log.Error().Err(err).Str("id", id).Msg("Error getting organization by ID")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error getting organization by ID")
+		return nil, err
+	}
+
+	if err := r.loadMembers(ctx, &org); err != nil {
+		return nil, err
+	}
+	if err := r.loadGroupGrants(ctx, &org); err != nil {
 		return nil, err
 	}
 
@@ -86,24 +134,162 @@ func (r *OrganizationRepository) GetByName(ctx context.Context, name string) (*m
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, mongo.ErrNoDocuments
 		}
-		log.Error().Err(err).Str("name", name).Msg("Error getting organization by name")
+		logger.Ctx(ctx).Error().Err(err).Str("name", name).Msg("Error getting organization by name")
+		return nil, err
+	}
+
+	if err := r.loadMembers(ctx, &org); err != nil {
 		return nil, err
 	}
 
 	return &org, nil
 }
 
-// GetOrganizationsByUser gets organizations by user ID
-func (r *OrganizationRepository) GetOrganizationsByUser(ctx context.Context, userID string, page, limit int) ([]*models.Organization, int64, error) {
+// GetOrganizationsByUser gets organizations by user ID, only including
+// organizations with all of the tags. Only the stored fields the fieldset
+// needs are read.
+func (r *OrganizationRepository) GetOrganizationsByUser(ctx context.Context, userID string, tags []string, page, limit int, fields models.FieldSet) ([]*models.Organization, int64, error) {
 	var orgs []*models.Organization
 
 	// Build filter for organizations where the user is a member
+	filter, err := r.memberFilter(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter = filterTags(filter, tags)
+
+	// Count total
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error counting user organizations")
+		return nil, 0, err
+	}
+
+	// Set options for pagination and sorting
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"name": 1})
+	if projection := fieldProjection(fields, models.OrganizationResponseFields); projection != nil {
+		opts.SetProjection(projection)
+	}
+
+	// Find organizations
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error finding user organizations")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	// Decode organizations
+	if err := cursor.All(ctx, &orgs); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding user organizations")
+		return nil, 0, err
+	}
+
+	if fields.Has("members") || fields.Has("memberCount") {
+		if err := r.loadMembers(ctx, orgs...); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return orgs, total, nil
+}
+
+// GetOrganizationIDsByUser gets the IDs of every organization a user is a member of
+func (r *OrganizationRepository) GetOrganizationIDsByUser(ctx context.Context, userID string) ([]string, error) {
+	filter, err := r.memberFilter(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error finding user organization IDs")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var orgs []*models.Organization
+	if err := cursor.All(ctx, &orgs); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error decoding user organization IDs")
+		return nil, err
+	}
+
+	ids := make([]string, len(orgs))
+	for i, org := range orgs {
+		ids[i] = org.ID
+	}
+
+	return ids, nil
+}
+
+// FindChangedForUser gets up to limit organizations a user is a member of
+// that changed since a time, oldest change first
+func (r *OrganizationRepository) FindChangedForUser(ctx context.Context, userID string, since time.Time, limit int64) ([]*models.Organization, error) {
+	var orgs []*models.Organization
+
+	filter, err := r.memberFilter(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	filter = bson.M{"$and": bson.A{filter, bson.M{"updatedAt": bson.M{"$gte": since}}}}
+	opts := options.Find().
+		SetLimit(limit).
+		SetSort(bson.M{"updatedAt": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error finding changed user organizations")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &orgs); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error decoding changed user organizations")
+		return nil, err
+	}
+
+	if err := r.loadMembers(ctx, orgs...); err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+// memberFilter builds the filter of the organizations a user is a member of,
+// in either member storage layout
+func (r *OrganizationRepository) memberFilter(ctx context.Context, userID string) (bson.M, error) {
 	filter := bson.M{"members.userId": userID}
 
+	recordOrgIDs, err := r.memberRecordOrganizationIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(recordOrgIDs) > 0 {
+		filter = bson.M{"$or": bson.A{
+			filter,
+			bson.M{"_id": bson.M{"$in": recordOrgIDs}},
+		}}
+	}
+
+	return filter, nil
+}
+
+// ListOrganizations lists all organizations with pagination, only including
+// organizations with all of the tags. Only the stored fields the fieldset
+// needs are read.
+func (r *OrganizationRepository) ListOrganizations(ctx context.Context, tags []string, page, limit int, fields models.FieldSet) ([]*models.Organization, int64, error) {
+	var orgs []*models.Organization
+
+	// Build filter
+	filter := filterTags(bson.M{}, tags)
+
 	// Count total
 	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Error counting user organizations")
+		logger.Ctx(ctx).Error().Err(err).Msg("Error counting organizations")
 		return nil, 0, err
 	}
 
@@ -112,35 +298,50 @@ func (r *OrganizationRepository) GetOrganizationsByUser(ctx context.Context, use
 		SetSkip(int64((page - 1) * limit)).
 		SetLimit(int64(limit)).
 		SetSort(bson.M{"name": 1})
+	if projection := fieldProjection(fields, models.OrganizationResponseFields); projection != nil {
+		opts.SetProjection(projection)
+	}
 
 	// Find organizations
 	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Error finding user organizations")
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding organizations")
 		return nil, 0, err
 	}
 	defer cursor.Close(ctx)
 
 	// Decode organizations
 	if err := cursor.All(ctx, &orgs); err != nil {
-		log.Error().Err(err).Msg("Error decoding user organizations")
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding organizations")
 		return nil, 0, err
 	}
 
+	if fields.Has("members") || fields.Has("memberCount") {
+		if err := r.loadMembers(ctx, orgs...); err != nil {
+			return nil, 0, err
+		}
+	}
+
 	return orgs, total, nil
 }
 
-// ListOrganizations lists all organizations with pagination
-func (r *OrganizationRepository) ListOrganizations(ctx context.Context, page, limit int) ([]*models.Organization, int64, error) {
+// ListDiscoverable lists the organizations listed in the public directory
+// with pagination, sorted by name and matched against the filter's industry
+// and, by name, description or industry, its search
+func (r *OrganizationRepository) ListDiscoverable(ctx context.Context, directoryFilter models.DirectoryFilter, page, limit int) ([]*models.Organization, int64, error) {
 	var orgs []*models.Organization
 
 	// Build filter
-	filter := bson.M{}
+	filter := bson.M{"settings.features.discoverable": true}
+	if directoryFilter.Industry != "" {
+		filter["industry"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(directoryFilter.Industry) + "$", Options: "i"}
+	}
+	filter = filterSearch(filter, directoryFilter.Search, models.OrganizationSearchFields...)
 
 	// Count total
 	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
-		log.Error().Err(err).Msg("Error counting organizations")
+		logger.Ctx(ctx).Error().Err(err).Msg("Error counting discoverable organizations")
 		return nil, 0, err
 	}
 
@@ -153,40 +354,202 @@ func (r *OrganizationRepository) ListOrganizations(ctx context.Context, page, li
 	// Find organizations
 	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
-		log.Error().Err(err).Msg("Error finding organizations")
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding discoverable organizations")
 		return nil, 0, err
 	}
 	defer cursor.Close(ctx)
 
 	// Decode organizations
 	if err := cursor.All(ctx, &orgs); err != nil {
-		log.Error().Err(err).Msg("Error decoding organizations")
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding discoverable organizations")
+		return nil, 0, err
+	}
+
+	if err := r.loadMembers(ctx, orgs...); err != nil {
 		return nil, 0, err
 	}
 
 	return orgs, total, nil
 }
 
+// FindBatch gets up to limit organizations matching a filter, ordered by ID, for
+// keyset iteration over the whole collection
+func (r *OrganizationRepository) FindBatch(ctx context.Context, filter bson.M, limit int64) ([]*models.Organization, error) {
+	var orgs []*models.Organization
+
+	opts := options.Find().
+		SetLimit(limit).
+		SetSort(bson.M{"_id": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding organizations batch")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &orgs); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding organizations batch")
+		return nil, err
+	}
+
+	if err := r.loadMembers(ctx, orgs...); err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+// GetMembers gets a page of the members of an organization, oldest first,
+// with the details of their users. Members are matched against the filter's
+// role and, by user name or email, its search.
+func (r *OrganizationRepository) GetMembers(ctx context.Context, org *models.Organization, filter models.OrganizationMemberFilter, page, limit int) ([]models.OrganizationMemberDetail, int64, error) {
+	// Start from the member records or the embedded members, depending on
+	// the organization's layout
+	collection := r.members
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"organizationId": org.ID}}},
+	}
+	if !org.HasMemberCollection() {
+		objID, err := primitive.ObjectIDFromHex(org.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		collection = r.collection
+		pipeline = mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"_id": objID}}},
+			{{Key: "$unwind", Value: "$members"}},
+			{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$members"}}},
+		}
+	}
+	if filter.Role != "" {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"role": filter.Role}}})
+	}
+
+	lookupUser := mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.M{
+			"from":         db.UsersCollection,
+			"localField":   "userId",
+			"foreignField": "userId",
+			"as":           "user",
+		}}},
+		{{Key: "$unwind", Value: bson.M{"path": "$user", "preserveNullAndEmptyArrays": true}}},
+	}
+	paginate := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "joinedAt", Value: 1}, {Key: "userId", Value: 1}}}},
+		{{Key: "$skip", Value: int64((page - 1) * limit)}},
+		{{Key: "$limit", Value: int64(limit)}},
+	}
+	project := bson.D{{Key: "$project", Value: bson.M{
+		"_id":            0,
+		"userId":         1,
+		"role":           1,
+		"joinedAt":       1,
+		"invitedBy":      1,
+		"licensed":       1,
+		"expiresAt":      1,
+		"reviewedAt":     1,
+		"reviewedBy":     1,
+		"email":          "$user.email",
+		"firstName":      "$user.firstName",
+		"lastName":       "$user.lastName",
+		"profilePicture": "$user.profilePicture",
+		"status":         "$user.status",
+		"customFields":   "$user.customFields." + org.ID,
+		"lastSeenAt":     "$user.lastSeenAt",
+	}}}
+
+	// Searching needs every member's user; otherwise only the users of the
+	// page are looked up
+	var members mongo.Pipeline
+	if filter.Search != "" {
+		search := primitive.Regex{Pattern: regexp.QuoteMeta(filter.Search), Options: "i"}
+		pipeline = append(pipeline, lookupUser...)
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"user.firstName": search},
+			bson.M{"user.lastName": search},
+			bson.M{"user.email": search},
+		}}}})
+		members = append(paginate, project)
+	} else {
+		members = append(append(paginate, lookupUser...), project)
+	}
+
+	pipeline = append(pipeline, bson.D{{Key: "$facet", Value: bson.M{
+		"total":   bson.A{bson.M{"$count": "count"}},
+		"members": members,
+	}}})
+
+	var results []struct {
+		Total []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+		Members []models.OrganizationMemberDetail `bson:"members"`
+	}
+	if err := aggregate(ctx, collection, pipeline, &results); err != nil {
+		if !errors.Is(err, db.ErrAggregationUnsupported) {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Error getting organization members")
+		}
+		return nil, 0, err
+	}
+
+	details := []models.OrganizationMemberDetail{}
+	var total int64
+	if len(results) > 0 {
+		if results[0].Members != nil {
+			details = results[0].Members
+		}
+		if len(results[0].Total) > 0 {
+			total = results[0].Total[0].Count
+		}
+	}
+	for i := range details {
+		details[i].SetFullName()
+	}
+
+	return details, total, nil
+}
+
 // Update updates an organization
 func (r *OrganizationRepository) Update(ctx context.Context, org *models.Organization) error {
+	return r.update(ctx, org, nil)
+}
+
+// UpdateIfUnmodified updates an organization unless it changed since the
+// version last updated at updatedAt was read. It returns
+// mongo.ErrNoDocuments if it did.
+func (r *OrganizationRepository) UpdateIfUnmodified(ctx context.Context, org *models.Organization, updatedAt time.Time) error {
+	return r.update(ctx, org, &updatedAt)
+}
+
+// update updates an organization, conditional on its last update if one is
+// given
+func (r *OrganizationRepository) update(ctx context.Context, org *models.Organization, updatedAt *time.Time) error {
 	objID, err := primitive.ObjectIDFromHex(org.ID)
 	if err != nil {
 		return err
 	}
 
 	filter := bson.M{"_id": objID}
+	if updatedAt != nil {
+		filter["updatedAt"] = *updatedAt
+	}
 
-	// Check if updating name and if new name conflicts with existing organization
-	existingOrg, err := r.GetByName(ctx, org.Name)
+	// Check if updating name and if new name conflicts with existing
+	// organization; names are unique across tenants
+	existingOrg, err := r.GetByName(tenancy.Unscoped(ctx), org.Name)
 	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-		log.Error().Err(err).Str("name", org.Name).Msg("Error checking organization name conflict")
+		logger.Ctx(ctx).Error().Err(err).Str("name", org.Name).Msg("Error checking organization name conflict")
 		return err
 	}
 	if existingOrg != nil && existingOrg.ID != org.ID {
-		return errors.New("another organization with this name already exists")
+		return ErrOrganizationNameTaken
 	}
 
-	// Update organization
+	// Update organization. Members are only changed through the member
+	// methods, which handle both member storage layouts.
+	now := time.Now()
 	update := bson.M{
 		"$set": bson.M{
 			"name":        org.Name,
@@ -196,19 +559,23 @@ func (r *OrganizationRepository) Update(ctx context.Context, org *models.Organiz
 			"industry":    org.Industry,
 			"size":        org.Size,
 			"location":    org.Location,
-			"members":     org.Members,
 			"settings":    org.Settings,
-			"updatedAt":   time.Now(),
+			"search":      org.SearchKeys(),
+			"updatedAt":   now,
 		},
 	}
 
-	_, err = r.collection.UpdateOne(ctx, filter, update)
+	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Str("id", org.ID).Msg("Error updating organization")
+		logger.Ctx(ctx).Error().Err(err).Str("id", org.ID).Msg("Error updating organization")
 		return err
 	}
+	if updatedAt != nil && result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	org.UpdatedAt = now
 
-	log.Debug().Str("id", org.ID).Msg("Organization updated")
+	logger.Ctx(ctx).Debug().Str("id", org.ID).Msg("Organization updated")
 	return nil
 }
 
@@ -222,173 +589,1610 @@ func (r *OrganizationRepository) Delete(ctx context.Context, id string) error {
 	filter := bson.M{"_id": objID}
 	_, err = r.collection.DeleteOne(ctx, filter)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Error deleting organization")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error deleting organization")
+		return err
+	}
+
+	if err := r.deleteMemberRecords(ctx, id); err != nil {
+		return err
+	}
+	if err := r.deleteGroups(ctx, id); err != nil {
 		return err
 	}
 
-	log.Debug().Str("id", id).Msg("Organization deleted")
+	logger.Ctx(ctx).Debug().Str("id", id).Msg("Organization deleted")
 	return nil
 }
 
-// AddMember adds a member to an organization
+// AddMember adds a member to an organization, or updates the role of an existing
+// member. Each step is a single conditional update, so concurrent adds of the
+// same user can't create duplicate member entries.
 func (r *OrganizationRepository) AddMember(ctx context.Context, orgID, userID string, role models.OrganizationMemberRole, invitedBy string) error {
 	objID, err := primitive.ObjectIDFromHex(orgID)
 	if err != nil {
 		return err
 	}
 
-	// Check if member already exists
-	filter := bson.M{
-		"_id":            objID,
-		"members.userId": userID,
-	}
+	err = r.changeMembers(ctx, objID,
+		func() error {
+			// Update the role if the user is already a member
+			updated, err := r.setMemberRole(ctx, objID, userID, role)
+			if err != nil || updated {
+				return err
+			}
 
-	existingOrg, err := r.collection.CountDocuments(ctx, filter)
-	if err != nil {
-		log.Error().Err(err).Str("orgId", orgID).Str("userId", userID).
-			Msg("Error checking existing organization member")
-		return err
-	}
+			// Add the member, conditional on the user not being a member yet
+			now := time.Now()
+			filter := bson.M{
+				"_id":            objID,
+				"members.userId": bson.M{"$ne": userID},
+				"memberStorage":  bson.M{"$ne": models.MemberStorageCollection},
+			}
+			update := bson.M{
+				"$push": bson.M{
+					"members": bson.M{
+						"userId":    userID,
+						"role":      role,
+						"joinedAt":  now,
+						"invitedBy": invitedBy,
+					},
+				},
+				"$set": bson.M{
+					"updatedAt": now,
+				},
+			}
 
-	if existingOrg > 0 {
-		// Member already exists, update role
-		filter = bson.M{"_id": objID, "members.userId": userID}
-		update := bson.M{
-			"$set": bson.M{
-				"members.$.role": role,
-				"updatedAt":      time.Now(),
-			},
-		}
+			result, err := r.collection.UpdateOne(ctx, filter, update)
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return errMemberChangeConflict
+			}
+			return nil
+		},
+		func() error {
+			record := models.NewOrganizationMemberRecord(orgID, models.OrganizationMember{
+				UserID:    userID,
+				Role:      role,
+				JoinedAt:  time.Now(),
+				InvitedBy: invitedBy,
+			})
+			if err := r.upsertMemberRecord(ctx, record); err != nil {
+				return err
+			}
 
-		_, err = r.collection.UpdateOne(ctx, filter, update)
-		if err != nil {
-			log.Error().Err(err).Str("orgId", orgID).Str("userId", userID).
-				Msg("Error updating organization member role")
+			// A record written after the members moved back into the
+			// document would be orphaned
+			err := r.touchMemberCollection(ctx, objID)
+			if errors.Is(err, errMemberChangeConflict) {
+				if _, deleteErr := r.members.DeleteOne(ctx, bson.M{"_id": record.ID}); deleteErr != nil {
+					return deleteErr
+				}
+			}
 			return err
-		}
-
-		log.Debug().Str("orgId", orgID).Str("userId", userID).
-			Str("role", string(role)).Msg("Organization member role updated")
-	} else {
-		// Add new member
-		now := time.Now()
-		filter = bson.M{"_id": objID}
-		update := bson.M{
-			"$push": bson.M{
-				"members": bson.M{
-					"userId":    userID,
-					"role":      role,
-					"joinedAt":  now,
-					"invitedBy": invitedBy,
-				},
-			},
-			"$set": bson.M{
-				"updatedAt": now,
-			},
-		}
-
-		_, err = r.collection.UpdateOne(ctx, filter, update)
-		if err != nil {
-			log.Error().Err(err).Str("orgId", orgID).Str("userId", userID).
+		},
+	)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", userID).
 				Msg("Error adding organization member")
-			return err
 		}
-
-		log.Debug().Str("orgId", orgID).Str("userId", userID).
-			Str("role", string(role)).Msg("Organization member added")
+		return err
 	}
 
+	logger.Ctx(ctx).Debug().Str("orgId", orgID).Str("userId", userID).
+		Str("role", string(role)).Msg("Organization member added")
 	return nil
 }
 
-// RemoveMember removes a member from an organization
-func (r *OrganizationRepository) RemoveMember(ctx context.Context, orgID, userID string) error {
+// UpdateMemberRole updates the role of an existing organization member
+func (r *OrganizationRepository) UpdateMemberRole(ctx context.Context, orgID, userID string, role models.OrganizationMemberRole) error {
 	objID, err := primitive.ObjectIDFromHex(orgID)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{"_id": objID}
-	update := bson.M{
-		"$pull": bson.M{
-			"members": bson.M{"userId": userID},
+	err = r.changeMembers(ctx, objID,
+		func() error {
+			updated, err := r.setMemberRole(ctx, objID, userID, role)
+			if err == nil && !updated {
+				return errMemberChangeConflict
+			}
+			return err
 		},
-		"$set": bson.M{
-			"updatedAt": time.Now(),
+		func() error {
+			filter := bson.M{"_id": models.OrganizationMemberRecordID(orgID, userID)}
+			update := bson.M{"$set": bson.M{"role": role}}
+
+			result, err := r.members.UpdateOne(ctx, filter, update)
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return errMemberChangeConflict
+			}
+			return r.touchMemberCollection(ctx, objID)
 		},
+	)
+	if errors.Is(err, errMemberChangeConflict) {
+		return errors.New("member not found in organization")
 	}
-
-	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Str("orgId", orgID).Str("userId", userID).
-			Msg("Error removing organization member")
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", userID).
+			Msg("Error updating organization member role")
 		return err
 	}
 
-	if result.ModifiedCount == 0 {
-		return errors.New("member not found in organization")
-	}
-
-	log.Debug().Str("orgId", orgID).Str("userId", userID).Msg("Organization member removed")
+	logger.Ctx(ctx).Debug().Str("orgId", orgID).Str("userId", userID).
+		Str("role", string(role)).Msg("Organization member role updated")
 	return nil
 }
 
-// AddTeam adds a team to an organization
-func (r *OrganizationRepository) AddTeam(ctx context.Context, orgID, teamID string) error {
+// SetMemberLicensed assigns or frees the presenter seat of an organization
+// member
+func (r *OrganizationRepository) SetMemberLicensed(ctx context.Context, orgID, userID string, licensed bool) error {
 	objID, err := primitive.ObjectIDFromHex(orgID)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{"_id": objID}
-	update := bson.M{
-		"$addToSet": bson.M{
-			"teamIds": teamID,
+	err = r.changeMembers(ctx, objID,
+		func() error {
+			filter := bson.M{
+				"_id":            objID,
+				"members.userId": userID,
+				"memberStorage":  bson.M{"$ne": models.MemberStorageCollection},
+			}
+			update := bson.M{
+				"$set": bson.M{
+					"members.$[member].licensed": licensed,
+					"updatedAt":                  time.Now(),
+				},
+			}
+			opts := options.Update().SetArrayFilters(options.ArrayFilters{
+				Filters: []interface{}{bson.M{"member.userId": userID}},
+			})
+
+			result, err := r.collection.UpdateOne(ctx, filter, update, opts)
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return errMemberChangeConflict
+			}
+			return nil
 		},
-		"$set": bson.M{
-			"updatedAt": time.Now(),
+		func() error {
+			filter := bson.M{"_id": models.OrganizationMemberRecordID(orgID, userID)}
+			update := bson.M{"$set": bson.M{"licensed": licensed}}
+
+			result, err := r.members.UpdateOne(ctx, filter, update)
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return errMemberChangeConflict
+			}
+			return r.touchMemberCollection(ctx, objID)
 		},
+	)
+	if errors.Is(err, errMemberChangeConflict) {
+		return errors.New("member not found in organization")
 	}
-
-	_, err = r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Str("orgId", orgID).Str("teamId", teamID).
-			Msg("Error adding team to organization")
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", userID).
+			Msg("Error setting organization member seat")
 		return err
 	}
 
-	log.Debug().Str("orgId", orgID).Str("teamId", teamID).Msg("Team added to organization")
+	logger.Ctx(ctx).Debug().Str("orgId", orgID).Str("userId", userID).
+		Bool("licensed", licensed).Msg("Organization member seat updated")
 	return nil
 }
 
-// RemoveTeam removes a team from an organization
-func (r *OrganizationRepository) RemoveTeam(ctx context.Context, orgID, teamID string) error {
+// SetMemberExpiry sets when the membership of an organization member ends, or
+// clears it when expiresAt is nil
+func (r *OrganizationRepository) SetMemberExpiry(ctx context.Context, orgID, userID string, expiresAt *time.Time) error {
 	objID, err := primitive.ObjectIDFromHex(orgID)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{"_id": objID}
-	update := bson.M{
-		"$pull": bson.M{
-			"teamIds": teamID,
+	err = r.changeMembers(ctx, objID,
+		func() error {
+			filter := bson.M{
+				"_id":            objID,
+				"members.userId": userID,
+				"memberStorage":  bson.M{"$ne": models.MemberStorageCollection},
+			}
+			update := bson.M{
+				"$set": bson.M{
+					"members.$[member].expiresAt": expiresAt,
+					"updatedAt":                   time.Now(),
+				},
+			}
+			if expiresAt == nil {
+				update = bson.M{
+					"$unset": bson.M{"members.$[member].expiresAt": ""},
+					"$set":   bson.M{"updatedAt": time.Now()},
+				}
+			}
+			opts := options.Update().SetArrayFilters(options.ArrayFilters{
+				Filters: []interface{}{bson.M{"member.userId": userID}},
+			})
+
+			result, err := r.collection.UpdateOne(ctx, filter, update, opts)
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return errMemberChangeConflict
+			}
+			return nil
 		},
-		"$set": bson.M{
-			"updatedAt": time.Now(),
+		func() error {
+			filter := bson.M{"_id": models.OrganizationMemberRecordID(orgID, userID)}
+			update := bson.M{"$set": bson.M{"expiresAt": expiresAt}}
+			if expiresAt == nil {
+				update = bson.M{"$unset": bson.M{"expiresAt": ""}}
+			}
+
+			result, err := r.members.UpdateOne(ctx, filter, update)
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return errMemberChangeConflict
+			}
+			return r.touchMemberCollection(ctx, objID)
 		},
+	)
+	if errors.Is(err, errMemberChangeConflict) {
+		return errors.New("member not found in organization")
 	}
-
-	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Str("orgId", orgID).Str("teamId", teamID).
-			Msg("Error removing team from organization")
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", userID).
+			Msg("Error setting organization member expiry")
 		return err
 	}
 
-	if result.ModifiedCount == 0 {
-		return errors.New("team not found in organization")
+	logger.Ctx(ctx).Debug().Str("orgId", orgID).Str("userId", userID).
+		Msg("Organization member expiry updated")
+	return nil
+}
+
+// SetMemberProvisioning records how the identity provider of an organization
+// knows one of its members: their external ID and whether it suspended them
+func (r *OrganizationRepository) SetMemberProvisioning(ctx context.Context, orgID, userID, externalID string, suspended bool) error {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return err
 	}
 
-	log.Debug().Str("orgId", orgID).Str("teamId", teamID).Msg("Team removed from organization")
-	return nil
+	err = r.changeMembers(ctx, objID,
+		func() error {
+			filter := bson.M{
+				"_id":            objID,
+				"members.userId": userID,
+				"memberStorage":  bson.M{"$ne": models.MemberStorageCollection},
+			}
+			update := bson.M{
+				"$set": bson.M{
+					"members.$[member].externalId": externalID,
+					"members.$[member].suspended":  suspended,
+					"updatedAt":                    time.Now(),
+				},
+			}
+			opts := options.Update().SetArrayFilters(options.ArrayFilters{
+				Filters: []interface{}{bson.M{"member.userId": userID}},
+			})
+
+			result, err := r.collection.UpdateOne(ctx, filter, update, opts)
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return errMemberChangeConflict
+			}
+			return nil
+		},
+		func() error {
+			filter := bson.M{"_id": models.OrganizationMemberRecordID(orgID, userID)}
+			update := bson.M{"$set": bson.M{"externalId": externalID, "suspended": suspended}}
+
+			result, err := r.members.UpdateOne(ctx, filter, update)
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return errMemberChangeConflict
+			}
+			return r.touchMemberCollection(ctx, objID)
+		},
+	)
+	if errors.Is(err, errMemberChangeConflict) {
+		return errors.New("member not found in organization")
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", userID).
+			Msg("Error setting organization member provisioning")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("orgId", orgID).Str("userId", userID).
+		Bool("suspended", suspended).Msg("Organization member provisioning updated")
+	return nil
+}
+
+// FindExpiredMembers gets up to limit members, of organizations in either
+// member storage layout, whose membership ended by now. Owners never expire.
+func (r *OrganizationRepository) FindExpiredMembers(ctx context.Context, now time.Time, limit int64) ([]*models.OrganizationMemberRecord, error) {
+	expired := bson.M{
+		"role":      bson.M{"$ne": models.OrgRoleOwner},
+		"expiresAt": bson.M{"$lte": now},
+	}
+
+	// Members of organizations that embed them
+	filter := bson.M{
+		"memberStorage": bson.M{"$ne": models.MemberStorageCollection},
+		"members":       bson.M{"$elemMatch": expired},
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetLimit(limit))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding organizations with expired members")
+		return nil, err
+	}
+	var orgs []*models.Organization
+	if err := cursor.All(ctx, &orgs); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding organizations with expired members")
+		return nil, err
+	}
+
+	members := make([]*models.OrganizationMemberRecord, 0)
+	for _, org := range orgs {
+		for _, member := range org.Members {
+			if member.IsExpired(now) && int64(len(members)) < limit {
+				members = append(members, models.NewOrganizationMemberRecord(org.ID, member))
+			}
+		}
+	}
+	if int64(len(members)) >= limit {
+		return members, nil
+	}
+
+	// Members in the members collection
+	cursor, err = r.members.Find(ctx, expired, options.Find().SetLimit(limit-int64(len(members))))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding expired member records")
+		return nil, err
+	}
+	var records []*models.OrganizationMemberRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding expired member records")
+		return nil, err
+	}
+
+	return append(members, records...), nil
+}
+
+// SetMembersReviewed records that an access review confirmed members of an
+// organization. Users that aren't members are skipped.
+func (r *OrganizationRepository) SetMembersReviewed(ctx context.Context, orgID string, userIDs []string, reviewedAt time.Time, reviewedBy string) error {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return err
+	}
+
+	err = r.changeMembers(ctx, objID,
+		func() error {
+			filter := bson.M{
+				"_id":           objID,
+				"memberStorage": bson.M{"$ne": models.MemberStorageCollection},
+			}
+			update := bson.M{
+				"$set": bson.M{
+					"members.$[member].reviewedAt": reviewedAt,
+					"members.$[member].reviewedBy": reviewedBy,
+					"updatedAt":                    time.Now(),
+				},
+			}
+			opts := options.Update().SetArrayFilters(options.ArrayFilters{
+				Filters: []interface{}{bson.M{"member.userId": bson.M{"$in": userIDs}}},
+			})
+
+			result, err := r.collection.UpdateOne(ctx, filter, update, opts)
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return errMemberChangeConflict
+			}
+			return nil
+		},
+		func() error {
+			update := bson.M{"$set": bson.M{"reviewedAt": reviewedAt, "reviewedBy": reviewedBy}}
+			for _, userID := range userIDs {
+				filter := bson.M{"_id": models.OrganizationMemberRecordID(orgID, userID)}
+				if _, err := r.members.UpdateOne(ctx, filter, update); err != nil {
+					return err
+				}
+			}
+			return r.touchMemberCollection(ctx, objID)
+		},
+	)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error recording organization access review")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("orgId", orgID).Int("members", len(userIDs)).
+		Msg("Organization access review recorded")
+	return nil
+}
+
+// setMemberRole sets the role of every embedded member entry of a user in one
+// conditional update. It reports false if the user isn't a member.
+func (r *OrganizationRepository) setMemberRole(ctx context.Context, objID primitive.ObjectID, userID string, role models.OrganizationMemberRole) (bool, error) {
+	filter := bson.M{
+		"_id":            objID,
+		"members.userId": userID,
+		"memberStorage":  bson.M{"$ne": models.MemberStorageCollection},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"members.$[member].role": role,
+			"updatedAt":              time.Now(),
+		},
+	}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"member.userId": userID}},
+	})
+
+	result, err := r.collection.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// changeMembers runs a member change against the member storage layout of an
+// organization. A change that conflicts, with a concurrent change or a move of
+// the members to the other layout, is retried with the current layout.
+func (r *OrganizationRepository) changeMembers(ctx context.Context, objID primitive.ObjectID, embedded, collection func() error) error {
+	var err error
+	for attempt := 0; attempt < memberChangeAttempts; attempt++ {
+		var storage models.MemberStorage
+		if storage, err = r.memberStorage(ctx, objID); err != nil {
+			return err
+		}
+
+		if storage == models.MemberStorageCollection {
+			err = collection()
+		} else {
+			err = embedded()
+		}
+		if !errors.Is(err, errMemberChangeConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// memberStorage gets the member storage layout of an organization, without
+// loading its members
+func (r *OrganizationRepository) memberStorage(ctx context.Context, objID primitive.ObjectID) (models.MemberStorage, error) {
+	var org models.Organization
+	opts := options.FindOne().SetProjection(bson.M{"memberStorage": 1})
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}, opts).Decode(&org); err != nil {
+		return "", err
+	}
+
+	if org.HasMemberCollection() {
+		return models.MemberStorageCollection, nil
+	}
+	return models.MemberStorageEmbedded, nil
+}
+
+// touchMemberCollection records a change to the members collection on the
+// organization, conditional on it still using that layout, so a concurrent
+// move of the members notices the change
+func (r *OrganizationRepository) touchMemberCollection(ctx context.Context, objID primitive.ObjectID) error {
+	filter := bson.M{
+		"_id":           objID,
+		"memberStorage": models.MemberStorageCollection,
+	}
+	update := bson.M{"$set": bson.M{"updatedAt": time.Now()}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errMemberChangeConflict
+	}
+	return nil
+}
+
+// upsertMemberRecord writes a member record, keeping the join details of an
+// existing record
+func (r *OrganizationRepository) upsertMemberRecord(ctx context.Context, record *models.OrganizationMemberRecord) error {
+	filter := bson.M{"_id": record.ID}
+	insert := bson.M{
+		"organizationId": record.OrganizationID,
+		"userId":         record.UserID,
+		"joinedAt":       record.JoinedAt,
+		"invitedBy":      record.InvitedBy,
+		"licensed":       record.Licensed,
+	}
+	if record.ExpiresAt != nil {
+		insert["expiresAt"] = record.ExpiresAt
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"role": record.Role,
+		},
+		"$setOnInsert": insert,
+	}
+
+	_, err := r.members.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// getMemberRecords gets the member records of an organization, oldest first
+func (r *OrganizationRepository) getMemberRecords(ctx context.Context, orgIDs ...string) ([]*models.OrganizationMemberRecord, error) {
+	var records []*models.OrganizationMemberRecord
+
+	opts := options.Find().SetSort(bson.M{"joinedAt": 1})
+	cursor, err := db.ReadCollection(ctx, r.members, "organizations.GetByID").Find(ctx, bson.M{"organizationId": bson.M{"$in": orgIDs}}, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Strs("orgIds", orgIDs).Msg("Error finding organization members")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &records); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Strs("orgIds", orgIDs).Msg("Error decoding organization members")
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// loadMembers loads the members of organizations that store them in the
+// members collection
+func (r *OrganizationRepository) loadMembers(ctx context.Context, orgs ...*models.Organization) error {
+	byID := make(map[string]*models.Organization)
+	orgIDs := make([]string, 0)
+	for _, org := range orgs {
+		if org.HasMemberCollection() {
+			org.Members = []models.OrganizationMember{}
+			byID[org.ID] = org
+			orgIDs = append(orgIDs, org.ID)
+		}
+	}
+	if len(orgIDs) == 0 {
+		return nil
+	}
+
+	records, err := r.getMemberRecords(ctx, orgIDs...)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if org, ok := byID[record.OrganizationID]; ok {
+			org.Members = append(org.Members, record.ToMember())
+		}
+	}
+
+	return nil
+}
+
+// memberRecordOrganizationIDs gets the IDs of the organizations a user is a
+// member of through the members collection
+func (r *OrganizationRepository) memberRecordOrganizationIDs(ctx context.Context, userID string) ([]primitive.ObjectID, error) {
+	cursor, err := r.members.Find(ctx, bson.M{"userId": userID})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error finding organization member records")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []*models.OrganizationMemberRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error decoding organization member records")
+		return nil, err
+	}
+
+	orgIDs := make([]primitive.ObjectID, 0, len(records))
+	for _, record := range records {
+		if objID, err := primitive.ObjectIDFromHex(record.OrganizationID); err == nil {
+			orgIDs = append(orgIDs, objID)
+		}
+	}
+
+	return orgIDs, nil
+}
+
+// deleteMemberRecords deletes the member records of an organization
+func (r *OrganizationRepository) deleteMemberRecords(ctx context.Context, orgID string) error {
+	records, err := r.getMemberRecords(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if _, err := r.members.DeleteOne(ctx, bson.M{"_id": record.ID}); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", record.UserID).
+				Msg("Error deleting organization member record")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MoveMembersToCollection moves the embedded members of an organization to
+// the members collection. It does nothing if they were already moved.
+func (r *OrganizationRepository) MoveMembersToCollection(ctx context.Context, orgID string) error {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return err
+	}
+
+	var org models.Organization
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&org); err != nil {
+		return err
+	}
+	if org.HasMemberCollection() {
+		return nil
+	}
+
+	for _, member := range org.Members {
+		if err := r.upsertMemberRecord(ctx, models.NewOrganizationMemberRecord(orgID, member)); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", member.UserID).
+				Msg("Error copying organization member")
+			return err
+		}
+	}
+
+	// Conditional on the organization not having changed since it was read,
+	// so members changed meanwhile aren't lost
+	filter := bson.M{
+		"_id":           objID,
+		"updatedAt":     org.UpdatedAt,
+		"memberStorage": bson.M{"$ne": models.MemberStorageCollection},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"memberStorage": models.MemberStorageCollection,
+			"members":       []models.OrganizationMember{},
+			"updatedAt":     time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error moving organization members to collection")
+		return err
+	}
+	if result.MatchedCount == 0 {
+		// The copied records are stale unless the members were moved concurrently
+		if storage, err := r.memberStorage(ctx, objID); err == nil && storage == models.MemberStorageCollection {
+			return nil
+		}
+		if err := r.deleteMemberRecords(ctx, orgID); err != nil {
+			return err
+		}
+		return fmt.Errorf("organization %s changed while moving its members", orgID)
+	}
+
+	logger.Ctx(ctx).Info().Str("orgId", orgID).Int("members", len(org.Members)).
+		Msg("Moved organization members to collection")
+	return nil
+}
+
+// MoveMembersToDocument moves the members of an organization from the members
+// collection back into the organization document. It does nothing if they
+// are already embedded.
+func (r *OrganizationRepository) MoveMembersToDocument(ctx context.Context, orgID string) error {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return err
+	}
+
+	var org models.Organization
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&org); err != nil {
+		return err
+	}
+	if !org.HasMemberCollection() {
+		return nil
+	}
+
+	records, err := r.getMemberRecords(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	members := make([]models.OrganizationMember, 0, len(records))
+	for _, record := range records {
+		members = append(members, record.ToMember())
+	}
+
+	// Conditional on the organization not having changed since it was read;
+	// member changes in the collection touch the organization
+	filter := bson.M{
+		"_id":           objID,
+		"updatedAt":     org.UpdatedAt,
+		"memberStorage": models.MemberStorageCollection,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"memberStorage": models.MemberStorageEmbedded,
+			"members":       members,
+			"updatedAt":     time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error moving organization members to document")
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("organization %s changed while moving its members", orgID)
+	}
+
+	if err := r.deleteMemberRecords(ctx, orgID); err != nil {
+		return err
+	}
+
+	logger.Ctx(ctx).Info().Str("orgId", orgID).Int("members", len(members)).
+		Msg("Moved organization members to document")
+	return nil
+}
+
+// CountBySize counts organizations grouped by the member count buckets
+// they fall in. Members are counted in either storage layout.
+func (r *OrganizationRepository) CountBySize(ctx context.Context, buckets []models.OrganizationSizeBucket) ([]models.StatsCount, error) {
+	var branches bson.A
+	var fallback string
+	for _, bucket := range buckets {
+		if bucket.MaxMembers == 0 {
+			fallback = bucket.Label
+			continue
+		}
+		branches = append(branches, bson.M{
+			"case": bson.M{"$lte": bson.A{"$memberCount", bucket.MaxMembers}},
+			"then": bucket.Label,
+		})
+	}
+
+	pipeline := mongo.Pipeline{
+		// Only organizations in the members collection have member records
+		{{Key: "$lookup", Value: bson.M{
+			"from": db.OrgMembersCollection,
+			"let":  bson.M{"orgId": bson.M{"$toString": "$_id"}},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$organizationId", "$$orgId"}}}},
+				bson.M{"$count": "count"},
+			},
+			"as": "memberRecords",
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"memberCount": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$memberStorage", models.MemberStorageCollection}},
+				bson.M{"$ifNull": bson.A{bson.M{"$first": "$memberRecords.count"}, 0}},
+				bson.M{"$size": bson.M{"$ifNull": bson.A{"$members", bson.A{}}}},
+			}},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$switch": bson.M{"branches": branches, "default": fallback}},
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	var counts []models.StatsCount
+	if err := aggregate(ctx, r.collection, pipeline, &counts); err != nil {
+		if !errors.Is(err, db.ErrAggregationUnsupported) {
+			logger.Ctx(ctx).Error().Err(err).Msg("Error counting organizations by size")
+		}
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// FindOversizedOrganizations gets the IDs of organizations that embed more
+// than threshold members
+func (r *OrganizationRepository) FindOversizedOrganizations(ctx context.Context, threshold int) ([]string, error) {
+	filter := bson.M{
+		"memberStorage":                      bson.M{"$ne": models.MemberStorageCollection},
+		fmt.Sprintf("members.%d", threshold): bson.M{"$exists": true},
+	}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding oversized organizations")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var orgs []*models.Organization
+	if err := cursor.All(ctx, &orgs); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding oversized organizations")
+		return nil, err
+	}
+
+	ids := make([]string, len(orgs))
+	for i, org := range orgs {
+		ids[i] = org.ID
+	}
+
+	return ids, nil
+}
+
+// RemoveDuplicateMembers collapses the duplicate member entries of a user,
+// left by concurrent adds before AddMember was made conditional, into the
+// first entry. It returns the number of organizations that were fixed.
+func (r *OrganizationRepository) RemoveDuplicateMembers(ctx context.Context) (int, error) {
+	// Only organizations with more than one member entry can have duplicates
+	cursor, err := r.collection.Find(ctx, bson.M{"members.1": bson.M{"$exists": true}})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding organizations for member cleanup")
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []*models.Organization
+	if err := cursor.All(ctx, &docs); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding organizations for member cleanup")
+		return 0, err
+	}
+
+	fixed, changed := 0, 0
+	for _, doc := range docs {
+		seen := make(map[string]bool, len(doc.Members))
+		members := make([]models.OrganizationMember, 0, len(doc.Members))
+		for _, member := range doc.Members {
+			if !seen[member.UserID] {
+				seen[member.UserID] = true
+				members = append(members, member)
+			}
+		}
+		if len(members) == len(doc.Members) {
+			continue
+		}
+
+		objID, err := primitive.ObjectIDFromHex(doc.ID)
+		if err != nil {
+			return fixed, err
+		}
+
+		// Conditional on the organization not having changed since it was read
+		filter := bson.M{"_id": objID, "updatedAt": doc.UpdatedAt}
+		update := bson.M{
+			"$set": bson.M{
+				"members":   members,
+				"updatedAt": time.Now(),
+			},
+		}
+
+		result, err := r.collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", doc.ID).Msg("Error removing duplicate organization members")
+			return fixed, err
+		}
+		if result.MatchedCount == 0 {
+			changed++
+			continue
+		}
+
+		logger.Ctx(ctx).Info().Str("orgId", doc.ID).Int("removed", len(doc.Members)-len(members)).
+			Msg("Removed duplicate organization members")
+		fixed++
+	}
+
+	if changed > 0 {
+		return fixed, fmt.Errorf("%d organizations changed during member cleanup", changed)
+	}
+
+	return fixed, nil
+}
+
+// RemoveMember removes a member from an organization
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, orgID, userID string) error {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return err
+	}
+
+	err = r.changeMembers(ctx, objID,
+		func() error {
+			filter := bson.M{
+				"_id":            objID,
+				"members.userId": userID,
+				"memberStorage":  bson.M{"$ne": models.MemberStorageCollection},
+			}
+			update := bson.M{
+				"$pull": bson.M{
+					"members": bson.M{"userId": userID},
+				},
+				"$set": bson.M{
+					"updatedAt": time.Now(),
+				},
+			}
+
+			result, err := r.collection.UpdateOne(ctx, filter, update)
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return errMemberChangeConflict
+			}
+			return nil
+		},
+		func() error {
+			result, err := r.members.DeleteOne(ctx, bson.M{"_id": models.OrganizationMemberRecordID(orgID, userID)})
+			if err != nil {
+				return err
+			}
+			if result.DeletedCount == 0 {
+				return errMemberChangeConflict
+			}
+			return r.touchMemberCollection(ctx, objID)
+		},
+	)
+	if errors.Is(err, errMemberChangeConflict) {
+		return errors.New("member not found in organization")
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", userID).
+			Msg("Error removing organization member")
+		return err
+	}
+
+	if err := r.removeFromGroups(ctx, orgID, userID); err != nil {
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("orgId", orgID).Str("userId", userID).Msg("Organization member removed")
+	return nil
+}
+
+// ApplyMemberChanges writes the member changes of a bulk member request with
+// one write to the organization's member storage, conditional on the
+// organization not having changed since it was read. It returns
+// mongo.ErrNoDocuments if it did. Removed members leave their groups.
+func (r *OrganizationRepository) ApplyMemberChanges(ctx context.Context, org *models.Organization, changes models.OrganizationMemberChanges) error {
+	objID, err := primitive.ObjectIDFromHex(org.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if org.HasMemberCollection() {
+		err = r.applyMemberRecordChanges(ctx, objID, org, changes, now)
+	} else {
+		err = r.applyEmbeddedMemberChanges(ctx, objID, org, changes, now)
+	}
+	if errors.Is(err, errMemberChangeConflict) {
+		return mongo.ErrNoDocuments
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Error applying organization member changes")
+		return err
+	}
+	org.UpdatedAt = now
+
+	removed := make([]string, len(changes.Removed))
+	for i, member := range changes.Removed {
+		removed[i] = member.UserID
+	}
+	if err := r.removeManyFromGroups(ctx, org.ID, removed); err != nil {
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("orgId", org.ID).Int("added", len(changes.Added)).Int("updated", len(changes.Updated)).
+		Int("removed", len(changes.Removed)).Msg("Organization member changes applied")
+	return nil
+}
+
+// applyEmbeddedMemberChanges replaces the embedded members of an organization
+// with the changed list
+func (r *OrganizationRepository) applyEmbeddedMemberChanges(ctx context.Context, objID primitive.ObjectID, org *models.Organization, changes models.OrganizationMemberChanges, now time.Time) error {
+	roles := make(map[string]models.OrganizationMemberRole, len(changes.Updated))
+	for _, member := range changes.Updated {
+		roles[member.UserID] = member.Role
+	}
+	removed := make(map[string]bool, len(changes.Removed))
+	for _, member := range changes.Removed {
+		removed[member.UserID] = true
+	}
+
+	members := make([]models.OrganizationMember, 0, len(org.Members)+len(changes.Added))
+	for _, member := range org.Members {
+		if removed[member.UserID] {
+			continue
+		}
+		if role, ok := roles[member.UserID]; ok {
+			member.Role = role
+		}
+		members = append(members, member)
+	}
+	members = append(members, changes.Added...)
+
+	filter := bson.M{
+		"_id":           objID,
+		"updatedAt":     org.UpdatedAt,
+		"memberStorage": bson.M{"$ne": models.MemberStorageCollection},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"members":   members,
+			"updatedAt": now,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errMemberChangeConflict
+	}
+	return nil
+}
+
+// applyMemberRecordChanges writes the changed member records of an
+// organization in one bulk write, after recording the change on the
+// organization so a concurrent change or move of the members conflicts
+func (r *OrganizationRepository) applyMemberRecordChanges(ctx context.Context, objID primitive.ObjectID, org *models.Organization, changes models.OrganizationMemberChanges, now time.Time) error {
+	filter := bson.M{
+		"_id":           objID,
+		"updatedAt":     org.UpdatedAt,
+		"memberStorage": models.MemberStorageCollection,
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"updatedAt": now}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errMemberChangeConflict
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(changes.Added)+len(changes.Updated)+len(changes.Removed))
+	for _, member := range changes.Added {
+		record := models.NewOrganizationMemberRecord(org.ID, member)
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": record.ID}).
+			SetUpdate(bson.M{
+				"$set": bson.M{
+					"role": record.Role,
+				},
+				"$setOnInsert": bson.M{
+					"organizationId": record.OrganizationID,
+					"userId":         record.UserID,
+					"joinedAt":       record.JoinedAt,
+					"invitedBy":      record.InvitedBy,
+					"licensed":       record.Licensed,
+				},
+			}).
+			SetUpsert(true))
+	}
+	for _, member := range changes.Updated {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": models.OrganizationMemberRecordID(org.ID, member.UserID)}).
+			SetUpdate(bson.M{"$set": bson.M{"role": member.Role}}))
+	}
+	for _, member := range changes.Removed {
+		writes = append(writes, mongo.NewDeleteOneModel().
+			SetFilter(bson.M{"_id": models.OrganizationMemberRecordID(org.ID, member.UserID)}))
+	}
+
+	_, err = db.BulkWrite(ctx, r.members, writes)
+	return err
+}
+
+// orgGroups gets the groups of an organization, optionally only those a user
+// is a member of
+func (r *OrganizationRepository) orgGroups(ctx context.Context, orgID, userID string) ([]*models.Group, error) {
+	filter := bson.M{"organizationId": orgID}
+	if userID != "" {
+		filter["memberIds"] = userID
+	}
+
+	cursor, err := r.groups.Find(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error finding organization groups")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []*models.Group
+	if err := cursor.All(ctx, &groups); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error decoding organization groups")
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// loadGroupGrants loads the permissions the groups of an organization grant
+// to their members
+func (r *OrganizationRepository) loadGroupGrants(ctx context.Context, org *models.Organization) error {
+	cursor, err := db.ReadCollection(ctx, r.groups, "organizations.GetByID").Find(ctx, bson.M{
+		"organizationId": org.ID,
+		"permissions.0":  bson.M{"$exists": true},
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Error finding organization group grants")
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []*models.Group
+	if err := cursor.All(ctx, &groups); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Error decoding organization group grants")
+		return err
+	}
+
+	org.GroupGrants = make(map[string][]models.Permission)
+	for _, group := range groups {
+		for _, memberID := range group.MemberIDs {
+			org.GroupGrants[memberID] = append(org.GroupGrants[memberID], group.Permissions...)
+		}
+	}
+
+	return nil
+}
+
+// removeFromGroups removes a user from the groups of an organization
+func (r *OrganizationRepository) removeFromGroups(ctx context.Context, orgID, userID string) error {
+	groups, err := r.orgGroups(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		update := bson.M{
+			"$pull": bson.M{"memberIds": userID},
+			"$set":  bson.M{"updatedAt": time.Now()},
+		}
+		if _, err := r.groups.UpdateOne(ctx, bson.M{"_id": group.ID}, update); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("groupId", group.ID).Str("userId", userID).
+				Msg("Error removing user from organization group")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeManyFromGroups removes users from the groups of an organization with
+// one bulk write
+func (r *OrganizationRepository) removeManyFromGroups(ctx context.Context, orgID string, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	cursor, err := r.groups.Find(ctx, bson.M{"organizationId": orgID, "memberIds": bson.M{"$in": userIDs}})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error finding organization groups")
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []*models.Group
+	if err := cursor.All(ctx, &groups); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error decoding organization groups")
+		return err
+	}
+
+	writes := make([]mongo.WriteModel, len(groups))
+	for i, group := range groups {
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": group.ID}).
+			SetUpdate(bson.M{
+				"$pull": bson.M{"memberIds": bson.M{"$in": userIDs}},
+				"$set":  bson.M{"updatedAt": time.Now()},
+			})
+	}
+	if _, err := db.BulkWrite(ctx, r.groups, writes); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Strs("userIds", userIDs).
+			Msg("Error removing users from organization groups")
+		return err
+	}
+
+	return nil
+}
+
+// deleteGroups deletes the groups of an organization
+func (r *OrganizationRepository) deleteGroups(ctx context.Context, orgID string) error {
+	groups, err := r.orgGroups(ctx, orgID, "")
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		if _, err := r.groups.DeleteOne(ctx, bson.M{"_id": group.ID}); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("groupId", group.ID).Msg("Error deleting organization group")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddTeam adds a team to an organization
+func (r *OrganizationRepository) AddTeam(ctx context.Context, orgID, teamID string) error {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objID}
+	update := bson.M{
+		"$addToSet": bson.M{
+			"teamIds": teamID,
+		},
+		"$set": bson.M{
+			"updatedAt": time.Now(),
+		},
+	}
+
+	_, err = r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("teamId", teamID).
+			Msg("Error adding team to organization")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("orgId", orgID).Str("teamId", teamID).Msg("Team added to organization")
+	return nil
+}
+
+// RemoveTeam removes a team from an organization
+func (r *OrganizationRepository) RemoveTeam(ctx context.Context, orgID, teamID string) error {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objID}
+	update := bson.M{
+		"$pull": bson.M{
+			"teamIds": teamID,
+		},
+		"$set": bson.M{
+			"updatedAt": time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("teamId", teamID).
+			Msg("Error removing team from organization")
+		return err
+	}
+
+	if result.ModifiedCount == 0 {
+		return errors.New("team not found in organization")
+	}
+
+	logger.Ctx(ctx).Debug().Str("orgId", orgID).Str("teamId", teamID).Msg("Team removed from organization")
+	return nil
+}
+
+// AddTags adds tags to an organization
+func (r *OrganizationRepository) AddTags(ctx context.Context, orgID string, tags []string) error {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return err
+	}
+
+	if err := addTags(ctx, r.collection, bson.M{"_id": objID}, tags); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) && !errors.Is(err, ErrTooManyTags) {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error adding organization tags")
+		}
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("orgId", orgID).Strs("tags", tags).Msg("Organization tags added")
+	return nil
+}
+
+// RemoveTag removes a tag from an organization
+func (r *OrganizationRepository) RemoveTag(ctx context.Context, orgID, tag string) error {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return err
+	}
+
+	if err := removeTag(ctx, r.collection, bson.M{"_id": objID}, tag); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("tag", tag).Msg("Error removing organization tag")
+		}
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("orgId", orgID).Str("tag", tag).Msg("Organization tag removed")
+	return nil
+}
+
+// SetPendingTransfer sets or clears the pending ownership transfer of an organization
+func (r *OrganizationRepository) SetPendingTransfer(ctx context.Context, orgID string, transfer *models.OwnershipTransfer) error {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objID}
+	update := bson.M{
+		"$set": bson.M{
+			"pendingTransfer": transfer,
+			"updatedAt":       time.Now(),
+		},
+	}
+	if transfer == nil {
+		update = bson.M{
+			"$unset": bson.M{"pendingTransfer": ""},
+			"$set":   bson.M{"updatedAt": time.Now()},
+		}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error setting organization pending transfer")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// SetSearchKeys sets the keys directory searches match an organization
+// against
+func (r *OrganizationRepository) SetSearchKeys(ctx context.Context, orgID string, keys models.SearchKeys) error {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objID}
+	update := bson.M{
+		"$set": bson.M{
+			"search": keys,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error setting organization search keys")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SetVerified grants an organization its verified badge as of verifiedAt, or
+// takes it back if verifiedAt is nil
+func (r *OrganizationRepository) SetVerified(ctx context.Context, orgID string, verifiedAt *time.Time) error {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objID}
+	update := bson.M{
+		"$set": bson.M{
+			"verified":   true,
+			"verifiedAt": verifiedAt,
+			"updatedAt":  time.Now(),
+		},
+	}
+	if verifiedAt == nil {
+		update = bson.M{
+			"$unset": bson.M{"verified": "", "verifiedAt": ""},
+			"$set":   bson.M{"updatedAt": time.Now()},
+		}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error setting organization verified")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("orgId", orgID).Bool("verified", verifiedAt != nil).Msg("Organization verified badge set")
+	return nil
+}
+
+// SetSubscription sets the subscription of an organization unless it already
+// has a newer one. It reports whether the subscription was set.
+func (r *OrganizationRepository) SetSubscription(ctx context.Context, orgID string, subscription *models.Subscription) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return false, err
+	}
+
+	filter := bson.M{
+		"_id": objID,
+		"$or": []bson.M{
+			{"subscription.updatedAt": bson.M{"$exists": false}},
+			{"subscription.updatedAt": bson.M{"$lt": subscription.UpdatedAt}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"subscription": subscription,
+			"updatedAt":    time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error setting organization subscription")
+		return false, err
+	}
+
+	if result.MatchedCount == 0 {
+		// Tell a missing organization from a newer subscription
+		count, err := r.collection.CountDocuments(ctx, bson.M{"_id": objID})
+		if err != nil {
+			return false, err
+		}
+		if count == 0 {
+			return false, mongo.ErrNoDocuments
+		}
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// TransferOwnership completes a pending ownership transfer, promoting the new
+// owner and demoting or removing the previous owner. Embedded members are
+// changed in a single update; with the members collection the pending
+// transfer is claimed first, so concurrent accepts can't both complete it.
+func (r *OrganizationRepository) TransferOwnership(ctx context.Context, orgID string, transfer *models.OwnershipTransfer) error {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return err
+	}
+
+	err = r.changeMembers(ctx, objID,
+		func() error {
+			// Only match if the transfer is still pending and both members are still in place
+			filter := bson.M{
+				"_id":                        objID,
+				"pendingTransfer.fromUserId": transfer.FromUserID,
+				"pendingTransfer.toUserId":   transfer.ToUserID,
+				"members": bson.M{"$elemMatch": bson.M{
+					"userId": transfer.FromUserID,
+					"role":   models.OrgRoleOwner,
+				}},
+				"members.userId": transfer.ToUserID,
+				"memberStorage":  bson.M{"$ne": models.MemberStorageCollection},
+			}
+
+			result, err := r.collection.UpdateOne(ctx, filter, ownershipTransferPipeline(transfer, string(models.OrgRoleOwner)))
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return mongo.ErrNoDocuments
+			}
+			return nil
+		},
+		func() error {
+			return r.transferCollectionOwnership(ctx, objID, orgID, transfer)
+		},
+	)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("from", transfer.FromUserID).Str("to", transfer.ToUserID).
+				Msg("Error transferring organization ownership")
+		}
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("orgId", orgID).Str("from", transfer.FromUserID).Str("to", transfer.ToUserID).
+		Msg("Organization ownership transferred")
+	return nil
+}
+
+// transferCollectionOwnership completes a pending ownership transfer of an
+// organization that stores its members in the members collection
+func (r *OrganizationRepository) transferCollectionOwnership(ctx context.Context, objID primitive.ObjectID, orgID string, transfer *models.OwnershipTransfer) error {
+	fromID := models.OrganizationMemberRecordID(orgID, transfer.FromUserID)
+	toID := models.OrganizationMemberRecordID(orgID, transfer.ToUserID)
+
+	// Both members must still be in place
+	owners, err := r.members.CountDocuments(ctx, bson.M{"_id": fromID, "role": models.OrgRoleOwner})
+	if err != nil {
+		return err
+	}
+	recipients, err := r.members.CountDocuments(ctx, bson.M{"_id": toID})
+	if err != nil {
+		return err
+	}
+	if owners == 0 || recipients == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	// Claim the pending transfer
+	filter := bson.M{
+		"_id":                        objID,
+		"pendingTransfer.fromUserId": transfer.FromUserID,
+		"pendingTransfer.toUserId":   transfer.ToUserID,
+		"memberStorage":              models.MemberStorageCollection,
+	}
+	update := bson.M{
+		"$unset": bson.M{"pendingTransfer": ""},
+		"$set":   bson.M{"updatedAt": time.Now()},
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	if _, err := r.members.UpdateOne(ctx, bson.M{"_id": toID}, bson.M{"$set": bson.M{"role": models.OrgRoleOwner}}); err != nil {
+		return err
+	}
+	if transfer.RemovesPreviousOwner() {
+		_, err = r.members.DeleteOne(ctx, bson.M{"_id": fromID})
+	} else {
+		_, err = r.members.UpdateOne(ctx, bson.M{"_id": fromID}, bson.M{"$set": bson.M{"role": transfer.PreviousOwnerRole}})
+	}
+	return err
+}
+
+// ownershipTransferPipeline builds the update pipeline that swaps ownership between two members
+func ownershipTransferPipeline(transfer *models.OwnershipTransfer, ownerRole string) mongo.Pipeline {
+	members := bson.M{"$map": bson.M{
+		"input": "$members",
+		"as":    "m",
+		"in": bson.M{"$switch": bson.M{
+			"branches": bson.A{
+				bson.M{
+					"case": bson.M{"$eq": bson.A{"$$m.userId", transfer.ToUserID}},
+					"then": bson.M{"$mergeObjects": bson.A{"$$m", bson.M{"role": ownerRole}}},
+				},
+				bson.M{
+					"case": bson.M{"$eq": bson.A{"$$m.userId", transfer.FromUserID}},
+					"then": bson.M{"$mergeObjects": bson.A{"$$m", bson.M{"role": transfer.PreviousOwnerRole}}},
+				},
+			},
+			"default": "$$m",
+		}},
+	}}
+
+	if transfer.RemovesPreviousOwner() {
+		members = bson.M{"$filter": bson.M{
+			"input": members,
+			"as":    "m",
+			"cond":  bson.M{"$ne": bson.A{"$$m.userId", transfer.FromUserID}},
+		}}
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"members":   members,
+			"updatedAt": "$$NOW",
+		}}},
+		{{Key: "$unset", Value: "pendingTransfer"}},
+	}
 }
@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrExportRunning is returned when an organization already has an export running
+var ErrExportRunning = apperrors.Conflict("EXPORT_RUNNING", "an export of the organization is already running")
+
+// OrganizationExportRepository is a repository for organization exports and
+// their archives
+type OrganizationExportRepository struct {
+	exports db.Collection
+	files   db.Collection
+	chunks  db.Collection
+}
+
+// NewOrganizationExportRepository creates a new organization export repository
+func NewOrganizationExportRepository(store db.Storage) *OrganizationExportRepository {
+	return &OrganizationExportRepository{
+		exports: tenantCollection(store, db.OrgExportsCollection, "organizationId"),
+		files:   tenantCollection(store, db.OrgExportFilesCollection, "organizationId"),
+		chunks:  tenantCollection(store, db.OrgExportChunksCollection, "organizationId"),
+	}
+}
+
+// Create creates a new export
+func (r *OrganizationExportRepository) Create(ctx context.Context, export *models.OrganizationExport) error {
+	_, err := insertScoped(ctx, r.exports, export)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrExportRunning
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", export.OrganizationID).Msg("Error creating organization export")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", export.ID).Str("orgId", export.OrganizationID).Msg("Organization export created")
+	return nil
+}
+
+// Save updates the status of an export
+func (r *OrganizationExportRepository) Save(ctx context.Context, export *models.OrganizationExport) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":      export.Status,
+			"error":       export.Error,
+			"size":        export.Size,
+			"completedAt": export.CompletedAt,
+			"expiresAt":   export.ExpiresAt,
+		},
+	}
+
+	_, err := r.exports.UpdateOne(ctx, bson.M{"_id": export.ID, "organizationId": export.OrganizationID}, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", export.ID).Msg("Error saving organization export")
+		return err
+	}
+	return nil
+}
+
+// Get gets an export of an organization
+func (r *OrganizationExportRepository) Get(ctx context.Context, orgID, exportID string) (*models.OrganizationExport, error) {
+	var export models.OrganizationExport
+
+	err := r.exports.FindOne(ctx, bson.M{"_id": exportID, "organizationId": orgID}).Decode(&export)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("id", exportID).Msg("Error getting organization export")
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// List lists the exports of an organization, newest first
+func (r *OrganizationExportRepository) List(ctx context.Context, orgID string, page, limit int) ([]*models.OrganizationExport, int64, error) {
+	var exports []*models.OrganizationExport
+
+	filter := bson.M{"organizationId": orgID}
+
+	// Count total
+	total, err := r.exports.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error counting organization exports")
+		return nil, 0, err
+	}
+
+	// Set options for pagination and sorting, newest first
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.exports.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error finding organization exports")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &exports); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding organization exports")
+		return nil, 0, err
+	}
+
+	return exports, total, nil
+}
+
+// FailStale marks the running export of an organization as failed if it
+// started before staleBefore, so an export interrupted by a restart doesn't
+// block the organization's exports forever
+func (r *OrganizationExportRepository) FailStale(ctx context.Context, orgID string, staleBefore time.Time, reason string) error {
+	filter := bson.M{
+		"organizationId": orgID,
+		"status":         models.ExportRunning,
+		"createdAt":      bson.M{"$lt": staleBefore},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      models.ExportFailed,
+			"error":       reason,
+			"completedAt": time.Now(),
+		},
+	}
+
+	_, err := r.exports.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error failing stale organization export")
+		return err
+	}
+	return nil
+}
+
+// FindExpired finds completed exports whose archive expired
+func (r *OrganizationExportRepository) FindExpired(ctx context.Context, now time.Time, limit int64) ([]*models.OrganizationExport, error) {
+	var exports []*models.OrganizationExport
+
+	filter := bson.M{
+		"status":    models.ExportCompleted,
+		"expiresAt": bson.M{"$lte": now},
+	}
+	opts := options.Find().
+		SetSort(bson.M{"expiresAt": 1}).
+		SetLimit(limit)
+
+	cursor, err := r.exports.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding expired organization exports")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &exports); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding expired organization exports")
+		return nil, err
+	}
+
+	return exports, nil
+}
+
+// Expire removes the archive of an export and marks it as expired. Archives
+// the storage driver already removed are marked too.
+func (r *OrganizationExportRepository) Expire(ctx context.Context, export *models.OrganizationExport) error {
+	if err := r.DeleteFile(ctx, export.OrganizationID, export.ID); err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$set": bson.M{"status": models.ExportExpired},
+	}
+	_, err := r.exports.UpdateOne(ctx, bson.M{"_id": export.ID, "organizationId": export.OrganizationID}, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", export.ID).Msg("Error expiring organization export")
+		return err
+	}
+
+	export.Status = models.ExportExpired
+	return nil
+}
@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// exportChunkSize is the size of the chunks export archives are stored in,
+// that of GridFS chunks
+const exportChunkSize = 255 << 10
+
+// ExportFileWriter writes the archive of an export to its chunks as it's
+// written, so the archive is never held in memory. Close records the file
+// once the last chunk is written.
+type ExportFileWriter struct {
+	ctx  context.Context
+	repo *OrganizationExportRepository
+	file models.OrganizationExportFile
+	buf  []byte
+	err  error
+}
+
+// CreateFile starts writing the archive of an export, which expires at
+// expiresAt
+func (r *OrganizationExportRepository) CreateFile(ctx context.Context, orgID, exportID string, expiresAt time.Time) *ExportFileWriter {
+	return &ExportFileWriter{
+		ctx:  ctx,
+		repo: r,
+		file: models.OrganizationExportFile{
+			ExportID:       exportID,
+			OrganizationID: orgID,
+			ExpiresAt:      expiresAt,
+		},
+		buf: make([]byte, 0, exportChunkSize),
+	}
+}
+
+// Write writes to the archive, saving every chunk that fills up
+func (w *ExportFileWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if w.err != nil {
+			return written, w.err
+		}
+		n := copy(w.buf[len(w.buf):exportChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		w.file.Length += int64(n)
+		written += n
+		p = p[n:]
+
+		if len(w.buf) == exportChunkSize {
+			w.err = w.flush()
+		}
+	}
+	return written, w.err
+}
+
+// Length is the number of bytes written to the archive
+func (w *ExportFileWriter) Length() int64 {
+	return w.file.Length
+}
+
+// Close saves the last chunk and records the file
+func (w *ExportFileWriter) Close() error {
+	if w.err == nil && len(w.buf) > 0 {
+		w.err = w.flush()
+	}
+	if w.err != nil {
+		return w.err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"organizationId": w.file.OrganizationID,
+			"length":         w.file.Length,
+			"chunks":         w.file.Chunks,
+			"expiresAt":      w.file.ExpiresAt,
+		},
+	}
+	filter := bson.M{"_id": w.file.ExportID, "organizationId": w.file.OrganizationID}
+	if _, err := w.repo.files.UpdateOne(w.ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		logger.Ctx(w.ctx).Error().Err(err).Str("id", w.file.ExportID).Msg("Error saving organization export archive")
+		return err
+	}
+	return nil
+}
+
+// flush saves the buffered chunk of the archive
+func (w *ExportFileWriter) flush() error {
+	chunk := &models.OrganizationExportChunk{
+		ID:             exportChunkID(w.file.ExportID, w.file.Chunks),
+		ExportID:       w.file.ExportID,
+		OrganizationID: w.file.OrganizationID,
+		N:              w.file.Chunks,
+		Data:           w.buf,
+		ExpiresAt:      w.file.ExpiresAt,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"exportId":       chunk.ExportID,
+			"organizationId": chunk.OrganizationID,
+			"n":              chunk.N,
+			"data":           chunk.Data,
+			"expiresAt":      chunk.ExpiresAt,
+		},
+	}
+
+	filter := bson.M{"_id": chunk.ID, "organizationId": chunk.OrganizationID}
+	if _, err := w.repo.chunks.UpdateOne(w.ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		logger.Ctx(w.ctx).Error().Err(err).Str("id", chunk.ExportID).Int("chunk", chunk.N).Msg("Error saving organization export archive chunk")
+		return err
+	}
+
+	w.file.Chunks++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// ExportFileReader reads the archive of an export one chunk at a time
+type ExportFileReader struct {
+	ctx  context.Context
+	repo *OrganizationExportRepository
+	file *models.OrganizationExportFile
+	next int
+	buf  []byte
+}
+
+// OpenFile opens the archive of an export of an organization for reading
+func (r *OrganizationExportRepository) OpenFile(ctx context.Context, orgID, exportID string) (*ExportFileReader, error) {
+	var file models.OrganizationExportFile
+
+	err := r.files.FindOne(ctx, bson.M{"_id": exportID, "organizationId": orgID}).Decode(&file)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("id", exportID).Msg("Error getting organization export archive")
+		return nil, err
+	}
+
+	return &ExportFileReader{ctx: ctx, repo: r, file: &file}, nil
+}
+
+// Length is the size of the archive in bytes
+func (r *ExportFileReader) Length() int64 {
+	return r.file.Length
+}
+
+// Read reads from the archive, loading its chunks in order
+func (r *ExportFileReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.next >= r.file.Chunks {
+			return 0, io.EOF
+		}
+
+		var chunk models.OrganizationExportChunk
+		filter := bson.M{"_id": exportChunkID(r.file.ExportID, r.next), "organizationId": r.file.OrganizationID}
+		if err := r.repo.chunks.FindOne(r.ctx, filter).Decode(&chunk); err != nil {
+			logger.Ctx(r.ctx).Error().Err(err).Str("id", r.file.ExportID).Int("chunk", r.next).Msg("Error reading organization export archive chunk")
+			return 0, fmt.Errorf("reading chunk %d of export %s: %w", r.next, r.file.ExportID, err)
+		}
+		r.buf = chunk.Data
+		r.next++
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// DeleteFile removes the archive of an export with its chunks, including
+// those of an archive that was never completed
+func (r *OrganizationExportRepository) DeleteFile(ctx context.Context, orgID, exportID string) error {
+	if _, err := r.files.DeleteOne(ctx, bson.M{"_id": exportID, "organizationId": orgID}); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", exportID).Msg("Error deleting organization export archive")
+		return err
+	}
+
+	// Chunks are written in order, so the first missing one is the end
+	for n := 0; ; n++ {
+		result, err := r.chunks.DeleteOne(ctx, bson.M{"_id": exportChunkID(exportID, n), "organizationId": orgID})
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("id", exportID).Int("chunk", n).Msg("Error deleting organization export archive chunk")
+			return err
+		}
+		if result.DeletedCount == 0 {
+			return nil
+		}
+	}
+}
+
+// exportChunkID identifies the nth chunk of the archive of an export
+func exportChunkID(exportID string, n int) string {
+	return fmt.Sprintf("%s:%d", exportID, n)
+}
@@ -0,0 +1,69 @@
+package repositories_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"github.com/your-username/slido-clone/user-service/testsupport"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestExportFileStreamsChunks(t *testing.T) {
+	f := testsupport.New(t)
+	ctx := tenancy.Unscoped(context.Background())
+	exportRepo := repositories.NewOrganizationExportRepository(f.Store)
+
+	// Spans three chunks, the last one partly filled
+	archive := bytes.Repeat([]byte("0123456789abcdef"), 40000)
+	file := exportRepo.CreateFile(ctx, "org-1", "export-1", time.Now().Add(time.Hour))
+	for rest := archive; len(rest) > 0; {
+		n := min(len(rest), 100000)
+		if _, err := file.Write(rest[:n]); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		rest = rest[n:]
+	}
+	if _, err := exportRepo.OpenFile(ctx, "org-1", "export-1"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Fatalf("OpenFile before Close = %v, want no archive", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reader, err := exportRepo.OpenFile(ctx, "org-1", "export-1")
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	if reader.Length() != int64(len(archive)) {
+		t.Errorf("Length = %d, want %d", reader.Length(), len(archive))
+	}
+	read, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("reading archive: %v", err)
+	}
+	if !bytes.Equal(read, archive) {
+		t.Errorf("read %d bytes that differ from the %d written", len(read), len(archive))
+	}
+
+	chunks := f.Store.GetCollection(db.OrgExportChunksCollection)
+	if count, _ := chunks.CountDocuments(ctx, bson.M{"exportId": "export-1"}); count != 3 {
+		t.Errorf("stored %d chunks, want 3", count)
+	}
+	if err := exportRepo.DeleteFile(ctx, "org-1", "export-1"); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if count, _ := chunks.CountDocuments(ctx, bson.M{"exportId": "export-1"}); count != 0 {
+		t.Errorf("%d chunks left after deleting the archive, want none", count)
+	}
+	if _, err := exportRepo.OpenFile(ctx, "org-1", "export-1"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("OpenFile after DeleteFile = %v, want no archive", err)
+	}
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OrganizationHistoryRepository is a repository for the history of
+// organization states
+type OrganizationHistoryRepository struct {
+	collection db.Collection
+}
+
+// NewOrganizationHistoryRepository creates a new organization history repository
+func NewOrganizationHistoryRepository(store db.Storage) *OrganizationHistoryRepository {
+	return &OrganizationHistoryRepository{
+		collection: tenantCollection(store, db.OrgHistoryCollection, "organizationId"),
+	}
+}
+
+// Create appends an entry to the history of an organization. A version
+// number already recorded for the organization fails with a duplicate key
+// error.
+func (r *OrganizationHistoryRepository) Create(ctx context.Context, entry *models.OrganizationHistoryEntry) error {
+	_, err := insertScoped(ctx, r.collection, entry)
+	if err != nil {
+		if !mongo.IsDuplicateKeyError(err) {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", entry.OrganizationID).Int("version", entry.Version).
+				Msg("Error creating organization history entry")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// List lists the history entries of an organization before a version, or
+// from the latest one when before is 0, newest first. Snapshots are left out.
+func (r *OrganizationHistoryRepository) List(ctx context.Context, orgID string, before, limit int) ([]*models.OrganizationHistoryEntry, error) {
+	var entries []*models.OrganizationHistoryEntry
+
+	filter := bson.M{"organizationId": orgID}
+	if before > 0 {
+		filter["version"] = bson.M{"$lt": before}
+	}
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetProjection(bson.M{"snapshot": 0})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error finding organization history entries")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &entries); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding organization history entries")
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetStateEntries gets the entries the state of an organization at a time is
+// rebuilt from, oldest first: the latest entry with a snapshot recorded by
+// then and every later entry recorded by then. A zero time gets the entries
+// of the current state. No entries are returned before the first one.
+func (r *OrganizationHistoryRepository) GetStateEntries(ctx context.Context, orgID string, at time.Time) ([]*models.OrganizationHistoryEntry, error) {
+	filter := bson.M{"organizationId": orgID}
+	if !at.IsZero() {
+		filter["occurredAt"] = bson.M{"$lte": at}
+	}
+
+	// Find the latest snapshot
+	var snapshot models.OrganizationHistoryEntry
+	snapshotFilter := bson.M{"snapshot": bson.M{"$exists": true}}
+	for key, value := range filter {
+		snapshotFilter[key] = value
+	}
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetProjection(bson.M{"version": 1})
+	err := r.collection.FindOne(ctx, snapshotFilter, opts).Decode(&snapshot)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error finding organization history snapshot")
+		return nil, err
+	}
+
+	// Get it with the entries after it
+	var entries []*models.OrganizationHistoryEntry
+	filter["version"] = bson.M{"$gte": snapshot.Version}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "version", Value: 1}}))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error finding organization history entries")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &entries); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding organization history entries")
+		return nil, err
+	}
+
+	return entries, nil
+}
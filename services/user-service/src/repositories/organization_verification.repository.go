@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrVerificationPending is returned when an organization already has a pending verification request
+var ErrVerificationPending = apperrors.Conflict("VERIFICATION_PENDING", "organization already has a pending verification request")
+
+// VerificationRepository is a repository for organization verification requests
+type VerificationRepository struct {
+	collection db.Collection
+}
+
+// NewVerificationRepository creates a new verification repository
+func NewVerificationRepository(store db.Storage) *VerificationRepository {
+	return &VerificationRepository{
+		collection: store.GetCollection(db.VerificationsCollection),
+	}
+}
+
+// Create creates a new verification request
+func (r *VerificationRepository) Create(ctx context.Context, verification *models.OrganizationVerification) error {
+	_, err := r.collection.InsertOne(ctx, verification)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrVerificationPending
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", verification.OrganizationID).Msg("Error creating verification request")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", verification.ID).Str("orgId", verification.OrganizationID).Msg("Verification request created")
+	return nil
+}
+
+// GetByID gets a verification request by ID
+func (r *VerificationRepository) GetByID(ctx context.Context, id string) (*models.OrganizationVerification, error) {
+	var verification models.OrganizationVerification
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&verification)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error getting verification request by ID")
+		return nil, err
+	}
+
+	return &verification, nil
+}
+
+// GetLatest gets the newest verification request of an organization
+func (r *VerificationRepository) GetLatest(ctx context.Context, orgID string) (*models.OrganizationVerification, error) {
+	var verification models.OrganizationVerification
+
+	opts := options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	err := r.collection.FindOne(ctx, bson.M{"organizationId": orgID}, opts).Decode(&verification)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error getting latest verification request")
+		return nil, err
+	}
+
+	return &verification, nil
+}
+
+// GetApproved gets the approved verification request of an organization,
+// the one its verified badge was granted by
+func (r *VerificationRepository) GetApproved(ctx context.Context, orgID string) (*models.OrganizationVerification, error) {
+	var verification models.OrganizationVerification
+
+	filter := bson.M{"organizationId": orgID, "status": models.VerificationApproved}
+	opts := options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	err := r.collection.FindOne(ctx, filter, opts).Decode(&verification)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error getting approved verification request")
+		return nil, err
+	}
+
+	return &verification, nil
+}
+
+// ListByStatus lists the verification requests with the given status, oldest first
+func (r *VerificationRepository) ListByStatus(ctx context.Context, status models.VerificationStatus, page, limit int) ([]*models.OrganizationVerification, int64, error) {
+	var verifications []*models.OrganizationVerification
+
+	filter := bson.M{"status": status}
+
+	// Count total
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("status", string(status)).Msg("Error counting verification requests")
+		return nil, 0, err
+	}
+
+	// Set options for pagination and sorting, oldest first
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "createdAt", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("status", string(status)).Msg("Error finding verification requests")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &verifications); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding verification requests")
+		return nil, 0, err
+	}
+
+	return verifications, total, nil
+}
+
+// SetDomainVerified records when the TXT record of a pending domain request
+// was found. It returns mongo.ErrNoDocuments if the request is no longer pending.
+func (r *VerificationRepository) SetDomainVerified(ctx context.Context, id string, verifiedAt time.Time) error {
+	filter := bson.M{
+		"_id":    id,
+		"status": models.VerificationPending,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"domainVerifiedAt": verifiedAt,
+			"updatedAt":        time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error setting verification request domain verified")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// Resolve moves a verification request from a status to its current one. It
+// returns mongo.ErrNoDocuments if the request no longer has the from status.
+func (r *VerificationRepository) Resolve(ctx context.Context, verification *models.OrganizationVerification, from models.VerificationStatus) error {
+	filter := bson.M{
+		"_id":    verification.ID,
+		"status": from,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       verification.Status,
+			"reviewedBy":   verification.ReviewedBy,
+			"reviewReason": verification.ReviewReason,
+			"reviewedAt":   verification.ReviewedAt,
+			"updatedAt":    time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", verification.ID).Msg("Error resolving verification request")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", verification.ID).Str("status", string(verification.Status)).Msg("Verification request resolved")
+	return nil
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ProcessedEventRepository is a repository for the IDs of handled Kafka events
+type ProcessedEventRepository struct {
+	collection db.Collection
+	ttl        time.Duration
+}
+
+// NewProcessedEventRepository creates a new processed event repository. Event
+// IDs are remembered for ttl, which must exceed the longest redelivery delay.
+func NewProcessedEventRepository(store db.Storage, ttl time.Duration) *ProcessedEventRepository {
+	return &ProcessedEventRepository{
+		collection: store.GetCollection(db.ProcessedEventsCollection),
+		ttl:        ttl,
+	}
+}
+
+// IsProcessed checks if an event has already been handled
+func (r *ProcessedEventRepository) IsProcessed(ctx context.Context, eventID string) (bool, error) {
+	// Expired records may linger until the TTL monitor removes them
+	filter := bson.M{
+		"_id":       eventID,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}
+
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("eventId", eventID).Msg("Error checking processed event")
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// MarkProcessed records that an event has been handled
+func (r *ProcessedEventRepository) MarkProcessed(ctx context.Context, eventID, topic, eventType string) error {
+	now := time.Now()
+	filter := bson.M{"_id": eventID}
+	update := bson.M{
+		"$set": bson.M{
+			"topic":       topic,
+			"type":        eventType,
+			"processedAt": now,
+			"expiresAt":   now.Add(r.ttl),
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("eventId", eventID).Msg("Error marking event processed")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"github.com/your-username/slido-clone/user-service/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// fieldProjection gets the projection reading only the stored fields a fieldset
+// needs, along with the required ones, or nil to read whole documents
+func fieldProjection(fields models.FieldSet, responseFields map[string][]string, required ...string) bson.M {
+	stored := fields.StoredFields(responseFields, required...)
+	if stored == nil {
+		return nil
+	}
+
+	projection := bson.M{}
+	for _, field := range stored {
+		projection[field] = 1
+	}
+	return projection
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SCIMTokenRepository is a repository for SCIM tokens
+type SCIMTokenRepository struct {
+	collection db.Collection
+}
+
+// NewSCIMTokenRepository creates a new SCIM token repository
+func NewSCIMTokenRepository(store db.Storage) *SCIMTokenRepository {
+	return &SCIMTokenRepository{
+		collection: store.GetCollection(db.SCIMTokensCollection),
+	}
+}
+
+// Create creates a new SCIM token
+func (r *SCIMTokenRepository) Create(ctx context.Context, token *models.SCIMToken) error {
+	_, err := r.collection.InsertOne(ctx, token)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", token.OrganizationID).Msg("Error creating SCIM token")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", token.ID).Str("orgId", token.OrganizationID).Msg("SCIM token created")
+	return nil
+}
+
+// GetByHash gets a SCIM token by its hash
+func (r *SCIMTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.SCIMToken, error) {
+	var token models.SCIMToken
+
+	filter := bson.M{"tokenHash": tokenHash}
+	err := r.collection.FindOne(ctx, filter).Decode(&token)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Msg("Error getting SCIM token by hash")
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// GetByOrganization gets all SCIM tokens for an organization
+func (r *SCIMTokenRepository) GetByOrganization(ctx context.Context, orgID string) ([]*models.SCIMToken, error) {
+	var tokens []*models.SCIMToken
+
+	filter := bson.M{"organizationId": orgID}
+	opts := options.Find().SetSort(bson.M{"createdAt": -1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error finding SCIM tokens")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &tokens); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding SCIM tokens")
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// TouchLastUsed updates the last used time of a SCIM token
+func (r *SCIMTokenRepository) TouchLastUsed(ctx context.Context, id string, lastUsed time.Time) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"lastUsedAt": lastUsed,
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error updating SCIM token last used time")
+		return err
+	}
+
+	return nil
+}
+
+// Delete deletes a SCIM token belonging to an organization
+func (r *SCIMTokenRepository) Delete(ctx context.Context, orgID, id string) error {
+	filter := bson.M{"_id": id, "organizationId": orgID}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("orgId", orgID).Msg("Error deleting SCIM token")
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", id).Str("orgId", orgID).Msg("SCIM token deleted")
+	return nil
+}
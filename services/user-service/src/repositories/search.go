@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"regexp"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// filterSearch adds a search for term to filter: each word of the term must
+// start a search key of one of fields, so "jan doe" finds Jane Doe. Search
+// keys are lowercased, so the anchored regexes ignore case without an "i"
+// option and can be answered from the indexes on the keys.
+func filterSearch(filter bson.M, term string, fields ...string) bson.M {
+	words := models.SearchTerms(term)
+	if len(words) == 0 {
+		return filter
+	}
+
+	conditions, _ := filter["$and"].(bson.A)
+	for _, word := range words {
+		prefix := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(word)}
+		matches := make(bson.A, 0, len(fields))
+		for _, field := range fields {
+			matches = append(matches, bson.M{"search." + field: bson.M{"$regex": prefix}})
+		}
+		conditions = append(conditions, bson.M{"$or": matches})
+	}
+	filter["$and"] = conditions
+	return filter
+}
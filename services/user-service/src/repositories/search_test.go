@@ -0,0 +1,145 @@
+package repositories_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+	"github.com/your-username/slido-clone/user-service/testsupport"
+)
+
+// searchUsers lists the user IDs of the users matching a search
+func searchUsers(t *testing.T, f *testsupport.Fixtures, search string) []string {
+	t.Helper()
+
+	users, _, err := f.Users.GetUsers(context.Background(), 1, 100, models.UserListFilter{Search: search})
+	if err != nil {
+		t.Fatalf("GetUsers(%q): %v", search, err)
+	}
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.UserID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestUserSearchMatchesWordPrefixes(t *testing.T) {
+	f := testsupport.New(t)
+	jane := f.SeedUser(func(u *models.User) {
+		u.FirstName, u.LastName, u.Email = "Jane", "Doe", "jane.doe@example.com"
+	})
+	john := f.SeedUser(func(u *models.User) {
+		u.FirstName, u.LastName, u.Email = "John", "Smith-Jones", "jsmith@acme.io"
+	})
+
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		{search: "ja", want: []string{jane.UserID}},
+		{search: "DOE", want: []string{jane.UserID}},
+		{search: "jan do", want: []string{jane.UserID}},
+		{search: "jane.doe@ex", want: []string{jane.UserID}},
+		{search: "example.com", want: []string{jane.UserID}},
+		{search: "jones", want: []string{john.UserID}},
+		{search: "acme.io", want: []string{john.UserID}},
+		{search: "j", want: []string{jane.UserID, john.UserID}},
+		{search: "ohn", want: []string{}},
+		{search: "jane smith", want: []string{}},
+		{search: "(", want: []string{}},
+	}
+	for _, tt := range tests {
+		if got := searchUsers(t, f, tt.search); !equalIDs(got, tt.want) {
+			t.Errorf("search %q = %v, want %v", tt.search, got, tt.want)
+		}
+	}
+}
+
+func TestUserSearchFollowsUpdates(t *testing.T) {
+	f := testsupport.New(t)
+	ctx := tenancy.Unscoped(context.Background())
+	user := f.SeedUser(func(u *models.User) {
+		u.FirstName, u.LastName, u.Email = "Jane", "Doe", "jane@example.com"
+	})
+
+	user.LastName = "Roe"
+	if err := f.Users.Update(ctx, user); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got := searchUsers(t, f, "doe"); len(got) != 0 {
+		t.Errorf("search for the old name = %v, want none", got)
+	}
+	if got := searchUsers(t, f, "roe"); !equalIDs(got, []string{user.UserID}) {
+		t.Errorf("search for the new name = %v, want %s", got, user.UserID)
+	}
+
+	if err := f.Users.SetPendingEmail(ctx, user.UserID, "jroe@newmail.org", time.Now()); err != nil {
+		t.Fatalf("SetPendingEmail: %v", err)
+	}
+	if err := f.Users.ConfirmEmailChange(ctx, user.UserID, "jroe@newmail.org"); err != nil {
+		t.Fatalf("ConfirmEmailChange: %v", err)
+	}
+	if got := searchUsers(t, f, "newmail"); !equalIDs(got, []string{user.UserID}) {
+		t.Errorf("search for the new email = %v, want %s", got, user.UserID)
+	}
+	if got := searchUsers(t, f, "jane jroe"); !equalIDs(got, []string{user.UserID}) {
+		t.Errorf("search across the name and email = %v, want %s", got, user.UserID)
+	}
+}
+
+func TestDirectorySearchMatchesWordPrefixes(t *testing.T) {
+	f := testsupport.New(t)
+	owner := f.SeedUser()
+	discoverable := func(name, industry string) func(*models.Organization) {
+		return func(o *models.Organization) {
+			o.Name, o.Industry = name, industry
+			o.Description = "Meetups for the " + industry + " community"
+			o.Settings.Features.Discoverable = true
+		}
+	}
+	acme := f.SeedOrganization(owner, discoverable("Acme Events", "Media"))
+	globex := f.SeedOrganization(owner, discoverable("Globex", "Energy"))
+
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		{search: "even", want: []string{acme.ID}},
+		{search: "ENERGY", want: []string{globex.ID}},
+		{search: "meetups comm", want: []string{acme.ID, globex.ID}},
+		{search: "vents", want: []string{}},
+	}
+	for _, tt := range tests {
+		orgs, _, err := f.Orgs.ListDiscoverable(tenancy.Unscoped(context.Background()), models.DirectoryFilter{Search: tt.search}, 1, 100)
+		if err != nil {
+			t.Fatalf("ListDiscoverable(%q): %v", tt.search, err)
+		}
+		got := make([]string, 0, len(orgs))
+		for _, org := range orgs {
+			got = append(got, org.ID)
+		}
+		sort.Strings(got)
+		want := append([]string(nil), tt.want...)
+		sort.Strings(want)
+		if !equalIDs(got, want) {
+			t.Errorf("search %q = %v, want %v", tt.search, got, want)
+		}
+	}
+}
+
+// equalIDs checks if two sorted lists of IDs are equal
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SessionRepository is a repository for user sessions
+type SessionRepository struct {
+	collection db.Collection
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(store db.Storage) *SessionRepository {
+	return &SessionRepository{
+		collection: store.GetCollection(db.SessionsCollection),
+	}
+}
+
+// Upsert creates a session, or updates it when its event is redelivered
+func (r *SessionRepository) Upsert(ctx context.Context, session *models.Session) error {
+	filter := bson.M{"_id": session.ID}
+	set := bson.M{
+		"userId":       session.UserID,
+		"device":       session.Device,
+		"userAgent":    session.UserAgent,
+		"ipAddress":    session.IPAddress,
+		"lastActiveAt": session.LastActiveAt,
+	}
+	if session.ExpiresAt != nil {
+		set["expiresAt"] = session.ExpiresAt
+	}
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": bson.M{"createdAt": session.CreatedAt},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("sessionId", session.ID).Msg("Error upserting session")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("sessionId", session.ID).Str("userId", session.UserID).Msg("Session upserted")
+	return nil
+}
+
+// Touch records activity on a session of a user. An empty IP address or nil
+// expiry leaves the stored one unchanged.
+func (r *SessionRepository) Touch(ctx context.Context, userID, id string, activeAt time.Time, ipAddress string, expiresAt *time.Time) error {
+	filter := bson.M{"_id": id, "userId": userID}
+	set := bson.M{"lastActiveAt": activeAt}
+	if ipAddress != "" {
+		set["ipAddress"] = ipAddress
+	}
+	if expiresAt != nil {
+		set["expiresAt"] = expiresAt
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("sessionId", id).Msg("Error touching session")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// GetByID gets a session of a user by ID
+func (r *SessionRepository) GetByID(ctx context.Context, userID, id string) (*models.Session, error) {
+	var session models.Session
+
+	filter := bson.M{"_id": id, "userId": userID}
+	err := r.collection.FindOne(ctx, filter).Decode(&session)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("sessionId", id).Msg("Error getting session by ID")
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// GetActiveByUser gets the unexpired sessions of a user, most recently active
+// first. Expired sessions are also removed by a TTL index, which runs
+// periodically.
+func (r *SessionRepository) GetActiveByUser(ctx context.Context, userID string, now time.Time, limit int) ([]*models.Session, error) {
+	var sessions []*models.Session
+
+	filter := bson.M{
+		"userId": userID,
+		"$or": []bson.M{
+			{"expiresAt": bson.M{"$exists": false}},
+			{"expiresAt": bson.M{"$gt": now}},
+		},
+	}
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "lastActiveAt", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error finding sessions")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &sessions); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding sessions")
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Delete deletes a session of a user
+func (r *SessionRepository) Delete(ctx context.Context, userID, id string) error {
+	filter := bson.M{"_id": id, "userId": userID}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("sessionId", id).Msg("Error deleting session")
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("sessionId", id).Str("userId", userID).Msg("Session deleted")
+	return nil
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SettingsHistoryRepository is a repository for the versions of organization
+// settings
+type SettingsHistoryRepository struct {
+	collection db.Collection
+}
+
+// NewSettingsHistoryRepository creates a new settings history repository
+func NewSettingsHistoryRepository(store db.Storage) *SettingsHistoryRepository {
+	return &SettingsHistoryRepository{
+		collection: tenantCollection(store, db.SettingsHistoryCollection, "organizationId"),
+	}
+}
+
+// Create records a settings version. A version number already recorded for
+// the organization fails with a duplicate key error.
+func (r *SettingsHistoryRepository) Create(ctx context.Context, version *models.SettingsVersion) error {
+	_, err := insertScoped(ctx, r.collection, version)
+	if err != nil {
+		if !mongo.IsDuplicateKeyError(err) {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", version.OrganizationID).Int("version", version.Version).
+				Msg("Error creating settings version")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetVersion gets a settings version of an organization
+func (r *SettingsHistoryRepository) GetVersion(ctx context.Context, orgID string, version int) (*models.SettingsVersion, error) {
+	var settingsVersion models.SettingsVersion
+
+	filter := bson.M{"organizationId": orgID, "version": version}
+	err := r.collection.FindOne(ctx, filter).Decode(&settingsVersion)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Int("version", version).Msg("Error getting settings version")
+		return nil, err
+	}
+
+	return &settingsVersion, nil
+}
+
+// GetLatestVersion gets the number of the latest settings version of an
+// organization, or 0 if none was recorded
+func (r *SettingsHistoryRepository) GetLatestVersion(ctx context.Context, orgID string) (int, error) {
+	var latest models.SettingsVersion
+
+	opts := options.FindOne().
+		SetSort(bson.M{"version": -1}).
+		SetProjection(bson.M{"version": 1})
+	err := r.collection.FindOne(ctx, bson.M{"organizationId": orgID}, opts).Decode(&latest)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, nil
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error getting latest settings version")
+		return 0, err
+	}
+
+	return latest.Version, nil
+}
+
+// ListByOrganization lists the settings versions of an organization, newest
+// first
+func (r *SettingsHistoryRepository) ListByOrganization(ctx context.Context, orgID string, page, limit int) ([]*models.SettingsVersion, int64, error) {
+	var versions []*models.SettingsVersion
+
+	filter := bson.M{"organizationId": orgID}
+
+	// Count total
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error counting settings versions")
+		return nil, 0, err
+	}
+
+	// Set options for pagination and sorting, newest first
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"version": -1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error finding settings versions")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &versions); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding settings versions")
+		return nil, 0, err
+	}
+
+	return versions, total, nil
+}
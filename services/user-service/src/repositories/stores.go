@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// The services depend on these stores rather than on the repositories, so
+// they can be exercised with the mocks of the mocks package instead of a
+// database. The repositories, backed by the configured storage driver, are
+// the implementation used by the service.
+var (
+	_ UserStore = (*UserRepository)(nil)
+	_ TeamStore = (*TeamRepository)(nil)
+	_ OrgStore  = (*OrganizationRepository)(nil)
+)
+
+// UserStore stores users
+type UserStore interface {
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	GetByUserId(ctx context.Context, userId string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUsers(ctx context.Context, page, limit int, params models.UserListFilter) ([]*models.User, int64, error)
+	FindUsers(ctx context.Context, filter bson.M, skip, limit int64) ([]*models.User, int64, error)
+	FindBatch(ctx context.Context, filter bson.M, limit int64) ([]*models.User, error)
+	FindChangedSince(ctx context.Context, filter bson.M, since time.Time, limit int64) ([]*models.User, error)
+	Update(ctx context.Context, user *models.User) error
+	UpdateIfUnmodified(ctx context.Context, user *models.User, updatedAt time.Time) error
+	UpdateIdentity(ctx context.Context, user *models.User) error
+	UpdateLastLogin(ctx context.Context, userId string, lastLogin time.Time) error
+	UpdateLastSeen(ctx context.Context, userId string, seenAt time.Time, minInterval time.Duration) error
+	CountSignupsFromIP(ctx context.Context, ip string, since time.Time) (int64, error)
+	CountOrganizationMembers(ctx context.Context, orgID string, activeSince time.Time) (total, active int64, err error)
+	CountByField(ctx context.Context, field string) ([]models.StatsCount, error)
+	CountSignups(ctx context.Context, interval models.StatsInterval, from, to time.Time) ([]models.StatsBucketCount, error)
+	ResolveSignupReview(ctx context.Context, userId string, status models.UserStatus, review *models.SignupReview) error
+	AddOrganizationToUser(ctx context.Context, userId, organizationId string) error
+	RemoveOrganizationFromUser(ctx context.Context, userId, organizationId string) error
+	UpdateOrganizationMemberships(ctx context.Context, organizationId string, added, removed []string) error
+	SetFavorites(ctx context.Context, userId string, favorites []models.Favorite) error
+	SetCustomFields(ctx context.Context, userId, organizationId string, values map[string]interface{}) error
+	SetPendingEmail(ctx context.Context, userId, email string, requestedAt time.Time) error
+	ClearPendingEmail(ctx context.Context, userId string) error
+	ConfirmEmailChange(ctx context.Context, userId, email string) error
+	AddTeamToUser(ctx context.Context, userId, teamId string) error
+	RemoveTeamFromUser(ctx context.Context, userId, teamId string) error
+	UpdateTeamMemberships(ctx context.Context, teamId string, added, removed []string) error
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	Purge(ctx context.Context, id string) error
+}
+
+// TeamStore stores teams
+type TeamStore interface {
+	Create(ctx context.Context, team *models.Team) error
+	GetByID(ctx context.Context, id string) (*models.Team, error)
+	GetByNameAndOrganization(ctx context.Context, name, organizationID string) (*models.Team, error)
+	GetTeamsByOrganization(ctx context.Context, organizationID string, tags []string, page, limit int, fields models.FieldSet, hiddenFrom string, includeArchived bool) ([]*models.Team, int64, error)
+	GetTeamsByUser(ctx context.Context, userID string, tags []string, page, limit int, fields models.FieldSet, includeArchived bool) ([]*models.Team, int64, error)
+	GetChildren(ctx context.Context, parentID string, page, limit int, hiddenFrom string, includeArchived bool) ([]*models.Team, int64, error)
+	GetChildIDs(ctx context.Context, parentIDs ...string) ([]string, error)
+	GetAncestors(ctx context.Context, team *models.Team) ([]*models.Team, error)
+	SetParent(ctx context.Context, teamID, parentID string) error
+	AddTags(ctx context.Context, teamID string, tags []string) error
+	RemoveTag(ctx context.Context, teamID, tag string) error
+	CountOrganizationTeams(ctx context.Context, orgID string) (teams, memberships int64, err error)
+	FindTeams(ctx context.Context, filter bson.M, skip, limit int64) ([]*models.Team, int64, error)
+	FindBatch(ctx context.Context, filter bson.M, limit int64) ([]*models.Team, error)
+	FindChangedSince(ctx context.Context, filter bson.M, since time.Time, limit int64) ([]*models.Team, error)
+	Update(ctx context.Context, team *models.Team) error
+	UpdateSettings(ctx context.Context, teamID string, settings models.TeamSettings) error
+	SetArchived(ctx context.Context, teamID string, archivedAt *time.Time, archivedBy string) error
+	Delete(ctx context.Context, id string) error
+	AddMember(ctx context.Context, teamID, userID string, role models.TeamMemberRole, invitedBy string) error
+	UpdateMemberRole(ctx context.Context, teamID, userID string, role models.TeamMemberRole) error
+	RemoveDuplicateMembers(ctx context.Context) (int, error)
+	RemoveMember(ctx context.Context, teamID, userID string) error
+	ReplaceMembers(ctx context.Context, team *models.Team, members []models.TeamMember) error
+	SetPendingTransfer(ctx context.Context, teamID string, transfer *models.OwnershipTransfer) error
+	TransferOwnership(ctx context.Context, teamID string, transfer *models.OwnershipTransfer) error
+}
+
+// OrgStore stores organizations
+type OrgStore interface {
+	Create(ctx context.Context, org *models.Organization) error
+	GetByID(ctx context.Context, id string) (*models.Organization, error)
+	GetByName(ctx context.Context, name string) (*models.Organization, error)
+	GetOrganizationsByUser(ctx context.Context, userID string, tags []string, page, limit int, fields models.FieldSet) ([]*models.Organization, int64, error)
+	GetOrganizationIDsByUser(ctx context.Context, userID string) ([]string, error)
+	FindChangedForUser(ctx context.Context, userID string, since time.Time, limit int64) ([]*models.Organization, error)
+	ListOrganizations(ctx context.Context, tags []string, page, limit int, fields models.FieldSet) ([]*models.Organization, int64, error)
+	ListDiscoverable(ctx context.Context, directoryFilter models.DirectoryFilter, page, limit int) ([]*models.Organization, int64, error)
+	FindBatch(ctx context.Context, filter bson.M, limit int64) ([]*models.Organization, error)
+	GetMembers(ctx context.Context, org *models.Organization, filter models.OrganizationMemberFilter, page, limit int) ([]models.OrganizationMemberDetail, int64, error)
+	Update(ctx context.Context, org *models.Organization) error
+	UpdateIfUnmodified(ctx context.Context, org *models.Organization, updatedAt time.Time) error
+	Delete(ctx context.Context, id string) error
+	AddMember(ctx context.Context, orgID, userID string, role models.OrganizationMemberRole, invitedBy string) error
+	UpdateMemberRole(ctx context.Context, orgID, userID string, role models.OrganizationMemberRole) error
+	SetMemberLicensed(ctx context.Context, orgID, userID string, licensed bool) error
+	SetMemberExpiry(ctx context.Context, orgID, userID string, expiresAt *time.Time) error
+	SetMemberProvisioning(ctx context.Context, orgID, userID, externalID string, suspended bool) error
+	FindExpiredMembers(ctx context.Context, now time.Time, limit int64) ([]*models.OrganizationMemberRecord, error)
+	SetMembersReviewed(ctx context.Context, orgID string, userIDs []string, reviewedAt time.Time, reviewedBy string) error
+	MoveMembersToCollection(ctx context.Context, orgID string) error
+	MoveMembersToDocument(ctx context.Context, orgID string) error
+	CountBySize(ctx context.Context, buckets []models.OrganizationSizeBucket) ([]models.StatsCount, error)
+	FindOversizedOrganizations(ctx context.Context, threshold int) ([]string, error)
+	RemoveDuplicateMembers(ctx context.Context) (int, error)
+	RemoveMember(ctx context.Context, orgID, userID string) error
+	ApplyMemberChanges(ctx context.Context, org *models.Organization, changes models.OrganizationMemberChanges) error
+	AddTeam(ctx context.Context, orgID, teamID string) error
+	RemoveTeam(ctx context.Context, orgID, teamID string) error
+	AddTags(ctx context.Context, orgID string, tags []string) error
+	RemoveTag(ctx context.Context, orgID, tag string) error
+	SetPendingTransfer(ctx context.Context, orgID string, transfer *models.OwnershipTransfer) error
+	SetVerified(ctx context.Context, orgID string, verifiedAt *time.Time) error
+	SetSubscription(ctx context.Context, orgID string, subscription *models.Subscription) (bool, error)
+	TransferOwnership(ctx context.Context, orgID string, transfer *models.OwnershipTransfer) error
+}
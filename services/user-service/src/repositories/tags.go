@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrTooManyTags is returned when adding tags would take an organization or
+// team over models.MaxTags
+var ErrTooManyTags = apperrors.Conflict("TOO_MANY_TAGS", fmt.Sprintf("cannot have more than %d tags", models.MaxTags))
+
+// filterTags restricts a filter to documents with all of the tags
+func filterTags(filter bson.M, tags []string) bson.M {
+	if len(tags) > 0 {
+		filter["tags"] = bson.M{"$all": tags}
+	}
+	return filter
+}
+
+// addTags adds tags to the document matching a filter. The update only
+// applies while the document has room for all of them, so concurrent adds
+// can't take it over the limit. Tags the document already has count against
+// the room, so callers should leave them out.
+func addTags(ctx context.Context, collection db.Collection, filter bson.M, tags []string) error {
+	if len(tags) > models.MaxTags {
+		return ErrTooManyTags
+	}
+
+	guarded := bson.M{fmt.Sprintf("tags.%d", models.MaxTags-len(tags)): bson.M{"$exists": false}}
+	for key, value := range filter {
+		guarded[key] = value
+	}
+	update := bson.M{
+		"$addToSet": bson.M{"tags": bson.M{"$each": tags}},
+		"$set":      bson.M{"updatedAt": time.Now()},
+	}
+
+	result, err := collection.UpdateOne(ctx, guarded, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		// Tell a missing document from a full one
+		count, err := collection.CountDocuments(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return mongo.ErrNoDocuments
+		}
+		return ErrTooManyTags
+	}
+
+	return nil
+}
+
+// removeTag removes a tag from the document matching a filter, returning
+// mongo.ErrNoDocuments when no matching document has the tag
+func removeTag(ctx context.Context, collection db.Collection, filter bson.M, tag string) error {
+	filter["tags"] = tag
+	update := bson.M{
+		"$pull": bson.M{"tags": tag},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -3,11 +3,12 @@ package repositories
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
-	"github.com/rs/zerolog/log"
 	"github.com/your-username/slido-clone/user-service/db"
 	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -16,13 +17,13 @@ import (
 
 // TeamRepository is a repository for teams
 type TeamRepository struct {
-	collection *mongo.Collection
+	collection db.Collection
 }
 
 // NewTeamRepository creates a new team repository
-func NewTeamRepository(mongoDB *db.MongoDB) *TeamRepository {
+func NewTeamRepository(store db.Storage) *TeamRepository {
 	return &TeamRepository{
-		collection: mongoDB.GetCollection(db.TeamsCollection),
+		collection: tenantCollection(store, db.TeamsCollection, "organizationId"),
 	}
 }
 
@@ -31,7 +32,7 @@ func (r *TeamRepository) Create(ctx context.Context, team *models.Team) error {
 	// Check if team with the same name already exists in the organization
 	existingTeam, err := r.GetByNameAndOrganization(ctx, team.Name, team.OrganizationID)
 	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-		log.Error().Err(err).Str("name", team.Name).Str("orgId", team.OrganizationID).
+		logger.Ctx(ctx).Error().Err(err).Str("name", team.Name).Str("orgId", team.OrganizationID).
 			Msg("Error checking existing team")
 		return err
 	}
@@ -40,9 +41,9 @@ func (r *TeamRepository) Create(ctx context.Context, team *models.Team) error {
 	}
 
 	// Create team
-	result, err := r.collection.InsertOne(ctx, team)
+	result, err := insertScoped(ctx, r.collection, team)
 	if err != nil {
-		log.Error().Err(err).Interface("team", team).Msg("Error creating team")
+		logger.Ctx(ctx).Error().Err(err).Interface("team", team).Msg("Error creating team")
 		return err
 	}
 
@@ -51,7 +52,7 @@ func (r *TeamRepository) Create(ctx context.Context, team *models.Team) error {
 		team.ID = oid.Hex()
 	}
 
-	log.Debug().Str("id", team.ID).Str("name", team.Name).Msg("Team created")
+	logger.Ctx(ctx).Debug().Str("id", team.ID).Str("name", team.Name).Msg("Team created")
 	return nil
 }
 
@@ -65,12 +66,12 @@ func (r *TeamRepository) GetByID(ctx context.Context, id string) (*models.Team,
 	}
 
 	filter := bson.M{"_id": objID}
-	err = r.collection.FindOne(ctx, filter).Decode(&team)
+	err = db.ReadCollection(ctx, r.collection, "teams.GetByID").FindOne(ctx, filter).Decode(&team)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, mongo.ErrNoDocuments
 		}
-		log.Error().Err(err).Str("id", id).Msg("Error getting team by ID")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error getting team by ID")
 		return nil, err
 	}
 
@@ -87,7 +88,7 @@ func (r *TeamRepository) GetByNameAndOrganization(ctx context.Context, name, org
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, mongo.ErrNoDocuments
 		}
-		log.Error().Err(err).Str("name", name).Str("orgId", organizationID).
+		logger.Ctx(ctx).Error().Err(err).Str("name", name).Str("orgId", organizationID).
 			Msg("Error getting team by name and organization")
 		return nil, err
 	}
@@ -95,17 +96,22 @@ func (r *TeamRepository) GetByNameAndOrganization(ctx context.Context, name, org
 	return &team, nil
 }
 
-// GetTeamsByOrganization gets teams by organization ID
-func (r *TeamRepository) GetTeamsByOrganization(ctx context.Context, organizationID string, page, limit int) ([]*models.Team, int64, error) {
+// GetTeamsByOrganization gets teams by organization ID, only including teams
+// with all of the tags. Secret teams are left out unless hiddenFrom, when set,
+// is one of their members, and archived teams unless they are included. Only
+// the stored fields the fieldset needs are read.
+func (r *TeamRepository) GetTeamsByOrganization(ctx context.Context, organizationID string, tags []string, page, limit int, fields models.FieldSet, hiddenFrom string, includeArchived bool) ([]*models.Team, int64, error) {
 	var teams []*models.Team
 
 	// Build filter
-	filter := bson.M{"organizationId": organizationID}
+	filter := filterTags(bson.M{"organizationId": organizationID}, tags)
+	filterSecretTeams(filter, hiddenFrom)
+	filterArchivedTeams(filter, includeArchived)
 
 	// Count total
 	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
-		log.Error().Err(err).Str("orgId", organizationID).Msg("Error counting teams")
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", organizationID).Msg("Error counting teams")
 		return nil, 0, err
 	}
 
@@ -114,35 +120,41 @@ func (r *TeamRepository) GetTeamsByOrganization(ctx context.Context, organizatio
 		SetSkip(int64((page - 1) * limit)).
 		SetLimit(int64(limit)).
 		SetSort(bson.M{"name": 1})
+	if projection := fieldProjection(fields, models.TeamResponseFields); projection != nil {
+		opts.SetProjection(projection)
+	}
 
 	// Find teams
 	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
-		log.Error().Err(err).Str("orgId", organizationID).Msg("Error finding teams")
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", organizationID).Msg("Error finding teams")
 		return nil, 0, err
 	}
 	defer cursor.Close(ctx)
 
 	// Decode teams
 	if err := cursor.All(ctx, &teams); err != nil {
-		log.Error().Err(err).Msg("Error decoding teams")
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding teams")
 		return nil, 0, err
 	}
 
 	return teams, total, nil
 }
 
-// GetTeamsByUser gets teams by user ID
-func (r *TeamRepository) GetTeamsByUser(ctx context.Context, userID string, page, limit int) ([]*models.Team, int64, error) {
+// GetTeamsByUser gets teams by user ID, only including teams with all of the
+// tags. Archived teams are left out unless they are included. Only the stored
+// fields the fieldset needs are read.
+func (r *TeamRepository) GetTeamsByUser(ctx context.Context, userID string, tags []string, page, limit int, fields models.FieldSet, includeArchived bool) ([]*models.Team, int64, error) {
 	var teams []*models.Team
 
 	// Build filter for teams where the user is a member
-	filter := bson.M{"members.userId": userID}
+	filter := filterTags(bson.M{"members.userId": userID}, tags)
+	filterArchivedTeams(filter, includeArchived)
 
 	// Count total
 	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Error counting user teams")
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error counting user teams")
 		return nil, 0, err
 	}
 
@@ -151,24 +163,312 @@ func (r *TeamRepository) GetTeamsByUser(ctx context.Context, userID string, page
 		SetSkip(int64((page - 1) * limit)).
 		SetLimit(int64(limit)).
 		SetSort(bson.M{"name": 1})
+	if projection := fieldProjection(fields, models.TeamResponseFields); projection != nil {
+		opts.SetProjection(projection)
+	}
 
 	// Find teams
 	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Error finding user teams")
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error finding user teams")
 		return nil, 0, err
 	}
 	defer cursor.Close(ctx)
 
 	// Decode teams
 	if err := cursor.All(ctx, &teams); err != nil {
-		log.Error().Err(err).Msg("Error decoding user teams")
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding user teams")
 		return nil, 0, err
 	}
 
 	return teams, total, nil
 }
 
+// GetChildren gets the teams directly under a team. Secret teams are left out
+// unless hiddenFrom, when set, is one of their members, and archived teams
+// unless they are included.
+func (r *TeamRepository) GetChildren(ctx context.Context, parentID string, page, limit int, hiddenFrom string, includeArchived bool) ([]*models.Team, int64, error) {
+	var teams []*models.Team
+
+	filter := bson.M{"parentTeamId": parentID}
+	filterSecretTeams(filter, hiddenFrom)
+	filterArchivedTeams(filter, includeArchived)
+
+	// Count total
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("parentTeamId", parentID).Msg("Error counting child teams")
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"name": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("parentTeamId", parentID).Msg("Error finding child teams")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &teams); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding child teams")
+		return nil, 0, err
+	}
+
+	return teams, total, nil
+}
+
+// filterSecretTeams restricts a team filter to the teams a user can see,
+// leaving out the secret teams they aren't a member of. An empty user sees
+// every team.
+func filterSecretTeams(filter bson.M, userID string) {
+	if userID == "" {
+		return
+	}
+	filter["$or"] = bson.A{
+		bson.M{"settings.visibility": bson.M{"$ne": models.TeamVisibilitySecret}},
+		bson.M{"members.userId": userID},
+	}
+}
+
+// filterArchivedTeams restricts a team filter to the teams that aren't
+// archived, unless archived teams are included
+func filterArchivedTeams(filter bson.M, includeArchived bool) {
+	if includeArchived {
+		return
+	}
+	filter["archivedAt"] = bson.M{"$exists": false}
+}
+
+// GetChildIDs gets the IDs of the teams directly under any of the teams
+func (r *TeamRepository) GetChildIDs(ctx context.Context, parentIDs ...string) ([]string, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"parentTeamId": bson.M{"$in": parentIDs}})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Strs("parentTeamIds", parentIDs).Msg("Error finding child teams")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var teams []*models.Team
+	if err := cursor.All(ctx, &teams); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding child teams")
+		return nil, err
+	}
+
+	ids := make([]string, len(teams))
+	for i, team := range teams {
+		ids[i] = team.ID
+	}
+
+	return ids, nil
+}
+
+// GetAncestors gets the teams above a team, nearest first. The walk stops at
+// a parent that no longer exists and after MaxTeamDepth levels.
+func (r *TeamRepository) GetAncestors(ctx context.Context, team *models.Team) ([]*models.Team, error) {
+	ancestors := make([]*models.Team, 0)
+	seen := map[string]bool{team.ID: true}
+
+	parentID := team.ParentTeamID
+	for parentID != "" && !seen[parentID] && len(ancestors) < models.MaxTeamDepth {
+		parent, err := r.GetByID(ctx, parentID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				break
+			}
+			return nil, err
+		}
+
+		seen[parentID] = true
+		ancestors = append(ancestors, parent)
+		parentID = parent.ParentTeamID
+	}
+
+	return ancestors, nil
+}
+
+// SetParent moves a team under another team, or to the top level when
+// parentID is empty
+func (r *TeamRepository) SetParent(ctx context.Context, teamID, parentID string) error {
+	objID, err := primitive.ObjectIDFromHex(teamID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"parentTeamId": parentID, "updatedAt": time.Now()}}
+	if parentID == "" {
+		update = bson.M{
+			"$set":   bson.M{"updatedAt": time.Now()},
+			"$unset": bson.M{"parentTeamId": ""},
+		}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Str("parentTeamId", parentID).Msg("Error setting team parent")
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// AddTags adds tags to a team
+func (r *TeamRepository) AddTags(ctx context.Context, teamID string, tags []string) error {
+	objID, err := primitive.ObjectIDFromHex(teamID)
+	if err != nil {
+		return err
+	}
+
+	if err := addTags(ctx, r.collection, bson.M{"_id": objID}, tags); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) && !errors.Is(err, ErrTooManyTags) {
+			logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Error adding team tags")
+		}
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", teamID).Strs("tags", tags).Msg("Team tags added")
+	return nil
+}
+
+// RemoveTag removes a tag from a team
+func (r *TeamRepository) RemoveTag(ctx context.Context, teamID, tag string) error {
+	objID, err := primitive.ObjectIDFromHex(teamID)
+	if err != nil {
+		return err
+	}
+
+	if err := removeTag(ctx, r.collection, bson.M{"_id": objID}, tag); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Str("tag", tag).Msg("Error removing team tag")
+		}
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", teamID).Str("tag", tag).Msg("Team tag removed")
+	return nil
+}
+
+// CountOrganizationTeams counts the teams of an organization and their
+// members
+func (r *TeamRepository) CountOrganizationTeams(ctx context.Context, orgID string) (teams, memberships int64, err error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"organizationId": orgID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         nil,
+			"teams":       bson.M{"$sum": 1},
+			"memberships": bson.M{"$sum": bson.M{"$size": bson.M{"$ifNull": bson.A{"$members", bson.A{}}}}},
+		}}},
+	}
+
+	var counts []struct {
+		Teams       int64 `bson:"teams"`
+		Memberships int64 `bson:"memberships"`
+	}
+	if err := aggregate(ctx, r.collection, pipeline, &counts); err != nil {
+		if !errors.Is(err, db.ErrAggregationUnsupported) {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error counting organization teams")
+		}
+		return 0, 0, err
+	}
+	if len(counts) == 0 {
+		return 0, 0, nil
+	}
+
+	return counts[0].Teams, counts[0].Memberships, nil
+}
+
+// FindTeams gets teams matching an arbitrary filter with offset pagination
+func (r *TeamRepository) FindTeams(ctx context.Context, filter bson.M, skip, limit int64) ([]*models.Team, int64, error) {
+	var teams []*models.Team
+
+	// Count total
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error counting teams")
+		return nil, 0, err
+	}
+
+	// Set options for pagination and sorting
+	opts := options.Find().
+		SetSkip(skip).
+		SetLimit(limit).
+		SetSort(bson.M{"createdAt": 1})
+
+	// Find teams
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding teams")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	// Decode teams
+	if err := cursor.All(ctx, &teams); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding teams")
+		return nil, 0, err
+	}
+
+	return teams, total, nil
+}
+
+// FindBatch gets up to limit teams matching a filter, ordered by ID, for
+// keyset iteration over the whole collection
+func (r *TeamRepository) FindBatch(ctx context.Context, filter bson.M, limit int64) ([]*models.Team, error) {
+	var teams []*models.Team
+
+	opts := options.Find().
+		SetLimit(limit).
+		SetSort(bson.M{"_id": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding teams batch")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &teams); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding teams batch")
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+// FindChangedSince gets up to limit teams matching a filter that changed
+// since a time, oldest change first
+func (r *TeamRepository) FindChangedSince(ctx context.Context, filter bson.M, since time.Time, limit int64) ([]*models.Team, error) {
+	var teams []*models.Team
+
+	changed := bson.M{"updatedAt": bson.M{"$gte": since}}
+	for key, value := range filter {
+		changed[key] = value
+	}
+	opts := options.Find().
+		SetLimit(limit).
+		SetSort(bson.M{"updatedAt": 1})
+
+	cursor, err := r.collection.Find(ctx, changed, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding changed teams")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &teams); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding changed teams")
+		return nil, err
+	}
+
+	return teams, nil
+}
+
 // Update updates a team
 func (r *TeamRepository) Update(ctx context.Context, team *models.Team) error {
 	objID, err := primitive.ObjectIDFromHex(team.ID)
@@ -181,7 +481,7 @@ func (r *TeamRepository) Update(ctx context.Context, team *models.Team) error {
 	// Check if updating name and if new name conflicts with existing team
 	existingTeam, err := r.GetByNameAndOrganization(ctx, team.Name, team.OrganizationID)
 	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-		log.Error().Err(err).Str("name", team.Name).Str("orgId", team.OrganizationID).
+		logger.Ctx(ctx).Error().Err(err).Str("name", team.Name).Str("orgId", team.OrganizationID).
 			Msg("Error checking team name conflict")
 		return err
 	}
@@ -195,6 +495,7 @@ func (r *TeamRepository) Update(ctx context.Context, team *models.Team) error {
 			"name":        team.Name,
 			"description": team.Description,
 			"logoUrl":     team.LogoURL,
+			"externalId":  team.ExternalID,
 			"members":     team.Members,
 			"updatedAt":   time.Now(),
 		},
@@ -202,75 +503,122 @@ func (r *TeamRepository) Update(ctx context.Context, team *models.Team) error {
 
 	_, err = r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Str("id", team.ID).Msg("Error updating team")
+		logger.Ctx(ctx).Error().Err(err).Str("id", team.ID).Msg("Error updating team")
 		return err
 	}
 
-	log.Debug().Str("id", team.ID).Msg("Team updated")
+	logger.Ctx(ctx).Debug().Str("id", team.ID).Msg("Team updated")
 	return nil
 }
 
-// Delete deletes a team
-func (r *TeamRepository) Delete(ctx context.Context, id string) error {
-	objID, err := primitive.ObjectIDFromHex(id)
+// UpdateSettings replaces the settings of a team
+func (r *TeamRepository) UpdateSettings(ctx context.Context, teamID string, settings models.TeamSettings) error {
+	objID, err := primitive.ObjectIDFromHex(teamID)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{"_id": objID}
-	_, err = r.collection.DeleteOne(ctx, filter)
+	update := bson.M{"$set": bson.M{"settings": settings, "updatedAt": time.Now()}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Error deleting team")
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Error updating team settings")
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
 
-	log.Debug().Str("id", id).Msg("Team deleted")
+	logger.Ctx(ctx).Debug().Str("id", teamID).Msg("Team settings updated")
 	return nil
 }
 
-// AddMember adds a member to a team
-func (r *TeamRepository) AddMember(ctx context.Context, teamID, userID string, role models.TeamMemberRole, invitedBy string) error {
+// SetArchived archives a team, or unarchives it when archivedAt is nil. It
+// returns mongo.ErrNoDocuments if the team is missing or already in that
+// state.
+func (r *TeamRepository) SetArchived(ctx context.Context, teamID string, archivedAt *time.Time, archivedBy string) error {
 	objID, err := primitive.ObjectIDFromHex(teamID)
 	if err != nil {
 		return err
 	}
 
-	// Check if member already exists
-	filter := bson.M{
-		"_id":            objID,
-		"members.userId": userID,
+	filter := bson.M{"_id": objID, "archivedAt": bson.M{"$exists": false}}
+	update := bson.M{
+		"$set": bson.M{
+			"archivedAt": archivedAt,
+			"archivedBy": archivedBy,
+			"updatedAt":  time.Now(),
+		},
+	}
+	if archivedAt == nil {
+		filter["archivedAt"] = bson.M{"$exists": true}
+		update = bson.M{
+			"$unset": bson.M{"archivedAt": "", "archivedBy": ""},
+			"$set":   bson.M{"updatedAt": time.Now()},
+		}
 	}
 
-	existingTeam, err := r.collection.CountDocuments(ctx, filter)
+	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Str("teamId", teamID).Str("userId", userID).
-			Msg("Error checking existing team member")
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Error archiving team")
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
 
-	if existingTeam > 0 {
-		// Member already exists, update role
-		filter = bson.M{"_id": objID, "members.userId": userID}
-		update := bson.M{
-			"$set": bson.M{
-				"members.$.role": role,
-				"updatedAt":      time.Now(),
-			},
-		}
+	logger.Ctx(ctx).Debug().Str("id", teamID).Bool("archived", archivedAt != nil).Msg("Team archive state changed")
+	return nil
+}
+
+// Delete deletes a team
+func (r *TeamRepository) Delete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objID}
+	_, err = r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error deleting team")
+		return err
+	}
 
-		_, err = r.collection.UpdateOne(ctx, filter, update)
+	logger.Ctx(ctx).Debug().Str("id", id).Msg("Team deleted")
+	return nil
+}
+
+// AddMember adds a member to a team, or updates the role of an existing
+// member. Each step is a single conditional update, so concurrent adds of the
+// same user can't create duplicate member entries.
+func (r *TeamRepository) AddMember(ctx context.Context, teamID, userID string, role models.TeamMemberRole, invitedBy string) error {
+	objID, err := primitive.ObjectIDFromHex(teamID)
+	if err != nil {
+		return err
+	}
+
+	// A failed add means the member was added concurrently, so the role update
+	// is retried once before giving up
+	for attempt := 0; attempt < 2; attempt++ {
+		// Update the role if the user is already a member
+		updated, err := r.setMemberRole(ctx, objID, userID, role)
 		if err != nil {
-			log.Error().Err(err).Str("teamId", teamID).Str("userId", userID).
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("userId", userID).
 				Msg("Error updating team member role")
 			return err
 		}
+		if updated {
+			logger.Ctx(ctx).Debug().Str("teamId", teamID).Str("userId", userID).
+				Str("role", string(role)).Msg("Team member role updated")
+			return nil
+		}
 
-		log.Debug().Str("teamId", teamID).Str("userId", userID).
-			Str("role", string(role)).Msg("Team member role updated")
-	} else {
-		// Add new member
+		// Add the member, conditional on the user not being a member yet
 		now := time.Now()
-		filter = bson.M{"_id": objID}
+		filter := bson.M{
+			"_id":            objID,
+			"members.userId": bson.M{"$ne": userID},
+		}
 		update := bson.M{
 			"$push": bson.M{
 				"members": bson.M{
@@ -285,20 +633,137 @@ func (r *TeamRepository) AddMember(ctx context.Context, teamID, userID string, r
 			},
 		}
 
-		_, err = r.collection.UpdateOne(ctx, filter, update)
+		result, err := r.collection.UpdateOne(ctx, filter, update)
 		if err != nil {
-			log.Error().Err(err).Str("teamId", teamID).Str("userId", userID).
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("userId", userID).
 				Msg("Error adding team member")
 			return err
 		}
+		if result.MatchedCount > 0 {
+			logger.Ctx(ctx).Debug().Str("teamId", teamID).Str("userId", userID).
+				Str("role", string(role)).Msg("Team member added")
+			return nil
+		}
+	}
+
+	// Neither update matched, so the team doesn't exist
+	return mongo.ErrNoDocuments
+}
+
+// UpdateMemberRole updates the role of an existing team member
+func (r *TeamRepository) UpdateMemberRole(ctx context.Context, teamID, userID string, role models.TeamMemberRole) error {
+	objID, err := primitive.ObjectIDFromHex(teamID)
+	if err != nil {
+		return err
+	}
 
-		log.Debug().Str("teamId", teamID).Str("userId", userID).
-			Str("role", string(role)).Msg("Team member added")
+	updated, err := r.setMemberRole(ctx, objID, userID, role)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("userId", userID).
+			Msg("Error updating team member role")
+		return err
+	}
+	if !updated {
+		return errors.New("member not found in team")
 	}
 
+	logger.Ctx(ctx).Debug().Str("teamId", teamID).Str("userId", userID).
+		Str("role", string(role)).Msg("Team member role updated")
 	return nil
 }
 
+// setMemberRole sets the role of every member entry of a user in one
+// conditional update. It reports false if the user isn't a member.
+func (r *TeamRepository) setMemberRole(ctx context.Context, objID primitive.ObjectID, userID string, role models.TeamMemberRole) (bool, error) {
+	filter := bson.M{
+		"_id":            objID,
+		"members.userId": userID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"members.$[member].role": role,
+			"updatedAt":              time.Now(),
+		},
+	}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"member.userId": userID}},
+	})
+
+	result, err := r.collection.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// RemoveDuplicateMembers collapses the duplicate member entries of a user,
+// left by concurrent adds before AddMember was made conditional, into the
+// first entry. It returns the number of teams that were fixed.
+func (r *TeamRepository) RemoveDuplicateMembers(ctx context.Context) (int, error) {
+	// Only teams with more than one member entry can have duplicates
+	cursor, err := r.collection.Find(ctx, bson.M{"members.1": bson.M{"$exists": true}})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding teams for member cleanup")
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []*models.Team
+	if err := cursor.All(ctx, &docs); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding teams for member cleanup")
+		return 0, err
+	}
+
+	fixed, changed := 0, 0
+	for _, doc := range docs {
+		seen := make(map[string]bool, len(doc.Members))
+		members := make([]models.TeamMember, 0, len(doc.Members))
+		for _, member := range doc.Members {
+			if !seen[member.UserID] {
+				seen[member.UserID] = true
+				members = append(members, member)
+			}
+		}
+		if len(members) == len(doc.Members) {
+			continue
+		}
+
+		objID, err := primitive.ObjectIDFromHex(doc.ID)
+		if err != nil {
+			return fixed, err
+		}
+
+		// Conditional on the team not having changed since it was read
+		filter := bson.M{"_id": objID, "updatedAt": doc.UpdatedAt}
+		update := bson.M{
+			"$set": bson.M{
+				"members":   members,
+				"updatedAt": time.Now(),
+			},
+		}
+
+		result, err := r.collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", doc.ID).Msg("Error removing duplicate team members")
+			return fixed, err
+		}
+		if result.MatchedCount == 0 {
+			changed++
+			continue
+		}
+
+		logger.Ctx(ctx).Info().Str("teamId", doc.ID).Int("removed", len(doc.Members)-len(members)).
+			Msg("Removed duplicate team members")
+		fixed++
+	}
+
+	if changed > 0 {
+		return fixed, fmt.Errorf("%d teams changed during member cleanup", changed)
+	}
+
+	return fixed, nil
+}
+
 // RemoveMember removes a member from a team
 func (r *TeamRepository) RemoveMember(ctx context.Context, teamID, userID string) error {
 	objID, err := primitive.ObjectIDFromHex(teamID)
@@ -318,7 +783,7 @@ func (r *TeamRepository) RemoveMember(ctx context.Context, teamID, userID string
 
 	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Str("teamId", teamID).Str("userId", userID).
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("userId", userID).
 			Msg("Error removing team member")
 		return err
 	}
@@ -327,6 +792,109 @@ func (r *TeamRepository) RemoveMember(ctx context.Context, teamID, userID string
 		return errors.New("member not found in team")
 	}
 
-	log.Debug().Str("teamId", teamID).Str("userId", userID).Msg("Team member removed")
+	logger.Ctx(ctx).Debug().Str("teamId", teamID).Str("userId", userID).Msg("Team member removed")
+	return nil
+}
+
+// ReplaceMembers replaces the members of a team in one update, conditional on
+// the team not having changed since it was read. It returns
+// mongo.ErrNoDocuments if it did.
+func (r *TeamRepository) ReplaceMembers(ctx context.Context, team *models.Team, members []models.TeamMember) error {
+	objID, err := primitive.ObjectIDFromHex(team.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	filter := bson.M{"_id": objID, "updatedAt": team.UpdatedAt}
+	update := bson.M{
+		"$set": bson.M{
+			"members":   members,
+			"updatedAt": now,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Error replacing team members")
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	team.Members = members
+	team.UpdatedAt = now
+
+	logger.Ctx(ctx).Debug().Str("teamId", team.ID).Int("members", len(members)).Msg("Team members replaced")
+	return nil
+}
+
+// SetPendingTransfer sets or clears the pending ownership transfer of a team
+func (r *TeamRepository) SetPendingTransfer(ctx context.Context, teamID string, transfer *models.OwnershipTransfer) error {
+	objID, err := primitive.ObjectIDFromHex(teamID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objID}
+	update := bson.M{
+		"$set": bson.M{
+			"pendingTransfer": transfer,
+			"updatedAt":       time.Now(),
+		},
+	}
+	if transfer == nil {
+		update = bson.M{
+			"$unset": bson.M{"pendingTransfer": ""},
+			"$set":   bson.M{"updatedAt": time.Now()},
+		}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Msg("Error setting team pending transfer")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// TransferOwnership atomically completes a pending ownership transfer, promoting the
+// new owner and demoting or removing the previous owner in a single update
+func (r *TeamRepository) TransferOwnership(ctx context.Context, teamID string, transfer *models.OwnershipTransfer) error {
+	objID, err := primitive.ObjectIDFromHex(teamID)
+	if err != nil {
+		return err
+	}
+
+	// Only match if the transfer is still pending and both members are still in place
+	filter := bson.M{
+		"_id":                        objID,
+		"pendingTransfer.fromUserId": transfer.FromUserID,
+		"pendingTransfer.toUserId":   transfer.ToUserID,
+		"members": bson.M{"$elemMatch": bson.M{
+			"userId": transfer.FromUserID,
+			"role":   models.TeamRoleOwner,
+		}},
+		"members.userId": transfer.ToUserID,
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, ownershipTransferPipeline(transfer, string(models.TeamRoleOwner)))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("from", transfer.FromUserID).Str("to", transfer.ToUserID).
+			Msg("Error transferring team ownership")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("teamId", teamID).Str("from", transfer.FromUserID).Str("to", transfer.ToUserID).
+		Msg("Team ownership transferred")
 	return nil
 }
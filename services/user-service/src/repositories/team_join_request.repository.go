@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrTeamJoinRequestPending is returned when a user already has a pending request to join a team
+var ErrTeamJoinRequestPending = apperrors.Conflict("TEAM_JOIN_REQUEST_PENDING", "user already has a pending request to join the team")
+
+// TeamJoinRequestRepository is a repository for team join requests
+type TeamJoinRequestRepository struct {
+	collection db.Collection
+}
+
+// NewTeamJoinRequestRepository creates a new team join request repository
+func NewTeamJoinRequestRepository(store db.Storage) *TeamJoinRequestRepository {
+	return &TeamJoinRequestRepository{
+		collection: store.GetCollection(db.TeamJoinRequestsCollection),
+	}
+}
+
+// Create creates a new team join request
+func (r *TeamJoinRequestRepository) Create(ctx context.Context, req *models.TeamJoinRequest) error {
+	_, err := r.collection.InsertOne(ctx, req)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrTeamJoinRequestPending
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", req.TeamID).Str("userId", req.UserID).
+			Msg("Error creating team join request")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", req.ID).Str("teamId", req.TeamID).Msg("Team join request created")
+	return nil
+}
+
+// GetByID gets a join request of a team by ID
+func (r *TeamJoinRequestRepository) GetByID(ctx context.Context, teamID, id string) (*models.TeamJoinRequest, error) {
+	var req models.TeamJoinRequest
+
+	filter := bson.M{"_id": id, "teamId": teamID}
+	err := r.collection.FindOne(ctx, filter).Decode(&req)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error getting team join request by ID")
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// GetPendingByUser gets the pending join request of a user for a team
+func (r *TeamJoinRequestRepository) GetPendingByUser(ctx context.Context, teamID, userID string) (*models.TeamJoinRequest, error) {
+	var req models.TeamJoinRequest
+
+	filter := bson.M{
+		"teamId": teamID,
+		"userId": userID,
+		"status": models.JoinRequestPending,
+	}
+	err := r.collection.FindOne(ctx, filter).Decode(&req)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("userId", userID).Msg("Error getting pending team join request")
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// ListByTeam lists the join requests of a team with the given status, oldest first
+func (r *TeamJoinRequestRepository) ListByTeam(ctx context.Context, teamID string, status models.JoinRequestStatus, page, limit int) ([]*models.TeamJoinRequest, int64, error) {
+	var reqs []*models.TeamJoinRequest
+
+	filter := bson.M{"teamId": teamID, "status": status}
+
+	// Count total
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Msg("Error counting team join requests")
+		return nil, 0, err
+	}
+
+	// Set options for pagination and sorting, oldest first
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"createdAt": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Msg("Error finding team join requests")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &reqs); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding team join requests")
+		return nil, 0, err
+	}
+
+	return reqs, total, nil
+}
+
+// Resolve moves a pending team join request to a final status. It returns
+// mongo.ErrNoDocuments if the request is no longer pending.
+func (r *TeamJoinRequestRepository) Resolve(ctx context.Context, req *models.TeamJoinRequest) error {
+	filter := bson.M{
+		"_id":    req.ID,
+		"status": models.JoinRequestPending,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       req.Status,
+			"role":         req.Role,
+			"reviewedBy":   req.ReviewedBy,
+			"reviewReason": req.ReviewReason,
+			"reviewedAt":   req.ReviewedAt,
+			"updatedAt":    time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", req.ID).Msg("Error resolving team join request")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", req.ID).Str("status", string(req.Status)).Msg("Team join request resolved")
+	return nil
+}
@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrTeamTemplateNameTaken is returned when an organization already has a team template with the name
+var ErrTeamTemplateNameTaken = apperrors.Conflict("TEAM_TEMPLATE_NAME_TAKEN", "a team template with this name already exists in the organization")
+
+// TeamTemplateRepository is a repository for team templates
+type TeamTemplateRepository struct {
+	collection db.Collection
+}
+
+// NewTeamTemplateRepository creates a new team template repository
+func NewTeamTemplateRepository(store db.Storage) *TeamTemplateRepository {
+	return &TeamTemplateRepository{
+		collection: tenantCollection(store, db.TeamTemplatesCollection, "organizationId"),
+	}
+}
+
+// Create creates a new team template
+func (r *TeamTemplateRepository) Create(ctx context.Context, template *models.TeamTemplate) error {
+	_, err := insertScoped(ctx, r.collection, template)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrTeamTemplateNameTaken
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", template.OrganizationID).Msg("Error creating team template")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", template.ID).Str("orgId", template.OrganizationID).Msg("Team template created")
+	return nil
+}
+
+// GetByID gets a team template of an organization by ID
+func (r *TeamTemplateRepository) GetByID(ctx context.Context, orgID, id string) (*models.TeamTemplate, error) {
+	var template models.TeamTemplate
+
+	filter := bson.M{"_id": id, "organizationId": orgID}
+	err := r.collection.FindOne(ctx, filter).Decode(&template)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error getting team template by ID")
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// GetByOrganization gets a page of the team templates of an organization, by name
+func (r *TeamTemplateRepository) GetByOrganization(ctx context.Context, orgID string, page, limit int) ([]*models.TeamTemplate, int64, error) {
+	var templates []*models.TeamTemplate
+
+	filter := bson.M{"organizationId": orgID}
+
+	// Count total
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error counting team templates")
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"name": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error finding team templates")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &templates); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding team templates")
+		return nil, 0, err
+	}
+
+	return templates, total, nil
+}
+
+// Update updates a team template
+func (r *TeamTemplateRepository) Update(ctx context.Context, template *models.TeamTemplate) error {
+	filter := bson.M{"_id": template.ID, "organizationId": template.OrganizationID}
+	update := bson.M{
+		"$set": bson.M{
+			"name":        template.Name,
+			"description": template.Description,
+			"namePattern": template.NamePattern,
+			"settings":    template.Settings,
+			"seedRoles":   template.SeedRoles,
+			"updatedAt":   template.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrTeamTemplateNameTaken
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", template.ID).Msg("Error updating team template")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", template.ID).Msg("Team template updated")
+	return nil
+}
+
+// Delete deletes a team template of an organization
+func (r *TeamTemplateRepository) Delete(ctx context.Context, orgID, id string) error {
+	filter := bson.M{"_id": id, "organizationId": orgID}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("orgId", orgID).Msg("Error deleting team template")
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", id).Str("orgId", orgID).Msg("Team template deleted")
+	return nil
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Documents of tenant collections name their organization
+var (
+	_ tenancy.Scoped = (*models.Organization)(nil)
+	_ tenancy.Scoped = (*models.Team)(nil)
+	_ tenancy.Scoped = (*models.OrganizationMemberRecord)(nil)
+	_ tenancy.Scoped = (*models.Group)(nil)
+	_ tenancy.Scoped = (*models.JoinRequest)(nil)
+	_ tenancy.Scoped = (*models.TeamTemplate)(nil)
+	_ tenancy.Scoped = (*models.SettingsVersion)(nil)
+	_ tenancy.Scoped = (*models.OrganizationExport)(nil)
+	_ tenancy.Scoped = (*models.OrganizationExportFile)(nil)
+	_ tenancy.Scoped = (*models.OrganizationExportChunk)(nil)
+	_ tenancy.Scoped = (*models.Event)(nil)
+)
+
+// tenantCollection gets a collection of organization data, whose queries
+// are kept inside the active organization of their context
+func tenantCollection(store db.Storage, name, field string) db.Collection {
+	return db.TenantCollection(db.RegionalCollection(store, name), field)
+}
+
+// insertScoped inserts a document into a tenant collection. Only documents
+// naming their organization can be inserted, so one that doesn't is caught
+// at compile time, and one of another organization than the active one is
+// rejected.
+func insertScoped[T tenancy.Scoped](ctx context.Context, collection db.Collection, document T) (*mongo.InsertOneResult, error) {
+	if err := tenancy.Check(ctx, document.TenantID()); err != nil {
+		return nil, err
+	}
+	return collection.InsertOne(ctx, document)
+}
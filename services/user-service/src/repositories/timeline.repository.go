@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TimelineRepository is a repository for organization timeline entries
+type TimelineRepository struct {
+	collection db.Collection
+}
+
+// NewTimelineRepository creates a new timeline repository
+func NewTimelineRepository(store db.Storage) *TimelineRepository {
+	return &TimelineRepository{
+		collection: store.GetCollection(db.TimelineCollection),
+	}
+}
+
+// Create creates a new timeline entry
+func (r *TimelineRepository) Create(ctx context.Context, entry *models.TimelineEntry) error {
+	_, err := r.collection.InsertOne(ctx, entry)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", entry.OrganizationID).Str("eventType", entry.EventType).
+			Msg("Error creating timeline entry")
+		return err
+	}
+
+	return nil
+}
+
+// GetByOrganization lists the timeline entries of an organization, newest
+// first, starting after the cursor. Entries are limited to the given types
+// unless types is empty.
+func (r *TimelineRepository) GetByOrganization(ctx context.Context, orgID string, types []models.TimelineEntryType, after *models.TimelineCursor, limit int) ([]*models.TimelineEntry, error) {
+	var entries []*models.TimelineEntry
+
+	filter := bson.M{"organizationId": orgID}
+	if len(types) > 0 {
+		filter["type"] = bson.M{"$in": types}
+	}
+	if after != nil {
+		filter["$or"] = []bson.M{
+			{"occurredAt": bson.M{"$lt": after.OccurredAt}},
+			{"occurredAt": after.OccurredAt, "_id": bson.M{"$lt": after.ID}},
+		}
+	}
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "occurredAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error finding timeline entries")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &entries); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding timeline entries")
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// CountMembershipChanges counts the members that joined and left an
+// organization since a time, from its joinedEvent and leftEvent timeline
+// entries, per bucket of interval
+func (r *TimelineRepository) CountMembershipChanges(ctx context.Context, orgID string, joinedEvent, leftEvent string, interval models.StatsInterval, since time.Time) ([]models.MembershipChangeCount, error) {
+	countEvent := func(eventType string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$eventType", eventType}}, 1, 0}}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"organizationId": orgID,
+			"eventType":      bson.M{"$in": bson.A{joinedEvent, leftEvent}},
+			"occurredAt":     bson.M{"$gte": since},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    dateTrunc("occurredAt", interval),
+			"joined": countEvent(joinedEvent),
+			"left":   countEvent(leftEvent),
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	var counts []models.MembershipChangeCount
+	if err := aggregate(ctx, r.collection, pipeline, &counts); err != nil {
+		if !errors.Is(err, db.ErrAggregationUnsupported) {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error counting membership changes")
+		}
+		return nil, err
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TombstoneRepository is a repository for the tombstones of deleted entities
+type TombstoneRepository struct {
+	collection db.Collection
+}
+
+// NewTombstoneRepository creates a new tombstone repository
+func NewTombstoneRepository(store db.Storage) *TombstoneRepository {
+	return &TombstoneRepository{
+		collection: store.GetCollection(db.TombstonesCollection),
+	}
+}
+
+// Create creates a new tombstone
+func (r *TombstoneRepository) Create(ctx context.Context, tombstone *models.Tombstone) error {
+	_, err := r.collection.InsertOne(ctx, tombstone)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("entityType", string(tombstone.EntityType)).Str("entityId", tombstone.EntityID).
+			Msg("Error creating tombstone")
+		return err
+	}
+
+	return nil
+}
+
+// CreateMany creates tombstones with one bulk write
+func (r *TombstoneRepository) CreateMany(ctx context.Context, tombstones []*models.Tombstone) error {
+	writes := make([]mongo.WriteModel, len(tombstones))
+	for i, tombstone := range tombstones {
+		writes[i] = mongo.NewInsertOneModel().SetDocument(tombstone)
+	}
+
+	if _, err := db.BulkWrite(ctx, r.collection, writes); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Int("count", len(tombstones)).Msg("Error creating tombstones")
+		return err
+	}
+
+	return nil
+}
+
+// GetVisible gets up to limit tombstones recorded since a time that are shown
+// to a user, directly or as a member of one of the organizations, oldest first
+func (r *TombstoneRepository) GetVisible(ctx context.Context, userID string, orgIDs []string, since time.Time, limit int64) ([]*models.Tombstone, error) {
+	var tombstones []*models.Tombstone
+
+	filter := bson.M{
+		"deletedAt": bson.M{"$gte": since},
+		"$or": bson.A{
+			bson.M{"userIds": userID},
+			bson.M{"organizationId": bson.M{"$in": orgIDs}},
+		},
+	}
+	opts := options.Find().
+		SetLimit(limit).
+		SetSort(bson.M{"deletedAt": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error finding tombstones")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &tombstones); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Error decoding tombstones")
+		return nil, err
+	}
+
+	return tombstones, nil
+}
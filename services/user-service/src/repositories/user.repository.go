@@ -5,24 +5,33 @@ import (
 	"errors"
 	"time"
 
-	"github.com/rs/zerolog/log"
 	"github.com/your-username/slido-clone/user-service/db"
 	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrEmailTaken is returned when a user's email is changed to one another
+// user already has
+var ErrEmailTaken = apperrors.Conflict("EMAIL_TAKEN", "email is already in use")
+
+// ErrUserIDTaken is returned when a user is created with the user ID of an
+// existing user
+var ErrUserIDTaken = apperrors.Conflict("USER_ID_TAKEN", "a user with this userId already exists")
+
 // UserRepository is a repository for users
 type UserRepository struct {
-	collection *mongo.Collection
+	collection db.Collection
 }
 
 // NewUserRepository creates a new user repository
-func NewUserRepository(mongoDB *db.MongoDB) *UserRepository {
+func NewUserRepository(store db.Storage) *UserRepository {
 	return &UserRepository{
-		collection: mongoDB.GetCollection(db.UsersCollection),
+		collection: store.GetCollection(db.UsersCollection),
 	}
 }
 
@@ -31,26 +40,27 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	// Check if user with the same userId or email already exists
 	existingUser, err := r.GetByUserId(ctx, user.UserID)
 	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-		log.Error().Err(err).Str("userId", user.UserID).Msg("Error checking existing user by userId")
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Error checking existing user by userId")
 		return err
 	}
 	if existingUser != nil {
-		return errors.New("user with this userId already exists")
+		return ErrUserIDTaken
 	}
 
 	existingUser, err = r.GetByEmail(ctx, user.Email)
 	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-		log.Error().Err(err).Str("email", user.Email).Msg("Error checking existing user by email")
+		logger.Ctx(ctx).Error().Err(err).Str("email", user.Email).Msg("Error checking existing user by email")
 		return err
 	}
 	if existingUser != nil {
-		return errors.New("user with this email already exists")
+		return ErrEmailTaken
 	}
 
 	// Create user
+	user.Search = user.SearchKeys()
 	result, err := r.collection.InsertOne(ctx, user)
 	if err != nil {
-		log.Error().Err(err).Interface("user", user).Msg("Error creating user")
+		logger.Ctx(ctx).Error().Err(err).Interface("user", user).Msg("Error creating user")
 		return err
 	}
 
@@ -59,7 +69,7 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 		user.ID = oid.Hex()
 	}
 
-	log.Debug().Str("id", user.ID).Str("userId", user.UserID).Msg("User created")
+	logger.Ctx(ctx).Debug().Str("id", user.ID).Str("userId", user.UserID).Msg("User created")
 	return nil
 }
 
@@ -73,12 +83,12 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User,
 	}
 
 	filter := bson.M{"_id": objID}
-	err = r.collection.FindOne(ctx, filter).Decode(&user)
+	err = db.ReadCollection(ctx, r.collection, "users.GetByID").FindOne(ctx, filter).Decode(&user)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, mongo.ErrNoDocuments
 		}
-		log.Error().Err(err).Str("id", id).Msg("Error getting user by ID")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error getting user by ID")
 		return nil, err
 	}
 
@@ -90,12 +100,12 @@ func (r *UserRepository) GetByUserId(ctx context.Context, userId string) (*model
 	var user models.User
 
 	filter := bson.M{"userId": userId}
-	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	err := db.ReadCollection(ctx, r.collection, "users.GetByUserId").FindOne(ctx, filter).Decode(&user)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, mongo.ErrNoDocuments
 		}
-		log.Error().Err(err).Str("userId", userId).Msg("Error getting user by userId")
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Msg("Error getting user by userId")
 		return nil, err
 	}
 
@@ -112,68 +122,206 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, mongo.ErrNoDocuments
 		}
-		log.Error().Err(err).Str("email", email).Msg("Error getting user by email")
+		logger.Ctx(ctx).Error().Err(err).Str("email", email).Msg("Error getting user by email")
 		return nil, err
 	}
 
 	return &user, nil
 }
 
-// GetUsers gets users with pagination and filtering
-func (r *UserRepository) GetUsers(ctx context.Context, page, limit int, search string) ([]*models.User, int64, error) {
+// GetUsers gets users with pagination, filtering and sorting. Only the stored
+// fields the filter's fieldset needs are read.
+func (r *UserRepository) GetUsers(ctx context.Context, page, limit int, params models.UserListFilter) ([]*models.User, int64, error) {
 	var users []*models.User
 
-	// Build filter
-	filter := bson.M{}
-	if search != "" {
-		// Search by name or email
-		filter = bson.M{
-			"$or": []bson.M{
-				{"firstName": bson.M{"$regex": search, "$options": "i"}},
-				{"lastName": bson.M{"$regex": search, "$options": "i"}},
-				{"email": bson.M{"$regex": search, "$options": "i"}},
-			},
+	// Build filter; signups held for review are never listed
+	conditions := bson.A{bson.M{"status": bson.M{"$ne": models.StatusPendingReview}}}
+	if params.Status != "" {
+		conditions = append(conditions, bson.M{"status": params.Status})
+	}
+	filter := bson.M{"$and": conditions}
+	if !params.IncludeDeleted {
+		filter["deletedAt"] = bson.M{"$exists": false}
+	}
+	if params.Role != "" {
+		filter["role"] = params.Role
+	}
+	if params.OrganizationID != "" {
+		filter["organizationIds"] = params.OrganizationID
+	}
+	if params.TeamID != "" {
+		filter["teamIds"] = params.TeamID
+	}
+	if params.CreatedAfter != nil || params.CreatedBefore != nil {
+		createdAt := bson.M{}
+		if params.CreatedAfter != nil {
+			createdAt["$gte"] = *params.CreatedAfter
+		}
+		if params.CreatedBefore != nil {
+			createdAt["$lt"] = *params.CreatedBefore
 		}
+		filter["createdAt"] = createdAt
 	}
+	// Search by name or email
+	filter = filterSearch(filter, params.Search, models.UserSearchFields...)
 
 	// Count total
 	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
-		log.Error().Err(err).Msg("Error counting users")
+		logger.Ctx(ctx).Error().Err(err).Msg("Error counting users")
 		return nil, 0, err
 	}
 
+	// Sort by the requested fields, then by ID so pages are stable
+	sortFields, ok := models.UserSortFields[params.SortBy]
+	if !ok {
+		sortFields = models.UserSortFields["name"]
+	}
+	order := params.SortOrder
+	if order != -1 {
+		order = 1
+	}
+	sortBy := bson.D{}
+	for _, field := range sortFields {
+		sortBy = append(sortBy, bson.E{Key: field, Value: order})
+	}
+	sortBy = append(sortBy, bson.E{Key: "_id", Value: order})
+
 	// Set options for pagination and sorting
 	opts := options.Find().
 		SetSkip(int64((page - 1) * limit)).
 		SetLimit(int64(limit)).
-		SetSort(bson.M{"lastName": 1, "firstName": 1})
+		SetSort(sortBy)
+	if projection := fieldProjection(params.Fields, models.UserResponseFields, models.UserVisibilityFields...); projection != nil {
+		opts.SetProjection(projection)
+	}
 
 	// Find users
 	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
-		log.Error().Err(err).Msg("Error finding users")
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding users")
 		return nil, 0, err
 	}
 	defer cursor.Close(ctx)
 
 	// Decode users
 	if err := cursor.All(ctx, &users); err != nil {
-		log.Error().Err(err).Msg("Error decoding users")
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding users")
 		return nil, 0, err
 	}
 
 	return users, total, nil
 }
 
+// FindUsers gets users matching an arbitrary filter with offset pagination
+func (r *UserRepository) FindUsers(ctx context.Context, filter bson.M, skip, limit int64) ([]*models.User, int64, error) {
+	var users []*models.User
+
+	// Count total
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error counting users")
+		return nil, 0, err
+	}
+
+	// Set options for pagination and sorting
+	opts := options.Find().
+		SetSkip(skip).
+		SetLimit(limit).
+		SetSort(bson.M{"createdAt": 1})
+
+	// Find users
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding users")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	// Decode users
+	if err := cursor.All(ctx, &users); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding users")
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// FindBatch gets up to limit users matching a filter, ordered by ID, for
+// keyset iteration over the whole collection
+func (r *UserRepository) FindBatch(ctx context.Context, filter bson.M, limit int64) ([]*models.User, error) {
+	var users []*models.User
+
+	opts := options.Find().
+		SetLimit(limit).
+		SetSort(bson.M{"_id": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding users batch")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &users); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding users batch")
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// FindChangedSince gets up to limit users matching a filter that changed
+// since a time, oldest change first
+func (r *UserRepository) FindChangedSince(ctx context.Context, filter bson.M, since time.Time, limit int64) ([]*models.User, error) {
+	var users []*models.User
+
+	changed := bson.M{"updatedAt": bson.M{"$gte": since}}
+	for key, value := range filter {
+		changed[key] = value
+	}
+	opts := options.Find().
+		SetLimit(limit).
+		SetSort(bson.M{"updatedAt": 1})
+
+	cursor, err := r.collection.Find(ctx, changed, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding changed users")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &users); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding changed users")
+		return nil, err
+	}
+
+	return users, nil
+}
+
 // Update updates a user
 func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
+	return r.update(ctx, user, nil)
+}
+
+// UpdateIfUnmodified updates a user unless it changed since the version last
+// updated at updatedAt was read. It returns mongo.ErrNoDocuments if it did.
+func (r *UserRepository) UpdateIfUnmodified(ctx context.Context, user *models.User, updatedAt time.Time) error {
+	return r.update(ctx, user, &updatedAt)
+}
+
+// update updates a user, conditional on its last update if one is given
+func (r *UserRepository) update(ctx context.Context, user *models.User, updatedAt *time.Time) error {
 	objID, err := primitive.ObjectIDFromHex(user.ID)
 	if err != nil {
 		return err
 	}
 
 	filter := bson.M{"_id": objID}
+	if updatedAt != nil {
+		filter["updatedAt"] = *updatedAt
+	}
+	now := time.Now()
 	update := bson.M{
 		"$set": bson.M{
 			"firstName":      user.FirstName,
@@ -188,17 +336,49 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 			"website":        user.Website,
 			"socialLinks":    user.SocialLinks,
 			"preferences":    user.Preferences,
-			"updatedAt":      time.Now(),
+			"search":         user.SearchKeys(),
+			"updatedAt":      now,
 		},
 	}
 
-	_, err = r.collection.UpdateOne(ctx, filter, update)
+	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Str("id", user.ID).Msg("Error updating user")
+		logger.Ctx(ctx).Error().Err(err).Str("id", user.ID).Msg("Error updating user")
 		return err
 	}
+	if updatedAt != nil && result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	user.UpdatedAt = now
 
-	log.Debug().Str("id", user.ID).Msg("User updated")
+	logger.Ctx(ctx).Debug().Str("id", user.ID).Msg("User updated")
+	return nil
+}
+
+// UpdateIdentity updates the fields of a user owned by the Auth Service
+func (r *UserRepository) UpdateIdentity(ctx context.Context, user *models.User) error {
+	filter := bson.M{"userId": user.UserID}
+	update := bson.M{
+		"$set": bson.M{
+			"email":     user.Email,
+			"firstName": user.FirstName,
+			"lastName":  user.LastName,
+			"role":      user.Role,
+			"search":    user.SearchKeys(),
+			"updatedAt": time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Error updating user identity")
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("userId", user.UserID).Msg("User identity updated")
 	return nil
 }
 
@@ -214,11 +394,155 @@ func (r *UserRepository) UpdateLastLogin(ctx context.Context, userId string, las
 
 	_, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userId).Msg("Error updating user last login")
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Msg("Error updating user last login")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("userId", userId).Msg("User last login updated")
+	return nil
+}
+
+// UpdateLastSeen records when a user was last seen. The update is skipped
+// while the saved time is less than minInterval old, so frequent heartbeats
+// don't each write to the database. updatedAt is left alone, so presence
+// doesn't count as a change to the user in differential sync.
+func (r *UserRepository) UpdateLastSeen(ctx context.Context, userId string, seenAt time.Time, minInterval time.Duration) error {
+	filter := bson.M{
+		"userId": userId,
+		"$or": []bson.M{
+			{"lastSeenAt": bson.M{"$exists": false}},
+			{"lastSeenAt": bson.M{"$lte": seenAt.Add(-minInterval)}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"lastSeenAt": seenAt},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Msg("Error updating user last seen")
 		return err
 	}
 
-	log.Debug().Str("userId", userId).Msg("User last login updated")
+	return nil
+}
+
+// CountSignupsFromIP counts users that signed up from an IP address since a time
+func (r *UserRepository) CountSignupsFromIP(ctx context.Context, ip string, since time.Time) (int64, error) {
+	filter := bson.M{
+		"signupIp":  ip,
+		"createdAt": bson.M{"$gte": since},
+	}
+
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("ip", ip).Msg("Error counting signups from IP")
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountOrganizationMembers counts the users that are members of an
+// organization, and those of them that logged in since a time
+func (r *UserRepository) CountOrganizationMembers(ctx context.Context, orgID string, activeSince time.Time) (total, active int64, err error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"organizationIds": orgID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   nil,
+			"total": bson.M{"$sum": 1},
+			"active": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$gte": bson.A{"$lastLogin", activeSince}}, 1, 0,
+			}}},
+		}}},
+	}
+
+	var counts []struct {
+		Total  int64 `bson:"total"`
+		Active int64 `bson:"active"`
+	}
+	if err := aggregate(ctx, r.collection, pipeline, &counts); err != nil {
+		if !errors.Is(err, db.ErrAggregationUnsupported) {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error counting organization members")
+		}
+		return 0, 0, err
+	}
+	if len(counts) == 0 {
+		return 0, 0, nil
+	}
+
+	return counts[0].Total, counts[0].Active, nil
+}
+
+// CountByField counts users grouped by the value of a field
+func (r *UserRepository) CountByField(ctx context.Context, field string) ([]models.StatsCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$" + field,
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	var counts []models.StatsCount
+	if err := aggregate(ctx, r.collection, pipeline, &counts); err != nil {
+		if !errors.Is(err, db.ErrAggregationUnsupported) {
+			logger.Ctx(ctx).Error().Err(err).Str("field", field).Msg("Error counting users")
+		}
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// CountSignups counts the users created from one time until before
+// another, per bucket of interval
+func (r *UserRepository) CountSignups(ctx context.Context, interval models.StatsInterval, from, to time.Time) ([]models.StatsBucketCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"createdAt": bson.M{"$gte": from, "$lt": to}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   dateTrunc("createdAt", interval),
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	var counts []models.StatsBucketCount
+	if err := aggregate(ctx, r.collection, pipeline, &counts); err != nil {
+		if !errors.Is(err, db.ErrAggregationUnsupported) {
+			logger.Ctx(ctx).Error().Err(err).Msg("Error counting signups")
+		}
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// ResolveSignupReview sets the status and review outcome of a user whose
+// signup is held for review. It returns mongo.ErrNoDocuments if the user
+// doesn't exist or is no longer pending review.
+func (r *UserRepository) ResolveSignupReview(ctx context.Context, userId string, status models.UserStatus, review *models.SignupReview) error {
+	filter := bson.M{
+		"userId": userId,
+		"status": models.StatusPendingReview,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       status,
+			"signupReview": review,
+			"updatedAt":    time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Msg("Error resolving signup review")
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("userId", userId).Str("status", string(status)).Msg("Signup review resolved")
 	return nil
 }
 
@@ -232,12 +556,12 @@ func (r *UserRepository) AddOrganizationToUser(ctx context.Context, userId, orga
 
 	_, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userId).Str("organizationId", organizationId).
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Str("organizationId", organizationId).
 			Msg("Error adding organization to user")
 		return err
 	}
 
-	log.Debug().Str("userId", userId).Str("organizationId", organizationId).
+	logger.Ctx(ctx).Debug().Str("userId", userId).Str("organizationId", organizationId).
 		Msg("Organization added to user")
 	return nil
 }
@@ -246,22 +570,319 @@ func (r *UserRepository) AddOrganizationToUser(ctx context.Context, userId, orga
 func (r *UserRepository) RemoveOrganizationFromUser(ctx context.Context, userId, organizationId string) error {
 	filter := bson.M{"userId": userId}
 	update := bson.M{
-		"$pull": bson.M{"organizationIds": organizationId},
-		"$set":  bson.M{"updatedAt": time.Now()},
+		"$pull":  bson.M{"organizationIds": organizationId},
+		"$unset": bson.M{"customFields." + organizationId: ""},
+		"$set":   bson.M{"updatedAt": time.Now()},
 	}
 
 	_, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userId).Str("organizationId", organizationId).
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Str("organizationId", organizationId).
 			Msg("Error removing organization from user")
 		return err
 	}
 
-	log.Debug().Str("userId", userId).Str("organizationId", organizationId).
+	logger.Ctx(ctx).Debug().Str("userId", userId).Str("organizationId", organizationId).
 		Msg("Organization removed from user")
 	return nil
 }
 
+// UpdateOrganizationMemberships adds an organization to some users and
+// removes it from others with one bulk write
+func (r *UserRepository) UpdateOrganizationMemberships(ctx context.Context, organizationId string, added, removed []string) error {
+	now := time.Now()
+	writes := make([]mongo.WriteModel, 0, len(added)+len(removed))
+	for _, userId := range added {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"userId": userId}).
+			SetUpdate(bson.M{
+				"$addToSet": bson.M{"organizationIds": organizationId},
+				"$set":      bson.M{"updatedAt": now},
+			}))
+	}
+	for _, userId := range removed {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"userId": userId}).
+			SetUpdate(bson.M{
+				"$pull":  bson.M{"organizationIds": organizationId},
+				"$unset": bson.M{"customFields." + organizationId: ""},
+				"$set":   bson.M{"updatedAt": now},
+			}))
+	}
+
+	if _, err := db.BulkWrite(ctx, r.collection, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("organizationId", organizationId).
+			Msg("Error updating organization memberships of users")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("organizationId", organizationId).Int("added", len(added)).Int("removed", len(removed)).
+		Msg("Organization memberships of users updated")
+	return nil
+}
+
+// SetFavorites replaces a user's favorites
+func (r *UserRepository) SetFavorites(ctx context.Context, userId string, favorites []models.Favorite) error {
+	filter := bson.M{"userId": userId}
+	update := bson.M{
+		"$set": bson.M{
+			"favorites": favorites,
+			"updatedAt": time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Msg("Error setting user favorites")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("userId", userId).Int("count", len(favorites)).Msg("User favorites set")
+	return nil
+}
+
+// CompleteOnboardingStep records an onboarding step as done, unless it
+// already is. It reports whether the step was recorded.
+func (r *UserRepository) CompleteOnboardingStep(ctx context.Context, userId string, state models.OnboardingStepState) (bool, error) {
+	filter := bson.M{
+		"userId":                userId,
+		"onboarding.steps.step": bson.M{"$ne": state.Step},
+	}
+	update := bson.M{
+		"$push": bson.M{"onboarding.steps": state},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Str("step", string(state.Step)).
+			Msg("Error completing onboarding step")
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// CompleteOnboarding records that a user completed onboarding, unless they
+// already have. It reports whether completion was recorded, so it is only
+// announced once.
+func (r *UserRepository) CompleteOnboarding(ctx context.Context, userId string, completedAt time.Time) (bool, error) {
+	filter := bson.M{
+		"userId":                 userId,
+		"onboarding.completedAt": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"onboarding.completedAt": completedAt},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Msg("Error completing onboarding")
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// SetOnboardingDismissed dismisses a user's onboarding checklist, or brings
+// it back when dismissedAt is nil
+func (r *UserRepository) SetOnboardingDismissed(ctx context.Context, userId string, dismissedAt *time.Time) error {
+	filter := bson.M{"userId": userId}
+	update := bson.M{"$unset": bson.M{"onboarding.dismissedAt": ""}}
+	if dismissedAt != nil {
+		update = bson.M{"$set": bson.M{"onboarding.dismissedAt": *dismissedAt}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Msg("Error setting onboarding dismissal")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SetTwoFactor records a user's two-factor enrollment, unless a later change
+// is already recorded. It reports whether the enrollment was recorded.
+func (r *UserRepository) SetTwoFactor(ctx context.Context, userId string, status models.TwoFactorStatus) (bool, error) {
+	filter := bson.M{
+		"userId": userId,
+		"$or": bson.A{
+			bson.M{"twoFactor.updatedAt": bson.M{"$exists": false}},
+			bson.M{"twoFactor.updatedAt": bson.M{"$lt": status.UpdatedAt}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"twoFactor": status},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Msg("Error setting user two-factor status")
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// SetPreferenceOverrides records the preferences a user set themselves,
+// without touching updatedAt. It backfills users created before overrides
+// were recorded.
+func (r *UserRepository) SetPreferenceOverrides(ctx context.Context, userId string, overrides []string) error {
+	filter := bson.M{"userId": userId}
+	update := bson.M{
+		"$set": bson.M{
+			"preferences.overrides": overrides,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Msg("Error setting user preference overrides")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SetSearchKeys sets the keys searches match a user against
+func (r *UserRepository) SetSearchKeys(ctx context.Context, userId string, keys models.SearchKeys) error {
+	filter := bson.M{"userId": userId}
+	update := bson.M{
+		"$set": bson.M{
+			"search": keys,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Msg("Error setting user search keys")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SetCustomFields replaces a user's custom profile field values for an
+// organization, removing them when there are none
+func (r *UserRepository) SetCustomFields(ctx context.Context, userId, organizationId string, values map[string]interface{}) error {
+	filter := bson.M{"userId": userId}
+	path := "customFields." + organizationId
+
+	var update bson.M
+	if len(values) == 0 {
+		update = bson.M{
+			"$unset": bson.M{path: ""},
+			"$set":   bson.M{"updatedAt": time.Now()},
+		}
+	} else {
+		update = bson.M{"$set": bson.M{path: values, "updatedAt": time.Now()}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Str("organizationId", organizationId).
+			Msg("Error setting user custom fields")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("userId", userId).Str("organizationId", organizationId).
+		Msg("User custom fields set")
+	return nil
+}
+
+// SetPendingEmail records the email a user asked to change to
+func (r *UserRepository) SetPendingEmail(ctx context.Context, userId, email string, requestedAt time.Time) error {
+	filter := bson.M{"userId": userId}
+	update := bson.M{
+		"$set": bson.M{
+			"pendingEmail":           email,
+			"emailChangeRequestedAt": requestedAt,
+			"updatedAt":              time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Msg("Error setting user pending email")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("userId", userId).Msg("User pending email set")
+	return nil
+}
+
+// ClearPendingEmail removes a user's pending email change
+func (r *UserRepository) ClearPendingEmail(ctx context.Context, userId string) error {
+	filter := bson.M{"userId": userId}
+	update := bson.M{
+		"$unset": bson.M{"pendingEmail": "", "emailChangeRequestedAt": ""},
+		"$set":   bson.M{"updatedAt": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Msg("Error clearing user pending email")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("userId", userId).Msg("User pending email cleared")
+	return nil
+}
+
+// ConfirmEmailChange replaces a user's email with their pending email in one
+// update, so the unique email index decides between users racing for the
+// same email. It returns mongo.ErrNoDocuments when the user's pending email
+// is no longer email, and ErrEmailTaken when another user has it.
+func (r *UserRepository) ConfirmEmailChange(ctx context.Context, userId, email string) error {
+	filter := bson.M{"userId": userId, "pendingEmail": email}
+	update := bson.M{
+		"$set": bson.M{
+			"email":        email,
+			"search.email": models.SearchKeysOf(email),
+			"updatedAt":    time.Now(),
+		},
+		"$unset": bson.M{"pendingEmail": "", "emailChangeRequestedAt": ""},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrEmailTaken
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Msg("Error confirming user email change")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("userId", userId).Msg("User email change confirmed")
+	return nil
+}
+
 // AddTeamToUser adds a team to a user
 func (r *UserRepository) AddTeamToUser(ctx context.Context, userId, teamId string) error {
 	filter := bson.M{"userId": userId}
@@ -272,12 +893,12 @@ func (r *UserRepository) AddTeamToUser(ctx context.Context, userId, teamId strin
 
 	_, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userId).Str("teamId", teamId).
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Str("teamId", teamId).
 			Msg("Error adding team to user")
 		return err
 	}
 
-	log.Debug().Str("userId", userId).Str("teamId", teamId).
+	logger.Ctx(ctx).Debug().Str("userId", userId).Str("teamId", teamId).
 		Msg("Team added to user")
 	return nil
 }
@@ -292,16 +913,48 @@ func (r *UserRepository) RemoveTeamFromUser(ctx context.Context, userId, teamId
 
 	_, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userId).Str("teamId", teamId).
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Str("teamId", teamId).
 			Msg("Error removing team from user")
 		return err
 	}
 
-	log.Debug().Str("userId", userId).Str("teamId", teamId).
+	logger.Ctx(ctx).Debug().Str("userId", userId).Str("teamId", teamId).
 		Msg("Team removed from user")
 	return nil
 }
 
+// UpdateTeamMemberships adds a team to some users and removes it from others
+// with one bulk write
+func (r *UserRepository) UpdateTeamMemberships(ctx context.Context, teamId string, added, removed []string) error {
+	now := time.Now()
+	writes := make([]mongo.WriteModel, 0, len(added)+len(removed))
+	for _, userId := range added {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"userId": userId}).
+			SetUpdate(bson.M{
+				"$addToSet": bson.M{"teamIds": teamId},
+				"$set":      bson.M{"updatedAt": now},
+			}))
+	}
+	for _, userId := range removed {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"userId": userId}).
+			SetUpdate(bson.M{
+				"$pull": bson.M{"teamIds": teamId},
+				"$set":  bson.M{"updatedAt": now},
+			}))
+	}
+
+	if _, err := db.BulkWrite(ctx, r.collection, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamId).Msg("Error updating team memberships of users")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("teamId", teamId).Int("added", len(added)).Int("removed", len(removed)).
+		Msg("Team memberships of users updated")
+	return nil
+}
+
 // Delete deletes a user (soft delete by updating status)
 func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -309,20 +962,76 @@ func (r *UserRepository) Delete(ctx context.Context, id string) error {
 		return err
 	}
 
+	now := time.Now()
 	filter := bson.M{"_id": objID}
 	update := bson.M{
 		"$set": bson.M{
 			"status":    models.StatusInactive,
-			"updatedAt": time.Now(),
+			"deletedAt": now,
+			"updatedAt": now,
 		},
 	}
 
 	_, err = r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Error deleting user")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error deleting user")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", id).Msg("User deleted (soft delete)")
+	return nil
+}
+
+// Restore restores a soft deleted user as active. It returns
+// mongo.ErrNoDocuments if the user isn't soft deleted.
+func (r *UserRepository) Restore(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objID, "deletedAt": bson.M{"$exists": true}}
+	update := bson.M{
+		"$set": bson.M{
+			"status":    models.StatusActive,
+			"updatedAt": time.Now(),
+		},
+		"$unset": bson.M{
+			"deletedAt": "",
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error restoring user")
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", id).Msg("User restored")
+	return nil
+}
+
+// Purge removes a soft deleted user for good. It returns
+// mongo.ErrNoDocuments if the user isn't soft deleted.
+func (r *UserRepository) Purge(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objID, "deletedAt": bson.M{"$exists": true}}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error purging user")
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
 
-	log.Debug().Str("id", id).Msg("User deleted (soft delete)")
+	logger.Ctx(ctx).Debug().Str("id", id).Msg("User purged")
 	return nil
 }
@@ -0,0 +1,40 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/testsupport"
+)
+
+func TestGetUsersNeverListsPendingReview(t *testing.T) {
+	f := testsupport.New(t)
+	active := f.SeedUser(func(u *models.User) { u.Status = models.StatusActive })
+	f.SeedUser(func(u *models.User) { u.Status = models.StatusPendingReview })
+
+	tests := []struct {
+		status models.UserStatus
+		search string
+		want   []string
+	}{
+		{want: []string{active.UserID}},
+		{status: models.StatusActive, want: []string{active.UserID}},
+		{status: models.StatusPendingReview, want: []string{}},
+		{status: models.StatusPendingReview, search: "user", want: []string{}},
+	}
+	for _, tt := range tests {
+		users, total, err := f.Users.GetUsers(context.Background(), 1, 100,
+			models.UserListFilter{Status: tt.status, Search: tt.search})
+		if err != nil {
+			t.Fatalf("GetUsers(%q): %v", tt.status, err)
+		}
+		got := make([]string, 0, len(users))
+		for _, user := range users {
+			got = append(got, user.UserID)
+		}
+		if !equalIDs(got, tt.want) || total != int64(len(tt.want)) {
+			t.Errorf("status %q search %q = %v (total %d), want %v", tt.status, tt.search, got, total, tt.want)
+		}
+	}
+}
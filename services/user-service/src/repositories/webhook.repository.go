@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WebhookRepository is a repository for webhook subscriptions
+type WebhookRepository struct {
+	collection db.Collection
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(store db.Storage) *WebhookRepository {
+	return &WebhookRepository{
+		collection: store.GetCollection(db.WebhooksCollection),
+	}
+}
+
+// Create creates a new webhook subscription
+func (r *WebhookRepository) Create(ctx context.Context, webhook *models.WebhookSubscription) error {
+	_, err := r.collection.InsertOne(ctx, webhook)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", webhook.OrganizationID).Msg("Error creating webhook")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", webhook.ID).Str("orgId", webhook.OrganizationID).Msg("Webhook created")
+	return nil
+}
+
+// GetByID gets a webhook subscription of an organization by ID
+func (r *WebhookRepository) GetByID(ctx context.Context, orgID, id string) (*models.WebhookSubscription, error) {
+	var webhook models.WebhookSubscription
+
+	filter := bson.M{"_id": id, "organizationId": orgID}
+	err := r.collection.FindOne(ctx, filter).Decode(&webhook)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, mongo.ErrNoDocuments
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Error getting webhook by ID")
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// GetByOrganization gets the webhook subscriptions of an organization
+func (r *WebhookRepository) GetByOrganization(ctx context.Context, orgID string, activeOnly bool) ([]*models.WebhookSubscription, error) {
+	var webhooks []*models.WebhookSubscription
+
+	filter := bson.M{"organizationId": orgID}
+	if activeOnly {
+		filter["active"] = true
+	}
+	opts := options.Find().SetSort(bson.M{"createdAt": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Error finding webhooks")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &webhooks); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding webhooks")
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// Update updates a webhook subscription
+func (r *WebhookRepository) Update(ctx context.Context, webhook *models.WebhookSubscription) error {
+	filter := bson.M{"_id": webhook.ID, "organizationId": webhook.OrganizationID}
+	update := bson.M{
+		"$set": bson.M{
+			"url":         webhook.URL,
+			"secret":      webhook.Secret,
+			"events":      webhook.Events,
+			"description": webhook.Description,
+			"active":      webhook.Active,
+			"updatedAt":   webhook.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", webhook.ID).Msg("Error updating webhook")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", webhook.ID).Msg("Webhook updated")
+	return nil
+}
+
+// Delete deletes a webhook subscription of an organization
+func (r *WebhookRepository) Delete(ctx context.Context, orgID, id string) error {
+	filter := bson.M{"_id": id, "organizationId": orgID}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("orgId", orgID).Msg("Error deleting webhook")
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	logger.Ctx(ctx).Debug().Str("id", id).Str("orgId", orgID).Msg("Webhook deleted")
+	return nil
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WebhookDeliveryRepository is a repository for webhook delivery logs
+type WebhookDeliveryRepository struct {
+	collection db.Collection
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository
+func NewWebhookDeliveryRepository(store db.Storage) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{
+		collection: store.GetCollection(db.WebhookDeliveriesCollection),
+	}
+}
+
+// Create creates a new webhook delivery
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, delivery *models.WebhookDelivery) error {
+	_, err := r.collection.InsertOne(ctx, delivery)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("webhookId", delivery.WebhookID).Str("eventType", delivery.EventType).
+			Msg("Error creating webhook delivery")
+		return err
+	}
+
+	return nil
+}
+
+// GetByWebhook lists the deliveries of a webhook, newest first
+func (r *WebhookDeliveryRepository) GetByWebhook(ctx context.Context, orgID, webhookID string, page, limit int) ([]*models.WebhookDelivery, int64, error) {
+	var deliveries []*models.WebhookDelivery
+
+	filter := bson.M{"organizationId": orgID, "webhookId": webhookID}
+
+	// Count total
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("webhookId", webhookID).Msg("Error counting webhook deliveries")
+		return nil, 0, err
+	}
+
+	// Set options for pagination and sorting
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"createdAt": -1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("webhookId", webhookID).Msg("Error finding webhook deliveries")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding webhook deliveries")
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// GetDue gets pending deliveries whose next attempt is due, oldest first
+func (r *WebhookDeliveryRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+
+	filter := bson.M{
+		"status":        models.WebhookDeliveryPending,
+		"nextAttemptAt": bson.M{"$lte": now},
+	}
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"nextAttemptAt": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error finding due webhook deliveries")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Error decoding due webhook deliveries")
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// RecordAttempt appends an attempt to a delivery's log and stores its new status
+func (r *WebhookDeliveryRepository) RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery, attempt models.WebhookAttempt) error {
+	set := bson.M{
+		"status": delivery.Status,
+	}
+	if delivery.CompletedAt != nil {
+		set["completedAt"] = delivery.CompletedAt
+	}
+
+	update := bson.M{
+		"$push": bson.M{"attempts": attempt},
+		"$set":  set,
+	}
+	if delivery.NextAttemptAt != nil {
+		set["nextAttemptAt"] = delivery.NextAttemptAt
+	} else {
+		update["$unset"] = bson.M{"nextAttemptAt": ""}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": delivery.ID}, update)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", delivery.ID).Msg("Error recording webhook delivery attempt")
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Activity feed page sizes
+const (
+	DefaultActivityLimit = 20
+	MaxActivityLimit     = 100
+)
+
+// ActivityService records published events into user activity feeds
+type ActivityService struct {
+	activityRepo *repositories.ActivityRepository
+	orgRepo      repositories.OrgStore
+	teamRepo     repositories.TeamStore
+}
+
+// NewActivityService creates a new activity service
+func NewActivityService(
+	activityRepo *repositories.ActivityRepository,
+	orgRepo repositories.OrgStore,
+	teamRepo repositories.TeamStore,
+) *ActivityService {
+	return &ActivityService{
+		activityRepo: activityRepo,
+		orgRepo:      orgRepo,
+		teamRepo:     teamRepo,
+	}
+}
+
+// HandleEvent records a published event as an activity of the user it is
+// about. Events that aren't user activities are ignored.
+func (s *ActivityService) HandleEvent(ctx context.Context, event kafka.Event) {
+	activity, err := s.activityFromEvent(ctx, event)
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("eventId", event.ID).Str("eventType", string(event.Type)).
+			Msg("Failed to build activity from event")
+		return
+	}
+	if activity == nil {
+		return
+	}
+
+	if err := s.activityRepo.Create(ctx, activity); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("eventId", event.ID).Msg("Failed to record activity")
+	}
+}
+
+// activityFromEvent builds the activity of an event, or nil for events that
+// aren't user activities
+func (s *ActivityService) activityFromEvent(ctx context.Context, event kafka.Event) (*models.Activity, error) {
+	switch event.Type {
+	case kafka.OrganizationMemberAdded:
+		var data kafka.OrganizationMemberAddedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		activity := models.NewActivity(data.UserID, models.ActivityOrganizationJoined, data.OrgID, event.ID, event.Time)
+		activity.OrganizationName = data.OrgName
+		activity.ActorID = data.InvitedBy
+		activity.Role = data.Role
+		return activity, nil
+
+	case kafka.OrganizationMemberUpdated:
+		var data kafka.OrganizationMemberUpdatedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		activity := models.NewActivity(data.UserID, models.ActivityOrganizationRoleChanged, data.OrgID, event.ID, event.Time)
+		activity.OrganizationName = data.OrgName
+		activity.ActorID = data.UpdatedBy
+		activity.Role = data.Role
+		return activity, nil
+
+	case kafka.OrganizationMemberRemoved:
+		var data kafka.OrganizationMemberRemovedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		activity := models.NewActivity(data.UserID, models.ActivityOrganizationLeft, data.OrgID, event.ID, event.Time)
+		activity.OrganizationName = data.OrgName
+		activity.ActorID = data.RemovedBy
+		return activity, nil
+
+	case kafka.TeamCreated:
+		var data models.TeamResponse
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		if data.CreatedBy == "" {
+			// No creator to attribute the team to
+			return nil, nil
+		}
+		activity := models.NewActivity(data.CreatedBy, models.ActivityTeamCreated, data.OrganizationID, event.ID, event.Time)
+		activity.TeamID = data.ID
+		activity.TeamName = data.Name
+		return activity, nil
+
+	case kafka.TeamMemberAdded:
+		var data kafka.TeamMemberAddedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		return s.teamActivity(ctx, event, data.TeamID, data.TeamName, data.UserID, models.ActivityTeamJoined, data.InvitedBy, data.Role)
+
+	case kafka.TeamMemberUpdated:
+		var data kafka.TeamMemberUpdatedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		return s.teamActivity(ctx, event, data.TeamID, data.TeamName, data.UserID, models.ActivityTeamRoleChanged, data.UpdatedBy, data.Role)
+
+	case kafka.TeamMemberRemoved:
+		var data kafka.TeamMemberRemovedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		return s.teamActivity(ctx, event, data.TeamID, data.TeamName, data.UserID, models.ActivityTeamLeft, data.RemovedBy, "")
+	}
+
+	return nil, nil
+}
+
+// teamActivity builds the activity of a team member event. Team member events
+// only carry the team, so the organization is looked up from it.
+func (s *ActivityService) teamActivity(ctx context.Context, event kafka.Event, teamID, teamName, userID string, activityType models.ActivityType, actorID, role string) (*models.Activity, error) {
+	team, err := s.teamRepo.GetByID(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := models.NewActivity(userID, activityType, team.OrganizationID, event.ID, event.Time)
+	activity.TeamID = teamID
+	activity.TeamName = teamName
+	activity.ActorID = actorID
+	activity.Role = role
+	return activity, nil
+}
+
+// GetUserActivity gets a page of a user's own activity feed, newest first
+func (s *ActivityService) GetUserActivity(ctx context.Context, userID, cursor string, limit int) (*models.ActivityResponse, error) {
+	return s.page(ctx, models.ActivityFilter{UserID: userID}, cursor, limit)
+}
+
+// GetOrganizationActivity gets a page of the activity of an organization's
+// members, newest first, optionally of one member only
+func (s *ActivityService) GetOrganizationActivity(ctx context.Context, orgID, memberID, cursor string, limit int, userID string) (*models.ActivityResponse, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for activity")
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgViewActivity) {
+		return nil, insufficientPermissions("view organization activity")
+	}
+
+	return s.page(ctx, models.ActivityFilter{UserID: memberID, OrganizationID: orgID}, cursor, limit)
+}
+
+// page gets a page of the activities matching a filter
+func (s *ActivityService) page(ctx context.Context, filter models.ActivityFilter, cursor string, limit int) (*models.ActivityResponse, error) {
+	var after *models.TimelineCursor
+	if cursor != "" {
+		var err error
+		if after, err = models.ParseTimelineCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fetch one extra activity to know if there is a next page
+	activities, err := s.activityRepo.Find(ctx, filter, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.ActivityResponse{
+		Activities: activities,
+		Limit:      limit,
+	}
+	if len(activities) > limit {
+		response.Activities = activities[:limit]
+		last := response.Activities[limit-1]
+		response.NextCursor = models.TimelineCursor{OccurredAt: last.OccurredAt, ID: last.ID}.Encode()
+	}
+	if response.Activities == nil {
+		response.Activities = []*models.Activity{}
+	}
+
+	return response, nil
+}
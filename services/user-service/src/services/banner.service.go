@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// BannerService manages the platform banner. The banner is attached to every
+// response, so it is served from memory and reloaded from the database
+// periodically; changes reach every instance within the refresh interval.
+type BannerService struct {
+	bannerRepo *repositories.BannerRepository
+	config     *config.BannerConfig
+
+	mu     sync.RWMutex
+	banner *models.Banner
+}
+
+// NewBannerService creates a new banner service
+func NewBannerService(bannerRepo *repositories.BannerRepository, cfg *config.BannerConfig) *BannerService {
+	return &BannerService{
+		bannerRepo: bannerRepo,
+		config:     cfg,
+	}
+}
+
+// Current returns the banner to show now, or nil if there is none
+func (s *BannerService) Current() *models.Banner {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.banner == nil || !s.banner.IsActive(time.Now()) {
+		return nil
+	}
+	return s.banner
+}
+
+// GetBanner gets the platform banner, including one that isn't active yet
+// or has ended
+func (s *BannerService) GetBanner(ctx context.Context) (*models.Banner, error) {
+	banner, err := s.bannerRepo.Get(ctx)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NotFound("BANNER_NOT_FOUND", "banner not found")
+		}
+		return nil, err
+	}
+
+	return banner, nil
+}
+
+// SetBanner sets the platform banner
+func (s *BannerService) SetBanner(ctx context.Context, req models.UpdateBannerRequest, userID string) (*models.Banner, error) {
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return nil, apperrors.InvalidField("endsAt", "endsAt must be after startsAt")
+	}
+
+	banner := models.NewBanner(req, userID)
+	if err := s.bannerRepo.Save(ctx, banner); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to save platform banner")
+		return nil, err
+	}
+
+	s.set(banner)
+	logger.Ctx(ctx).Info().Str("userId", userID).Str("severity", string(banner.Severity)).Msg("Platform banner set")
+	return banner, nil
+}
+
+// ClearBanner removes the platform banner
+func (s *BannerService) ClearBanner(ctx context.Context, userID string) error {
+	if err := s.bannerRepo.Delete(ctx); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("BANNER_NOT_FOUND", "banner not found")
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to delete platform banner")
+		return err
+	}
+
+	s.set(nil)
+	logger.Ctx(ctx).Info().Str("userId", userID).Msg("Platform banner cleared")
+	return nil
+}
+
+// RunRefresher reloads the banner from the database every refresh interval
+// until ctx is cancelled
+func (s *BannerService) RunRefresher(ctx context.Context) {
+	ticker := time.NewTicker(s.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		s.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh reloads the banner, keeping the cached one if the database fails
+func (s *BannerService) refresh(ctx context.Context) {
+	banner, err := s.bannerRepo.Get(ctx)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			s.set(nil)
+		}
+		return
+	}
+
+	s.set(banner)
+}
+
+// set replaces the cached banner
+func (s *BannerService) set(banner *models.Banner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.banner = banner
+}
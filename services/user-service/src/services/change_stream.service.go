@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/pkg/cache"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// errChangeStreamHistoryLost is the MongoDB error code of a change stream
+// resumed from a token that is no longer in the oplog
+const errChangeStreamHistoryLost = 286
+
+// watchedCollection is a collection whose changes are published, with the
+// kind of entity its documents are and the event their changes publish
+type watchedCollection struct {
+	collection string
+	kind       string
+	eventType  kafka.EventType
+}
+
+// watchedCollections are the collections watched for changes
+var watchedCollections = []watchedCollection{
+	{collection: db.UsersCollection, kind: "user", eventType: kafka.UserChanged},
+	{collection: db.TeamsCollection, kind: "team", eventType: kafka.TeamChanged},
+	{collection: db.OrganizationsCollection, kind: "organization", eventType: kafka.OrganizationChanged},
+}
+
+// changeStreamPipeline limits change streams to the operations that change
+// a document
+var changeStreamPipeline = mongo.Pipeline{
+	{{Key: "$match", Value: bson.M{
+		"operationType": bson.M{"$in": []string{"insert", "update", "replace", "delete"}},
+	}}},
+}
+
+// changeEvent is the part of a change stream event that is published
+type changeEvent struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID interface{} `bson:"_id"`
+	} `bson:"documentKey"`
+	UpdateDescription struct {
+		UpdatedFields bson.M   `bson:"updatedFields"`
+		RemovedFields []string `bson:"removedFields"`
+	} `bson:"updateDescription"`
+	ClusterTime primitive.Timestamp `bson:"clusterTime"`
+}
+
+// ChangeStreamService watches user, team and organization documents on
+// MongoDB change streams. Every change, including those written outside the
+// service by migrations or manual fixes, invalidates the entity in the shared
+// cache and is published as a changed event. Each stream's resume token is
+// saved once its change is published, so a restart resumes after it and a
+// change may be published twice but isn't skipped.
+type ChangeStreamService struct {
+	store  db.Storage
+	tokens *repositories.ChangeStreamRepository
+	events kafka.EventPublisher
+	cache  cache.Invalidator
+	config *config.ChangeStreamConfig
+}
+
+// NewChangeStreamService creates a new change stream service
+func NewChangeStreamService(store db.Storage, tokens *repositories.ChangeStreamRepository, events kafka.EventPublisher, invalidator cache.Invalidator, cfg *config.ChangeStreamConfig) *ChangeStreamService {
+	return &ChangeStreamService{
+		store:  store,
+		tokens: tokens,
+		events: events,
+		cache:  invalidator,
+		config: cfg,
+	}
+}
+
+// RunListener watches the collections until ctx is done, if change streams
+// are enabled. A stream that fails is reopened from its resume token after
+// the retry delay. Run it on one instance only, so each change is published
+// once.
+func (s *ChangeStreamService) RunListener(ctx context.Context) {
+	if !s.config.Enabled {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, watched := range watchedCollections {
+		wg.Add(1)
+		go func(watched watchedCollection) {
+			defer wg.Done()
+			s.watch(ctx, watched)
+		}(watched)
+	}
+	wg.Wait()
+}
+
+// watch reads the change stream of a collection until ctx is done
+func (s *ChangeStreamService) watch(ctx context.Context, watched watchedCollection) {
+	log := logger.Ctx(ctx).With().Str("collection", watched.collection).Logger()
+
+	for {
+		err := s.stream(ctx, watched)
+		if ctx.Err() != nil {
+			return
+		}
+
+		var serverErr mongo.ServerError
+		if errors.As(err, &serverErr) && serverErr.HasErrorCode(errChangeStreamHistoryLost) {
+			// The changes since the token are gone; start from the current
+			// change rather than failing forever
+			log.Warn().Err(err).Msg("Change stream history lost, changes since the last resume token were missed")
+			if err := s.tokens.DeleteResumeToken(ctx, watched.collection); err != nil {
+				log.Error().Err(err).Msg("Failed to reset change stream resume token")
+			}
+		} else {
+			log.Error().Err(err).Dur("retryIn", s.config.RetryDelay).Msg("Change stream failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.config.RetryDelay):
+		}
+	}
+}
+
+// stream opens the change stream of a collection after its resume token and
+// handles changes until the stream or ctx ends
+func (s *ChangeStreamService) stream(ctx context.Context, watched watchedCollection) error {
+	resumeToken, err := s.tokens.GetResumeToken(ctx, watched.collection)
+	if err != nil {
+		return err
+	}
+
+	opts := options.ChangeStream()
+	if resumeToken != nil {
+		opts.SetStartAfter(resumeToken)
+	}
+
+	changes, err := db.Watch(ctx, s.store.GetCollection(watched.collection), changeStreamPipeline, opts)
+	if err != nil {
+		return err
+	}
+	defer changes.Close(context.Background())
+
+	logger.Ctx(ctx).Info().Str("collection", watched.collection).Bool("resumed", resumeToken != nil).Msg("Change stream opened")
+
+	for changes.Next(ctx) {
+		var change changeEvent
+		if err := changes.Decode(&change); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("collection", watched.collection).Msg("Failed to decode change stream event")
+		} else if err := s.handle(ctx, watched, change); err != nil {
+			// Keep the previous token, so the change is handled again
+			// when the stream is reopened
+			return err
+		}
+
+		if err := s.tokens.SaveResumeToken(ctx, watched.collection, changes.ResumeToken()); err != nil {
+			return err
+		}
+	}
+
+	return changes.Err()
+}
+
+// handle invalidates the changed entity and publishes its changed event.
+// Failing to invalidate is logged; failing to publish is returned.
+func (s *ChangeStreamService) handle(ctx context.Context, watched watchedCollection, change changeEvent) error {
+	id := documentID(change.DocumentKey.ID)
+
+	if err := s.cache.Invalidate(ctx, watched.kind, id); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("kind", watched.kind).Str("id", id).Msg("Failed to invalidate cached entity")
+	}
+
+	updatedFields := make([]string, 0, len(change.UpdateDescription.UpdatedFields))
+	for field := range change.UpdateDescription.UpdatedFields {
+		updatedFields = append(updatedFields, field)
+	}
+	sort.Strings(updatedFields)
+
+	payload := kafka.DocumentChangedV1{
+		ID:            id,
+		Operation:     change.OperationType,
+		UpdatedFields: updatedFields,
+		RemovedFields: change.UpdateDescription.RemovedFields,
+		ChangedAt:     time.Unix(int64(change.ClusterTime.T), 0).UTC(),
+	}
+
+	// Team events have their own topic; organization events share the user
+	// topic
+	publish := s.events.PublishUserEvent
+	if watched.eventType == kafka.TeamChanged {
+		publish = s.events.PublishTeamEvent
+	}
+	if err := publish(ctx, watched.eventType, payload, id); err != nil {
+		return fmt.Errorf("failed to publish %s event for %s: %w", watched.eventType, id, err)
+	}
+	return nil
+}
+
+// documentID formats the _id of a changed document as the entity ID
+func documentID(id interface{}) string {
+	switch id := id.(type) {
+	case primitive.ObjectID:
+		return id.Hex()
+	case string:
+		return id
+	default:
+		return fmt.Sprint(id)
+	}
+}
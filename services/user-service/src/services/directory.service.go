@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+)
+
+// DirectoryService is a service for the public directory of the
+// organizations that opted in to being discovered
+type DirectoryService struct {
+	orgRepo repositories.OrgStore
+}
+
+// NewDirectoryService creates a new directory service
+func NewDirectoryService(orgRepo repositories.OrgStore) *DirectoryService {
+	return &DirectoryService{
+		orgRepo: orgRepo,
+	}
+}
+
+// ListOrganizations lists a page of the discoverable organizations as seen by
+// a user, who is empty for anonymous visitors
+func (s *DirectoryService) ListOrganizations(ctx context.Context, filter models.DirectoryFilter, page, limit int, userID string) (*models.DirectoryResponse, error) {
+	// Validate pagination
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	orgs, total, err := s.orgRepo.ListDiscoverable(ctx, filter, page, limit)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Int("page", page).Int("limit", limit).
+			Msg("Failed to list directory organizations")
+		return nil, err
+	}
+
+	response := &models.DirectoryResponse{
+		Organizations: make([]models.DirectoryOrganization, len(orgs)),
+		Total:         total,
+		Page:          page,
+		Limit:         limit,
+		TotalPages:    (total + int64(limit) - 1) / int64(limit),
+	}
+	for i, org := range orgs {
+		response.Organizations[i] = org.ToDirectoryEntry(userID)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/directory"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Directory import errors
+var (
+	// ErrDirectoryUnavailable is returned when a workspace directory can't be read
+	ErrDirectoryUnavailable = apperrors.Unavailable("DIRECTORY_UNAVAILABLE", "workspace directory could not be read")
+	// ErrDirectoryTooLarge is returned when a workspace directory has more members than an import reads
+	ErrDirectoryTooLarge = apperrors.Validation("DIRECTORY_TOO_LARGE", "workspace directory has too many members to import")
+)
+
+// emailBatchSize is how many emails are looked up at a time
+const emailBatchSize = 500
+
+// DirectoryImportService imports the members of Google Workspace and Slack
+// directories into organizations. Imports are previewed first and applied as
+// a bulk membership operation; members without an account are invited.
+type DirectoryImportService struct {
+	userRepo   repositories.UserStore
+	orgRepo    repositories.OrgStore
+	orgService *OrganizationService
+	config     *config.DirectoryImportConfig
+}
+
+// NewDirectoryImportService creates a new directory import service
+func NewDirectoryImportService(
+	userRepo repositories.UserStore,
+	orgRepo repositories.OrgStore,
+	orgService *OrganizationService,
+	cfg *config.DirectoryImportConfig,
+) *DirectoryImportService {
+	return &DirectoryImportService{
+		userRepo:   userRepo,
+		orgRepo:    orgRepo,
+		orgService: orgService,
+		config:     cfg,
+	}
+}
+
+// Import previews, or applies if requested, an import of a workspace
+// directory into an organization
+func (s *DirectoryImportService) Import(ctx context.Context, orgID string, source models.DirectoryImportSource, req models.DirectoryImportRequest, userID string) (*models.DirectoryImportResponse, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for directory import")
+		return nil, err
+	}
+
+	// Check permissions
+	if !org.Can(userID, models.PermOrgManageMembers) {
+		return nil, insufficientPermissions("import organization members")
+	}
+
+	role := req.Role
+	if role == "" {
+		role = org.Settings.DefaultUserRole
+	}
+	if role == "" || role == models.OrgRoleOwner {
+		role = models.OrgRoleMember
+	}
+
+	members, err := s.fetch(ctx, source, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the selected members, once per email
+	var emails []string
+	selected := make([]directory.Member, 0, len(members))
+	seen := make(map[string]bool, len(members))
+	for _, member := range members {
+		member.Email = strings.ToLower(strings.TrimSpace(member.Email))
+		if member.Email != "" {
+			if seen[member.Email] || !req.Selects(member.Email) {
+				continue
+			}
+			seen[member.Email] = true
+			emails = append(emails, member.Email)
+		} else if len(req.Emails) > 0 {
+			continue
+		}
+		selected = append(selected, member)
+	}
+
+	users, err := usersByEmail(ctx, s.userRepo, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.DirectoryImportResponse{
+		OrganizationID: org.ID,
+		Source:         source,
+		Applied:        req.Apply,
+		Entries:        []models.DirectoryImportEntry{},
+	}
+	for _, member := range selected {
+		entry := s.plan(org, member, users[member.Email], role)
+		if req.Apply {
+			s.apply(ctx, org, &entry, userID)
+		}
+		resp.Add(entry)
+	}
+
+	if req.Apply {
+		logger.Ctx(ctx).Info().Str("orgId", org.ID).Str("source", string(source)).Str("userId", userID).
+			Int("added", resp.Summary.Add).Int("invited", resp.Summary.Invite).Int("failed", resp.Summary.Failed).
+			Msg("Directory import applied")
+	}
+
+	return resp, nil
+}
+
+// fetch reads the active members of a workspace directory
+func (s *DirectoryImportService) fetch(ctx context.Context, source models.DirectoryImportSource, req models.DirectoryImportRequest) ([]directory.Member, error) {
+	var members []directory.Member
+	var err error
+	switch source {
+	case models.DirectoryImportGoogle:
+		members, err = directory.Google(ctx, s.config.GoogleAPIURL, req.AccessToken, req.Domain, s.config.MaxMembers)
+	case models.DirectoryImportSlack:
+		members, err = directory.Slack(ctx, s.config.SlackAPIURL, req.AccessToken, s.config.MaxMembers)
+	default:
+		return nil, apperrors.InvalidField("source", "must be google or slack")
+	}
+
+	switch {
+	case err == nil:
+		return members, nil
+	case errors.Is(err, directory.ErrUnauthorized):
+		return nil, apperrors.InvalidField("accessToken", "was rejected by the directory or can't read its members")
+	case errors.Is(err, directory.ErrTooManyMembers):
+		return nil, ErrDirectoryTooLarge.WithDetails(map[string]int{"maxMembers": s.config.MaxMembers})
+	default:
+		logger.Ctx(ctx).Error().Err(err).Str("source", string(source)).Msg("Failed to read workspace directory")
+		return nil, ErrDirectoryUnavailable.WithCause(err)
+	}
+}
+
+// usersByEmail gets the users with the lowercase emails, keyed by email
+func usersByEmail(ctx context.Context, userRepo repositories.UserStore, emails []string) (map[string]*models.User, error) {
+	users := make(map[string]*models.User, len(emails))
+	for start := 0; start < len(emails); start += emailBatchSize {
+		end := start + emailBatchSize
+		if end > len(emails) {
+			end = len(emails)
+		}
+
+		batch, err := userRepo.FindBatch(ctx, bson.M{"email": bson.M{"$in": emails[start:end]}}, 0)
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Int("emails", end-start).Msg("Failed to get users by email")
+			return nil, err
+		}
+		for _, user := range batch {
+			users[strings.ToLower(user.Email)] = user
+		}
+	}
+	return users, nil
+}
+
+// plan decides what an import does with a directory member, given the user
+// with their email, if any
+func (s *DirectoryImportService) plan(org *models.Organization, member directory.Member, user *models.User, role models.OrganizationMemberRole) models.DirectoryImportEntry {
+	entry := models.DirectoryImportEntry{
+		Email:     member.Email,
+		FirstName: member.FirstName,
+		LastName:  member.LastName,
+		Action:    models.DirectoryImportSkip,
+	}
+
+	if member.Email == "" {
+		entry.Reason = "directory doesn't share the member's email"
+		return entry
+	}
+
+	if user != nil {
+		entry.UserID = user.UserID
+		switch {
+		case user.IsDeleted():
+			entry.Reason = "user is deleted"
+		case org.IsMember(user.UserID):
+			entry.Action = models.DirectoryImportAlreadyMember
+		case user.IsPendingReview():
+			entry.Reason = ErrUserPendingReview.Message
+		default:
+			if reason := joinViolation(org, user); reason != "" {
+				entry.Reason = reason
+				return entry
+			}
+			entry.Action = models.DirectoryImportAdd
+			entry.Role = string(role)
+		}
+		return entry
+	}
+
+	if member.FirstName == "" || member.LastName == "" {
+		entry.Reason = "member has no first or last name"
+		return entry
+	}
+	// New users have no two-factor authentication
+	if reason := joinViolation(org, &models.User{Email: member.Email}); reason != "" {
+		entry.Reason = reason
+		return entry
+	}
+	entry.Action = models.DirectoryImportInvite
+	entry.Role = string(role)
+	return entry
+}
+
+// joinViolation says why an organization doesn't let a user join it, or is
+// empty if it does
+func joinViolation(org *models.Organization, user *models.User) string {
+	if err := checkEmailDomain(org, user, "email"); err != nil {
+		return apperrors.From(err, "").Message
+	}
+	if err := checkTwoFactor(org, user, "email"); err != nil {
+		return apperrors.From(err, "").Message
+	}
+	return ""
+}
+
+// apply adds the member of a planned entry to the organization, creating
+// their user first if they are invited. Failures are recorded on the entry.
+func (s *DirectoryImportService) apply(ctx context.Context, org *models.Organization, entry *models.DirectoryImportEntry, userID string) {
+	fail := func(err error) {
+		appErr := apperrors.From(err, "Failed to import member")
+		entry.Error = appErr.Code
+		entry.Reason = appErr.Message
+	}
+
+	switch entry.Action {
+	case models.DirectoryImportInvite:
+		// Like SCIM, invited users get a generated user ID and stay pending
+		// until they sign in for the first time
+		user := models.NewUser(models.CreateUserRequest{
+			UserID:    uuid.New().String(),
+			Email:     entry.Email,
+			FirstName: entry.FirstName,
+			LastName:  entry.LastName,
+			Role:      models.RoleUser,
+		})
+		user.Status = models.StatusPending
+		user.Preferences = user.Preferences.Resolve(org.Settings.DefaultPreferences)
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("email", entry.Email).
+				Msg("Failed to create invited user for directory import")
+			fail(err)
+			return
+		}
+		// The user stays if adding them fails, so a later import adds them
+		entry.UserID = user.UserID
+	case models.DirectoryImportAdd:
+	default:
+		return
+	}
+
+	err := s.orgService.AddOrganizationMember(ctx, org.ID, models.AddOrganizationMemberRequest{
+		UserID: entry.UserID,
+		Role:   models.OrganizationMemberRole(entry.Role),
+	}, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", entry.UserID).
+			Str("action", string(entry.Action)).Msg("Failed to add member for directory import")
+		fail(err)
+	}
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// errEmailTemplateNotFound is returned when an organization has no override of a template
+var errEmailTemplateNotFound = apperrors.NotFound("EMAIL_TEMPLATE_NOT_FOUND", "email template not found")
+
+// unknownTemplate creates the error returned for a template key that doesn't exist
+func unknownTemplate(key models.EmailTemplateKey) error {
+	return apperrors.InvalidField("key", fmt.Sprintf("unknown email template %q", key))
+}
+
+// EmailTemplateService is a service for organization email template overrides
+type EmailTemplateService struct {
+	templateRepo *repositories.EmailTemplateRepository
+	orgRepo      repositories.OrgStore
+	events       kafka.EventPublisher
+}
+
+// NewEmailTemplateService creates a new email template service
+func NewEmailTemplateService(
+	templateRepo *repositories.EmailTemplateRepository,
+	orgRepo repositories.OrgStore,
+	events kafka.EventPublisher,
+) *EmailTemplateService {
+	return &EmailTemplateService{
+		templateRepo: templateRepo,
+		orgRepo:      orgRepo,
+		events:       events,
+	}
+}
+
+// GetTemplates gets the current email template overrides of an organization
+func (s *EmailTemplateService) GetTemplates(ctx context.Context, orgID string, userID string) ([]*models.EmailTemplate, error) {
+	if _, err := s.getOrganization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.currentTemplates(ctx, orgID)
+}
+
+// GetTemplate gets the current override of one email template
+func (s *EmailTemplateService) GetTemplate(ctx context.Context, orgID string, key models.EmailTemplateKey, userID string) (*models.EmailTemplate, error) {
+	if !key.IsValid() {
+		return nil, unknownTemplate(key)
+	}
+	if _, err := s.getOrganization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	template, err := s.templateRepo.GetLatest(ctx, orgID, key)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errEmailTemplateNotFound
+		}
+		return nil, err
+	}
+	if template.Deleted {
+		return nil, errEmailTemplateNotFound
+	}
+
+	return template, nil
+}
+
+// GetTemplateVersions gets the version history of one email template
+func (s *EmailTemplateService) GetTemplateVersions(ctx context.Context, orgID string, key models.EmailTemplateKey, userID string) ([]*models.EmailTemplate, error) {
+	if !key.IsValid() {
+		return nil, unknownTemplate(key)
+	}
+	if _, err := s.getOrganization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.templateRepo.GetVersions(ctx, orgID, key)
+}
+
+// UpdateTemplate stores a new version of an email template override
+func (s *EmailTemplateService) UpdateTemplate(ctx context.Context, orgID string, key models.EmailTemplateKey, req models.UpdateEmailTemplateRequest, userID string) (*models.EmailTemplate, error) {
+	if !key.IsValid() {
+		return nil, unknownTemplate(key)
+	}
+	if err := req.Validate(); err != nil {
+		return nil, apperrors.Validation("INVALID_EMAIL_TEMPLATE", err.Error())
+	}
+
+	org, err := s.getOrganization(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.nextVersion(ctx, orgID, key, req.ExpectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	template := models.NewEmailTemplate(orgID, key, version, req, userID)
+	if err := s.templateRepo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, org, template, kafka.OrganizationEmailTemplateUpdated)
+	return template, nil
+}
+
+// DeleteTemplate resets an email template to the platform default. The
+// reset is recorded as a new version so the history is kept.
+func (s *EmailTemplateService) DeleteTemplate(ctx context.Context, orgID string, key models.EmailTemplateKey, userID string) error {
+	if !key.IsValid() {
+		return unknownTemplate(key)
+	}
+
+	org, err := s.getOrganization(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+
+	latest, err := s.templateRepo.GetLatest(ctx, orgID, key)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return errEmailTemplateNotFound
+		}
+		return err
+	}
+	if latest.Deleted {
+		return errEmailTemplateNotFound
+	}
+
+	template := models.NewDeletedEmailTemplate(orgID, key, latest.Version+1, userID)
+	if err := s.templateRepo.Create(ctx, template); err != nil {
+		return err
+	}
+
+	s.publish(ctx, org, template, kafka.OrganizationEmailTemplateDeleted)
+	return nil
+}
+
+// GetEffectiveTemplates gets the branding and current template overrides the
+// notification service renders an organization's emails with
+func (s *EmailTemplateService) GetEffectiveTemplates(ctx context.Context, orgID string) (*models.OrganizationEmailTemplatesResponse, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for email templates")
+		return nil, err
+	}
+
+	templates, err := s.currentTemplates(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	logoURL := org.Settings.Branding.LogoURL
+	if logoURL == "" {
+		logoURL = org.LogoURL
+	}
+
+	return &models.OrganizationEmailTemplatesResponse{
+		OrganizationID: org.ID,
+		Branding: models.EmailTemplateBranding{
+			OrgName:        org.Name,
+			OrgLogoURL:     logoURL,
+			PrimaryColor:   org.Settings.Branding.PrimaryColor,
+			SecondaryColor: org.Settings.Branding.SecondaryColor,
+		},
+		Templates: templates,
+	}, nil
+}
+
+// currentTemplates gets the latest version of every template that hasn't been reset
+func (s *EmailTemplateService) currentTemplates(ctx context.Context, orgID string) ([]*models.EmailTemplate, error) {
+	versions, err := s.templateRepo.GetByOrganization(ctx, orgID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to get email templates")
+		return nil, err
+	}
+
+	// Versions are grouped by key, newest first
+	templates := make([]*models.EmailTemplate, 0)
+	seen := make(map[models.EmailTemplateKey]bool)
+	for _, template := range versions {
+		if seen[template.Key] {
+			continue
+		}
+		seen[template.Key] = true
+		if !template.Deleted {
+			templates = append(templates, template)
+		}
+	}
+
+	return templates, nil
+}
+
+// nextVersion returns the version number of the next change to a template,
+// checking the expected current version if one is given
+func (s *EmailTemplateService) nextVersion(ctx context.Context, orgID string, key models.EmailTemplateKey, expected *int) (int, error) {
+	current := 0
+	latest, err := s.templateRepo.GetLatest(ctx, orgID, key)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, err
+	}
+	if latest != nil {
+		current = latest.Version
+	}
+
+	if expected != nil && *expected != current {
+		return 0, repositories.ErrEmailTemplateVersionConflict.WithDetails(map[string]int{"currentVersion": current})
+	}
+
+	return current + 1, nil
+}
+
+// getOrganization gets an organization and checks the user can manage its email templates
+func (s *EmailTemplateService) getOrganization(ctx context.Context, orgID, userID string) (*models.Organization, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for email templates")
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgManageEmailTemplates) {
+		return nil, insufficientPermissions("manage email templates")
+	}
+
+	return org, nil
+}
+
+// publish publishes an email template change event
+func (s *EmailTemplateService) publish(ctx context.Context, org *models.Organization, template *models.EmailTemplate, eventType kafka.EventType) {
+	if err := s.events.PublishUserEvent(
+		ctx,
+		eventType,
+		kafka.EmailTemplateChangedV1{
+			OrgID:     org.ID,
+			Key:       string(template.Key),
+			Version:   template.Version,
+			Subject:   template.Subject,
+			IntroText: template.IntroText,
+			Variables: template.Variables,
+			Deleted:   template.Deleted,
+			ChangedBy: template.CreatedBy,
+			ChangedAt: template.CreatedAt,
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("key", string(template.Key)).
+			Msgf("Failed to publish %s event", eventType)
+	}
+}
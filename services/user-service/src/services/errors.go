@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+)
+
+// Domain errors shared by the services. Controllers attach them to the
+// request and ErrorHandler maps them to HTTP responses.
+var (
+	// ErrUserNotFound is returned when a user does not exist
+	ErrUserNotFound = apperrors.NotFound("USER_NOT_FOUND", "user not found")
+	// ErrOrganizationNotFound is returned when an organization does not exist
+	ErrOrganizationNotFound = apperrors.NotFound("ORGANIZATION_NOT_FOUND", "organization not found")
+	// ErrTeamNotFound is returned when a team does not exist
+	ErrTeamNotFound = apperrors.NotFound("TEAM_NOT_FOUND", "team not found")
+	// ErrGroupNotFound is returned when a group does not exist in the organization
+	ErrGroupNotFound = apperrors.NotFound("GROUP_NOT_FOUND", "group not found")
+	// ErrTeamTemplateNotFound is returned when a team template does not exist in the organization
+	ErrTeamTemplateNotFound = apperrors.NotFound("TEAM_TEMPLATE_NOT_FOUND", "team template not found")
+	// ErrEventNotFound is returned when an event does not exist in the organization, or has no such join code
+	ErrEventNotFound = apperrors.NotFound("EVENT_NOT_FOUND", "event not found")
+	// ErrTagNotFound is returned when removing a tag an organization or team doesn't have
+	ErrTagNotFound = apperrors.NotFound("TAG_NOT_FOUND", "tag not found")
+	// ErrSessionNotFound is returned when a user has no session with the ID
+	ErrSessionNotFound = apperrors.NotFound("SESSION_NOT_FOUND", "session not found")
+	// ErrSettingsVersionNotFound is returned when an organization has no settings version with the number
+	ErrSettingsVersionNotFound = apperrors.NotFound("SETTINGS_VERSION_NOT_FOUND", "settings version not found")
+	// ErrSubscriptionNotFound is returned when an organization has no billing subscription
+	ErrSubscriptionNotFound = apperrors.NotFound("SUBSCRIPTION_NOT_FOUND", "organization has no subscription")
+	// ErrNotOrganizationMember is returned when the caller is not a member of the organization
+	ErrNotOrganizationMember = apperrors.Forbidden("NOT_ORGANIZATION_MEMBER", "user is not a member of the organization")
+	// ErrUserPendingReview is returned when a user held for signup review is added somewhere
+	ErrUserPendingReview = apperrors.Conflict("USER_PENDING_REVIEW", "user is pending signup review")
+	// ErrUserNotDeleted is returned when restoring or purging a user that isn't soft deleted
+	ErrUserNotDeleted = apperrors.Conflict("USER_NOT_DELETED", "user is not deleted")
+	// ErrPreconditionFailed is returned when an update's If-Match precondition names an outdated version
+	ErrPreconditionFailed = apperrors.PreconditionFailed("PRECONDITION_FAILED", "resource has changed since it was read")
+	// ErrSeatLimitReached is returned when adding a member to an organization that uses all its seats
+	ErrSeatLimitReached = apperrors.Conflict("SEAT_LIMIT_REACHED", "organization has no free seats")
+	// ErrEmailDomainBlocked is returned when adding a user whose email domain the organization blocks
+	ErrEmailDomainBlocked = apperrors.Validation("EMAIL_DOMAIN_BLOCKED", "email domain is blocked by the organization")
+	// ErrEmailDomainNotAllowed is returned when adding an external user to an organization that only accepts its own email domains
+	ErrEmailDomainNotAllowed = apperrors.Validation("EMAIL_DOMAIN_NOT_ALLOWED", "organization only accepts members from its email domains")
+	// ErrTwoFactorRequired is returned when a user without two-factor authentication joins or is promoted in an organization that requires it
+	ErrTwoFactorRequired = apperrors.Forbidden("TWO_FACTOR_REQUIRED", "organization requires two-factor authentication")
+
+	// ErrNoPendingEmailChange is returned when cancelling an email change the user didn't request
+	ErrNoPendingEmailChange = apperrors.NotFound("NO_PENDING_EMAIL_CHANGE", "no pending email change")
+	// ErrNoPendingOwnershipTransfer is returned when there is no ownership transfer to accept or cancel
+	ErrNoPendingOwnershipTransfer = apperrors.NotFound("NO_PENDING_OWNERSHIP_TRANSFER", "no pending ownership transfer")
+	// ErrNotTransferRecipient is returned when someone other than the new owner accepts a transfer
+	ErrNotTransferRecipient = apperrors.Forbidden("NOT_TRANSFER_RECIPIENT", "only the new owner can accept the ownership transfer")
+	// ErrOwnershipTransferExpired is returned when a transfer is accepted after it expired
+	ErrOwnershipTransferExpired = apperrors.Conflict("OWNERSHIP_TRANSFER_EXPIRED", "ownership transfer has expired")
+	// ErrOwnershipTransferInvalid is returned when the members changed since a transfer started
+	ErrOwnershipTransferInvalid = apperrors.Conflict("OWNERSHIP_TRANSFER_INVALID", "ownership transfer is no longer valid")
+	// ErrTeamMembersHidden is returned when a user who isn't in a private or secret team asks who is
+	ErrTeamMembersHidden = apperrors.Forbidden("TEAM_MEMBERS_HIDDEN", "only members of the team can see who is in it")
+	// ErrTeamInviteOnly is returned when a user joins a team that only takes members added by its admins
+	ErrTeamInviteOnly = apperrors.Forbidden("TEAM_INVITE_ONLY", "team only takes members added by its admins")
+	// ErrAlreadyTeamMember is returned when a user joins a team they are already in
+	ErrAlreadyTeamMember = apperrors.Conflict("ALREADY_TEAM_MEMBER", "user is already a member of the team")
+	// ErrTeamArchived is returned when the members of an archived team are changed
+	ErrTeamArchived = apperrors.Conflict("TEAM_ARCHIVED", "team is archived")
+	// ErrTeamNotArchived is returned when a team that isn't archived is unarchived
+	ErrTeamNotArchived = apperrors.Conflict("TEAM_NOT_ARCHIVED", "team is not archived")
+	// ErrMembersChanged is returned when the members of an organization or team keep changing during a bulk member request
+	ErrMembersChanged = apperrors.Conflict("MEMBERS_CHANGED", "members changed during the request, try again")
+	// ErrTeamHierarchyCycle is returned when a team is moved under itself or one of its sub-teams
+	ErrTeamHierarchyCycle = apperrors.Conflict("TEAM_HIERARCHY_CYCLE", "a team cannot be moved under itself or one of its sub-teams")
+	// ErrTeamHierarchyTooDeep is returned when a move or create would nest teams too deeply
+	ErrTeamHierarchyTooDeep = apperrors.Conflict("TEAM_HIERARCHY_TOO_DEEP", fmt.Sprintf("teams cannot be nested more than %d levels deep", models.MaxTeamDepth))
+	// ErrEventNotDraft is returned when an event that isn't a draft is started
+	ErrEventNotDraft = apperrors.Conflict("EVENT_NOT_DRAFT", "only draft events can be started")
+	// ErrEventNotLive is returned when an event that isn't live is ended
+	ErrEventNotLive = apperrors.Conflict("EVENT_NOT_LIVE", "only live events can be ended")
+	// ErrEventEnded is returned when an ended event is updated
+	ErrEventEnded = apperrors.Conflict("EVENT_ENDED", "event has ended")
+	// ErrEventLive is returned when a live event is deleted
+	ErrEventLive = apperrors.Conflict("EVENT_LIVE", "live events must be ended before they are deleted")
+
+	// ErrMemberRoleRequired is returned for a bulk member operation that needs a role and has none
+	ErrMemberRoleRequired = apperrors.InvalidField("role", "role is required")
+	// ErrGuestExpiryRequired is returned when a guest is added without an expiry
+	ErrGuestExpiryRequired = apperrors.InvalidField("expiresAt", "expiresAt is required for guests")
+	// ErrOwnerExpiryNotAllowed is returned when an owner is given an expiry
+	ErrOwnerExpiryNotAllowed = apperrors.InvalidField("expiresAt", "owners can't have an expiry")
+	// ErrMemberExpiryInvalid is returned when a membership's expiry is in the past or too far away
+	ErrMemberExpiryInvalid = apperrors.InvalidField("expiresAt", fmt.Sprintf("expiresAt must be in the future and at most %d days away", int(models.MaxMembershipDuration.Hours()/24)))
+	// ErrInactiveDaysInvalid is returned when an access review asks for members inactive for too few or many days
+	ErrInactiveDaysInvalid = apperrors.InvalidField("inactiveDays", fmt.Sprintf("inactiveDays must be between 1 and %d", models.MaxAccessReviewDays))
+	// ErrResidencyUnavailable is returned when an organization is created in a data residency region that isn't configured
+	ErrResidencyUnavailable = apperrors.InvalidField("residency", "residency must be a configured data residency region")
+	// ErrTransferToSelf is returned when an owner transfers ownership to themselves
+	ErrTransferToSelf = apperrors.InvalidField("newOwnerId", "cannot transfer ownership to yourself")
+	// ErrEventScheduleInvalid is returned when an event would end before it starts
+	ErrEventScheduleInvalid = apperrors.InvalidField("endsAt", "endsAt must be after startsAt")
+)
+
+// insufficientPermissions creates the error returned when the caller's role
+// doesn't allow an action
+func insufficientPermissions(action string) error {
+	return apperrors.Forbidden("INSUFFICIENT_PERMISSIONS", "insufficient permissions to "+action)
+}
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/id"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// eventCodeAttempts is how many join codes are tried before creating an
+// event fails; a code is only retried when another event already has it
+const eventCodeAttempts = 5
+
+// EventService is a service for organization events: the meetings, talks
+// and sessions participants join with a code. Hosts run their events, from
+// draft to live to ended, and every change is published for the Q&A and
+// poll services.
+type EventService struct {
+	eventRepo *repositories.EventRepository
+	orgRepo   repositories.OrgStore
+	events    kafka.EventPublisher
+}
+
+// NewEventService creates a new event service
+func NewEventService(eventRepo *repositories.EventRepository, orgRepo repositories.OrgStore, events kafka.EventPublisher) *EventService {
+	return &EventService{
+		eventRepo: eventRepo,
+		orgRepo:   orgRepo,
+		events:    events,
+	}
+}
+
+// CreateEvent creates a draft event in an organization, hosted by its
+// creator and the hosts of the request
+func (s *EventService) CreateEvent(ctx context.Context, orgID string, req models.CreateEventRequest, userID string) (*models.Event, error) {
+	org, err := s.getOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check permissions - must be allowed to create events
+	if !org.Can(userID, models.PermOrgCreateEvents) {
+		return nil, insufficientPermissions("create events")
+	}
+
+	if err := validateEventHosts(org, req.HostIDs); err != nil {
+		return nil, err
+	}
+
+	// Join codes are short, so a new code is drawn when one is taken
+	var event *models.Event
+	for attempt := 1; ; attempt++ {
+		code, err := id.Code(models.EventCodeLength)
+		if err != nil {
+			return nil, err
+		}
+		event = models.NewEvent(orgID, code, req, userID)
+		if len(event.HostIDs) > models.MaxEventHosts {
+			return nil, apperrors.InvalidField("hostIds", fmt.Sprintf("events cannot have more than %d hosts", models.MaxEventHosts))
+		}
+
+		err = s.eventRepo.Create(ctx, event)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, repositories.ErrEventCodeTaken) && attempt < eventCodeAttempts {
+			continue
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to create event")
+		return nil, err
+	}
+
+	s.publish(ctx, event, kafka.EventCreated, userID)
+	return event, nil
+}
+
+// GetEvents lists the events of an organization, optionally only those with
+// a status
+func (s *EventService) GetEvents(ctx context.Context, orgID string, status models.EventStatus, page, limit int, userID string) ([]*models.Event, int64, error) {
+	// Validate pagination
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	org, err := s.getOrganization(ctx, orgID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !org.Can(userID, models.PermOrgView) {
+		return nil, 0, ErrNotOrganizationMember
+	}
+
+	filter := models.EventFilter{OrganizationID: orgID, Status: status}
+	events, total, err := s.eventRepo.Find(ctx, filter, page, limit)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Int("page", page).Int("limit", limit).
+			Msg("Failed to list events")
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+// GetEvent gets an event of an organization
+func (s *EventService) GetEvent(ctx context.Context, orgID, eventID string, userID string) (*models.Event, error) {
+	org, err := s.getOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !org.Can(userID, models.PermOrgView) {
+		return nil, ErrNotOrganizationMember
+	}
+
+	return s.getEvent(ctx, orgID, eventID)
+}
+
+// GetEventByCode gets the event with a join code. Participants needn't be
+// members of the event's organization to join it.
+func (s *EventService) GetEventByCode(ctx context.Context, code string) (*models.Event, error) {
+	event, err := s.eventRepo.GetByCode(ctx, models.NormalizeEventCode(code))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrEventNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("code", code).Msg("Failed to get event by code")
+		return nil, err
+	}
+	return event, nil
+}
+
+// UpdateEvent updates the details, schedule or hosts of an event that hasn't
+// ended
+func (s *EventService) UpdateEvent(ctx context.Context, orgID, eventID string, req models.UpdateEventRequest, userID string) (*models.Event, error) {
+	org, event, err := s.getManagedEvent(ctx, orgID, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == models.EventEnded {
+		return nil, ErrEventEnded
+	}
+	if req.HostIDs != nil {
+		if err := validateEventHosts(org, *req.HostIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	event.Apply(req)
+	if !event.EndsAt.After(event.StartsAt) {
+		return nil, ErrEventScheduleInvalid
+	}
+
+	if err := s.eventRepo.Update(ctx, event); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// Tell a deleted event from one ended meanwhile
+			if _, err := s.getEvent(ctx, orgID, eventID); err != nil {
+				return nil, err
+			}
+			return nil, ErrEventEnded
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", eventID).Msg("Failed to update event")
+		return nil, err
+	}
+
+	s.publish(ctx, event, kafka.EventUpdated, userID)
+	return event, nil
+}
+
+// StartEvent takes a draft event live
+func (s *EventService) StartEvent(ctx context.Context, orgID, eventID string, userID string) (*models.Event, error) {
+	return s.transition(ctx, orgID, eventID, userID, models.EventDraft, models.EventLive)
+}
+
+// EndEvent ends a live event
+func (s *EventService) EndEvent(ctx context.Context, orgID, eventID string, userID string) (*models.Event, error) {
+	return s.transition(ctx, orgID, eventID, userID, models.EventLive, models.EventEnded)
+}
+
+// DeleteEvent deletes an event of an organization that isn't live
+func (s *EventService) DeleteEvent(ctx context.Context, orgID, eventID string, userID string) error {
+	_, event, err := s.getManagedEvent(ctx, orgID, eventID, userID)
+	if err != nil {
+		return err
+	}
+	if event.Status == models.EventLive {
+		return ErrEventLive
+	}
+
+	if err := s.eventRepo.Delete(ctx, orgID, eventID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// Tell a deleted event from one started meanwhile
+			if _, err := s.getEvent(ctx, orgID, eventID); err != nil {
+				return err
+			}
+			return ErrEventLive
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", eventID).Msg("Failed to delete event")
+		return err
+	}
+
+	s.publish(ctx, event, kafka.EventDeleted, userID)
+	return nil
+}
+
+// transition moves an event from a status to the next, conditional on it
+// still having the from status, so concurrent requests can't both win
+func (s *EventService) transition(ctx context.Context, orgID, eventID, userID string, from, to models.EventStatus) (*models.Event, error) {
+	notFrom := ErrEventNotDraft
+	eventType := kafka.EventStarted
+	if from == models.EventLive {
+		notFrom = ErrEventNotLive
+		eventType = kafka.EventEnded
+	}
+
+	_, event, err := s.getManagedEvent(ctx, orgID, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != from {
+		return nil, notFrom
+	}
+
+	now := time.Now()
+	event.Status = to
+	event.UpdatedAt = now
+	if to == models.EventLive {
+		event.StartedAt = &now
+	} else {
+		event.EndedAt = &now
+	}
+
+	if err := s.eventRepo.Transition(ctx, event, from); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, notFrom
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", eventID).Str("status", string(to)).Msg("Failed to change event status")
+		return nil, err
+	}
+
+	s.publish(ctx, event, eventType, userID)
+	return event, nil
+}
+
+// getOrganization gets an organization, mapping a missing document to a not
+// found error
+func (s *EventService) getOrganization(ctx context.Context, orgID string) (*models.Organization, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for events")
+		return nil, err
+	}
+	return org, nil
+}
+
+// getEvent gets an event, mapping a missing document to a not found error
+func (s *EventService) getEvent(ctx context.Context, orgID, eventID string) (*models.Event, error) {
+	event, err := s.eventRepo.GetByID(ctx, orgID, eventID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrEventNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", eventID).Msg("Failed to get event")
+		return nil, err
+	}
+	return event, nil
+}
+
+// getManagedEvent gets an event the user may manage: one they host while a
+// member of its organization, or any with the permission to manage events
+func (s *EventService) getManagedEvent(ctx context.Context, orgID, eventID, userID string) (*models.Organization, *models.Event, error) {
+	org, err := s.getOrganization(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !org.IsMember(userID) {
+		return nil, nil, ErrNotOrganizationMember
+	}
+
+	event, err := s.getEvent(ctx, orgID, eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check permissions - must host the event or manage events
+	if !event.IsHost(userID) && !org.Can(userID, models.PermOrgManageEvents) {
+		return nil, nil, insufficientPermissions("manage this event")
+	}
+
+	return org, event, nil
+}
+
+// publish publishes an event (meeting) event
+func (s *EventService) publish(ctx context.Context, event *models.Event, eventType kafka.EventType, changedBy string) {
+	if err := s.events.PublishUserEvent(
+		ctx,
+		eventType,
+		kafka.EventV1{
+			ID:        event.ID,
+			OrgID:     event.OrganizationID,
+			Title:     event.Title,
+			Code:      event.Code,
+			Status:    string(event.Status),
+			StartsAt:  event.StartsAt,
+			EndsAt:    event.EndsAt,
+			HostIDs:   event.HostIDs,
+			StartedAt: event.StartedAt,
+			EndedAt:   event.EndedAt,
+			ChangedBy: changedBy,
+			ChangedAt: event.UpdatedAt,
+		},
+		event.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", event.OrganizationID).Str("id", event.ID).
+			Msgf("Failed to publish %s event", eventType)
+	}
+}
+
+// validateEventHosts checks that the hosts are members of the organization
+func validateEventHosts(org *models.Organization, hostIDs []string) error {
+	for _, hostID := range hostIDs {
+		if !org.IsMember(hostID) {
+			return apperrors.InvalidField("hostIds", "user "+hostID+" is not a member of the organization")
+		}
+	}
+	return nil
+}
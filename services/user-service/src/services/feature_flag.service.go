@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Feature flag errors
+var (
+	// ErrFeatureFlagNotFound is returned when a feature flag does not exist
+	ErrFeatureFlagNotFound = apperrors.NotFound("FEATURE_FLAG_NOT_FOUND", "feature flag not found")
+	// ErrFlagOverrideNotFound is returned when removing an override a flag doesn't have
+	ErrFlagOverrideNotFound = apperrors.NotFound("FLAG_OVERRIDE_NOT_FOUND", "feature flag override not found")
+	// ErrFlagOverrideLimit is returned when a flag has as many overrides in a scope as allowed
+	ErrFlagOverrideLimit = apperrors.Conflict("FLAG_OVERRIDE_LIMIT", "feature flag has too many overrides; roll it out by percentage instead")
+)
+
+// flagChangeScopeDefinition is the scope of feature_flag.changed events for
+// changes to the flag itself
+const flagChangeScopeDefinition = "definition"
+
+// FeatureFlagService manages feature flags and evaluates them for callers.
+// Flags are evaluated on every client load, so they are served from memory
+// and reloaded from the database periodically; changes made on another
+// instance reach this one within the refresh interval.
+type FeatureFlagService struct {
+	flagRepo *repositories.FeatureFlagRepository
+	orgRepo  repositories.OrgStore
+	userRepo repositories.UserStore
+	events   kafka.EventPublisher
+	config   *config.FeatureFlagConfig
+
+	mu    sync.RWMutex
+	flags []*models.FeatureFlag
+}
+
+// NewFeatureFlagService creates a new feature flag service
+func NewFeatureFlagService(
+	flagRepo *repositories.FeatureFlagRepository,
+	orgRepo repositories.OrgStore,
+	userRepo repositories.UserStore,
+	events kafka.EventPublisher,
+	cfg *config.FeatureFlagConfig,
+) *FeatureFlagService {
+	return &FeatureFlagService{
+		flagRepo: flagRepo,
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+		events:   events,
+		config:   cfg,
+	}
+}
+
+// Evaluate evaluates every flag for a user, in an organization they are a
+// member of if orgID is set
+func (s *FeatureFlagService) Evaluate(ctx context.Context, userID, orgID string) (*models.FeatureFlagsResponse, error) {
+	if orgID != "" {
+		org, err := s.orgRepo.GetByID(ctx, orgID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, ErrOrganizationNotFound
+			}
+			return nil, err
+		}
+		if !org.Can(userID, models.PermOrgView) {
+			return nil, ErrNotOrganizationMember
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	flags := make(map[string]bool, len(s.flags))
+	for _, flag := range s.flags {
+		flags[flag.Key] = flag.Evaluate(userID, orgID)
+	}
+	return &models.FeatureFlagsResponse{OrganizationID: orgID, Flags: flags}, nil
+}
+
+// ListFlags lists every feature flag with its overrides
+func (s *FeatureFlagService) ListFlags(ctx context.Context) ([]*models.FeatureFlag, error) {
+	flags, err := s.flagRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if flags == nil {
+		flags = []*models.FeatureFlag{}
+	}
+	return flags, nil
+}
+
+// GetFlag gets a feature flag by key
+func (s *FeatureFlagService) GetFlag(ctx context.Context, key string) (*models.FeatureFlag, error) {
+	flag, err := s.flagRepo.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrFeatureFlagNotFound
+		}
+		return nil, err
+	}
+	return flag, nil
+}
+
+// UpdateFlag creates or updates a feature flag
+func (s *FeatureFlagService) UpdateFlag(ctx context.Context, key string, req models.UpdateFeatureFlagRequest, userID string) (*models.FeatureFlag, error) {
+	if !models.ValidFlagKey(key) {
+		return nil, apperrors.InvalidField("key", "flag keys are 1-64 lowercase letters, digits, dots, dashes and underscores")
+	}
+
+	flag, err := s.flagRepo.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+		flag = &models.FeatureFlag{Key: key, CreatedAt: time.Now()}
+	}
+
+	flag.Apply(req, userID)
+	if err := s.flagRepo.Save(ctx, flag); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("key", key).Msg("Failed to save feature flag")
+		return nil, err
+	}
+
+	s.refresh(ctx)
+	s.publishChange(ctx, key, flagChangeScopeDefinition, "", false, userID)
+	logger.Ctx(ctx).Info().Str("key", key).Bool("enabled", flag.Enabled).Int("rollout", flag.Rollout).Str("userId", userID).
+		Msg("Feature flag updated")
+	return flag, nil
+}
+
+// DeleteFlag deletes a feature flag, turning it off for everyone
+func (s *FeatureFlagService) DeleteFlag(ctx context.Context, key, userID string) error {
+	if err := s.flagRepo.Delete(ctx, key); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrFeatureFlagNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("key", key).Msg("Failed to delete feature flag")
+		return err
+	}
+
+	s.refresh(ctx)
+	s.publishChange(ctx, key, flagChangeScopeDefinition, "", true, userID)
+	logger.Ctx(ctx).Info().Str("key", key).Str("userId", userID).Msg("Feature flag deleted")
+	return nil
+}
+
+// SetOverride turns a flag on or off for an organization or user, by the
+// override scope, regardless of its rollout
+func (s *FeatureFlagService) SetOverride(ctx context.Context, key, scope, targetID string, req models.SetFlagOverrideRequest, userID string) (*models.FeatureFlag, error) {
+	if !models.ValidFlagScope(scope) {
+		return nil, apperrors.InvalidField("scope", "scope must be organizations or users")
+	}
+
+	flag, err := s.GetFlag(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := flag.Overrides(scope)
+	if _, ok := overrides[targetID]; !ok && len(overrides) >= models.MaxFlagOverrides {
+		return nil, ErrFlagOverrideLimit
+	}
+
+	// Overrides must name an existing organization or user
+	if scope == models.FlagScopeOrganizations {
+		_, err = s.orgRepo.GetByID(ctx, targetID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+	} else {
+		_, err = s.userRepo.GetByUserId(ctx, targetID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.flagRepo.SetOverride(ctx, key, scope, targetID, *req.Enabled, userID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrFeatureFlagNotFound
+		}
+		return nil, err
+	}
+
+	s.refresh(ctx)
+	s.publishChange(ctx, key, scope, targetID, false, userID)
+	return s.GetFlag(ctx, key)
+}
+
+// DeleteOverride removes the override of a flag for an organization or user,
+// so the flag's rollout decides for them again
+func (s *FeatureFlagService) DeleteOverride(ctx context.Context, key, scope, targetID, userID string) (*models.FeatureFlag, error) {
+	if !models.ValidFlagScope(scope) {
+		return nil, apperrors.InvalidField("scope", "scope must be organizations or users")
+	}
+
+	flag, err := s.GetFlag(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := flag.Overrides(scope)[targetID]; !ok {
+		return nil, ErrFlagOverrideNotFound
+	}
+
+	if err := s.flagRepo.DeleteOverride(ctx, key, scope, targetID, userID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrFeatureFlagNotFound
+		}
+		return nil, err
+	}
+
+	s.refresh(ctx)
+	s.publishChange(ctx, key, scope, targetID, true, userID)
+	return s.GetFlag(ctx, key)
+}
+
+// RunRefresher reloads the flags from the database every refresh interval
+// until ctx is cancelled
+func (s *FeatureFlagService) RunRefresher(ctx context.Context) {
+	ticker := time.NewTicker(s.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		s.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh reloads the flags, keeping the cached ones if the database fails
+func (s *FeatureFlagService) refresh(ctx context.Context) {
+	flags, err := s.flagRepo.List(ctx)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.flags = flags
+}
+
+// publishChange publishes a feature_flag.changed event, so other services
+// drop the flag from their caches
+func (s *FeatureFlagService) publishChange(ctx context.Context, key, scope, targetID string, deleted bool, userID string) {
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.FeatureFlagChanged,
+		kafka.FeatureFlagChangedV1{
+			Key:       key,
+			Scope:     scope,
+			TargetID:  targetID,
+			Deleted:   deleted,
+			ChangedBy: userID,
+			ChangedAt: time.Now(),
+		},
+		key,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("key", key).Msg("Failed to publish feature_flag.changed event")
+	}
+}
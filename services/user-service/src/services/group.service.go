@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GroupService is a service for organization groups
+type GroupService struct {
+	groupRepo *repositories.GroupRepository
+	orgRepo   repositories.OrgStore
+}
+
+// NewGroupService creates a new group service
+func NewGroupService(groupRepo *repositories.GroupRepository, orgRepo repositories.OrgStore) *GroupService {
+	return &GroupService{
+		groupRepo: groupRepo,
+		orgRepo:   orgRepo,
+	}
+}
+
+// CreateGroup creates a group in an organization
+func (s *GroupService) CreateGroup(ctx context.Context, orgID string, req models.CreateGroupRequest, userID string) (*models.Group, error) {
+	org, err := s.getOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgManageGroups) {
+		return nil, insufficientPermissions("manage groups")
+	}
+
+	if err := validateGroupPermissions(req.Permissions); err != nil {
+		return nil, err
+	}
+	if err := validateGroupMembers(org, "memberIds", req.MemberIDs); err != nil {
+		return nil, err
+	}
+
+	group := models.NewGroup(orgID, req, userID)
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to create group")
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// GetGroups lists the groups of an organization
+func (s *GroupService) GetGroups(ctx context.Context, orgID string, page, limit int, userID string) ([]*models.Group, int64, error) {
+	// Validate pagination
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	if err := s.checkMember(ctx, orgID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	groups, total, err := s.groupRepo.GetByOrganization(ctx, orgID, page, limit)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Int("page", page).Int("limit", limit).
+			Msg("Failed to list groups")
+		return nil, 0, err
+	}
+
+	return groups, total, nil
+}
+
+// GetGroup gets a group of an organization
+func (s *GroupService) GetGroup(ctx context.Context, orgID, groupID string, userID string) (*models.Group, error) {
+	if err := s.checkMember(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.getGroup(ctx, orgID, groupID)
+}
+
+// UpdateGroup updates a group of an organization
+func (s *GroupService) UpdateGroup(ctx context.Context, orgID, groupID string, req models.UpdateGroupRequest, userID string) (*models.Group, error) {
+	if err := s.checkManager(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	if req.Permissions != nil {
+		if err := validateGroupPermissions(*req.Permissions); err != nil {
+			return nil, err
+		}
+	}
+
+	group, err := s.getGroup(ctx, orgID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Apply(req)
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrGroupNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", groupID).Msg("Failed to update group")
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// DeleteGroup deletes a group of an organization
+func (s *GroupService) DeleteGroup(ctx context.Context, orgID, groupID string, userID string) error {
+	if err := s.checkManager(ctx, orgID, userID); err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.Delete(ctx, orgID, groupID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrGroupNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", groupID).Msg("Failed to delete group")
+		return err
+	}
+
+	return nil
+}
+
+// AddGroupMembers adds organization members to a group
+func (s *GroupService) AddGroupMembers(ctx context.Context, orgID, groupID string, req models.AddGroupMembersRequest, userID string) (*models.Group, error) {
+	org, err := s.getOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgManageGroups) {
+		return nil, insufficientPermissions("manage groups")
+	}
+
+	if err := validateGroupMembers(org, "userIds", req.UserIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.groupRepo.AddMembers(ctx, orgID, groupID, req.UserIDs); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrGroupNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", groupID).Msg("Failed to add group members")
+		return nil, err
+	}
+
+	return s.getGroup(ctx, orgID, groupID)
+}
+
+// RemoveGroupMember removes a member from a group. Members may leave a group
+// themselves.
+func (s *GroupService) RemoveGroupMember(ctx context.Context, orgID, groupID, memberID string, userID string) error {
+	if memberID != userID {
+		if err := s.checkManager(ctx, orgID, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.groupRepo.RemoveMember(ctx, orgID, groupID, memberID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("MEMBER_NOT_FOUND", "member not found in group")
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", groupID).Str("userId", memberID).Msg("Failed to remove group member")
+		return err
+	}
+
+	return nil
+}
+
+// getOrganization gets an organization, mapping a missing document to a not
+// found error
+func (s *GroupService) getOrganization(ctx context.Context, orgID string) (*models.Organization, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for groups")
+		return nil, err
+	}
+	return org, nil
+}
+
+// checkMember checks that a user can view an organization's groups
+func (s *GroupService) checkMember(ctx context.Context, orgID, userID string) error {
+	org, err := s.getOrganization(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if !org.Can(userID, models.PermOrgView) {
+		return ErrNotOrganizationMember
+	}
+	return nil
+}
+
+// checkManager checks that a user can manage an organization's groups
+func (s *GroupService) checkManager(ctx context.Context, orgID, userID string) error {
+	org, err := s.getOrganization(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if !org.Can(userID, models.PermOrgManageGroups) {
+		return insufficientPermissions("manage groups")
+	}
+	return nil
+}
+
+// getGroup gets a group, mapping a missing document to a not found error
+func (s *GroupService) getGroup(ctx context.Context, orgID, groupID string) (*models.Group, error) {
+	group, err := s.groupRepo.GetByID(ctx, orgID, groupID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrGroupNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", groupID).Msg("Failed to get group")
+		return nil, err
+	}
+	return group, nil
+}
+
+// validateGroupPermissions checks that groups may grant the permissions
+func validateGroupPermissions(permissions []models.Permission) error {
+	for _, permission := range permissions {
+		if !models.IsGroupGrantable(permission) {
+			return apperrors.InvalidField("permissions", "permission "+string(permission)+" cannot be granted by a group")
+		}
+	}
+	return nil
+}
+
+// validateGroupMembers checks that the users are members of the organization
+func validateGroupMembers(org *models.Organization, field string, userIDs []string) error {
+	for _, userID := range userIDs {
+		if !org.IsMember(userID) {
+			return apperrors.InvalidField(field, "user "+userID+" is not a member of the organization")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/lifecycle"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/utils"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Impersonation errors
+var (
+	// ErrImpersonationNotFound is returned when an impersonation session does not exist
+	ErrImpersonationNotFound = apperrors.NotFound("IMPERSONATION_NOT_FOUND", "impersonation session not found")
+	// ErrImpersonationEnded is returned when ending an impersonation session that already ended or expired
+	ErrImpersonationEnded = apperrors.Conflict("IMPERSONATION_ENDED", "impersonation session has already ended")
+)
+
+// auditWriteTimeout bounds recording an audit entry after its request
+const auditWriteTimeout = 5 * time.Second
+
+// ImpersonationService lets support engineers act as a user to see exactly
+// what they see. Sessions are short-lived and read-only by default, and every
+// request made in one is recorded in the audit log.
+type ImpersonationService struct {
+	impersonationRepo *repositories.ImpersonationRepository
+	auditRepo         *repositories.AuditRepository
+	userRepo          repositories.UserStore
+	events            kafka.EventPublisher
+	jwtConfig         *config.JWTConfig
+	config            *config.SupportConfig
+}
+
+// NewImpersonationService creates a new impersonation service
+func NewImpersonationService(
+	impersonationRepo *repositories.ImpersonationRepository,
+	auditRepo *repositories.AuditRepository,
+	userRepo repositories.UserStore,
+	events kafka.EventPublisher,
+	jwtConfig *config.JWTConfig,
+	cfg *config.SupportConfig,
+) *ImpersonationService {
+	return &ImpersonationService{
+		impersonationRepo: impersonationRepo,
+		auditRepo:         auditRepo,
+		userRepo:          userRepo,
+		events:            events,
+		jwtConfig:         jwtConfig,
+		config:            cfg,
+	}
+}
+
+// StartImpersonation starts a session of an admin acting as a user and mints
+// its token. Admins can't impersonate themselves or users that could
+// impersonate others.
+func (s *ImpersonationService) StartImpersonation(ctx context.Context, userID string, req models.StartImpersonationRequest, adminID string) (*models.ImpersonationResponse, error) {
+	if userID == adminID {
+		return nil, apperrors.Validation("CANNOT_IMPERSONATE_SELF", "cannot impersonate yourself")
+	}
+
+	user, err := s.userRepo.GetByUserId(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user for impersonation")
+		return nil, err
+	}
+	if models.HasPlatformPermission([]string{string(user.Role)}, models.PermPlatformImpersonateUsers) {
+		return nil, apperrors.Forbidden("CANNOT_IMPERSONATE_ADMIN", "cannot impersonate an admin")
+	}
+
+	session := models.NewImpersonationSession(adminID, userID, req, s.config.ImpersonationTTL)
+	if err := s.impersonationRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	token, err := utils.NewImpersonationToken(s.jwtConfig, user.UserID, user.Email, string(user.Role), utils.ImpersonationClaims{
+		SessionID: session.ID,
+		AdminID:   adminID,
+		ReadOnly:  session.ReadOnly,
+	}, session.ExpiresAt)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("impersonationId", session.ID).Msg("Failed to mint impersonation token")
+		return nil, err
+	}
+
+	s.publishEvent(ctx, kafka.UserImpersonationStarted, session)
+
+	logger.Ctx(ctx).Info().Str("impersonationId", session.ID).Str("adminId", adminID).Str("userId", userID).
+		Bool("readOnly", session.ReadOnly).Msg("Impersonation started")
+	return &models.ImpersonationResponse{
+		Token:   token,
+		Session: session,
+	}, nil
+}
+
+// EndImpersonation ends an impersonation session; its token stops working
+// right away
+func (s *ImpersonationService) EndImpersonation(ctx context.Context, sessionID string, adminID string) error {
+	session, err := s.impersonationRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrImpersonationNotFound
+		}
+		return err
+	}
+
+	now := time.Now()
+	if !session.IsActive(now) {
+		return ErrImpersonationEnded
+	}
+
+	if err := s.impersonationRepo.End(ctx, sessionID, adminID, now); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrImpersonationEnded
+		}
+		return err
+	}
+	session.EndedAt = &now
+	session.EndedBy = adminID
+
+	s.publishEvent(ctx, kafka.UserImpersonationEnded, session)
+
+	logger.Ctx(ctx).Info().Str("impersonationId", sessionID).Str("endedBy", adminID).Msg("Impersonation ended")
+	return nil
+}
+
+// IsActive checks if an impersonation session is neither ended nor expired
+func (s *ImpersonationService) IsActive(ctx context.Context, sessionID string) (bool, error) {
+	session, err := s.impersonationRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return session.IsActive(time.Now()), nil
+}
+
+// RecordRequest records a request made in an impersonation session in the
+// audit log, in the background
+func (s *ImpersonationService) RecordRequest(entry *models.AuditEntry) {
+	lifecycle.Add()
+	go func() {
+		defer lifecycle.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+		defer cancel()
+
+		if err := s.auditRepo.Create(ctx, entry); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("impersonationId", entry.ImpersonationID).Str("path", entry.Path).
+				Msg("Failed to record impersonated request")
+		}
+	}()
+}
+
+// publishEvent publishes a user.impersonation event
+func (s *ImpersonationService) publishEvent(ctx context.Context, eventType kafka.EventType, session *models.ImpersonationSession) {
+	if err := s.events.PublishUserEvent(
+		ctx,
+		eventType,
+		kafka.ImpersonationV1{
+			SessionID: session.ID,
+			AdminID:   session.AdminID,
+			UserID:    session.UserID,
+			Reason:    session.Reason,
+			ReadOnly:  session.ReadOnly,
+			StartedAt: session.StartedAt,
+			ExpiresAt: session.ExpiresAt,
+			EndedAt:   session.EndedAt,
+			EndedBy:   session.EndedBy,
+		},
+		session.UserID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("impersonationId", session.ID).
+			Msgf("Failed to publish %s event", eventType)
+	}
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Inbox page sizes
+const (
+	DefaultInboxLimit = 20
+	MaxInboxLimit     = 100
+)
+
+// Inbox stream timing. Streams end after inboxStreamMaxDuration so clients
+// reconnect with a fresh session, and send a ping every inboxStreamKeepAlive
+// so proxies keep idle streams open.
+const (
+	inboxStreamMaxDuration = 30 * time.Minute
+	inboxStreamKeepAlive   = 25 * time.Second
+)
+
+// inboxStreamBatchSize is the most new notifications sent per check
+const inboxStreamBatchSize = 50
+
+// Inbox stream event names
+const (
+	InboxEventUnread       = "unread"
+	InboxEventNotification = "notification"
+	InboxEventPing         = "ping"
+)
+
+// ErrInboxNotificationNotFound is returned for a notification that isn't in
+// the user's inbox
+var ErrInboxNotificationNotFound = apperrors.NotFound("NOTIFICATION_NOT_FOUND", "notification not found")
+
+// InboxService keeps the in-app notification inboxes of users and streams
+// their changes. Streams are woken right away by changes made on this
+// instance, and poll storage for changes made on other instances.
+type InboxService struct {
+	inboxRepo *repositories.InboxRepository
+	config    *config.NotificationConfig
+
+	mu       sync.Mutex
+	watchers map[string]map[chan struct{}]struct{}
+	closed   chan struct{}
+	close    sync.Once
+}
+
+// NewInboxService creates a new inbox service
+func NewInboxService(inboxRepo *repositories.InboxRepository, cfg *config.NotificationConfig) *InboxService {
+	return &InboxService{
+		inboxRepo: inboxRepo,
+		config:    cfg,
+		watchers:  make(map[string]map[chan struct{}]struct{}),
+		closed:    make(chan struct{}),
+	}
+}
+
+// Add adds a notification to its user's inbox
+func (s *InboxService) Add(ctx context.Context, notification *models.Notification) error {
+	if err := s.inboxRepo.Create(ctx, models.NewInboxNotification(notification, s.config.InboxRetention)); err != nil {
+		return err
+	}
+
+	s.wake(notification.UserID)
+	return nil
+}
+
+// GetInbox gets a page of a user's inbox, newest first, optionally of unread
+// notifications only
+func (s *InboxService) GetInbox(ctx context.Context, userID, cursor string, limit int, unreadOnly bool) (*models.InboxResponse, error) {
+	var after *models.TimelineCursor
+	if cursor != "" {
+		var err error
+		if after, err = models.ParseTimelineCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fetch one extra notification to know if there is a next page
+	notifications, err := s.inboxRepo.Find(ctx, models.InboxFilter{UserID: userID, UnreadOnly: unreadOnly}, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.inboxRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.InboxResponse{
+		Notifications: notifications,
+		UnreadCount:   unread,
+		Limit:         limit,
+	}
+	if len(notifications) > limit {
+		response.Notifications = notifications[:limit]
+		last := response.Notifications[limit-1]
+		response.NextCursor = models.TimelineCursor{OccurredAt: last.OccurredAt, ID: last.ID}.Encode()
+	}
+	if response.Notifications == nil {
+		response.Notifications = []*models.InboxNotification{}
+	}
+
+	return response, nil
+}
+
+// GetUnreadCount gets the number of unread notifications in a user's inbox
+func (s *InboxService) GetUnreadCount(ctx context.Context, userID string) (*models.InboxUnreadResponse, error) {
+	unread, err := s.inboxRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.InboxUnreadResponse{UnreadCount: unread}, nil
+}
+
+// MarkRead marks a notification of a user's inbox read
+func (s *InboxService) MarkRead(ctx context.Context, userID, id string) (*models.MarkInboxReadResponse, error) {
+	marked, err := s.inboxRepo.MarkRead(ctx, userID, id, time.Now())
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInboxNotificationNotFound
+		}
+		return nil, err
+	}
+
+	var updated int64
+	if marked {
+		updated = 1
+	}
+	return s.marked(ctx, userID, updated)
+}
+
+// MarkAllRead marks the unread notifications of a user's inbox read, up to
+// the request's before time if one is given
+func (s *InboxService) MarkAllRead(ctx context.Context, userID string, req models.MarkInboxReadRequest) (*models.MarkInboxReadResponse, error) {
+	now := time.Now()
+	before := now
+	if req.Before != nil {
+		before = *req.Before
+	}
+
+	updated, err := s.inboxRepo.MarkAllRead(ctx, userID, before, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.marked(ctx, userID, updated)
+}
+
+// marked builds the response to marking notifications read and wakes the
+// user's other streams, such as in other tabs, to update their count
+func (s *InboxService) marked(ctx context.Context, userID string, updated int64) (*models.MarkInboxReadResponse, error) {
+	s.wake(userID)
+
+	unread, err := s.inboxRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MarkInboxReadResponse{Updated: updated, UnreadCount: unread}, nil
+}
+
+// Watch streams the changes of a user's inbox to send until the context is
+// done, the stream has run for its longest duration or the service closes.
+// The unread count is sent first and whenever it changes, then each
+// notification added to the inbox.
+func (s *InboxService) Watch(ctx context.Context, userID string, send func(event string, data interface{}) error) error {
+	wake, unwatch := s.watch(userID)
+	defer unwatch()
+
+	since := time.Now()
+	unread, err := s.inboxRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := send(InboxEventUnread, models.InboxUnreadResponse{UnreadCount: unread}); err != nil {
+		return err
+	}
+
+	poll := time.NewTicker(s.config.InboxPollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(inboxStreamKeepAlive)
+	defer keepAlive.Stop()
+	timeout := time.NewTimer(inboxStreamMaxDuration)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.closed:
+			return nil
+		case <-timeout.C:
+			return nil
+		case <-keepAlive.C:
+			if err := send(InboxEventPing, struct{}{}); err != nil {
+				return err
+			}
+			continue
+		case <-wake:
+		case <-poll.C:
+		}
+
+		notifications, err := s.inboxRepo.FindCreatedAfter(ctx, userID, since, inboxStreamBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, notification := range notifications {
+			if err := send(InboxEventNotification, notification); err != nil {
+				return err
+			}
+			since = notification.CreatedAt
+		}
+
+		count, err := s.inboxRepo.CountUnread(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if count != unread {
+			unread = count
+			if err := send(InboxEventUnread, models.InboxUnreadResponse{UnreadCount: unread}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Close ends every inbox stream, so the server can shut down without
+// waiting for them
+func (s *InboxService) Close() {
+	s.close.Do(func() { close(s.closed) })
+}
+
+// watch registers a stream of a user's inbox, returning the channel it is
+// woken on and a function to unregister it
+func (s *InboxService) watch(userID string) (<-chan struct{}, func()) {
+	wake := make(chan struct{}, 1)
+
+	s.mu.Lock()
+	if s.watchers[userID] == nil {
+		s.watchers[userID] = make(map[chan struct{}]struct{})
+	}
+	s.watchers[userID][wake] = struct{}{}
+	s.mu.Unlock()
+
+	return wake, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.watchers[userID], wake)
+		if len(s.watchers[userID]) == 0 {
+			delete(s.watchers, userID)
+		}
+	}
+}
+
+// wake wakes the streams of a user's inbox. Streams already woken aren't
+// woken twice.
+func (s *InboxService) wake(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for wake := range s.watchers[userID] {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/jobs"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Job errors
+var (
+	ErrJobNotFound = apperrors.NotFound("JOB_NOT_FOUND", "job not found")
+	ErrJobRunning  = apperrors.Conflict("JOB_RUNNING", "job is already running")
+)
+
+// JobService is a service for the admin view of scheduled jobs
+type JobService struct {
+	scheduler *jobs.Scheduler
+	runRepo   *repositories.JobRunRepository
+}
+
+// NewJobService creates a new job service
+func NewJobService(scheduler *jobs.Scheduler, runRepo *repositories.JobRunRepository) *JobService {
+	return &JobService{
+		scheduler: scheduler,
+		runRepo:   runRepo,
+	}
+}
+
+// ListJobs lists the registered jobs with their next and latest run
+func (s *JobService) ListJobs(ctx context.Context) ([]*models.JobResponse, error) {
+	now := time.Now()
+	registered := s.scheduler.Jobs()
+	responses := make([]*models.JobResponse, len(registered))
+	for i, job := range registered {
+		lastRun, err := s.runRepo.Latest(ctx, job.Name)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+		responses[i] = &models.JobResponse{
+			Name:        job.Name,
+			Description: job.Description,
+			Schedule:    job.Schedule.String(),
+			NextRunAt:   job.Schedule.Next(now),
+			LastRun:     lastRun,
+		}
+	}
+	return responses, nil
+}
+
+// ListRuns lists the runs of a job, newest first
+func (s *JobService) ListRuns(ctx context.Context, name string, page, limit int) ([]*jobs.Run, int64, error) {
+	if _, ok := s.scheduler.Job(name); !ok {
+		return nil, 0, ErrJobNotFound
+	}
+	return s.runRepo.List(ctx, name, page, limit)
+}
+
+// TriggerJob starts a run of a job right away. The run continues after the
+// request, and fails if the job is already running on any replica.
+func (s *JobService) TriggerJob(ctx context.Context, name string, userID string) (*jobs.Run, error) {
+	// Jobs work across organizations, like their scheduled runs
+	run, err := s.scheduler.Trigger(tenancy.Unscoped(logger.Detach(ctx)), name, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, jobs.ErrJobNotFound):
+			return nil, ErrJobNotFound
+		case errors.Is(err, jobs.ErrJobRunning), errors.Is(err, jobs.ErrRunExists):
+			return nil, ErrJobRunning
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("job", name).Msg("Failed to trigger job")
+		return nil, err
+	}
+
+	logger.Ctx(ctx).Info().Str("job", name).Str("runId", run.ID).Str("triggeredBy", userID).Msg("Job triggered")
+	return run, nil
+}
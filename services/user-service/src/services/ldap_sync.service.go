@@ -0,0 +1,867 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/ldap"
+	"github.com/your-username/slido-clone/user-service/pkg/lifecycle"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// LDAP sync errors
+var (
+	// ErrLDAPSyncNotConfigured is returned when an organization has no LDAP sync
+	ErrLDAPSyncNotConfigured = apperrors.NotFound("LDAP_SYNC_NOT_CONFIGURED", "organization has no LDAP sync configured")
+	// ErrLDAPSyncRunNotFound is returned when an organization has no LDAP sync report with the ID
+	ErrLDAPSyncRunNotFound = apperrors.NotFound("LDAP_SYNC_RUN_NOT_FOUND", "LDAP sync report not found")
+	// ErrLDAPSyncRunning is returned when starting a sync while another sync of the organization runs
+	ErrLDAPSyncRunning = apperrors.Conflict("LDAP_SYNC_RUNNING", "an LDAP sync of the organization is already running")
+)
+
+// errLDAPSyncNoEntries aborts syncs that would remove every member because
+// the search found nobody, which is more likely a wrong base DN or filter
+// than an empty directory
+var errLDAPSyncNoEntries = errors.New("directory search returned no entries; nothing was removed")
+
+// ldapSyncStaleAfter is how long a sync may run before another may start,
+// so a sync interrupted by a restart doesn't block the organization forever
+const ldapSyncStaleAfter = time.Hour
+
+// ldapSyncBatchSize is how many due syncs the scheduler loads at a time
+const ldapSyncBatchSize = 10
+
+// LDAPSyncService syncs organization members and team members from LDAP and
+// Active Directory servers. Syncs run nightly on a singleton worker or on
+// demand, and every run is reported with the changes it made.
+type LDAPSyncService struct {
+	syncRepo   *repositories.LDAPSyncRepository
+	userRepo   repositories.UserStore
+	orgRepo    repositories.OrgStore
+	teamRepo   repositories.TeamStore
+	orgService *OrganizationService
+	events     kafka.EventPublisher
+	sync       *SyncService
+	config     *config.LDAPConfig
+}
+
+// NewLDAPSyncService creates a new LDAP sync service
+func NewLDAPSyncService(
+	syncRepo *repositories.LDAPSyncRepository,
+	userRepo repositories.UserStore,
+	orgRepo repositories.OrgStore,
+	teamRepo repositories.TeamStore,
+	orgService *OrganizationService,
+	events kafka.EventPublisher,
+	syncService *SyncService,
+	cfg *config.LDAPConfig,
+) *LDAPSyncService {
+	return &LDAPSyncService{
+		syncRepo:   syncRepo,
+		userRepo:   userRepo,
+		orgRepo:    orgRepo,
+		teamRepo:   teamRepo,
+		orgService: orgService,
+		events:     events,
+		sync:       syncService,
+		config:     cfg,
+	}
+}
+
+// GetConfig gets the LDAP sync configuration of an organization
+func (s *LDAPSyncService) GetConfig(ctx context.Context, orgID string, userID string) (*models.LDAPSyncConfig, error) {
+	if _, err := s.getManagedOrganization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.getConfig(ctx, orgID)
+}
+
+// UpdateConfig creates or updates the LDAP sync configuration of an
+// organization. The bind password is kept unless the request sets one.
+func (s *LDAPSyncService) UpdateConfig(ctx context.Context, orgID string, req models.UpdateLDAPSyncRequest, userID string) (*models.LDAPSyncConfig, error) {
+	if _, err := s.getManagedOrganization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	if req.UserFilter != "" {
+		if err := ldap.ValidateFilter(req.UserFilter); err != nil {
+			return nil, apperrors.InvalidField("userFilter", err.Error())
+		}
+	}
+
+	// Mapped teams must belong to the organization
+	for _, mapping := range req.TeamMappings {
+		team, err := s.teamRepo.GetByID(ctx, mapping.TeamID)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+		if team == nil || team.OrganizationID != orgID {
+			return nil, apperrors.InvalidField("teamMappings", "team "+mapping.TeamID+" is not a team of the organization")
+		}
+	}
+
+	config, err := s.syncRepo.GetConfig(ctx, orgID)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+		config = &models.LDAPSyncConfig{OrganizationID: orgID}
+	}
+
+	config.Apply(req, userID)
+	if err := s.syncRepo.SaveConfig(ctx, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// DeleteConfig deletes the LDAP sync configuration of an organization. The
+// members it added stay.
+func (s *LDAPSyncService) DeleteConfig(ctx context.Context, orgID string, userID string) error {
+	if _, err := s.getManagedOrganization(ctx, orgID, userID); err != nil {
+		return err
+	}
+
+	if err := s.syncRepo.DeleteConfig(ctx, orgID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrLDAPSyncNotConfigured
+		}
+		return err
+	}
+	return nil
+}
+
+// RunNow starts a sync of an organization in the background and returns its
+// report, which is updated once the sync finishes
+func (s *LDAPSyncService) RunNow(ctx context.Context, orgID string, req models.RunLDAPSyncRequest, userID string) (*models.LDAPSyncRun, error) {
+	if _, err := s.getManagedOrganization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	config, err := s.getConfig(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := s.start(ctx, config, models.LDAPSyncManual, userID, req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	report := *run
+	detached := tenancy.Inherit(logger.Detach(ctx), ctx)
+	lifecycle.Go(func() { s.execute(detached, config, run) })
+
+	return &report, nil
+}
+
+// ListRuns lists the reports of an organization's syncs, newest first
+func (s *LDAPSyncService) ListRuns(ctx context.Context, orgID string, page, limit int, userID string) ([]*models.LDAPSyncRun, int64, error) {
+	if _, err := s.getManagedOrganization(ctx, orgID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	runs, total, err := s.syncRepo.ListRuns(ctx, orgID, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if runs == nil {
+		runs = []*models.LDAPSyncRun{}
+	}
+	return runs, total, nil
+}
+
+// GetRun gets the report of a sync of an organization, with its changes
+func (s *LDAPSyncService) GetRun(ctx context.Context, orgID, runID string, userID string) (*models.LDAPSyncRun, error) {
+	if _, err := s.getManagedOrganization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	run, err := s.syncRepo.GetRun(ctx, orgID, runID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrLDAPSyncRunNotFound
+		}
+		return nil, err
+	}
+	if run.Changes == nil {
+		run.Changes = []models.LDAPSyncChange{}
+	}
+	return run, nil
+}
+
+// RunScheduler runs due nightly syncs until the context is cancelled. It runs
+// as a singleton worker so each sync runs once.
+func (s *LDAPSyncService) RunScheduler(ctx context.Context) {
+	if s.config.SyncInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		s.runDueSyncs(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDueSyncs runs the sync of every organization with one due
+func (s *LDAPSyncService) runDueSyncs(ctx context.Context) {
+	for {
+		configs, err := s.syncRepo.FindDue(ctx, time.Now(), ldapSyncBatchSize)
+		if err != nil || len(configs) == 0 {
+			return
+		}
+
+		started := 0
+		for _, config := range configs {
+			if ctx.Err() != nil {
+				return
+			}
+
+			// Syncs that can't start, such as while a manual sync runs, are
+			// retried on the next check
+			run, err := s.start(ctx, config, models.LDAPSyncScheduled, "", config.DryRun)
+			if err != nil {
+				logger.Ctx(ctx).Warn().Err(err).Str("orgId", config.OrganizationID).Msg("Failed to start scheduled LDAP sync")
+				continue
+			}
+			s.execute(ctx, config, run)
+			started++
+		}
+		if started == 0 {
+			return
+		}
+	}
+}
+
+// getManagedOrganization gets an organization whose LDAP sync the user manages
+func (s *LDAPSyncService) getManagedOrganization(ctx context.Context, orgID, userID string) (*models.Organization, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for LDAP sync")
+		return nil, err
+	}
+
+	if !org.Can(userID, models.PermOrgManageLDAPSync) {
+		return nil, insufficientPermissions("manage the LDAP sync")
+	}
+	return org, nil
+}
+
+// getConfig gets the LDAP sync configuration of an organization
+func (s *LDAPSyncService) getConfig(ctx context.Context, orgID string) (*models.LDAPSyncConfig, error) {
+	config, err := s.syncRepo.GetConfig(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrLDAPSyncNotConfigured
+		}
+		return nil, err
+	}
+	return config, nil
+}
+
+// start marks a sync of an organization as running and saves its report
+func (s *LDAPSyncService) start(ctx context.Context, config *models.LDAPSyncConfig, trigger models.LDAPSyncTrigger, triggeredBy string, dryRun bool) (*models.LDAPSyncRun, error) {
+	now := time.Now()
+	started, err := s.syncRepo.StartRun(ctx, config.OrganizationID, now, now.Add(-ldapSyncStaleAfter))
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, ErrLDAPSyncRunning
+	}
+
+	run := models.NewLDAPSyncRun(config.OrganizationID, trigger, triggeredBy, dryRun)
+	if err := s.syncRepo.SaveRun(ctx, run); err != nil {
+		s.syncRepo.FinishRun(ctx, config.OrganizationID, run, nil)
+		return nil, err
+	}
+	return run, nil
+}
+
+// execute runs a started sync and saves its report. Scheduled syncs move on
+// to the next night whether or not they succeed.
+func (s *LDAPSyncService) execute(ctx context.Context, config *models.LDAPSyncConfig, run *models.LDAPSyncRun) {
+	err := s.apply(ctx, config, run)
+	run.Finish(err)
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("orgId", config.OrganizationID).Str("runId", run.ID).Msg("LDAP sync failed")
+	}
+
+	if err := s.syncRepo.SaveRun(ctx, run); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("runId", run.ID).Msg("Failed to save LDAP sync report")
+	}
+
+	var nextRunAt *time.Time
+	if run.Trigger == models.LDAPSyncScheduled {
+		next := models.NextLDAPSyncAt(time.Now(), config.SyncHour)
+		nextRunAt = &next
+	}
+	if err := s.syncRepo.FinishRun(ctx, config.OrganizationID, run, nextRunAt); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("runId", run.ID).Msg("Failed to finish LDAP sync")
+	}
+}
+
+// ldapSyncState tracks who a sync found in the directory
+type ldapSyncState struct {
+	org    *models.Organization
+	teams  map[string]*models.Team
+	emails map[string]bool
+	// members and teamMembers hold the users found, per team for teamMembers
+	members     map[string]bool
+	teamMembers map[string]map[string]bool
+}
+
+// apply reads the directory and applies it to the organization, or only
+// records the changes in a dry run
+func (s *LDAPSyncService) apply(ctx context.Context, config *models.LDAPSyncConfig, run *models.LDAPSyncRun) error {
+	org, err := s.orgRepo.GetByID(ctx, config.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("get organization: %w", err)
+	}
+
+	entries, err := s.search(ctx, config)
+	if err != nil {
+		return err
+	}
+	run.Summary.EntriesRead = len(entries)
+
+	state := &ldapSyncState{
+		org:         org,
+		teams:       make(map[string]*models.Team, len(config.TeamMappings)),
+		emails:      make(map[string]bool, len(entries)),
+		members:     make(map[string]bool, len(entries)),
+		teamMembers: make(map[string]map[string]bool, len(config.TeamMappings)),
+	}
+
+	// Teams deleted, moved or archived since they were mapped are skipped
+	for _, mapping := range config.TeamMappings {
+		team, err := s.teamRepo.GetByID(ctx, mapping.TeamID)
+		if err == nil && team.IsArchived() {
+			logger.Ctx(ctx).Info().Str("orgId", org.ID).Str("teamId", mapping.TeamID).Msg("Skipping LDAP team mapping of an archived team")
+			continue
+		}
+		if err != nil || team.OrganizationID != org.ID {
+			logger.Ctx(ctx).Warn().Str("orgId", org.ID).Str("teamId", mapping.TeamID).Msg("Skipping LDAP team mapping of a missing team")
+			continue
+		}
+		state.teams[team.ID] = team
+		state.teamMembers[team.ID] = make(map[string]bool)
+	}
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.applyEntry(ctx, config, run, state, entry)
+	}
+
+	if !config.RemoveMissing {
+		return nil
+	}
+	if len(entries) == 0 {
+		return errLDAPSyncNoEntries
+	}
+	s.removeMissing(ctx, run, state)
+	return nil
+}
+
+// search reads the users of the directory
+func (s *LDAPSyncService) search(ctx context.Context, config *models.LDAPSyncConfig) ([]*ldap.Entry, error) {
+	conn, err := ldap.Dial(ctx, config.URL, &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}, s.config.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("connect to directory: %w", err)
+	}
+	defer conn.Close()
+
+	if config.BindDN != "" {
+		if err := conn.Bind(config.BindDN, config.BindPassword); err != nil {
+			return nil, fmt.Errorf("bind to directory: %w", err)
+		}
+	}
+
+	// A partial read would remove everyone past the limit, so the sync
+	// stops instead
+	entries, err := conn.Search(ldap.SearchRequest{
+		BaseDN:     config.BaseDN,
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     config.UserFilter,
+		Attributes: config.SearchAttributes(),
+		PageSize:   s.config.PageSize,
+		SizeLimit:  s.config.MaxEntries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search directory: %w", err)
+	}
+	return entries, nil
+}
+
+// applyEntry creates or updates the user of a directory entry and adds them
+// to the organization and their mapped teams. Users the organization's join
+// policies reject are skipped, and only users of the organization alone have
+// their profile updated.
+func (s *LDAPSyncService) applyEntry(ctx context.Context, config *models.LDAPSyncConfig, run *models.LDAPSyncRun, state *ldapSyncState, entry *ldap.Entry) {
+	email := strings.ToLower(strings.TrimSpace(entry.Value(config.Attributes.Email)))
+	skip := func(reason string) {
+		run.Record(models.LDAPSyncChange{Action: models.LDAPSyncEntrySkipped, DN: entry.DN, Email: email, Reason: reason})
+	}
+	switch {
+	case !strings.Contains(email, "@"):
+		skip("entry has no email")
+		return
+	case state.emails[email]:
+		skip("another entry has the same email")
+		return
+	}
+	state.emails[email] = true
+
+	firstName := strings.TrimSpace(entry.Value(config.Attributes.FirstName))
+	lastName := strings.TrimSpace(entry.Value(config.Attributes.LastName))
+	jobTitle := ""
+	if config.Attributes.JobTitle != "" {
+		jobTitle = strings.TrimSpace(entry.Value(config.Attributes.JobTitle))
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		run.Record(models.LDAPSyncChange{Action: models.LDAPSyncUserCreated, DN: entry.DN, Email: email, Error: err.Error()})
+		return
+	}
+
+	if user == nil {
+		if firstName == "" || lastName == "" {
+			skip("entry has no first or last name")
+			return
+		}
+
+		// Like SCIM, directory users get a generated user ID until they sign
+		// in for the first time
+		user = models.NewUser(models.CreateUserRequest{
+			UserID:    uuid.New().String(),
+			Email:     email,
+			FirstName: firstName,
+			LastName:  lastName,
+			Role:      models.RoleUser,
+		})
+		user.JobTitle = jobTitle
+		user.Preferences = user.Preferences.Resolve(state.org.Settings.DefaultPreferences)
+
+		// New users have no two-factor authentication
+		if reason := joinViolation(state.org, user); reason != "" {
+			skip(reason)
+			return
+		}
+
+		change := models.LDAPSyncChange{Action: models.LDAPSyncUserCreated, DN: entry.DN, Email: email}
+		if run.DryRun {
+			// The user doesn't exist, so nothing refers to their ID
+			user.UserID = ""
+		} else if err := s.userRepo.Create(ctx, user); err != nil {
+			change.Error = err.Error()
+			run.Record(change)
+			return
+		}
+		change.UserID = user.UserID
+		run.Record(change)
+	} else {
+		if !state.org.IsMember(user.UserID) {
+			if user.IsPendingReview() {
+				skip(ErrUserPendingReview.Message)
+				return
+			}
+			if reason := joinViolation(state.org, user); reason != "" {
+				skip(reason)
+				return
+			}
+		}
+
+		if user.BelongsOnlyTo(state.org.ID) && ldapSyncUserFields(user, firstName, lastName, jobTitle) {
+			change := models.LDAPSyncChange{Action: models.LDAPSyncUserUpdated, DN: entry.DN, Email: email, UserID: user.UserID}
+			if !run.DryRun {
+				if err := s.saveUser(ctx, user); err != nil {
+					change.Error = err.Error()
+				}
+			}
+			run.Record(change)
+		}
+	}
+
+	if user.UserID != "" {
+		state.members[user.UserID] = true
+	}
+	s.applyMembership(ctx, config, run, state, entry, user)
+
+	for _, teamID := range config.TeamIDs(entry.DN) {
+		team, ok := state.teams[teamID]
+		if !ok {
+			continue
+		}
+		if user.UserID != "" {
+			state.teamMembers[teamID][user.UserID] = true
+		}
+		if user.UserID != "" && team.IsMember(user.UserID) {
+			continue
+		}
+
+		change := models.LDAPSyncChange{Action: models.LDAPSyncTeamMemberAdded, DN: entry.DN, Email: email, UserID: user.UserID, TeamID: team.ID, Role: string(models.TeamRoleMember)}
+		if !run.DryRun {
+			if err := s.addTeamMember(ctx, team, user.UserID); err != nil {
+				change.Error = err.Error()
+			}
+		}
+		run.Record(change)
+	}
+}
+
+// applyMembership adds the user of a directory entry to the organization or
+// changes their role. Members the sync added follow the role mappings, and
+// falling out of every mapping gives them the default role back; members
+// added otherwise only change role when a mapping matches. Owners never
+// change role.
+func (s *LDAPSyncService) applyMembership(ctx context.Context, config *models.LDAPSyncConfig, run *models.LDAPSyncRun, state *ldapSyncState, entry *ldap.Entry, user *models.User) {
+	org := state.org
+	defaultRole := org.Settings.DefaultUserRole
+	if defaultRole == "" || defaultRole == models.OrgRoleOwner {
+		defaultRole = models.OrgRoleMember
+	}
+	mappedRole := config.Role(entry.Values)
+
+	var member *models.OrganizationMember
+	if user.UserID != "" {
+		member = org.GetMember(user.UserID)
+	}
+
+	if member == nil {
+		role := mappedRole
+		if role == "" {
+			role = defaultRole
+		}
+
+		change := models.LDAPSyncChange{Action: models.LDAPSyncMemberAdded, DN: entry.DN, Email: user.Email, UserID: user.UserID, Role: string(role)}
+		if !run.DryRun {
+			if err := s.addMember(ctx, org, user, role); err != nil {
+				change.Error = err.Error()
+			}
+		}
+		run.Record(change)
+		return
+	}
+
+	if member.Role == models.OrgRoleOwner {
+		return
+	}
+	role := mappedRole
+	if role == "" && member.InvitedBy == models.LDAPSyncActor {
+		role = defaultRole
+	}
+	if role == "" || role == member.Role {
+		return
+	}
+
+	change := models.LDAPSyncChange{Action: models.LDAPSyncMemberRoleChanged, DN: entry.DN, Email: user.Email, UserID: user.UserID, Role: string(role), PreviousRole: string(member.Role)}
+	if !run.DryRun {
+		if err := s.updateMemberRole(ctx, org, user, member.Role, role); err != nil {
+			change.Error = err.Error()
+		}
+	}
+	run.Record(change)
+}
+
+// removeMissing removes the members and team members the sync added that
+// it no longer found in the directory or their mapped OU. Owners are never
+// removed.
+func (s *LDAPSyncService) removeMissing(ctx context.Context, run *models.LDAPSyncRun, state *ldapSyncState) {
+	removed := make(map[string]bool)
+	for _, member := range append([]models.OrganizationMember(nil), state.org.Members...) {
+		if member.InvitedBy != models.LDAPSyncActor || member.Role == models.OrgRoleOwner || state.members[member.UserID] {
+			continue
+		}
+
+		change := models.LDAPSyncChange{Action: models.LDAPSyncMemberRemoved, UserID: member.UserID, PreviousRole: string(member.Role)}
+		if !run.DryRun {
+			if err := s.removeMember(ctx, state.org, member.UserID); err != nil {
+				change.Error = err.Error()
+			}
+		}
+		removed[member.UserID] = true
+		run.Record(change)
+	}
+
+	for teamID, team := range state.teams {
+		for _, member := range append([]models.TeamMember(nil), team.Members...) {
+			if member.InvitedBy != models.LDAPSyncActor || member.Role == models.TeamRoleOwner ||
+				state.teamMembers[teamID][member.UserID] || removed[member.UserID] {
+				continue
+			}
+
+			change := models.LDAPSyncChange{Action: models.LDAPSyncTeamMemberRemoved, UserID: member.UserID, TeamID: teamID}
+			if !run.DryRun {
+				if err := s.removeTeamMember(ctx, team, member.UserID); err != nil {
+					change.Error = err.Error()
+				}
+			}
+			run.Record(change)
+		}
+	}
+}
+
+// saveUser persists a user the sync updated and publishes an update event
+func (s *LDAPSyncService) saveUser(ctx context.Context, user *models.User) error {
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to update LDAP user")
+		return err
+	}
+
+	if err := s.events.PublishUserEvent(ctx, kafka.UserUpdated, user.ToResponse(), user.ID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to publish user.updated event")
+	}
+	return nil
+}
+
+// addMember adds a user to the organization, once its join policies and
+// approval webhook allow it
+func (s *LDAPSyncService) addMember(ctx context.Context, org *models.Organization, user *models.User, role models.OrganizationMemberRole) error {
+	if err := s.orgService.checkMemberAdd(ctx, org, user, role, models.LDAPSyncActor, "email"); err != nil {
+		return err
+	}
+
+	if err := s.orgRepo.AddMember(ctx, org.ID, user.UserID, role, models.LDAPSyncActor); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", user.UserID).
+			Msg("Failed to add LDAP user to organization")
+		return err
+	}
+	org.AddMember(user.UserID, role, models.LDAPSyncActor)
+
+	if err := s.userRepo.AddOrganizationToUser(ctx, user.UserID, org.ID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", user.UserID).
+			Msg("Failed to add organization to LDAP user")
+		// Don't fail the operation, but log the error
+	}
+
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationMemberAdded,
+		kafka.OrganizationMemberAddedV1{
+			OrgID:     org.ID,
+			OrgName:   org.Name,
+			UserID:    user.UserID,
+			UserEmail: user.Email,
+			UserName:  user.FirstName + " " + user.LastName,
+			Role:      string(role),
+			InvitedBy: models.LDAPSyncActor,
+			JoinedAt:  time.Now(),
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", user.UserID).
+			Msg("Failed to publish organization.member.added event")
+	}
+	return nil
+}
+
+// updateMemberRole changes the role of a member of the organization. Like
+// any role escalation, a higher role needs two-factor authentication, when
+// the organization asks for it, and external approval.
+func (s *LDAPSyncService) updateMemberRole(ctx context.Context, org *models.Organization, user *models.User, previous, role models.OrganizationMemberRole) error {
+	userID := user.UserID
+	if role.Rank() > previous.Rank() {
+		if err := checkTwoFactor(org, user, "role"); err != nil {
+			return err
+		}
+		err := s.orgService.requestApproval(ctx, org, models.NewApprovalRequest(
+			models.ApprovalActionRoleEscalation, org.ID, userID, role, previous, models.LDAPSyncActor,
+		))
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.orgRepo.UpdateMemberRole(ctx, org.ID, userID, role); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", userID).
+			Msg("Failed to update LDAP member role")
+		return err
+	}
+	org.UpdateMember(userID, role)
+
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationMemberUpdated,
+		kafka.OrganizationMemberUpdatedV1{
+			OrgID:     org.ID,
+			OrgName:   org.Name,
+			UserID:    userID,
+			Role:      string(role),
+			UpdatedBy: models.LDAPSyncActor,
+			UpdatedAt: time.Now(),
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", userID).
+			Msg("Failed to publish organization.member.updated event")
+	}
+	return nil
+}
+
+// removeMember removes a user from the organization and its teams
+func (s *LDAPSyncService) removeMember(ctx context.Context, org *models.Organization, userID string) error {
+	teams, _, err := s.teamRepo.FindTeams(ctx, bson.M{
+		"organizationId": org.ID,
+		"members.userId": userID,
+	}, 0, 0)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", userID).
+			Msg("Failed to get teams of removed LDAP member")
+		return err
+	}
+	for _, team := range teams {
+		if err := s.removeTeamMember(ctx, team, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.orgRepo.RemoveMember(ctx, org.ID, userID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", userID).
+			Msg("Failed to remove LDAP member from organization")
+		return err
+	}
+
+	if err := s.userRepo.RemoveOrganizationFromUser(ctx, userID, org.ID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", userID).
+			Msg("Failed to remove organization from LDAP member")
+		// Don't fail the operation, but log the error
+	}
+	s.sync.RecordMemberRemoval(ctx, org.ID, userID)
+
+	// Free the member's seat
+	member := org.GetMember(userID)
+	org.RemoveMember(userID)
+	if member != nil && member.Licensed {
+		publishSeatChanged(ctx, s.events, kafka.SeatUnassigned, org, userID, models.LDAPSyncActor, org.LicensedMembers())
+	}
+
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationMemberRemoved,
+		kafka.OrganizationMemberRemovedV1{
+			OrgID:     org.ID,
+			OrgName:   org.Name,
+			UserID:    userID,
+			RemovedBy: models.LDAPSyncActor,
+			RemovedAt: time.Now(),
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", userID).
+			Msg("Failed to publish organization.member.removed event")
+	}
+	return nil
+}
+
+// addTeamMember adds a user to a mapped team
+func (s *LDAPSyncService) addTeamMember(ctx context.Context, team *models.Team, userID string) error {
+	if err := s.teamRepo.AddMember(ctx, team.ID, userID, models.TeamRoleMember, models.LDAPSyncActor); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", userID).
+			Msg("Failed to add LDAP user to team")
+		return err
+	}
+	team.AddMember(userID, models.TeamRoleMember, models.LDAPSyncActor)
+
+	if err := s.userRepo.AddTeamToUser(ctx, userID, team.ID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", userID).
+			Msg("Failed to add team to LDAP user")
+	}
+
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamMemberAdded,
+		kafka.TeamMemberAddedV1{
+			TeamID:    team.ID,
+			TeamName:  team.Name,
+			UserID:    userID,
+			Role:      string(models.TeamRoleMember),
+			InvitedBy: models.LDAPSyncActor,
+			JoinedAt:  time.Now(),
+		},
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", userID).
+			Msg("Failed to publish team.member.added event")
+	}
+	return nil
+}
+
+// removeTeamMember removes a user from a team
+func (s *LDAPSyncService) removeTeamMember(ctx context.Context, team *models.Team, userID string) error {
+	if err := s.teamRepo.RemoveMember(ctx, team.ID, userID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", userID).
+			Msg("Failed to remove LDAP member from team")
+		return err
+	}
+	team.RemoveMember(userID)
+
+	if err := s.userRepo.RemoveTeamFromUser(ctx, userID, team.ID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", userID).
+			Msg("Failed to remove team from LDAP member")
+	}
+
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamMemberRemoved,
+		kafka.TeamMemberRemovedV1{
+			TeamID:    team.ID,
+			TeamName:  team.Name,
+			UserID:    userID,
+			RemovedBy: models.LDAPSyncActor,
+			RemovedAt: time.Now(),
+		},
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", userID).
+			Msg("Failed to publish team.member.removed event")
+	}
+	return nil
+}
+
+// ldapSyncUserFields copies the directory's names and job title to a user,
+// reporting whether any changed. Empty directory values are ignored.
+func ldapSyncUserFields(user *models.User, firstName, lastName, jobTitle string) bool {
+	changed := false
+	if firstName != "" && user.FirstName != firstName {
+		user.FirstName = firstName
+		changed = true
+	}
+	if lastName != "" && user.LastName != lastName {
+		user.LastName = lastName
+		changed = true
+	}
+	if jobTitle != "" && user.JobTitle != jobTitle {
+		user.JobTitle = jobTitle
+		changed = true
+	}
+	return changed
+}
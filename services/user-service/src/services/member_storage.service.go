@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MemberStorageService moves organization members between the embedded and
+// the members collection layouts. Organizations that embed more members than
+// the quota are moved to the members collection, so their document stays
+// well below MongoDB's 16MB limit.
+type MemberStorageService struct {
+	orgRepo repositories.OrgStore
+	config  *config.OrganizationConfig
+}
+
+// NewMemberStorageService creates a new member storage service
+func NewMemberStorageService(orgRepo repositories.OrgStore, cfg *config.OrganizationConfig) *MemberStorageService {
+	return &MemberStorageService{
+		orgRepo: orgRepo,
+		config:  cfg,
+	}
+}
+
+// SetMemberStorage moves the members of an organization to a storage layout
+func (s *MemberStorageService) SetMemberStorage(ctx context.Context, orgID string, storage models.MemberStorage) (*models.Organization, error) {
+	if _, err := s.orgRepo.GetByID(ctx, orgID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+
+	var err error
+	if storage == models.MemberStorageCollection {
+		err = s.orgRepo.MoveMembersToCollection(ctx, orgID)
+	} else {
+		err = s.orgRepo.MoveMembersToDocument(ctx, orgID)
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("storage", string(storage)).Msg("Failed to move organization members")
+		return nil, err
+	}
+
+	return s.orgRepo.GetByID(ctx, orgID)
+}
+
+// RunMover periodically moves the members of organizations over the member
+// quota to the members collection, until ctx is cancelled
+func (s *MemberStorageService) RunMover(ctx context.Context) {
+	if s.config.MemberQuota <= 0 || s.config.MemberStorageInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.MemberStorageInterval)
+	defer ticker.Stop()
+
+	for {
+		s.moveOversized(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// moveOversized moves the members of every organization over the quota
+func (s *MemberStorageService) moveOversized(ctx context.Context) {
+	orgIDs, err := s.orgRepo.FindOversizedOrganizations(ctx, s.config.MemberQuota)
+	if err != nil {
+		return
+	}
+
+	for _, orgID := range orgIDs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Organizations that changed while moving are retried on the next run
+		if err := s.orgRepo.MoveMembersToCollection(ctx, orgID); err != nil {
+			logger.Ctx(ctx).Warn().Err(err).Str("orgId", orgID).Msg("Failed to move members of oversized organization")
+		}
+	}
+}
@@ -0,0 +1,508 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// digestBatchSize is the most users sent a digest per run
+const digestBatchSize = 100
+
+// unknownActorName stands in for who made a change in rendered notifications
+// when the actor isn't known, such as for changes made by directory syncs
+const unknownActorName = "An administrator"
+
+// NotificationService dispatches notifications about published membership
+// events. Each notification is filtered by its user's notification settings,
+// then requested from the notification service right away or held for the
+// user's daily digest. Notifications about an organization are rendered with
+// its email templates and branding when they are requested. In-app
+// notifications go to the user's inbox right away, even for users that get a
+// digest.
+type NotificationService struct {
+	digestRepo *repositories.DigestRepository
+	userRepo   repositories.UserStore
+	inbox      *InboxService
+	templates  *EmailTemplateService
+	producer   *kafka.Producer
+	config     *config.NotificationConfig
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(
+	digestRepo *repositories.DigestRepository,
+	userRepo repositories.UserStore,
+	inbox *InboxService,
+	templates *EmailTemplateService,
+	producer *kafka.Producer,
+	cfg *config.NotificationConfig,
+) *NotificationService {
+	return &NotificationService{
+		digestRepo: digestRepo,
+		userRepo:   userRepo,
+		inbox:      inbox,
+		templates:  templates,
+		producer:   producer,
+		config:     cfg,
+	}
+}
+
+// HandleEvent dispatches the notification of a published event. Events
+// without a notification are ignored.
+func (s *NotificationService) HandleEvent(ctx context.Context, event kafka.Event) {
+	notification, err := notificationFromEvent(event)
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("eventId", event.ID).Str("eventType", string(event.Type)).
+			Msg("Failed to build notification from event")
+		return
+	}
+	if notification == nil {
+		return
+	}
+
+	if err := s.dispatch(ctx, notification); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("eventId", event.ID).Str("userId", notification.UserID).
+			Msg("Failed to dispatch notification")
+	}
+}
+
+// notificationFromEvent builds the notification of an event, or nil for
+// events that don't notify anyone. Users aren't notified of their own
+// actions, such as leaving an organization.
+func notificationFromEvent(event kafka.Event) (*models.Notification, error) {
+	var notification *models.Notification
+
+	switch event.Type {
+	case kafka.OrganizationMemberAdded:
+		var data kafka.OrganizationMemberAddedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		notification = models.NewNotification(data.UserID, models.NotificationOrganizationMemberAdded, event.ID, event.Time)
+		notification.ActorID = data.InvitedBy
+		notification.OrganizationID = data.OrgID
+		notification.OrganizationName = data.OrgName
+		notification.Role = data.Role
+
+	case kafka.OrganizationMemberUpdated:
+		var data kafka.OrganizationMemberUpdatedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		notification = models.NewNotification(data.UserID, models.NotificationOrganizationRoleChanged, event.ID, event.Time)
+		notification.ActorID = data.UpdatedBy
+		notification.OrganizationID = data.OrgID
+		notification.OrganizationName = data.OrgName
+		notification.Role = data.Role
+
+	case kafka.OrganizationMemberRemoved:
+		var data kafka.OrganizationMemberRemovedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		notification = models.NewNotification(data.UserID, models.NotificationOrganizationMemberRemoved, event.ID, event.Time)
+		notification.ActorID = data.RemovedBy
+		notification.OrganizationID = data.OrgID
+		notification.OrganizationName = data.OrgName
+
+	case kafka.TeamMemberAdded:
+		var data kafka.TeamMemberAddedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		notification = models.NewNotification(data.UserID, models.NotificationTeamMemberAdded, event.ID, event.Time)
+		notification.ActorID = data.InvitedBy
+		notification.TeamID = data.TeamID
+		notification.TeamName = data.TeamName
+		notification.Role = data.Role
+
+	case kafka.TeamMemberUpdated:
+		var data kafka.TeamMemberUpdatedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		notification = models.NewNotification(data.UserID, models.NotificationTeamRoleChanged, event.ID, event.Time)
+		notification.ActorID = data.UpdatedBy
+		notification.TeamID = data.TeamID
+		notification.TeamName = data.TeamName
+		notification.Role = data.Role
+
+	case kafka.TeamMemberRemoved:
+		var data kafka.TeamMemberRemovedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		notification = models.NewNotification(data.UserID, models.NotificationTeamMemberRemoved, event.ID, event.Time)
+		notification.ActorID = data.RemovedBy
+		notification.TeamID = data.TeamID
+		notification.TeamName = data.TeamName
+
+	case kafka.OrganizationOwnershipTransferRequested, kafka.TeamOwnershipTransferRequested:
+		var data kafka.OwnershipTransferRequestedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		notification = models.NewNotification(data.ToUserID, models.NotificationOwnershipTransferRequested, event.ID, event.Time)
+		notification.ActorID = data.FromUserID
+		notification.OrganizationID = data.OrgID
+		notification.OrganizationName = data.OrgName
+		notification.TeamID = data.TeamID
+		notification.TeamName = data.TeamName
+
+	case kafka.OrganizationJoinRequested:
+		// Join requests are reviewed by the organization, while invitations
+		// are for the invited user to accept
+		var data kafka.JoinRequestCreatedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		if data.InvitedBy == "" {
+			return nil, nil
+		}
+		notification = models.NewNotification(data.UserID, models.NotificationOrganizationMemberInvited, event.ID, event.Time)
+		notification.ActorID = data.InvitedBy
+		notification.OrganizationID = data.OrgID
+		notification.OrganizationName = data.OrgName
+		notification.Role = data.Role
+
+	case kafka.OrganizationJoinRequestRejected:
+		// Approved requests are notified as the member being added
+		var data kafka.JoinRequestResolvedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return nil, err
+		}
+		notification = models.NewNotification(data.UserID, models.NotificationJoinRequestRejected, event.ID, event.Time)
+		notification.ActorID = data.ReviewedBy
+		notification.OrganizationID = data.OrgID
+		notification.OrganizationName = data.OrgName
+		notification.Reason = data.ReviewReason
+
+	default:
+		return nil, nil
+	}
+
+	if notification.ActorID == notification.UserID {
+		return nil, nil
+	}
+	return notification, nil
+}
+
+// dispatch requests a notification right away, or holds it for the user's
+// daily digest. Users that turned off every channel, or aren't active, get
+// no notifications, except invited users who are emailed their invitation.
+func (s *NotificationService) dispatch(ctx context.Context, notification *models.Notification) error {
+	user, err := s.userRepo.GetByUserId(ctx, notification.UserID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+		return err
+	}
+
+	// Users added before they have an account are invited; they have no
+	// settings of their own yet, and find the invitation in their inbox once
+	// they sign in
+	if notification.Type == models.NotificationOrganizationMemberAdded && user.Status == models.StatusPending {
+		notification.Type = models.NotificationOrganizationMemberInvited
+		if err := s.inbox.Add(ctx, notification); err != nil {
+			return err
+		}
+		if user.Email == "" {
+			return nil
+		}
+		channels := []models.NotificationChannel{models.NotificationChannelEmail}
+		return s.publishRequested(ctx, user, channels, false, []*models.Notification{notification})
+	}
+
+	channels := user.Preferences.NotificationChannels()
+	if len(channels) == 0 || user.Status != models.StatusActive {
+		logger.Ctx(ctx).Debug().Str("userId", user.UserID).Str("type", string(notification.Type)).
+			Msg("Skipping notification for user that doesn't get notifications")
+		return nil
+	}
+
+	// In-app notifications are delivered by the inbox, the other channels by
+	// the notification service
+	channels, inApp := withoutInApp(channels)
+	if inApp {
+		if err := s.inbox.Add(ctx, notification); err != nil {
+			return err
+		}
+	}
+	if len(channels) == 0 {
+		return nil
+	}
+
+	if user.Preferences.WantsDigest() {
+		notification.DueAt = models.NextDigestAt(notification.OccurredAt, user.Preferences.Timezone, s.config.DigestHour)
+		return s.digestRepo.Queue(ctx, notification)
+	}
+
+	return s.publishRequested(ctx, user, channels, false, []*models.Notification{notification})
+}
+
+// withoutInApp leaves the in-app channel out of channels, reporting if it was
+// in them
+func withoutInApp(channels []models.NotificationChannel) ([]models.NotificationChannel, bool) {
+	others := make([]models.NotificationChannel, 0, len(channels))
+	inApp := false
+	for _, channel := range channels {
+		if channel == models.NotificationChannelInApp {
+			inApp = true
+		} else {
+			others = append(others, channel)
+		}
+	}
+	return others, inApp
+}
+
+// RunDigests sends due daily digests until the context is cancelled. It runs
+// as a singleton worker so each digest is sent once.
+func (s *NotificationService) RunDigests(ctx context.Context) {
+	if s.config.DigestInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.DigestInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sendDueDigests(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendDueDigests sends the digest of every user with one due
+func (s *NotificationService) sendDueDigests(ctx context.Context) {
+	for {
+		userIDs, err := s.digestRepo.FindDueUsers(ctx, time.Now(), digestBatchSize)
+		if err != nil || len(userIDs) == 0 {
+			return
+		}
+
+		for _, userID := range userIDs {
+			if ctx.Err() != nil {
+				return
+			}
+
+			// Digests that fail are retried on the next run
+			if err := s.sendDigest(ctx, userID); err != nil {
+				logger.Ctx(ctx).Warn().Err(err).Str("userId", userID).Msg("Failed to send notification digest")
+				return
+			}
+		}
+	}
+}
+
+// sendDigest sends a user's due notifications as one digest. Notifications
+// beyond the digest size are sent as a further digest, and users that stopped
+// getting notifications since they were held are sent nothing.
+func (s *NotificationService) sendDigest(ctx context.Context, userID string) error {
+	notifications, err := s.digestRepo.FindDue(ctx, userID, time.Now(), models.MaxDigestNotifications)
+	if err != nil || len(notifications) == 0 {
+		return err
+	}
+
+	user, err := s.userRepo.GetByUserId(ctx, userID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	if user != nil && user.Status == models.StatusActive {
+		if channels, _ := withoutInApp(user.Preferences.NotificationChannels()); len(channels) > 0 {
+			if err := s.publishRequested(ctx, user, channels, true, notifications); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, notification := range notifications {
+		if err := s.digestRepo.Delete(ctx, notification.ID); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+	}
+
+	logger.Ctx(ctx).Debug().Str("userId", userID).Int("count", len(notifications)).Msg("Notification digest sent")
+	return nil
+}
+
+// publishRequested publishes a notification.requested event to the
+// notification topic for the notification service to deliver notifications
+// to a user
+func (s *NotificationService) publishRequested(ctx context.Context, user *models.User, channels []models.NotificationChannel, digest bool, notifications []*models.Notification) error {
+	messages, err := s.renderMessages(ctx, user, notifications)
+	if err != nil {
+		return err
+	}
+
+	payload := kafka.NotificationRequestedV1{
+		UserID:        user.UserID,
+		Email:         user.Email,
+		Language:      user.Preferences.Language,
+		Timezone:      user.Preferences.Timezone,
+		Channels:      make([]string, len(channels)),
+		Digest:        digest,
+		Notifications: make([]kafka.NotificationV1, len(notifications)),
+		RequestedAt:   time.Now(),
+	}
+	for i, channel := range channels {
+		payload.Channels[i] = string(channel)
+	}
+	for i, n := range notifications {
+		payload.Notifications[i] = kafka.NotificationV1{
+			ID:         n.ID,
+			Type:       string(n.Type),
+			ActorID:    n.ActorID,
+			OrgID:      n.OrganizationID,
+			OrgName:    n.OrganizationName,
+			TeamID:     n.TeamID,
+			TeamName:   n.TeamName,
+			Role:       n.Role,
+			Reason:     n.Reason,
+			OccurredAt: n.OccurredAt,
+			Message:    messages[i],
+		}
+	}
+
+	err = s.producer.PublishNotificationEvent(ctx, kafka.NotificationRequested, payload, user.UserID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to publish notification.requested event")
+		return err
+	}
+	return nil
+}
+
+// renderMessages renders the notifications about an organization with its
+// email templates and branding. The templates of each organization and the
+// name of each actor are read once per request; notifications without a
+// template get no message.
+func (s *NotificationService) renderMessages(ctx context.Context, user *models.User, notifications []*models.Notification) ([]*kafka.NotificationMessageV1, error) {
+	messages := make([]*kafka.NotificationMessageV1, len(notifications))
+	orgTemplates := make(map[string]*models.OrganizationEmailTemplatesResponse)
+	actorNames := make(map[string]string)
+
+	for i, n := range notifications {
+		key, ok := n.EmailTemplate()
+		if !ok {
+			continue
+		}
+
+		templates, ok := orgTemplates[n.OrganizationID]
+		if !ok {
+			var err error
+			templates, err = s.templates.GetEffectiveTemplates(ctx, n.OrganizationID)
+			if errors.Is(err, ErrOrganizationNotFound) {
+				// Members are still told about organizations deleted since
+				templates = &models.OrganizationEmailTemplatesResponse{
+					OrganizationID: n.OrganizationID,
+					Branding:       models.EmailTemplateBranding{OrgName: n.OrganizationName},
+				}
+			} else if err != nil {
+				return nil, err
+			}
+			orgTemplates[n.OrganizationID] = templates
+		}
+
+		actorName, ok := actorNames[n.ActorID]
+		if !ok {
+			var err error
+			actorName, err = s.actorName(ctx, n.ActorID)
+			if err != nil {
+				return nil, err
+			}
+			actorNames[n.ActorID] = actorName
+		}
+
+		messages[i] = s.renderMessage(user, n, key, templates, actorName)
+	}
+
+	return messages, nil
+}
+
+// renderMessage renders a notification with the organization's override of
+// its email template, or the platform default
+func (s *NotificationService) renderMessage(user *models.User, n *models.Notification, key models.EmailTemplateKey, templates *models.OrganizationEmailTemplatesResponse, actorName string) *kafka.NotificationMessageV1 {
+	content, version := models.DefaultEmailTemplates[key], 0
+	variables := make(map[string]string)
+	for _, template := range templates.Templates {
+		if template.Key == key {
+			content, version = template.Content(), template.Version
+			for name, value := range template.Variables {
+				variables[name] = value
+			}
+			break
+		}
+	}
+
+	branding := templates.Branding
+	actionURL := s.actionURL(n)
+	variables["orgName"] = branding.OrgName
+	variables["orgLogoUrl"] = branding.OrgLogoURL
+	variables["primaryColor"] = branding.PrimaryColor
+	variables["secondaryColor"] = branding.SecondaryColor
+	variables["userName"] = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	variables["userEmail"] = user.Email
+	variables["actorName"] = actorName
+	variables["actionUrl"] = actionURL
+	variables["role"] = n.Role
+
+	rendered := content.Render(variables)
+	return &kafka.NotificationMessageV1{
+		Template:        string(key),
+		TemplateVersion: version,
+		Subject:         rendered.Subject,
+		IntroText:       rendered.IntroText,
+		ActionURL:       actionURL,
+		Branding: kafka.NotificationBrandingV1{
+			OrgName:        branding.OrgName,
+			OrgLogoURL:     branding.OrgLogoURL,
+			PrimaryColor:   branding.PrimaryColor,
+			SecondaryColor: branding.SecondaryColor,
+		},
+	}
+}
+
+// actionURL gets the page of the web app a notification links to: the
+// organization, or the home page once the user has lost access to it
+func (s *NotificationService) actionURL(n *models.Notification) string {
+	appURL := strings.TrimRight(s.config.AppURL, "/")
+	switch n.Type {
+	case models.NotificationOrganizationMemberRemoved, models.NotificationJoinRequestRejected:
+		return appURL
+	default:
+		return appURL + "/organizations/" + n.OrganizationID
+	}
+}
+
+// actorName gets the name of the user who made a change
+func (s *NotificationService) actorName(ctx context.Context, actorID string) (string, error) {
+	if actorID == "" {
+		return unknownActorName, nil
+	}
+
+	actor, err := s.userRepo.GetByUserId(ctx, actorID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return unknownActorName, nil
+		}
+		return "", err
+	}
+
+	if name := strings.TrimSpace(actor.FirstName + " " + actor.LastName); name != "" {
+		return name, nil
+	}
+	return unknownActorName, nil
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// OnboardingService tracks users through the onboarding checklist. Steps
+// advance from published events: completing the profile, joining or creating
+// an organization, and creating a team. Once every step is done or skipped,
+// onboarding.completed is published for lifecycle email campaigns.
+type OnboardingService struct {
+	userRepo *repositories.UserRepository
+	events   kafka.EventPublisher
+}
+
+// NewOnboardingService creates a new onboarding service
+func NewOnboardingService(userRepo *repositories.UserRepository, events kafka.EventPublisher) *OnboardingService {
+	return &OnboardingService{
+		userRepo: userRepo,
+		events:   events,
+	}
+}
+
+// GetOnboarding gets a user's onboarding checklist
+func (s *OnboardingService) GetOnboarding(ctx context.Context, userID string) (*models.OnboardingResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := user.Onboarding.ToResponse()
+	return &response, nil
+}
+
+// UpdateOnboarding skips onboarding steps, and dismisses the checklist or
+// brings it back
+func (s *OnboardingService) UpdateOnboarding(ctx context.Context, userID string, req models.UpdateOnboardingRequest) (*models.OnboardingResponse, error) {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	for _, step := range req.SkippedSteps {
+		if err := s.completeStep(ctx, userID, step, true); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Dismissed != nil {
+		var dismissedAt *time.Time
+		if *req.Dismissed {
+			now := time.Now()
+			dismissedAt = &now
+		}
+		if err := s.userRepo.SetOnboardingDismissed(ctx, userID, dismissedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetOnboarding(ctx, userID)
+}
+
+// HandleEvent advances the onboarding of the user a published event is
+// about. Events that don't advance onboarding are ignored.
+func (s *OnboardingService) HandleEvent(ctx context.Context, event kafka.Event) {
+	userID, step, err := s.stepFromEvent(ctx, event)
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("eventId", event.ID).Str("eventType", string(event.Type)).
+			Msg("Failed to get onboarding step from event")
+		return
+	}
+	if userID == "" {
+		return
+	}
+
+	if err := s.completeStep(ctx, userID, step, false); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("eventId", event.ID).Str("userId", userID).Str("step", string(step)).
+			Msg("Failed to advance onboarding")
+	}
+}
+
+// stepFromEvent gets the user and onboarding step an event completes, or an
+// empty user ID for events that complete none
+func (s *OnboardingService) stepFromEvent(ctx context.Context, event kafka.Event) (string, models.OnboardingStep, error) {
+	switch event.Type {
+	case kafka.UserUpdated:
+		var data models.UserResponse
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return "", "", err
+		}
+		// The event carries the user as other users see them, so check the
+		// stored profile
+		user, err := s.userRepo.GetByID(ctx, data.ID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return "", "", nil
+			}
+			return "", "", err
+		}
+		if !user.ProfileComplete() || user.Onboarding.Step(models.OnboardingProfileCompleted) != nil {
+			return "", "", nil
+		}
+		return user.UserID, models.OnboardingProfileCompleted, nil
+
+	case kafka.OrganizationCreated:
+		var data models.OrganizationResponse
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return "", "", err
+		}
+		return data.CreatedBy, models.OnboardingJoinedOrganization, nil
+
+	case kafka.OrganizationMemberAdded:
+		var data kafka.OrganizationMemberAddedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return "", "", err
+		}
+		return data.UserID, models.OnboardingJoinedOrganization, nil
+
+	case kafka.TeamCreated:
+		var data models.TeamResponse
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return "", "", err
+		}
+		return data.CreatedBy, models.OnboardingCreatedTeam, nil
+	}
+
+	return "", "", nil
+}
+
+// completeStep records a step as done or skipped and, if it was the last
+// one, completes onboarding
+func (s *OnboardingService) completeStep(ctx context.Context, userID string, step models.OnboardingStep, skipped bool) error {
+	recorded, err := s.userRepo.CompleteOnboardingStep(ctx, userID, models.OnboardingStepState{
+		Step:        step,
+		Skipped:     skipped,
+		CompletedAt: time.Now(),
+	})
+	if err != nil || !recorded {
+		return err
+	}
+	logger.Ctx(ctx).Debug().Str("userId", userID).Str("step", string(step)).Bool("skipped", skipped).Msg("Onboarding step completed")
+
+	// Steps completed at the same time each check for completion after they
+	// are recorded, so the last one sees them all
+	user, err := s.userRepo.GetByUserId(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.Onboarding.AllStepsDone() {
+		return nil
+	}
+
+	completedAt := time.Now()
+	completed, err := s.userRepo.CompleteOnboarding(ctx, userID, completedAt)
+	if err != nil || !completed {
+		return err
+	}
+
+	var skippedSteps []string
+	for _, state := range user.Onboarding.Steps {
+		if state.Skipped {
+			skippedSteps = append(skippedSteps, string(state.Step))
+		}
+	}
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OnboardingCompleted,
+		kafka.OnboardingCompletedV1{
+			UserID:       user.UserID,
+			Email:        user.Email,
+			FirstName:    user.FirstName,
+			LastName:     user.LastName,
+			SkippedSteps: skippedSteps,
+			CompletedAt:  completedAt,
+		},
+		user.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to publish onboarding.completed event")
+	}
+
+	logger.Ctx(ctx).Info().Str("userId", userID).Msg("Onboarding completed")
+	return nil
+}
+
+// getUser gets a user by user ID
+func (s *OnboardingService) getUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.userRepo.GetByUserId(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
@@ -3,70 +3,116 @@ package services
 import (
 	"context"
 	"errors"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
-	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/db"
 	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
 	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/webhook"
 	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// Approval webhook errors
+var (
+	ErrApprovalDenied      = apperrors.Forbidden("APPROVAL_DENIED", "membership change rejected by approval webhook")
+	ErrApprovalUnavailable = apperrors.Unavailable("APPROVAL_WEBHOOK_UNAVAILABLE", "approval webhook unavailable")
+)
+
+// errJoinRequestNotPending is returned when a join request was already resolved
+var errJoinRequestNotPending = apperrors.Conflict("JOIN_REQUEST_NOT_PENDING", "join request is no longer pending")
+
+// errInvitationNotApprovable is returned when an admin approves an
+// invitation, which only the invited user can accept
+var errInvitationNotApprovable = apperrors.Forbidden("INVITATION_NOT_APPROVABLE", "invitations are accepted by the invited user")
+
+// bulkMemberAttempts is the number of times a bulk member request is checked
+// and written when the members change concurrently
+const bulkMemberAttempts = 3
+
+// memberExpiryBatchSize is the number of expired members removed per query
+const memberExpiryBatchSize = 100
+
 // OrganizationService is a service for organizations
 type OrganizationService struct {
-	orgRepo  *repositories.OrganizationRepository
-	userRepo *repositories.UserRepository
-	teamRepo *repositories.TeamRepository
-	producer *kafka.Producer
+	orgRepo         repositories.OrgStore
+	userRepo        repositories.UserStore
+	teamRepo        repositories.TeamStore
+	joinRequestRepo *repositories.JoinRequestRepository
+	settingsRepo    *repositories.SettingsHistoryRepository
+	events          kafka.EventPublisher
+	sync            *SyncService
+	presence        *PresenceService
+	config          *config.OrganizationConfig
 }
 
 // NewOrganizationService creates a new organization service
 func NewOrganizationService(
-	orgRepo *repositories.OrganizationRepository,
-	userRepo *repositories.UserRepository,
-	teamRepo *repositories.TeamRepository,
-	producer *kafka.Producer,
+	orgRepo repositories.OrgStore,
+	userRepo repositories.UserStore,
+	teamRepo repositories.TeamStore,
+	joinRequestRepo *repositories.JoinRequestRepository,
+	settingsRepo *repositories.SettingsHistoryRepository,
+	events kafka.EventPublisher,
+	syncService *SyncService,
+	presenceService *PresenceService,
+	cfg *config.OrganizationConfig,
 ) *OrganizationService {
 	return &OrganizationService{
-		orgRepo:  orgRepo,
-		userRepo: userRepo,
-		teamRepo: teamRepo,
-		producer: producer,
+		orgRepo:         orgRepo,
+		userRepo:        userRepo,
+		teamRepo:        teamRepo,
+		joinRequestRepo: joinRequestRepo,
+		settingsRepo:    settingsRepo,
+		events:          events,
+		sync:            syncService,
+		presence:        presenceService,
+		config:          cfg,
 	}
 }
 
 // CreateOrganization creates a new organization
 func (s *OrganizationService) CreateOrganization(ctx context.Context, req models.CreateOrganizationRequest, createdBy string) (*models.Organization, error) {
+	// Organizations are stored in the main database or a configured region
+	if req.Residency != "" && !containsString(s.config.Residencies, req.Residency) {
+		return nil, ErrResidencyUnavailable
+	}
+
 	// Create organization
 	org := models.NewOrganization(req, createdBy)
+	org.MemberStorage = models.MemberStorage(s.config.MemberStorage)
 
 	// Save to database
 	err := s.orgRepo.Create(ctx, org)
 	if err != nil {
-		log.Error().Err(err).Interface("req", req).Msg("Failed to create organization")
+		logger.Ctx(ctx).Error().Err(err).Interface("req", req).Msg("Failed to create organization")
 		return nil, err
 	}
 
 	// Add organization to creator's user profile
 	err = s.userRepo.AddOrganizationToUser(ctx, createdBy, org.ID)
 	if err != nil {
-		log.Error().Err(err).Str("orgId", org.ID).Str("userId", createdBy).
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", createdBy).
 			Msg("Failed to add organization to user")
 		// Don't fail the organization creation, but log the error
 	}
 
 	// Publish event
-	go func(o *models.Organization) {
-		err := s.producer.PublishUserEvent(
-			kafka.OrganizationCreated,
-			o.ToResponse(false, false),
-			o.ID,
-			"",
-		)
-		if err != nil {
-			log.Error().Err(err).Str("orgId", o.ID).Msg("Failed to publish organization.created event")
-		}
-	}(org)
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationCreated,
+		org.ToResponse(false, false),
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to publish organization.created event")
+	}
 
 	return org, nil
 }
@@ -76,16 +122,17 @@ func (s *OrganizationService) GetOrganizationByID(ctx context.Context, id string
 	org, err := s.orgRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("organization not found")
+			return nil, ErrOrganizationNotFound
 		}
-		log.Error().Err(err).Str("id", id).Msg("Failed to get organization by ID")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get organization by ID")
 		return nil, err
 	}
 	return org, nil
 }
 
-// GetOrganizationsByUser gets organizations by user ID
-func (s *OrganizationService) GetOrganizationsByUser(ctx context.Context, userID string, page, limit int) ([]*models.Organization, int64, error) {
+// GetOrganizationsByUser gets organizations by user ID, only including
+// organizations with all of the tags
+func (s *OrganizationService) GetOrganizationsByUser(ctx context.Context, userID string, tags []string, page, limit int, fields models.FieldSet) ([]*models.Organization, int64, error) {
 	// Validate pagination
 	if page < 1 {
 		page = 1
@@ -95,9 +142,9 @@ func (s *OrganizationService) GetOrganizationsByUser(ctx context.Context, userID
 	}
 
 	// Get organizations
-	orgs, total, err := s.orgRepo.GetOrganizationsByUser(ctx, userID, page, limit)
+	orgs, total, err := s.orgRepo.GetOrganizationsByUser(ctx, userID, tags, page, limit, fields)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Int("page", page).Int("limit", limit).
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Int("page", page).Int("limit", limit).
 			Msg("Failed to get organizations by user")
 		return nil, 0, err
 	}
@@ -105,8 +152,9 @@ func (s *OrganizationService) GetOrganizationsByUser(ctx context.Context, userID
 	return orgs, total, nil
 }
 
-// ListOrganizations lists all organizations with pagination
-func (s *OrganizationService) ListOrganizations(ctx context.Context, page, limit int) ([]*models.Organization, int64, error) {
+// ListOrganizations lists all organizations with pagination, only including
+// organizations with all of the tags
+func (s *OrganizationService) ListOrganizations(ctx context.Context, tags []string, page, limit int, fields models.FieldSet) ([]*models.Organization, int64, error) {
 	// Validate pagination
 	if page < 1 {
 		page = 1
@@ -116,9 +164,9 @@ func (s *OrganizationService) ListOrganizations(ctx context.Context, page, limit
 	}
 
 	// Get organizations
-	orgs, total, err := s.orgRepo.ListOrganizations(ctx, page, limit)
+	orgs, total, err := s.orgRepo.ListOrganizations(ctx, tags, page, limit, fields)
 	if err != nil {
-		log.Error().Err(err).Int("page", page).Int("limit", limit).
+		logger.Ctx(ctx).Error().Err(err).Int("page", page).Int("limit", limit).
 			Msg("Failed to list organizations")
 		return nil, 0, err
 	}
@@ -126,46 +174,153 @@ func (s *OrganizationService) ListOrganizations(ctx context.Context, page, limit
 	return orgs, total, nil
 }
 
-// UpdateOrganization updates an organization
-func (s *OrganizationService) UpdateOrganization(ctx context.Context, id string, req models.UpdateOrganizationRequest, userID string) (*models.Organization, error) {
+// AddOrganizationTags adds tags to an organization
+func (s *OrganizationService) AddOrganizationTags(ctx context.Context, id string, req models.AddTagsRequest, userID string) (*models.Organization, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get organization for tagging")
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgUpdate) {
+		return nil, insufficientPermissions("tag organization")
+	}
+
+	tags, err := models.NormalizeTags("tags", req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	tags = models.MissingTags(org.Tags, tags)
+	if len(tags) == 0 {
+		return org, nil
+	}
+
+	err = s.orgRepo.AddTags(ctx, id, tags)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to add organization tags")
+		return nil, err
+	}
+	org.Tags = append(org.Tags, tags...)
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationUpdated,
+		org.ToResponse(false, true),
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to publish organization.updated event")
+	}
+
+	return org, nil
+}
+
+// RemoveOrganizationTag removes a tag from an organization
+func (s *OrganizationService) RemoveOrganizationTag(ctx context.Context, id, tag string, userID string) error {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get organization for untagging")
+		return err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgUpdate) {
+		return insufficientPermissions("tag organization")
+	}
+
+	tag = models.NormalizeTag(tag)
+	err = s.orgRepo.RemoveTag(ctx, id, tag)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrTagNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("tag", tag).Msg("Failed to remove organization tag")
+		return err
+	}
+
+	for i, t := range org.Tags {
+		if t == tag {
+			org.Tags = append(org.Tags[:i], org.Tags[i+1:]...)
+			break
+		}
+	}
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationUpdated,
+		org.ToResponse(false, true),
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to publish organization.updated event")
+	}
+
+	return nil
+}
+
+// UpdateOrganization updates an organization. With an If-Match
+// precondition, the update is only applied to the version the client has.
+func (s *OrganizationService) UpdateOrganization(ctx context.Context, id string, req models.UpdateOrganizationRequest, userID string, ifMatch models.Precondition) (*models.Organization, error) {
 	// Get organization
 	org, err := s.orgRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("organization not found")
+			return nil, ErrOrganizationNotFound
 		}
-		log.Error().Err(err).Str("id", id).Msg("Failed to get organization for update")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get organization for update")
 		return nil, err
 	}
 
 	// Check permissions - must be admin or owner
-	if !org.HasRole(userID, models.OrgRoleOwner, models.OrgRoleAdmin) {
-		return nil, errors.New("insufficient permissions to update organization")
+	if !org.Can(userID, models.PermOrgUpdate) {
+		return nil, insufficientPermissions("update organization")
+	}
+	if !ifMatch.Holds(org.ETag()) {
+		return nil, ErrPreconditionFailed
 	}
+	readAt := org.UpdatedAt
+	settingsBefore := org.Settings.Clone()
 
 	// Apply changes
 	org.Apply(req)
 
 	// Save to database
-	err = s.orgRepo.Update(ctx, org)
+	if ifMatch != "" {
+		err = s.orgRepo.UpdateIfUnmodified(ctx, org, readAt)
+	} else {
+		err = s.orgRepo.Update(ctx, org)
+	}
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Interface("req", req).
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrPreconditionFailed
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).
 			Msg("Failed to update organization")
 		return nil, err
 	}
+	s.recordSettingsVersion(ctx, org, settingsBefore, userID, 0)
 
 	// Publish event
-	go func(o *models.Organization) {
-		err := s.producer.PublishUserEvent(
-			kafka.OrganizationUpdated,
-			o.ToResponse(false, true),
-			o.ID,
-			"",
-		)
-		if err != nil {
-			log.Error().Err(err).Str("orgId", o.ID).Msg("Failed to publish organization.updated event")
-		}
-	}(org)
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationUpdated,
+		org.ToResponse(false, true),
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to publish organization.updated event")
+	}
 
 	return org, nil
 }
@@ -176,22 +331,22 @@ func (s *OrganizationService) DeleteOrganization(ctx context.Context, id string,
 	org, err := s.orgRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return errors.New("organization not found")
+			return ErrOrganizationNotFound
 		}
-		log.Error().Err(err).Str("id", id).Msg("Failed to get organization for deletion")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get organization for deletion")
 		return err
 	}
 
 	// Check permissions - must be owner
-	if !org.HasRole(userID, models.OrgRoleOwner) {
-		return errors.New("insufficient permissions to delete organization")
+	if !org.Can(userID, models.PermOrgDelete) {
+		return insufficientPermissions("delete organization")
 	}
 
 	// Delete all teams in the organization
 	for _, teamID := range org.TeamIDs {
 		err = s.teamRepo.Delete(ctx, teamID)
 		if err != nil {
-			log.Error().Err(err).Str("teamId", teamID).Str("orgId", id).
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("orgId", id).
 				Msg("Failed to delete team during organization deletion")
 			// Continue with other teams
 		}
@@ -200,35 +355,40 @@ func (s *OrganizationService) DeleteOrganization(ctx context.Context, id string,
 	// Delete organization
 	err = s.orgRepo.Delete(ctx, id)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to delete organization")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to delete organization")
 		return err
 	}
 
+	// The organization is gone, so its former members are told to drop it
+	// and its teams
+	memberIDs := org.MemberIDs()
+	s.sync.RecordDeletion(ctx, models.SyncOrganization, id, "", memberIDs)
+	for _, teamID := range org.TeamIDs {
+		s.sync.RecordDeletion(ctx, models.SyncTeam, teamID, "", memberIDs)
+	}
+
 	// Remove organization from all members
 	for _, member := range org.Members {
 		err = s.userRepo.RemoveOrganizationFromUser(ctx, member.UserID, id)
 		if err != nil {
-			log.Error().Err(err).Str("orgId", id).Str("userId", member.UserID).
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", id).Str("userId", member.UserID).
 				Msg("Failed to remove organization from user")
 			// Don't fail the organization deletion, but log the error
 		}
 	}
 
 	// Publish event
-	go func(o *models.Organization) {
-		err := s.producer.PublishUserEvent(
-			kafka.OrganizationDeleted,
-			models.OrganizationResponse{
-				ID:   o.ID,
-				Name: o.Name,
-			},
-			o.ID,
-			"",
-		)
-		if err != nil {
-			log.Error().Err(err).Str("orgId", o.ID).Msg("Failed to publish organization.deleted event")
-		}
-	}(org)
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationDeleted,
+		models.OrganizationResponse{
+			ID:   org.ID,
+			Name: org.Name,
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to publish organization.deleted event")
+	}
 
 	return nil
 }
@@ -239,31 +399,45 @@ func (s *OrganizationService) AddOrganizationMember(ctx context.Context, orgID s
 	org, err := s.orgRepo.GetByID(ctx, orgID)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return errors.New("organization not found")
+			return ErrOrganizationNotFound
 		}
-		log.Error().Err(err).Str("id", orgID).Msg("Failed to get organization for adding member")
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for adding member")
 		return err
 	}
 
 	// Check permissions - must be admin or owner
-	if !org.HasRole(invitedBy, models.OrgRoleOwner, models.OrgRoleAdmin) {
-		return errors.New("insufficient permissions to add organization member")
+	if !org.Can(invitedBy, models.PermOrgManageMembers) {
+		return insufficientPermissions("add organization member")
 	}
 
 	// Verify user exists
 	user, err := s.userRepo.GetByUserID(ctx, req.UserID)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return errors.New("user not found")
+			return ErrUserNotFound
 		}
-		log.Error().Err(err).Str("userId", req.UserID).Msg("Failed to get user for adding to organization")
+		logger.Ctx(ctx).Error().Err(err).Str("userId", req.UserID).Msg("Failed to get user for adding to organization")
+		return err
+	}
+
+	// Guests need an expiry, and owners can't have one
+	if err := checkMemberExpiry(req.Role, req.ExpiresAt); err != nil {
+		return err
+	}
+
+	// Licensed members take a presenter seat of the organization's subscription
+	if req.Licensed && !org.Subscription.HasFreeSeat(org.LicensedMembers()) {
+		return ErrSeatLimitReached
+	}
+
+	if err := s.checkMemberAdd(ctx, org, user, req.Role, invitedBy, "userId"); err != nil {
 		return err
 	}
 
 	// Add member to organization
 	err = s.orgRepo.AddMember(ctx, orgID, req.UserID, req.Role, invitedBy)
 	if err != nil {
-		log.Error().Err(err).Str("orgId", orgID).Str("userId", req.UserID).
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", req.UserID).
 			Msg("Failed to add member to organization")
 		return err
 	}
@@ -271,59 +445,80 @@ func (s *OrganizationService) AddOrganizationMember(ctx context.Context, orgID s
 	// Add organization to user
 	err = s.userRepo.AddOrganizationToUser(ctx, req.UserID, orgID)
 	if err != nil {
-		log.Error().Err(err).Str("orgId", orgID).Str("userId", req.UserID).
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", req.UserID).
 			Msg("Failed to add organization to user")
 		// Don't fail the operation, but log the error
 	}
 
-	// Refresh organization data
-	org, err = s.orgRepo.GetByID(ctx, orgID)
+	// Set when the membership ends. A member left without their expiry
+	// would never be removed, so the request fails and can be retried.
+	if req.ExpiresAt != nil {
+		if err := s.orgRepo.SetMemberExpiry(ctx, orgID, req.UserID, req.ExpiresAt); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", req.UserID).
+				Msg("Failed to set expiry of added member")
+			return err
+		}
+	}
+
+	// Assign the member a seat
+	if req.Licensed {
+		if err := s.orgRepo.SetMemberLicensed(ctx, orgID, req.UserID, true); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", req.UserID).
+				Msg("Failed to assign seat to added member")
+			// Don't fail the operation, but log the error
+		} else {
+			publishSeatChanged(ctx, s.events, kafka.SeatAssigned, org, req.UserID, invitedBy, org.LicensedMembers()+1)
+		}
+	}
+
+	// Refresh organization data from the primary, which has the change
+	org, err = s.orgRepo.GetByID(db.ReadPrimary(ctx), orgID)
 	if err != nil {
-		log.Error().Err(err).Str("id", orgID).Msg("Failed to refresh organization data after adding member")
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to refresh organization data after adding member")
 		// Don't fail the operation, but log the error
 	}
 
 	// Publish event
-	go func(o *models.Organization, userID string, role models.OrganizationMemberRole) {
-		if o == nil {
-			return
-		}
+	if org == nil {
+		return nil
+	}
 
-		// Find the added member
-		var addedMember *models.OrganizationMember
-		for _, m := range o.Members {
-			if m.UserID == userID {
-				addedMember = &m
-				break
-			}
+	// Find the added member
+	var addedMember *models.OrganizationMember
+	for i := range org.Members {
+		if org.Members[i].UserID == req.UserID {
+			addedMember = &org.Members[i]
+			break
 		}
+	}
 
-		if addedMember == nil {
-			log.Error().Str("orgId", orgID).Str("userId", userID).
-				Msg("Failed to find added member for event")
-			return
-		}
+	if addedMember == nil {
+		logger.Ctx(ctx).Error().Str("orgId", orgID).Str("userId", req.UserID).
+			Msg("Failed to find added member for event")
+		return nil
+	}
 
-		err := s.producer.PublishUserEvent(
-			kafka.OrganizationMemberAdded,
-			map[string]interface{}{
-				"orgId":     o.ID,
-				"orgName":   o.Name,
-				"userId":    userID,
-				"userEmail": user.Email,
-				"userName":  user.FirstName + " " + user.LastName,
-				"role":      role,
-				"invitedBy": invitedBy,
-				"joinedAt":  addedMember.JoinedAt,
-			},
-			o.ID,
-			"",
-		)
-		if err != nil {
-			log.Error().Err(err).Str("orgId", o.ID).Str("userId", userID).
-				Msg("Failed to publish organization.member.added event")
-		}
-	}(org, req.UserID, req.Role)
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationMemberAdded,
+		kafka.OrganizationMemberAddedV1{
+			OrgID:     org.ID,
+			OrgName:   org.Name,
+			UserID:    req.UserID,
+			UserEmail: user.Email,
+			UserName:  user.FirstName + " " + user.LastName,
+			Role:      string(req.Role),
+			InvitedBy: invitedBy,
+			JoinedAt:  addedMember.JoinedAt,
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", req.UserID).
+			Msg("Failed to publish organization.member.added event")
+	}
+	if req.Role == models.OrgRoleGuest {
+		s.publishGuestEvent(ctx, kafka.OrganizationGuestAdded, org, req.UserID, *req.ExpiresAt, invitedBy)
+	}
 
 	return nil
 }
@@ -334,21 +529,21 @@ func (s *OrganizationService) UpdateOrganizationMember(ctx context.Context, orgI
 	org, err := s.orgRepo.GetByID(ctx, orgID)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return errors.New("organization not found")
+			return ErrOrganizationNotFound
 		}
-		log.Error().Err(err).Str("id", orgID).Msg("Failed to get organization for updating member")
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for updating member")
 		return err
 	}
 
 	// Check permissions - must be admin or owner
-	if !org.HasRole(updatedBy, models.OrgRoleOwner, models.OrgRoleAdmin) {
-		return errors.New("insufficient permissions to update organization member")
+	if !org.Can(updatedBy, models.PermOrgManageMembers) {
+		return insufficientPermissions("update organization member")
 	}
 
 	// If updating an owner, only an owner can do that
 	currentMember := org.GetMember(memberID)
 	if currentMember != nil && currentMember.Role == models.OrgRoleOwner && !org.HasRole(updatedBy, models.OrgRoleOwner) {
-		return errors.New("only an organization owner can change the role of another owner")
+		return apperrors.Forbidden("OWNER_REQUIRED", "only an organization owner can change the role of another owner")
 	}
 
 	// Check if the user is trying to update their own role to a lower one
@@ -363,49 +558,95 @@ func (s *OrganizationService) UpdateOrganizationMember(ctx context.Context, orgI
 
 		// If this is the only owner, don't allow role change
 		if ownerCount <= 1 {
-			return errors.New("cannot change role: organization must have at least one owner")
+			return apperrors.Conflict("LAST_OWNER", "cannot change role: organization must have at least one owner")
+		}
+	}
+
+	// Work out the member's expiry after the update
+	expiresAt, expiryChanged, err := nextMemberExpiry(currentMember, req)
+	if err != nil {
+		return err
+	}
+
+	// Role escalations require two-factor authentication, when the
+	// organization asks for it, and external approval before committing the
+	// change
+	if currentMember != nil && req.Role.Rank() > currentMember.Role.Rank() {
+		if org.Settings.RequireTwoFactor {
+			user, err := s.userRepo.GetByUserId(ctx, memberID)
+			if err != nil {
+				if errors.Is(err, mongo.ErrNoDocuments) {
+					return ErrUserNotFound
+				}
+				logger.Ctx(ctx).Error().Err(err).Str("userId", memberID).Msg("Failed to get user for updating organization member")
+				return err
+			}
+			if err := checkTwoFactor(org, user, "role"); err != nil {
+				return err
+			}
+		}
+
+		err = s.requestApproval(ctx, org, models.NewApprovalRequest(
+			models.ApprovalActionRoleEscalation, orgID, memberID, req.Role, currentMember.Role, updatedBy,
+		))
+		if err != nil {
+			return err
 		}
 	}
 
 	// Update member role
-	err = s.orgRepo.AddMember(ctx, orgID, memberID, req.Role, updatedBy)
+	err = s.orgRepo.UpdateMemberRole(ctx, orgID, memberID, req.Role)
 	if err != nil {
-		log.Error().Err(err).Str("orgId", orgID).Str("userId", memberID).
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", memberID).
 			Msg("Failed to update organization member")
 		return err
 	}
 
-	// Refresh organization data
-	org, err = s.orgRepo.GetByID(ctx, orgID)
+	// Set the member's new expiry, or remove it
+	if expiryChanged {
+		if err := s.orgRepo.SetMemberExpiry(ctx, orgID, memberID, expiresAt); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", memberID).
+				Msg("Failed to set organization member expiry")
+			return err
+		}
+	}
+
+	// Refresh organization data from the primary, which has the change
+	org, err = s.orgRepo.GetByID(db.ReadPrimary(ctx), orgID)
 	if err != nil {
-		log.Error().Err(err).Str("id", orgID).Msg("Failed to refresh organization data after updating member")
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to refresh organization data after updating member")
 		// Don't fail the operation, but log the error
 	}
 
 	// Publish event
-	go func(o *models.Organization, userID string, role models.OrganizationMemberRole) {
-		if o == nil {
-			return
-		}
-
-		err := s.producer.PublishUserEvent(
+	if org != nil {
+		if err := s.events.PublishUserEvent(
+			ctx,
 			kafka.OrganizationMemberUpdated,
-			map[string]interface{}{
-				"orgId":     o.ID,
-				"orgName":   o.Name,
-				"userId":    userID,
-				"role":      role,
-				"updatedBy": updatedBy,
-				"updatedAt": time.Now(),
+			kafka.OrganizationMemberUpdatedV1{
+				OrgID:     org.ID,
+				OrgName:   org.Name,
+				UserID:    memberID,
+				Role:      string(req.Role),
+				UpdatedBy: updatedBy,
+				UpdatedAt: time.Now(),
 			},
-			o.ID,
-			"",
-		)
-		if err != nil {
-			log.Error().Err(err).Str("orgId", o.ID).Str("userId", userID).
+			org.ID,
+		); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", memberID).
 				Msg("Failed to publish organization.member.updated event")
 		}
-	}(org, memberID, req.Role)
+
+		// Members made guests are added as guests, guests given a new
+		// expiry are extended
+		if req.Role == models.OrgRoleGuest && req.ExpiresAt != nil {
+			eventType := kafka.OrganizationGuestAdded
+			if currentMember != nil && currentMember.IsGuest() {
+				eventType = kafka.OrganizationGuestExtended
+			}
+			s.publishGuestEvent(ctx, eventType, org, memberID, *req.ExpiresAt, updatedBy)
+		}
+	}
 
 	return nil
 }
@@ -416,16 +657,16 @@ func (s *OrganizationService) RemoveOrganizationMember(ctx context.Context, orgI
 	org, err := s.orgRepo.GetByID(ctx, orgID)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return errors.New("organization not found")
+			return ErrOrganizationNotFound
 		}
-		log.Error().Err(err).Str("id", orgID).Msg("Failed to get organization for removing member")
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for removing member")
 		return err
 	}
 
 	// Get member being removed
 	memberToRemove := org.GetMember(memberID)
 	if memberToRemove == nil {
-		return errors.New("member not found in organization")
+		return apperrors.NotFound("MEMBER_NOT_FOUND", "member not found in organization")
 	}
 
 	// Check permissions
@@ -437,7 +678,7 @@ func (s *OrganizationService) RemoveOrganizationMember(ctx context.Context, orgI
 	isSelf := removedBy == memberID
 
 	if !isOwner && !isSelf && (memberToRemove.Role == models.OrgRoleOwner || (!isAdmin && memberToRemove.Role == models.OrgRoleAdmin)) {
-		return errors.New("insufficient permissions to remove this organization member")
+		return insufficientPermissions("remove this organization member")
 	}
 
 	// If trying to remove the last owner, prevent it
@@ -452,14 +693,22 @@ func (s *OrganizationService) RemoveOrganizationMember(ctx context.Context, orgI
 
 		// If this is the only owner, don't allow removal
 		if ownerCount <= 1 {
-			return errors.New("cannot remove the only organization owner")
+			return apperrors.Conflict("LAST_OWNER", "cannot remove the only organization owner")
 		}
 	}
 
+	return s.removeMember(ctx, org, *memberToRemove, removedBy)
+}
+
+// removeMember removes a member from an organization and its users, frees
+// their seat and publishes the removal
+func (s *OrganizationService) removeMember(ctx context.Context, org *models.Organization, member models.OrganizationMember, removedBy string) error {
+	orgID, memberID := org.ID, member.UserID
+
 	// Remove member from organization
-	err = s.orgRepo.RemoveMember(ctx, orgID, memberID)
+	err := s.orgRepo.RemoveMember(ctx, orgID, memberID)
 	if err != nil {
-		log.Error().Err(err).Str("orgId", orgID).Str("userId", memberID).
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", memberID).
 			Msg("Failed to remove member from organization")
 		return err
 	}
@@ -467,51 +716,1847 @@ func (s *OrganizationService) RemoveOrganizationMember(ctx context.Context, orgI
 	// Remove organization from user
 	err = s.userRepo.RemoveOrganizationFromUser(ctx, memberID, orgID)
 	if err != nil {
-		log.Error().Err(err).Str("orgId", orgID).Str("userId", memberID).
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", memberID).
 			Msg("Failed to remove organization from user")
 		// Don't fail the operation, but log the error
 	}
+	s.sync.RecordMemberRemoval(ctx, orgID, memberID)
+
+	// Free the member's seat
+	if member.Licensed {
+		publishSeatChanged(ctx, s.events, kafka.SeatUnassigned, org, memberID, removedBy, org.LicensedMembers()-1)
+	}
 
 	// Publish event
-	go func(o *models.Organization, userID string) {
-		err := s.producer.PublishUserEvent(
-			kafka.OrganizationMemberRemoved,
-			map[string]interface{}{
-				"orgId":     o.ID,
-				"orgName":   o.Name,
-				"userId":    userID,
-				"removedBy": removedBy,
-				"removedAt": time.Now(),
-			},
-			o.ID,
-			"",
-		)
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationMemberRemoved,
+		kafka.OrganizationMemberRemovedV1{
+			OrgID:     org.ID,
+			OrgName:   org.Name,
+			UserID:    memberID,
+			RemovedBy: removedBy,
+			RemovedAt: time.Now(),
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", memberID).
+			Msg("Failed to publish organization.member.removed event")
+	}
+
+	return nil
+}
+
+// checkMemberExpiry checks that a member with the role has an expiry if they
+// are a guest and none if they are an owner, and that the expiry is in the
+// future and at most MaxMembershipDuration away
+func checkMemberExpiry(role models.OrganizationMemberRole, expiresAt *time.Time) error {
+	if expiresAt == nil {
+		if role == models.OrgRoleGuest {
+			return ErrGuestExpiryRequired
+		}
+		return nil
+	}
+	if role == models.OrgRoleOwner {
+		return ErrOwnerExpiryNotAllowed
+	}
+
+	now := time.Now()
+	if !expiresAt.After(now) || expiresAt.After(now.Add(models.MaxMembershipDuration)) {
+		return ErrMemberExpiryInvalid
+	}
+	return nil
+}
+
+// nextMemberExpiry gets the expiry a member has after an update, and if it
+// changes. Members keep their expiry unless the update sets or removes it,
+// except that members made owners and guests given another role lose it.
+func nextMemberExpiry(member *models.OrganizationMember, req models.UpdateOrganizationMemberRequest) (*time.Time, bool, error) {
+	if req.ExpiresAt != nil {
+		return req.ExpiresAt, true, checkMemberExpiry(req.Role, req.ExpiresAt)
+	}
+
+	var current *time.Time
+	if member != nil {
+		current = member.ExpiresAt
+	}
+	if current == nil || req.RemoveExpiry || req.Role == models.OrgRoleOwner ||
+		(member.IsGuest() && req.Role != models.OrgRoleGuest) {
+		if req.Role == models.OrgRoleGuest {
+			return nil, false, ErrGuestExpiryRequired
+		}
+		return nil, current != nil, nil
+	}
+	return current, false, nil
+}
+
+// publishGuestEvent publishes an organization.guest event
+func (s *OrganizationService) publishGuestEvent(ctx context.Context, eventType kafka.EventType, org *models.Organization, userID string, expiresAt time.Time, changedBy string) {
+	if err := s.events.PublishUserEvent(
+		ctx,
+		eventType,
+		kafka.OrganizationGuestV1{
+			OrgID:     org.ID,
+			OrgName:   org.Name,
+			UserID:    userID,
+			ExpiresAt: expiresAt,
+			ChangedBy: changedBy,
+			ChangedAt: time.Now(),
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", userID).
+			Msgf("Failed to publish %s event", eventType)
+	}
+}
+
+// RunMemberExpiry periodically removes the members whose membership expired,
+// until ctx is cancelled
+func (s *OrganizationService) RunMemberExpiry(ctx context.Context) {
+	if s.config.MemberExpiryInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.MemberExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		s.removeExpiredMembers(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// removeExpiredMembers removes every member whose membership expired, in
+// batches. Members that fail to be removed are retried on the next run.
+func (s *OrganizationService) removeExpiredMembers(ctx context.Context) {
+	now := time.Now()
+	seen := make(map[string]bool)
+
+	for ctx.Err() == nil {
+		members, err := s.orgRepo.FindExpiredMembers(ctx, now, memberExpiryBatchSize)
 		if err != nil {
-			log.Error().Err(err).Str("orgId", o.ID).Str("userId", userID).
-				Msg("Failed to publish organization.member.removed event")
+			return
+		}
+
+		found := 0
+		for _, member := range members {
+			key := member.OrganizationID + "/" + member.UserID
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			found++
+
+			if err := s.expireMember(ctx, member, now); err != nil {
+				logger.Ctx(ctx).Warn().Err(err).Str("orgId", member.OrganizationID).Str("userId", member.UserID).
+					Msg("Failed to remove expired member")
+			}
+		}
+
+		// Stop after the last batch, or one holding only members that
+		// couldn't be removed
+		if len(members) < memberExpiryBatchSize || found == 0 {
+			return
 		}
-	}(org, memberID)
+	}
+}
+
+// expireMember removes an expired member from their organization, if their
+// membership still expired
+func (s *OrganizationService) expireMember(ctx context.Context, record *models.OrganizationMemberRecord, now time.Time) error {
+	org, err := s.orgRepo.GetByID(db.ReadPrimary(ctx), record.OrganizationID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// The member may have been extended or removed since they were found
+	member := org.GetMember(record.UserID)
+	if member == nil || !member.IsExpired(now) {
+		return nil
+	}
+
+	if err := s.removeMember(ctx, org, *member, models.MemberExpiryActor); err != nil {
+		return err
+	}
+	if member.IsGuest() {
+		s.publishGuestEvent(ctx, kafka.OrganizationGuestExpired, org, member.UserID, *member.ExpiresAt, models.MemberExpiryActor)
+	} else if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationMemberExpired,
+		kafka.OrganizationMemberExpiredV1{
+			OrgID:     org.ID,
+			OrgName:   org.Name,
+			UserID:    member.UserID,
+			Role:      string(member.Role),
+			ExpiresAt: *member.ExpiresAt,
+			RemovedAt: time.Now(),
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", member.UserID).
+			Msg("Failed to publish organization.member.expired event")
+	}
 
+	logger.Ctx(ctx).Info().Str("orgId", org.ID).Str("userId", member.UserID).Msg("Removed expired organization member")
 	return nil
 }
 
-// GetOrganizationTeams gets teams in an organization
-func (s *OrganizationService) GetOrganizationTeams(ctx context.Context, orgID string, page, limit int, userID string) ([]*models.Team, int64, error) {
-	// Verify organization exists
-	org, err := s.orgRepo.GetByID(ctx, orgID)
+// BulkOrganizationMembers runs up to 100 member adds, role updates and
+// removals on an organization. Each operation is checked like its single
+// member endpoint, against the members left by the operations before it, and
+// failed operations are reported and skipped. The net changes are written
+// with one write per collection and published as a single
+// organization.member.batch event.
+func (s *OrganizationService) BulkOrganizationMembers(ctx context.Context, orgID string, req models.BulkOrganizationMembersRequest, changedBy string) (*models.BulkMemberResponse, error) {
+	// Get the users of all operations at once
+	userIDs := make([]string, len(req.Operations))
+	for i, op := range req.Operations {
+		userIDs[i] = op.UserID
+	}
+	users, err := s.userRepo.FindBatch(ctx, bson.M{"userId": bson.M{"$in": userIDs}}, 0)
 	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to get users for bulk organization member request")
+		return nil, err
+	}
+	usersByID := make(map[string]*models.User, len(users))
+	for _, user := range users {
+		usersByID[user.UserID] = user
+	}
+
+	// Approval webhook decisions are kept for the operations they were asked
+	// for, so a retry doesn't ask again
+	approvals := make(map[int]error)
+
+	for attempt := 0; attempt < bulkMemberAttempts; attempt++ {
+		// Get organization, from the primary once a write conflicted
+		readCtx := ctx
+		if attempt > 0 {
+			readCtx = db.ReadPrimary(ctx)
+		}
+		org, err := s.orgRepo.GetByID(readCtx, orgID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, ErrOrganizationNotFound
+			}
+			logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for bulk member request")
+			return nil, err
+		}
+
+		// Check permissions - must be admin or owner
+		if !org.Can(changedBy, models.PermOrgManageMembers) {
+			return nil, insufficientPermissions("manage organization members")
+		}
+
+		// Check the operations against a working copy of the members
+		working := *org
+		working.Members = append([]models.OrganizationMember(nil), org.Members...)
+		response := models.NewBulkMemberResponse(len(req.Operations))
+		for i, op := range req.Operations {
+			err := s.applyMemberOperation(ctx, &working, i, op, usersByID[op.UserID], approvals, changedBy)
+			response.Add(i, op.Op, op.UserID, err)
+		}
+
+		changes := models.DiffOrganizationMembers(org.Members, working.Members)
+		if changes.IsEmpty() {
+			return response, nil
+		}
+
+		// Write the changes, starting over if the members changed since they
+		// were read
+		err = s.orgRepo.ApplyMemberChanges(ctx, org, changes)
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, 0, errors.New("organization not found")
+			logger.Ctx(ctx).Debug().Str("orgId", orgID).Int("attempt", attempt+1).
+				Msg("Organization members changed during bulk member request, retrying")
+			continue
 		}
-		log.Error().Err(err).Str("id", orgID).Msg("Failed to get organization for teams")
-		return nil, 0, err
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to apply bulk organization member changes")
+			return nil, err
+		}
+
+		s.completeMemberBatch(ctx, org, changes, usersByID, changedBy)
+		return response, nil
 	}
 
-	// Verify user is member of the organization
-	if !org.IsMember(userID) {
-		return nil, 0, errors.New("user is not a member of the organization")
+	return nil, ErrMembersChanged
+}
+
+// applyMemberOperation checks an operation of a bulk member request against
+// the working copy of an organization and applies it there
+func (s *OrganizationService) applyMemberOperation(ctx context.Context, org *models.Organization, index int, op models.BulkOrganizationMemberOperation, user *models.User, approvals map[int]error, changedBy string) error {
+	member := org.GetMember(op.UserID)
+
+	switch op.Op {
+	case models.BulkMemberAdd:
+		if op.Role == "" {
+			return ErrMemberRoleRequired
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+		if user.IsPendingReview() {
+			return ErrUserPendingReview
+		}
+		if member != nil {
+			return apperrors.Conflict("ALREADY_MEMBER", "user is already a member of the organization")
+		}
+		if err := checkEmailDomain(org, user, "userId"); err != nil {
+			return err
+		}
+		if err := checkTwoFactor(org, user, "userId"); err != nil {
+			return err
+		}
+
+		err := s.approveMemberOperation(ctx, org, index, approvals, models.NewApprovalRequest(
+			models.ApprovalActionMemberAdd, org.ID, op.UserID, op.Role, "", changedBy,
+		))
+		if err != nil {
+			return err
+		}
+
+		org.AddMember(op.UserID, op.Role, changedBy)
+
+	case models.BulkMemberUpdate:
+		if op.Role == "" {
+			return ErrMemberRoleRequired
+		}
+		if member == nil {
+			return apperrors.NotFound("MEMBER_NOT_FOUND", "member not found in organization")
+		}
+
+		// If updating an owner, only an owner can do that, and the
+		// organization must keep one
+		if member.Role == models.OrgRoleOwner && !org.HasRole(changedBy, models.OrgRoleOwner) {
+			return apperrors.Forbidden("OWNER_REQUIRED", "only an organization owner can change the role of another owner")
+		}
+		if member.Role == models.OrgRoleOwner && op.Role != models.OrgRoleOwner && countOrgOwners(org) <= 1 {
+			return apperrors.Conflict("LAST_OWNER", "cannot change role: organization must have at least one owner")
+		}
+
+		// Role escalations require two-factor authentication and external
+		// approval, like single updates
+		if op.Role.Rank() > member.Role.Rank() {
+			if org.Settings.RequireTwoFactor {
+				if user == nil {
+					return ErrUserNotFound
+				}
+				if err := checkTwoFactor(org, user, "role"); err != nil {
+					return err
+				}
+			}
+
+			err := s.approveMemberOperation(ctx, org, index, approvals, models.NewApprovalRequest(
+				models.ApprovalActionRoleEscalation, org.ID, op.UserID, op.Role, member.Role, changedBy,
+			))
+			if err != nil {
+				return err
+			}
+		}
+
+		org.UpdateMember(op.UserID, op.Role)
+
+	case models.BulkMemberRemove:
+		if member == nil {
+			return apperrors.NotFound("MEMBER_NOT_FOUND", "member not found in organization")
+		}
+
+		// Owners can remove anyone, admins regular members and other admins,
+		// and users themselves
+		isOwner := org.HasRole(changedBy, models.OrgRoleOwner)
+		isAdmin := org.HasRole(changedBy, models.OrgRoleAdmin)
+		isSelf := changedBy == op.UserID
+		if !isOwner && !isSelf && (member.Role == models.OrgRoleOwner || (!isAdmin && member.Role == models.OrgRoleAdmin)) {
+			return insufficientPermissions("remove this organization member")
+		}
+		if member.Role == models.OrgRoleOwner && countOrgOwners(org) <= 1 {
+			return apperrors.Conflict("LAST_OWNER", "cannot remove the only organization owner")
+		}
+
+		org.RemoveMember(op.UserID)
 	}
 
-	// Get teams
-	return s.teamRepo.GetTeamsByOrganization(ctx, orgID, page, limit)
+	return nil
+}
+
+// approveMemberOperation asks the approval webhook about an operation of a
+// bulk member request, once
+func (s *OrganizationService) approveMemberOperation(ctx context.Context, org *models.Organization, index int, approvals map[int]error, req models.ApprovalRequest) error {
+	if err, ok := approvals[index]; ok {
+		return err
+	}
+
+	err := s.requestApproval(ctx, org, req)
+	approvals[index] = err
+	return err
+}
+
+// completeMemberBatch records the written changes of a bulk member request on
+// the users, tombstones and seats, and publishes organization.member.batch
+func (s *OrganizationService) completeMemberBatch(ctx context.Context, org *models.Organization, changes models.OrganizationMemberChanges, users map[string]*models.User, changedBy string) {
+	now := time.Now()
+	batch := kafka.OrganizationMemberBatchV1{
+		OrgID:     org.ID,
+		OrgName:   org.Name,
+		ChangedBy: changedBy,
+		Added:     make([]kafka.OrganizationMemberAddedV1, len(changes.Added)),
+		Updated:   make([]kafka.OrganizationMemberUpdatedV1, len(changes.Updated)),
+		Removed:   make([]kafka.OrganizationMemberRemovedV1, len(changes.Removed)),
+		ChangedAt: now,
+	}
+
+	added := make([]string, len(changes.Added))
+	for i, member := range changes.Added {
+		added[i] = member.UserID
+		batch.Added[i] = kafka.OrganizationMemberAddedV1{
+			OrgID:     org.ID,
+			OrgName:   org.Name,
+			UserID:    member.UserID,
+			Role:      string(member.Role),
+			InvitedBy: member.InvitedBy,
+			JoinedAt:  member.JoinedAt,
+		}
+		if user := users[member.UserID]; user != nil {
+			batch.Added[i].UserEmail = user.Email
+			batch.Added[i].UserName = user.FirstName + " " + user.LastName
+		}
+	}
+	for i, member := range changes.Updated {
+		batch.Updated[i] = kafka.OrganizationMemberUpdatedV1{
+			OrgID:     org.ID,
+			OrgName:   org.Name,
+			UserID:    member.UserID,
+			Role:      string(member.Role),
+			UpdatedBy: changedBy,
+			UpdatedAt: now,
+		}
+	}
+
+	removed := make([]string, len(changes.Removed))
+	licensed := org.LicensedMembers()
+	for i, member := range changes.Removed {
+		removed[i] = member.UserID
+		batch.Removed[i] = kafka.OrganizationMemberRemovedV1{
+			OrgID:     org.ID,
+			OrgName:   org.Name,
+			UserID:    member.UserID,
+			RemovedBy: changedBy,
+			RemovedAt: now,
+		}
+
+		// Free the member's seat
+		if member.Licensed {
+			licensed--
+			publishSeatChanged(ctx, s.events, kafka.SeatUnassigned, org, member.UserID, changedBy, licensed)
+		}
+	}
+
+	// Update the organizations of the users
+	if err := s.userRepo.UpdateOrganizationMemberships(ctx, org.ID, added, removed); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to update organizations of bulk changed members")
+		// Don't fail the operation, but log the error
+	}
+	s.sync.RecordMemberRemovals(ctx, org.ID, removed)
+
+	// Publish event
+	if err := s.events.PublishUserEvent(ctx, kafka.OrganizationMemberBatch, batch, org.ID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to publish organization.member.batch event")
+	}
+}
+
+// GetOrganizationMembers gets a page of the members of an organization with
+// the details of their users
+func (s *OrganizationService) GetOrganizationMembers(ctx context.Context, orgID string, filter models.OrganizationMemberFilter, page, limit int, userID string) (*models.OrganizationMembersResponse, error) {
+	// Validate pagination
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for members")
+		return nil, err
+	}
+
+	// Verify user is member of the organization
+	if !org.Can(userID, models.PermOrgView) {
+		return nil, ErrNotOrganizationMember
+	}
+
+	members, total, err := s.orgRepo.GetMembers(ctx, org, filter, page, limit)
+	if errors.Is(err, db.ErrAggregationUnsupported) {
+		members, total, err = s.pageMembers(ctx, org, filter, page, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Only show the custom field values the viewer may see
+	canSeeAdminFields := org.Can(userID, models.PermOrgManageMembers)
+	for i := range members {
+		members[i].CustomFields = org.Settings.VisibleCustomFieldValues(
+			members[i].CustomFields, canSeeAdminFields || members[i].UserID == userID)
+	}
+
+	// Add who is online
+	memberIDs := make([]string, len(members))
+	for i := range members {
+		memberIDs[i] = members[i].UserID
+	}
+	online := s.presence.Online(ctx, memberIDs)
+	for i := range members {
+		members[i].Presence = models.NewPresenceStatus(online[members[i].UserID])
+	}
+
+	return &models.OrganizationMembersResponse{
+		OrganizationID:   org.ID,
+		OrganizationName: org.Name,
+		MemberCount:      len(org.Members),
+		Members:          members,
+		Total:            total,
+		Page:             page,
+		Limit:            limit,
+		TotalPages:       (total + int64(limit) - 1) / int64(limit),
+	}, nil
+}
+
+// pageMembers gets a page of the members of an organization like
+// OrganizationRepository.GetMembers, in memory, for storage drivers without
+// aggregation pipelines
+func (s *OrganizationService) pageMembers(ctx context.Context, org *models.Organization, filter models.OrganizationMemberFilter, page, limit int) ([]models.OrganizationMemberDetail, int64, error) {
+	members := make([]models.OrganizationMember, 0, len(org.Members))
+	for _, member := range org.Members {
+		if filter.Role == "" || member.Role == filter.Role {
+			members = append(members, member)
+		}
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		if !members[i].JoinedAt.Equal(members[j].JoinedAt) {
+			return members[i].JoinedAt.Before(members[j].JoinedAt)
+		}
+		return members[i].UserID < members[j].UserID
+	})
+
+	// Searching needs every member's user; otherwise only the users of the
+	// page are loaded
+	if filter.Search == "" {
+		start := min((page-1)*limit, len(members))
+		end := min(start+limit, len(members))
+		details, err := s.memberDetails(ctx, org.ID, members[start:end])
+		return details, int64(len(members)), err
+	}
+
+	details, err := s.memberDetails(ctx, org.ID, members)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	search := strings.ToLower(filter.Search)
+	matched := make([]models.OrganizationMemberDetail, 0, len(details))
+	for _, detail := range details {
+		if strings.Contains(strings.ToLower(detail.FirstName), search) ||
+			strings.Contains(strings.ToLower(detail.LastName), search) ||
+			strings.Contains(strings.ToLower(detail.Email), search) {
+			matched = append(matched, detail)
+		}
+	}
+
+	start := min((page-1)*limit, len(matched))
+	end := min(start+limit, len(matched))
+	return matched[start:end], int64(len(matched)), nil
+}
+
+// memberDetails gets the details of members of an organization from their users
+func (s *OrganizationService) memberDetails(ctx context.Context, orgID string, members []models.OrganizationMember) ([]models.OrganizationMemberDetail, error) {
+	userIDs := make([]string, len(members))
+	for i, member := range members {
+		userIDs[i] = member.UserID
+	}
+
+	users, err := s.userRepo.FindBatch(ctx, bson.M{"userId": bson.M{"$in": userIDs}}, 0)
+	if err != nil {
+		return nil, err
+	}
+	byUserID := make(map[string]*models.User, len(users))
+	for _, user := range users {
+		byUserID[user.UserID] = user
+	}
+
+	details := make([]models.OrganizationMemberDetail, len(members))
+	for i, member := range members {
+		details[i] = models.NewOrganizationMemberDetail(orgID, member, byUserID[member.UserID])
+	}
+	return details, nil
+}
+
+// GetTwoFactorCompliance gets a page of the members of an organization that
+// haven't enabled two-factor authentication, oldest members first
+func (s *OrganizationService) GetTwoFactorCompliance(ctx context.Context, orgID string, page, limit int, userID string) (*models.TwoFactorComplianceResponse, error) {
+	// Validate pagination
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for two-factor compliance")
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgManageMembers) {
+		return nil, insufficientPermissions("view two-factor compliance")
+	}
+
+	userIDs := make([]string, len(org.Members))
+	for i, member := range org.Members {
+		userIDs[i] = member.UserID
+	}
+	users, err := s.userRepo.FindBatch(ctx, bson.M{"userId": bson.M{"$in": userIDs}}, 0)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to get members for two-factor compliance")
+		return nil, err
+	}
+	byUserID := make(map[string]*models.User, len(users))
+	for _, user := range users {
+		byUserID[user.UserID] = user
+	}
+
+	// Members whose user no longer exists can't sign in, so they aren't listed
+	nonCompliant := make([]models.OrganizationMember, 0, len(org.Members))
+	compliant := 0
+	for _, member := range org.Members {
+		user := byUserID[member.UserID]
+		switch {
+		case user == nil:
+		case user.TwoFactorEnabled():
+			compliant++
+		default:
+			nonCompliant = append(nonCompliant, member)
+		}
+	}
+	sort.SliceStable(nonCompliant, func(i, j int) bool {
+		if !nonCompliant[i].JoinedAt.Equal(nonCompliant[j].JoinedAt) {
+			return nonCompliant[i].JoinedAt.Before(nonCompliant[j].JoinedAt)
+		}
+		return nonCompliant[i].UserID < nonCompliant[j].UserID
+	})
+
+	start := min((page-1)*limit, len(nonCompliant))
+	end := min(start+limit, len(nonCompliant))
+	members := make([]models.OrganizationMemberDetail, 0, end-start)
+	for _, member := range nonCompliant[start:end] {
+		members = append(members, models.NewOrganizationMemberDetail(org.ID, member, byUserID[member.UserID]))
+	}
+
+	total := int64(len(nonCompliant))
+	return &models.TwoFactorComplianceResponse{
+		OrganizationID:   org.ID,
+		OrganizationName: org.Name,
+		RequireTwoFactor: org.Settings.RequireTwoFactor,
+		MemberCount:      len(org.Members),
+		CompliantCount:   compliant,
+		Members:          members,
+		Total:            total,
+		Page:             page,
+		Limit:            limit,
+		TotalPages:       (total + int64(limit) - 1) / int64(limit),
+	}, nil
+}
+
+// GetAccessReview gets a page of the stale members of an organization: those
+// who haven't logged in, or been confirmed by an access review, within
+// inactiveDays. Without inactiveDays the configured window is used. Members
+// who haven't logged in for longest come first.
+func (s *OrganizationService) GetAccessReview(ctx context.Context, orgID string, inactiveDays, page, limit int, userID string) (*models.AccessReviewResponse, error) {
+	// Validate pagination
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	if inactiveDays == 0 {
+		inactiveDays = int(s.config.AccessReviewWindow / (24 * time.Hour))
+	}
+	if inactiveDays < 1 || inactiveDays > models.MaxAccessReviewDays {
+		return nil, ErrInactiveDaysInvalid
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for access review")
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgManageMembers) {
+		return nil, insufficientPermissions("review organization access")
+	}
+
+	userIDs := make([]string, len(org.Members))
+	for i, member := range org.Members {
+		userIDs[i] = member.UserID
+	}
+	users, err := s.userRepo.FindBatch(ctx, bson.M{"userId": bson.M{"$in": userIDs}}, 0)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to get members for access review")
+		return nil, err
+	}
+	byUserID := make(map[string]*models.User, len(users))
+	for _, user := range users {
+		byUserID[user.UserID] = user
+	}
+
+	since := time.Now().Add(-time.Duration(inactiveDays) * 24 * time.Hour)
+	stale := make([]models.AccessReviewMember, 0)
+	for _, member := range org.Members {
+		user := byUserID[member.UserID]
+		if !member.IsStale(user, since) {
+			continue
+		}
+		reviewMember := models.AccessReviewMember{OrganizationMemberDetail: models.NewOrganizationMemberDetail(org.ID, member, user)}
+		if user != nil {
+			reviewMember.LastLogin = user.LastLogin
+		}
+		stale = append(stale, reviewMember)
+	}
+	sort.SliceStable(stale, func(i, j int) bool {
+		a, b := stale[i].LastLogin, stale[j].LastLogin
+		switch {
+		case a == nil || b == nil:
+			if (a == nil) != (b == nil) {
+				return a == nil
+			}
+		case !a.Equal(*b):
+			return a.Before(*b)
+		}
+		return stale[i].UserID < stale[j].UserID
+	})
+
+	start := min((page-1)*limit, len(stale))
+	end := min(start+limit, len(stale))
+
+	total := int64(len(stale))
+	return &models.AccessReviewResponse{
+		OrganizationID:   org.ID,
+		OrganizationName: org.Name,
+		InactiveDays:     inactiveDays,
+		MemberCount:      len(org.Members),
+		Members:          stale[start:end],
+		Total:            total,
+		Page:             page,
+		Limit:            limit,
+		TotalPages:       (total + int64(limit) - 1) / int64(limit),
+	}, nil
+}
+
+// ConfirmAccessReview records that an access review confirmed members keep
+// their access, so they aren't listed as stale until they are inactive again.
+// Users that aren't members are reported as failed.
+func (s *OrganizationService) ConfirmAccessReview(ctx context.Context, orgID string, req models.AccessReviewDecisionRequest, reviewedBy string) (*models.BulkMemberResponse, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for confirming access review")
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(reviewedBy, models.PermOrgManageMembers) {
+		return nil, insufficientPermissions("review organization access")
+	}
+
+	response := models.NewBulkMemberResponse(len(req.UserIDs))
+	confirmed := make([]string, 0, len(req.UserIDs))
+	for i, userID := range req.UserIDs {
+		if org.GetMember(userID) == nil {
+			response.Add(i, models.BulkMemberConfirm, userID, apperrors.NotFound("MEMBER_NOT_FOUND", "member not found in organization"))
+			continue
+		}
+		response.Add(i, models.BulkMemberConfirm, userID, nil)
+		confirmed = append(confirmed, userID)
+	}
+	if len(confirmed) == 0 {
+		return response, nil
+	}
+
+	reviewedAt := time.Now()
+	if err := s.orgRepo.SetMembersReviewed(ctx, orgID, confirmed, reviewedAt, reviewedBy); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to confirm organization access review")
+		return nil, err
+	}
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationAccessReviewConfirmed,
+		kafka.OrganizationAccessReviewV1{
+			OrgID:      org.ID,
+			OrgName:    org.Name,
+			UserIDs:    confirmed,
+			ReviewedBy: reviewedBy,
+			ReviewedAt: reviewedAt,
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).
+			Msg("Failed to publish organization.access_review.confirmed event")
+	}
+
+	return response, nil
+}
+
+// RevokeAccessReview removes members whose access an access review revoked.
+// Each removal is checked like a bulk member removal, and the removals are
+// published as a single organization.member.batch event.
+func (s *OrganizationService) RevokeAccessReview(ctx context.Context, orgID string, req models.AccessReviewDecisionRequest, reviewedBy string) (*models.BulkMemberResponse, error) {
+	operations := make([]models.BulkOrganizationMemberOperation, len(req.UserIDs))
+	for i, userID := range req.UserIDs {
+		operations[i] = models.BulkOrganizationMemberOperation{Op: models.BulkMemberRemove, UserID: userID}
+	}
+
+	return s.BulkOrganizationMembers(ctx, orgID, models.BulkOrganizationMembersRequest{Operations: operations}, reviewedBy)
+}
+
+// GetOrganizationTeams gets the teams in an organization that a user can
+// see, with the viewer they are shown to, only including teams with all of
+// the tags. Archived teams are left out unless they are included.
+func (s *OrganizationService) GetOrganizationTeams(ctx context.Context, orgID string, tags []string, page, limit int, fields models.FieldSet, includeArchived bool, userID string) ([]*models.Team, models.TeamViewer, int64, error) {
+	// Verify organization exists
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, models.TeamViewer{}, 0, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for teams")
+		return nil, models.TeamViewer{}, 0, err
+	}
+
+	// Verify user is member of the organization
+	if !org.Can(userID, models.PermOrgView) {
+		return nil, models.TeamViewer{}, 0, ErrNotOrganizationMember
+	}
+
+	// Get teams
+	viewer := models.NewTeamViewer(userID, org)
+	teams, total, err := s.teamRepo.GetTeamsByOrganization(ctx, orgID, tags, page, limit, fields, viewer.HiddenFrom(), includeArchived)
+	if err != nil {
+		return nil, models.TeamViewer{}, 0, err
+	}
+	return teams, viewer, total, nil
+}
+
+// GetApprovalWebhook gets the approval webhook of an organization
+func (s *OrganizationService) GetApprovalWebhook(ctx context.Context, orgID string, userID string) (*models.ApprovalWebhookSettings, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for approval webhook")
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgViewApprovalWebhook) {
+		return nil, insufficientPermissions("view approval webhook")
+	}
+
+	if org.Settings.ApprovalWebhook == nil {
+		return nil, apperrors.NotFound("APPROVAL_WEBHOOK_NOT_FOUND", "approval webhook not configured")
+	}
+
+	return org.Settings.ApprovalWebhook, nil
+}
+
+// UpdateApprovalWebhook configures the approval webhook of an organization
+func (s *OrganizationService) UpdateApprovalWebhook(ctx context.Context, orgID string, req models.UpdateApprovalWebhookRequest, userID string) (*models.ApprovalWebhookSettings, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for approval webhook update")
+		return nil, err
+	}
+
+	// Check permissions - must be owner
+	if !org.Can(userID, models.PermOrgManageApprovalWebhook) {
+		return nil, insufficientPermissions("configure approval webhook")
+	}
+
+	// Apply changes
+	settingsBefore := org.Settings.Clone()
+	if org.Settings.ApprovalWebhook == nil {
+		org.Settings.ApprovalWebhook = &models.ApprovalWebhookSettings{}
+	}
+	org.Settings.ApprovalWebhook.Apply(req, userID)
+	org.UpdatedAt = time.Now()
+
+	// Save to database
+	err = s.orgRepo.Update(ctx, org)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to update approval webhook")
+		return nil, err
+	}
+	s.recordSettingsVersion(ctx, org, settingsBefore, userID, 0)
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationUpdated,
+		org.ToResponse(false, true),
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to publish organization.updated event")
+	}
+
+	return org.Settings.ApprovalWebhook, nil
+}
+
+// DeleteApprovalWebhook removes the approval webhook of an organization
+func (s *OrganizationService) DeleteApprovalWebhook(ctx context.Context, orgID string, userID string) error {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for approval webhook deletion")
+		return err
+	}
+
+	// Check permissions - must be owner
+	if !org.Can(userID, models.PermOrgManageApprovalWebhook) {
+		return insufficientPermissions("configure approval webhook")
+	}
+
+	if org.Settings.ApprovalWebhook == nil {
+		return apperrors.NotFound("APPROVAL_WEBHOOK_NOT_FOUND", "approval webhook not configured")
+	}
+
+	// Remove webhook
+	settingsBefore := org.Settings.Clone()
+	org.Settings.ApprovalWebhook = nil
+	org.UpdatedAt = time.Now()
+
+	err = s.orgRepo.Update(ctx, org)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to delete approval webhook")
+		return err
+	}
+	s.recordSettingsVersion(ctx, org, settingsBefore, userID, 0)
+
+	return nil
+}
+
+// GetCustomFields gets the custom profile fields of an organization
+func (s *OrganizationService) GetCustomFields(ctx context.Context, orgID string, userID string) ([]models.CustomFieldDefinition, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for custom fields")
+		return nil, err
+	}
+
+	// Verify user is member of the organization
+	if !org.Can(userID, models.PermOrgView) {
+		return nil, ErrNotOrganizationMember
+	}
+
+	if org.Settings.CustomFields == nil {
+		return []models.CustomFieldDefinition{}, nil
+	}
+	return org.Settings.CustomFields, nil
+}
+
+// UpdateCustomFields replaces the custom profile fields of an organization.
+// Values of removed fields are kept on users but no longer shown.
+func (s *OrganizationService) UpdateCustomFields(ctx context.Context, orgID string, req models.UpdateCustomFieldsRequest, userID string) ([]models.CustomFieldDefinition, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for custom fields update")
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgManageCustomFields) {
+		return nil, insufficientPermissions("manage custom fields")
+	}
+
+	if err := models.ValidateCustomFieldDefinitions(req.Fields); err != nil {
+		return nil, err
+	}
+
+	// Apply changes
+	settingsBefore := org.Settings.Clone()
+	org.Settings.CustomFields = req.Fields
+	if org.Settings.CustomFields == nil {
+		org.Settings.CustomFields = []models.CustomFieldDefinition{}
+	}
+	org.UpdatedAt = time.Now()
+
+	// Save to database
+	err = s.orgRepo.Update(ctx, org)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to update custom fields")
+		return nil, err
+	}
+	s.recordSettingsVersion(ctx, org, settingsBefore, userID, 0)
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationUpdated,
+		org.ToResponse(false, true),
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to publish organization.updated event")
+	}
+
+	return org.Settings.CustomFields, nil
+}
+
+// GetSettingsHistory lists the versions of an organization's settings,
+// newest first
+func (s *OrganizationService) GetSettingsHistory(ctx context.Context, orgID string, page, limit int, userID string) ([]*models.SettingsVersion, int64, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, 0, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for settings history")
+		return nil, 0, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgUpdate) {
+		return nil, 0, insufficientPermissions("view settings history")
+	}
+
+	return s.settingsRepo.ListByOrganization(ctx, orgID, page, limit)
+}
+
+// RollbackSettings restores the settings of an organization to a recorded
+// version. The rollback is recorded as a new version, so it can be undone in
+// turn. Restoring a different approval webhook or custom fields takes the
+// permission to manage them.
+func (s *OrganizationService) RollbackSettings(ctx context.Context, orgID string, version int, userID string) (*models.Organization, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for settings rollback")
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgUpdate) {
+		return nil, insufficientPermissions("roll back settings")
+	}
+
+	target, err := s.settingsRepo.GetVersion(ctx, orgID, version)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrSettingsVersionNotFound
+		}
+		return nil, err
+	}
+
+	if !reflect.DeepEqual(org.Settings.ApprovalWebhook, target.Settings.ApprovalWebhook) && !org.Can(userID, models.PermOrgManageApprovalWebhook) {
+		return nil, insufficientPermissions("configure approval webhook")
+	}
+	if !reflect.DeepEqual(org.Settings.CustomFields, target.Settings.CustomFields) && !org.Can(userID, models.PermOrgManageCustomFields) {
+		return nil, insufficientPermissions("manage custom fields")
+	}
+
+	// Apply changes
+	settingsBefore := org.Settings.Clone()
+	org.Settings = target.Settings
+	org.UpdatedAt = time.Now()
+
+	// Save to database
+	err = s.orgRepo.Update(ctx, org)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Int("version", version).Msg("Failed to roll back settings")
+		return nil, err
+	}
+	s.recordSettingsVersion(ctx, org, settingsBefore, userID, version)
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationUpdated,
+		org.ToResponse(false, true),
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to publish organization.updated event")
+	}
+
+	return org, nil
+}
+
+// recordSettingsVersion records the settings of an organization as a new
+// version if they changed from before. The settings from before the first
+// recorded change are recorded first, so they can be restored. Failures are
+// logged; the change itself is already saved.
+func (s *OrganizationService) recordSettingsVersion(ctx context.Context, org *models.Organization, before models.OrganizationSettings, userID string, restoredVersion int) {
+	changes := models.DiffSettings(before, org.Settings)
+	if len(changes) == 0 {
+		return
+	}
+
+	// Concurrent changes may take the same version number; retry with the
+	// next one
+	for attempt := 0; attempt < 3; attempt++ {
+		latest, err := s.settingsRepo.GetLatestVersion(ctx, org.ID)
+		if err != nil {
+			return
+		}
+
+		if latest == 0 {
+			err = s.settingsRepo.Create(ctx, models.NewSettingsVersion(org.ID, 1, before, nil, ""))
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
+			if err != nil {
+				return
+			}
+			latest = 1
+		}
+
+		version := models.NewSettingsVersion(org.ID, latest+1, org.Settings, changes, userID)
+		version.RestoredVersion = restoredVersion
+		err = s.settingsRepo.Create(ctx, version)
+		if err == nil || !mongo.IsDuplicateKeyError(err) {
+			return
+		}
+	}
+
+	logger.Ctx(ctx).Warn().Str("orgId", org.ID).Msg("Failed to record settings version after concurrent changes")
+}
+
+// UpdateMemberCustomFields replaces the custom profile field values of an
+// organization member. Members may set their own values.
+func (s *OrganizationService) UpdateMemberCustomFields(ctx context.Context, orgID, memberID string, req models.UpdateMemberCustomFieldsRequest, userID string) (map[string]interface{}, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for member custom fields")
+		return nil, err
+	}
+
+	// Check permissions
+	if memberID != userID && !org.Can(userID, models.PermOrgManageMembers) {
+		return nil, insufficientPermissions("update member custom fields")
+	}
+	if !org.IsMember(memberID) {
+		return nil, apperrors.NotFound("MEMBER_NOT_FOUND", "member not found in organization")
+	}
+
+	values, err := org.Settings.ValidateCustomFieldValues(req.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.userRepo.SetCustomFields(ctx, memberID, org.ID, values)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Str("memberId", memberID).Msg("Failed to update member custom fields")
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// checkMemberAdd runs the checks every way of adding a user to an
+// organization goes through: the user's signup review, the organization's
+// email domain and two-factor policies, and its approval webhook, which is
+// asked last. field names the request field that identifies the user.
+func (s *OrganizationService) checkMemberAdd(ctx context.Context, org *models.Organization, user *models.User, role models.OrganizationMemberRole, addedBy, field string) error {
+	if user.IsPendingReview() {
+		return ErrUserPendingReview
+	}
+	if err := checkEmailDomain(org, user, field); err != nil {
+		return err
+	}
+	if err := checkTwoFactor(org, user, field); err != nil {
+		return err
+	}
+
+	// Require external approval before committing the change
+	return s.requestApproval(ctx, org, models.NewApprovalRequest(
+		models.ApprovalActionMemberAdd, org.ID, user.UserID, role, "", addedBy,
+	))
+}
+
+// requestApproval calls the organization's approval webhook synchronously,
+// applying the configured fallback policy when the webhook cannot be reached
+func (s *OrganizationService) requestApproval(ctx context.Context, org *models.Organization, req models.ApprovalRequest) error {
+	hook := org.Settings.ApprovalWebhook
+	if !hook.Requires(req.Action) {
+		return nil
+	}
+
+	timeout := time.Duration(hook.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = models.DefaultApprovalTimeoutMs * time.Millisecond
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var decision models.ApprovalDecision
+	err := webhook.Post(callCtx, hook.URL, hook.Secret, req, &decision)
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("orgId", org.ID).Str("userId", req.UserID).
+			Str("action", string(req.Action)).Str("fallback", string(hook.FallbackPolicy)).
+			Msg("Approval webhook call failed, applying fallback policy")
+
+		if hook.FallbackPolicy == models.ApprovalFallbackAllow {
+			return nil
+		}
+		return ErrApprovalUnavailable
+	}
+
+	if !decision.Approved {
+		logger.Ctx(ctx).Info().Str("orgId", org.ID).Str("userId", req.UserID).
+			Str("action", string(req.Action)).Str("reason", decision.Reason).
+			Msg("Membership change rejected by approval webhook")
+
+		if decision.Reason != "" {
+			return ErrApprovalDenied.WithDetails(map[string]string{"reason": decision.Reason})
+		}
+		return ErrApprovalDenied
+	}
+
+	logger.Ctx(ctx).Debug().Str("orgId", org.ID).Str("userId", req.UserID).
+		Str("action", string(req.Action)).Msg("Membership change approved by approval webhook")
+
+	return nil
+}
+
+// checkEmailDomain checks that an organization's email domain lists let a
+// user join it. Violations name the request field and the user's domain.
+func checkEmailDomain(org *models.Organization, user *models.User, field string) error {
+	rule := org.Settings.EmailDomainViolation(user.Email)
+
+	var err *apperrors.Error
+	switch rule {
+	case models.EmailDomainBlocked:
+		err = ErrEmailDomainBlocked
+	case models.EmailDomainExternal:
+		err = ErrEmailDomainNotAllowed
+	default:
+		return nil
+	}
+
+	return err.WithDetails([]apperrors.FieldError{{
+		Field:   field,
+		Rule:    string(rule),
+		Param:   models.EmailDomain(user.Email),
+		Message: err.Message,
+	}})
+}
+
+// checkTwoFactor checks that a user has two-factor authentication enabled,
+// if the organization requires it. field is the request field naming the user.
+func checkTwoFactor(org *models.Organization, user *models.User, field string) error {
+	if !org.Settings.RequireTwoFactor || user.TwoFactorEnabled() {
+		return nil
+	}
+
+	return ErrTwoFactorRequired.WithDetails([]apperrors.FieldError{{
+		Field:   field,
+		Rule:    "two_factor_required",
+		Param:   user.UserID,
+		Message: ErrTwoFactorRequired.Message,
+	}})
+}
+
+// TransferOwnership starts a organization ownership transfer that the new owner must accept
+func (s *OrganizationService) TransferOwnership(ctx context.Context, orgID string, req models.TransferOwnershipRequest, userID string) (*models.OwnershipTransfer, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for ownership transfer")
+		return nil, err
+	}
+
+	// Check permissions - must be owner
+	if !org.Can(userID, models.PermOrgTransferOwnership) {
+		return nil, apperrors.Forbidden("OWNER_REQUIRED", "only an organization owner can transfer ownership")
+	}
+
+	// Validate target
+	if req.NewOwnerID == userID {
+		return nil, ErrTransferToSelf
+	}
+	target := org.GetMember(req.NewOwnerID)
+	if target == nil {
+		return nil, apperrors.InvalidField("newOwnerId", "new owner must be a member of the organization")
+	}
+	if target.Role == models.OrgRoleOwner {
+		return nil, apperrors.Conflict("ALREADY_OWNER", "new owner is already an organization owner")
+	}
+	if req.PreviousOwnerRole == "viewer" {
+		return nil, apperrors.InvalidField("previousOwnerRole", "previous owner role must be admin, member or remove")
+	}
+
+	// Verify target user exists
+	targetUser, err := s.userRepo.GetByUserId(ctx, req.NewOwnerID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", req.NewOwnerID).Msg("Failed to get user for ownership transfer")
+		return nil, err
+	}
+	if err := checkTwoFactor(org, targetUser, "newOwnerId"); err != nil {
+		return nil, err
+	}
+
+	// Store pending transfer, replacing any previous one
+	transfer := models.NewOwnershipTransfer(userID, req)
+	err = s.orgRepo.SetPendingTransfer(ctx, orgID, transfer)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("newOwnerId", req.NewOwnerID).
+			Msg("Failed to store pending ownership transfer")
+		return nil, err
+	}
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationOwnershipTransferRequested,
+		kafka.OwnershipTransferRequestedV1{
+			OrgID:             org.ID,
+			OrgName:           org.Name,
+			FromUserID:        transfer.FromUserID,
+			ToUserID:          transfer.ToUserID,
+			PreviousOwnerRole: transfer.PreviousOwnerRole,
+			RequestedAt:       transfer.RequestedAt,
+			ExpiresAt:         transfer.ExpiresAt,
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to publish organization.ownership.transfer_requested event")
+	}
+
+	return transfer, nil
+}
+
+// AcceptOwnershipTransfer completes a pending organization ownership transfer on behalf of the new owner
+func (s *OrganizationService) AcceptOwnershipTransfer(ctx context.Context, orgID string, userID string) error {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for ownership transfer acceptance")
+		return err
+	}
+
+	// Validate acceptance
+	transfer := org.PendingTransfer
+	if transfer == nil {
+		return ErrNoPendingOwnershipTransfer
+	}
+	if transfer.ToUserID != userID {
+		return ErrNotTransferRecipient
+	}
+	if transfer.IsExpired() {
+		if err := s.orgRepo.SetPendingTransfer(ctx, orgID, nil); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to clear expired ownership transfer")
+		}
+		return ErrOwnershipTransferExpired
+	}
+
+	// The new owner may have disabled two-factor authentication since the
+	// transfer was requested
+	if org.Settings.RequireTwoFactor {
+		user, err := s.userRepo.GetByUserId(ctx, userID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return ErrUserNotFound
+			}
+			logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user for ownership transfer acceptance")
+			return err
+		}
+		if err := checkTwoFactor(org, user, "userId"); err != nil {
+			return err
+		}
+	}
+
+	// Swap ownership atomically
+	err = s.orgRepo.TransferOwnership(ctx, orgID, transfer)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrOwnershipTransferInvalid
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to transfer organization ownership")
+		return err
+	}
+
+	// Remove organization from the previous owner if they are leaving
+	if transfer.RemovesPreviousOwner() {
+		err = s.userRepo.RemoveOrganizationFromUser(ctx, transfer.FromUserID, orgID)
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", transfer.FromUserID).
+				Msg("Failed to remove organization from previous owner")
+			// Don't fail the operation, but log the error
+		}
+	}
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationOwnershipTransferred,
+		kafka.OwnershipTransferredV1{
+			OrgID:             org.ID,
+			OrgName:           org.Name,
+			PreviousOwnerID:   transfer.FromUserID,
+			NewOwnerID:        transfer.ToUserID,
+			PreviousOwnerRole: transfer.PreviousOwnerRole,
+			TransferredAt:     time.Now(),
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to publish organization.ownership.transferred event")
+	}
+
+	return nil
+}
+
+// CancelOwnershipTransfer cancels or declines a pending organization ownership transfer
+func (s *OrganizationService) CancelOwnershipTransfer(ctx context.Context, orgID string, userID string) error {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for ownership transfer cancellation")
+		return err
+	}
+
+	transfer := org.PendingTransfer
+	if transfer == nil {
+		return ErrNoPendingOwnershipTransfer
+	}
+
+	// Owners may cancel, the new owner may decline
+	if transfer.ToUserID != userID && !org.Can(userID, models.PermOrgTransferOwnership) {
+		return insufficientPermissions("cancel ownership transfer")
+	}
+
+	err = s.orgRepo.SetPendingTransfer(ctx, orgID, nil)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to cancel ownership transfer")
+		return err
+	}
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationOwnershipTransferCancelled,
+		kafka.OwnershipTransferCancelledV1{
+			OrgID:       org.ID,
+			OrgName:     org.Name,
+			FromUserID:  transfer.FromUserID,
+			ToUserID:    transfer.ToUserID,
+			CancelledBy: userID,
+			CancelledAt: time.Now(),
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to publish organization.ownership.transfer_cancelled event")
+	}
+
+	return nil
+}
+
+// CreateJoinRequest records a user's request to join an organization that accepts external users
+func (s *OrganizationService) CreateJoinRequest(ctx context.Context, orgID string, req models.CreateJoinRequestRequest, userID string) (*models.JoinRequest, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for join request")
+		return nil, err
+	}
+
+	// Only organizations that allow external users accept join requests
+	if !org.Settings.Features.AllowExternalUsers {
+		return nil, apperrors.Forbidden("JOIN_REQUESTS_DISABLED", "organization is not accepting join requests")
+	}
+	if org.IsMember(userID) {
+		return nil, apperrors.Conflict("ALREADY_MEMBER", "user is already a member of the organization")
+	}
+
+	// Verify user exists
+	user, err := s.userRepo.GetByUserId(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user for join request")
+		return nil, err
+	}
+	if user.IsPendingReview() {
+		return nil, ErrUserPendingReview
+	}
+	if err := checkEmailDomain(org, user, "email"); err != nil {
+		return nil, err
+	}
+
+	// Save join request; at most one can be pending per user
+	joinReq := models.NewJoinRequest(orgID, userID, req)
+	if err := s.joinRequestRepo.Create(ctx, joinReq); err != nil {
+		return nil, err
+	}
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationJoinRequested,
+		kafka.JoinRequestCreatedV1{
+			RequestID: joinReq.ID,
+			OrgID:     org.ID,
+			OrgName:   org.Name,
+			UserID:    joinReq.UserID,
+			UserEmail: user.Email,
+			UserName:  user.FirstName + " " + user.LastName,
+			Message:   joinReq.Message,
+			CreatedAt: joinReq.CreatedAt,
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("requestId", joinReq.ID).
+			Msg("Failed to publish organization.join_request.created event")
+	}
+
+	return joinReq, nil
+}
+
+// GetJoinRequests lists the pending join requests of an organization
+func (s *OrganizationService) GetJoinRequests(ctx context.Context, orgID string, page, limit int, userID string) ([]*models.JoinRequest, int64, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, 0, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for join requests")
+		return nil, 0, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgReviewJoinRequests) {
+		return nil, 0, insufficientPermissions("view join requests")
+	}
+
+	reqs, total, err := s.joinRequestRepo.ListByOrganization(ctx, orgID, models.JoinRequestPending, page, limit)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to list join requests")
+		return nil, 0, err
+	}
+
+	return reqs, total, nil
+}
+
+// GetJoinRequestUser gets the user who made a join request, or nil if the user no longer exists
+func (s *OrganizationService) GetJoinRequestUser(ctx context.Context, req *models.JoinRequest) *models.User {
+	user, err := s.userRepo.GetByUserId(ctx, req.UserID)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Error().Err(err).Str("userId", req.UserID).Msg("Failed to get user for join request")
+		}
+		return nil
+	}
+	return user
+}
+
+// ApproveJoinRequest approves a pending join request and adds the user to the organization
+func (s *OrganizationService) ApproveJoinRequest(ctx context.Context, orgID, requestID string, req models.ApproveJoinRequestRequest, reviewedBy string) (*models.JoinRequest, error) {
+	org, joinReq, err := s.getPendingJoinRequest(ctx, orgID, requestID, reviewedBy)
+	if err != nil {
+		return nil, err
+	}
+	if joinReq.IsInvitation() {
+		return nil, errInvitationNotApprovable
+	}
+
+	// Approved users join with the requested role, falling back to the default role
+	role := req.Role
+	if role == "" {
+		role = org.Settings.DefaultUserRole
+	}
+	if role != models.OrgRoleAdmin {
+		role = models.OrgRoleMember
+	}
+
+	// Add member; this also runs the approval webhook and publishes organization.member.added
+	err = s.AddOrganizationMember(ctx, orgID, models.AddOrganizationMemberRequest{
+		UserID: joinReq.UserID,
+		Role:   role,
+	}, reviewedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	joinReq.Status = models.JoinRequestApproved
+	joinReq.Role = role
+	joinReq.ReviewedBy = reviewedBy
+	joinReq.ReviewReason = req.Reason
+	joinReq.ReviewedAt = &now
+	if err := s.resolveJoinRequest(ctx, org, joinReq, kafka.OrganizationJoinRequestApproved); err != nil {
+		return nil, err
+	}
+
+	return joinReq, nil
+}
+
+// RejectJoinRequest rejects a pending join request
+func (s *OrganizationService) RejectJoinRequest(ctx context.Context, orgID, requestID string, req models.RejectJoinRequestRequest, reviewedBy string) (*models.JoinRequest, error) {
+	org, joinReq, err := s.getPendingJoinRequest(ctx, orgID, requestID, reviewedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	joinReq.Status = models.JoinRequestRejected
+	joinReq.ReviewedBy = reviewedBy
+	joinReq.ReviewReason = req.Reason
+	joinReq.ReviewedAt = &now
+	if err := s.resolveJoinRequest(ctx, org, joinReq, kafka.OrganizationJoinRequestRejected); err != nil {
+		return nil, err
+	}
+
+	return joinReq, nil
+}
+
+// CancelJoinRequest withdraws the user's own pending join request
+func (s *OrganizationService) CancelJoinRequest(ctx context.Context, orgID string, userID string) error {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for join request cancellation")
+		return err
+	}
+
+	joinReq, err := s.joinRequestRepo.GetPendingByUser(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("JOIN_REQUEST_NOT_FOUND", "no pending join request")
+		}
+		return err
+	}
+
+	now := time.Now()
+	joinReq.Status = models.JoinRequestCancelled
+	joinReq.ReviewedAt = &now
+	return s.resolveJoinRequest(ctx, org, joinReq, kafka.OrganizationJoinRequestCancelled)
+}
+
+// AcceptInvitation accepts the user's own pending invitation to join an
+// organization. The user joins with the invited role as if the inviter added
+// them, so the checks of member adds run as of now.
+func (s *OrganizationService) AcceptInvitation(ctx context.Context, orgID string, userID string) (*models.JoinRequest, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for invitation")
+		return nil, err
+	}
+
+	joinReq, err := s.joinRequestRepo.GetPendingByUser(ctx, orgID, userID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if joinReq == nil || !joinReq.IsInvitation() {
+		return nil, apperrors.NotFound("INVITATION_NOT_FOUND", "no pending invitation")
+	}
+
+	// Add member; this also runs the approval webhook and publishes organization.member.added
+	err = s.AddOrganizationMember(ctx, orgID, models.AddOrganizationMemberRequest{
+		UserID:    joinReq.UserID,
+		Role:      joinReq.Role,
+		ExpiresAt: joinReq.ExpiresAt,
+	}, joinReq.InvitedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	joinReq.Status = models.JoinRequestApproved
+	joinReq.ReviewedBy = userID
+	joinReq.ReviewedAt = &now
+	if err := s.resolveJoinRequest(ctx, org, joinReq, kafka.OrganizationJoinRequestApproved); err != nil {
+		return nil, err
+	}
+
+	return joinReq, nil
+}
+
+// inviteMember invites a user to join an organization with a role, instead of
+// adding them, for when the inviter isn't trusted to speak for the user
+func (s *OrganizationService) inviteMember(ctx context.Context, org *models.Organization, user *models.User, role models.OrganizationMemberRole, expiresAt *time.Time, invitedBy string) (*models.JoinRequest, error) {
+	if user.IsPendingReview() {
+		return nil, ErrUserPendingReview
+	}
+	if err := checkEmailDomain(org, user, "email"); err != nil {
+		return nil, err
+	}
+
+	// Save invitation; at most one join request can be pending per user
+	invitation := models.NewInvitation(org.ID, user.UserID, role, expiresAt, invitedBy)
+	if err := s.joinRequestRepo.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationJoinRequested,
+		kafka.JoinRequestCreatedV1{
+			RequestID: invitation.ID,
+			OrgID:     org.ID,
+			OrgName:   org.Name,
+			UserID:    invitation.UserID,
+			UserEmail: user.Email,
+			UserName:  user.FirstName + " " + user.LastName,
+			Role:      string(invitation.Role),
+			InvitedBy: invitation.InvitedBy,
+			CreatedAt: invitation.CreatedAt,
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("requestId", invitation.ID).
+			Msg("Failed to publish organization.join_request.created event")
+	}
+
+	return invitation, nil
+}
+
+// getPendingJoinRequest gets an organization and one of its pending join requests for review
+func (s *OrganizationService) getPendingJoinRequest(ctx context.Context, orgID, requestID, reviewedBy string) (*models.Organization, *models.JoinRequest, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for join request review")
+		return nil, nil, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(reviewedBy, models.PermOrgReviewJoinRequests) {
+		return nil, nil, insufficientPermissions("review join requests")
+	}
+
+	joinReq, err := s.joinRequestRepo.GetByID(ctx, orgID, requestID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, apperrors.NotFound("JOIN_REQUEST_NOT_FOUND", "join request not found")
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("requestId", requestID).Msg("Failed to get join request")
+		return nil, nil, err
+	}
+	if !joinReq.IsPending() {
+		return nil, nil, errJoinRequestNotPending.WithDetails(map[string]string{"status": string(joinReq.Status)})
+	}
+
+	return org, joinReq, nil
+}
+
+// resolveJoinRequest stores the final status of a join request and publishes the matching event
+func (s *OrganizationService) resolveJoinRequest(ctx context.Context, org *models.Organization, joinReq *models.JoinRequest, eventType kafka.EventType) error {
+	err := s.joinRequestRepo.Resolve(ctx, joinReq)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return errJoinRequestNotPending
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("requestId", joinReq.ID).Msg("Failed to resolve join request")
+		return err
+	}
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		eventType,
+		kafka.JoinRequestResolvedV1{
+			RequestID:    joinReq.ID,
+			OrgID:        org.ID,
+			OrgName:      org.Name,
+			UserID:       joinReq.UserID,
+			Status:       string(joinReq.Status),
+			Role:         string(joinReq.Role),
+			ReviewedBy:   joinReq.ReviewedBy,
+			ReviewReason: joinReq.ReviewReason,
+			ReviewedAt:   joinReq.ReviewedAt,
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("requestId", joinReq.ID).
+			Msgf("Failed to publish %s event", eventType)
+	}
+
+	return nil
 }
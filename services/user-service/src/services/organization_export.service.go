@@ -0,0 +1,499 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/lifecycle"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Organization export errors
+var (
+	// ErrExportNotFound is returned when an organization has no export with the ID
+	ErrExportNotFound = apperrors.NotFound("EXPORT_NOT_FOUND", "organization export not found")
+	// ErrExportNotReady is returned when downloading an export that is running or failed
+	ErrExportNotReady = apperrors.Conflict("EXPORT_NOT_READY", "organization export has no archive to download")
+	// ErrExportExpired is returned when downloading an export whose archive was removed
+	ErrExportExpired = apperrors.Gone("EXPORT_EXPIRED", "organization export archive has expired")
+	// ErrExportLinkExpired is returned when downloading with a signed URL that expired
+	ErrExportLinkExpired = apperrors.Gone("EXPORT_LINK_EXPIRED", "download link has expired")
+	// ErrExportSignatureInvalid is returned when downloading with a URL that isn't signed by the service
+	ErrExportSignatureInvalid = apperrors.Forbidden("EXPORT_SIGNATURE_INVALID", "download link signature is invalid")
+)
+
+// errExportTooLarge fails exports whose archive grows past the configured
+// size
+var errExportTooLarge = errors.New("archive exceeds the maximum export size")
+
+// exportStaleAfter is how long an export may run before another may start,
+// so an export interrupted by a restart doesn't block the organization forever
+const exportStaleAfter = time.Hour
+
+// exportBatchSize is how many teams, members or timeline entries an export
+// reads at a time, and how many expired exports the cleanup loads at a time
+const exportBatchSize = 500
+
+// OrganizationExportService exports organizations' data. Exports run in the
+// background and archive the organization's metadata, teams, members and
+// audit log as a zip file, which is downloaded with an expiring signed URL
+// and removed once the export expires.
+type OrganizationExportService struct {
+	exportRepo   *repositories.OrganizationExportRepository
+	orgRepo      repositories.OrgStore
+	teamRepo     repositories.TeamStore
+	timelineRepo *repositories.TimelineRepository
+	orgService   *OrganizationService
+	events       kafka.EventPublisher
+	config       *config.ExportConfig
+}
+
+// NewOrganizationExportService creates a new organization export service
+func NewOrganizationExportService(
+	exportRepo *repositories.OrganizationExportRepository,
+	orgRepo repositories.OrgStore,
+	teamRepo repositories.TeamStore,
+	timelineRepo *repositories.TimelineRepository,
+	orgService *OrganizationService,
+	events kafka.EventPublisher,
+	cfg *config.ExportConfig,
+) *OrganizationExportService {
+	return &OrganizationExportService{
+		exportRepo:   exportRepo,
+		orgRepo:      orgRepo,
+		teamRepo:     teamRepo,
+		timelineRepo: timelineRepo,
+		orgService:   orgService,
+		events:       events,
+		config:       cfg,
+	}
+}
+
+// StartExport starts an export of an organization in the background and
+// returns it; its status changes once the archive is written
+func (s *OrganizationExportService) StartExport(ctx context.Context, orgID string, req models.CreateOrganizationExportRequest, userID string) (*models.OrganizationExport, error) {
+	org, err := s.getExportableOrganization(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.exportRepo.FailStale(ctx, orgID, time.Now().Add(-exportStaleAfter), "export was interrupted"); err != nil {
+		return nil, err
+	}
+
+	export := models.NewOrganizationExport(orgID, req.Format, userID)
+	if err := s.exportRepo.Create(ctx, export); err != nil {
+		return nil, err
+	}
+	s.publish(ctx, export, kafka.OrganizationExportRequested)
+
+	started := *export
+	detached := tenancy.Inherit(logger.Detach(ctx), ctx)
+	lifecycle.Go(func() { s.execute(detached, org, export) })
+
+	return &started, nil
+}
+
+// ListExports lists the exports of an organization, newest first
+func (s *OrganizationExportService) ListExports(ctx context.Context, orgID string, page, limit int, userID string) ([]*models.OrganizationExportResponse, int64, error) {
+	if _, err := s.getExportableOrganization(ctx, orgID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	exports, total, err := s.exportRepo.List(ctx, orgID, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*models.OrganizationExportResponse, len(exports))
+	for i, export := range exports {
+		responses[i] = s.toResponse(export)
+	}
+	return responses, total, nil
+}
+
+// GetExport gets an export of an organization, with a signed URL its
+// archive can be downloaded from once it's completed
+func (s *OrganizationExportService) GetExport(ctx context.Context, orgID, exportID string, userID string) (*models.OrganizationExportResponse, error) {
+	if _, err := s.getExportableOrganization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	export, err := s.getExport(ctx, orgID, exportID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(export), nil
+}
+
+// Download opens the archive of an export with the expiry and signature of
+// its signed URL, to be read chunk by chunk. The URL is its own
+// authorization, so it's checked instead of the caller's permissions.
+func (s *OrganizationExportService) Download(ctx context.Context, orgID, exportID, expires, signature string) (*models.OrganizationExport, *repositories.ExportFileReader, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !s.verify(orgID, exportID, expires, signature) {
+		return nil, nil, ErrExportSignatureInvalid
+	}
+	now := time.Now()
+	if now.Unix() >= expiresAt {
+		return nil, nil, ErrExportLinkExpired
+	}
+
+	export, err := s.getExport(ctx, orgID, exportID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if export.Expired(now) {
+		s.expire(ctx, export)
+		return nil, nil, ErrExportExpired
+	}
+	if export.Status != models.ExportCompleted {
+		return nil, nil, ErrExportNotReady
+	}
+
+	file, err := s.exportRepo.OpenFile(ctx, orgID, exportID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			s.expire(ctx, export)
+			return nil, nil, ErrExportExpired
+		}
+		return nil, nil, err
+	}
+
+	logger.Ctx(ctx).Info().Str("orgId", orgID).Str("id", exportID).Msg("Organization export downloaded")
+	return export, file, nil
+}
+
+// RunCleanup removes the archives of expired exports until the context is
+// cancelled. MongoDB removes expired archives itself, but other storage
+// drivers don't, and the exports are marked as expired either way. It runs
+// as a singleton worker.
+func (s *OrganizationExportService) RunCleanup(ctx context.Context) {
+	ticker := time.NewTicker(s.config.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		s.cleanup(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cleanup expires every export whose archive expired
+func (s *OrganizationExportService) cleanup(ctx context.Context) {
+	for {
+		exports, err := s.exportRepo.FindExpired(ctx, time.Now(), exportBatchSize)
+		if err != nil || len(exports) == 0 {
+			return
+		}
+
+		expired := 0
+		for _, export := range exports {
+			if ctx.Err() != nil {
+				return
+			}
+			if s.expire(ctx, export) {
+				expired++
+			}
+		}
+		if expired == 0 {
+			return
+		}
+	}
+}
+
+// expire removes the archive of an export, reporting whether it was removed
+func (s *OrganizationExportService) expire(ctx context.Context, export *models.OrganizationExport) bool {
+	if export.Status != models.ExportCompleted {
+		return false
+	}
+	if err := s.exportRepo.Expire(ctx, export); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("orgId", export.OrganizationID).Str("id", export.ID).Msg("Failed to expire organization export")
+		return false
+	}
+	return true
+}
+
+// execute writes the archive of an export and records its outcome
+func (s *OrganizationExportService) execute(ctx context.Context, org *models.Organization, export *models.OrganizationExport) {
+	file := s.exportRepo.CreateFile(ctx, org.ID, export.ID, time.Now().Add(s.config.Retention))
+	err := s.archive(ctx, org, export, file)
+	if err != nil {
+		if err := s.exportRepo.DeleteFile(ctx, org.ID, export.ID); err != nil {
+			logger.Ctx(ctx).Warn().Err(err).Str("orgId", org.ID).Str("id", export.ID).Msg("Failed to remove archive of failed organization export")
+		}
+	}
+
+	export.Finish(file.Length(), s.config.Retention, err)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("id", export.ID).Msg("Organization export failed")
+	}
+	if err := s.exportRepo.Save(ctx, export); err != nil {
+		return
+	}
+
+	eventType := kafka.OrganizationExportCompleted
+	if export.Status == models.ExportFailed {
+		eventType = kafka.OrganizationExportFailed
+	}
+	s.publish(ctx, export, eventType)
+}
+
+// archive writes the zip archive of an organization's data to its file as
+// it's compressed
+func (s *OrganizationExportService) archive(ctx context.Context, org *models.Organization, export *models.OrganizationExport, file *repositories.ExportFileWriter) error {
+	teams, err := s.exportTeams(ctx, org.ID)
+	if err != nil {
+		return fmt.Errorf("reading teams: %w", err)
+	}
+	members, err := s.exportMembers(ctx, org)
+	if err != nil {
+		return fmt.Errorf("reading members: %w", err)
+	}
+	entries, err := s.exportAuditLog(ctx, org.ID)
+	if err != nil {
+		return fmt.Errorf("reading audit log: %w", err)
+	}
+
+	archive := zip.NewWriter(file)
+	write := func(name string, writeFile func(archive *zip.Writer, name string) error) error {
+		if err := writeFile(archive, name); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		if file.Length() > int64(s.config.MaxSize) {
+			return errExportTooLarge
+		}
+		return nil
+	}
+
+	if err := write(models.ExportOrganizationFile, writeExportJSON(org.ToResponse(false, true))); err != nil {
+		return err
+	}
+	switch export.Format {
+	case models.ExportCSV:
+		files := map[string][][]string{
+			models.ExportTeamsFile:       models.ExportTeamsCSV(teams),
+			models.ExportTeamMembersFile: models.ExportTeamMembersCSV(teams),
+			models.ExportMembersFile:     models.ExportMembersCSV(members),
+			models.ExportAuditLogFile:    models.ExportAuditLogCSV(entries),
+		}
+		for _, name := range []string{models.ExportTeamsFile, models.ExportTeamMembersFile, models.ExportMembersFile, models.ExportAuditLogFile} {
+			if err := write(export.FileName(name), writeExportCSV(files[name])); err != nil {
+				return err
+			}
+		}
+	default:
+		files := map[string]interface{}{
+			models.ExportTeamsFile:    teams,
+			models.ExportMembersFile:  members,
+			models.ExportAuditLogFile: entries,
+		}
+		for _, name := range []string{models.ExportTeamsFile, models.ExportMembersFile, models.ExportAuditLogFile} {
+			if err := write(export.FileName(name), writeExportJSON(files[name])); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	if file.Length() > int64(s.config.MaxSize) {
+		return errExportTooLarge
+	}
+	return file.Close()
+}
+
+// writeExportJSON writes a value to an archive as an indented JSON file
+func writeExportJSON(value interface{}) func(archive *zip.Writer, name string) error {
+	return func(archive *zip.Writer, name string) error {
+		f, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+}
+
+// writeExportCSV writes records to an archive as a CSV file
+func writeExportCSV(records [][]string) func(archive *zip.Writer, name string) error {
+	return func(archive *zip.Writer, name string) error {
+		f, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		return csv.NewWriter(f).WriteAll(records)
+	}
+}
+
+// exportTeams reads every team of an organization, archived ones included
+func (s *OrganizationExportService) exportTeams(ctx context.Context, orgID string) ([]*models.Team, error) {
+	teams := []*models.Team{}
+	for skip := int64(0); ; skip += exportBatchSize {
+		batch, _, err := s.teamRepo.FindTeams(ctx, bson.M{"organizationId": orgID}, skip, exportBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, batch...)
+		if len(batch) < exportBatchSize {
+			return teams, nil
+		}
+	}
+}
+
+// exportMembers reads every member of an organization, with the details of
+// their users
+func (s *OrganizationExportService) exportMembers(ctx context.Context, org *models.Organization) ([]models.OrganizationMemberDetail, error) {
+	members := []models.OrganizationMemberDetail{}
+	for page := 1; ; page++ {
+		batch, _, err := s.orgRepo.GetMembers(ctx, org, models.OrganizationMemberFilter{}, page, exportBatchSize)
+		if errors.Is(err, db.ErrAggregationUnsupported) {
+			batch, _, err = s.orgService.pageMembers(ctx, org, models.OrganizationMemberFilter{}, page, exportBatchSize)
+		}
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, batch...)
+		if len(batch) < exportBatchSize {
+			return members, nil
+		}
+	}
+}
+
+// exportAuditLog reads every timeline entry of an organization, newest first
+func (s *OrganizationExportService) exportAuditLog(ctx context.Context, orgID string) ([]*models.TimelineEntry, error) {
+	entries := []*models.TimelineEntry{}
+	var after *models.TimelineCursor
+	for {
+		batch, err := s.timelineRepo.GetByOrganization(ctx, orgID, nil, after, exportBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, batch...)
+		if len(batch) < exportBatchSize {
+			return entries, nil
+		}
+		last := batch[len(batch)-1]
+		after = &models.TimelineCursor{OccurredAt: last.OccurredAt, ID: last.ID}
+	}
+}
+
+// toResponse adds a signed download URL to an export whose archive can be
+// downloaded
+func (s *OrganizationExportService) toResponse(export *models.OrganizationExport) *models.OrganizationExportResponse {
+	response := &models.OrganizationExportResponse{OrganizationExport: export}
+	now := time.Now()
+	if !export.Downloadable(now) {
+		if export.Status == models.ExportCompleted {
+			export.Status = models.ExportExpired
+		}
+		return response
+	}
+
+	expiresAt := now.Add(s.config.URLTTL).Truncate(time.Second)
+	if expiresAt.After(*export.ExpiresAt) {
+		expiresAt = export.ExpiresAt.Truncate(time.Second)
+	}
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(export.OrganizationID, export.ID, expires))
+	response.DownloadURL = "/api/organizations/" + url.PathEscape(export.OrganizationID) +
+		"/exports/" + url.PathEscape(export.ID) + "/download?" + query.Encode()
+	response.DownloadURLExpiresAt = &expiresAt
+	return response
+}
+
+// sign computes the HMAC-SHA256 signature of a download URL of an export.
+// URLs are signed with their own secret rather than the JWT secret, so a
+// leaked download key can't mint tokens and rotating either leaves the
+// other alone.
+func (s *OrganizationExportService) sign(orgID, exportID, expires string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.SigningSecret))
+	mac.Write([]byte(orgID + ":" + exportID + ":" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks the signature of a download URL
+func (s *OrganizationExportService) verify(orgID, exportID, expires, signature string) bool {
+	return hmac.Equal([]byte(s.sign(orgID, exportID, expires)), []byte(signature))
+}
+
+// getExport gets an export of an organization
+func (s *OrganizationExportService) getExport(ctx context.Context, orgID, exportID string) (*models.OrganizationExport, error) {
+	export, err := s.exportRepo.Get(ctx, orgID, exportID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrExportNotFound
+		}
+		return nil, err
+	}
+	return export, nil
+}
+
+// getExportableOrganization gets an organization whose data the user may export
+func (s *OrganizationExportService) getExportableOrganization(ctx context.Context, orgID, userID string) (*models.Organization, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for export")
+		return nil, err
+	}
+
+	if !org.Can(userID, models.PermOrgExport) {
+		return nil, insufficientPermissions("export the organization")
+	}
+	return org, nil
+}
+
+// publish publishes an organization export event
+func (s *OrganizationExportService) publish(ctx context.Context, export *models.OrganizationExport, eventType kafka.EventType) {
+	if err := s.events.PublishUserEvent(
+		ctx,
+		eventType,
+		kafka.OrganizationExportV1{
+			ExportID:    export.ID,
+			OrgID:       export.OrganizationID,
+			Format:      string(export.Format),
+			Status:      string(export.Status),
+			Error:       export.Error,
+			Size:        export.Size,
+			RequestedBy: export.RequestedBy,
+			RequestedAt: export.CreatedAt,
+			CompletedAt: export.CompletedAt,
+			ExpiresAt:   export.ExpiresAt,
+		},
+		export.OrganizationID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", export.OrganizationID).Str("id", export.ID).
+			Msgf("Failed to publish %s event", eventType)
+	}
+}
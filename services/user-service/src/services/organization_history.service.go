@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Organization history page sizes
+const (
+	DefaultHistoryLimit = 20
+	MaxHistoryLimit     = 100
+)
+
+// historyLockStripes is how many locks serialize recording the histories of
+// organizations, so an organization's transitions are recorded one at a time
+const historyLockStripes = 64
+
+// ErrOrganizationHistoryNotFound is returned for a time before the first
+// recorded transition of an organization
+var ErrOrganizationHistoryNotFound = apperrors.NotFound("HISTORY_NOT_FOUND", "no organization history was recorded by then")
+
+// OrganizationHistoryService records the transitions of organization states
+// from published events and rebuilds past states
+type OrganizationHistoryService struct {
+	historyRepo *repositories.OrganizationHistoryRepository
+	orgRepo     repositories.OrgStore
+	locks       [historyLockStripes]sync.Mutex
+}
+
+// NewOrganizationHistoryService creates a new organization history service
+func NewOrganizationHistoryService(
+	historyRepo *repositories.OrganizationHistoryRepository,
+	orgRepo repositories.OrgStore,
+) *OrganizationHistoryService {
+	return &OrganizationHistoryService{
+		historyRepo: historyRepo,
+		orgRepo:     orgRepo,
+	}
+}
+
+// HandleEvent records the transition of an organization's state after a
+// published event about it. The state is read from storage and compared
+// with the latest recorded one; events that didn't change it aren't
+// recorded.
+func (s *OrganizationHistoryService) HandleEvent(ctx context.Context, event kafka.Event) {
+	if !isOrganizationHistoryEvent(event.Type) {
+		return
+	}
+	orgID := eventOrganizationID(event)
+	if orgID == "" {
+		return
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(orgID))
+	lock := &s.locks[hash.Sum32()%historyLockStripes]
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Another instance may record a transition with the same version; retry
+	// from its state
+	for attempt := 0; attempt < 3; attempt++ {
+		if err := s.record(ctx, orgID, event); !mongo.IsDuplicateKeyError(err) {
+			return
+		}
+	}
+
+	logger.Ctx(ctx).Warn().Str("orgId", orgID).Str("eventId", event.ID).
+		Msg("Failed to record organization history after concurrent changes")
+}
+
+// record appends the transition from the latest recorded state of an
+// organization to its current state
+func (s *OrganizationHistoryService) record(ctx context.Context, orgID string, event kafka.Event) error {
+	entries, err := s.historyRepo.GetStateEntries(ctx, orgID, time.Time{})
+	if err != nil {
+		return err
+	}
+	var before models.OrganizationState
+	for _, entry := range entries {
+		before = before.Apply(entry)
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("eventId", event.ID).
+				Msg("Failed to get organization for history")
+		}
+		return err
+	}
+	after := models.NewOrganizationState(org)
+
+	changes := before.Diff(after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	// Keep entries in version order by time, so states by time are rebuilt
+	// from the same entries as by version
+	version, occurredAt := 1, event.Time
+	if len(entries) > 0 {
+		latest := entries[len(entries)-1]
+		version = latest.Version + 1
+		if occurredAt.Before(latest.OccurredAt) {
+			occurredAt = latest.OccurredAt
+		}
+	}
+
+	entry := models.NewOrganizationHistoryEntry(orgID, version, event.ID, string(event.Type), eventActorID(eventPayload(event)), changes, occurredAt)
+	if version%models.OrganizationHistorySnapshotInterval == 1 {
+		entry.Snapshot = after.Snapshot()
+	}
+	return s.historyRepo.Create(ctx, entry)
+}
+
+// GetHistory gets a page of an organization's history before a version, or
+// from the latest one when before is 0, newest first
+func (s *OrganizationHistoryService) GetHistory(ctx context.Context, orgID string, before, limit int, userID string) (*models.OrganizationHistoryResponse, error) {
+	if err := s.checkAccess(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra entry to know if there is a next page
+	entries, err := s.historyRepo.List(ctx, orgID, before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.OrganizationHistoryResponse{
+		Entries: entries,
+		Limit:   limit,
+	}
+	if len(entries) > limit {
+		response.Entries = entries[:limit]
+		response.NextBefore = response.Entries[limit-1].Version
+	}
+	if response.Entries == nil {
+		response.Entries = []*models.OrganizationHistoryEntry{}
+	}
+
+	return response, nil
+}
+
+// GetState rebuilds the state of an organization at a time from its history
+func (s *OrganizationHistoryService) GetState(ctx context.Context, orgID string, at time.Time, userID string) (*models.OrganizationStateResponse, error) {
+	if err := s.checkAccess(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.historyRepo.GetStateEntries(ctx, orgID, at)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrOrganizationHistoryNotFound
+	}
+
+	var state models.OrganizationState
+	for _, entry := range entries {
+		state = state.Apply(entry)
+	}
+	latest := entries[len(entries)-1]
+
+	return &models.OrganizationStateResponse{
+		OrganizationID: orgID,
+		At:             at,
+		Version:        latest.Version,
+		RecordedAt:     latest.OccurredAt,
+		State:          state.Nest(),
+	}, nil
+}
+
+// checkAccess checks the user may view the history of an organization
+func (s *OrganizationHistoryService) checkAccess(ctx context.Context, orgID string, userID string) error {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for history")
+		return err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgUpdate) {
+		return insufficientPermissions("view organization history")
+	}
+	return nil
+}
+
+// isOrganizationHistoryEvent checks if an event may change the state of an
+// organization. Deleted organizations have no state to record.
+func isOrganizationHistoryEvent(eventType kafka.EventType) bool {
+	name := string(eventType)
+	switch {
+	case eventType == kafka.OrganizationDeleted, kafka.IsReplay(eventType), kafka.IsDocumentChange(eventType):
+		return false
+	default:
+		return strings.HasPrefix(name, "organization.") || strings.HasPrefix(name, "seat.")
+	}
+}
@@ -0,0 +1,768 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Organization import errors
+var (
+	// ErrImportArchiveInvalid is returned when an uploaded file isn't an organization export archive
+	ErrImportArchiveInvalid = apperrors.Validation("IMPORT_ARCHIVE_INVALID", "file is not a valid organization export archive")
+	// ErrImportArchiveTooLarge is returned when an uploaded archive is larger than an export may be
+	ErrImportArchiveTooLarge = apperrors.Validation("IMPORT_ARCHIVE_TOO_LARGE", "archive exceeds the maximum export size")
+	// ErrImportConflict is returned when applying an import whose organization can't be created
+	ErrImportConflict = apperrors.Conflict("IMPORT_CONFLICT", "organization can't be imported; preview the import for its conflicts")
+)
+
+// OrganizationImportService restores organizations from the archives of
+// organization exports. Imports are previewed first, reporting what conflicts
+// with existing data, and applied by re-creating the organization, its teams
+// and its members, who are matched to users by email or invited.
+type OrganizationImportService struct {
+	userRepo    repositories.UserStore
+	orgRepo     repositories.OrgStore
+	orgService  *OrganizationService
+	teamService *TeamService
+	config      *config.ExportConfig
+}
+
+// NewOrganizationImportService creates a new organization import service
+func NewOrganizationImportService(
+	userRepo repositories.UserStore,
+	orgRepo repositories.OrgStore,
+	orgService *OrganizationService,
+	teamService *TeamService,
+	cfg *config.ExportConfig,
+) *OrganizationImportService {
+	return &OrganizationImportService{
+		userRepo:    userRepo,
+		orgRepo:     orgRepo,
+		orgService:  orgService,
+		teamService: teamService,
+		config:      cfg,
+	}
+}
+
+// organizationImportPlan is what an import does with the items of an archive
+type organizationImportPlan struct {
+	organization models.OrganizationImportEntry
+	members      []models.OrganizationImportEntry
+	// memberUsers maps the exported user IDs of imported members to their
+	// entry, the importer's included
+	memberUsers map[string]*models.OrganizationImportEntry
+	teams       []organizationImportTeam
+}
+
+// organizationImportTeam is what an import does with a team and its members
+type organizationImportTeam struct {
+	team    *models.Team
+	entry   models.OrganizationImportEntry
+	members []organizationImportTeamMember
+}
+
+// organizationImportTeamMember is what an import does with a team member,
+// whose user is the one of an organization member entry
+type organizationImportTeamMember struct {
+	member *models.OrganizationImportEntry
+	entry  models.OrganizationImportEntry
+}
+
+// Import previews, or applies if requested, an import of an organization
+// export archive. The organization is named name, or as exported if empty,
+// and the importer becomes its owner.
+func (s *OrganizationImportService) Import(ctx context.Context, body io.Reader, name string, apply bool, userID string) (*models.OrganizationImportResponse, error) {
+	archive, err := s.readArchive(body)
+	if err != nil {
+		return nil, err
+	}
+
+	importer, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user for organization import")
+		return nil, err
+	}
+
+	if name == "" {
+		name = archive.Organization.Name
+	}
+	plan, err := s.plan(ctx, archive, name, importer)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.OrganizationImportResponse{
+		Name:    name,
+		Format:  archive.Format,
+		Applied: apply,
+		Entries: []models.OrganizationImportEntry{},
+	}
+	if apply {
+		if plan.organization.Action != models.OrganizationImportCreate {
+			return nil, ErrImportConflict.WithDetails(map[string]string{"organization": plan.organization.Reason})
+		}
+		if err := s.apply(ctx, archive, plan, userID); err != nil {
+			return nil, err
+		}
+		resp.OrganizationID = plan.organization.ID
+	}
+
+	resp.Add(plan.organization)
+	for _, entry := range plan.members {
+		resp.Add(entry)
+	}
+	for _, team := range plan.teams {
+		resp.Add(team.entry)
+		for _, member := range team.members {
+			resp.Add(member.entry)
+		}
+	}
+
+	if apply {
+		logger.Ctx(ctx).Info().Str("orgId", resp.OrganizationID).Str("userId", userID).
+			Int("created", resp.Summary.Create).Int("added", resp.Summary.Add).Int("invited", resp.Summary.Invite).
+			Int("failed", resp.Summary.Failed).Msg("Organization import applied")
+	}
+
+	return resp, nil
+}
+
+// readArchive reads and validates an uploaded export archive
+func (s *OrganizationImportService) readArchive(body io.Reader) (*models.OrganizationArchive, error) {
+	data, err := io.ReadAll(io.LimitReader(body, int64(s.config.MaxSize)+1))
+	if err != nil {
+		return nil, apperrors.InvalidBody(err)
+	}
+	if len(data) > s.config.MaxSize {
+		return nil, ErrImportArchiveTooLarge.WithDetails(map[string]int{"maxSize": s.config.MaxSize})
+	}
+
+	archive, err := parseOrganizationArchive(data)
+	if err != nil {
+		return nil, ErrImportArchiveInvalid.WithDetails(map[string]string{"error": err.Error()})
+	}
+	return archive, nil
+}
+
+// parseOrganizationArchive parses the files of an export archive, in
+// whichever format it was exported
+func parseOrganizationArchive(data []byte) (*models.OrganizationArchive, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		files[path.Clean(f.Name)] = f
+	}
+
+	archive := &models.OrganizationArchive{}
+	if err := readArchiveJSON(files, models.ExportOrganizationFile, &archive.Organization); err != nil {
+		return nil, err
+	}
+	if archive.Organization.Name == "" {
+		return nil, fmt.Errorf("%s: missing organization name", models.ExportOrganizationFile)
+	}
+
+	export := &models.OrganizationExport{Format: models.ExportJSON}
+	if _, ok := files[export.FileName(models.ExportMembersFile)]; !ok {
+		export.Format = models.ExportCSV
+	}
+	archive.Format = export.Format
+
+	switch export.Format {
+	case models.ExportCSV:
+		records, err := readArchiveCSV(files, export.FileName(models.ExportTeamsFile))
+		if err != nil {
+			return nil, err
+		}
+		if archive.Teams, err = models.ParseTeamsCSV(records); err != nil {
+			return nil, fmt.Errorf("%s: %w", export.FileName(models.ExportTeamsFile), err)
+		}
+		if records, err = readArchiveCSV(files, export.FileName(models.ExportTeamMembersFile)); err != nil {
+			return nil, err
+		}
+		if err := models.ParseTeamMembersCSV(records, archive.Teams); err != nil {
+			return nil, fmt.Errorf("%s: %w", export.FileName(models.ExportTeamMembersFile), err)
+		}
+		if records, err = readArchiveCSV(files, export.FileName(models.ExportMembersFile)); err != nil {
+			return nil, err
+		}
+		if archive.Members, err = models.ParseMembersCSV(records); err != nil {
+			return nil, fmt.Errorf("%s: %w", export.FileName(models.ExportMembersFile), err)
+		}
+	default:
+		if err := readArchiveJSON(files, export.FileName(models.ExportTeamsFile), &archive.Teams); err != nil {
+			return nil, err
+		}
+		if err := readArchiveJSON(files, export.FileName(models.ExportMembersFile), &archive.Members); err != nil {
+			return nil, err
+		}
+	}
+
+	return archive, nil
+}
+
+// openArchiveFile opens a file of an export archive
+func openArchiveFile(files map[string]*zip.File, name string) (io.ReadCloser, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("missing %s", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return r, nil
+}
+
+// readArchiveJSON decodes a JSON file of an export archive into value
+func readArchiveJSON(files map[string]*zip.File, name string, value interface{}) error {
+	r, err := openArchiveFile(files, name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := json.NewDecoder(r).Decode(value); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// readArchiveCSV reads the records of a CSV file of an export archive
+func readArchiveCSV(files map[string]*zip.File, name string) ([][]string, error) {
+	r, err := openArchiveFile(files, name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return records, nil
+}
+
+// plan decides what an import does with the organization, members and teams
+// of an archive
+func (s *OrganizationImportService) plan(ctx context.Context, archive *models.OrganizationArchive, name string, importer *models.User) (*organizationImportPlan, error) {
+	plan := &organizationImportPlan{
+		organization: s.planOrganization(ctx, archive, name),
+		memberUsers:  make(map[string]*models.OrganizationImportEntry, len(archive.Members)),
+	}
+
+	// Members are checked against the organization's restored settings
+	org := &models.Organization{Settings: archive.Organization.Settings}
+
+	var emails []string
+	seen := make(map[string]bool, len(archive.Members))
+	for i := range archive.Members {
+		email := strings.ToLower(strings.TrimSpace(archive.Members[i].Email))
+		archive.Members[i].Email = email
+		if email != "" && !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	users, err := usersByEmail(ctx, s.userRepo, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	planned := make(map[string]bool, len(archive.Members))
+	plan.members = make([]models.OrganizationImportEntry, 0, len(archive.Members))
+	for _, member := range archive.Members {
+		entry := planImportMember(org, member, users[member.Email], importer, now)
+		if member.Email != "" && planned[member.Email] {
+			entry.Action = models.OrganizationImportSkip
+			entry.Reason = "member is listed more than once"
+		}
+		planned[member.Email] = true
+		plan.members = append(plan.members, entry)
+	}
+	for i := range plan.members {
+		entry := &plan.members[i]
+		if entry.Action != models.OrganizationImportSkip || entry.ID == importer.UserID {
+			plan.memberUsers[entry.SourceID] = entry
+		}
+	}
+
+	plan.teams = planImportTeams(archive.Teams, plan.memberUsers, importer)
+	return plan, nil
+}
+
+// planOrganization decides whether the archive's organization can be
+// created with the name
+func (s *OrganizationImportService) planOrganization(ctx context.Context, archive *models.OrganizationArchive, name string) models.OrganizationImportEntry {
+	entry := models.OrganizationImportEntry{
+		Item:     models.OrganizationImportOrganization,
+		Action:   models.OrganizationImportSkip,
+		SourceID: archive.Organization.ID,
+		Name:     name,
+		Role:     string(models.OrgRoleOwner),
+	}
+
+	switch residency := archive.Organization.Residency; {
+	case len(name) < 3 || len(name) > 100:
+		entry.Reason = "name must be 3 to 100 characters long"
+	case residency != "" && !containsString(s.orgService.config.Residencies, residency):
+		entry.Reason = "data residency region " + residency + " isn't configured"
+	default:
+		_, err := s.orgRepo.GetByName(ctx, name)
+		switch {
+		case err == nil:
+			entry.Reason = "an organization with this name already exists"
+		case errors.Is(err, mongo.ErrNoDocuments):
+			entry.Action = models.OrganizationImportCreate
+		default:
+			logger.Ctx(ctx).Error().Err(err).Str("name", name).Msg("Failed to check organization name for import")
+			entry.Reason = "organization name couldn't be checked"
+		}
+	}
+	return entry
+}
+
+// planImportMember decides what an import does with an exported member,
+// given the user with their email, if any
+func planImportMember(org *models.Organization, member models.OrganizationMemberDetail, user *models.User, importer *models.User, now time.Time) models.OrganizationImportEntry {
+	entry := models.OrganizationImportEntry{
+		Item:     models.OrganizationImportMember,
+		Action:   models.OrganizationImportSkip,
+		SourceID: member.UserID,
+		Name:     strings.TrimSpace(member.FirstName + " " + member.LastName),
+		Email:    member.Email,
+		Role:     string(member.Role),
+	}
+
+	switch {
+	case member.Email == "":
+		entry.Reason = "member has no email"
+		return entry
+	case strings.EqualFold(member.Email, importer.Email):
+		entry.ID = importer.UserID
+		entry.Role = string(models.OrgRoleOwner)
+		entry.Reason = "importer owns the imported organization"
+		return entry
+	case member.Role != models.OrgRoleOwner && member.Role != models.OrgRoleAdmin &&
+		member.Role != models.OrgRoleMember && member.Role != models.OrgRoleGuest:
+		entry.Reason = "member has an unknown role"
+		return entry
+	case member.ExpiresAt != nil && !member.ExpiresAt.After(now):
+		entry.Reason = "membership has expired"
+		return entry
+	case member.Role == models.OrgRoleGuest && member.ExpiresAt == nil:
+		entry.Reason = "guest membership has no expiry"
+		return entry
+	}
+
+	// Archives are the importer's word only, so nobody becomes an owner
+	// through one
+	if member.Role == models.OrgRoleOwner {
+		entry.Role = string(models.OrgRoleAdmin)
+		entry.Reason = "owners are imported as admins"
+	}
+
+	if user != nil {
+		entry.ID = user.UserID
+		switch {
+		case user.IsDeleted():
+			entry.Reason = "user is deleted"
+		case user.IsPendingReview():
+			entry.Reason = ErrUserPendingReview.Message
+		default:
+			if reason := joinViolation(org, user); reason != "" {
+				entry.Reason = reason
+				return entry
+			}
+			// Users with an account decide themselves whether they join
+			entry.Action = models.OrganizationImportRequest
+		}
+		return entry
+	}
+
+	if member.FirstName == "" || member.LastName == "" {
+		entry.Reason = "member has no first or last name"
+		return entry
+	}
+	// New users have no two-factor authentication
+	if reason := joinViolation(org, &models.User{Email: member.Email}); reason != "" {
+		entry.Reason = reason
+		return entry
+	}
+	entry.Action = models.OrganizationImportInvite
+	return entry
+}
+
+// planImportTeams decides what an import does with exported teams and their
+// members, parent teams first. Archived teams and teams whose name is
+// already taken are left out.
+func planImportTeams(teams []*models.Team, memberUsers map[string]*models.OrganizationImportEntry, importer *models.User) []organizationImportTeam {
+	planned := make([]organizationImportTeam, 0, len(teams))
+	created := make(map[string]bool, len(teams))
+	names := make(map[string]bool, len(teams))
+	for _, team := range orderImportTeams(teams) {
+		entry := models.OrganizationImportEntry{
+			Item:     models.OrganizationImportTeam,
+			Action:   models.OrganizationImportSkip,
+			SourceID: team.ID,
+			Name:     team.Name,
+			Role:     string(models.TeamRoleOwner),
+		}
+
+		switch {
+		case len(team.Name) < 3 || len(team.Name) > 50:
+			entry.Reason = "name must be 3 to 50 characters long"
+		case names[team.Name]:
+			entry.Reason = "another team has the same name"
+		case team.ArchivedAt != nil:
+			entry.Reason = "team is archived"
+		default:
+			entry.Action = models.OrganizationImportCreate
+			names[team.Name] = true
+			created[team.ID] = true
+			if team.ParentTeamID != "" && !created[team.ParentTeamID] {
+				entry.Reason = "parent team isn't imported; created as a top-level team"
+				team.ParentTeamID = ""
+			}
+		}
+
+		planned = append(planned, organizationImportTeam{
+			team:    team,
+			entry:   entry,
+			members: planImportTeamMembers(team, entry, memberUsers, importer),
+		})
+	}
+	return planned
+}
+
+// planImportTeamMembers decides what an import does with the members of a
+// team
+func planImportTeamMembers(team *models.Team, teamEntry models.OrganizationImportEntry, memberUsers map[string]*models.OrganizationImportEntry, importer *models.User) []organizationImportTeamMember {
+	planned := make([]organizationImportTeamMember, 0, len(team.Members))
+	for _, member := range team.Members {
+		entry := models.OrganizationImportEntry{
+			Item:     models.OrganizationImportTeamMember,
+			Action:   models.OrganizationImportSkip,
+			SourceID: member.UserID,
+			Team:     team.Name,
+			Role:     string(member.Role),
+		}
+		orgMember := memberUsers[member.UserID]
+		if orgMember != nil {
+			entry.ID = orgMember.ID
+			entry.Name = orgMember.Name
+			entry.Email = orgMember.Email
+		}
+
+		switch {
+		case teamEntry.Action != models.OrganizationImportCreate:
+			entry.Reason = "team isn't imported"
+		case orgMember == nil:
+			entry.Reason = "user isn't imported to the organization"
+		case orgMember.Action == models.OrganizationImportRequest:
+			entry.Reason = "user is invited to join the organization"
+		case orgMember.ID == importer.UserID:
+			entry.Role = string(models.TeamRoleOwner)
+			entry.Reason = "importer owns the imported team"
+		case member.Role != models.TeamRoleOwner && member.Role != models.TeamRoleAdmin &&
+			member.Role != models.TeamRoleMember && member.Role != models.TeamRoleViewer:
+			entry.Reason = "member has an unknown role"
+		default:
+			entry.Action = models.OrganizationImportAdd
+		}
+		planned = append(planned, organizationImportTeamMember{member: orgMember, entry: entry})
+	}
+	return planned
+}
+
+// orderImportTeams orders teams so parent teams come before their sub-teams.
+// Teams whose parent isn't exported, or that are in a cycle, come last.
+func orderImportTeams(teams []*models.Team) []*models.Team {
+	children := make(map[string][]*models.Team, len(teams))
+	exported := make(map[string]bool, len(teams))
+	for _, team := range teams {
+		exported[team.ID] = true
+	}
+	var ordered []*models.Team
+	for _, team := range teams {
+		if team.ParentTeamID == "" || !exported[team.ParentTeamID] {
+			ordered = append(ordered, team)
+		} else {
+			children[team.ParentTeamID] = append(children[team.ParentTeamID], team)
+		}
+	}
+	for i := 0; i < len(ordered); i++ {
+		ordered = append(ordered, children[ordered[i].ID]...)
+		delete(children, ordered[i].ID)
+	}
+
+	// Whatever is left is in a cycle
+	if len(ordered) < len(teams) {
+		placed := make(map[*models.Team]bool, len(ordered))
+		for _, team := range ordered {
+			placed[team] = true
+		}
+		for _, team := range teams {
+			if !placed[team] {
+				ordered = append(ordered, team)
+			}
+		}
+	}
+	return ordered
+}
+
+// apply creates the organization of a planned import, then its members and
+// teams, recording failures on their entries. Settings are restored last, so
+// an approval webhook doesn't hold up the restored members. An import whose
+// organization can't be fully restored is undone, so it doesn't leave a
+// half-imported organization behind.
+func (s *OrganizationImportService) apply(ctx context.Context, archive *models.OrganizationArchive, plan *organizationImportPlan, userID string) (err error) {
+	exported := archive.Organization
+	org, err := s.orgService.CreateOrganization(ctx, models.CreateOrganizationRequest{
+		Name:        plan.organization.Name,
+		Description: exported.Description,
+		LogoURL:     exported.LogoURL,
+		Website:     exported.Website,
+		Industry:    exported.Industry,
+		Size:        exported.Size,
+		Location:    exported.Location,
+		Residency:   exported.Residency,
+	}, userID)
+	if err != nil {
+		return err
+	}
+	plan.organization.ID = org.ID
+
+	var invited []*models.User
+	defer func() {
+		if err != nil {
+			s.rollback(ctx, org, invited, userID)
+		}
+	}()
+
+	if len(exported.Tags) > 0 {
+		if _, err := s.orgService.AddOrganizationTags(ctx, org.ID, models.AddTagsRequest{Tags: exported.Tags}, userID); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to restore tags of imported organization")
+		}
+	}
+
+	for i := range plan.members {
+		if user := s.applyMember(ctx, org, &plan.members[i], archive.Members[i], userID); user != nil {
+			invited = append(invited, user)
+		}
+	}
+
+	// Sub-teams are created under their parent's new ID
+	teamIDs := make(map[string]string, len(plan.teams))
+	for i := range plan.teams {
+		s.applyTeam(ctx, org, archive.Format, &plan.teams[i], teamIDs, userID)
+	}
+
+	// Approval webhooks are set up again, with a new secret
+	before := org.Settings
+	org.Settings = exported.Settings
+	org.Settings.ApprovalWebhook = nil
+	if org.Settings.DefaultUserRole == "" {
+		org.Settings.DefaultUserRole = before.DefaultUserRole
+	}
+	if err := s.orgRepo.Update(ctx, org); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to restore settings of imported organization")
+		return err
+	}
+	s.orgService.recordSettingsVersion(ctx, org, before, userID, 0)
+
+	// Invitations go out once the organization is restored, so a rolled back
+	// import doesn't invite anyone
+	for i := range plan.members {
+		s.applyInvitation(ctx, org, &plan.members[i], archive.Members[i], userID)
+	}
+
+	return nil
+}
+
+// rollback undoes an import that failed after its organization was created:
+// the organization is deleted with its teams, and the users it invited are
+// purged, as they never had an account before. The rollback runs even if the
+// request was cancelled.
+func (s *OrganizationImportService) rollback(ctx context.Context, org *models.Organization, invited []*models.User, userID string) {
+	ctx = context.WithoutCancel(ctx)
+
+	// The stored organization has the teams created since
+	if stored, err := s.orgRepo.GetByID(ctx, org.ID); err == nil {
+		org = stored
+	}
+	if err := s.orgService.DeleteOrganization(ctx, org.ID, userID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Msg("Failed to roll back imported organization")
+	}
+
+	// Deleting the organization leaves its teams on their members
+	memberIDs := org.MemberIDs()
+	for _, teamID := range org.TeamIDs {
+		if err := s.userRepo.UpdateTeamMemberships(ctx, teamID, nil, memberIDs); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("teamId", teamID).
+				Msg("Failed to remove rolled back team from its members")
+		}
+	}
+
+	for _, user := range invited {
+		err := s.userRepo.Delete(ctx, user.ID)
+		if err == nil {
+			err = s.userRepo.Purge(ctx, user.ID)
+		}
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", user.UserID).
+				Msg("Failed to roll back invited user of organization import")
+		}
+	}
+
+	logger.Ctx(ctx).Warn().Str("orgId", org.ID).Int("invited", len(invited)).Msg("Organization import rolled back")
+}
+
+// applyMember creates the pending user of a planned entry for an exported
+// member without an account and adds them to the organization. Existing users
+// are invited by applyInvitation instead. It returns the invited user it
+// created, if any.
+func (s *OrganizationImportService) applyMember(ctx context.Context, org *models.Organization, entry *models.OrganizationImportEntry, member models.OrganizationMemberDetail, userID string) *models.User {
+	if entry.Action != models.OrganizationImportInvite {
+		return nil
+	}
+
+	// Like directory imports, invited users get a generated user ID and stay
+	// pending until they sign in for the first time
+	invited := models.NewUser(models.CreateUserRequest{
+		UserID:    uuid.New().String(),
+		Email:     entry.Email,
+		FirstName: member.FirstName,
+		LastName:  member.LastName,
+		Role:      models.RoleUser,
+	})
+	invited.Status = models.StatusPending
+	invited.Preferences = invited.Preferences.Resolve(org.Settings.DefaultPreferences)
+	if err := s.userRepo.Create(ctx, invited); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("email", entry.Email).
+			Msg("Failed to create invited user for organization import")
+		failImportEntry(entry, err)
+		return nil
+	}
+	entry.ID = invited.UserID
+
+	err := s.orgService.AddOrganizationMember(ctx, org.ID, models.AddOrganizationMemberRequest{
+		UserID:    entry.ID,
+		Role:      models.OrganizationMemberRole(entry.Role),
+		ExpiresAt: importExpiry(member),
+	}, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", entry.ID).
+			Msg("Failed to add member for organization import")
+		failImportEntry(entry, err)
+	}
+	return invited
+}
+
+// applyInvitation invites the existing user of a planned entry for an
+// exported member to join the organization
+func (s *OrganizationImportService) applyInvitation(ctx context.Context, org *models.Organization, entry *models.OrganizationImportEntry, member models.OrganizationMemberDetail, userID string) {
+	if entry.Action != models.OrganizationImportRequest {
+		return
+	}
+
+	user, err := s.userRepo.GetByUserId(ctx, entry.ID)
+	if err == nil {
+		_, err = s.orgService.inviteMember(ctx, org, user, models.OrganizationMemberRole(entry.Role), importExpiry(member), userID)
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", entry.ID).
+			Msg("Failed to invite member for organization import")
+		failImportEntry(entry, err)
+	}
+}
+
+// importExpiry is when the imported membership of an exported member
+// expires. Owners, who are imported as admins, never had an expiry.
+func importExpiry(member models.OrganizationMemberDetail) *time.Time {
+	if member.Role == models.OrgRoleOwner {
+		return nil
+	}
+	return member.ExpiresAt
+}
+
+// applyTeam creates a planned team with its members, which are seeded like
+// the members of a team created from a template
+func (s *OrganizationImportService) applyTeam(ctx context.Context, org *models.Organization, format models.ExportFormat, planned *organizationImportTeam, teamIDs map[string]string, userID string) {
+	if planned.entry.Action != models.OrganizationImportCreate {
+		return
+	}
+
+	// CSV archives don't have the teams' settings
+	template := &models.TeamTemplate{Settings: planned.team.Settings}
+	if format == models.ExportCSV {
+		template.Settings = models.DefaultTeamSettings()
+	}
+	for i := range planned.members {
+		member := &planned.members[i]
+		if member.entry.Action != models.OrganizationImportAdd {
+			continue
+		}
+		if member.member.Error != "" {
+			member.entry.Error = member.member.Error
+			member.entry.Reason = "user couldn't be added to the organization"
+			continue
+		}
+		template.SeedRoles = append(template.SeedRoles, models.TeamTemplateSeedRole{
+			UserID: member.member.ID,
+			Role:   models.TeamMemberRole(member.entry.Role),
+		})
+		member.entry.ID = member.member.ID
+	}
+
+	team, err := s.teamService.CreateTeamFromTemplate(ctx, template, models.CreateTeamRequest{
+		Name:           planned.team.Name,
+		Description:    planned.team.Description,
+		LogoURL:        planned.team.LogoURL,
+		OrganizationID: org.ID,
+		ParentTeamID:   teamIDs[planned.team.ParentTeamID],
+	}, userID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("team", planned.team.Name).
+			Msg("Failed to create team for organization import")
+		failImportEntry(&planned.entry, err)
+		for i := range planned.members {
+			if planned.members[i].entry.Action == models.OrganizationImportAdd && planned.members[i].entry.Error == "" {
+				failImportEntry(&planned.members[i].entry, err)
+			}
+		}
+		return
+	}
+	planned.entry.ID = team.ID
+	teamIDs[planned.team.ID] = team.ID
+}
+
+// failImportEntry records the error that failed an applied change on its entry
+func failImportEntry(entry *models.OrganizationImportEntry, err error) {
+	appErr := apperrors.From(err, "Failed to import "+string(entry.Item))
+	entry.Error = appErr.Code
+	entry.Reason = appErr.Message
+}
@@ -0,0 +1,199 @@
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"github.com/your-username/slido-clone/user-service/services"
+	"github.com/your-username/slido-clone/user-service/testsupport"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// errSettingsUnavailable fails restoring an imported organization's settings
+var errSettingsUnavailable = errors.New("settings unavailable")
+
+// failingSettingsStore is an organization store whose updates fail, like
+// restoring the settings of an imported organization against a failing
+// database
+type failingSettingsStore struct {
+	*repositories.OrganizationRepository
+}
+
+func (s failingSettingsStore) Update(ctx context.Context, org *models.Organization) error {
+	return errSettingsUnavailable
+}
+
+// newImportOrganizationService creates the organization service of imports
+// over the fixtures
+func newImportOrganizationService(f *testsupport.Fixtures) *services.OrganizationService {
+	syncService := services.NewSyncService(f.Users, f.Teams, f.Orgs, repositories.NewTombstoneRepository(f.Store), &config.SyncConfig{})
+	return services.NewOrganizationService(f.Orgs, f.Users, f.Teams,
+		repositories.NewJoinRequestRepository(f.Store), repositories.NewSettingsHistoryRepository(f.Store),
+		f.Kafka, syncService, nil, &config.OrganizationConfig{})
+}
+
+// newOrganizationImportService creates an organization import service over
+// the fixtures, importing through orgRepo
+func newOrganizationImportService(f *testsupport.Fixtures, orgRepo repositories.OrgStore) *services.OrganizationImportService {
+	syncService := services.NewSyncService(f.Users, f.Teams, f.Orgs, repositories.NewTombstoneRepository(f.Store), &config.SyncConfig{})
+	orgService := newImportOrganizationService(f)
+	teamService := services.NewTeamService(f.Teams, f.Users, f.Orgs,
+		repositories.NewGroupRepository(f.Store), repositories.NewTeamJoinRequestRepository(f.Store),
+		f.Kafka, syncService)
+	return services.NewOrganizationImportService(f.Users, orgRepo, orgService, teamService, &config.ExportConfig{MaxSize: 1 << 20})
+}
+
+// newImportArchive writes a JSON export archive of an organization
+func newImportArchive(t *testing.T, org models.OrganizationResponse, members []models.OrganizationMemberDetail, teams []*models.Team) *bytes.Buffer {
+	t.Helper()
+
+	export := &models.OrganizationExport{Format: models.ExportJSON}
+	files := map[string]interface{}{
+		models.ExportOrganizationFile:             org,
+		export.FileName(models.ExportMembersFile): members,
+		export.FileName(models.ExportTeamsFile):   teams,
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, value := range files {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("creating %s: %v", name, err)
+		}
+		if err := json.NewEncoder(w).Encode(value); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("closing archive: %v", err)
+	}
+	return &buf
+}
+
+// importFixture is an archive with the importer, an owner with an account,
+// a member to invite and a team of both members
+func importFixture(t *testing.T, f *testsupport.Fixtures) (importer, existing *models.User, archive *bytes.Buffer) {
+	t.Helper()
+
+	importer = f.SeedUser()
+	existing = f.SeedUser()
+
+	org := models.OrganizationResponse{ID: "exported-org", Name: "Imported Org"}
+	org.Settings.Features.EnableTeams = true
+	org.Settings.Branding.PrimaryColor = "#112233"
+
+	members := []models.OrganizationMemberDetail{
+		{UserID: "exported-importer", Email: importer.Email, FirstName: "Import", LastName: "Er", Role: models.OrgRoleOwner},
+		{UserID: "exported-existing", Email: existing.Email, FirstName: "Exist", LastName: "Ing", Role: models.OrgRoleOwner},
+		{UserID: "exported-invited", Email: "invited@example.com", FirstName: "Invi", LastName: "Ted", Role: models.OrgRoleMember},
+	}
+	teams := []*models.Team{{
+		ID:   "exported-team",
+		Name: "Imported Team",
+		Members: []models.TeamMember{
+			{UserID: "exported-existing", Role: models.TeamRoleAdmin},
+			{UserID: "exported-invited", Role: models.TeamRoleMember},
+		},
+		Settings: models.DefaultTeamSettings(),
+	}}
+
+	return importer, existing, newImportArchive(t, org, members, teams)
+}
+
+func TestOrganizationImportApply(t *testing.T) {
+	f := testsupport.New(t)
+	ctx := tenancy.Unscoped(context.Background())
+	importer, existing, archive := importFixture(t, f)
+
+	resp, err := newOrganizationImportService(f, f.Orgs).Import(ctx, archive, "", true, importer.UserID)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if resp.Summary.Failed != 0 {
+		t.Fatalf("import failed %d entries: %+v", resp.Summary.Failed, resp.Entries)
+	}
+
+	org, err := f.Orgs.GetByID(ctx, resp.OrganizationID)
+	if err != nil {
+		t.Fatalf("GetByID(%s): %v", resp.OrganizationID, err)
+	}
+	if org.Settings.Branding.PrimaryColor != "#112233" {
+		t.Errorf("restored primary color = %q, want %q", org.Settings.Branding.PrimaryColor, "#112233")
+	}
+
+	invited, err := f.Users.GetByEmail(ctx, "invited@example.com")
+	if err != nil {
+		t.Fatalf("GetByEmail: %v", err)
+	}
+	if invited.Status != models.StatusPending {
+		t.Errorf("invited user status = %s, want %s", invited.Status, models.StatusPending)
+	}
+	for _, userID := range []string{importer.UserID, invited.UserID} {
+		if !org.IsMember(userID) {
+			t.Errorf("user %s isn't a member of the imported organization", userID)
+		}
+	}
+
+	team, err := f.Teams.GetByNameAndOrganization(ctx, "Imported Team", org.ID)
+	if err != nil {
+		t.Fatalf("GetByNameAndOrganization: %v", err)
+	}
+	if team.IsMember(existing.UserID) || !team.IsMember(invited.UserID) {
+		t.Errorf("team members = %v, want only %s", team.MemberIDs(), invited.UserID)
+	}
+
+	// Users with an account are invited, and exported owners join as admins
+	if org.IsMember(existing.UserID) {
+		t.Fatalf("existing user %s was added without accepting an invitation", existing.UserID)
+	}
+	invitation, err := newImportOrganizationService(f).AcceptInvitation(ctx, org.ID, existing.UserID)
+	if err != nil {
+		t.Fatalf("AcceptInvitation: %v", err)
+	}
+	if invitation.InvitedBy != importer.UserID || invitation.Role != models.OrgRoleAdmin {
+		t.Errorf("invitation by %q with role %s, want by %s with role %s",
+			invitation.InvitedBy, invitation.Role, importer.UserID, models.OrgRoleAdmin)
+	}
+	org, err = f.Orgs.GetByID(ctx, resp.OrganizationID)
+	if err != nil {
+		t.Fatalf("GetByID(%s): %v", resp.OrganizationID, err)
+	}
+	if member := org.GetMember(existing.UserID); member == nil || member.Role != models.OrgRoleAdmin {
+		t.Errorf("member after accepting = %+v, want role %s", member, models.OrgRoleAdmin)
+	}
+}
+
+func TestOrganizationImportApplyRollsBack(t *testing.T) {
+	f := testsupport.New(t)
+	ctx := tenancy.Unscoped(context.Background())
+	importer, _, archive := importFixture(t, f)
+
+	service := newOrganizationImportService(f, failingSettingsStore{f.Orgs})
+	if _, err := service.Import(ctx, archive, "", true, importer.UserID); !errors.Is(err, errSettingsUnavailable) {
+		t.Fatalf("Import error = %v, want %v", err, errSettingsUnavailable)
+	}
+
+	if _, err := f.Orgs.GetByName(ctx, "Imported Org"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("imported organization left behind: GetByName error = %v", err)
+	}
+	if _, err := f.Users.GetByEmail(ctx, "invited@example.com"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("invited user left behind: GetByEmail error = %v", err)
+	}
+
+	user, err := f.Users.GetByUserId(ctx, importer.UserID)
+	if err != nil {
+		t.Fatalf("GetByUserId: %v", err)
+	}
+	if len(user.OrganizationIDs) != 0 || len(user.TeamIDs) != 0 {
+		t.Errorf("importer still has organizations %v and teams %v", user.OrganizationIDs, user.TeamIDs)
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PermissionService resolves the effective permissions of a user
+type PermissionService struct {
+	orgRepo  repositories.OrgStore
+	teamRepo repositories.TeamStore
+}
+
+// NewPermissionService creates a new permission service
+func NewPermissionService(orgRepo repositories.OrgStore, teamRepo repositories.TeamStore) *PermissionService {
+	return &PermissionService{
+		orgRepo:  orgRepo,
+		teamRepo: teamRepo,
+	}
+}
+
+// GetPermissions gets the permissions a user has on the platform and, when
+// requested, in an organization and team. A team implies its organization,
+// and team permissions include those inherited from parent teams.
+func (s *PermissionService) GetPermissions(ctx context.Context, userID string, roles []string, orgID, teamID string) (*models.PermissionsResponse, error) {
+	response := &models.PermissionsResponse{
+		Platform: models.PlatformPermissions(roles),
+	}
+
+	if teamID != "" {
+		team, err := s.teamRepo.GetByID(ctx, teamID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, ErrTeamNotFound
+			}
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Msg("Failed to get team for permissions")
+			return nil, err
+		}
+		if orgID != "" && orgID != team.OrganizationID {
+			return nil, apperrors.InvalidField("teamId", "team does not belong to the organization")
+		}
+		orgID = team.OrganizationID
+
+		ancestors, err := s.teamRepo.GetAncestors(ctx, team)
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Msg("Failed to get team ancestors for permissions")
+			return nil, err
+		}
+
+		response.TeamID = team.ID
+		response.Team = team.InheritedPermissions(userID, ancestors)
+		if member := team.GetMember(userID); member != nil {
+			response.TeamRole = member.Role
+		}
+	}
+
+	if orgID != "" {
+		org, err := s.orgRepo.GetByID(ctx, orgID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, ErrOrganizationNotFound
+			}
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to get organization for permissions")
+			return nil, err
+		}
+
+		response.OrganizationID = org.ID
+		response.Organization = org.Permissions(userID)
+		if member := org.GetMember(userID); member != nil {
+			response.OrganizationRole = member.Role
+		}
+	}
+
+	return response, nil
+}
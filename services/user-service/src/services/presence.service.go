@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/presence"
+	"github.com/your-username/slido-clone/user-service/repositories"
+)
+
+// PresenceService is a service for whether users are online. Clients send a
+// heartbeat while the app is open; users are online until their last
+// heartbeat is older than the presence TTL.
+type PresenceService struct {
+	store    presence.Store
+	userRepo repositories.UserStore
+	config   *config.PresenceConfig
+}
+
+// NewPresenceService creates a new presence service
+func NewPresenceService(store presence.Store, userRepo repositories.UserStore, cfg *config.PresenceConfig) *PresenceService {
+	return &PresenceService{
+		store:    store,
+		userRepo: userRepo,
+		config:   cfg,
+	}
+}
+
+// Heartbeat marks a user online and records when they were last seen
+func (s *PresenceService) Heartbeat(ctx context.Context, userID string) (*models.HeartbeatResponse, error) {
+	if err := s.store.Touch(ctx, userID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to record presence heartbeat")
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateLastSeen(ctx, userID, time.Now(), s.config.LastSeenInterval); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to update last seen")
+		return nil, err
+	}
+
+	return &models.HeartbeatResponse{
+		Status:     models.PresenceOnline,
+		TTLSeconds: int(s.config.TTL / time.Second),
+	}, nil
+}
+
+// Online reports which of the users are online. Presence is best effort:
+// when the store can't be reached everyone is reported offline rather than
+// failing the request.
+func (s *PresenceService) Online(ctx context.Context, userIDs []string) map[string]bool {
+	online, err := s.store.Online(ctx, userIDs)
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Int("users", len(userIDs)).Msg("Failed to get presence, reporting users offline")
+		return map[string]bool{}
+	}
+	return online
+}
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Profile stream timing. Streams end after profileStreamMaxDuration so
+// clients reconnect with a fresh session, and send a ping every
+// profileStreamKeepAlive so proxies keep idle streams open.
+const (
+	profileStreamMaxDuration = 30 * time.Minute
+	profileStreamKeepAlive   = 25 * time.Second
+)
+
+// profileStreamBuffer is the most events waiting to be sent to a stream. A
+// stream that falls further behind is ended, and its client reconnects and
+// reloads the profile.
+const profileStreamBuffer = 32
+
+// Profile stream event names
+const (
+	ProfileEventUserUpdated        = "user.updated"
+	ProfileEventMembershipChanged  = "membership-changed"
+	ProfileEventOrgSettingsChanged = "org-settings-changed"
+	ProfileEventPing               = "ping"
+)
+
+// ProfileStreamService streams the changes of users' profiles, memberships
+// and organizations to their frontends. Every instance consumes every user
+// and team event and sends each stream the events about its user.
+type ProfileStreamService struct {
+	userRepo *repositories.UserRepository
+	orgRepo  *repositories.OrganizationRepository
+
+	mu      sync.Mutex
+	streams map[*profileStream]struct{}
+	closed  chan struct{}
+	close   sync.Once
+}
+
+// profileStream is an open stream of a user's changes. Its organizations and
+// teams are guarded by the service's lock.
+type profileStream struct {
+	userID string
+	// id is the stored user ID, which user.updated events are about
+	id    string
+	orgs  map[string]bool
+	teams map[string]bool
+
+	events  chan profileStreamMessage
+	behind  chan struct{}
+	dropped sync.Once
+}
+
+// profileStreamMessage is an event waiting to be sent to a stream
+type profileStreamMessage struct {
+	event string
+	data  *models.ProfileStreamEvent
+}
+
+// NewProfileStreamService creates a new profile stream service
+func NewProfileStreamService(userRepo *repositories.UserRepository, orgRepo *repositories.OrganizationRepository) *ProfileStreamService {
+	return &ProfileStreamService{
+		userRepo: userRepo,
+		orgRepo:  orgRepo,
+		streams:  make(map[*profileStream]struct{}),
+		closed:   make(chan struct{}),
+	}
+}
+
+// HandleEvent sends a user or team event to the streams it is about. Events
+// are only streamed, so ones that can't be decoded are logged and skipped.
+func (s *ProfileStreamService) HandleEvent(ctx context.Context, event kafka.Event) error {
+	events, err := kafka.ExpandMemberBatch(event)
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("eventId", event.ID).Str("eventType", string(event.Type)).
+			Msg("Failed to expand member batch event")
+		return nil
+	}
+
+	for _, event := range events {
+		if err := s.route(event); err != nil {
+			logger.Ctx(ctx).Warn().Err(err).Str("eventId", event.ID).Str("eventType", string(event.Type)).
+				Msg("Failed to stream event")
+		}
+	}
+
+	return nil
+}
+
+// route sends an event to the streams it is about, keeping the streams'
+// organizations and teams up to date with membership changes
+func (s *ProfileStreamService) route(event kafka.Event) error {
+	change := &models.ProfileStreamEvent{Type: string(event.Type), OccurredAt: event.Time}
+
+	switch event.Type {
+	case kafka.UserUpdated:
+		var data models.UserResponse
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		change.User = &data
+		s.send(func(stream *profileStream) bool {
+			return stream.id == data.ID
+		}, ProfileEventUserUpdated, change)
+
+	case kafka.OrganizationUpdated:
+		var data models.OrganizationResponse
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		change.OrganizationID = data.ID
+		change.OrganizationName = data.Name
+		s.send(func(stream *profileStream) bool {
+			return stream.orgs[data.ID]
+		}, ProfileEventOrgSettingsChanged, change)
+
+	case kafka.OrganizationDeleted:
+		var data models.OrganizationResponse
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		change.OrganizationID = data.ID
+		change.OrganizationName = data.Name
+		s.send(func(stream *profileStream) bool {
+			if !stream.orgs[data.ID] {
+				return false
+			}
+			delete(stream.orgs, data.ID)
+			return true
+		}, ProfileEventMembershipChanged, change)
+
+	case kafka.OrganizationMemberAdded:
+		var data kafka.OrganizationMemberAddedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		change.OrganizationID, change.OrganizationName, change.Role = data.OrgID, data.OrgName, data.Role
+		s.orgMembershipChanged(data.UserID, data.OrgID, true, change)
+
+	case kafka.OrganizationMemberUpdated:
+		var data kafka.OrganizationMemberUpdatedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		change.OrganizationID, change.OrganizationName, change.Role = data.OrgID, data.OrgName, data.Role
+		s.orgMembershipChanged(data.UserID, data.OrgID, true, change)
+
+	case kafka.OrganizationMemberRemoved:
+		var data kafka.OrganizationMemberRemovedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		change.OrganizationID, change.OrganizationName = data.OrgID, data.OrgName
+		s.orgMembershipChanged(data.UserID, data.OrgID, false, change)
+
+	case kafka.OrganizationMemberExpired:
+		var data kafka.OrganizationMemberExpiredV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		change.OrganizationID, change.OrganizationName = data.OrgID, data.OrgName
+		s.orgMembershipChanged(data.UserID, data.OrgID, false, change)
+
+	case kafka.OrganizationGuestAdded, kafka.OrganizationGuestExtended, kafka.OrganizationGuestExpired:
+		var data kafka.OrganizationGuestV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		change.OrganizationID, change.OrganizationName = data.OrgID, data.OrgName
+		member := event.Type != kafka.OrganizationGuestExpired
+		if member {
+			change.Role = string(models.OrgRoleGuest)
+		}
+		s.orgMembershipChanged(data.UserID, data.OrgID, member, change)
+
+	case kafka.OrganizationOwnershipTransferred, kafka.TeamOwnershipTransferred:
+		var data kafka.OwnershipTransferredV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		change.OrganizationID, change.OrganizationName = data.OrgID, data.OrgName
+		change.TeamID, change.TeamName = data.TeamID, data.TeamName
+
+		previous := *change
+		member := data.PreviousOwnerRole != models.PreviousOwnerRemove
+		if member {
+			previous.Role = data.PreviousOwnerRole
+		}
+		change.Role = string(models.OrgRoleOwner)
+		if data.TeamID != "" {
+			change.Role = string(models.TeamRoleOwner)
+			s.teamMembershipChanged(data.PreviousOwnerID, data.TeamID, member, &previous)
+			s.teamMembershipChanged(data.NewOwnerID, data.TeamID, true, change)
+		} else {
+			s.orgMembershipChanged(data.PreviousOwnerID, data.OrgID, member, &previous)
+			s.orgMembershipChanged(data.NewOwnerID, data.OrgID, true, change)
+		}
+
+	case kafka.TeamDeleted:
+		var data models.TeamResponse
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		change.TeamID, change.TeamName, change.OrganizationID = data.ID, data.Name, data.OrganizationID
+		s.send(func(stream *profileStream) bool {
+			if !stream.teams[data.ID] {
+				return false
+			}
+			delete(stream.teams, data.ID)
+			return true
+		}, ProfileEventMembershipChanged, change)
+
+	case kafka.TeamMemberAdded:
+		var data kafka.TeamMemberAddedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		change.TeamID, change.TeamName, change.Role = data.TeamID, data.TeamName, data.Role
+		s.teamMembershipChanged(data.UserID, data.TeamID, true, change)
+
+	case kafka.TeamMemberUpdated:
+		var data kafka.TeamMemberUpdatedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		change.TeamID, change.TeamName, change.Role = data.TeamID, data.TeamName, data.Role
+		s.teamMembershipChanged(data.UserID, data.TeamID, true, change)
+
+	case kafka.TeamMemberRemoved:
+		var data kafka.TeamMemberRemovedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		change.TeamID, change.TeamName = data.TeamID, data.TeamName
+		s.teamMembershipChanged(data.UserID, data.TeamID, false, change)
+	}
+
+	return nil
+}
+
+// orgMembershipChanged sends a membership change to a user's streams,
+// recording if the user is still a member of the organization
+func (s *ProfileStreamService) orgMembershipChanged(userID, orgID string, member bool, change *models.ProfileStreamEvent) {
+	s.send(func(stream *profileStream) bool {
+		if stream.userID != userID {
+			return false
+		}
+		if member {
+			stream.orgs[orgID] = true
+		} else {
+			delete(stream.orgs, orgID)
+		}
+		return true
+	}, ProfileEventMembershipChanged, change)
+}
+
+// teamMembershipChanged sends a membership change to a user's streams,
+// recording if the user is still a member of the team
+func (s *ProfileStreamService) teamMembershipChanged(userID, teamID string, member bool, change *models.ProfileStreamEvent) {
+	s.send(func(stream *profileStream) bool {
+		if stream.userID != userID {
+			return false
+		}
+		if member {
+			stream.teams[teamID] = true
+		} else {
+			delete(stream.teams, teamID)
+		}
+		return true
+	}, ProfileEventMembershipChanged, change)
+}
+
+// send sends an event to the streams it matches. The match function runs
+// under the service's lock, so it may update the stream's organizations and
+// teams. Streams too far behind to take the event are ended.
+func (s *ProfileStreamService) send(match func(*profileStream) bool, event string, change *models.ProfileStreamEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for stream := range s.streams {
+		if !match(stream) {
+			continue
+		}
+		select {
+		case stream.events <- profileStreamMessage{event: event, data: change}:
+		default:
+			stream.dropped.Do(func() { close(stream.behind) })
+		}
+	}
+}
+
+// Watch streams the changes of a user's profile, memberships and
+// organizations to send until the context is done, the stream has run for
+// its longest duration, falls behind or the service closes
+func (s *ProfileStreamService) Watch(ctx context.Context, userID string, send func(event string, data interface{}) error) error {
+	stream := &profileStream{
+		userID: userID,
+		orgs:   make(map[string]bool),
+		teams:  make(map[string]bool),
+		events: make(chan profileStreamMessage, profileStreamBuffer),
+		behind: make(chan struct{}),
+	}
+
+	// Register the stream before loading the user's memberships, so changes
+	// made meanwhile aren't missed
+	s.mu.Lock()
+	s.streams[stream] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.streams, stream)
+		s.mu.Unlock()
+	}()
+
+	user, err := s.userRepo.GetByUserId(ctx, userID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	orgIDs, err := s.orgRepo.GetOrganizationIDsByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if user != nil {
+		stream.id = user.ID
+		for _, teamID := range user.TeamIDs {
+			stream.teams[teamID] = true
+		}
+	}
+	for _, orgID := range orgIDs {
+		stream.orgs[orgID] = true
+	}
+	s.mu.Unlock()
+
+	keepAlive := time.NewTicker(profileStreamKeepAlive)
+	defer keepAlive.Stop()
+	timeout := time.NewTimer(profileStreamMaxDuration)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.closed:
+			return nil
+		case <-timeout.C:
+			return nil
+		case <-stream.behind:
+			logger.Ctx(ctx).Warn().Str("userId", userID).Msg("Profile stream fell behind, ending it")
+			return nil
+		case <-keepAlive.C:
+			if err := send(ProfileEventPing, struct{}{}); err != nil {
+				return err
+			}
+		case message := <-stream.events:
+			if err := send(message.event, message.data); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Close ends every profile stream, so the server can shut down without
+// waiting for them
+func (s *ProfileStreamService) Close() {
+	s.close.Do(func() { close(s.closed) })
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/realtime"
+	"github.com/your-username/slido-clone/user-service/repositories"
+)
+
+// RealtimeService sends membership and presence changes to the realtime
+// connections of organization members. Each connection is in a room for
+// every organization its user is a member of; every instance consumes every
+// user and team event and broadcasts membership changes to the rooms of the
+// organization they happened in.
+type RealtimeService struct {
+	hub             *realtime.Hub
+	orgRepo         *repositories.OrganizationRepository
+	teamRepo        repositories.TeamStore
+	presenceService *PresenceService
+	events          kafka.EventPublisher
+	presenceConfig  *config.PresenceConfig
+}
+
+// NewRealtimeService creates a new realtime service
+func NewRealtimeService(
+	hub *realtime.Hub,
+	orgRepo *repositories.OrganizationRepository,
+	teamRepo repositories.TeamStore,
+	presenceService *PresenceService,
+	events kafka.EventPublisher,
+	presenceCfg *config.PresenceConfig,
+) *RealtimeService {
+	return &RealtimeService{
+		hub:             hub,
+		orgRepo:         orgRepo,
+		teamRepo:        teamRepo,
+		presenceService: presenceService,
+		events:          events,
+		presenceConfig:  presenceCfg,
+	}
+}
+
+// Connect serves a user's realtime connection until it closes. The user is
+// kept online while connected, and their organizations are told when their
+// first connection to this instance opens and their last one closes.
+func (s *RealtimeService) Connect(ctx context.Context, conn *websocket.Conn, userID string) error {
+	client, err := s.hub.Register(conn, userID)
+	if err != nil {
+		return err
+	}
+
+	// Join the rooms after registering, so membership changes made
+	// meanwhile aren't missed
+	orgIDs, err := s.orgRepo.GetOrganizationIDsByUser(ctx, userID)
+	if err != nil {
+		client.Close(websocket.CloseInternalServerErr, "failed to load organizations")
+		client.Run()
+		return err
+	}
+	client.Join(orgIDs...)
+
+	stop := make(chan struct{})
+	go s.keepOnline(ctx, userID, stop)
+	if client.First() {
+		s.publishPresence(ctx, userID, models.PresenceOnline, orgIDs)
+	}
+
+	last := client.Run()
+	close(stop)
+
+	// Users aren't reported offline when the server shuts down, since their
+	// clients reconnect to another instance
+	if last && !s.hub.Closed() {
+		ctx := context.WithoutCancel(ctx)
+		if current, err := s.orgRepo.GetOrganizationIDsByUser(ctx, userID); err == nil {
+			orgIDs = current
+		}
+		s.publishPresence(ctx, userID, models.PresenceOffline, orgIDs)
+	}
+
+	return nil
+}
+
+// keepOnline sends presence heartbeats for a connected user until stopped
+func (s *RealtimeService) keepOnline(ctx context.Context, userID string, stop <-chan struct{}) {
+	ticker := time.NewTicker(s.presenceConfig.TTL / 2)
+	defer ticker.Stop()
+
+	for {
+		// Heartbeat failures are logged by the presence service
+		s.presenceService.Heartbeat(ctx, userID)
+
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishPresence publishes that a user came online or went offline
+func (s *RealtimeService) publishPresence(ctx context.Context, userID string, status models.PresenceStatus, orgIDs []string) {
+	err := s.events.PublishUserEvent(ctx, kafka.UserPresenceChanged, kafka.UserPresenceChangedV1{
+		UserID:          userID,
+		Status:          string(status),
+		OrganizationIDs: orgIDs,
+		ChangedAt:       time.Now(),
+	}, userID)
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("userId", userID).Str("status", string(status)).
+			Msg("Failed to publish presence change")
+	}
+}
+
+// HandleEvent broadcasts a user or team event to the rooms of the
+// organization it happened in. Events are only broadcast, so ones that
+// can't be decoded are logged and skipped.
+func (s *RealtimeService) HandleEvent(ctx context.Context, event kafka.Event) error {
+	events, err := kafka.ExpandMemberBatch(event)
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("eventId", event.ID).Str("eventType", string(event.Type)).
+			Msg("Failed to expand member batch event")
+		return nil
+	}
+
+	for _, event := range events {
+		if err := s.route(ctx, event); err != nil {
+			logger.Ctx(ctx).Warn().Err(err).Str("eventId", event.ID).Str("eventType", string(event.Type)).
+				Msg("Failed to broadcast realtime event")
+		}
+	}
+
+	return nil
+}
+
+// route broadcasts an event to the rooms it is about, moving members'
+// connections into and out of the rooms of their organizations
+func (s *RealtimeService) route(ctx context.Context, event kafka.Event) error {
+	message := &models.RealtimeMessage{Type: string(event.Type), OccurredAt: event.Time}
+
+	switch event.Type {
+	case kafka.UserPresenceChanged:
+		var data kafka.UserPresenceChangedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		message.UserID, message.Status = data.UserID, models.PresenceStatus(data.Status)
+		for _, orgID := range data.OrganizationIDs {
+			if err := s.broadcast(orgID, message); err != nil {
+				return err
+			}
+		}
+
+	case kafka.OrganizationDeleted:
+		var data models.OrganizationResponse
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		if err := s.broadcast(data.ID, message); err != nil {
+			return err
+		}
+		s.hub.CloseRoom(data.ID)
+
+	case kafka.OrganizationMemberAdded:
+		var data kafka.OrganizationMemberAddedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		message.UserID, message.Role = data.UserID, data.Role
+		return s.orgMembershipChanged(data.OrgID, true, message)
+
+	case kafka.OrganizationMemberUpdated:
+		var data kafka.OrganizationMemberUpdatedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		message.UserID, message.Role = data.UserID, data.Role
+		return s.orgMembershipChanged(data.OrgID, true, message)
+
+	case kafka.OrganizationMemberRemoved:
+		var data kafka.OrganizationMemberRemovedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		message.UserID = data.UserID
+		return s.orgMembershipChanged(data.OrgID, false, message)
+
+	case kafka.OrganizationMemberExpired:
+		var data kafka.OrganizationMemberExpiredV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		message.UserID = data.UserID
+		return s.orgMembershipChanged(data.OrgID, false, message)
+
+	case kafka.OrganizationGuestAdded, kafka.OrganizationGuestExtended, kafka.OrganizationGuestExpired:
+		var data kafka.OrganizationGuestV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		message.UserID = data.UserID
+		member := event.Type != kafka.OrganizationGuestExpired
+		if member {
+			message.Role = string(models.OrgRoleGuest)
+		}
+		return s.orgMembershipChanged(data.OrgID, member, message)
+
+	case kafka.OrganizationOwnershipTransferred, kafka.TeamOwnershipTransferred:
+		var data kafka.OwnershipTransferredV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		message.TeamID, message.TeamName = data.TeamID, data.TeamName
+
+		previous := *message
+		previous.UserID = data.PreviousOwnerID
+		member := data.PreviousOwnerRole != models.PreviousOwnerRemove
+		if member {
+			previous.Role = data.PreviousOwnerRole
+		}
+		message.UserID = data.NewOwnerID
+		message.Role = string(models.OrgRoleOwner)
+		if data.TeamID != "" {
+			message.Role = string(models.TeamRoleOwner)
+			if err := s.teamMembershipChanged(ctx, data.TeamID, &previous); err != nil {
+				return err
+			}
+			return s.teamMembershipChanged(ctx, data.TeamID, message)
+		}
+		if err := s.orgMembershipChanged(data.OrgID, member, &previous); err != nil {
+			return err
+		}
+		return s.orgMembershipChanged(data.OrgID, true, message)
+
+	case kafka.TeamDeleted:
+		var data models.TeamResponse
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		message.TeamID, message.TeamName = data.ID, data.Name
+		return s.broadcast(data.OrganizationID, message)
+
+	case kafka.TeamMemberAdded:
+		var data kafka.TeamMemberAddedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		message.TeamID, message.TeamName, message.UserID, message.Role = data.TeamID, data.TeamName, data.UserID, data.Role
+		return s.teamMembershipChanged(ctx, data.TeamID, message)
+
+	case kafka.TeamMemberUpdated:
+		var data kafka.TeamMemberUpdatedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		message.TeamID, message.TeamName, message.UserID, message.Role = data.TeamID, data.TeamName, data.UserID, data.Role
+		return s.teamMembershipChanged(ctx, data.TeamID, message)
+
+	case kafka.TeamMemberRemoved:
+		var data kafka.TeamMemberRemovedV1
+		if err := kafka.DecodeData(event, &data); err != nil {
+			return err
+		}
+		message.TeamID, message.TeamName, message.UserID = data.TeamID, data.TeamName, data.UserID
+		return s.teamMembershipChanged(ctx, data.TeamID, message)
+	}
+
+	return nil
+}
+
+// orgMembershipChanged broadcasts a membership change to an organization's
+// room, moving the member's connections into or out of it. Members who
+// left are told before their connections leave.
+func (s *RealtimeService) orgMembershipChanged(orgID string, member bool, message *models.RealtimeMessage) error {
+	if member {
+		s.hub.Join(message.UserID, orgID)
+		return s.broadcast(orgID, message)
+	}
+
+	err := s.broadcast(orgID, message)
+	s.hub.Leave(message.UserID, orgID)
+	return err
+}
+
+// teamMembershipChanged broadcasts a team membership change to the room of
+// the team's organization. Team member events only carry the team, so its
+// organization is looked up.
+func (s *RealtimeService) teamMembershipChanged(ctx context.Context, teamID string, message *models.RealtimeMessage) error {
+	team, err := s.teamRepo.GetByID(ctx, teamID)
+	if err != nil {
+		return err
+	}
+	return s.broadcast(team.OrganizationID, message)
+}
+
+// broadcast sends a message to an organization's room
+func (s *RealtimeService) broadcast(orgID string, message *models.RealtimeMessage) error {
+	msg := *message
+	msg.OrganizationID = orgID
+	return s.hub.Broadcast(orgID, &msg)
+}
+
+// Close closes every realtime connection, so the server can shut down
+func (s *RealtimeService) Close() {
+	s.hub.Close()
+}
@@ -0,0 +1,347 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxReplayJobs is the number of finished replay jobs kept for status lookups
+const maxReplayJobs = 20
+
+// replaySnapshot is an entity snapshot to re-publish
+type replaySnapshot struct {
+	id      string
+	publish func() error
+}
+
+// ReplayService re-publishes user, organization and team snapshots as
+// *.replayed events so downstream consumers can rebuild their state. One
+// replay runs at a time and events are published at a limited rate to avoid
+// flooding Kafka.
+type ReplayService struct {
+	userRepo repositories.UserStore
+	orgRepo  repositories.OrgStore
+	teamRepo repositories.TeamStore
+	producer *kafka.Producer
+	config   *config.ReplayConfig
+
+	mu        sync.Mutex
+	rateLimit int
+	jobs      map[string]*models.ReplayJob
+	order     []string
+	running   string
+	cancel    context.CancelFunc
+}
+
+// NewReplayService creates a new replay service
+func NewReplayService(
+	userRepo repositories.UserStore,
+	orgRepo repositories.OrgStore,
+	teamRepo repositories.TeamStore,
+	producer *kafka.Producer,
+	cfg *config.ReplayConfig,
+) *ReplayService {
+	return &ReplayService{
+		userRepo:  userRepo,
+		orgRepo:   orgRepo,
+		teamRepo:  teamRepo,
+		producer:  producer,
+		config:    cfg,
+		rateLimit: cfg.RateLimit,
+		jobs:      make(map[string]*models.ReplayJob),
+	}
+}
+
+// SetRateLimit sets the rate limit of replays, in events per second. A
+// running replay keeps the rate it started with.
+func (s *ReplayService) SetRateLimit(rate int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rateLimit = rate
+}
+
+// StartReplay starts re-publishing the snapshots matching a request in the
+// background and returns the job tracking it
+func (s *ReplayService) StartReplay(req models.ReplayEventsRequest, requestedBy string) (*models.ReplayJob, error) {
+	if req.FromID != "" && req.ToID != "" && req.FromID > req.ToID {
+		return nil, apperrors.InvalidField("fromId", "fromId must not be after toId")
+	}
+	if req.Since != nil && req.Until != nil && req.Since.After(*req.Until) {
+		return nil, apperrors.InvalidField("since", "since must not be after until")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running != "" {
+		return nil, apperrors.Conflict("REPLAY_RUNNING", "a replay is already running")
+	}
+
+	job := models.NewReplayJob(req, requestedBy)
+	// Replays read the entities of every organization
+	ctx, cancel := context.WithCancel(tenancy.Unscoped(context.Background()))
+	s.running = job.ID
+	s.cancel = cancel
+	s.store(job)
+
+	logger.Ctx(ctx).Info().
+		Str("jobId", job.ID).
+		Str("entityType", string(req.EntityType)).
+		Str("requestedBy", requestedBy).
+		Msg("Starting event replay")
+
+	go s.run(ctx, job)
+
+	copied := *job
+	return &copied, nil
+}
+
+// GetJob gets a replay job by ID
+func (s *ReplayService) GetJob(id string) (*models.ReplayJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, apperrors.NotFound("REPLAY_JOB_NOT_FOUND", "replay job not found")
+	}
+
+	copied := *job
+	return &copied, nil
+}
+
+// CancelJob stops a running replay job
+func (s *ReplayService) CancelJob(id string) (*models.ReplayJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, apperrors.NotFound("REPLAY_JOB_NOT_FOUND", "replay job not found")
+	}
+	if s.running != id {
+		return nil, apperrors.Conflict("REPLAY_JOB_NOT_RUNNING", "replay job is not running")
+	}
+
+	s.cancel()
+
+	copied := *job
+	return &copied, nil
+}
+
+// Stop cancels the running replay, if any
+func (s *ReplayService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// store records a job, evicting the oldest finished jobs
+func (s *ReplayService) store(job *models.ReplayJob) {
+	s.jobs[job.ID] = job
+	s.order = append(s.order, job.ID)
+	for len(s.order) > maxReplayJobs {
+		delete(s.jobs, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// run publishes the snapshots of a job batch by batch, in ID order
+func (s *ReplayService) run(ctx context.Context, job *models.ReplayJob) {
+	s.mu.Lock()
+	rate := s.rateLimit
+	s.mu.Unlock()
+	if rate < 1 {
+		rate = 1
+	}
+	batchSize := s.config.BatchSize
+	if batchSize < 1 {
+		batchSize = 100
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+
+	err := func() error {
+		lastID := ""
+		for {
+			filter, err := replayFilter(job.Request, lastID)
+			if err != nil {
+				return err
+			}
+
+			snapshots, err := s.nextBatch(ctx, job.Request.EntityType, filter, int64(batchSize))
+			if err != nil {
+				return err
+			}
+
+			for _, snapshot := range snapshots {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-ticker.C:
+				}
+
+				err := snapshot.publish()
+
+				s.mu.Lock()
+				if err != nil {
+					job.Failed++
+				} else {
+					job.Published++
+				}
+				job.LastID = snapshot.id
+				s.mu.Unlock()
+
+				if err != nil {
+					logger.Ctx(ctx).Error().Err(err).Str("jobId", job.ID).Str("id", snapshot.id).Msg("Failed to publish replayed event")
+				}
+				lastID = snapshot.id
+			}
+
+			if len(snapshots) < batchSize {
+				return nil
+			}
+		}
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	job.FinishedAt = &now
+	switch {
+	case err == nil:
+		job.Status = models.ReplayCompleted
+	case errors.Is(err, context.Canceled):
+		job.Status = models.ReplayCancelled
+	default:
+		job.Status = models.ReplayFailed
+		job.Error = err.Error()
+	}
+	s.running = ""
+	s.cancel = nil
+
+	logger.Ctx(ctx).Info().
+		Str("jobId", job.ID).
+		Str("status", string(job.Status)).
+		Int("published", job.Published).
+		Int("failed", job.Failed).
+		Msg("Event replay finished")
+}
+
+// nextBatch gets the next batch of snapshots of an entity type
+func (s *ReplayService) nextBatch(ctx context.Context, entityType models.ReplayEntityType, filter bson.M, limit int64) ([]replaySnapshot, error) {
+	var snapshots []replaySnapshot
+
+	switch entityType {
+	case models.ReplayUsers:
+		users, err := s.userRepo.FindBatch(ctx, filter, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			u := user
+			snapshots = append(snapshots, replaySnapshot{
+				id: u.ID,
+				publish: func() error {
+					return s.producer.PublishUserEvent(ctx, kafka.UserReplayed, u.ToResponse(), u.ID)
+				},
+			})
+		}
+
+	case models.ReplayOrganizations:
+		orgs, err := s.orgRepo.FindBatch(ctx, filter, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, org := range orgs {
+			o := org
+			snapshots = append(snapshots, replaySnapshot{
+				id: o.ID,
+				publish: func() error {
+					return s.producer.PublishUserEvent(ctx, kafka.OrganizationReplayed, o.ToResponse(true, false), o.ID)
+				},
+			})
+		}
+
+	case models.ReplayTeams:
+		teams, err := s.teamRepo.FindBatch(ctx, filter, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, team := range teams {
+			t := team
+			snapshots = append(snapshots, replaySnapshot{
+				id: t.ID,
+				publish: func() error {
+					return s.producer.PublishTeamEvent(ctx, kafka.TeamReplayed, t.ToResponse(true), t.ID)
+				},
+			})
+		}
+
+	default:
+		return nil, errors.New("unsupported entity type")
+	}
+
+	return snapshots, nil
+}
+
+// replayFilter builds the filter of the batch after lastID
+func replayFilter(req models.ReplayEventsRequest, lastID string) (bson.M, error) {
+	filter := bson.M{}
+
+	idRange := bson.M{}
+	if lastID != "" {
+		objID, err := primitive.ObjectIDFromHex(lastID)
+		if err != nil {
+			return nil, err
+		}
+		idRange["$gt"] = objID
+	} else if req.FromID != "" {
+		objID, err := primitive.ObjectIDFromHex(req.FromID)
+		if err != nil {
+			return nil, err
+		}
+		idRange["$gte"] = objID
+	}
+	if req.ToID != "" {
+		objID, err := primitive.ObjectIDFromHex(req.ToID)
+		if err != nil {
+			return nil, err
+		}
+		idRange["$lte"] = objID
+	}
+	if len(idRange) > 0 {
+		filter["_id"] = idRange
+	}
+
+	window := bson.M{}
+	if req.Since != nil {
+		window["$gte"] = *req.Since
+	}
+	if req.Until != nil {
+		window["$lte"] = *req.Until
+	}
+	if len(window) > 0 {
+		filter["updatedAt"] = window
+	}
+
+	return filter, nil
+}
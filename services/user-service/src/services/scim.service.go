@@ -0,0 +1,1158 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/id"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/scim"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SCIMBasePath is the path prefix of the SCIM API, used for resource locations
+const SCIMBasePath = "/scim/v2"
+
+// scimTokenPrefix makes SCIM tokens recognizable in logs and secret scanners
+const scimTokenPrefix = "scim_"
+
+var (
+	// ErrSCIMInvalidToken is returned when a SCIM bearer token is unknown
+	ErrSCIMInvalidToken = errors.New("invalid SCIM token")
+	// ErrSCIMNotFound is returned when a SCIM resource does not exist in the organization
+	ErrSCIMNotFound = errors.New("resource not found")
+	// ErrSCIMConflict is returned when a SCIM resource already exists
+	ErrSCIMConflict = errors.New("resource already exists")
+	// ErrSCIMInvalidValue is returned when a SCIM request contains an invalid value
+	ErrSCIMInvalidValue = errors.New("invalid value")
+	// ErrSCIMUnsupportedFilter is returned when a filter uses an unsupported attribute
+	ErrSCIMUnsupportedFilter = errors.New("unsupported filter attribute")
+	// ErrSCIMUserNotLinkable is returned when a user with the email of a
+	// provisioned user exists outside the organization's verified domain
+	ErrSCIMUserNotLinkable = errors.New("user exists outside the organization's verified domain")
+)
+
+// scimMemberField prefixes the SCIM User attributes kept on the membership
+// rather than the user document
+const scimMemberField = "member."
+
+// scimUserAttributes maps SCIM User attributes to user document fields, or
+// membership fields
+var scimUserAttributes = map[string]string{
+	"id":              "userId",
+	"username":        "email",
+	"externalid":      scimMemberField + "externalId",
+	"emails":          "email",
+	"emails.value":    "email",
+	"name.givenname":  "firstName",
+	"name.familyname": "lastName",
+	"title":           "jobTitle",
+	"active":          scimMemberField + "active",
+}
+
+// scimGroupAttributes maps SCIM Group attributes to team document fields
+var scimGroupAttributes = map[string]string{
+	"id":            "_id",
+	"displayname":   "name",
+	"externalid":    "externalId",
+	"members":       "members.userId",
+	"members.value": "members.userId",
+}
+
+// SCIMService is a service for SCIM provisioning
+type SCIMService struct {
+	tokenRepo        *repositories.SCIMTokenRepository
+	userRepo         repositories.UserStore
+	teamRepo         repositories.TeamStore
+	orgRepo          repositories.OrgStore
+	verificationRepo *repositories.VerificationRepository
+	orgService       *OrganizationService
+	events           kafka.EventPublisher
+	sync             *SyncService
+}
+
+// NewSCIMService creates a new SCIM service
+func NewSCIMService(
+	tokenRepo *repositories.SCIMTokenRepository,
+	userRepo repositories.UserStore,
+	teamRepo repositories.TeamStore,
+	orgRepo repositories.OrgStore,
+	verificationRepo *repositories.VerificationRepository,
+	orgService *OrganizationService,
+	events kafka.EventPublisher,
+	syncService *SyncService,
+) *SCIMService {
+	return &SCIMService{
+		tokenRepo:        tokenRepo,
+		userRepo:         userRepo,
+		teamRepo:         teamRepo,
+		orgRepo:          orgRepo,
+		verificationRepo: verificationRepo,
+		orgService:       orgService,
+		events:           events,
+		sync:             syncService,
+	}
+}
+
+// CreateToken issues a new SCIM bearer token for an organization
+func (s *SCIMService) CreateToken(ctx context.Context, orgID string, req models.CreateSCIMTokenRequest, userID string) (*models.SCIMToken, string, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, "", ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for SCIM token")
+		return nil, "", err
+	}
+
+	// Check permissions - must be owner
+	if !org.Can(userID, models.PermOrgManageSCIM) {
+		return nil, "", insufficientPermissions("manage SCIM tokens")
+	}
+
+	// Generate token
+	plainToken, err := id.Token(scimTokenPrefix, 32)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Failed to generate SCIM token")
+		return nil, "", err
+	}
+
+	// Save hashed token
+	token := models.NewSCIMToken(orgID, hashSCIMToken(plainToken), req.Description, userID)
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to create SCIM token")
+		return nil, "", err
+	}
+
+	return token, plainToken, nil
+}
+
+// ListTokens lists the SCIM tokens of an organization
+func (s *SCIMService) ListTokens(ctx context.Context, orgID string, userID string) ([]*models.SCIMToken, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for SCIM tokens")
+		return nil, err
+	}
+
+	// Check permissions - must be owner
+	if !org.Can(userID, models.PermOrgManageSCIM) {
+		return nil, insufficientPermissions("manage SCIM tokens")
+	}
+
+	return s.tokenRepo.GetByOrganization(ctx, orgID)
+}
+
+// RevokeToken revokes a SCIM token of an organization
+func (s *SCIMService) RevokeToken(ctx context.Context, orgID, tokenID string, userID string) error {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for SCIM token revocation")
+		return err
+	}
+
+	// Check permissions - must be owner
+	if !org.Can(userID, models.PermOrgManageSCIM) {
+		return insufficientPermissions("manage SCIM tokens")
+	}
+
+	err = s.tokenRepo.Delete(ctx, orgID, tokenID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("SCIM_TOKEN_NOT_FOUND", "SCIM token not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Authenticate resolves a SCIM bearer token to the organization it was issued for
+func (s *SCIMService) Authenticate(ctx context.Context, plainToken string) (string, error) {
+	token, err := s.tokenRepo.GetByHash(ctx, hashSCIMToken(plainToken))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", ErrSCIMInvalidToken
+		}
+		return "", err
+	}
+
+	// Track usage without failing the request
+	if err := s.tokenRepo.TouchLastUsed(ctx, token.ID, time.Now()); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("id", token.ID).Msg("Failed to update SCIM token usage")
+	}
+
+	return token.OrganizationID, nil
+}
+
+// ListUsers lists the members of an organization matching a SCIM filter
+func (s *SCIMService) ListUsers(ctx context.Context, orgID string, filter *scim.Filter, startIndex, count int) ([]*models.SCIMMember, int64, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for SCIM users")
+		return nil, 0, err
+	}
+
+	query, err := buildSCIMQuery(filter, scimUserAttributes, org.Members)
+	if err != nil {
+		return nil, 0, err
+	}
+	query["organizationIds"] = orgID
+
+	users, total, err := s.userRepo.FindUsers(ctx, query, int64(startIndex-1), int64(count))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	members := make([]*models.SCIMMember, 0, len(users))
+	for _, user := range users {
+		member := &models.SCIMMember{User: user}
+		if m := org.GetMember(user.UserID); m != nil {
+			member.Member = *m
+		}
+		members = append(members, member)
+	}
+	return members, total, nil
+}
+
+// GetUser gets a member of an organization by SCIM id
+func (s *SCIMService) GetUser(ctx context.Context, orgID, id string) (*models.SCIMMember, error) {
+	user, err := s.userRepo.GetByUserId(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrSCIMNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", id).Msg("Failed to get SCIM user")
+		return nil, err
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for SCIM user")
+		return nil, err
+	}
+
+	// Users outside the organization are invisible to its SCIM client
+	member := org.GetMember(user.UserID)
+	if member == nil {
+		return nil, ErrSCIMNotFound
+	}
+
+	return &models.SCIMMember{User: user, Member: *member}, nil
+}
+
+// CreateUser provisions a user into an organization. Existing users with the
+// same email are linked to the organization instead of being duplicated, if
+// they're members not yet provisioned or the organization has verified it
+// controls their email domain. Anyone else's account isn't the
+// organization's to manage.
+func (s *SCIMService) CreateUser(ctx context.Context, orgID string, req models.SCIMUser) (*models.SCIMMember, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for SCIM user")
+		return nil, err
+	}
+
+	email := strings.ToLower(req.PrimaryEmail())
+	provisioning := models.OrganizationMember{
+		ExternalID: req.ExternalID,
+		Suspended:  req.Active != nil && !*req.Active,
+	}
+
+	// Link an existing user if possible
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		logger.Ctx(ctx).Error().Err(err).Str("email", email).Msg("Failed to check existing user for SCIM")
+		return nil, err
+	}
+
+	if user != nil {
+		if member := org.GetMember(user.UserID); member != nil {
+			if member.ExternalID != "" {
+				return nil, ErrSCIMConflict
+			}
+			return s.saveProvisioning(ctx, orgID, &models.SCIMMember{User: user, Member: *member}, provisioning)
+		}
+
+		controls, err := s.controlsEmailDomain(ctx, orgID, email)
+		if err != nil {
+			return nil, err
+		}
+		if !controls {
+			logger.Ctx(ctx).Warn().Str("orgId", orgID).Str("userId", user.UserID).
+				Msg("Refusing to link SCIM user outside the organization's verified domain")
+			return nil, ErrSCIMUserNotLinkable
+		}
+	}
+
+	create := user == nil
+	if create {
+		firstName, lastName := scimNames(req)
+		if firstName == "" || lastName == "" {
+			return nil, ErrSCIMInvalidValue
+		}
+
+		// Users provisioned through SCIM have no auth account yet, so they get
+		// a generated user ID until they sign in for the first time
+		user = models.NewUser(models.CreateUserRequest{
+			UserID:    uuid.New().String(),
+			Email:     email,
+			FirstName: firstName,
+			LastName:  lastName,
+			Role:      models.RoleUser,
+		})
+		user.JobTitle = req.Title
+		user.Preferences = user.Preferences.Resolve(org.Settings.DefaultPreferences)
+	}
+
+	// The identity provider adds members like anyone else does, so the
+	// organization's join policies and approval webhook apply
+	role := org.Settings.DefaultUserRole
+	if role == "" || role == models.OrgRoleOwner {
+		role = models.OrgRoleMember
+	}
+	if err := s.orgService.checkMemberAdd(ctx, org, user, role, "scim", "userName"); err != nil {
+		return nil, err
+	}
+
+	if create {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("email", email).Msg("Failed to create SCIM user")
+			return nil, err
+		}
+	}
+
+	// Add the user to the organization
+	if err := s.orgRepo.AddMember(ctx, orgID, user.UserID, role, org.CreatedBy); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", user.UserID).
+			Msg("Failed to add SCIM user to organization")
+		return nil, err
+	}
+	member := &models.SCIMMember{
+		User: user,
+		Member: models.OrganizationMember{
+			UserID:    user.UserID,
+			Role:      role,
+			JoinedAt:  time.Now(),
+			InvitedBy: org.CreatedBy,
+		},
+	}
+
+	if err := s.userRepo.AddOrganizationToUser(ctx, user.UserID, orgID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", user.UserID).
+			Msg("Failed to add organization to SCIM user")
+		// Don't fail the operation, but log the error
+	}
+	user.OrganizationIDs = append(user.OrganizationIDs, orgID)
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationMemberAdded,
+		kafka.OrganizationMemberAddedV1{
+			OrgID:     org.ID,
+			OrgName:   org.Name,
+			UserID:    user.UserID,
+			UserEmail: user.Email,
+			UserName:  user.FirstName + " " + user.LastName,
+			Role:      string(role),
+			InvitedBy: "scim",
+			JoinedAt:  member.Member.JoinedAt,
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", user.UserID).
+			Msg("Failed to publish organization.member.added event")
+	}
+
+	return s.saveProvisioning(ctx, orgID, member, provisioning)
+}
+
+// ReplaceUser replaces the mutable attributes of a provisioned user
+func (s *SCIMService) ReplaceUser(ctx context.Context, orgID, id string, req models.SCIMUser) (*models.SCIMMember, error) {
+	member, err := s.GetUser(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName := scimNames(req)
+	if firstName == "" || lastName == "" {
+		return nil, ErrSCIMInvalidValue
+	}
+
+	profile := *member.User
+	profile.FirstName = firstName
+	profile.LastName = lastName
+	profile.JobTitle = req.Title
+
+	provisioning := member.Member
+	provisioning.ExternalID = req.ExternalID
+	if req.Active != nil {
+		provisioning.Suspended = !*req.Active
+	}
+
+	return s.saveMember(ctx, orgID, member, &profile, provisioning)
+}
+
+// PatchUser applies SCIM PATCH operations to a provisioned user
+func (s *SCIMService) PatchUser(ctx context.Context, orgID, id string, req scim.PatchRequest) (*models.SCIMMember, error) {
+	member, err := s.GetUser(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := *member.User
+	provisioning := member.Member
+	for _, op := range req.Operations {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			// Azure AD sends path-less operations with a map of attributes
+			if op.Path == "" {
+				values, ok := op.Value.(map[string]interface{})
+				if !ok {
+					return nil, ErrSCIMInvalidValue
+				}
+				for path, value := range values {
+					if err := applySCIMUserAttribute(&profile, &provisioning, path, value); err != nil {
+						return nil, err
+					}
+				}
+				continue
+			}
+			if err := applySCIMUserAttribute(&profile, &provisioning, op.Path, op.Value); err != nil {
+				return nil, err
+			}
+		case "remove":
+			if err := applySCIMUserAttribute(&profile, &provisioning, op.Path, nil); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, ErrSCIMInvalidValue
+		}
+	}
+
+	return s.saveMember(ctx, orgID, member, &profile, provisioning)
+}
+
+// DeleteUser deprovisions a user from an organization and its teams
+func (s *SCIMService) DeleteUser(ctx context.Context, orgID, id string) error {
+	user, err := s.GetUser(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for SCIM deprovisioning")
+		return err
+	}
+
+	// Never deprovision the last owner, the organization would become unmanageable
+	if org.HasRole(user.UserID, models.OrgRoleOwner) {
+		ownerCount := 0
+		for _, m := range org.Members {
+			if m.Role == models.OrgRoleOwner {
+				ownerCount++
+			}
+		}
+		if ownerCount <= 1 {
+			return ErrSCIMInvalidValue
+		}
+	}
+
+	// Remove the user from the organization's teams
+	teams, _, err := s.teamRepo.FindTeams(ctx, bson.M{
+		"organizationId": orgID,
+		"members.userId": user.UserID,
+	}, 0, 0)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", user.UserID).
+			Msg("Failed to get teams for SCIM deprovisioning")
+		return err
+	}
+	for _, team := range teams {
+		if err := s.teamRepo.RemoveMember(ctx, team.ID, user.UserID); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", user.UserID).
+				Msg("Failed to remove SCIM user from team")
+			continue
+		}
+		if err := s.userRepo.RemoveTeamFromUser(ctx, user.UserID, team.ID); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", user.UserID).
+				Msg("Failed to remove team from SCIM user")
+		}
+	}
+
+	// Remove the user from the organization
+	if err := s.orgRepo.RemoveMember(ctx, orgID, user.UserID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", user.UserID).
+			Msg("Failed to remove SCIM user from organization")
+		return err
+	}
+
+	if err := s.userRepo.RemoveOrganizationFromUser(ctx, user.UserID, orgID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", user.UserID).
+			Msg("Failed to remove organization from SCIM user")
+		// Don't fail the operation, but log the error
+	}
+	s.sync.RecordMemberRemoval(ctx, orgID, user.UserID)
+
+	// Free the member's seat
+	if member := org.GetMember(user.UserID); member != nil && member.Licensed {
+		publishSeatChanged(ctx, s.events, kafka.SeatUnassigned, org, user.UserID, "scim", org.LicensedMembers()-1)
+	}
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.OrganizationMemberRemoved,
+		kafka.OrganizationMemberRemovedV1{
+			OrgID:     org.ID,
+			OrgName:   org.Name,
+			UserID:    user.UserID,
+			RemovedBy: "scim",
+			RemovedAt: time.Now(),
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", user.UserID).
+			Msg("Failed to publish organization.member.removed event")
+	}
+
+	return nil
+}
+
+// ListGroups lists the teams of an organization matching a SCIM filter
+func (s *SCIMService) ListGroups(ctx context.Context, orgID string, filter *scim.Filter, startIndex, count int) ([]*models.Team, int64, error) {
+	query, err := buildSCIMQuery(filter, scimGroupAttributes, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	query["organizationId"] = orgID
+
+	return s.teamRepo.FindTeams(ctx, query, int64(startIndex-1), int64(count))
+}
+
+// GetGroup gets a team of an organization by SCIM id
+func (s *SCIMService) GetGroup(ctx context.Context, orgID, id string) (*models.Team, error) {
+	team, err := s.teamRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrSCIMNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get SCIM group")
+		return nil, err
+	}
+
+	if team.OrganizationID != orgID {
+		return nil, ErrSCIMNotFound
+	}
+
+	return team, nil
+}
+
+// CreateGroup provisions a team into an organization. The team is owned by
+// the organization creator so it stays manageable from the application.
+func (s *SCIMService) CreateGroup(ctx context.Context, orgID string, req models.SCIMGroup) (*models.Team, error) {
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for SCIM group")
+		return nil, err
+	}
+
+	// Check name conflict
+	existingTeam, err := s.teamRepo.GetByNameAndOrganization(ctx, req.DisplayName, orgID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if existingTeam != nil {
+		return nil, ErrSCIMConflict
+	}
+
+	// Create team
+	team := models.NewTeam(models.CreateTeamRequest{
+		Name:           req.DisplayName,
+		OrganizationID: orgID,
+	}, org.CreatedBy)
+	team.ExternalID = req.ExternalID
+
+	members, err := s.resolveGroupMembers(ctx, org, req.Members)
+	if err != nil {
+		return nil, err
+	}
+	for _, userID := range members {
+		team.AddMember(userID, models.TeamRoleMember, "scim")
+	}
+
+	if err := s.teamRepo.Create(ctx, team); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to create SCIM group")
+		return nil, err
+	}
+
+	// Link team to organization and members
+	if err := s.orgRepo.AddTeam(ctx, orgID, team.ID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("orgId", orgID).
+			Msg("Failed to add SCIM group to organization")
+	}
+	for _, member := range team.Members {
+		if err := s.userRepo.AddTeamToUser(ctx, member.UserID, team.ID); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", member.UserID).
+				Msg("Failed to add team to user")
+		}
+	}
+
+	// Publish event
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamCreated,
+		team.ToResponse(false),
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to publish team.created event")
+	}
+
+	return team, nil
+}
+
+// ReplaceGroup replaces the name and membership of a provisioned team
+func (s *SCIMService) ReplaceGroup(ctx context.Context, orgID, id string, req models.SCIMGroup) (*models.Team, error) {
+	team, err := s.GetGroup(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.resolveGroupMembers(ctx, org, req.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	team.Name = req.DisplayName
+	team.ExternalID = req.ExternalID
+
+	return s.saveGroupMembers(ctx, team, members, nil, true)
+}
+
+// PatchGroup applies SCIM PATCH operations to a provisioned team
+func (s *SCIMService) PatchGroup(ctx context.Context, orgID, id string, req scim.PatchRequest) (*models.Team, error) {
+	team, err := s.GetGroup(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var added, removed []string
+	replaceMembers := false
+
+	for _, op := range req.Operations {
+		path := strings.ToLower(op.Path)
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			switch {
+			case path == "displayname":
+				name, ok := op.Value.(string)
+				if !ok || len(name) < 3 || len(name) > 50 {
+					return nil, ErrSCIMInvalidValue
+				}
+				team.Name = name
+			case path == "externalid":
+				externalID, _ := op.Value.(string)
+				team.ExternalID = externalID
+			case path == "members":
+				userIDs, err := s.resolveGroupMembers(ctx, org, scimMemberValues(op.Value))
+				if err != nil {
+					return nil, err
+				}
+				if strings.EqualFold(op.Op, "replace") {
+					replaceMembers = true
+					added = userIDs
+				} else {
+					added = append(added, userIDs...)
+				}
+			case path == "":
+				values, ok := op.Value.(map[string]interface{})
+				if !ok {
+					return nil, ErrSCIMInvalidValue
+				}
+				if name, ok := values["displayName"].(string); ok {
+					team.Name = name
+				}
+				if externalID, ok := values["externalId"].(string); ok {
+					team.ExternalID = externalID
+				}
+			default:
+				return nil, ErrSCIMInvalidValue
+			}
+		case "remove":
+			switch {
+			case path == "members" && op.Value == nil:
+				replaceMembers = true
+				added = nil
+			case path == "members":
+				for _, member := range scimMemberValues(op.Value) {
+					removed = append(removed, member.Value)
+				}
+			case strings.HasPrefix(path, "members["):
+				userID, ok := parseSCIMMemberPath(op.Path)
+				if !ok {
+					return nil, ErrSCIMInvalidValue
+				}
+				removed = append(removed, userID)
+			default:
+				return nil, ErrSCIMInvalidValue
+			}
+		default:
+			return nil, ErrSCIMInvalidValue
+		}
+	}
+
+	return s.saveGroupMembers(ctx, team, added, removed, replaceMembers)
+}
+
+// DeleteGroup deletes a provisioned team
+func (s *SCIMService) DeleteGroup(ctx context.Context, orgID, id string) error {
+	team, err := s.GetGroup(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.teamRepo.Delete(ctx, team.ID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", team.ID).Msg("Failed to delete SCIM group")
+		return err
+	}
+
+	s.sync.RecordDeletion(ctx, models.SyncTeam, team.ID, orgID, team.MemberIDs())
+
+	if err := s.orgRepo.RemoveTeam(ctx, orgID, team.ID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("orgId", orgID).
+			Msg("Failed to remove SCIM group from organization")
+	}
+
+	for _, member := range team.Members {
+		if err := s.userRepo.RemoveTeamFromUser(ctx, member.UserID, team.ID); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", member.UserID).
+				Msg("Failed to remove team from user")
+		}
+	}
+
+	// Publish event
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamDeleted,
+		models.TeamResponse{
+			ID:             team.ID,
+			Name:           team.Name,
+			OrganizationID: team.OrganizationID,
+		},
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to publish team.deleted event")
+	}
+
+	return nil
+}
+
+// saveUser persists a user changed through SCIM and publishes an update event
+func (s *SCIMService) saveUser(ctx context.Context, user *models.User) (*models.User, error) {
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to update SCIM user")
+		return nil, err
+	}
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.UserUpdated,
+		user.ToResponse(),
+		user.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to publish user.updated event")
+	}
+
+	return user, nil
+}
+
+// saveMember saves the changes SCIM made to a member's profile and
+// provisioning. The profile is the user's own across organizations, so it's
+// only changed for users who belong to no other organization; for others,
+// only the membership changes.
+func (s *SCIMService) saveMember(ctx context.Context, orgID string, member *models.SCIMMember, profile *models.User, provisioning models.OrganizationMember) (*models.SCIMMember, error) {
+	if profile.FirstName != member.FirstName || profile.LastName != member.LastName || profile.JobTitle != member.JobTitle {
+		if member.BelongsOnlyTo(orgID) {
+			user, err := s.saveUser(ctx, profile)
+			if err != nil {
+				return nil, err
+			}
+			member.User = user
+		} else {
+			logger.Ctx(ctx).Info().Str("orgId", orgID).Str("userId", member.UserID).
+				Msg("Ignoring SCIM profile changes of a user in other organizations")
+		}
+	}
+
+	return s.saveProvisioning(ctx, orgID, member, provisioning)
+}
+
+// saveProvisioning records a member's external ID and whether the identity
+// provider suspended them, if either changed
+func (s *SCIMService) saveProvisioning(ctx context.Context, orgID string, member *models.SCIMMember, provisioning models.OrganizationMember) (*models.SCIMMember, error) {
+	if provisioning.ExternalID == member.Member.ExternalID && provisioning.Suspended == member.Member.Suspended {
+		return member, nil
+	}
+
+	if err := s.orgRepo.SetMemberProvisioning(ctx, orgID, member.UserID, provisioning.ExternalID, provisioning.Suspended); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", member.UserID).
+			Msg("Failed to update SCIM member")
+		return nil, err
+	}
+	member.Member.ExternalID = provisioning.ExternalID
+	member.Member.Suspended = provisioning.Suspended
+
+	return member, nil
+}
+
+// controlsEmailDomain checks if an organization verified that it controls
+// the domain of an email address
+func (s *SCIMService) controlsEmailDomain(ctx context.Context, orgID, email string) (bool, error) {
+	verification, err := s.verificationRepo.GetApproved(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to get verification for SCIM user")
+		return false, err
+	}
+	return verification.CoversEmail(email), nil
+}
+
+// saveGroupMembers applies membership changes to a team. Owners and admins
+// are never removed by SCIM so the team stays manageable in the application.
+func (s *SCIMService) saveGroupMembers(ctx context.Context, team *models.Team, added, removed []string, replace bool) (*models.Team, error) {
+	previous := make(map[string]bool, len(team.Members))
+	for _, member := range team.Members {
+		previous[member.UserID] = true
+	}
+
+	if replace {
+		keep := make(map[string]bool, len(added))
+		for _, userID := range added {
+			keep[userID] = true
+		}
+		for _, member := range team.Members {
+			if !keep[member.UserID] && member.Role != models.TeamRoleOwner && member.Role != models.TeamRoleAdmin {
+				removed = append(removed, member.UserID)
+			}
+		}
+	}
+
+	for _, userID := range added {
+		if !team.IsMember(userID) {
+			team.AddMember(userID, models.TeamRoleMember, "scim")
+		}
+	}
+	for _, userID := range removed {
+		if team.HasRole(userID, models.TeamRoleOwner, models.TeamRoleAdmin) {
+			continue
+		}
+		team.RemoveMember(userID)
+	}
+
+	// Archived teams keep their members
+	if team.IsArchived() {
+		changed := len(team.Members) != len(previous)
+		for _, member := range team.Members {
+			changed = changed || !previous[member.UserID]
+		}
+		if changed {
+			return nil, ErrTeamArchived
+		}
+	}
+
+	if err := s.teamRepo.Update(ctx, team); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", team.ID).Msg("Failed to update SCIM group")
+		return nil, err
+	}
+
+	// Keep the users' team references in sync
+	current := make(map[string]bool, len(team.Members))
+	for _, member := range team.Members {
+		current[member.UserID] = true
+		if !previous[member.UserID] {
+			if err := s.userRepo.AddTeamToUser(ctx, member.UserID, team.ID); err != nil {
+				logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", member.UserID).
+					Msg("Failed to add team to user")
+			}
+		}
+	}
+	for userID := range previous {
+		if !current[userID] {
+			if err := s.userRepo.RemoveTeamFromUser(ctx, userID, team.ID); err != nil {
+				logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", userID).
+					Msg("Failed to remove team from user")
+			}
+		}
+	}
+
+	// Publish event
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamUpdated,
+		team.ToResponse(false),
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to publish team.updated event")
+	}
+
+	return team, nil
+}
+
+// resolveGroupMembers validates that SCIM group members belong to the organization
+func (s *SCIMService) resolveGroupMembers(ctx context.Context, org *models.Organization, members []models.SCIMMultiAttr) ([]string, error) {
+	userIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		if !org.IsMember(member.Value) {
+			logger.Ctx(ctx).Warn().Str("orgId", org.ID).Str("userId", member.Value).
+				Msg("SCIM group member is not a member of the organization")
+			return nil, ErrSCIMInvalidValue
+		}
+		userIDs = append(userIDs, member.Value)
+	}
+	return userIDs, nil
+}
+
+// applySCIMUserAttribute sets a single SCIM attribute on a user's profile or
+// membership; a nil value clears it
+func applySCIMUserAttribute(user *models.User, member *models.OrganizationMember, path string, value interface{}) error {
+	str, _ := value.(string)
+
+	switch strings.ToLower(path) {
+	case "active":
+		switch active := value.(type) {
+		case bool:
+			member.Suspended = !active
+		case string:
+			// Some providers send booleans as strings
+			member.Suspended = !strings.EqualFold(active, "true")
+		default:
+			return ErrSCIMInvalidValue
+		}
+	case "name.givenname":
+		if str == "" {
+			return ErrSCIMInvalidValue
+		}
+		user.FirstName = str
+	case "name.familyname":
+		if str == "" {
+			return ErrSCIMInvalidValue
+		}
+		user.LastName = str
+	case "name":
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return ErrSCIMInvalidValue
+		}
+		if givenName, ok := values["givenName"].(string); ok && givenName != "" {
+			user.FirstName = givenName
+		}
+		if familyName, ok := values["familyName"].(string); ok && familyName != "" {
+			user.LastName = familyName
+		}
+	case "title":
+		user.JobTitle = str
+	case "externalid":
+		member.ExternalID = str
+	case "displayname":
+		// Display name is derived from the first and last name
+	default:
+		return ErrSCIMInvalidValue
+	}
+
+	return nil
+}
+
+// buildSCIMQuery converts a parsed SCIM filter into a MongoDB query.
+// Comparisons of membership attributes are resolved against members, to the
+// users whose membership matches.
+func buildSCIMQuery(filter *scim.Filter, attributes map[string]string, members []models.OrganizationMember) (bson.M, error) {
+	if filter.IsEmpty() {
+		return bson.M{}, nil
+	}
+
+	groups := make([]bson.M, 0, len(filter.Groups))
+	for _, group := range filter.Groups {
+		clauses := make([]bson.M, 0, len(group))
+		for _, comparison := range group {
+			field, ok := attributes[strings.ToLower(comparison.Attribute)]
+			if !ok {
+				return nil, ErrSCIMUnsupportedFilter
+			}
+
+			if strings.HasPrefix(field, scimMemberField) {
+				userIDs := matchSCIMMembers(members, strings.TrimPrefix(field, scimMemberField), comparison)
+				clauses = append(clauses, bson.M{"userId": bson.M{"$in": userIDs}})
+				continue
+			}
+
+			value := comparison.Value
+
+			var condition interface{}
+			switch comparison.Operator {
+			case scim.OpEqual:
+				if field == "email" {
+					condition = bson.M{"$regex": "^" + regexp.QuoteMeta(value) + "$", "$options": "i"}
+				} else {
+					condition = value
+				}
+			case scim.OpNotEqual:
+				condition = bson.M{"$ne": value}
+			case scim.OpContains:
+				condition = bson.M{"$regex": regexp.QuoteMeta(value), "$options": "i"}
+			case scim.OpStartsWith:
+				condition = bson.M{"$regex": "^" + regexp.QuoteMeta(value), "$options": "i"}
+			case scim.OpEndsWith:
+				condition = bson.M{"$regex": regexp.QuoteMeta(value) + "$", "$options": "i"}
+			case scim.OpPresent:
+				condition = bson.M{"$exists": true, "$nin": []interface{}{"", nil}}
+			}
+
+			clauses = append(clauses, bson.M{field: condition})
+		}
+		groups = append(groups, bson.M{"$and": clauses})
+	}
+
+	if len(groups) == 1 {
+		return groups[0], nil
+	}
+	return bson.M{"$or": groups}, nil
+}
+
+// scimNames extracts first and last names from a SCIM user
+func scimNames(req models.SCIMUser) (string, string) {
+	if req.Name != nil && req.Name.GivenName != "" && req.Name.FamilyName != "" {
+		return req.Name.GivenName, req.Name.FamilyName
+	}
+
+	// Fall back to splitting the display name
+	parts := strings.Fields(req.DisplayName)
+	if len(parts) >= 2 {
+		return parts[0], strings.Join(parts[1:], " ")
+	}
+
+	return "", ""
+}
+
+// matchSCIMMembers gets the users whose membership field matches a SCIM
+// comparison
+func matchSCIMMembers(members []models.OrganizationMember, field string, comparison scim.Comparison) []string {
+	expected := comparison.Value
+	if field == "active" {
+		expected = strconv.FormatBool(strings.EqualFold(expected, "true"))
+	}
+
+	userIDs := make([]string, 0)
+	for _, member := range members {
+		actual := member.ExternalID
+		if field == "active" {
+			actual = strconv.FormatBool(!member.Suspended)
+		}
+
+		var matches bool
+		switch comparison.Operator {
+		case scim.OpEqual:
+			matches = actual == expected
+		case scim.OpNotEqual:
+			matches = actual != expected
+		case scim.OpContains:
+			matches = strings.Contains(strings.ToLower(actual), strings.ToLower(expected))
+		case scim.OpStartsWith:
+			matches = strings.HasPrefix(strings.ToLower(actual), strings.ToLower(expected))
+		case scim.OpEndsWith:
+			matches = strings.HasSuffix(strings.ToLower(actual), strings.ToLower(expected))
+		case scim.OpPresent:
+			matches = actual != ""
+		}
+		if matches {
+			userIDs = append(userIDs, member.UserID)
+		}
+	}
+	return userIDs
+}
+
+// scimMemberValues converts a SCIM PATCH value to a list of member references
+func scimMemberValues(value interface{}) []models.SCIMMultiAttr {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	members := make([]models.SCIMMultiAttr, 0, len(items))
+	for _, item := range items {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id, ok := entry["value"].(string); ok && id != "" {
+			members = append(members, models.SCIMMultiAttr{Value: id})
+		}
+	}
+	return members
+}
+
+// scimMemberPathRegex matches paths like members[value eq "user-id"]
+var scimMemberPathRegex = regexp.MustCompile(`(?i)^members\[value eq "([^"]+)"\]$`)
+
+// parseSCIMMemberPath extracts the member ID from a value-filtered members path
+func parseSCIMMemberPath(path string) (string, bool) {
+	matches := scimMemberPathRegex.FindStringSubmatch(strings.TrimSpace(path))
+	if len(matches) != 2 {
+		return "", false
+	}
+	return matches[1], true
+}
+
+// hashSCIMToken hashes a SCIM token for storage and lookup
+func hashSCIMToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// containsString checks if a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
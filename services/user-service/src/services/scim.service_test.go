@@ -0,0 +1,247 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/scim"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"github.com/your-username/slido-clone/user-service/services"
+	"github.com/your-username/slido-clone/user-service/testsupport"
+)
+
+// newSCIMService creates a SCIM service over the fixtures
+func newSCIMService(f *testsupport.Fixtures) *services.SCIMService {
+	syncService := services.NewSyncService(f.Users, f.Teams, f.Orgs, repositories.NewTombstoneRepository(f.Store), &config.SyncConfig{})
+	orgService := services.NewOrganizationService(f.Orgs, f.Users, f.Teams,
+		repositories.NewJoinRequestRepository(f.Store), repositories.NewSettingsHistoryRepository(f.Store),
+		f.Kafka, syncService, nil, &config.OrganizationConfig{})
+	return services.NewSCIMService(repositories.NewSCIMTokenRepository(f.Store), f.Users, f.Teams, f.Orgs,
+		repositories.NewVerificationRepository(f.Store), orgService, f.Kafka, syncService)
+}
+
+func TestSCIMCreateGroupThenPatch(t *testing.T) {
+	f := testsupport.New(t)
+	scimService := newSCIMService(f)
+
+	owner := f.SeedUser()
+	member := f.SeedUser()
+	org := f.SeedOrganization(owner)
+	f.SeedMember(org, member, models.OrgRoleMember)
+	// SCIM requests act on the organization of their token
+	ctx := tenancy.WithOrganization(context.Background(), org.ID)
+
+	team, err := scimService.CreateGroup(ctx, org.ID, models.SCIMGroup{DisplayName: "Engineering"})
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+
+	// The group is found by the ID it was created with
+	if _, err := scimService.GetGroup(ctx, org.ID, team.ID); err != nil {
+		t.Fatalf("GetGroup(%s): %v", team.ID, err)
+	}
+
+	patched, err := scimService.PatchGroup(ctx, org.ID, team.ID, scim.PatchRequest{
+		Operations: []scim.PatchOperation{
+			{Op: "replace", Path: "displayName", Value: "Platform Engineering"},
+			{Op: "add", Path: "members", Value: []interface{}{map[string]interface{}{"value": member.UserID}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("PatchGroup: %v", err)
+	}
+	if patched.Name != "Platform Engineering" {
+		t.Errorf("patched name = %q, want %q", patched.Name, "Platform Engineering")
+	}
+
+	stored, err := scimService.GetGroup(ctx, org.ID, team.ID)
+	if err != nil {
+		t.Fatalf("GetGroup after patch: %v", err)
+	}
+	if stored.Name != "Platform Engineering" || !stored.IsMember(member.UserID) {
+		t.Errorf("stored group = %q with members %v, want the patched name and member %s",
+			stored.Name, stored.MemberIDs(), member.UserID)
+	}
+
+	user, err := f.Users.GetByUserId(ctx, member.UserID)
+	if err != nil {
+		t.Fatalf("GetByUserId: %v", err)
+	}
+	if !containsID(user.TeamIDs, team.ID) {
+		t.Errorf("user teams = %v, want them to include %s", user.TeamIDs, team.ID)
+	}
+
+	if got := len(f.Kafka.PublishedOfType(kafka.TeamUpdated)); got != 1 {
+		t.Errorf("published %d team.updated events, want 1", got)
+	}
+}
+
+func TestSCIMCreateUserLinksOnlyVerifiedDomainUsers(t *testing.T) {
+	f := testsupport.New(t)
+	scimService := newSCIMService(f)
+
+	owner := f.SeedUser()
+	org := f.SeedOrganization(owner)
+	// SCIM requests act on the organization of their token
+	ctx := tenancy.WithOrganization(context.Background(), org.ID)
+	outsider := f.SeedUser(func(u *models.User) { u.Email = "outsider@other.com" })
+	employee := f.SeedUser(func(u *models.User) { u.Email = "employee@eng.acme.com" })
+
+	// Without a verified domain, existing users aren't the organization's to link
+	_, err := scimService.CreateUser(ctx, org.ID, models.SCIMUser{UserName: employee.Email})
+	if !errors.Is(err, services.ErrSCIMUserNotLinkable) {
+		t.Fatalf("CreateUser(%s) without a verified domain: err = %v, want ErrSCIMUserNotLinkable", employee.Email, err)
+	}
+
+	verification := models.NewOrganizationVerification("verification-1", org.ID, models.CreateVerificationRequest{
+		Method: models.VerificationDomain,
+		Domain: "acme.com",
+	}, "txt", owner.UserID)
+	verification.Status = models.VerificationApproved
+	if err := repositories.NewVerificationRepository(f.Store).Create(ctx, verification); err != nil {
+		t.Fatalf("creating verification: %v", err)
+	}
+
+	_, err = scimService.CreateUser(ctx, org.ID, models.SCIMUser{UserName: outsider.Email})
+	if !errors.Is(err, services.ErrSCIMUserNotLinkable) {
+		t.Fatalf("CreateUser(%s) outside the verified domain: err = %v, want ErrSCIMUserNotLinkable", outsider.Email, err)
+	}
+
+	active := false
+	linked, err := scimService.CreateUser(ctx, org.ID, models.SCIMUser{
+		UserName:   employee.Email,
+		ExternalID: "okta-42",
+		Active:     &active,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser(%s) of a subdomain of the verified domain: %v", employee.Email, err)
+	}
+	if linked.UserID != employee.UserID {
+		t.Errorf("linked user = %s, want %s", linked.UserID, employee.UserID)
+	}
+
+	// The external ID and suspension are the membership's
+	stored, err := f.Orgs.GetByID(ctx, org.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	member := stored.GetMember(employee.UserID)
+	if member == nil || member.ExternalID != "okta-42" || !member.Suspended {
+		t.Fatalf("member = %+v, want external ID okta-42 and suspended", member)
+	}
+	if len(stored.Permissions(employee.UserID)) != 0 {
+		t.Errorf("suspended member permissions = %v, want none", stored.Permissions(employee.UserID))
+	}
+	user, err := f.Users.GetByUserId(ctx, employee.UserID)
+	if err != nil {
+		t.Fatalf("GetByUserId: %v", err)
+	}
+	if user.Status != models.StatusActive {
+		t.Errorf("user status = %s, want %s", user.Status, models.StatusActive)
+	}
+}
+
+func TestSCIMPatchUserKeepsProfileOfUsersInOtherOrganizations(t *testing.T) {
+	f := testsupport.New(t)
+	scimService := newSCIMService(f)
+
+	owner := f.SeedUser()
+	org := f.SeedOrganization(owner)
+	other := f.SeedOrganization(f.SeedUser())
+	// SCIM requests act on the organization of their token
+	ctx := tenancy.WithOrganization(context.Background(), org.ID)
+	shared := f.SeedUser()
+	f.SeedMember(org, shared, models.OrgRoleMember)
+	f.SeedMember(other, shared, models.OrgRoleMember)
+	own := f.SeedUser()
+	f.SeedMember(org, own, models.OrgRoleMember)
+
+	patch := scim.PatchRequest{
+		Operations: []scim.PatchOperation{
+			{Op: "replace", Path: "name.givenName", Value: "Mallory"},
+			{Op: "replace", Path: "title", Value: "Intern"},
+			{Op: "replace", Path: "active", Value: false},
+		},
+	}
+
+	patched, err := scimService.PatchUser(ctx, org.ID, shared.UserID, patch)
+	if err != nil {
+		t.Fatalf("PatchUser(%s): %v", shared.UserID, err)
+	}
+	if !patched.Member.Suspended {
+		t.Errorf("patched member isn't suspended")
+	}
+	user, err := f.Users.GetByUserId(ctx, shared.UserID)
+	if err != nil {
+		t.Fatalf("GetByUserId: %v", err)
+	}
+	if user.FirstName != shared.FirstName || user.JobTitle != shared.JobTitle || user.Status != models.StatusActive {
+		t.Errorf("user in another organization = %s, %q, %s; want their profile and status unchanged",
+			user.FirstName, user.JobTitle, user.Status)
+	}
+
+	// Users of the organization alone have their profile changed
+	if _, err := scimService.PatchUser(ctx, org.ID, own.UserID, patch); err != nil {
+		t.Fatalf("PatchUser(%s): %v", own.UserID, err)
+	}
+	user, err = f.Users.GetByUserId(ctx, own.UserID)
+	if err != nil {
+		t.Fatalf("GetByUserId: %v", err)
+	}
+	if user.FirstName != "Mallory" || user.JobTitle != "Intern" || user.Status != models.StatusActive {
+		t.Errorf("user of the organization alone = %s, %q, %s; want Mallory, Intern and an unchanged status",
+			user.FirstName, user.JobTitle, user.Status)
+	}
+
+	// Filters on active match the membership
+	filter, err := scim.ParseFilter(`active eq false`)
+	if err != nil {
+		t.Fatalf("ParseFilter: %v", err)
+	}
+	members, _, err := scimService.ListUsers(ctx, org.ID, filter, 1, 10)
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if len(members) != 2 {
+		t.Errorf("ListUsers(active eq false) = %d users, want 2", len(members))
+	}
+}
+
+func TestSCIMCreateUserChecksJoinPolicies(t *testing.T) {
+	f := testsupport.New(t)
+	scimService := newSCIMService(f)
+
+	org := f.SeedOrganization(f.SeedUser(), func(o *models.Organization) {
+		o.Settings.BlockedEmailDomains = []string{"blocked.com"}
+	})
+	// SCIM requests act on the organization of their token
+	ctx := tenancy.WithOrganization(context.Background(), org.ID)
+
+	_, err := scimService.CreateUser(ctx, org.ID, models.SCIMUser{
+		UserName: "new@blocked.com",
+		Name:     &models.SCIMName{GivenName: "New", FamilyName: "User"},
+	})
+	if !errors.Is(err, services.ErrEmailDomainBlocked) {
+		t.Fatalf("CreateUser(new@blocked.com): err = %v, want ErrEmailDomainBlocked", err)
+	}
+
+	// Refused users aren't created either
+	if _, err := f.Users.GetByEmail(ctx, "new@blocked.com"); err == nil {
+		t.Errorf("refused SCIM user was created")
+	}
+}
+
+// containsID checks if a list of IDs contains an ID
+func containsID(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
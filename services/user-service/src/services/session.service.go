@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SessionService is a service for the sessions users are signed in with.
+// Sessions are owned by the Auth Service; this service mirrors them from its
+// events so users can see where they are signed in and sign out remotely.
+type SessionService struct {
+	sessionRepo *repositories.SessionRepository
+	events      kafka.EventPublisher
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(sessionRepo *repositories.SessionRepository, events kafka.EventPublisher) *SessionService {
+	return &SessionService{
+		sessionRepo: sessionRepo,
+		events:      events,
+	}
+}
+
+// GetUserSessions lists a user's active sessions, most recently active first
+func (s *SessionService) GetUserSessions(ctx context.Context, userID string) ([]*models.Session, error) {
+	sessions, err := s.sessionRepo.GetActiveByUser(ctx, userID, time.Now(), models.MaxListedSessions)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get sessions")
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs a user out of one of their sessions. The session is
+// removed from the listing right away and a user.session.revoked event asks
+// the Auth Service to terminate it.
+func (s *SessionService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	err := s.sessionRepo.Delete(ctx, userID, sessionID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrSessionNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("sessionId", sessionID).Msg("Failed to revoke session")
+		return err
+	}
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.UserSessionRevoked,
+		kafka.UserSessionRevokedV1{
+			SessionID: sessionID,
+			UserID:    userID,
+			RevokedAt: time.Now(),
+		},
+		sessionID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("sessionId", sessionID).Msg("Failed to publish user.session.revoked event")
+	}
+
+	logger.Ctx(ctx).Info().Str("sessionId", sessionID).Str("userId", userID).Msg("Session revoked")
+	return nil
+}
+
+// ProcessAuthSessionCreated processes a session.created event from the Auth
+// Service
+func (s *SessionService) ProcessAuthSessionCreated(ctx context.Context, event kafka.Event) error {
+	// Decode and validate data
+	var data kafka.AuthSessionCreatedV1
+	if err := kafka.DecodeData(event, &data); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Interface("data", event.Data).Msg("Invalid auth session.created event")
+		return err
+	}
+
+	createdAt := data.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = event.Time
+	}
+
+	session := &models.Session{
+		ID:           data.ID,
+		UserID:       data.UserID,
+		Device:       data.Device,
+		UserAgent:    data.UserAgent,
+		IPAddress:    data.IPAddress,
+		CreatedAt:    createdAt,
+		LastActiveAt: createdAt,
+		ExpiresAt:    data.ExpiresAt,
+	}
+	if err := s.sessionRepo.Upsert(ctx, session); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("sessionId", data.ID).Msg("Failed to save session from auth event")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("sessionId", data.ID).Str("userId", data.UserID).Msg("Saved session from auth event")
+	return nil
+}
+
+// ProcessAuthSessionRefreshed processes a session.refreshed event from the
+// Auth Service, recording when the session was last active
+func (s *SessionService) ProcessAuthSessionRefreshed(ctx context.Context, event kafka.Event) error {
+	// Decode and validate data
+	var data kafka.AuthSessionRefreshedV1
+	if err := kafka.DecodeData(event, &data); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Interface("data", event.Data).Msg("Invalid auth session.refreshed event")
+		return err
+	}
+
+	refreshedAt := data.RefreshedAt
+	if refreshedAt.IsZero() {
+		refreshedAt = event.Time
+	}
+
+	err := s.sessionRepo.Touch(ctx, data.UserID, data.ID, refreshedAt, data.IPAddress, data.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Debug().Str("sessionId", data.ID).Msg("Skipping auth session.refreshed event for unknown session")
+			return nil
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("sessionId", data.ID).Msg("Failed to update session from auth event")
+		return err
+	}
+
+	return nil
+}
+
+// ProcessAuthSessionTerminated processes a session.terminated event from the
+// Auth Service
+func (s *SessionService) ProcessAuthSessionTerminated(ctx context.Context, event kafka.Event) error {
+	// Decode and validate data
+	var data kafka.AuthSessionTerminatedV1
+	if err := kafka.DecodeData(event, &data); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Interface("data", event.Data).Msg("Invalid auth session.terminated event")
+		return err
+	}
+
+	err := s.sessionRepo.Delete(ctx, data.UserID, data.ID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// Already revoked here, or never seen
+			logger.Ctx(ctx).Debug().Str("sessionId", data.ID).Msg("Session already removed, skipping termination")
+			return nil
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("sessionId", data.ID).Msg("Failed to delete session from auth event")
+		return err
+	}
+
+	logger.Ctx(ctx).Debug().Str("sessionId", data.ID).Str("reason", data.Reason).Msg("Removed session from auth event")
+	return nil
+}
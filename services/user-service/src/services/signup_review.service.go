@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Reasons a signup is held for review
+const (
+	SignupRiskDisposableEmail = "disposable_email_domain"
+	SignupRiskIPBurst         = "signup_burst_from_ip"
+)
+
+// disposableEmailDomains are well-known disposable email providers. The
+// SIGNUP_DISPOSABLE_DOMAINS setting extends this list.
+var disposableEmailDomains = []string{
+	"10minutemail.com",
+	"dispostable.com",
+	"fakeinbox.com",
+	"getnada.com",
+	"guerrillamail.com",
+	"mailinator.com",
+	"maildrop.cc",
+	"mintemail.com",
+	"sharklasers.com",
+	"temp-mail.org",
+	"tempmail.com",
+	"throwawaymail.com",
+	"trashmail.com",
+	"yopmail.com",
+}
+
+// SignupReviewService holds risky signups for review by a platform admin
+type SignupReviewService struct {
+	userRepo          repositories.UserStore
+	events            kafka.EventPublisher
+	config            *config.SignupReviewConfig
+	disposableDomains map[string]bool
+}
+
+// NewSignupReviewService creates a new signup review service
+func NewSignupReviewService(userRepo repositories.UserStore, events kafka.EventPublisher, cfg *config.SignupReviewConfig) *SignupReviewService {
+	domains := make(map[string]bool, len(disposableEmailDomains)+len(cfg.DisposableDomains))
+	for _, domain := range disposableEmailDomains {
+		domains[domain] = true
+	}
+	for _, domain := range cfg.DisposableDomains {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			domains[domain] = true
+		}
+	}
+
+	return &SignupReviewService{
+		userRepo:          userRepo,
+		events:            events,
+		config:            cfg,
+		disposableDomains: domains,
+	}
+}
+
+// Assess returns the reasons a signup should be held for review, or nil if
+// it looks legitimate. Heuristics that can't be evaluated don't flag the signup.
+func (s *SignupReviewService) Assess(ctx context.Context, email, ip string) []string {
+	if !s.config.Enabled {
+		return nil
+	}
+
+	var reasons []string
+
+	// Disposable email domains, including their subdomains
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		domain := strings.ToLower(email[at+1:])
+		for domain != "" {
+			if s.disposableDomains[domain] {
+				reasons = append(reasons, SignupRiskDisposableEmail)
+				break
+			}
+			dot := strings.Index(domain, ".")
+			if dot < 0 {
+				break
+			}
+			domain = domain[dot+1:]
+		}
+	}
+
+	// Burst of signups from one IP address
+	if ip != "" && s.config.BurstThreshold > 0 {
+		count, err := s.userRepo.CountSignupsFromIP(ctx, ip, time.Now().Add(-s.config.BurstWindow))
+		if err != nil {
+			logger.Ctx(ctx).Warn().Err(err).Str("ip", ip).Msg("Failed to check signup burst, skipping heuristic")
+		} else if count+1 > int64(s.config.BurstThreshold) {
+			reasons = append(reasons, SignupRiskIPBurst)
+		}
+	}
+
+	return reasons
+}
+
+// Hold marks a new user as pending review. It must be called before the user is saved.
+func (s *SignupReviewService) Hold(user *models.User, reasons []string) {
+	user.Status = models.StatusPendingReview
+	user.SignupReview = &models.SignupReview{
+		Reasons:   reasons,
+		FlaggedAt: time.Now(),
+	}
+}
+
+// Flagged publishes the event for a user that was held for review
+func (s *SignupReviewService) Flagged(ctx context.Context, user *models.User) {
+	s.publish(ctx, user, kafka.UserSignupFlagged)
+}
+
+// GetQueue gets the users pending review, oldest first
+func (s *SignupReviewService) GetQueue(ctx context.Context, page, limit int) ([]*models.User, int64, error) {
+	users, total, err := s.userRepo.FindUsers(
+		ctx,
+		bson.M{"status": models.StatusPendingReview},
+		int64((page-1)*limit),
+		int64(limit),
+	)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Failed to get signup review queue")
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// Approve activates a user held for review
+func (s *SignupReviewService) Approve(ctx context.Context, userID string, req models.ReviewSignupRequest, reviewedBy string) (*models.User, error) {
+	return s.resolve(ctx, userID, models.SignupReviewApproved, models.StatusActive, req, reviewedBy, kafka.UserSignupApproved)
+}
+
+// Reject deactivates a user held for review
+func (s *SignupReviewService) Reject(ctx context.Context, userID string, req models.ReviewSignupRequest, reviewedBy string) (*models.User, error) {
+	return s.resolve(ctx, userID, models.SignupReviewRejected, models.StatusInactive, req, reviewedBy, kafka.UserSignupRejected)
+}
+
+// resolve records a review decision and moves the user out of the queue
+func (s *SignupReviewService) resolve(
+	ctx context.Context,
+	userID string,
+	decision models.SignupReviewDecision,
+	status models.UserStatus,
+	req models.ReviewSignupRequest,
+	reviewedBy string,
+	eventType kafka.EventType,
+) (*models.User, error) {
+	user, err := s.userRepo.GetByUserId(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user for signup review")
+		return nil, err
+	}
+	if !user.IsPendingReview() {
+		return nil, apperrors.Conflict("USER_NOT_PENDING_REVIEW", "user is not pending review")
+	}
+
+	now := time.Now()
+	review := &models.SignupReview{FlaggedAt: user.CreatedAt}
+	if user.SignupReview != nil {
+		*review = *user.SignupReview
+	}
+	review.Decision = decision
+	review.ReviewedBy = reviewedBy
+	review.Reason = req.Reason
+	review.ReviewedAt = &now
+
+	// Conditional on the user still pending, so concurrent reviews can't both win
+	if err := s.userRepo.ResolveSignupReview(ctx, userID, status, review); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.Conflict("USER_NOT_PENDING_REVIEW", "user is not pending review")
+		}
+		return nil, err
+	}
+
+	user.Status = status
+	user.SignupReview = review
+	user.UpdatedAt = now
+
+	s.publish(ctx, user, eventType)
+	return user, nil
+}
+
+// publish publishes a signup review event
+func (s *SignupReviewService) publish(ctx context.Context, user *models.User, eventType kafka.EventType) {
+	payload := kafka.SignupReviewV1{
+		UserID:   user.UserID,
+		Email:    user.Email,
+		SignupIP: user.SignupIP,
+		Status:   string(user.Status),
+	}
+	if r := user.SignupReview; r != nil {
+		payload.Reasons = r.Reasons
+		payload.FlaggedAt = r.FlaggedAt
+		payload.Decision = string(r.Decision)
+		payload.ReviewedBy = r.ReviewedBy
+		payload.Reason = r.Reason
+		payload.ReviewedAt = r.ReviewedAt
+	}
+
+	if err := s.events.PublishUserEvent(ctx, eventType, payload, user.ID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msgf("Failed to publish %s event", eventType)
+	}
+}
@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/cache"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// StatsService computes usage statistics with aggregation pipelines. The
+// aggregations are expensive, so results are cached for the configured TTL.
+type StatsService struct {
+	orgRepo        repositories.OrgStore
+	userRepo       repositories.UserStore
+	teamRepo       repositories.TeamStore
+	timelineRepo   *repositories.TimelineRepository
+	eventCountRepo *repositories.EventCountRepository
+	config         *config.StatsConfig
+
+	cache *cache.TTL[string, any]
+}
+
+// NewStatsService creates a new stats service
+func NewStatsService(
+	orgRepo repositories.OrgStore,
+	userRepo repositories.UserStore,
+	teamRepo repositories.TeamStore,
+	timelineRepo *repositories.TimelineRepository,
+	eventCountRepo *repositories.EventCountRepository,
+	cfg *config.StatsConfig,
+) *StatsService {
+	return &StatsService{
+		orgRepo:        orgRepo,
+		userRepo:       userRepo,
+		teamRepo:       teamRepo,
+		timelineRepo:   timelineRepo,
+		eventCountRepo: eventCountRepo,
+		config:         cfg,
+		cache:          cache.New[string, any](cfg.CacheTTL),
+	}
+}
+
+// ParseStatsQuery parses the interval and RFC 3339 range of a statistics
+// time series. The range defaults to the last DefaultStatsBuckets intervals
+// and is aligned to whole intervals, so requests within the same interval
+// share cached results.
+func ParseStatsQuery(interval, from, to string) (models.StatsQuery, error) {
+	query := models.StatsQuery{Interval: models.StatsInterval(interval)}
+	if query.Interval == "" {
+		query.Interval = models.StatsDaily
+	}
+	if !query.Interval.Valid() {
+		return query, models.ErrInvalidStatsInterval
+	}
+
+	now := time.Now()
+	query.To = now
+	if to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return query, apperrors.InvalidField("to", "to must be an RFC 3339 timestamp")
+		}
+		if t.Before(now) {
+			query.To = t
+		}
+	}
+	query.To = query.Interval.Truncate(query.To)
+
+	query.From = query.Interval.Add(query.To, 1-models.DefaultStatsBuckets)
+	if from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return query, apperrors.InvalidField("from", "from must be an RFC 3339 timestamp")
+		}
+		query.From = query.Interval.Truncate(t)
+	}
+
+	if query.From.After(query.To) {
+		return query, models.ErrInvalidStatsRange
+	}
+	if query.Interval.Add(query.From, models.MaxStatsBuckets-1).Before(query.To) {
+		return query, models.ErrStatsRangeTooLarge
+	}
+
+	return query, nil
+}
+
+// GetOrganizationStats gets the member, team and growth statistics of an
+// organization
+func (s *StatsService) GetOrganizationStats(ctx context.Context, orgID string, query models.StatsQuery, userID string) (*models.OrganizationStats, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for stats")
+		return nil, err
+	}
+
+	// Check permissions - must be an owner or admin
+	if !org.Can(userID, models.PermOrgViewStats) {
+		return nil, insufficientPermissions("view organization statistics")
+	}
+
+	key := fmt.Sprintf("organization|%s|%s", orgID, seriesKey(query))
+	return cached(s, key, func() (*models.OrganizationStats, error) {
+		return s.computeOrganizationStats(ctx, org, query)
+	})
+}
+
+// computeOrganizationStats runs the aggregations of an organization's
+// statistics
+func (s *StatsService) computeOrganizationStats(ctx context.Context, org *models.Organization, query models.StatsQuery) (*models.OrganizationStats, error) {
+	now := time.Now()
+	stats := &models.OrganizationStats{
+		OrganizationID: org.ID,
+		Members: models.OrganizationMemberStats{
+			Total:            int64(len(org.Members)),
+			ActiveWindowDays: int(s.config.ActiveWindow / (24 * time.Hour)),
+			ByRole: map[models.OrganizationMemberRole]int64{
+				models.OrgRoleOwner:  0,
+				models.OrgRoleAdmin:  0,
+				models.OrgRoleMember: 0,
+				models.OrgRoleGuest:  0,
+			},
+		},
+		GeneratedAt: now,
+	}
+	for _, member := range org.Members {
+		stats.Members.ByRole[member.Role]++
+	}
+
+	var err error
+	if _, stats.Members.Active, err = s.userRepo.CountOrganizationMembers(ctx, org.ID, now.Add(-s.config.ActiveWindow)); err != nil {
+		return nil, err
+	}
+	if stats.Teams.Total, stats.Teams.Memberships, err = s.teamRepo.CountOrganizationTeams(ctx, org.ID); err != nil {
+		return nil, err
+	}
+
+	changes, err := s.timelineRepo.CountMembershipChanges(ctx, org.ID,
+		string(kafka.OrganizationMemberAdded), string(kafka.OrganizationMemberRemoved), query.Interval, query.From)
+	if err != nil {
+		return nil, err
+	}
+	stats.Growth = memberGrowth(query, stats.Members.Total, changes)
+
+	return stats, nil
+}
+
+// memberGrowth builds the member growth series of a range from the
+// membership changes since its start, sorted by bucket. The member count at
+// the end of each bucket is worked back from the current count.
+func memberGrowth(query models.StatsQuery, memberCount int64, changes []models.MembershipChangeCount) models.MemberGrowthSeries {
+	starts := query.Interval.Buckets(query.From, query.To)
+	points := make([]models.MemberGrowthPoint, len(starts))
+
+	total := memberCount
+	j := len(changes) - 1
+	for i := len(starts) - 1; i >= 0; i-- {
+		// Undo the changes after this bucket
+		for ; j >= 0 && changes[j].Start.After(starts[i]); j-- {
+			total -= changes[j].Joined - changes[j].Left
+		}
+
+		point := models.MemberGrowthPoint{Start: starts[i], Total: total}
+		if j >= 0 && changes[j].Start.Equal(starts[i]) {
+			point.Joined, point.Left = changes[j].Joined, changes[j].Left
+		}
+		// Members removed without a timeline entry can take the count
+		// below zero
+		if point.Total < 0 {
+			point.Total = 0
+		}
+		points[i] = point
+	}
+
+	return models.MemberGrowthSeries{
+		Interval: query.Interval,
+		From:     query.From,
+		To:       query.Interval.Add(query.To, 1),
+		Points:   points,
+	}
+}
+
+// GetUserStats gets the number of users of the platform by status and role
+func (s *StatsService) GetUserStats(ctx context.Context) (*models.PlatformUserStats, error) {
+	return cached(s, "users", func() (*models.PlatformUserStats, error) {
+		stats := &models.PlatformUserStats{
+			ByStatus:    make(map[models.UserStatus]int64),
+			ByRole:      make(map[models.UserRole]int64),
+			GeneratedAt: time.Now(),
+		}
+
+		byStatus, err := s.userRepo.CountByField(ctx, "status")
+		if err != nil {
+			return nil, err
+		}
+		for _, count := range byStatus {
+			stats.ByStatus[models.UserStatus(count.Key)] = count.Count
+			stats.Total += count.Count
+		}
+
+		byRole, err := s.userRepo.CountByField(ctx, "role")
+		if err != nil {
+			return nil, err
+		}
+		for _, count := range byRole {
+			stats.ByRole[models.UserRole(count.Key)] = count.Count
+		}
+
+		return stats, nil
+	})
+}
+
+// GetOrganizationSizeStats gets the number of organizations of the platform
+// by member count
+func (s *StatsService) GetOrganizationSizeStats(ctx context.Context) (*models.PlatformOrganizationStats, error) {
+	return cached(s, "organizations", func() (*models.PlatformOrganizationStats, error) {
+		counts, err := s.orgRepo.CountBySize(ctx, models.OrganizationSizeBuckets)
+		if err != nil {
+			return nil, err
+		}
+
+		bySize := make(map[string]int64, len(counts))
+		for _, count := range counts {
+			bySize[count.Key] = count.Count
+		}
+
+		// Every bucket is listed, smallest first
+		stats := &models.PlatformOrganizationStats{GeneratedAt: time.Now()}
+		for _, bucket := range models.OrganizationSizeBuckets {
+			stats.BySize = append(stats.BySize, models.StatsCount{Key: bucket.Label, Count: bySize[bucket.Label]})
+			stats.Total += bySize[bucket.Label]
+		}
+
+		return stats, nil
+	})
+}
+
+// GetSignupSeries gets the number of users that signed up per bucket
+func (s *StatsService) GetSignupSeries(ctx context.Context, query models.StatsQuery) (*models.SignupSeries, error) {
+	return cached(s, "signups|"+seriesKey(query), func() (*models.SignupSeries, error) {
+		to := query.Interval.Add(query.To, 1)
+		counts, err := s.userRepo.CountSignups(ctx, query.Interval, query.From, to)
+		if err != nil {
+			return nil, err
+		}
+
+		byStart := make(map[int64]int64, len(counts))
+		for _, count := range counts {
+			byStart[count.Start.Unix()] = count.Count
+		}
+
+		series := &models.SignupSeries{
+			Interval:    query.Interval,
+			From:        query.From,
+			To:          to,
+			Points:      []models.StatsBucketCount{},
+			GeneratedAt: time.Now(),
+		}
+		for _, start := range query.Interval.Buckets(query.From, query.To) {
+			series.Points = append(series.Points, models.StatsBucketCount{Start: start, Count: byStart[start.Unix()]})
+		}
+
+		return series, nil
+	})
+}
+
+// GetEventSeries gets the number of events published per bucket and type
+func (s *StatsService) GetEventSeries(ctx context.Context, query models.StatsQuery) (*models.EventSeries, error) {
+	return cached(s, "events|"+seriesKey(query), func() (*models.EventSeries, error) {
+		to := query.Interval.Add(query.To, 1)
+		counts, err := s.eventCountRepo.CountByType(ctx, query.Interval, query.From, to)
+		if err != nil {
+			return nil, err
+		}
+
+		byStart := make(map[int64]map[string]int64)
+		for _, count := range counts {
+			key := count.Start.Unix()
+			if byStart[key] == nil {
+				byStart[key] = make(map[string]int64)
+			}
+			byStart[key][count.EventType] = count.Count
+		}
+
+		series := &models.EventSeries{
+			Interval:    query.Interval,
+			From:        query.From,
+			To:          to,
+			Points:      []models.EventPoint{},
+			GeneratedAt: time.Now(),
+		}
+		for _, start := range query.Interval.Buckets(query.From, query.To) {
+			point := models.EventPoint{Start: start, ByType: byStart[start.Unix()]}
+			if point.ByType == nil {
+				point.ByType = map[string]int64{}
+			}
+			for _, count := range point.ByType {
+				point.Total += count
+			}
+			series.Points = append(series.Points, point)
+		}
+
+		return series, nil
+	})
+}
+
+// RecordEvent counts a published event for the event statistics
+func (s *StatsService) RecordEvent(ctx context.Context, event kafka.Event) {
+	if err := s.eventCountRepo.Increment(ctx, string(event.Type), event.Time); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("eventId", event.ID).Msg("Failed to count published event")
+	}
+}
+
+// seriesKey identifies the range and interval of a time series in the cache
+func seriesKey(query models.StatsQuery) string {
+	return fmt.Sprintf("%s|%d|%d", query.Interval, query.From.Unix(), query.To.Unix())
+}
+
+// cached gets statistics from the cache, computing and caching them on a
+// miss
+func cached[V any](s *StatsService, key string, compute func() (V, error)) (V, error) {
+	if value, ok := s.cache.Get(key); ok {
+		return value.(V), nil
+	}
+
+	value, err := compute()
+	if err != nil {
+		if errors.Is(err, db.ErrAggregationUnsupported) {
+			err = models.ErrStatsUnavailable
+		}
+		return value, err
+	}
+
+	s.cache.Set(key, value)
+	return value, nil
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SubscriptionService is a service for organization billing subscriptions
+// and their presenter seats. Subscriptions are owned by the billing service;
+// this service mirrors them from its events, assigns their seats to members
+// and reports seat changes back.
+type SubscriptionService struct {
+	orgRepo repositories.OrgStore
+	events  kafka.EventPublisher
+}
+
+// NewSubscriptionService creates a new subscription service
+func NewSubscriptionService(orgRepo repositories.OrgStore, events kafka.EventPublisher) *SubscriptionService {
+	return &SubscriptionService{
+		orgRepo: orgRepo,
+		events:  events,
+	}
+}
+
+// GetSubscription gets an organization's subscription and its seat usage
+func (s *SubscriptionService) GetSubscription(ctx context.Context, orgID string, userID string) (*models.SubscriptionResponse, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for subscription")
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgViewSubscription) {
+		return nil, insufficientPermissions("view organization subscription")
+	}
+
+	if org.Subscription == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	return &models.SubscriptionResponse{
+		OrganizationID: org.ID,
+		Subscription:   *org.Subscription,
+		SeatsUsed:      org.LicensedMembers(),
+	}, nil
+}
+
+// AssignSeat licenses a member of an organization, taking one of its
+// presenter seats. Members that are already licensed are left unchanged.
+func (s *SubscriptionService) AssignSeat(ctx context.Context, orgID, memberID string, userID string) error {
+	return s.setLicensed(ctx, orgID, memberID, true, userID)
+}
+
+// UnassignSeat frees the presenter seat of a member of an organization.
+// Members that aren't licensed are left unchanged.
+func (s *SubscriptionService) UnassignSeat(ctx context.Context, orgID, memberID string, userID string) error {
+	return s.setLicensed(ctx, orgID, memberID, false, userID)
+}
+
+// setLicensed assigns or frees the seat of a member and publishes the change
+func (s *SubscriptionService) setLicensed(ctx context.Context, orgID, memberID string, licensed bool, userID string) error {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for seat change")
+		return err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgManageSeats) {
+		return insufficientPermissions("manage organization seats")
+	}
+
+	member := org.GetMember(memberID)
+	if member == nil {
+		return apperrors.NotFound("MEMBER_NOT_FOUND", "member not found in organization")
+	}
+	if member.Licensed == licensed {
+		return nil
+	}
+
+	seatsUsed := org.LicensedMembers()
+	if licensed {
+		if !org.Subscription.HasFreeSeat(seatsUsed) {
+			return ErrSeatLimitReached
+		}
+		seatsUsed++
+	} else {
+		seatsUsed--
+	}
+
+	if err := s.orgRepo.SetMemberLicensed(ctx, orgID, memberID, licensed); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("userId", memberID).Msg("Failed to change member seat")
+		return err
+	}
+
+	eventType := kafka.SeatUnassigned
+	if licensed {
+		eventType = kafka.SeatAssigned
+	}
+	publishSeatChanged(ctx, s.events, eventType, org, memberID, userID, seatsUsed)
+
+	logger.Ctx(ctx).Info().Str("orgId", orgID).Str("userId", memberID).Bool("licensed", licensed).
+		Int("seatsUsed", seatsUsed).Msg("Organization seat changed")
+	return nil
+}
+
+// publishSeatChanged publishes a seat.assigned or seat.unassigned event for
+// a member of an organization
+func publishSeatChanged(ctx context.Context, events kafka.EventPublisher, eventType kafka.EventType, org *models.Organization, memberID, changedBy string, seatsUsed int) {
+	seats := 0
+	if org.Subscription != nil {
+		seats = org.Subscription.Seats
+	}
+
+	if err := events.PublishUserEvent(
+		ctx,
+		eventType,
+		kafka.SeatChangedV1{
+			OrgID:     org.ID,
+			UserID:    memberID,
+			ChangedBy: changedBy,
+			Seats:     seats,
+			SeatsUsed: seatsUsed,
+			ChangedAt: time.Now(),
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", memberID).
+			Msgf("Failed to publish %s event", eventType)
+	}
+}
+
+// ProcessBillingSubscriptionUpdated processes a billing.subscription.updated
+// event from the billing service. Updates older than the organization's
+// subscription, delivered late, are skipped.
+func (s *SubscriptionService) ProcessBillingSubscriptionUpdated(ctx context.Context, event kafka.Event) error {
+	// Decode and validate data
+	var data kafka.BillingSubscriptionUpdatedV1
+	if err := kafka.DecodeData(event, &data); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Interface("data", event.Data).Msg("Invalid billing.subscription.updated event")
+		return err
+	}
+
+	updatedAt := data.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = event.Time
+	}
+
+	subscription := &models.Subscription{
+		Plan:              data.Plan,
+		Seats:             data.Seats,
+		Status:            models.SubscriptionStatus(data.Status),
+		RenewsAt:          data.RenewsAt,
+		CancelAtPeriodEnd: data.CancelAtPeriodEnd,
+		UpdatedAt:         updatedAt,
+	}
+
+	set, err := s.orgRepo.SetSubscription(ctx, data.OrgID, subscription)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Debug().Str("orgId", data.OrgID).Msg("Skipping billing.subscription.updated event for unknown organization")
+			return nil
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", data.OrgID).Msg("Failed to save subscription from billing event")
+		return err
+	}
+	if !set {
+		logger.Ctx(ctx).Debug().Str("orgId", data.OrgID).Msg("Skipping stale billing.subscription.updated event")
+		return nil
+	}
+
+	logger.Ctx(ctx).Info().Str("orgId", data.OrgID).Str("plan", data.Plan).Int("seats", data.Seats).
+		Str("status", data.Status).Msg("Organization subscription updated")
+	return nil
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// syncClockSkew is subtracted from the next sync cursor, so changes written
+// by instances with a slightly late clock aren't missed. Clients may receive
+// an entity twice and must apply changes idempotently.
+const syncClockSkew = 5 * time.Second
+
+// SyncService returns the users, teams and organizations visible to a user
+// that changed since a point in time, so clients can refresh cheaply
+type SyncService struct {
+	userRepo      repositories.UserStore
+	teamRepo      repositories.TeamStore
+	orgRepo       repositories.OrgStore
+	tombstoneRepo *repositories.TombstoneRepository
+	config        *config.SyncConfig
+}
+
+// NewSyncService creates a new sync service
+func NewSyncService(
+	userRepo repositories.UserStore,
+	teamRepo repositories.TeamStore,
+	orgRepo repositories.OrgStore,
+	tombstoneRepo *repositories.TombstoneRepository,
+	cfg *config.SyncConfig,
+) *SyncService {
+	return &SyncService{
+		userRepo:      userRepo,
+		teamRepo:      teamRepo,
+		orgRepo:       orgRepo,
+		tombstoneRepo: tombstoneRepo,
+		config:        cfg,
+	}
+}
+
+// Sync gets the changes visible to a user since a cursor or timestamp. An
+// empty since returns everything visible. A user sees themselves, the
+// organizations they are a member of, and the teams and members of those
+// organizations.
+func (s *SyncService) Sync(ctx context.Context, userID, since string) (*models.SyncResponse, error) {
+	start := time.Now()
+
+	var sinceTime time.Time
+	if since != "" {
+		cursor, err := models.ParseSyncCursor(since)
+		if err != nil {
+			return nil, err
+		}
+		// Deletions older than the tombstone TTL can't be returned anymore
+		if cursor.Since.Before(start.Add(-s.config.TombstoneTTL)) {
+			return nil, models.ErrSyncCursorExpired
+		}
+		sinceTime = cursor.Since
+	}
+
+	orgIDs, err := s.orgRepo.GetOrganizationIDsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int64(s.config.MaxItems)
+	next := start.Add(-syncClockSkew)
+	hasMore := false
+
+	// A truncated list continues from its last change. The other lists
+	// restart from there too, so some entities may be returned again.
+	checkTruncated := func(count int, last time.Time) {
+		if int64(count) == limit {
+			hasMore = true
+			if last.Before(next) {
+				next = last
+			}
+		}
+	}
+
+	orgs, err := s.orgRepo.FindChangedForUser(ctx, userID, sinceTime, limit)
+	if err != nil {
+		return nil, err
+	}
+	response := &models.SyncResponse{
+		Organizations: make([]models.OrganizationResponse, len(orgs)),
+	}
+	for i, org := range orgs {
+		response.Organizations[i] = org.ToResponse(false, false)
+	}
+	if len(orgs) > 0 {
+		checkTruncated(len(orgs), orgs[len(orgs)-1].UpdatedAt)
+	}
+
+	teams, err := s.teamRepo.FindChangedSince(ctx, bson.M{"organizationId": bson.M{"$in": orgIDs}}, sinceTime, limit)
+	if err != nil {
+		return nil, err
+	}
+	response.Teams = make([]models.TeamResponse, len(teams))
+	for i, team := range teams {
+		response.Teams[i] = team.ToResponse(true)
+	}
+	if len(teams) > 0 {
+		checkTruncated(len(teams), teams[len(teams)-1].UpdatedAt)
+	}
+
+	users, err := s.userRepo.FindChangedSince(ctx, bson.M{"$or": bson.A{
+		bson.M{"userId": userID},
+		bson.M{
+			"organizationIds": bson.M{"$in": orgIDs},
+			"status":          bson.M{"$ne": models.StatusPendingReview},
+		},
+	}}, sinceTime, limit)
+	if err != nil {
+		return nil, err
+	}
+	response.Users = make([]models.UserResponse, len(users))
+	for i, user := range users {
+		response.Users[i] = user.ToResponse()
+	}
+	if len(users) > 0 {
+		checkTruncated(len(users), users[len(users)-1].UpdatedAt)
+	}
+
+	// A full sync has nothing to delete
+	response.Deleted = []*models.Tombstone{}
+	if since != "" {
+		tombstones, err := s.tombstoneRepo.GetVisible(ctx, userID, orgIDs, sinceTime, limit)
+		if err != nil {
+			return nil, err
+		}
+		response.Deleted = tombstones
+		if len(tombstones) > 0 {
+			checkTruncated(len(tombstones), tombstones[len(tombstones)-1].DeletedAt)
+		}
+	}
+
+	response.NextCursor = models.SyncCursor{Since: next}.Encode()
+	response.HasMore = hasMore
+	return response, nil
+}
+
+// RecordDeletion records a tombstone telling the listed users, and the
+// members of the organization if set, to drop an entity. Failures are logged
+// and don't fail the deletion.
+func (s *SyncService) RecordDeletion(ctx context.Context, entityType models.SyncEntityType, entityID, orgID string, userIDs []string) {
+	tombstone := models.NewTombstone(entityType, entityID, orgID, userIDs, s.config.TombstoneTTL)
+	if err := s.tombstoneRepo.Create(ctx, tombstone); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("entityType", string(entityType)).Str("entityId", entityID).
+			Msg("Failed to record tombstone")
+	}
+}
+
+// RecordMemberRemoval records the tombstones of a user leaving an
+// organization: the user drops the organization, and the remaining members
+// drop the user
+func (s *SyncService) RecordMemberRemoval(ctx context.Context, orgID, userID string) {
+	s.RecordDeletion(ctx, models.SyncOrganization, orgID, "", []string{userID})
+	s.RecordDeletion(ctx, models.SyncUser, userID, orgID, nil)
+}
+
+// RecordMemberRemovals records the tombstones of users leaving an
+// organization at once: the users drop the organization, and the remaining
+// members drop the users. Failures are logged and don't fail the removals.
+func (s *SyncService) RecordMemberRemovals(ctx context.Context, orgID string, userIDs []string) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	tombstones := []*models.Tombstone{models.NewTombstone(models.SyncOrganization, orgID, "", userIDs, s.config.TombstoneTTL)}
+	for _, userID := range userIDs {
+		tombstones = append(tombstones, models.NewTombstone(models.SyncUser, userID, orgID, nil, s.config.TombstoneTTL))
+	}
+	if err := s.tombstoneRepo.CreateMany(ctx, tombstones); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Int("count", len(userIDs)).
+			Msg("Failed to record member removal tombstones")
+	}
+}
@@ -5,67 +5,112 @@ import (
 	"errors"
 	"time"
 
-	"github.com/rs/zerolog/log"
+	"github.com/your-username/slido-clone/user-service/db"
 	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
 	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
 	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // TeamService is a service for teams
 type TeamService struct {
-	teamRepo *repositories.TeamRepository
-	userRepo *repositories.UserRepository
-	orgRepo  *repositories.OrganizationRepository
-	producer *kafka.Producer
+	teamRepo        repositories.TeamStore
+	userRepo        repositories.UserStore
+	orgRepo         repositories.OrgStore
+	groupRepo       *repositories.GroupRepository
+	joinRequestRepo *repositories.TeamJoinRequestRepository
+	events          kafka.EventPublisher
+	sync            *SyncService
 }
 
 // NewTeamService creates a new team service
 func NewTeamService(
-	teamRepo *repositories.TeamRepository,
-	userRepo *repositories.UserRepository,
-	orgRepo *repositories.OrganizationRepository,
-	producer *kafka.Producer,
+	teamRepo repositories.TeamStore,
+	userRepo repositories.UserStore,
+	orgRepo repositories.OrgStore,
+	groupRepo *repositories.GroupRepository,
+	joinRequestRepo *repositories.TeamJoinRequestRepository,
+	events kafka.EventPublisher,
+	syncService *SyncService,
 ) *TeamService {
 	return &TeamService{
-		teamRepo: teamRepo,
-		userRepo: userRepo,
-		orgRepo:  orgRepo,
-		producer: producer,
+		teamRepo:        teamRepo,
+		userRepo:        userRepo,
+		orgRepo:         orgRepo,
+		groupRepo:       groupRepo,
+		joinRequestRepo: joinRequestRepo,
+		events:          events,
+		sync:            syncService,
 	}
 }
 
 // CreateTeam creates a new team
 func (s *TeamService) CreateTeam(ctx context.Context, req models.CreateTeamRequest, createdBy string) (*models.Team, error) {
+	return s.createTeam(ctx, req, models.DefaultTeamSettings(), nil, createdBy)
+}
+
+// CreateTeamFromTemplate creates a new team with the settings of a template
+// and its seed roles as members. Seed roles that can't join the team, such as
+// users who left the organization, are skipped.
+func (s *TeamService) CreateTeamFromTemplate(ctx context.Context, template *models.TeamTemplate, req models.CreateTeamRequest, createdBy string) (*models.Team, error) {
+	return s.createTeam(ctx, req, template.Settings.Resolve(), template.SeedRoles, createdBy)
+}
+
+// createTeam creates a new team with the given settings and seed members
+func (s *TeamService) createTeam(ctx context.Context, req models.CreateTeamRequest, settings models.TeamSettings, seedRoles []models.TeamTemplateSeedRole, createdBy string) (*models.Team, error) {
 	// Verify organization exists
 	org, err := s.orgRepo.GetByID(ctx, req.OrganizationID)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("organization not found")
+			return nil, ErrOrganizationNotFound
 		}
-		log.Error().Err(err).Str("orgId", req.OrganizationID).Msg("Failed to get organization for team creation")
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", req.OrganizationID).Msg("Failed to get organization for team creation")
 		return nil, err
 	}
 
 	// Verify user is member of the organization
-	if !org.IsMember(createdBy) {
-		return nil, errors.New("user is not a member of the organization")
+	if !org.Can(createdBy, models.PermOrgCreateTeams) {
+		return nil, ErrNotOrganizationMember
+	}
+
+	// Verify the parent team, if any, can take a sub-team from the creator
+	if req.ParentTeamID != "" {
+		parent, err := s.getParentTeam(ctx, req.ParentTeamID, req.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		ancestors, err := s.teamRepo.GetAncestors(ctx, parent)
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", parent.ID).Msg("Failed to get parent team ancestors")
+			return nil, err
+		}
+		if !parent.CanInherited(createdBy, ancestors, models.PermTeamManageSubteams) {
+			return nil, insufficientPermissions("create a sub-team of the parent team")
+		}
+		if len(ancestors)+2 > models.MaxTeamDepth {
+			return nil, ErrTeamHierarchyTooDeep
+		}
 	}
 
 	// Create team
 	team := models.NewTeam(req, createdBy)
+	team.Settings = settings
+	seeded := s.seedMembers(ctx, org, team, seedRoles, createdBy)
 
 	// Save to database
 	err = s.teamRepo.Create(ctx, team)
 	if err != nil {
-		log.Error().Err(err).Interface("req", req).Msg("Failed to create team")
+		logger.Ctx(ctx).Error().Err(err).Interface("req", req).Msg("Failed to create team")
 		return nil, err
 	}
 
 	// Add team to organization
 	err = s.orgRepo.AddTeam(ctx, req.OrganizationID, team.ID)
 	if err != nil {
-		log.Error().Err(err).Str("teamId", team.ID).Str("orgId", req.OrganizationID).
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("orgId", req.OrganizationID).
 			Msg("Failed to add team to organization")
 		// Don't fail the team creation, but log the error
 	}
@@ -73,51 +118,150 @@ func (s *TeamService) CreateTeam(ctx context.Context, req models.CreateTeamReque
 	// Add team to creator's user profile
 	err = s.userRepo.AddTeamToUser(ctx, createdBy, team.ID)
 	if err != nil {
-		log.Error().Err(err).Str("teamId", team.ID).Str("userId", createdBy).
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", createdBy).
 			Msg("Failed to add team to user")
 		// Don't fail the team creation, but log the error
 	}
 
 	// Publish event
-	go func(t *models.Team) {
-		err := s.producer.PublishTeamEvent(
-			kafka.TeamCreated,
-			models.TeamResponse{
-				ID:             t.ID,
-				Name:           t.Name,
-				Description:    t.Description,
-				LogoURL:        t.LogoURL,
-				OrganizationID: t.OrganizationID,
-				CreatedBy:      t.CreatedBy,
-				CreatedAt:      t.CreatedAt,
-				MemberCount:    len(t.Members),
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamCreated,
+		models.TeamResponse{
+			ID:             team.ID,
+			Name:           team.Name,
+			Description:    team.Description,
+			LogoURL:        team.LogoURL,
+			OrganizationID: team.OrganizationID,
+			ParentTeamID:   team.ParentTeamID,
+			CreatedBy:      team.CreatedBy,
+			CreatedAt:      team.CreatedAt,
+			MemberCount:    len(team.Members),
+			Settings:       team.Settings,
+		},
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to publish team.created event")
+	}
+
+	for _, member := range seeded {
+		// Add team to seeded member's user profile
+		if err := s.userRepo.AddTeamToUser(ctx, member.UserID, team.ID); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", member.UserID).
+				Msg("Failed to add team to user")
+			// Don't fail the team creation, but log the error
+		}
+
+		if err := s.events.PublishTeamEvent(
+			ctx,
+			kafka.TeamMemberAdded,
+			kafka.TeamMemberAddedV1{
+				TeamID:    team.ID,
+				TeamName:  team.Name,
+				UserID:    member.UserID,
+				Role:      string(member.Role),
+				InvitedBy: createdBy,
+				JoinedAt:  member.JoinedAt,
 			},
-			t.ID,
-			"",
-		)
-		if err != nil {
-			log.Error().Err(err).Str("teamId", t.ID).Msg("Failed to publish team.created event")
+			team.ID,
+		); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", member.UserID).
+				Msg("Failed to publish team.member.added event")
 		}
-	}(team)
+	}
 
 	return team, nil
 }
 
+// seedMembers adds the users of seed roles to a new team. Users who can't
+// join the team, because they left the organization or are pending signup
+// review, are skipped.
+func (s *TeamService) seedMembers(ctx context.Context, org *models.Organization, team *models.Team, seedRoles []models.TeamTemplateSeedRole, invitedBy string) []models.TeamMember {
+	seeded := make([]models.TeamMember, 0, len(seedRoles))
+	for _, seed := range seedRoles {
+		if team.IsMember(seed.UserID) || !org.IsMember(seed.UserID) {
+			continue
+		}
+		user, err := s.userRepo.GetByUserID(ctx, seed.UserID)
+		if err != nil {
+			if !errors.Is(err, mongo.ErrNoDocuments) {
+				logger.Ctx(ctx).Error().Err(err).Str("userId", seed.UserID).Msg("Failed to get user for team seed role")
+			}
+			continue
+		}
+		if user.IsPendingReview() {
+			continue
+		}
+
+		team.AddMember(seed.UserID, seed.Role, invitedBy)
+		seeded = append(seeded, team.Members[len(team.Members)-1])
+	}
+	return seeded
+}
+
 // GetTeamByID gets a team by ID
 func (s *TeamService) GetTeamByID(ctx context.Context, id string) (*models.Team, error) {
 	team, err := s.teamRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("team not found")
+			return nil, ErrTeamNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get team by ID")
+		return nil, err
+	}
+	return team, nil
+}
+
+// GetTeamViewer gets the viewer that the teams of an organization are shown
+// to. Teams of organizations that no longer exist are shown as to anyone.
+func (s *TeamService) GetTeamViewer(ctx context.Context, organizationID, userID string) (models.TeamViewer, error) {
+	org, err := s.orgRepo.GetByID(ctx, organizationID)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", organizationID).Msg("Failed to get team viewer")
+			return models.TeamViewer{}, err
 		}
-		log.Error().Err(err).Str("id", id).Msg("Failed to get team by ID")
+		org = nil
+	}
+	return models.NewTeamViewer(userID, org), nil
+}
+
+// GetVisibleTeam gets a team by ID for a user, with the viewer it is shown to.
+// Secret teams the user can't see are not found.
+func (s *TeamService) GetVisibleTeam(ctx context.Context, id, userID string) (*models.Team, models.TeamViewer, error) {
+	team, err := s.GetTeamByID(ctx, id)
+	if err != nil {
+		return nil, models.TeamViewer{}, err
+	}
+
+	viewer, err := s.GetTeamViewer(ctx, team.OrganizationID, userID)
+	if err != nil {
+		return nil, models.TeamViewer{}, err
+	}
+	if !viewer.CanSee(team) {
+		return nil, models.TeamViewer{}, ErrTeamNotFound
+	}
+
+	return team, viewer, nil
+}
+
+// GetTeamMembers gets a team for listing its members to a user. Only members
+// of private and secret teams can see who is in them.
+func (s *TeamService) GetTeamMembers(ctx context.Context, id, userID string) (*models.Team, error) {
+	team, viewer, err := s.GetVisibleTeam(ctx, id, userID)
+	if err != nil {
 		return nil, err
 	}
+	if !viewer.CanSeeMembers(team) {
+		return nil, ErrTeamMembersHidden
+	}
 	return team, nil
 }
 
-// GetTeamsByOrganization gets teams by organization ID
-func (s *TeamService) GetTeamsByOrganization(ctx context.Context, organizationID string, page, limit int) ([]*models.Team, int64, error) {
+// GetTeamsByOrganization gets the teams of an organization a viewer can see,
+// only including teams with all of the tags. Archived teams are left out
+// unless they are included.
+func (s *TeamService) GetTeamsByOrganization(ctx context.Context, organizationID string, tags []string, page, limit int, fields models.FieldSet, viewer models.TeamViewer, includeArchived bool) ([]*models.Team, int64, error) {
 	// Validate pagination
 	if page < 1 {
 		page = 1
@@ -127,9 +271,9 @@ func (s *TeamService) GetTeamsByOrganization(ctx context.Context, organizationID
 	}
 
 	// Get teams
-	teams, total, err := s.teamRepo.GetTeamsByOrganization(ctx, organizationID, page, limit)
+	teams, total, err := s.teamRepo.GetTeamsByOrganization(ctx, organizationID, tags, page, limit, fields, viewer.HiddenFrom(), includeArchived)
 	if err != nil {
-		log.Error().Err(err).Str("orgId", organizationID).Int("page", page).Int("limit", limit).
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", organizationID).Int("page", page).Int("limit", limit).
 			Msg("Failed to get teams by organization")
 		return nil, 0, err
 	}
@@ -137,8 +281,9 @@ func (s *TeamService) GetTeamsByOrganization(ctx context.Context, organizationID
 	return teams, total, nil
 }
 
-// GetTeamsByUser gets teams by user ID
-func (s *TeamService) GetTeamsByUser(ctx context.Context, userID string, page, limit int) ([]*models.Team, int64, error) {
+// GetTeamsByUser gets teams by user ID, only including teams with all of the
+// tags. Archived teams are left out unless they are included.
+func (s *TeamService) GetTeamsByUser(ctx context.Context, userID string, tags []string, page, limit int, fields models.FieldSet, includeArchived bool) ([]*models.Team, int64, error) {
 	// Validate pagination
 	if page < 1 {
 		page = 1
@@ -148,9 +293,9 @@ func (s *TeamService) GetTeamsByUser(ctx context.Context, userID string, page, l
 	}
 
 	// Get teams
-	teams, total, err := s.teamRepo.GetTeamsByUser(ctx, userID, page, limit)
+	teams, total, err := s.teamRepo.GetTeamsByUser(ctx, userID, tags, page, limit, fields, includeArchived)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Int("page", page).Int("limit", limit).
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Int("page", page).Int("limit", limit).
 			Msg("Failed to get teams by user")
 		return nil, 0, err
 	}
@@ -164,15 +309,15 @@ func (s *TeamService) UpdateTeam(ctx context.Context, id string, req models.Upda
 	team, err := s.teamRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("team not found")
+			return nil, ErrTeamNotFound
 		}
-		log.Error().Err(err).Str("id", id).Msg("Failed to get team for update")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get team for update")
 		return nil, err
 	}
 
-	// Check permissions - must be admin or owner
-	if !team.HasRole(userID, models.TeamRoleOwner, models.TeamRoleAdmin) {
-		return nil, errors.New("insufficient permissions to update team")
+	// Check permissions - must be admin or owner, here or in a parent team
+	if !s.can(ctx, team, userID, models.PermTeamUpdate) {
+		return nil, insufficientPermissions("update team")
 	}
 
 	// Apply changes
@@ -181,55 +326,216 @@ func (s *TeamService) UpdateTeam(ctx context.Context, id string, req models.Upda
 	// Save to database
 	err = s.teamRepo.Update(ctx, team)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Interface("req", req).
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).
 			Msg("Failed to update team")
 		return nil, err
 	}
 
 	// Publish event
-	go func(t *models.Team) {
-		err := s.producer.PublishTeamEvent(
-			kafka.TeamUpdated,
-			team.ToResponse(false),
-			t.ID,
-			"",
-		)
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamUpdated,
+		team.ToResponse(false),
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to publish team.updated event")
+	}
+
+	return team, nil
+}
+
+// UpdateTeamSettings updates the settings of a team
+func (s *TeamService) UpdateTeamSettings(ctx context.Context, id string, req models.UpdateTeamSettingsRequest, userID string) (*models.Team, error) {
+	// Get team
+	team, err := s.teamRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTeamNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get team for settings update")
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner, here or in a parent team,
+	// or manage the organization's teams
+	if !s.can(ctx, team, userID, models.PermTeamUpdate) {
+		viewer, err := s.GetTeamViewer(ctx, team.OrganizationID, userID)
 		if err != nil {
-			log.Error().Err(err).Str("teamId", t.ID).Msg("Failed to publish team.updated event")
+			return nil, err
+		}
+		if !viewer.CanViewAll {
+			return nil, insufficientPermissions("update team settings")
 		}
-	}(team)
+	}
+
+	// Apply changes
+	team.ApplySettings(req)
+
+	// Save to database
+	err = s.teamRepo.UpdateSettings(ctx, team.ID, team.Settings)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTeamNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).
+			Msg("Failed to update team settings")
+		return nil, err
+	}
+
+	// Publish event
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamUpdated,
+		team.ToResponse(false),
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to publish team.updated event")
+	}
+
+	return team, nil
+}
+
+// AddTeamTags adds tags to a team
+func (s *TeamService) AddTeamTags(ctx context.Context, id string, req models.AddTagsRequest, userID string) (*models.Team, error) {
+	// Get team
+	team, err := s.teamRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTeamNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get team for tagging")
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner, here or in a parent team
+	if !s.can(ctx, team, userID, models.PermTeamUpdate) {
+		return nil, insufficientPermissions("tag team")
+	}
+
+	tags, err := models.NormalizeTags("tags", req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	tags = models.MissingTags(team.Tags, tags)
+	if len(tags) == 0 {
+		return team, nil
+	}
+
+	err = s.teamRepo.AddTags(ctx, id, tags)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTeamNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to add team tags")
+		return nil, err
+	}
+	team.Tags = append(team.Tags, tags...)
+
+	// Publish event
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamUpdated,
+		team.ToResponse(false),
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to publish team.updated event")
+	}
 
 	return team, nil
 }
 
+// RemoveTeamTag removes a tag from a team
+func (s *TeamService) RemoveTeamTag(ctx context.Context, id, tag string, userID string) error {
+	// Get team
+	team, err := s.teamRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrTeamNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get team for untagging")
+		return err
+	}
+
+	// Check permissions - must be admin or owner, here or in a parent team
+	if !s.can(ctx, team, userID, models.PermTeamUpdate) {
+		return insufficientPermissions("tag team")
+	}
+
+	tag = models.NormalizeTag(tag)
+	err = s.teamRepo.RemoveTag(ctx, id, tag)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrTagNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Str("tag", tag).Msg("Failed to remove team tag")
+		return err
+	}
+
+	for i, t := range team.Tags {
+		if t == tag {
+			team.Tags = append(team.Tags[:i], team.Tags[i+1:]...)
+			break
+		}
+	}
+
+	// Publish event
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamUpdated,
+		team.ToResponse(false),
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to publish team.updated event")
+	}
+
+	return nil
+}
+
 // DeleteTeam deletes a team
 func (s *TeamService) DeleteTeam(ctx context.Context, id string, userID string) error {
 	// Get team
 	team, err := s.teamRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return errors.New("team not found")
+			return ErrTeamNotFound
 		}
-		log.Error().Err(err).Str("id", id).Msg("Failed to get team for deletion")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get team for deletion")
 		return err
 	}
 
-	// Check permissions - must be owner
-	if !team.HasRole(userID, models.TeamRoleOwner) {
-		return errors.New("insufficient permissions to delete team")
+	// Check permissions - must be owner, here or in a parent team
+	if !s.can(ctx, team, userID, models.PermTeamDelete) {
+		return insufficientPermissions("delete team")
 	}
 
 	// Delete team
 	err = s.teamRepo.Delete(ctx, id)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to delete team")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to delete team")
 		return err
 	}
 
+	// Move the team's sub-teams up to its parent
+	childIDs, err := s.teamRepo.GetChildIDs(ctx, id)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", id).Msg("Failed to get sub-teams of deleted team")
+		// Don't fail the team deletion, but log the error
+	}
+	for _, childID := range childIDs {
+		if err := s.teamRepo.SetParent(ctx, childID, team.ParentTeamID); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", childID).Str("parentTeamId", team.ParentTeamID).
+				Msg("Failed to move sub-team of deleted team")
+			// Don't fail the team deletion, but log the error
+		}
+	}
+
+	// Tell the organization's members, and team members outside it, to drop the team
+	s.sync.RecordDeletion(ctx, models.SyncTeam, id, team.OrganizationID, team.MemberIDs())
+
 	// Remove team from organization
 	err = s.orgRepo.RemoveTeam(ctx, team.OrganizationID, id)
 	if err != nil {
-		log.Error().Err(err).Str("teamId", id).Str("orgId", team.OrganizationID).
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", id).Str("orgId", team.OrganizationID).
 			Msg("Failed to remove team from organization")
 		// Don't fail the team deletion, but log the error
 	}
@@ -238,214 +544,561 @@ func (s *TeamService) DeleteTeam(ctx context.Context, id string, userID string)
 	for _, member := range team.Members {
 		err = s.userRepo.RemoveTeamFromUser(ctx, member.UserID, id)
 		if err != nil {
-			log.Error().Err(err).Str("teamId", id).Str("userId", member.UserID).
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", id).Str("userId", member.UserID).
 				Msg("Failed to remove team from user")
 			// Don't fail the team deletion, but log the error
 		}
 	}
 
 	// Publish event
-	go func(t *models.Team) {
-		err := s.producer.PublishTeamEvent(
-			kafka.TeamDeleted,
-			models.TeamResponse{
-				ID:             t.ID,
-				Name:           t.Name,
-				OrganizationID: t.OrganizationID,
-			},
-			t.ID,
-			"",
-		)
-		if err != nil {
-			log.Error().Err(err).Str("teamId", t.ID).Msg("Failed to publish team.deleted event")
-		}
-	}(team)
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamDeleted,
+		models.TeamResponse{
+			ID:             team.ID,
+			Name:           team.Name,
+			OrganizationID: team.OrganizationID,
+		},
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to publish team.deleted event")
+	}
 
 	return nil
 }
 
-// AddTeamMember adds a member to a team
-func (s *TeamService) AddTeamMember(ctx context.Context, teamID string, req models.AddTeamMemberRequest, invitedBy string) error {
+// ArchiveTeam archives a team. Archived teams stay readable, with their
+// members and history, but are left out of team listings and their members
+// can't change until the team is unarchived.
+func (s *TeamService) ArchiveTeam(ctx context.Context, id string, userID string) (*models.Team, error) {
+	return s.setArchived(ctx, id, true, userID)
+}
+
+// UnarchiveTeam brings an archived team back
+func (s *TeamService) UnarchiveTeam(ctx context.Context, id string, userID string) (*models.Team, error) {
+	return s.setArchived(ctx, id, false, userID)
+}
+
+// setArchived archives or unarchives a team and publishes team.archived or
+// team.unarchived
+func (s *TeamService) setArchived(ctx context.Context, id string, archived bool, userID string) (*models.Team, error) {
 	// Get team
-	team, err := s.teamRepo.GetByID(ctx, teamID)
+	team, err := s.teamRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return errors.New("team not found")
+			return nil, ErrTeamNotFound
 		}
-		log.Error().Err(err).Str("id", teamID).Msg("Failed to get team for adding member")
-		return err
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get team for archiving")
+		return nil, err
 	}
 
-	// Check permissions - must be admin or owner
-	if !team.HasRole(invitedBy, models.TeamRoleOwner, models.TeamRoleAdmin) {
-		return errors.New("insufficient permissions to add team member")
+	// Check permissions - must be owner, here or in a parent team, as for
+	// deleting it
+	if !s.can(ctx, team, userID, models.PermTeamDelete) {
+		if archived {
+			return nil, insufficientPermissions("archive team")
+		}
+		return nil, insufficientPermissions("unarchive team")
 	}
 
-	// Verify user exists
-	user, err := s.userRepo.GetByUserID(ctx, req.UserID)
+	stateErr := ErrTeamNotArchived
+	if archived {
+		stateErr = ErrTeamArchived
+	}
+	if team.IsArchived() == archived {
+		return nil, stateErr
+	}
+
+	// Save to database
+	now := time.Now()
+	eventType := kafka.TeamUnarchived
+	team.ArchivedAt, team.ArchivedBy = nil, ""
+	if archived {
+		eventType = kafka.TeamArchived
+		team.ArchivedAt, team.ArchivedBy = &now, userID
+	}
+	err = s.teamRepo.SetArchived(ctx, team.ID, team.ArchivedAt, team.ArchivedBy)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return errors.New("user not found")
+			// Archived or unarchived since it was read
+			return nil, stateErr
 		}
-		log.Error().Err(err).Str("userId", req.UserID).Msg("Failed to get user for adding to team")
-		return err
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Bool("archived", archived).Msg("Failed to archive team")
+		return nil, err
 	}
+	team.UpdatedAt = now
 
-	// Verify user is member of the organization
-	org, err := s.orgRepo.GetByID(ctx, team.OrganizationID)
-	if err != nil {
-		log.Error().Err(err).Str("orgId", team.OrganizationID).Msg("Failed to get organization for team member")
-		return err
+	// Publish event
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		eventType,
+		kafka.TeamArchiveV1{
+			TeamID:    team.ID,
+			TeamName:  team.Name,
+			OrgID:     team.OrganizationID,
+			Archived:  archived,
+			ChangedBy: userID,
+			ChangedAt: now,
+		},
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msgf("Failed to publish %s event", eventType)
 	}
 
-	if !org.IsMember(req.UserID) {
-		return errors.New("user is not a member of the organization")
+	return team, nil
+}
+
+// GetChildTeams gets the teams directly under a team that a user can see.
+// Archived teams are left out unless they are included.
+func (s *TeamService) GetChildTeams(ctx context.Context, teamID string, page, limit int, includeArchived bool, userID string) ([]*models.Team, int64, error) {
+	// Validate pagination
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
 	}
 
-	// Add member to team
-	err = s.teamRepo.AddMember(ctx, teamID, req.UserID, req.Role, invitedBy)
+	_, viewer, err := s.GetVisibleTeam(ctx, teamID, userID)
 	if err != nil {
-		log.Error().Err(err).Str("teamId", teamID).Str("userId", req.UserID).
-			Msg("Failed to add member to team")
-		return err
+		return nil, 0, err
 	}
 
-	// Add team to user
-	err = s.userRepo.AddTeamToUser(ctx, req.UserID, teamID)
+	teams, total, err := s.teamRepo.GetChildren(ctx, teamID, page, limit, viewer.HiddenFrom(), includeArchived)
 	if err != nil {
-		log.Error().Err(err).Str("teamId", teamID).Str("userId", req.UserID).
-			Msg("Failed to add team to user")
-		// Don't fail the operation, but log the error
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Int("page", page).Int("limit", limit).
+			Msg("Failed to get child teams")
+		return nil, 0, err
 	}
 
-	// Refresh team data
-	team, err = s.teamRepo.GetByID(ctx, teamID)
+	return teams, total, nil
+}
+
+// MoveTeam moves a team, with its sub-teams, under another team of the same
+// organization or to the top level
+func (s *TeamService) MoveTeam(ctx context.Context, teamID string, req models.MoveTeamRequest, userID string) (*models.Team, error) {
+	// Get team
+	team, err := s.teamRepo.GetByID(ctx, teamID)
 	if err != nil {
-		log.Error().Err(err).Str("id", teamID).Msg("Failed to refresh team data after adding member")
-		// Don't fail the operation, but log the error
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTeamNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Failed to get team for move")
+		return nil, err
 	}
 
-	// Publish event
-	go func(t *models.Team, userID string, role models.TeamMemberRole) {
-		if t == nil {
-			return
+	// Check permissions - must be able to update the team
+	if !s.can(ctx, team, userID, models.PermTeamUpdate) {
+		return nil, insufficientPermissions("move team")
+	}
+	if req.ParentTeamID == team.ParentTeamID {
+		return team, nil
+	}
+
+	if req.ParentTeamID != "" {
+		if req.ParentTeamID == team.ID {
+			return nil, ErrTeamHierarchyCycle
+		}
+		parent, err := s.getParentTeam(ctx, req.ParentTeamID, team.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		ancestors, err := s.teamRepo.GetAncestors(ctx, parent)
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", parent.ID).Msg("Failed to get parent team ancestors")
+			return nil, err
 		}
 
-		// Find the added member
-		var addedMember *models.TeamMember
-		for _, m := range t.Members {
-			if m.UserID == userID {
-				addedMember = &m
-				break
+		// The team can't end up under its own sub-teams
+		for _, ancestor := range ancestors {
+			if ancestor.ID == team.ID {
+				return nil, ErrTeamHierarchyCycle
 			}
 		}
 
-		if addedMember == nil {
-			log.Error().Str("teamId", teamID).Str("userId", userID).
-				Msg("Failed to find added member for event")
-			return
+		if !parent.CanInherited(userID, ancestors, models.PermTeamManageSubteams) {
+			return nil, insufficientPermissions("move a team under the parent team")
 		}
 
-		err := s.producer.PublishTeamEvent(
-			kafka.TeamMemberAdded,
-			map[string]interface{}{
-				"teamId":    t.ID,
-				"teamName":  t.Name,
-				"userId":    userID,
-				"role":      role,
-				"invitedBy": invitedBy,
-				"joinedAt":  addedMember.JoinedAt,
-			},
-			t.ID,
-			"",
-		)
+		// The parent's level plus the levels of the moved subtree
+		height, err := s.subtreeHeight(ctx, team.ID)
 		if err != nil {
-			log.Error().Err(err).Str("teamId", t.ID).Str("userId", userID).
-				Msg("Failed to publish team.member.added event")
+			return nil, err
 		}
-	}(team, req.UserID, req.Role)
-
-	return nil
-}
+		if len(ancestors)+1+height > models.MaxTeamDepth {
+			return nil, ErrTeamHierarchyTooDeep
+		}
+	}
 
-// UpdateTeamMember updates a team member's role
-func (s *TeamService) UpdateTeamMember(ctx context.Context, teamID, memberID string, req models.UpdateTeamMemberRequest, updatedBy string) error {
-	// Get team
-	team, err := s.teamRepo.GetByID(ctx, teamID)
-	if err != nil {
+	if err := s.teamRepo.SetParent(ctx, teamID, req.ParentTeamID); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return errors.New("team not found")
+			return nil, ErrTeamNotFound
 		}
-		log.Error().Err(err).Str("id", teamID).Msg("Failed to get team for updating member")
-		return err
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Str("parentTeamId", req.ParentTeamID).Msg("Failed to move team")
+		return nil, err
 	}
+	team.ParentTeamID = req.ParentTeamID
+	team.UpdatedAt = time.Now()
 
-	// Check permissions - must be admin or owner
-	if !team.HasRole(updatedBy, models.TeamRoleOwner, models.TeamRoleAdmin) {
-		return errors.New("insufficient permissions to update team member")
+	// Publish event
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamUpdated,
+		team.ToResponse(false),
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to publish team.updated event")
 	}
 
-	// If updating an owner, only an owner can do that
-	currentMember := team.GetMember(memberID)
-	if currentMember != nil && currentMember.Role == models.TeamRoleOwner && !team.HasRole(updatedBy, models.TeamRoleOwner) {
-		return errors.New("only a team owner can change the role of another owner")
-	}
+	return team, nil
+}
 
-	// Check if the user is trying to update their own role to a lower one
-	if memberID == updatedBy && (currentMember.Role == models.TeamRoleOwner && req.Role != models.TeamRoleOwner) {
-		// Count owners
-		ownerCount := 0
-		for _, m := range team.Members {
-			if m.Role == models.TeamRoleOwner {
-				ownerCount++
-			}
+// getParentTeam gets the team a team is created or moved under, which must
+// belong to the same organization
+func (s *TeamService) getParentTeam(ctx context.Context, parentID, organizationID string) (*models.Team, error) {
+	parent, err := s.teamRepo.GetByID(ctx, parentID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.InvalidField("parentTeamId", "parent team not found")
 		}
+		logger.Ctx(ctx).Error().Err(err).Str("parentTeamId", parentID).Msg("Failed to get parent team")
+		return nil, err
+	}
+	if parent.OrganizationID != organizationID {
+		return nil, apperrors.InvalidField("parentTeamId", "parent team belongs to another organization")
+	}
+	return parent, nil
+}
 
-		// If this is the only owner, don't allow role change
-		if ownerCount <= 1 {
-			return errors.New("cannot change role: team must have at least one owner")
+// subtreeHeight counts the levels of a team and its sub-teams
+func (s *TeamService) subtreeHeight(ctx context.Context, teamID string) (int, error) {
+	height := 0
+	level := []string{teamID}
+	for len(level) > 0 && height <= models.MaxTeamDepth {
+		height++
+		childIDs, err := s.teamRepo.GetChildIDs(ctx, level...)
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Msg("Failed to get sub-teams")
+			return 0, err
 		}
+		level = childIDs
 	}
+	return height, nil
+}
 
-	// Update member role
-	err = s.teamRepo.AddMember(ctx, teamID, memberID, req.Role, updatedBy)
-	if err != nil {
-		log.Error().Err(err).Str("teamId", teamID).Str("userId", memberID).
-			Msg("Failed to update team member")
-		return err
+// can checks if a user has a permission in a team, directly or through their
+// roles in its parent teams. Ancestors are only loaded when the user's own
+// role falls short, and a failure to load them denies the permission.
+func (s *TeamService) can(ctx context.Context, team *models.Team, userID string, permission models.Permission) bool {
+	if team.Can(userID, permission) {
+		return true
+	}
+	if team.ParentTeamID == "" {
+		return false
 	}
 
-	// Refresh team data
-	team, err = s.teamRepo.GetByID(ctx, teamID)
+	ancestors, err := s.teamRepo.GetAncestors(ctx, team)
 	if err != nil {
-		log.Error().Err(err).Str("id", teamID).Msg("Failed to refresh team data after updating member")
-		// Don't fail the operation, but log the error
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to get team ancestors for permissions")
+		return false
 	}
+	return team.CanInherited(userID, ancestors, permission)
+}
 
-	// Publish event
-	go func(t *models.Team, userID string, role models.TeamMemberRole) {
-		if t == nil {
-			return
+// AddTeamMember adds a member to a team
+func (s *TeamService) AddTeamMember(ctx context.Context, teamID string, req models.AddTeamMemberRequest, invitedBy string) error {
+	// Get team
+	team, err := s.teamRepo.GetByID(ctx, teamID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrTeamNotFound
 		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Failed to get team for adding member")
+		return err
+	}
 
-		err := s.producer.PublishTeamEvent(
+	// Check permissions - must be admin or owner, here or in a parent team
+	if !s.can(ctx, team, invitedBy, models.PermTeamManageMembers) {
+		return insufficientPermissions("add team member")
+	}
+
+	// Members added without a role get the team's default member role
+	role := req.Role
+	if role == "" {
+		role = team.Settings.Resolve().DefaultMemberRole
+	}
+
+	return s.addMember(ctx, team, req.UserID, role, invitedBy)
+}
+
+// addMember adds a member of the team's organization to a team and publishes
+// team.member.added
+func (s *TeamService) addMember(ctx context.Context, team *models.Team, userID string, role models.TeamMemberRole, invitedBy string) error {
+	teamID := team.ID
+
+	// Archived teams keep their members
+	if team.IsArchived() {
+		return ErrTeamArchived
+	}
+
+	// Verify user exists
+	user, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user for adding to team")
+		return err
+	}
+	if user.IsPendingReview() {
+		return ErrUserPendingReview
+	}
+
+	// Verify user is member of the organization
+	org, err := s.orgRepo.GetByID(ctx, team.OrganizationID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", team.OrganizationID).Msg("Failed to get organization for team member")
+		return err
+	}
+
+	if !org.IsMember(userID) {
+		return apperrors.InvalidField("userId", "user is not a member of the organization")
+	}
+
+	// Add member to team
+	err = s.teamRepo.AddMember(ctx, teamID, userID, role, invitedBy)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("userId", userID).
+			Msg("Failed to add member to team")
+		return err
+	}
+
+	// Add team to user
+	err = s.userRepo.AddTeamToUser(ctx, userID, teamID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("userId", userID).
+			Msg("Failed to add team to user")
+		// Don't fail the operation, but log the error
+	}
+
+	// Refresh team data from the primary, which has the change
+	team, err = s.teamRepo.GetByID(db.ReadPrimary(ctx), teamID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Failed to refresh team data after adding member")
+		// Don't fail the operation, but log the error
+	}
+
+	// Publish event
+	if team == nil {
+		return nil
+	}
+
+	// Find the added member
+	var addedMember *models.TeamMember
+	for i := range team.Members {
+		if team.Members[i].UserID == userID {
+			addedMember = &team.Members[i]
+			break
+		}
+	}
+
+	if addedMember == nil {
+		logger.Ctx(ctx).Error().Str("teamId", teamID).Str("userId", userID).
+			Msg("Failed to find added member for event")
+		return nil
+	}
+
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamMemberAdded,
+		kafka.TeamMemberAddedV1{
+			TeamID:    team.ID,
+			TeamName:  team.Name,
+			UserID:    userID,
+			Role:      string(role),
+			InvitedBy: invitedBy,
+			JoinedAt:  addedMember.JoinedAt,
+		},
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", userID).
+			Msg("Failed to publish team.member.added event")
+	}
+
+	return nil
+}
+
+// AddTeamGroup adds the members of an organization group to a team. Group
+// members already in the team, no longer in the organization or pending
+// signup review are skipped.
+func (s *TeamService) AddTeamGroup(ctx context.Context, teamID string, req models.AddTeamGroupRequest, invitedBy string) (*models.AddTeamGroupResponse, error) {
+	// Get team
+	team, err := s.teamRepo.GetByID(ctx, teamID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTeamNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Failed to get team for adding group")
+		return nil, err
+	}
+
+	// Check permissions - must be admin or owner, here or in a parent team
+	if !s.can(ctx, team, invitedBy, models.PermTeamManageMembers) {
+		return nil, insufficientPermissions("add team members")
+	}
+
+	// Archived teams keep their members
+	if team.IsArchived() {
+		return nil, ErrTeamArchived
+	}
+
+	group, err := s.groupRepo.GetByID(ctx, team.OrganizationID, req.GroupID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrGroupNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("groupId", req.GroupID).Msg("Failed to get group for adding to team")
+		return nil, err
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, team.OrganizationID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", team.OrganizationID).Msg("Failed to get organization for team group")
+		return nil, err
+	}
+
+	// Pick the group members that can join
+	candidates := make([]string, 0, len(group.MemberIDs))
+	for _, userID := range group.MemberIDs {
+		if org.IsMember(userID) && !team.IsMember(userID) {
+			candidates = append(candidates, userID)
+		}
+	}
+	users, err := s.userRepo.FindBatch(ctx, bson.M{"userId": bson.M{"$in": candidates}}, 0)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("groupId", group.ID).Msg("Failed to get group members for adding to team")
+		return nil, err
+	}
+
+	response := &models.AddTeamGroupResponse{Skipped: len(group.MemberIDs)}
+	for _, user := range users {
+		if user.IsPendingReview() {
+			continue
+		}
+
+		if err := s.teamRepo.AddMember(ctx, teamID, user.UserID, req.Role, invitedBy); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("userId", user.UserID).
+				Msg("Failed to add group member to team")
+			return nil, err
+		}
+		if err := s.userRepo.AddTeamToUser(ctx, user.UserID, teamID); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("userId", user.UserID).
+				Msg("Failed to add team to user")
+			// Don't fail the operation, but log the error
+		}
+		response.Added++
+		response.Skipped--
+
+		// Publish event
+		if err := s.events.PublishTeamEvent(
+			ctx,
+			kafka.TeamMemberAdded,
+			kafka.TeamMemberAddedV1{
+				TeamID:    team.ID,
+				TeamName:  team.Name,
+				UserID:    user.UserID,
+				Role:      string(req.Role),
+				InvitedBy: invitedBy,
+				JoinedAt:  time.Now(),
+			},
+			team.ID,
+		); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", user.UserID).
+				Msg("Failed to publish team.member.added event")
+		}
+	}
+
+	return response, nil
+}
+
+// UpdateTeamMember updates a team member's role
+func (s *TeamService) UpdateTeamMember(ctx context.Context, teamID, memberID string, req models.UpdateTeamMemberRequest, updatedBy string) error {
+	// Get team
+	team, err := s.teamRepo.GetByID(ctx, teamID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrTeamNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Failed to get team for updating member")
+		return err
+	}
+
+	// Check permissions - must be admin or owner, here or in a parent team
+	if !s.can(ctx, team, updatedBy, models.PermTeamManageMembers) {
+		return insufficientPermissions("update team member")
+	}
+
+	// Archived teams keep their members
+	if team.IsArchived() {
+		return ErrTeamArchived
+	}
+
+	// If updating an owner, only an owner can do that
+	currentMember := team.GetMember(memberID)
+	if currentMember != nil && currentMember.Role == models.TeamRoleOwner && !team.HasRole(updatedBy, models.TeamRoleOwner) {
+		return apperrors.Forbidden("OWNER_REQUIRED", "only a team owner can change the role of another owner")
+	}
+
+	// Check if the user is trying to update their own role to a lower one
+	if memberID == updatedBy && (currentMember.Role == models.TeamRoleOwner && req.Role != models.TeamRoleOwner) {
+		// Count owners
+		ownerCount := 0
+		for _, m := range team.Members {
+			if m.Role == models.TeamRoleOwner {
+				ownerCount++
+			}
+		}
+
+		// If this is the only owner, don't allow role change
+		if ownerCount <= 1 {
+			return apperrors.Conflict("LAST_OWNER", "cannot change role: team must have at least one owner")
+		}
+	}
+
+	// Update member role
+	err = s.teamRepo.UpdateMemberRole(ctx, teamID, memberID, req.Role)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("userId", memberID).
+			Msg("Failed to update team member")
+		return err
+	}
+
+	// Refresh team data from the primary, which has the change
+	team, err = s.teamRepo.GetByID(db.ReadPrimary(ctx), teamID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Failed to refresh team data after updating member")
+		// Don't fail the operation, but log the error
+	}
+
+	// Publish event
+	if team != nil {
+		if err := s.events.PublishTeamEvent(
+			ctx,
 			kafka.TeamMemberUpdated,
-			map[string]interface{}{
-				"teamId":    t.ID,
-				"teamName":  t.Name,
-				"userId":    userID,
-				"role":      role,
-				"updatedBy": updatedBy,
-				"updatedAt": time.Now(),
+			kafka.TeamMemberUpdatedV1{
+				TeamID:    team.ID,
+				TeamName:  team.Name,
+				UserID:    memberID,
+				Role:      string(req.Role),
+				UpdatedBy: updatedBy,
+				UpdatedAt: time.Now(),
 			},
-			t.ID,
-			"",
-		)
-		if err != nil {
-			log.Error().Err(err).Str("teamId", t.ID).Str("userId", userID).
+			team.ID,
+		); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", memberID).
 				Msg("Failed to publish team.member.updated event")
 		}
-	}(team, memberID, req.Role)
+	}
 
 	return nil
 }
@@ -456,16 +1109,16 @@ func (s *TeamService) RemoveTeamMember(ctx context.Context, teamID, memberID str
 	team, err := s.teamRepo.GetByID(ctx, teamID)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return errors.New("team not found")
+			return ErrTeamNotFound
 		}
-		log.Error().Err(err).Str("id", teamID).Msg("Failed to get team for removing member")
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Failed to get team for removing member")
 		return err
 	}
 
 	// Get member being removed
 	memberToRemove := team.GetMember(memberID)
 	if memberToRemove == nil {
-		return errors.New("member not found in team")
+		return apperrors.NotFound("MEMBER_NOT_FOUND", "member not found in team")
 	}
 
 	// Check permissions
@@ -477,7 +1130,12 @@ func (s *TeamService) RemoveTeamMember(ctx context.Context, teamID, memberID str
 	isSelf := removedBy == memberID
 
 	if !isOwner && !isSelf && (memberToRemove.Role == models.TeamRoleOwner || (!isAdmin && memberToRemove.Role == models.TeamRoleAdmin)) {
-		return errors.New("insufficient permissions to remove this team member")
+		return insufficientPermissions("remove this team member")
+	}
+
+	// Archived teams keep their members
+	if team.IsArchived() {
+		return ErrTeamArchived
 	}
 
 	// If trying to remove the last owner, prevent it
@@ -492,14 +1150,14 @@ func (s *TeamService) RemoveTeamMember(ctx context.Context, teamID, memberID str
 
 		// If this is the only owner, don't allow removal
 		if ownerCount <= 1 {
-			return errors.New("cannot remove the only team owner")
+			return apperrors.Conflict("LAST_OWNER", "cannot remove the only team owner")
 		}
 	}
 
 	// Remove member from team
 	err = s.teamRepo.RemoveMember(ctx, teamID, memberID)
 	if err != nil {
-		log.Error().Err(err).Str("teamId", teamID).Str("userId", memberID).
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("userId", memberID).
 			Msg("Failed to remove member from team")
 		return err
 	}
@@ -507,30 +1165,705 @@ func (s *TeamService) RemoveTeamMember(ctx context.Context, teamID, memberID str
 	// Remove team from user
 	err = s.userRepo.RemoveTeamFromUser(ctx, memberID, teamID)
 	if err != nil {
-		log.Error().Err(err).Str("teamId", teamID).Str("userId", memberID).
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("userId", memberID).
 			Msg("Failed to remove team from user")
 		// Don't fail the operation, but log the error
 	}
 
 	// Publish event
-	go func(t *models.Team, userID string) {
-		err := s.producer.PublishTeamEvent(
-			kafka.TeamMemberRemoved,
-			map[string]interface{}{
-				"teamId":    t.ID,
-				"teamName":  t.Name,
-				"userId":    userID,
-				"removedBy": removedBy,
-				"removedAt": time.Now(),
-			},
-			t.ID,
-			"",
-		)
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamMemberRemoved,
+		kafka.TeamMemberRemovedV1{
+			TeamID:    team.ID,
+			TeamName:  team.Name,
+			UserID:    memberID,
+			RemovedBy: removedBy,
+			RemovedAt: time.Now(),
+		},
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", memberID).
+			Msg("Failed to publish team.member.removed event")
+	}
+
+	return nil
+}
+
+// BulkTeamMembers runs up to 100 member adds, role updates and removals on a
+// team. Each operation is checked like its single member endpoint, against
+// the members left by the operations before it, and failed operations are
+// reported and skipped. The net changes are written with one write per
+// collection and published as a single team.member.batch event.
+func (s *TeamService) BulkTeamMembers(ctx context.Context, teamID string, req models.BulkTeamMembersRequest, changedBy string) (*models.BulkMemberResponse, error) {
+	// Get the users of all operations at once
+	userIDs := make([]string, len(req.Operations))
+	for i, op := range req.Operations {
+		userIDs[i] = op.UserID
+	}
+	users, err := s.userRepo.FindBatch(ctx, bson.M{"userId": bson.M{"$in": userIDs}}, 0)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Msg("Failed to get users for bulk team member request")
+		return nil, err
+	}
+	usersByID := make(map[string]*models.User, len(users))
+	for _, user := range users {
+		usersByID[user.UserID] = user
+	}
+
+	for attempt := 0; attempt < bulkMemberAttempts; attempt++ {
+		// Get team, from the primary once a write conflicted
+		readCtx := ctx
+		if attempt > 0 {
+			readCtx = db.ReadPrimary(ctx)
+		}
+		team, err := s.teamRepo.GetByID(readCtx, teamID)
 		if err != nil {
-			log.Error().Err(err).Str("teamId", t.ID).Str("userId", userID).
-				Msg("Failed to publish team.member.removed event")
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, ErrTeamNotFound
+			}
+			logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Failed to get team for bulk member request")
+			return nil, err
 		}
-	}(team, memberID)
+
+		// Check permissions - must be admin or owner, here or in a parent team
+		if !s.can(ctx, team, changedBy, models.PermTeamManageMembers) {
+			return nil, insufficientPermissions("manage team members")
+		}
+
+		// Archived teams keep their members
+		if team.IsArchived() {
+			return nil, ErrTeamArchived
+		}
+
+		// Added members must be in the team's organization
+		org, err := s.orgRepo.GetByID(readCtx, team.OrganizationID)
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", team.OrganizationID).Msg("Failed to get organization for bulk team member request")
+			return nil, err
+		}
+
+		// Check the operations against a working copy of the members
+		working := *team
+		working.Members = append([]models.TeamMember(nil), team.Members...)
+		response := models.NewBulkMemberResponse(len(req.Operations))
+		for i, op := range req.Operations {
+			err := applyTeamMemberOperation(&working, org, op, usersByID[op.UserID], changedBy)
+			response.Add(i, op.Op, op.UserID, err)
+		}
+
+		changes := models.DiffTeamMembers(team.Members, working.Members)
+		if changes.IsEmpty() {
+			return response, nil
+		}
+
+		// Write the changes, starting over if the members changed since they
+		// were read
+		err = s.teamRepo.ReplaceMembers(ctx, team, working.Members)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Debug().Str("teamId", teamID).Int("attempt", attempt+1).
+				Msg("Team members changed during bulk member request, retrying")
+			continue
+		}
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Msg("Failed to apply bulk team member changes")
+			return nil, err
+		}
+
+		s.completeMemberBatch(ctx, team, changes, changedBy)
+		return response, nil
+	}
+
+	return nil, ErrMembersChanged
+}
+
+// applyTeamMemberOperation checks an operation of a bulk member request
+// against the working copy of a team and applies it there
+func applyTeamMemberOperation(team *models.Team, org *models.Organization, op models.BulkTeamMemberOperation, user *models.User, changedBy string) error {
+	member := team.GetMember(op.UserID)
+
+	switch op.Op {
+	case models.BulkMemberAdd:
+		if user == nil {
+			return ErrUserNotFound
+		}
+		if user.IsPendingReview() {
+			return ErrUserPendingReview
+		}
+		if !org.IsMember(op.UserID) {
+			return apperrors.InvalidField("userId", "user is not a member of the organization")
+		}
+		if member != nil {
+			return ErrAlreadyTeamMember
+		}
+
+		// Members added without a role get the team's default member role
+		role := op.Role
+		if role == "" {
+			role = team.Settings.Resolve().DefaultMemberRole
+		}
+		team.AddMember(op.UserID, role, changedBy)
+
+	case models.BulkMemberUpdate:
+		if op.Role == "" {
+			return ErrMemberRoleRequired
+		}
+		if member == nil {
+			return apperrors.NotFound("MEMBER_NOT_FOUND", "member not found in team")
+		}
+
+		// If updating an owner, only an owner can do that, and the team must
+		// keep one
+		if member.Role == models.TeamRoleOwner && !team.HasRole(changedBy, models.TeamRoleOwner) {
+			return apperrors.Forbidden("OWNER_REQUIRED", "only a team owner can change the role of another owner")
+		}
+		if member.Role == models.TeamRoleOwner && op.Role != models.TeamRoleOwner && countTeamOwners(team) <= 1 {
+			return apperrors.Conflict("LAST_OWNER", "cannot change role: team must have at least one owner")
+		}
+
+		team.UpdateMember(op.UserID, op.Role)
+
+	case models.BulkMemberRemove:
+		if member == nil {
+			return apperrors.NotFound("MEMBER_NOT_FOUND", "member not found in team")
+		}
+
+		// Owners can remove anyone, admins regular members and other admins,
+		// and users themselves
+		isOwner := team.HasRole(changedBy, models.TeamRoleOwner)
+		isAdmin := team.HasRole(changedBy, models.TeamRoleAdmin)
+		isSelf := changedBy == op.UserID
+		if !isOwner && !isSelf && (member.Role == models.TeamRoleOwner || (!isAdmin && member.Role == models.TeamRoleAdmin)) {
+			return insufficientPermissions("remove this team member")
+		}
+		if member.Role == models.TeamRoleOwner && countTeamOwners(team) <= 1 {
+			return apperrors.Conflict("LAST_OWNER", "cannot remove the only team owner")
+		}
+
+		team.RemoveMember(op.UserID)
+	}
+
+	return nil
+}
+
+// completeMemberBatch records the written changes of a bulk member request on
+// the users and publishes team.member.batch
+func (s *TeamService) completeMemberBatch(ctx context.Context, team *models.Team, changes models.TeamMemberChanges, changedBy string) {
+	now := time.Now()
+	batch := kafka.TeamMemberBatchV1{
+		TeamID:    team.ID,
+		TeamName:  team.Name,
+		ChangedBy: changedBy,
+		Added:     make([]kafka.TeamMemberAddedV1, len(changes.Added)),
+		Updated:   make([]kafka.TeamMemberUpdatedV1, len(changes.Updated)),
+		Removed:   make([]kafka.TeamMemberRemovedV1, len(changes.Removed)),
+		ChangedAt: now,
+	}
+
+	added := make([]string, len(changes.Added))
+	for i, member := range changes.Added {
+		added[i] = member.UserID
+		batch.Added[i] = kafka.TeamMemberAddedV1{
+			TeamID:    team.ID,
+			TeamName:  team.Name,
+			UserID:    member.UserID,
+			Role:      string(member.Role),
+			InvitedBy: member.InvitedBy,
+			JoinedAt:  member.JoinedAt,
+		}
+	}
+	for i, member := range changes.Updated {
+		batch.Updated[i] = kafka.TeamMemberUpdatedV1{
+			TeamID:    team.ID,
+			TeamName:  team.Name,
+			UserID:    member.UserID,
+			Role:      string(member.Role),
+			UpdatedBy: changedBy,
+			UpdatedAt: now,
+		}
+	}
+	removed := make([]string, len(changes.Removed))
+	for i, member := range changes.Removed {
+		removed[i] = member.UserID
+		batch.Removed[i] = kafka.TeamMemberRemovedV1{
+			TeamID:    team.ID,
+			TeamName:  team.Name,
+			UserID:    member.UserID,
+			RemovedBy: changedBy,
+			RemovedAt: now,
+		}
+	}
+
+	// Update the teams of the users
+	if err := s.userRepo.UpdateTeamMemberships(ctx, team.ID, added, removed); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to update teams of bulk changed members")
+		// Don't fail the operation, but log the error
+	}
+
+	// Publish event
+	if err := s.events.PublishTeamEvent(ctx, kafka.TeamMemberBatch, batch, team.ID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to publish team.member.batch event")
+	}
+}
+
+// TransferOwnership starts a team ownership transfer that the new owner must accept
+func (s *TeamService) TransferOwnership(ctx context.Context, teamID string, req models.TransferOwnershipRequest, userID string) (*models.OwnershipTransfer, error) {
+	// Get team
+	team, err := s.teamRepo.GetByID(ctx, teamID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTeamNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Failed to get team for ownership transfer")
+		return nil, err
+	}
+
+	// Check permissions - must be owner
+	if !team.Can(userID, models.PermTeamTransferOwnership) {
+		return nil, apperrors.Forbidden("OWNER_REQUIRED", "only a team owner can transfer ownership")
+	}
+
+	// Archived teams keep their members
+	if team.IsArchived() {
+		return nil, ErrTeamArchived
+	}
+
+	// Validate target
+	if req.NewOwnerID == userID {
+		return nil, ErrTransferToSelf
+	}
+	target := team.GetMember(req.NewOwnerID)
+	if target == nil {
+		return nil, apperrors.InvalidField("newOwnerId", "new owner must be a member of the team")
+	}
+	if target.Role == models.TeamRoleOwner {
+		return nil, apperrors.Conflict("ALREADY_OWNER", "new owner is already a team owner")
+	}
+
+	// Verify target user exists
+	if _, err := s.userRepo.GetByUserId(ctx, req.NewOwnerID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", req.NewOwnerID).Msg("Failed to get user for ownership transfer")
+		return nil, err
+	}
+
+	// Store pending transfer, replacing any previous one
+	transfer := models.NewOwnershipTransfer(userID, req)
+	err = s.teamRepo.SetPendingTransfer(ctx, teamID, transfer)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("newOwnerId", req.NewOwnerID).
+			Msg("Failed to store pending ownership transfer")
+		return nil, err
+	}
+
+	// Publish event
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamOwnershipTransferRequested,
+		kafka.OwnershipTransferRequestedV1{
+			TeamID:            team.ID,
+			TeamName:          team.Name,
+			FromUserID:        transfer.FromUserID,
+			ToUserID:          transfer.ToUserID,
+			PreviousOwnerRole: transfer.PreviousOwnerRole,
+			RequestedAt:       transfer.RequestedAt,
+			ExpiresAt:         transfer.ExpiresAt,
+		},
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to publish team.ownership.transfer_requested event")
+	}
+
+	return transfer, nil
+}
+
+// AcceptOwnershipTransfer completes a pending team ownership transfer on behalf of the new owner
+func (s *TeamService) AcceptOwnershipTransfer(ctx context.Context, teamID string, userID string) error {
+	// Get team
+	team, err := s.teamRepo.GetByID(ctx, teamID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrTeamNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Failed to get team for ownership transfer acceptance")
+		return err
+	}
+
+	// Validate acceptance
+	transfer := team.PendingTransfer
+	if transfer == nil {
+		return ErrNoPendingOwnershipTransfer
+	}
+	if transfer.ToUserID != userID {
+		return ErrNotTransferRecipient
+	}
+	if transfer.IsExpired() {
+		if err := s.teamRepo.SetPendingTransfer(ctx, teamID, nil); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Msg("Failed to clear expired ownership transfer")
+		}
+		return ErrOwnershipTransferExpired
+	}
+
+	// Archived teams keep their members
+	if team.IsArchived() {
+		return ErrTeamArchived
+	}
+
+	// Swap ownership atomically
+	err = s.teamRepo.TransferOwnership(ctx, teamID, transfer)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrOwnershipTransferInvalid
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Msg("Failed to transfer team ownership")
+		return err
+	}
+
+	// Remove team from the previous owner if they are leaving
+	if transfer.RemovesPreviousOwner() {
+		err = s.userRepo.RemoveTeamFromUser(ctx, transfer.FromUserID, teamID)
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Str("userId", transfer.FromUserID).
+				Msg("Failed to remove team from previous owner")
+			// Don't fail the operation, but log the error
+		}
+	}
+
+	// Publish event
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamOwnershipTransferred,
+		kafka.OwnershipTransferredV1{
+			TeamID:            team.ID,
+			TeamName:          team.Name,
+			PreviousOwnerID:   transfer.FromUserID,
+			NewOwnerID:        transfer.ToUserID,
+			PreviousOwnerRole: transfer.PreviousOwnerRole,
+			TransferredAt:     time.Now(),
+		},
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to publish team.ownership.transferred event")
+	}
+
+	return nil
+}
+
+// CancelOwnershipTransfer cancels or declines a pending team ownership transfer
+func (s *TeamService) CancelOwnershipTransfer(ctx context.Context, teamID string, userID string) error {
+	// Get team
+	team, err := s.teamRepo.GetByID(ctx, teamID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrTeamNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Failed to get team for ownership transfer cancellation")
+		return err
+	}
+
+	transfer := team.PendingTransfer
+	if transfer == nil {
+		return ErrNoPendingOwnershipTransfer
+	}
+
+	// Owners may cancel, the new owner may decline
+	if transfer.ToUserID != userID && !team.Can(userID, models.PermTeamTransferOwnership) {
+		return insufficientPermissions("cancel ownership transfer")
+	}
+
+	err = s.teamRepo.SetPendingTransfer(ctx, teamID, nil)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Msg("Failed to cancel ownership transfer")
+		return err
+	}
+
+	// Publish event
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamOwnershipTransferCancelled,
+		kafka.OwnershipTransferCancelledV1{
+			TeamID:      team.ID,
+			TeamName:    team.Name,
+			FromUserID:  transfer.FromUserID,
+			ToUserID:    transfer.ToUserID,
+			CancelledBy: userID,
+			CancelledAt: time.Now(),
+		},
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Msg("Failed to publish team.ownership.transfer_cancelled event")
+	}
+
+	return nil
+}
+
+// JoinTeam adds a user to a team they can see, following the team's join
+// policy. Open teams are joined right away with the default member role;
+// teams requiring approval get a pending join request for their admins.
+func (s *TeamService) JoinTeam(ctx context.Context, teamID string, req models.CreateJoinRequestRequest, userID string) (*models.TeamJoinResponse, error) {
+	// Get team
+	team, err := s.teamRepo.GetByID(ctx, teamID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTeamNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", teamID).Msg("Failed to get team for join")
+		return nil, err
+	}
+
+	// Get organization
+	org, err := s.orgRepo.GetByID(ctx, team.OrganizationID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", team.OrganizationID).Msg("Failed to get organization for team join")
+		return nil, err
+	}
+
+	// Only members of the organization can join the teams they can see
+	if !models.NewTeamViewer(userID, org).CanSee(team) {
+		return nil, ErrTeamNotFound
+	}
+	if !org.IsMember(userID) {
+		return nil, ErrNotOrganizationMember
+	}
+	if team.IsMember(userID) {
+		return nil, ErrAlreadyTeamMember
+	}
+
+	// Archived teams keep their members
+	if team.IsArchived() {
+		return nil, ErrTeamArchived
+	}
+
+	settings := team.Settings.Resolve()
+	switch settings.JoinPolicy {
+	case models.TeamJoinOpen:
+		if err := s.addMember(ctx, team, userID, settings.DefaultMemberRole, userID); err != nil {
+			return nil, err
+		}
+		return &models.TeamJoinResponse{Joined: true, Role: settings.DefaultMemberRole}, nil
+	case models.TeamJoinApproval:
+	default:
+		return nil, ErrTeamInviteOnly
+	}
+
+	// Verify user exists
+	user, err := s.userRepo.GetByUserId(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user for team join request")
+		return nil, err
+	}
+	if user.IsPendingReview() {
+		return nil, ErrUserPendingReview
+	}
+
+	// Save join request; at most one can be pending per user
+	joinReq := models.NewTeamJoinRequest(team, userID, req)
+	if err := s.joinRequestRepo.Create(ctx, joinReq); err != nil {
+		return nil, err
+	}
+
+	// Publish event
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		kafka.TeamJoinRequested,
+		kafka.TeamJoinRequestCreatedV1{
+			RequestID: joinReq.ID,
+			TeamID:    team.ID,
+			TeamName:  team.Name,
+			OrgID:     team.OrganizationID,
+			UserID:    joinReq.UserID,
+			UserEmail: user.Email,
+			UserName:  user.FirstName + " " + user.LastName,
+			Message:   joinReq.Message,
+			CreatedAt: joinReq.CreatedAt,
+		},
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("requestId", joinReq.ID).
+			Msg("Failed to publish team.join_request.created event")
+	}
+
+	response := joinReq.ToResponse(nil)
+	return &models.TeamJoinResponse{JoinRequest: &response}, nil
+}
+
+// GetTeamJoinRequests lists the pending join requests of a team
+func (s *TeamService) GetTeamJoinRequests(ctx context.Context, teamID string, page, limit int, userID string) ([]*models.TeamJoinRequest, int64, error) {
+	// Get team
+	team, err := s.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Check permissions - must be admin or owner, here or in a parent team
+	if !s.can(ctx, team, userID, models.PermTeamManageMembers) {
+		return nil, 0, insufficientPermissions("view team join requests")
+	}
+
+	reqs, total, err := s.joinRequestRepo.ListByTeam(ctx, teamID, models.JoinRequestPending, page, limit)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", teamID).Msg("Failed to list team join requests")
+		return nil, 0, err
+	}
+
+	return reqs, total, nil
+}
+
+// GetTeamJoinRequestUser gets the user who made a team join request, or nil
+// if the user no longer exists
+func (s *TeamService) GetTeamJoinRequestUser(ctx context.Context, req *models.TeamJoinRequest) *models.User {
+	user, err := s.userRepo.GetByUserId(ctx, req.UserID)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Error().Err(err).Str("userId", req.UserID).Msg("Failed to get user for team join request")
+		}
+		return nil
+	}
+	return user
+}
+
+// ApproveTeamJoinRequest approves a pending team join request and adds the
+// user to the team
+func (s *TeamService) ApproveTeamJoinRequest(ctx context.Context, teamID, requestID string, req models.ApproveTeamJoinRequestRequest, reviewedBy string) (*models.TeamJoinRequest, error) {
+	team, joinReq, err := s.getPendingTeamJoinRequest(ctx, teamID, requestID, reviewedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Approved users join with the requested role, falling back to the default role
+	role := req.Role
+	if role == "" {
+		role = team.Settings.Resolve().DefaultMemberRole
+	}
+
+	// Add member; this also publishes team.member.added
+	if err := s.addMember(ctx, team, joinReq.UserID, role, reviewedBy); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	joinReq.Status = models.JoinRequestApproved
+	joinReq.Role = role
+	joinReq.ReviewedBy = reviewedBy
+	joinReq.ReviewReason = req.Reason
+	joinReq.ReviewedAt = &now
+	if err := s.resolveTeamJoinRequest(ctx, team, joinReq, kafka.TeamJoinRequestApproved); err != nil {
+		return nil, err
+	}
+
+	return joinReq, nil
+}
+
+// RejectTeamJoinRequest rejects a pending team join request
+func (s *TeamService) RejectTeamJoinRequest(ctx context.Context, teamID, requestID string, req models.RejectJoinRequestRequest, reviewedBy string) (*models.TeamJoinRequest, error) {
+	team, joinReq, err := s.getPendingTeamJoinRequest(ctx, teamID, requestID, reviewedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	joinReq.Status = models.JoinRequestRejected
+	joinReq.ReviewedBy = reviewedBy
+	joinReq.ReviewReason = req.Reason
+	joinReq.ReviewedAt = &now
+	if err := s.resolveTeamJoinRequest(ctx, team, joinReq, kafka.TeamJoinRequestRejected); err != nil {
+		return nil, err
+	}
+
+	return joinReq, nil
+}
+
+// CancelTeamJoinRequest withdraws the user's own pending team join request
+func (s *TeamService) CancelTeamJoinRequest(ctx context.Context, teamID string, userID string) error {
+	// Get team
+	team, err := s.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return err
+	}
+
+	joinReq, err := s.joinRequestRepo.GetPendingByUser(ctx, teamID, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("JOIN_REQUEST_NOT_FOUND", "no pending join request")
+		}
+		return err
+	}
+
+	now := time.Now()
+	joinReq.Status = models.JoinRequestCancelled
+	joinReq.ReviewedAt = &now
+	return s.resolveTeamJoinRequest(ctx, team, joinReq, kafka.TeamJoinRequestCancelled)
+}
+
+// getPendingTeamJoinRequest gets a team and one of its pending join requests for review
+func (s *TeamService) getPendingTeamJoinRequest(ctx context.Context, teamID, requestID, reviewedBy string) (*models.Team, *models.TeamJoinRequest, error) {
+	// Get team
+	team, err := s.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check permissions - must be admin or owner, here or in a parent team
+	if !s.can(ctx, team, reviewedBy, models.PermTeamManageMembers) {
+		return nil, nil, insufficientPermissions("review team join requests")
+	}
+
+	joinReq, err := s.joinRequestRepo.GetByID(ctx, teamID, requestID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, apperrors.NotFound("JOIN_REQUEST_NOT_FOUND", "join request not found")
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("requestId", requestID).Msg("Failed to get team join request")
+		return nil, nil, err
+	}
+	if !joinReq.IsPending() {
+		return nil, nil, errJoinRequestNotPending.WithDetails(map[string]string{"status": string(joinReq.Status)})
+	}
+
+	return team, joinReq, nil
+}
+
+// resolveTeamJoinRequest stores the final status of a team join request and
+// publishes the matching event
+func (s *TeamService) resolveTeamJoinRequest(ctx context.Context, team *models.Team, joinReq *models.TeamJoinRequest, eventType kafka.EventType) error {
+	err := s.joinRequestRepo.Resolve(ctx, joinReq)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return errJoinRequestNotPending
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("requestId", joinReq.ID).Msg("Failed to resolve team join request")
+		return err
+	}
+
+	// Publish event
+	if err := s.events.PublishTeamEvent(
+		ctx,
+		eventType,
+		kafka.TeamJoinRequestResolvedV1{
+			RequestID:    joinReq.ID,
+			TeamID:       team.ID,
+			TeamName:     team.Name,
+			OrgID:        team.OrganizationID,
+			UserID:       joinReq.UserID,
+			Status:       string(joinReq.Status),
+			Role:         string(joinReq.Role),
+			ReviewedBy:   joinReq.ReviewedBy,
+			ReviewReason: joinReq.ReviewReason,
+			ReviewedAt:   joinReq.ReviewedAt,
+		},
+		team.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("requestId", joinReq.ID).
+			Msgf("Failed to publish %s event", eventType)
+	}
 
 	return nil
 }
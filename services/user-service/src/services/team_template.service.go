@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TeamTemplateService is a service for team templates
+type TeamTemplateService struct {
+	templateRepo *repositories.TeamTemplateRepository
+	orgRepo      repositories.OrgStore
+	teamService  *TeamService
+}
+
+// NewTeamTemplateService creates a new team template service
+func NewTeamTemplateService(templateRepo *repositories.TeamTemplateRepository, orgRepo repositories.OrgStore, teamService *TeamService) *TeamTemplateService {
+	return &TeamTemplateService{
+		templateRepo: templateRepo,
+		orgRepo:      orgRepo,
+		teamService:  teamService,
+	}
+}
+
+// CreateTemplate creates a team template in an organization
+func (s *TeamTemplateService) CreateTemplate(ctx context.Context, orgID string, req models.CreateTeamTemplateRequest, userID string) (*models.TeamTemplate, error) {
+	org, err := s.checkManager(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateNamePattern(req.NamePattern); err != nil {
+		return nil, err
+	}
+	if err := validateSeedRoles(org, req.SeedRoles); err != nil {
+		return nil, err
+	}
+
+	template := models.NewTeamTemplate(orgID, req, userID)
+	if err := s.templateRepo.Create(ctx, template); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to create team template")
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// GetTemplates lists the team templates of an organization
+func (s *TeamTemplateService) GetTemplates(ctx context.Context, orgID string, page, limit int, userID string) ([]*models.TeamTemplate, int64, error) {
+	// Validate pagination
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	if err := s.checkTeamCreator(ctx, orgID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	templates, total, err := s.templateRepo.GetByOrganization(ctx, orgID, page, limit)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Int("page", page).Int("limit", limit).
+			Msg("Failed to list team templates")
+		return nil, 0, err
+	}
+
+	return templates, total, nil
+}
+
+// GetTemplate gets a team template of an organization
+func (s *TeamTemplateService) GetTemplate(ctx context.Context, orgID, templateID string, userID string) (*models.TeamTemplate, error) {
+	if err := s.checkTeamCreator(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.getTemplate(ctx, orgID, templateID)
+}
+
+// UpdateTemplate updates a team template of an organization
+func (s *TeamTemplateService) UpdateTemplate(ctx context.Context, orgID, templateID string, req models.UpdateTeamTemplateRequest, userID string) (*models.TeamTemplate, error) {
+	org, err := s.checkManager(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.NamePattern != nil {
+		if err := validateNamePattern(*req.NamePattern); err != nil {
+			return nil, err
+		}
+	}
+	if req.SeedRoles != nil {
+		if err := validateSeedRoles(org, *req.SeedRoles); err != nil {
+			return nil, err
+		}
+	}
+
+	template, err := s.getTemplate(ctx, orgID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	template.Apply(req)
+	if err := s.templateRepo.Update(ctx, template); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTeamTemplateNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", templateID).Msg("Failed to update team template")
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// DeleteTemplate deletes a team template of an organization. Teams created
+// from the template are kept.
+func (s *TeamTemplateService) DeleteTemplate(ctx context.Context, orgID, templateID string, userID string) error {
+	if _, err := s.checkManager(ctx, orgID, userID); err != nil {
+		return err
+	}
+
+	if err := s.templateRepo.Delete(ctx, orgID, templateID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrTeamTemplateNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", templateID).Msg("Failed to delete team template")
+		return err
+	}
+
+	return nil
+}
+
+// CreateTeamFromTemplate creates a team in an organization from one of its
+// templates. Creating the team needs the same permissions as creating it
+// without a template.
+func (s *TeamTemplateService) CreateTeamFromTemplate(ctx context.Context, orgID, templateID string, req models.CreateTeamFromTemplateRequest, userID string) (*models.Team, error) {
+	if err := s.checkTeamCreator(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	template, err := s.getTemplate(ctx, orgID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := template.TeamName(req.Variables)
+	if err != nil {
+		return nil, apperrors.InvalidField("variables", err.Error())
+	}
+	if len(name) < 3 || len(name) > 50 {
+		return nil, apperrors.InvalidField("variables", "team name \""+name+"\" must be between 3 and 50 characters")
+	}
+
+	return s.teamService.CreateTeamFromTemplate(ctx, template, models.CreateTeamRequest{
+		Name:           name,
+		Description:    req.Description,
+		LogoURL:        req.LogoURL,
+		OrganizationID: orgID,
+		ParentTeamID:   req.ParentTeamID,
+	}, userID)
+}
+
+// getOrganization gets an organization, mapping a missing document to a not
+// found error
+func (s *TeamTemplateService) getOrganization(ctx context.Context, orgID string) (*models.Organization, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for team templates")
+		return nil, err
+	}
+	return org, nil
+}
+
+// checkTeamCreator checks that a user can use an organization's team
+// templates
+func (s *TeamTemplateService) checkTeamCreator(ctx context.Context, orgID, userID string) error {
+	org, err := s.getOrganization(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if !org.Can(userID, models.PermOrgCreateTeams) {
+		return ErrNotOrganizationMember
+	}
+	return nil
+}
+
+// checkManager checks that a user can manage an organization's team templates
+func (s *TeamTemplateService) checkManager(ctx context.Context, orgID, userID string) (*models.Organization, error) {
+	org, err := s.getOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !org.Can(userID, models.PermOrgManageTeams) {
+		return nil, insufficientPermissions("manage team templates")
+	}
+	return org, nil
+}
+
+// getTemplate gets a team template, mapping a missing document to a not found
+// error
+func (s *TeamTemplateService) getTemplate(ctx context.Context, orgID, templateID string) (*models.TeamTemplate, error) {
+	template, err := s.templateRepo.GetByID(ctx, orgID, templateID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTeamTemplateNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", templateID).Msg("Failed to get team template")
+		return nil, err
+	}
+	return template, nil
+}
+
+// validateNamePattern checks the placeholders of a team name pattern
+func validateNamePattern(pattern string) error {
+	if err := models.ValidateNamePattern(pattern); err != nil {
+		return apperrors.InvalidField("namePattern", err.Error())
+	}
+	return nil
+}
+
+// validateSeedRoles checks that the users of seed roles are members of the
+// organization
+func validateSeedRoles(org *models.Organization, seedRoles []models.TeamTemplateSeedRole) error {
+	for _, seed := range seedRoles {
+		if !org.IsMember(seed.UserID) {
+			return apperrors.InvalidField("seedRoles", "user "+seed.UserID+" is not a member of the organization")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Timeline page sizes
+const (
+	DefaultTimelineLimit = 20
+	MaxTimelineLimit     = 100
+)
+
+// timelineActorFields are the payload fields naming the user behind an
+// event, in order of preference
+var timelineActorFields = []string{
+	"createdBy",
+	"invitedBy",
+	"updatedBy",
+	"removedBy",
+	"reviewedBy",
+	"cancelledBy",
+	"changedBy",
+	"fromUserId",
+}
+
+// TimelineService records published events into organization timelines
+type TimelineService struct {
+	timelineRepo *repositories.TimelineRepository
+	orgRepo      repositories.OrgStore
+	teamRepo     repositories.TeamStore
+}
+
+// NewTimelineService creates a new timeline service
+func NewTimelineService(
+	timelineRepo *repositories.TimelineRepository,
+	orgRepo repositories.OrgStore,
+	teamRepo repositories.TeamStore,
+) *TimelineService {
+	return &TimelineService{
+		timelineRepo: timelineRepo,
+		orgRepo:      orgRepo,
+		teamRepo:     teamRepo,
+	}
+}
+
+// HandleEvent records a published event in the timeline of its organization
+func (s *TimelineService) HandleEvent(ctx context.Context, event kafka.Event) {
+	entryType, ok := timelineEntryType(event.Type)
+	if !ok {
+		return
+	}
+
+	data := eventPayload(event)
+
+	orgID := eventOrganizationID(event)
+	if orgID == "" && entryType == models.TimelineTeam {
+		// Team member events only carry the team
+		team, err := s.teamRepo.GetByID(ctx, event.Subject)
+		if err != nil {
+			logger.Ctx(ctx).Warn().Err(err).Str("teamId", event.Subject).Str("eventId", event.ID).
+				Msg("Failed to resolve organization of team event for timeline")
+			return
+		}
+		orgID = team.OrganizationID
+	}
+	if orgID == "" {
+		return
+	}
+
+	entry := models.NewTimelineEntry(orgID, entryType, event.ID, string(event.Type), eventActorID(data), event.Subject, data, event.Time)
+	if err := s.timelineRepo.Create(ctx, entry); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("eventId", event.ID).Msg("Failed to record timeline entry")
+	}
+}
+
+// GetTimeline gets a page of an organization's timeline, newest first
+func (s *TimelineService) GetTimeline(ctx context.Context, orgID string, types []models.TimelineEntryType, cursor string, limit int, userID string) (*models.TimelineResponse, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for timeline")
+		return nil, err
+	}
+
+	// Check permissions - must be a member
+	if !org.Can(userID, models.PermOrgView) {
+		return nil, insufficientPermissions("view organization timeline")
+	}
+
+	var after *models.TimelineCursor
+	if cursor != "" {
+		if after, err = models.ParseTimelineCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fetch one extra entry to know if there is a next page
+	entries, err := s.timelineRepo.GetByOrganization(ctx, orgID, types, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.TimelineResponse{
+		Entries: entries,
+		Limit:   limit,
+	}
+	if len(entries) > limit {
+		response.Entries = entries[:limit]
+		last := response.Entries[limit-1]
+		response.NextCursor = models.TimelineCursor{OccurredAt: last.OccurredAt, ID: last.ID}.Encode()
+	}
+	if response.Entries == nil {
+		response.Entries = []*models.TimelineEntry{}
+	}
+
+	return response, nil
+}
+
+// ParseTimelineTypes parses a comma-separated list of timeline entry types
+func ParseTimelineTypes(s string) ([]models.TimelineEntryType, error) {
+	var types []models.TimelineEntryType
+	for _, value := range strings.Split(s, ",") {
+		switch entryType := models.TimelineEntryType(strings.TrimSpace(value)); entryType {
+		case "":
+			continue
+		case models.TimelineMembership, models.TimelineTeam, models.TimelineAudit:
+			types = append(types, entryType)
+		default:
+			return nil, apperrors.InvalidField("types", "unknown timeline type: "+string(entryType))
+		}
+	}
+	return types, nil
+}
+
+// eventPayload flattens the typed payload of an event, so it is read and
+// stored with its JSON field names
+func eventPayload(event kafka.Event) map[string]interface{} {
+	var data map[string]interface{}
+	if raw, err := json.Marshal(event.Data); err == nil {
+		_ = json.Unmarshal(raw, &data)
+	}
+	return data
+}
+
+// eventActorID gets the user behind an event from its flattened payload
+func eventActorID(data map[string]interface{}) string {
+	for _, field := range timelineActorFields {
+		if value, ok := data[field].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// timelineEntryType maps an event type to its timeline entry type. Events
+// that don't belong on an organization timeline are reported as false.
+func timelineEntryType(eventType kafka.EventType) (models.TimelineEntryType, bool) {
+	name := string(eventType)
+	switch {
+	case eventType == kafka.OrganizationDeleted, kafka.IsReplay(eventType), kafka.IsDocumentChange(eventType):
+		return "", false
+	case strings.HasPrefix(name, "organization.member."), strings.HasPrefix(name, "organization.join_request."):
+		return models.TimelineMembership, true
+	case strings.HasPrefix(name, "team."):
+		return models.TimelineTeam, true
+	case strings.HasPrefix(name, "organization."):
+		return models.TimelineAudit, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+)
+
+// TwoFactorService keeps users' two-factor enrollment in sync with the Auth
+// Service, which organizations that require two-factor authentication check
+// members against
+type TwoFactorService struct {
+	userRepo *repositories.UserRepository
+}
+
+// NewTwoFactorService creates a new two-factor service
+func NewTwoFactorService(userRepo *repositories.UserRepository) *TwoFactorService {
+	return &TwoFactorService{
+		userRepo: userRepo,
+	}
+}
+
+// ProcessAuthUserTwoFactorChanged processes a user.two_factor.changed event
+// from the Auth Service. Changes older than the recorded one are ignored, so
+// redelivered and reordered events don't undo later changes.
+func (s *TwoFactorService) ProcessAuthUserTwoFactorChanged(ctx context.Context, event kafka.Event) error {
+	// Decode and validate data
+	var data kafka.AuthUserTwoFactorChangedV1
+	if err := kafka.DecodeData(event, &data); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Interface("data", event.Data).Msg("Invalid auth user.two_factor.changed event")
+		return err
+	}
+
+	recorded, err := s.userRepo.SetTwoFactor(ctx, data.ID, models.TwoFactorStatus{
+		Enabled:   data.Enabled,
+		Methods:   data.Methods,
+		UpdatedAt: data.ChangedAt,
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", data.ID).Msg("Failed to update user two-factor status from auth event")
+		return err
+	}
+	if !recorded {
+		logger.Ctx(ctx).Debug().Str("userId", data.ID).Msg("Skipping auth user.two_factor.changed event for unknown user or outdated change")
+		return nil
+	}
+
+	logger.Ctx(ctx).Info().Str("userId", data.ID).Bool("enabled", data.Enabled).Msg("Updated user two-factor status from auth event")
+	return nil
+}
@@ -3,63 +3,85 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
 	"time"
 
-	"github.com/rs/zerolog/log"
 	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
 	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
 	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// userPurgeBatchSize is how many deleted users are read at a time while purging
+const userPurgeBatchSize = 100
+
 // UserService is a service for users
 type UserService struct {
-	userRepo *repositories.UserRepository
-	producer *kafka.Producer
+	userRepo     repositories.UserStore
+	orgRepo      repositories.OrgStore
+	teamRepo     repositories.TeamStore
+	signupReview *SignupReviewService
+	sync         *SyncService
+	events       kafka.EventPublisher
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo *repositories.UserRepository, producer *kafka.Producer) *UserService {
+func NewUserService(
+	userRepo repositories.UserStore,
+	orgRepo repositories.OrgStore,
+	teamRepo repositories.TeamStore,
+	signupReview *SignupReviewService,
+	syncService *SyncService,
+	events kafka.EventPublisher,
+) *UserService {
 	return &UserService{
-		userRepo: userRepo,
-		producer: producer,
+		userRepo:     userRepo,
+		orgRepo:      orgRepo,
+		teamRepo:     teamRepo,
+		signupReview: signupReview,
+		sync:         syncService,
+		events:       events,
 	}
 }
 
 // CreateUser creates a new user
 func (s *UserService) CreateUser(ctx context.Context, req models.CreateUserRequest) (*models.User, error) {
-	// Create user
-	user := models.NewUser(req)
+	return s.createUser(ctx, models.NewUser(req))
+}
 
+// createUser saves a new user and publishes the user.created event
+func (s *UserService) createUser(ctx context.Context, user *models.User) (*models.User, error) {
 	// Save to database
 	err := s.userRepo.Create(ctx, user)
 	if err != nil {
-		log.Error().Err(err).Interface("req", req).Msg("Failed to create user")
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Str("email", user.Email).Msg("Failed to create user")
 		return nil, err
 	}
 
 	// Publish event
-	go func(u *models.User) {
-		err := s.producer.PublishUserEvent(
-			kafka.UserCreated,
-			models.UserResponse{
-				ID:             u.ID,
-				Email:          u.Email,
-				FirstName:      u.FirstName,
-				LastName:       u.LastName,
-				FullName:       u.FirstName + " " + u.LastName,
-				Role:           u.Role,
-				Status:         u.Status,
-				ProfilePicture: u.ProfilePicture,
-				CreatedAt:      u.CreatedAt,
-			},
-			u.ID,
-			"",
-		)
-		if err != nil {
-			log.Error().Err(err).Str("userId", u.UserID).Msg("Failed to publish user.created event")
-		}
-	}(user)
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.UserCreated,
+		models.UserResponse{
+			ID:             user.ID,
+			Email:          user.Email,
+			FirstName:      user.FirstName,
+			LastName:       user.LastName,
+			FullName:       user.FirstName + " " + user.LastName,
+			Role:           user.Role,
+			Status:         user.Status,
+			ProfilePicture: user.ProfilePicture,
+			CreatedAt:      user.CreatedAt,
+		},
+		user.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to publish user.created event")
+	}
 
 	return user, nil
 }
@@ -69,9 +91,9 @@ func (s *UserService) GetUserByID(ctx context.Context, id string) (*models.User,
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
-		log.Error().Err(err).Str("id", id).Msg("Failed to get user by ID")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get user by ID")
 		return nil, err
 	}
 	return user, nil
@@ -82,29 +104,78 @@ func (s *UserService) GetUserByUserID(ctx context.Context, userID string) (*mode
 	user, err := s.userRepo.GetByUserId(ctx, userID)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
-		log.Error().Err(err).Str("userId", userID).Msg("Failed to get user by user ID")
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user by user ID")
 		return nil, err
 	}
 	return user, nil
 }
 
+// ResolvePreferences gets the effective preferences of a user: those they
+// set themselves, then the default preferences of the first organization
+// they joined, then the system defaults. The user's own preferences are
+// returned if the organization can't be read.
+func (s *UserService) ResolvePreferences(ctx context.Context, user *models.User) models.UserPreferences {
+	if len(user.OrganizationIDs) == 0 {
+		return user.Preferences
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, user.OrganizationIDs[0])
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Warn().Err(err).Str("userId", user.UserID).Msg("Failed to get organization for default preferences")
+		}
+		return user.Preferences
+	}
+	return user.Preferences.Resolve(org.Settings.DefaultPreferences)
+}
+
+// GetProfileViewer gets the viewer that user profiles are shown to, from the
+// viewer's user and platform roles. Callers without a user, such as service
+// accounts, are viewers without memberships.
+func (s *UserService) GetProfileViewer(ctx context.Context, userID string, roles []string) (models.ProfileViewer, error) {
+	user, err := s.userRepo.GetByUserId(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get profile viewer")
+			return models.ProfileViewer{}, err
+		}
+		user = nil
+	}
+	return models.NewProfileViewer(userID, user, roles), nil
+}
+
+// GetUsersByUserIDs gets the users with the given user IDs. IDs of users
+// that don't exist are skipped.
+func (s *UserService) GetUsersByUserIDs(ctx context.Context, userIDs []string) ([]*models.User, error) {
+	if len(userIDs) == 0 {
+		return []*models.User{}, nil
+	}
+
+	users, err := s.userRepo.FindBatch(ctx, bson.M{"userId": bson.M{"$in": userIDs}}, int64(len(userIDs)))
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Int("count", len(userIDs)).Msg("Failed to get users by user IDs")
+		return nil, err
+	}
+	return users, nil
+}
+
 // GetUserByEmail gets a user by email
 func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
-		log.Error().Err(err).Str("email", email).Msg("Failed to get user by email")
+		logger.Ctx(ctx).Error().Err(err).Str("email", email).Msg("Failed to get user by email")
 		return nil, err
 	}
 	return user, nil
 }
 
-// GetUsers gets users with pagination and filtering
-func (s *UserService) GetUsers(ctx context.Context, page, limit int, search string) ([]*models.User, int64, error) {
+// GetUsers gets users with pagination, filtering and sorting
+func (s *UserService) GetUsers(ctx context.Context, page, limit int, filter models.UserListFilter) ([]*models.User, int64, error) {
 	// Validate pagination
 	if page < 1 {
 		page = 1
@@ -114,9 +185,9 @@ func (s *UserService) GetUsers(ctx context.Context, page, limit int, search stri
 	}
 
 	// Get users
-	users, total, err := s.userRepo.GetUsers(ctx, page, limit, search)
+	users, total, err := s.userRepo.GetUsers(ctx, page, limit, filter)
 	if err != nil {
-		log.Error().Err(err).Int("page", page).Int("limit", limit).Str("search", search).
+		logger.Ctx(ctx).Error().Err(err).Int("page", page).Int("limit", limit).Str("search", filter.Search).
 			Msg("Failed to get users")
 		return nil, 0, err
 	}
@@ -124,68 +195,267 @@ func (s *UserService) GetUsers(ctx context.Context, page, limit int, search stri
 	return users, total, nil
 }
 
-// UpdateUser updates a user
-func (s *UserService) UpdateUser(ctx context.Context, id string, req models.UpdateUserRequest) (*models.User, error) {
+// ParseUserListFilter parses the filter, sort and fieldset query parameters of
+// a user listing
+func ParseUserListFilter(query url.Values) (models.UserListFilter, error) {
+	filter := models.UserListFilter{
+		Search:         query.Get("search"),
+		Role:           models.UserRole(query.Get("role")),
+		Status:         models.UserStatus(query.Get("status")),
+		OrganizationID: query.Get("organizationId"),
+		TeamID:         query.Get("teamId"),
+		IncludeDeleted: query.Get("includeDeleted") == "true",
+		SortBy:         query.Get("sortBy"),
+		SortOrder:      1,
+	}
+
+	switch filter.Role {
+	case "", models.RoleUser, models.RolePresenter, models.RoleAdmin:
+	default:
+		return filter, apperrors.InvalidField("role", "role must be one of user, presenter, admin")
+	}
+
+	// Signups held for review are never listed
+	switch filter.Status {
+	case "", models.StatusActive, models.StatusInactive, models.StatusPending:
+	default:
+		return filter, apperrors.InvalidField("status", "status must be one of active, inactive, pending")
+	}
+
+	if value := query.Get("createdAfter"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, apperrors.InvalidField("createdAfter", "createdAfter must be an RFC 3339 timestamp")
+		}
+		filter.CreatedAfter = &t
+	}
+	if value := query.Get("createdBefore"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, apperrors.InvalidField("createdBefore", "createdBefore must be an RFC 3339 timestamp")
+		}
+		filter.CreatedBefore = &t
+	}
+
+	if filter.SortBy == "" {
+		filter.SortBy = "name"
+	}
+	if _, ok := models.UserSortFields[filter.SortBy]; !ok {
+		return filter, apperrors.InvalidField("sortBy", "sortBy must be one of name, email, createdAt, updatedAt, lastLogin")
+	}
+
+	switch query.Get("sortOrder") {
+	case "", "asc":
+	case "desc":
+		filter.SortOrder = -1
+	default:
+		return filter, apperrors.InvalidField("sortOrder", "sortOrder must be asc or desc")
+	}
+
+	fields, err := models.ParseFieldSet(query.Get("fields"), query.Get("include"), models.UserResponseFields)
+	if err != nil {
+		return filter, err
+	}
+	filter.Fields = fields
+
+	return filter, nil
+}
+
+// UpdateUser updates a user. With an If-Match precondition, the update is
+// only applied to the version the client has.
+func (s *UserService) UpdateUser(ctx context.Context, id string, req models.UpdateUserRequest, ifMatch models.Precondition) (*models.User, error) {
 	// Get user
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
-		log.Error().Err(err).Str("id", id).Msg("Failed to get user for update")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get user for update")
 		return nil, err
 	}
+	if !ifMatch.Holds(user.ETag()) {
+		return nil, ErrPreconditionFailed
+	}
+	readAt := user.UpdatedAt
 
 	// Apply changes
 	user.Apply(req)
 
 	// Save to database
-	err = s.userRepo.Update(ctx, user)
+	if ifMatch != "" {
+		err = s.userRepo.UpdateIfUnmodified(ctx, user, readAt)
+	} else {
+		err = s.userRepo.Update(ctx, user)
+	}
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Interface("req", req).
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrPreconditionFailed
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Interface("req", req).
 			Msg("Failed to update user")
 		return nil, err
 	}
 
 	// Publish event
-	go func(u *models.User) {
-		err := s.producer.PublishUserEvent(
-			kafka.UserUpdated,
-			models.UserResponse{
-				ID:             u.ID,
-				Email:          u.Email,
-				FirstName:      u.FirstName,
-				LastName:       u.LastName,
-				FullName:       u.FirstName + " " + u.LastName,
-				Role:           u.Role,
-				Status:         u.Status,
-				ProfilePicture: u.ProfilePicture,
-				Bio:            u.Bio,
-				JobTitle:       u.JobTitle,
-				Company:        u.Company,
-				Location:       u.Location,
-				SocialLinks:    u.SocialLinks,
-				LastLogin:      u.LastLogin,
-				CreatedAt:      u.CreatedAt,
-			},
-			u.ID,
-			"",
-		)
-		if err != nil {
-			log.Error().Err(err).Str("userId", u.UserID).Msg("Failed to publish user.updated event")
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.UserUpdated,
+		models.UserResponse{
+			ID:             user.ID,
+			Email:          user.Email,
+			FirstName:      user.FirstName,
+			LastName:       user.LastName,
+			FullName:       user.FirstName + " " + user.LastName,
+			Role:           user.Role,
+			Status:         user.Status,
+			ProfilePicture: user.ProfilePicture,
+			Bio:            user.Bio,
+			JobTitle:       user.JobTitle,
+			Company:        user.Company,
+			Location:       user.Location,
+			SocialLinks:    user.SocialLinks,
+			LastLogin:      user.LastLogin,
+			CreatedAt:      user.CreatedAt,
+		},
+		user.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to publish user.updated event")
+	}
+
+	return user, nil
+}
+
+// UpdateFavorites replaces the organizations and teams a user pinned
+func (s *UserService) UpdateFavorites(ctx context.Context, userID string, req models.UpdateFavoritesRequest) (*models.User, error) {
+	// Get user
+	user, err := s.userRepo.GetByUserId(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user for favorites update")
+		return nil, err
+	}
+
+	if err := user.ValidateFavorites(req.Favorites); err != nil {
+		return nil, err
+	}
+
+	favorites := req.Favorites
+	if favorites == nil {
+		favorites = []models.Favorite{}
+	}
+
+	// Save to database
+	err = s.userRepo.SetFavorites(ctx, userID, favorites)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to update favorites")
+		return nil, err
+	}
+	user.Favorites = favorites
+
+	return user, nil
+}
+
+// RequestEmailChange records the email a user wants to change to and asks the
+// Auth Service to verify it. The email changes once the Auth Service confirms
+// the verification; a new request replaces a pending one.
+func (s *UserService) RequestEmailChange(ctx context.Context, userID string, req models.EmailChangeRequest) (*models.User, error) {
+	// Get user
+	user, err := s.userRepo.GetByUserId(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user for email change")
+		return nil, err
+	}
+
+	if req.NewEmail == user.Email {
+		return nil, apperrors.InvalidField("newEmail", "new email must differ from the current email")
+	}
+
+	// Check the email is free. The unique index settles races on confirmation.
+	existing, err := s.userRepo.GetByEmail(ctx, req.NewEmail)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to check email for email change")
+		return nil, err
+	}
+	if existing != nil {
+		return nil, repositories.ErrEmailTaken
+	}
+
+	// Save to database
+	requestedAt := time.Now()
+	err = s.userRepo.SetPendingEmail(ctx, userID, req.NewEmail, requestedAt)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
 		}
-	}(user)
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to save pending email")
+		return nil, err
+	}
+	user.PendingEmail = req.NewEmail
+	user.EmailChangeRequestedAt = &requestedAt
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.UserEmailChangeRequested,
+		kafka.UserEmailChangeRequestedV1{
+			UserID:       user.UserID,
+			CurrentEmail: user.Email,
+			NewEmail:     user.PendingEmail,
+			RequestedAt:  requestedAt,
+		},
+		user.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to publish user.email.change.requested event")
+	}
 
+	logger.Ctx(ctx).Info().Str("userId", userID).Msg("Email change requested")
 	return user, nil
 }
 
+// CancelEmailChange drops a user's pending email change. A confirmation that
+// arrives afterwards is ignored.
+func (s *UserService) CancelEmailChange(ctx context.Context, userID string) error {
+	// Get user
+	user, err := s.userRepo.GetByUserId(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to get user for email change cancellation")
+		return err
+	}
+
+	if !user.HasPendingEmailChange() {
+		return ErrNoPendingEmailChange
+	}
+
+	if err := s.userRepo.ClearPendingEmail(ctx, userID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to cancel email change")
+		return err
+	}
+
+	logger.Ctx(ctx).Info().Str("userId", userID).Msg("Email change cancelled")
+	return nil
+}
+
 // UpdateUserLastLogin updates a user's last login time
 func (s *UserService) UpdateUserLastLogin(ctx context.Context, userID string) error {
 	// Update last login
 	now := time.Now()
 	err := s.userRepo.UpdateLastLogin(ctx, userID, now)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID).Msg("Failed to update user last login")
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userID).Msg("Failed to update user last login")
 		return err
 	}
 	return nil
@@ -197,9 +467,9 @@ func (s *UserService) DeactivateUser(ctx context.Context, id string) error {
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return errors.New("user not found")
+			return ErrUserNotFound
 		}
-		log.Error().Err(err).Str("id", id).Msg("Failed to get user for deactivation")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get user for deactivation")
 		return err
 	}
 
@@ -210,31 +480,28 @@ func (s *UserService) DeactivateUser(ctx context.Context, id string) error {
 	// Save to database
 	err = s.userRepo.Update(ctx, user)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to deactivate user")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to deactivate user")
 		return err
 	}
 
 	// Publish event
-	go func(u *models.User) {
-		err := s.producer.PublishUserEvent(
-			kafka.UserDeactivated,
-			models.UserResponse{
-				ID:        u.ID,
-				Email:     u.Email,
-				FirstName: u.FirstName,
-				LastName:  u.LastName,
-				FullName:  u.FirstName + " " + u.LastName,
-				Role:      u.Role,
-				Status:    u.Status,
-				CreatedAt: u.CreatedAt,
-			},
-			u.ID,
-			"",
-		)
-		if err != nil {
-			log.Error().Err(err).Str("userId", u.UserID).Msg("Failed to publish user.deactivated event")
-		}
-	}(user)
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.UserDeactivated,
+		models.UserResponse{
+			ID:        user.ID,
+			Email:     user.Email,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			FullName:  user.FirstName + " " + user.LastName,
+			Role:      user.Role,
+			Status:    user.Status,
+			CreatedAt: user.CreatedAt,
+		},
+		user.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to publish user.deactivated event")
+	}
 
 	return nil
 }
@@ -245,9 +512,9 @@ func (s *UserService) ActivateUser(ctx context.Context, id string) error {
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return errors.New("user not found")
+			return ErrUserNotFound
 		}
-		log.Error().Err(err).Str("id", id).Msg("Failed to get user for activation")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get user for activation")
 		return err
 	}
 
@@ -258,31 +525,28 @@ func (s *UserService) ActivateUser(ctx context.Context, id string) error {
 	// Save to database
 	err = s.userRepo.Update(ctx, user)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to activate user")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to activate user")
 		return err
 	}
 
 	// Publish event
-	go func(u *models.User) {
-		err := s.producer.PublishUserEvent(
-			kafka.UserActivated,
-			models.UserResponse{
-				ID:        u.ID,
-				Email:     u.Email,
-				FirstName: u.FirstName,
-				LastName:  u.LastName,
-				FullName:  u.FirstName + " " + u.LastName,
-				Role:      u.Role,
-				Status:    u.Status,
-				CreatedAt: u.CreatedAt,
-			},
-			u.ID,
-			"",
-		)
-		if err != nil {
-			log.Error().Err(err).Str("userId", u.UserID).Msg("Failed to publish user.activated event")
-		}
-	}(user)
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.UserActivated,
+		models.UserResponse{
+			ID:        user.ID,
+			Email:     user.Email,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			FullName:  user.FirstName + " " + user.LastName,
+			Role:      user.Role,
+			Status:    user.Status,
+			CreatedAt: user.CreatedAt,
+		},
+		user.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to publish user.activated event")
+	}
 
 	return nil
 }
@@ -293,114 +557,613 @@ func (s *UserService) DeleteUser(ctx context.Context, id string) error {
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return errors.New("user not found")
+			return ErrUserNotFound
 		}
-		log.Error().Err(err).Str("id", id).Msg("Failed to get user for deletion")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get user for deletion")
 		return err
 	}
 
 	// Delete user (soft delete)
 	err = s.userRepo.Delete(ctx, id)
 	if err != nil {
-		log.Error().Err(err).Str("id", id).Msg("Failed to delete user")
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to delete user")
 		return err
 	}
 
 	// Publish event
-	go func(u *models.User) {
-		err := s.producer.PublishUserEvent(
-			kafka.UserDeleted,
-			models.UserResponse{
-				ID:        u.ID,
-				Email:     u.Email,
-				FirstName: u.FirstName,
-				LastName:  u.LastName,
-				FullName:  u.FirstName + " " + u.LastName,
-				Role:      u.Role,
-				Status:    u.Status,
-				CreatedAt: u.CreatedAt,
-			},
-			u.ID,
-			"",
-		)
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.UserDeleted,
+		models.UserResponse{
+			ID:        user.ID,
+			Email:     user.Email,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			FullName:  user.FirstName + " " + user.LastName,
+			Role:      user.Role,
+			Status:    user.Status,
+			CreatedAt: user.CreatedAt,
+		},
+		user.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to publish user.deleted event")
+	}
+
+	return nil
+}
+
+// RestoreUser restores a soft deleted user as active
+func (s *UserService) RestoreUser(ctx context.Context, id string) (*models.User, error) {
+	// Get user
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get user for restoring")
+		return nil, err
+	}
+	if !user.IsDeleted() {
+		return nil, ErrUserNotDeleted
+	}
+
+	// Restore user; it may have been restored or purged meanwhile
+	if err := s.userRepo.Restore(ctx, id); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotDeleted
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to restore user")
+		return nil, err
+	}
+	user.Status = models.StatusActive
+	user.DeletedAt = nil
+	user.UpdatedAt = time.Now()
+
+	// Publish event
+	if err := s.events.PublishUserEvent(ctx, kafka.UserRestored, user.ToResponse(), user.ID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to publish user.restored event")
+	}
+
+	logger.Ctx(ctx).Info().Str("id", id).Str("userId", user.UserID).Msg("User restored")
+	return user, nil
+}
+
+// PurgeUser removes a soft deleted user for good, with their organization
+// and team memberships. Users that are the only owner of an organization or
+// team can't be purged until its ownership is transferred.
+func (s *UserService) PurgeUser(ctx context.Context, id string, purgedBy string) error {
+	// Get user
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrUserNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to get user for purging")
+		return err
+	}
+	if !user.IsDeleted() {
+		return ErrUserNotDeleted
+	}
+
+	// Get memberships
+	orgIDs, err := s.orgRepo.GetOrganizationIDsByUser(ctx, user.UserID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to get organizations for purging user")
+		return err
+	}
+	orgs := make([]*models.Organization, 0, len(orgIDs))
+	for _, orgID := range orgIDs {
+		org, err := s.orgRepo.GetByID(ctx, orgID)
 		if err != nil {
-			log.Error().Err(err).Str("userId", u.UserID).Msg("Failed to publish user.deleted event")
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to get organization for purging user")
+			return err
+		}
+		if org.HasRole(user.UserID, models.OrgRoleOwner) && countOrgOwners(org) <= 1 {
+			return apperrors.Conflict("LAST_OWNER", fmt.Sprintf("user is the only owner of organization %s", org.Name))
+		}
+		orgs = append(orgs, org)
+	}
+
+	teams, err := s.teamRepo.FindBatch(ctx, bson.M{"members.userId": user.UserID}, 0)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to get teams for purging user")
+		return err
+	}
+	for _, team := range teams {
+		if team.HasRole(user.UserID, models.TeamRoleOwner) && countTeamOwners(team) <= 1 {
+			return apperrors.Conflict("LAST_OWNER", fmt.Sprintf("user is the only owner of team %s", team.Name))
+		}
+	}
+
+	// Remove memberships
+	teamIDs := make([]string, len(teams))
+	for i, team := range teams {
+		if err := s.teamRepo.RemoveMember(ctx, team.ID, user.UserID); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", user.UserID).
+				Msg("Failed to remove purged user from team")
+			return err
+		}
+		teamIDs[i] = team.ID
+
+		if err := s.events.PublishTeamEvent(
+			ctx,
+			kafka.TeamMemberRemoved,
+			kafka.TeamMemberRemovedV1{
+				TeamID:    team.ID,
+				TeamName:  team.Name,
+				UserID:    user.UserID,
+				RemovedBy: purgedBy,
+				RemovedAt: time.Now(),
+			},
+			team.ID,
+		); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("teamId", team.ID).Str("userId", user.UserID).
+				Msg("Failed to publish team.member.removed event")
+		}
+	}
+
+	for _, org := range orgs {
+		if err := s.orgRepo.RemoveMember(ctx, org.ID, user.UserID); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", user.UserID).
+				Msg("Failed to remove purged user from organization")
+			return err
+		}
+		s.sync.RecordMemberRemoval(ctx, org.ID, user.UserID)
+
+		// Free the member's seat
+		if member := org.GetMember(user.UserID); member != nil && member.Licensed {
+			publishSeatChanged(ctx, s.events, kafka.SeatUnassigned, org, user.UserID, purgedBy, org.LicensedMembers()-1)
+		}
+
+		if err := s.events.PublishUserEvent(
+			ctx,
+			kafka.OrganizationMemberRemoved,
+			kafka.OrganizationMemberRemovedV1{
+				OrgID:     org.ID,
+				OrgName:   org.Name,
+				UserID:    user.UserID,
+				RemovedBy: purgedBy,
+				RemovedAt: time.Now(),
+			},
+			org.ID,
+		); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("userId", user.UserID).
+				Msg("Failed to publish organization.member.removed event")
+		}
+	}
+
+	// Remove user
+	if err := s.userRepo.Purge(ctx, id); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrUserNotDeleted
 		}
-	}(user)
+		logger.Ctx(ctx).Error().Err(err).Str("id", id).Msg("Failed to purge user")
+		return err
+	}
+
+	// Publish event
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.UserPurged,
+		kafka.UserPurgedV1{
+			ID:              user.ID,
+			UserID:          user.UserID,
+			Email:           user.Email,
+			OrganizationIDs: orgIDs,
+			TeamIDs:         teamIDs,
+			PurgedBy:        purgedBy,
+			PurgedAt:        time.Now(),
+		},
+		user.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to publish user.purged event")
+	}
 
+	logger.Ctx(ctx).Info().Str("id", id).Str("userId", user.UserID).Str("purgedBy", purgedBy).
+		Int("organizations", len(orgIDs)).Int("teams", len(teamIDs)).Msg("User purged")
 	return nil
 }
 
-// ProcessAuthUserCreated processes a user.created event from the Auth Service
-func (s *UserService) ProcessAuthUserCreated(ctx context.Context, event kafka.Event) error {
-	// Parse data
-	var userData struct {
-		ID        string          `json:"id"`
-		Email     string          `json:"email"`
-		FirstName string          `json:"firstName"`
-		LastName  string          `json:"lastName"`
-		Role      models.UserRole `json:"role"`
+// PurgeDeletedUsers purges the users soft deleted at or before deletedBefore
+// and returns how many were purged. Users that are the only owner of an
+// organization or team are left until their ownership is transferred.
+func (s *UserService) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int, error) {
+	filter := bson.M{"deletedAt": bson.M{"$lte": deletedBefore}}
+	purged, skipped := 0, 0
+	for {
+		users, err := s.userRepo.FindBatch(ctx, filter, userPurgeBatchSize)
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Msg("Failed to find deleted users to purge")
+			return purged, err
+		}
+
+		for _, user := range users {
+			if err := ctx.Err(); err != nil {
+				return purged, err
+			}
+
+			var appErr *apperrors.Error
+			err := s.PurgeUser(ctx, user.ID, models.UserPurgeActor)
+			switch {
+			case err == nil:
+				purged++
+			case errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrUserNotDeleted):
+				// Restored or purged meanwhile
+			case errors.As(err, &appErr) && appErr.Code == "LAST_OWNER":
+				skipped++
+				logger.Ctx(ctx).Warn().Str("userId", user.UserID).Str("reason", appErr.Message).
+					Msg("Skipped purging deleted user")
+			default:
+				return purged, err
+			}
+		}
+
+		if len(users) < userPurgeBatchSize {
+			break
+		}
+		lastID, err := primitive.ObjectIDFromHex(users[len(users)-1].ID)
+		if err != nil {
+			return purged, err
+		}
+		filter["_id"] = bson.M{"$gt": lastID}
 	}
 
-	// Try to unmarshal the data
-	data, ok := event.Data.(map[string]interface{})
-	if !ok {
-		log.Error().Interface("data", event.Data).Msg("Invalid data format for auth user.created event")
-		return errors.New("invalid data format")
+	logger.Ctx(ctx).Info().Int("purged", purged).Int("skipped", skipped).Time("deletedBefore", deletedBefore).
+		Msg("Deleted users purged")
+	return purged, nil
+}
+
+// countOrgOwners counts the owners of an organization
+func countOrgOwners(org *models.Organization) int {
+	count := 0
+	for _, m := range org.Members {
+		if m.Role == models.OrgRoleOwner {
+			count++
+		}
 	}
+	return count
+}
 
-	// Extract fields
-	userId, _ := data["id"].(string)
-	email, _ := data["email"].(string)
-	firstName, _ := data["firstName"].(string)
-	lastName, _ := data["lastName"].(string)
-	roleStr, _ := data["role"].(string)
+// countTeamOwners counts the owners of a team
+func countTeamOwners(team *models.Team) int {
+	count := 0
+	for _, m := range team.Members {
+		if m.Role == models.TeamRoleOwner {
+			count++
+		}
+	}
+	return count
+}
 
-	// Validate required fields
-	if userId == "" || email == "" || firstName == "" || lastName == "" {
-		log.Error().Interface("data", data).Msg("Missing required fields for auth user.created event")
-		return errors.New("missing required fields")
+// ProcessAuthUserCreated processes a user.created event from the Auth Service
+func (s *UserService) ProcessAuthUserCreated(ctx context.Context, event kafka.Event) error {
+	// Decode and validate data
+	var data kafka.AuthUserCreatedV1
+	if err := kafka.DecodeData(event, &data); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Interface("data", event.Data).Msg("Invalid auth user.created event")
+		return err
 	}
+	userId := data.ID
 
 	// Check if user already exists
 	existingUser, err := s.userRepo.GetByUserId(ctx, userId)
 	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-		log.Error().Err(err).Str("userId", userId).Msg("Error checking existing user in auth event handler")
+		logger.Ctx(ctx).Error().Err(err).Str("userId", userId).Msg("Error checking existing user in auth event handler")
 		return err
 	}
 
 	// If user already exists, do nothing
 	if existingUser != nil {
-		log.Info().Str("userId", userId).Msg("User already exists, skipping creation")
+		logger.Ctx(ctx).Info().Str("userId", userId).Msg("User already exists, skipping creation")
 		return nil
 	}
 
-	// Create user request
-	var role models.UserRole
-	switch roleStr {
-	case "admin":
-		role = models.RoleAdmin
-	case "presenter":
-		role = models.RolePresenter
-	default:
-		role = models.RoleUser
-	}
+	return s.createFromAuth(ctx, data)
+}
+
+// createFromAuth creates a local user for an Auth Service user
+func (s *UserService) createFromAuth(ctx context.Context, data kafka.AuthUserCreatedV1) error {
+	userId := data.ID
 
 	createReq := models.CreateUserRequest{
 		UserID:    userId,
-		Email:     email,
-		FirstName: firstName,
-		LastName:  lastName,
-		Role:      role,
+		Email:     data.Email,
+		FirstName: data.FirstName,
+		LastName:  data.LastName,
+		Role:      authRole(data.Role),
+	}
+
+	user := models.NewUser(createReq)
+	user.SignupIP = data.SignupIP
+	user.Preferences = user.Preferences.Resolve(s.domainDefaultPreferences(ctx, data.Email))
+
+	// Hold risky signups for review
+	reasons := s.signupReview.Assess(ctx, data.Email, data.SignupIP)
+	if len(reasons) > 0 {
+		s.signupReview.Hold(user, reasons)
 	}
 
 	// Create user
-	_, err = s.CreateUser(ctx, createReq)
+	_, err := s.createUser(ctx, user)
 	if err != nil {
-		log.Error().Err(err).Interface("req", createReq).Msg("Failed to create user from auth event")
+		logger.Ctx(ctx).Error().Err(err).Interface("req", createReq).Msg("Failed to create user from auth event")
 		return err
 	}
 
-	log.Info().Str("userId", userId).Msg("Created user from auth event")
+	if user.IsPendingReview() {
+		s.signupReview.Flagged(ctx, user)
+		logger.Ctx(ctx).Warn().Str("userId", userId).Strs("reasons", reasons).Msg("Created user from auth event, held for review")
+		return nil
+	}
+
+	logger.Ctx(ctx).Info().Str("userId", userId).Msg("Created user from auth event")
 	return nil
 }
+
+// domainDefaultPreferences gets the default preferences of the organization
+// that owns an email's domain, or nil if no organization or several claim
+// it, so users provisioned from auth events start with their organization's
+// defaults
+func (s *UserService) domainDefaultPreferences(ctx context.Context, email string) *models.OrganizationDefaultPreferences {
+	domain := models.EmailDomain(email)
+	if domain == "" {
+		return nil
+	}
+
+	orgs, err := s.orgRepo.FindBatch(ctx, bson.M{
+		"settings.allowedEmailDomains": domain,
+		"settings.defaultPreferences":  bson.M{"$exists": true},
+	}, 2)
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("domain", domain).Msg("Failed to find organization for default preferences")
+		return nil
+	}
+	if len(orgs) != 1 {
+		return nil
+	}
+	return orgs[0].Settings.DefaultPreferences
+}
+
+// ProcessAuthUserUpdated processes a user.updated event from the Auth
+// Service, reconciling the fields it owns: email, name and role
+func (s *UserService) ProcessAuthUserUpdated(ctx context.Context, event kafka.Event) error {
+	// Decode and validate data
+	var data kafka.AuthUserUpdatedV1
+	if err := kafka.DecodeData(event, &data); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Interface("data", event.Data).Msg("Invalid auth user.updated event")
+		return err
+	}
+
+	user, err := s.userRepo.GetByUserId(ctx, data.ID)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Error().Err(err).Str("userId", data.ID).Msg("Failed to get user in auth event handler")
+			return err
+		}
+
+		// The user.created event was missed; create the user if the update
+		// carries enough of it
+		if data.Email == "" || data.FirstName == "" || data.LastName == "" {
+			logger.Ctx(ctx).Warn().Str("userId", data.ID).Msg("Skipping auth user.updated event for unknown user")
+			return nil
+		}
+		return s.createFromAuth(ctx, kafka.AuthUserCreatedV1{
+			ID:        data.ID,
+			Email:     data.Email,
+			FirstName: data.FirstName,
+			LastName:  data.LastName,
+			Role:      data.Role,
+		})
+	}
+
+	// Apply changes
+	changed := false
+	if data.Email != "" && data.Email != user.Email {
+		user.Email = data.Email
+		changed = true
+	}
+	if data.FirstName != "" && data.FirstName != user.FirstName {
+		user.FirstName = data.FirstName
+		changed = true
+	}
+	if data.LastName != "" && data.LastName != user.LastName {
+		user.LastName = data.LastName
+		changed = true
+	}
+	if data.Role != "" && authRole(data.Role) != user.Role {
+		user.Role = authRole(data.Role)
+		changed = true
+	}
+	if !changed {
+		logger.Ctx(ctx).Debug().Str("userId", data.ID).Msg("User already up to date with auth event")
+		return nil
+	}
+
+	if err := s.userRepo.UpdateIdentity(ctx, user); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", data.ID).Msg("Failed to update user from auth event")
+		return err
+	}
+
+	s.publishUserUpdated(ctx, user)
+
+	logger.Ctx(ctx).Info().Str("userId", data.ID).Msg("Updated user from auth event")
+	return nil
+}
+
+// ProcessAuthUserEmailChanged processes a user.email.changed event from the
+// Auth Service
+func (s *UserService) ProcessAuthUserEmailChanged(ctx context.Context, event kafka.Event) error {
+	// Decode and validate data
+	var data kafka.AuthUserEmailChangedV1
+	if err := kafka.DecodeData(event, &data); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Interface("data", event.Data).Msg("Invalid auth user.email.changed event")
+		return err
+	}
+
+	user, err := s.userRepo.GetByUserId(ctx, data.ID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Warn().Str("userId", data.ID).Msg("Skipping auth user.email.changed event for unknown user")
+			return nil
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", data.ID).Msg("Failed to get user in auth event handler")
+		return err
+	}
+
+	if user.Email == data.NewEmail {
+		logger.Ctx(ctx).Debug().Str("userId", data.ID).Msg("User email already up to date with auth event")
+		return nil
+	}
+
+	oldEmail := user.Email
+	user.Email = data.NewEmail
+	if err := s.userRepo.UpdateIdentity(ctx, user); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", data.ID).Msg("Failed to update user email from auth event")
+		return err
+	}
+
+	// Publish events
+	s.publishUserEmailChanged(ctx, user, oldEmail)
+	s.publishUserUpdated(ctx, user)
+
+	logger.Ctx(ctx).Info().Str("userId", data.ID).Msg("Updated user email from auth event")
+	return nil
+}
+
+// ProcessAuthEmailChangeConfirmed processes a user.email.change.confirmed
+// event from the Auth Service, applying the pending email the user verified
+func (s *UserService) ProcessAuthEmailChangeConfirmed(ctx context.Context, event kafka.Event) error {
+	// Decode and validate data
+	var data kafka.AuthUserEmailChangeConfirmedV1
+	if err := kafka.DecodeData(event, &data); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Interface("data", event.Data).Msg("Invalid auth user.email.change.confirmed event")
+		return err
+	}
+
+	user, err := s.userRepo.GetByUserId(ctx, data.ID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Warn().Str("userId", data.ID).Msg("Skipping auth user.email.change.confirmed event for unknown user")
+			return nil
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", data.ID).Msg("Failed to get user in auth event handler")
+		return err
+	}
+
+	if user.Email == data.NewEmail {
+		// A user.email.changed event may have applied the email first
+		if user.PendingEmail == data.NewEmail {
+			if err := s.userRepo.ClearPendingEmail(ctx, data.ID); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				logger.Ctx(ctx).Error().Err(err).Str("userId", data.ID).Msg("Failed to clear pending email")
+				return err
+			}
+		}
+		logger.Ctx(ctx).Debug().Str("userId", data.ID).Msg("User email already up to date with auth event")
+		return nil
+	}
+	if user.PendingEmail != data.NewEmail {
+		logger.Ctx(ctx).Warn().Str("userId", data.ID).Msg("Skipping confirmation of an email change that is no longer pending")
+		return nil
+	}
+
+	oldEmail := user.Email
+	err = s.userRepo.ConfirmEmailChange(ctx, data.ID, data.NewEmail)
+	if err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			// Cancelled or replaced since it was read
+			logger.Ctx(ctx).Warn().Str("userId", data.ID).Msg("Skipping confirmation of an email change that is no longer pending")
+			return nil
+		case errors.Is(err, repositories.ErrEmailTaken):
+			// Another user took the email after it was requested. Retrying
+			// can't succeed, so drop the change.
+			logger.Ctx(ctx).Warn().Str("userId", data.ID).Msg("Dropping confirmed email change to an email another user has")
+			if err := s.userRepo.ClearPendingEmail(ctx, data.ID); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				logger.Ctx(ctx).Error().Err(err).Str("userId", data.ID).Msg("Failed to clear pending email")
+				return err
+			}
+			return nil
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", data.ID).Msg("Failed to confirm email change from auth event")
+		return err
+	}
+	user.Email = data.NewEmail
+	user.PendingEmail = ""
+	user.EmailChangeRequestedAt = nil
+
+	// Publish events
+	s.publishUserEmailChanged(ctx, user, oldEmail)
+	s.publishUserUpdated(ctx, user)
+
+	logger.Ctx(ctx).Info().Str("userId", data.ID).Msg("Confirmed user email change from auth event")
+	return nil
+}
+
+// ProcessAuthUserDeleted processes a user.deleted event from the Auth Service
+func (s *UserService) ProcessAuthUserDeleted(ctx context.Context, event kafka.Event) error {
+	// Decode and validate data
+	var data kafka.AuthUserDeletedV1
+	if err := kafka.DecodeData(event, &data); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Interface("data", event.Data).Msg("Invalid auth user.deleted event")
+		return err
+	}
+
+	user, err := s.userRepo.GetByUserId(ctx, data.ID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Ctx(ctx).Info().Str("userId", data.ID).Msg("User doesn't exist, skipping deletion")
+			return nil
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("userId", data.ID).Msg("Failed to get user in auth event handler")
+		return err
+	}
+
+	if user.IsDeleted() {
+		logger.Ctx(ctx).Info().Str("userId", data.ID).Msg("User already deleted, skipping deletion")
+		return nil
+	}
+
+	// Soft delete, which publishes user.deleted
+	if err := s.DeleteUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	logger.Ctx(ctx).Info().Str("userId", data.ID).Msg("Deleted user from auth event")
+	return nil
+}
+
+// publishUserUpdated publishes a user.updated event
+func (s *UserService) publishUserUpdated(ctx context.Context, user *models.User) {
+	if err := s.events.PublishUserEvent(ctx, kafka.UserUpdated, user.ToResponse(), user.ID); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to publish user.updated event")
+	}
+}
+
+// publishUserEmailChanged publishes a user.email.changed event
+func (s *UserService) publishUserEmailChanged(ctx context.Context, user *models.User, oldEmail string) {
+	if err := s.events.PublishUserEvent(
+		ctx,
+		kafka.UserEmailChanged,
+		kafka.UserEmailChangedV1{
+			UserID:    user.UserID,
+			OldEmail:  oldEmail,
+			NewEmail:  user.Email,
+			ChangedAt: time.Now(),
+		},
+		user.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("userId", user.UserID).Msg("Failed to publish user.email.changed event")
+	}
+}
+
+// authRole maps an Auth Service role to a user role
+func authRole(role string) models.UserRole {
+	switch role {
+	case "admin":
+		return models.RoleAdmin
+	case "presenter":
+		return models.RolePresenter
+	default:
+		return models.RoleUser
+	}
+}
@@ -0,0 +1,382 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/id"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Organization verification errors
+var (
+	// ErrVerificationNotFound is returned when a verification request doesn't exist
+	ErrVerificationNotFound = apperrors.NotFound("VERIFICATION_NOT_FOUND", "verification request not found")
+	// ErrAlreadyVerified is returned when requesting a badge for a verified organization
+	ErrAlreadyVerified = apperrors.Conflict("ORGANIZATION_ALREADY_VERIFIED", "organization is already verified")
+	// ErrVerificationNotPending is returned when a verification request was already resolved
+	ErrVerificationNotPending = apperrors.Conflict("VERIFICATION_NOT_PENDING", "verification request is no longer pending")
+	// ErrVerificationNotApproved is returned when revoking a badge that wasn't granted by the request
+	ErrVerificationNotApproved = apperrors.Conflict("VERIFICATION_NOT_APPROVED", "verification request is not approved")
+	// ErrVerificationNotDomain is returned when checking the TXT record of a document request
+	ErrVerificationNotDomain = apperrors.Conflict("VERIFICATION_NOT_DOMAIN", "verification request is not a domain verification")
+	// ErrVerificationDomainUnverified is returned when approving a domain request whose TXT record wasn't found
+	ErrVerificationDomainUnverified = apperrors.Conflict("VERIFICATION_DOMAIN_UNVERIFIED", "the domain's TXT record has not been found yet")
+	// ErrVerificationTXTNotFound is returned when a domain doesn't publish the request's TXT record
+	ErrVerificationTXTNotFound = apperrors.Conflict("VERIFICATION_TXT_RECORD_NOT_FOUND", "the domain does not publish the verification TXT record")
+	// ErrVerificationDNSUnavailable is returned when a domain's TXT records can't be looked up
+	ErrVerificationDNSUnavailable = apperrors.Unavailable("VERIFICATION_DNS_UNAVAILABLE", "the domain's TXT records could not be looked up")
+)
+
+// verificationDNSTimeout is how long a TXT record lookup may take
+const verificationDNSTimeout = 10 * time.Second
+
+// VerificationService handles organizations' requests for a verified badge
+// and their review by platform admins. Every step is published for the trust
+// & safety pipeline.
+type VerificationService struct {
+	verificationRepo *repositories.VerificationRepository
+	orgRepo          repositories.OrgStore
+	events           kafka.EventPublisher
+	lookupTXT        func(ctx context.Context, name string) ([]string, error)
+}
+
+// NewVerificationService creates a new verification service
+func NewVerificationService(
+	verificationRepo *repositories.VerificationRepository,
+	orgRepo repositories.OrgStore,
+	events kafka.EventPublisher,
+) *VerificationService {
+	return &VerificationService{
+		verificationRepo: verificationRepo,
+		orgRepo:          orgRepo,
+		events:           events,
+		lookupTXT:        net.DefaultResolver.LookupTXT,
+	}
+}
+
+// RequestVerification requests a verified badge for an organization
+func (s *VerificationService) RequestVerification(ctx context.Context, orgID string, req models.CreateVerificationRequest, userID string) (*models.OrganizationVerification, error) {
+	org, err := s.getOrganization(ctx, orgID, userID, "request organization verification")
+	if err != nil {
+		return nil, err
+	}
+	if org.Verified {
+		return nil, ErrAlreadyVerified
+	}
+
+	txtRecord := ""
+	if req.Method == models.VerificationDomain {
+		if txtRecord, err = id.Token(models.VerificationTXTPrefix, 16); err != nil {
+			return nil, err
+		}
+	}
+
+	// Save request; at most one can be pending per organization
+	verification := models.NewOrganizationVerification(id.New(), org.ID, req, txtRecord, userID)
+	if err := s.verificationRepo.Create(ctx, verification); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, org, verification, kafka.OrganizationVerificationRequested)
+	return verification, nil
+}
+
+// GetVerification gets the latest verification request of an organization
+func (s *VerificationService) GetVerification(ctx context.Context, orgID, userID string) (*models.OrganizationVerification, error) {
+	if _, err := s.getOrganization(ctx, orgID, userID, "view organization verification"); err != nil {
+		return nil, err
+	}
+
+	return s.getLatest(ctx, orgID)
+}
+
+// CheckDomain looks up the TXT record of an organization's pending domain
+// request. Once it is found the request can be approved.
+func (s *VerificationService) CheckDomain(ctx context.Context, orgID, userID string) (*models.OrganizationVerification, error) {
+	if _, err := s.getOrganization(ctx, orgID, userID, "check organization verification"); err != nil {
+		return nil, err
+	}
+
+	verification, err := s.getLatest(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !verification.IsPending() {
+		return nil, ErrVerificationNotPending
+	}
+	if verification.Method != models.VerificationDomain {
+		return nil, ErrVerificationNotDomain
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, verificationDNSTimeout)
+	defer cancel()
+
+	records, err := s.lookupTXT(lookupCtx, verification.Domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			logger.Ctx(ctx).Warn().Err(err).Str("domain", verification.Domain).Msg("Failed to look up verification TXT record")
+			return nil, ErrVerificationDNSUnavailable.WithCause(err)
+		}
+	}
+
+	found := false
+	for _, record := range records {
+		if strings.TrimSpace(record) == verification.TXTRecord {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrVerificationTXTNotFound.WithDetails([]apperrors.FieldError{{
+			Field:   "domain",
+			Rule:    "txt_record",
+			Param:   verification.TXTRecord,
+			Message: ErrVerificationTXTNotFound.Message,
+		}})
+	}
+
+	now := time.Now()
+	if err := s.verificationRepo.SetDomainVerified(ctx, verification.ID, now); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrVerificationNotPending
+		}
+		return nil, err
+	}
+	verification.DomainVerifiedAt = &now
+	verification.UpdatedAt = now
+
+	logger.Ctx(ctx).Info().Str("orgId", orgID).Str("domain", verification.Domain).Msg("Verification TXT record found")
+	return verification, nil
+}
+
+// CancelVerification withdraws an organization's pending verification request
+func (s *VerificationService) CancelVerification(ctx context.Context, orgID, userID string) error {
+	org, err := s.getOrganization(ctx, orgID, userID, "cancel organization verification")
+	if err != nil {
+		return err
+	}
+
+	verification, err := s.getLatest(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if !verification.IsPending() {
+		return ErrVerificationNotPending
+	}
+
+	verification.Status = models.VerificationCancelled
+	if err := s.resolve(ctx, verification, models.VerificationPending); err != nil {
+		return err
+	}
+
+	s.publish(ctx, org, verification, kafka.OrganizationVerificationCancelled)
+	return nil
+}
+
+// ListVerifications lists the verification requests with a status, oldest first
+func (s *VerificationService) ListVerifications(ctx context.Context, status models.VerificationStatus, page, limit int) ([]*models.OrganizationVerification, int64, error) {
+	return s.verificationRepo.ListByStatus(ctx, status, page, limit)
+}
+
+// GetVerificationByID gets a verification request for review
+func (s *VerificationService) GetVerificationByID(ctx context.Context, verificationID string) (*models.OrganizationVerification, error) {
+	verification, err := s.verificationRepo.GetByID(ctx, verificationID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrVerificationNotFound
+		}
+		return nil, err
+	}
+	return verification, nil
+}
+
+// Approve approves a pending verification request and grants the
+// organization its verified badge
+func (s *VerificationService) Approve(ctx context.Context, verificationID string, req models.ReviewVerificationRequest, reviewedBy string) (*models.OrganizationVerification, error) {
+	verification, org, err := s.getForReview(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	if !verification.IsPending() {
+		return nil, ErrVerificationNotPending
+	}
+	if !verification.Reviewable() {
+		return nil, ErrVerificationDomainUnverified
+	}
+
+	s.review(verification, models.VerificationApproved, req, reviewedBy)
+	if err := s.resolve(ctx, verification, models.VerificationPending); err != nil {
+		return nil, err
+	}
+
+	if err := s.orgRepo.SetVerified(ctx, org.ID, verification.ReviewedAt); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("id", verification.ID).
+			Msg("Failed to grant organization verified badge")
+		return nil, err
+	}
+
+	logger.Ctx(ctx).Info().Str("orgId", org.ID).Str("id", verification.ID).Str("reviewedBy", reviewedBy).
+		Msg("Organization verified")
+
+	s.publish(ctx, org, verification, kafka.OrganizationVerificationApproved)
+	return verification, nil
+}
+
+// Reject rejects a pending verification request
+func (s *VerificationService) Reject(ctx context.Context, verificationID string, req models.ReviewVerificationRequest, reviewedBy string) (*models.OrganizationVerification, error) {
+	verification, org, err := s.getForReview(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	if !verification.IsPending() {
+		return nil, ErrVerificationNotPending
+	}
+
+	s.review(verification, models.VerificationRejected, req, reviewedBy)
+	if err := s.resolve(ctx, verification, models.VerificationPending); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, org, verification, kafka.OrganizationVerificationRejected)
+	return verification, nil
+}
+
+// Revoke takes back the verified badge an approved request granted
+func (s *VerificationService) Revoke(ctx context.Context, verificationID string, req models.ReviewVerificationRequest, reviewedBy string) (*models.OrganizationVerification, error) {
+	verification, org, err := s.getForReview(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	if verification.Status != models.VerificationApproved {
+		return nil, ErrVerificationNotApproved
+	}
+
+	s.review(verification, models.VerificationRevoked, req, reviewedBy)
+	if err := s.resolve(ctx, verification, models.VerificationApproved); err != nil {
+		if errors.Is(err, ErrVerificationNotPending) {
+			return nil, ErrVerificationNotApproved
+		}
+		return nil, err
+	}
+
+	if err := s.orgRepo.SetVerified(ctx, org.ID, nil); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("id", verification.ID).
+			Msg("Failed to revoke organization verified badge")
+		return nil, err
+	}
+
+	logger.Ctx(ctx).Info().Str("orgId", org.ID).Str("id", verification.ID).Str("reviewedBy", reviewedBy).
+		Msg("Organization verification revoked")
+
+	s.publish(ctx, org, verification, kafka.OrganizationVerificationRevoked)
+	return verification, nil
+}
+
+// getOrganization gets an organization a user manages the verification of
+func (s *VerificationService) getOrganization(ctx context.Context, orgID, userID, action string) (*models.Organization, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for verification")
+		return nil, err
+	}
+
+	// Check permissions
+	if !org.Can(userID, models.PermOrgManageVerification) {
+		return nil, insufficientPermissions(action)
+	}
+
+	return org, nil
+}
+
+// getLatest gets the latest verification request of an organization
+func (s *VerificationService) getLatest(ctx context.Context, orgID string) (*models.OrganizationVerification, error) {
+	verification, err := s.verificationRepo.GetLatest(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrVerificationNotFound
+		}
+		return nil, err
+	}
+	return verification, nil
+}
+
+// getForReview gets a verification request and its organization
+func (s *VerificationService) getForReview(ctx context.Context, verificationID string) (*models.OrganizationVerification, *models.Organization, error) {
+	verification, err := s.GetVerificationByID(ctx, verificationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, verification.OrganizationID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", verification.OrganizationID).Msg("Failed to get organization for verification review")
+		return nil, nil, err
+	}
+
+	return verification, org, nil
+}
+
+// review records a platform admin's decision on a verification request
+func (s *VerificationService) review(verification *models.OrganizationVerification, status models.VerificationStatus, req models.ReviewVerificationRequest, reviewedBy string) {
+	now := time.Now()
+	verification.Status = status
+	verification.ReviewedBy = reviewedBy
+	verification.ReviewReason = req.Reason
+	verification.ReviewedAt = &now
+	verification.UpdatedAt = now
+}
+
+// resolve saves a verification request's new status, conditional on it still
+// having the from status, so concurrent reviews can't both win
+func (s *VerificationService) resolve(ctx context.Context, verification *models.OrganizationVerification, from models.VerificationStatus) error {
+	if err := s.verificationRepo.Resolve(ctx, verification, from); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrVerificationNotPending
+		}
+		return err
+	}
+	return nil
+}
+
+// publish publishes an organization verification event
+func (s *VerificationService) publish(ctx context.Context, org *models.Organization, verification *models.OrganizationVerification, eventType kafka.EventType) {
+	if err := s.events.PublishUserEvent(
+		ctx,
+		eventType,
+		kafka.OrganizationVerificationV1{
+			RequestID:        verification.ID,
+			OrgID:            org.ID,
+			OrgName:          org.Name,
+			Method:           string(verification.Method),
+			Domain:           verification.Domain,
+			DomainVerifiedAt: verification.DomainVerifiedAt,
+			DocumentURL:      verification.DocumentURL,
+			Status:           string(verification.Status),
+			RequestedBy:      verification.RequestedBy,
+			RequestedAt:      verification.CreatedAt,
+			ReviewedBy:       verification.ReviewedBy,
+			ReviewReason:     verification.ReviewReason,
+			ReviewedAt:       verification.ReviewedAt,
+		},
+		org.ID,
+	); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", org.ID).Str("id", verification.ID).
+			Msgf("Failed to publish %s event", eventType)
+	}
+}
@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/apperrors"
+	"github.com/your-username/slido-clone/user-service/pkg/id"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+	"github.com/your-username/slido-clone/user-service/pkg/utils"
+	"github.com/your-username/slido-clone/user-service/pkg/webhook"
+	"github.com/your-username/slido-clone/user-service/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Webhook delivery settings
+const (
+	webhookSecretPrefix     = "whsec_"
+	webhookMaxAttempts      = 8
+	webhookInitialBackoff   = 30 * time.Second
+	webhookMaxBackoff       = 6 * time.Hour
+	webhookDeliveryTimeout  = 10 * time.Second
+	webhookDispatchInterval = 5 * time.Second
+	webhookDispatchBatch    = 50
+	webhookMaxErrorLength   = 200
+)
+
+// WebhookService is a service for organization webhook subscriptions
+type WebhookService struct {
+	webhookRepo  *repositories.WebhookRepository
+	deliveryRepo *repositories.WebhookDeliveryRepository
+	orgRepo      repositories.OrgStore
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(
+	webhookRepo *repositories.WebhookRepository,
+	deliveryRepo *repositories.WebhookDeliveryRepository,
+	orgRepo repositories.OrgStore,
+) *WebhookService {
+	return &WebhookService{
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		orgRepo:      orgRepo,
+	}
+}
+
+// CreateWebhook registers a webhook for an organization. A signing secret is
+// generated when the request doesn't provide one.
+func (s *WebhookService) CreateWebhook(ctx context.Context, orgID string, req models.CreateWebhookRequest, userID string) (*models.WebhookSubscription, error) {
+	if err := s.checkPermission(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := generateWebhookSecret()
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Msg("Failed to generate webhook secret")
+			return nil, err
+		}
+		secret = generated
+	}
+
+	wh := models.NewWebhookSubscription(orgID, req, secret, userID)
+	if err := s.webhookRepo.Create(ctx, wh); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to create webhook")
+		return nil, err
+	}
+
+	return wh, nil
+}
+
+// GetWebhooks lists the webhooks of an organization
+func (s *WebhookService) GetWebhooks(ctx context.Context, orgID string, userID string) ([]*models.WebhookSubscription, error) {
+	if err := s.checkPermission(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := s.webhookRepo.GetByOrganization(ctx, orgID, false)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Msg("Failed to list webhooks")
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhook gets a webhook of an organization
+func (s *WebhookService) GetWebhook(ctx context.Context, orgID, webhookID string, userID string) (*models.WebhookSubscription, error) {
+	if err := s.checkPermission(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.getWebhook(ctx, orgID, webhookID)
+}
+
+// UpdateWebhook updates a webhook of an organization
+func (s *WebhookService) UpdateWebhook(ctx context.Context, orgID, webhookID string, req models.UpdateWebhookRequest, userID string) (*models.WebhookSubscription, error) {
+	if err := s.checkPermission(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	wh, err := s.getWebhook(ctx, orgID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	wh.Apply(req)
+	if err := s.webhookRepo.Update(ctx, wh); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NotFound("WEBHOOK_NOT_FOUND", "webhook not found")
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", webhookID).Msg("Failed to update webhook")
+		return nil, err
+	}
+
+	return wh, nil
+}
+
+// DeleteWebhook deletes a webhook of an organization
+func (s *WebhookService) DeleteWebhook(ctx context.Context, orgID, webhookID string, userID string) error {
+	if err := s.checkPermission(ctx, orgID, userID); err != nil {
+		return err
+	}
+
+	if err := s.webhookRepo.Delete(ctx, orgID, webhookID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("WEBHOOK_NOT_FOUND", "webhook not found")
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", webhookID).Msg("Failed to delete webhook")
+		return err
+	}
+
+	return nil
+}
+
+// GetDeliveries lists the delivery log of a webhook
+func (s *WebhookService) GetDeliveries(ctx context.Context, orgID, webhookID string, page, limit int, userID string) ([]*models.WebhookDelivery, int64, error) {
+	if err := s.checkPermission(ctx, orgID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	if _, err := s.getWebhook(ctx, orgID, webhookID); err != nil {
+		return nil, 0, err
+	}
+
+	deliveries, total, err := s.deliveryRepo.GetByWebhook(ctx, orgID, webhookID, page, limit)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("webhookId", webhookID).Msg("Failed to list webhook deliveries")
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// TestWebhook sends a test event to a webhook right away. Test deliveries
+// are logged but not retried.
+func (s *WebhookService) TestWebhook(ctx context.Context, orgID, webhookID string, userID string) (*models.WebhookDelivery, error) {
+	if err := s.checkPermission(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	wh, err := s.getWebhook(ctx, orgID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(models.WebhookPayload{
+		ID:             uuid.New().String(),
+		Type:           models.WebhookTestEvent,
+		OrganizationID: orgID,
+		Time:           time.Now(),
+		Data: map[string]interface{}{
+			"webhookId":   wh.ID,
+			"requestedBy": userID,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := models.NewWebhookDelivery(wh, models.WebhookTestEvent, payload)
+	if err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	s.attempt(ctx, wh, delivery, false)
+	return delivery, nil
+}
+
+// HandleEvent queues deliveries of a published event to every matching
+// webhook of the event's organization. Replayed snapshots are meant for Kafka
+// consumers rebuilding state and aren't delivered to webhooks, nor are
+// document changes, which repeat the events of changes made through the
+// service.
+func (s *WebhookService) HandleEvent(ctx context.Context, event kafka.Event) {
+	if kafka.IsReplay(event.Type) || kafka.IsDocumentChange(event.Type) {
+		return
+	}
+
+	orgID := eventOrganizationID(event)
+	if orgID == "" {
+		return
+	}
+
+	webhooks, err := s.webhookRepo.GetByOrganization(ctx, orgID, true)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("orgId", orgID).Str("eventId", event.ID).Msg("Failed to get webhooks for event")
+		return
+	}
+
+	var payload []byte
+	for _, wh := range webhooks {
+		if !wh.Matches(string(event.Type)) {
+			continue
+		}
+
+		// Encode the payload once, on the first match
+		if payload == nil {
+			payload, err = json.Marshal(models.WebhookPayload{
+				ID:             event.ID,
+				Type:           string(event.Type),
+				OrganizationID: orgID,
+				Time:           event.Time,
+				Data:           event.Data,
+			})
+			if err != nil {
+				logger.Ctx(ctx).Error().Err(err).Str("eventId", event.ID).Msg("Failed to encode webhook payload")
+				return
+			}
+		}
+
+		delivery := models.NewWebhookDelivery(wh, string(event.Type), payload)
+		if err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("webhookId", wh.ID).Str("eventId", event.ID).Msg("Failed to queue webhook delivery")
+		}
+	}
+}
+
+// RunDispatcher delivers due webhook deliveries until the context is
+// cancelled. It must only run on one instance at a time.
+func (s *WebhookService) RunDispatcher(ctx context.Context) {
+	ticker := time.NewTicker(webhookDispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		s.dispatchDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchDue attempts one batch of due deliveries
+func (s *WebhookService) dispatchDue(ctx context.Context) {
+	deliveries, err := s.deliveryRepo.GetDue(ctx, time.Now(), webhookDispatchBatch)
+	if err != nil {
+		return
+	}
+
+	webhooks := make(map[string]*models.WebhookSubscription)
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+
+		wh, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			wh, err = s.webhookRepo.GetByID(ctx, delivery.OrganizationID, delivery.WebhookID)
+			if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				continue
+			}
+			webhooks[delivery.WebhookID] = wh
+		}
+
+		// Give up on deliveries of deleted or disabled webhooks
+		if wh == nil || !wh.Active {
+			now := time.Now()
+			delivery.Status = models.WebhookDeliveryFailed
+			delivery.NextAttemptAt = nil
+			delivery.CompletedAt = &now
+			_ = s.deliveryRepo.RecordAttempt(ctx, delivery, models.WebhookAttempt{
+				At:    now,
+				Error: "webhook deleted or disabled",
+			})
+			continue
+		}
+
+		s.attempt(ctx, wh, delivery, true)
+	}
+}
+
+// attempt sends a delivery once and records the outcome, scheduling a retry
+// with exponential backoff if it failed and retry is set
+func (s *WebhookService) attempt(ctx context.Context, wh *models.WebhookSubscription, delivery *models.WebhookDelivery, retry bool) {
+	sendCtx, cancel := context.WithTimeout(ctx, webhookDeliveryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	status, err := webhook.Deliver(sendCtx, wh.URL, wh.Secret, delivery.Payload)
+	attempt := models.WebhookAttempt{
+		At:         start,
+		StatusCode: status,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+
+	now := time.Now()
+	attempts := len(delivery.Attempts) + 1
+	switch {
+	case err == nil:
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.NextAttemptAt = nil
+		delivery.CompletedAt = &now
+	case retry && attempts < webhookMaxAttempts && isRetryable(err):
+		attempt.Error = attemptError(err)
+		next := now.Add(webhookBackoff(attempts))
+		delivery.NextAttemptAt = &next
+	default:
+		attempt.Error = attemptError(err)
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.CompletedAt = &now
+	}
+	delivery.Attempts = append(delivery.Attempts, attempt)
+
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("webhookId", wh.ID).Str("deliveryId", delivery.ID).
+			Int("attempt", attempts).Str("status", string(delivery.Status)).Msg("Webhook delivery attempt failed")
+	}
+
+	if err := s.deliveryRepo.RecordAttempt(ctx, delivery, attempt); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("deliveryId", delivery.ID).Msg("Failed to record webhook delivery attempt")
+	}
+}
+
+// checkPermission checks that the user can manage the organization's webhooks
+func (s *WebhookService) checkPermission(ctx context.Context, orgID, userID string) error {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrOrganizationNotFound
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", orgID).Msg("Failed to get organization for webhooks")
+		return err
+	}
+
+	// Check permissions - must be admin or owner
+	if !org.Can(userID, models.PermOrgManageWebhooks) {
+		return insufficientPermissions("manage webhooks")
+	}
+
+	return nil
+}
+
+// getWebhook gets a webhook, mapping a missing document to a not found error
+func (s *WebhookService) getWebhook(ctx context.Context, orgID, webhookID string) (*models.WebhookSubscription, error) {
+	wh, err := s.webhookRepo.GetByID(ctx, orgID, webhookID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NotFound("WEBHOOK_NOT_FOUND", "webhook not found")
+		}
+		logger.Ctx(ctx).Error().Err(err).Str("id", webhookID).Msg("Failed to get webhook")
+		return nil, err
+	}
+	return wh, nil
+}
+
+// generateWebhookSecret generates a random webhook signing secret
+func generateWebhookSecret() (string, error) {
+	return id.Token(webhookSecretPrefix, 24)
+}
+
+// webhookBackoff returns the delay before the next attempt after the given number of attempts
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookInitialBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// isRetryable checks if a delivery error may succeed on a later attempt.
+// Client errors other than timeouts and rate limiting are permanent, as are
+// refused addresses.
+func isRetryable(err error) bool {
+	if errors.Is(err, webhook.ErrForbiddenAddress) {
+		return false
+	}
+	var httpErr *utils.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Status >= 500 || httpErr.Status == 408 || httpErr.Status == 429
+	}
+	return true
+}
+
+// attemptError describes a failed attempt for the delivery log, which the
+// organization's admins see. Error responses are described by their status
+// alone, and other errors are cut short.
+func attemptError(err error) string {
+	var httpErr *utils.HTTPError
+	if errors.As(err, &httpErr) {
+		return fmt.Sprintf("HTTP %d", httpErr.Status)
+	}
+	if errors.Is(err, webhook.ErrForbiddenAddress) {
+		return webhook.ErrForbiddenAddress.Error()
+	}
+
+	message := err.Error()
+	if len(message) > webhookMaxErrorLength {
+		message = strings.ToValidUTF8(message[:webhookMaxErrorLength], "") + "..."
+	}
+	return message
+}
+
+// eventOrganizationID resolves the organization an event belongs to
+func eventOrganizationID(event kafka.Event) string {
+	if strings.HasPrefix(string(event.Type), "organization.") {
+		return event.Subject
+	}
+
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return ""
+	}
+
+	var fields struct {
+		OrgID          string `json:"orgId"`
+		OrganizationID string `json:"organizationId"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ""
+	}
+
+	if fields.OrganizationID != "" {
+		return fields.OrganizationID
+	}
+	return fields.OrgID
+}
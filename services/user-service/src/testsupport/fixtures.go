@@ -0,0 +1,43 @@
+package testsupport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/repositories"
+)
+
+// processedEventTTL is how long the fixtures' processed events are kept
+const processedEventTTL = time.Hour
+
+// Fixtures are the repositories of a test over an ephemeral store, and an
+// in-memory Kafka that skips events recorded as processed, like the service
+type Fixtures struct {
+	Store           db.Storage
+	Users           *repositories.UserRepository
+	Teams           *repositories.TeamRepository
+	Orgs            *repositories.OrganizationRepository
+	ProcessedEvents *repositories.ProcessedEventRepository
+	Kafka           *Kafka
+
+	t testing.TB
+}
+
+// New sets up the fixtures of a test, torn down when the test ends
+func New(t testing.TB) *Fixtures {
+	t.Helper()
+
+	store := NewStorage(t)
+	f := &Fixtures{
+		Store:           store,
+		Users:           repositories.NewUserRepository(store),
+		Teams:           repositories.NewTeamRepository(store),
+		Orgs:            repositories.NewOrganizationRepository(store),
+		ProcessedEvents: repositories.NewProcessedEventRepository(store, processedEventTTL),
+		Kafka:           NewKafka(Topics),
+		t:               t,
+	}
+	f.Kafka.UseIdempotencyStore(f.ProcessedEvents)
+	return f
+}
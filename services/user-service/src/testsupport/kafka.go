@@ -0,0 +1,181 @@
+package testsupport
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/pkg/id"
+	"github.com/your-username/slido-clone/user-service/pkg/kafka"
+	"github.com/your-username/slido-clone/user-service/pkg/logger"
+)
+
+// Topics are the default Kafka topics
+var Topics = config.KafkaTopics{
+	UserEvents:    "user.events",
+	AuthEvents:    "auth.events",
+	TeamEvents:    "team.events",
+	BillingEvents: "billing.events",
+	Notifications: "notification.events",
+}
+
+var (
+	_ kafka.EventPublisher = (*Kafka)(nil)
+	_ kafka.EventConsumer  = (*Kafka)(nil)
+)
+
+// Published is an event published to a topic
+type Published struct {
+	Topic string
+	Event kafka.Event
+}
+
+// Kafka is an in-memory stand-in for the Kafka producer and consumer.
+// Published events are recorded rather than sent, and events are delivered
+// to the registered handlers with Deliver, the way the consumer would.
+type Kafka struct {
+	topics config.KafkaTopics
+
+	mu          sync.Mutex
+	published   []Published
+	handlers    map[string]map[kafka.EventType]kafka.Handler
+	idempotency kafka.IdempotencyStore
+}
+
+// NewKafka creates an in-memory Kafka publishing to topics
+func NewKafka(topics config.KafkaTopics) *Kafka {
+	return &Kafka{
+		topics:   topics,
+		handlers: make(map[string]map[kafka.EventType]kafka.Handler),
+	}
+}
+
+// PublishUserEvent implements kafka.EventPublisher
+func (k *Kafka) PublishUserEvent(ctx context.Context, eventType kafka.EventType, data interface{}, subject string) error {
+	k.publish(ctx, k.topics.UserEvents, eventType, data, subject)
+	return nil
+}
+
+// PublishTeamEvent implements kafka.EventPublisher
+func (k *Kafka) PublishTeamEvent(ctx context.Context, eventType kafka.EventType, data interface{}, subject string) error {
+	k.publish(ctx, k.topics.TeamEvents, eventType, data, subject)
+	return nil
+}
+
+// publish records an event
+func (k *Kafka) publish(ctx context.Context, topic string, eventType kafka.EventType, data interface{}, subject string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.published = append(k.published, Published{
+		Topic: topic,
+		Event: NewEvent(ctx, eventType, data, subject),
+	})
+}
+
+// Published gets the events published so far, in order
+func (k *Kafka) Published() []Published {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return append([]Published(nil), k.published...)
+}
+
+// PublishedOfType gets the events of a type published so far, in order
+func (k *Kafka) PublishedOfType(eventType kafka.EventType) []kafka.Event {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	var events []kafka.Event
+	for _, p := range k.published {
+		if p.Event.Type == eventType {
+			events = append(events, p.Event)
+		}
+	}
+	return events
+}
+
+// Reset forgets the events published so far
+func (k *Kafka) Reset() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.published = nil
+}
+
+// UseIdempotencyStore implements kafka.EventConsumer
+func (k *Kafka) UseIdempotencyStore(store kafka.IdempotencyStore) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.idempotency = store
+}
+
+// RegisterHandler implements kafka.EventConsumer
+func (k *Kafka) RegisterHandler(topic string, eventType kafka.EventType, handler kafka.Handler) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.handlers[topic]; !ok {
+		k.handlers[topic] = make(map[kafka.EventType]kafka.Handler)
+	}
+	k.handlers[topic][eventType] = handler
+}
+
+// Deliver hands an event read from a topic to its handler, like the
+// consumer: the handler for its type, or the topic's wildcard handler, is
+// called with the event's correlation ID, and events already processed are
+// skipped. Events without a handler are ignored.
+func (k *Kafka) Deliver(ctx context.Context, topic string, event kafka.Event) error {
+	k.mu.Lock()
+	handler, ok := k.handlers[topic][event.Type]
+	if !ok {
+		handler, ok = k.handlers[topic]["*"]
+	}
+	idempotency := k.idempotency
+	k.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	if idempotency != nil && event.ID != "" {
+		processed, err := idempotency.IsProcessed(ctx, event.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check processed event: %w", err)
+		}
+		if processed {
+			return nil
+		}
+	}
+
+	handlerCtx := ctx
+	if event.CorrelationID != "" {
+		handlerCtx = logger.WithCorrelationID(ctx, event.CorrelationID)
+	}
+	if err := handler(handlerCtx, event); err != nil {
+		return fmt.Errorf("error handling event: %w", err)
+	}
+
+	if idempotency != nil && event.ID != "" {
+		return idempotency.MarkProcessed(ctx, event.ID, topic, string(event.Type))
+	}
+	return nil
+}
+
+// NewEvent creates an event the way the producer does, carrying the
+// correlation ID of ctx
+func NewEvent(ctx context.Context, eventType kafka.EventType, data interface{}, subject string) kafka.Event {
+	return kafka.Event{
+		ID:            id.New(),
+		Type:          eventType,
+		Source:        "user-service",
+		Subject:       subject,
+		Time:          time.Now(),
+		SchemaVersion: kafka.SchemaVersion(eventType),
+		Data:          data,
+		CorrelationID: logger.CorrelationID(ctx),
+	}
+}
@@ -0,0 +1,103 @@
+package testsupport
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/your-username/slido-clone/user-service/models"
+	"github.com/your-username/slido-clone/user-service/pkg/tenancy"
+)
+
+// seq numbers seeded records, so their names and emails are unique
+var seq atomic.Uint64
+
+// SeedUser saves an active user with a unique user ID and email, after
+// applying the options
+func (f *Fixtures) SeedUser(opts ...func(*models.User)) *models.User {
+	f.t.Helper()
+
+	n := seq.Add(1)
+	user := models.NewUser(models.CreateUserRequest{
+		UserID:    fmt.Sprintf("user-%d", n),
+		Email:     fmt.Sprintf("user%d@example.com", n),
+		FirstName: "Test",
+		LastName:  fmt.Sprintf("User %d", n),
+		Role:      models.RoleUser,
+	})
+	for _, opt := range opts {
+		opt(user)
+	}
+
+	if err := f.Users.Create(seedContext(), user); err != nil {
+		f.t.Fatalf("seeding user %s: %v", user.UserID, err)
+	}
+	return user
+}
+
+// SeedOrganization saves an organization owned by owner, after applying the
+// options, and adds it to the owner's organizations
+func (f *Fixtures) SeedOrganization(owner *models.User, opts ...func(*models.Organization)) *models.Organization {
+	f.t.Helper()
+
+	ctx := seedContext()
+	org := models.NewOrganization(models.CreateOrganizationRequest{
+		Name: fmt.Sprintf("Organization %d", seq.Add(1)),
+	}, owner.UserID)
+	for _, opt := range opts {
+		opt(org)
+	}
+
+	if err := f.Orgs.Create(ctx, org); err != nil {
+		f.t.Fatalf("seeding organization %s: %v", org.Name, err)
+	}
+	if err := f.Users.AddOrganizationToUser(ctx, owner.UserID, org.ID); err != nil {
+		f.t.Fatalf("adding organization %s to user %s: %v", org.ID, owner.UserID, err)
+	}
+	return org
+}
+
+// SeedTeam saves a team of org owned by owner, after applying the options,
+// and adds it to the organization and the owner's teams
+func (f *Fixtures) SeedTeam(org *models.Organization, owner *models.User, opts ...func(*models.Team)) *models.Team {
+	f.t.Helper()
+
+	ctx := seedContext()
+	team := models.NewTeam(models.CreateTeamRequest{
+		Name:           fmt.Sprintf("Team %d", seq.Add(1)),
+		OrganizationID: org.ID,
+	}, owner.UserID)
+	for _, opt := range opts {
+		opt(team)
+	}
+
+	if err := f.Teams.Create(ctx, team); err != nil {
+		f.t.Fatalf("seeding team %s: %v", team.Name, err)
+	}
+	if err := f.Orgs.AddTeam(ctx, org.ID, team.ID); err != nil {
+		f.t.Fatalf("adding team %s to organization %s: %v", team.ID, org.ID, err)
+	}
+	if err := f.Users.AddTeamToUser(ctx, owner.UserID, team.ID); err != nil {
+		f.t.Fatalf("adding team %s to user %s: %v", team.ID, owner.UserID, err)
+	}
+	return team
+}
+
+// SeedMember adds a user to an organization with a role
+func (f *Fixtures) SeedMember(org *models.Organization, user *models.User, role models.OrganizationMemberRole) {
+	f.t.Helper()
+
+	ctx := seedContext()
+	if err := f.Orgs.AddMember(ctx, org.ID, user.UserID, role, org.CreatedBy); err != nil {
+		f.t.Fatalf("adding user %s to organization %s: %v", user.UserID, org.ID, err)
+	}
+	if err := f.Users.AddOrganizationToUser(ctx, user.UserID, org.ID); err != nil {
+		f.t.Fatalf("adding organization %s to user %s: %v", org.ID, user.UserID, err)
+	}
+}
+
+// seedContext returns the context fixtures are saved with. Seeding sets up
+// data across organizations, like background work.
+func seedContext() context.Context {
+	return tenancy.Unscoped(context.Background())
+}
@@ -0,0 +1,71 @@
+// Package testsupport sets up what repository and consumer tests need: an
+// ephemeral store, an in-memory stand-in for Kafka and seed data.
+package testsupport
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/your-username/slido-clone/user-service/config"
+	"github.com/your-username/slido-clone/user-service/db"
+	"github.com/your-username/slido-clone/user-service/pkg/id"
+)
+
+// MongoURIEnv is the environment variable holding the URI of a MongoDB
+// server for tests. When it's set, each test gets a database of its own on
+// that server; otherwise tests use an in-memory embedded store.
+const MongoURIEnv = "TEST_MONGO_URI"
+
+// storageTimeout bounds connecting to and dropping a test database
+const storageTimeout = 10 * time.Second
+
+// NewStorage opens an empty store for a test, removed when the test ends
+func NewStorage(t testing.TB) db.Storage {
+	t.Helper()
+
+	if uri := os.Getenv(MongoURIEnv); uri != "" {
+		return newMongoStorage(t, uri)
+	}
+
+	store, err := db.Open(&config.Config{Storage: config.StorageConfig{Driver: db.DriverEmbedded}})
+	if err != nil {
+		t.Fatalf("opening embedded store: %v (set %s to test against MongoDB)", err, MongoURIEnv)
+	}
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+	return store
+}
+
+// newMongoStorage creates a database for a test on a MongoDB server, and
+// drops it when the test ends
+func newMongoStorage(t testing.TB, uri string) db.Storage {
+	t.Helper()
+
+	suffix, err := id.Token("", 8)
+	if err != nil {
+		t.Fatalf("naming test database: %v", err)
+	}
+
+	store, err := db.New(&config.MongoDBConfig{
+		URI:         uri,
+		DBName:      "user_service_test_" + suffix,
+		Timeout:     storageTimeout,
+		MaxPoolSize: 10,
+	})
+	if err != nil {
+		t.Fatalf("connecting to MongoDB at %s: %v", MongoURIEnv, err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+		defer cancel()
+
+		if err := store.DB.Drop(ctx); err != nil {
+			t.Errorf("dropping test database: %v", err)
+		}
+		_ = store.Close()
+	})
+	return store
+}